@@ -0,0 +1,23 @@
+//go:build embedplugins
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// bundledArchives holds the first-party plugin archives that `make
+// build-bundled` packs into cmd/cortex/bundled before compiling.
+//
+//go:embed bundled/*.cortexplugin
+var bundledArchives embed.FS
+
+// bundledPlugins returns the plugin archives built into this binary.
+func bundledPlugins() fs.FS {
+	bundle, err := fs.Sub(bundledArchives, "bundled")
+	if err != nil {
+		return nil
+	}
+	return bundle
+}
@@ -0,0 +1,11 @@
+//go:build !embedplugins
+
+package main
+
+import "io/fs"
+
+// bundledPlugins returns nil: only binaries built with the embedplugins tag
+// carry plugins of their own.
+func bundledPlugins() fs.FS {
+	return nil
+}
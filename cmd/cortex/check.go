@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+	"github.com/alvarotorresc/cortex/internal/config"
+	pluginpkg "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// configChecker prints the outcome of each check-config step and counts the
+// failures.
+type configChecker struct {
+	out      io.Writer
+	failures int
+}
+
+func (c *configChecker) ok(format string, args ...any) {
+	fmt.Fprintf(c.out, "ok    "+format+"\n", args...)
+}
+
+func (c *configChecker) warn(format string, args ...any) {
+	fmt.Fprintf(c.out, "warn  "+format+"\n", args...)
+}
+
+func (c *configChecker) fail(format string, args ...any) {
+	c.failures++
+	fmt.Fprintf(c.out, "FAIL  "+format+"\n", args...)
+}
+
+// checkConfig runs the checks behind `cortex check-config`: it loads the
+// configuration and verifies everything startup depends on, reporting every
+// problem instead of stopping at the first. It returns the exit code.
+func checkConfig(out io.Writer) int {
+	checker := &configChecker{out: out}
+
+	cfg, err := config.Load()
+	if err != nil {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			for _, problem := range joined.Unwrap() {
+				checker.fail("%v", problem)
+			}
+		} else {
+			checker.fail("%v", err)
+		}
+		fmt.Fprintf(out, "\n%d problem(s) found; fix the settings above and run check-config again\n", checker.failures)
+		return 1
+	}
+	checker.ok("settings are valid")
+
+	checkDirectory(checker, "CORTEX_DATA_DIR", cfg.DataDir)
+	checkDirectory(checker, "CORTEX_BACKUP_DIR", cfg.BackupDir)
+	checkPort(checker, cfg)
+	checkTLS(checker, cfg)
+	checkPassphrase(checker, cfg)
+	checkPlugins(checker, cfg.PluginDir)
+
+	if info, err := os.Stat(cfg.FrontendDir); err != nil || !info.IsDir() {
+		checker.warn("CORTEX_FRONTEND_DIR %s does not exist; only the API will be served", cfg.FrontendDir)
+	} else {
+		checker.ok("frontend found in %s", cfg.FrontendDir)
+	}
+
+	if checker.failures > 0 {
+		fmt.Fprintf(out, "\n%d problem(s) found\n", checker.failures)
+		return 1
+	}
+	fmt.Fprintln(out, "\nconfiguration looks good")
+	return 0
+}
+
+// checkDirectory verifies that dir, or the nearest existing parent it would be
+// created in, accepts new files.
+func checkDirectory(checker *configChecker, name string, dir string) {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				checker.fail("%s %s: %s is not a directory", name, dir, existing)
+				return
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		if !os.IsNotExist(err) || parent == existing {
+			checker.fail("%s %s: %v", name, dir, err)
+			return
+		}
+		existing = parent
+	}
+
+	file, err := os.CreateTemp(existing, ".cortex-check-*")
+	if err != nil {
+		checker.fail("%s %s is not writable: %v (fix its permissions or point %s elsewhere)", name, dir, err, name)
+		return
+	}
+	file.Close()
+	os.Remove(file.Name())
+
+	if existing != dir {
+		checker.ok("%s %s will be created in %s", name, dir, existing)
+		return
+	}
+	checker.ok("%s %s is writable", name, dir)
+}
+
+// checkPort verifies that nothing else is listening on the configured port.
+func checkPort(checker *configChecker, cfg *config.Config) {
+	listener, err := net.Listen("tcp", cfg.Address())
+	if err != nil {
+		checker.fail("port %d is not available: %v (stop the process using it or set CORTEX_PORT)", cfg.Port, err)
+		return
+	}
+	listener.Close()
+	checker.ok("port %d is free", cfg.Port)
+}
+
+// checkTLS verifies that the TLS certificate and key load, or that a
+// self-signed certificate will be generated for them.
+func checkTLS(checker *configChecker, cfg *config.Config) {
+	if !cfg.TLSEnabled() {
+		return
+	}
+
+	_, certErr := os.Stat(cfg.TLSCertFile)
+	_, keyErr := os.Stat(cfg.TLSKeyFile)
+	if cfg.TLSSelfSigned && os.IsNotExist(certErr) && os.IsNotExist(keyErr) {
+		checker.ok("a self-signed certificate will be generated in %s on first start", cfg.TLSCertFile)
+		return
+	}
+
+	certificate, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		checker.fail("TLS certificate %s cannot be loaded: %v (check CORTEX_TLS_CERT and CORTEX_TLS_KEY)", cfg.TLSCertFile, err)
+		return
+	}
+	if expires := certificate.Leaf.NotAfter; time.Now().After(expires) {
+		if cfg.TLSSelfSigned {
+			checker.warn("TLS certificate %s expired on %s; a new self-signed one will be generated", cfg.TLSCertFile, expires.Format(time.DateOnly))
+			return
+		}
+		checker.fail("TLS certificate %s expired on %s", cfg.TLSCertFile, expires.Format(time.DateOnly))
+		return
+	}
+	checker.ok("TLS certificate %s is valid until %s", cfg.TLSCertFile, certificate.Leaf.NotAfter.Format(time.DateOnly))
+}
+
+// checkPassphrase verifies that encrypted plugin databases can be opened with
+// the configured passphrase, without creating a key for a new data directory.
+func checkPassphrase(checker *configChecker, cfg *config.Config) {
+	if !atrest.Enabled(cfg.DataDir) {
+		if cfg.DBPassphrase != "" || cfg.DBPassphrasePrompt {
+			checker.ok("plugin databases will be encrypted at rest on first start")
+		}
+		return
+	}
+
+	switch {
+	case cfg.DBPassphrase != "":
+		if _, err := atrest.DeriveKey(cfg.DataDir, cfg.DBPassphrase); err != nil {
+			checker.fail("CORTEX_DB_PASSPHRASE cannot unlock the encrypted plugin databases: %v", err)
+			return
+		}
+		checker.ok("CORTEX_DB_PASSPHRASE unlocks the encrypted plugin databases")
+	case cfg.DBPassphrasePrompt:
+		checker.ok("plugin databases are encrypted; the passphrase will be asked for at startup")
+	default:
+		checker.fail("plugin databases in %s are encrypted: set CORTEX_DB_PASSPHRASE, CORTEX_DB_PASSPHRASE_FILE or CORTEX_DB_PASSPHRASE_PROMPT=true", cfg.DataDir)
+	}
+}
+
+// checkPlugins validates every plugin in the plugin directory without
+// starting them.
+func checkPlugins(checker *configChecker, pluginDir string) {
+	if _, err := os.Stat(pluginDir); os.IsNotExist(err) {
+		checker.warn("CORTEX_PLUGIN_DIR %s does not exist; no plugins will be loaded", pluginDir)
+		return
+	}
+
+	valid, problems, err := pluginpkg.CheckPluginDir(pluginDir)
+	if err != nil {
+		checker.fail("CORTEX_PLUGIN_DIR %s: %v", pluginDir, err)
+		return
+	}
+	for _, id := range valid {
+		checker.ok("plugin %s", id)
+	}
+	for _, problem := range problems {
+		checker.fail("%v", problem)
+	}
+	if len(valid) == 0 && len(problems) == 0 {
+		checker.warn("CORTEX_PLUGIN_DIR %s contains no plugins", pluginDir)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/config"
+)
+
+// commandTimeout bounds how long a plugin command waits for the host.
+const commandTimeout = 30 * time.Second
+
+// commandGroup mirrors the entries of GET /api/commands.
+type commandGroup struct {
+	Plugin   string `json:"plugin"`
+	CLI      string `json:"cli"`
+	Commands []struct {
+		Name        string `json:"name"`
+		Usage       string `json:"usage"`
+		Description string `json:"description"`
+	} `json:"commands"`
+}
+
+// commandClient talks to the running host on behalf of `cortex {cli} {command}`.
+type commandClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// newCommandClient targets CORTEX_URL, or the host listening on CORTEX_PORT
+// on this machine, authenticating with CORTEX_API_KEY when it is set. When
+// the local host serves HTTPS, its certificate is trusted, so self-signed
+// ones work too.
+func newCommandClient() *commandClient {
+	client := &http.Client{Timeout: commandTimeout}
+	baseURL := os.Getenv("CORTEX_URL")
+	if baseURL == "" {
+		port := config.Setting("CORTEX_PORT")
+		if port == "" {
+			port = "8080"
+		}
+		baseURL = "http://localhost:" + port
+		if certFile := localCertificateFile(); certFile != "" {
+			baseURL = "https://localhost:" + port
+			client.Transport = trustingTransport(certFile)
+		}
+	}
+	return &commandClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  os.Getenv("CORTEX_API_KEY"),
+		http:    client,
+	}
+}
+
+// localCertificateFile returns the certificate the local host serves HTTPS
+// with, from the same settings the host reads, or "" when it serves HTTP.
+func localCertificateFile() string {
+	if certFile := config.Setting("CORTEX_TLS_CERT"); certFile != "" {
+		return certFile
+	}
+	if config.Setting("CORTEX_TLS_SELF_SIGNED") != "true" {
+		return ""
+	}
+	return filepath.Join(dataDir(), "tls", "cert.pem")
+}
+
+// dataDir returns the host's data directory, from the same settings the
+// host reads.
+func dataDir() string {
+	if dir := config.Setting("CORTEX_DATA_DIR"); dir != "" {
+		return dir
+	}
+	return "./data"
+}
+
+// trustingTransport returns a transport that trusts certFile on top of the
+// system roots. An unreadable file leaves the system roots alone, and the
+// request fails with the usual certificate error.
+func trustingTransport(certFile string) http.RoundTripper {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if pem, err := os.ReadFile(certFile); err == nil {
+		pool.AppendCertsFromPEM(pem)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport
+}
+
+// runPluginCommand runs `cortex {cli} {command} [args...]`: it finds the
+// plugin whose manifest declares the command and has the host run it, so the
+// command goes through the same plugin handler as the HTTP API. It prints the
+// response data and returns the exit code.
+func runPluginCommand(client *commandClient, args []string, stdout io.Writer, stderr io.Writer) int {
+	groups, err := client.groups()
+	if args[0] == "help" {
+		// Without a running host, only the host's own commands are listed
+		printUsage(stdout, groups)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "cannot list plugin commands: %v\n", err)
+		return 1
+	}
+
+	var group *commandGroup
+	for i := range groups {
+		if groups[i].CLI == args[0] || groups[i].Plugin == args[0] {
+			group = &groups[i]
+			break
+		}
+	}
+	if group == nil {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr, groups)
+		return 2
+	}
+
+	if len(args) < 2 || args[1] == "help" {
+		printGroupUsage(stdout, group)
+		if len(args) < 2 {
+			return 2
+		}
+		return 0
+	}
+
+	known := false
+	for _, command := range group.Commands {
+		known = known || command.Name == args[1]
+	}
+	if !known {
+		fmt.Fprintf(stderr, "unknown command %q for %s\n\n", args[1], group.CLI)
+		printGroupUsage(stderr, group)
+		return 2
+	}
+
+	status, body, err := client.run(group.Plugin, args[1], args[2:])
+	if err != nil {
+		fmt.Fprintf(stderr, "%s %s: %v\n", group.CLI, args[1], err)
+		return 1
+	}
+	return printCommandResponse(stdout, stderr, status, body)
+}
+
+// groups returns the commands of every loaded plugin.
+func (c *commandClient) groups() ([]commandGroup, error) {
+	status, body, err := c.do(http.MethodGet, "/api/commands", nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("host answered %d: %s", status, errorMessage(body))
+	}
+
+	var response struct {
+		Data []commandGroup `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parsing command list: %w", err)
+	}
+	return response.Data, nil
+}
+
+// run asks the host to run a plugin command and returns its response.
+func (c *commandClient) run(pluginID string, command string, args []string) (int, []byte, error) {
+	payload, err := json.Marshal(map[string][]string{"args": args})
+	if err != nil {
+		return 0, nil, err
+	}
+	return c.do(http.MethodPost, "/api/plugins/"+url.PathEscape(pluginID)+"/commands/"+url.PathEscape(command), payload)
+}
+
+func (c *commandClient) do(method string, path string, payload []byte) (int, []byte, error) {
+	request, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return 0, nil, fmt.Errorf("is cortex running at %s? %w", c.baseURL, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading response: %w", err)
+	}
+	return response.StatusCode, body, nil
+}
+
+// printCommandResponse prints the data of a successful response, indented,
+// or the error message of a failed one, and returns the exit code.
+func printCommandResponse(stdout io.Writer, stderr io.Writer, status int, body []byte) int {
+	if status >= 400 {
+		fmt.Fprintln(stderr, errorMessage(body))
+		return 1
+	}
+
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil || response.Data == nil {
+		_, _ = stdout.Write(body)
+		return 0
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, response.Data, "", "  "); err != nil {
+		_, _ = stdout.Write(response.Data)
+		return 0
+	}
+	fmt.Fprintln(stdout, indented.String())
+	return 0
+}
+
+// errorMessage returns the message of an API error body, or the body itself.
+func errorMessage(body []byte) string {
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err == nil && response.Error.Message != "" {
+		return response.Error.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// printUsage lists the host's commands and the loaded plugins' groups.
+func printUsage(out io.Writer, groups []commandGroup) {
+	fmt.Fprintln(out, "usage: cortex [command] [args...]")
+	fmt.Fprintln(out, "\n  (none)          start the server")
+	fmt.Fprintln(out, "  check-config    check the configuration without starting")
+	fmt.Fprintln(out, "  plugin new      generate a plugin skeleton")
+	for _, group := range groups {
+		fmt.Fprintf(out, "  %-15s %d command(s) of plugin %s; run `cortex %s help`\n", group.CLI, len(group.Commands), group.Plugin, group.CLI)
+	}
+}
+
+// printGroupUsage lists the commands of one plugin.
+func printGroupUsage(out io.Writer, group *commandGroup) {
+	fmt.Fprintf(out, "usage: cortex %s <command> [args...]\n\n", group.CLI)
+	for _, command := range group.Commands {
+		fmt.Fprintf(out, "  %-40s %s\n", command.Usage, command.Description)
+	}
+}
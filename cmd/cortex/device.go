@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/server"
+)
+
+// runDeviceTool runs `cortex device <command>`, which manages devices on this
+// machine without going through the API. It returns the exit code.
+func runDeviceTool(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Fprintln(stderr, "usage: cortex device add <name> [-platform <platform>]")
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	if args[0] != "add" {
+		fmt.Fprintf(stderr, "unknown command %q for device\n", args[0])
+		return 2
+	}
+	return addDevice(args[1:], stdout, stderr)
+}
+
+// addDevice registers a device straight in the host database and prints its
+// token for `cortex device add`. It is the way back in once every device and
+// API key of the instance is lost, and works whether or not the host is running.
+func addDevice(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cortex device add", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	platform := flags.String("platform", "web", "platform of the device")
+
+	// Flags may come before or after the name.
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	name := strings.TrimSpace(flags.Arg(0))
+	if err := flags.Parse(flags.Args()[min(1, flags.NArg()):]); err != nil {
+		return 2
+	}
+	if name == "" || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: cortex device add <name> [-platform <platform>]")
+		return 2
+	}
+
+	hostDB, err := db.NewHostDB(dataDir())
+	if err != nil {
+		fmt.Fprintf(stderr, "cannot open the host database: %v\n", err)
+		return 1
+	}
+	defer hostDB.Close()
+
+	device, token, err := server.IssueDevice(hostDB, name, strings.ToLower(*platform))
+	if err != nil {
+		fmt.Fprintf(stderr, "cannot register device: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "registered device %s (id %d)\n", device.Name, device.ID)
+	fmt.Fprintf(stdout, "token: %s\n", token)
+	fmt.Fprintln(stdout, "Sign in with it at /signin, or send it as the X-Device-Token header. It is not shown again.")
+	return 0
+}
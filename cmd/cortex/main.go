@@ -1,57 +1,268 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/alvarotorresc/cortex/internal/atrest"
+	"github.com/alvarotorresc/cortex/internal/attachments"
+	"github.com/alvarotorresc/cortex/internal/backup"
 	"github.com/alvarotorresc/cortex/internal/config"
 	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/logging"
+	"github.com/alvarotorresc/cortex/internal/notify"
 	pluginpkg "github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/secrets"
 	"github.com/alvarotorresc/cortex/internal/server"
+	"github.com/alvarotorresc/cortex/internal/undo"
+)
+
+const (
+	// heartbeatInterval bounds how far off the recorded time of a crash can be.
+	heartbeatInterval = time.Minute
+	// resourceCheckInterval is how often limited plugins are measured.
+	resourceCheckInterval = 2 * time.Second
+	// walCheckInterval is how often plugin database WALs are measured.
+	walCheckInterval = time.Minute
+	// undoPruneInterval is how long past its window an undo action and the
+	// data it set aside may linger.
+	undoPruneInterval = time.Minute
+	// auditPruneInterval is how often audit entries past their retention are removed.
+	auditPruneInterval = time.Hour
 )
 
 func main() {
-	log.SetOutput(os.Stdout)
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check-config":
+			os.Exit(checkConfig(os.Stdout))
+		case "plugin":
+			os.Exit(runPluginTool(os.Args[2:], os.Stdout, os.Stderr))
+		case "device":
+			os.Exit(runDeviceTool(os.Args[2:], os.Stdout, os.Stderr))
+		case pluginpkg.SandboxCommand:
+			// The loader starts plugins with a filesystem root through the host binary
+			fatal("failed to start sandboxed plugin", pluginpkg.RunSandbox(os.Args[2:]))
+		default:
+			// Anything else is a plugin command, run by the host that is serving
+			os.Exit(runPluginCommand(newCommandClient(), os.Args[1:], os.Stdout, os.Stderr))
+		}
+	}
+
+	slog.SetDefault(logging.NewLogger(os.Stdout, logging.FormatText, "info"))
 
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("failed to load configuration", err)
 	}
 
-	log.Printf("Configuration loaded: port=%d, data=%s, plugins=%s",
-		cfg.Port, cfg.DataDir, cfg.PluginDir)
+	slog.SetDefault(logging.NewLogger(os.Stdout, cfg.LogFormat, cfg.LogLevel))
+	slog.Info("configuration loaded", "port", cfg.Port, "data", cfg.DataDir, "plugins", cfg.PluginDir, "file", cfg.ConfigFile)
 
 	// Ensure data directory exists
 	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
-		log.Fatalf("Failed to create data directory: %v", err)
+		fatal("failed to create data directory", err)
 	}
 
 	// Initialize host database
 	hostDB, err := db.NewHostDB(cfg.DataDir)
 	if err != nil {
-		log.Fatalf("Failed to initialize host database: %v", err)
+		fatal("failed to initialize host database", err)
 	}
 	defer hostDB.Close()
 
+	// Record this run so crashes and restarts show up in the system history
+	if _, err := hostDB.StartRun(); err != nil {
+		slog.Warn("failed to record host run", "error", err)
+	}
+
+	// Initialize notification center and start the digest scheduler
+	center := notify.NewCenter(hostDB, notify.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	})
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go center.Start(schedulerCtx)
+	go recordHeartbeats(schedulerCtx, hostDB)
+
+	// Back up the host and plugin databases on a schedule, keeping the most recent ones
+	backups := backup.NewManager(cfg.DataDir, cfg.BackupDir, cfg.BackupRetention)
+	if cfg.BackupInterval > 0 {
+		go backups.Start(schedulerCtx, cfg.BackupInterval)
+	}
+
 	// Initialize plugin system
 	registry := pluginpkg.NewRegistry()
 	loader := pluginpkg.NewLoader(cfg.PluginDir, cfg.DataDir, registry)
+	loader.SetSettingsStore(hostDB)
+	loader.SetLoadRecorder(hostDB)
+	loader.SetInstallTracker(hostDB)
+	loader.SetMigrationPolicy(pluginpkg.MigrationPolicy(cfg.MigrationLint))
+	loader.SetDemoMode(cfg.DemoMode)
+	loader.SetDisabledPlugins(cfg.DisabledPlugins)
+	loader.SetRequestTimeout(cfg.PluginTimeout)
+	loader.SetKeepalive(cfg.PluginKeepalive)
+
+	// Plugins with resource limits are confined by a cgroup or the resource
+	// monitor, and the ones with a filesystem root by the sandbox launcher
+	loader.SetResourceLimits(cfg.ResourceLimits())
+	loader.SetCgroupRoot(cfg.PluginCgroup)
+	if executable, err := os.Executable(); err == nil {
+		loader.SetSandboxLauncher(executable)
+	}
+
+	// Plugins with the attachments permission share a deduplicated file store
+	loader.SetAttachmentStore(attachments.NewStore(filepath.Join(cfg.DataDir, "attachments"), hostDB))
+
+	// Plugins with the notifications permission post to the notification center
+	loader.SetNotifier(center)
+
+	// Plugins using HostServices keep their key-value store in the host database
+	loader.SetValueStore(hostDB)
+
+	// Encrypt plugin databases at rest with a key derived from the passphrase
+	key := databaseKey(cfg)
+	if key != nil {
+		loader.SetDatabaseKey(key)
+		slog.Info("plugin databases are encrypted at rest")
+	}
+
+	// Secrets referenced by plugin settings are encrypted with the same master key
+	secretStore := secrets.NewStore(hostDB, key)
+	loader.SetSecretStore(secretStore)
+
+	// Plugins' NotifyChanged calls drop their cached widget data and are
+	// pushed to dashboards over /api/ws
+	events := server.NewEventHub()
+	widgets := server.NewWidgetCache(cfg.WidgetCacheTTL, events)
+	loader.SetChangeListener(widgets)
+
+	// Single-binary builds install their first-party plugins into the plugins directory
+	if bundle := bundledPlugins(); bundle != nil {
+		installed, err := pluginpkg.InstallBundled(bundle, cfg.PluginDir)
+		if err != nil {
+			slog.Warn("failed to install bundled plugins", "error", err)
+		}
+		if len(installed) > 0 {
+			slog.Info("installed bundled plugins", "plugins", installed, "dir", cfg.PluginDir)
+		}
+	}
 
 	// Load all plugins from the plugins directory
 	if err := loader.LoadAll(); err != nil {
-		log.Printf("Warning: error loading plugins: %v", err)
+		slog.Warn("error loading plugins", "error", err)
+	}
+
+	// Check plugins that publish releases for updates, shown in GET /api/plugins
+	updates := pluginpkg.NewUpdateChecker(registry)
+	if cfg.UpdateCheckInterval > 0 {
+		go updates.Start(schedulerCtx, cfg.UpdateCheckInterval)
+	}
+
+	// Measure plugin database WALs for /metrics, warning when one grows too large
+	go loader.MonitorWAL(schedulerCtx, walCheckInterval, int64(cfg.WALWarnMB)<<20)
+
+	// Kill plugins over the memory and CPU limits no cgroup enforces
+	go loader.MonitorResources(schedulerCtx, resourceCheckInterval)
+
+	// Keep destructive admin actions reversible for a while, then forget them
+	undoLog := undo.NewLog(hostDB, filepath.Join(cfg.DataDir, "undo"), cfg.UndoWindow)
+	go undoLog.Start(schedulerCtx, undoPruneInterval)
+
+	// Forget audit entries older than their retention
+	if cfg.AuditRetention > 0 {
+		go pruneAuditLog(schedulerCtx, hostDB, cfg.AuditRetention)
 	}
 
 	// Ensure plugins are unloaded on exit.
 	// The server.Start function handles SIGINT/SIGTERM for HTTP shutdown.
 	// We defer plugin cleanup so it runs after the server stops.
 	defer func() {
-		log.Println("Unloading plugins...")
+		slog.Info("unloading plugins")
 		loader.UnloadAll()
 	}()
 
-	if err := server.Start(cfg, registry, loader, hostDB); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	if err := server.Start(cfg, registry, loader, hostDB, center, events, backups, secretStore, updates, undoLog, widgets); err != nil {
+		fatal("server failed", err)
+	}
+
+	if err := hostDB.EndRun(); err != nil {
+		slog.Warn("failed to record clean shutdown", "error", err)
+	}
+}
+
+// recordHeartbeats periodically marks the current run as alive, so the time of
+// a crash can be estimated from the last heartbeat.
+func recordHeartbeats(ctx context.Context, hostDB *db.HostDB) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := hostDB.HeartbeatRun(); err != nil {
+				slog.Warn("failed to record heartbeat", "error", err)
+			}
+		}
 	}
 }
+
+// pruneAuditLog removes audit entries older than retention now and then
+// every auditPruneInterval.
+func pruneAuditLog(ctx context.Context, hostDB *db.HostDB, retention time.Duration) {
+	ticker := time.NewTicker(auditPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := hostDB.DeleteAuditEntriesBefore(time.Now().Add(-retention))
+		if err != nil {
+			slog.Warn("failed to prune audit log", "error", err)
+		} else if deleted > 0 {
+			slog.Info("pruned audit log", "entries", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// databaseKey derives the at-rest master key, or returns nil when encryption
+// is off. Once databases have been encrypted, starting without the passphrase
+// is fatal: plugins could not open them.
+func databaseKey(cfg *config.Config) []byte {
+	passphrase := cfg.DBPassphrase
+	if passphrase == "" && (cfg.DBPassphrasePrompt || atrest.Enabled(cfg.DataDir)) {
+		var err error
+		passphrase, err = promptPassphrase()
+		if err != nil {
+			fatal("plugin databases are encrypted; set CORTEX_DB_PASSPHRASE", err)
+		}
+	}
+	if passphrase == "" {
+		return nil
+	}
+
+	key, err := atrest.DeriveKey(cfg.DataDir, passphrase)
+	if err != nil {
+		fatal("failed to derive database key", err)
+	}
+	return key
+}
+
+// fatal logs err and exits. Like log.Fatal, deferred calls do not run.
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// promptPassphrase is only implemented on Linux, where Cortex is deployed.
+func promptPassphrase() (string, error) {
+	return "", errors.New("prompting for the passphrase is only supported on Linux; set CORTEX_DB_PASSPHRASE")
+}
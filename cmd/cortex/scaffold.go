@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	pluginpkg "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// runPluginTool runs `cortex plugin <command>`, the host's tools for plugin
+// authors. It returns the exit code.
+func runPluginTool(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Fprintln(stderr, "usage: cortex plugin new <id> [-name <display name>] [-dir <parent dir>]")
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	if args[0] != "new" {
+		fmt.Fprintf(stderr, "unknown command %q for plugin\n", args[0])
+		return 2
+	}
+	return scaffoldPlugin(args[1:], stdout, stderr)
+}
+
+// scaffoldPlugin generates a plugin skeleton for `cortex plugin new` and
+// prints how to build and run it.
+func scaffoldPlugin(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cortex plugin new", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	name := flags.String("name", "", "display name (default: the id in title case)")
+	dir := flags.String("dir", "plugins", "directory to create the plugin in")
+
+	// Flags may come before or after the id.
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	id := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[min(1, flags.NArg()):]); err != nil {
+		return 2
+	}
+	if id == "" || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: cortex plugin new <id> [-name <display name>] [-dir <parent dir>]")
+		return 2
+	}
+
+	result, err := pluginpkg.Scaffold(*dir, pluginpkg.ScaffoldOptions{ID: id, Name: *name})
+	if err != nil {
+		fmt.Fprintf(stderr, "cannot create plugin: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "created plugin %s in %s\n", id, result.Dir)
+	for _, file := range result.Files {
+		fmt.Fprintf(stdout, "  %s\n", file)
+	}
+
+	fmt.Fprintln(stdout, "\nnext steps:")
+	fmt.Fprintf(stdout, "  cd %s\n", result.Dir)
+	if result.Standalone {
+		fmt.Fprintln(stdout, "  go mod tidy")
+	}
+	fmt.Fprintln(stdout, "  go test ./backend/...")
+	fmt.Fprintln(stdout, "  go build -o plugin ./backend")
+	fmt.Fprintf(stdout, "then start cortex with CORTEX_PLUGIN_DIR=%s\n", filepath.Dir(result.Dir))
+	return 0
+}
@@ -1,5 +1,10 @@
+import { browser } from '$app/environment';
+
 const BASE = '/api';
 
+/** Page where a browser signs in with a device token. */
+export const SIGNIN_PATH = '/signin';
+
 export async function apiFetch<T>(path: string, init?: RequestInit): Promise<T> {
   const response = await fetch(`${BASE}${path}`, {
     headers: {
@@ -9,6 +14,12 @@ export async function apiFetch<T>(path: string, init?: RequestInit): Promise<T>
     ...init,
   });
 
+  // Once devices or API keys are in use, the host answers 401 until this
+  // browser signs in; the device cookie is then sent with every request.
+  if (response.status === 401 && browser && window.location.pathname !== SIGNIN_PATH) {
+    window.location.assign(SIGNIN_PATH);
+  }
+
   if (!response.ok) {
     const error = await response.json().catch(() => ({ error: { message: 'Request failed' } }));
     throw new Error(error.error?.message ?? `HTTP ${response.status}`);
@@ -27,3 +38,8 @@ export function pluginApi(pluginId: string) {
     },
   };
 }
+
+/** Signs this browser in with a device token, kept by the host in a cookie. */
+export async function signIn(token: string): Promise<void> {
+  await apiFetch('/devices/signin', { method: 'POST', body: JSON.stringify({ token }) });
+}
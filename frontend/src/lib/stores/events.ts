@@ -0,0 +1,55 @@
+import { browser } from '$app/environment';
+import type { PluginEvent } from '$lib/types';
+
+const RECONNECT_MIN_MS = 1000;
+const RECONNECT_MAX_MS = 30000;
+
+/**
+ * Subscribes to the host's /api/ws push channel and calls onChange for every
+ * plugin.changed event. The connection is re-established with backoff if it
+ * drops. Returns a function that closes the subscription.
+ */
+export function subscribePluginChanges(onChange: (event: PluginEvent) => void): () => void {
+  if (!browser) {
+    return () => {};
+  }
+
+  let socket: WebSocket | null = null;
+  let reconnectTimer: ReturnType<typeof setTimeout> | null = null;
+  let delay = RECONNECT_MIN_MS;
+  let closed = false;
+
+  function connect() {
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    socket = new WebSocket(`${protocol}//${window.location.host}/api/ws`);
+
+    socket.onopen = () => {
+      delay = RECONNECT_MIN_MS;
+    };
+
+    socket.onmessage = (message) => {
+      try {
+        const event = JSON.parse(message.data) as PluginEvent;
+        if (event.type === 'plugin.changed') {
+          onChange(event);
+        }
+      } catch {
+        // Ignore malformed messages
+      }
+    };
+
+    socket.onclose = () => {
+      if (closed) return;
+      reconnectTimer = setTimeout(connect, delay);
+      delay = Math.min(delay * 2, RECONNECT_MAX_MS);
+    };
+  }
+
+  connect();
+
+  return () => {
+    closed = true;
+    if (reconnectTimer) clearTimeout(reconnectTimer);
+    socket?.close();
+  };
+}
@@ -7,3 +7,26 @@ export interface PluginManifest {
   color: string;
   permissions: string[];
 }
+
+export interface PluginEvent {
+  type: 'plugin.changed' | 'ping';
+  plugin?: string;
+  topic?: string;
+  time: string;
+}
+
+export interface SearchHit {
+  plugin: string;
+  type: string;
+  id: string;
+  title: string;
+  snippet: string;
+  link: string;
+  score: number;
+}
+
+export interface SearchResponse {
+  query: string;
+  results: SearchHit[];
+  failed: string[];
+}
@@ -0,0 +1,434 @@
+package atrest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
+)
+
+// EncryptedExtension is appended to a database path for its encrypted file.
+const EncryptedExtension = ".enc"
+
+// flushInterval is how often committed changes are written back to disk.
+const flushInterval = 200 * time.Millisecond
+
+// ErrEncryptedDatabase is returned when an encrypted database is opened
+// without a key.
+var ErrEncryptedDatabase = errors.New("database is encrypted and no key was provided")
+
+// EncryptedPath returns where the encrypted form of the database at path lives.
+func EncryptedPath(path string) string {
+	return path + EncryptedExtension
+}
+
+// rawConn is the part of a modernc.org/sqlite connection used to move whole
+// databases in and out of memory.
+type rawConn interface {
+	driver.Conn
+	Serialize() ([]byte, error)
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+}
+
+// OpenDatabase opens the database at path so that it is only ever stored
+// encrypted with key, in EncryptedPath(path). The database lives in memory
+// while open; committed changes are sealed and written to disk atomically
+// within flushInterval, and once more when the returned *sql.DB is closed.
+//
+// A plaintext database still at path is imported on first open and its files
+// are removed once the encrypted copy is on disk.
+func OpenDatabase(path string, key []byte) (*sql.DB, error) {
+	connector, err := OpenConnector(path, key)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// OpenConnector is OpenDatabase for callers that wrap the connector before
+// opening the *sql.DB. The connector must be closed, which sql.DB.Close does.
+func OpenConnector(path string, key []byte) (driver.Connector, error) {
+	database, err := newEncryptedDatabase(EncryptedPath(path), key)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.open(path); err != nil {
+		return nil, err
+	}
+
+	go database.flushLoop()
+	return database, nil
+}
+
+// OpenSnapshot opens a private in-memory copy of the encrypted database at
+// encryptedPath, for inspecting a database another process has open. Changes
+// made through it are discarded.
+func OpenSnapshot(encryptedPath string, key []byte) (*sql.DB, error) {
+	database, err := newEncryptedDatabase(encryptedPath, key)
+	if err != nil {
+		return nil, err
+	}
+	database.snapshotOnly = true
+
+	keeper, err := database.Driver().Open(database.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening in-memory database: %w", err)
+	}
+	database.keeper = keeper.(rawConn)
+
+	sealed, err := os.ReadFile(encryptedPath)
+	if err == nil {
+		var image []byte
+		image, err = Open(key, sealed)
+		if err == nil {
+			err = database.restore(image)
+		}
+	}
+	if err != nil {
+		database.keeper.Close()
+		return nil, fmt.Errorf("loading %s: %w", encryptedPath, err)
+	}
+
+	close(database.done)
+	return sql.OpenDB(database), nil
+}
+
+func newEncryptedDatabase(encryptedPath string, key []byte) (*encryptedDatabase, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	name := make([]byte, 8)
+	if _, err := rand.Read(name); err != nil {
+		return nil, fmt.Errorf("naming in-memory database: %w", err)
+	}
+
+	// The memdb VFS shares a database between all connections that open the
+	// same name starting with "/", and frees it when the last one closes.
+	// Transactions take the write lock at BEGIN, as they do for plaintext
+	// databases opened by sdk.OpenDatabase.
+	return &encryptedDatabase{
+		path: encryptedPath,
+		key:  key,
+		dsn:  fmt.Sprintf("file:/cortex-%s?vfs=memdb&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_txlock=immediate", hex.EncodeToString(name)),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}, nil
+}
+
+// encryptedDatabase is the driver.Connector behind OpenDatabase. It holds
+// one connection of its own for the lifetime of the database, which keeps the
+// in-memory database alive and is used to serialize it.
+type encryptedDatabase struct {
+	path string
+	key  []byte
+	dsn  string
+	// snapshotOnly databases, from OpenSnapshot, are never written back.
+	snapshotOnly bool
+
+	keeper rawConn
+	// mu serializes flushes and guards keeper and lastVersion.
+	mu sync.Mutex
+	// lastVersion is keeper's PRAGMA data_version at the last flush; other
+	// connections' commits change it.
+	lastVersion int64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var _ driver.Connector = (*encryptedDatabase)(nil)
+
+func (d *encryptedDatabase) Connect(context.Context) (driver.Conn, error) {
+	return d.Driver().Open(d.dsn)
+}
+
+func (d *encryptedDatabase) Driver() driver.Driver {
+	return &sqlite.Driver{}
+}
+
+// Close is called by sql.DB.Close. It stops the flush loop, writes pending
+// changes and frees the in-memory database.
+func (d *encryptedDatabase) Close() error {
+	var err error
+	d.closeOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+
+		err = d.flush(false)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if closeErr := d.keeper.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("closing database: %w", closeErr)
+		}
+	})
+	return err
+}
+
+// open creates the in-memory database and loads it from the encrypted file,
+// or from a plaintext database at plainPath when there is no encrypted one.
+func (d *encryptedDatabase) open(plainPath string) error {
+	keeper, err := d.Driver().Open(d.dsn)
+	if err != nil {
+		return fmt.Errorf("opening in-memory database: %w", err)
+	}
+	d.keeper = keeper.(rawConn)
+
+	if err := d.load(plainPath); err != nil {
+		d.keeper.Close()
+		return err
+	}
+	return nil
+}
+
+func (d *encryptedDatabase) load(plainPath string) error {
+	sealed, err := os.ReadFile(d.path)
+	switch {
+	case err == nil:
+		image, err := Open(d.key, sealed)
+		if err != nil {
+			return fmt.Errorf("decrypting %s: %w", d.path, err)
+		}
+		if err := d.restore(image); err != nil {
+			return err
+		}
+		d.lastVersion, err = d.dataVersion()
+		if err != nil {
+			return err
+		}
+		// Plaintext files left by an import that stopped before cleaning up
+		// are already contained in the encrypted copy.
+		return removeDatabaseFiles(plainPath)
+
+	case !os.IsNotExist(err):
+		return fmt.Errorf("reading encrypted database: %w", err)
+	}
+
+	if _, err := os.Stat(plainPath); err != nil {
+		if os.IsNotExist(err) {
+			// A new database: nothing to load until the first write.
+			d.lastVersion, err = d.dataVersion()
+			return err
+		}
+		return fmt.Errorf("checking plaintext database: %w", err)
+	}
+
+	if err := d.importPlaintext(plainPath); err != nil {
+		return err
+	}
+	if err := d.flush(true); err != nil {
+		return err
+	}
+	slog.Info("encrypted plaintext database", "path", plainPath)
+	return removeDatabaseFiles(plainPath)
+}
+
+// restore copies a serialized database image into the in-memory database.
+func (d *encryptedDatabase) restore(image []byte) error {
+	// The memdb VFS cannot open a WAL database; mark the image as using a
+	// rollback journal, which is how the host's snapshots are written.
+	if len(image) > 19 {
+		image[18], image[19] = 1, 1
+	}
+
+	// Read the image through a read-only VFS over memory, so the decrypted
+	// database never touches the disk.
+	name, filesystem, err := vfs.New(imageFS(image))
+	if err != nil {
+		return fmt.Errorf("registering image VFS: %w", err)
+	}
+	defer filesystem.Close()
+
+	source, err := d.Driver().Open(fmt.Sprintf("file:%s?vfs=%s&mode=ro", imageName, name))
+	if err != nil {
+		return fmt.Errorf("opening database image: %w", err)
+	}
+	defer source.Close()
+
+	return copyDatabase(source.(rawConn), d.dsn)
+}
+
+// importPlaintext copies the plaintext database at path, including pages
+// still in its WAL, into the in-memory database.
+func (d *encryptedDatabase) importPlaintext(path string) error {
+	source, err := d.Driver().Open(fmt.Sprintf("%s?_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		return fmt.Errorf("opening plaintext database: %w", err)
+	}
+	defer source.Close()
+
+	image, err := source.(rawConn).Serialize()
+	if err != nil {
+		return fmt.Errorf("reading plaintext database: %w", err)
+	}
+	if err := d.restore(image); err != nil {
+		return fmt.Errorf("importing plaintext database: %w", err)
+	}
+	return nil
+}
+
+func copyDatabase(source rawConn, destination string) error {
+	backup, err := source.NewBackup(destination)
+	if err != nil {
+		return fmt.Errorf("starting copy: %w", err)
+	}
+	if _, err := backup.Step(-1); err != nil {
+		backup.Finish()
+		return fmt.Errorf("copying database: %w", err)
+	}
+	if err := backup.Finish(); err != nil {
+		return fmt.Errorf("finishing copy: %w", err)
+	}
+	return nil
+}
+
+func (d *encryptedDatabase) flushLoop() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			if err := d.flush(false); err != nil {
+				slog.Error("writing encrypted database", "path", d.path, "error", err)
+			}
+		}
+	}
+}
+
+// flush seals the in-memory database and replaces the encrypted file with
+// it. Unless force is set, nothing is written when no connection committed
+// since the last flush.
+func (d *encryptedDatabase) flush(force bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.snapshotOnly {
+		return nil
+	}
+
+	version, err := d.dataVersion()
+	if err != nil {
+		return err
+	}
+	if !force && version == d.lastVersion {
+		return nil
+	}
+
+	image, err := d.snapshot()
+	if err != nil {
+		return err
+	}
+	sealed, err := Seal(d.key, image)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(d.path, sealed); err != nil {
+		return err
+	}
+
+	d.lastVersion = version
+	return nil
+}
+
+// snapshot serializes the in-memory database inside a read transaction, so no
+// commit can change it halfway through.
+func (d *encryptedDatabase) snapshot() ([]byte, error) {
+	ctx := context.Background()
+	keeper := d.keeper.(sqlite.ExecQuerierContext)
+
+	if _, err := keeper.ExecContext(ctx, "BEGIN", nil); err != nil {
+		return nil, fmt.Errorf("starting snapshot: %w", err)
+	}
+	defer keeper.ExecContext(ctx, "COMMIT", nil)
+
+	// Reading the schema takes the shared lock that blocks writers.
+	rows, err := keeper.QueryContext(ctx, "SELECT count(*) FROM sqlite_master", nil)
+	if err != nil {
+		return nil, fmt.Errorf("locking database: %w", err)
+	}
+	rows.Close()
+
+	image, err := d.keeper.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("serializing database: %w", err)
+	}
+	return image, nil
+}
+
+// dataVersion returns the keeper connection's PRAGMA data_version.
+func (d *encryptedDatabase) dataVersion() (int64, error) {
+	rows, err := d.keeper.(sqlite.ExecQuerierContext).QueryContext(context.Background(), "PRAGMA data_version", nil)
+	if err != nil {
+		return 0, fmt.Errorf("reading data version: %w", err)
+	}
+	defer rows.Close()
+
+	values := make([]driver.Value, 1)
+	if err := rows.Next(values); err != nil {
+		return 0, fmt.Errorf("reading data version: %w", err)
+	}
+	version, ok := values[0].(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected data version %v", values[0])
+	}
+	return version, nil
+}
+
+// writeFileAtomic replaces path with data so that readers, such as a backup
+// running at the same time, see either the old or the new file.
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	temporary := file.Name()
+	defer os.Remove(temporary)
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("syncing temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+	if err := os.Chmod(temporary, 0o600); err != nil {
+		return fmt.Errorf("setting file mode: %w", err)
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeDatabaseFiles deletes a plaintext SQLite database with its WAL and
+// shared-memory files.
+func removeDatabaseFiles(path string) error {
+	for _, file := range []string{path, path + "-wal", path + "-shm"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing plaintext database: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package atrest
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{42}, KeySize)
+
+func countNotes(t *testing.T, database *sql.DB) int {
+	t.Helper()
+
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count); err != nil {
+		t.Fatalf("failed to count notes: %v", err)
+	}
+	return count
+}
+
+func TestOpenDatabase_PersistsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.sqlite")
+
+	database, err := OpenDatabase(path, testKey)
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	for _, statement := range []string{
+		"CREATE TABLE notes (title TEXT NOT NULL)",
+		"INSERT INTO notes (title) VALUES ('bank pin')",
+	} {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+	if err := database.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no plaintext database on disk, got %v", err)
+	}
+	content, err := os.ReadFile(EncryptedPath(path))
+	if err != nil {
+		t.Fatalf("failed to read encrypted database: %v", err)
+	}
+	if !IsSealed(content) || bytes.Contains(content, []byte("bank pin")) {
+		t.Fatal("expected the database file to be encrypted")
+	}
+
+	reopened, err := OpenDatabase(path, testKey)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	defer reopened.Close()
+	if count := countNotes(t, reopened); count != 1 {
+		t.Errorf("expected 1 note after reopening, got %d", count)
+	}
+
+	if _, err := OpenDatabase(path, bytes.Repeat([]byte{1}, KeySize)); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey with another key, got %v", err)
+	}
+}
+
+func TestOpenDatabase_ImportsPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.sqlite")
+
+	plain, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open plaintext database: %v", err)
+	}
+	for _, statement := range []string{
+		"PRAGMA journal_mode=WAL",
+		"CREATE TABLE notes (title TEXT NOT NULL)",
+		"INSERT INTO notes (title) VALUES ('one'), ('two')",
+	} {
+		if _, err := plain.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+	plain.Close()
+
+	database, err := OpenDatabase(path, testKey)
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	defer database.Close()
+
+	if count := countNotes(t, database); count != 2 {
+		t.Errorf("expected the plaintext notes to be imported, got %d", count)
+	}
+	for _, leftover := range []string{path, path + "-wal", path + "-shm"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed after the import", filepath.Base(leftover))
+		}
+	}
+	if _, err := os.Stat(EncryptedPath(path)); err != nil {
+		t.Errorf("expected the encrypted database to be written, got %v", err)
+	}
+}
+
+func TestOpenDatabase_ForeignKeysAcrossConnections(t *testing.T) {
+	database, err := OpenDatabase(filepath.Join(t.TempDir(), "db.sqlite"), testKey)
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	defer database.Close()
+	database.SetMaxOpenConns(4)
+
+	for _, statement := range []string{
+		"CREATE TABLE notes (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE note_tags (note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE)",
+		"INSERT INTO notes (id) VALUES (1)",
+		"INSERT INTO note_tags (note_id) VALUES (1)",
+		"DELETE FROM notes WHERE id = 1",
+	} {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+
+	var remaining int
+	if err := database.QueryRow("SELECT COUNT(*) FROM note_tags").Scan(&remaining); err != nil {
+		t.Fatalf("failed to count tags: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("expected ON DELETE CASCADE to apply, %d tag rows left", remaining)
+	}
+}
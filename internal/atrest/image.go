@@ -0,0 +1,42 @@
+package atrest
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// imageName is the file an imageFS serves its database under.
+const imageName = "image.db"
+
+// imageFS is a file system holding a single database image, for reading an
+// image with SQLite without writing it to disk.
+type imageFS []byte
+
+func (image imageFS) Open(name string) (fs.File, error) {
+	if strings.TrimPrefix(name, "/") != imageName {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &imageFile{Reader: bytes.NewReader(image), size: int64(len(image))}, nil
+}
+
+// imageFile is an open imageFS database. SQLite needs it to seek.
+type imageFile struct {
+	*bytes.Reader
+	size int64
+}
+
+func (f *imageFile) Stat() (fs.FileInfo, error) { return imageInfo{size: f.size}, nil }
+func (f *imageFile) Close() error               { return nil }
+
+type imageInfo struct {
+	size int64
+}
+
+func (i imageInfo) Name() string       { return imageName }
+func (i imageInfo) Size() int64        { return i.size }
+func (i imageInfo) Mode() fs.FileMode  { return 0o400 }
+func (i imageInfo) ModTime() time.Time { return time.Time{} }
+func (i imageInfo) IsDir() bool        { return false }
+func (i imageInfo) Sys() any           { return nil }
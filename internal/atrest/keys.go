@@ -0,0 +1,146 @@
+package atrest
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// KeyEnv carries a plugin's database key, base64-encoded, into its process.
+	KeyEnv = "CORTEX_DB_KEY"
+	// MinPassphraseLength is the shortest passphrase accepted for encryption.
+	MinPassphraseLength = 8
+
+	// keyFile, in the data directory, holds the parameters of the master key.
+	keyFile  = "encryption.json"
+	saltSize = 16
+	// keyIterations follows the OWASP 2023 recommendation for PBKDF2-HMAC-SHA256.
+	keyIterations = 600_000
+	checkMessage  = "cortex at-rest key check"
+)
+
+// ErrWrongPassphrase is returned by DeriveKey when the passphrase differs from
+// the one the databases were first encrypted with.
+var ErrWrongPassphrase = errors.New("passphrase does not match the one the databases are encrypted with")
+
+// keyParameters is stored in keyFile. Check is an HMAC of a fixed message
+// under the master key, so a wrong passphrase is detected before any database
+// is opened with it.
+type keyParameters struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Check   []byte `json:"check"`
+}
+
+// DeriveKey returns the master key for passphrase. The first call for a data
+// directory generates a salt and stores it next to a check value; later calls
+// with a different passphrase return ErrWrongPassphrase.
+func DeriveKey(dataDir string, passphrase string) ([]byte, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLength)
+	}
+
+	path := filepath.Join(dataDir, keyFile)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading key parameters: %w", err)
+	}
+
+	if err == nil {
+		var parameters keyParameters
+		if err := json.Unmarshal(data, &parameters); err != nil || len(parameters.Salt) != saltSize {
+			return nil, fmt.Errorf("parsing key parameters in %s", path)
+		}
+
+		key, err := pbkdf2.Key(sha256.New, passphrase, parameters.Salt, keyIterations, KeySize)
+		if err != nil {
+			return nil, fmt.Errorf("deriving key: %w", err)
+		}
+		if !hmac.Equal(keyCheck(key), parameters.Check) {
+			return nil, ErrWrongPassphrase
+		}
+		return key, nil
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, keyIterations, KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+
+	data, err = json.MarshalIndent(keyParameters{Version: 1, Salt: salt, Check: keyCheck(key)}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding key parameters: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("writing key parameters: %w", err)
+	}
+	return key, nil
+}
+
+// Enabled reports whether a master key was ever derived for dataDir, which
+// means plugin databases there may be encrypted.
+func Enabled(dataDir string) bool {
+	_, err := os.Stat(filepath.Join(dataDir, keyFile))
+	return err == nil
+}
+
+// PluginKey derives the database key of one plugin from the master key, so a
+// plugin cannot decrypt another plugin's database.
+func PluginKey(master []byte, pluginID string) []byte {
+	key, err := hkdf.Key(sha256.New, master, nil, "cortex plugin database "+pluginID, KeySize)
+	if err != nil {
+		// Only reachable with an invalid master key, which DeriveKey never returns.
+		panic(fmt.Sprintf("deriving plugin key: %v", err))
+	}
+	return key
+}
+
+// SecretsKey derives the key of the host's secret store from the master key,
+// kept apart from every plugin's database key.
+func SecretsKey(master []byte) []byte {
+	key, err := hkdf.Key(sha256.New, master, nil, "cortex secrets", KeySize)
+	if err != nil {
+		// Only reachable with an invalid master key, which DeriveKey never returns.
+		panic(fmt.Sprintf("deriving secrets key: %v", err))
+	}
+	return key
+}
+
+// EncodeKey formats key for KeyEnv.
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// KeyFromEnvironment returns the database key passed by the host in KeyEnv,
+// or nil when the host has encryption disabled.
+func KeyFromEnvironment() ([]byte, error) {
+	value := os.Getenv(KeyEnv)
+	if value == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("%s must be a base64-encoded %d-byte key", KeyEnv, KeySize)
+	}
+	return key, nil
+}
+
+func keyCheck(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(checkMessage))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,94 @@
+// Package atrest encrypts plugin databases at rest. The host derives a master
+// key from a passphrase and hands every plugin its own subkey; plugins open
+// their database with OpenDatabase, which keeps the file on disk encrypted.
+package atrest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Sealed files are a header followed by the AES-256-GCM encryption of the
+// content, authenticated together with the header:
+//
+//	magic "CORTEXDB" | version (1 byte) | nonce (12 bytes) | ciphertext and tag
+const (
+	sealMagic   = "CORTEXDB"
+	sealVersion = 1
+	nonceSize   = 12
+	// KeySize is the length of master and plugin keys.
+	KeySize = 32
+)
+
+const sealHeaderSize = len(sealMagic) + 1 + nonceSize
+
+// ErrWrongKey is returned when sealed data cannot be opened, either because the
+// key is wrong or the data was tampered with.
+var ErrWrongKey = errors.New("decryption failed: wrong key or corrupted data")
+
+// Seal encrypts plaintext with key.
+func Seal(key []byte, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, sealHeaderSize, sealHeaderSize+len(plaintext)+aead.Overhead())
+	copy(header, sealMagic)
+	header[len(sealMagic)] = sealVersion
+	nonce := header[len(sealMagic)+1:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// Open decrypts data produced by Seal. It returns ErrWrongKey if the data does
+// not authenticate with key.
+func Open(key []byte, sealed []byte) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, errors.New("not an encrypted cortex database")
+	}
+	if sealed[len(sealMagic)] != sealVersion {
+		return nil, fmt.Errorf("unsupported encryption version %d", sealed[len(sealMagic)])
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := sealed[:sealHeaderSize]
+	plaintext, err := aead.Open(nil, header[len(sealMagic)+1:], sealed[sealHeaderSize:], header)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plaintext, nil
+}
+
+// IsSealed reports whether data starts with the header Seal writes.
+func IsSealed(data []byte) bool {
+	return len(data) >= sealHeaderSize && bytes.HasPrefix(data, []byte(sealMagic))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCM: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,87 @@
+package atrest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+	plaintext := []byte("SQLite format 3\x00 finance data")
+
+	sealed, err := Seal(key, plaintext)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if !IsSealed(sealed) {
+		t.Fatal("expected sealed data to carry the header")
+	}
+	if bytes.Contains(sealed, []byte("finance data")) {
+		t.Fatal("expected the plaintext not to appear in the sealed data")
+	}
+
+	opened, err := Open(key, sealed)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, opened)
+	}
+}
+
+func TestOpen_WrongKeyOrTampered(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+	sealed, err := Seal(key, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	if _, err := Open(bytes.Repeat([]byte{8}, KeySize), sealed); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey with another key, got %v", err)
+	}
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Open(key, tampered); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey for tampered data, got %v", err)
+	}
+
+	if _, err := Open(key, []byte("SQLite format 3\x00")); err == nil {
+		t.Error("expected an error for data that was never sealed")
+	}
+}
+
+func TestDeriveKey_ChecksPassphrase(t *testing.T) {
+	dataDir := t.TempDir()
+	if Enabled(dataDir) {
+		t.Fatal("expected encryption to be off before a key is derived")
+	}
+
+	key, err := DeriveKey(dataDir, "correct horse")
+	if err != nil {
+		t.Fatalf("DeriveKey failed: %v", err)
+	}
+	if !Enabled(dataDir) {
+		t.Fatal("expected encryption to be on once a key is derived")
+	}
+
+	again, err := DeriveKey(dataDir, "correct horse")
+	if err != nil {
+		t.Fatalf("DeriveKey with the same passphrase failed: %v", err)
+	}
+	if !bytes.Equal(key, again) {
+		t.Error("expected the same passphrase to derive the same key")
+	}
+
+	if _, err := DeriveKey(dataDir, "battery staple"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+	if _, err := DeriveKey(t.TempDir(), "short"); err == nil {
+		t.Error("expected a short passphrase to be rejected")
+	}
+
+	if bytes.Equal(PluginKey(key, "finance-tracker"), PluginKey(key, "quick-notes")) {
+		t.Error("expected plugins to get different keys")
+	}
+}
@@ -0,0 +1,170 @@
+// Package attachments stores the files plugins attach to their records.
+// Contents are addressed by their SHA-256 hash, so the same file attached
+// several times, by one plugin or many, is kept on disk once.
+package attachments
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// Store keeps blobs under dir and their references in the host database. It
+// implements plugin.AttachmentStore.
+type Store struct {
+	dir     string
+	records *db.HostDB
+
+	// mu serializes writes so a blob is never removed while another Put is
+	// adding a reference to it.
+	mu sync.Mutex
+}
+
+// NewStore creates a store that keeps blobs under dir.
+func NewStore(dir string, records *db.HostDB) *Store {
+	return &Store{dir: dir, records: records}
+}
+
+// Put stores content for pluginID. When a blob with the same hash already
+// exists only a new reference is recorded and the result is Deduplicated.
+func (s *Store) Put(pluginID string, name string, contentType string, content []byte) (*plugin.Attachment, error) {
+	if len(content) > plugin.MaxAttachmentSize {
+		return nil, plugin.ErrAttachmentTooLarge
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The blob is written first: a record never points at a missing file, and
+	// a file left behind by a failed insert is overwritten by the next Put.
+	if !s.blobExists(hash, int64(len(content))) {
+		if err := s.writeBlob(hash, content); err != nil {
+			return nil, err
+		}
+	}
+
+	record, storedBefore, err := s.records.CreateAttachment(pluginID, hash, int64(len(content)), name, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("recording attachment: %w", err)
+	}
+
+	attachment := fromRecord(record)
+	attachment.Deduplicated = storedBefore
+	return attachment, nil
+}
+
+// Get returns one of pluginID's attachments with its content.
+func (s *Store) Get(pluginID string, id int64) (*plugin.Attachment, []byte, error) {
+	record, err := s.records.GetAttachment(pluginID, id)
+	if err != nil {
+		return nil, nil, translateNotFound(err)
+	}
+
+	content, err := os.ReadFile(s.blobPath(record.SHA256))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading attachment blob: %w", err)
+	}
+	return fromRecord(record), content, nil
+}
+
+// Delete removes one of pluginID's attachments, and its blob once no
+// attachment references it.
+func (s *Store) Delete(pluginID string, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orphaned, err := s.records.DeleteAttachment(pluginID, id)
+	if err != nil {
+		return translateNotFound(err)
+	}
+	if orphaned == "" {
+		return nil
+	}
+
+	if err := os.Remove(s.blobPath(orphaned)); err != nil && !os.IsNotExist(err) {
+		// The reference is gone; the file only wastes space until the same
+		// content is stored again.
+		slog.Warn("failed to remove attachment blob", "sha256", orphaned, "error", err)
+	}
+	return nil
+}
+
+// Stats summarizes how much the store holds and how much deduplication saves.
+func (s *Store) Stats() (*db.AttachmentStats, error) {
+	return s.records.GetAttachmentStats()
+}
+
+// blobPath spreads blobs over subdirectories named after the first two hex
+// digits of their hash.
+func (s *Store) blobPath(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+func (s *Store) blobExists(hash string, size int64) bool {
+	info, err := os.Stat(s.blobPath(hash))
+	return err == nil && info.Size() == size
+}
+
+// writeBlob writes content atomically, so a crash never leaves a truncated
+// blob under its hash.
+func (s *Store) writeBlob(hash string, content []byte) error {
+	path := s.blobPath(hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating attachment directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), hash+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating attachment blob: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("writing attachment blob: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("syncing attachment blob: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing attachment blob: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("saving attachment blob: %w", err)
+	}
+	return nil
+}
+
+func translateNotFound(err error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return plugin.ErrAttachmentNotFound
+	}
+	return err
+}
+
+func fromRecord(record *db.AttachmentRecord) *plugin.Attachment {
+	return &plugin.Attachment{
+		ID:          record.ID,
+		Name:        record.Name,
+		ContentType: record.ContentType,
+		SHA256:      record.SHA256,
+		Size:        record.Size,
+		CreatedAt:   record.CreatedAt,
+	}
+}
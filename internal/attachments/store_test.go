@@ -0,0 +1,134 @@
+package attachments
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	return NewStore(filepath.Join(dataDir, "attachments"), hostDB)
+}
+
+func countBlobs(t *testing.T, store *Store) int {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(store.dir, "*", "*"))
+	if err != nil {
+		t.Fatalf("failed to list blobs: %v", err)
+	}
+	return len(matches)
+}
+
+func TestStore_DeduplicatesAcrossPlugins(t *testing.T) {
+	store := newTestStore(t)
+	receipt := []byte("%PDF-1.4 grocery receipt")
+
+	first, err := store.Put("finance-tracker", "receipt.pdf", "application/pdf", receipt)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if first.Deduplicated {
+		t.Error("expected the first copy not to be deduplicated")
+	}
+
+	second, err := store.Put("quick-notes", "scan.pdf", "", receipt)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if !second.Deduplicated || second.SHA256 != first.SHA256 {
+		t.Errorf("expected the second copy to share the first blob, got %+v", second)
+	}
+	if second.ContentType != "application/pdf" {
+		t.Errorf("expected the content type to be detected, got %q", second.ContentType)
+	}
+	if count := countBlobs(t, store); count != 1 {
+		t.Errorf("expected 1 blob on disk, got %d", count)
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	size := int64(len(receipt))
+	if stats.Blobs != 1 || stats.Attachments != 2 || stats.StoredBytes != size || stats.SavedBytes != size {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if len(stats.Plugins) != 2 || stats.SharedBlobs != 1 {
+		t.Errorf("expected both plugins to share one blob, got %+v", stats)
+	}
+
+	_, content, err := store.Get("quick-notes", second.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !bytes.Equal(content, receipt) {
+		t.Errorf("expected the stored content back, got %q", content)
+	}
+}
+
+func TestStore_DeleteKeepsSharedBlobs(t *testing.T) {
+	store := newTestStore(t)
+	photo := []byte("\x89PNG\r\n\x1a\n whiteboard")
+
+	first, err := store.Put("project-hub", "board.png", "", photo)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	second, err := store.Put("quick-notes", "board.png", "", photo)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if err := store.Delete("quick-notes", first.ID); !errors.Is(err, plugin.ErrAttachmentNotFound) {
+		t.Errorf("expected plugins not to delete each other's attachments, got %v", err)
+	}
+	if _, _, err := store.Get("quick-notes", first.ID); !errors.Is(err, plugin.ErrAttachmentNotFound) {
+		t.Errorf("expected plugins not to read each other's attachments, got %v", err)
+	}
+
+	if err := store.Delete("project-hub", first.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, content, err := store.Get("quick-notes", second.ID); err != nil || !bytes.Equal(content, photo) {
+		t.Fatalf("expected the remaining reference to still read, got %v", err)
+	}
+
+	if err := store.Delete("quick-notes", second.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(store.blobPath(second.SHA256)); !os.IsNotExist(err) {
+		t.Errorf("expected the blob to be removed with its last reference, got %v", err)
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Blobs != 0 || stats.Attachments != 0 {
+		t.Errorf("expected an empty store, got %+v", stats)
+	}
+}
+
+func TestStore_RejectsLargeContent(t *testing.T) {
+	store := newTestStore(t)
+
+	_, err := store.Put("quick-notes", "huge.bin", "", make([]byte, plugin.MaxAttachmentSize+1))
+	if !errors.Is(err, plugin.ErrAttachmentTooLarge) {
+		t.Errorf("expected ErrAttachmentTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// Package backup produces point-in-time archives of the host and plugin databases
+// and restores them.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+)
+
+// metadataFile is the archive entry describing what the archive contains.
+const metadataFile = "export.json"
+
+// Metadata is written as the first entry of every archive.
+type Metadata struct {
+	CreatedAt string   `json:"created_at"`
+	Files     []string `json:"files"`
+}
+
+// WriteArchive writes a gzip-compressed tar archive containing a consistent
+// snapshot of the host database and every plugin database under dataDir.
+// Snapshots are taken with the SQLite online backup API, so WAL databases
+// are captured consistently while they stay writable. Encrypted plugin
+// databases are archived as they are; they are always replaced atomically, so
+// the file itself is a consistent snapshot.
+func WriteArchive(writer io.Writer, dataDir string) error {
+	databases, err := listDatabases(dataDir)
+	if err != nil {
+		return err
+	}
+
+	snapshotDir, err := os.MkdirTemp("", "cortex-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	defer os.RemoveAll(snapshotDir)
+
+	gzipWriter := gzip.NewWriter(writer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	metadata := Metadata{
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Files:     databases,
+	}
+	metadataBytes, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding archive metadata: %w", err)
+	}
+	if err := writeTarEntry(tarWriter, metadataFile, metadataBytes); err != nil {
+		return err
+	}
+
+	for index, relativePath := range databases {
+		snapshotPath := filepath.Join(snapshotDir, fmt.Sprintf("%d.sqlite", index))
+		if strings.HasSuffix(relativePath, atrest.EncryptedExtension) {
+			snapshotPath = filepath.Join(dataDir, relativePath)
+		} else if err := snapshotDatabase(filepath.Join(dataDir, relativePath), snapshotPath); err != nil {
+			return fmt.Errorf("snapshotting %s: %w", relativePath, err)
+		}
+
+		content, err := os.ReadFile(snapshotPath)
+		if err != nil {
+			return fmt.Errorf("reading snapshot of %s: %w", relativePath, err)
+		}
+
+		if err := writeTarEntry(tarWriter, filepath.ToSlash(relativePath), content); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("closing tar archive: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("closing gzip stream: %w", err)
+	}
+
+	return nil
+}
+
+// listDatabases returns the host database and plugin databases, plaintext or
+// encrypted, relative to dataDir.
+func listDatabases(dataDir string) ([]string, error) {
+	var databases []string
+
+	if _, err := os.Stat(filepath.Join(dataDir, "cortex.db")); err == nil {
+		databases = append(databases, "cortex.db")
+	}
+
+	pluginDatabases, err := filepath.Glob(filepath.Join(dataDir, "plugins", "*", "db.sqlite"))
+	if err != nil {
+		return nil, fmt.Errorf("listing plugin databases: %w", err)
+	}
+	encryptedDatabases, err := filepath.Glob(filepath.Join(dataDir, "plugins", "*", "db.sqlite"+atrest.EncryptedExtension))
+	if err != nil {
+		return nil, fmt.Errorf("listing encrypted plugin databases: %w", err)
+	}
+	pluginDatabases = append(pluginDatabases, encryptedDatabases...)
+	slices.Sort(pluginDatabases)
+
+	for _, databasePath := range pluginDatabases {
+		relativePath, err := filepath.Rel(dataDir, databasePath)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", databasePath, err)
+		}
+		databases = append(databases, relativePath)
+	}
+
+	return databases, nil
+}
+
+func writeTarEntry(tarWriter *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(content)),
+		ModTime: time.Now().UTC(),
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("writing tar header for %s: %w", name, err)
+	}
+	if _, err := tarWriter.Write(content); err != nil {
+		return fmt.Errorf("writing tar entry %s: %w", name, err)
+	}
+
+	return nil
+}
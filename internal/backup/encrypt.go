@@ -0,0 +1,229 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Encrypted archives use AES-256-GCM over fixed-size chunks (the STREAM
+// construction), so arbitrarily large archives can be encrypted without
+// buffering them in memory and truncation is detected on decryption.
+//
+// Layout:
+//
+//	magic "CORTEXENC" | version (1 byte) | salt (16 bytes) | nonce prefix (7 bytes) | chunks...
+//
+// Each chunk nonce is the prefix followed by a 4-byte big-endian counter and
+// a 1-byte flag set to 1 on the final chunk. The header is authenticated as
+// additional data on every chunk.
+const (
+	encryptionMagic   = "CORTEXENC"
+	encryptionVersion = 1
+	saltSize          = 16
+	noncePrefixSize   = 7
+	chunkSize         = 64 * 1024
+	// keyIterations follows the OWASP 2023 recommendation for PBKDF2-HMAC-SHA256.
+	keyIterations = 600_000
+	// MinPassphraseLength is the shortest passphrase accepted for encryption.
+	MinPassphraseLength = 8
+)
+
+// ErrDecryption is returned when an archive cannot be decrypted, either
+// because the passphrase is wrong or the data was tampered with or truncated.
+var ErrDecryption = errors.New("decryption failed: wrong passphrase or corrupted archive")
+
+const headerSize = len(encryptionMagic) + 1 + saltSize + noncePrefixSize
+
+type encryptWriter struct {
+	destination io.Writer
+	aead        cipher.AEAD
+	header      []byte
+	noncePrefix []byte
+	counter     uint32
+	buffer      []byte
+	closed      bool
+}
+
+// NewEncryptWriter returns a writer that encrypts everything written to it with
+// a key derived from passphrase. Close must be called to flush the final chunk.
+func NewEncryptWriter(destination io.Writer, passphrase string) (io.WriteCloser, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLength)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, encryptionMagic)
+	header[len(encryptionMagic)] = encryptionVersion
+	if _, err := rand.Read(header[len(encryptionMagic)+1:]); err != nil {
+		return nil, fmt.Errorf("generating salt and nonce: %w", err)
+	}
+
+	salt := header[len(encryptionMagic)+1 : len(encryptionMagic)+1+saltSize]
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := destination.Write(header); err != nil {
+		return nil, fmt.Errorf("writing encryption header: %w", err)
+	}
+
+	return &encryptWriter{
+		destination: destination,
+		aead:        aead,
+		header:      header,
+		noncePrefix: header[len(encryptionMagic)+1+saltSize:],
+		buffer:      make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (w *encryptWriter) Write(data []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed encrypt writer")
+	}
+
+	written := 0
+	for len(data) > 0 {
+		// Keep a full chunk buffered until more data arrives, so the last
+		// chunk can always be sealed as final on Close.
+		if len(w.buffer) == chunkSize {
+			if err := w.sealChunk(false); err != nil {
+				return written, err
+			}
+		}
+
+		count := copy(w.buffer[len(w.buffer):chunkSize], data)
+		w.buffer = w.buffer[:len(w.buffer)+count]
+		data = data[count:]
+		written += count
+	}
+
+	return written, nil
+}
+
+// Close seals the final chunk. It does not close the underlying writer.
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	// A full final chunk would be indistinguishable from an intermediate one
+	// on read, so flush it and terminate the stream with an empty final chunk.
+	if len(w.buffer) == chunkSize {
+		if err := w.sealChunk(false); err != nil {
+			return err
+		}
+	}
+	return w.sealChunk(true)
+}
+
+func (w *encryptWriter) sealChunk(final bool) error {
+	nonce := chunkNonce(w.noncePrefix, w.counter, final)
+	sealed := w.aead.Seal(nil, nonce, w.buffer, w.header)
+
+	if _, err := w.destination.Write(sealed); err != nil {
+		return fmt.Errorf("writing encrypted chunk: %w", err)
+	}
+
+	w.counter++
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// Decrypt reads an encrypted archive from source and writes the plaintext to
+// destination. It returns ErrDecryption if authentication fails at any point.
+func Decrypt(destination io.Writer, source io.Reader, passphrase string) error {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(source, header); err != nil {
+		return ErrDecryption
+	}
+	if !bytes.Equal(header[:len(encryptionMagic)], []byte(encryptionMagic)) {
+		return errors.New("not an encrypted cortex archive")
+	}
+	if header[len(encryptionMagic)] != encryptionVersion {
+		return fmt.Errorf("unsupported encryption version %d", header[len(encryptionMagic)])
+	}
+
+	salt := header[len(encryptionMagic)+1 : len(encryptionMagic)+1+saltSize]
+	noncePrefix := header[len(encryptionMagic)+1+saltSize:]
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	chunk := make([]byte, chunkSize+aead.Overhead())
+	var counter uint32
+
+	for {
+		count, readErr := io.ReadFull(source, chunk)
+		if readErr == io.EOF {
+			// The writer always emits a final chunk, so a clean EOF here means truncation.
+			return ErrDecryption
+		}
+		if readErr != nil && readErr != io.ErrUnexpectedEOF {
+			return fmt.Errorf("reading encrypted chunk: %w", readErr)
+		}
+
+		final := readErr == io.ErrUnexpectedEOF
+		plaintext, err := aead.Open(nil, chunkNonce(noncePrefix, counter, final), chunk[:count], header)
+		if err != nil {
+			return ErrDecryption
+		}
+
+		if _, err := destination.Write(plaintext); err != nil {
+			return fmt.Errorf("writing decrypted data: %w", err)
+		}
+
+		if final {
+			return nil
+		}
+		counter++
+	}
+}
+
+// IsEncrypted reports whether the stream buffered in reader starts like an
+// archive written by NewEncryptWriter. Nothing is consumed from reader.
+func IsEncrypted(reader *bufio.Reader) bool {
+	prefix, _ := reader.Peek(len(encryptionMagic))
+	return bytes.Equal(prefix, []byte(encryptionMagic))
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, keyIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCM: %w", err)
+	}
+
+	return aead, nil
+}
+
+func chunkNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if final {
+		nonce[noncePrefixSize+4] = 1
+	}
+	return nonce
+}
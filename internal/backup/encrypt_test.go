@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func encryptBytes(t *testing.T, plaintext []byte, passphrase string) []byte {
+	t.Helper()
+
+	var encrypted bytes.Buffer
+	writer, err := NewEncryptWriter(&encrypted, passphrase)
+	if err != nil {
+		t.Fatalf("NewEncryptWriter failed: %v", err)
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return encrypted.Bytes()
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	sizes := []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 17}
+
+	for _, size := range sizes {
+		plaintext := make([]byte, size)
+		_, _ = rand.Read(plaintext)
+
+		encrypted := encryptBytes(t, plaintext, "correct horse battery")
+
+		var decrypted bytes.Buffer
+		if err := Decrypt(&decrypted, bytes.NewReader(encrypted), "correct horse battery"); err != nil {
+			t.Fatalf("size %d: Decrypt failed: %v", size, err)
+		}
+		if !bytes.Equal(decrypted.Bytes(), plaintext) {
+			t.Fatalf("size %d: decrypted data does not match plaintext", size)
+		}
+	}
+}
+
+func TestDecrypt_WrongPassphrase(t *testing.T) {
+	encrypted := encryptBytes(t, []byte("secret ledger"), "correct horse battery")
+
+	var decrypted bytes.Buffer
+	err := Decrypt(&decrypted, bytes.NewReader(encrypted), "wrong passphrase")
+	if !errors.Is(err, ErrDecryption) {
+		t.Fatalf("expected ErrDecryption, got %v", err)
+	}
+}
+
+func TestDecrypt_Truncated(t *testing.T) {
+	plaintext := make([]byte, 2*chunkSize+10)
+	encrypted := encryptBytes(t, plaintext, "correct horse battery")
+
+	// Drop the final chunk entirely: the stream must not be accepted as complete.
+	truncated := encrypted[:headerSize+2*(chunkSize+16)]
+
+	var decrypted bytes.Buffer
+	err := Decrypt(&decrypted, bytes.NewReader(truncated), "correct horse battery")
+	if !errors.Is(err, ErrDecryption) {
+		t.Fatalf("expected ErrDecryption for truncated archive, got %v", err)
+	}
+}
+
+func TestNewEncryptWriter_ShortPassphrase(t *testing.T) {
+	if _, err := NewEncryptWriter(&bytes.Buffer{}, "short"); err == nil {
+		t.Fatal("expected error for short passphrase")
+	}
+}
@@ -0,0 +1,289 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupPrefix    = "cortex-backup-"
+	backupExtension = ".tar.gz"
+	// scheduleCheckInterval is how often Start checks whether a backup is due.
+	scheduleCheckInterval = time.Minute
+)
+
+// ErrNotFound is returned when a named backup does not exist.
+var ErrNotFound = errors.New("backup not found")
+
+// Info describes a stored backup.
+type Info struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"created_at"`
+}
+
+// RestoreResult describes a completed restore.
+type RestoreResult struct {
+	// Restored lists the databases that were overwritten, relative to the data directory.
+	Restored []string `json:"restored"`
+	// ArchiveCreatedAt is when the restored archive was taken.
+	ArchiveCreatedAt string `json:"archive_created_at"`
+	// SafetyBackup is the backup of the databases taken just before the restore.
+	SafetyBackup string `json:"safety_backup"`
+}
+
+// Manager stores backups of the data directory in backupDir, keeping only the
+// most recent ones. Backups and restores are serialized.
+type Manager struct {
+	dataDir   string
+	backupDir string
+	retention int
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewManager creates a manager that archives the databases under dataDir into
+// backupDir and keeps the retention most recent backups.
+func NewManager(dataDir string, backupDir string, retention int) *Manager {
+	return &Manager{
+		dataDir:   dataDir,
+		backupDir: backupDir,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Create writes a new backup and prunes the ones beyond the retention count.
+func (m *Manager) Create() (*Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.create()
+}
+
+func (m *Manager) create() (*Info, error) {
+	if err := os.MkdirAll(m.backupDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	// Write to a hidden temp file first so a failed backup never shows up in List.
+	file, err := os.CreateTemp(m.backupDir, ".cortex-backup-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("creating backup file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := WriteArchive(file, m.dataDir); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("closing backup file: %w", err)
+	}
+
+	name, err := m.nextName()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(m.backupDir, name)); err != nil {
+		return nil, fmt.Errorf("storing backup: %w", err)
+	}
+
+	if err := m.prune(); err != nil {
+		slog.Warn("failed to prune old backups", "error", err)
+	}
+
+	return m.stat(name)
+}
+
+// nextName returns a backup name for the current time that is not taken yet.
+func (m *Manager) nextName() (string, error) {
+	base := backupPrefix + m.now().UTC().Format("20060102-150405")
+	for attempt := 1; attempt <= 100; attempt++ {
+		name := base + backupExtension
+		if attempt > 1 {
+			name = fmt.Sprintf("%s-%d%s", base, attempt, backupExtension)
+		}
+
+		if _, err := os.Stat(filepath.Join(m.backupDir, name)); os.IsNotExist(err) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no free backup name for %s", base)
+}
+
+// List returns the stored backups, newest first.
+func (m *Manager) List() ([]Info, error) {
+	entries, err := os.ReadDir(m.backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Info{}, nil
+		}
+		return nil, fmt.Errorf("reading backup directory: %w", err)
+	}
+
+	backups := []Info{}
+	modTimes := map[string]time.Time{}
+	for _, entry := range entries {
+		if entry.IsDir() || !validBackupName(entry.Name()) {
+			continue
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, infoOf(fileInfo))
+		modTimes[entry.Name()] = fileInfo.ModTime()
+	}
+
+	slices.SortFunc(backups, func(a, b Info) int {
+		if byTime := modTimes[b.Name].Compare(modTimes[a.Name]); byTime != 0 {
+			return byTime
+		}
+		return strings.Compare(b.Name, a.Name)
+	})
+	return backups, nil
+}
+
+// Open opens a stored backup for reading, or returns ErrNotFound.
+func (m *Manager) Open(name string) (*os.File, error) {
+	if !validBackupName(name) {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(filepath.Join(m.backupDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("opening backup: %w", err)
+	}
+	return file, nil
+}
+
+// Restore verifies the archive read from source, takes a safety backup of the
+// current databases and overwrites them with the archive's. pause runs after
+// the archive has been verified and before anything is overwritten; the
+// function it returns runs once the restore is over, so callers can stop and
+// restart processes holding the databases open.
+func (m *Manager) Restore(source io.Reader, pause func() (resume func())) (*RestoreResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	staged, err := StageRestore(source)
+	if err != nil {
+		return nil, err
+	}
+	defer staged.Close()
+
+	safety, err := m.create()
+	if err != nil {
+		return nil, fmt.Errorf("creating safety backup: %w", err)
+	}
+
+	resume := pause()
+	defer resume()
+
+	if err := staged.Apply(m.dataDir); err != nil {
+		return nil, err
+	}
+
+	return &RestoreResult{
+		Restored:         staged.Files,
+		ArchiveCreatedAt: staged.CreatedAt,
+		SafetyBackup:     safety.Name,
+	}, nil
+}
+
+// RunScheduled creates a backup if the newest one is older than interval.
+// It returns the new backup, or nil when none was due.
+func (m *Manager) RunScheduled(interval time.Duration) (*Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	backups, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(backups) > 0 {
+		latest, err := time.Parse(time.RFC3339, backups[0].CreatedAt)
+		if err == nil && m.now().Sub(latest) < interval {
+			return nil, nil
+		}
+	}
+
+	return m.create()
+}
+
+// Start takes a backup whenever the newest one is older than interval, until
+// ctx is cancelled. Checking the newest backup rather than running a plain
+// ticker keeps the schedule across restarts.
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := m.RunScheduled(interval)
+			if err != nil {
+				slog.Error("scheduled backup failed", "error", err)
+			} else if info != nil {
+				slog.Info("scheduled backup created", "name", info.Name, "size", info.Size)
+			}
+		}
+	}
+}
+
+// prune removes the backups beyond the retention count, oldest first.
+func (m *Manager) prune() error {
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, stale := range backups[min(m.retention, len(backups)):] {
+		if err := os.Remove(filepath.Join(m.backupDir, stale.Name)); err != nil {
+			errs = append(errs, fmt.Errorf("removing %s: %w", stale.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stat(name string) (*Info, error) {
+	fileInfo, err := os.Stat(filepath.Join(m.backupDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	info := infoOf(fileInfo)
+	return &info, nil
+}
+
+func infoOf(fileInfo os.FileInfo) Info {
+	return Info{
+		Name:      fileInfo.Name(),
+		Size:      fileInfo.Size(),
+		CreatedAt: fileInfo.ModTime().UTC().Format(time.RFC3339),
+	}
+}
+
+// validBackupName reports whether name is a backup file this manager created.
+func validBackupName(name string) bool {
+	return strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupExtension) &&
+		filepath.Base(name) == name
+}
@@ -0,0 +1,245 @@
+package backup
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+)
+
+// openWALDatabase creates a WAL-mode database at path with a single notes table.
+func openWALDatabase(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	database, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	for _, statement := range []string{
+		"PRAGMA journal_mode=WAL",
+		"CREATE TABLE notes (title TEXT NOT NULL)",
+	} {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+	return database
+}
+
+func insertNote(t *testing.T, database *sql.DB, title string) {
+	t.Helper()
+	if _, err := database.Exec("INSERT INTO notes (title) VALUES (?)", title); err != nil {
+		t.Fatalf("failed to insert note: %v", err)
+	}
+}
+
+func noteTitles(t *testing.T, database *sql.DB) []string {
+	t.Helper()
+
+	rows, err := database.Query("SELECT title FROM notes ORDER BY rowid")
+	if err != nil {
+		t.Fatalf("failed to query notes: %v", err)
+	}
+	defer rows.Close()
+
+	titles := []string{}
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			t.Fatalf("failed to scan note: %v", err)
+		}
+		titles = append(titles, title)
+	}
+	return titles
+}
+
+func TestManager_RestoreOverwritesLiveDatabases(t *testing.T) {
+	dataDir := t.TempDir()
+	host := openWALDatabase(t, filepath.Join(dataDir, "cortex.db"))
+	notes := openWALDatabase(t, filepath.Join(dataDir, "plugins", "quick-notes", "db.sqlite"))
+	insertNote(t, host, "host before")
+	insertNote(t, notes, "note before")
+
+	manager := NewManager(dataDir, filepath.Join(dataDir, "backups"), 5)
+	info, err := manager.Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	insertNote(t, host, "host after")
+	insertNote(t, notes, "note after")
+
+	archive, err := manager.Open(info.Name)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer archive.Close()
+
+	paused, resumed := false, false
+	result, err := manager.Restore(archive, func() func() {
+		paused = true
+		return func() { resumed = true }
+	})
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if !paused || !resumed {
+		t.Errorf("expected pause and resume to run, got paused=%v resumed=%v", paused, resumed)
+	}
+	if len(result.Restored) != 2 || result.SafetyBackup == "" || result.SafetyBackup == info.Name {
+		t.Errorf("unexpected restore result: %+v", result)
+	}
+
+	// Handles that stayed open through the restore see the restored content.
+	if titles := noteTitles(t, host); len(titles) != 1 || titles[0] != "host before" {
+		t.Errorf("expected host database to be restored, got %v", titles)
+	}
+	if titles := noteTitles(t, notes); len(titles) != 1 || titles[0] != "note before" {
+		t.Errorf("expected plugin database to be restored, got %v", titles)
+	}
+}
+
+func TestManager_RestoreEncryptedDatabase(t *testing.T) {
+	dataDir := t.TempDir()
+	key := bytes.Repeat([]byte{9}, atrest.KeySize)
+	pluginPath := filepath.Join(dataDir, "plugins", "finance-tracker", "db.sqlite")
+	if err := os.MkdirAll(filepath.Dir(pluginPath), 0755); err != nil {
+		t.Fatalf("failed to create plugin directory: %v", err)
+	}
+
+	finance, err := atrest.OpenDatabase(pluginPath, key)
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	if _, err := finance.Exec("CREATE TABLE notes (title TEXT NOT NULL)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	insertNote(t, finance, "before")
+	if err := finance.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	manager := NewManager(dataDir, filepath.Join(dataDir, "backups"), 5)
+	info, err := manager.Create()
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Replace the encrypted database with a plaintext one; the restore must
+	// bring back the encrypted copy and drop the plaintext file.
+	os.Remove(atrest.EncryptedPath(pluginPath))
+	insertNote(t, openWALDatabase(t, pluginPath), "plaintext")
+
+	archive, err := manager.Open(info.Name)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer archive.Close()
+	result, err := manager.Restore(archive, func() func() { return func() {} })
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if len(result.Restored) != 1 || result.Restored[0] != "plugins/finance-tracker/db.sqlite.enc" {
+		t.Fatalf("expected the encrypted database to be restored, got %v", result.Restored)
+	}
+	if _, err := os.Stat(pluginPath); !os.IsNotExist(err) {
+		t.Errorf("expected the plaintext database to be removed, got %v", err)
+	}
+
+	restored, err := atrest.OpenDatabase(pluginPath, key)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	defer restored.Close()
+	if titles := noteTitles(t, restored); len(titles) != 1 || titles[0] != "before" {
+		t.Errorf("expected the encrypted database to be restored, got %v", titles)
+	}
+}
+
+func TestManager_RestoreRejectsInvalidArchive(t *testing.T) {
+	dataDir := t.TempDir()
+	host := openWALDatabase(t, filepath.Join(dataDir, "cortex.db"))
+	insertNote(t, host, "keep me")
+
+	manager := NewManager(dataDir, filepath.Join(dataDir, "backups"), 5)
+	_, err := manager.Restore(bytes.NewReader([]byte("not an archive")), func() func() {
+		t.Fatal("pause must not run for an invalid archive")
+		return func() {}
+	})
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Fatalf("expected ErrInvalidArchive, got %v", err)
+	}
+
+	if titles := noteTitles(t, host); len(titles) != 1 || titles[0] != "keep me" {
+		t.Errorf("expected data to be untouched, got %v", titles)
+	}
+}
+
+func TestManager_PrunesBeyondRetention(t *testing.T) {
+	dataDir := t.TempDir()
+	openWALDatabase(t, filepath.Join(dataDir, "cortex.db"))
+
+	manager := NewManager(dataDir, filepath.Join(dataDir, "backups"), 2)
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return clock }
+
+	var names []string
+	for range 3 {
+		info, err := manager.Create()
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		names = append(names, info.Name)
+	}
+
+	// Backups within the same second get distinct names.
+	if names[0] == names[1] || names[1] == names[2] {
+		t.Fatalf("expected distinct backup names, got %v", names)
+	}
+
+	backups, err := manager.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != names[2] || backups[1].Name != names[1] {
+		t.Fatalf("expected the two newest backups %v, got %+v", names[1:], backups)
+	}
+
+	if _, err := manager.Open(names[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the oldest backup to be pruned, got %v", err)
+	}
+	if _, err := manager.Open("../cortex.db"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a path outside the backup directory, got %v", err)
+	}
+}
+
+func TestManager_RunScheduled(t *testing.T) {
+	dataDir := t.TempDir()
+	openWALDatabase(t, filepath.Join(dataDir, "cortex.db"))
+
+	manager := NewManager(dataDir, filepath.Join(dataDir, "backups"), 5)
+	clock := time.Now()
+	manager.now = func() time.Time { return clock }
+
+	if info, err := manager.RunScheduled(time.Hour); err != nil || info == nil {
+		t.Fatalf("expected a first scheduled backup, got %v, %v", info, err)
+	}
+	if info, err := manager.RunScheduled(time.Hour); err != nil || info != nil {
+		t.Fatalf("expected no backup while the newest is recent, got %v, %v", info, err)
+	}
+
+	clock = clock.Add(2 * time.Hour)
+	if info, err := manager.RunScheduled(time.Hour); err != nil || info == nil {
+		t.Fatalf("expected a backup once the interval elapsed, got %v, %v", info, err)
+	}
+}
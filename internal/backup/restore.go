@@ -0,0 +1,256 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+)
+
+// maxDatabaseSize caps a single database inside an archive being restored.
+const maxDatabaseSize = 4 << 30
+
+// ErrInvalidArchive is returned when an archive being restored was not written
+// by WriteArchive or contains a damaged database.
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// StagedRestore is an archive that has been unpacked and verified, ready to be
+// applied over the live databases.
+type StagedRestore struct {
+	Metadata
+	dir   string
+	paths map[string]string
+}
+
+// StageRestore unpacks a tar.gz archive written by WriteArchive into a
+// temporary directory and checks the integrity of every database in it. Nothing
+// under the data directory is touched. Close must be called to remove the files.
+func StageRestore(source io.Reader) (*StagedRestore, error) {
+	gzipReader, err := gzip.NewReader(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a gzip stream", ErrInvalidArchive)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	header, err := tarReader.Next()
+	if err != nil || header.Name != metadataFile {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, metadataFile)
+	}
+
+	var metadata Metadata
+	if err := json.NewDecoder(io.LimitReader(tarReader, 1<<20)).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("%w: reading %s", ErrInvalidArchive, metadataFile)
+	}
+	for _, name := range metadata.Files {
+		if !validDatabasePath(name) {
+			return nil, fmt.Errorf("%w: unexpected file %q", ErrInvalidArchive, name)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "cortex-restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating restore directory: %w", err)
+	}
+	staged := &StagedRestore{Metadata: metadata, dir: dir, paths: make(map[string]string)}
+
+	if err := staged.unpack(tarReader); err != nil {
+		staged.Close()
+		return nil, err
+	}
+
+	return staged, nil
+}
+
+// unpack writes every database entry to the staging directory and verifies it.
+func (s *StagedRestore) unpack(tarReader *tar.Reader) error {
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: reading entry: %v", ErrInvalidArchive, err)
+		}
+
+		name := header.Name
+		if header.Typeflag != tar.TypeReg || !slices.Contains(s.Files, name) {
+			return fmt.Errorf("%w: unexpected entry %q", ErrInvalidArchive, name)
+		}
+		if _, seen := s.paths[name]; seen {
+			return fmt.Errorf("%w: duplicate entry %q", ErrInvalidArchive, name)
+		}
+		if header.Size > maxDatabaseSize {
+			return fmt.Errorf("%w: %s is too large", ErrInvalidArchive, name)
+		}
+
+		stagedPath := filepath.Join(s.dir, fmt.Sprintf("%d.sqlite", len(s.paths)))
+		if err := writeStagedFile(stagedPath, tarReader); err != nil {
+			return fmt.Errorf("staging %s: %w", name, err)
+		}
+		check := checkDatabase
+		if strings.HasSuffix(name, atrest.EncryptedExtension) {
+			check = checkEncryptedDatabase
+		}
+		if err := check(stagedPath); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+		}
+		s.paths[name] = stagedPath
+	}
+
+	for _, name := range s.Files {
+		if _, ok := s.paths[name]; !ok {
+			return fmt.Errorf("%w: %s is listed but missing", ErrInvalidArchive, name)
+		}
+	}
+
+	return nil
+}
+
+// Apply overwrites the databases under dataDir with the staged snapshots.
+// Databases that are not in the archive are left as they are. Restoring a
+// plugin database removes its counterpart in the other form, so the plugin
+// opens the restored copy whether it was encrypted or not.
+func (s *StagedRestore) Apply(dataDir string) error {
+	for _, name := range s.Files {
+		livePath := filepath.Join(dataDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(livePath), 0755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", name, err)
+		}
+
+		plainPath, encrypted := strings.CutSuffix(livePath, atrest.EncryptedExtension)
+		var err error
+		switch {
+		case encrypted:
+			err = restoreEncryptedDatabase(s.paths[name], livePath)
+			if err == nil {
+				err = removeFiles(plainPath, plainPath+"-wal", plainPath+"-shm")
+			}
+		case name != "cortex.db":
+			err = restoreDatabase(s.paths[name], livePath)
+			if err == nil {
+				err = removeFiles(atrest.EncryptedPath(livePath))
+			}
+		default:
+			err = restoreDatabase(s.paths[name], livePath)
+		}
+		if err != nil {
+			return fmt.Errorf("restoring %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Close removes the staged files.
+func (s *StagedRestore) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// validDatabasePath reports whether name is a database path WriteArchive
+// produces: the host database or a plugin database, plaintext or encrypted.
+func validDatabasePath(name string) bool {
+	if name == "cortex.db" {
+		return true
+	}
+
+	parts := strings.Split(name, "/")
+	return len(parts) == 3 && parts[0] == "plugins" && (parts[2] == "db.sqlite" || parts[2] == "db.sqlite"+atrest.EncryptedExtension) &&
+		parts[1] != "" && parts[1] != "." && parts[1] != ".." && !strings.ContainsAny(parts[1], `\:`)
+}
+
+func writeStagedFile(path string, source io.Reader) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, source); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// checkDatabase runs SQLite's quick integrity check on a staged database.
+func checkDatabase(path string) error {
+	database, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var result string
+	if err := database.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+	return nil
+}
+
+// checkEncryptedDatabase checks that a staged encrypted database has the
+// header OpenDatabase writes. Its content can only be verified with the
+// plugin's key, which the host does not hold while restoring.
+func checkEncryptedDatabase(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header := make([]byte, 64)
+	read, _ := io.ReadFull(file, header)
+	if !atrest.IsSealed(header[:read]) {
+		return errors.New("not an encrypted cortex database")
+	}
+	return nil
+}
+
+// restoreEncryptedDatabase replaces livePath with a copy of snapshotPath. The
+// copy is written next to livePath and renamed over it.
+func restoreEncryptedDatabase(snapshotPath string, livePath string) error {
+	source, err := os.Open(snapshotPath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	temporary, err := os.CreateTemp(filepath.Dir(livePath), ".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+
+	if _, err := io.Copy(temporary, source); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Sync(); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), livePath)
+}
+
+func removeFiles(paths ...string) error {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+)
+
+// busyTimeoutMillis is how long a restore waits for writers on the live database.
+const busyTimeoutMillis = 5000
+
+// onlineBackupConn is the part of a modernc.org/sqlite connection that exposes
+// SQLite's online backup API.
+type onlineBackupConn interface {
+	NewBackup(destinationURI string) (*sqlite.Backup, error)
+	NewRestore(sourceURI string) (*sqlite.Backup, error)
+}
+
+// snapshotDatabase copies a live SQLite database into a standalone file with the
+// online backup API. The copy is consistent and includes transactions that are
+// still in the source's WAL, while other connections keep reading and writing.
+func snapshotDatabase(sourcePath, destinationPath string) error {
+	return runOnlineBackup(sourcePath, func(conn onlineBackupConn) (*sqlite.Backup, error) {
+		return conn.NewBackup(destinationPath)
+	})
+}
+
+// restoreDatabase overwrites the database at livePath with the snapshot at
+// snapshotPath. The pages are copied under SQLite's locking, so connections that
+// already have livePath open see the restored content on their next query.
+func restoreDatabase(snapshotPath, livePath string) error {
+	return runOnlineBackup(livePath, func(conn onlineBackupConn) (*sqlite.Backup, error) {
+		return conn.NewRestore(snapshotPath)
+	})
+}
+
+// runOnlineBackup opens path and runs the backup started by start to completion.
+func runOnlineBackup(path string, start func(onlineBackupConn) (*sqlite.Backup, error)) error {
+	database, err := sql.Open("sqlite", fmt.Sprintf("%s?_pragma=busy_timeout(%d)", path, busyTimeoutMillis))
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer database.Close()
+
+	conn, err := database.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		backupConn, ok := driverConn.(onlineBackupConn)
+		if !ok {
+			return errors.New("sqlite driver does not support online backup")
+		}
+
+		operation, err := start(backupConn)
+		if err != nil {
+			return fmt.Errorf("starting online backup: %w", err)
+		}
+
+		// Copy every page in a single step so the result reflects one point in time.
+		if _, err := operation.Step(-1); err != nil {
+			_ = operation.Finish()
+			return fmt.Errorf("copying pages: %w", err)
+		}
+
+		if err := operation.Finish(); err != nil {
+			return fmt.Errorf("finishing online backup: %w", err)
+		}
+		return nil
+	})
+}
@@ -1,57 +1,557 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+	"github.com/alvarotorresc/cortex/internal/logging"
+	"github.com/alvarotorresc/cortex/internal/plugin"
 )
 
+// DefaultCSP is the frontend's Content-Security-Policy unless CORTEX_CSP
+// replaces it. Inline scripts and styles are allowed because the SvelteKit
+// build bootstraps itself with an inline script.
+const DefaultCSP = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data: blob:; font-src 'self' data:; connect-src 'self'; object-src 'none'; base-uri 'self'"
+
+// Version is the Cortex release the host was built as, set by make build with
+// -ldflags "-X github.com/alvarotorresc/cortex/internal/config.Version=...".
+var Version = "dev"
+
+// basePathPattern matches CORTEX_BASE_PATH values such as "/cortex" or
+// "/apps/cortex".
+var basePathPattern = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)+$`)
+
+// referrerPolicies are the values CORTEX_REFERRER_POLICY accepts.
+var referrerPolicies = map[string]bool{
+	"no-referrer":                     true,
+	"no-referrer-when-downgrade":      true,
+	"origin":                          true,
+	"origin-when-cross-origin":        true,
+	"same-origin":                     true,
+	"strict-origin":                   true,
+	"strict-origin-when-cross-origin": true,
+	"unsafe-url":                      true,
+}
+
 // Config holds all runtime configuration for the Cortex host server.
 // Values are loaded from environment variables with sensible defaults for local development.
 type Config struct {
+	// ConfigFile is the config file settings were read from, empty when none
+	// was.
+	ConfigFile string
+
 	Port        int
 	DataDir     string
 	PluginDir   string
 	FrontendDir string
+
+	// SMTP settings for email notification delivery. Email is disabled when SMTPHost is empty.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// PluginRegistryURL points to a JSON index used to install plugins by name.
+	// PluginPublicKey is a base64 Ed25519 key; when set, installed archives must be signed with it.
+	PluginRegistryURL string
+	PluginPublicKey   string
+
+	// UpdateCheckInterval is how often plugins with an update_url are checked
+	// for newer releases (0 disables the checks).
+	UpdateCheckInterval time.Duration
+
+	// UndoWindow is how long destructive admin actions, such as purging a
+	// plugin or deleting a secret, can be undone.
+	UndoWindow time.Duration
+
+	// AuditRetention is how long entries of the audit log of state-changing
+	// requests are kept (0 keeps them forever).
+	AuditRetention time.Duration
+
+	// WidgetCacheTTL is how long plugin widget data is cached by the host
+	// (0 disables the cache).
+	WidgetCacheTTL time.Duration
+
+	// PluginTimeout is how long a plugin may take to answer an API request
+	// before the host gives up with 504 (0 waits for as long as the client
+	// does). PluginKeepalive is how often the host pings idle plugin
+	// connections to notice frozen plugins (0 disables the pings).
+	PluginTimeout   time.Duration
+	PluginKeepalive time.Duration
+
+	// MigrationLint is what happens to plugins whose SQL migrations look unsafe:
+	// "off", "warn" (log and run them) or "enforce" (refuse to load the plugin).
+	MigrationLint string
+
+	// LogFormat is "text" or "json"; LogLevel is "debug", "info", "warn" or "error".
+	LogFormat string
+	LogLevel  string
+
+	// BackupDir holds automatic and on-demand backups. A backup is taken whenever
+	// the newest one is older than BackupInterval (0 disables scheduled backups),
+	// and only the BackupRetention most recent backups are kept.
+	BackupDir       string
+	BackupInterval  time.Duration
+	BackupRetention int
+
+	// CSP is the base Content-Security-Policy of the frontend ("off" disables
+	// it), to which loaded plugins add the sources their manifests declare.
+	// FrameAncestors sets its frame-ancestors directive, and ReferrerPolicy the
+	// Referrer-Policy header.
+	CSP            string
+	FrameAncestors string
+	ReferrerPolicy string
+
+	// AllowedOrigins are the origins other than the host's own that may call
+	// the API from a browser, such as a frontend dev server. A "*" matches
+	// any run of characters, as in "http://localhost:*".
+	AllowedOrigins []string
+
+	// TrustedProxies are the IPs and CIDR ranges of reverse proxies whose
+	// X-Forwarded-For, X-Real-IP and X-Forwarded-Proto headers are believed.
+	// From other peers the headers are dropped.
+	TrustedProxies []string
+
+	// BasePath is the path prefix Cortex is served under, such as "/cortex"
+	// behind a reverse proxy; empty serves it at the root.
+	BasePath string
+
+	// TLSCertFile and TLSKeyFile are the PEM certificate and private key the
+	// server listens on HTTPS with; HTTP is served when they are empty. With
+	// TLSSelfSigned a self-signed certificate is generated there on first
+	// start, under DataDir/tls unless paths are given, covering localhost, the
+	// machine's hostname and IPs, and TLSHosts.
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSSelfSigned bool
+	TLSHosts      []string
+
+	// RateLimit is how many requests a client IP may make per minute, with
+	// bursts of up to RateLimitBurst (0 disables rate limiting). MaxBodyMB
+	// caps request bodies in MiB, except uploaded backups, which have their
+	// own larger limit.
+	RateLimit      int
+	RateLimitBurst int
+	MaxBodyMB      int
+
+	// WALWarnMB is the size in MiB past which a plugin database's write-ahead
+	// log raises a notification (0 disables the warning).
+	WALWarnMB int
+
+	// DisabledPlugins are the IDs of installed plugins not loaded at startup.
+	DisabledPlugins []string
+
+	// PluginLimits caps the memory and CPU of plugins and confines them to a
+	// filesystem root, in the form read by plugin.ParseResourceLimits.
+	// PluginCgroup is a cgroup v2 directory delegated to the host, under
+	// which the kernel enforces the memory and CPU limits; without it the host
+	// measures plugins and kills the ones over their limits.
+	PluginLimits string
+	PluginCgroup string
+
+	// DemoMode seeds every plugin's database with sample data the first time
+	// it is migrated, for demo and screenshot instances.
+	DemoMode bool
+
+	// DBPassphrase enables at-rest encryption of plugin databases. It is read
+	// from CORTEX_DB_PASSPHRASE or from the file named by
+	// CORTEX_DB_PASSPHRASE_FILE; with DBPassphrasePrompt it is asked for on the
+	// terminal at startup instead.
+	DBPassphrase       string
+	DBPassphrasePrompt bool
 }
 
-// Load reads configuration from environment variables and validates it.
-// It returns an error immediately if any required value is invalid,
-// following the fail-fast principle.
+// Load reads configuration from environment variables and the config file,
+// named by CORTEX_CONFIG or DefaultConfigFile, and validates it. Environment
+// variables override the file. It returns an error if any value is invalid,
+// following the fail-fast principle; the error lists every invalid value,
+// not just the first.
 func Load() (*Config, error) {
+	configFile, required := configFilePath()
+	values, err := readConfigFile(configFile)
+	switch {
+	case err == nil:
+		fileValues, fileKeys = values, fileKeysByEnv(values)
+	case errors.Is(err, os.ErrNotExist) && !required:
+		fileValues, fileKeys, configFile = nil, nil, ""
+	default:
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
 	config := &Config{
+		ConfigFile: configFile,
+
 		Port:        getEnvAsInt("CORTEX_PORT", 8080),
 		DataDir:     getEnv("CORTEX_DATA_DIR", "./data"),
 		PluginDir:   getEnv("CORTEX_PLUGIN_DIR", "./plugins"),
 		FrontendDir: getEnv("CORTEX_FRONTEND_DIR", "./frontend/build"),
+
+		SMTPHost:     getEnv("CORTEX_SMTP_HOST", ""),
+		SMTPPort:     getEnvAsInt("CORTEX_SMTP_PORT", 587),
+		SMTPUsername: getEnv("CORTEX_SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("CORTEX_SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("CORTEX_SMTP_FROM", ""),
+
+		PluginRegistryURL: getEnv("CORTEX_PLUGIN_REGISTRY_URL", ""),
+		PluginPublicKey:   getEnv("CORTEX_PLUGIN_PUBLIC_KEY", ""),
+		MigrationLint:     getEnv("CORTEX_MIGRATION_LINT", string(plugin.MigrationPolicyWarn)),
+
+		UpdateCheckInterval: getEnvAsDuration("CORTEX_UPDATE_CHECK_INTERVAL", 24*time.Hour),
+		UndoWindow:          getEnvAsDuration("CORTEX_UNDO_WINDOW", 10*time.Minute),
+		WidgetCacheTTL:      getEnvAsDuration("CORTEX_WIDGET_CACHE_TTL", 30*time.Second),
+		AuditRetention:      getEnvAsDuration("CORTEX_AUDIT_RETENTION", 90*24*time.Hour),
+		PluginTimeout:       getEnvAsDuration("CORTEX_PLUGIN_TIMEOUT", 30*time.Second),
+		PluginKeepalive:     getEnvAsDuration("CORTEX_PLUGIN_KEEPALIVE", 30*time.Second),
+
+		LogFormat: getEnv("CORTEX_LOG_FORMAT", logging.FormatText),
+		LogLevel:  getEnv("CORTEX_LOG_LEVEL", "info"),
+
+		BackupInterval:  getEnvAsDuration("CORTEX_BACKUP_INTERVAL", 24*time.Hour),
+		BackupRetention: getEnvAsInt("CORTEX_BACKUP_RETENTION", 7),
+
+		CSP:            getEnv("CORTEX_CSP", DefaultCSP),
+		FrameAncestors: getEnv("CORTEX_FRAME_ANCESTORS", "'self'"),
+		ReferrerPolicy: getEnv("CORTEX_REFERRER_POLICY", "strict-origin-when-cross-origin"),
+
+		AllowedOrigins: getEnvAsList("CORTEX_ALLOWED_ORIGINS", []string{"http://localhost:*", "http://127.0.0.1:*"}),
+		TrustedProxies: getEnvAsList("CORTEX_TRUSTED_PROXIES", []string{"127.0.0.0/8", "::1"}),
+		BasePath:       strings.TrimSuffix(getEnv("CORTEX_BASE_PATH", ""), "/"),
+
+		RateLimit:      getEnvAsInt("CORTEX_RATE_LIMIT", 600),
+		RateLimitBurst: getEnvAsInt("CORTEX_RATE_LIMIT_BURST", 100),
+		MaxBodyMB:      getEnvAsInt("CORTEX_MAX_BODY_MB", 32),
+
+		WALWarnMB: getEnvAsInt("CORTEX_WAL_WARN_MB", 100),
+
+		DemoMode: getEnv("CORTEX_DEMO", "false") == "true",
+	}
+	config.BackupDir = getEnv("CORTEX_BACKUP_DIR", filepath.Join(config.DataDir, "backups"))
+
+	config.TLSCertFile = getEnv("CORTEX_TLS_CERT", "")
+	config.TLSKeyFile = getEnv("CORTEX_TLS_KEY", "")
+	config.TLSSelfSigned = getEnv("CORTEX_TLS_SELF_SIGNED", "false") == "true"
+	config.TLSHosts = getEnvAsList("CORTEX_TLS_HOSTS", nil)
+	config.DisabledPlugins = getEnvAsList("CORTEX_DISABLED_PLUGINS", nil)
+	config.PluginLimits = getEnv("CORTEX_PLUGIN_LIMITS", "")
+	config.PluginCgroup = getEnv("CORTEX_PLUGIN_CGROUP", "")
+	if config.TLSSelfSigned && config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		config.TLSCertFile = filepath.Join(config.DataDir, "tls", "cert.pem")
+		config.TLSKeyFile = filepath.Join(config.DataDir, "tls", "key.pem")
+	}
+
+	config.DBPassphrase = lookup("CORTEX_DB_PASSPHRASE")
+	config.DBPassphrasePrompt = getEnv("CORTEX_DB_PASSPHRASE_PROMPT", "false") == "true"
+	if path := lookup("CORTEX_DB_PASSPHRASE_FILE"); path != "" && config.DBPassphrase == "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading CORTEX_DB_PASSPHRASE_FILE: %w", err)
+		}
+		config.DBPassphrase = strings.TrimRight(string(content), "\r\n")
 	}
 
 	if err := config.validate(); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+		return nil, fmt.Errorf("config validation failed: %w", renameFileProblems(err, config.ConfigFile))
 	}
 
 	return config, nil
 }
 
 // validate checks that all configuration values are within acceptable bounds.
+// Every invalid value is reported, joined into one error, so they can all be
+// fixed at once.
 func (c *Config) validate() error {
+	var problems []error
+
 	if c.Port < 1 || c.Port > 65535 {
-		return fmt.Errorf("CORTEX_PORT must be between 1 and 65535, got %d", c.Port)
+		problems = append(problems, fmt.Errorf("CORTEX_PORT must be between 1 and 65535, got %d", c.Port))
 	}
 
 	if c.DataDir == "" {
-		return fmt.Errorf("CORTEX_DATA_DIR must not be empty")
+		problems = append(problems, errors.New("CORTEX_DATA_DIR must not be empty"))
 	}
 
 	if c.PluginDir == "" {
-		return fmt.Errorf("CORTEX_PLUGIN_DIR must not be empty")
+		problems = append(problems, errors.New("CORTEX_PLUGIN_DIR must not be empty"))
 	}
 
 	if c.FrontendDir == "" {
-		return fmt.Errorf("CORTEX_FRONTEND_DIR must not be empty")
+		problems = append(problems, errors.New("CORTEX_FRONTEND_DIR must not be empty"))
+	}
+
+	if c.SMTPHost != "" {
+		if c.SMTPPort < 1 || c.SMTPPort > 65535 {
+			problems = append(problems, fmt.Errorf("CORTEX_SMTP_PORT must be between 1 and 65535, got %d", c.SMTPPort))
+		}
+		if c.SMTPFrom == "" {
+			problems = append(problems, errors.New("CORTEX_SMTP_FROM must be set when CORTEX_SMTP_HOST is set"))
+		}
+	}
+
+	if c.PluginPublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.PluginPublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			problems = append(problems, errors.New("CORTEX_PLUGIN_PUBLIC_KEY must be a base64-encoded Ed25519 public key"))
+		}
+	}
+
+	if !plugin.ValidMigrationPolicy(c.MigrationLint) {
+		problems = append(problems, fmt.Errorf("CORTEX_MIGRATION_LINT must be off, warn or enforce, got %q", c.MigrationLint))
+	}
+
+	if !logging.ValidFormat(c.LogFormat) {
+		problems = append(problems, fmt.Errorf("CORTEX_LOG_FORMAT must be text or json, got %q", c.LogFormat))
+	}
+
+	if !logging.ValidLevel(c.LogLevel) {
+		problems = append(problems, fmt.Errorf("CORTEX_LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+
+	if c.BackupInterval < 0 || (c.BackupInterval > 0 && c.BackupInterval < time.Minute) {
+		problems = append(problems, fmt.Errorf("CORTEX_BACKUP_INTERVAL must be at least 1m, or 0 to disable scheduled backups, got %s", c.BackupInterval))
+	}
+
+	if c.UpdateCheckInterval < 0 || (c.UpdateCheckInterval > 0 && c.UpdateCheckInterval < time.Minute) {
+		problems = append(problems, fmt.Errorf("CORTEX_UPDATE_CHECK_INTERVAL must be at least 1m, or 0 to disable update checks, got %s", c.UpdateCheckInterval))
+	}
+
+	if c.UndoWindow < time.Minute {
+		problems = append(problems, fmt.Errorf("CORTEX_UNDO_WINDOW must be at least 1m, got %s", c.UndoWindow))
+	}
+
+	if c.AuditRetention < 0 {
+		problems = append(problems, fmt.Errorf("CORTEX_AUDIT_RETENTION must not be negative, or 0 to keep every entry, got %s", c.AuditRetention))
+	}
+
+	if c.WidgetCacheTTL < 0 {
+		problems = append(problems, fmt.Errorf("CORTEX_WIDGET_CACHE_TTL must not be negative, or 0 to disable the cache, got %s", c.WidgetCacheTTL))
+	}
+
+	if c.PluginTimeout < 0 {
+		problems = append(problems, fmt.Errorf("CORTEX_PLUGIN_TIMEOUT must not be negative, or 0 to disable the timeout, got %s", c.PluginTimeout))
+	}
+
+	if c.PluginKeepalive < 0 || (c.PluginKeepalive > 0 && c.PluginKeepalive < plugin.MinKeepaliveInterval) {
+		problems = append(problems, fmt.Errorf("CORTEX_PLUGIN_KEEPALIVE must be at least %s, or 0 to disable keepalive pings, got %s", plugin.MinKeepaliveInterval, c.PluginKeepalive))
+	}
+
+	if c.BackupRetention < 1 {
+		problems = append(problems, fmt.Errorf("CORTEX_BACKUP_RETENTION must be at least 1, got %d", c.BackupRetention))
+	}
+
+	if strings.ContainsAny(c.CSP, "\r\n") {
+		problems = append(problems, errors.New("CORTEX_CSP must be a single line"))
+	}
+
+	if strings.ContainsAny(c.FrameAncestors, ";\r\n") {
+		problems = append(problems, fmt.Errorf("CORTEX_FRAME_ANCESTORS must be a list of sources such as 'self', got %q", c.FrameAncestors))
+	}
+
+	if !referrerPolicies[c.ReferrerPolicy] {
+		problems = append(problems, fmt.Errorf("CORTEX_REFERRER_POLICY must be a Referrer-Policy value such as same-origin, got %q", c.ReferrerPolicy))
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if !validOrigin(origin) {
+			problems = append(problems, fmt.Errorf("CORTEX_ALLOWED_ORIGINS must list origins such as http://localhost:5173, got %q", origin))
+		}
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if _, err := parseProxy(proxy); err != nil {
+			problems = append(problems, fmt.Errorf("CORTEX_TRUSTED_PROXIES must list IPs or CIDR ranges, got %q", proxy))
+		}
+	}
+
+	if c.BasePath != "" && !basePathPattern.MatchString(c.BasePath) {
+		problems = append(problems, fmt.Errorf("CORTEX_BASE_PATH must be a path such as /cortex, got %q", c.BasePath))
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		problems = append(problems, errors.New("CORTEX_TLS_CERT and CORTEX_TLS_KEY must be set together"))
+	}
+
+	for _, host := range c.TLSHosts {
+		if strings.ContainsAny(host, " /:") && net.ParseIP(host) == nil {
+			problems = append(problems, fmt.Errorf("CORTEX_TLS_HOSTS must list hostnames or IPs, got %q", host))
+		}
+	}
+
+	if c.RateLimit < 0 {
+		problems = append(problems, fmt.Errorf("CORTEX_RATE_LIMIT must be 0 or more, got %d", c.RateLimit))
+	}
+
+	if c.RateLimit > 0 && c.RateLimitBurst < 1 {
+		problems = append(problems, fmt.Errorf("CORTEX_RATE_LIMIT_BURST must be at least 1, got %d", c.RateLimitBurst))
+	}
+
+	if c.MaxBodyMB < 1 {
+		problems = append(problems, fmt.Errorf("CORTEX_MAX_BODY_MB must be at least 1, got %d", c.MaxBodyMB))
+	}
+
+	if c.WALWarnMB < 0 {
+		problems = append(problems, fmt.Errorf("CORTEX_WAL_WARN_MB must be 0 or more, got %d", c.WALWarnMB))
+	}
+
+	if _, err := plugin.ParseResourceLimits(c.PluginLimits); err != nil {
+		problems = append(problems, fmt.Errorf("CORTEX_PLUGIN_LIMITS is invalid: %w", err))
 	}
 
-	return nil
+	if c.PluginCgroup != "" && !filepath.IsAbs(c.PluginCgroup) {
+		problems = append(problems, fmt.Errorf("CORTEX_PLUGIN_CGROUP must be an absolute path, got %q", c.PluginCgroup))
+	}
+
+	if c.DBPassphrase != "" && len(c.DBPassphrase) < atrest.MinPassphraseLength {
+		problems = append(problems, fmt.Errorf("CORTEX_DB_PASSPHRASE must be at least %d characters", atrest.MinPassphraseLength))
+	}
+
+	return errors.Join(problems...)
+}
+
+// PluginSigningKey returns the decoded plugin signing key, or nil if none is configured.
+// The key has already been checked by validate.
+func (c *Config) PluginSigningKey() ed25519.PublicKey {
+	if c.PluginPublicKey == "" {
+		return nil
+	}
+	key, _ := base64.StdEncoding.DecodeString(c.PluginPublicKey)
+	return ed25519.PublicKey(key)
+}
+
+// ResourceLimits returns the resource limits of plugins by ID. They have
+// already been checked by validate.
+func (c *Config) ResourceLimits() map[string]plugin.ResourceLimits {
+	limits, _ := plugin.ParseResourceLimits(c.PluginLimits)
+	return limits
+}
+
+// TrustedProxyNets returns the trusted proxies as IP ranges, a single IP
+// being a range of one. They have already been checked by validate.
+func (c *Config) TrustedProxyNets() []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		if network, err := parseProxy(proxy); err == nil {
+			nets = append(nets, network)
+		}
+	}
+	return nets
+}
+
+// parseProxy parses an IP or CIDR range.
+func parseProxy(proxy string) (*net.IPNet, error) {
+	if ip := net.ParseIP(proxy); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(proxy)
+	return network, err
+}
+
+// validOrigin reports whether origin is an http or https origin, without a
+// path, with at most one "*" wildcard.
+func validOrigin(origin string) bool {
+	scheme, host, found := strings.Cut(origin, "://")
+	if !found || (scheme != "http" && scheme != "https") || host == "" {
+		return false
+	}
+	return !strings.ContainsAny(host, "/ \t") && strings.Count(origin, "*") <= 1
+}
+
+// TLSEnabled reports whether the server listens on HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+// Sanitized returns the effective configuration in the shape of the config
+// file, for display. Secrets are replaced by whether they are set.
+func (c *Config) Sanitized() map[string]interface{} {
+	return map[string]interface{}{
+		"config_file":  c.ConfigFile,
+		"port":         c.Port,
+		"data_dir":     c.DataDir,
+		"plugin_dir":   c.PluginDir,
+		"frontend_dir": c.FrontendDir,
+		"demo":         c.DemoMode,
+		"log": map[string]interface{}{
+			"format": c.LogFormat,
+			"level":  c.LogLevel,
+		},
+		"smtp": map[string]interface{}{
+			"host":         c.SMTPHost,
+			"port":         c.SMTPPort,
+			"username":     c.SMTPUsername,
+			"password_set": c.SMTPPassword != "",
+			"from":         c.SMTPFrom,
+		},
+		"plugins": map[string]interface{}{
+			"registry_url":          c.PluginRegistryURL,
+			"public_key":            c.PluginPublicKey,
+			"migration_lint":        c.MigrationLint,
+			"update_check_interval": c.UpdateCheckInterval.String(),
+			"widget_cache_ttl":      c.WidgetCacheTTL.String(),
+			"timeout":               c.PluginTimeout.String(),
+			"keepalive":             c.PluginKeepalive.String(),
+			"wal_warn_mb":           c.WALWarnMB,
+			"disabled":              nonNil(c.DisabledPlugins),
+			"limits":                c.ResourceLimits(),
+			"cgroup":                c.PluginCgroup,
+		},
+		"backup": map[string]interface{}{
+			"dir":       c.BackupDir,
+			"interval":  c.BackupInterval.String(),
+			"retention": c.BackupRetention,
+		},
+		"undo_window": c.UndoWindow.String(),
+		"audit": map[string]interface{}{
+			"retention": c.AuditRetention.String(),
+		},
+		"http": map[string]interface{}{
+			"allowed_origins":  nonNil(c.AllowedOrigins),
+			"trusted_proxies":  nonNil(c.TrustedProxies),
+			"base_path":        c.BasePath,
+			"rate_limit":       c.RateLimit,
+			"rate_limit_burst": c.RateLimitBurst,
+			"max_body_mb":      c.MaxBodyMB,
+		},
+		"security": map[string]interface{}{
+			"csp":             c.CSP,
+			"frame_ancestors": c.FrameAncestors,
+			"referrer_policy": c.ReferrerPolicy,
+		},
+		"tls": map[string]interface{}{
+			"enabled":     c.TLSEnabled(),
+			"cert":        c.TLSCertFile,
+			"key":         c.TLSKeyFile,
+			"self_signed": c.TLSSelfSigned,
+			"hosts":       nonNil(c.TLSHosts),
+		},
+		"database": map[string]interface{}{
+			"encrypted":         c.DBPassphrase != "" || c.DBPassphrasePrompt,
+			"passphrase_prompt": c.DBPassphrasePrompt,
+		},
+	}
+}
+
+// nonNil returns items, or an empty list for nil, so lists encode as [].
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
 }
 
 // Address returns the formatted listen address for the HTTP server.
@@ -59,19 +559,37 @@ func (c *Config) Address() string {
 	return fmt.Sprintf(":%d", c.Port)
 }
 
-// getEnv reads an environment variable or returns a default value.
+// getEnv reads an environment variable, or its config file setting, or
+// returns a default value.
 func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
+	value := lookup(key)
 	if value == "" {
 		return defaultValue
 	}
 	return value
 }
 
+// getEnvAsList reads an environment variable as a comma-separated list or
+// returns a default value. Empty items are ignored.
+func getEnvAsList(key string, defaultValue []string) []string {
+	value := lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getEnvAsInt reads an environment variable as an integer or returns a default value.
 // If the value cannot be parsed as an integer, the default is returned.
 func getEnvAsInt(key string, defaultValue int) int {
-	value := os.Getenv(key)
+	value := lookup(key)
 	if value == "" {
 		return defaultValue
 	}
@@ -83,3 +601,19 @@ func getEnvAsInt(key string, defaultValue int) int {
 
 	return parsed
 }
+
+// getEnvAsDuration reads an environment variable as a duration (e.g. "12h") or returns a default value.
+// If the value cannot be parsed as a duration, the default is returned.
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	value := lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+
+	return parsed
+}
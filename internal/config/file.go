@@ -0,0 +1,357 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is read when CORTEX_CONFIG is not set, if it exists.
+const DefaultConfigFile = "cortex.yaml"
+
+// Kinds of values a config file setting holds.
+const (
+	kindString = iota
+	kindInt
+	kindBool
+	kindDuration
+	kindList
+	kindLimits
+)
+
+// fileSetting is a setting of the config file: the environment variable it
+// stands for, which overrides it, and the kind of value it holds.
+type fileSetting struct {
+	env  string
+	kind int
+}
+
+// fileSettings are the settings a config file may contain, by their dotted
+// path. Every one has an environment variable counterpart.
+var fileSettings = map[string]fileSetting{
+	"port":         {"CORTEX_PORT", kindInt},
+	"data_dir":     {"CORTEX_DATA_DIR", kindString},
+	"plugin_dir":   {"CORTEX_PLUGIN_DIR", kindString},
+	"frontend_dir": {"CORTEX_FRONTEND_DIR", kindString},
+	"demo":         {"CORTEX_DEMO", kindBool},
+
+	"log.format": {"CORTEX_LOG_FORMAT", kindString},
+	"log.level":  {"CORTEX_LOG_LEVEL", kindString},
+
+	"smtp.host":     {"CORTEX_SMTP_HOST", kindString},
+	"smtp.port":     {"CORTEX_SMTP_PORT", kindInt},
+	"smtp.username": {"CORTEX_SMTP_USERNAME", kindString},
+	"smtp.password": {"CORTEX_SMTP_PASSWORD", kindString},
+	"smtp.from":     {"CORTEX_SMTP_FROM", kindString},
+
+	"plugins.registry_url":          {"CORTEX_PLUGIN_REGISTRY_URL", kindString},
+	"plugins.public_key":            {"CORTEX_PLUGIN_PUBLIC_KEY", kindString},
+	"plugins.migration_lint":        {"CORTEX_MIGRATION_LINT", kindString},
+	"plugins.update_check_interval": {"CORTEX_UPDATE_CHECK_INTERVAL", kindDuration},
+	"plugins.widget_cache_ttl":      {"CORTEX_WIDGET_CACHE_TTL", kindDuration},
+	"plugins.timeout":               {"CORTEX_PLUGIN_TIMEOUT", kindDuration},
+	"plugins.keepalive":             {"CORTEX_PLUGIN_KEEPALIVE", kindDuration},
+	"plugins.wal_warn_mb":           {"CORTEX_WAL_WARN_MB", kindInt},
+	"plugins.disabled":              {"CORTEX_DISABLED_PLUGINS", kindList},
+	"plugins.limits":                {"CORTEX_PLUGIN_LIMITS", kindLimits},
+	"plugins.cgroup":                {"CORTEX_PLUGIN_CGROUP", kindString},
+
+	"backup.dir":       {"CORTEX_BACKUP_DIR", kindString},
+	"backup.interval":  {"CORTEX_BACKUP_INTERVAL", kindDuration},
+	"backup.retention": {"CORTEX_BACKUP_RETENTION", kindInt},
+	"undo_window":      {"CORTEX_UNDO_WINDOW", kindDuration},
+
+	"audit.retention": {"CORTEX_AUDIT_RETENTION", kindDuration},
+
+	"http.allowed_origins":  {"CORTEX_ALLOWED_ORIGINS", kindList},
+	"http.trusted_proxies":  {"CORTEX_TRUSTED_PROXIES", kindList},
+	"http.base_path":        {"CORTEX_BASE_PATH", kindString},
+	"http.rate_limit":       {"CORTEX_RATE_LIMIT", kindInt},
+	"http.rate_limit_burst": {"CORTEX_RATE_LIMIT_BURST", kindInt},
+	"http.max_body_mb":      {"CORTEX_MAX_BODY_MB", kindInt},
+
+	"security.csp":             {"CORTEX_CSP", kindString},
+	"security.frame_ancestors": {"CORTEX_FRAME_ANCESTORS", kindString},
+	"security.referrer_policy": {"CORTEX_REFERRER_POLICY", kindString},
+
+	"tls.cert":        {"CORTEX_TLS_CERT", kindString},
+	"tls.key":         {"CORTEX_TLS_KEY", kindString},
+	"tls.self_signed": {"CORTEX_TLS_SELF_SIGNED", kindBool},
+	"tls.hosts":       {"CORTEX_TLS_HOSTS", kindList},
+
+	"database.passphrase_file":   {"CORTEX_DB_PASSPHRASE_FILE", kindString},
+	"database.passphrase_prompt": {"CORTEX_DB_PASSPHRASE_PROMPT", kindBool},
+}
+
+// fileValues holds the settings read from the config file by Load, by the
+// environment variable they stand for; lookup prefers the environment.
+var fileValues map[string]string
+
+// fileKeys maps the environment variables set by the config file back to
+// their settings, so validation errors name what the user wrote.
+var fileKeys map[string]string
+
+// lookup reads a setting from the environment, or from the config file when
+// the environment variable is unset or empty.
+func lookup(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fileValues[key]
+}
+
+// Setting returns the value of a setting by its environment variable, from
+// the environment or the config file, without validating the configuration.
+// It is for CLI commands that talk to a running host.
+func Setting(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	path, _ := configFilePath()
+	values, _ := readConfigFile(path)
+	return values[key]
+}
+
+// configFilePath returns the config file to read and whether it must exist:
+// CORTEX_CONFIG when set, otherwise DefaultConfigFile if present.
+func configFilePath() (string, bool) {
+	if path := os.Getenv("CORTEX_CONFIG"); path != "" {
+		return path, true
+	}
+	return DefaultConfigFile, false
+}
+
+// readConfigFile parses a YAML config file into the environment variables
+// its settings stand for. Unknown settings and values of the wrong kind are
+// all reported, with their line, in one error.
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if len(document.Content) == 0 {
+		return values, nil
+	}
+
+	var problems []error
+	flattenConfigNode(document.Content[0], "", values, &problems)
+	for index, problem := range problems {
+		problems[index] = fmt.Errorf("%s:%w", path, problem)
+	}
+	return values, errors.Join(problems...)
+}
+
+// flattenConfigNode walks a mapping of the config file, storing each setting
+// under its environment variable and reporting the ones that do not fit the
+// schema.
+func flattenConfigNode(node *yaml.Node, prefix string, values map[string]string, problems *[]error) {
+	if node.Kind != yaml.MappingNode {
+		*problems = append(*problems, fmt.Errorf("%d: %s must be a mapping of settings", node.Line, describeKey(prefix)))
+		return
+	}
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		keyNode, valueNode := node.Content[index], node.Content[index+1]
+		key := keyNode.Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		setting, known := fileSettings[key]
+		if !known {
+			if isSection(key) {
+				flattenConfigNode(valueNode, key, values, problems)
+				continue
+			}
+			message := fmt.Sprintf("%d: unknown setting %q", keyNode.Line, key)
+			if suggestion := closestSetting(key); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			*problems = append(*problems, errors.New(message))
+			continue
+		}
+
+		value, err := settingValue(setting, valueNode)
+		if err != nil {
+			*problems = append(*problems, fmt.Errorf("%d: %s %w", valueNode.Line, key, err))
+			continue
+		}
+		values[setting.env] = value
+	}
+}
+
+// settingValue converts a config file value to the string its environment
+// variable would hold, checking it is of the setting's kind.
+func settingValue(setting fileSetting, node *yaml.Node) (string, error) {
+	if setting.kind == kindList {
+		if node.Kind == yaml.ScalarNode {
+			return node.Value, nil
+		}
+		if node.Kind != yaml.SequenceNode {
+			return "", errors.New("must be a list")
+		}
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode || strings.Contains(item.Value, ",") {
+				return "", errors.New("must be a list of plain values without commas")
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	}
+	if setting.kind == kindLimits && node.Kind != yaml.ScalarNode {
+		return limitsValue(node)
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return "", errors.New("must be a single value")
+	}
+	switch setting.kind {
+	case kindInt:
+		if _, err := strconv.Atoi(node.Value); err != nil {
+			return "", fmt.Errorf("must be a whole number, got %q", node.Value)
+		}
+	case kindBool:
+		switch strings.ToLower(node.Value) {
+		case "true", "yes", "on":
+			return "true", nil
+		case "false", "no", "off":
+			return "false", nil
+		}
+		return "", fmt.Errorf("must be true or false, got %q", node.Value)
+	case kindDuration:
+		if _, err := time.ParseDuration(node.Value); err != nil {
+			return "", fmt.Errorf("must be a duration such as 30s or 24h, got %q", node.Value)
+		}
+	}
+	return node.Value, nil
+}
+
+// limitsValue converts a mapping of plugin IDs to their limits, such as
+// {finance-tracker: {memory_mb: 256}}, to the form of CORTEX_PLUGIN_LIMITS.
+// The limits themselves are checked by validation.
+func limitsValue(node *yaml.Node) (string, error) {
+	if node.Kind != yaml.MappingNode {
+		return "", errors.New("must map plugin IDs to their limits")
+	}
+	plugins := make([]string, 0, len(node.Content)/2)
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		id, limitsNode := node.Content[index].Value, node.Content[index+1]
+		if limitsNode.Kind != yaml.MappingNode {
+			return "", fmt.Errorf("of %s must be a mapping such as {memory_mb: 256}", id)
+		}
+		limits := make([]string, 0, len(limitsNode.Content)/2)
+		for limit := 0; limit+1 < len(limitsNode.Content); limit += 2 {
+			name, value := limitsNode.Content[limit], limitsNode.Content[limit+1]
+			if value.Kind != yaml.ScalarNode || strings.ContainsAny(value.Value, ",;") {
+				return "", fmt.Errorf("of %s: %s must be a single value", id, name.Value)
+			}
+			limits = append(limits, name.Value+"="+value.Value)
+		}
+		plugins = append(plugins, id+":"+strings.Join(limits, ","))
+	}
+	return strings.Join(plugins, ";"), nil
+}
+
+// isSection reports whether key is the parent of settings, such as "tls".
+func isSection(key string) bool {
+	for name := range fileSettings {
+		if strings.HasPrefix(name, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// describeKey names a part of the config file in errors.
+func describeKey(key string) string {
+	if key == "" {
+		return "the config file"
+	}
+	return key
+}
+
+// closestSetting returns the known setting nearest to a misspelled key, or ""
+// when none is close.
+func closestSetting(key string) string {
+	names := make([]string, 0, len(fileSettings))
+	for name := range fileSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if distance := editDistance(key, name); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// fileKeysByEnv inverts fileSettings for the variables values sets.
+func fileKeysByEnv(values map[string]string) map[string]string {
+	keys := make(map[string]string, len(values))
+	for name, setting := range fileSettings {
+		if _, ok := values[setting.env]; ok {
+			keys[setting.env] = name
+		}
+	}
+	return keys
+}
+
+// renameFileProblems rewrites validation errors about settings that came from
+// the config file to name the setting there instead of its environment
+// variable.
+func renameFileProblems(err error, path string) error {
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return err
+	}
+
+	problems := joined.Unwrap()
+	renamed := make([]error, 0, len(problems))
+	for _, problem := range problems {
+		message := problem.Error()
+		env, rest, _ := strings.Cut(message, " ")
+		if key, fromFile := fileKeys[env]; fromFile && os.Getenv(env) == "" {
+			problem = fmt.Errorf("%s (%s in %s) %s", key, env, path, rest)
+		}
+		renamed = append(renamed, problem)
+	}
+	return errors.Join(renamed...)
+}
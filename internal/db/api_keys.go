@@ -0,0 +1,129 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// APIKey is a long-lived credential for scripts and shortcuts.
+// Only a SHA-256 hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	Prefix     string  `json:"prefix"`
+	LastUsedAt *string `json:"last_used_at"`
+	// LastIP and LastUserAgent describe the most recent request made with the key.
+	LastIP        string `json:"last_ip"`
+	LastUserAgent string `json:"last_user_agent"`
+	CreatedAt     string `json:"created_at"`
+}
+
+const apiKeyColumns = "id, name, key_prefix, last_used_at, last_ip, last_user_agent, created_at"
+
+// CreateAPIKey stores a new API key by its hash.
+func (h *HostDB) CreateAPIKey(name, prefix, keyHash string) (*APIKey, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	result, err := h.db.Exec(
+		"INSERT INTO api_keys (name, key_prefix, key_hash, created_at) VALUES (?, ?, ?, ?)",
+		name, prefix, keyHash, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting api key: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("reading api key id: %w", err)
+	}
+
+	row := h.db.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys WHERE id = ?", id)
+	return scanAPIKey(row)
+}
+
+// ListAPIKeys returns all API keys, newest first.
+func (h *HostDB) ListAPIKeys() ([]APIKey, error) {
+	rows, err := h.db.Query("SELECT " + apiKeyColumns + " FROM api_keys ORDER BY created_at DESC, id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("querying api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// HasAPIKeys reports whether any API key exists. Deleted keys are gone, so
+// deleting the last one opens the instance up again.
+func (h *HostDB) HasAPIKeys() (bool, error) {
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS (SELECT 1 FROM api_keys)").Scan(&exists); err != nil {
+		return false, fmt.Errorf("checking for api keys: %w", err)
+	}
+	return exists, nil
+}
+
+// GetAPIKeyByHash looks up an API key by its hash, or returns ErrNotFound.
+func (h *HostDB) GetAPIKeyByHash(keyHash string) (*APIKey, error) {
+	row := h.db.QueryRow("SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?", keyHash)
+	return scanAPIKey(row)
+}
+
+// TouchAPIKey records usage of an API key from the given IP and user agent,
+// throttled to one write per interval unless the IP or user agent changed.
+func (h *HostDB) TouchAPIKey(id int64, interval time.Duration, ip string, userAgent string) error {
+	now := time.Now().UTC()
+	threshold := now.Add(-interval).Format(time.RFC3339)
+
+	_, err := h.db.Exec(
+		`UPDATE api_keys SET last_used_at = ?, last_ip = ?, last_user_agent = ?
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ? OR last_ip != ? OR last_user_agent != ?)`,
+		now.Format(time.RFC3339), ip, userAgent, id, threshold, ip, userAgent,
+	)
+	if err != nil {
+		return fmt.Errorf("updating api key last used: %w", err)
+	}
+	return nil
+}
+
+// DeleteAPIKey permanently removes an API key, or returns ErrNotFound.
+func (h *HostDB) DeleteAPIKey(id int64) error {
+	result, err := h.db.Exec("DELETE FROM api_keys WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting api key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading deleted rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(scanner rowScanner) (*APIKey, error) {
+	var key APIKey
+	if err := scanner.Scan(&key.ID, &key.Name, &key.Prefix, &key.LastUsedAt, &key.LastIP, &key.LastUserAgent, &key.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning api key: %w", err)
+	}
+	return &key, nil
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AttachmentRecord is a plugin's reference to a stored blob. Several records,
+// from one plugin or many, can share the same blob.
+type AttachmentRecord struct {
+	ID          int64
+	PluginID    string
+	SHA256      string
+	Name        string
+	ContentType string
+	Size        int64
+	CreatedAt   string
+}
+
+// AttachmentStats summarizes the attachment store.
+type AttachmentStats struct {
+	// Blobs and StoredBytes count unique contents as stored on disk.
+	Blobs       int64 `json:"blobs"`
+	StoredBytes int64 `json:"stored_bytes"`
+	// Attachments and ReferencedBytes count every reference, as if nothing
+	// were deduplicated; SavedBytes is the difference.
+	Attachments     int64                   `json:"attachments"`
+	ReferencedBytes int64                   `json:"referenced_bytes"`
+	SavedBytes      int64                   `json:"saved_bytes"`
+	SharedBlobs     int64                   `json:"shared_blobs"`
+	Plugins         []PluginAttachmentStats `json:"plugins"`
+}
+
+// PluginAttachmentStats is one plugin's share of the attachment store.
+type PluginAttachmentStats struct {
+	PluginID        string `json:"plugin_id"`
+	Attachments     int64  `json:"attachments"`
+	ReferencedBytes int64  `json:"referenced_bytes"`
+}
+
+const attachmentColumns = `attachments.id, attachments.plugin_id, attachments.sha256, attachments.name,
+	attachments.content_type, attachment_blobs.size, attachments.created_at`
+
+// CreateAttachment records a reference from pluginID to the blob with the
+// given hash, adding the blob if it is new. storedBefore reports whether the
+// blob already existed, i.e. the content was deduplicated.
+func (h *HostDB) CreateAttachment(pluginID, sha256 string, size int64, name, contentType string) (record *AttachmentRecord, storedBefore bool, err error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	transaction, err := h.db.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() { _ = transaction.Rollback() }()
+
+	result, err := transaction.Exec(
+		"INSERT OR IGNORE INTO attachment_blobs (sha256, size, created_at) VALUES (?, ?, ?)",
+		sha256, size, now,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("inserting attachment blob: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, fmt.Errorf("reading inserted blobs: %w", err)
+	}
+
+	result, err = transaction.Exec(
+		"INSERT INTO attachments (plugin_id, sha256, name, content_type, created_at) VALUES (?, ?, ?, ?, ?)",
+		pluginID, sha256, name, contentType, now,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("inserting attachment: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, false, fmt.Errorf("reading attachment id: %w", err)
+	}
+
+	record, err = scanAttachment(transaction.QueryRow(
+		"SELECT "+attachmentColumns+" FROM attachments JOIN attachment_blobs USING (sha256) WHERE attachments.id = ?", id,
+	))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, false, fmt.Errorf("committing attachment: %w", err)
+	}
+	return record, inserted == 0, nil
+}
+
+// GetAttachment returns one of pluginID's attachments, or ErrNotFound.
+func (h *HostDB) GetAttachment(pluginID string, id int64) (*AttachmentRecord, error) {
+	return scanAttachment(h.db.QueryRow(
+		"SELECT "+attachmentColumns+" FROM attachments JOIN attachment_blobs USING (sha256) WHERE attachments.id = ? AND attachments.plugin_id = ?",
+		id, pluginID,
+	))
+}
+
+// DeleteAttachment removes one of pluginID's attachments, or returns
+// ErrNotFound. When it was the last reference to its blob, the blob record is
+// removed too and orphaned is the hash of the content to delete.
+func (h *HostDB) DeleteAttachment(pluginID string, id int64) (orphaned string, err error) {
+	transaction, err := h.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() { _ = transaction.Rollback() }()
+
+	var sha256 string
+	err = transaction.QueryRow("SELECT sha256 FROM attachments WHERE id = ? AND plugin_id = ?", id, pluginID).Scan(&sha256)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("querying attachment: %w", err)
+	}
+
+	if _, err := transaction.Exec("DELETE FROM attachments WHERE id = ?", id); err != nil {
+		return "", fmt.Errorf("deleting attachment: %w", err)
+	}
+
+	result, err := transaction.Exec(
+		"DELETE FROM attachment_blobs WHERE sha256 = ? AND NOT EXISTS (SELECT 1 FROM attachments WHERE sha256 = ?)",
+		sha256, sha256,
+	)
+	if err != nil {
+		return "", fmt.Errorf("deleting attachment blob: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted > 0 {
+		orphaned = sha256
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return "", fmt.Errorf("committing attachment deletion: %w", err)
+	}
+	return orphaned, nil
+}
+
+// GetAttachmentStats summarizes blobs and references across all plugins.
+func (h *HostDB) GetAttachmentStats() (*AttachmentStats, error) {
+	stats := &AttachmentStats{Plugins: []PluginAttachmentStats{}}
+
+	err := h.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(size), 0) FROM attachment_blobs").Scan(&stats.Blobs, &stats.StoredBytes)
+	if err != nil {
+		return nil, fmt.Errorf("querying attachment blobs: %w", err)
+	}
+
+	err = h.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(attachment_blobs.size), 0)
+		FROM attachments JOIN attachment_blobs USING (sha256)
+	`).Scan(&stats.Attachments, &stats.ReferencedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("querying attachment references: %w", err)
+	}
+	stats.SavedBytes = stats.ReferencedBytes - stats.StoredBytes
+
+	err = h.db.QueryRow(`
+		SELECT COUNT(*) FROM (SELECT sha256 FROM attachments GROUP BY sha256 HAVING COUNT(*) > 1)
+	`).Scan(&stats.SharedBlobs)
+	if err != nil {
+		return nil, fmt.Errorf("querying shared attachment blobs: %w", err)
+	}
+
+	rows, err := h.db.Query(`
+		SELECT attachments.plugin_id, COUNT(*), COALESCE(SUM(attachment_blobs.size), 0)
+		FROM attachments JOIN attachment_blobs USING (sha256)
+		GROUP BY attachments.plugin_id
+		ORDER BY attachments.plugin_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying attachments per plugin: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var plugin PluginAttachmentStats
+		if err := rows.Scan(&plugin.PluginID, &plugin.Attachments, &plugin.ReferencedBytes); err != nil {
+			return nil, fmt.Errorf("scanning attachments per plugin: %w", err)
+		}
+		stats.Plugins = append(stats.Plugins, plugin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating attachments per plugin: %w", err)
+	}
+
+	return stats, nil
+}
+
+func scanAttachment(row *sql.Row) (*AttachmentRecord, error) {
+	var record AttachmentRecord
+	err := row.Scan(&record.ID, &record.PluginID, &record.SHA256, &record.Name, &record.ContentType, &record.Size, &record.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning attachment: %w", err)
+	}
+	return &record, nil
+}
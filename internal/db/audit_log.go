@@ -0,0 +1,134 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditEntry records one state-changing request the server handled: who
+// made it, what it changed and how it ended. PayloadHash is the hex SHA-256
+// of the request body, empty for requests without one; bodies themselves
+// are never stored.
+type AuditEntry struct {
+	ID          int64  `json:"id"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	PluginID    string `json:"plugin,omitempty"`
+	PayloadHash string `json:"payload_hash,omitempty"`
+	// Actor is the API key ("api_key:<name>") or device ("device:<name>")
+	// the request authenticated with, or empty for the web UI.
+	Actor     string `json:"actor,omitempty"`
+	IP        string `json:"ip"`
+	Status    int    `json:"status"`
+	RequestID string `json:"request_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// AuditFilter narrows ListAuditEntries. Zero fields match every entry;
+// Before is the ID entries must be older than, for paging.
+type AuditFilter struct {
+	PluginID string
+	Method   string
+	Status   int
+	Since    time.Time
+	Before   int64
+}
+
+const auditColumns = "id, method, path, plugin_id, payload_hash, actor, ip, status, request_id, created_at"
+
+// RecordAuditEntry stores an entry, stamped with the current time unless
+// CreatedAt is set.
+func (h *HostDB) RecordAuditEntry(entry AuditEntry) error {
+	if entry.CreatedAt == "" {
+		entry.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	_, err := h.db.Exec(
+		"INSERT INTO audit_log (method, path, plugin_id, payload_hash, actor, ip, status, request_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.Method, entry.Path, entry.PluginID, entry.PayloadHash, entry.Actor, entry.IP, entry.Status, entry.RequestID, entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns up to limit entries matching filter, newest first.
+func (h *HostDB) ListAuditEntries(filter AuditFilter, limit int) ([]AuditEntry, error) {
+	var conditions []string
+	var arguments []interface{}
+	if filter.PluginID != "" {
+		conditions = append(conditions, "plugin_id = ?")
+		arguments = append(arguments, filter.PluginID)
+	}
+	if filter.Method != "" {
+		conditions = append(conditions, "method = ?")
+		arguments = append(arguments, filter.Method)
+	}
+	if filter.Status != 0 {
+		conditions = append(conditions, "status = ?")
+		arguments = append(arguments, filter.Status)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		arguments = append(arguments, filter.Since.UTC().Format(time.RFC3339))
+	}
+	if filter.Before != 0 {
+		conditions = append(conditions, "id < ?")
+		arguments = append(arguments, filter.Before)
+	}
+
+	query := "SELECT " + auditColumns + " FROM audit_log"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	arguments = append(arguments, limit)
+
+	rows, err := h.db.Query(query, arguments...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.Method,
+			&entry.Path,
+			&entry.PluginID,
+			&entry.PayloadHash,
+			&entry.Actor,
+			&entry.IP,
+			&entry.Status,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteAuditEntriesBefore removes the entries recorded before cutoff and
+// returns how many there were.
+func (h *HostDB) DeleteAuditEntriesBefore(cutoff time.Time) (int64, error) {
+	result, err := h.db.Exec("DELETE FROM audit_log WHERE created_at < ?", cutoff.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("deleting audit entries: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("reading deleted rows: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Device represents a client (phone, laptop, browser) registered against the instance.
+// The raw token is never stored; only its SHA-256 hash is persisted.
+type Device struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	Platform   string  `json:"platform"`
+	LastSeenAt *string `json:"last_seen_at"`
+	LastSyncAt *string `json:"last_sync_at"`
+	// LastIP and LastUserAgent describe the device's most recent request.
+	LastIP        string  `json:"last_ip"`
+	LastUserAgent string  `json:"last_user_agent"`
+	RevokedAt     *string `json:"revoked_at"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+const deviceColumns = "id, name, platform, last_seen_at, last_sync_at, last_ip, last_user_agent, revoked_at, created_at"
+
+// CreateDevice registers a new device with the given token hash.
+func (h *HostDB) CreateDevice(name, platform, tokenHash string) (*Device, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	result, err := h.db.Exec(
+		"INSERT INTO devices (name, platform, token_hash, created_at) VALUES (?, ?, ?, ?)",
+		name, platform, tokenHash, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting device: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("reading device id: %w", err)
+	}
+
+	return h.GetDevice(id)
+}
+
+// ListDevices returns all registered devices, including revoked ones, newest first.
+func (h *HostDB) ListDevices() ([]Device, error) {
+	rows, err := h.db.Query("SELECT " + deviceColumns + " FROM devices ORDER BY created_at DESC, id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("querying devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []Device{}
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// HasDevices reports whether any device can still connect, that is, whether
+// one is registered and not revoked.
+func (h *HostDB) HasDevices() (bool, error) {
+	var exists bool
+	if err := h.db.QueryRow("SELECT EXISTS (SELECT 1 FROM devices WHERE revoked_at IS NULL)").Scan(&exists); err != nil {
+		return false, fmt.Errorf("checking for devices: %w", err)
+	}
+	return exists, nil
+}
+
+// GetDevice returns a single device by ID, or ErrNotFound.
+func (h *HostDB) GetDevice(id int64) (*Device, error) {
+	row := h.db.QueryRow("SELECT "+deviceColumns+" FROM devices WHERE id = ?", id)
+	return scanDevice(row)
+}
+
+// GetDeviceByTokenHash looks up a device by its token hash, or returns ErrNotFound.
+func (h *HostDB) GetDeviceByTokenHash(tokenHash string) (*Device, error) {
+	row := h.db.QueryRow("SELECT "+deviceColumns+" FROM devices WHERE token_hash = ?", tokenHash)
+	return scanDevice(row)
+}
+
+// TouchDevice records activity for a device from the given IP and user agent.
+// To avoid a write on every request, the row is only updated when last_seen_at
+// is older than the given interval or the IP or user agent changed.
+func (h *HostDB) TouchDevice(id int64, interval time.Duration, ip string, userAgent string) error {
+	now := time.Now().UTC()
+	threshold := now.Add(-interval).Format(time.RFC3339)
+
+	_, err := h.db.Exec(
+		`UPDATE devices SET last_seen_at = ?, last_ip = ?, last_user_agent = ?
+		WHERE id = ? AND (last_seen_at IS NULL OR last_seen_at < ? OR last_ip != ? OR last_user_agent != ?)`,
+		now.Format(time.RFC3339), ip, userAgent, id, threshold, ip, userAgent,
+	)
+	if err != nil {
+		return fmt.Errorf("updating device last seen: %w", err)
+	}
+	return nil
+}
+
+// MarkDeviceSynced records a completed sync for a device.
+func (h *HostDB) MarkDeviceSynced(id int64) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if _, err := h.db.Exec("UPDATE devices SET last_sync_at = ?, last_seen_at = ? WHERE id = ?", now, now, id); err != nil {
+		return fmt.Errorf("updating device last sync: %w", err)
+	}
+	return nil
+}
+
+// RevokeDevice marks a device token as revoked. Revoking an already revoked
+// device keeps the original revocation timestamp.
+func (h *HostDB) RevokeDevice(id int64) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	result, err := h.db.Exec("UPDATE devices SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?", now, id)
+	if err != nil {
+		return fmt.Errorf("revoking device: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading revoked rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDevice(scanner rowScanner) (*Device, error) {
+	var device Device
+	if err := scanner.Scan(
+		&device.ID,
+		&device.Name,
+		&device.Platform,
+		&device.LastSeenAt,
+		&device.LastSyncAt,
+		&device.LastIP,
+		&device.LastUserAgent,
+		&device.RevokedAt,
+		&device.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning device: %w", err)
+	}
+	return &device, nil
+}
@@ -2,12 +2,19 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"path/filepath"
 
 	_ "modernc.org/sqlite"
 )
 
+// ErrNotFound is returned when a requested host record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrExists is returned when a record to be put back has been recreated since.
+var ErrExists = errors.New("already exists")
+
 // WidgetLayout represents a widget's position and size on the dashboard grid.
 type WidgetLayout struct {
 	ID        int64  `json:"id"`
@@ -23,6 +30,9 @@ type WidgetLayout struct {
 // HostDB manages the host-level SQLite database.
 type HostDB struct {
 	db *sql.DB
+
+	// runID is the host_runs row for the current process, set by StartRun.
+	runID int64
 }
 
 // NewHostDB opens the host database and runs migrations.
@@ -66,9 +76,221 @@ func (h *HostDB) migrate() error {
 
 		CREATE INDEX IF NOT EXISTS idx_dashboard_layouts_widget_id
 			ON dashboard_layouts(widget_id);
+
+		CREATE TABLE IF NOT EXISTS devices (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			platform TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			last_seen_at TEXT,
+			last_sync_at TEXT,
+			last_ip TEXT NOT NULL DEFAULT '',
+			last_user_agent TEXT NOT NULL DEFAULT '',
+			revoked_at TEXT,
+			created_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE TABLE IF NOT EXISTS notifications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source TEXT NOT NULL,
+			title TEXT NOT NULL,
+			body TEXT NOT NULL DEFAULT '',
+			urgent INTEGER NOT NULL DEFAULT 0,
+			read_at TEXT,
+			digested_at TEXT,
+			created_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_notifications_pending
+			ON notifications(urgent, digested_at);
+
+		CREATE TABLE IF NOT EXISTS digest_settings (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			enabled INTEGER NOT NULL DEFAULT 0,
+			frequency TEXT NOT NULL DEFAULT 'daily',
+			time_of_day TEXT NOT NULL DEFAULT '08:00',
+			weekday INTEGER NOT NULL DEFAULT 1,
+			webhook_url TEXT NOT NULL DEFAULT '',
+			email TEXT NOT NULL DEFAULT '',
+			ntfy_url TEXT NOT NULL DEFAULT '',
+			gotify_url TEXT NOT NULL DEFAULT '',
+			gotify_token TEXT NOT NULL DEFAULT '',
+			last_sent_at TEXT,
+			updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		INSERT OR IGNORE INTO digest_settings (id) VALUES (1);
+
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			key_prefix TEXT NOT NULL,
+			key_hash TEXT NOT NULL UNIQUE,
+			last_used_at TEXT,
+			last_ip TEXT NOT NULL DEFAULT '',
+			last_user_agent TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE TABLE IF NOT EXISTS plugin_settings (
+			plugin_id TEXT PRIMARY KEY,
+			settings TEXT NOT NULL DEFAULT '{}',
+			plugin_version TEXT NOT NULL,
+			updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE TABLE IF NOT EXISTS host_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			started_at TEXT NOT NULL,
+			last_heartbeat_at TEXT NOT NULL,
+			stopped_at TEXT,
+			shutdown TEXT NOT NULL DEFAULT 'running'
+		);
+
+		CREATE TABLE IF NOT EXISTS plugin_loads (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL REFERENCES host_runs(id) ON DELETE CASCADE,
+			plugin_id TEXT NOT NULL,
+			loaded_at TEXT NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_plugin_loads_run_id
+			ON plugin_loads(run_id);
+
+		CREATE TABLE IF NOT EXISTS plugin_installs (
+			plugin_id TEXT PRIMARY KEY,
+			installed_at TEXT NOT NULL,
+			last_loaded_at TEXT,
+			version TEXT NOT NULL DEFAULT '',
+			previous_version TEXT NOT NULL DEFAULT '',
+			upgraded_at TEXT,
+			migration_status TEXT NOT NULL DEFAULT '',
+			migration_error TEXT NOT NULL DEFAULT '',
+			migrated_at TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS attachment_blobs (
+			sha256 TEXT PRIMARY KEY,
+			size INTEGER NOT NULL,
+			created_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE TABLE IF NOT EXISTS attachments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			plugin_id TEXT NOT NULL,
+			sha256 TEXT NOT NULL REFERENCES attachment_blobs(sha256),
+			name TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			created_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_attachments_sha256
+			ON attachments(sha256);
+
+		CREATE INDEX IF NOT EXISTS idx_attachments_plugin_id
+			ON attachments(plugin_id);
+
+		CREATE TABLE IF NOT EXISTS plugin_secrets (
+			name TEXT PRIMARY KEY,
+			value BLOB NOT NULL,
+			created_at TEXT NOT NULL DEFAULT (datetime('now')),
+			updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE TABLE IF NOT EXISTS host_secrets (
+			name TEXT PRIMARY KEY,
+			value BLOB NOT NULL,
+			created_at TEXT NOT NULL DEFAULT (datetime('now'))
+		);
+
+		CREATE TABLE IF NOT EXISTS widget_snapshots (
+			id TEXT PRIMARY KEY,
+			plugin_id TEXT NOT NULL,
+			slot TEXT NOT NULL,
+			format TEXT NOT NULL,
+			data TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			expires_at TEXT NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_widget_snapshots_expires_at
+			ON widget_snapshots(expires_at);
+
+		CREATE TABLE IF NOT EXISTS plugin_values (
+			plugin_id TEXT NOT NULL,
+			key TEXT NOT NULL,
+			value BLOB NOT NULL,
+			updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+			PRIMARY KEY (plugin_id, key)
+		);
+
+		CREATE TABLE IF NOT EXISTS undo_actions (
+			id TEXT PRIMARY KEY,
+			kind TEXT NOT NULL,
+			target TEXT NOT NULL,
+			payload TEXT NOT NULL DEFAULT '{}',
+			created_at TEXT NOT NULL,
+			expires_at TEXT NOT NULL,
+			undone_at TEXT
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_undo_actions_expires_at
+			ON undo_actions(expires_at);
+
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			method TEXT NOT NULL,
+			path TEXT NOT NULL,
+			plugin_id TEXT NOT NULL DEFAULT '',
+			payload_hash TEXT NOT NULL DEFAULT '',
+			actor TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			status INTEGER NOT NULL,
+			request_id TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_audit_log_created_at
+			ON audit_log(created_at);
 	`
-	_, err := h.db.Exec(query)
-	return err
+	if _, err := h.db.Exec(query); err != nil {
+		return err
+	}
+
+	// Columns added after their table was first released. CREATE TABLE IF NOT
+	// EXISTS leaves existing tables alone, so older databases get them here.
+	for _, column := range []struct{ table, name, definition string }{
+		{"digest_settings", "ntfy_url", "TEXT NOT NULL DEFAULT ''"},
+		{"digest_settings", "gotify_url", "TEXT NOT NULL DEFAULT ''"},
+		{"digest_settings", "gotify_token", "TEXT NOT NULL DEFAULT ''"},
+		{"plugin_loads", "load_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"plugin_loads", "warmup_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"devices", "last_ip", "TEXT NOT NULL DEFAULT ''"},
+		{"devices", "last_user_agent", "TEXT NOT NULL DEFAULT ''"},
+		{"api_keys", "last_ip", "TEXT NOT NULL DEFAULT ''"},
+		{"api_keys", "last_user_agent", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		if err := h.addColumnIfMissing(column.table, column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column to a table created by an earlier version.
+func (h *HostDB) addColumnIfMissing(table string, column string, definition string) error {
+	var count int
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count); err != nil {
+		return fmt.Errorf("inspecting %s columns: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := h.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("adding %s.%s: %w", table, column, err)
+	}
+	return nil
 }
 
 // GetDashboardLayouts returns all widget layouts from the dashboard.
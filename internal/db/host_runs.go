@@ -0,0 +1,238 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Shutdown states recorded for each host run.
+const (
+	ShutdownRunning = "running"
+	ShutdownClean   = "clean"
+	ShutdownCrash   = "crash"
+)
+
+// HostRun is one lifetime of the host process, from start to shutdown.
+// For crashed runs StoppedAt is the last heartbeat, the best known time of death.
+type HostRun struct {
+	ID              int64          `json:"id"`
+	StartedAt       string         `json:"started_at"`
+	LastHeartbeatAt string         `json:"last_heartbeat_at"`
+	StoppedAt       *string        `json:"stopped_at"`
+	Shutdown        string         `json:"shutdown"`
+	UptimeSeconds   int64          `json:"uptime_seconds"`
+	PluginRestarts  map[string]int `json:"plugin_restarts"`
+	// PluginLoadTimes is the duration of each plugin's latest load in the run.
+	PluginLoadTimes map[string]PluginLoadTime `json:"plugin_load_times"`
+}
+
+// PluginLoadTime is how long a plugin load took in total, and how much of it
+// the plugin's warm-up hook spent.
+type PluginLoadTime struct {
+	LoadMS   int64 `json:"load_ms"`
+	WarmupMS int64 `json:"warmup_ms"`
+}
+
+// StartRun records a new host run. Any run still marked as running belongs to
+// a previous process that never shut down cleanly, so it is marked as a crash.
+func (h *HostDB) StartRun() (int64, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if _, err := h.db.Exec(
+		"UPDATE host_runs SET shutdown = ?, stopped_at = last_heartbeat_at WHERE shutdown = ?",
+		ShutdownCrash, ShutdownRunning,
+	); err != nil {
+		return 0, fmt.Errorf("marking crashed runs: %w", err)
+	}
+
+	result, err := h.db.Exec(
+		"INSERT INTO host_runs (started_at, last_heartbeat_at, shutdown) VALUES (?, ?, ?)",
+		now, now, ShutdownRunning,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("inserting host run: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("reading host run id: %w", err)
+	}
+
+	h.runID = id
+	return id, nil
+}
+
+// HeartbeatRun refreshes the current run's heartbeat.
+func (h *HostDB) HeartbeatRun() error {
+	if h.runID == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := h.db.Exec("UPDATE host_runs SET last_heartbeat_at = ? WHERE id = ?", now, h.runID); err != nil {
+		return fmt.Errorf("updating host run heartbeat: %w", err)
+	}
+	return nil
+}
+
+// EndRun marks the current run as cleanly stopped.
+func (h *HostDB) EndRun() error {
+	if h.runID == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := h.db.Exec(
+		"UPDATE host_runs SET shutdown = ?, stopped_at = ?, last_heartbeat_at = ? WHERE id = ?",
+		ShutdownClean, now, now, h.runID,
+	); err != nil {
+		return fmt.Errorf("ending host run: %w", err)
+	}
+	return nil
+}
+
+// RecordPluginLoad records that a plugin was (re)started during the current
+// run, with how long the load and its warm-up took.
+func (h *HostDB) RecordPluginLoad(pluginID string, loadDuration time.Duration, warmupDuration time.Duration) error {
+	if h.runID == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := h.db.Exec(
+		"INSERT INTO plugin_loads (run_id, plugin_id, loaded_at, load_ms, warmup_ms) VALUES (?, ?, ?, ?, ?)",
+		h.runID, pluginID, now, loadDuration.Milliseconds(), warmupDuration.Milliseconds(),
+	); err != nil {
+		return fmt.Errorf("recording plugin load: %w", err)
+	}
+	return nil
+}
+
+// CurrentRunID returns the ID of the current host run, or 0 before StartRun.
+func (h *HostDB) CurrentRunID() int64 {
+	return h.runID
+}
+
+// ListHostRuns returns the most recent host runs, newest first, with per-plugin
+// restart counts and load times. The first load of a plugin in a run is not a
+// restart.
+func (h *HostDB) ListHostRuns(limit int) ([]HostRun, error) {
+	rows, err := h.db.Query(`
+		SELECT id, started_at, last_heartbeat_at, stopped_at, shutdown
+		FROM host_runs
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying host runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []HostRun{}
+	runIndex := map[int64]int{}
+	for rows.Next() {
+		var run HostRun
+		if err := rows.Scan(&run.ID, &run.StartedAt, &run.LastHeartbeatAt, &run.StoppedAt, &run.Shutdown); err != nil {
+			return nil, fmt.Errorf("scanning host run: %w", err)
+		}
+		run.PluginRestarts = map[string]int{}
+		run.PluginLoadTimes = map[string]PluginLoadTime{}
+		run.UptimeSeconds = runUptime(run)
+		runIndex[run.ID] = len(runs)
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating host runs: %w", err)
+	}
+
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	loadRows, err := h.db.Query(`
+		SELECT run_id, plugin_id, COUNT(*) - 1
+		FROM plugin_loads
+		WHERE run_id >= ?
+		GROUP BY run_id, plugin_id
+		HAVING COUNT(*) > 1
+	`, runs[len(runs)-1].ID)
+	if err != nil {
+		return nil, fmt.Errorf("querying plugin restarts: %w", err)
+	}
+	defer loadRows.Close()
+
+	for loadRows.Next() {
+		var runID int64
+		var pluginID string
+		var restarts int
+		if err := loadRows.Scan(&runID, &pluginID, &restarts); err != nil {
+			return nil, fmt.Errorf("scanning plugin restarts: %w", err)
+		}
+		if index, ok := runIndex[runID]; ok {
+			runs[index].PluginRestarts[pluginID] = restarts
+		}
+	}
+
+	if err := loadRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating plugin restarts: %w", err)
+	}
+
+	if err := h.addPluginLoadTimes(runs, runIndex); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// addPluginLoadTimes fills in the duration of each plugin's latest load in runs.
+func (h *HostDB) addPluginLoadTimes(runs []HostRun, runIndex map[int64]int) error {
+	oldestRunID := runs[len(runs)-1].ID
+	rows, err := h.db.Query(`
+		SELECT run_id, plugin_id, load_ms, warmup_ms
+		FROM plugin_loads
+		WHERE id IN (
+			SELECT MAX(id) FROM plugin_loads
+			WHERE run_id >= ?
+			GROUP BY run_id, plugin_id
+		)
+	`, oldestRunID)
+	if err != nil {
+		return fmt.Errorf("querying plugin load times: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var runID int64
+		var pluginID string
+		var loadTime PluginLoadTime
+		if err := rows.Scan(&runID, &pluginID, &loadTime.LoadMS, &loadTime.WarmupMS); err != nil {
+			return fmt.Errorf("scanning plugin load time: %w", err)
+		}
+		if index, ok := runIndex[runID]; ok {
+			runs[index].PluginLoadTimes[pluginID] = loadTime
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating plugin load times: %w", err)
+	}
+	return nil
+}
+
+// runUptime returns how long a run lasted, or has lasted so far if still running.
+func runUptime(run HostRun) int64 {
+	started, err := time.Parse(time.RFC3339, run.StartedAt)
+	if err != nil {
+		return 0
+	}
+
+	ended := time.Now().UTC()
+	if run.StoppedAt != nil {
+		if stopped, err := time.Parse(time.RFC3339, *run.StoppedAt); err == nil {
+			ended = stopped
+		}
+	}
+
+	return int64(ended.Sub(started).Seconds())
+}
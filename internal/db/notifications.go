@@ -0,0 +1,216 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Notification is a message shown in the notification center.
+// Non-urgent notifications are batched into the digest; urgent ones are delivered immediately.
+type Notification struct {
+	ID         int64   `json:"id"`
+	Source     string  `json:"source"`
+	Title      string  `json:"title"`
+	Body       string  `json:"body"`
+	Urgent     bool    `json:"urgent"`
+	ReadAt     *string `json:"read_at"`
+	DigestedAt *string `json:"digested_at"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// DigestSettings configures when and where the notification digest is delivered.
+// Frequency is "daily" or "weekly"; Weekday (0 = Sunday) only applies to weekly digests.
+type DigestSettings struct {
+	Enabled    bool   `json:"enabled"`
+	Frequency  string `json:"frequency"`
+	TimeOfDay  string `json:"time_of_day"`
+	Weekday    int    `json:"weekday"`
+	WebhookURL string `json:"webhook_url"`
+	Email      string `json:"email"`
+	// NtfyURL is a full topic URL, such as https://ntfy.sh/my-topic.
+	NtfyURL     string  `json:"ntfy_url"`
+	GotifyURL   string  `json:"gotify_url"`
+	GotifyToken string  `json:"gotify_token"`
+	LastSentAt  *string `json:"last_sent_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+const notificationColumns = "id, source, title, body, urgent, read_at, digested_at, created_at"
+
+// CreateNotification stores a notification. When digested is true the notification
+// is stored as already batched, which is how the digest summary itself is recorded.
+func (h *HostDB) CreateNotification(notification Notification, digested bool) (*Notification, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	var digestedAt interface{}
+	if digested {
+		digestedAt = now
+	}
+
+	result, err := h.db.Exec(
+		"INSERT INTO notifications (source, title, body, urgent, digested_at, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		notification.Source, notification.Title, notification.Body, notification.Urgent, digestedAt, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting notification: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("reading notification id: %w", err)
+	}
+
+	row := h.db.QueryRow("SELECT "+notificationColumns+" FROM notifications WHERE id = ?", id)
+	return scanNotification(row)
+}
+
+// ListNotifications returns the most recent notifications, newest first.
+func (h *HostDB) ListNotifications(unreadOnly bool, limit int) ([]Notification, error) {
+	query := "SELECT " + notificationColumns + " FROM notifications"
+	if unreadOnly {
+		query += " WHERE read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+
+	return h.queryNotifications(query, limit)
+}
+
+// ListPendingDigestNotifications returns non-urgent notifications not yet included in a digest.
+func (h *HostDB) ListPendingDigestNotifications() ([]Notification, error) {
+	query := "SELECT " + notificationColumns + " FROM notifications WHERE urgent = 0 AND digested_at IS NULL ORDER BY created_at, id"
+	return h.queryNotifications(query)
+}
+
+// MarkNotificationRead marks a notification as read, or returns ErrNotFound.
+func (h *HostDB) MarkNotificationRead(id int64) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	result, err := h.db.Exec("UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ?", now, id)
+	if err != nil {
+		return fmt.Errorf("marking notification read: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading updated rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkNotificationsDigested flags the given notifications as included in a digest.
+func (h *HostDB) MarkNotificationsDigested(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []interface{}{time.Now().UTC().Format(time.RFC3339)}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	if _, err := h.db.Exec("UPDATE notifications SET digested_at = ? WHERE id IN ("+placeholders+")", args...); err != nil {
+		return fmt.Errorf("marking notifications digested: %w", err)
+	}
+	return nil
+}
+
+// GetDigestSettings returns the digest configuration.
+func (h *HostDB) GetDigestSettings() (*DigestSettings, error) {
+	var settings DigestSettings
+	err := h.db.QueryRow(`
+		SELECT enabled, frequency, time_of_day, weekday, webhook_url, email, ntfy_url, gotify_url, gotify_token,
+			last_sent_at, updated_at
+		FROM digest_settings WHERE id = 1
+	`).Scan(
+		&settings.Enabled,
+		&settings.Frequency,
+		&settings.TimeOfDay,
+		&settings.Weekday,
+		&settings.WebhookURL,
+		&settings.Email,
+		&settings.NtfyURL,
+		&settings.GotifyURL,
+		&settings.GotifyToken,
+		&settings.LastSentAt,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying digest settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// SaveDigestSettings updates the digest configuration. LastSentAt is not modified.
+func (h *HostDB) SaveDigestSettings(settings DigestSettings) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err := h.db.Exec(`
+		UPDATE digest_settings
+		SET enabled = ?, frequency = ?, time_of_day = ?, weekday = ?, webhook_url = ?, email = ?,
+			ntfy_url = ?, gotify_url = ?, gotify_token = ?, updated_at = ?
+		WHERE id = 1
+	`, settings.Enabled, settings.Frequency, settings.TimeOfDay, settings.Weekday, settings.WebhookURL, settings.Email,
+		settings.NtfyURL, settings.GotifyURL, settings.GotifyToken, now)
+	if err != nil {
+		return fmt.Errorf("saving digest settings: %w", err)
+	}
+	return nil
+}
+
+// SetDigestLastSent records when the last digest was delivered.
+func (h *HostDB) SetDigestLastSent(sentAt time.Time) error {
+	if _, err := h.db.Exec("UPDATE digest_settings SET last_sent_at = ? WHERE id = 1", sentAt.UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("updating digest last sent: %w", err)
+	}
+	return nil
+}
+
+func (h *HostDB) queryNotifications(query string, args ...interface{}) ([]Notification, error) {
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func scanNotification(scanner rowScanner) (*Notification, error) {
+	var notification Notification
+	if err := scanner.Scan(
+		&notification.ID,
+		&notification.Source,
+		&notification.Title,
+		&notification.Body,
+		&notification.Urgent,
+		&notification.ReadAt,
+		&notification.DigestedAt,
+		&notification.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning notification: %w", err)
+	}
+	return &notification, nil
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PluginInstall is what the host remembers about a plugin across restarts.
+// Version is the version of its last successful load and PreviousVersion the
+// one before the latest upgrade, if any. MigrationStatus is the outcome of
+// its latest migrations: "applied", "failed" (with MigrationError) or
+// "not_required" for plugins without a database.
+type PluginInstall struct {
+	PluginID        string  `json:"plugin_id"`
+	InstalledAt     string  `json:"installed_at"`
+	LastLoadedAt    *string `json:"last_loaded_at"`
+	Version         string  `json:"version"`
+	PreviousVersion string  `json:"previous_version,omitempty"`
+	UpgradedAt      *string `json:"upgraded_at"`
+	MigrationStatus string  `json:"migration_status"`
+	MigrationError  string  `json:"migration_error,omitempty"`
+	MigratedAt      *string `json:"migrated_at"`
+}
+
+// LastPluginVersion returns the version of the plugin's last successful load.
+// found is false until the plugin has loaded once.
+func (h *HostDB) LastPluginVersion(pluginID string) (string, bool, error) {
+	var version string
+	err := h.db.QueryRow(
+		"SELECT version FROM plugin_installs WHERE plugin_id = ? AND last_loaded_at IS NOT NULL",
+		pluginID,
+	).Scan(&version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("querying plugin version: %w", err)
+	}
+	return version, true, nil
+}
+
+// RecordPluginMigration stores the outcome of a plugin's migrations,
+// recording the plugin as installed the first time it is seen.
+func (h *HostDB) RecordPluginMigration(pluginID string, status string, message string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err := h.db.Exec(`
+		INSERT INTO plugin_installs (plugin_id, installed_at, migration_status, migration_error, migrated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(plugin_id) DO UPDATE SET
+			migration_status = excluded.migration_status,
+			migration_error = excluded.migration_error,
+			migrated_at = excluded.migrated_at
+	`, pluginID, now, status, message, now)
+	if err != nil {
+		return fmt.Errorf("recording plugin migration: %w", err)
+	}
+	return nil
+}
+
+// RecordPluginStart stores a successful load of the given plugin version.
+// Loading a version other than the last one seen records an upgrade.
+func (h *HostDB) RecordPluginStart(pluginID string, version string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err := h.db.Exec(`
+		INSERT INTO plugin_installs (plugin_id, installed_at, last_loaded_at, version)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(plugin_id) DO UPDATE SET
+			previous_version = CASE
+				WHEN plugin_installs.last_loaded_at IS NOT NULL AND plugin_installs.version != excluded.version
+				THEN plugin_installs.version ELSE plugin_installs.previous_version END,
+			upgraded_at = CASE
+				WHEN plugin_installs.last_loaded_at IS NOT NULL AND plugin_installs.version != excluded.version
+				THEN excluded.last_loaded_at ELSE plugin_installs.upgraded_at END,
+			last_loaded_at = excluded.last_loaded_at,
+			version = excluded.version
+	`, pluginID, now, now, version)
+	if err != nil {
+		return fmt.Errorf("recording plugin start: %w", err)
+	}
+	return nil
+}
+
+// ListPluginInstalls returns what the host remembers about every plugin it
+// has seen, by plugin ID.
+func (h *HostDB) ListPluginInstalls() (map[string]PluginInstall, error) {
+	rows, err := h.db.Query(`
+		SELECT plugin_id, installed_at, last_loaded_at, version, previous_version,
+		       upgraded_at, migration_status, migration_error, migrated_at
+		FROM plugin_installs
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying plugin installs: %w", err)
+	}
+	defer rows.Close()
+
+	installs := make(map[string]PluginInstall)
+	for rows.Next() {
+		var install PluginInstall
+		var lastLoadedAt, upgradedAt, migratedAt sql.NullString
+		if err := rows.Scan(
+			&install.PluginID, &install.InstalledAt, &lastLoadedAt, &install.Version, &install.PreviousVersion,
+			&upgradedAt, &install.MigrationStatus, &install.MigrationError, &migratedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning plugin install: %w", err)
+		}
+		if lastLoadedAt.Valid {
+			install.LastLoadedAt = &lastLoadedAt.String
+		}
+		if upgradedAt.Valid {
+			install.UpgradedAt = &upgradedAt.String
+		}
+		if migratedAt.Valid {
+			install.MigratedAt = &migratedAt.String
+		}
+		installs[install.PluginID] = install
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating plugin installs: %w", err)
+	}
+	return installs, nil
+}
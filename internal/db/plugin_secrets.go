@@ -0,0 +1,111 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PluginSecret describes a secret in the secret store plugin settings refer
+// to. Its value is never part of it; see GetPluginSecret.
+type PluginSecret struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ListPluginSecrets returns the stored secrets by name, without their values.
+func (h *HostDB) ListPluginSecrets() ([]PluginSecret, error) {
+	rows, err := h.db.Query("SELECT name, created_at, updated_at FROM plugin_secrets ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("querying plugin secrets: %w", err)
+	}
+	defer rows.Close()
+
+	secrets := []PluginSecret{}
+	for rows.Next() {
+		var secret PluginSecret
+		if err := rows.Scan(&secret.Name, &secret.CreatedAt, &secret.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning plugin secret: %w", err)
+		}
+		secrets = append(secrets, secret)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating plugin secrets: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// GetPluginSecret returns the encrypted value stored under name, or ErrNotFound.
+func (h *HostDB) GetPluginSecret(name string) ([]byte, error) {
+	var value []byte
+	if err := h.db.QueryRow("SELECT value FROM plugin_secrets WHERE name = ?", name).Scan(&value); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("querying plugin secret: %w", err)
+	}
+	return value, nil
+}
+
+// SavePluginSecret stores the encrypted value of a secret, replacing any value
+// already stored under name.
+func (h *HostDB) SavePluginSecret(name string, value []byte) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err := h.db.Exec(`
+		INSERT INTO plugin_secrets (name, value, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at
+	`, name, value, now, now)
+	if err != nil {
+		return fmt.Errorf("saving plugin secret: %w", err)
+	}
+	return nil
+}
+
+// RestorePluginSecret puts back the encrypted value of a deleted secret, or
+// returns ErrExists if a secret has been saved under name since.
+func (h *HostDB) RestorePluginSecret(name string, value []byte) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	result, err := h.db.Exec(`
+		INSERT INTO plugin_secrets (name, value, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO NOTHING
+	`, name, value, now, now)
+	if err != nil {
+		return fmt.Errorf("restoring plugin secret: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading restored rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+// DeletePluginSecret removes a secret, or returns ErrNotFound.
+func (h *HostDB) DeletePluginSecret(name string) error {
+	result, err := h.db.Exec("DELETE FROM plugin_secrets WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("deleting plugin secret: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading deleted rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
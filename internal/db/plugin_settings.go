@@ -0,0 +1,86 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetPluginSettings returns a plugin's stored settings JSON and the plugin
+// version that wrote them. found is false when nothing has been stored yet.
+func (h *HostDB) GetPluginSettings(pluginID string) ([]byte, string, bool, error) {
+	var settings, version string
+
+	err := h.db.QueryRow(
+		"SELECT settings, plugin_version FROM plugin_settings WHERE plugin_id = ?",
+		pluginID,
+	).Scan(&settings, &version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", false, nil
+		}
+		return nil, "", false, fmt.Errorf("querying plugin settings: %w", err)
+	}
+
+	return []byte(settings), version, true, nil
+}
+
+// SavePluginSettings stores a plugin's settings JSON, tagged with the plugin version.
+func (h *HostDB) SavePluginSettings(pluginID string, settings []byte, version string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err := h.db.Exec(`
+		INSERT INTO plugin_settings (plugin_id, settings, plugin_version, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(plugin_id) DO UPDATE SET
+			settings = excluded.settings,
+			plugin_version = excluded.plugin_version,
+			updated_at = excluded.updated_at
+	`, pluginID, string(settings), version, now)
+	if err != nil {
+		return fmt.Errorf("saving plugin settings: %w", err)
+	}
+	return nil
+}
+
+// RestorePluginSettings puts back wiped settings, or returns ErrExists if the
+// plugin's settings have been saved since.
+func (h *HostDB) RestorePluginSettings(pluginID string, settings []byte, version string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	result, err := h.db.Exec(`
+		INSERT INTO plugin_settings (plugin_id, settings, plugin_version, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(plugin_id) DO NOTHING
+	`, pluginID, string(settings), version, now)
+	if err != nil {
+		return fmt.Errorf("restoring plugin settings: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading restored rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+// DeletePluginSettings removes a plugin's stored settings, or returns ErrNotFound.
+func (h *HostDB) DeletePluginSettings(pluginID string) error {
+	result, err := h.db.Exec("DELETE FROM plugin_settings WHERE plugin_id = ?", pluginID)
+	if err != nil {
+		return fmt.Errorf("deleting plugin settings: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading deleted rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
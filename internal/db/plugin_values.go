@@ -0,0 +1,77 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PluginValue returns the value a plugin stored under key. found is false when
+// there is none. It implements plugin.ValueStore.
+func (h *HostDB) PluginValue(pluginID string, key string) ([]byte, bool, error) {
+	var value []byte
+	err := h.db.QueryRow("SELECT value FROM plugin_values WHERE plugin_id = ? AND key = ?", pluginID, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("querying plugin value: %w", err)
+	}
+	return value, true, nil
+}
+
+// SetPluginValue stores a value under key for a plugin, replacing any value
+// already stored there.
+func (h *HostDB) SetPluginValue(pluginID string, key string, value []byte) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err := h.db.Exec(`
+		INSERT INTO plugin_values (plugin_id, key, value, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(plugin_id, key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at
+	`, pluginID, key, value, now)
+	if err != nil {
+		return fmt.Errorf("saving plugin value: %w", err)
+	}
+	return nil
+}
+
+// DeletePluginValue removes the value a plugin stored under key. Deleting a
+// key that holds nothing is not an error.
+func (h *HostDB) DeletePluginValue(pluginID string, key string) error {
+	if _, err := h.db.Exec("DELETE FROM plugin_values WHERE plugin_id = ? AND key = ?", pluginID, key); err != nil {
+		return fmt.Errorf("deleting plugin value: %w", err)
+	}
+	return nil
+}
+
+// PluginKeys returns the keys a plugin stored values under that start with
+// prefix, in order.
+func (h *HostDB) PluginKeys(pluginID string, prefix string) ([]string, error) {
+	rows, err := h.db.Query(
+		"SELECT key FROM plugin_values WHERE plugin_id = ? AND substr(key, 1, length(?)) = ? ORDER BY key",
+		pluginID, prefix, prefix,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying plugin keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("scanning plugin key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating plugin keys: %w", err)
+	}
+
+	return keys, nil
+}
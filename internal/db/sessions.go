@@ -0,0 +1,112 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// SessionDevice marks a session held by a registered device's token.
+	SessionDevice = "device"
+	// SessionAPIKey marks a session held by an API key.
+	SessionAPIKey = "api_key"
+)
+
+// Session is a credential that can currently reach the instance: a device that
+// has not been revoked, or an API key.
+type Session struct {
+	Kind           string  `json:"kind"`
+	CredentialID   int64   `json:"credential_id"`
+	Name           string  `json:"name"`
+	IP             string  `json:"ip"`
+	UserAgent      string  `json:"user_agent"`
+	LastActivityAt *string `json:"last_activity_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// ListSessions returns the active sessions, most recently active first.
+// Sessions never used sort last.
+func (h *HostDB) ListSessions() ([]Session, error) {
+	rows, err := h.db.Query(`
+		SELECT kind, id, name, last_ip, last_user_agent, last_activity_at, created_at FROM (
+			SELECT ? AS kind, id, name, last_ip, last_user_agent, last_seen_at AS last_activity_at, created_at
+			FROM devices WHERE revoked_at IS NULL
+			UNION ALL
+			SELECT ? AS kind, id, name, last_ip, last_user_agent, last_used_at AS last_activity_at, created_at
+			FROM api_keys
+		)
+		ORDER BY last_activity_at IS NULL, last_activity_at DESC, created_at DESC, kind, id DESC`,
+		SessionDevice, SessionAPIKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		if err := rows.Scan(
+			&session.Kind,
+			&session.CredentialID,
+			&session.Name,
+			&session.IP,
+			&session.UserAgent,
+			&session.LastActivityAt,
+			&session.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeAllSessions revokes every device and deletes every API key except the
+// session identified by keepKind and keepID, which may be empty to revoke them
+// all. It returns how many sessions were revoked.
+func (h *HostDB) RevokeAllSessions(keepKind string, keepID int64) (int64, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	keepDevice, keepAPIKey := int64(0), int64(0)
+	switch keepKind {
+	case SessionDevice:
+		keepDevice = keepID
+	case SessionAPIKey:
+		keepAPIKey = keepID
+	}
+
+	transaction, err := h.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	devices, err := transaction.Exec("UPDATE devices SET revoked_at = ? WHERE revoked_at IS NULL AND id != ?", now, keepDevice)
+	if err != nil {
+		return 0, fmt.Errorf("revoking devices: %w", err)
+	}
+	revokedDevices, err := devices.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("reading revoked rows: %w", err)
+	}
+
+	keys, err := transaction.Exec("DELETE FROM api_keys WHERE id != ?", keepAPIKey)
+	if err != nil {
+		return 0, fmt.Errorf("deleting api keys: %w", err)
+	}
+	deletedKeys, err := keys.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("reading deleted rows: %w", err)
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return revokedDevices + deletedKeys, nil
+}
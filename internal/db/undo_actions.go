@@ -0,0 +1,158 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UndoAction is a destructive admin action recorded so it can be reversed
+// until ExpiresAt. Payload is the JSON the action needs to be put back, such
+// as the settings a wipe removed; it is never returned by the API.
+type UndoAction struct {
+	ID        string  `json:"id"`
+	Kind      string  `json:"kind"`
+	Target    string  `json:"target"`
+	Payload   string  `json:"-"`
+	CreatedAt string  `json:"created_at"`
+	ExpiresAt string  `json:"expires_at"`
+	UndoneAt  *string `json:"undone_at,omitempty"`
+}
+
+const undoActionColumns = "id, kind, target, payload, created_at, expires_at, undone_at"
+
+// CreateUndoAction records a reversible action.
+func (h *HostDB) CreateUndoAction(action UndoAction) error {
+	_, err := h.db.Exec(
+		"INSERT INTO undo_actions ("+undoActionColumns+") VALUES (?, ?, ?, ?, ?, ?, NULL)",
+		action.ID, action.Kind, action.Target, action.Payload, action.CreatedAt, action.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting undo action: %w", err)
+	}
+	return nil
+}
+
+// GetUndoAction returns an action by ID, or ErrNotFound. Callers check
+// ExpiresAt and UndoneAt themselves.
+func (h *HostDB) GetUndoAction(id string) (*UndoAction, error) {
+	row := h.db.QueryRow("SELECT "+undoActionColumns+" FROM undo_actions WHERE id = ?", id)
+	return scanUndoAction(row)
+}
+
+// ListUndoActions returns the actions that can still be undone at now,
+// newest first.
+func (h *HostDB) ListUndoActions(now time.Time) ([]UndoAction, error) {
+	rows, err := h.db.Query(
+		"SELECT "+undoActionColumns+" FROM undo_actions WHERE undone_at IS NULL AND expires_at > ? ORDER BY created_at DESC, id",
+		now.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying undo actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []UndoAction{}
+	for rows.Next() {
+		action, err := scanUndoAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, *action)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating undo actions: %w", err)
+	}
+
+	return actions, nil
+}
+
+// ClaimUndoAction marks an action undone at now, unless it has expired or was
+// already undone, in which case claimed is false. Claiming first makes two
+// concurrent undos of the same action reverse it once.
+func (h *HostDB) ClaimUndoAction(id string, now time.Time) (claimed bool, err error) {
+	at := now.UTC().Format(time.RFC3339)
+	result, err := h.db.Exec(
+		"UPDATE undo_actions SET undone_at = ? WHERE id = ? AND undone_at IS NULL AND expires_at > ?",
+		at, id, at,
+	)
+	if err != nil {
+		return false, fmt.Errorf("claiming undo action: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("reading claimed rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ReleaseUndoAction clears the undone mark of an action whose undo failed,
+// so it can be tried again.
+func (h *HostDB) ReleaseUndoAction(id string) error {
+	if _, err := h.db.Exec("UPDATE undo_actions SET undone_at = NULL WHERE id = ?", id); err != nil {
+		return fmt.Errorf("releasing undo action: %w", err)
+	}
+	return nil
+}
+
+// DeleteUndoAction removes an action, or returns ErrNotFound.
+func (h *HostDB) DeleteUndoAction(id string) error {
+	result, err := h.db.Exec("DELETE FROM undo_actions WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting undo action: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("reading deleted rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteExpiredUndoActions removes the actions that expired by now, undone or
+// not, and returns their IDs.
+func (h *HostDB) DeleteExpiredUndoActions(now time.Time) ([]string, error) {
+	rows, err := h.db.Query("DELETE FROM undo_actions WHERE expires_at <= ? RETURNING id", now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("deleting expired undo actions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning expired undo action: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating expired undo actions: %w", err)
+	}
+
+	return ids, nil
+}
+
+func scanUndoAction(row rowScanner) (*UndoAction, error) {
+	var action UndoAction
+	var undoneAt sql.NullString
+	err := row.Scan(&action.ID, &action.Kind, &action.Target, &action.Payload,
+		&action.CreatedAt, &action.ExpiresAt, &undoneAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning undo action: %w", err)
+	}
+	if undoneAt.Valid {
+		action.UndoneAt = &undoneAt.String
+	}
+	return &action, nil
+}
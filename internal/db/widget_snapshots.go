@@ -0,0 +1,125 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WidgetSnapshot is widget data frozen at the time it was shared. Data is the
+// JSON the plugin returned for the slot.
+type WidgetSnapshot struct {
+	ID        string `json:"id"`
+	PluginID  string `json:"plugin_id"`
+	Slot      string `json:"slot"`
+	Format    string `json:"format"`
+	Data      string `json:"-"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+const widgetSnapshotColumns = "id, plugin_id, slot, format, data, created_at, expires_at"
+
+// CreateWidgetSnapshot stores a shared snapshot. Expired snapshots are
+// removed at the same time, so the table only holds live shares.
+func (h *HostDB) CreateWidgetSnapshot(snapshot WidgetSnapshot) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if _, err := h.db.Exec("DELETE FROM widget_snapshots WHERE expires_at <= ?", now); err != nil {
+		return fmt.Errorf("deleting expired widget snapshots: %w", err)
+	}
+
+	_, err := h.db.Exec(
+		"INSERT INTO widget_snapshots ("+widgetSnapshotColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		snapshot.ID, snapshot.PluginID, snapshot.Slot, snapshot.Format, snapshot.Data, snapshot.CreatedAt, snapshot.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting widget snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetWidgetSnapshot returns a snapshot by ID, or ErrNotFound. Callers check
+// ExpiresAt themselves.
+func (h *HostDB) GetWidgetSnapshot(id string) (*WidgetSnapshot, error) {
+	row := h.db.QueryRow("SELECT "+widgetSnapshotColumns+" FROM widget_snapshots WHERE id = ?", id)
+	return scanWidgetSnapshot(row)
+}
+
+// ListWidgetSnapshots returns the snapshots that have not expired, newest first.
+func (h *HostDB) ListWidgetSnapshots() ([]WidgetSnapshot, error) {
+	rows, err := h.db.Query(
+		"SELECT "+widgetSnapshotColumns+" FROM widget_snapshots WHERE expires_at > ? ORDER BY created_at DESC, id",
+		time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying widget snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []WidgetSnapshot{}
+	for rows.Next() {
+		snapshot, err := scanWidgetSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating widget snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// DeleteWidgetSnapshot revokes a snapshot, or returns ErrNotFound.
+func (h *HostDB) DeleteWidgetSnapshot(id string) error {
+	result, err := h.db.Exec("DELETE FROM widget_snapshots WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting widget snapshot: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking deleted widget snapshot: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// HostSecret returns the random secret stored under name, generating size
+// bytes the first time it is asked for.
+func (h *HostDB) HostSecret(name string, size int) ([]byte, error) {
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generating secret: %w", err)
+	}
+
+	if _, err := h.db.Exec("INSERT OR IGNORE INTO host_secrets (name, value) VALUES (?, ?)", name, secret); err != nil {
+		return nil, fmt.Errorf("storing secret: %w", err)
+	}
+
+	var stored []byte
+	if err := h.db.QueryRow("SELECT value FROM host_secrets WHERE name = ?", name).Scan(&stored); err != nil {
+		return nil, fmt.Errorf("reading secret: %w", err)
+	}
+	return stored, nil
+}
+
+func scanWidgetSnapshot(row rowScanner) (*WidgetSnapshot, error) {
+	var snapshot WidgetSnapshot
+	err := row.Scan(&snapshot.ID, &snapshot.PluginID, &snapshot.Slot, &snapshot.Format, &snapshot.Data,
+		&snapshot.CreatedAt, &snapshot.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning widget snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,56 @@
+// Package logging configures the host's structured logger and captures the
+// output of plugin subprocesses into per-plugin log files.
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Supported log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// NewLogger returns a slog logger writing to w in the given format ("text" or
+// "json") at the given level ("debug", "info", "warn" or "error"). Unknown
+// values fall back to text output at info level.
+func NewLogger(w io.Writer, format string, level string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: ParseLevel(level)}
+
+	if strings.EqualFold(format, FormatJSON) {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// ParseLevel converts a level name to a slog level, defaulting to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// ValidLevel reports whether level is a level name NewLogger understands.
+func ValidLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "warning", "error":
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidFormat reports whether format is a format NewLogger understands.
+func ValidFormat(format string) bool {
+	return strings.EqualFold(format, FormatText) || strings.EqualFold(format, FormatJSON)
+}
@@ -0,0 +1,302 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxLogFileSize is the size at which a plugin log file is rotated.
+	maxLogFileSize = 5 << 20
+	// maxLogBackups is how many rotated files are kept per plugin, as
+	// {id}.log.1 (newest) through {id}.log.{maxLogBackups} (oldest).
+	maxLogBackups = 3
+	// timeFormat matches the timestamps hclog writes, so captured stdout and
+	// stderr lines line up with go-plugin's own log lines.
+	timeFormat = "2006-01-02T15:04:05.000Z0700"
+)
+
+var (
+	// ErrNoLogs is returned by Tail when a plugin has never written any output.
+	ErrNoLogs = errors.New("no logs for plugin")
+	// ErrInvalidPluginID is returned for IDs that would escape the log directory.
+	ErrInvalidPluginID = errors.New("invalid plugin ID")
+)
+
+// PluginLogs stores the captured output of each plugin in its own rotating
+// log file, {dir}/{id}.log. Files are opened on first write and stay open
+// across plugin restarts.
+type PluginLogs struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*rotatingFile
+}
+
+// NewPluginLogs creates a store that keeps plugin log files in dir. The
+// directory is created when the first log line is written.
+func NewPluginLogs(dir string) *PluginLogs {
+	return &PluginLogs{dir: dir, files: make(map[string]*rotatingFile)}
+}
+
+// Writer returns the log file writer for a plugin. Each Write is appended as is.
+func (l *PluginLogs) Writer(pluginID string) (io.Writer, error) {
+	return l.file(pluginID)
+}
+
+// StreamWriter returns a writer that timestamps each line written to it and
+// tags it with stream, e.g. "STDOUT", before appending it to the plugin's log.
+func (l *PluginLogs) StreamWriter(pluginID string, stream string) (io.Writer, error) {
+	file, err := l.file(pluginID)
+	if err != nil {
+		return nil, err
+	}
+	return &lineWriter{out: file, prefix: "[" + stream + "] ", now: time.Now}, nil
+}
+
+// Tail returns the last n lines logged by a plugin, oldest first, reading
+// into rotated files when the current one is shorter than n lines.
+func (l *PluginLogs) Tail(pluginID string, n int) ([]string, error) {
+	if err := validatePluginID(pluginID); err != nil {
+		return nil, err
+	}
+
+	// Hold the file lock so a concurrent rotation cannot shuffle files mid-read.
+	l.mu.Lock()
+	file := l.files[pluginID]
+	l.mu.Unlock()
+	if file != nil {
+		file.mu.Lock()
+		defer file.mu.Unlock()
+	}
+
+	path := l.path(pluginID)
+	lines := make([]string, 0)
+	found := false
+	for backup := 0; backup <= maxLogBackups && len(lines) < n; backup++ {
+		name := path
+		if backup > 0 {
+			name = path + "." + strconv.Itoa(backup)
+		}
+
+		fileLines, err := readLastLines(name, n-len(lines))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading plugin log: %w", err)
+		}
+		found = true
+		lines = append(fileLines, lines...)
+	}
+
+	if !found {
+		return nil, ErrNoLogs
+	}
+	return lines, nil
+}
+
+// Close closes every open log file. Later writes reopen them.
+func (l *PluginLogs) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, file := range l.files {
+		errs = append(errs, file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (l *PluginLogs) file(pluginID string) (*rotatingFile, error) {
+	if err := validatePluginID(pluginID); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, ok := l.files[pluginID]
+	if !ok {
+		file = &rotatingFile{path: l.path(pluginID), maxSize: maxLogFileSize, maxBackups: maxLogBackups}
+		l.files[pluginID] = file
+	}
+	return file, nil
+}
+
+func (l *PluginLogs) path(pluginID string) string {
+	return filepath.Join(l.dir, pluginID+".log")
+}
+
+// validatePluginID keeps plugin IDs from escaping the log directory.
+func validatePluginID(pluginID string) error {
+	if pluginID == "" || strings.HasPrefix(pluginID, ".") || filepath.Base(pluginID) != pluginID {
+		return fmt.Errorf("%w: %q", ErrInvalidPluginID, pluginID)
+	}
+	return nil
+}
+
+// readLastLines returns up to n trailing lines of the file at path.
+func readLastLines(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Keep a ring of the last n lines so memory stays bounded by n, not the file size.
+	ring := make([]string, 0, n)
+	next := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogFileSize)
+	for scanner.Scan() {
+		if len(ring) < n {
+			ring = append(ring, scanner.Text())
+			continue
+		}
+		ring[next] = scanner.Text()
+		next = (next + 1) % n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return append(ring[next:], ring[:next]...), nil
+}
+
+// rotatingFile is an append-only file that is renamed to {path}.1 once it
+// grows past maxSize, shifting older backups along and dropping the oldest.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func (f *rotatingFile) Write(data []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.size > 0 && f.size+int64(len(data)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	written, err := f.file.Write(data)
+	f.size += int64(written)
+	return written, err
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open must be called with mu held.
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("creating log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("reading log file size: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate must be called with mu held.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	f.file = nil
+
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		from := f.path + "." + strconv.Itoa(i)
+		if err := os.Rename(from, f.path+"."+strconv.Itoa(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+
+	return f.open()
+}
+
+// lineWriter buffers partial writes and forwards complete lines, each
+// prefixed with a timestamp and a fixed tag.
+type lineWriter struct {
+	out    io.Writer
+	prefix string
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, data...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			break
+		}
+
+		line := w.now().Format(timeFormat) + " " + w.prefix + string(w.pending[:end+1])
+		w.pending = w.pending[end+1:]
+		if _, err := io.WriteString(w.out, line); err != nil {
+			return 0, err
+		}
+	}
+
+	// A line that never ends must not grow the buffer without bound.
+	if len(w.pending) > maxLogFileSize {
+		line := w.now().Format(timeFormat) + " " + w.prefix + string(w.pending) + "\n"
+		w.pending = nil
+		if _, err := io.WriteString(w.out, line); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
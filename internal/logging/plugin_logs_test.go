@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPluginLogs_WriteAndTail(t *testing.T) {
+	logs := NewPluginLogs(filepath.Join(t.TempDir(), "logs"))
+
+	writer, err := logs.Writer("notes")
+	if err != nil {
+		t.Fatalf("opening writer: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(writer, "line %d\n", i)
+	}
+
+	lines, err := logs.Tail("notes", 3)
+	if err != nil {
+		t.Fatalf("tailing logs: %v", err)
+	}
+	if strings.Join(lines, ",") != "line 3,line 4,line 5" {
+		t.Errorf("expected the last 3 lines, got %q", lines)
+	}
+
+	lines, err = logs.Tail("notes", 100)
+	if err != nil {
+		t.Fatalf("tailing logs: %v", err)
+	}
+	if len(lines) != 5 {
+		t.Errorf("expected all 5 lines, got %d", len(lines))
+	}
+}
+
+func TestPluginLogs_TailWithoutLogs(t *testing.T) {
+	logs := NewPluginLogs(t.TempDir())
+
+	if _, err := logs.Tail("missing", 10); !errors.Is(err, ErrNoLogs) {
+		t.Errorf("expected ErrNoLogs, got %v", err)
+	}
+	for _, id := range []string{"", "..", "../etc", ".extracted"} {
+		if _, err := logs.Tail(id, 10); !errors.Is(err, ErrInvalidPluginID) {
+			t.Errorf("Tail(%q): expected ErrInvalidPluginID, got %v", id, err)
+		}
+	}
+}
+
+func TestPluginLogs_ReopensAfterClose(t *testing.T) {
+	logs := NewPluginLogs(t.TempDir())
+
+	writer, _ := logs.Writer("notes")
+	fmt.Fprintln(writer, "before")
+	if err := logs.Close(); err != nil {
+		t.Fatalf("closing logs: %v", err)
+	}
+	fmt.Fprintln(writer, "after")
+
+	lines, err := logs.Tail("notes", 10)
+	if err != nil {
+		t.Fatalf("tailing logs: %v", err)
+	}
+	if strings.Join(lines, ",") != "before,after" {
+		t.Errorf("expected writes after Close to be appended, got %q", lines)
+	}
+}
+
+func TestRotatingFile_RotatesAndKeepsBackups(t *testing.T) {
+	dir := t.TempDir()
+	logs := NewPluginLogs(dir)
+	file, _ := logs.file("notes")
+	// Each line is 8 bytes, so every file holds two lines.
+	file.maxSize = 16
+
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(file, "line %02d\n", i)
+	}
+
+	for _, name := range []string{"notes.log", "notes.log.1", "notes.log.2", "notes.log.3"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to exist: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.log.4")); !os.IsNotExist(err) {
+		t.Error("expected no more than 3 backups")
+	}
+
+	// Tail reads across the current file and its backups, oldest first.
+	lines, err := logs.Tail("notes", 5)
+	if err != nil {
+		t.Fatalf("tailing logs: %v", err)
+	}
+	if strings.Join(lines, ",") != "line 06,line 07,line 08,line 09,line 10" {
+		t.Errorf("expected lines 06-10 across rotated files, got %q", lines)
+	}
+
+	// Lines older than the oldest backup are gone.
+	lines, _ = logs.Tail("notes", 100)
+	if len(lines) != 8 || lines[0] != "line 03" {
+		t.Errorf("expected 8 lines starting at line 03, got %q", lines)
+	}
+}
+
+func TestStreamWriter_TimestampsCompleteLines(t *testing.T) {
+	logs := NewPluginLogs(t.TempDir())
+	writer, err := logs.StreamWriter("notes", "STDERR")
+	if err != nil {
+		t.Fatalf("opening stream writer: %v", err)
+	}
+	writer.(*lineWriter).now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	fmt.Fprint(writer, "partial ")
+	if _, err := logs.Tail("notes", 10); !errors.Is(err, ErrNoLogs) {
+		t.Fatalf("expected an unfinished line to stay buffered, got %v", err)
+	}
+	fmt.Fprint(writer, "line\nsecond line\n")
+
+	lines, err := logs.Tail("notes", 10)
+	if err != nil {
+		t.Fatalf("tailing logs: %v", err)
+	}
+	expected := []string{
+		"2026-03-01T12:00:00.000Z [STDERR] partial line",
+		"2026-03-01T12:00:00.000Z [STDERR] second line",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
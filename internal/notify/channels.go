@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is what gets delivered to external channels.
+type Message struct {
+	Source    string `json:"source"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Urgent    bool   `json:"urgent"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SMTPConfig holds outbound mail settings. An empty Host disables email delivery.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Enabled reports whether enough settings are present to send email.
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+const webhookTimeout = 10 * time.Second
+
+// Gotify priorities: 8 and above make clients ring, like urgent notifications.
+const (
+	gotifyPriorityNormal = 5
+	gotifyPriorityUrgent = 8
+)
+
+// Channel delivers messages to one external service.
+type Channel interface {
+	// Name identifies the channel in logs.
+	Name() string
+	Send(message Message) error
+}
+
+// headerValue strips CR/LF from header values to prevent header injection.
+var headerValue = strings.NewReplacer("\r", "", "\n", " ")
+
+// webhookChannel POSTs the message as JSON, for desktop notifiers and
+// automation tools listening on a URL.
+type webhookChannel struct {
+	client *http.Client
+	url    string
+}
+
+func (c webhookChannel) Name() string { return "webhook" }
+
+func (c webhookChannel) Send(message Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+
+	response, err := c.client.Post(c.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	return checkResponse("webhook", response)
+}
+
+// ntfyChannel publishes the message to an ntfy topic URL.
+type ntfyChannel struct {
+	client *http.Client
+	url    string
+}
+
+func (c ntfyChannel) Name() string { return "ntfy" }
+
+func (c ntfyChannel) Send(message Message) error {
+	request, err := http.NewRequest(http.MethodPost, c.url, strings.NewReader(messageText(message)))
+	if err != nil {
+		return fmt.Errorf("building ntfy request: %w", err)
+	}
+	request.Header.Set("Title", headerValue.Replace(message.Title))
+	request.Header.Set("Tags", headerValue.Replace(message.Source))
+	if message.Urgent {
+		request.Header.Set("Priority", "urgent")
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("publishing to ntfy: %w", err)
+	}
+	return checkResponse("ntfy", response)
+}
+
+// gotifyChannel pushes the message to a Gotify server with an application token.
+type gotifyChannel struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+func (c gotifyChannel) Name() string { return "gotify" }
+
+func (c gotifyChannel) Send(message Message) error {
+	priority := gotifyPriorityNormal
+	if message.Urgent {
+		priority = gotifyPriorityUrgent
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"title":    message.Title,
+		"message":  messageText(message),
+		"priority": priority,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding gotify payload: %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.url, "/")+"/message", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("building gotify request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Gotify-Key", c.token)
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("pushing to gotify: %w", err)
+	}
+	return checkResponse("gotify", response)
+}
+
+// emailChannel delivers the message as a plain-text email.
+type emailChannel struct {
+	config SMTPConfig
+	to     string
+}
+
+func (c emailChannel) Name() string { return "email" }
+
+func (c emailChannel) Send(message Message) error {
+	config, to := c.config, c.to
+	address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	subject := headerValue.Replace(message.Title)
+
+	var body strings.Builder
+	body.WriteString("From: " + config.From + "\r\n")
+	body.WriteString("To: " + to + "\r\n")
+	body.WriteString("Subject: [Cortex] " + subject + "\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(address, auth, config.From, []string{to}, []byte(body.String())); err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+	return nil
+}
+
+// messageText is the body push services show, falling back to the title so
+// they never display an empty message.
+func messageText(message Message) string {
+	if message.Body == "" {
+		return message.Title
+	}
+	return message.Body
+}
+
+func checkResponse(channel string, response *http.Response) error {
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", channel, response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+// Package notify implements the host notification center: notifications are
+// stored in the host database, urgent ones are pushed to the configured
+// channels right away, and the rest are batched into a scheduled digest.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// digestCheckInterval is how often the scheduler checks whether a digest is due.
+const digestCheckInterval = time.Minute
+
+var timeOfDayPattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// Center stores notifications and delivers them to external channels.
+type Center struct {
+	hostDB     *db.HostDB
+	smtp       SMTPConfig
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewCenter creates a notification center backed by the host database.
+func NewCenter(hostDB *db.HostDB, smtpConfig SMTPConfig) *Center {
+	return &Center{
+		hostDB:     hostDB,
+		smtp:       smtpConfig,
+		httpClient: &http.Client{Timeout: webhookTimeout},
+		now:        time.Now,
+	}
+}
+
+// Notify stores a notification. Urgent notifications are delivered to the
+// digest channels immediately, in the background; the rest wait for the digest.
+func (c *Center) Notify(notification db.Notification) (*db.Notification, error) {
+	stored, err := c.hostDB.CreateNotification(notification, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.Urgent {
+		go func() {
+			settings, err := c.hostDB.GetDigestSettings()
+			if err != nil {
+				slog.Error("loading digest settings for urgent notification", "notification", stored.ID, "error", err)
+				return
+			}
+			c.deliver(settings, messageFromNotification(stored))
+		}()
+	}
+
+	return stored, nil
+}
+
+// SendNotification stores a notification raised by a plugin, with the plugin
+// as its source. It implements plugin.Notifier.
+func (c *Center) SendNotification(source string, title string, body string, urgent bool) error {
+	_, err := c.Notify(db.Notification{Source: source, Title: title, Body: body, Urgent: urgent})
+	return err
+}
+
+// Start runs the digest scheduler until ctx is cancelled.
+func (c *Center) Start(ctx context.Context) {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.RunDigest(); err != nil {
+				slog.Error("digest run failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunDigest sends the digest if its scheduled slot has passed since the last
+// delivery. It returns the stored digest notification, or nil when nothing was sent.
+func (c *Center) RunDigest() (*db.Notification, error) {
+	settings, err := c.hostDB.GetDigestSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	now := c.now()
+	slot, err := LastScheduledSlot(*settings, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Until the first digest goes out, the slot is compared against when the
+	// settings were saved, so enabling the digest does not fire one right away.
+	reference := settings.UpdatedAt
+	if settings.LastSentAt != nil {
+		reference = *settings.LastSentAt
+	}
+	if handledAt, err := time.Parse(time.RFC3339, reference); err == nil && !handledAt.Before(slot) {
+		return nil, nil
+	}
+
+	pending, err := c.hostDB.ListPendingDigestNotifications()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.hostDB.SetDigestLastSent(now); err != nil {
+		return nil, err
+	}
+
+	// An empty digest is skipped, but the slot still counts as handled.
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	summary := buildDigest(settings.Frequency, pending)
+	stored, err := c.hostDB.CreateNotification(summary, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(pending))
+	for index, notification := range pending {
+		ids[index] = notification.ID
+	}
+	if err := c.hostDB.MarkNotificationsDigested(ids); err != nil {
+		return nil, err
+	}
+
+	c.deliver(settings, messageFromNotification(stored))
+	return stored, nil
+}
+
+// deliver sends a message to every channel configured in the digest settings.
+// Delivery failures are logged; the notification center remains the source of truth.
+func (c *Center) deliver(settings *db.DigestSettings, message Message) {
+	for _, channel := range c.channels(settings) {
+		if err := channel.Send(message); err != nil {
+			slog.Warn("notification delivery failed", "channel", channel.Name(), "error", err)
+		}
+	}
+}
+
+// channels returns the channels configured in the digest settings.
+func (c *Center) channels(settings *db.DigestSettings) []Channel {
+	var channels []Channel
+	if settings.WebhookURL != "" {
+		channels = append(channels, webhookChannel{client: c.httpClient, url: settings.WebhookURL})
+	}
+	if settings.NtfyURL != "" {
+		channels = append(channels, ntfyChannel{client: c.httpClient, url: settings.NtfyURL})
+	}
+	if settings.GotifyURL != "" && settings.GotifyToken != "" {
+		channels = append(channels, gotifyChannel{client: c.httpClient, url: settings.GotifyURL, token: settings.GotifyToken})
+	}
+	if settings.Email != "" && c.smtp.Enabled() {
+		channels = append(channels, emailChannel{config: c.smtp, to: settings.Email})
+	}
+	return channels
+}
+
+// ValidateDigestSettings checks user-supplied digest settings.
+func ValidateDigestSettings(settings db.DigestSettings) error {
+	if settings.Frequency != "daily" && settings.Frequency != "weekly" {
+		return fmt.Errorf("frequency must be 'daily' or 'weekly'")
+	}
+	if !timeOfDayPattern.MatchString(settings.TimeOfDay) {
+		return fmt.Errorf("time_of_day must be in HH:MM format")
+	}
+	if settings.Weekday < 0 || settings.Weekday > 6 {
+		return fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"webhook_url", settings.WebhookURL},
+		{"ntfy_url", settings.NtfyURL},
+		{"gotify_url", settings.GotifyURL},
+	} {
+		if field.value != "" && !strings.HasPrefix(field.value, "http://") && !strings.HasPrefix(field.value, "https://") {
+			return fmt.Errorf("%s must start with http:// or https://", field.name)
+		}
+	}
+	if settings.GotifyURL != "" && settings.GotifyToken == "" {
+		return fmt.Errorf("gotify_token is required with gotify_url")
+	}
+	if settings.Email != "" && !strings.Contains(settings.Email, "@") {
+		return fmt.Errorf("email must be a valid address")
+	}
+	return nil
+}
+
+// LastScheduledSlot returns the most recent digest delivery time at or before now,
+// in now's location.
+func LastScheduledSlot(settings db.DigestSettings, now time.Time) (time.Time, error) {
+	clock, err := time.Parse("15:04", settings.TimeOfDay)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing digest time_of_day: %w", err)
+	}
+
+	slot := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+
+	if settings.Frequency == "weekly" {
+		daysBack := (int(now.Weekday()) - settings.Weekday + 7) % 7
+		slot = slot.AddDate(0, 0, -daysBack)
+		if slot.After(now) {
+			slot = slot.AddDate(0, 0, -7)
+		}
+		return slot, nil
+	}
+
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	return slot, nil
+}
+
+// buildDigest summarizes pending notifications into a single notification.
+func buildDigest(frequency string, pending []db.Notification) db.Notification {
+	title := "Daily digest"
+	if frequency == "weekly" {
+		title = "Weekly digest"
+	}
+	title = fmt.Sprintf("%s: %d update", title, len(pending))
+	if len(pending) != 1 {
+		title += "s"
+	}
+
+	var body strings.Builder
+	for _, notification := range pending {
+		fmt.Fprintf(&body, "- [%s] %s", notification.Source, notification.Title)
+		if notification.Body != "" {
+			fmt.Fprintf(&body, ": %s", notification.Body)
+		}
+		body.WriteString("\n")
+	}
+
+	return db.Notification{
+		Source: "digest",
+		Title:  title,
+		Body:   strings.TrimSuffix(body.String(), "\n"),
+	}
+}
+
+func messageFromNotification(notification *db.Notification) Message {
+	return Message{
+		Source:    notification.Source,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Urgent:    notification.Urgent,
+		CreatedAt: notification.CreatedAt,
+	}
+}
@@ -0,0 +1,213 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+func newTestCenter(t *testing.T) (*Center, *db.HostDB) {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	return NewCenter(hostDB, SMTPConfig{}), hostDB
+}
+
+func TestLastScheduledSlot_Daily(t *testing.T) {
+	settings := db.DigestSettings{Frequency: "daily", TimeOfDay: "08:30"}
+
+	before := time.Date(2026, 3, 10, 7, 0, 0, 0, time.UTC)
+	slot, err := LastScheduledSlot(settings, before)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 3, 9, 8, 30, 0, 0, time.UTC); !slot.Equal(want) {
+		t.Errorf("expected %v, got %v", want, slot)
+	}
+
+	after := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	slot, _ = LastScheduledSlot(settings, after)
+	if want := time.Date(2026, 3, 10, 8, 30, 0, 0, time.UTC); !slot.Equal(want) {
+		t.Errorf("expected %v, got %v", want, slot)
+	}
+}
+
+func TestLastScheduledSlot_Weekly(t *testing.T) {
+	// Weekday 1 = Monday. 2026-03-11 is a Wednesday.
+	settings := db.DigestSettings{Frequency: "weekly", TimeOfDay: "18:00", Weekday: 1}
+
+	slot, err := LastScheduledSlot(settings, time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 3, 9, 18, 0, 0, 0, time.UTC); !slot.Equal(want) {
+		t.Errorf("expected %v, got %v", want, slot)
+	}
+
+	// Monday before the delivery time falls back to the previous Monday.
+	slot, _ = LastScheduledSlot(settings, time.Date(2026, 3, 9, 17, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC); !slot.Equal(want) {
+		t.Errorf("expected %v, got %v", want, slot)
+	}
+}
+
+func TestRunDigest_BatchesNonUrgentAndDelivers(t *testing.T) {
+	center, hostDB := newTestCenter(t)
+
+	var received []Message
+	webhook := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var message Message
+		_ = json.NewDecoder(request.Body).Decode(&message)
+		received = append(received, message)
+	}))
+	defer webhook.Close()
+
+	if err := hostDB.SaveDigestSettings(db.DigestSettings{
+		Enabled:    true,
+		Frequency:  "daily",
+		TimeOfDay:  "08:00",
+		WebhookURL: webhook.URL,
+	}); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	for _, title := range []string{"Budget Food at 90%", "Project Cortex is stale"} {
+		if _, err := center.Notify(db.Notification{Source: "test", Title: title}); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+
+	// Jump past the next 08:00 slot.
+	center.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+
+	digest, err := center.RunDigest()
+	if err != nil {
+		t.Fatalf("RunDigest failed: %v", err)
+	}
+	if digest == nil {
+		t.Fatal("expected a digest to be sent")
+	}
+	if !strings.Contains(digest.Title, "2 updates") {
+		t.Errorf("expected title to mention 2 updates, got %q", digest.Title)
+	}
+	if !strings.Contains(digest.Body, "Budget Food at 90%") {
+		t.Errorf("expected body to list pending notifications, got %q", digest.Body)
+	}
+
+	if len(received) != 1 || received[0].Title != digest.Title {
+		t.Errorf("expected webhook to receive the digest, got %v", received)
+	}
+
+	pending, err := hostDB.ListPendingDigestNotifications()
+	if err != nil {
+		t.Fatalf("failed to list pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected no pending notifications after digest, got %d", len(pending))
+	}
+
+	// Same slot again: nothing to send.
+	again, err := center.RunDigest()
+	if err != nil {
+		t.Fatalf("second RunDigest failed: %v", err)
+	}
+	if again != nil {
+		t.Error("expected no digest for an already handled slot")
+	}
+}
+
+func TestRunDigest_Disabled(t *testing.T) {
+	center, _ := newTestCenter(t)
+
+	if _, err := center.Notify(db.Notification{Source: "test", Title: "Pending"}); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+
+	digest, err := center.RunDigest()
+	if err != nil {
+		t.Fatalf("RunDigest failed: %v", err)
+	}
+	if digest != nil {
+		t.Error("expected no digest while disabled")
+	}
+}
+
+func TestValidateDigestSettings(t *testing.T) {
+	valid := db.DigestSettings{Frequency: "weekly", TimeOfDay: "23:59", Weekday: 6}
+	if err := ValidateDigestSettings(valid); err != nil {
+		t.Errorf("expected valid settings, got %v", err)
+	}
+
+	invalid := []db.DigestSettings{
+		{Frequency: "hourly", TimeOfDay: "08:00"},
+		{Frequency: "daily", TimeOfDay: "24:00"},
+		{Frequency: "weekly", TimeOfDay: "08:00", Weekday: 7},
+		{Frequency: "daily", TimeOfDay: "08:00", WebhookURL: "ftp://example.com"},
+		{Frequency: "daily", TimeOfDay: "08:00", NtfyURL: "ntfy.sh/topic"},
+		{Frequency: "daily", TimeOfDay: "08:00", GotifyURL: "https://gotify.example.com"},
+	}
+	for _, settings := range invalid {
+		if err := ValidateDigestSettings(settings); err == nil {
+			t.Errorf("expected error for %+v", settings)
+		}
+	}
+}
+
+func TestDeliver_PushChannels(t *testing.T) {
+	center, _ := newTestCenter(t)
+
+	var ntfyRequest *http.Request
+	var ntfyBody string
+	ntfy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		content, _ := io.ReadAll(request.Body)
+		ntfyRequest, ntfyBody = request, string(content)
+	}))
+	defer ntfy.Close()
+
+	var gotifyRequest *http.Request
+	var gotifyPayload struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+	}
+	gotify := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		gotifyRequest = request
+		_ = json.NewDecoder(request.Body).Decode(&gotifyPayload)
+	}))
+	defer gotify.Close()
+
+	settings := &db.DigestSettings{
+		NtfyURL:     ntfy.URL + "/cortex",
+		GotifyURL:   gotify.URL + "/",
+		GotifyToken: "app-token",
+	}
+	center.deliver(settings, Message{Source: "finance-tracker", Title: "Food is over budget", Body: "Spent 320 of 300", Urgent: true})
+
+	if ntfyRequest == nil || ntfyRequest.URL.Path != "/cortex" {
+		t.Fatalf("expected ntfy to receive the message on its topic, got %v", ntfyRequest)
+	}
+	if ntfyRequest.Header.Get("Title") != "Food is over budget" || ntfyRequest.Header.Get("Priority") != "urgent" || ntfyBody != "Spent 320 of 300" {
+		t.Errorf("unexpected ntfy message: %v %q", ntfyRequest.Header, ntfyBody)
+	}
+
+	if gotifyRequest == nil || gotifyRequest.URL.Path != "/message" {
+		t.Fatalf("expected gotify to receive the message, got %v", gotifyRequest)
+	}
+	if gotifyRequest.Header.Get("X-Gotify-Key") != "app-token" {
+		t.Errorf("expected the application token, got %q", gotifyRequest.Header.Get("X-Gotify-Key"))
+	}
+	if gotifyPayload.Title != "Food is over budget" || gotifyPayload.Priority != gotifyPriorityUrgent {
+		t.Errorf("unexpected gotify payload: %+v", gotifyPayload)
+	}
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ArchiveExtension is the file extension of packaged plugins.
+//
+// A .cortexplugin file is a gzipped tarball laid out as:
+//
+//	manifest.json
+//	backend/<goos>-<goarch>/plugin   one binary per supported platform
+//	frontend/...                     optional frontend assets
+//
+// A single "plugin" binary at the root is also accepted, for archives built
+// for one platform only.
+const ArchiveExtension = ".cortexplugin"
+
+// extractedDirName is where archives are unpacked inside the plugin directory.
+// It is hidden, so LoadAll never mistakes it for a plugin.
+const extractedDirName = ".extracted"
+
+// ErrUnsupportedPlatform is returned when an archive has no binary for the running platform.
+var ErrUnsupportedPlatform = fmt.Errorf("%w: no backend binary for %s-%s", ErrInvalidArchive, runtime.GOOS, runtime.GOARCH)
+
+// selectPlatformBinary places the backend binary for the running platform at
+// dir/plugin, where the loader expects it.
+func selectPlatformBinary(dir string) error {
+	if info, err := os.Stat(filepath.Join(dir, "plugin")); err == nil && info.Mode().IsRegular() {
+		return nil
+	}
+
+	candidate := filepath.Join(dir, "backend", runtime.GOOS+"-"+runtime.GOARCH, "plugin")
+	info, err := os.Stat(candidate)
+	if err != nil || !info.Mode().IsRegular() {
+		return ErrUnsupportedPlatform
+	}
+
+	if err := os.Rename(candidate, filepath.Join(dir, "plugin")); err != nil {
+		return fmt.Errorf("selecting platform binary: %w", err)
+	}
+	return nil
+}
+
+// resolvePluginPath returns the directory to launch a plugin from. Unpacked
+// directories take precedence; otherwise {id}.cortexplugin is extracted.
+func (l *Loader) resolvePluginPath(id string) (string, error) {
+	pluginPath := filepath.Join(l.pluginDir, id)
+	if info, err := os.Stat(pluginPath); err == nil && info.IsDir() {
+		return pluginPath, nil
+	}
+
+	archivePath := pluginPath + ArchiveExtension
+	if _, err := os.Stat(archivePath); err != nil {
+		// Neither exists; let the caller report the missing manifest.
+		return pluginPath, nil
+	}
+
+	return l.extractArchive(id, archivePath)
+}
+
+// extractArchive unpacks a plugin archive into the extraction directory,
+// replacing any previous extraction, and returns the unpacked directory.
+// Archives are re-extracted on every load so a replaced file takes effect on reload.
+func (l *Loader) extractArchive(id string, archivePath string) (string, error) {
+	extractedDir := filepath.Join(l.pluginDir, extractedDirName)
+	if err := os.MkdirAll(extractedDir, 0755); err != nil {
+		return "", fmt.Errorf("creating extraction directory: %w", err)
+	}
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("opening plugin archive: %w", err)
+	}
+	defer archive.Close()
+
+	staging, err := os.MkdirTemp(extractedDir, "."+id+"-")
+	if err != nil {
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := unpackArchive(archive, staging); err != nil {
+		return "", err
+	}
+
+	if err := selectPlatformBinary(staging); err != nil {
+		return "", err
+	}
+
+	manifest, err := readStagedManifest(staging)
+	if err != nil {
+		return "", err
+	}
+	if manifest.ID != id {
+		return "", fmt.Errorf("%w: archive %s declares plugin id %q", ErrInvalidArchive, filepath.Base(archivePath), manifest.ID)
+	}
+
+	target := filepath.Join(extractedDir, id)
+	if err := os.RemoveAll(target); err != nil {
+		return "", fmt.Errorf("removing previous extraction: %w", err)
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return "", fmt.Errorf("moving extracted plugin into place: %w", err)
+	}
+
+	return target, nil
+}
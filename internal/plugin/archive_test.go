@@ -0,0 +1,116 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// platformArchive returns a .cortexplugin archive with binaries for the
+// running platform and one other.
+func platformArchive(t *testing.T, id string) []byte {
+	return buildArchive(t,
+		archiveEntry{name: "manifest.json", body: `{"id":"` + id + `","name":"Test","version":"1.0.0"}`, mode: 0644},
+		archiveEntry{name: "backend/" + runtime.GOOS + "-" + runtime.GOARCH + "/plugin", body: "native", mode: 0755},
+		archiveEntry{name: "backend/plan9-mips/plugin", body: "foreign", mode: 0755},
+		archiveEntry{name: "frontend/index.html", body: "<html></html>", mode: 0644},
+	)
+}
+
+// writePluginArchive writes an archive file into the plugin directory.
+func writePluginArchive(t *testing.T, dir string, name string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatalf("writing archive: %v", err)
+	}
+}
+
+func TestResolvePluginPath_ExtractsArchive(t *testing.T) {
+	pluginDir := t.TempDir()
+	writePluginArchive(t, pluginDir, "weather"+ArchiveExtension, platformArchive(t, "weather"))
+
+	loader := NewLoader(pluginDir, t.TempDir(), NewRegistry())
+	path, err := loader.resolvePluginPath("weather")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+
+	if path != filepath.Join(pluginDir, extractedDirName, "weather") {
+		t.Errorf("unexpected extraction path %s", path)
+	}
+
+	binary, err := os.ReadFile(filepath.Join(path, "plugin"))
+	if err != nil {
+		t.Fatalf("expected plugin binary at the root of the extraction: %v", err)
+	}
+	if string(binary) != "native" {
+		t.Errorf("expected the binary for the running platform, got %q", binary)
+	}
+	if _, err := os.Stat(filepath.Join(path, "frontend", "index.html")); err != nil {
+		t.Errorf("expected frontend assets to be extracted: %v", err)
+	}
+}
+
+func TestResolvePluginPath_DirectoryTakesPrecedence(t *testing.T) {
+	pluginDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(pluginDir, "weather"), 0755); err != nil {
+		t.Fatalf("creating plugin dir: %v", err)
+	}
+	writePluginArchive(t, pluginDir, "weather"+ArchiveExtension, platformArchive(t, "weather"))
+
+	loader := NewLoader(pluginDir, t.TempDir(), NewRegistry())
+	path, err := loader.resolvePluginPath("weather")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if path != filepath.Join(pluginDir, "weather") {
+		t.Errorf("expected the unpacked directory, got %s", path)
+	}
+}
+
+func TestResolvePluginPath_RejectsMismatchedID(t *testing.T) {
+	pluginDir := t.TempDir()
+	writePluginArchive(t, pluginDir, "weather"+ArchiveExtension, platformArchive(t, "other"))
+
+	loader := NewLoader(pluginDir, t.TempDir(), NewRegistry())
+	if _, err := loader.resolvePluginPath("weather"); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("expected ErrInvalidArchive, got %v", err)
+	}
+}
+
+func TestResolvePluginPath_UnsupportedPlatform(t *testing.T) {
+	pluginDir := t.TempDir()
+	archive := buildArchive(t,
+		archiveEntry{name: "manifest.json", body: `{"id":"weather"}`, mode: 0644},
+		archiveEntry{name: "backend/plan9-mips/plugin", body: "foreign", mode: 0755},
+	)
+	writePluginArchive(t, pluginDir, "weather"+ArchiveExtension, archive)
+
+	loader := NewLoader(pluginDir, t.TempDir(), NewRegistry())
+	if _, err := loader.resolvePluginPath("weather"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("expected ErrUnsupportedPlatform, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(pluginDir, extractedDirName, "weather")); !os.IsNotExist(err) {
+		t.Error("expected no extraction to be left behind")
+	}
+}
+
+func TestInstall_PlatformArchive(t *testing.T) {
+	pluginDir := t.TempDir()
+	archive := platformArchive(t, "weather")
+
+	installer := NewInstaller(pluginDir, "", nil)
+	if _, err := installer.Install(InstallSource{URL: serveBytes(t, archive), SHA256: checksum(archive)}); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+
+	binary, err := os.ReadFile(filepath.Join(pluginDir, "weather", "plugin"))
+	if err != nil {
+		t.Fatalf("expected plugin binary to be installed: %v", err)
+	}
+	if string(binary) != "native" {
+		t.Errorf("expected the binary for the running platform, got %q", binary)
+	}
+}
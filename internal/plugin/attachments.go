@@ -0,0 +1,206 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+const (
+	// MaxAttachmentSize caps a single attachment.
+	MaxAttachmentSize = 16 << 20
+	// maxAttachmentNameLength bounds attachment file names.
+	maxAttachmentNameLength = 255
+	// attachmentMessageSize is the largest gRPC message carrying an
+	// attachment: its content plus room for the metadata.
+	attachmentMessageSize = MaxAttachmentSize + 64<<10
+)
+
+// Errors returned by attachment calls, on both sides of the host connection.
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = fmt.Errorf("attachment exceeds %d bytes", MaxAttachmentSize)
+)
+
+// Attachment describes a file a plugin stored with the host. Files with the
+// same content are stored once, whichever plugins attach them.
+type Attachment struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	SHA256      string `json:"sha256"`
+	Size        int64  `json:"size"`
+	CreatedAt   string `json:"created_at"`
+	// Deduplicated is set by PutAttachment when the content was already stored.
+	Deduplicated bool `json:"deduplicated"`
+}
+
+// AttachmentStore keeps the files plugins attach to their records. The host's
+// attachments.Store implements it. Each plugin only sees its own attachments.
+type AttachmentStore interface {
+	Put(pluginID string, name string, contentType string, content []byte) (*Attachment, error)
+	Get(pluginID string, id int64) (*Attachment, []byte, error)
+	Delete(pluginID string, id int64) error
+}
+
+// --- Host side ---
+
+func (s *hostServer) PutAttachment(ctx context.Context, request *pb.PutAttachmentRequest) (*pb.Attachment, error) {
+	if err := s.requireAttachments(); err != nil {
+		return nil, err
+	}
+	if request.Name == "" || len(request.Name) > maxAttachmentNameLength {
+		return nil, status.Errorf(codes.InvalidArgument, "name must be between 1 and %d characters", maxAttachmentNameLength)
+	}
+	if len(request.Content) > MaxAttachmentSize {
+		return nil, status.Error(codes.InvalidArgument, ErrAttachmentTooLarge.Error())
+	}
+
+	attachment, err := s.attachments.Put(s.pluginID, request.Name, request.ContentType, request.Content)
+	if err != nil {
+		return nil, s.attachmentStatus(err)
+	}
+	return attachmentToProto(attachment), nil
+}
+
+func (s *hostServer) GetAttachment(ctx context.Context, request *pb.AttachmentRequest) (*pb.AttachmentContent, error) {
+	if err := s.requireAttachments(); err != nil {
+		return nil, err
+	}
+
+	attachment, content, err := s.attachments.Get(s.pluginID, request.Id)
+	if err != nil {
+		return nil, s.attachmentStatus(err)
+	}
+	return &pb.AttachmentContent{Attachment: attachmentToProto(attachment), Content: content}, nil
+}
+
+func (s *hostServer) DeleteAttachment(ctx context.Context, request *pb.AttachmentRequest) (*pb.Empty, error) {
+	if err := s.requireAttachments(); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachments.Delete(s.pluginID, request.Id); err != nil {
+		return nil, s.attachmentStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+// requireAttachments rejects attachment calls from plugins that did not
+// declare the attachments permission; the loader only hands those a store.
+func (s *hostServer) requireAttachments() error {
+	if s.attachments == nil {
+		return status.Errorf(codes.PermissionDenied, "plugin has not declared %q", PermissionAttachments)
+	}
+	return nil
+}
+
+func (s *hostServer) attachmentStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrAttachmentNotFound):
+		return status.Error(codes.NotFound, ErrAttachmentNotFound.Error())
+	case errors.Is(err, ErrAttachmentTooLarge):
+		return status.Error(codes.InvalidArgument, ErrAttachmentTooLarge.Error())
+	default:
+		slog.Error("attachment store failed", "plugin", s.pluginID, "error", err)
+		return status.Error(codes.Internal, "attachment store failed")
+	}
+}
+
+func attachmentToProto(attachment *Attachment) *pb.Attachment {
+	return &pb.Attachment{
+		Id:           attachment.ID,
+		Name:         attachment.Name,
+		ContentType:  attachment.ContentType,
+		Sha256:       attachment.SHA256,
+		Size:         attachment.Size,
+		CreatedAt:    attachment.CreatedAt,
+		Deduplicated: attachment.Deduplicated,
+	}
+}
+
+// --- Plugin side ---
+
+// PutAttachment stores content with the host and returns its attachment. It
+// is called from within a plugin that declared the attachments permission.
+func PutAttachment(name string, contentType string, content []byte) (*Attachment, error) {
+	if len(content) > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	client, err := hostClient()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.PutAttachment(context.Background(),
+		&pb.PutAttachmentRequest{Name: name, ContentType: contentType, Content: content},
+		grpc.MaxCallSendMsgSize(attachmentMessageSize))
+	if err != nil {
+		return nil, translateAttachmentError(err)
+	}
+	return attachmentFromProto(response), nil
+}
+
+// GetAttachment returns one of the plugin's attachments with its content.
+func GetAttachment(id int64) (*Attachment, []byte, error) {
+	client, err := hostClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := client.GetAttachment(context.Background(), &pb.AttachmentRequest{Id: id},
+		grpc.MaxCallRecvMsgSize(attachmentMessageSize))
+	if err != nil {
+		return nil, nil, translateAttachmentError(err)
+	}
+	return attachmentFromProto(response.Attachment), response.Content, nil
+}
+
+// DeleteAttachment removes one of the plugin's attachments. The content is
+// deleted once no plugin references it anymore.
+func DeleteAttachment(id int64) error {
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.DeleteAttachment(context.Background(), &pb.AttachmentRequest{Id: id})
+	return translateAttachmentError(err)
+}
+
+// translateAttachmentError maps host status codes back to the package errors.
+func translateAttachmentError(err error) error {
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.NotFound:
+		return ErrAttachmentNotFound
+	case codes.PermissionDenied:
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, status.Convert(err).Message())
+	default:
+		return translateError(err)
+	}
+}
+
+func attachmentFromProto(attachment *pb.Attachment) *Attachment {
+	if attachment == nil {
+		return &Attachment{}
+	}
+	return &Attachment{
+		ID:           attachment.Id,
+		Name:         attachment.Name,
+		ContentType:  attachment.ContentType,
+		SHA256:       attachment.Sha256,
+		Size:         attachment.Size,
+		CreatedAt:    attachment.CreatedAt,
+		Deduplicated: attachment.Deduplicated,
+	}
+}
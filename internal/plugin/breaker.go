@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// breakerFailureThreshold is the number of consecutive failed calls that
+	// opens a plugin's circuit.
+	breakerFailureThreshold = 5
+	// breakerCooldown is how long an open circuit rejects calls before letting
+	// a single trial call through.
+	breakerCooldown = 30 * time.Second
+	// maxRestarts bounds automatic relaunches of a crashed plugin within restartWindow.
+	maxRestarts = 3
+	// restartWindow is the period over which automatic relaunches are counted.
+	restartWindow = 10 * time.Minute
+)
+
+var (
+	// ErrPluginNotFound is returned when no plugin with the given ID is registered.
+	ErrPluginNotFound = errors.New("plugin not found")
+	// ErrPluginUnavailable is returned when a plugin is registered but cannot
+	// serve calls: it is not running, crashed beyond its restart budget, or its
+	// circuit is open.
+	ErrPluginUnavailable = errors.New("plugin unavailable")
+	// ErrPluginTimeout is returned when a plugin did not answer a call before
+	// its deadline.
+	ErrPluginTimeout = errors.New("plugin did not answer in time")
+)
+
+// Circuit breaker states, as reported by CircuitBreaker.State.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// CircuitBreaker tracks the health of one plugin. After repeated failures it
+// opens and rejects calls immediately, so a crash-looping plugin fails fast
+// instead of slowing down every request routed to it. It also tracks whether
+// the plugin crashed and how often it was relaunched.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+	crashed  bool
+	restarts []time.Time
+	now      func() time.Time
+
+	// restartMu serializes relaunches so concurrent requests to a crashed
+	// plugin start it only once.
+	restartMu sync.Mutex
+}
+
+func newCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{state: BreakerClosed, now: time.Now}
+}
+
+// State returns the breaker state: closed, open, or half-open.
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= breakerCooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a call may proceed. Once the cooldown of an open
+// circuit has passed, exactly one trial call is let through; its outcome
+// closes or reopens the circuit.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < breakerCooldown {
+			return ErrPluginUnavailable
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrPluginUnavailable
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess closes the circuit and clears the failure count.
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// RecordCanceled forgets a call its caller cancelled, which says nothing about
+// the plugin. A cancelled trial call lets the next call be the trial.
+func (b *CircuitBreaker) RecordCanceled() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// RecordFailure counts a failed call. crashed marks the plugin process as
+// dead so the next call relaunches it. A failed trial call reopens the circuit.
+func (b *CircuitBreaker) RecordFailure(crashed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if crashed {
+		b.crashed = true
+	}
+	if b.state == BreakerHalfOpen || b.failures >= breakerFailureThreshold {
+		b.open()
+	}
+}
+
+// trip opens the circuit immediately.
+func (b *CircuitBreaker) trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.open()
+}
+
+// open must be called with mu held.
+func (b *CircuitBreaker) open() {
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) markCrashed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.crashed = true
+}
+
+func (b *CircuitBreaker) isCrashed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.crashed
+}
+
+// reserveRestart records a relaunch attempt, or returns false when the plugin
+// was already relaunched maxRestarts times within restartWindow.
+func (b *CircuitBreaker) reserveRestart() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	recent := b.restarts[:0]
+	for _, restartedAt := range b.restarts {
+		if now.Sub(restartedAt) < restartWindow {
+			recent = append(recent, restartedAt)
+		}
+	}
+	b.restarts = recent
+
+	if len(b.restarts) >= maxRestarts {
+		return false
+	}
+	b.restarts = append(b.restarts, now)
+	return true
+}
+
+// restarted clears the crash flag after a successful relaunch.
+func (b *CircuitBreaker) restarted() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.crashed = false
+}
+
+// isConnectionLost reports whether err means the plugin subprocess went away
+// mid-call, which go-plugin surfaces as an Unavailable gRPC status.
+func isConnectionLost(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
@@ -0,0 +1,174 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestBreaker returns a breaker whose clock is controlled by the returned pointer.
+func newTestBreaker() (*CircuitBreaker, *time.Time) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker()
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	breaker, _ := newTestBreaker()
+
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		breaker.RecordFailure(false)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("expected closed below the threshold, got %s", breaker.State())
+	}
+
+	breaker.RecordSuccess()
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		breaker.RecordFailure(false)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("expected a success to reset the failure count, got %s", breaker.State())
+	}
+
+	breaker.RecordFailure(false)
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("expected open at the threshold, got %s", breaker.State())
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrPluginUnavailable) {
+		t.Errorf("expected an open circuit to reject calls, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleTrial(t *testing.T) {
+	breaker, now := newTestBreaker()
+	breaker.trip()
+
+	*now = now.Add(breakerCooldown)
+	if breaker.State() != BreakerHalfOpen {
+		t.Fatalf("expected half-open after the cooldown, got %s", breaker.State())
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected the trial call to be allowed, got %v", err)
+	}
+	if err := breaker.Allow(); err == nil {
+		t.Fatal("expected a second concurrent trial to be rejected")
+	}
+
+	// A failed trial reopens the circuit for another cooldown.
+	breaker.RecordFailure(false)
+	if err := breaker.Allow(); err == nil {
+		t.Fatal("expected the circuit to reopen after a failed trial")
+	}
+
+	*now = now.Add(breakerCooldown)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected another trial after the cooldown, got %v", err)
+	}
+	breaker.RecordSuccess()
+	if breaker.State() != BreakerClosed {
+		t.Errorf("expected a successful trial to close the circuit, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_RestartBudget(t *testing.T) {
+	breaker, now := newTestBreaker()
+
+	for i := 0; i < maxRestarts; i++ {
+		if !breaker.reserveRestart() {
+			t.Fatalf("expected restart %d to be allowed", i+1)
+		}
+	}
+	if breaker.reserveRestart() {
+		t.Fatal("expected the restart budget to be exhausted")
+	}
+
+	*now = now.Add(restartWindow)
+	if !breaker.reserveRestart() {
+		t.Error("expected the budget to recover once the window has passed")
+	}
+}
+
+// crashingPlugin fails every call the way go-plugin reports a dead subprocess.
+type crashingPlugin struct{ fakePlugin }
+
+func (p *crashingPlugin) HandleAPI(_ context.Context, request *APIRequest) (*APIResponse, error) {
+	return nil, status.Error(codes.Unavailable, "connection closed")
+}
+
+func TestLoaderAcquire_RelaunchesCrashedPlugin(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader("", "", registry)
+
+	relaunches := 0
+	loader.relaunch = func(id string) error {
+		relaunches++
+		registry.Register(id, nil, &Manifest{ID: id})
+		entry, _ := registry.Get(id)
+		entry.Plugin = &crashingPlugin{}
+		return nil
+	}
+
+	registry.Register("flaky", nil, &Manifest{ID: "flaky"})
+	entry, _ := registry.Get("flaky")
+	entry.Plugin = &crashingPlugin{}
+
+	for i := 0; i <= maxRestarts; i++ {
+		entry, err := loader.Acquire("flaky")
+		if err != nil {
+			t.Fatalf("call %d: expected the plugin to be available, got %v", i+1, err)
+		}
+		_, callErr := entry.Plugin.HandleAPI(context.Background(), &APIRequest{Method: "GET", Path: "/"})
+		if crashed := loader.Release("flaky", entry, callErr); !crashed {
+			t.Fatalf("call %d: expected the failure to be detected as a crash", i+1)
+		}
+	}
+	if relaunches != maxRestarts {
+		t.Fatalf("expected %d relaunches, got %d", maxRestarts, relaunches)
+	}
+
+	// The restart budget is spent: fail fast without relaunching again.
+	if _, err := loader.Acquire("flaky"); !errors.Is(err, ErrPluginUnavailable) {
+		t.Fatalf("expected ErrPluginUnavailable, got %v", err)
+	}
+	if relaunches != maxRestarts {
+		t.Errorf("expected no further relaunches, got %d", relaunches)
+	}
+	if registry.Breaker("flaky").State() != BreakerOpen {
+		t.Errorf("expected the circuit to be open, got %s", registry.Breaker("flaky").State())
+	}
+
+	// Other plugins are unaffected.
+	registry.Register("healthy", nil, &Manifest{ID: "healthy"})
+	healthy, _ := registry.Get("healthy")
+	healthy.Plugin = &fakePlugin{}
+	if _, err := loader.Acquire("healthy"); err != nil {
+		t.Errorf("expected a healthy plugin to be available, got %v", err)
+	}
+}
+
+func TestLoaderAcquire_UnloadResetsBreaker(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader("", "", registry)
+
+	registry.Register("alpha", nil, &Manifest{ID: "alpha"})
+	registry.Breaker("alpha").trip()
+
+	if _, err := loader.Acquire("alpha"); !errors.Is(err, ErrPluginUnavailable) {
+		t.Fatalf("expected an open circuit, got %v", err)
+	}
+	if err := loader.UnloadPlugin("alpha"); err != nil {
+		t.Fatalf("unload failed: %v", err)
+	}
+	if registry.Breaker("alpha").State() != BreakerClosed {
+		t.Errorf("expected a fresh breaker after unload, got %s", registry.Breaker("alpha").State())
+	}
+	if _, err := loader.Acquire("alpha"); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("expected ErrPluginNotFound after unload, got %v", err)
+	}
+}
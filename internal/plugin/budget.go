@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// errorBudgetWindow is the period over which a route's error rate is measured.
+	errorBudgetWindow = time.Minute
+	// errorBudgetMinRequests is how many requests a window needs before its
+	// error rate can degrade a route, so a single early failure does not.
+	errorBudgetMinRequests = 10
+	// errorBudgetMaxFailureRate is the share of failed requests in a window
+	// past which a route is degraded.
+	errorBudgetMaxFailureRate = 0.5
+	// degradedProbeInterval is how often a request to a degraded route is
+	// still passed to the plugin, to find out whether it recovered.
+	degradedProbeInterval = 15 * time.Second
+	// maxStaleResponses bounds how many GET responses are kept per plugin.
+	maxStaleResponses = 100
+	// maxStaleBodySize is the largest response body kept for stale serving.
+	maxStaleBodySize = 1 << 20
+)
+
+// StaleResponse is the last successful response to a GET request, served in
+// place of calling a plugin while the request's route is degraded.
+type StaleResponse struct {
+	Response *APIResponse
+	StoredAt time.Time
+}
+
+// ErrorBudget tracks the error rate of each route of one plugin. When more
+// than half of a route's recent requests fail, the route is degraded: GET
+// requests are answered with the last successful response for the same path
+// and query, and only an occasional probe reaches the plugin, until one
+// succeeds. Routes are told apart by method and path, with numeric path
+// segments treated as the same route.
+type ErrorBudget struct {
+	mu        sync.Mutex
+	routes    map[string]*routeBudget
+	responses map[string]*StaleResponse
+	now       func() time.Time
+}
+
+// routeBudget is the error count of one route in the current window.
+type routeBudget struct {
+	windowStart time.Time
+	requests    int
+	failures    int
+	degraded    bool
+	lastProbe   time.Time
+}
+
+func newErrorBudget() *ErrorBudget {
+	return &ErrorBudget{
+		routes:    make(map[string]*routeBudget),
+		responses: make(map[string]*StaleResponse),
+		now:       time.Now,
+	}
+}
+
+// Stale returns the response to serve instead of calling the plugin, when
+// request is a GET to a degraded route with a stored response and it is not
+// yet time for the next probe.
+func (b *ErrorBudget) Stale(request *APIRequest) (*StaleResponse, bool) {
+	if request.Method != http.MethodGet {
+		return nil, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	route, ok := b.routes[routeKey(request.Method, request.Path)]
+	if !ok || !route.degraded {
+		return nil, false
+	}
+	stale, ok := b.responses[responseKey(request)]
+	if !ok {
+		return nil, false
+	}
+
+	now := b.now()
+	if now.Sub(route.lastProbe) >= degradedProbeInterval {
+		route.lastProbe = now
+		return nil, false
+	}
+	return stale, true
+}
+
+// Record counts the outcome of a call to the plugin: err is the call's error
+// and response its result, where 5xx statuses count as failures. A successful
+// GET response is kept for stale serving. On failure of a GET to a degraded
+// route, it returns the stored response to serve instead. degraded reports
+// whether this call degraded the route.
+func (b *ErrorBudget) Record(request *APIRequest, response *APIResponse, err error) (stale *StaleResponse, degraded bool) {
+	failed := err != nil || response == nil || response.StatusCode >= http.StatusInternalServerError
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	key := routeKey(request.Method, request.Path)
+	route, ok := b.routes[key]
+	if !ok {
+		route = &routeBudget{windowStart: now}
+		b.routes[key] = route
+	}
+	if now.Sub(route.windowStart) >= errorBudgetWindow {
+		route.windowStart = now
+		route.requests = 0
+		route.failures = 0
+	}
+
+	route.requests++
+	if !failed {
+		if route.degraded {
+			slog.Info("plugin route recovered", "route", key)
+			route.degraded = false
+			route.requests = 1
+			route.failures = 0
+			route.windowStart = now
+		}
+		if request.Method == http.MethodGet && response.StatusCode < http.StatusMultipleChoices && len(response.Body) <= maxStaleBodySize {
+			b.store(responseKey(request), response, now)
+		}
+		return nil, false
+	}
+
+	route.failures++
+	if !route.degraded && route.requests >= errorBudgetMinRequests &&
+		float64(route.failures)/float64(route.requests) > errorBudgetMaxFailureRate {
+		route.degraded = true
+		route.lastProbe = now
+		degraded = true
+	}
+
+	if route.degraded && request.Method == http.MethodGet {
+		stale = b.responses[responseKey(request)]
+	}
+	return stale, degraded
+}
+
+// DegradedRoutes returns the routes currently degraded, as "METHOD /path".
+func (b *ErrorBudget) DegradedRoutes() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	routes := make([]string, 0)
+	for key, route := range b.routes {
+		if route.degraded {
+			routes = append(routes, key)
+		}
+	}
+	return routes
+}
+
+// store keeps response for key, evicting the oldest stored response when
+// full. It must be called with mu held.
+func (b *ErrorBudget) store(key string, response *APIResponse, now time.Time) {
+	if _, ok := b.responses[key]; !ok && len(b.responses) >= maxStaleResponses {
+		var oldestKey string
+		var oldest time.Time
+		for candidate, stored := range b.responses {
+			if oldestKey == "" || stored.StoredAt.Before(oldest) {
+				oldestKey, oldest = candidate, stored.StoredAt
+			}
+		}
+		delete(b.responses, oldestKey)
+	}
+	b.responses[key] = &StaleResponse{Response: response, StoredAt: now}
+}
+
+// routeKey identifies the route of a request, such as "GET /notes/{id}".
+func routeKey(method string, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789") == "" {
+			segments[i] = "{id}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// responseKey identifies the stored response of a GET request by its path
+// and query.
+func responseKey(request *APIRequest) string {
+	query := make(url.Values, len(request.Query))
+	for key, value := range request.Query {
+		query.Set(key, value)
+	}
+	return request.Path + "?" + query.Encode()
+}
+
+// StaleResponse returns the response to serve for request instead of calling
+// plugin id, while the request's route is degraded.
+func (l *Loader) StaleResponse(id string, request *APIRequest) (*StaleResponse, bool) {
+	return l.registry.ErrorBudget(id).Stale(request)
+}
+
+// RecordResponse counts the outcome of a call to plugin id against its error
+// budget, notifying when a route becomes degraded. For a failed GET to a
+// degraded route, it returns the stored response to serve instead.
+func (l *Loader) RecordResponse(id string, request *APIRequest, response *APIResponse, err error) (*StaleResponse, bool) {
+	stale, degraded := l.registry.ErrorBudget(id).Record(request, response, err)
+	if degraded {
+		l.warnDegradedRoute(id, routeKey(request.Method, request.Path))
+	}
+	return stale, stale != nil
+}
+
+func (l *Loader) warnDegradedRoute(id string, route string) {
+	slog.Warn("plugin route exceeded its error budget", "plugin", id, "route", route)
+	if l.notifier == nil {
+		return
+	}
+
+	name := id
+	if entry, ok := l.registry.Get(id); ok && entry.Manifest != nil && entry.Manifest.Name != "" {
+		name = entry.Manifest.Name
+	}
+	title := fmt.Sprintf("%s is failing on %s", name, route)
+	body := fmt.Sprintf("More than half of the recent requests to %s of %s failed. "+
+		"Until it recovers, GET requests are answered with the last successful response, marked as stale, "+
+		"and the plugin is only retried every %s. Its logs may tell why.", route, id, degradedProbeInterval)
+	if err := l.notifier.SendNotification(systemNotificationSource, title, body, false); err != nil {
+		slog.Warn("sending route degradation warning", "plugin", id, "error", err)
+	}
+}
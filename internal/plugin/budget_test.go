@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestErrorBudget_DegradesAndRecovers(t *testing.T) {
+	budget := newErrorBudget()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	budget.now = func() time.Time { return now }
+
+	request := &APIRequest{Method: http.MethodGet, Path: "/reports/summary", Query: map[string]string{"month": "2026-02"}}
+	good := &APIResponse{StatusCode: http.StatusOK, Body: []byte(`{"data":1}`), ContentType: "application/json"}
+	failing := &APIResponse{StatusCode: http.StatusInternalServerError, Body: []byte(`{"error":{}}`)}
+
+	budget.Record(request, good, nil)
+	for i := 1; i <= 8; i++ {
+		if stale, degraded := budget.Record(request, failing, nil); stale != nil || degraded {
+			t.Fatalf("failure %d: expected the route to stay within its budget", i)
+		}
+	}
+
+	// The ninth failure makes 9 of 10 requests fail.
+	stale, degraded := budget.Record(request, nil, errors.New("plugin request failed"))
+	if !degraded || stale == nil || string(stale.Response.Body) != `{"data":1}` {
+		t.Fatalf("expected the route to degrade and the last good response to be returned, got %v %v", stale, degraded)
+	}
+	if routes := budget.DegradedRoutes(); len(routes) != 1 || routes[0] != "GET /reports/summary" {
+		t.Errorf("expected the summary route to be degraded, got %v", routes)
+	}
+
+	if _, ok := budget.Stale(request); !ok {
+		t.Fatal("expected requests to be served stale while degraded")
+	}
+	other := &APIRequest{Method: http.MethodGet, Path: "/reports/summary", Query: map[string]string{"month": "2026-01"}}
+	if _, ok := budget.Stale(other); ok {
+		t.Error("expected a query with no stored response to reach the plugin")
+	}
+
+	// After the probe interval one request reaches the plugin again.
+	now = now.Add(degradedProbeInterval)
+	if _, ok := budget.Stale(request); ok {
+		t.Fatal("expected a probe to reach the plugin")
+	}
+	if _, ok := budget.Stale(request); !ok {
+		t.Error("expected only one probe per interval")
+	}
+	budget.Record(request, good, nil)
+	if _, ok := budget.Stale(request); ok {
+		t.Error("expected a successful probe to restore the route")
+	}
+	if routes := budget.DegradedRoutes(); len(routes) != 0 {
+		t.Errorf("expected no degraded routes, got %v", routes)
+	}
+}
+
+func TestErrorBudget_WritesAreNeverServedStale(t *testing.T) {
+	budget := newErrorBudget()
+	request := &APIRequest{Method: http.MethodPost, Path: "/notes"}
+
+	for i := 0; i < errorBudgetMinRequests; i++ {
+		budget.Record(request, &APIResponse{StatusCode: http.StatusServiceUnavailable}, nil)
+	}
+	if routes := budget.DegradedRoutes(); len(routes) != 1 {
+		t.Fatalf("expected failing writes to degrade the route, got %v", routes)
+	}
+	if _, ok := budget.Stale(request); ok {
+		t.Error("expected writes to always reach the plugin")
+	}
+}
+
+func TestRouteKey_GroupsNumericSegments(t *testing.T) {
+	if key := routeKey(http.MethodGet, "/notes/42/attachments"); key != "GET /notes/{id}/attachments" {
+		t.Errorf("unexpected route key %q", key)
+	}
+	if key := routeKey(http.MethodGet, "/reports/2026-02"); key != "GET /reports/2026-02" {
+		t.Errorf("unexpected route key %q", key)
+	}
+}
+
+func TestRecordResponse_NotifiesOnDegradation(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker", Name: "Finance Tracker"})
+	loader := NewLoader(t.TempDir(), t.TempDir(), registry)
+	notifier := &recordingNotifier{}
+	loader.SetNotifier(notifier)
+
+	request := &APIRequest{Method: http.MethodGet, Path: "/accounts/3/balance"}
+	for i := 0; i < 2*errorBudgetMinRequests; i++ {
+		loader.RecordResponse("finance-tracker", request, nil, errors.New("plugin request failed"))
+	}
+
+	if len(notifier.titles) != 1 || notifier.sources[0] != systemNotificationSource {
+		t.Fatalf("expected a single notification, got %+v", notifier)
+	}
+	if notifier.titles[0] != "Finance Tracker is failing on GET /accounts/{id}/balance" {
+		t.Errorf("unexpected title %q", notifier.titles[0])
+	}
+}
@@ -0,0 +1,151 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// bundledStateFile records the digest of every archive InstallBundled has
+// written, so it can tell its own copies from the user's. It is hidden, so
+// LoadAll never mistakes it for a plugin.
+const bundledStateFile = ".bundled.json"
+
+// InstallBundled copies the plugin archives at the root of bundle into
+// pluginDir, for binaries built with their first-party plugins embedded. It
+// returns the IDs of the plugins it installed or upgraded.
+//
+// A bundled plugin is only written where the user has not taken over: an
+// unpacked directory or an archive that is not the one last installed from a
+// bundle is left alone, and a plugin the user deleted stays deleted. An
+// archive still as installed is replaced when the bundle carries a new build.
+func InstallBundled(bundle fs.FS, pluginDir string) ([]string, error) {
+	entries, err := fs.ReadDir(bundle, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading bundled plugins: %w", err)
+	}
+
+	state, err := readBundledState(pluginDir)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make([]string, 0)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ArchiveExtension)
+		if entry.IsDir() || !ok || !pluginIDPattern.MatchString(id) {
+			continue
+		}
+
+		data, err := fs.ReadFile(bundle, entry.Name())
+		if err != nil {
+			return installed, fmt.Errorf("reading bundled plugin %s: %w", id, err)
+		}
+		sum := sha256.Sum256(data)
+		digest := hex.EncodeToString(sum[:])
+
+		write, err := bundledWriteNeeded(pluginDir, id, state[id], digest)
+		if err != nil {
+			return installed, err
+		}
+		if !write {
+			continue
+		}
+
+		if err := os.MkdirAll(pluginDir, 0755); err != nil {
+			return installed, fmt.Errorf("creating plugin directory: %w", err)
+		}
+		if err := writeFileAtomic(filepath.Join(pluginDir, id+ArchiveExtension), data); err != nil {
+			return installed, fmt.Errorf("installing bundled plugin %s: %w", id, err)
+		}
+		state[id] = digest
+		installed = append(installed, id)
+	}
+
+	if len(installed) == 0 {
+		return installed, nil
+	}
+	sort.Strings(installed)
+	return installed, writeBundledState(pluginDir, state)
+}
+
+// bundledWriteNeeded reports whether the bundled archive of plugin id, with
+// the given digest, should be written. recorded is the digest of the archive
+// last installed from a bundle, empty if there was none.
+func bundledWriteNeeded(pluginDir string, id string, recorded string, digest string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(pluginDir, id)); err == nil {
+		return false, nil
+	}
+
+	current, err := os.ReadFile(filepath.Join(pluginDir, id+ArchiveExtension))
+	if errors.Is(err, os.ErrNotExist) {
+		// Never installed, or installed once and since removed by the user
+		return recorded == "", nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading installed plugin %s: %w", id, err)
+	}
+
+	sum := sha256.Sum256(current)
+	onDisk := hex.EncodeToString(sum[:])
+	return onDisk == recorded && onDisk != digest, nil
+}
+
+func readBundledState(pluginDir string) (map[string]string, error) {
+	state := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(pluginDir, bundledStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading bundled plugin state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing bundled plugin state: %w", err)
+	}
+	return state, nil
+}
+
+func writeBundledState(pluginDir string, state map[string]string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding bundled plugin state: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(pluginDir, bundledStateFile), data); err != nil {
+		return fmt.Errorf("writing bundled plugin state: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to path through a temporary file in the same
+// directory, so the loader never picks up a half-written archive.
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	temporary := file.Name()
+	defer os.Remove(temporary)
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("writing temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+	if err := os.Chmod(temporary, 0644); err != nil {
+		return fmt.Errorf("setting file mode: %w", err)
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+	return nil
+}
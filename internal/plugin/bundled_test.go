@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func bundleOf(files map[string][]byte) fstest.MapFS {
+	bundle := fstest.MapFS{}
+	for name, data := range files {
+		bundle[name] = &fstest.MapFile{Data: data, Mode: 0644}
+	}
+	return bundle
+}
+
+func TestInstallBundled_InstallsArchivesOnFirstRun(t *testing.T) {
+	pluginDir := filepath.Join(t.TempDir(), "plugins")
+	bundle := bundleOf(map[string][]byte{
+		"weather" + ArchiveExtension: platformArchive(t, "weather"),
+		"README.md":                  []byte("not a plugin"),
+	})
+
+	installed, err := InstallBundled(bundle, pluginDir)
+	if err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	if !slices.Equal(installed, []string{"weather"}) {
+		t.Fatalf("expected weather to be installed, got %v", installed)
+	}
+	if _, err := os.Stat(filepath.Join(pluginDir, "README.md")); err == nil {
+		t.Error("expected files other than plugin archives to be skipped")
+	}
+
+	registry := NewRegistry()
+	loader := NewLoader(pluginDir, t.TempDir(), registry)
+	if path, err := loader.resolvePluginPath("weather"); err != nil || path != filepath.Join(pluginDir, extractedDirName, "weather") {
+		t.Errorf("expected the installed archive to be loadable, got %s, %v", path, err)
+	}
+
+	installed, err = InstallBundled(bundle, pluginDir)
+	if err != nil || len(installed) != 0 {
+		t.Errorf("expected nothing to install on the next run, got %v, %v", installed, err)
+	}
+}
+
+func TestInstallBundled_UpgradesOnlyUntouchedArchives(t *testing.T) {
+	pluginDir := t.TempDir()
+	if _, err := InstallBundled(bundleOf(map[string][]byte{
+		"weather" + ArchiveExtension: platformArchive(t, "weather"),
+		"todo" + ArchiveExtension:    platformArchive(t, "todo"),
+	}), pluginDir); err != nil {
+		t.Fatalf("first install failed: %v", err)
+	}
+
+	// The user replaces one bundled plugin with a build of their own
+	custom := []byte("custom build")
+	writePluginArchive(t, pluginDir, "todo"+ArchiveExtension, custom)
+
+	upgrade := bundleOf(map[string][]byte{
+		"weather" + ArchiveExtension: buildArchive(t, archiveEntry{name: "manifest.json", body: `{"id":"weather","version":"2.0.0"}`, mode: 0644}),
+		"todo" + ArchiveExtension:    buildArchive(t, archiveEntry{name: "manifest.json", body: `{"id":"todo","version":"2.0.0"}`, mode: 0644}),
+	})
+	installed, err := InstallBundled(upgrade, pluginDir)
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if !slices.Equal(installed, []string{"weather"}) {
+		t.Fatalf("expected only the untouched plugin to be upgraded, got %v", installed)
+	}
+
+	data, err := os.ReadFile(filepath.Join(pluginDir, "todo"+ArchiveExtension))
+	if err != nil || string(data) != string(custom) {
+		t.Errorf("expected the user's build to be kept, got %q, %v", data, err)
+	}
+}
+
+func TestInstallBundled_RespectsUserChoices(t *testing.T) {
+	pluginDir := t.TempDir()
+	bundle := bundleOf(map[string][]byte{
+		"weather" + ArchiveExtension: platformArchive(t, "weather"),
+		"todo" + ArchiveExtension:    platformArchive(t, "todo"),
+	})
+
+	// An unpacked plugin directory takes precedence over the bundle
+	if err := os.Mkdir(filepath.Join(pluginDir, "todo"), 0755); err != nil {
+		t.Fatalf("creating plugin dir: %v", err)
+	}
+	if _, err := InstallBundled(bundle, pluginDir); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(pluginDir, "todo"+ArchiveExtension)); err == nil {
+		t.Error("expected no archive next to an unpacked plugin")
+	}
+
+	// A bundled plugin the user removed is not reinstalled
+	if err := os.Remove(filepath.Join(pluginDir, "weather"+ArchiveExtension)); err != nil {
+		t.Fatalf("removing plugin: %v", err)
+	}
+	installed, err := InstallBundled(bundle, pluginDir)
+	if err != nil || len(installed) != 0 {
+		t.Errorf("expected the removed plugin to stay removed, got %v, %v", installed, err)
+	}
+}
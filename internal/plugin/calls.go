@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+// CallerHeader names the header carrying the ID of the plugin that sent a
+// request through CallPlugin. The host sets it; callers cannot.
+const CallerHeader = "X-Cortex-Caller"
+
+// PluginCaller passes one plugin's requests to another plugin's API. The
+// loader implements it.
+type PluginCaller interface {
+	CallPlugin(ctx context.Context, callerID string, targetID string, request *APIRequest) (*APIResponse, error)
+}
+
+// --- Host side ---
+
+// CallPlugin sends a read-only request from plugin callerID to the API of
+// plugin targetID, the way the host's proxy would, within the request
+// timeout.
+func (l *Loader) CallPlugin(ctx context.Context, callerID string, targetID string, request *APIRequest) (*APIResponse, error) {
+	if isCanaryKey(targetID) {
+		return nil, ErrPluginNotFound
+	}
+	entry, err := l.Acquire(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := l.RequestContext(ctx)
+	defer cancel()
+
+	headers := make(map[string]string, len(request.Headers)+1)
+	for name, value := range request.Headers {
+		headers[name] = value
+	}
+	headers[CallerHeader] = callerID
+	forwarded := *request
+	forwarded.Headers = headers
+
+	response, err := entry.Plugin.HandleAPI(ctx, &forwarded)
+	l.Release(targetID, entry, err)
+	return response, err
+}
+
+func (s *hostServer) CallPlugin(ctx context.Context, request *pb.PluginCallRequest) (*pb.APIResponse, error) {
+	if s.caller == nil {
+		return nil, status.Errorf(codes.PermissionDenied, "plugin has not declared %q", PermissionPluginsRead)
+	}
+	if request.PluginId == "" || request.Request == nil {
+		return nil, status.Error(codes.InvalidArgument, "plugin_id and request are required")
+	}
+	if request.PluginId == s.pluginID {
+		return nil, status.Error(codes.InvalidArgument, "a plugin cannot call itself")
+	}
+	method := strings.ToUpper(request.Request.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if method != http.MethodGet {
+		return nil, status.Error(codes.PermissionDenied, "only GET requests can be sent to other plugins")
+	}
+
+	headers := make(map[string]string, len(request.Request.Headers))
+	for name, value := range request.Request.Headers {
+		if http.CanonicalHeaderKey(name) != CallerHeader {
+			headers[name] = value
+		}
+	}
+
+	response, err := s.caller.CallPlugin(ctx, s.pluginID, request.PluginId, &APIRequest{
+		Method:      method,
+		Path:        request.Request.Path,
+		Query:       request.Request.Query,
+		Headers:     headers,
+		ContentType: request.Request.ContentType,
+		RequestID:   request.Request.RequestId,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrPluginNotFound):
+		return nil, status.Errorf(codes.NotFound, "plugin %s is not loaded", request.PluginId)
+	case errors.Is(err, ErrPluginUnavailable):
+		return nil, status.Errorf(codes.Unavailable, "plugin %s is unavailable", request.PluginId)
+	case errors.Is(err, ErrPluginTimeout):
+		return nil, status.Errorf(codes.DeadlineExceeded, "plugin %s did not answer in time", request.PluginId)
+	case errors.Is(err, context.Canceled):
+		return nil, status.Error(codes.Canceled, "call cancelled")
+	default:
+		slog.Error("calling plugin", "plugin", s.pluginID, "target", request.PluginId, "request_id", request.Request.RequestId, "error", err)
+		return nil, status.Errorf(codes.Internal, "plugin %s failed to answer", request.PluginId)
+	}
+
+	return &pb.APIResponse{
+		StatusCode:  int32(response.StatusCode),
+		Body:        response.Body,
+		ContentType: response.ContentType,
+		Headers:     response.Headers,
+	}, nil
+}
+
+// --- Plugin side ---
+
+// CallPlugin sends a GET request to the API of another plugin through the
+// host and returns its response, whatever its status code. The request's
+// Context bounds the call. It is called from within a plugin that declared
+// the plugins:read permission.
+func CallPlugin(pluginID string, request *APIRequest) (*APIResponse, error) {
+	client, err := hostClient()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.CallPlugin(request.Context(), &pb.PluginCallRequest{
+		PluginId: pluginID,
+		Request: &pb.APIRequest{
+			Method:      request.Method,
+			Path:        request.Path,
+			Query:       request.Query,
+			Headers:     request.Headers,
+			ContentType: request.ContentType,
+			RequestId:   request.RequestID,
+		},
+	}, grpc.MaxCallRecvMsgSize(apiMessageSize))
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.PermissionDenied:
+		return nil, fmt.Errorf("%w: %s", ErrPermissionDenied, status.Convert(err).Message())
+	case codes.NotFound:
+		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, status.Convert(err).Message())
+	case codes.Unavailable:
+		return nil, fmt.Errorf("%w: %s", ErrPluginUnavailable, status.Convert(err).Message())
+	default:
+		return nil, translateError(err)
+	}
+
+	return &APIResponse{
+		StatusCode:  int(response.StatusCode),
+		Body:        response.Body,
+		ContentType: response.ContentType,
+		Headers:     response.Headers,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// summaryPlugin answers every request with its path and records it.
+type summaryPlugin struct {
+	fakePlugin
+	received *APIRequest
+}
+
+func (p *summaryPlugin) HandleAPI(_ context.Context, request *APIRequest) (*APIResponse, error) {
+	p.received = request
+	return &APIResponse{StatusCode: http.StatusOK, Body: []byte(`{"data":"` + request.Path + `"}`), ContentType: "application/json"}, nil
+}
+
+// connectCaller connects a "reports" plugin whose CallPlugin requests reach
+// a loader running "finance-tracker", and returns finance-tracker's stub.
+func connectCaller(t *testing.T, permitted bool) *summaryPlugin {
+	t.Helper()
+
+	registry := NewRegistry()
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker"})
+	entry, _ := registry.Get("finance-tracker")
+	target := &summaryPlugin{}
+	entry.Plugin = target
+
+	grpcPlugin := &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "reports"}
+	if permitted {
+		grpcPlugin.Caller = NewLoader(t.TempDir(), t.TempDir(), registry)
+	}
+	connectPluginOverGRPC(t, grpcPlugin)
+	return target
+}
+
+func TestCallPlugin_ReachesOtherPlugin(t *testing.T) {
+	target := connectCaller(t, true)
+
+	response, err := CallPlugin("finance-tracker", &APIRequest{
+		Path:    "/summary",
+		Query:   map[string]string{"month": "2026-03"},
+		Headers: map[string]string{CallerHeader: "finance-tracker"},
+	})
+	if err != nil {
+		t.Fatalf("CallPlugin failed: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || string(response.Body) != `{"data":"/summary"}` {
+		t.Errorf("unexpected response %d %s", response.StatusCode, response.Body)
+	}
+	if target.received.Method != http.MethodGet || target.received.Query["month"] != "2026-03" {
+		t.Errorf("expected a GET with the query, got %+v", target.received)
+	}
+	if caller := target.received.Headers[CallerHeader]; caller != "reports" {
+		t.Errorf("expected the host to name the caller, got %q", caller)
+	}
+}
+
+func TestCallPlugin_RequiresPermission(t *testing.T) {
+	connectCaller(t, false)
+
+	if _, err := CallPlugin("finance-tracker", &APIRequest{Path: "/summary"}); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+}
+
+func TestCallPlugin_ReadOnly(t *testing.T) {
+	target := connectCaller(t, true)
+
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		if _, err := CallPlugin("finance-tracker", &APIRequest{Method: method, Path: "/transactions"}); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("expected %s to be denied, got %v", method, err)
+		}
+	}
+	if target.received != nil {
+		t.Error("expected denied requests not to reach the plugin")
+	}
+}
+
+func TestCallPlugin_UnknownPlugin(t *testing.T) {
+	connectCaller(t, true)
+
+	for _, id := range []string{"project-hub", CanaryKey("finance-tracker")} {
+		if _, err := CallPlugin(id, &APIRequest{Path: "/projects"}); !errors.Is(err, ErrPluginNotFound) {
+			t.Errorf("expected ErrPluginNotFound for %s, got %v", id, err)
+		}
+	}
+	if _, err := CallPlugin("reports", &APIRequest{Path: "/"}); err == nil {
+		t.Error("expected a plugin calling itself to be rejected")
+	}
+}
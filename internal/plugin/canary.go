@@ -0,0 +1,253 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// canaryDirName is where canary builds live inside the plugin directory, one
+// subdirectory per plugin ID. It is hidden, so LoadAll never loads them as
+// plugins of their own.
+const canaryDirName = ".canary"
+
+// canarySuffix turns a plugin ID into the registry key of its canary.
+const canarySuffix = "@canary"
+
+// Errors returned by canary operations. The HTTP layer maps them to error codes.
+var (
+	ErrNoCanary        = errors.New("plugin has no running canary")
+	ErrNoCanaryBuild   = errors.New("no canary build found")
+	ErrInvalidRollout  = errors.New("invalid rollout")
+	ErrPromoteRestored = errors.New("promoted canary failed to load, previous version restored")
+	// ErrCanaryEncrypted is returned for plugins with an encrypted database:
+	// each process holds that database in memory, so a canary and the live
+	// plugin would overwrite each other's writes.
+	ErrCanaryEncrypted = errors.New("canary rollouts are not available for plugins with an encrypted database")
+)
+
+// Rollout is the share of a live plugin's traffic served by its canary.
+// Requests made with one of APIKeyIDs always go to the canary; of the rest,
+// Percent out of 100 do.
+type Rollout struct {
+	Percent   int     `json:"percent"`
+	APIKeyIDs []int64 `json:"api_key_ids"`
+}
+
+func (r Rollout) validate() error {
+	if r.Percent < 0 || r.Percent > 100 {
+		return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidRollout)
+	}
+	for _, id := range r.APIKeyIDs {
+		if id <= 0 {
+			return fmt.Errorf("%w: api_key_ids must be positive", ErrInvalidRollout)
+		}
+	}
+	return nil
+}
+
+// CanaryStatus describes a running canary next to the live version it may replace.
+type CanaryStatus struct {
+	ID          string `json:"id"`
+	Version     string `json:"version"`
+	LiveVersion string `json:"live_version"`
+	Rollout
+}
+
+// CanaryKey returns the registry key a plugin's canary runs under.
+func CanaryKey(id string) string {
+	return id + canarySuffix
+}
+
+// canaryOf returns the live plugin ID of a canary registry key.
+func canaryOf(key string) (string, bool) {
+	return strings.CutSuffix(key, canarySuffix)
+}
+
+func isCanaryKey(key string) bool {
+	return strings.HasSuffix(key, canarySuffix)
+}
+
+// canaryPath is the directory a plugin's canary build is launched from.
+func (l *Loader) canaryPath(id string) string {
+	return filepath.Join(l.pluginDir, canaryDirName, id)
+}
+
+// StartCanary launches the build in the plugin's canary directory alongside
+// the live plugin and routes traffic to it according to rollout. The canary
+// shares the live plugin's data directory and database, and runs its
+// migrations against them. A canary that is already running is replaced.
+func (l *Loader) StartCanary(id string, rollout Rollout) error {
+	if err := rollout.validate(); err != nil {
+		return err
+	}
+	live, ok := l.registry.Get(id)
+	if !ok || isCanaryKey(id) {
+		return ErrPluginNotFound
+	}
+	if l.databaseKey != nil && needsDatabase(live.Manifest) {
+		return ErrCanaryEncrypted
+	}
+
+	path := l.canaryPath(id)
+	if _, err := os.Stat(filepath.Join(path, "manifest.json")); err != nil {
+		return fmt.Errorf("%w in %s", ErrNoCanaryBuild, path)
+	}
+
+	key := CanaryKey(id)
+	if _, running := l.registry.Get(key); running {
+		l.registry.clearRollout(id)
+		if err := l.UnloadPlugin(key); err != nil {
+			return fmt.Errorf("stopping previous canary: %w", err)
+		}
+	}
+
+	if err := l.launch(key, id, path); err != nil {
+		return fmt.Errorf("starting canary: %w", err)
+	}
+	l.registry.SetRollout(id, rollout)
+
+	slog.Info("canary started", "plugin", id, "percent", rollout.Percent, "api_keys", len(rollout.APIKeyIDs))
+	return nil
+}
+
+// UpdateRollout changes how much traffic a running canary receives.
+func (l *Loader) UpdateRollout(id string, rollout Rollout) error {
+	if err := rollout.validate(); err != nil {
+		return err
+	}
+	if _, ok := l.registry.Get(CanaryKey(id)); !ok {
+		return ErrNoCanary
+	}
+
+	l.registry.SetRollout(id, rollout)
+	return nil
+}
+
+// Canary reports the running canary of a plugin.
+func (l *Loader) Canary(id string) (*CanaryStatus, error) {
+	canary, ok := l.registry.Get(CanaryKey(id))
+	if !ok {
+		return nil, ErrNoCanary
+	}
+
+	status := &CanaryStatus{ID: id, Version: canary.Manifest.Version}
+	if live, ok := l.registry.Get(id); ok {
+		status.LiveVersion = live.Manifest.Version
+	}
+	if rollout, ok := l.registry.Rollout(id); ok {
+		status.Rollout = rollout
+	}
+	if status.APIKeyIDs == nil {
+		status.APIKeyIDs = []int64{}
+	}
+	return status, nil
+}
+
+// RollbackCanary stops a plugin's canary and discards its build. The live
+// plugin keeps serving all traffic.
+func (l *Loader) RollbackCanary(id string) error {
+	key := CanaryKey(id)
+	if _, ok := l.registry.Get(key); !ok {
+		return ErrNoCanary
+	}
+
+	l.registry.clearRollout(id)
+	if err := l.UnloadPlugin(key); err != nil {
+		return fmt.Errorf("stopping canary: %w", err)
+	}
+	if err := os.RemoveAll(l.canaryPath(id)); err != nil {
+		return fmt.Errorf("removing canary build: %w", err)
+	}
+
+	slog.Info("canary rolled back", "plugin", id)
+	return nil
+}
+
+// PromoteCanary makes a plugin's canary build its live version: both
+// processes are stopped, the canary build replaces the live one on disk and
+// the plugin is loaded again, migrating its settings. If the promoted build
+// fails to load, the previous version is put back and loaded instead.
+func (l *Loader) PromoteCanary(id string) error {
+	if _, ok := l.registry.Get(CanaryKey(id)); !ok {
+		return ErrNoCanary
+	}
+
+	if err := l.replaceBuild(id, l.canaryPath(id), ErrPromoteRestored); err != nil {
+		return err
+	}
+
+	slog.Info("canary promoted", "plugin", id)
+	return nil
+}
+
+// replaceBuild stops plugin id, puts the unpacked build at build in place of
+// its live build and loads it. If the new build fails to load, it is moved
+// back to build and the previous version is restored, and the error returned
+// wraps restored.
+func (l *Loader) replaceBuild(id string, build string, restored error) error {
+	if err := l.UnloadPlugin(id); err != nil {
+		return fmt.Errorf("stopping plugin: %w", err)
+	}
+
+	// Keep the live build (a directory, an archive or both) until the
+	// new one has loaded.
+	previous := filepath.Join(l.pluginDir, canaryDirName, ".previous-"+id)
+	if err := os.RemoveAll(previous); err != nil {
+		return fmt.Errorf("clearing previous build: %w", err)
+	}
+	if err := os.MkdirAll(previous, 0755); err != nil {
+		return fmt.Errorf("creating previous build directory: %w", err)
+	}
+	if err := movePluginBuild(l.pluginDir, previous, id); err != nil {
+		return fmt.Errorf("moving live build aside: %w", err)
+	}
+
+	livePath := filepath.Join(l.pluginDir, id)
+	if err := os.Rename(build, livePath); err != nil {
+		return errors.Join(fmt.Errorf("moving new build into place: %w", err), l.restoreBuild(id, previous))
+	}
+
+	if err := l.LoadPlugin(id); err != nil {
+		slog.Error("new build failed to load, restoring previous version", "plugin", id, "error", err)
+		if restoreErr := os.Rename(livePath, build); restoreErr != nil {
+			return errors.Join(err, restoreErr)
+		}
+		return errors.Join(restored, err, l.restoreBuild(id, previous))
+	}
+
+	if err := os.RemoveAll(previous); err != nil {
+		slog.Warn("removing previous plugin build", "plugin", id, "error", err)
+	}
+	return nil
+}
+
+// restoreBuild moves a build set aside by replaceBuild back into place and
+// loads it.
+func (l *Loader) restoreBuild(id string, previous string) error {
+	if err := movePluginBuild(previous, l.pluginDir, id); err != nil {
+		return fmt.Errorf("restoring previous build: %w", err)
+	}
+	if err := l.LoadPlugin(id); err != nil {
+		return fmt.Errorf("loading previous build: %w", err)
+	}
+	return os.RemoveAll(previous)
+}
+
+// movePluginBuild moves the directory and archive of plugin id, whichever
+// exist, from one directory to another.
+func movePluginBuild(from string, to string, id string) error {
+	for _, name := range []string{id, id + ArchiveExtension} {
+		source := filepath.Join(from, name)
+		if _, err := os.Lstat(source); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(source, filepath.Join(to, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
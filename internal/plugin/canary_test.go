@@ -0,0 +1,166 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// registerFake registers a running in-process plugin under key.
+func registerFake(registry *Registry, key string, version string) {
+	id, _ := canaryOf(key)
+	registry.Register(key, nil, &Manifest{ID: id, Version: version})
+	entry, _ := registry.Get(key)
+	entry.Plugin = &fakePlugin{}
+}
+
+func TestRegistryRouteTarget(t *testing.T) {
+	registry := NewRegistry()
+	registerFake(registry, "notes", "1.0.0")
+
+	registry.SetRollout("notes", Rollout{Percent: 100})
+	if target := registry.RouteTarget("notes", 0); target != "notes" {
+		t.Errorf("expected live traffic while no canary runs, got %s", target)
+	}
+
+	registerFake(registry, CanaryKey("notes"), "2.0.0")
+	if target := registry.RouteTarget("notes", 0); target != CanaryKey("notes") {
+		t.Errorf("expected a 100%% rollout to reach the canary, got %s", target)
+	}
+
+	registry.SetRollout("notes", Rollout{Percent: 0, APIKeyIDs: []int64{7}})
+	if target := registry.RouteTarget("notes", 7); target != CanaryKey("notes") {
+		t.Errorf("expected the pinned API key to reach the canary, got %s", target)
+	}
+	if target := registry.RouteTarget("notes", 8); target != "notes" {
+		t.Errorf("expected other API keys to reach the live plugin, got %s", target)
+	}
+	if target := registry.RouteTarget("notes", 0); target != "notes" {
+		t.Errorf("expected requests without an API key to reach the live plugin, got %s", target)
+	}
+
+	if list := registry.List(); len(list) != 1 || list[0].Version != "1.0.0" {
+		t.Errorf("expected only the live plugin to be listed, got %d manifests", len(list))
+	}
+}
+
+func TestLoaderStartCanary_Errors(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader(t.TempDir(), t.TempDir(), registry)
+
+	if err := loader.StartCanary("notes", Rollout{}); !errors.Is(err, ErrPluginNotFound) {
+		t.Errorf("expected ErrPluginNotFound without a live plugin, got %v", err)
+	}
+
+	registerFake(registry, "notes", "1.0.0")
+	if err := loader.StartCanary("notes", Rollout{Percent: 101}); !errors.Is(err, ErrInvalidRollout) {
+		t.Errorf("expected ErrInvalidRollout, got %v", err)
+	}
+	if err := loader.StartCanary("notes", Rollout{Percent: 10}); !errors.Is(err, ErrNoCanaryBuild) {
+		t.Errorf("expected ErrNoCanaryBuild without a build on disk, got %v", err)
+	}
+	if err := loader.UpdateRollout("notes", Rollout{Percent: 10}); !errors.Is(err, ErrNoCanary) {
+		t.Errorf("expected ErrNoCanary, got %v", err)
+	}
+	if err := loader.PromoteCanary("notes"); !errors.Is(err, ErrNoCanary) {
+		t.Errorf("expected ErrNoCanary, got %v", err)
+	}
+}
+
+func TestLoaderStartCanary_EncryptedDatabase(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader(t.TempDir(), t.TempDir(), registry)
+	loader.SetDatabaseKey(make([]byte, 32))
+
+	registry.Register("notes", nil, &Manifest{ID: "notes", Permissions: []string{PermissionDBWrite}})
+	if err := loader.StartCanary("notes", Rollout{Percent: 10}); !errors.Is(err, ErrCanaryEncrypted) {
+		t.Errorf("expected ErrCanaryEncrypted for a plugin with an encrypted database, got %v", err)
+	}
+
+	registerFake(registry, "clock", "1.0.0")
+	if err := loader.StartCanary("clock", Rollout{Percent: 10}); !errors.Is(err, ErrNoCanaryBuild) {
+		t.Errorf("expected plugins without a database to allow canaries, got %v", err)
+	}
+}
+
+func TestLoaderUnloadPlugin_StopsCanary(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader(t.TempDir(), t.TempDir(), registry)
+	registerFake(registry, "notes", "1.0.0")
+	registerFake(registry, CanaryKey("notes"), "2.0.0")
+	registry.SetRollout("notes", Rollout{Percent: 50})
+
+	if err := loader.UnloadPlugin("notes"); err != nil {
+		t.Fatalf("unload failed: %v", err)
+	}
+	if _, ok := registry.Get(CanaryKey("notes")); ok {
+		t.Error("expected the canary to be unloaded with its live plugin")
+	}
+	if _, ok := registry.Rollout("notes"); ok {
+		t.Error("expected the rollout to be cleared")
+	}
+}
+
+func TestLoaderRollbackCanary(t *testing.T) {
+	pluginDir := t.TempDir()
+	registry := NewRegistry()
+	loader := NewLoader(pluginDir, t.TempDir(), registry)
+	registerFake(registry, "notes", "1.0.0")
+	registerFake(registry, CanaryKey("notes"), "2.0.0")
+	registry.SetRollout("notes", Rollout{Percent: 50})
+
+	build := loader.canaryPath("notes")
+	if err := os.MkdirAll(build, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := loader.Canary("notes")
+	if err != nil {
+		t.Fatalf("expected a canary, got %v", err)
+	}
+	if status.Version != "2.0.0" || status.LiveVersion != "1.0.0" || status.Percent != 50 {
+		t.Errorf("unexpected canary status %+v", status)
+	}
+
+	if err := loader.RollbackCanary("notes"); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if _, ok := registry.Get(CanaryKey("notes")); ok {
+		t.Error("expected the canary to be unloaded")
+	}
+	if _, ok := registry.Get("notes"); !ok {
+		t.Error("expected the live plugin to keep running")
+	}
+	if _, err := os.Stat(build); !os.IsNotExist(err) {
+		t.Errorf("expected the canary build to be removed, got %v", err)
+	}
+	if target := registry.RouteTarget("notes", 0); target != "notes" {
+		t.Errorf("expected all traffic on the live plugin, got %s", target)
+	}
+}
+
+func TestMovePluginBuild(t *testing.T) {
+	from := t.TempDir()
+	to := t.TempDir()
+	if err := os.Mkdir(filepath.Join(from, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(from, "notes"+ArchiveExtension), []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := movePluginBuild(from, to, "notes"); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	for _, name := range []string{"notes", "notes" + ArchiveExtension} {
+		if _, err := os.Stat(filepath.Join(to, name)); err != nil {
+			t.Errorf("expected %s to be moved: %v", name, err)
+		}
+	}
+
+	// Nothing left to move is not an error.
+	if err := movePluginBuild(from, to, "notes"); err != nil {
+		t.Errorf("expected no error for a missing build, got %v", err)
+	}
+}
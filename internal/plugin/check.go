@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CheckPluginDir validates the plugins LoadAll would find in pluginDir without
+// starting any of them. Each plugin needs a manifest with valid permissions,
+// routes and CSP extensions, routes no other plugin claims, the plugins it
+// depends on, and a plugin binary for the running platform. Archives are unpacked into a temporary
+// directory to be checked. It returns the IDs of the plugins that passed and
+// one error for each that did not.
+func CheckPluginDir(pluginDir string) (valid []string, problems []error, err error) {
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading plugin directory: %w", err)
+	}
+
+	registry := NewRegistry()
+	dependencies := make(map[string][]string)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		id := entry.Name()
+		var manifest *Manifest
+		var checkErr error
+		switch {
+		case entry.IsDir():
+			manifest, checkErr = checkPluginDirectory(filepath.Join(pluginDir, id))
+		case strings.HasSuffix(id, ArchiveExtension):
+			id = strings.TrimSuffix(id, ArchiveExtension)
+			if info, err := os.Stat(filepath.Join(pluginDir, id)); err == nil && info.IsDir() {
+				continue
+			}
+			manifest, checkErr = checkPluginArchive(filepath.Join(pluginDir, entry.Name()))
+		default:
+			continue
+		}
+
+		if checkErr == nil {
+			if route, owner, found := registry.routeConflict(id, manifest.Routes); found {
+				checkErr = fmt.Errorf("route %s is already claimed by plugin %s", route, owner)
+			}
+			if owner, found := registry.cliConflict(id, manifest); found {
+				checkErr = fmt.Errorf("cli name %s is already claimed by plugin %s", manifest.CLIName(), owner)
+			}
+		}
+		if checkErr != nil {
+			problems = append(problems, fmt.Errorf("plugin %s: %w", id, checkErr))
+			continue
+		}
+
+		registry.Register(id, nil, manifest)
+		dependencies[id] = manifest.Dependencies
+	}
+
+	valid, skipped := orderByDependencies(dependencies)
+	unmet := make([]string, 0, len(skipped))
+	for id := range skipped {
+		unmet = append(unmet, id)
+	}
+	sort.Strings(unmet)
+	for _, id := range unmet {
+		problems = append(problems, fmt.Errorf("plugin %s: %w", id, skipped[id]))
+	}
+	sort.Strings(valid)
+	return valid, problems, nil
+}
+
+// checkPluginDirectory validates an unpacked plugin the way launch does.
+func checkPluginDirectory(dir string) (*Manifest, error) {
+	manifestData, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+
+	if err := validatePermissions(manifest.Permissions); err != nil {
+		return nil, fmt.Errorf("validating manifest permissions: %w", err)
+	}
+
+	if err := validateRoutes(manifest.Routes); err != nil {
+		return nil, fmt.Errorf("validating manifest routes: %w", err)
+	}
+
+	if err := validateCSP(manifest.CSP); err != nil {
+		return nil, fmt.Errorf("validating manifest csp: %w", err)
+	}
+
+	if err := validateCommands(manifest.CLI, manifest.Commands); err != nil {
+		return nil, fmt.Errorf("validating manifest commands: %w", err)
+	}
+
+	if err := validateUpdateURL(manifest.UpdateURL); err != nil {
+		return nil, fmt.Errorf("validating manifest update_url: %w", err)
+	}
+
+	if err := validateWidgets(manifest.Widgets); err != nil {
+		return nil, fmt.Errorf("validating manifest widgets: %w", err)
+	}
+
+	if err := validateDependencies(manifest.ID, manifest.Dependencies); err != nil {
+		return nil, fmt.Errorf("validating manifest dependencies: %w", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "plugin"))
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("missing plugin binary: build it to %s", filepath.Join(dir, "plugin"))
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return nil, fmt.Errorf("plugin binary is not executable: chmod +x %s", filepath.Join(dir, "plugin"))
+	}
+
+	return &manifest, nil
+}
+
+// checkPluginArchive unpacks a plugin archive into a temporary directory and
+// validates it the way the installer does.
+func checkPluginArchive(path string) (*Manifest, error) {
+	archive, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening plugin archive: %w", err)
+	}
+	defer archive.Close()
+
+	staging, err := os.MkdirTemp("", "cortex-check-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	return unpackStaged(archive, staging)
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePluginDirectory writes an unpacked plugin with the given manifest.
+func writePluginDirectory(t *testing.T, pluginDir string, id string, manifest string, binaryMode os.FileMode) {
+	t.Helper()
+	dir := filepath.Join(pluginDir, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("creating plugin directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+	if binaryMode != 0 {
+		if err := os.WriteFile(filepath.Join(dir, "plugin"), []byte("#!/bin/sh\n"), binaryMode); err != nil {
+			t.Fatalf("writing plugin binary: %v", err)
+		}
+	}
+}
+
+func TestCheckPluginDir_ReportsEveryProblem(t *testing.T) {
+	pluginDir := t.TempDir()
+	writePluginDirectory(t, pluginDir, "finance", `{"id":"finance","routes":["/finance/*"]}`, 0755)
+	writePluginDirectory(t, pluginDir, "ledger", `{"id":"ledger","routes":["/finance/*"]}`, 0755)
+	writePluginDirectory(t, pluginDir, "broken", `{"id":`, 0755)
+	writePluginDirectory(t, pluginDir, "greedy", `{"id":"greedy","permissions":["root"]}`, 0755)
+	writePluginDirectory(t, pluginDir, "unbuilt", `{"id":"unbuilt"}`, 0)
+	writePluginDirectory(t, pluginDir, "stuck", `{"id":"stuck"}`, 0644)
+	writePluginArchive(t, pluginDir, "notes"+ArchiveExtension, validArchive(t, "notes"))
+	writePluginArchive(t, pluginDir, "foreign"+ArchiveExtension, buildArchive(t,
+		archiveEntry{name: "manifest.json", body: `{"id":"foreign"}`, mode: 0644},
+		archiveEntry{name: "backend/plan9-mips/plugin", body: "foreign", mode: 0755},
+	))
+
+	valid, problems, err := CheckPluginDir(pluginDir)
+	if err != nil {
+		t.Fatalf("CheckPluginDir failed: %v", err)
+	}
+
+	if strings.Join(valid, ",") != "finance,notes" {
+		t.Errorf("expected finance and notes to pass, got %v", valid)
+	}
+
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Error()
+	}
+	report := strings.Join(messages, "\n")
+	for _, want := range []string{
+		"plugin broken: parsing manifest",
+		"plugin greedy: validating manifest permissions",
+		"plugin ledger: route /finance/* is already claimed by plugin finance",
+		"plugin unbuilt: missing plugin binary",
+		"plugin stuck: plugin binary is not executable",
+		"plugin foreign: " + ErrUnsupportedPlatform.Error(),
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q in the problems, got:\n%s", want, report)
+		}
+	}
+	if len(problems) != 6 {
+		t.Errorf("expected 6 problems, got %d:\n%s", len(problems), report)
+	}
+}
+
+func TestCheckPluginDir_MissingDirectory(t *testing.T) {
+	if _, _, err := CheckPluginDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected an error for a missing plugin directory")
+	}
+}
@@ -0,0 +1,245 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Command argument types.
+const (
+	CommandArgString  = "string"
+	CommandArgNumber  = "number"
+	CommandArgBoolean = "boolean"
+)
+
+// commandNamePattern matches CLI group and command names, such as
+// "finance" and "add-expense".
+var commandNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// commandPlaceholderPattern matches the {arg} placeholders of a command path.
+var commandPlaceholderPattern = regexp.MustCompile(`\{([a-z][a-z0-9_]*)\}`)
+
+// reservedCLINames are the host's own subcommands, which plugins cannot claim.
+var reservedCLINames = map[string]bool{
+	"check-config": true,
+	"device":       true,
+	"help":         true,
+	"plugin":       true,
+}
+
+// Command is a CLI subcommand a plugin declares in its manifest, run as
+// `cortex {cli} {name} [args...]`. The host turns it into a request to the
+// plugin's API, so a command runs the same handler as the HTTP API does.
+type Command struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Method      string       `json:"method"`
+	Path        string       `json:"path"`
+	Args        []CommandArg `json:"args,omitempty"`
+	// Fields are sent with every run of the command, such as
+	// {"type": "expense"}, and cannot be overridden by arguments.
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+// CommandArg is a positional argument of a Command. It fills the {name}
+// placeholder of the command's path when there is one; otherwise it is sent
+// as the JSON body field name, or as a query parameter for GET and DELETE.
+// Optional arguments may only follow required ones.
+type CommandArg struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Optional    bool   `json:"optional,omitempty"`
+}
+
+// CLIName returns the group the manifest's commands are run under: its cli
+// field, or the plugin ID.
+func (m *Manifest) CLIName() string {
+	if m.CLI != "" {
+		return m.CLI
+	}
+	return m.ID
+}
+
+// FindCommand returns the manifest's command called name.
+func (m *Manifest) FindCommand(name string) (Command, bool) {
+	for _, command := range m.Commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// Usage returns the command's synopsis, such as "add-expense <amount> [description]".
+func (c Command) Usage() string {
+	parts := []string{c.Name}
+	for _, arg := range c.Args {
+		if arg.Optional {
+			parts = append(parts, "["+arg.Name+"]")
+		} else {
+			parts = append(parts, "<"+arg.Name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Request builds the API request running the command with args.
+func (c Command) Request(args []string) (*APIRequest, error) {
+	if len(args) > len(c.Args) {
+		return nil, fmt.Errorf("too many arguments; usage: %s", c.Usage())
+	}
+
+	path := c.Path
+	query := make(map[string]string)
+	fields := make(map[string]any, len(c.Fields)+len(args))
+	for i, arg := range c.Args {
+		if i >= len(args) {
+			if !arg.Optional {
+				return nil, fmt.Errorf("missing argument %s; usage: %s", arg.Name, c.Usage())
+			}
+			continue
+		}
+
+		raw := args[i]
+		placeholder := "{" + arg.Name + "}"
+		if strings.Contains(path, placeholder) {
+			path = strings.ReplaceAll(path, placeholder, url.PathEscape(raw))
+			continue
+		}
+
+		value, err := arg.parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		fields[arg.Name] = value
+		query[arg.Name] = raw
+	}
+
+	request := &APIRequest{Method: c.Method, Path: path, Query: map[string]string{}, Headers: map[string]string{}}
+	for name, value := range c.Fields {
+		fields[name] = value
+		query[name] = fmt.Sprint(value)
+	}
+
+	switch c.Method {
+	case http.MethodGet, http.MethodDelete:
+		request.Query = query
+	default:
+		body, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("encoding command body: %w", err)
+		}
+		request.Body = body
+		request.ContentType = "application/json"
+	}
+	return request, nil
+}
+
+// parse converts a command-line argument to the JSON value of its type.
+func (a CommandArg) parse(raw string) (any, error) {
+	switch a.Type {
+	case CommandArgNumber:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", a.Name, raw)
+		}
+		return value, nil
+	case CommandArgBoolean:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", a.Name, raw)
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+// validateCommands rejects a manifest's CLI group and commands unless they
+// can be run: valid names, known methods and argument types, and paths whose
+// placeholders all name arguments.
+func validateCommands(cli string, commands []Command) error {
+	if cli != "" && !commandNamePattern.MatchString(cli) {
+		return fmt.Errorf("invalid cli name %q: use lowercase letters, digits and dashes", cli)
+	}
+	if reservedCLINames[cli] {
+		return fmt.Errorf("invalid cli name %q: reserved for the host", cli)
+	}
+
+	seen := make(map[string]bool, len(commands))
+	for _, command := range commands {
+		if !commandNamePattern.MatchString(command.Name) {
+			return fmt.Errorf("invalid command name %q: use lowercase letters, digits and dashes", command.Name)
+		}
+		if seen[command.Name] {
+			return fmt.Errorf("command %q is declared twice", command.Name)
+		}
+		seen[command.Name] = true
+
+		switch command.Method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return fmt.Errorf("command %s: invalid method %q", command.Name, command.Method)
+		}
+		if !strings.HasPrefix(command.Path, "/") {
+			return fmt.Errorf("command %s: path must start with /", command.Name)
+		}
+
+		argNames := make(map[string]bool, len(command.Args))
+		optional := false
+		for _, arg := range command.Args {
+			if arg.Name == "" || argNames[arg.Name] {
+				return fmt.Errorf("command %s: argument names must be unique and not empty", command.Name)
+			}
+			argNames[arg.Name] = true
+			switch arg.Type {
+			case "", CommandArgString, CommandArgNumber, CommandArgBoolean:
+			default:
+				return fmt.Errorf("command %s: argument %s has invalid type %q", command.Name, arg.Name, arg.Type)
+			}
+			if optional && !arg.Optional {
+				return fmt.Errorf("command %s: required argument %s follows an optional one", command.Name, arg.Name)
+			}
+			optional = arg.Optional
+		}
+		for _, match := range commandPlaceholderPattern.FindAllStringSubmatch(command.Path, -1) {
+			if !argNames[match[1]] {
+				return fmt.Errorf("command %s: path placeholder {%s} is not an argument", command.Name, match[1])
+			}
+			for _, arg := range command.Args {
+				if arg.Name == match[1] && arg.Optional {
+					return fmt.Errorf("command %s: path argument %s cannot be optional", command.Name, arg.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// cliConflict reports another plugin whose commands already run under the
+// CLI group of manifest.
+func (r *Registry) cliConflict(pluginID string, manifest *Manifest) (owner string, found bool) {
+	if len(manifest.Commands) == 0 {
+		return "", false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cli := manifest.CLIName()
+	for id, entry := range r.plugins {
+		if id == pluginID || entry.Manifest == nil || isCanaryKey(id) || len(entry.Manifest.Commands) == 0 {
+			continue
+		}
+		if entry.Manifest.CLIName() == cli {
+			return id, true
+		}
+	}
+	return "", false
+}
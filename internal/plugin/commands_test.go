@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateCommands(t *testing.T) {
+	valid := []Command{
+		{Name: "add-expense", Method: "POST", Path: "/transactions", Args: []CommandArg{
+			{Name: "amount", Type: CommandArgNumber},
+			{Name: "description", Optional: true},
+		}},
+		{Name: "show", Method: "GET", Path: "/notes/{id}", Args: []CommandArg{{Name: "id"}}},
+	}
+	if err := validateCommands("finance", valid); err != nil {
+		t.Errorf("expected valid commands, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cli      string
+		commands []Command
+	}{
+		{"reserved cli name", "check-config", nil},
+		{"reserved plugin cli name", "plugin", nil},
+		{"invalid cli name", "Finance", nil},
+		{"invalid command name", "", []Command{{Name: "Add", Method: "POST", Path: "/x"}}},
+		{"duplicate command", "", []Command{{Name: "a", Method: "GET", Path: "/x"}, {Name: "a", Method: "GET", Path: "/y"}}},
+		{"unknown method", "", []Command{{Name: "a", Method: "RUN", Path: "/x"}}},
+		{"relative path", "", []Command{{Name: "a", Method: "GET", Path: "x"}}},
+		{"unknown type", "", []Command{{Name: "a", Method: "GET", Path: "/x", Args: []CommandArg{{Name: "n", Type: "int"}}}}},
+		{"required after optional", "", []Command{{Name: "a", Method: "GET", Path: "/x", Args: []CommandArg{{Name: "n", Optional: true}, {Name: "m"}}}}},
+		{"unknown placeholder", "", []Command{{Name: "a", Method: "GET", Path: "/x/{id}"}}},
+		{"optional placeholder", "", []Command{{Name: "a", Method: "GET", Path: "/x/{id}", Args: []CommandArg{{Name: "id", Optional: true}}}}},
+	}
+	for _, test := range tests {
+		if err := validateCommands(test.cli, test.commands); err == nil {
+			t.Errorf("%s: expected commands to be rejected", test.name)
+		}
+	}
+}
+
+func TestCommand_Request(t *testing.T) {
+	addExpense := Command{
+		Name:   "add-expense",
+		Method: "POST",
+		Path:   "/transactions",
+		Args: []CommandArg{
+			{Name: "amount", Type: CommandArgNumber},
+			{Name: "category"},
+			{Name: "description", Optional: true},
+		},
+		Fields: map[string]any{"type": "expense"},
+	}
+	if usage := addExpense.Usage(); usage != "add-expense <amount> <category> [description]" {
+		t.Errorf("unexpected usage %q", usage)
+	}
+
+	request, err := addExpense.Request([]string{"12.5", "coffee"})
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	if request.Method != "POST" || request.Path != "/transactions" || request.ContentType != "application/json" {
+		t.Errorf("unexpected request %+v", request)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(request.Body, &body); err != nil {
+		t.Fatalf("parsing body: %v", err)
+	}
+	if body["amount"] != 12.5 || body["category"] != "coffee" || body["type"] != "expense" || len(body) != 3 {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	for _, args := range [][]string{{"12.5"}, {"lots", "coffee"}, {"1", "2", "3", "4"}} {
+		if _, err := addExpense.Request(args); err == nil {
+			t.Errorf("expected %q to be rejected", args)
+		}
+	}
+
+	list := Command{Name: "list", Method: "GET", Path: "/projects/{slug}/notes", Args: []CommandArg{{Name: "slug"}, {Name: "q", Optional: true}}}
+	request, err = list.Request([]string{"my app", "draft"})
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	if request.Path != "/projects/my%20app/notes" || request.Query["q"] != "draft" || request.Body != nil {
+		t.Errorf("unexpected request %+v", request)
+	}
+	if _, ok := request.Query["slug"]; ok {
+		t.Error("expected path arguments to stay out of the query")
+	}
+}
+
+func TestRegistry_CLIConflict(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker", CLI: "finance", Commands: []Command{{Name: "add-expense"}}})
+
+	owner, found := registry.cliConflict("finance-reports", &Manifest{ID: "finance-reports", CLI: "finance", Commands: []Command{{Name: "report"}}})
+	if !found || owner != "finance-tracker" {
+		t.Errorf("expected finance to be claimed by finance-tracker, got %q, %v", owner, found)
+	}
+	if _, found := registry.cliConflict("finance-tracker", &Manifest{ID: "finance-tracker", CLI: "finance", Commands: []Command{{Name: "add-expense"}}}); found {
+		t.Error("expected a plugin not to conflict with itself")
+	}
+	if _, found := registry.cliConflict("finance-reports", &Manifest{ID: "finance-reports", CLI: "finance"}); found {
+		t.Error("expected a plugin without commands not to claim its cli name")
+	}
+	if cli := (&Manifest{ID: "quick-notes"}).CLIName(); cli != "quick-notes" {
+		t.Errorf("expected the cli name to default to the plugin id, got %q", cli)
+	}
+}
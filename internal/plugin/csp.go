@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// cspDirectives are the Content-Security-Policy directives a manifest may
+// extend: the fetch directives for resources a plugin's UI loads.
+var cspDirectives = map[string]bool{
+	"script-src":  true,
+	"style-src":   true,
+	"font-src":    true,
+	"img-src":     true,
+	"connect-src": true,
+	"media-src":   true,
+	"frame-src":   true,
+	"worker-src":  true,
+}
+
+// cspSourcePattern matches the sources a manifest may add: https or wss
+// origins, optionally with a wildcard subdomain, a port and a path, or the
+// data: and blob: schemes. Keywords such as 'unsafe-eval' cannot be added.
+var cspSourcePattern = regexp.MustCompile(`^((https|wss)://(\*\.)?[a-z0-9-]+(\.[a-z0-9-]+)*(:[0-9]+)?(/[^\s;,']*)?|data:|blob:)$`)
+
+// validateCSP rejects unknown directives and sources that are not plain
+// origins in a manifest's CSP extensions.
+func validateCSP(csp map[string][]string) error {
+	for directive, sources := range csp {
+		if !cspDirectives[directive] {
+			return fmt.Errorf("invalid csp directive %q: only fetch directives such as font-src can be extended", directive)
+		}
+		for _, source := range sources {
+			if !cspSourcePattern.MatchString(source) {
+				return fmt.Errorf("invalid csp source %q for %s: must be an https or wss origin, data: or blob:", source, directive)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package plugin
+
+import "testing"
+
+func TestValidateCSP(t *testing.T) {
+	if err := validateCSP(map[string][]string{
+		"font-src":    {"https://fonts.gstatic.com", "data:"},
+		"style-src":   {"https://fonts.googleapis.com/css2"},
+		"connect-src": {"wss://*.example.com:8443"},
+	}); err != nil {
+		t.Errorf("expected valid CSP extensions, got %v", err)
+	}
+
+	for _, csp := range []map[string][]string{
+		{"frame-ancestors": {"https://example.com"}},
+		{"default-src": {"https://example.com"}},
+		{"script-src": {"'unsafe-eval'"}},
+		{"script-src": {"*"}},
+		{"script-src": {"http://cdn.example.com"}},
+		{"style-src": {"https://example.com; script-src *"}},
+	} {
+		if err := validateCSP(csp); err == nil {
+			t.Errorf("expected %v to be rejected", csp)
+		}
+	}
+}
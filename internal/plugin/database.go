@@ -0,0 +1,179 @@
+package plugin
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// databasesLocked is set in the plugin process once its migrations have run.
+// From then on, the databases of a plugin without db:write are query-only.
+var databasesLocked atomic.Bool
+
+// lockDatabases makes the databases opened through GuardDatabase query-only,
+// unless the plugin was granted db:write. The plugin side of Migrate calls it
+// once the plugin's migrations succeeded.
+func lockDatabases() {
+	databasesLocked.Store(true)
+}
+
+// databaseWritable reports whether the host granted the plugin db:write.
+// Outside the host, where CORTEX_PLUGIN_PERMISSIONS is not set, databases
+// stay writable.
+func databaseWritable() bool {
+	permissions, ok := os.LookupEnv(permissionsEnv)
+	if !ok {
+		return true
+	}
+	for _, permission := range strings.Split(permissions, ",") {
+		if permission == PermissionDBWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// GuardDatabase wraps the connector of a plugin's SQLite database. For a
+// plugin without db:write, every connection is switched to PRAGMA query_only
+// before its next statement once the plugin's migrations have run, so
+// writes fail with SQLITE_READONLY whichever handler makes them.
+func GuardDatabase(connector driver.Connector) driver.Connector {
+	if databaseWritable() {
+		return connector
+	}
+	return &guardedConnector{Connector: connector}
+}
+
+// guardedConnector opens guardedConns.
+type guardedConnector struct {
+	driver.Connector
+}
+
+func (c *guardedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &guardedConn{Conn: conn}, nil
+}
+
+// Close closes the wrapped connector when it holds resources, as the
+// encrypted databases' does.
+func (c *guardedConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// guardedConn turns query-only before the first statement it runs after the
+// databases are locked. database/sql only reaches the connection through the
+// methods below, so none runs a statement unguarded.
+type guardedConn struct {
+	driver.Conn
+	queryOnly bool
+}
+
+// guard switches the connection to query-only once the databases are locked.
+func (c *guardedConn) guard(ctx context.Context) error {
+	if c.queryOnly || !databasesLocked.Load() {
+		return nil
+	}
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		if _, err := execer.ExecContext(ctx, "PRAGMA query_only = ON", nil); err != nil {
+			return err
+		}
+	} else {
+		statement, err := c.Conn.Prepare("PRAGMA query_only = ON")
+		if err != nil {
+			return err
+		}
+		_, err = statement.Exec(nil)
+		statement.Close()
+		if err != nil {
+			return err
+		}
+	}
+	c.queryOnly = true
+	return nil
+}
+
+func (c *guardedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *guardedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.guard(ctx); err != nil {
+		return nil, err
+	}
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *guardedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.guard(ctx); err != nil {
+		return nil, err
+	}
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *guardedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.guard(ctx); err != nil {
+		return nil, err
+	}
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *guardedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *guardedConn) BeginTx(ctx context.Context, options driver.TxOptions) (driver.Tx, error) {
+	if err := c.guard(ctx); err != nil {
+		return nil, err
+	}
+	// A query-only connection cannot take the write lock that SQLite's
+	// BEGIN IMMEDIATE does, so its transactions start deferred; writes in
+	// them still fail.
+	if c.queryOnly {
+		options.ReadOnly = true
+	}
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, options)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *guardedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *guardedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *guardedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DataBundleFormat identifies the bundles GET /api/plugins/{id}/export
+// produces, and DataBundleVersion their layout.
+const (
+	DataBundleFormat  = "cortex-plugin-data"
+	DataBundleVersion = 1
+)
+
+var (
+	// ErrInvalidBundle is returned for a bundle that is not one of
+	// DataBundleFormat, or was exported by another plugin.
+	ErrInvalidBundle = errors.New("invalid plugin data bundle")
+
+	// ErrBundleTooNew is returned for a bundle exported by a newer version of
+	// the plugin than the one installed, whose data it may not understand.
+	ErrBundleTooNew = errors.New("plugin data bundle is from a newer plugin version")
+)
+
+// DataBundle is a plugin's data as ExportData returned it, with what another
+// Cortex instance needs to import it: which plugin and version exported it
+// and when.
+type DataBundle struct {
+	Format        string          `json:"format"`
+	FormatVersion int             `json:"format_version"`
+	PluginID      string          `json:"plugin_id"`
+	PluginName    string          `json:"plugin_name"`
+	PluginVersion string          `json:"plugin_version"`
+	ExportedAt    time.Time       `json:"exported_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewDataBundle wraps data exported by the plugin of manifest at exportedAt.
+// The data must be JSON.
+func NewDataBundle(manifest *Manifest, data []byte, exportedAt time.Time) (*DataBundle, error) {
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("plugin %s exported data that is not JSON", manifest.ID)
+	}
+	return &DataBundle{
+		Format:        DataBundleFormat,
+		FormatVersion: DataBundleVersion,
+		PluginID:      manifest.ID,
+		PluginName:    manifest.Name,
+		PluginVersion: manifest.Version,
+		ExportedAt:    exportedAt.UTC(),
+		Data:          json.RawMessage(data),
+	}, nil
+}
+
+// CheckImport reports whether the plugin of manifest can import the bundle:
+// it must have been exported by the same plugin, at the same version or an
+// older one.
+func (b *DataBundle) CheckImport(manifest *Manifest) error {
+	switch {
+	case b.Format != DataBundleFormat:
+		return fmt.Errorf("%w: format must be %q", ErrInvalidBundle, DataBundleFormat)
+	case b.FormatVersion < 1 || b.FormatVersion > DataBundleVersion:
+		return fmt.Errorf("%w: unsupported format_version %d", ErrInvalidBundle, b.FormatVersion)
+	case b.PluginID != manifest.ID:
+		return fmt.Errorf("%w: exported by plugin %q, not %q", ErrInvalidBundle, b.PluginID, manifest.ID)
+	case len(b.Data) == 0 || !json.Valid(b.Data):
+		return fmt.Errorf("%w: data must be JSON", ErrInvalidBundle)
+	case b.PluginVersion == "":
+		return fmt.Errorf("%w: plugin_version is required", ErrInvalidBundle)
+	case compareVersions(b.PluginVersion, manifest.Version) > 0:
+		return fmt.Errorf("%w: exported at %s, installed %s", ErrBundleTooNew, b.PluginVersion, manifest.Version)
+	}
+	return nil
+}
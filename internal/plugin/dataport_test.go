@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDataBundle_CheckImport(t *testing.T) {
+	exporter := &Manifest{ID: "quick-notes", Name: "Quick Notes", Version: "1.2.0"}
+	bundle, err := NewDataBundle(exporter, []byte(`{"notes":[]}`), time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewDataBundle failed: %v", err)
+	}
+	if bundle.Format != DataBundleFormat || bundle.FormatVersion != DataBundleVersion || bundle.PluginVersion != "1.2.0" {
+		t.Errorf("expected a self-describing bundle, got %+v", bundle)
+	}
+
+	cases := []struct {
+		name      string
+		installed Manifest
+		edit      func(*DataBundle)
+		want      error
+	}{
+		{name: "same version", installed: *exporter},
+		{name: "newer install", installed: Manifest{ID: "quick-notes", Version: "1.10.0"}},
+		{name: "newer bundle", installed: Manifest{ID: "quick-notes", Version: "1.1.9"}, want: ErrBundleTooNew},
+		{name: "other plugin", installed: Manifest{ID: "project-hub", Version: "1.2.0"}, want: ErrInvalidBundle},
+		{name: "unknown format", installed: *exporter, edit: func(b *DataBundle) { b.Format = "sqlite" }, want: ErrInvalidBundle},
+		{name: "future layout", installed: *exporter, edit: func(b *DataBundle) { b.FormatVersion = DataBundleVersion + 1 }, want: ErrInvalidBundle},
+		{name: "no data", installed: *exporter, edit: func(b *DataBundle) { b.Data = nil }, want: ErrInvalidBundle},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			candidate := *bundle
+			if tc.edit != nil {
+				tc.edit(&candidate)
+			}
+			err := candidate.CheckImport(&tc.installed)
+			if tc.want == nil && err != nil {
+				t.Errorf("expected the bundle to be accepted, got %v", err)
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	if _, err := NewDataBundle(exporter, []byte("not json"), time.Now()); err == nil {
+		t.Error("expected an export that is not JSON to be rejected")
+	}
+}
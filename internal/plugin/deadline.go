@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// MinKeepaliveInterval is the shortest interval between keepalive pings
+	// plugin servers accept, and so the shortest the host may use.
+	MinKeepaliveInterval = 10 * time.Second
+	// keepaliveTimeout is how long the host waits for a ping to be answered
+	// before it closes the connection to a plugin.
+	keepaliveTimeout = 10 * time.Second
+)
+
+// SetRequestTimeout bounds every API request the host forwards to a plugin:
+// RequestContext gives it a deadline timeout away. Zero, the default, lets
+// requests run until the client goes away.
+func (l *Loader) SetRequestTimeout(timeout time.Duration) {
+	l.requestTimeout = timeout
+}
+
+// SetKeepalive makes the host ping each plugin's connection every interval,
+// so a frozen plugin or a broken connection is noticed between calls. Zero,
+// the default, disables pings; otherwise interval must be at least
+// MinKeepaliveInterval. Plugins built for a plugin API older than
+// keepaliveProtocol are never pinged.
+func (l *Loader) SetKeepalive(interval time.Duration) {
+	l.keepalive = interval
+}
+
+// RequestContext derives the context of a plugin API call from the context of
+// the HTTP request it serves, adding the request timeout.
+func (l *Loader) RequestContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if l.requestTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, l.requestTimeout)
+}
+
+// keepaliveOptions returns the dial options pinging a plugin that negotiated
+// the given plugin API, or none when it cannot take pings.
+func (l *Loader) keepaliveOptions(protocol int) []grpc.DialOption {
+	if l.keepalive <= 0 || protocol < keepaliveProtocol {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                l.keepalive,
+		Timeout:             keepaliveTimeout,
+		PermitWithoutStream: true,
+	})}
+}
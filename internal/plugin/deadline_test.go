@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingPlugin answers API requests only once their context is done,
+// reporting whether it had a deadline.
+type blockingPlugin struct {
+	fakePlugin
+	seen chan blockedCall
+}
+
+// blockedCall is what blockingPlugin saw of a call's context.
+type blockedCall struct {
+	err         error
+	hasDeadline bool
+	sameContext bool
+}
+
+func (p *blockingPlugin) HandleAPI(ctx context.Context, request *APIRequest) (*APIResponse, error) {
+	<-ctx.Done()
+	_, hasDeadline := ctx.Deadline()
+	p.seen <- blockedCall{err: ctx.Err(), hasDeadline: hasDeadline, sameContext: request.Context() == ctx}
+	return nil, ctx.Err()
+}
+
+func TestHandleAPI_PropagatesDeadline(t *testing.T) {
+	impl := &blockingPlugin{seen: make(chan blockedCall, 1)}
+	client := dispenseOverGRPC(t, impl)
+
+	loader := NewLoader(t.TempDir(), t.TempDir(), NewRegistry())
+	loader.SetRequestTimeout(50 * time.Millisecond)
+	ctx, cancel := loader.RequestContext(context.Background())
+	defer cancel()
+
+	started := time.Now()
+	_, err := client.HandleAPI(ctx, &APIRequest{Method: "GET", Path: "/slow"})
+	if !errors.Is(err, ErrPluginTimeout) {
+		t.Fatalf("expected ErrPluginTimeout, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("expected the call to give up at its deadline, took %s", elapsed)
+	}
+
+	select {
+	case seen := <-impl.seen:
+		// The plugin's own timer and the host's cancellation race to end it.
+		if seen.err == nil || !seen.hasDeadline || !seen.sameContext {
+			t.Errorf("expected the plugin's request context to carry the deadline, got %+v", seen)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the plugin's context to be done")
+	}
+}
+
+func TestHandleAPI_PropagatesCancellation(t *testing.T) {
+	impl := &blockingPlugin{seen: make(chan blockedCall, 1)}
+	client := dispenseOverGRPC(t, impl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := client.HandleAPI(ctx, &APIRequest{Method: "GET", Path: "/slow"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if seen := <-impl.seen; !errors.Is(seen.err, context.Canceled) || seen.hasDeadline {
+		t.Errorf("expected the plugin's context to be cancelled, got %+v", seen)
+	}
+}
+
+func TestRelease_IgnoresCancelledCalls(t *testing.T) {
+	loader := NewLoader(t.TempDir(), t.TempDir(), NewRegistry())
+	breaker := loader.registry.Breaker("notes")
+	breaker.trip()
+	breaker.openedAt = time.Now().Add(-breakerCooldown)
+
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected a trial call after the cooldown, got %v", err)
+	}
+	if crashed := loader.Release("notes", &RegistryEntry{}, context.Canceled); crashed {
+		t.Error("expected a cancelled call not to count as a crash")
+	}
+	if breaker.State() != BreakerHalfOpen {
+		t.Errorf("expected a cancelled trial to leave the circuit half-open, got %s", breaker.State())
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Errorf("expected the next call to become the trial, got %v", err)
+	}
+}
+
+func TestKeepaliveOptions_OnlyForPluginsThatAcceptPings(t *testing.T) {
+	loader := NewLoader(t.TempDir(), t.TempDir(), NewRegistry())
+	if options := loader.keepaliveOptions(SDKProtocolVersion); len(options) != 0 {
+		t.Error("expected no pings while keepalive is disabled")
+	}
+
+	loader.SetKeepalive(30 * time.Second)
+	if options := loader.keepaliveOptions(keepaliveProtocol - 1); len(options) != 0 {
+		t.Error("expected plugins on an older plugin API not to be pinged")
+	}
+	if options := loader.keepaliveOptions(keepaliveProtocol); len(options) != 1 {
+		t.Errorf("expected keepalive for plugins on API %d, got %d options", keepaliveProtocol, len(options))
+	}
+}
@@ -0,0 +1,216 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrMissingDependency is returned when a plugin is loaded before a plugin
+// it depends on is running.
+var ErrMissingDependency = errors.New("dependency is not loaded")
+
+// ErrHasDependents is returned when unloading a plugin other running plugins
+// depend on without forcing it.
+var ErrHasDependents = errors.New("plugin is required by other plugins")
+
+// DependentsError lists the running plugins that stop a plugin from being
+// unloaded. It matches ErrHasDependents.
+type DependentsError struct {
+	ID         string
+	Dependents []string
+}
+
+func (e *DependentsError) Error() string {
+	return fmt.Sprintf("plugin %s is required by %s", e.ID, strings.Join(e.Dependents, ", "))
+}
+
+func (e *DependentsError) Is(target error) bool { return target == ErrHasDependents }
+
+// DependencyNode is one plugin in the dependency graph: the plugins it
+// depends on, those among them that are not running, and the running
+// plugins that depend on it.
+type DependencyNode struct {
+	ID           string   `json:"id"`
+	Dependencies []string `json:"dependencies"`
+	Missing      []string `json:"missing,omitempty"`
+	Dependents   []string `json:"dependents"`
+}
+
+// validateDependencies rejects malformed, repeated, or self-referencing
+// dependency IDs.
+func validateDependencies(id string, dependencies []string) error {
+	seen := make(map[string]bool, len(dependencies))
+	for _, dependency := range dependencies {
+		if !pluginIDPattern.MatchString(dependency) {
+			return fmt.Errorf("invalid dependency %q: must be a plugin id", dependency)
+		}
+		if dependency == id {
+			return fmt.Errorf("plugin %s cannot depend on itself", id)
+		}
+		if seen[dependency] {
+			return fmt.Errorf("dependency %q is declared more than once", dependency)
+		}
+		seen[dependency] = true
+	}
+	return nil
+}
+
+// orderByDependencies sorts plugins so every plugin comes after the plugins
+// it depends on, alphabetically where the order is free. Plugins depending
+// on one that is not in the set, or on a cycle, cannot be started and are
+// returned in skipped with the reason.
+func orderByDependencies(dependencies map[string][]string) (order []string, skipped map[string]error) {
+	skipped = make(map[string]error)
+	pending := make(map[string]int, len(dependencies))
+	dependents := make(map[string][]string, len(dependencies))
+	for id, required := range dependencies {
+		for _, dependency := range required {
+			if _, ok := dependencies[dependency]; !ok {
+				skipped[id] = fmt.Errorf("%w: %s", ErrMissingDependency, dependency)
+				continue
+			}
+			pending[id]++
+			dependents[dependency] = append(dependents[dependency], id)
+		}
+	}
+
+	var ready []string
+	for id := range dependencies {
+		if pending[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, dependent := range dependents[id] {
+			if pending[dependent]--; pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	for id := range dependencies {
+		if pending[id] > 0 && skipped[id] == nil {
+			skipped[id] = errors.New("dependency cycle")
+		}
+	}
+
+	// A plugin whose dependency is skipped cannot start either.
+	started := make([]string, 0, len(order))
+	for _, id := range order {
+		if err := skippedDependency(id, dependencies[id], skipped); err != nil {
+			skipped[id] = err
+			continue
+		}
+		started = append(started, id)
+	}
+	return started, skipped
+}
+
+// skippedDependency reports the first of a plugin's dependencies that will
+// not be started.
+func skippedDependency(id string, required []string, skipped map[string]error) error {
+	if err, ok := skipped[id]; ok {
+		return err
+	}
+	for _, dependency := range required {
+		if _, ok := skipped[dependency]; ok {
+			return fmt.Errorf("%w: %s", ErrMissingDependency, dependency)
+		}
+	}
+	return nil
+}
+
+// missingDependencies returns the dependencies of manifest that are not
+// registered.
+func (r *Registry) missingDependencies(manifest *Manifest) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var missing []string
+	for _, dependency := range manifest.Dependencies {
+		if _, ok := r.plugins[dependency]; !ok {
+			missing = append(missing, dependency)
+		}
+	}
+	return missing
+}
+
+// Dependents returns the IDs of the running plugins that depend on id,
+// sorted. Canaries count through their live plugin.
+func (r *Registry) Dependents(id string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var dependents []string
+	for key, entry := range r.plugins {
+		if isCanaryKey(key) || entry.Manifest == nil {
+			continue
+		}
+		for _, dependency := range entry.Manifest.Dependencies {
+			if dependency == id {
+				dependents = append(dependents, key)
+				break
+			}
+		}
+	}
+	sort.Strings(dependents)
+	return dependents
+}
+
+// DependencyOrder returns the IDs of the running plugins in the order they
+// are started: every plugin after the plugins it depends on. Plugins whose
+// dependencies are no longer running come last.
+func (r *Registry) DependencyOrder() []string {
+	r.mu.RLock()
+	dependencies := make(map[string][]string, len(r.plugins))
+	for key, entry := range r.plugins {
+		if isCanaryKey(key) || entry.Manifest == nil {
+			continue
+		}
+		dependencies[key] = entry.Manifest.Dependencies
+	}
+	r.mu.RUnlock()
+
+	order, skipped := orderByDependencies(dependencies)
+	rest := make([]string, 0, len(skipped))
+	for id := range skipped {
+		rest = append(rest, id)
+	}
+	sort.Strings(rest)
+	return append(order, rest...)
+}
+
+// DependencyGraph describes the dependencies of every running plugin, in
+// DependencyOrder.
+func (r *Registry) DependencyGraph() []DependencyNode {
+	order := r.DependencyOrder()
+	nodes := make([]DependencyNode, 0, len(order))
+	for _, id := range order {
+		entry, ok := r.Get(id)
+		if !ok {
+			continue
+		}
+		nodes = append(nodes, DependencyNode{
+			ID:           id,
+			Dependencies: append([]string{}, entry.Manifest.Dependencies...),
+			Missing:      r.missingDependencies(entry.Manifest),
+			Dependents:   append([]string{}, r.Dependents(id)...),
+		})
+	}
+	return nodes
+}
+
+// CheckUnload returns a *DependentsError when running plugins depend on id,
+// so it should not be unloaded unless forced.
+func (l *Loader) CheckUnload(id string) error {
+	if dependents := l.registry.Dependents(id); len(dependents) > 0 {
+		return &DependentsError{ID: id, Dependents: dependents}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderByDependencies(t *testing.T) {
+	order, skipped := orderByDependencies(map[string][]string{
+		"reports":         {"finance-tracker", "project-hub"},
+		"finance-tracker": nil,
+		"project-hub":     {"quick-notes"},
+		"quick-notes":     nil,
+		"budgets":         {"missing"},
+		"forecasts":       {"budgets"},
+		"ping":            {"pong"},
+		"pong":            {"ping"},
+	})
+
+	want := []string{"finance-tracker", "quick-notes", "project-hub", "reports"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("expected startup order %v, got %v", want, order)
+	}
+	for _, id := range []string{"budgets", "forecasts"} {
+		if !errors.Is(skipped[id], ErrMissingDependency) {
+			t.Errorf("expected %s to be skipped for a missing dependency, got %v", id, skipped[id])
+		}
+	}
+	for _, id := range []string{"ping", "pong"} {
+		if skipped[id] == nil {
+			t.Errorf("expected %s to be skipped for the cycle", id)
+		}
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	if err := validateDependencies("reports", []string{"finance-tracker", "project-hub"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, dependencies := range [][]string{
+		{"reports"},
+		{"Finance"},
+		{"finance-tracker", "finance-tracker"},
+	} {
+		if err := validateDependencies("reports", dependencies); err == nil {
+			t.Errorf("expected %v to be rejected", dependencies)
+		}
+	}
+}
+
+func TestCheckUnload_RunningDependents(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker"})
+	registry.Register("reports", nil, &Manifest{ID: "reports", Dependencies: []string{"finance-tracker"}})
+	registry.Register(CanaryKey("reports"), nil, &Manifest{ID: "reports", Dependencies: []string{"finance-tracker"}})
+	loader := NewLoader(t.TempDir(), t.TempDir(), registry)
+
+	err := loader.CheckUnload("finance-tracker")
+	var dependents *DependentsError
+	if !errors.Is(err, ErrHasDependents) || !errors.As(err, &dependents) || !reflect.DeepEqual(dependents.Dependents, []string{"reports"}) {
+		t.Fatalf("expected reports to block the unload, got %v", err)
+	}
+	if err := loader.CheckUnload("reports"); err != nil {
+		t.Errorf("expected a plugin nothing depends on to unload, got %v", err)
+	}
+
+	if order := registry.DependencyOrder(); !reflect.DeepEqual(order, []string{"finance-tracker", "reports"}) {
+		t.Errorf("unexpected dependency order %v", order)
+	}
+	registry.Unregister("finance-tracker")
+	graph := registry.DependencyGraph()
+	if len(graph) != 1 || !reflect.DeepEqual(graph[0].Missing, []string{"finance-tracker"}) {
+		t.Errorf("expected the unloaded dependency to be reported missing, got %+v", graph)
+	}
+}
@@ -2,6 +2,12 @@ package plugin
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
 )
@@ -10,6 +16,9 @@ import (
 // It implements the CortexPlugin interface by translating calls to gRPC.
 type GRPCClient struct {
 	client pb.CortexPluginClient
+	// sdk is the plugin API the plugin negotiated; optional hooks it does not
+	// report are answered with ErrNotImplemented without a call.
+	sdk SDKInfo
 }
 
 func (c *GRPCClient) GetManifest() (*Manifest, error) {
@@ -29,22 +38,30 @@ func (c *GRPCClient) GetManifest() (*Manifest, error) {
 	}, nil
 }
 
-func (c *GRPCClient) HandleAPI(request *APIRequest) (*APIResponse, error) {
-	response, err := c.client.HandleAPI(context.Background(), &pb.APIRequest{
-		Method: request.Method,
-		Path:   request.Path,
-		Body:   request.Body,
-		Query:  request.Query,
-	})
+func (c *GRPCClient) HandleAPI(ctx context.Context, request *APIRequest) (*APIResponse, error) {
+	response, err := c.client.HandleAPI(ctx, &pb.APIRequest{
+		Method:      request.Method,
+		Path:        request.Path,
+		Body:        request.Body,
+		Query:       request.Query,
+		Headers:     request.Headers,
+		ContentType: request.ContentType,
+		RequestId:   request.RequestID,
+	}, grpc.MaxCallRecvMsgSize(apiMessageSize))
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
-	return &APIResponse{
+	apiResponse := &APIResponse{
 		StatusCode:  int(response.StatusCode),
 		Body:        response.Body,
 		ContentType: response.ContentType,
-	}, nil
+		Headers:     response.Headers,
+	}
+	if response.Deleted != nil {
+		apiResponse.Deleted = &DeletedEntity{Kind: response.Deleted.Kind, Data: response.Deleted.Data}
+	}
+	return apiResponse, nil
 }
 
 func (c *GRPCClient) GetWidgetData(slot string) ([]byte, error) {
@@ -65,3 +82,256 @@ func (c *GRPCClient) Teardown() error {
 	_, err := c.client.Teardown(context.Background(), &pb.Empty{})
 	return err
 }
+
+// MigrateSettings asks the plugin to upgrade settings written by fromVersion.
+// It returns ErrNotImplemented if the plugin does not implement SettingsMigrator.
+func (c *GRPCClient) MigrateSettings(fromVersion string, settings []byte) ([]byte, error) {
+	if !c.sdk.Supports(CapabilitySettingsMigration) {
+		return nil, notCapable("MigrateSettings")
+	}
+	response, err := c.client.MigrateSettings(context.Background(), &pb.SettingsMigrationRequest{
+		FromVersion:  fromVersion,
+		SettingsJson: settings,
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return response.SettingsJson, nil
+}
+
+// Search asks the plugin for records matching query.
+// It returns ErrNotImplemented if the plugin does not implement Searcher.
+func (c *GRPCClient) Search(query string) ([]SearchResult, error) {
+	if !c.sdk.Supports(CapabilitySearch) {
+		return nil, notCapable("Search")
+	}
+	response, err := c.client.Search(context.Background(), &pb.SearchRequest{Query: query})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	results := make([]SearchResult, 0, len(response.Results))
+	for _, result := range response.Results {
+		results = append(results, SearchResult{
+			Type:    result.Type,
+			ID:      result.Id,
+			Title:   result.Title,
+			Snippet: result.Snippet,
+			Link:    result.Link,
+		})
+	}
+	return results, nil
+}
+
+// Migrations asks the plugin for the SQL migrations it runs in Migrate.
+// It returns ErrNotImplemented if the plugin does not implement MigrationLister.
+func (c *GRPCClient) Migrations() ([]MigrationFile, error) {
+	if !c.sdk.Supports(CapabilityMigrations) {
+		return nil, notCapable("Migrations")
+	}
+	response, err := c.client.ListMigrations(context.Background(), &pb.Empty{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	files := make([]MigrationFile, 0, len(response.Files))
+	for _, file := range response.Files {
+		files = append(files, MigrationFile{Name: file.Name, SQL: file.Sql})
+	}
+	return files, nil
+}
+
+// Warmup asks the plugin to prepare for its first request.
+// It returns ErrNotImplemented if the plugin does not implement Warmer.
+func (c *GRPCClient) Warmup() error {
+	if !c.sdk.Supports(CapabilityWarmup) {
+		return notCapable("Warmup")
+	}
+	_, err := c.client.Warmup(context.Background(), &pb.Empty{})
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// Upgrade asks the plugin to finish an upgrade from fromVersion.
+// It returns ErrNotImplemented if the plugin does not implement Upgrader.
+func (c *GRPCClient) Upgrade(fromVersion string) error {
+	if !c.sdk.Supports(CapabilityUpgrade) {
+		return notCapable("Upgrade")
+	}
+	_, err := c.client.Upgrade(context.Background(), &pb.UpgradeRequest{FromVersion: fromVersion})
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// SeedDemo asks the plugin to fill its database with sample data.
+// It returns ErrNotImplemented if the plugin does not implement DemoSeeder.
+func (c *GRPCClient) SeedDemo() error {
+	if !c.sdk.Supports(CapabilityDemoSeed) {
+		return notCapable("SeedDemo")
+	}
+	_, err := c.client.SeedDemo(context.Background(), &pb.Empty{})
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// SyncPull asks the plugin for the records changed after cursor.
+// It returns ErrNotImplemented if the plugin does not implement Syncer.
+func (c *GRPCClient) SyncPull(cursor string, limit int) (*SyncPage, error) {
+	if !c.sdk.Supports(CapabilitySync) {
+		return nil, notCapable("SyncPull")
+	}
+	response, err := c.client.SyncPull(context.Background(), &pb.SyncPullRequest{Cursor: cursor, Limit: int32(limit)})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	page := &SyncPage{Records: make([]SyncRecord, 0, len(response.Records)), Cursor: response.Cursor, HasMore: response.HasMore}
+	for _, record := range response.Records {
+		page.Records = append(page.Records, syncRecordFromProto(record))
+	}
+	return page, nil
+}
+
+// SyncPush sends changes made offline to the plugin.
+// It returns ErrNotImplemented if the plugin does not implement Syncer.
+func (c *GRPCClient) SyncPush(changes []SyncChange) ([]SyncResult, error) {
+	if !c.sdk.Supports(CapabilitySync) {
+		return nil, notCapable("SyncPush")
+	}
+	request := &pb.SyncPushRequest{Changes: make([]*pb.SyncChange, 0, len(changes))}
+	for _, change := range changes {
+		request.Changes = append(request.Changes, &pb.SyncChange{
+			Collection:  change.Collection,
+			Id:          change.ID,
+			ClientId:    change.ClientID,
+			BaseVersion: change.BaseVersion,
+			Deleted:     change.Deleted,
+			Data:        change.Data,
+		})
+	}
+
+	response, err := c.client.SyncPush(context.Background(), request)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	results := make([]SyncResult, 0, len(response.Results))
+	for _, result := range response.Results {
+		item := SyncResult{
+			Collection: result.Collection,
+			ID:         result.Id,
+			ClientID:   result.ClientId,
+			Status:     result.Status,
+			Version:    result.Version,
+			Message:    result.Message,
+		}
+		if result.Current != nil {
+			current := syncRecordFromProto(result.Current)
+			item.Current = &current
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// RestoreDeleted hands the plugin back records one of its deletes removed.
+// It returns ErrNotImplemented if the plugin does not implement Restorer, and
+// an error wrapping ErrRestoreConflict if the records cannot be put back.
+func (c *GRPCClient) RestoreDeleted(kind string, data []byte) error {
+	if !c.sdk.Supports(CapabilityRestore) {
+		return notCapable("RestoreDeleted")
+	}
+	_, err := c.client.RestoreDeleted(context.Background(), &pb.DeletedEntity{Kind: kind, Data: data})
+	if status.Code(err) == codes.AlreadyExists {
+		return fmt.Errorf("%w: %s", ErrRestoreConflict, status.Convert(err).Message())
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// ExportData asks the plugin for all of its records.
+// It returns ErrNotImplemented if the plugin does not implement DataPorter.
+func (c *GRPCClient) ExportData() ([]byte, error) {
+	if !c.sdk.Supports(CapabilityDataPort) {
+		return nil, notCapable("ExportData")
+	}
+	response, err := c.client.ExportData(context.Background(), &pb.Empty{}, grpc.MaxCallRecvMsgSize(apiMessageSize))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return response.Data, nil
+}
+
+// ImportData asks the plugin to replace its records with data it exported at
+// fromVersion. It returns ErrNotImplemented if the plugin does not implement
+// DataPorter, and an error wrapping ErrInvalidData if it rejects the data.
+func (c *GRPCClient) ImportData(fromVersion string, data []byte) error {
+	if !c.sdk.Supports(CapabilityDataPort) {
+		return notCapable("ImportData")
+	}
+	_, err := c.client.ImportData(context.Background(), &pb.ImportDataRequest{FromVersion: fromVersion, Data: data})
+	if status.Code(err) == codes.InvalidArgument {
+		return fmt.Errorf("%w: %s", ErrInvalidData, status.Convert(err).Message())
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// Capabilities asks the plugin which optional hooks it implements. Plugins
+// on API version 1 do not answer it.
+func (c *GRPCClient) Capabilities() ([]string, error) {
+	response, err := c.client.Capabilities(context.Background(), &pb.Empty{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if response.Capabilities == nil {
+		return []string{}, nil
+	}
+	return response.Capabilities, nil
+}
+
+func syncRecordFromProto(record *pb.SyncRecord) SyncRecord {
+	return SyncRecord{
+		Collection: record.Collection,
+		ID:         record.Id,
+		Version:    record.Version,
+		Deleted:    record.Deleted,
+		Data:       record.Data,
+		ChangedAt:  record.ChangedAt,
+	}
+}
+
+// translateError maps the gRPC status codes of missing optional hooks, missed
+// deadlines and cancelled calls to package errors.
+func translateError(err error) error {
+	switch status.Code(err) {
+	case codes.Unimplemented:
+		return fmt.Errorf("%w: %s", ErrNotImplemented, status.Convert(err).Message())
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %s", ErrPluginTimeout, status.Convert(err).Message())
+	case codes.Canceled:
+		return fmt.Errorf("%w: %s", context.Canceled, status.Convert(err).Message())
+	}
+	return err
+}
+
+// notCapable is the error of an optional hook the plugin reported it lacks.
+func notCapable(hook string) error {
+	return fmt.Errorf("%w: plugin does not implement %s", ErrNotImplemented, hook)
+}
+
+// isNotImplemented reports whether err means the plugin lacks an optional hook.
+func isNotImplemented(err error) bool {
+	return errors.Is(err, ErrNotImplemented)
+}
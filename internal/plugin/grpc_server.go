@@ -2,33 +2,67 @@ package plugin
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
 	goplugin "github.com/hashicorp/go-plugin"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
 )
 
 // CortexGRPCPlugin implements go-plugin's GRPCPlugin interface.
 // It bridges the HashiCorp go-plugin system with the gRPC transport layer.
+// Impl is set on the plugin side; PluginID and Listener on the host side.
 type CortexGRPCPlugin struct {
 	goplugin.Plugin
 	Impl CortexPlugin
+
+	PluginID string
+	Listener ChangeListener
+	// Attachments is nil for plugins without the attachments permission.
+	Attachments AttachmentStore
+	// Notifier is nil for plugins without the notifications permission.
+	Notifier Notifier
+	// Caller is nil for plugins without the plugins:read permission.
+	Caller PluginCaller
+	// Values and Scheduler back the plugin's HostServices.
+	Values    ValueStore
+	Scheduler JobScheduler
+	// Settings serves GetSettings.
+	Settings SettingsStore
 }
 
 func (p *CortexGRPCPlugin) GRPCServer(broker *goplugin.GRPCBroker, server *grpc.Server) error {
-	pb.RegisterCortexPluginServer(server, &grpcServer{impl: p.Impl})
+	pb.RegisterCortexPluginServer(server, &grpcServer{impl: p.Impl, broker: broker})
 	return nil
 }
 
 func (p *CortexGRPCPlugin) GRPCClient(ctx context.Context, broker *goplugin.GRPCBroker, connection *grpc.ClientConn) (interface{}, error) {
-	return &GRPCClient{client: pb.NewCortexPluginClient(connection)}, nil
+	client := pb.NewCortexPluginClient(connection)
+	host := &hostServer{
+		pluginID:    p.PluginID,
+		listener:    p.Listener,
+		attachments: p.Attachments,
+		notifier:    p.Notifier,
+		caller:      p.Caller,
+		values:      p.Values,
+		scheduler:   p.Scheduler,
+		settings:    p.Settings,
+	}
+	if err := serveHost(broker, client, host); err != nil {
+		return nil, err
+	}
+	return &GRPCClient{client: client}, nil
 }
 
 // grpcServer wraps a CortexPlugin implementation to serve over gRPC (plugin side).
 type grpcServer struct {
 	pb.UnimplementedCortexPluginServer
-	impl CortexPlugin
+	impl   CortexPlugin
+	broker *goplugin.GRPCBroker
 }
 
 func (s *grpcServer) GetManifest(ctx context.Context, _ *pb.Empty) (*pb.PluginManifest, error) {
@@ -49,21 +83,31 @@ func (s *grpcServer) GetManifest(ctx context.Context, _ *pb.Empty) (*pb.PluginMa
 }
 
 func (s *grpcServer) HandleAPI(ctx context.Context, request *pb.APIRequest) (*pb.APIResponse, error) {
-	response, err := s.impl.HandleAPI(&APIRequest{
-		Method: request.Method,
-		Path:   request.Path,
-		Body:   request.Body,
-		Query:  request.Query,
+	response, err := s.impl.HandleAPI(ctx, &APIRequest{
+		Method:      request.Method,
+		Path:        request.Path,
+		Body:        request.Body,
+		Query:       request.Query,
+		Headers:     request.Headers,
+		ContentType: request.ContentType,
+		RequestID:   request.RequestId,
+		ctx:         ctx,
 	})
 	if err != nil {
+		slog.Error("handling API request", "method", request.Method, "path", request.Path, "request_id", request.RequestId, "error", err)
 		return nil, err
 	}
 
-	return &pb.APIResponse{
+	pbResponse := &pb.APIResponse{
 		StatusCode:  int32(response.StatusCode),
 		Body:        response.Body,
 		ContentType: response.ContentType,
-	}, nil
+		Headers:     response.Headers,
+	}
+	if response.Deleted != nil {
+		pbResponse.Deleted = &pb.DeletedEntity{Kind: response.Deleted.Kind, Data: response.Deleted.Data}
+	}
+	return pbResponse, nil
 }
 
 func (s *grpcServer) GetWidgetData(ctx context.Context, request *pb.WidgetRequest) (*pb.WidgetData, error) {
@@ -80,6 +124,7 @@ func (s *grpcServer) Migrate(ctx context.Context, request *pb.MigrateRequest) (*
 	if err != nil {
 		return &pb.MigrateResult{Success: false, Message: err.Error()}, nil
 	}
+	lockDatabases()
 
 	return &pb.MigrateResult{Success: true, Message: "ok"}, nil
 }
@@ -87,3 +132,222 @@ func (s *grpcServer) Migrate(ctx context.Context, request *pb.MigrateRequest) (*
 func (s *grpcServer) Teardown(ctx context.Context, _ *pb.Empty) (*pb.Empty, error) {
 	return &pb.Empty{}, s.impl.Teardown()
 }
+
+func (s *grpcServer) MigrateSettings(ctx context.Context, request *pb.SettingsMigrationRequest) (*pb.SettingsMigrationResult, error) {
+	migrator, ok := s.impl.(SettingsMigrator)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement MigrateSettings")
+	}
+
+	migrated, err := migrator.MigrateSettings(request.FromVersion, request.SettingsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SettingsMigrationResult{SettingsJson: migrated}, nil
+}
+
+func (s *grpcServer) Search(ctx context.Context, request *pb.SearchRequest) (*pb.SearchResponse, error) {
+	searcher, ok := s.impl.(Searcher)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement Search")
+	}
+
+	results, err := searcher.Search(request.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.SearchResponse{Results: make([]*pb.SearchResult, 0, len(results))}
+	for _, result := range results {
+		response.Results = append(response.Results, &pb.SearchResult{
+			Type:    result.Type,
+			Id:      result.ID,
+			Title:   result.Title,
+			Snippet: result.Snippet,
+			Link:    result.Link,
+		})
+	}
+	return response, nil
+}
+
+func (s *grpcServer) ListMigrations(ctx context.Context, request *pb.Empty) (*pb.MigrationList, error) {
+	lister, ok := s.impl.(MigrationLister)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement Migrations")
+	}
+
+	files, err := lister.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.MigrationList{Files: make([]*pb.MigrationFile, 0, len(files))}
+	for _, file := range files {
+		response.Files = append(response.Files, &pb.MigrationFile{Name: file.Name, Sql: file.SQL})
+	}
+	return response, nil
+}
+
+func (s *grpcServer) Capabilities(ctx context.Context, request *pb.Empty) (*pb.CapabilityList, error) {
+	return &pb.CapabilityList{Capabilities: capabilitiesOf(s.impl)}, nil
+}
+
+func (s *grpcServer) Warmup(ctx context.Context, request *pb.Empty) (*pb.Empty, error) {
+	warmer, ok := s.impl.(Warmer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement Warmup")
+	}
+
+	if err := warmer.Warmup(); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *grpcServer) Upgrade(ctx context.Context, request *pb.UpgradeRequest) (*pb.Empty, error) {
+	upgrader, ok := s.impl.(Upgrader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement Upgrade")
+	}
+
+	if err := upgrader.Upgrade(request.FromVersion); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *grpcServer) RestoreDeleted(ctx context.Context, request *pb.DeletedEntity) (*pb.Empty, error) {
+	restorer, ok := s.impl.(Restorer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement RestoreDeleted")
+	}
+
+	err := restorer.RestoreDeleted(request.Kind, request.Data)
+	if errors.Is(err, ErrRestoreConflict) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *grpcServer) ExportData(ctx context.Context, request *pb.Empty) (*pb.PluginData, error) {
+	porter, ok := s.impl.(DataPorter)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement ExportData")
+	}
+
+	data, err := porter.ExportData()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PluginData{Data: data}, nil
+}
+
+func (s *grpcServer) ImportData(ctx context.Context, request *pb.ImportDataRequest) (*pb.Empty, error) {
+	porter, ok := s.impl.(DataPorter)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement ImportData")
+	}
+
+	err := porter.ImportData(request.FromVersion, request.Data)
+	if errors.Is(err, ErrInvalidData) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *grpcServer) SeedDemo(ctx context.Context, request *pb.Empty) (*pb.Empty, error) {
+	seeder, ok := s.impl.(DemoSeeder)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement SeedDemo")
+	}
+
+	if err := seeder.SeedDemo(); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *grpcServer) SyncPull(ctx context.Context, request *pb.SyncPullRequest) (*pb.SyncPage, error) {
+	syncer, ok := s.impl.(Syncer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement SyncPull")
+	}
+
+	page, err := syncer.SyncPull(request.Cursor, int(request.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.SyncPage{Records: make([]*pb.SyncRecord, 0, len(page.Records)), Cursor: page.Cursor, HasMore: page.HasMore}
+	for i := range page.Records {
+		response.Records = append(response.Records, syncRecordToProto(&page.Records[i]))
+	}
+	return response, nil
+}
+
+func (s *grpcServer) SyncPush(ctx context.Context, request *pb.SyncPushRequest) (*pb.SyncPushResponse, error) {
+	syncer, ok := s.impl.(Syncer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement SyncPush")
+	}
+
+	changes := make([]SyncChange, 0, len(request.Changes))
+	for _, change := range request.Changes {
+		changes = append(changes, SyncChange{
+			Collection:  change.Collection,
+			ID:          change.Id,
+			ClientID:    change.ClientId,
+			BaseVersion: change.BaseVersion,
+			Deleted:     change.Deleted,
+			Data:        change.Data,
+		})
+	}
+
+	results, err := syncer.SyncPush(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.SyncPushResponse{Results: make([]*pb.SyncResult, 0, len(results))}
+	for _, result := range results {
+		item := &pb.SyncResult{
+			Collection: result.Collection,
+			Id:         result.ID,
+			ClientId:   result.ClientID,
+			Status:     result.Status,
+			Version:    result.Version,
+			Message:    result.Message,
+		}
+		if result.Current != nil {
+			item.Current = syncRecordToProto(result.Current)
+		}
+		response.Results = append(response.Results, item)
+	}
+	return response, nil
+}
+
+func (s *grpcServer) ConnectHost(ctx context.Context, request *pb.ConnectHostRequest) (*pb.Empty, error) {
+	if err := connectHost(s.broker, request.BrokerId); err != nil {
+		return nil, err
+	}
+	useHost(s.impl)
+	return &pb.Empty{}, nil
+}
+
+func syncRecordToProto(record *SyncRecord) *pb.SyncRecord {
+	return &pb.SyncRecord{
+		Collection: record.Collection,
+		Id:         record.ID,
+		Version:    record.Version,
+		Deleted:    record.Deleted,
+		Data:       record.Data,
+		ChangedAt:  record.ChangedAt,
+	}
+}
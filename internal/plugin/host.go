@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+// maxTopicLength bounds the topic a plugin passes to NotifyChanged.
+const maxTopicLength = 100
+
+// ErrHostNotConnected is returned by plugin-side host calls made before the
+// host has connected, e.g. from main() before sdk.Serve.
+var ErrHostNotConnected = errors.New("host is not connected")
+
+// ChangeListener is told when a plugin reports that its data changed, so
+// subscribers such as dashboard widgets can refresh. The server's event hub
+// implements it.
+type ChangeListener interface {
+	PluginChanged(pluginID string, topic string)
+}
+
+// --- Host side ---
+
+// serveHost starts the CortexHost service for one plugin on the go-plugin
+// broker and tells the plugin where to reach it. Plugins built against an SDK
+// without ConnectHost are left without a host connection.
+func serveHost(broker *goplugin.GRPCBroker, client pb.CortexPluginClient, host *hostServer) error {
+	brokerID := broker.NextId()
+	go broker.AcceptAndServe(brokerID, func(options []grpc.ServerOption) *grpc.Server {
+		server := grpc.NewServer(append(options, grpc.MaxRecvMsgSize(attachmentMessageSize))...)
+		pb.RegisterCortexHostServer(server, host)
+		return server
+	})
+
+	_, err := client.ConnectHost(context.Background(), &pb.ConnectHostRequest{BrokerId: brokerID})
+	if err != nil && status.Code(err) != codes.Unimplemented {
+		return fmt.Errorf("connecting plugin to host: %w", err)
+	}
+	return nil
+}
+
+// hostServer handles calls from one plugin back into the host.
+type hostServer struct {
+	pb.UnimplementedCortexHostServer
+	pluginID    string
+	listener    ChangeListener
+	attachments AttachmentStore
+	notifier    Notifier
+	caller      PluginCaller
+	values      ValueStore
+	scheduler   JobScheduler
+	settings    SettingsStore
+}
+
+func (s *hostServer) NotifyChanged(ctx context.Context, request *pb.ChangeNotification) (*pb.Empty, error) {
+	if request.Topic == "" || len(request.Topic) > maxTopicLength {
+		return nil, status.Errorf(codes.InvalidArgument, "topic must be between 1 and %d characters", maxTopicLength)
+	}
+
+	if s.listener != nil {
+		s.listener.PluginChanged(s.pluginID, request.Topic)
+	}
+	return &pb.Empty{}, nil
+}
+
+// --- Plugin side ---
+
+// hostConnection is the plugin process's client for the CortexHost service,
+// set once the host calls ConnectHost.
+var hostConnection struct {
+	mu     sync.RWMutex
+	client pb.CortexHostClient
+}
+
+func connectHost(broker *goplugin.GRPCBroker, brokerID uint32) error {
+	connection, err := broker.Dial(brokerID)
+	if err != nil {
+		return fmt.Errorf("dialing host: %w", err)
+	}
+
+	hostConnection.mu.Lock()
+	defer hostConnection.mu.Unlock()
+	hostConnection.client = pb.NewCortexHostClient(connection)
+	return nil
+}
+
+func hostClient() (pb.CortexHostClient, error) {
+	hostConnection.mu.RLock()
+	defer hostConnection.mu.RUnlock()
+
+	if hostConnection.client == nil {
+		return nil, ErrHostNotConnected
+	}
+	return hostConnection.client, nil
+}
+
+// NotifyChanged tells the host that the plugin's data for topic changed, so
+// dashboards subscribed to /api/ws refresh the affected widgets. It is called
+// from within a plugin.
+func NotifyChanged(topic string) error {
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.NotifyChanged(context.Background(), &pb.ChangeNotification{Topic: topic})
+	return err
+}
@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingListener is a ChangeListener that records every notification.
+type recordingListener struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+func (l *recordingListener) PluginChanged(pluginID string, topic string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, pluginID+":"+topic)
+}
+
+// connectOverGRPC dispenses a fake plugin whose host calls reach listener.
+func connectOverGRPC(t *testing.T, pluginID string, listener ChangeListener) {
+	t.Helper()
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: pluginID, Listener: listener})
+}
+
+// connectPluginOverGRPC dispenses grpcPlugin, so host calls made from this
+// process go through its host server, and returns the host's client of it.
+func connectPluginOverGRPC(t *testing.T, grpcPlugin *CortexGRPCPlugin) *GRPCClient {
+	t.Helper()
+
+	client, _ := goplugin.TestPluginGRPCConn(t, false, map[string]goplugin.Plugin{
+		"cortex_plugin": grpcPlugin,
+	})
+	t.Cleanup(func() {
+		client.Close()
+		hostConnection.mu.Lock()
+		hostConnection.client = nil
+		hostConnection.mu.Unlock()
+		scheduledJobs.mu.Lock()
+		scheduledJobs.jobs = nil
+		scheduledJobs.mu.Unlock()
+	})
+
+	raw, err := client.Dispense("cortex_plugin")
+	if err != nil {
+		t.Fatalf("failed to dispense plugin: %v", err)
+	}
+	return raw.(*GRPCClient)
+}
+
+func TestNotifyChanged_ReachesHostListener(t *testing.T) {
+	listener := &recordingListener{}
+	connectOverGRPC(t, "quick-notes", listener)
+
+	if err := NotifyChanged("notes"); err != nil {
+		t.Fatalf("NotifyChanged failed: %v", err)
+	}
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	if len(listener.changes) != 1 || listener.changes[0] != "quick-notes:notes" {
+		t.Errorf("expected one change for quick-notes:notes, got %v", listener.changes)
+	}
+}
+
+func TestNotifyChanged_RejectsInvalidTopic(t *testing.T) {
+	listener := &recordingListener{}
+	connectOverGRPC(t, "quick-notes", listener)
+
+	for _, topic := range []string{"", strings.Repeat("x", maxTopicLength+1)} {
+		if err := NotifyChanged(topic); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for a %d-character topic, got %v", len(topic), err)
+		}
+	}
+	if len(listener.changes) != 0 {
+		t.Errorf("expected invalid topics not to reach the listener, got %v", listener.changes)
+	}
+}
+
+func TestNotifyChanged_WithoutHost(t *testing.T) {
+	if err := NotifyChanged("notes"); !errors.Is(err, ErrHostNotConnected) {
+		t.Errorf("expected ErrHostNotConnected, got %v", err)
+	}
+}
+
+// memoryAttachments is an AttachmentStore that keeps contents in memory.
+type memoryAttachments struct {
+	mu       sync.Mutex
+	contents map[int64][]byte
+	owners   map[int64]string
+}
+
+func (m *memoryAttachments) Put(pluginID string, name string, contentType string, content []byte) (*Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := int64(len(m.contents) + 1)
+	m.contents[id], m.owners[id] = content, pluginID
+	return &Attachment{ID: id, Name: name, ContentType: contentType, Size: int64(len(content))}, nil
+}
+
+func (m *memoryAttachments) Get(pluginID string, id int64) (*Attachment, []byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.owners[id] != pluginID {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	return &Attachment{ID: id, Size: int64(len(m.contents[id]))}, m.contents[id], nil
+}
+
+func (m *memoryAttachments) Delete(pluginID string, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.owners[id] != pluginID {
+		return ErrAttachmentNotFound
+	}
+	delete(m.owners, id)
+	return nil
+}
+
+func TestAttachments_RoundTripOverHost(t *testing.T) {
+	store := &memoryAttachments{contents: map[int64][]byte{}, owners: map[int64]string{}}
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "finance-tracker", Attachments: store})
+
+	// Larger than gRPC's default 4 MiB message limit.
+	content := make([]byte, 5<<20)
+	attachment, err := PutAttachment("statement.pdf", "application/pdf", content)
+	if err != nil {
+		t.Fatalf("PutAttachment failed: %v", err)
+	}
+
+	_, stored, err := GetAttachment(attachment.ID)
+	if err != nil {
+		t.Fatalf("GetAttachment failed: %v", err)
+	}
+	if len(stored) != len(content) {
+		t.Errorf("expected %d bytes back, got %d", len(content), len(stored))
+	}
+
+	if err := DeleteAttachment(attachment.ID); err != nil {
+		t.Fatalf("DeleteAttachment failed: %v", err)
+	}
+	if _, _, err := GetAttachment(attachment.ID); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Errorf("expected ErrAttachmentNotFound after deleting, got %v", err)
+	}
+}
+
+func TestAttachments_RequirePermission(t *testing.T) {
+	connectOverGRPC(t, "quick-notes", &recordingListener{})
+
+	if _, err := PutAttachment("note.txt", "", []byte("hello")); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied without the attachments permission, got %v", err)
+	}
+}
+
+// recordingNotifier records the notifications sent through it.
+type recordingNotifier struct {
+	sources []string
+	titles  []string
+	urgent  []bool
+}
+
+func (r *recordingNotifier) SendNotification(source string, title string, body string, urgent bool) error {
+	r.sources = append(r.sources, source)
+	r.titles = append(r.titles, title)
+	r.urgent = append(r.urgent, urgent)
+	return nil
+}
+
+func TestSendNotification_ReachesHostNotifier(t *testing.T) {
+	notifier := &recordingNotifier{}
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{PluginID: "finance-tracker", Notifier: notifier})
+
+	if err := SendNotification("Food is over budget", "Spent 320.00 of 300.00", true); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if len(notifier.titles) != 1 || notifier.sources[0] != "finance-tracker" || !notifier.urgent[0] {
+		t.Errorf("expected one urgent notification from finance-tracker, got %+v", notifier)
+	}
+
+	if err := SendNotification("  ", "", false); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an empty title, got %v", err)
+	}
+}
+
+func TestSendNotification_RequiresPermission(t *testing.T) {
+	connectOverGRPC(t, "quick-notes", &recordingListener{})
+
+	if err := SendNotification("Reminder", "", false); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied without the notifications permission, got %v", err)
+	}
+}
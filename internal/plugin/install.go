@@ -0,0 +1,433 @@
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// maxArchiveSize caps downloaded plugin archives.
+	maxArchiveSize = 256 << 20
+	// maxUnpackedSize caps the total size of the files in an archive, so a
+	// small compressed archive cannot fill the disk.
+	maxUnpackedSize = 1 << 30
+	// downloadTimeout bounds a single archive or registry download.
+	downloadTimeout = 5 * time.Minute
+)
+
+// Errors returned by the installer. The HTTP layer maps them to error codes.
+var (
+	ErrPluginExists       = errors.New("plugin already exists")
+	ErrRegistryNotFound   = errors.New("plugin not found in registry")
+	ErrNoRegistry         = errors.New("no plugin registry configured")
+	ErrChecksumMismatch   = errors.New("archive checksum mismatch")
+	ErrSignatureInvalid   = errors.New("archive signature invalid")
+	ErrSignatureRequired  = errors.New("archive signature required")
+	ErrInvalidArchive     = errors.New("invalid plugin archive")
+	ErrDownloadFailed     = errors.New("downloading plugin failed")
+	errArchiveTooLarge    = fmt.Errorf("%w: archive too large", ErrInvalidArchive)
+	errUnsafeArchivePath  = fmt.Errorf("%w: unsafe path", ErrInvalidArchive)
+	errUnsupportedEntry   = fmt.Errorf("%w: unsupported entry type", ErrInvalidArchive)
+	errMissingPluginFiles = fmt.Errorf("%w: manifest.json and plugin binary are required", ErrInvalidArchive)
+)
+
+// pluginIDPattern restricts plugin IDs to names that are safe as directory names.
+var pluginIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// InstallSource describes a plugin archive to download and how to verify it.
+// SHA256 is the hex digest of the archive; Signature is a base64 Ed25519
+// signature of the archive bytes.
+type InstallSource struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// registryIndex is the JSON document served at the registry URL.
+type registryIndex struct {
+	Plugins []struct {
+		ID string `json:"id"`
+		InstallSource
+	} `json:"plugins"`
+}
+
+// Installer downloads, verifies and unpacks plugin archives into the plugin
+// directory. Archives use the .cortexplugin format (see ArchiveExtension).
+type Installer struct {
+	pluginDir   string
+	registryURL string
+	publicKey   ed25519.PublicKey
+	client      *http.Client
+}
+
+// NewInstaller creates an installer writing to pluginDir. registryURL may be
+// empty to disable installs by name. When publicKey is set, every archive must
+// carry a valid signature from it.
+func NewInstaller(pluginDir string, registryURL string, publicKey ed25519.PublicKey) *Installer {
+	return &Installer{
+		pluginDir:   pluginDir,
+		registryURL: registryURL,
+		publicKey:   publicKey,
+		client:      &http.Client{Timeout: downloadTimeout},
+	}
+}
+
+// Resolve looks up a plugin by name in the configured registry.
+func (i *Installer) Resolve(name string) (*InstallSource, error) {
+	if i.registryURL == "" {
+		return nil, ErrNoRegistry
+	}
+
+	response, err := i.client.Get(i.registryURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: fetching registry: %v", ErrDownloadFailed, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: registry returned status %d", ErrDownloadFailed, response.StatusCode)
+	}
+
+	var index registryIndex
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxArchiveSize)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("%w: parsing registry: %v", ErrDownloadFailed, err)
+	}
+
+	for _, entry := range index.Plugins {
+		if entry.ID == name {
+			source := entry.InstallSource
+			return &source, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrRegistryNotFound, name)
+}
+
+// Install downloads the archive, verifies it and unpacks it into the plugin
+// directory. It returns the plugin ID declared by the archive's manifest.
+func (i *Installer) Install(source InstallSource) (string, error) {
+	staging, manifest, err := i.stage(source)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+
+	target := filepath.Join(i.pluginDir, manifest.ID)
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("%w: %s", ErrPluginExists, manifest.ID)
+	}
+
+	if err := os.Rename(staging, target); err != nil {
+		return "", fmt.Errorf("moving plugin into place: %w", err)
+	}
+
+	return manifest.ID, nil
+}
+
+// InstallCanary downloads and verifies a new build of plugin id and unpacks it
+// as that plugin's canary build, replacing any previous one. The live plugin
+// is left untouched until the canary is promoted.
+func (i *Installer) InstallCanary(source InstallSource, id string) error {
+	staging, manifest, err := i.stage(source)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	if manifest.ID != id {
+		return fmt.Errorf("%w: archive declares plugin id %q, expected %q", ErrInvalidArchive, manifest.ID, id)
+	}
+
+	canaryDir := filepath.Join(i.pluginDir, canaryDirName)
+	if err := os.MkdirAll(canaryDir, 0755); err != nil {
+		return fmt.Errorf("creating canary directory: %w", err)
+	}
+
+	target := filepath.Join(canaryDir, id)
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("removing previous canary build: %w", err)
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return fmt.Errorf("moving canary build into place: %w", err)
+	}
+	return nil
+}
+
+// StageUpdate downloads and verifies a new build of plugin id and unpacks it
+// into a staging directory, whose path it returns, for Loader.Upgrade. The
+// caller removes the directory once the update is done.
+func (i *Installer) StageUpdate(source InstallSource, id string) (string, error) {
+	staging, manifest, err := i.stage(source)
+	if err != nil {
+		return "", err
+	}
+
+	if manifest.ID != id {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("%w: archive declares plugin id %q, expected %q", ErrInvalidArchive, manifest.ID, id)
+	}
+	return staging, nil
+}
+
+// stage downloads and verifies an archive and unpacks it into a new staging
+// directory inside the plugin directory. The caller removes the staging
+// directory once it has been moved into place or is no longer needed.
+func (i *Installer) stage(source InstallSource) (string, *Manifest, error) {
+	expected, err := hex.DecodeString(strings.TrimSpace(source.SHA256))
+	if err != nil || len(expected) != sha256.Size {
+		return "", nil, fmt.Errorf("%w: sha256 must be a hex digest", ErrChecksumMismatch)
+	}
+
+	if err := os.MkdirAll(i.pluginDir, 0755); err != nil {
+		return "", nil, fmt.Errorf("creating plugin directory: %w", err)
+	}
+
+	archive, err := i.download(source.URL)
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := i.verify(archive, expected, source.Signature); err != nil {
+		return "", nil, err
+	}
+
+	// Unpack next to the final location so the move is a rename on the same filesystem.
+	staging, err := os.MkdirTemp(i.pluginDir, ".install-")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+
+	manifest, err := unpackStaged(archive, staging)
+	if err != nil {
+		os.RemoveAll(staging)
+		return "", nil, err
+	}
+	return staging, manifest, nil
+}
+
+// unpackStaged unpacks a verified archive into staging and reads its manifest.
+func unpackStaged(archive *os.File, staging string) (*Manifest, error) {
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewinding archive: %w", err)
+	}
+	if err := unpackArchive(archive, staging); err != nil {
+		return nil, err
+	}
+	if err := selectPlatformBinary(staging); err != nil {
+		return nil, err
+	}
+	return readStagedManifest(staging)
+}
+
+// Remove deletes an installed plugin's directory, used to roll back an
+// install whose plugin then fails to load.
+func (i *Installer) Remove(id string) error {
+	if !pluginIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid plugin id %q", id)
+	}
+	return os.RemoveAll(filepath.Join(i.pluginDir, id))
+}
+
+// download fetches the archive into a temporary file.
+func (i *Installer) download(url string) (*os.File, error) {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil, fmt.Errorf("%w: url must be http or https", ErrDownloadFailed)
+	}
+
+	response, err := i.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: server returned status %d", ErrDownloadFailed, response.StatusCode)
+	}
+
+	file, err := os.CreateTemp("", "cortex-plugin-*.tar.gz")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp file: %w", err)
+	}
+
+	written, err := io.Copy(file, io.LimitReader(response.Body, maxArchiveSize+1))
+	if err == nil && written > maxArchiveSize {
+		err = errArchiveTooLarge
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		if errors.Is(err, ErrInvalidArchive) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+
+	return file, nil
+}
+
+// verify checks the archive checksum and, when a public key is configured, its signature.
+func (i *Installer) verify(archive *os.File, expected []byte, signature string) error {
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding archive: %w", err)
+	}
+
+	data, err := io.ReadAll(archive)
+	if err != nil {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+	if !bytes.Equal(digest[:], expected) {
+		return ErrChecksumMismatch
+	}
+
+	if i.publicKey == nil {
+		return nil
+	}
+
+	if signature == "" {
+		return ErrSignatureRequired
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(i.publicKey, data, decoded) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// unpackArchive extracts a gzipped tarball into dir, rejecting entries that
+// would escape it, links, and archives that expand beyond maxUnpackedSize.
+func unpackArchive(reader io.Reader, dir string) error {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	var unpacked int64
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%w: %s", errUnsafeArchivePath, header.Name)
+		}
+		path := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return fmt.Errorf("creating %s: %w", name, err)
+			}
+		case tar.TypeReg:
+			unpacked += header.Size
+			if unpacked > maxUnpackedSize {
+				return errArchiveTooLarge
+			}
+			if err := writeArchiveFile(path, tarReader, header); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %s", errUnsupportedEntry, header.Name)
+		}
+	}
+}
+
+// writeArchiveFile writes one regular file from the archive, keeping only the
+// executable bit of its mode.
+func writeArchiveFile(path string, reader io.Reader, header *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", header.Name, err)
+	}
+
+	mode := os.FileMode(0644)
+	if header.Mode&0111 != 0 {
+		mode = 0755
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", header.Name, err)
+	}
+	defer file.Close()
+
+	if _, err := io.CopyN(file, reader, header.Size); err != nil {
+		return fmt.Errorf("%w: writing %s: %v", ErrInvalidArchive, header.Name, err)
+	}
+	return nil
+}
+
+// readStagedManifest validates the unpacked plugin and returns its manifest.
+func readStagedManifest(dir string) (*Manifest, error) {
+	manifestData, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, errMissingPluginFiles
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "plugin"))
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, errMissingPluginFiles
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: parsing manifest: %v", ErrInvalidArchive, err)
+	}
+
+	if !pluginIDPattern.MatchString(manifest.ID) {
+		return nil, fmt.Errorf("%w: invalid plugin id %q", ErrInvalidArchive, manifest.ID)
+	}
+
+	if err := validatePermissions(manifest.Permissions); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	if err := validateRoutes(manifest.Routes); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	if err := validateCSP(manifest.CSP); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	if err := validateCommands(manifest.CLI, manifest.Commands); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	if err := validateUpdateURL(manifest.UpdateURL); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	if err := validateWidgets(manifest.Widgets); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,230 @@
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveEntry is one file in a test plugin archive.
+type archiveEntry struct {
+	name string
+	body string
+	mode int64
+}
+
+// buildArchive returns a gzipped tarball with the given entries.
+func buildArchive(t *testing.T, entries ...archiveEntry) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: entry.mode, Size: int64(len(entry.body)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("writing tar header: %v", err)
+		}
+		if _, err := tarWriter.Write([]byte(entry.body)); err != nil {
+			t.Fatalf("writing tar entry: %v", err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("closing tar: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("closing gzip: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+// validArchive returns an archive for a plugin with the given ID.
+func validArchive(t *testing.T, id string) []byte {
+	return buildArchive(t,
+		archiveEntry{name: "manifest.json", body: `{"id":"` + id + `","name":"Test","version":"1.0.0","permissions":["db:read"]}`, mode: 0644},
+		archiveEntry{name: "plugin", body: "#!/bin/sh\n", mode: 0755},
+		archiveEntry{name: "frontend/index.html", body: "<html></html>", mode: 0644},
+	)
+}
+
+// serveBytes serves body at every path and returns the server URL.
+func serveBytes(t *testing.T, body []byte) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func checksum(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+func TestInstall_UnpacksVerifiedArchive(t *testing.T) {
+	pluginDir := t.TempDir()
+	archive := validArchive(t, "weather")
+	url := serveBytes(t, archive)
+
+	installer := NewInstaller(pluginDir, "", nil)
+	id, err := installer.Install(InstallSource{URL: url + "/weather.tar.gz", SHA256: checksum(archive)})
+	if err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	if id != "weather" {
+		t.Errorf("expected plugin id weather, got %s", id)
+	}
+
+	info, err := os.Stat(filepath.Join(pluginDir, "weather", "plugin"))
+	if err != nil {
+		t.Fatalf("expected plugin binary to be unpacked: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Error("expected plugin binary to be executable")
+	}
+	if _, err := os.Stat(filepath.Join(pluginDir, "weather", "frontend", "index.html")); err != nil {
+		t.Errorf("expected frontend assets to be unpacked: %v", err)
+	}
+
+	// A second install of the same plugin is refused.
+	if _, err := installer.Install(InstallSource{URL: url, SHA256: checksum(archive)}); !errors.Is(err, ErrPluginExists) {
+		t.Errorf("expected ErrPluginExists, got %v", err)
+	}
+}
+
+func TestInstall_ChecksumMismatch(t *testing.T) {
+	pluginDir := t.TempDir()
+	archive := validArchive(t, "weather")
+	url := serveBytes(t, archive)
+
+	installer := NewInstaller(pluginDir, "", nil)
+	_, err := installer.Install(InstallSource{URL: url, SHA256: checksum([]byte("something else"))})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(pluginDir, "weather")); !os.IsNotExist(err) {
+		t.Error("expected nothing to be unpacked on checksum mismatch")
+	}
+}
+
+func TestInstall_Signature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	archive := validArchive(t, "weather")
+	url := serveBytes(t, archive)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, archive))
+	forged := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte("other")))
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   error
+	}{
+		{name: "missing", signature: "", wantErr: ErrSignatureRequired},
+		{name: "forged", signature: forged, wantErr: ErrSignatureInvalid},
+		{name: "valid", signature: signature, wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer := NewInstaller(t.TempDir(), "", publicKey)
+			_, err := installer.Install(InstallSource{URL: url, SHA256: checksum(archive), Signature: tt.signature})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestInstall_RejectsUnsafeArchives(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{
+			name: "path traversal",
+			archive: buildArchive(t,
+				archiveEntry{name: "manifest.json", body: `{"id":"evil"}`, mode: 0644},
+				archiveEntry{name: "plugin", body: "x", mode: 0755},
+				archiveEntry{name: "../../escape", body: "x", mode: 0644},
+			),
+		},
+		{
+			name:    "missing binary",
+			archive: buildArchive(t, archiveEntry{name: "manifest.json", body: `{"id":"evil"}`, mode: 0644}),
+		},
+		{
+			name: "invalid id",
+			archive: buildArchive(t,
+				archiveEntry{name: "manifest.json", body: `{"id":"../evil"}`, mode: 0644},
+				archiveEntry{name: "plugin", body: "x", mode: 0755},
+			),
+		},
+		{
+			name: "unknown permission",
+			archive: buildArchive(t,
+				archiveEntry{name: "manifest.json", body: `{"id":"evil","permissions":["root"]}`, mode: 0644},
+				archiveEntry{name: "plugin", body: "x", mode: 0755},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginDir := t.TempDir()
+			installer := NewInstaller(pluginDir, "", nil)
+
+			_, err := installer.Install(InstallSource{URL: serveBytes(t, tt.archive), SHA256: checksum(tt.archive)})
+			if !errors.Is(err, ErrInvalidArchive) {
+				t.Fatalf("expected ErrInvalidArchive, got %v", err)
+			}
+
+			entries, _ := os.ReadDir(pluginDir)
+			if len(entries) != 0 {
+				t.Errorf("expected plugin directory to stay empty, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestResolve_RegistryName(t *testing.T) {
+	index := []byte(`{"plugins":[{"id":"weather","url":"https://example.com/weather.tar.gz","sha256":"abc","signature":"sig"}]}`)
+	installer := NewInstaller(t.TempDir(), serveBytes(t, index), nil)
+
+	source, err := installer.Resolve("weather")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if source.URL != "https://example.com/weather.tar.gz" || source.SHA256 != "abc" || source.Signature != "sig" {
+		t.Errorf("unexpected source: %+v", source)
+	}
+
+	if _, err := installer.Resolve("missing"); !errors.Is(err, ErrRegistryNotFound) {
+		t.Errorf("expected ErrRegistryNotFound, got %v", err)
+	}
+}
+
+func TestResolve_NoRegistry(t *testing.T) {
+	installer := NewInstaller(t.TempDir(), "", nil)
+	if _, err := installer.Resolve("weather"); !errors.Is(err, ErrNoRegistry) {
+		t.Errorf("expected ErrNoRegistry, got %v", err)
+	}
+}
@@ -1,18 +1,227 @@
 package plugin
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+
 	"github.com/hashicorp/go-plugin"
 )
 
 // CortexPlugin is the interface that all plugins must implement.
+//
+// The context of HandleAPI is cancelled when the client goes away or the
+// host's request timeout (CORTEX_PLUGIN_TIMEOUT) passes; by then the host has
+// already answered, so long handlers should pass it to their queries and stop
+// when it is done. It is also available as request.Context().
 type CortexPlugin interface {
 	GetManifest() (*Manifest, error)
-	HandleAPI(request *APIRequest) (*APIResponse, error)
+	HandleAPI(ctx context.Context, request *APIRequest) (*APIResponse, error)
 	GetWidgetData(slot string) ([]byte, error)
 	Migrate(databasePath string) error
 	Teardown() error
 }
 
+// SettingsMigrator is an optional interface for plugins whose settings schema
+// changes between versions. On load, when the stored settings were written by a
+// different plugin version, the host passes them to MigrateSettings and stores
+// the result, instead of letting validation fail and reset them to defaults.
+type SettingsMigrator interface {
+	MigrateSettings(fromVersion string, settings []byte) ([]byte, error)
+}
+
+// Upgrader is an optional interface for plugins with one-off work to do after
+// an upgrade that their SQL migrations cannot express, such as rebuilding a
+// search index or backfilling computed values. When a plugin loads with a
+// version other than the one of its last successful load, the host calls
+// Upgrade with that version after Migrate and MigrateSettings, before
+// Warmup. The very first load is not an upgrade. A failed upgrade stops the
+// plugin from loading, like a failed migration, and runs again on the next
+// load.
+type Upgrader interface {
+	Upgrade(fromVersion string) error
+}
+
+// Searcher is an optional interface for plugins with searchable data. The
+// host's GET /api/search calls Search on every plugin implementing it and
+// ranks the combined results, so one query finds notes, projects and
+// transactions alike. Plugins return their best matches first; the host caps
+// and re-ranks them.
+type Searcher interface {
+	Search(query string) ([]SearchResult, error)
+}
+
+// MaxSearchResults is how many results of one plugin's Search the host ranks;
+// any beyond it are dropped.
+const MaxSearchResults = 50
+
+// SearchResult is one match returned by a plugin's Search. Type names the kind
+// of record, such as "note" or "transaction", and Link is the frontend path
+// that opens it.
+type SearchResult struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+	Link    string `json:"link"`
+}
+
+// MigrationLister is an optional interface for plugins with SQL migrations.
+// The host lints the listed files before calling Migrate, so a migration that
+// would drop or duplicate data is reported, or refused, before it runs.
+type MigrationLister interface {
+	Migrations() ([]MigrationFile, error)
+}
+
+// MigrationFile is one SQL migration a plugin runs in Migrate.
+type MigrationFile struct {
+	Name string `json:"name"`
+	SQL  string `json:"sql"`
+}
+
+// Warmer is an optional interface for plugins with work worth doing before
+// their first request, such as priming caches, preparing statements or
+// checking data consistency. The host calls Warmup once the plugin has
+// migrated and before routing requests to it, after every load or reload, and
+// reports how long it took in the load history. A failed warm-up is logged;
+// the plugin still loads.
+type Warmer interface {
+	Warmup() error
+}
+
+// SettingsListener is an optional interface for plugins that apply their
+// settings while running. The host calls OnSettingsChanged with the settings
+// JSON every time they are saved, wiped or restored from the dashboard, so a
+// changed default currency or refresh interval applies without a reload.
+// Settings that refer to secrets still need a reload for the secrets to change.
+type SettingsListener interface {
+	OnSettingsChanged(settings []byte) error
+}
+
+// DemoSeeder is an optional interface for plugins that can fill their database
+// with realistic sample data, kept apart from their schema migrations so a
+// real instance never starts with it. In demo mode the host calls SeedDemo
+// once per plugin data directory, after Migrate; seeders should leave a
+// database that already holds data untouched.
+type DemoSeeder interface {
+	SeedDemo() error
+}
+
+// Syncer is an optional interface for plugins whose records can be kept in
+// sync with an offline client. The host exposes it as GET and POST
+// /api/plugins/{id}/sync, the same for every plugin, so a mobile app needs one
+// sync loop rather than one per plugin.
+//
+// SyncPull returns the records changed after cursor, oldest change first, and
+// the cursor to pull from next; an empty cursor starts from the beginning.
+// Cursors are opaque to the host and the client. SyncPush applies changes made
+// offline and returns one result per change, in order.
+type Syncer interface {
+	SyncPull(cursor string, limit int) (*SyncPage, error)
+	SyncPush(changes []SyncChange) ([]SyncResult, error)
+}
+
+// Restorer is an optional interface for plugins whose deletes can be undone.
+// A delete handler attaches a copy of the records it removed to its response
+// with sdk.WithDeleted; the host keeps it in its undo log, returns a token for
+// it in the X-Undo-Token header and, if POST /api/undo/{token} arrives within
+// the undo window, hands it back to RestoreDeleted with the kind it was given.
+// RestoreDeleted returns an error wrapping ErrRestoreConflict when the records
+// cannot be put back without overwriting data saved since.
+type Restorer interface {
+	RestoreDeleted(kind string, data []byte) error
+}
+
+// DataPorter is an optional interface for plugins whose data can move
+// between Cortex instances without copying their database. ExportData
+// returns all of the plugin's records as JSON; ImportData replaces them with
+// data ExportData returned, here or on another instance, when the plugin was
+// at fromVersion. The host wraps the export in a self-describing bundle for
+// GET /api/plugins/{id}/export and unwraps it for POST
+// /api/plugins/{id}/import. ImportData returns an error wrapping
+// ErrInvalidData when the data cannot be imported, and must then leave the
+// plugin's records as they were.
+type DataPorter interface {
+	ExportData() ([]byte, error)
+	ImportData(fromVersion string, data []byte) error
+}
+
+// ErrInvalidData is returned by ImportData when the data is not an export
+// the plugin can import.
+var ErrInvalidData = errors.New("invalid plugin data")
+
+// ErrRestoreConflict is returned by RestoreDeleted when a deleted record's ID,
+// or another of its unique values, has been taken again.
+var ErrRestoreConflict = errors.New("deleted record conflicts with current data")
+
+// HostUser is an optional interface for plugins that use the services the
+// host offers them: a key-value store, notifications, scheduled jobs and the
+// host's log. UseHost is called once per plugin process, as soon as the host
+// has connected and before Migrate, and should return quickly; plugins
+// usually keep the handle and schedule their jobs there.
+type HostUser interface {
+	UseHost(host *HostServices)
+}
+
+// Sync push result statuses.
+const (
+	// SyncApplied means the change was saved; Version is the record's new
+	// version.
+	SyncApplied = "applied"
+	// SyncConflict means the record changed since BaseVersion. The change was
+	// not saved and Current holds the record as the plugin has it.
+	SyncConflict = "conflict"
+	// SyncRejected means the change was invalid; Message says why.
+	SyncRejected = "rejected"
+)
+
+// SyncRecord is one record in a sync page. Collection names the kind of
+// record, such as "notes", and Version increases on every change to it. A
+// deleted record is a tombstone: Deleted is set and Data is empty.
+type SyncRecord struct {
+	Collection string          `json:"collection"`
+	ID         string          `json:"id"`
+	Version    int64           `json:"version"`
+	Deleted    bool            `json:"deleted"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	ChangedAt  string          `json:"changed_at"`
+}
+
+// SyncPage is a page of changed records returned by SyncPull.
+type SyncPage struct {
+	Records []SyncRecord `json:"records"`
+	Cursor  string       `json:"cursor"`
+	HasMore bool         `json:"has_more"`
+}
+
+// SyncChange is a change made by an offline client. BaseVersion is the
+// version the client last saw, or 0 for a record it created; ID may then be
+// empty and ClientID carries the client's own ID for matching the result.
+type SyncChange struct {
+	Collection  string          `json:"collection"`
+	ID          string          `json:"id"`
+	ClientID    string          `json:"client_id,omitempty"`
+	BaseVersion int64           `json:"base_version"`
+	Deleted     bool            `json:"deleted"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// SyncResult is the outcome of one SyncChange. Current is the conflict hint:
+// the plugin's copy of the record when Status is SyncConflict.
+type SyncResult struct {
+	Collection string      `json:"collection"`
+	ID         string      `json:"id"`
+	ClientID   string      `json:"client_id,omitempty"`
+	Status     string      `json:"status"`
+	Version    int64       `json:"version"`
+	Message    string      `json:"message,omitempty"`
+	Current    *SyncRecord `json:"current,omitempty"`
+}
+
+// ErrNotImplemented is returned by the host-side client when a plugin
+// does not implement an optional hook.
+var ErrNotImplemented = errors.New("not implemented by plugin")
+
 // Manifest represents a plugin's metadata.
 type Manifest struct {
 	ID          string   `json:"id"`
@@ -22,6 +231,27 @@ type Manifest struct {
 	Icon        string   `json:"icon"`
 	Color       string   `json:"color"`
 	Permissions []string `json:"permissions"`
+	// Routes are path aliases the host mounts for the plugin in addition to
+	// /api/plugins/{id}/*, such as "/finance/*".
+	Routes []string `json:"routes,omitempty"`
+	// CSP lists extra Content-Security-Policy sources the plugin's UI needs,
+	// by directive, such as {"font-src": ["https://fonts.gstatic.com"]}.
+	CSP map[string][]string `json:"csp,omitempty"`
+	// CLI names the group the plugin's commands run under, such as "finance"
+	// for `cortex finance add-expense`; it defaults to the plugin ID.
+	CLI string `json:"cli,omitempty"`
+	// Commands are the CLI subcommands the plugin offers, each run as a
+	// request to its API.
+	Commands []Command `json:"commands,omitempty"`
+	// UpdateURL is where the plugin's latest release is published (see
+	// Release), checked by the host to offer updates.
+	UpdateURL string `json:"update_url,omitempty"`
+	// Widgets are the dashboard widgets the plugin offers. Plugins that
+	// declare none may still answer widget requests for any slot.
+	Widgets []Widget `json:"widgets,omitempty"`
+	// Dependencies are the IDs of plugins that must be running before this
+	// one starts, such as "finance-tracker" for a reporting plugin.
+	Dependencies []string `json:"dependencies,omitempty"`
 }
 
 // APIRequest represents an incoming API request for a plugin.
@@ -30,6 +260,37 @@ type APIRequest struct {
 	Path   string            `json:"path"`
 	Body   []byte            `json:"body"`
 	Query  map[string]string `json:"query"`
+	// Headers holds the first value of each request header, keyed by its
+	// canonical name. Credentials meant for the host are not forwarded.
+	Headers     map[string]string `json:"headers"`
+	ContentType string            `json:"contentType"`
+	// RequestID is the host's correlation ID of the HTTP request, also
+	// returned to the client in X-Request-ID. Log it to trace a request
+	// across the host and the plugin.
+	RequestID string `json:"requestId,omitempty"`
+	// PathParams holds the {name} segments of the route that matched, when
+	// the plugin routes with sdk.Router. It is never sent by the host.
+	PathParams map[string]string `json:"-"`
+
+	// ctx is the context of the HandleAPI call carrying the request.
+	ctx context.Context
+}
+
+// Context returns the context of the HandleAPI call the request came with,
+// so handlers registered with sdk.Router can honour its deadline. It is
+// never nil.
+func (r *APIRequest) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// WithContext returns a shallow copy of the request whose Context is ctx.
+func (r *APIRequest) WithContext(ctx context.Context) *APIRequest {
+	copied := *r
+	copied.ctx = ctx
+	return &copied
 }
 
 // APIResponse represents a plugin's API response.
@@ -37,6 +298,18 @@ type APIResponse struct {
 	StatusCode  int    `json:"statusCode"`
 	Body        []byte `json:"body"`
 	ContentType string `json:"contentType"`
+	// Headers are extra response headers, such as Content-Disposition.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Deleted is the copy of the records a delete removed, for Restorer
+	// plugins. The host keeps it instead of sending it to the client.
+	Deleted *DeletedEntity `json:"-"`
+}
+
+// DeletedEntity is what a delete removed, encoded by the plugin. Kind tells
+// RestoreDeleted how to decode Data, such as "note" or "project".
+type DeletedEntity struct {
+	Kind string
+	Data []byte
 }
 
 // Handshake is the shared handshake config for host and plugins.
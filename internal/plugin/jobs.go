@@ -0,0 +1,225 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+const (
+	// MinJobInterval is the shortest interval a job can be scheduled at.
+	MinJobInterval = time.Minute
+	// maxJobNameLength bounds the names of scheduled jobs.
+	maxJobNameLength = 100
+)
+
+// ErrJobNotFound is returned when the host runs a job the plugin has not
+// scheduled in its current process.
+var ErrJobNotFound = errors.New("job not found")
+
+// JobScheduler runs the jobs plugins schedule through HostServices. The
+// loader implements it.
+type JobScheduler interface {
+	ScheduleJob(pluginID string, name string, interval time.Duration)
+}
+
+// JobRunner runs a job a plugin scheduled. The host's client of a plugin
+// implements it.
+type JobRunner interface {
+	RunJob(name string) error
+}
+
+// --- Host side ---
+
+func (s *hostServer) ScheduleJob(ctx context.Context, request *pb.ScheduleRequest) (*pb.Empty, error) {
+	if s.scheduler == nil {
+		return nil, status.Error(codes.Unavailable, "host has no job scheduler")
+	}
+	if request.Name == "" || len(request.Name) > maxJobNameLength {
+		return nil, status.Errorf(codes.InvalidArgument, "job name must be between 1 and %d characters", maxJobNameLength)
+	}
+	interval := time.Duration(request.IntervalSeconds) * time.Second
+	if interval < MinJobInterval {
+		return nil, status.Errorf(codes.InvalidArgument, "jobs run at most every %s", MinJobInterval)
+	}
+
+	s.scheduler.ScheduleJob(s.pluginID, request.Name, interval)
+	return &pb.Empty{}, nil
+}
+
+// ScheduleJob runs a plugin's job every interval until the plugin is
+// unloaded. Scheduling a job again under the same name replaces it, so a
+// relaunched plugin that schedules its jobs again does not run them twice.
+func (l *Loader) ScheduleJob(pluginID string, name string, interval time.Duration) {
+	l.jobsMu.Lock()
+	defer l.jobsMu.Unlock()
+
+	if l.jobs == nil {
+		l.jobs = make(map[string]map[string]chan struct{})
+	}
+	if l.jobs[pluginID] == nil {
+		l.jobs[pluginID] = make(map[string]chan struct{})
+	}
+	if stop, ok := l.jobs[pluginID][name]; ok {
+		close(stop)
+	}
+
+	stop := make(chan struct{})
+	l.jobs[pluginID][name] = stop
+	go l.runScheduledJob(pluginID, name, interval, stop)
+	slog.Info("plugin job scheduled", "plugin", pluginID, "job", name, "interval", interval)
+}
+
+// runScheduledJob runs a job on every tick until stop is closed or the plugin
+// is gone.
+func (l *Loader) runScheduledJob(pluginID string, name string, interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if !l.runJob(pluginID, name) {
+			l.dropJob(pluginID, name, stop)
+			return
+		}
+	}
+}
+
+// runJob runs one job and reports whether it should keep being scheduled: a
+// job whose plugin is no longer loaded, or no longer knows it, is dropped.
+func (l *Loader) runJob(pluginID string, name string) bool {
+	entry, err := l.Acquire(pluginID)
+	if errors.Is(err, ErrPluginNotFound) {
+		return false
+	}
+	if err != nil {
+		slog.Warn("skipping plugin job", "plugin", pluginID, "job", name, "error", err)
+		return true
+	}
+
+	runner, ok := entry.Plugin.(JobRunner)
+	if !ok {
+		l.Release(pluginID, entry, nil)
+		return false
+	}
+
+	started := time.Now()
+	err = runner.RunJob(name)
+	if errors.Is(err, ErrJobNotFound) {
+		l.Release(pluginID, entry, nil)
+		return false
+	}
+	l.Release(pluginID, entry, err)
+
+	if err != nil {
+		slog.Warn("plugin job failed", "plugin", pluginID, "job", name, "error", err)
+		return true
+	}
+	slog.Debug("plugin job ran", "plugin", pluginID, "job", name, "duration", time.Since(started))
+	return true
+}
+
+// dropJob forgets a job, unless it has been scheduled again since.
+func (l *Loader) dropJob(pluginID string, name string, stop chan struct{}) {
+	l.jobsMu.Lock()
+	defer l.jobsMu.Unlock()
+
+	if l.jobs[pluginID][name] == stop {
+		delete(l.jobs[pluginID], name)
+	}
+}
+
+// stopJobs stops every job a plugin scheduled.
+func (l *Loader) stopJobs(pluginID string) {
+	l.jobsMu.Lock()
+	defer l.jobsMu.Unlock()
+
+	for _, stop := range l.jobs[pluginID] {
+		close(stop)
+	}
+	delete(l.jobs, pluginID)
+}
+
+// RunJob runs a job the plugin scheduled. It returns ErrJobNotFound for a job
+// the plugin process does not know.
+func (c *GRPCClient) RunJob(name string) error {
+	_, err := c.client.RunJob(context.Background(), &pb.JobRequest{Name: name})
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, status.Convert(err).Message())
+	}
+	return translateError(err)
+}
+
+// --- Plugin side ---
+
+// scheduledJobs holds the jobs the plugin process scheduled, by name.
+var scheduledJobs struct {
+	mu   sync.RWMutex
+	jobs map[string]func() error
+}
+
+func (s *grpcServer) RunJob(ctx context.Context, request *pb.JobRequest) (*pb.Empty, error) {
+	scheduledJobs.mu.RLock()
+	job, ok := scheduledJobs.jobs[request.Name]
+	scheduledJobs.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "job %q is not scheduled", request.Name)
+	}
+
+	if err := job(); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+// Schedule has the host run job every interval, at least MinJobInterval,
+// while the plugin is loaded. The first run is one interval from now.
+// Scheduling a job again under the same name replaces it. A failing job is
+// logged by the host and run again on the next tick.
+func (h *HostServices) Schedule(name string, every time.Duration, job func() error) error {
+	if name == "" || len(name) > maxJobNameLength {
+		return fmt.Errorf("job name must be between 1 and %d characters", maxJobNameLength)
+	}
+	if every < MinJobInterval {
+		return fmt.Errorf("jobs run at most every %s", MinJobInterval)
+	}
+
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	scheduledJobs.mu.Lock()
+	if scheduledJobs.jobs == nil {
+		scheduledJobs.jobs = make(map[string]func() error)
+	}
+	previous, replaced := scheduledJobs.jobs[name]
+	scheduledJobs.jobs[name] = job
+	scheduledJobs.mu.Unlock()
+
+	_, err = client.ScheduleJob(context.Background(), &pb.ScheduleRequest{Name: name, IntervalSeconds: int64(every / time.Second)})
+	if err != nil {
+		scheduledJobs.mu.Lock()
+		if replaced {
+			scheduledJobs.jobs[name] = previous
+		} else {
+			delete(scheduledJobs.jobs, name)
+		}
+		scheduledJobs.mu.Unlock()
+		return translateError(err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingScheduler records the jobs scheduled through it.
+type recordingScheduler struct {
+	mu        sync.Mutex
+	scheduled []string
+	intervals []time.Duration
+}
+
+func (r *recordingScheduler) ScheduleJob(pluginID string, name string, interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scheduled = append(r.scheduled, pluginID+":"+name)
+	r.intervals = append(r.intervals, interval)
+}
+
+func TestSchedule_RegistersWithHostAndRuns(t *testing.T) {
+	scheduler := &recordingScheduler{}
+	client := connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "finance-tracker", Scheduler: scheduler})
+
+	runs := 0
+	if err := newHostServices().Schedule("refresh-rates", time.Hour, func() error {
+		runs++
+		return nil
+	}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if len(scheduler.scheduled) != 1 || scheduler.scheduled[0] != "finance-tracker:refresh-rates" || scheduler.intervals[0] != time.Hour {
+		t.Fatalf("expected the job to reach the scheduler, got %v %v", scheduler.scheduled, scheduler.intervals)
+	}
+
+	if err := client.RunJob("refresh-rates"); err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+	if runs != 1 {
+		t.Errorf("expected the job to run once, got %d", runs)
+	}
+	if err := client.RunJob("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound for an unscheduled job, got %v", err)
+	}
+}
+
+func TestSchedule_RejectsInvalidJobs(t *testing.T) {
+	scheduler := &recordingScheduler{}
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "finance-tracker", Scheduler: scheduler})
+	host := newHostServices()
+
+	if err := host.Schedule("too-often", time.Second, func() error { return nil }); err == nil {
+		t.Error("expected an error for an interval below MinJobInterval")
+	}
+	if err := host.Schedule("", time.Hour, func() error { return nil }); err == nil {
+		t.Error("expected an error for an empty job name")
+	}
+	if len(scheduler.scheduled) != 0 {
+		t.Errorf("expected invalid jobs not to reach the scheduler, got %v", scheduler.scheduled)
+	}
+}
+
+func TestSchedule_WithoutScheduler(t *testing.T) {
+	connectOverGRPC(t, "finance-tracker", nil)
+
+	err := newHostServices().Schedule("refresh-rates", time.Hour, func() error { return nil })
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable without a scheduler, got %v", err)
+	}
+	scheduledJobs.mu.RLock()
+	defer scheduledJobs.mu.RUnlock()
+	if _, ok := scheduledJobs.jobs["refresh-rates"]; ok {
+		t.Error("expected a job the host rejected not to stay registered")
+	}
+}
+
+func TestLoaderRunJob_DropsStaleJobs(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader("", "", registry)
+	client := connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "finance-tracker", Scheduler: loader})
+
+	if err := newHostServices().Schedule("refresh-rates", time.Hour, func() error { return nil }); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if _, ok := loader.jobs["finance-tracker"]["refresh-rates"]; !ok {
+		t.Fatal("expected the loader to track the job")
+	}
+
+	if loader.runJob("finance-tracker", "refresh-rates") {
+		t.Error("expected a job of an unloaded plugin to be dropped")
+	}
+
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker"})
+	entry, _ := registry.Get("finance-tracker")
+	entry.Plugin = client
+	if !loader.runJob("finance-tracker", "refresh-rates") {
+		t.Error("expected a scheduled job to keep running")
+	}
+	if loader.runJob("finance-tracker", "unknown") {
+		t.Error("expected a job the plugin does not know to be dropped")
+	}
+
+	loader.stopJobs("finance-tracker")
+	if _, ok := loader.jobs["finance-tracker"]; ok {
+		t.Error("expected stopJobs to forget the plugin's jobs")
+	}
+}
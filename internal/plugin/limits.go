@@ -0,0 +1,364 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minMemoryLimitMB is the smallest memory limit accepted; a Go plugin
+	// needs a few MiB just to start.
+	minMemoryLimitMB = 16
+	// cpuOverLimitSamples is how many checks in a row a plugin must be over
+	// its CPU limit to be killed, so short bursts are tolerated.
+	cpuOverLimitSamples = 5
+)
+
+// Ways a plugin's resource limits are enforced, as reported by ResourceHealth.
+const (
+	// EnforcementCgroup means the kernel enforces the limits through the
+	// plugin's cgroup: CPU use is throttled and memory overuse OOM-killed.
+	EnforcementCgroup = "cgroup"
+	// EnforcementWatchdog means the host measures the plugin's process at
+	// every check and kills it when it is over a limit.
+	EnforcementWatchdog = "watchdog"
+	// EnforcementNone means the platform offers no way to measure the
+	// process; limits other than FSRoot are not enforced.
+	EnforcementNone = "none"
+)
+
+// SandboxCommand is the hidden subcommand of the host binary that confines a
+// plugin with an FSRoot before running it; the host's main hands its
+// arguments to RunSandbox.
+const SandboxCommand = "__plugin-sandbox"
+
+// errResourcesUnsupported is returned where processes cannot be measured or
+// confined on this platform.
+var errResourcesUnsupported = errors.New("resource limits are not supported on this platform")
+
+// ResourceLimits constrain the process of a plugin. Zero values are
+// unlimited.
+type ResourceLimits struct {
+	// MemoryMB caps the resident memory of the process, in MiB.
+	MemoryMB int `json:"memory_mb,omitempty"`
+	// CPUPercent caps its CPU use, in percent of one core; 200 allows two
+	// full cores.
+	CPUPercent int `json:"cpu_percent,omitempty"`
+	// FSRoot is the only directory, besides its data directory and its own
+	// binary, the plugin can use on the filesystem; system libraries,
+	// certificates and time zones stay readable. It requires Linux.
+	FSRoot string `json:"fs_root,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (r ResourceLimits) IsZero() bool {
+	return r == ResourceLimits{}
+}
+
+// ResourceUsage is the latest measurement of a plugin's process.
+type ResourceUsage struct {
+	MemoryBytes int64   `json:"memory_bytes"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	SampledAt   string  `json:"sampled_at"`
+}
+
+// Termination records why the host killed a plugin, or saw it killed, for
+// exceeding its limits.
+type Termination struct {
+	Reason string `json:"reason"`
+	At     string `json:"at"`
+}
+
+// ResourceHealth describes the limits of a plugin and how it fares against
+// them.
+type ResourceHealth struct {
+	Limits          *ResourceLimits `json:"limits,omitempty"`
+	Enforcement     string          `json:"enforcement,omitempty"`
+	Usage           *ResourceUsage  `json:"usage,omitempty"`
+	LastTermination *Termination    `json:"last_termination,omitempty"`
+}
+
+// resourceWatch is what the resource monitor remembers of one plugin process
+// between checks.
+type resourceWatch struct {
+	entry    *RegistryEntry
+	cpuTime  time.Duration
+	sampled  time.Time
+	overCPU  int
+	oomKills int
+}
+
+// ParseResourceLimits parses limits in the form of CORTEX_PLUGIN_LIMITS:
+// plugins separated by semicolons, each an ID, a colon and comma-separated
+// limits, as in "finance-tracker:memory_mb=256,cpu_percent=50;quick-notes:fs_root=/srv/notes".
+func ParseResourceLimits(spec string) (map[string]ResourceLimits, error) {
+	limits := make(map[string]ResourceLimits)
+	for _, part := range strings.Split(spec, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		id, settings, found := strings.Cut(part, ":")
+		id = strings.TrimSpace(id)
+		if !found || !pluginIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("%q must be a plugin id followed by its limits, as in notes:memory_mb=128", part)
+		}
+		if _, duplicate := limits[id]; duplicate {
+			return nil, fmt.Errorf("plugin %s has limits set twice", id)
+		}
+
+		var limit ResourceLimits
+		for _, setting := range strings.Split(settings, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(setting), "=")
+			switch name {
+			case "memory_mb":
+				parsed, err := strconv.Atoi(value)
+				if err != nil || parsed < minMemoryLimitMB {
+					return nil, fmt.Errorf("plugin %s: memory_mb must be at least %d, got %q", id, minMemoryLimitMB, value)
+				}
+				limit.MemoryMB = parsed
+			case "cpu_percent":
+				parsed, err := strconv.Atoi(value)
+				if err != nil || parsed < 1 {
+					return nil, fmt.Errorf("plugin %s: cpu_percent must be a positive number, got %q", id, value)
+				}
+				limit.CPUPercent = parsed
+			case "fs_root":
+				if !filepath.IsAbs(value) {
+					return nil, fmt.Errorf("plugin %s: fs_root must be an absolute path, got %q", id, value)
+				}
+				limit.FSRoot = filepath.Clean(value)
+			default:
+				return nil, fmt.Errorf("plugin %s: unknown limit %q; use memory_mb, cpu_percent or fs_root", id, name)
+			}
+		}
+		limits[id] = limit
+	}
+	return limits, nil
+}
+
+// SetResourceLimits sets the limits of plugins by ID, applied from their next
+// launch. Canaries get the limits of their live plugin.
+func (l *Loader) SetResourceLimits(limits map[string]ResourceLimits) {
+	l.resourceMu.Lock()
+	defer l.resourceMu.Unlock()
+	l.resourceLimits = limits
+}
+
+// SetCgroupRoot makes the loader place each plugin with memory or CPU limits
+// in a cgroup v2 under root, a directory delegated to the host, so the kernel
+// enforces them. Without one, or when it cannot be used, the monitor kills
+// plugins over their limits instead.
+func (l *Loader) SetCgroupRoot(root string) {
+	l.cgroupRoot = root
+}
+
+// SetSandboxLauncher sets the executable run as SandboxCommand to confine
+// plugins with an FSRoot, normally the host binary itself. Plugins with an
+// FSRoot fail to load without one.
+func (l *Loader) SetSandboxLauncher(path string) {
+	l.sandboxLauncher = path
+}
+
+// limitsFor returns the limits of the plugin registered under key.
+func (l *Loader) limitsFor(key string) ResourceLimits {
+	id := key
+	if liveID, ok := canaryOf(key); ok {
+		id = liveID
+	}
+
+	l.resourceMu.Lock()
+	defer l.resourceMu.Unlock()
+	return l.resourceLimits[id]
+}
+
+// confine applies the memory and CPU limits of the plugin being launched
+// under key to its process, started as pid. It returns how they are enforced
+// and the cgroup the process was placed in, if any.
+func (l *Loader) confine(key string, pid int, limits ResourceLimits) (enforcement string, cgroup string) {
+	if limits.MemoryMB == 0 && limits.CPUPercent == 0 {
+		return "", ""
+	}
+
+	if l.cgroupRoot != "" {
+		path, err := joinCgroup(l.cgroupRoot, key, pid, limits)
+		if err == nil {
+			return EnforcementCgroup, path
+		}
+		slog.Warn("placing plugin in a cgroup failed, enforcing its limits by measuring it", "plugin", key, "error", err)
+	}
+	if _, _, err := l.sampleUsage(pid); err != nil {
+		slog.Warn("plugin resource limits cannot be enforced on this platform", "plugin", key, "error", err)
+		return EnforcementNone, ""
+	}
+	return EnforcementWatchdog, ""
+}
+
+// MonitorResources measures the process of every plugin with memory or CPU
+// limits each interval until ctx is done. Plugins over a limit the kernel
+// does not enforce are killed, and the reason kept for ResourceHealth; they
+// are relaunched by the next call like any crashed plugin.
+func (l *Loader) MonitorResources(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.checkResources(time.Now())
+		}
+	}
+}
+
+// checkResources runs one check of every limited plugin.
+func (l *Loader) checkResources(now time.Time) {
+	l.resourceMu.Lock()
+	defer l.resourceMu.Unlock()
+
+	// Processes that exited or were replaced since the last check.
+	for key, watch := range l.resourceWatches {
+		if current, ok := l.registry.Get(key); ok && current == watch.entry && !watch.entry.Exited() {
+			continue
+		}
+		if watch.entry.cgroup != "" {
+			if kills := cgroupOOMKills(watch.entry.cgroup); kills > watch.oomKills {
+				l.recordTermination(key, fmt.Sprintf("killed by the kernel for exceeding its memory limit of %d MiB", watch.entry.limits.MemoryMB), now)
+			}
+			removeCgroup(watch.entry.cgroup)
+		}
+		delete(l.resourceWatches, key)
+		delete(l.resourceUsage, key)
+	}
+
+	for _, key := range l.registry.Keys() {
+		entry, ok := l.registry.Get(key)
+		if !ok || entry.enforcement == "" || entry.enforcement == EnforcementNone || entry.Exited() {
+			continue
+		}
+
+		watch, ok := l.resourceWatches[key]
+		if !ok || watch.entry != entry {
+			watch = &resourceWatch{entry: entry}
+			if entry.cgroup != "" {
+				watch.oomKills = cgroupOOMKills(entry.cgroup)
+			}
+			l.resourceWatches[key] = watch
+		}
+
+		memory, cpuTime, err := l.sampleUsage(entry.pid)
+		if err != nil {
+			continue
+		}
+		usage := ResourceUsage{MemoryBytes: memory, SampledAt: now.UTC().Format(time.RFC3339)}
+		if !watch.sampled.IsZero() && now.After(watch.sampled) {
+			usage.CPUPercent = float64(cpuTime-watch.cpuTime) / float64(now.Sub(watch.sampled)) * 100
+		}
+		watch.cpuTime, watch.sampled = cpuTime, now
+		l.resourceUsage[key] = usage
+
+		if entry.enforcement != EnforcementWatchdog {
+			continue
+		}
+		if reason := watch.exceeded(entry.limits, usage); reason != "" {
+			slog.Warn("plugin exceeded its resource limits, killing it", "plugin", key, "reason", reason)
+			l.recordTermination(key, reason, now)
+			if err := l.killProcess(entry.pid); err != nil {
+				slog.Error("killing plugin over its limits failed", "plugin", key, "error", err)
+			}
+			delete(l.resourceUsage, key)
+		}
+	}
+}
+
+// exceeded returns why usage breaks limits, or "" while it does not. Memory
+// breaks them at once; CPU only after cpuOverLimitSamples checks in a row.
+func (w *resourceWatch) exceeded(limits ResourceLimits, usage ResourceUsage) string {
+	if limits.MemoryMB > 0 && usage.MemoryBytes > int64(limits.MemoryMB)<<20 {
+		return fmt.Sprintf("used %d MiB of memory, over its limit of %d MiB", usage.MemoryBytes>>20, limits.MemoryMB)
+	}
+
+	if limits.CPUPercent > 0 && usage.CPUPercent > float64(limits.CPUPercent) {
+		w.overCPU++
+	} else {
+		w.overCPU = 0
+	}
+	if w.overCPU >= cpuOverLimitSamples {
+		return fmt.Sprintf("used %.0f%% CPU for %d checks in a row, over its limit of %d%%", usage.CPUPercent, w.overCPU, limits.CPUPercent)
+	}
+	return ""
+}
+
+// recordTermination keeps the reason a plugin was killed. The caller holds
+// resourceMu.
+func (l *Loader) recordTermination(key string, reason string, now time.Time) {
+	l.terminations[key] = Termination{Reason: reason, At: now.UTC().Format(time.RFC3339)}
+}
+
+// ResourceHealth returns the limits of a loaded plugin, how they are
+// enforced, its latest measurement and the last time it was killed for
+// exceeding them.
+func (l *Loader) ResourceHealth(id string) (ResourceHealth, error) {
+	entry, ok := l.registry.Get(id)
+	if !ok {
+		return ResourceHealth{}, ErrPluginNotFound
+	}
+
+	l.resourceMu.Lock()
+	defer l.resourceMu.Unlock()
+
+	health := ResourceHealth{Enforcement: entry.enforcement}
+	if !entry.limits.IsZero() {
+		limits := entry.limits
+		health.Limits = &limits
+	}
+	if usage, ok := l.resourceUsage[id]; ok {
+		health.Usage = &usage
+	}
+	if termination, ok := l.terminations[id]; ok {
+		health.LastTermination = &termination
+	}
+	return health, nil
+}
+
+// PluginProcess is the process serving a loaded plugin.
+type PluginProcess struct {
+	PID     int  `json:"pid"`
+	Running bool `json:"running"`
+	// MemoryBytes is its resident memory, only measured on Linux.
+	MemoryBytes int64 `json:"memory_bytes,omitempty"`
+}
+
+// Process returns the process serving plugin id, with its resident memory
+// measured now whether or not the plugin has limits.
+func (l *Loader) Process(id string) (PluginProcess, error) {
+	entry, ok := l.registry.Get(id)
+	if !ok {
+		return PluginProcess{}, ErrPluginNotFound
+	}
+
+	process := PluginProcess{PID: entry.pid, Running: !entry.Exited()}
+	if process.Running && entry.pid > 0 {
+		if memory, _, err := l.sampleUsage(entry.pid); err == nil {
+			process.MemoryBytes = memory
+		}
+	}
+	return process, nil
+}
+
+// killPID kills the process with the given pid.
+func killPID(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,364 @@
+//go:build linux
+
+package plugin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// clockTicks is the kernel's USER_HZ, the unit of CPU times in /proc. It
+	// is 100 on every Linux architecture Cortex runs on.
+	clockTicks = 100
+	// cgroupCPUPeriod is the cpu.max period, in microseconds.
+	cgroupCPUPeriod = 100000
+)
+
+const (
+	// landlockRead is what sandboxed plugins may do in read-only paths.
+	landlockRead = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR
+	// landlockFileAccess are the rights that apply to files rather than
+	// directories.
+	landlockFileAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_TRUNCATE | unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+	// landlockNetworkABI is the first Landlock ABI to handle TCP.
+	landlockNetworkABI = 4
+	// landlockDevices covers creating device files, never allowed.
+	landlockDevices = unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK | unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+)
+
+// sandboxSystemPaths stay readable by sandboxed plugins: libraries, TLS
+// certificates, name resolution, time zones and what the Go runtime reads
+// about its process. Missing ones are skipped.
+var sandboxSystemPaths = []string{
+	"/usr", "/lib", "/lib64", "/bin",
+	"/etc/ssl", "/etc/ca-certificates", "/etc/pki", "/etc/resolv.conf", "/etc/hosts", "/etc/nsswitch.conf", "/etc/localtime",
+	"/proc", "/sys/fs/cgroup", "/sys/kernel/mm/transparent_hugepage",
+}
+
+// sandboxDevices stay readable and writable by sandboxed plugins.
+var sandboxDevices = []string{"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom"}
+
+// processUsage returns the resident memory and total CPU time of a process,
+// from /proc.
+func processUsage(pid int) (int64, time.Duration, error) {
+	statm, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0, 0, fmt.Errorf("unexpected /proc/%d/statm: %q", pid, statm)
+	}
+	residentPages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	// The command name in parentheses may contain spaces; the fields after
+	// it start with the state, so utime and stime are the 12th and 13th.
+	closing := strings.LastIndexByte(string(stat), ')')
+	fields = strings.Fields(string(stat[closing+1:]))
+	if closing < 0 || len(fields) < 13 {
+		return 0, 0, fmt.Errorf("unexpected /proc/%d/stat", pid)
+	}
+	userTicks, err := strconv.ParseInt(fields[11], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	systemTicks, err := strconv.ParseInt(fields[12], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cpuTime := time.Duration(userTicks+systemTicks) * time.Second / clockTicks
+	return residentPages * int64(os.Getpagesize()), cpuTime, nil
+}
+
+// joinCgroup creates the cgroup of the plugin registered under key below
+// root, sets its limits and moves pid into it, returning its path.
+func joinCgroup(root string, key string, pid int, limits ResourceLimits) (path string, err error) {
+	// Enabling the controllers for the children of root fails when another
+	// host already did; writing the limits below reports real problems.
+	_ = os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), []byte("+memory +cpu"), 0)
+
+	path = filepath.Join(root, key)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("creating cgroup: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			removeCgroup(path)
+		}
+	}()
+
+	settings := map[string]string{"memory.max": "max", "cpu.max": "max"}
+	if limits.MemoryMB > 0 {
+		settings["memory.max"] = strconv.FormatInt(int64(limits.MemoryMB)<<20, 10)
+	}
+	if limits.CPUPercent > 0 {
+		settings["cpu.max"] = fmt.Sprintf("%d %d", limits.CPUPercent*cgroupCPUPeriod/100, cgroupCPUPeriod)
+	}
+	for file, value := range settings {
+		if err := os.WriteFile(filepath.Join(path, file), []byte(value), 0); err != nil {
+			return "", fmt.Errorf("setting %s: %w", file, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0); err != nil {
+		return "", fmt.Errorf("moving plugin into cgroup: %w", err)
+	}
+	return path, nil
+}
+
+// cgroupOOMKills returns how many processes of a cgroup the kernel killed
+// for exceeding memory.max.
+func cgroupOOMKills(path string) int {
+	file, err := os.Open(filepath.Join(path, "memory.events"))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if count, found := strings.CutPrefix(scanner.Text(), "oom_kill "); found {
+			kills, _ := strconv.Atoi(count)
+			return kills
+		}
+	}
+	return 0
+}
+
+// removeCgroup removes the cgroup of an exited plugin.
+func removeCgroup(path string) {
+	_ = os.Remove(path)
+}
+
+// canDenyNetwork reports whether plugins can be started without network
+// access: through launcher, on a kernel whose Landlock handles TCP.
+func canDenyNetwork(launcher string) bool {
+	return launcher != "" && landlockABI() >= landlockNetworkABI
+}
+
+// sandboxCommand returns the command starting a plugin binary through the
+// sandbox launcher and the environment it needs. With an fsRoot, the plugin
+// is confined to its data directory and fsRoot and gets a private temporary
+// directory, also used for its go-plugin socket. With denyNetwork, it can
+// neither connect nor listen over TCP.
+func sandboxCommand(launcher string, key string, binaryPath string, dataPath string, fsRoot string, denyNetwork bool) (*exec.Cmd, []string, error) {
+	if launcher == "" {
+		return nil, nil, errors.New("sandboxing a plugin needs the sandbox launcher, which is not set")
+	}
+	if denyNetwork && landlockABI() < landlockNetworkABI {
+		return nil, nil, errors.New("denying network access needs Landlock ABI 4, which this kernel does not support")
+	}
+
+	args := []string{SandboxCommand}
+	var env []string
+	if fsRoot != "" {
+		if landlockABI() < 1 {
+			return nil, nil, errors.New("a filesystem root needs Landlock, which this kernel does not support")
+		}
+		if info, err := os.Stat(fsRoot); err != nil || !info.IsDir() {
+			return nil, nil, fmt.Errorf("filesystem root %s is not a directory", fsRoot)
+		}
+
+		tempDir := filepath.Join(os.TempDir(), fmt.Sprintf("cortex-%d-%s", os.Getuid(), key))
+		if err := os.MkdirAll(tempDir, 0700); err != nil {
+			return nil, nil, fmt.Errorf("creating plugin temporary directory: %w", err)
+		}
+
+		args = append(args,
+			"--write", dataPath,
+			"--write", fsRoot,
+			"--write", tempDir,
+			"--read", filepath.Dir(binaryPath))
+		env = []string{"TMPDIR=" + tempDir, "PLUGIN_UNIX_SOCKET_DIR=" + tempDir}
+	}
+	if denyNetwork {
+		args = append(args, "--deny-network")
+	}
+
+	args = append(args, "--", binaryPath)
+	return exec.Command(launcher, args...), env, nil
+}
+
+// RunSandbox is SandboxCommand: it restricts the process with Landlock, then
+// executes the binary after "--" in its place. Given --read or --write
+// paths, the filesystem is limited to them plus the system paths programs
+// need; with --deny-network, TCP connections and listening are refused. args
+// are the arguments after the subcommand. It only returns on failure.
+func RunSandbox(args []string) error {
+	var read, write []string
+	denyNetwork := false
+	for len(args) > 0 && args[0] != "--" {
+		if args[0] == "--deny-network" {
+			denyNetwork = true
+			args = args[1:]
+			continue
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("%s needs a path", args[0])
+		}
+		switch args[0] {
+		case "--read":
+			read = append(read, args[1])
+		case "--write":
+			write = append(write, args[1])
+		default:
+			return fmt.Errorf("unknown sandbox option %s", args[0])
+		}
+		args = args[2:]
+	}
+	if len(args) < 2 {
+		return errors.New("usage: " + SandboxCommand + " [--read path] [--write path] [--deny-network] -- binary [args...]")
+	}
+
+	// Landlock and no_new_privs apply to the calling thread, which must be
+	// the one executing the plugin.
+	runtime.LockOSThread()
+	if err := restrict(read, write, denyNetwork); err != nil {
+		return fmt.Errorf("restricting plugin: %w", err)
+	}
+	return syscall.Exec(args[1], args[1:], os.Environ())
+}
+
+// landlockABI returns the Landlock ABI version of the kernel, or 0 when it
+// does not support Landlock.
+func landlockABI() int {
+	version, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0
+	}
+	return int(version)
+}
+
+// restrict confines the calling thread with Landlock. Given read or write
+// paths, it may read read, read and change write, and use the system paths,
+// and nothing else on the filesystem. With denyNetwork, it may neither
+// connect nor listen over TCP; the go-plugin connection to the host is a
+// Unix socket and keeps working.
+func restrict(read []string, write []string, denyNetwork bool) error {
+	abi := landlockABI()
+	if abi < 1 {
+		return errors.New("Landlock is not supported by this kernel")
+	}
+
+	var handled uint64
+	if len(read) > 0 || len(write) > 0 {
+		handled = uint64(unix.LANDLOCK_ACCESS_FS_MAKE_SYM<<1 - 1)
+		if abi >= 2 {
+			handled |= unix.LANDLOCK_ACCESS_FS_REFER
+		}
+		if abi >= 3 {
+			handled |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+		}
+		if abi >= 5 {
+			handled |= unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+		}
+	}
+
+	if handled == 0 && !denyNetwork {
+		return nil
+	}
+
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	if denyNetwork {
+		if abi < landlockNetworkABI {
+			return errors.New("denying network access needs Landlock ABI 4, which this kernel does not support")
+		}
+		// No port is allowed, so every TCP connect and bind is refused.
+		attr.Access_net = unix.LANDLOCK_ACCESS_NET_BIND_TCP | unix.LANDLOCK_ACCESS_NET_CONNECT_TCP
+	}
+	ruleset, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("creating ruleset: %w", errno)
+	}
+	defer unix.Close(int(ruleset))
+
+	if handled == 0 {
+		return restrictSelf(ruleset)
+	}
+
+	writeAccess := handled &^ landlockDevices
+	for _, path := range write {
+		if err := allowPath(int(ruleset), path, writeAccess&handled, true); err != nil {
+			return err
+		}
+	}
+	for _, path := range read {
+		if err := allowPath(int(ruleset), path, landlockRead&handled, true); err != nil {
+			return err
+		}
+	}
+	for _, path := range sandboxSystemPaths {
+		if err := allowPath(int(ruleset), path, landlockRead&handled, false); err != nil {
+			return err
+		}
+	}
+	for _, path := range sandboxDevices {
+		access := uint64(unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE)
+		if err := allowPath(int(ruleset), path, access, false); err != nil {
+			return err
+		}
+	}
+
+	return restrictSelf(ruleset)
+}
+
+// restrictSelf enforces ruleset on the calling thread and what it executes.
+func restrictSelf(ruleset uintptr) error {
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("setting no_new_privs: %w", err)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, ruleset, 0, 0); errno != 0 {
+		return fmt.Errorf("restricting process: %w", errno)
+	}
+	return nil
+}
+
+// allowPath adds a rule granting access beneath path. Missing paths fail
+// when required and are skipped otherwise.
+func allowPath(ruleset int, path string, access uint64, required bool) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if !required && errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer unix.Close(fd)
+
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return fmt.Errorf("inspecting %s: %w", path, err)
+	}
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFileAccess
+	}
+
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH,
+		uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("allowing %s: %w", path, errno)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+//go:build linux
+
+package plugin
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// sandboxTestStage tells a re-executed test binary which part of
+// TestRunSandbox_DeniesNetwork to play.
+const sandboxTestStage = "CORTEX_SANDBOX_TEST_STAGE"
+
+// runSandboxStage runs the test binary again as stage of
+// TestRunSandbox_DeniesNetwork, dialing address, and returns its output.
+func runSandboxStage(t *testing.T, address string, sandboxArgs ...string) string {
+	t.Helper()
+
+	command := exec.Command(os.Args[0], "-test.run=^TestRunSandbox_DeniesNetwork$")
+	command.Env = append(os.Environ(), sandboxTestStage+"=sandbox", "CORTEX_SANDBOX_TEST_ADDRESS="+address,
+		"CORTEX_SANDBOX_TEST_ARGS="+strings.Join(sandboxArgs, " "))
+	output, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("sandboxed dial failed to run: %v\n%s", err, output)
+	}
+	return string(output)
+}
+
+func TestRunSandbox_DeniesNetwork(t *testing.T) {
+	switch os.Getenv(sandboxTestStage) {
+	case "sandbox":
+		// Restrict this process and run the dial in its place.
+		os.Setenv(sandboxTestStage, "dial")
+		args := strings.Fields(os.Getenv("CORTEX_SANDBOX_TEST_ARGS"))
+		args = append(args, "--", os.Args[0], "-test.run=^TestRunSandbox_DeniesNetwork$")
+		t.Fatal(RunSandbox(args))
+	case "dial":
+		connection, err := net.Dial("tcp", os.Getenv("CORTEX_SANDBOX_TEST_ADDRESS"))
+		switch {
+		case err == nil:
+			connection.Close()
+			os.Stdout.WriteString("connected\n")
+		case errors.Is(err, syscall.EACCES):
+			os.Stdout.WriteString("denied\n")
+		default:
+			os.Stdout.WriteString("failed: " + err.Error() + "\n")
+		}
+		return
+	}
+
+	if landlockABI() < landlockNetworkABI {
+		t.Skip("this kernel's Landlock cannot deny network access")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connection.Close()
+		}
+	}()
+
+	if output := runSandboxStage(t, listener.Addr().String()); !strings.Contains(output, "connected") {
+		t.Fatalf("expected the dial to connect without --deny-network, got %q", output)
+	}
+	if output := runSandboxStage(t, listener.Addr().String(), "--deny-network"); !strings.Contains(output, "denied") {
+		t.Fatalf("expected the dial to be denied with --deny-network, got %q", output)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !linux
+
+package plugin
+
+import (
+	"os/exec"
+	"time"
+)
+
+// processUsage is not available outside Linux; limited plugins are reported
+// with EnforcementNone.
+func processUsage(int) (int64, time.Duration, error) {
+	return 0, 0, errResourcesUnsupported
+}
+
+// joinCgroup is not available outside Linux.
+func joinCgroup(string, string, int, ResourceLimits) (string, error) {
+	return "", errResourcesUnsupported
+}
+
+// cgroupOOMKills is not available outside Linux.
+func cgroupOOMKills(string) int {
+	return 0
+}
+
+// removeCgroup is not available outside Linux.
+func removeCgroup(string) {}
+
+// canDenyNetwork is false outside Linux.
+func canDenyNetwork(string) bool {
+	return false
+}
+
+// sandboxCommand fails outside Linux, so plugins with an FSRoot are not
+// started unconfined.
+func sandboxCommand(string, string, string, string, string, bool) (*exec.Cmd, []string, error) {
+	return nil, nil, errResourcesUnsupported
+}
+
+// RunSandbox is not available outside Linux.
+func RunSandbox([]string) error {
+	return errResourcesUnsupported
+}
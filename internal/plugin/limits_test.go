@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResourceLimits(t *testing.T) {
+	limits, err := ParseResourceLimits("finance-tracker:memory_mb=256,cpu_percent=50; quick-notes:fs_root=/srv/notes/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits["finance-tracker"] != (ResourceLimits{MemoryMB: 256, CPUPercent: 50}) {
+		t.Errorf("unexpected finance-tracker limits: %+v", limits["finance-tracker"])
+	}
+	if limits["quick-notes"] != (ResourceLimits{FSRoot: "/srv/notes"}) {
+		t.Errorf("unexpected quick-notes limits: %+v", limits["quick-notes"])
+	}
+
+	if limits, err := ParseResourceLimits(""); err != nil || len(limits) != 0 {
+		t.Errorf("expected no limits from an empty spec, got %v, %v", limits, err)
+	}
+
+	for _, spec := range []string{
+		"finance-tracker",
+		"finance-tracker:memory_mb=8",
+		"finance-tracker:cpu_percent=0",
+		"finance-tracker:fs_root=relative",
+		"finance-tracker:swap=1",
+		"finance-tracker:memory_mb=64;finance-tracker:cpu_percent=10",
+	} {
+		if _, err := ParseResourceLimits(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+// limitedLoader returns a loader with one plugin registered under "notes"
+// with the given limits, enforced by the watchdog, whose measurements and
+// kills go through the returned fakes.
+func limitedLoader(t *testing.T, limits ResourceLimits) (*Loader, *int64, *time.Duration, *[]int) {
+	t.Helper()
+
+	registry := NewRegistry()
+	registry.Register("notes", nil, &Manifest{ID: "notes"})
+	entry, _ := registry.Get("notes")
+	entry.pid, entry.limits, entry.enforcement = 4242, limits, EnforcementWatchdog
+
+	var memory int64
+	var cpuTime time.Duration
+	var killed []int
+	loader := NewLoader(t.TempDir(), t.TempDir(), registry)
+	loader.sampleUsage = func(int) (int64, time.Duration, error) { return memory, cpuTime, nil }
+	loader.killProcess = func(pid int) error {
+		killed = append(killed, pid)
+		return nil
+	}
+	return loader, &memory, &cpuTime, &killed
+}
+
+func TestCheckResources_KillsOverMemory(t *testing.T) {
+	loader, memory, _, killed := limitedLoader(t, ResourceLimits{MemoryMB: 64})
+	now := time.Now()
+
+	*memory = 32 << 20
+	loader.checkResources(now)
+	if len(*killed) != 0 {
+		t.Fatal("expected a plugin under its limit to be left alone")
+	}
+	health, err := loader.ResourceHealth("notes")
+	if err != nil || health.Usage == nil || health.Usage.MemoryBytes != 32<<20 || health.Enforcement != EnforcementWatchdog {
+		t.Fatalf("expected the measurement to be reported, got %+v, %v", health, err)
+	}
+
+	*memory = 100 << 20
+	loader.checkResources(now.Add(time.Second))
+	if len(*killed) != 1 || (*killed)[0] != 4242 {
+		t.Fatalf("expected the plugin over its memory limit to be killed, got %v", *killed)
+	}
+	health, _ = loader.ResourceHealth("notes")
+	if health.LastTermination == nil || !strings.Contains(health.LastTermination.Reason, "memory") {
+		t.Errorf("expected the termination reason to be reported, got %+v", health.LastTermination)
+	}
+}
+
+func TestCheckResources_ToleratesCPUBursts(t *testing.T) {
+	loader, _, cpuTime, killed := limitedLoader(t, ResourceLimits{CPUPercent: 50})
+	now := time.Now()
+	loader.checkResources(now)
+
+	// A second of CPU per second of wall time is 100%, twice the limit.
+	busy := func() {
+		*cpuTime += time.Second
+		now = now.Add(time.Second)
+		loader.checkResources(now)
+	}
+	for range cpuOverLimitSamples - 1 {
+		busy()
+	}
+	// An idle second in between starts the count over.
+	now = now.Add(time.Second)
+	loader.checkResources(now)
+	for range cpuOverLimitSamples - 1 {
+		busy()
+	}
+	if len(*killed) != 0 {
+		t.Fatalf("expected bursts shorter than %d checks to be tolerated, got kills %v", cpuOverLimitSamples, *killed)
+	}
+
+	busy()
+	if len(*killed) != 1 {
+		t.Fatalf("expected sustained CPU use over the limit to kill the plugin, got %v", *killed)
+	}
+	health, _ := loader.ResourceHealth("notes")
+	if health.LastTermination == nil || !strings.Contains(health.LastTermination.Reason, "CPU") {
+		t.Errorf("expected the termination reason to be reported, got %+v", health.LastTermination)
+	}
+}
+
+func TestCheckResources_CgroupEnforcedNotKilled(t *testing.T) {
+	loader, memory, _, killed := limitedLoader(t, ResourceLimits{MemoryMB: 64})
+	entry, _ := loader.registry.Get("notes")
+	entry.enforcement = EnforcementCgroup
+
+	*memory = 100 << 20
+	loader.checkResources(time.Now())
+	if len(*killed) != 0 {
+		t.Error("expected the kernel, not the monitor, to enforce cgroup limits")
+	}
+	if health, _ := loader.ResourceHealth("notes"); health.Usage == nil {
+		t.Error("expected cgroup-confined plugins to still be measured")
+	}
+}
+
+func TestResourceHealth_UnknownPlugin(t *testing.T) {
+	loader := NewLoader(t.TempDir(), t.TempDir(), NewRegistry())
+	if _, err := loader.ResourceHealth("missing"); err != ErrPluginNotFound {
+		t.Errorf("expected ErrPluginNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MigrationPolicy decides what the loader does with a plugin whose migrations
+// fail linting.
+type MigrationPolicy string
+
+const (
+	// MigrationPolicyOff skips migration linting.
+	MigrationPolicyOff MigrationPolicy = "off"
+	// MigrationPolicyWarn logs findings and runs the migrations anyway.
+	MigrationPolicyWarn MigrationPolicy = "warn"
+	// MigrationPolicyEnforce refuses to load a plugin with findings.
+	MigrationPolicyEnforce MigrationPolicy = "enforce"
+)
+
+// ValidMigrationPolicy reports whether policy names a MigrationPolicy.
+func ValidMigrationPolicy(policy string) bool {
+	switch MigrationPolicy(policy) {
+	case MigrationPolicyOff, MigrationPolicyWarn, MigrationPolicyEnforce:
+		return true
+	}
+	return false
+}
+
+// ErrUnsafeMigrations is returned when MigrationPolicyEnforce stops a plugin
+// from loading because of lint findings.
+var ErrUnsafeMigrations = errors.New("unsafe migrations")
+
+// Migration lint rules.
+const (
+	RuleDropTable          = "drop-table"
+	RuleMissingTransaction = "missing-transaction"
+	RuleNonIdempotentSeed  = "non-idempotent-seed"
+)
+
+// lintIgnoreDirective in a comment before a statement silences the listed
+// rules for that statement, for changes that are deliberate:
+//
+//	-- cortex:lint-ignore drop-table,missing-transaction rebuilt once, run tracked in _migrations
+const lintIgnoreDirective = "cortex:lint-ignore"
+
+// MigrationFinding is a statement in a plugin migration that can destroy or
+// duplicate data.
+type MigrationFinding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (f MigrationFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", f.File, f.Line, f.Message, f.Rule)
+}
+
+// LintMigrations statically checks migration files for statements that are
+// unsafe when a migration runs again, as happens when a plugin is reinstalled
+// or does not track which migrations it already applied:
+//
+//   - drop-table: DROP TABLE without IF EXISTS.
+//   - non-idempotent-seed: INSERT without OR IGNORE, OR REPLACE, ON CONFLICT or
+//     a NOT EXISTS guard.
+//   - missing-transaction: a destructive statement (DROP TABLE, ALTER TABLE,
+//     DELETE, UPDATE) in a multi-statement file not wrapped in BEGIN ... COMMIT.
+func LintMigrations(files []MigrationFile) []MigrationFinding {
+	findings := []MigrationFinding{}
+	for _, file := range files {
+		findings = append(findings, lintMigration(file)...)
+	}
+	return findings
+}
+
+func lintMigration(file MigrationFile) []MigrationFinding {
+	var findings []MigrationFinding
+	report := func(statement sqlStatement, rule string, message string) {
+		if slices.Contains(statement.ignored, rule) {
+			return
+		}
+		findings = append(findings, MigrationFinding{File: file.Name, Line: statement.line, Rule: rule, Message: message})
+	}
+
+	statements := splitStatements(file.SQL)
+	firstDestructive := -1
+	for index, statement := range statements {
+		switch {
+		case statement.startsWith("DROP", "TABLE") && !statement.startsWith("DROP", "TABLE", "IF", "EXISTS"):
+			report(statement, RuleDropTable, "DROP TABLE without IF EXISTS fails or drops data when the migration runs again")
+		case statement.isInsert() && !statement.isIdempotentInsert():
+			report(statement, RuleNonIdempotentSeed, "INSERT without OR IGNORE, ON CONFLICT or a NOT EXISTS guard duplicates rows when the migration runs again")
+		}
+
+		if firstDestructive < 0 && statement.isDestructive() {
+			firstDestructive = index
+		}
+	}
+
+	if firstDestructive >= 0 && len(statements) > 1 && !inTransaction(statements) {
+		report(statements[firstDestructive], RuleMissingTransaction,
+			"destructive statement outside a transaction: wrap the migration in BEGIN ... COMMIT so a failure cannot leave it half applied")
+	}
+
+	return findings
+}
+
+// inTransaction reports whether statements are wrapped in BEGIN ... COMMIT.
+func inTransaction(statements []sqlStatement) bool {
+	last := statements[len(statements)-1]
+	return statements[0].startsWith("BEGIN") && (last.startsWith("COMMIT") || last.startsWith("END"))
+}
+
+// sqlStatement is one statement of a migration, reduced to its words.
+type sqlStatement struct {
+	// line is where the statement starts, 1-based.
+	line int
+	// tokens are the statement's keywords and identifiers, upper-cased.
+	// String literals, quoted identifiers and punctuation are dropped.
+	tokens []string
+	// ignored lists the rules silenced by lint-ignore directives.
+	ignored []string
+}
+
+func (s sqlStatement) startsWith(words ...string) bool {
+	return len(s.tokens) >= len(words) && slices.Equal(s.tokens[:len(words)], words)
+}
+
+func (s sqlStatement) contains(words ...string) bool {
+	for index := range s.tokens {
+		if len(s.tokens)-index >= len(words) && slices.Equal(s.tokens[index:index+len(words)], words) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s sqlStatement) isInsert() bool {
+	return s.startsWith("INSERT") || (s.startsWith("WITH") && s.contains("INSERT"))
+}
+
+func (s sqlStatement) isIdempotentInsert() bool {
+	return s.contains("INSERT", "OR", "IGNORE") || s.contains("INSERT", "OR", "REPLACE") ||
+		s.contains("ON", "CONFLICT") || s.contains("NOT", "EXISTS")
+}
+
+func (s sqlStatement) isDestructive() bool {
+	return s.startsWith("DROP", "TABLE") || s.startsWith("ALTER", "TABLE") ||
+		s.startsWith("DELETE") || s.startsWith("UPDATE")
+}
+
+// splitStatements splits a SQL script into statements. It understands
+// comments, quoted strings and identifiers, and CREATE TRIGGER bodies, whose
+// inner statements end in semicolons too.
+func splitStatements(sql string) []sqlStatement {
+	var statements []sqlStatement
+	current := sqlStatement{}
+	line := 1
+	inTrigger := false
+	caseDepth := 0
+	// triggerEnd is set when the last token is the END closing a trigger body.
+	triggerEnd := false
+
+	finish := func() {
+		if len(current.tokens) > 0 {
+			statements = append(statements, current)
+		}
+		current = sqlStatement{}
+		inTrigger, caseDepth, triggerEnd = false, 0, false
+	}
+
+	for index := 0; index < len(sql); {
+		char := sql[index]
+		switch {
+		case char == '\n':
+			line++
+			index++
+
+		case strings.HasPrefix(sql[index:], "--"):
+			end := strings.IndexByte(sql[index:], '\n')
+			if end < 0 {
+				end = len(sql) - index
+			}
+			current.ignored = append(current.ignored, lintIgnores(sql[index+2:index+end])...)
+			index += end
+
+		case strings.HasPrefix(sql[index:], "/*"):
+			end := strings.Index(sql[index+2:], "*/")
+			if end < 0 {
+				end = len(sql) - index - 2
+			}
+			comment := sql[index+2 : index+2+end]
+			current.ignored = append(current.ignored, lintIgnores(comment)...)
+			line += strings.Count(comment, "\n")
+			index += min(len(sql)-index, end+4)
+
+		case char == '\'' || char == '"' || char == '`' || char == '[':
+			closing := char
+			if char == '[' {
+				closing = ']'
+			}
+			end := index + 1
+			for end < len(sql) {
+				if sql[end] == closing {
+					// A doubled quote is an escaped quote inside the literal.
+					if closing != ']' && end+1 < len(sql) && sql[end+1] == closing {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			line += strings.Count(sql[index:min(end, len(sql))], "\n")
+			index = min(end+1, len(sql))
+			triggerEnd = false
+
+		case char == ';':
+			index++
+			if inTrigger && !triggerEnd {
+				continue
+			}
+			finish()
+
+		case isWordChar(char):
+			end := index
+			for end < len(sql) && isWordChar(sql[end]) {
+				end++
+			}
+			word := strings.ToUpper(sql[index:end])
+			index = end
+
+			if len(current.tokens) == 0 {
+				current.line = line
+			}
+			current.tokens = append(current.tokens, word)
+
+			if !inTrigger && word == "TRIGGER" && current.tokens[0] == "CREATE" && len(current.tokens) <= 3 {
+				inTrigger = true
+			}
+			triggerEnd = false
+			if inTrigger {
+				switch word {
+				case "CASE":
+					caseDepth++
+				case "END":
+					if caseDepth > 0 {
+						caseDepth--
+					} else {
+						triggerEnd = true
+					}
+				}
+			}
+
+		default:
+			index++
+			if char != ' ' && char != '\t' && char != '\r' {
+				triggerEnd = false
+			}
+		}
+	}
+	finish()
+
+	return statements
+}
+
+// lintIgnores returns the rules listed by a lint-ignore directive in comment.
+func lintIgnores(comment string) []string {
+	directive, found := strings.CutPrefix(strings.TrimSpace(comment), lintIgnoreDirective)
+	if !found {
+		return nil
+	}
+
+	fields := strings.Fields(directive)
+	if len(fields) == 0 {
+		return nil
+	}
+	return strings.Split(fields[0], ",")
+}
+
+func isWordChar(char byte) bool {
+	return char == '_' || char >= '0' && char <= '9' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+}
@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// lintRules returns the rules reported for a single migration file.
+func lintRules(t *testing.T, sql string) []string {
+	t.Helper()
+
+	var rules []string
+	for _, finding := range LintMigrations([]MigrationFile{{Name: "001.sql", SQL: sql}}) {
+		rules = append(rules, finding.Rule)
+	}
+	return rules
+}
+
+func TestLintMigrations_Rules(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"idempotent schema", "CREATE TABLE IF NOT EXISTS notes (id INTEGER);\nINSERT OR IGNORE INTO notes (id) VALUES (1);", nil},
+		{"drop table", "DROP TABLE notes;", []string{RuleDropTable}},
+		{"drop table if exists", "drop table if exists notes;", nil},
+		{"plain seed", "INSERT INTO tags (name) VALUES ('go');", []string{RuleNonIdempotentSeed}},
+		{"upsert seed", "INSERT INTO tags (name) VALUES ('go') ON CONFLICT DO NOTHING;", nil},
+		{"guarded seed", "INSERT INTO tags (name) SELECT 'go' WHERE NOT EXISTS (SELECT 1 FROM tags WHERE name = 'go');", nil},
+		{"replace", "REPLACE INTO tags (name) VALUES ('go');", nil},
+		{
+			"destructive without transaction",
+			"CREATE TABLE IF NOT EXISTS notes_new (id INTEGER);\nDELETE FROM notes;",
+			[]string{RuleMissingTransaction},
+		},
+		{
+			"destructive in transaction",
+			"BEGIN;\nCREATE TABLE IF NOT EXISTS notes_new (id INTEGER);\nDELETE FROM notes;\nCOMMIT;",
+			nil,
+		},
+		{"single destructive statement", "ALTER TABLE notes ADD COLUMN pinned INTEGER;", nil},
+		{
+			"keywords in strings and comments",
+			"-- DROP TABLE notes;\nINSERT OR IGNORE INTO log (message) VALUES ('DROP TABLE notes; INSERT INTO x');",
+			nil,
+		},
+		{
+			"trigger body",
+			"CREATE TRIGGER IF NOT EXISTS touch AFTER UPDATE ON notes BEGIN\n  UPDATE notes SET updated_at = CASE WHEN 1 THEN 'now' END WHERE id = NEW.id;\n  INSERT INTO log (id) VALUES (NEW.id);\nEND;",
+			nil,
+		},
+		{
+			"ignored",
+			"-- cortex:lint-ignore drop-table,missing-transaction rebuilt once\nDROP TABLE notes;\nALTER TABLE notes_new RENAME TO notes;",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := lintRules(t, test.sql)
+			if len(got) != len(test.want) {
+				t.Fatalf("expected rules %v, got %v", test.want, got)
+			}
+			for i := range test.want {
+				if got[i] != test.want[i] {
+					t.Fatalf("expected rules %v, got %v", test.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLintMigrations_ReportsLines(t *testing.T) {
+	findings := LintMigrations([]MigrationFile{{
+		Name: "002_rebuild.sql",
+		SQL:  "CREATE TABLE IF NOT EXISTS a (id INTEGER);\n\n/* rebuild\n   b */\nDROP TABLE b;\n",
+	}})
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %v", findings)
+	}
+	if findings[0].Line != 5 || findings[0].File != "002_rebuild.sql" {
+		t.Errorf("expected the DROP TABLE at 002_rebuild.sql:5, got %s", findings[0])
+	}
+}
+
+func TestLintMigrations_FirstPartyPluginsAreClean(t *testing.T) {
+	// Under CORTEX_MIGRATION_LINT=enforce a finding stops the plugin loading.
+	paths, err := filepath.Glob(filepath.Join("..", "..", "plugins", "*", "backend", "migrations", "*.sql"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("expected the first-party migrations, got %v (err %v)", paths, err)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		for _, finding := range LintMigrations([]MigrationFile{{Name: path, SQL: string(data)}}) {
+			t.Errorf("unexpected finding: %s", finding)
+		}
+	}
+}
+
+// listingPlugin lists fixed SQL migrations.
+type listingPlugin struct {
+	fakePlugin
+	files []MigrationFile
+	err   error
+}
+
+func (p *listingPlugin) Migrations() ([]MigrationFile, error) {
+	return p.files, p.err
+}
+
+func TestLoaderCheckMigrations_Policy(t *testing.T) {
+	unsafe := &listingPlugin{files: []MigrationFile{{Name: "001.sql", SQL: "DROP TABLE notes;"}}}
+	loader := NewLoader(t.TempDir(), t.TempDir(), NewRegistry())
+
+	if err := loader.checkMigrations("notes", unsafe); err != nil {
+		t.Fatalf("expected the default warn policy to allow loading, got %v", err)
+	}
+
+	loader.SetMigrationPolicy(MigrationPolicyEnforce)
+	if err := loader.checkMigrations("notes", unsafe); !errors.Is(err, ErrUnsafeMigrations) {
+		t.Fatalf("expected ErrUnsafeMigrations, got %v", err)
+	}
+
+	// Plugins that cannot list their migrations are not blocked.
+	unlisted := &listingPlugin{err: ErrNotImplemented}
+	if err := loader.checkMigrations("legacy", unlisted); err != nil {
+		t.Fatalf("expected plugins without Migrations to load, got %v", err)
+	}
+	if err := loader.checkMigrations("plain", &fakePlugin{}); err != nil {
+		t.Fatalf("expected plugins without Migrations to load, got %v", err)
+	}
+
+	loader.SetMigrationPolicy(MigrationPolicyOff)
+	if err := loader.checkMigrations("notes", unsafe); err != nil {
+		t.Fatalf("expected the off policy to skip linting, got %v", err)
+	}
+}
@@ -1,60 +1,303 @@
 package plugin
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/go-hclog"
 	goplugin "github.com/hashicorp/go-plugin"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+	"github.com/alvarotorresc/cortex/internal/logging"
+)
+
+// SettingsStore persists each plugin's settings together with the plugin
+// version that wrote them. The host database implements it.
+type SettingsStore interface {
+	GetPluginSettings(pluginID string) (settings []byte, version string, found bool, err error)
+	SavePluginSettings(pluginID string, settings []byte, version string) error
+}
+
+// LoadRecorder is notified every time a plugin starts, so restarts and load
+// times can be shown in the system history. The host database implements it.
+type LoadRecorder interface {
+	RecordPluginLoad(pluginID string, loadDuration time.Duration, warmupDuration time.Duration) error
+}
+
+// InstallTracker remembers every plugin across restarts: when it was first
+// installed, when and with which version it last loaded, and how its latest
+// migrations went, so the loader can tell an upgrade from a reload. The host
+// database implements it.
+type InstallTracker interface {
+	LastPluginVersion(pluginID string) (version string, found bool, err error)
+	RecordPluginMigration(pluginID string, status string, message string) error
+	RecordPluginStart(pluginID string, version string) error
+}
+
+// Migration statuses recorded with the InstallTracker. Plugins without a
+// database permission have no migrations to run.
+const (
+	MigrationApplied     = "applied"
+	MigrationFailed      = "failed"
+	MigrationNotRequired = "not_required"
 )
 
 // Loader discovers and launches plugin subprocesses.
 type Loader struct {
-	pluginDir string
-	dataDir   string
-	registry  *Registry
+	pluginDir      string
+	dataDir        string
+	registry       *Registry
+	settingsStore  SettingsStore
+	loadRecorder   LoadRecorder
+	installTracker InstallTracker
+	changeListener ChangeListener
+	attachments    AttachmentStore
+	notifier       Notifier
+	values         ValueStore
+	secrets        SecretStore
+	logs           *logging.PluginLogs
+
+	// migrationPolicy decides what happens to plugins whose migrations fail linting.
+	migrationPolicy MigrationPolicy
+	// databaseKey is the at-rest master key; nil leaves plugin databases in plaintext.
+	databaseKey []byte
+	// demoMode seeds each plugin database with sample data the first time it is migrated.
+	demoMode bool
+	// disabled holds the IDs of plugins LoadAll skips.
+	disabled map[string]bool
+	// requestTimeout bounds API requests forwarded to plugins, and keepalive
+	// is the interval the host pings their connections at; zero disables
+	// either.
+	requestTimeout time.Duration
+	keepalive      time.Duration
+
+	// relaunch restarts a crashed plugin; tests replace it to avoid real subprocesses.
+	relaunch func(id string) error
+
+	// walStats holds the latest WAL check of each plugin database, and
+	// walWarned the plugins already warned about an oversized WAL.
+	walMu     sync.Mutex
+	walStats  map[string]*WALStats
+	walWarned map[string]bool
+
+	// resourceLimits holds the limits of plugins by ID. resourceWatches,
+	// resourceUsage and terminations are the resource monitor's state by
+	// registry key; terminations survive relaunches.
+	resourceMu      sync.Mutex
+	resourceLimits  map[string]ResourceLimits
+	resourceWatches map[string]*resourceWatch
+	resourceUsage   map[string]ResourceUsage
+	terminations    map[string]Termination
+	// cgroupRoot is the delegated cgroup v2 directory limited plugins are
+	// placed under, and sandboxLauncher the executable confining plugins
+	// with an FSRoot.
+	cgroupRoot      string
+	sandboxLauncher string
+	// sampleUsage and killProcess measure and kill plugin processes; tests
+	// replace them.
+	sampleUsage func(pid int) (int64, time.Duration, error)
+	killProcess func(pid int) error
+
+	// jobs holds the stop channel of every job plugins scheduled, by plugin
+	// and job name.
+	jobsMu sync.Mutex
+	jobs   map[string]map[string]chan struct{}
 }
 
 // NewLoader creates a loader that scans pluginDir for plugins
-// and stores runtime data in dataDir.
+// and stores runtime data in dataDir. Plugin output is captured
+// into rotating log files under dataDir/logs.
 func NewLoader(pluginDir string, dataDir string, registry *Registry) *Loader {
-	return &Loader{
+	loader := &Loader{
 		pluginDir: pluginDir,
 		dataDir:   dataDir,
 		registry:  registry,
+		logs:      logging.NewPluginLogs(filepath.Join(dataDir, "logs")),
+
+		migrationPolicy: MigrationPolicyWarn,
+
+		resourceWatches: make(map[string]*resourceWatch),
+		resourceUsage:   make(map[string]ResourceUsage),
+		terminations:    make(map[string]Termination),
+		sampleUsage:     processUsage,
+		killProcess:     killPID,
+	}
+	loader.relaunch = loader.restartPlugin
+	return loader
+}
+
+// SetSettingsStore enables settings migrations on load using the given store,
+// and lets plugins read their settings with GetSettings.
+func (l *Loader) SetSettingsStore(store SettingsStore) {
+	l.settingsStore = store
+}
+
+// SetLoadRecorder records every successful plugin load with the given recorder.
+func (l *Loader) SetLoadRecorder(recorder LoadRecorder) {
+	l.loadRecorder = recorder
+}
+
+// SetInstallTracker records each plugin's loads, versions and migration
+// outcomes with the given tracker, and enables Upgrade hooks.
+func (l *Loader) SetInstallTracker(tracker InstallTracker) {
+	l.installTracker = tracker
+}
+
+// SetMigrationPolicy sets how plugins whose migrations fail linting are
+// treated. The default is MigrationPolicyWarn.
+func (l *Loader) SetMigrationPolicy(policy MigrationPolicy) {
+	l.migrationPolicy = policy
+}
+
+// SetDatabaseKey enables at-rest encryption of plugin databases. Each plugin
+// receives a key derived from master in its environment and encrypts its
+// database with it.
+func (l *Loader) SetDatabaseKey(master []byte) {
+	l.databaseKey = master
+}
+
+// SetDemoMode makes the loader call each plugin's optional SeedDemo hook
+// after its first migration, so a fresh instance opens with sample data.
+func (l *Loader) SetDemoMode(enabled bool) {
+	l.demoMode = enabled
+}
+
+// SetDisabledPlugins makes LoadAll skip the plugins with the given IDs. They
+// stay installed and can still be loaded explicitly.
+func (l *Loader) SetDisabledPlugins(ids []string) {
+	l.disabled = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		l.disabled[id] = true
 	}
 }
 
-// LoadAll discovers plugins in pluginDir and starts them.
-// Each plugin directory must contain a "plugin" binary and a "manifest.json" file.
+// SetAttachmentStore lets plugins that declare the attachments permission
+// store files with the given store.
+func (l *Loader) SetAttachmentStore(store AttachmentStore) {
+	l.attachments = store
+}
+
+// SetNotifier lets plugins that declare the notifications permission send
+// notifications through the given notifier.
+func (l *Loader) SetNotifier(notifier Notifier) {
+	l.notifier = notifier
+}
+
+// SetValueStore gives plugins that use HostServices a key-value store.
+func (l *Loader) SetValueStore(store ValueStore) {
+	l.values = store
+}
+
+// SetChangeListener forwards plugins' NotifyChanged calls to the given listener.
+func (l *Loader) SetChangeListener(listener ChangeListener) {
+	l.changeListener = listener
+}
+
+// LoadAll discovers plugins in pluginDir and starts them, except disabled ones.
+// Each plugin is either a directory containing a "plugin" binary and a
+// "manifest.json" file, or an {id}.cortexplugin archive. Plugins start after
+// the plugins they depend on; one whose dependencies are missing, disabled
+// or part of a cycle is not started.
 func (l *Loader) LoadAll() error {
 	entries, err := os.ReadDir(l.pluginDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			log.Println("No plugins directory found, skipping plugin loading")
+			slog.Info("no plugins directory found, skipping plugin loading", "dir", l.pluginDir)
 			return nil
 		}
 		return fmt.Errorf("reading plugin directory: %w", err)
 	}
 
+	// Plugins start after the plugins they depend on, so their manifests
+	// are read first.
+	paths := make(map[string]string)
+	dependencies := make(map[string][]string)
 	for _, entry := range entries {
+		// Hidden entries are installer staging areas and extracted archives, not plugins.
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		id := entry.Name()
 		if !entry.IsDir() {
+			if !strings.HasSuffix(id, ArchiveExtension) {
+				continue
+			}
+			id = strings.TrimSuffix(id, ArchiveExtension)
+			// An unpacked directory with the same ID wins over the archive.
+			if info, err := os.Stat(filepath.Join(l.pluginDir, id)); err == nil && info.IsDir() {
+				slog.Info("skipping plugin archive, plugin directory takes precedence", "archive", entry.Name(), "plugin", id)
+				continue
+			}
+		}
+
+		if l.disabled[id] {
+			slog.Info("skipping disabled plugin", "plugin", id)
+			continue
+		}
+		pluginPath, manifest, err := l.readManifest(id)
+		if err != nil {
+			slog.Error("failed to load plugin", "plugin", id, "error", err)
 			continue
 		}
-		if err := l.LoadPlugin(entry.Name()); err != nil {
-			log.Printf("Failed to load plugin %s: %v", entry.Name(), err)
+		paths[id] = pluginPath
+		dependencies[id] = manifest.Dependencies
+	}
+
+	order, skipped := orderByDependencies(dependencies)
+	for id, err := range skipped {
+		slog.Error("failed to load plugin", "plugin", id, "error", err)
+	}
+	for _, id := range order {
+		if err := l.launch(id, id, paths[id]); err != nil {
+			slog.Error("failed to load plugin", "plugin", id, "error", err)
 		}
 	}
 
 	return nil
 }
 
-// LoadPlugin starts a single plugin by its directory name.
+// readManifest returns the directory LoadPlugin would launch plugin id from
+// and the manifest in it.
+func (l *Loader) readManifest(id string) (string, *Manifest, error) {
+	pluginPath, err := l.resolvePluginPath(id)
+	if err != nil {
+		return "", nil, fmt.Errorf("extracting plugin archive: %w", err)
+	}
+	manifestData, err := os.ReadFile(filepath.Join(pluginPath, "manifest.json"))
+	if err != nil {
+		return "", nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return "", nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	return pluginPath, &manifest, nil
+}
+
+// LoadPlugin starts a single plugin by its directory or archive name.
 func (l *Loader) LoadPlugin(id string) error {
-	pluginPath := filepath.Join(l.pluginDir, id)
+	pluginPath, err := l.resolvePluginPath(id)
+	if err != nil {
+		return fmt.Errorf("extracting plugin archive: %w", err)
+	}
+	return l.launch(id, id, pluginPath)
+}
+
+// launch starts the plugin in pluginPath and registers it under key. The
+// process gets the data directory of plugin id: key is id itself for live
+// plugins and CanaryKey(id) for a canary, which runs against live data.
+func (l *Loader) launch(key string, id string, pluginPath string) error {
+	started := time.Now()
 	binaryPath := filepath.Join(pluginPath, "plugin")
 	manifestPath := filepath.Join(pluginPath, "manifest.json")
 
@@ -69,24 +312,145 @@ func (l *Loader) LoadPlugin(id string) error {
 		return fmt.Errorf("parsing manifest: %w", err)
 	}
 
+	if key != id && manifest.ID != id {
+		return fmt.Errorf("canary manifest declares plugin id %q, expected %q", manifest.ID, id)
+	}
+
+	if err := validatePermissions(manifest.Permissions); err != nil {
+		return fmt.Errorf("validating manifest permissions: %w", err)
+	}
+
+	if err := validateRoutes(manifest.Routes); err != nil {
+		return fmt.Errorf("validating manifest routes: %w", err)
+	}
+
+	if err := validateCSP(manifest.CSP); err != nil {
+		return fmt.Errorf("validating manifest csp: %w", err)
+	}
+
+	if err := validateCommands(manifest.CLI, manifest.Commands); err != nil {
+		return fmt.Errorf("validating manifest commands: %w", err)
+	}
+	if err := validateUpdateURL(manifest.UpdateURL); err != nil {
+		return fmt.Errorf("validating manifest update_url: %w", err)
+	}
+	if err := validateWidgets(manifest.Widgets); err != nil {
+		return fmt.Errorf("validating manifest widgets: %w", err)
+	}
+	if err := validateDependencies(id, manifest.Dependencies); err != nil {
+		return fmt.Errorf("validating manifest dependencies: %w", err)
+	}
+	if route, owner, found := l.registry.routeConflict(id, manifest.Routes); found {
+		return fmt.Errorf("route %s is already claimed by plugin %s", route, owner)
+	}
+	if owner, found := l.registry.cliConflict(id, &manifest); found {
+		return fmt.Errorf("cli name %s is already claimed by plugin %s", manifest.CLIName(), owner)
+	}
+	if missing := l.registry.missingDependencies(&manifest); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingDependency, strings.Join(missing, ", "))
+	}
+
 	// Ensure plugin data directory exists
-	dataPath := filepath.Join(l.dataDir, "plugins", id)
+	dataPath, err := filepath.Abs(filepath.Join(l.dataDir, "plugins", id))
+	if err != nil {
+		return fmt.Errorf("resolving data directory: %w", err)
+	}
 	if err := os.MkdirAll(dataPath, 0755); err != nil {
 		return fmt.Errorf("creating data directory: %w", err)
 	}
 
-	// Launch plugin subprocess via go-plugin
-	client := goplugin.NewClient(&goplugin.ClientConfig{
-		HandshakeConfig:  Handshake,
-		Plugins:          PluginMap,
-		Cmd:              exec.Command(binaryPath),
+	// The plugin runs from its data directory, so relative plugin and data
+	// directories, such as the defaults, must not be resolved against it
+	binaryPath, err = filepath.Abs(binaryPath)
+	if err != nil {
+		return fmt.Errorf("resolving plugin binary: %w", err)
+	}
+
+	// Launch plugin subprocess via go-plugin, confined to its data directory
+	// and an environment derived from its declared permissions. A plugin with
+	// a filesystem root or without the network permission is started through
+	// the sandbox launcher; where the kernel cannot deny network access, only
+	// HTTP clients honoring the proxy variables are kept offline.
+	limits := l.limitsFor(key)
+	denyNetwork := !manifest.HasPermission(PermissionNetwork)
+	if denyNetwork && !canDenyNetwork(l.sandboxLauncher) {
+		slog.Warn("plugin network access cannot be denied on this platform; only proxy-aware HTTP clients are blocked", "plugin", key)
+		denyNetwork = false
+	}
+	command := exec.Command(binaryPath)
+	var sandboxEnv []string
+	if limits.FSRoot != "" || denyNetwork {
+		command, sandboxEnv, err = sandboxCommand(l.sandboxLauncher, key, binaryPath, dataPath, limits.FSRoot, denyNetwork)
+		if err != nil {
+			return fmt.Errorf("sandboxing plugin: %w", err)
+		}
+	}
+	command.Dir = dataPath
+	command.Env = append(pluginEnvironment(&manifest, dataPath), sandboxEnv...)
+	if l.databaseKey != nil && needsDatabase(&manifest) {
+		command.Env = append(command.Env, atrest.KeyEnv+"="+atrest.EncodeKey(atrest.PluginKey(l.databaseKey, id)))
+	}
+	if secrets := l.secretsEnvironment(id); secrets != "" {
+		command.Env = append(command.Env, secrets)
+	}
+
+	// Capture everything the plugin prints, plus go-plugin's own messages
+	// about it (start, exit status, panics), in the plugin's log file.
+	logWriter, err := l.logs.Writer(key)
+	if err != nil {
+		return fmt.Errorf("opening plugin log: %w", err)
+	}
+	stdout, err := l.logs.StreamWriter(key, "STDOUT")
+	if err != nil {
+		return fmt.Errorf("opening plugin log: %w", err)
+	}
+	stderr, err := l.logs.StreamWriter(key, "STDERR")
+	if err != nil {
+		return fmt.Errorf("opening plugin log: %w", err)
+	}
+
+	grpcPlugin := &CortexGRPCPlugin{PluginID: id, Listener: l.changeListener, Values: l.values, Scheduler: l, Settings: l.settingsStore}
+	if manifest.HasPermission(PermissionAttachments) {
+		grpcPlugin.Attachments = l.attachments
+	}
+	if manifest.HasPermission(PermissionNotifications) {
+		grpcPlugin.Notifier = l.notifier
+	}
+	if manifest.HasPermission(PermissionPluginsRead) {
+		grpcPlugin.Caller = l
+	}
+
+	clientConfig := &goplugin.ClientConfig{
+		HandshakeConfig: Handshake,
+		VersionedPlugins: supportedPluginSets(map[string]goplugin.Plugin{
+			"cortex_plugin": grpcPlugin,
+		}),
+		Cmd:              command,
+		SkipHostEnv:      true,
 		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
-	})
+		SyncStdout:       stdout,
+		SyncStderr:       stderr,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   key,
+			Level:  hclog.Debug,
+			Output: logWriter,
+		}),
+	}
+	client := goplugin.NewClient(clientConfig)
+
+	// go-plugin negotiates the plugin API when the subprocess starts and only
+	// dials it in Client, so keepalive is turned on just for plugins whose
+	// server accepts pings.
+	if _, err := client.Start(); err != nil {
+		client.Kill()
+		return fmt.Errorf("connecting to plugin: %w", incompatibleSDK(err))
+	}
+	clientConfig.GRPCDialOptions = l.keepaliveOptions(client.NegotiatedVersion())
 
 	rpcClient, err := client.Client()
 	if err != nil {
 		client.Kill()
-		return fmt.Errorf("connecting to plugin: %w", err)
+		return fmt.Errorf("connecting to plugin: %w", incompatibleSDK(err))
 	}
 
 	raw, err := rpcClient.Dispense("cortex_plugin")
@@ -101,45 +465,410 @@ func (l *Loader) LoadPlugin(id string) error {
 		return fmt.Errorf("plugin does not implement CortexPlugin interface")
 	}
 
-	// Run database migrations
-	databasePath := filepath.Join(dataPath, "db.sqlite")
-	if err := cortexPlugin.Migrate(databasePath); err != nil {
+	pid := 0
+	if reattach := client.ReattachConfig(); reattach != nil {
+		pid = reattach.Pid
+	}
+	enforcement, cgroup := l.confine(key, pid, limits)
+
+	// Until the plugin is registered and its cgroup handed to the resource
+	// monitor, every failure below kills the plugin and leaves the cgroup
+	// behind; remove it once the process is gone.
+	registered := false
+	defer func() {
+		if !registered && cgroup != "" {
+			removeCgroup(cgroup)
+		}
+	}()
+
+	sdk, err := negotiateSDK(client.NegotiatedVersion(), cortexPlugin)
+	if err != nil {
 		client.Kill()
-		return fmt.Errorf("running migrations: %w", err)
+		return err
+	}
+	if sdk.Outdated {
+		slog.Warn("plugin was built with an older SDK and runs without newer host features; rebuild it",
+			"plugin", key, "protocol", sdk.Protocol, "host_protocol", SDKProtocolVersion)
+	}
+
+	// Run database migrations. Plugins without a db permission get no database.
+	if needsDatabase(&manifest) {
+		if err := l.checkMigrations(key, cortexPlugin); err != nil {
+			l.recordMigration(key, id, MigrationFailed, err.Error())
+			client.Kill()
+			return err
+		}
+
+		databasePath := filepath.Join(dataPath, "db.sqlite")
+		if err := cortexPlugin.Migrate(databasePath); err != nil {
+			l.recordMigration(key, id, MigrationFailed, err.Error())
+			client.Kill()
+			return fmt.Errorf("running migrations: %w", err)
+		}
+		l.recordMigration(key, id, MigrationApplied, "")
+
+		if l.demoMode {
+			seedDemo(key, cortexPlugin, dataPath)
+		}
+	} else {
+		l.recordMigration(key, id, MigrationNotRequired, "")
+	}
+
+	// Upgrade stored settings and data written by a previous plugin version.
+	// A canary leaves them alone until it is promoted, so rolling back stays
+	// safe.
+	if key == id {
+		l.migrateSettings(id, cortexPlugin, manifest.Version)
+		if err := l.upgrade(id, cortexPlugin, manifest.Version); err != nil {
+			client.Kill()
+			return err
+		}
 	}
 
+	// Warm up before the plugin is registered, so no request reaches it cold.
+	warmupDuration := warmup(key, cortexPlugin)
+
 	// Register plugin in the registry
-	l.registry.Register(id, client, &manifest)
-	entry, _ := l.registry.Get(id)
+	l.registry.Register(key, client, &manifest)
+	entry, _ := l.registry.Get(key)
 	entry.Plugin = cortexPlugin
+	entry.SDK = sdk
+	l.resourceMu.Lock()
+	entry.pid, entry.limits, entry.enforcement, entry.cgroup = pid, limits, enforcement, cgroup
+	l.resourceMu.Unlock()
+	registered = true
+
+	loadDuration := time.Since(started)
+	if key == id && l.installTracker != nil {
+		if err := l.installTracker.RecordPluginStart(id, manifest.Version); err != nil {
+			slog.Warn("recording plugin start", "plugin", id, "error", err)
+		}
+	}
+	if l.loadRecorder != nil {
+		if err := l.loadRecorder.RecordPluginLoad(key, loadDuration, warmupDuration); err != nil {
+			slog.Warn("recording plugin load", "plugin", key, "error", err)
+		}
+	}
+
+	slog.Info("plugin loaded", "plugin", key, "name", manifest.Name, "version", manifest.Version,
+		"sdk_protocol", sdk.Protocol, "duration", loadDuration, "warmup", warmupDuration)
+	return nil
+}
+
+// warmup runs the plugin's optional Warmup hook and returns how long it took,
+// or zero for plugins without one. Failures are logged and do not stop the load.
+func warmup(key string, cortexPlugin CortexPlugin) time.Duration {
+	warmer, ok := cortexPlugin.(Warmer)
+	if !ok {
+		return 0
+	}
+
+	started := time.Now()
+	err := warmer.Warmup()
+	elapsed := time.Since(started)
+	if err != nil {
+		if isNotImplemented(err) {
+			return 0
+		}
+		slog.Warn("plugin warm-up failed", "plugin", key, "duration", elapsed, "error", err)
+	}
+	return elapsed
+}
+
+// demoSeededMarker is written to a plugin's data directory once its demo data
+// has been seeded, so reloads and restarts do not seed it again.
+const demoSeededMarker = ".demo-seeded"
+
+// seedDemo runs the plugin's optional SeedDemo hook unless the plugin's data
+// directory was seeded before. Failures are logged and do not stop the load;
+// the next load tries again.
+func seedDemo(key string, cortexPlugin CortexPlugin, dataPath string) {
+	seeder, ok := cortexPlugin.(DemoSeeder)
+	if !ok {
+		return
+	}
+
+	markerPath := filepath.Join(dataPath, demoSeededMarker)
+	if _, err := os.Stat(markerPath); err == nil {
+		return
+	}
+
+	if err := seeder.SeedDemo(); err != nil {
+		if isNotImplemented(err) {
+			return
+		}
+		slog.Warn("seeding plugin demo data failed", "plugin", key, "error", err)
+		return
+	}
+
+	if err := os.WriteFile(markerPath, nil, 0644); err != nil {
+		slog.Warn("recording seeded demo data", "plugin", key, "error", err)
+	}
+	slog.Info("plugin demo data seeded", "plugin", key)
+}
+
+// checkMigrations lints the plugin's SQL migrations before they run. Findings
+// are logged; under MigrationPolicyEnforce they also stop the plugin from
+// loading. Plugins that do not list their migrations are not checked.
+func (l *Loader) checkMigrations(key string, cortexPlugin CortexPlugin) error {
+	lister, ok := cortexPlugin.(MigrationLister)
+	if l.migrationPolicy == MigrationPolicyOff || !ok {
+		return nil
+	}
+
+	files, err := lister.Migrations()
+	if err != nil {
+		if isNotImplemented(err) {
+			slog.Debug("plugin does not list its migrations, skipping lint", "plugin", key)
+			return nil
+		}
+		if l.migrationPolicy == MigrationPolicyEnforce {
+			return fmt.Errorf("listing migrations: %w", err)
+		}
+		slog.Warn("listing plugin migrations failed, skipping lint", "plugin", key, "error", err)
+		return nil
+	}
+
+	findings := LintMigrations(files)
+	for _, finding := range findings {
+		slog.Warn("unsafe plugin migration", "plugin", key, "file", finding.File, "line", finding.Line,
+			"rule", finding.Rule, "message", finding.Message)
+	}
+
+	if len(findings) > 0 && l.migrationPolicy == MigrationPolicyEnforce {
+		descriptions := make([]string, 0, len(findings))
+		for _, finding := range findings {
+			descriptions = append(descriptions, finding.String())
+		}
+		return fmt.Errorf("%w: %s", ErrUnsafeMigrations, strings.Join(descriptions, "; "))
+	}
 
-	log.Printf("Plugin loaded: %s (%s v%s)", manifest.Name, manifest.ID, manifest.Version)
 	return nil
 }
 
-// UnloadPlugin stops and unregisters a plugin by ID.
+// migrateSettings upgrades a plugin's stored settings when they were written by
+// a different plugin version. Failures are logged and the stored settings are
+// left untouched, so a broken migration never resets user settings.
+func (l *Loader) migrateSettings(id string, cortexPlugin CortexPlugin, version string) {
+	if l.settingsStore == nil {
+		return
+	}
+
+	settings, storedVersion, found, err := l.settingsStore.GetPluginSettings(id)
+	if err != nil {
+		slog.Warn("reading plugin settings", "plugin", id, "error", err)
+		return
+	}
+	if !found || storedVersion == version {
+		return
+	}
+
+	migrator, ok := cortexPlugin.(SettingsMigrator)
+	if !ok {
+		return
+	}
+
+	migrated, err := migrator.MigrateSettings(storedVersion, settings)
+	if err != nil {
+		if isNotImplemented(err) {
+			return
+		}
+		slog.Warn("settings migration failed, keeping stored settings",
+			"plugin", id, "from", storedVersion, "to", version, "error", err)
+		return
+	}
+
+	if !json.Valid(migrated) {
+		slog.Warn("plugin returned invalid JSON from MigrateSettings, keeping stored settings", "plugin", id)
+		return
+	}
+
+	if err := l.settingsStore.SavePluginSettings(id, migrated, version); err != nil {
+		slog.Warn("saving migrated plugin settings", "plugin", id, "error", err)
+		return
+	}
+
+	slog.Info("plugin settings migrated", "plugin", id, "from", storedVersion, "to", version)
+}
+
+// recordMigration records the outcome of a live plugin's migrations. Canaries
+// are not recorded: they run the migrations of a build not yet promoted.
+func (l *Loader) recordMigration(key string, id string, status string, message string) {
+	if key != id || l.installTracker == nil {
+		return
+	}
+	if err := l.installTracker.RecordPluginMigration(id, status, message); err != nil {
+		slog.Warn("recording plugin migration", "plugin", id, "error", err)
+	}
+}
+
+// upgrade runs the plugin's optional Upgrade hook when it loads with a version
+// other than the one of its last successful load. First runs and plugins
+// without the hook are left alone.
+func (l *Loader) upgrade(id string, cortexPlugin CortexPlugin, version string) error {
+	if l.installTracker == nil {
+		return nil
+	}
+
+	previous, found, err := l.installTracker.LastPluginVersion(id)
+	if err != nil {
+		slog.Warn("reading last plugin version", "plugin", id, "error", err)
+		return nil
+	}
+	if !found || previous == version {
+		return nil
+	}
+
+	upgrader, ok := cortexPlugin.(Upgrader)
+	if !ok {
+		return nil
+	}
+	if err := upgrader.Upgrade(previous); err != nil {
+		if isNotImplemented(err) {
+			return nil
+		}
+		return fmt.Errorf("upgrading from %s: %w", previous, err)
+	}
+
+	slog.Info("plugin upgraded", "plugin", id, "from", previous, "to", version)
+	return nil
+}
+
+// UnloadPlugin stops and unregisters a plugin by ID. Unloading a live plugin
+// also stops its canary, ending the rollout.
 func (l *Loader) UnloadPlugin(id string) error {
 	entry, ok := l.registry.Get(id)
 	if !ok {
 		return fmt.Errorf("plugin %s not found", id)
 	}
 
+	if !isCanaryKey(id) {
+		if _, running := l.registry.Get(CanaryKey(id)); running {
+			if err := l.UnloadPlugin(CanaryKey(id)); err != nil {
+				return err
+			}
+		}
+		l.registry.clearRollout(id)
+		l.stopJobs(id)
+	}
+
 	if entry.Plugin != nil {
 		if err := entry.Plugin.Teardown(); err != nil {
-			log.Printf("Warning: teardown failed for plugin %s: %v", id, err)
+			slog.Warn("plugin teardown failed", "plugin", id, "error", err)
 		}
 	}
 
 	l.registry.Unregister(id)
-	log.Printf("Plugin unloaded: %s", id)
+	l.registry.resetBreaker(id)
+	slog.Info("plugin unloaded", "plugin", id)
 	return nil
 }
 
-// UnloadAll stops all registered plugins.
+// Acquire returns a running plugin ready to serve a call. A plugin whose
+// subprocess died is relaunched, at most maxRestarts times per restartWindow;
+// beyond that, or while its circuit is open, Acquire fails fast with
+// ErrPluginUnavailable. Every successful Acquire must be followed by Release.
+func (l *Loader) Acquire(id string) (*RegistryEntry, error) {
+	entry, ok := l.registry.Get(id)
+	if !ok {
+		return nil, ErrPluginNotFound
+	}
+
+	breaker := l.registry.Breaker(id)
+	if err := breaker.Allow(); err != nil {
+		return nil, fmt.Errorf("plugin %s: circuit open: %w", id, err)
+	}
+
+	if entry.Plugin == nil {
+		breaker.RecordFailure(false)
+		return nil, fmt.Errorf("plugin %s is not running: %w", id, ErrPluginUnavailable)
+	}
+
+	if entry.Exited() {
+		breaker.markCrashed()
+	}
+	if !breaker.isCrashed() {
+		return entry, nil
+	}
+
+	breaker.restartMu.Lock()
+	defer breaker.restartMu.Unlock()
+
+	// Another request may have relaunched the plugin while we waited.
+	if breaker.isCrashed() {
+		if !breaker.reserveRestart() {
+			breaker.trip()
+			return nil, fmt.Errorf("plugin %s crashed and reached its restart limit: %w", id, ErrPluginUnavailable)
+		}
+
+		slog.Warn("plugin crashed, relaunching", "plugin", id)
+		if err := l.relaunch(id); err != nil {
+			breaker.trip()
+			slog.Error("relaunching plugin failed", "plugin", id, "error", err)
+			return nil, fmt.Errorf("relaunching plugin %s: %w", id, ErrPluginUnavailable)
+		}
+		breaker.restarted()
+	}
+
+	entry, ok = l.registry.Get(id)
+	if !ok || entry.Plugin == nil {
+		breaker.RecordFailure(false)
+		return nil, fmt.Errorf("plugin %s is not running: %w", id, ErrPluginUnavailable)
+	}
+	return entry, nil
+}
+
+// Release records the outcome of a call made on an entry returned by Acquire.
+// It reports whether the call failed because the plugin subprocess died. A
+// call the caller cancelled says nothing about the plugin and is not counted.
+func (l *Loader) Release(id string, entry *RegistryEntry, err error) (crashed bool) {
+	breaker := l.registry.Breaker(id)
+	if err == nil {
+		breaker.RecordSuccess()
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		breaker.RecordCanceled()
+		return false
+	}
+
+	crashed = entry.Exited() || isConnectionLost(err)
+	breaker.RecordFailure(crashed)
+	return crashed
+}
+
+// restartPlugin replaces a dead plugin or canary with a fresh subprocess.
+// Teardown is skipped because the old process is already gone.
+func (l *Loader) restartPlugin(id string) error {
+	l.registry.Unregister(id)
+	if liveID, ok := canaryOf(id); ok {
+		return l.launch(id, liveID, l.canaryPath(liveID))
+	}
+	return l.LoadPlugin(id)
+}
+
+// UnloadAll stops all registered plugins and closes their log files.
 func (l *Loader) UnloadAll() {
-	for _, manifest := range l.registry.List() {
-		if err := l.UnloadPlugin(manifest.ID); err != nil {
-			log.Printf("Error unloading plugin %s: %v", manifest.ID, err)
+	order := l.registry.DependencyOrder()
+	for i := len(order) - 1; i >= 0; i-- {
+		if err := l.UnloadPlugin(order[i]); err != nil {
+			slog.Error("unloading plugin", "plugin", order[i], "error", err)
 		}
 	}
+
+	if err := l.logs.Close(); err != nil {
+		slog.Warn("closing plugin logs", "error", err)
+	}
+}
+
+// TailLogs returns the last n lines of a plugin's captured output, oldest
+// first. Logs outlive the plugin, so a plugin that failed to load can still be
+// inspected. It returns logging.ErrNoLogs when the plugin never logged anything.
+func (l *Loader) TailLogs(id string, n int) ([]string, error) {
+	return l.logs.Tail(id, n)
+}
+
+// DataPath returns the directory a plugin keeps its database and files in.
+func (l *Loader) DataPath(id string) string {
+	return filepath.Join(l.dataDir, "plugins", id)
 }
@@ -0,0 +1,321 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// fakePlugin is a minimal in-process CortexPlugin used to exercise the gRPC bridge.
+type fakePlugin struct{}
+
+func (p *fakePlugin) GetManifest() (*Manifest, error) {
+	return &Manifest{ID: "fake", Name: "Fake", Version: "2.0.0"}, nil
+}
+
+func (p *fakePlugin) HandleAPI(_ context.Context, request *APIRequest) (*APIResponse, error) {
+	return &APIResponse{StatusCode: 200, Body: []byte(`{}`), ContentType: "application/json"}, nil
+}
+
+func (p *fakePlugin) GetWidgetData(slot string) ([]byte, error) { return []byte(`{}`), nil }
+func (p *fakePlugin) Migrate(databasePath string) error         { return nil }
+func (p *fakePlugin) Teardown() error                           { return nil }
+
+// migratingPlugin renames the "currency" setting to "default_currency".
+type migratingPlugin struct {
+	fakePlugin
+	fail bool
+}
+
+func (p *migratingPlugin) MigrateSettings(fromVersion string, settings []byte) ([]byte, error) {
+	if p.fail {
+		return nil, errors.New("unsupported settings shape")
+	}
+	if fromVersion != "1.0.0" {
+		return settings, nil
+	}
+	return []byte(`{"default_currency":"EUR"}`), nil
+}
+
+// memorySettingsStore is an in-memory SettingsStore.
+type memorySettingsStore struct {
+	settings map[string][]byte
+	versions map[string]string
+}
+
+func newMemorySettingsStore() *memorySettingsStore {
+	return &memorySettingsStore{settings: map[string][]byte{}, versions: map[string]string{}}
+}
+
+func (s *memorySettingsStore) GetPluginSettings(pluginID string) ([]byte, string, bool, error) {
+	settings, ok := s.settings[pluginID]
+	return settings, s.versions[pluginID], ok, nil
+}
+
+func (s *memorySettingsStore) SavePluginSettings(pluginID string, settings []byte, version string) error {
+	s.settings[pluginID] = settings
+	s.versions[pluginID] = version
+	return nil
+}
+
+// dispenseOverGRPC serves impl over an in-memory gRPC connection and returns the host-side client.
+func dispenseOverGRPC(t *testing.T, impl CortexPlugin) CortexPlugin {
+	t.Helper()
+
+	client, _ := goplugin.TestPluginGRPCConn(t, false, map[string]goplugin.Plugin{
+		"cortex_plugin": &CortexGRPCPlugin{Impl: impl},
+	})
+	t.Cleanup(func() { client.Close() })
+
+	raw, err := client.Dispense("cortex_plugin")
+	if err != nil {
+		t.Fatalf("failed to dispense plugin: %v", err)
+	}
+	return raw.(CortexPlugin)
+}
+
+func newSettingsLoader(store SettingsStore) *Loader {
+	loader := NewLoader("", "", NewRegistry())
+	loader.SetSettingsStore(store)
+	return loader
+}
+
+func TestMigrateSettings_UpgradesStoredSettings(t *testing.T) {
+	store := newMemorySettingsStore()
+	_ = store.SavePluginSettings("fake", []byte(`{"currency":"EUR"}`), "1.0.0")
+
+	loader := newSettingsLoader(store)
+	loader.migrateSettings("fake", dispenseOverGRPC(t, &migratingPlugin{}), "2.0.0")
+
+	if got := string(store.settings["fake"]); got != `{"default_currency":"EUR"}` {
+		t.Errorf("expected migrated settings, got %s", got)
+	}
+	if store.versions["fake"] != "2.0.0" {
+		t.Errorf("expected version 2.0.0, got %s", store.versions["fake"])
+	}
+}
+
+func TestMigrateSettings_FailureKeepsStoredSettings(t *testing.T) {
+	store := newMemorySettingsStore()
+	_ = store.SavePluginSettings("fake", []byte(`{"currency":"EUR"}`), "1.0.0")
+
+	loader := newSettingsLoader(store)
+	loader.migrateSettings("fake", dispenseOverGRPC(t, &migratingPlugin{fail: true}), "2.0.0")
+
+	if got := string(store.settings["fake"]); got != `{"currency":"EUR"}` {
+		t.Errorf("expected settings to be preserved, got %s", got)
+	}
+	if store.versions["fake"] != "1.0.0" {
+		t.Errorf("expected version to stay 1.0.0, got %s", store.versions["fake"])
+	}
+}
+
+func TestMigrateSettings_PluginWithoutHook(t *testing.T) {
+	store := newMemorySettingsStore()
+	_ = store.SavePluginSettings("fake", []byte(`{"currency":"EUR"}`), "1.0.0")
+
+	client := dispenseOverGRPC(t, &fakePlugin{})
+
+	_, err := client.(SettingsMigrator).MigrateSettings("1.0.0", []byte(`{}`))
+	if !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+
+	newSettingsLoader(store).migrateSettings("fake", client, "2.0.0")
+
+	if got := string(store.settings["fake"]); got != `{"currency":"EUR"}` {
+		t.Errorf("expected settings to be untouched, got %s", got)
+	}
+}
+
+func TestMigrateSettings_SameVersionSkipped(t *testing.T) {
+	store := newMemorySettingsStore()
+	_ = store.SavePluginSettings("fake", []byte(`{"currency":"EUR"}`), "1.0.0")
+
+	newSettingsLoader(store).migrateSettings("fake", dispenseOverGRPC(t, &migratingPlugin{}), "1.0.0")
+
+	if got := string(store.settings["fake"]); got != `{"currency":"EUR"}` {
+		t.Errorf("expected settings to be untouched, got %s", got)
+	}
+}
+
+// warmingPlugin records its warm-up and takes a little time over it.
+type warmingPlugin struct {
+	fakePlugin
+	warmed bool
+	fail   bool
+}
+
+func (p *warmingPlugin) Warmup() error {
+	time.Sleep(5 * time.Millisecond)
+	p.warmed = true
+	if p.fail {
+		return errors.New("inconsistent data")
+	}
+	return nil
+}
+
+func TestWarmup_ReportsDuration(t *testing.T) {
+	impl := &warmingPlugin{}
+
+	elapsed := warmup("fake", dispenseOverGRPC(t, impl))
+	if !impl.warmed {
+		t.Fatal("expected Warmup to reach the plugin")
+	}
+	if elapsed < 5*time.Millisecond {
+		t.Errorf("expected the warm-up duration to be reported, got %v", elapsed)
+	}
+
+	failing := &warmingPlugin{fail: true}
+	if elapsed := warmup("fake", dispenseOverGRPC(t, failing)); !failing.warmed || elapsed == 0 {
+		t.Errorf("expected a failed warm-up to still be timed, got %v", elapsed)
+	}
+}
+
+func TestWarmup_PluginWithoutHook(t *testing.T) {
+	client := dispenseOverGRPC(t, &fakePlugin{})
+
+	if err := client.(Warmer).Warmup(); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+	if elapsed := warmup("fake", client); elapsed != 0 {
+		t.Errorf("expected no warm-up time, got %v", elapsed)
+	}
+}
+
+// upgradingPlugin records the versions it is upgraded from.
+type upgradingPlugin struct {
+	fakePlugin
+	from []string
+	fail bool
+}
+
+func (p *upgradingPlugin) Upgrade(fromVersion string) error {
+	p.from = append(p.from, fromVersion)
+	if p.fail {
+		return errors.New("index rebuild failed")
+	}
+	return nil
+}
+
+// memoryInstallTracker is an in-memory InstallTracker.
+type memoryInstallTracker struct {
+	versions   map[string]string
+	migrations map[string]string
+}
+
+func newMemoryInstallTracker() *memoryInstallTracker {
+	return &memoryInstallTracker{versions: map[string]string{}, migrations: map[string]string{}}
+}
+
+func (t *memoryInstallTracker) LastPluginVersion(pluginID string) (string, bool, error) {
+	version, ok := t.versions[pluginID]
+	return version, ok, nil
+}
+
+func (t *memoryInstallTracker) RecordPluginMigration(pluginID string, status string, message string) error {
+	t.migrations[pluginID] = status
+	return nil
+}
+
+func (t *memoryInstallTracker) RecordPluginStart(pluginID string, version string) error {
+	t.versions[pluginID] = version
+	return nil
+}
+
+func TestUpgrade_RunsOnVersionChange(t *testing.T) {
+	tracker := newMemoryInstallTracker()
+	loader := NewLoader("", "", NewRegistry())
+	loader.SetInstallTracker(tracker)
+	impl := &upgradingPlugin{}
+	client := dispenseOverGRPC(t, impl)
+
+	// The first load is an install, not an upgrade.
+	if err := loader.upgrade("fake", client, "1.0.0"); err != nil || len(impl.from) != 0 {
+		t.Fatalf("expected no upgrade on the first load, got %v %v", impl.from, err)
+	}
+	_ = tracker.RecordPluginStart("fake", "1.0.0")
+	if err := loader.upgrade("fake", client, "1.0.0"); err != nil || len(impl.from) != 0 {
+		t.Fatalf("expected no upgrade on a reload, got %v %v", impl.from, err)
+	}
+
+	if err := loader.upgrade("fake", client, "2.0.0"); err != nil {
+		t.Fatalf("upgrade returned error: %v", err)
+	}
+	if len(impl.from) != 1 || impl.from[0] != "1.0.0" {
+		t.Errorf("expected an upgrade from 1.0.0, got %v", impl.from)
+	}
+
+	failing := &upgradingPlugin{fail: true}
+	if err := loader.upgrade("fake", dispenseOverGRPC(t, failing), "2.0.0"); err == nil {
+		t.Error("expected a failed upgrade to stop the load")
+	}
+}
+
+func TestUpgrade_PluginWithoutHook(t *testing.T) {
+	tracker := newMemoryInstallTracker()
+	_ = tracker.RecordPluginStart("fake", "1.0.0")
+	loader := NewLoader("", "", NewRegistry())
+	loader.SetInstallTracker(tracker)
+
+	client := dispenseOverGRPC(t, &fakePlugin{})
+	if err := client.(Upgrader).Upgrade("1.0.0"); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+	if err := loader.upgrade("fake", client, "2.0.0"); err != nil {
+		t.Errorf("expected plugins without the hook to load, got %v", err)
+	}
+}
+
+// seedingPlugin counts how often its demo data is seeded.
+type seedingPlugin struct {
+	fakePlugin
+	seeded int
+	fail   bool
+}
+
+func (p *seedingPlugin) SeedDemo() error {
+	p.seeded++
+	if p.fail {
+		return errors.New("database is locked")
+	}
+	return nil
+}
+
+func TestSeedDemo_RunsOncePerDataDirectory(t *testing.T) {
+	dataPath := t.TempDir()
+	impl := &seedingPlugin{}
+	client := dispenseOverGRPC(t, impl)
+
+	seedDemo("fake", client, dataPath)
+	seedDemo("fake", client, dataPath)
+	if impl.seeded != 1 {
+		t.Errorf("expected demo data to be seeded once, got %d", impl.seeded)
+	}
+
+	failing := &seedingPlugin{fail: true}
+	failingPath := t.TempDir()
+	seedDemo("fake", dispenseOverGRPC(t, failing), failingPath)
+	seedDemo("fake", dispenseOverGRPC(t, failing), failingPath)
+	if failing.seeded != 2 {
+		t.Errorf("expected a failed seed to be retried on the next load, got %d attempts", failing.seeded)
+	}
+}
+
+func TestSeedDemo_PluginWithoutHook(t *testing.T) {
+	client := dispenseOverGRPC(t, &fakePlugin{})
+	dataPath := t.TempDir()
+
+	if err := client.(DemoSeeder).SeedDemo(); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+	seedDemo("fake", client, dataPath)
+	if _, err := os.Stat(filepath.Join(dataPath, demoSeededMarker)); !os.IsNotExist(err) {
+		t.Errorf("expected no seed marker for a plugin without the hook, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+const (
+	// maxNotificationTitleLength matches the limit of POST /api/notifications.
+	maxNotificationTitleLength = 200
+	maxNotificationBodyLength  = 4000
+)
+
+// Notifier receives the notifications plugins send. The host's notification
+// center implements it: notifications are stored, urgent ones are pushed to
+// the configured channels and the rest wait for the digest.
+type Notifier interface {
+	SendNotification(source string, title string, body string, urgent bool) error
+}
+
+// --- Host side ---
+
+func (s *hostServer) SendNotification(ctx context.Context, request *pb.NotificationRequest) (*pb.Empty, error) {
+	if s.notifier == nil {
+		return nil, status.Errorf(codes.PermissionDenied, "plugin has not declared %q", PermissionNotifications)
+	}
+
+	title := strings.TrimSpace(request.Title)
+	if title == "" || len(title) > maxNotificationTitleLength {
+		return nil, status.Errorf(codes.InvalidArgument, "title must be between 1 and %d characters", maxNotificationTitleLength)
+	}
+	if len(request.Body) > maxNotificationBodyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "body must be at most %d characters", maxNotificationBodyLength)
+	}
+
+	if err := s.notifier.SendNotification(s.pluginID, title, request.Body, request.Urgent); err != nil {
+		slog.Error("storing plugin notification", "plugin", s.pluginID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to store notification")
+	}
+	return &pb.Empty{}, nil
+}
+
+// --- Plugin side ---
+
+// SendNotification adds a notification to the host's notification center,
+// with the plugin as its source. Urgent notifications are pushed to the
+// user's channels right away; the rest are batched into the digest. It is
+// called from within a plugin that declared the notifications permission.
+func SendNotification(title string, body string, urgent bool) error {
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.SendNotification(context.Background(), &pb.NotificationRequest{Title: title, Body: body, Urgent: urgent})
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.PermissionDenied:
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, status.Convert(err).Message())
+	default:
+		return translateError(err)
+	}
+}
@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Permissions a plugin can declare in its manifest.
+const (
+	// PermissionDBRead gives the plugin its own SQLite database.
+	PermissionDBRead = "db:read"
+	// PermissionDBWrite allows state-changing requests (POST, PUT, PATCH, DELETE) to reach the plugin.
+	// Without it, the plugin's database turns query-only once its migrations
+	// have run, so writes from GET handlers or background tasks fail too.
+	PermissionDBWrite = "db:write"
+	// PermissionNetwork allows outbound network access from the plugin process.
+	// Without it, Linux plugins are denied TCP by Landlock where the kernel
+	// supports it, and HTTP is routed to a closed port everywhere.
+	PermissionNetwork = "network"
+	// PermissionAttachments allows storing files in the host's attachment store.
+	PermissionAttachments = "attachments"
+	// PermissionNotifications allows sending notifications to the host's notification center.
+	PermissionNotifications = "notifications"
+	// PermissionPluginsRead allows GET requests to other plugins' APIs through CallPlugin.
+	PermissionPluginsRead = "plugins:read"
+)
+
+// knownPermissions lists every permission the host understands. Manifests
+// declaring anything else are rejected at load so typos fail loudly.
+var knownPermissions = map[string]bool{
+	PermissionDBRead:        true,
+	PermissionDBWrite:       true,
+	PermissionNetwork:       true,
+	PermissionAttachments:   true,
+	PermissionNotifications: true,
+	PermissionPluginsRead:   true,
+}
+
+// ErrPermissionDenied is returned when a plugin calls a host API or receives
+// a request it has not declared the permission for.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// permissionsEnv lists a plugin's declared permissions in its environment.
+const permissionsEnv = "CORTEX_PLUGIN_PERMISSIONS"
+
+// blockedProxyURL routes outbound HTTP of plugins without the network
+// permission to a closed local port, so requests fail fast.
+const blockedProxyURL = "http://127.0.0.1:1"
+
+// HasPermission reports whether the manifest declares the given permission.
+func (m *Manifest) HasPermission(permission string) bool {
+	for _, declared := range m.Permissions {
+		if declared == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission returns an ErrPermissionDenied error if the manifest does
+// not declare the permission. Host APIs exposed to plugins call this before acting.
+func RequirePermission(manifest *Manifest, permission string) error {
+	if manifest == nil || !manifest.HasPermission(permission) {
+		return fmt.Errorf("%w: plugin has not declared %q", ErrPermissionDenied, permission)
+	}
+	return nil
+}
+
+// RequiredPermissionForMethod returns the permission needed to proxy an HTTP
+// method to a plugin, or "" if none is required. Writes made while serving
+// other methods are stopped by the plugin's read-only database instead.
+func RequiredPermissionForMethod(method string) string {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return PermissionDBWrite
+	default:
+		return ""
+	}
+}
+
+// validatePermissions rejects manifests declaring permissions the host does not know.
+func validatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !knownPermissions[permission] {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+	return nil
+}
+
+// needsDatabase reports whether the plugin declared any database permission.
+func needsDatabase(manifest *Manifest) bool {
+	return manifest.HasPermission(PermissionDBRead) || manifest.HasPermission(PermissionDBWrite)
+}
+
+// pluginEnvironment builds the environment for a plugin subprocess. Plugins do
+// not inherit the host environment, so host secrets (SMTP passwords and the like)
+// never reach them. Without the network permission, outbound HTTP is routed to
+// a closed port, which covers clients honoring proxy variables where the
+// sandbox cannot deny network access.
+func pluginEnvironment(manifest *Manifest, dataPath string) []string {
+	environment := []string{
+		"HOME=" + dataPath,
+		"CORTEX_PLUGIN_DATA_DIR=" + dataPath,
+		permissionsEnv + "=" + strings.Join(manifest.Permissions, ","),
+	}
+
+	// Pass through only what a process needs to run and to tell the time correctly.
+	for _, key := range []string{"PATH", "TMPDIR", "TZ"} {
+		if value, ok := os.LookupEnv(key); ok {
+			environment = append(environment, key+"="+value)
+		}
+	}
+
+	if !manifest.HasPermission(PermissionNetwork) {
+		environment = append(environment,
+			"HTTP_PROXY="+blockedProxyURL,
+			"HTTPS_PROXY="+blockedProxyURL,
+			"http_proxy="+blockedProxyURL,
+			"https_proxy="+blockedProxyURL,
+		)
+	}
+
+	return environment
+}
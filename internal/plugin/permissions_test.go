@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"modernc.org/sqlite"
+)
+
+func TestValidatePermissions(t *testing.T) {
+	if err := validatePermissions([]string{PermissionDBRead, PermissionDBWrite, PermissionNetwork}); err != nil {
+		t.Errorf("expected known permissions to be valid, got %v", err)
+	}
+	if err := validatePermissions([]string{"db:wirte"}); err == nil {
+		t.Error("expected unknown permission to be rejected")
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	manifest := &Manifest{ID: "notes", Permissions: []string{PermissionDBRead}}
+
+	if err := RequirePermission(manifest, PermissionDBRead); err != nil {
+		t.Errorf("expected declared permission to pass, got %v", err)
+	}
+	if err := RequirePermission(manifest, PermissionNetwork); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+}
+
+func TestPluginEnvironment_NetworkBlockedByDefault(t *testing.T) {
+	t.Setenv("CORTEX_SMTP_PASSWORD", "hunter2")
+
+	offline := strings.Join(pluginEnvironment(&Manifest{Permissions: []string{PermissionDBRead}}, "/data/plugins/notes"), "\n")
+	if !strings.Contains(offline, "HTTPS_PROXY="+blockedProxyURL) {
+		t.Error("expected outbound HTTPS to be blocked without the network permission")
+	}
+	if strings.Contains(offline, "hunter2") {
+		t.Error("host secrets must not leak into the plugin environment")
+	}
+	if !strings.Contains(offline, "HOME=/data/plugins/notes") {
+		t.Error("expected HOME to point at the plugin data directory")
+	}
+
+	online := strings.Join(pluginEnvironment(&Manifest{Permissions: []string{PermissionNetwork}}, "/data/plugins/feeds"), "\n")
+	if strings.Contains(online, "HTTPS_PROXY=") {
+		t.Error("expected no proxy override with the network permission")
+	}
+}
+
+// testConnector opens the SQLite database at the path it holds the way the
+// SDK does, taking the write lock at BEGIN.
+type testConnector string
+
+func (c testConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.Driver().Open(string(c) + "?_pragma=journal_mode(WAL)&_txlock=immediate")
+}
+
+func (c testConnector) Driver() driver.Driver { return &sqlite.Driver{} }
+
+// openGuarded opens a database through GuardDatabase for a plugin granted
+// permissions, and unlocks databases again when the test ends.
+func openGuarded(t *testing.T, permissions string) *sql.DB {
+	t.Helper()
+	t.Setenv(permissionsEnv, permissions)
+	t.Cleanup(func() { databasesLocked.Store(false) })
+
+	database := sql.OpenDB(GuardDatabase(testConnector(filepath.Join(t.TempDir(), "db.sqlite"))))
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func TestGuardDatabase_ReadOnlyWithoutDBWrite(t *testing.T) {
+	database := openGuarded(t, PermissionDBRead)
+
+	// Migrations run before the lock.
+	if _, err := database.Exec("CREATE TABLE notes (title TEXT); INSERT INTO notes VALUES ('first')"); err != nil {
+		t.Fatalf("expected migrations to write, got %v", err)
+	}
+	lockDatabases()
+
+	if _, err := database.Exec("INSERT INTO notes VALUES ('second')"); err == nil || !strings.Contains(err.Error(), "readonly") {
+		t.Errorf("expected the write to be denied, got %v", err)
+	}
+
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected reads to work, got %d, %v", count, err)
+	}
+	if tx, err := database.Begin(); err != nil {
+		t.Errorf("expected a read transaction to start, got %v", err)
+	} else {
+		if err := tx.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count); err != nil {
+			t.Errorf("expected reads inside a transaction to work, got %v", err)
+		}
+		if _, err := tx.Exec("INSERT INTO notes VALUES ('third')"); err == nil {
+			t.Error("expected writes inside a transaction to be denied")
+		}
+		_ = tx.Rollback()
+	}
+}
+
+func TestGuardDatabase_WritableWithDBWrite(t *testing.T) {
+	database := openGuarded(t, PermissionDBRead+","+PermissionDBWrite)
+
+	if _, err := database.Exec("CREATE TABLE notes (title TEXT)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	lockDatabases()
+
+	if _, err := database.Exec("INSERT INTO notes VALUES ('second')"); err != nil {
+		t.Errorf("expected writes to work with db:write, got %v", err)
+	}
+}
@@ -155,6 +155,9 @@ type APIRequest struct {
 	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
 	Body          []byte                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
 	Query         map[string]string      `protobuf:"bytes,4,rep,name=query,proto3" json:"query,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Headers       map[string]string      `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ContentType   string                 `protobuf:"bytes,6,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	RequestId     string                 `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -217,11 +220,34 @@ func (x *APIRequest) GetQuery() map[string]string {
 	return nil
 }
 
+func (x *APIRequest) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *APIRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *APIRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
 type APIResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	StatusCode    int32                  `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
 	Body          []byte                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
 	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Headers       map[string]string      `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Deleted       *DeletedEntity         `protobuf:"bytes,5,opt,name=deleted,proto3" json:"deleted,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -277,6 +303,72 @@ func (x *APIResponse) GetContentType() string {
 	return ""
 }
 
+func (x *APIResponse) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *APIResponse) GetDeleted() *DeletedEntity {
+	if x != nil {
+		return x.Deleted
+	}
+	return nil
+}
+
+type DeletedEntity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletedEntity) Reset() {
+	*x = DeletedEntity{}
+	mi := &file_plugin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletedEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletedEntity) ProtoMessage() {}
+
+func (x *DeletedEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletedEntity.ProtoReflect.Descriptor instead.
+func (*DeletedEntity) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeletedEntity) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *DeletedEntity) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type WidgetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Slot          string                 `protobuf:"bytes,1,opt,name=slot,proto3" json:"slot,omitempty"`
@@ -286,7 +378,7 @@ type WidgetRequest struct {
 
 func (x *WidgetRequest) Reset() {
 	*x = WidgetRequest{}
-	mi := &file_plugin_proto_msgTypes[4]
+	mi := &file_plugin_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -298,7 +390,7 @@ func (x *WidgetRequest) String() string {
 func (*WidgetRequest) ProtoMessage() {}
 
 func (x *WidgetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_plugin_proto_msgTypes[4]
+	mi := &file_plugin_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -311,7 +403,7 @@ func (x *WidgetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WidgetRequest.ProtoReflect.Descriptor instead.
 func (*WidgetRequest) Descriptor() ([]byte, []int) {
-	return file_plugin_proto_rawDescGZIP(), []int{4}
+	return file_plugin_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *WidgetRequest) GetSlot() string {
@@ -330,7 +422,7 @@ type WidgetData struct {
 
 func (x *WidgetData) Reset() {
 	*x = WidgetData{}
-	mi := &file_plugin_proto_msgTypes[5]
+	mi := &file_plugin_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -342,7 +434,7 @@ func (x *WidgetData) String() string {
 func (*WidgetData) ProtoMessage() {}
 
 func (x *WidgetData) ProtoReflect() protoreflect.Message {
-	mi := &file_plugin_proto_msgTypes[5]
+	mi := &file_plugin_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -355,7 +447,7 @@ func (x *WidgetData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WidgetData.ProtoReflect.Descriptor instead.
 func (*WidgetData) Descriptor() ([]byte, []int) {
-	return file_plugin_proto_rawDescGZIP(), []int{5}
+	return file_plugin_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *WidgetData) GetJsonData() []byte {
@@ -374,7 +466,7 @@ type MigrateRequest struct {
 
 func (x *MigrateRequest) Reset() {
 	*x = MigrateRequest{}
-	mi := &file_plugin_proto_msgTypes[6]
+	mi := &file_plugin_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -386,7 +478,7 @@ func (x *MigrateRequest) String() string {
 func (*MigrateRequest) ProtoMessage() {}
 
 func (x *MigrateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_plugin_proto_msgTypes[6]
+	mi := &file_plugin_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -399,7 +491,7 @@ func (x *MigrateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MigrateRequest.ProtoReflect.Descriptor instead.
 func (*MigrateRequest) Descriptor() ([]byte, []int) {
-	return file_plugin_proto_rawDescGZIP(), []int{6}
+	return file_plugin_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *MigrateRequest) GetDbPath() string {
@@ -419,7 +511,7 @@ type MigrateResult struct {
 
 func (x *MigrateResult) Reset() {
 	*x = MigrateResult{}
-	mi := &file_plugin_proto_msgTypes[7]
+	mi := &file_plugin_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -431,7 +523,7 @@ func (x *MigrateResult) String() string {
 func (*MigrateResult) ProtoMessage() {}
 
 func (x *MigrateResult) ProtoReflect() protoreflect.Message {
-	mi := &file_plugin_proto_msgTypes[7]
+	mi := &file_plugin_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -444,7 +536,7 @@ func (x *MigrateResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MigrateResult.ProtoReflect.Descriptor instead.
 func (*MigrateResult) Descriptor() ([]byte, []int) {
-	return file_plugin_proto_rawDescGZIP(), []int{7}
+	return file_plugin_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *MigrateResult) GetSuccess() bool {
@@ -461,51 +553,2073 @@ func (x *MigrateResult) GetMessage() string {
 	return ""
 }
 
-var File_plugin_proto protoreflect.FileDescriptor
+type SettingsMigrationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromVersion   string                 `protobuf:"bytes,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	SettingsJson  []byte                 `protobuf:"bytes,2,opt,name=settings_json,json=settingsJson,proto3" json:"settings_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_plugin_proto_rawDesc = "" +
-	"\n" +
-	"\fplugin.proto\x12\fcortexplugin\"\a\n" +
-	"\x05Empty\"\xbc\x01\n" +
-	"\x0ePluginManifest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\tR\aversion\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x12\n" +
-	"\x04icon\x18\x05 \x01(\tR\x04icon\x12\x14\n" +
-	"\x05color\x18\x06 \x01(\tR\x05color\x12 \n" +
-	"\vpermissions\x18\a \x03(\tR\vpermissions\"\xc1\x01\n" +
-	"\n" +
-	"APIRequest\x12\x16\n" +
-	"\x06method\x18\x01 \x01(\tR\x06method\x12\x12\n" +
-	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
-	"\x04body\x18\x03 \x01(\fR\x04body\x129\n" +
-	"\x05query\x18\x04 \x03(\v2#.cortexplugin.APIRequest.QueryEntryR\x05query\x1a8\n" +
-	"\n" +
-	"QueryEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"e\n" +
-	"\vAPIResponse\x12\x1f\n" +
-	"\vstatus_code\x18\x01 \x01(\x05R\n" +
-	"statusCode\x12\x12\n" +
-	"\x04body\x18\x02 \x01(\fR\x04body\x12!\n" +
-	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\"#\n" +
-	"\rWidgetRequest\x12\x12\n" +
-	"\x04slot\x18\x01 \x01(\tR\x04slot\")\n" +
-	"\n" +
-	"WidgetData\x12\x1b\n" +
-	"\tjson_data\x18\x01 \x01(\fR\bjsonData\")\n" +
-	"\x0eMigrateRequest\x12\x17\n" +
-	"\adb_path\x18\x01 \x01(\tR\x06dbPath\"C\n" +
-	"\rMigrateResult\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2\xd6\x02\n" +
-	"\fCortexPlugin\x12@\n" +
-	"\vGetManifest\x12\x13.cortexplugin.Empty\x1a\x1c.cortexplugin.PluginManifest\x12@\n" +
-	"\tHandleAPI\x12\x18.cortexplugin.APIRequest\x1a\x19.cortexplugin.APIResponse\x12F\n" +
-	"\rGetWidgetData\x12\x1b.cortexplugin.WidgetRequest\x1a\x18.cortexplugin.WidgetData\x12D\n" +
-	"\aMigrate\x12\x1c.cortexplugin.MigrateRequest\x1a\x1b.cortexplugin.MigrateResult\x124\n" +
-	"\bTeardown\x12\x13.cortexplugin.Empty\x1a\x13.cortexplugin.EmptyB7Z5github.com/alvarotorresc/cortex/internal/plugin/protob\x06proto3"
+func (x *SettingsMigrationRequest) Reset() {
+	*x = SettingsMigrationRequest{}
+	mi := &file_plugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettingsMigrationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettingsMigrationRequest) ProtoMessage() {}
+
+func (x *SettingsMigrationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettingsMigrationRequest.ProtoReflect.Descriptor instead.
+func (*SettingsMigrationRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SettingsMigrationRequest) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *SettingsMigrationRequest) GetSettingsJson() []byte {
+	if x != nil {
+		return x.SettingsJson
+	}
+	return nil
+}
+
+type SettingsMigrationResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SettingsJson  []byte                 `protobuf:"bytes,1,opt,name=settings_json,json=settingsJson,proto3" json:"settings_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SettingsMigrationResult) Reset() {
+	*x = SettingsMigrationResult{}
+	mi := &file_plugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettingsMigrationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettingsMigrationResult) ProtoMessage() {}
+
+func (x *SettingsMigrationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettingsMigrationResult.ProtoReflect.Descriptor instead.
+func (*SettingsMigrationResult) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SettingsMigrationResult) GetSettingsJson() []byte {
+	if x != nil {
+		return x.SettingsJson
+	}
+	return nil
+}
+
+type UpgradeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromVersion   string                 `protobuf:"bytes,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpgradeRequest) Reset() {
+	*x = UpgradeRequest{}
+	mi := &file_plugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpgradeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpgradeRequest) ProtoMessage() {}
+
+func (x *UpgradeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpgradeRequest.ProtoReflect.Descriptor instead.
+func (*UpgradeRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpgradeRequest) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+type PluginData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginData) Reset() {
+	*x = PluginData{}
+	mi := &file_plugin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginData) ProtoMessage() {}
+
+func (x *PluginData) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginData.ProtoReflect.Descriptor instead.
+func (*PluginData) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PluginData) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ImportDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromVersion   string                 `protobuf:"bytes,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportDataRequest) Reset() {
+	*x = ImportDataRequest{}
+	mi := &file_plugin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportDataRequest) ProtoMessage() {}
+
+func (x *ImportDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportDataRequest.ProtoReflect.Descriptor instead.
+func (*ImportDataRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ImportDataRequest) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *ImportDataRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Settings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SettingsJson  []byte                 `protobuf:"bytes,1,opt,name=settings_json,json=settingsJson,proto3" json:"settings_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Settings) Reset() {
+	*x = Settings{}
+	mi := &file_plugin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Settings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Settings) ProtoMessage() {}
+
+func (x *Settings) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Settings.ProtoReflect.Descriptor instead.
+func (*Settings) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Settings) GetSettingsJson() []byte {
+	if x != nil {
+		return x.SettingsJson
+	}
+	return nil
+}
+
+type ConnectHostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BrokerId      uint32                 `protobuf:"varint,1,opt,name=broker_id,json=brokerId,proto3" json:"broker_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectHostRequest) Reset() {
+	*x = ConnectHostRequest{}
+	mi := &file_plugin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectHostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectHostRequest) ProtoMessage() {}
+
+func (x *ConnectHostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectHostRequest.ProtoReflect.Descriptor instead.
+func (*ConnectHostRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConnectHostRequest) GetBrokerId() uint32 {
+	if x != nil {
+		return x.BrokerId
+	}
+	return 0
+}
+
+type ChangeNotification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeNotification) Reset() {
+	*x = ChangeNotification{}
+	mi := &file_plugin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeNotification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeNotification) ProtoMessage() {}
+
+func (x *ChangeNotification) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeNotification.ProtoReflect.Descriptor instead.
+func (*ChangeNotification) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ChangeNotification) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+type NotificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Body          string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	Urgent        bool                   `protobuf:"varint,3,opt,name=urgent,proto3" json:"urgent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationRequest) Reset() {
+	*x = NotificationRequest{}
+	mi := &file_plugin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationRequest) ProtoMessage() {}
+
+func (x *NotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationRequest.ProtoReflect.Descriptor instead.
+func (*NotificationRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NotificationRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *NotificationRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *NotificationRequest) GetUrgent() bool {
+	if x != nil {
+		return x.Urgent
+	}
+	return false
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_plugin_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Snippet       string                 `protobuf:"bytes,4,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	Link          string                 `protobuf:"bytes,5,opt,name=link,proto3" json:"link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_plugin_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SearchResult) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SearchResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SearchResult) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *SearchResult) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_plugin_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type MigrationFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Sql           string                 `protobuf:"bytes,2,opt,name=sql,proto3" json:"sql,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MigrationFile) Reset() {
+	*x = MigrationFile{}
+	mi := &file_plugin_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MigrationFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrationFile) ProtoMessage() {}
+
+func (x *MigrationFile) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrationFile.ProtoReflect.Descriptor instead.
+func (*MigrationFile) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *MigrationFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MigrationFile) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+type CapabilityList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Capabilities  []string               `protobuf:"bytes,1,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CapabilityList) Reset() {
+	*x = CapabilityList{}
+	mi := &file_plugin_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapabilityList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapabilityList) ProtoMessage() {}
+
+func (x *CapabilityList) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapabilityList.ProtoReflect.Descriptor instead.
+func (*CapabilityList) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CapabilityList) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type MigrationList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*MigrationFile       `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MigrationList) Reset() {
+	*x = MigrationList{}
+	mi := &file_plugin_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MigrationList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MigrationList) ProtoMessage() {}
+
+func (x *MigrationList) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MigrationList.ProtoReflect.Descriptor instead.
+func (*MigrationList) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MigrationList) GetFiles() []*MigrationFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type SyncRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int64                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Deleted       bool                   `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Data          []byte                 `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	ChangedAt     string                 `protobuf:"bytes,6,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRecord) Reset() {
+	*x = SyncRecord{}
+	mi := &file_plugin_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRecord) ProtoMessage() {}
+
+func (x *SyncRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRecord.ProtoReflect.Descriptor instead.
+func (*SyncRecord) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SyncRecord) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *SyncRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SyncRecord) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *SyncRecord) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *SyncRecord) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SyncRecord) GetChangedAt() string {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return ""
+}
+
+type SyncPullRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncPullRequest) Reset() {
+	*x = SyncPullRequest{}
+	mi := &file_plugin_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPullRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPullRequest) ProtoMessage() {}
+
+func (x *SyncPullRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPullRequest.ProtoReflect.Descriptor instead.
+func (*SyncPullRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SyncPullRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *SyncPullRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SyncPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*SyncRecord          `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncPage) Reset() {
+	*x = SyncPage{}
+	mi := &file_plugin_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPage) ProtoMessage() {}
+
+func (x *SyncPage) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPage.ProtoReflect.Descriptor instead.
+func (*SyncPage) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SyncPage) GetRecords() []*SyncRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *SyncPage) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *SyncPage) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type SyncChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	ClientId      string                 `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	BaseVersion   int64                  `protobuf:"varint,4,opt,name=base_version,json=baseVersion,proto3" json:"base_version,omitempty"`
+	Deleted       bool                   `protobuf:"varint,5,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Data          []byte                 `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncChange) Reset() {
+	*x = SyncChange{}
+	mi := &file_plugin_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncChange) ProtoMessage() {}
+
+func (x *SyncChange) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncChange.ProtoReflect.Descriptor instead.
+func (*SyncChange) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SyncChange) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *SyncChange) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SyncChange) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *SyncChange) GetBaseVersion() int64 {
+	if x != nil {
+		return x.BaseVersion
+	}
+	return 0
+}
+
+func (x *SyncChange) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *SyncChange) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SyncPushRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*SyncChange          `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncPushRequest) Reset() {
+	*x = SyncPushRequest{}
+	mi := &file_plugin_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPushRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPushRequest) ProtoMessage() {}
+
+func (x *SyncPushRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPushRequest.ProtoReflect.Descriptor instead.
+func (*SyncPushRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SyncPushRequest) GetChanges() []*SyncChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type SyncResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	ClientId      string                 `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	Current       *SyncRecord            `protobuf:"bytes,7,opt,name=current,proto3" json:"current,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncResult) Reset() {
+	*x = SyncResult{}
+	mi := &file_plugin_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncResult) ProtoMessage() {}
+
+func (x *SyncResult) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncResult.ProtoReflect.Descriptor instead.
+func (*SyncResult) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SyncResult) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *SyncResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SyncResult) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *SyncResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SyncResult) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *SyncResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SyncResult) GetCurrent() *SyncRecord {
+	if x != nil {
+		return x.Current
+	}
+	return nil
+}
+
+type SyncPushResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SyncResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncPushResponse) Reset() {
+	*x = SyncPushResponse{}
+	mi := &file_plugin_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPushResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPushResponse) ProtoMessage() {}
+
+func (x *SyncPushResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPushResponse.ProtoReflect.Descriptor instead.
+func (*SyncPushResponse) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SyncPushResponse) GetResults() []*SyncResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type Attachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Sha256        string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Deduplicated  bool                   `protobuf:"varint,7,opt,name=deduplicated,proto3" json:"deduplicated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_plugin_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Attachment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Attachment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Attachment) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Attachment) GetDeduplicated() bool {
+	if x != nil {
+		return x.Deduplicated
+	}
+	return false
+}
+
+type PutAttachmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutAttachmentRequest) Reset() {
+	*x = PutAttachmentRequest{}
+	mi := &file_plugin_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutAttachmentRequest) ProtoMessage() {}
+
+func (x *PutAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*PutAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *PutAttachmentRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PutAttachmentRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *PutAttachmentRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type AttachmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentRequest) Reset() {
+	*x = AttachmentRequest{}
+	mi := &file_plugin_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentRequest) ProtoMessage() {}
+
+func (x *AttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentRequest.ProtoReflect.Descriptor instead.
+func (*AttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *AttachmentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type AttachmentContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attachment    *Attachment            `protobuf:"bytes,1,opt,name=attachment,proto3" json:"attachment,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentContent) Reset() {
+	*x = AttachmentContent{}
+	mi := &file_plugin_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentContent) ProtoMessage() {}
+
+func (x *AttachmentContent) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentContent.ProtoReflect.Descriptor instead.
+func (*AttachmentContent) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *AttachmentContent) GetAttachment() *Attachment {
+	if x != nil {
+		return x.Attachment
+	}
+	return nil
+}
+
+func (x *AttachmentContent) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ValueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValueRequest) Reset() {
+	*x = ValueRequest{}
+	mi := &file_plugin_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueRequest) ProtoMessage() {}
+
+func (x *ValueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueRequest.ProtoReflect.Descriptor instead.
+func (*ValueRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ValueRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ValueRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type ValueResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValueResponse) Reset() {
+	*x = ValueResponse{}
+	mi := &file_plugin_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueResponse) ProtoMessage() {}
+
+func (x *ValueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueResponse.ProtoReflect.Descriptor instead.
+func (*ValueResponse) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ValueResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ValueResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+type KeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeysRequest) Reset() {
+	*x = KeysRequest{}
+	mi := &file_plugin_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeysRequest) ProtoMessage() {}
+
+func (x *KeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeysRequest.ProtoReflect.Descriptor instead.
+func (*KeysRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *KeysRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type KeyList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyList) Reset() {
+	*x = KeyList{}
+	mi := &file_plugin_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyList) ProtoMessage() {}
+
+func (x *KeyList) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyList.ProtoReflect.Descriptor instead.
+func (*KeyList) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *KeyList) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type ScheduleRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	IntervalSeconds int64                  `protobuf:"varint,2,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ScheduleRequest) Reset() {
+	*x = ScheduleRequest{}
+	mi := &file_plugin_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleRequest) ProtoMessage() {}
+
+func (x *ScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ScheduleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScheduleRequest) GetIntervalSeconds() int64 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+type JobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobRequest) Reset() {
+	*x = JobRequest{}
+	mi := &file_plugin_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobRequest) ProtoMessage() {}
+
+func (x *JobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobRequest.ProtoReflect.Descriptor instead.
+func (*JobRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *JobRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type LogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Attributes    map[string]string      `protobuf:"bytes,3,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	mi := &file_plugin_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRequest) ProtoMessage() {}
+
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *LogRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogRequest) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type PluginCallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
+	Request       *APIRequest            `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginCallRequest) Reset() {
+	*x = PluginCallRequest{}
+	mi := &file_plugin_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginCallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginCallRequest) ProtoMessage() {}
+
+func (x *PluginCallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginCallRequest.ProtoReflect.Descriptor instead.
+func (*PluginCallRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *PluginCallRequest) GetPluginId() string {
+	if x != nil {
+		return x.PluginId
+	}
+	return ""
+}
+
+func (x *PluginCallRequest) GetRequest() *APIRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+var File_plugin_proto protoreflect.FileDescriptor
+
+const file_plugin_proto_rawDesc = "" +
+	"\n" +
+	"\fplugin.proto\x12\fcortexplugin\"\a\n" +
+	"\x05Empty\"\xbc\x01\n" +
+	"\x0ePluginManifest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04icon\x18\x05 \x01(\tR\x04icon\x12\x14\n" +
+	"\x05color\x18\x06 \x01(\tR\x05color\x12 \n" +
+	"\vpermissions\x18\a \x03(\tR\vpermissions\"\x80\x03\n" +
+	"\n" +
+	"APIRequest\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\fR\x04body\x129\n" +
+	"\x05query\x18\x04 \x03(\v2#.cortexplugin.APIRequest.QueryEntryR\x05query\x12?\n" +
+	"\aheaders\x18\x05 \x03(\v2%.cortexplugin.APIRequest.HeadersEntryR\aheaders\x12!\n" +
+	"\fcontent_type\x18\x06 \x01(\tR\vcontentType\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\a \x01(\tR\trequestId\x1a8\n" +
+	"\n" +
+	"QueryEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9a\x02\n" +
+	"\vAPIResponse\x12\x1f\n" +
+	"\vstatus_code\x18\x01 \x01(\x05R\n" +
+	"statusCode\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\fR\x04body\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12@\n" +
+	"\aheaders\x18\x04 \x03(\v2&.cortexplugin.APIResponse.HeadersEntryR\aheaders\x125\n" +
+	"\adeleted\x18\x05 \x01(\v2\x1b.cortexplugin.DeletedEntityR\adeleted\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"7\n" +
+	"\rDeletedEntity\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"#\n" +
+	"\rWidgetRequest\x12\x12\n" +
+	"\x04slot\x18\x01 \x01(\tR\x04slot\")\n" +
+	"\n" +
+	"WidgetData\x12\x1b\n" +
+	"\tjson_data\x18\x01 \x01(\fR\bjsonData\")\n" +
+	"\x0eMigrateRequest\x12\x17\n" +
+	"\adb_path\x18\x01 \x01(\tR\x06dbPath\"C\n" +
+	"\rMigrateResult\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"b\n" +
+	"\x18SettingsMigrationRequest\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\tR\vfromVersion\x12#\n" +
+	"\rsettings_json\x18\x02 \x01(\fR\fsettingsJson\">\n" +
+	"\x17SettingsMigrationResult\x12#\n" +
+	"\rsettings_json\x18\x01 \x01(\fR\fsettingsJson\"3\n" +
+	"\x0eUpgradeRequest\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\tR\vfromVersion\" \n" +
+	"\n" +
+	"PluginData\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"J\n" +
+	"\x11ImportDataRequest\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\tR\vfromVersion\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"/\n" +
+	"\bSettings\x12#\n" +
+	"\rsettings_json\x18\x01 \x01(\fR\fsettingsJson\"1\n" +
+	"\x12ConnectHostRequest\x12\x1b\n" +
+	"\tbroker_id\x18\x01 \x01(\rR\bbrokerId\"*\n" +
+	"\x12ChangeNotification\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\"W\n" +
+	"\x13NotificationRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\x12\x16\n" +
+	"\x06urgent\x18\x03 \x01(\bR\x06urgent\"%\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\"v\n" +
+	"\fSearchResult\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x18\n" +
+	"\asnippet\x18\x04 \x01(\tR\asnippet\x12\x12\n" +
+	"\x04link\x18\x05 \x01(\tR\x04link\"F\n" +
+	"\x0eSearchResponse\x124\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.cortexplugin.SearchResultR\aresults\"5\n" +
+	"\rMigrationFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03sql\x18\x02 \x01(\tR\x03sql\"4\n" +
+	"\x0eCapabilityList\x12\"\n" +
+	"\fcapabilities\x18\x01 \x03(\tR\fcapabilities\"B\n" +
+	"\rMigrationList\x121\n" +
+	"\x05files\x18\x01 \x03(\v2\x1b.cortexplugin.MigrationFileR\x05files\"\xa3\x01\n" +
+	"\n" +
+	"SyncRecord\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\x12\x18\n" +
+	"\adeleted\x18\x04 \x01(\bR\adeleted\x12\x12\n" +
+	"\x04data\x18\x05 \x01(\fR\x04data\x12\x1d\n" +
+	"\n" +
+	"changed_at\x18\x06 \x01(\tR\tchangedAt\"?\n" +
+	"\x0fSyncPullRequest\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"q\n" +
+	"\bSyncPage\x122\n" +
+	"\arecords\x18\x01 \x03(\v2\x18.cortexplugin.SyncRecordR\arecords\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\xaa\x01\n" +
+	"\n" +
+	"SyncChange\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1b\n" +
+	"\tclient_id\x18\x03 \x01(\tR\bclientId\x12!\n" +
+	"\fbase_version\x18\x04 \x01(\x03R\vbaseVersion\x12\x18\n" +
+	"\adeleted\x18\x05 \x01(\bR\adeleted\x12\x12\n" +
+	"\x04data\x18\x06 \x01(\fR\x04data\"E\n" +
+	"\x0fSyncPushRequest\x122\n" +
+	"\achanges\x18\x01 \x03(\v2\x18.cortexplugin.SyncChangeR\achanges\"\xd9\x01\n" +
+	"\n" +
+	"SyncResult\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1b\n" +
+	"\tclient_id\x18\x03 \x01(\tR\bclientId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\x122\n" +
+	"\acurrent\x18\a \x01(\v2\x18.cortexplugin.SyncRecordR\acurrent\"F\n" +
+	"\x10SyncPushResponse\x122\n" +
+	"\aresults\x18\x01 \x03(\v2\x18.cortexplugin.SyncResultR\aresults\"\xc2\x01\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\"\n" +
+	"\fdeduplicated\x18\a \x01(\bR\fdeduplicated\"g\n" +
+	"\x14PutAttachmentRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"#\n" +
+	"\x11AttachmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"g\n" +
+	"\x11AttachmentContent\x128\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x18.cortexplugin.AttachmentR\n" +
+	"attachment\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"6\n" +
+	"\fValueRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\";\n" +
+	"\rValueResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\"%\n" +
+	"\vKeysRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\"\x1d\n" +
+	"\aKeyList\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"P\n" +
+	"\x0fScheduleRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12)\n" +
+	"\x10interval_seconds\x18\x02 \x01(\x03R\x0fintervalSeconds\" \n" +
+	"\n" +
+	"JobRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\xc5\x01\n" +
+	"\n" +
+	"LogRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12H\n" +
+	"\n" +
+	"attributes\x18\x03 \x03(\v2(.cortexplugin.LogRequest.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"d\n" +
+	"\x11PluginCallRequest\x12\x1b\n" +
+	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x122\n" +
+	"\arequest\x18\x02 \x01(\v2\x18.cortexplugin.APIRequestR\arequest2\xbe\n" +
+	"\n" +
+	"\fCortexPlugin\x12@\n" +
+	"\vGetManifest\x12\x13.cortexplugin.Empty\x1a\x1c.cortexplugin.PluginManifest\x12@\n" +
+	"\tHandleAPI\x12\x18.cortexplugin.APIRequest\x1a\x19.cortexplugin.APIResponse\x12F\n" +
+	"\rGetWidgetData\x12\x1b.cortexplugin.WidgetRequest\x1a\x18.cortexplugin.WidgetData\x12D\n" +
+	"\aMigrate\x12\x1c.cortexplugin.MigrateRequest\x1a\x1b.cortexplugin.MigrateResult\x124\n" +
+	"\bTeardown\x12\x13.cortexplugin.Empty\x1a\x13.cortexplugin.Empty\x12`\n" +
+	"\x0fMigrateSettings\x12&.cortexplugin.SettingsMigrationRequest\x1a%.cortexplugin.SettingsMigrationResult\x12D\n" +
+	"\vConnectHost\x12 .cortexplugin.ConnectHostRequest\x1a\x13.cortexplugin.Empty\x12C\n" +
+	"\x06Search\x12\x1b.cortexplugin.SearchRequest\x1a\x1c.cortexplugin.SearchResponse\x12B\n" +
+	"\x0eListMigrations\x12\x13.cortexplugin.Empty\x1a\x1b.cortexplugin.MigrationList\x122\n" +
+	"\x06Warmup\x12\x13.cortexplugin.Empty\x1a\x13.cortexplugin.Empty\x124\n" +
+	"\bSeedDemo\x12\x13.cortexplugin.Empty\x1a\x13.cortexplugin.Empty\x12A\n" +
+	"\bSyncPull\x12\x1d.cortexplugin.SyncPullRequest\x1a\x16.cortexplugin.SyncPage\x12I\n" +
+	"\bSyncPush\x12\x1d.cortexplugin.SyncPushRequest\x1a\x1e.cortexplugin.SyncPushResponse\x12A\n" +
+	"\fCapabilities\x12\x13.cortexplugin.Empty\x1a\x1c.cortexplugin.CapabilityList\x127\n" +
+	"\x06RunJob\x12\x18.cortexplugin.JobRequest\x1a\x13.cortexplugin.Empty\x12>\n" +
+	"\x0fSettingsChanged\x12\x16.cortexplugin.Settings\x1a\x13.cortexplugin.Empty\x12B\n" +
+	"\x0eRestoreDeleted\x12\x1b.cortexplugin.DeletedEntity\x1a\x13.cortexplugin.Empty\x12<\n" +
+	"\aUpgrade\x12\x1c.cortexplugin.UpgradeRequest\x1a\x13.cortexplugin.Empty\x12;\n" +
+	"\n" +
+	"ExportData\x12\x13.cortexplugin.Empty\x1a\x18.cortexplugin.PluginData\x12B\n" +
+	"\n" +
+	"ImportData\x12\x1f.cortexplugin.ImportDataRequest\x1a\x13.cortexplugin.Empty2\x8b\a\n" +
+	"\n" +
+	"CortexHost\x12F\n" +
+	"\rNotifyChanged\x12 .cortexplugin.ChangeNotification\x1a\x13.cortexplugin.Empty\x12M\n" +
+	"\rPutAttachment\x12\".cortexplugin.PutAttachmentRequest\x1a\x18.cortexplugin.Attachment\x12Q\n" +
+	"\rGetAttachment\x12\x1f.cortexplugin.AttachmentRequest\x1a\x1f.cortexplugin.AttachmentContent\x12H\n" +
+	"\x10DeleteAttachment\x12\x1f.cortexplugin.AttachmentRequest\x1a\x13.cortexplugin.Empty\x12J\n" +
+	"\x10SendNotification\x12!.cortexplugin.NotificationRequest\x1a\x13.cortexplugin.Empty\x12C\n" +
+	"\bGetValue\x12\x1a.cortexplugin.ValueRequest\x1a\x1b.cortexplugin.ValueResponse\x12;\n" +
+	"\bSetValue\x12\x1a.cortexplugin.ValueRequest\x1a\x13.cortexplugin.Empty\x12>\n" +
+	"\vDeleteValue\x12\x1a.cortexplugin.ValueRequest\x1a\x13.cortexplugin.Empty\x12<\n" +
+	"\bListKeys\x12\x19.cortexplugin.KeysRequest\x1a\x15.cortexplugin.KeyList\x12A\n" +
+	"\vScheduleJob\x12\x1d.cortexplugin.ScheduleRequest\x1a\x13.cortexplugin.Empty\x124\n" +
+	"\x03Log\x12\x18.cortexplugin.LogRequest\x1a\x13.cortexplugin.Empty\x12:\n" +
+	"\vGetSettings\x12\x13.cortexplugin.Empty\x1a\x16.cortexplugin.Settings\x12H\n" +
+	"\n" +
+	"CallPlugin\x12\x1f.cortexplugin.PluginCallRequest\x1a\x19.cortexplugin.APIResponseB7Z5github.com/alvarotorresc/cortex/internal/plugin/protob\x06proto3"
 
 var (
 	file_plugin_proto_rawDescOnce sync.Once
@@ -519,35 +2633,141 @@ func file_plugin_proto_rawDescGZIP() []byte {
 	return file_plugin_proto_rawDescData
 }
 
-var file_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_plugin_proto_goTypes = []any{
-	(*Empty)(nil),          // 0: cortexplugin.Empty
-	(*PluginManifest)(nil), // 1: cortexplugin.PluginManifest
-	(*APIRequest)(nil),     // 2: cortexplugin.APIRequest
-	(*APIResponse)(nil),    // 3: cortexplugin.APIResponse
-	(*WidgetRequest)(nil),  // 4: cortexplugin.WidgetRequest
-	(*WidgetData)(nil),     // 5: cortexplugin.WidgetData
-	(*MigrateRequest)(nil), // 6: cortexplugin.MigrateRequest
-	(*MigrateResult)(nil),  // 7: cortexplugin.MigrateResult
-	nil,                    // 8: cortexplugin.APIRequest.QueryEntry
+	(*Empty)(nil),                    // 0: cortexplugin.Empty
+	(*PluginManifest)(nil),           // 1: cortexplugin.PluginManifest
+	(*APIRequest)(nil),               // 2: cortexplugin.APIRequest
+	(*APIResponse)(nil),              // 3: cortexplugin.APIResponse
+	(*DeletedEntity)(nil),            // 4: cortexplugin.DeletedEntity
+	(*WidgetRequest)(nil),            // 5: cortexplugin.WidgetRequest
+	(*WidgetData)(nil),               // 6: cortexplugin.WidgetData
+	(*MigrateRequest)(nil),           // 7: cortexplugin.MigrateRequest
+	(*MigrateResult)(nil),            // 8: cortexplugin.MigrateResult
+	(*SettingsMigrationRequest)(nil), // 9: cortexplugin.SettingsMigrationRequest
+	(*SettingsMigrationResult)(nil),  // 10: cortexplugin.SettingsMigrationResult
+	(*UpgradeRequest)(nil),           // 11: cortexplugin.UpgradeRequest
+	(*PluginData)(nil),               // 12: cortexplugin.PluginData
+	(*ImportDataRequest)(nil),        // 13: cortexplugin.ImportDataRequest
+	(*Settings)(nil),                 // 14: cortexplugin.Settings
+	(*ConnectHostRequest)(nil),       // 15: cortexplugin.ConnectHostRequest
+	(*ChangeNotification)(nil),       // 16: cortexplugin.ChangeNotification
+	(*NotificationRequest)(nil),      // 17: cortexplugin.NotificationRequest
+	(*SearchRequest)(nil),            // 18: cortexplugin.SearchRequest
+	(*SearchResult)(nil),             // 19: cortexplugin.SearchResult
+	(*SearchResponse)(nil),           // 20: cortexplugin.SearchResponse
+	(*MigrationFile)(nil),            // 21: cortexplugin.MigrationFile
+	(*CapabilityList)(nil),           // 22: cortexplugin.CapabilityList
+	(*MigrationList)(nil),            // 23: cortexplugin.MigrationList
+	(*SyncRecord)(nil),               // 24: cortexplugin.SyncRecord
+	(*SyncPullRequest)(nil),          // 25: cortexplugin.SyncPullRequest
+	(*SyncPage)(nil),                 // 26: cortexplugin.SyncPage
+	(*SyncChange)(nil),               // 27: cortexplugin.SyncChange
+	(*SyncPushRequest)(nil),          // 28: cortexplugin.SyncPushRequest
+	(*SyncResult)(nil),               // 29: cortexplugin.SyncResult
+	(*SyncPushResponse)(nil),         // 30: cortexplugin.SyncPushResponse
+	(*Attachment)(nil),               // 31: cortexplugin.Attachment
+	(*PutAttachmentRequest)(nil),     // 32: cortexplugin.PutAttachmentRequest
+	(*AttachmentRequest)(nil),        // 33: cortexplugin.AttachmentRequest
+	(*AttachmentContent)(nil),        // 34: cortexplugin.AttachmentContent
+	(*ValueRequest)(nil),             // 35: cortexplugin.ValueRequest
+	(*ValueResponse)(nil),            // 36: cortexplugin.ValueResponse
+	(*KeysRequest)(nil),              // 37: cortexplugin.KeysRequest
+	(*KeyList)(nil),                  // 38: cortexplugin.KeyList
+	(*ScheduleRequest)(nil),          // 39: cortexplugin.ScheduleRequest
+	(*JobRequest)(nil),               // 40: cortexplugin.JobRequest
+	(*LogRequest)(nil),               // 41: cortexplugin.LogRequest
+	(*PluginCallRequest)(nil),        // 42: cortexplugin.PluginCallRequest
+	nil,                              // 43: cortexplugin.APIRequest.QueryEntry
+	nil,                              // 44: cortexplugin.APIRequest.HeadersEntry
+	nil,                              // 45: cortexplugin.APIResponse.HeadersEntry
+	nil,                              // 46: cortexplugin.LogRequest.AttributesEntry
 }
 var file_plugin_proto_depIdxs = []int32{
-	8, // 0: cortexplugin.APIRequest.query:type_name -> cortexplugin.APIRequest.QueryEntry
-	0, // 1: cortexplugin.CortexPlugin.GetManifest:input_type -> cortexplugin.Empty
-	2, // 2: cortexplugin.CortexPlugin.HandleAPI:input_type -> cortexplugin.APIRequest
-	4, // 3: cortexplugin.CortexPlugin.GetWidgetData:input_type -> cortexplugin.WidgetRequest
-	6, // 4: cortexplugin.CortexPlugin.Migrate:input_type -> cortexplugin.MigrateRequest
-	0, // 5: cortexplugin.CortexPlugin.Teardown:input_type -> cortexplugin.Empty
-	1, // 6: cortexplugin.CortexPlugin.GetManifest:output_type -> cortexplugin.PluginManifest
-	3, // 7: cortexplugin.CortexPlugin.HandleAPI:output_type -> cortexplugin.APIResponse
-	5, // 8: cortexplugin.CortexPlugin.GetWidgetData:output_type -> cortexplugin.WidgetData
-	7, // 9: cortexplugin.CortexPlugin.Migrate:output_type -> cortexplugin.MigrateResult
-	0, // 10: cortexplugin.CortexPlugin.Teardown:output_type -> cortexplugin.Empty
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	43, // 0: cortexplugin.APIRequest.query:type_name -> cortexplugin.APIRequest.QueryEntry
+	44, // 1: cortexplugin.APIRequest.headers:type_name -> cortexplugin.APIRequest.HeadersEntry
+	45, // 2: cortexplugin.APIResponse.headers:type_name -> cortexplugin.APIResponse.HeadersEntry
+	4,  // 3: cortexplugin.APIResponse.deleted:type_name -> cortexplugin.DeletedEntity
+	19, // 4: cortexplugin.SearchResponse.results:type_name -> cortexplugin.SearchResult
+	21, // 5: cortexplugin.MigrationList.files:type_name -> cortexplugin.MigrationFile
+	24, // 6: cortexplugin.SyncPage.records:type_name -> cortexplugin.SyncRecord
+	27, // 7: cortexplugin.SyncPushRequest.changes:type_name -> cortexplugin.SyncChange
+	24, // 8: cortexplugin.SyncResult.current:type_name -> cortexplugin.SyncRecord
+	29, // 9: cortexplugin.SyncPushResponse.results:type_name -> cortexplugin.SyncResult
+	31, // 10: cortexplugin.AttachmentContent.attachment:type_name -> cortexplugin.Attachment
+	46, // 11: cortexplugin.LogRequest.attributes:type_name -> cortexplugin.LogRequest.AttributesEntry
+	2,  // 12: cortexplugin.PluginCallRequest.request:type_name -> cortexplugin.APIRequest
+	0,  // 13: cortexplugin.CortexPlugin.GetManifest:input_type -> cortexplugin.Empty
+	2,  // 14: cortexplugin.CortexPlugin.HandleAPI:input_type -> cortexplugin.APIRequest
+	5,  // 15: cortexplugin.CortexPlugin.GetWidgetData:input_type -> cortexplugin.WidgetRequest
+	7,  // 16: cortexplugin.CortexPlugin.Migrate:input_type -> cortexplugin.MigrateRequest
+	0,  // 17: cortexplugin.CortexPlugin.Teardown:input_type -> cortexplugin.Empty
+	9,  // 18: cortexplugin.CortexPlugin.MigrateSettings:input_type -> cortexplugin.SettingsMigrationRequest
+	15, // 19: cortexplugin.CortexPlugin.ConnectHost:input_type -> cortexplugin.ConnectHostRequest
+	18, // 20: cortexplugin.CortexPlugin.Search:input_type -> cortexplugin.SearchRequest
+	0,  // 21: cortexplugin.CortexPlugin.ListMigrations:input_type -> cortexplugin.Empty
+	0,  // 22: cortexplugin.CortexPlugin.Warmup:input_type -> cortexplugin.Empty
+	0,  // 23: cortexplugin.CortexPlugin.SeedDemo:input_type -> cortexplugin.Empty
+	25, // 24: cortexplugin.CortexPlugin.SyncPull:input_type -> cortexplugin.SyncPullRequest
+	28, // 25: cortexplugin.CortexPlugin.SyncPush:input_type -> cortexplugin.SyncPushRequest
+	0,  // 26: cortexplugin.CortexPlugin.Capabilities:input_type -> cortexplugin.Empty
+	40, // 27: cortexplugin.CortexPlugin.RunJob:input_type -> cortexplugin.JobRequest
+	14, // 28: cortexplugin.CortexPlugin.SettingsChanged:input_type -> cortexplugin.Settings
+	4,  // 29: cortexplugin.CortexPlugin.RestoreDeleted:input_type -> cortexplugin.DeletedEntity
+	11, // 30: cortexplugin.CortexPlugin.Upgrade:input_type -> cortexplugin.UpgradeRequest
+	0,  // 31: cortexplugin.CortexPlugin.ExportData:input_type -> cortexplugin.Empty
+	13, // 32: cortexplugin.CortexPlugin.ImportData:input_type -> cortexplugin.ImportDataRequest
+	16, // 33: cortexplugin.CortexHost.NotifyChanged:input_type -> cortexplugin.ChangeNotification
+	32, // 34: cortexplugin.CortexHost.PutAttachment:input_type -> cortexplugin.PutAttachmentRequest
+	33, // 35: cortexplugin.CortexHost.GetAttachment:input_type -> cortexplugin.AttachmentRequest
+	33, // 36: cortexplugin.CortexHost.DeleteAttachment:input_type -> cortexplugin.AttachmentRequest
+	17, // 37: cortexplugin.CortexHost.SendNotification:input_type -> cortexplugin.NotificationRequest
+	35, // 38: cortexplugin.CortexHost.GetValue:input_type -> cortexplugin.ValueRequest
+	35, // 39: cortexplugin.CortexHost.SetValue:input_type -> cortexplugin.ValueRequest
+	35, // 40: cortexplugin.CortexHost.DeleteValue:input_type -> cortexplugin.ValueRequest
+	37, // 41: cortexplugin.CortexHost.ListKeys:input_type -> cortexplugin.KeysRequest
+	39, // 42: cortexplugin.CortexHost.ScheduleJob:input_type -> cortexplugin.ScheduleRequest
+	41, // 43: cortexplugin.CortexHost.Log:input_type -> cortexplugin.LogRequest
+	0,  // 44: cortexplugin.CortexHost.GetSettings:input_type -> cortexplugin.Empty
+	42, // 45: cortexplugin.CortexHost.CallPlugin:input_type -> cortexplugin.PluginCallRequest
+	1,  // 46: cortexplugin.CortexPlugin.GetManifest:output_type -> cortexplugin.PluginManifest
+	3,  // 47: cortexplugin.CortexPlugin.HandleAPI:output_type -> cortexplugin.APIResponse
+	6,  // 48: cortexplugin.CortexPlugin.GetWidgetData:output_type -> cortexplugin.WidgetData
+	8,  // 49: cortexplugin.CortexPlugin.Migrate:output_type -> cortexplugin.MigrateResult
+	0,  // 50: cortexplugin.CortexPlugin.Teardown:output_type -> cortexplugin.Empty
+	10, // 51: cortexplugin.CortexPlugin.MigrateSettings:output_type -> cortexplugin.SettingsMigrationResult
+	0,  // 52: cortexplugin.CortexPlugin.ConnectHost:output_type -> cortexplugin.Empty
+	20, // 53: cortexplugin.CortexPlugin.Search:output_type -> cortexplugin.SearchResponse
+	23, // 54: cortexplugin.CortexPlugin.ListMigrations:output_type -> cortexplugin.MigrationList
+	0,  // 55: cortexplugin.CortexPlugin.Warmup:output_type -> cortexplugin.Empty
+	0,  // 56: cortexplugin.CortexPlugin.SeedDemo:output_type -> cortexplugin.Empty
+	26, // 57: cortexplugin.CortexPlugin.SyncPull:output_type -> cortexplugin.SyncPage
+	30, // 58: cortexplugin.CortexPlugin.SyncPush:output_type -> cortexplugin.SyncPushResponse
+	22, // 59: cortexplugin.CortexPlugin.Capabilities:output_type -> cortexplugin.CapabilityList
+	0,  // 60: cortexplugin.CortexPlugin.RunJob:output_type -> cortexplugin.Empty
+	0,  // 61: cortexplugin.CortexPlugin.SettingsChanged:output_type -> cortexplugin.Empty
+	0,  // 62: cortexplugin.CortexPlugin.RestoreDeleted:output_type -> cortexplugin.Empty
+	0,  // 63: cortexplugin.CortexPlugin.Upgrade:output_type -> cortexplugin.Empty
+	12, // 64: cortexplugin.CortexPlugin.ExportData:output_type -> cortexplugin.PluginData
+	0,  // 65: cortexplugin.CortexPlugin.ImportData:output_type -> cortexplugin.Empty
+	0,  // 66: cortexplugin.CortexHost.NotifyChanged:output_type -> cortexplugin.Empty
+	31, // 67: cortexplugin.CortexHost.PutAttachment:output_type -> cortexplugin.Attachment
+	34, // 68: cortexplugin.CortexHost.GetAttachment:output_type -> cortexplugin.AttachmentContent
+	0,  // 69: cortexplugin.CortexHost.DeleteAttachment:output_type -> cortexplugin.Empty
+	0,  // 70: cortexplugin.CortexHost.SendNotification:output_type -> cortexplugin.Empty
+	36, // 71: cortexplugin.CortexHost.GetValue:output_type -> cortexplugin.ValueResponse
+	0,  // 72: cortexplugin.CortexHost.SetValue:output_type -> cortexplugin.Empty
+	0,  // 73: cortexplugin.CortexHost.DeleteValue:output_type -> cortexplugin.Empty
+	38, // 74: cortexplugin.CortexHost.ListKeys:output_type -> cortexplugin.KeyList
+	0,  // 75: cortexplugin.CortexHost.ScheduleJob:output_type -> cortexplugin.Empty
+	0,  // 76: cortexplugin.CortexHost.Log:output_type -> cortexplugin.Empty
+	14, // 77: cortexplugin.CortexHost.GetSettings:output_type -> cortexplugin.Settings
+	3,  // 78: cortexplugin.CortexHost.CallPlugin:output_type -> cortexplugin.APIResponse
+	46, // [46:79] is the sub-list for method output_type
+	13, // [13:46] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_plugin_proto_init() }
@@ -561,9 +2781,9 @@ func file_plugin_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_plugin_proto_rawDesc), len(file_plugin_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   47,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_plugin_proto_goTypes,
 		DependencyIndexes: file_plugin_proto_depIdxs,
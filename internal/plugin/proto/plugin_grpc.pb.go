@@ -19,11 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CortexPlugin_GetManifest_FullMethodName   = "/cortexplugin.CortexPlugin/GetManifest"
-	CortexPlugin_HandleAPI_FullMethodName     = "/cortexplugin.CortexPlugin/HandleAPI"
-	CortexPlugin_GetWidgetData_FullMethodName = "/cortexplugin.CortexPlugin/GetWidgetData"
-	CortexPlugin_Migrate_FullMethodName       = "/cortexplugin.CortexPlugin/Migrate"
-	CortexPlugin_Teardown_FullMethodName      = "/cortexplugin.CortexPlugin/Teardown"
+	CortexPlugin_GetManifest_FullMethodName     = "/cortexplugin.CortexPlugin/GetManifest"
+	CortexPlugin_HandleAPI_FullMethodName       = "/cortexplugin.CortexPlugin/HandleAPI"
+	CortexPlugin_GetWidgetData_FullMethodName   = "/cortexplugin.CortexPlugin/GetWidgetData"
+	CortexPlugin_Migrate_FullMethodName         = "/cortexplugin.CortexPlugin/Migrate"
+	CortexPlugin_Teardown_FullMethodName        = "/cortexplugin.CortexPlugin/Teardown"
+	CortexPlugin_MigrateSettings_FullMethodName = "/cortexplugin.CortexPlugin/MigrateSettings"
+	CortexPlugin_ConnectHost_FullMethodName     = "/cortexplugin.CortexPlugin/ConnectHost"
+	CortexPlugin_Search_FullMethodName          = "/cortexplugin.CortexPlugin/Search"
+	CortexPlugin_ListMigrations_FullMethodName  = "/cortexplugin.CortexPlugin/ListMigrations"
+	CortexPlugin_Warmup_FullMethodName          = "/cortexplugin.CortexPlugin/Warmup"
+	CortexPlugin_SeedDemo_FullMethodName        = "/cortexplugin.CortexPlugin/SeedDemo"
+	CortexPlugin_SyncPull_FullMethodName        = "/cortexplugin.CortexPlugin/SyncPull"
+	CortexPlugin_SyncPush_FullMethodName        = "/cortexplugin.CortexPlugin/SyncPush"
+	CortexPlugin_Capabilities_FullMethodName    = "/cortexplugin.CortexPlugin/Capabilities"
+	CortexPlugin_RunJob_FullMethodName          = "/cortexplugin.CortexPlugin/RunJob"
+	CortexPlugin_SettingsChanged_FullMethodName = "/cortexplugin.CortexPlugin/SettingsChanged"
+	CortexPlugin_RestoreDeleted_FullMethodName  = "/cortexplugin.CortexPlugin/RestoreDeleted"
+	CortexPlugin_Upgrade_FullMethodName         = "/cortexplugin.CortexPlugin/Upgrade"
+	CortexPlugin_ExportData_FullMethodName      = "/cortexplugin.CortexPlugin/ExportData"
+	CortexPlugin_ImportData_FullMethodName      = "/cortexplugin.CortexPlugin/ImportData"
 )
 
 // CortexPluginClient is the client API for CortexPlugin service.
@@ -35,6 +50,21 @@ type CortexPluginClient interface {
 	GetWidgetData(ctx context.Context, in *WidgetRequest, opts ...grpc.CallOption) (*WidgetData, error)
 	Migrate(ctx context.Context, in *MigrateRequest, opts ...grpc.CallOption) (*MigrateResult, error)
 	Teardown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	MigrateSettings(ctx context.Context, in *SettingsMigrationRequest, opts ...grpc.CallOption) (*SettingsMigrationResult, error)
+	ConnectHost(ctx context.Context, in *ConnectHostRequest, opts ...grpc.CallOption) (*Empty, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	ListMigrations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MigrationList, error)
+	Warmup(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	SeedDemo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	SyncPull(ctx context.Context, in *SyncPullRequest, opts ...grpc.CallOption) (*SyncPage, error)
+	SyncPush(ctx context.Context, in *SyncPushRequest, opts ...grpc.CallOption) (*SyncPushResponse, error)
+	Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CapabilityList, error)
+	RunJob(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Empty, error)
+	SettingsChanged(ctx context.Context, in *Settings, opts ...grpc.CallOption) (*Empty, error)
+	RestoreDeleted(ctx context.Context, in *DeletedEntity, opts ...grpc.CallOption) (*Empty, error)
+	Upgrade(ctx context.Context, in *UpgradeRequest, opts ...grpc.CallOption) (*Empty, error)
+	ExportData(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PluginData, error)
+	ImportData(ctx context.Context, in *ImportDataRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type cortexPluginClient struct {
@@ -95,6 +125,156 @@ func (c *cortexPluginClient) Teardown(ctx context.Context, in *Empty, opts ...gr
 	return out, nil
 }
 
+func (c *cortexPluginClient) MigrateSettings(ctx context.Context, in *SettingsMigrationRequest, opts ...grpc.CallOption) (*SettingsMigrationResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SettingsMigrationResult)
+	err := c.cc.Invoke(ctx, CortexPlugin_MigrateSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) ConnectHost(ctx context.Context, in *ConnectHostRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_ConnectHost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, CortexPlugin_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) ListMigrations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*MigrationList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MigrationList)
+	err := c.cc.Invoke(ctx, CortexPlugin_ListMigrations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) Warmup(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_Warmup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) SeedDemo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_SeedDemo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) SyncPull(ctx context.Context, in *SyncPullRequest, opts ...grpc.CallOption) (*SyncPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncPage)
+	err := c.cc.Invoke(ctx, CortexPlugin_SyncPull_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) SyncPush(ctx context.Context, in *SyncPushRequest, opts ...grpc.CallOption) (*SyncPushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncPushResponse)
+	err := c.cc.Invoke(ctx, CortexPlugin_SyncPush_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CapabilityList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CapabilityList)
+	err := c.cc.Invoke(ctx, CortexPlugin_Capabilities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) RunJob(ctx context.Context, in *JobRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_RunJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) SettingsChanged(ctx context.Context, in *Settings, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_SettingsChanged_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) RestoreDeleted(ctx context.Context, in *DeletedEntity, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_RestoreDeleted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) Upgrade(ctx context.Context, in *UpgradeRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_Upgrade_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) ExportData(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PluginData, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PluginData)
+	err := c.cc.Invoke(ctx, CortexPlugin_ExportData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexPluginClient) ImportData(ctx context.Context, in *ImportDataRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexPlugin_ImportData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CortexPluginServer is the server API for CortexPlugin service.
 // All implementations must embed UnimplementedCortexPluginServer
 // for forward compatibility.
@@ -104,6 +284,21 @@ type CortexPluginServer interface {
 	GetWidgetData(context.Context, *WidgetRequest) (*WidgetData, error)
 	Migrate(context.Context, *MigrateRequest) (*MigrateResult, error)
 	Teardown(context.Context, *Empty) (*Empty, error)
+	MigrateSettings(context.Context, *SettingsMigrationRequest) (*SettingsMigrationResult, error)
+	ConnectHost(context.Context, *ConnectHostRequest) (*Empty, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	ListMigrations(context.Context, *Empty) (*MigrationList, error)
+	Warmup(context.Context, *Empty) (*Empty, error)
+	SeedDemo(context.Context, *Empty) (*Empty, error)
+	SyncPull(context.Context, *SyncPullRequest) (*SyncPage, error)
+	SyncPush(context.Context, *SyncPushRequest) (*SyncPushResponse, error)
+	Capabilities(context.Context, *Empty) (*CapabilityList, error)
+	RunJob(context.Context, *JobRequest) (*Empty, error)
+	SettingsChanged(context.Context, *Settings) (*Empty, error)
+	RestoreDeleted(context.Context, *DeletedEntity) (*Empty, error)
+	Upgrade(context.Context, *UpgradeRequest) (*Empty, error)
+	ExportData(context.Context, *Empty) (*PluginData, error)
+	ImportData(context.Context, *ImportDataRequest) (*Empty, error)
 	mustEmbedUnimplementedCortexPluginServer()
 }
 
@@ -129,6 +324,51 @@ func (UnimplementedCortexPluginServer) Migrate(context.Context, *MigrateRequest)
 func (UnimplementedCortexPluginServer) Teardown(context.Context, *Empty) (*Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method Teardown not implemented")
 }
+func (UnimplementedCortexPluginServer) MigrateSettings(context.Context, *SettingsMigrationRequest) (*SettingsMigrationResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method MigrateSettings not implemented")
+}
+func (UnimplementedCortexPluginServer) ConnectHost(context.Context, *ConnectHostRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method ConnectHost not implemented")
+}
+func (UnimplementedCortexPluginServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedCortexPluginServer) ListMigrations(context.Context, *Empty) (*MigrationList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMigrations not implemented")
+}
+func (UnimplementedCortexPluginServer) Warmup(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method Warmup not implemented")
+}
+func (UnimplementedCortexPluginServer) SeedDemo(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method SeedDemo not implemented")
+}
+func (UnimplementedCortexPluginServer) SyncPull(context.Context, *SyncPullRequest) (*SyncPage, error) {
+	return nil, status.Error(codes.Unimplemented, "method SyncPull not implemented")
+}
+func (UnimplementedCortexPluginServer) SyncPush(context.Context, *SyncPushRequest) (*SyncPushResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SyncPush not implemented")
+}
+func (UnimplementedCortexPluginServer) Capabilities(context.Context, *Empty) (*CapabilityList, error) {
+	return nil, status.Error(codes.Unimplemented, "method Capabilities not implemented")
+}
+func (UnimplementedCortexPluginServer) RunJob(context.Context, *JobRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunJob not implemented")
+}
+func (UnimplementedCortexPluginServer) SettingsChanged(context.Context, *Settings) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method SettingsChanged not implemented")
+}
+func (UnimplementedCortexPluginServer) RestoreDeleted(context.Context, *DeletedEntity) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestoreDeleted not implemented")
+}
+func (UnimplementedCortexPluginServer) Upgrade(context.Context, *UpgradeRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method Upgrade not implemented")
+}
+func (UnimplementedCortexPluginServer) ExportData(context.Context, *Empty) (*PluginData, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExportData not implemented")
+}
+func (UnimplementedCortexPluginServer) ImportData(context.Context, *ImportDataRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method ImportData not implemented")
+}
 func (UnimplementedCortexPluginServer) mustEmbedUnimplementedCortexPluginServer() {}
 func (UnimplementedCortexPluginServer) testEmbeddedByValue()                      {}
 
@@ -240,32 +480,920 @@ func _CortexPlugin_Teardown_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
-// CortexPlugin_ServiceDesc is the grpc.ServiceDesc for CortexPlugin service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var CortexPlugin_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "cortexplugin.CortexPlugin",
-	HandlerType: (*CortexPluginServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetManifest",
-			Handler:    _CortexPlugin_GetManifest_Handler,
-		},
-		{
-			MethodName: "HandleAPI",
-			Handler:    _CortexPlugin_HandleAPI_Handler,
-		},
-		{
-			MethodName: "GetWidgetData",
-			Handler:    _CortexPlugin_GetWidgetData_Handler,
-		},
-		{
-			MethodName: "Migrate",
-			Handler:    _CortexPlugin_Migrate_Handler,
-		},
-		{
-			MethodName: "Teardown",
-			Handler:    _CortexPlugin_Teardown_Handler,
+func _CortexPlugin_MigrateSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SettingsMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).MigrateSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_MigrateSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).MigrateSettings(ctx, req.(*SettingsMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_ConnectHost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectHostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).ConnectHost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_ConnectHost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).ConnectHost(ctx, req.(*ConnectHostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_ListMigrations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).ListMigrations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_ListMigrations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).ListMigrations(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_Warmup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).Warmup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_Warmup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).Warmup(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_SeedDemo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).SeedDemo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_SeedDemo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).SeedDemo(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_SyncPull_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncPullRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).SyncPull(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_SyncPull_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).SyncPull(ctx, req.(*SyncPullRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_SyncPush_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncPushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).SyncPush(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_SyncPush_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).SyncPush(ctx, req.(*SyncPushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_Capabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).Capabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_Capabilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).Capabilities(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_RunJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).RunJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_RunJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).RunJob(ctx, req.(*JobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_SettingsChanged_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Settings)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).SettingsChanged(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_SettingsChanged_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).SettingsChanged(ctx, req.(*Settings))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_RestoreDeleted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletedEntity)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).RestoreDeleted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_RestoreDeleted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).RestoreDeleted(ctx, req.(*DeletedEntity))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_Upgrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpgradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).Upgrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_Upgrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).Upgrade(ctx, req.(*UpgradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_ExportData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).ExportData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_ExportData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).ExportData(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexPlugin_ImportData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexPluginServer).ImportData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexPlugin_ImportData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexPluginServer).ImportData(ctx, req.(*ImportDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CortexPlugin_ServiceDesc is the grpc.ServiceDesc for CortexPlugin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CortexPlugin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cortexplugin.CortexPlugin",
+	HandlerType: (*CortexPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetManifest",
+			Handler:    _CortexPlugin_GetManifest_Handler,
+		},
+		{
+			MethodName: "HandleAPI",
+			Handler:    _CortexPlugin_HandleAPI_Handler,
+		},
+		{
+			MethodName: "GetWidgetData",
+			Handler:    _CortexPlugin_GetWidgetData_Handler,
+		},
+		{
+			MethodName: "Migrate",
+			Handler:    _CortexPlugin_Migrate_Handler,
+		},
+		{
+			MethodName: "Teardown",
+			Handler:    _CortexPlugin_Teardown_Handler,
+		},
+		{
+			MethodName: "MigrateSettings",
+			Handler:    _CortexPlugin_MigrateSettings_Handler,
+		},
+		{
+			MethodName: "ConnectHost",
+			Handler:    _CortexPlugin_ConnectHost_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _CortexPlugin_Search_Handler,
+		},
+		{
+			MethodName: "ListMigrations",
+			Handler:    _CortexPlugin_ListMigrations_Handler,
+		},
+		{
+			MethodName: "Warmup",
+			Handler:    _CortexPlugin_Warmup_Handler,
+		},
+		{
+			MethodName: "SeedDemo",
+			Handler:    _CortexPlugin_SeedDemo_Handler,
+		},
+		{
+			MethodName: "SyncPull",
+			Handler:    _CortexPlugin_SyncPull_Handler,
+		},
+		{
+			MethodName: "SyncPush",
+			Handler:    _CortexPlugin_SyncPush_Handler,
+		},
+		{
+			MethodName: "Capabilities",
+			Handler:    _CortexPlugin_Capabilities_Handler,
+		},
+		{
+			MethodName: "RunJob",
+			Handler:    _CortexPlugin_RunJob_Handler,
+		},
+		{
+			MethodName: "SettingsChanged",
+			Handler:    _CortexPlugin_SettingsChanged_Handler,
+		},
+		{
+			MethodName: "RestoreDeleted",
+			Handler:    _CortexPlugin_RestoreDeleted_Handler,
+		},
+		{
+			MethodName: "Upgrade",
+			Handler:    _CortexPlugin_Upgrade_Handler,
+		},
+		{
+			MethodName: "ExportData",
+			Handler:    _CortexPlugin_ExportData_Handler,
+		},
+		{
+			MethodName: "ImportData",
+			Handler:    _CortexPlugin_ImportData_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "plugin.proto",
+}
+
+const (
+	CortexHost_NotifyChanged_FullMethodName    = "/cortexplugin.CortexHost/NotifyChanged"
+	CortexHost_PutAttachment_FullMethodName    = "/cortexplugin.CortexHost/PutAttachment"
+	CortexHost_GetAttachment_FullMethodName    = "/cortexplugin.CortexHost/GetAttachment"
+	CortexHost_DeleteAttachment_FullMethodName = "/cortexplugin.CortexHost/DeleteAttachment"
+	CortexHost_SendNotification_FullMethodName = "/cortexplugin.CortexHost/SendNotification"
+	CortexHost_GetValue_FullMethodName         = "/cortexplugin.CortexHost/GetValue"
+	CortexHost_SetValue_FullMethodName         = "/cortexplugin.CortexHost/SetValue"
+	CortexHost_DeleteValue_FullMethodName      = "/cortexplugin.CortexHost/DeleteValue"
+	CortexHost_ListKeys_FullMethodName         = "/cortexplugin.CortexHost/ListKeys"
+	CortexHost_ScheduleJob_FullMethodName      = "/cortexplugin.CortexHost/ScheduleJob"
+	CortexHost_Log_FullMethodName              = "/cortexplugin.CortexHost/Log"
+	CortexHost_GetSettings_FullMethodName      = "/cortexplugin.CortexHost/GetSettings"
+	CortexHost_CallPlugin_FullMethodName       = "/cortexplugin.CortexHost/CallPlugin"
+)
+
+// CortexHostClient is the client API for CortexHost service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CortexHostClient interface {
+	NotifyChanged(ctx context.Context, in *ChangeNotification, opts ...grpc.CallOption) (*Empty, error)
+	PutAttachment(ctx context.Context, in *PutAttachmentRequest, opts ...grpc.CallOption) (*Attachment, error)
+	GetAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*AttachmentContent, error)
+	DeleteAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*Empty, error)
+	SendNotification(ctx context.Context, in *NotificationRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetValue(ctx context.Context, in *ValueRequest, opts ...grpc.CallOption) (*ValueResponse, error)
+	SetValue(ctx context.Context, in *ValueRequest, opts ...grpc.CallOption) (*Empty, error)
+	DeleteValue(ctx context.Context, in *ValueRequest, opts ...grpc.CallOption) (*Empty, error)
+	ListKeys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (*KeyList, error)
+	ScheduleJob(ctx context.Context, in *ScheduleRequest, opts ...grpc.CallOption) (*Empty, error)
+	Log(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetSettings(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Settings, error)
+	CallPlugin(ctx context.Context, in *PluginCallRequest, opts ...grpc.CallOption) (*APIResponse, error)
+}
+
+type cortexHostClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCortexHostClient(cc grpc.ClientConnInterface) CortexHostClient {
+	return &cortexHostClient{cc}
+}
+
+func (c *cortexHostClient) NotifyChanged(ctx context.Context, in *ChangeNotification, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexHost_NotifyChanged_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) PutAttachment(ctx context.Context, in *PutAttachmentRequest, opts ...grpc.CallOption) (*Attachment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Attachment)
+	err := c.cc.Invoke(ctx, CortexHost_PutAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) GetAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*AttachmentContent, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachmentContent)
+	err := c.cc.Invoke(ctx, CortexHost_GetAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) DeleteAttachment(ctx context.Context, in *AttachmentRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexHost_DeleteAttachment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) SendNotification(ctx context.Context, in *NotificationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexHost_SendNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) GetValue(ctx context.Context, in *ValueRequest, opts ...grpc.CallOption) (*ValueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValueResponse)
+	err := c.cc.Invoke(ctx, CortexHost_GetValue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) SetValue(ctx context.Context, in *ValueRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexHost_SetValue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) DeleteValue(ctx context.Context, in *ValueRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexHost_DeleteValue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) ListKeys(ctx context.Context, in *KeysRequest, opts ...grpc.CallOption) (*KeyList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyList)
+	err := c.cc.Invoke(ctx, CortexHost_ListKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) ScheduleJob(ctx context.Context, in *ScheduleRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexHost_ScheduleJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) Log(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CortexHost_Log_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) GetSettings(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Settings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Settings)
+	err := c.cc.Invoke(ctx, CortexHost_GetSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cortexHostClient) CallPlugin(ctx context.Context, in *PluginCallRequest, opts ...grpc.CallOption) (*APIResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(APIResponse)
+	err := c.cc.Invoke(ctx, CortexHost_CallPlugin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CortexHostServer is the server API for CortexHost service.
+// All implementations must embed UnimplementedCortexHostServer
+// for forward compatibility.
+type CortexHostServer interface {
+	NotifyChanged(context.Context, *ChangeNotification) (*Empty, error)
+	PutAttachment(context.Context, *PutAttachmentRequest) (*Attachment, error)
+	GetAttachment(context.Context, *AttachmentRequest) (*AttachmentContent, error)
+	DeleteAttachment(context.Context, *AttachmentRequest) (*Empty, error)
+	SendNotification(context.Context, *NotificationRequest) (*Empty, error)
+	GetValue(context.Context, *ValueRequest) (*ValueResponse, error)
+	SetValue(context.Context, *ValueRequest) (*Empty, error)
+	DeleteValue(context.Context, *ValueRequest) (*Empty, error)
+	ListKeys(context.Context, *KeysRequest) (*KeyList, error)
+	ScheduleJob(context.Context, *ScheduleRequest) (*Empty, error)
+	Log(context.Context, *LogRequest) (*Empty, error)
+	GetSettings(context.Context, *Empty) (*Settings, error)
+	CallPlugin(context.Context, *PluginCallRequest) (*APIResponse, error)
+	mustEmbedUnimplementedCortexHostServer()
+}
+
+// UnimplementedCortexHostServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCortexHostServer struct{}
+
+func (UnimplementedCortexHostServer) NotifyChanged(context.Context, *ChangeNotification) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method NotifyChanged not implemented")
+}
+func (UnimplementedCortexHostServer) PutAttachment(context.Context, *PutAttachmentRequest) (*Attachment, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutAttachment not implemented")
+}
+func (UnimplementedCortexHostServer) GetAttachment(context.Context, *AttachmentRequest) (*AttachmentContent, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAttachment not implemented")
+}
+func (UnimplementedCortexHostServer) DeleteAttachment(context.Context, *AttachmentRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteAttachment not implemented")
+}
+func (UnimplementedCortexHostServer) SendNotification(context.Context, *NotificationRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendNotification not implemented")
+}
+func (UnimplementedCortexHostServer) GetValue(context.Context, *ValueRequest) (*ValueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetValue not implemented")
+}
+func (UnimplementedCortexHostServer) SetValue(context.Context, *ValueRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetValue not implemented")
+}
+func (UnimplementedCortexHostServer) DeleteValue(context.Context, *ValueRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteValue not implemented")
+}
+func (UnimplementedCortexHostServer) ListKeys(context.Context, *KeysRequest) (*KeyList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListKeys not implemented")
+}
+func (UnimplementedCortexHostServer) ScheduleJob(context.Context, *ScheduleRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScheduleJob not implemented")
+}
+func (UnimplementedCortexHostServer) Log(context.Context, *LogRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method Log not implemented")
+}
+func (UnimplementedCortexHostServer) GetSettings(context.Context, *Empty) (*Settings, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSettings not implemented")
+}
+func (UnimplementedCortexHostServer) CallPlugin(context.Context, *PluginCallRequest) (*APIResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CallPlugin not implemented")
+}
+func (UnimplementedCortexHostServer) mustEmbedUnimplementedCortexHostServer() {}
+func (UnimplementedCortexHostServer) testEmbeddedByValue()                    {}
+
+// UnsafeCortexHostServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CortexHostServer will
+// result in compilation errors.
+type UnsafeCortexHostServer interface {
+	mustEmbedUnimplementedCortexHostServer()
+}
+
+func RegisterCortexHostServer(s grpc.ServiceRegistrar, srv CortexHostServer) {
+	// If the following call panics, it indicates UnimplementedCortexHostServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CortexHost_ServiceDesc, srv)
+}
+
+func _CortexHost_NotifyChanged_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeNotification)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).NotifyChanged(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_NotifyChanged_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).NotifyChanged(ctx, req.(*ChangeNotification))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_PutAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutAttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).PutAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_PutAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).PutAttachment(ctx, req.(*PutAttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_GetAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).GetAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_GetAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).GetAttachment(ctx, req.(*AttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_DeleteAttachment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).DeleteAttachment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_DeleteAttachment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).DeleteAttachment(ctx, req.(*AttachmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_SendNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).SendNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_SendNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).SendNotification(ctx, req.(*NotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_GetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).GetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_GetValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).GetValue(ctx, req.(*ValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_SetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).SetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_SetValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).SetValue(ctx, req.(*ValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_DeleteValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).DeleteValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_DeleteValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).DeleteValue(ctx, req.(*ValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_ListKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).ListKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_ListKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).ListKeys(ctx, req.(*KeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_ScheduleJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).ScheduleJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_ScheduleJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).ScheduleJob(ctx, req.(*ScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_Log_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).Log(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_Log_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).Log(ctx, req.(*LogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_GetSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).GetSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_GetSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).GetSettings(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CortexHost_CallPlugin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginCallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CortexHostServer).CallPlugin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CortexHost_CallPlugin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CortexHostServer).CallPlugin(ctx, req.(*PluginCallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CortexHost_ServiceDesc is the grpc.ServiceDesc for CortexHost service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CortexHost_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cortexplugin.CortexHost",
+	HandlerType: (*CortexHostServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NotifyChanged",
+			Handler:    _CortexHost_NotifyChanged_Handler,
+		},
+		{
+			MethodName: "PutAttachment",
+			Handler:    _CortexHost_PutAttachment_Handler,
+		},
+		{
+			MethodName: "GetAttachment",
+			Handler:    _CortexHost_GetAttachment_Handler,
+		},
+		{
+			MethodName: "DeleteAttachment",
+			Handler:    _CortexHost_DeleteAttachment_Handler,
+		},
+		{
+			MethodName: "SendNotification",
+			Handler:    _CortexHost_SendNotification_Handler,
+		},
+		{
+			MethodName: "GetValue",
+			Handler:    _CortexHost_GetValue_Handler,
+		},
+		{
+			MethodName: "SetValue",
+			Handler:    _CortexHost_SetValue_Handler,
+		},
+		{
+			MethodName: "DeleteValue",
+			Handler:    _CortexHost_DeleteValue_Handler,
+		},
+		{
+			MethodName: "ListKeys",
+			Handler:    _CortexHost_ListKeys_Handler,
+		},
+		{
+			MethodName: "ScheduleJob",
+			Handler:    _CortexHost_ScheduleJob_Handler,
+		},
+		{
+			MethodName: "Log",
+			Handler:    _CortexHost_Log_Handler,
+		},
+		{
+			MethodName: "GetSettings",
+			Handler:    _CortexHost_GetSettings_Handler,
+		},
+		{
+			MethodName: "CallPlugin",
+			Handler:    _CortexHost_CallPlugin_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// SDKProtocolVersion is the version of the plugin API the host and the SDK
+// negotiate in the go-plugin handshake. It is bumped when the API gains calls
+// that plugins built with an older SDK cannot answer:
+//
+//	1  the original API
+//	2  adds Capabilities, listing the optional hooks a plugin implements
+//	3  the plugin's server accepts keepalive pings from the host, as often as
+//	   every MinKeepaliveInterval
+const SDKProtocolVersion = 3
+
+// keepaliveProtocol is the first plugin API whose servers accept the host's
+// keepalive pings; older ones close the connection when pinged too often.
+const keepaliveProtocol = 3
+
+// MinSDKProtocolVersion is the oldest plugin API the host still loads.
+// Plugins built for a version outside MinSDKProtocolVersion to
+// SDKProtocolVersion are refused and have to be rebuilt.
+const MinSDKProtocolVersion = 1
+
+// Optional hooks a plugin can report, from API version 2.
+const (
+	CapabilitySettingsMigration = "settings_migration"
+	CapabilitySearch            = "search"
+	CapabilityMigrations        = "migrations"
+	CapabilityWarmup            = "warmup"
+	CapabilityDemoSeed          = "demo_seed"
+	CapabilitySync              = "sync"
+	CapabilitySettingsListener  = "settings_listener"
+	CapabilityRestore           = "restore"
+	CapabilityUpgrade           = "upgrade"
+	CapabilityDataPort          = "data_port"
+)
+
+// ErrIncompatibleSDK is returned when a plugin was built for a plugin API the
+// host does not speak.
+var ErrIncompatibleSDK = errors.New("plugin was built with an incompatible SDK")
+
+// SDKInfo is the plugin API a loaded plugin negotiated with the host.
+type SDKInfo struct {
+	Protocol int `json:"protocol"`
+	// Capabilities is nil for plugins on API version 1, which cannot report
+	// them; the host then finds out which hooks they lack by calling them.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Outdated marks plugins built with an SDK older than the host's, which
+	// should be rebuilt after a host upgrade.
+	Outdated bool `json:"outdated"`
+}
+
+// Supports reports whether the plugin implements an optional hook, or may
+// do so when it cannot report its capabilities.
+func (s SDKInfo) Supports(capability string) bool {
+	return s.Capabilities == nil || slices.Contains(s.Capabilities, capability)
+}
+
+// capabilitiesOf lists the optional hooks impl implements.
+func capabilitiesOf(impl CortexPlugin) []string {
+	capabilities := make([]string, 0)
+	if _, ok := impl.(SettingsMigrator); ok {
+		capabilities = append(capabilities, CapabilitySettingsMigration)
+	}
+	if _, ok := impl.(Searcher); ok {
+		capabilities = append(capabilities, CapabilitySearch)
+	}
+	if _, ok := impl.(MigrationLister); ok {
+		capabilities = append(capabilities, CapabilityMigrations)
+	}
+	if _, ok := impl.(Warmer); ok {
+		capabilities = append(capabilities, CapabilityWarmup)
+	}
+	if _, ok := impl.(DemoSeeder); ok {
+		capabilities = append(capabilities, CapabilityDemoSeed)
+	}
+	if _, ok := impl.(Syncer); ok {
+		capabilities = append(capabilities, CapabilitySync)
+	}
+	if _, ok := impl.(SettingsListener); ok {
+		capabilities = append(capabilities, CapabilitySettingsListener)
+	}
+	if _, ok := impl.(Restorer); ok {
+		capabilities = append(capabilities, CapabilityRestore)
+	}
+	if _, ok := impl.(Upgrader); ok {
+		capabilities = append(capabilities, CapabilityUpgrade)
+	}
+	if _, ok := impl.(DataPorter); ok {
+		capabilities = append(capabilities, CapabilityDataPort)
+	}
+	return capabilities
+}
+
+// supportedPluginSets offers the host's plugin set for every API version it
+// loads, so go-plugin settles on the newest one the plugin speaks.
+func supportedPluginSets(set goplugin.PluginSet) map[int]goplugin.PluginSet {
+	sets := make(map[int]goplugin.PluginSet, SDKProtocolVersion-MinSDKProtocolVersion+1)
+	for version := MinSDKProtocolVersion; version <= SDKProtocolVersion; version++ {
+		sets[version] = set
+	}
+	return sets
+}
+
+// negotiateSDK records the API version a launched plugin settled on and, from
+// version 2, asks it for its capabilities, which the client then relies on
+// to skip the hooks the plugin lacks.
+func negotiateSDK(protocol int, cortexPlugin CortexPlugin) (SDKInfo, error) {
+	info := SDKInfo{Protocol: protocol}
+	info.Outdated = info.Protocol < SDKProtocolVersion
+
+	grpcClient, ok := cortexPlugin.(*GRPCClient)
+	if !ok || info.Protocol < 2 {
+		return info, nil
+	}
+
+	capabilities, err := grpcClient.Capabilities()
+	if err != nil {
+		return info, fmt.Errorf("reading plugin capabilities: %w", err)
+	}
+	info.Capabilities = capabilities
+	grpcClient.sdk = info
+	return info, nil
+}
+
+// incompatibleSDK turns go-plugin's version mismatch error into
+// ErrIncompatibleSDK, which says what to do about it.
+func incompatibleSDK(err error) error {
+	if !strings.Contains(err.Error(), "incompatible API version") {
+		return err
+	}
+	return fmt.Errorf("%w: the host loads plugin API versions %d to %d; rebuild the plugin against a matching SDK (%v)",
+		ErrIncompatibleSDK, MinSDKProtocolVersion, SDKProtocolVersion, err)
+}
@@ -0,0 +1,176 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// searchablePlugin implements Searcher and nothing else optional.
+type searchablePlugin struct{ fakePlugin }
+
+func (p *searchablePlugin) Search(query string) ([]SearchResult, error) {
+	return []SearchResult{{Type: "note", ID: "1", Title: query}}, nil
+}
+
+func TestNegotiateSDK_ReportsCapabilities(t *testing.T) {
+	client := dispenseOverGRPC(t, &searchablePlugin{})
+
+	info, err := negotiateSDK(SDKProtocolVersion, client)
+	if err != nil {
+		t.Fatalf("negotiation failed: %v", err)
+	}
+	if info.Protocol != SDKProtocolVersion || info.Outdated {
+		t.Errorf("expected the current protocol, got %+v", info)
+	}
+	if !slices.Equal(info.Capabilities, []string{CapabilitySearch}) {
+		t.Errorf("expected only the search capability, got %v", info.Capabilities)
+	}
+
+	if results, err := client.(Searcher).Search("rent"); err != nil || len(results) != 1 {
+		t.Errorf("expected a reported hook to be called, got %v, %v", results, err)
+	}
+	// Hooks the plugin did not report are answered without a call
+	if err := client.(Warmer).Warmup(); !errors.Is(err, ErrNotImplemented) {
+		t.Errorf("expected ErrNotImplemented for an unreported hook, got %v", err)
+	}
+}
+
+func TestNegotiateSDK_OlderProtocolDegrades(t *testing.T) {
+	client := dispenseOverGRPC(t, &searchablePlugin{})
+
+	info, err := negotiateSDK(1, client)
+	if err != nil {
+		t.Fatalf("negotiation failed: %v", err)
+	}
+	if !info.Outdated || info.Capabilities != nil {
+		t.Errorf("expected an outdated plugin without reported capabilities, got %+v", info)
+	}
+	if !info.Supports(CapabilitySync) {
+		t.Error("expected every hook to be tried on a plugin that cannot report capabilities")
+	}
+	if _, err := client.(Searcher).Search("rent"); err != nil {
+		t.Errorf("expected hooks to still be called, got %v", err)
+	}
+}
+
+func TestSupportedPluginSets(t *testing.T) {
+	sets := supportedPluginSets(PluginMap)
+	for version := MinSDKProtocolVersion; version <= SDKProtocolVersion; version++ {
+		if _, ok := sets[version]; !ok {
+			t.Errorf("expected protocol %d to be offered", version)
+		}
+	}
+	if len(sets) != SDKProtocolVersion-MinSDKProtocolVersion+1 {
+		t.Errorf("expected only supported protocols, got %d", len(sets))
+	}
+}
+
+func TestIncompatibleSDK(t *testing.T) {
+	mismatch := errors.New("incompatible API version with plugin. Plugin version: 9, Client versions: [1 2]")
+	if err := incompatibleSDK(mismatch); !errors.Is(err, ErrIncompatibleSDK) {
+		t.Errorf("expected ErrIncompatibleSDK, got %v", err)
+	}
+
+	other := errors.New("exec format error")
+	if err := incompatibleSDK(other); err != other {
+		t.Errorf("expected other errors unchanged, got %v", err)
+	}
+}
+
+// restoringPlugin attaches what it deletes to its responses and restores it,
+// unless told the records have been taken again.
+type restoringPlugin struct {
+	fakePlugin
+	restored string
+	conflict bool
+}
+
+func (p *restoringPlugin) HandleAPI(_ context.Context, request *APIRequest) (*APIResponse, error) {
+	return &APIResponse{StatusCode: 200, Deleted: &DeletedEntity{Kind: "note", Data: []byte(`{"id":7}`)}}, nil
+}
+
+func (p *restoringPlugin) RestoreDeleted(kind string, data []byte) error {
+	if p.conflict {
+		return ErrRestoreConflict
+	}
+	p.restored = kind + " " + string(data)
+	return nil
+}
+
+func TestRestoreDeleted_CrossesGRPC(t *testing.T) {
+	impl := &restoringPlugin{}
+	client := dispenseOverGRPC(t, impl)
+	if _, err := negotiateSDK(SDKProtocolVersion, client); err != nil {
+		t.Fatalf("negotiation failed: %v", err)
+	}
+
+	response, err := client.HandleAPI(context.Background(), &APIRequest{Method: "DELETE", Path: "/notes/7"})
+	if err != nil {
+		t.Fatalf("HandleAPI failed: %v", err)
+	}
+	if response.Deleted == nil || response.Deleted.Kind != "note" || string(response.Deleted.Data) != `{"id":7}` {
+		t.Fatalf("expected the deleted entity to reach the host, got %+v", response.Deleted)
+	}
+
+	restorer := client.(Restorer)
+	if err := restorer.RestoreDeleted(response.Deleted.Kind, response.Deleted.Data); err != nil {
+		t.Fatalf("RestoreDeleted failed: %v", err)
+	}
+	if impl.restored != `note {"id":7}` {
+		t.Errorf("expected the plugin to get the entity back, got %q", impl.restored)
+	}
+
+	impl.conflict = true
+	if err := restorer.RestoreDeleted("note", nil); !errors.Is(err, ErrRestoreConflict) {
+		t.Errorf("expected ErrRestoreConflict, got %v", err)
+	}
+}
+
+// portingPlugin implements DataPorter over an in-memory payload.
+type portingPlugin struct {
+	fakePlugin
+	data        []byte
+	fromVersion string
+}
+
+func (p *portingPlugin) ExportData() ([]byte, error) {
+	return p.data, nil
+}
+
+func (p *portingPlugin) ImportData(fromVersion string, data []byte) error {
+	if string(data) == "null" {
+		return fmt.Errorf("%w: nothing to import", ErrInvalidData)
+	}
+	p.fromVersion, p.data = fromVersion, data
+	return nil
+}
+
+func TestDataPorter_CrossesGRPC(t *testing.T) {
+	impl := &portingPlugin{data: []byte(`{"notes":[]}`)}
+	client := dispenseOverGRPC(t, impl)
+	info, err := negotiateSDK(SDKProtocolVersion, client)
+	if err != nil {
+		t.Fatalf("negotiation failed: %v", err)
+	}
+	if !slices.Equal(info.Capabilities, []string{CapabilityDataPort}) {
+		t.Errorf("expected only the data_port capability, got %v", info.Capabilities)
+	}
+
+	porter := client.(DataPorter)
+	data, err := porter.ExportData()
+	if err != nil || string(data) != `{"notes":[]}` {
+		t.Fatalf("expected the plugin's export, got %q, %v", data, err)
+	}
+	if err := porter.ImportData("1.0.0", []byte(`{"notes":[1]}`)); err != nil {
+		t.Fatalf("ImportData failed: %v", err)
+	}
+	if impl.fromVersion != "1.0.0" || string(impl.data) != `{"notes":[1]}` {
+		t.Errorf("expected the plugin to get the data and version, got %q %q", impl.fromVersion, impl.data)
+	}
+	if err := porter.ImportData("1.0.0", []byte("null")); !errors.Is(err, ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package plugin
 
 import (
+	"math/rand/v2"
+	"slices"
 	"sync"
 
 	goplugin "github.com/hashicorp/go-plugin"
@@ -11,18 +13,42 @@ type RegistryEntry struct {
 	Client   *goplugin.Client
 	Plugin   CortexPlugin
 	Manifest *Manifest
+	// SDK is the plugin API the plugin negotiated when it was launched.
+	SDK SDKInfo
+
+	// pid is the process the plugin was launched as, and limits the resource
+	// limits it was launched with, enforced as enforcement, through cgroup
+	// when it was placed in one.
+	pid         int
+	limits      ResourceLimits
+	enforcement string
+	cgroup      string
+}
+
+// Exited reports whether the plugin's subprocess has exited.
+func (e *RegistryEntry) Exited() bool {
+	return e.Client != nil && e.Client.Exited()
 }
 
 // Registry manages active plugins in a thread-safe map.
 type Registry struct {
 	mu      sync.RWMutex
 	plugins map[string]*RegistryEntry
+	// breakers outlive registry entries so crash history survives relaunches.
+	breakers map[string]*CircuitBreaker
+	// budgets, like breakers, survive relaunches.
+	budgets map[string]*ErrorBudget
+	// rollouts hold the canary traffic split per live plugin ID.
+	rollouts map[string]Rollout
 }
 
 // NewRegistry creates an empty plugin registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		plugins: make(map[string]*RegistryEntry),
+		plugins:  make(map[string]*RegistryEntry),
+		breakers: make(map[string]*CircuitBreaker),
+		budgets:  make(map[string]*ErrorBudget),
+		rollouts: make(map[string]Rollout),
 	}
 }
 
@@ -59,15 +85,118 @@ func (r *Registry) Unregister(id string) {
 	}
 }
 
-// List returns the manifests of all registered plugins.
+// Keys returns the registry keys of all registered plugins and canaries.
+func (r *Registry) Keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(r.plugins))
+	for key := range r.plugins {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// List returns the manifests of all registered plugins. Canaries are not
+// listed; they are reported by their live plugin's canary endpoint.
 func (r *Registry) List() []*Manifest {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	manifests := make([]*Manifest, 0, len(r.plugins))
-	for _, entry := range r.plugins {
+	for key, entry := range r.plugins {
+		if isCanaryKey(key) {
+			continue
+		}
 		manifests = append(manifests, entry.Manifest)
 	}
 
 	return manifests
 }
+
+// Breaker returns the circuit breaker for a plugin, creating it on first use.
+func (r *Registry) Breaker(id string) *CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	breaker, ok := r.breakers[id]
+	if !ok {
+		breaker = newCircuitBreaker()
+		r.breakers[id] = breaker
+	}
+	return breaker
+}
+
+// ErrorBudget returns the error budget for a plugin, creating it on first use.
+func (r *Registry) ErrorBudget(id string) *ErrorBudget {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	budget, ok := r.budgets[id]
+	if !ok {
+		budget = newErrorBudget()
+		r.budgets[id] = budget
+	}
+	return budget
+}
+
+// resetBreaker discards a plugin's breaker and error budget, so a deliberate
+// unload or reload starts with a clean failure and restart history and no
+// responses of the previous version.
+func (r *Registry) resetBreaker(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.breakers, id)
+	delete(r.budgets, id)
+}
+
+// SetRollout sets how much of a plugin's traffic goes to its canary.
+func (r *Registry) SetRollout(id string, rollout Rollout) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rollouts[id] = rollout
+}
+
+// Rollout returns the canary traffic split for a plugin, if one is set.
+func (r *Registry) Rollout(id string) (Rollout, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rollout, ok := r.rollouts[id]
+	return rollout, ok
+}
+
+// clearRollout stops routing a plugin's traffic to its canary.
+func (r *Registry) clearRollout(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.rollouts, id)
+}
+
+// RouteTarget returns the registry key that should serve a request for the
+// live plugin id: its canary when the request's API key is pinned to it or it
+// falls in the canary's percentage, otherwise id itself. apiKeyID is zero for
+// requests without an API key.
+func (r *Registry) RouteTarget(id string, apiKeyID int64) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rollout, ok := r.rollouts[id]
+	if !ok {
+		return id
+	}
+	if _, running := r.plugins[CanaryKey(id)]; !running {
+		return id
+	}
+
+	if apiKeyID != 0 && slices.Contains(rollout.APIKeyIDs, apiKeyID) {
+		return CanaryKey(id)
+	}
+	if rollout.Percent > 0 && rand.IntN(100) < rollout.Percent {
+		return CanaryKey(id)
+	}
+	return id
+}
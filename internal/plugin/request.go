@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// MaxAPIBodySize caps request and response bodies passed between the host
+	// and a plugin. Bodies are buffered in full, not streamed.
+	MaxAPIBodySize = 32 << 20
+	// apiMessageSize leaves room for the headers and query next to the body.
+	apiMessageSize = MaxAPIBodySize + 1<<20
+	// multipartMemory is how much of a multipart form ReadForm keeps in
+	// memory; larger files are spilled to temporary files.
+	multipartMemory = 8 << 20
+)
+
+// ErrNotMultipart is returned by MultipartForm for requests that are not
+// multipart/form-data.
+var ErrNotMultipart = errors.New("request is not multipart/form-data")
+
+// Header returns the first value of the named request header, matching the
+// name case-insensitively.
+func (r *APIRequest) Header(name string) string {
+	return r.Headers[textproto.CanonicalMIMEHeaderKey(name)]
+}
+
+// Logger returns base tagged with the request's ID, so the plugin's log
+// records can be matched with the host's for the same request. base is
+// usually HostServices.Logger; a nil base uses the default logger.
+func (r *APIRequest) Logger(base *slog.Logger) *slog.Logger {
+	if base == nil {
+		base = slog.Default()
+	}
+	if r.RequestID == "" {
+		return base
+	}
+	return base.With("request_id", r.RequestID)
+}
+
+// MultipartForm parses a multipart/form-data body, such as a file upload
+// from an HTML form. Call RemoveAll on the form once its files are read.
+func (r *APIRequest) MultipartForm() (*multipart.Form, error) {
+	mediaType, params, err := mime.ParseMediaType(r.ContentType)
+	if err != nil || !strings.EqualFold(mediaType, "multipart/form-data") {
+		return nil, ErrNotMultipart
+	}
+	if params["boundary"] == "" {
+		return nil, fmt.Errorf("%w: missing boundary", ErrNotMultipart)
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(r.Body), params["boundary"]).ReadForm(multipartMemory)
+	if err != nil {
+		return nil, fmt.Errorf("reading multipart form: %w", err)
+	}
+	return form, nil
+}
+
+// GRPCServer creates the plugin's gRPC server, accepting API requests with
+// bodies up to MaxAPIBodySize and the host's keepalive pings.
+func GRPCServer(options []grpc.ServerOption) *grpc.Server {
+	return goplugin.DefaultGRPCServer(append(options,
+		grpc.MaxRecvMsgSize(apiMessageSize),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: MinKeepaliveInterval, PermitWithoutStream: true}),
+	))
+}
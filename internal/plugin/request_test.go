@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestAPIRequest_MultipartForm(t *testing.T) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("account", "checking")
+	part, _ := form.CreateFormFile("statement", "march.csv")
+	_, _ = part.Write([]byte("date,amount\n2026-03-01,-12.50\n"))
+	_ = form.Close()
+
+	request := &APIRequest{
+		Method:      "POST",
+		Path:        "/imports",
+		Body:        body.Bytes(),
+		ContentType: form.FormDataContentType(),
+		Headers:     map[string]string{"X-Request-Id": "abc"},
+	}
+
+	if request.Header("x-request-id") != "abc" {
+		t.Errorf("expected headers to match case-insensitively, got %q", request.Header("x-request-id"))
+	}
+
+	parsed, err := request.MultipartForm()
+	if err != nil {
+		t.Fatalf("MultipartForm failed: %v", err)
+	}
+	defer parsed.RemoveAll()
+
+	if parsed.Value["account"][0] != "checking" {
+		t.Errorf("expected the account field, got %v", parsed.Value)
+	}
+	header := parsed.File["statement"][0]
+	file, err := header.Open()
+	if err != nil {
+		t.Fatalf("failed to open uploaded file: %v", err)
+	}
+	defer file.Close()
+	content, _ := io.ReadAll(file)
+	if header.Filename != "march.csv" || !bytes.HasPrefix(content, []byte("date,amount")) {
+		t.Errorf("unexpected upload %q: %q", header.Filename, content)
+	}
+
+	if _, err := (&APIRequest{ContentType: "application/json"}).MultipartForm(); !errors.Is(err, ErrNotMultipart) {
+		t.Errorf("expected ErrNotMultipart for JSON, got %v", err)
+	}
+}
+
+// requestRecordingPlugin records the last API request it handled.
+type requestRecordingPlugin struct {
+	fakePlugin
+	last *APIRequest
+}
+
+func (p *requestRecordingPlugin) HandleAPI(_ context.Context, request *APIRequest) (*APIResponse, error) {
+	p.last = request
+	return p.fakePlugin.HandleAPI(context.Background(), request)
+}
+
+func TestAPIRequest_RequestIDCrossesGRPC(t *testing.T) {
+	impl := &requestRecordingPlugin{}
+	client := connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: impl, PluginID: "notes"})
+
+	if _, err := client.HandleAPI(context.Background(), &APIRequest{Method: "GET", Path: "/notes", RequestID: "req-42"}); err != nil {
+		t.Fatalf("HandleAPI failed: %v", err)
+	}
+	if impl.last == nil || impl.last.RequestID != "req-42" {
+		t.Errorf("expected the plugin to receive the request ID, got %+v", impl.last)
+	}
+}
+
+func TestAPIRequest_Logger(t *testing.T) {
+	var output bytes.Buffer
+	base := slog.New(slog.NewTextHandler(&output, nil))
+
+	(&APIRequest{RequestID: "req-42"}).Logger(base).Info("saved")
+	if !strings.Contains(output.String(), "request_id=req-42") {
+		t.Errorf("expected the record to carry the request ID, got %q", output.String())
+	}
+
+	output.Reset()
+	(&APIRequest{}).Logger(base).Info("saved")
+	if strings.Contains(output.String(), "request_id") {
+		t.Errorf("expected no request ID without one, got %q", output.String())
+	}
+}
@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// routeAliasPattern matches a route alias after normalization: one or more
+// lowercase path segments, such as /finance or /tools/notes.
+var routeAliasPattern = regexp.MustCompile(`^(/[a-z0-9][a-z0-9-]*)+$`)
+
+// reservedRouteSegments are first path segments owned by the host API and the
+// frontend, which plugins cannot claim as aliases.
+var reservedRouteSegments = map[string]bool{
+	"api":      true,
+	"plugins":  true,
+	"settings": true,
+	"lite":     true,
+	"share":    true,
+	"_app":     true,
+	"metrics":  true,
+}
+
+// normalizeRouteAlias turns a manifest route such as "/finance/*" into the
+// prefix it claims, "/finance".
+func normalizeRouteAlias(route string) string {
+	route = strings.TrimSuffix(route, "*")
+	return strings.TrimSuffix(route, "/")
+}
+
+// validateRoutes rejects malformed, reserved, or repeated route aliases.
+func validateRoutes(routes []string) error {
+	seen := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		alias := normalizeRouteAlias(route)
+		if !routeAliasPattern.MatchString(alias) {
+			return fmt.Errorf("invalid route %q: must look like /name or /name/*", route)
+		}
+
+		first := strings.SplitN(strings.TrimPrefix(alias, "/"), "/", 2)[0]
+		if reservedRouteSegments[first] {
+			return fmt.Errorf("invalid route %q: /%s is reserved by the host", route, first)
+		}
+
+		if seen[alias] {
+			return fmt.Errorf("route %q is declared more than once", route)
+		}
+		seen[alias] = true
+	}
+	return nil
+}
+
+// ResolveRoute finds the plugin claiming a route alias that path falls under
+// and returns the path relative to that alias, so /finance/accounts resolves
+// to the plugin owning /finance/* with sub-path /accounts. The longest
+// matching alias wins.
+func (r *Registry) ResolveRoute(path string) (pluginID string, subPath string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	longest := -1
+	for id, entry := range r.plugins {
+		if entry.Manifest == nil || isCanaryKey(id) {
+			continue
+		}
+		for _, route := range entry.Manifest.Routes {
+			alias := normalizeRouteAlias(route)
+			if len(alias) <= longest {
+				continue
+			}
+			if path == alias || strings.HasPrefix(path, alias+"/") {
+				longest = len(alias)
+				pluginID = id
+				subPath = strings.TrimPrefix(path, alias)
+			}
+		}
+	}
+
+	if longest < 0 {
+		return "", "", false
+	}
+	if subPath == "" {
+		subPath = "/"
+	}
+	return pluginID, subPath, true
+}
+
+// routeConflict returns the first of routes already claimed by a registered
+// plugin other than pluginID, together with its owner. Canaries share their
+// live plugin's routes and never conflict.
+func (r *Registry) routeConflict(pluginID string, routes []string) (route string, owner string, found bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, route := range routes {
+		alias := normalizeRouteAlias(route)
+		for id, entry := range r.plugins {
+			if id == pluginID || entry.Manifest == nil || isCanaryKey(id) {
+				continue
+			}
+			for _, claimed := range entry.Manifest.Routes {
+				if normalizeRouteAlias(claimed) == alias {
+					return route, id, true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
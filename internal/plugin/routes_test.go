@@ -0,0 +1,68 @@
+package plugin
+
+import "testing"
+
+func TestValidateRoutes(t *testing.T) {
+	if err := validateRoutes([]string{"/finance/*", "/tools/notes", "/budget/"}); err != nil {
+		t.Errorf("expected valid routes, got %v", err)
+	}
+
+	for _, routes := range [][]string{
+		{"finance"},
+		{"/"},
+		{"/Finance/*"},
+		{"/finance/*/x"},
+		{"/api/*"},
+		{"/plugins/finance"},
+		{"/finance/*", "/finance"},
+	} {
+		if err := validateRoutes(routes); err == nil {
+			t.Errorf("expected %q to be rejected", routes)
+		}
+	}
+}
+
+func TestRegistry_ResolveRoute(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker", Routes: []string{"/finance/*"}})
+	registry.Register("finance-reports", nil, &Manifest{ID: "finance-reports", Routes: []string{"/finance/reports"}})
+	registry.Register("quick-notes", nil, &Manifest{ID: "quick-notes"})
+
+	tests := []struct {
+		path     string
+		pluginID string
+		subPath  string
+	}{
+		{"/finance", "finance-tracker", "/"},
+		{"/finance/accounts", "finance-tracker", "/accounts"},
+		{"/finance/reports/monthly", "finance-reports", "/monthly"},
+		{"/finance/reportsx", "finance-tracker", "/reportsx"},
+	}
+	for _, test := range tests {
+		pluginID, subPath, ok := registry.ResolveRoute(test.path)
+		if !ok || pluginID != test.pluginID || subPath != test.subPath {
+			t.Errorf("ResolveRoute(%q) = %q, %q, %v; want %q, %q", test.path, pluginID, subPath, ok, test.pluginID, test.subPath)
+		}
+	}
+
+	for _, path := range []string{"/financial", "/notes", "/"} {
+		if _, _, ok := registry.ResolveRoute(path); ok {
+			t.Errorf("expected %q not to resolve", path)
+		}
+	}
+}
+
+func TestRegistry_RouteConflict(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker", Routes: []string{"/finance/*"}})
+
+	route, owner, found := registry.routeConflict("budget", []string{"/budget", "/finance"})
+	if !found || route != "/finance" || owner != "finance-tracker" {
+		t.Errorf("expected /finance to conflict with finance-tracker, got %q, %q, %v", route, owner, found)
+	}
+
+	// A plugin reloading keeps its own aliases.
+	if _, _, found := registry.routeConflict("finance-tracker", []string{"/finance/*"}); found {
+		t.Error("expected a plugin not to conflict with itself")
+	}
+}
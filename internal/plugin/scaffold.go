@@ -0,0 +1,218 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// scaffoldTemplates is the plugin skeleton written by Scaffold. Every file is
+// a text/template named after the file it produces, plus a .tmpl suffix so
+// the Go files and go.mod are not taken for part of this module.
+//
+//go:embed all:scaffold
+var scaffoldTemplates embed.FS
+
+// ErrScaffoldExists is returned by Scaffold when the plugin directory already
+// exists, so an existing plugin is never written over.
+var ErrScaffoldExists = errors.New("plugin directory already exists")
+
+// ScaffoldOptions describes the plugin Scaffold generates. Name, the display
+// name, defaults to the ID in title case.
+type ScaffoldOptions struct {
+	ID   string
+	Name string
+}
+
+// ScaffoldResult is what Scaffold wrote. Standalone reports that the plugin
+// is its own Go module, rather than a package of the module it was generated
+// in, so its dependencies still have to be fetched with `go mod tidy`.
+type ScaffoldResult struct {
+	Dir        string
+	Files      []string
+	Standalone bool
+}
+
+// scaffoldData is what the templates are filled with.
+type scaffoldData struct {
+	ID        string
+	Name      string
+	Type      string
+	CLI       string
+	Module    string
+	GoVersion string
+}
+
+// Scaffold generates a ready-to-build plugin in parent/{id}: its manifest and
+// a backend laid out like the bundled plugins, with main.go calling
+// sdk.Serve, a migration runner and a first migration, an items package
+// split into handler, service and repository, and tests with their helpers.
+// When parent is inside a Go module the backend is one of its packages;
+// otherwise the plugin gets a go.mod of its own.
+func Scaffold(parent string, options ScaffoldOptions) (*ScaffoldResult, error) {
+	if !pluginIDPattern.MatchString(options.ID) {
+		return nil, fmt.Errorf("invalid plugin id %q: use lowercase letters, digits and dashes", options.ID)
+	}
+
+	dir, err := filepath.Abs(filepath.Join(parent, options.ID))
+	if err != nil {
+		return nil, fmt.Errorf("resolving plugin directory: %w", err)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrScaffoldExists, dir)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("checking plugin directory: %w", err)
+	}
+
+	data := scaffoldData{
+		ID:        options.ID,
+		Name:      strings.TrimSpace(options.Name),
+		Type:      scaffoldTypeName(options.ID),
+		GoVersion: scaffoldGoVersion(),
+	}
+	if data.Name == "" {
+		data.Name = scaffoldDisplayName(options.ID)
+	}
+	if strings.ContainsAny(data.Name, "\"\\\n") {
+		return nil, fmt.Errorf("invalid plugin name %q: quotes, backslashes and newlines are not allowed", data.Name)
+	}
+	if commandNamePattern.MatchString(options.ID) && !reservedCLINames[options.ID] {
+		data.CLI = options.ID
+	}
+
+	result := &ScaffoldResult{Dir: dir}
+	if root, module, ok := findModule(filepath.Dir(dir)); ok {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, fmt.Errorf("resolving plugin package: %w", err)
+		}
+		data.Module = path.Join(module, filepath.ToSlash(rel), "backend")
+	} else {
+		data.Module = options.ID + "/backend"
+		result.Standalone = true
+	}
+
+	files, err := renderScaffold(data, result.Standalone)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		target := filepath.Join(dir, filepath.FromSlash(file.name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, file.content, 0644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", target, err)
+		}
+		result.Files = append(result.Files, file.name)
+	}
+	return result, nil
+}
+
+// scaffoldFile is one rendered file of the skeleton, named relative to the
+// plugin directory.
+type scaffoldFile struct {
+	name    string
+	content []byte
+}
+
+// renderScaffold fills every template with data. The go.mod is only rendered
+// for a standalone plugin.
+func renderScaffold(data scaffoldData, standalone bool) ([]scaffoldFile, error) {
+	var files []scaffoldFile
+	err := fs.WalkDir(scaffoldTemplates, "scaffold", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		target := strings.TrimSuffix(strings.TrimPrefix(name, "scaffold/"), ".tmpl")
+		if target == "go.mod" && !standalone {
+			return nil
+		}
+
+		source, err := scaffoldTemplates.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New(target).Option("missingkey=error").Parse(string(source))
+		if err != nil {
+			return fmt.Errorf("parsing template %s: %w", target, err)
+		}
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, data); err != nil {
+			return fmt.Errorf("rendering %s: %w", target, err)
+		}
+		files = append(files, scaffoldFile{name: target, content: content.Bytes()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// findModule looks for the go.mod governing dir, returning the directory it
+// is in and the module path it declares.
+func findModule(dir string) (root string, module string, ok bool) {
+	for {
+		file, err := os.Open(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			defer file.Close()
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				if rest, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); found {
+					return dir, strings.Trim(strings.TrimSpace(rest), `"`), true
+				}
+			}
+			return "", "", false
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// scaffoldDisplayName turns a plugin ID such as "reading-list" into the
+// display name "Reading List".
+func scaffoldDisplayName(id string) string {
+	words := strings.Split(id, "-")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// scaffoldTypeName is the Go type of the plugin, such as ReadingListPlugin
+// for "reading-list".
+func scaffoldTypeName(id string) string {
+	name := strings.ReplaceAll(scaffoldDisplayName(id), " ", "")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "My" + name
+	}
+	return name + "Plugin"
+}
+
+// scaffoldGoVersion is the go directive of a standalone plugin's go.mod: the
+// language version of the toolchain this binary was built with.
+func scaffoldGoVersion() string {
+	parts := strings.SplitN(strings.TrimPrefix(runtime.Version(), "go"), ".", 3)
+	if len(parts) < 2 {
+		return "1.25"
+	}
+	return parts[0] + "." + strings.TrimFunc(parts[1], func(r rune) bool { return !unicode.IsDigit(r) })
+}
@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold_WritesValidPlugin(t *testing.T) {
+	parent := t.TempDir()
+
+	result, err := Scaffold(parent, ScaffoldOptions{ID: "reading-list"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Standalone {
+		t.Error("expected a plugin outside any module to be standalone")
+	}
+	for _, file := range []string{"manifest.json", "go.mod", "backend/main.go", "backend/plugin.go", "backend/plugin_test.go", "backend/migrations/001_init.sql", "backend/items/handler.go", "backend/items/service.go", "backend/items/repository.go"} {
+		if _, err := os.Stat(filepath.Join(result.Dir, filepath.FromSlash(file))); err != nil {
+			t.Errorf("expected %s to be generated: %v", file, err)
+		}
+	}
+
+	// Once built, the host accepts the generated manifest.
+	if err := os.WriteFile(filepath.Join(result.Dir, "plugin"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := checkPluginDirectory(result.Dir)
+	if err != nil {
+		t.Fatalf("expected a valid plugin directory, got %v", err)
+	}
+	if manifest.ID != "reading-list" || manifest.Name != "Reading List" || manifest.CLI != "reading-list" {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+
+	source, _ := os.ReadFile(filepath.Join(result.Dir, "backend", "plugin.go"))
+	if !strings.Contains(string(source), `"reading-list/backend/items"`) || !strings.Contains(string(source), "type ReadingListPlugin struct") {
+		t.Errorf("expected the plugin to import its own module, got:\n%s", source)
+	}
+
+	if _, err := Scaffold(parent, ScaffoldOptions{ID: "reading-list"}); !errors.Is(err, ErrScaffoldExists) {
+		t.Errorf("expected ErrScaffoldExists for an existing plugin, got %v", err)
+	}
+}
+
+func TestScaffold_UsesEnclosingModule(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/host\n\ngo 1.25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Scaffold(filepath.Join(root, "plugins"), ScaffoldOptions{ID: "2fa", Name: "Two Factor"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Standalone {
+		t.Error("expected a plugin inside a module to be one of its packages")
+	}
+	if _, err := os.Stat(filepath.Join(result.Dir, "go.mod")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no go.mod of its own, got %v", err)
+	}
+
+	source, _ := os.ReadFile(filepath.Join(result.Dir, "backend", "plugin.go"))
+	if !strings.Contains(string(source), `"example.com/host/plugins/2fa/backend/items"`) || !strings.Contains(string(source), "type My2faPlugin struct") {
+		t.Errorf("expected a package of the enclosing module, got:\n%s", source)
+	}
+	manifest, _ := os.ReadFile(filepath.Join(result.Dir, "manifest.json"))
+	if strings.Contains(string(manifest), `"cli"`) || !strings.Contains(string(manifest), `"name": "Two Factor"`) {
+		t.Errorf("expected no CLI group for an id that is not a command name, got:\n%s", manifest)
+	}
+}
+
+func TestScaffold_RejectsInvalidInput(t *testing.T) {
+	parent := t.TempDir()
+
+	for _, options := range []ScaffoldOptions{{ID: "Reading"}, {ID: "../escape"}, {ID: ""}, {ID: "notes", Name: `say "hi"`}} {
+		if _, err := Scaffold(parent, options); err == nil {
+			t.Errorf("expected an error for %+v", options)
+		}
+	}
+	if entries, _ := os.ReadDir(parent); len(entries) != 0 {
+		t.Errorf("expected nothing written for invalid input, got %d entries", len(entries))
+	}
+}
+
+func TestScaffold_GeneratedPluginBuildsAndPasses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and tests the generated plugin")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	// Generate inside this module so the plugin builds against this tree's SDK
+	// without fetching it; go ./... patterns skip testdata.
+	if err := os.MkdirAll("testdata", 0755); err != nil {
+		t.Fatal(err)
+	}
+	parent, err := os.MkdirTemp("testdata", "scaffold-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(parent)
+		os.Remove("testdata")
+	})
+
+	result, err := Scaffold(parent, ScaffoldOptions{ID: "reading-list"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, args := range [][]string{{"vet", "./backend/..."}, {"test", "-count=1", "./backend/..."}} {
+		cmd := exec.Command(goTool, args...)
+		cmd.Dir = result.Dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// SecretsEnv carries the secrets a plugin's settings refer to, as a JSON object
+// of name to value, into its process.
+const SecretsEnv = "CORTEX_PLUGIN_SECRETS"
+
+// secretReferenceKey marks a settings value that refers to a stored secret:
+// {"$secret": "github-token"}.
+const secretReferenceKey = "$secret"
+
+// ErrSecretNotFound is returned by a SecretStore for a name it does not hold.
+var ErrSecretNotFound = errors.New("secret not found")
+
+var secretNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// SecretStore holds secrets plugin settings refer to by name, such as an API
+// token. internal/secrets implements it.
+type SecretStore interface {
+	Secret(name string) (string, error)
+}
+
+// ValidSecretName reports whether name can name a secret: up to 64 lowercase
+// letters, digits, dashes and underscores.
+func ValidSecretName(name string) bool {
+	return secretNamePattern.MatchString(name)
+}
+
+// SecretReferences returns the names of the secrets settings refer to, sorted
+// and without duplicates. A reference is an object with a single "$secret"
+// key, anywhere in the settings.
+func SecretReferences(settings []byte) ([]string, error) {
+	var value interface{}
+	if err := json.Unmarshal(settings, &value); err != nil {
+		return nil, fmt.Errorf("parsing settings: %w", err)
+	}
+
+	found := make(map[string]bool)
+	var walk func(value interface{}) error
+	walk = func(value interface{}) error {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			if reference, ok := typed[secretReferenceKey]; ok && len(typed) == 1 {
+				name, ok := reference.(string)
+				if !ok || !ValidSecretName(name) {
+					return fmt.Errorf("invalid secret reference %v", reference)
+				}
+				found[name] = true
+				return nil
+			}
+			for _, child := range typed {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for _, child := range typed {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(value); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SetSecretStore passes each plugin the secrets its stored settings refer to,
+// read from store, when it starts.
+func (l *Loader) SetSecretStore(store SecretStore) {
+	l.secrets = store
+}
+
+// secretsEnvironment returns the SecretsEnv entry for plugin id, or "" when its
+// settings refer to no secrets. Secrets that cannot be read are logged and
+// left out, so the plugin still starts.
+func (l *Loader) secretsEnvironment(id string) string {
+	if l.secrets == nil || l.settingsStore == nil {
+		return ""
+	}
+
+	settings, _, found, err := l.settingsStore.GetPluginSettings(id)
+	if err != nil {
+		slog.Warn("reading plugin settings", "plugin", id, "error", err)
+		return ""
+	}
+	if !found {
+		return ""
+	}
+
+	names, err := SecretReferences(settings)
+	if err != nil {
+		slog.Warn("reading secret references from plugin settings", "plugin", id, "error", err)
+		return ""
+	}
+
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := l.secrets.Secret(name)
+		if err != nil {
+			slog.Warn("plugin settings refer to a secret that cannot be read", "plugin", id, "secret", name, "error", err)
+			continue
+		}
+		values[name] = value
+	}
+	if len(values) == 0 {
+		return ""
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		slog.Warn("encoding plugin secrets", "plugin", id, "error", err)
+		return ""
+	}
+	return SecretsEnv + "=" + string(encoded)
+}
+
+var (
+	environmentSecretsOnce sync.Once
+	environmentSecrets     map[string]string
+)
+
+// SecretFromEnvironment returns the secret named name that the host passed in
+// SecretsEnv. It is called from the plugin process.
+func SecretFromEnvironment(name string) (string, bool) {
+	environmentSecretsOnce.Do(func() {
+		environmentSecrets = make(map[string]string)
+		if value := os.Getenv(SecretsEnv); value != "" {
+			if err := json.Unmarshal([]byte(value), &environmentSecrets); err != nil {
+				slog.Warn("parsing secrets passed by the host", "error", err)
+			}
+		}
+	})
+
+	value, ok := environmentSecrets[name]
+	return value, ok
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// memorySecrets is an in-memory SecretStore.
+type memorySecrets map[string]string
+
+func (s memorySecrets) Secret(name string) (string, error) {
+	value, ok := s[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func TestSecretReferences(t *testing.T) {
+	names, err := SecretReferences([]byte(`{
+		"github": {"token": {"$secret": "github-token"}, "owner": "alvarotorresc"},
+		"feeds": [{"key": {"$secret": "price-api"}}, {"key": {"$secret": "github-token"}}],
+		"literal": {"$secret": "not-a-reference", "other": true}
+	}`))
+	if err != nil {
+		t.Fatalf("SecretReferences failed: %v", err)
+	}
+	if strings.Join(names, ",") != "github-token,price-api" {
+		t.Errorf("expected github-token and price-api, got %v", names)
+	}
+
+	for _, settings := range []string{`{"token": {"$secret": 42}}`, `{"token": {"$secret": "Bad Name"}}`, `not json`} {
+		if _, err := SecretReferences([]byte(settings)); err == nil {
+			t.Errorf("expected an error for %s", settings)
+		}
+	}
+}
+
+func TestSecretsEnvironment_PassesReferencedSecrets(t *testing.T) {
+	store := newMemorySettingsStore()
+	store.settings["prices"] = []byte(`{"api_key": {"$secret": "price-api"}, "backup": {"$secret": "missing"}}`)
+
+	loader := newSettingsLoader(store)
+	if got := loader.secretsEnvironment("prices"); got != "" {
+		t.Errorf("expected no secrets without a secret store, got %q", got)
+	}
+
+	loader.SetSecretStore(memorySecrets{"price-api": "k3y", "github-token": "ghp_unrelated"})
+
+	entry := loader.secretsEnvironment("prices")
+	value, ok := strings.CutPrefix(entry, SecretsEnv+"=")
+	if !ok {
+		t.Fatalf("expected a %s entry, got %q", SecretsEnv, entry)
+	}
+	var secrets map[string]string
+	if err := json.Unmarshal([]byte(value), &secrets); err != nil {
+		t.Fatalf("parsing secrets: %v", err)
+	}
+	if len(secrets) != 1 || secrets["price-api"] != "k3y" {
+		t.Errorf("expected only the referenced secret, got %v", secrets)
+	}
+
+	if got := loader.secretsEnvironment("notes"); got != "" {
+		t.Errorf("expected no secrets for a plugin without settings, got %q", got)
+	}
+}
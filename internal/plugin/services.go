@@ -0,0 +1,292 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+const (
+	// MaxValueSize caps one value in a plugin's key-value store.
+	MaxValueSize = 64 << 10
+	// maxValueKeyLength bounds the keys of a plugin's key-value store.
+	maxValueKeyLength = 200
+	// maxLogMessageLength and maxLogAttributes bound a record sent to the host's log.
+	maxLogMessageLength = 4000
+	maxLogAttributes    = 32
+)
+
+// ErrValueTooLarge is returned for values larger than MaxValueSize.
+var ErrValueTooLarge = fmt.Errorf("value exceeds %d bytes", MaxValueSize)
+
+// ValueStore keeps the key-value store of every plugin. The host database
+// implements it. Each plugin only sees its own keys.
+type ValueStore interface {
+	PluginValue(pluginID string, key string) ([]byte, bool, error)
+	SetPluginValue(pluginID string, key string, value []byte) error
+	DeletePluginValue(pluginID string, key string) error
+	PluginKeys(pluginID string, prefix string) ([]string, error)
+}
+
+// HostServices is a plugin's handle on the host, passed to plugins that
+// implement HostUser. Its calls go over the plugin's existing connection to
+// the host, so plugins need no config files or timers of their own.
+type HostServices struct {
+	logger *slog.Logger
+}
+
+// newHostServices returns the handle given to HostUser plugins.
+func newHostServices() *HostServices {
+	return &HostServices{logger: slog.New(&hostLogHandler{})}
+}
+
+// --- Host side ---
+
+func (s *hostServer) GetValue(ctx context.Context, request *pb.ValueRequest) (*pb.ValueResponse, error) {
+	if err := s.checkValueKey(request.Key); err != nil {
+		return nil, err
+	}
+
+	value, found, err := s.values.PluginValue(s.pluginID, request.Key)
+	if err != nil {
+		slog.Error("reading plugin value", "plugin", s.pluginID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to read value")
+	}
+	return &pb.ValueResponse{Value: value, Found: found}, nil
+}
+
+func (s *hostServer) SetValue(ctx context.Context, request *pb.ValueRequest) (*pb.Empty, error) {
+	if err := s.checkValueKey(request.Key); err != nil {
+		return nil, err
+	}
+	if len(request.Value) > MaxValueSize {
+		return nil, status.Error(codes.InvalidArgument, ErrValueTooLarge.Error())
+	}
+
+	if err := s.values.SetPluginValue(s.pluginID, request.Key, request.Value); err != nil {
+		slog.Error("storing plugin value", "plugin", s.pluginID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to store value")
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *hostServer) DeleteValue(ctx context.Context, request *pb.ValueRequest) (*pb.Empty, error) {
+	if err := s.checkValueKey(request.Key); err != nil {
+		return nil, err
+	}
+
+	if err := s.values.DeletePluginValue(s.pluginID, request.Key); err != nil {
+		slog.Error("deleting plugin value", "plugin", s.pluginID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to delete value")
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *hostServer) ListKeys(ctx context.Context, request *pb.KeysRequest) (*pb.KeyList, error) {
+	if s.values == nil {
+		return nil, status.Error(codes.Unavailable, "host has no value store")
+	}
+
+	keys, err := s.values.PluginKeys(s.pluginID, request.Prefix)
+	if err != nil {
+		slog.Error("listing plugin keys", "plugin", s.pluginID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to list keys")
+	}
+	return &pb.KeyList{Keys: keys}, nil
+}
+
+// checkValueKey rejects calls without a value store or with an invalid key.
+func (s *hostServer) checkValueKey(key string) error {
+	if s.values == nil {
+		return status.Error(codes.Unavailable, "host has no value store")
+	}
+	if key == "" || len(key) > maxValueKeyLength {
+		return status.Errorf(codes.InvalidArgument, "key must be between 1 and %d characters", maxValueKeyLength)
+	}
+	return nil
+}
+
+func (s *hostServer) Log(ctx context.Context, request *pb.LogRequest) (*pb.Empty, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(request.Level)); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown log level %q", request.Level)
+	}
+	if len(request.Message) > maxLogMessageLength {
+		return nil, status.Errorf(codes.InvalidArgument, "message must be at most %d characters", maxLogMessageLength)
+	}
+	if len(request.Attributes) > maxLogAttributes {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d attributes can be logged", maxLogAttributes)
+	}
+
+	keys := make([]string, 0, len(request.Attributes))
+	for key := range request.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attributes := make([]any, 0, 2+2*len(keys))
+	attributes = append(attributes, "plugin", s.pluginID)
+	for _, key := range keys {
+		attributes = append(attributes, key, request.Attributes[key])
+	}
+	slog.Log(ctx, level, request.Message, attributes...)
+	return &pb.Empty{}, nil
+}
+
+// --- Plugin side ---
+
+// Value returns the value stored under key in the plugin's key-value store.
+// found is false when there is none.
+func (h *HostServices) Value(key string) (value []byte, found bool, err error) {
+	client, err := hostClient()
+	if err != nil {
+		return nil, false, err
+	}
+
+	response, err := client.GetValue(context.Background(), &pb.ValueRequest{Key: key})
+	if err != nil {
+		return nil, false, translateError(err)
+	}
+	return response.Value, response.Found, nil
+}
+
+// SetValue stores value under key in the plugin's key-value store, replacing
+// any value stored there. Values are at most MaxValueSize bytes.
+func (h *HostServices) SetValue(key string, value []byte) error {
+	if len(value) > MaxValueSize {
+		return ErrValueTooLarge
+	}
+
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.SetValue(context.Background(), &pb.ValueRequest{Key: key, Value: value}); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// DeleteValue removes the value stored under key, if any.
+func (h *HostServices) DeleteValue(key string) error {
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.DeleteValue(context.Background(), &pb.ValueRequest{Key: key}); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// Keys returns the keys of the plugin's key-value store that start with
+// prefix, in order; an empty prefix lists them all.
+func (h *HostServices) Keys(prefix string) ([]string, error) {
+	client, err := hostClient()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.ListKeys(context.Background(), &pb.KeysRequest{Prefix: prefix})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if response.Keys == nil {
+		return []string{}, nil
+	}
+	return response.Keys, nil
+}
+
+// Notify adds a notification to the host's notification center, like
+// SendNotification. The plugin must declare the notifications permission.
+func (h *HostServices) Notify(title string, body string, urgent bool) error {
+	return SendNotification(title, body, urgent)
+}
+
+// Logger returns a logger whose records go to the host's log, tagged with the
+// plugin's ID, instead of the plugin's captured output.
+func (h *HostServices) Logger() *slog.Logger {
+	return h.logger
+}
+
+// hostLogHandler is a slog.Handler that sends records to the host's log.
+// Attributes are flattened to strings, with group names as key prefixes.
+type hostLogHandler struct {
+	attributes []slog.Attr
+	group      string
+}
+
+func (h *hostLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h *hostLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	attributes := make(map[string]string)
+	for _, attribute := range h.attributes {
+		flattenLogAttr(attributes, "", attribute)
+	}
+	record.Attrs(func(attribute slog.Attr) bool {
+		flattenLogAttr(attributes, h.group, attribute)
+		return true
+	})
+
+	_, err = client.Log(ctx, &pb.LogRequest{Level: record.Level.String(), Message: record.Message, Attributes: attributes})
+	return translateError(err)
+}
+
+func (h *hostLogHandler) WithAttrs(attributes []slog.Attr) slog.Handler {
+	handler := &hostLogHandler{group: h.group, attributes: append([]slog.Attr{}, h.attributes...)}
+	for _, attribute := range attributes {
+		if h.group != "" {
+			attribute.Key = h.group + attribute.Key
+		}
+		handler.attributes = append(handler.attributes, attribute)
+	}
+	return handler
+}
+
+func (h *hostLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &hostLogHandler{attributes: h.attributes, group: h.group + name + "."}
+}
+
+// flattenLogAttr adds attribute to attributes, expanding groups into
+// dotted keys.
+func flattenLogAttr(attributes map[string]string, prefix string, attribute slog.Attr) {
+	value := attribute.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		if attribute.Key != "" {
+			attributes[prefix+attribute.Key] = value.String()
+		}
+		return
+	}
+
+	if attribute.Key != "" {
+		prefix += attribute.Key + "."
+	}
+	for _, member := range value.Group() {
+		flattenLogAttr(attributes, prefix, member)
+	}
+}
+
+// useHost hands impl its HostServices if it implements HostUser.
+func useHost(impl CortexPlugin) {
+	if user, ok := impl.(HostUser); ok {
+		user.UseHost(newHostServices())
+	}
+}
@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+// memoryValues is an in-memory ValueStore.
+type memoryValues struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (m *memoryValues) PluginValue(pluginID string, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[pluginID+"/"+key]
+	return value, ok, nil
+}
+
+func (m *memoryValues) SetPluginValue(pluginID string, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = make(map[string][]byte)
+	}
+	m.values[pluginID+"/"+key] = value
+	return nil
+}
+
+func (m *memoryValues) DeletePluginValue(pluginID string, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, pluginID+"/"+key)
+	return nil
+}
+
+func (m *memoryValues) PluginKeys(pluginID string, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := []string{}
+	for stored := range m.values {
+		if key, ok := strings.CutPrefix(stored, pluginID+"/"); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// hostUserPlugin keeps the HostServices it is handed.
+type hostUserPlugin struct {
+	fakePlugin
+	host *HostServices
+}
+
+func (p *hostUserPlugin) UseHost(host *HostServices) { p.host = host }
+
+func TestHostServices_HandedToHostUsers(t *testing.T) {
+	impl := &hostUserPlugin{}
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: impl, PluginID: "quick-notes"})
+
+	if impl.host == nil {
+		t.Fatal("expected UseHost to be called when the host connects")
+	}
+	if impl.host.Logger() == nil {
+		t.Error("expected the host services to carry a logger")
+	}
+}
+
+func TestHostServices_ValuesRoundTrip(t *testing.T) {
+	values := &memoryValues{}
+	_ = values.SetPluginValue("finance-tracker", "cursor", []byte("other plugin"))
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "quick-notes", Values: values})
+	host := newHostServices()
+
+	if _, found, err := host.Value("cursor"); err != nil || found {
+		t.Fatalf("expected no value yet, got found=%v, %v", found, err)
+	}
+	if err := host.SetValue("cursor", []byte("42")); err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	if err := host.SetValue("sync.last", []byte("yesterday")); err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+
+	value, found, err := host.Value("cursor")
+	if err != nil || !found || string(value) != "42" {
+		t.Errorf("expected the plugin's own value, got %q, found=%v, %v", value, found, err)
+	}
+	if keys, err := host.Keys("sync."); err != nil || len(keys) != 1 || keys[0] != "sync.last" {
+		t.Errorf("expected only sync.last for the prefix, got %v, %v", keys, err)
+	}
+
+	if err := host.DeleteValue("cursor"); err != nil {
+		t.Fatalf("DeleteValue failed: %v", err)
+	}
+	if keys, err := host.Keys(""); err != nil || len(keys) != 1 {
+		t.Errorf("expected one key left, got %v, %v", keys, err)
+	}
+}
+
+func TestHostServices_ValueLimits(t *testing.T) {
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "quick-notes", Values: &memoryValues{}})
+	host := newHostServices()
+
+	if err := host.SetValue("big", make([]byte, MaxValueSize+1)); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("expected ErrValueTooLarge, got %v", err)
+	}
+	for _, key := range []string{"", strings.Repeat("k", maxValueKeyLength+1)} {
+		if err := host.SetValue(key, []byte("v")); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for a %d-character key, got %v", len(key), err)
+		}
+	}
+}
+
+func TestHostServices_ValuesWithoutStore(t *testing.T) {
+	connectOverGRPC(t, "quick-notes", nil)
+
+	if _, _, err := newHostServices().Value("cursor"); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable without a value store, got %v", err)
+	}
+}
+
+func TestHostServices_Logger(t *testing.T) {
+	connectOverGRPC(t, "quick-notes", nil)
+	logger := newHostServices().Logger()
+
+	if err := logger.Handler().Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "synced", 0)); err != nil {
+		t.Errorf("expected the record to reach the host, got %v", err)
+	}
+
+	client, err := hostClient()
+	if err != nil {
+		t.Fatalf("hostClient failed: %v", err)
+	}
+	if _, err := client.Log(t.Context(), &pb.LogRequest{Level: "LOUD", Message: "synced"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown level, got %v", err)
+	}
+}
+
+func TestFlattenLogAttr(t *testing.T) {
+	attributes := make(map[string]string)
+	flattenLogAttr(attributes, "", slog.Group("sync", "count", 3))
+
+	if attributes["sync.count"] != "3" || len(attributes) != 1 {
+		t.Errorf("expected sync.count=3, got %v", attributes)
+	}
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/alvarotorresc/cortex/internal/plugin/proto"
+)
+
+// --- Host side ---
+
+func (s *hostServer) GetSettings(ctx context.Context, request *pb.Empty) (*pb.Settings, error) {
+	if s.settings == nil {
+		return nil, status.Error(codes.Unavailable, "host has no settings store")
+	}
+
+	settings, _, found, err := s.settings.GetPluginSettings(s.pluginID)
+	if err != nil {
+		slog.Error("reading plugin settings", "plugin", s.pluginID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to read settings")
+	}
+	if !found {
+		settings = []byte("{}")
+	}
+	return &pb.Settings{SettingsJson: settings}, nil
+}
+
+// NotifySettingsChanged hands a running plugin its new settings if it
+// implements SettingsListener. Plugins without the hook, or not running, read
+// them on their next load.
+func (l *Loader) NotifySettingsChanged(id string, settings []byte) error {
+	if entry, ok := l.registry.Get(id); !ok || entry.Plugin == nil {
+		return nil
+	}
+
+	entry, err := l.Acquire(id)
+	if err != nil {
+		return err
+	}
+
+	listener, ok := entry.Plugin.(SettingsListener)
+	if !ok {
+		l.Release(id, entry, nil)
+		return nil
+	}
+
+	err = listener.OnSettingsChanged(settings)
+	if isNotImplemented(err) {
+		l.Release(id, entry, nil)
+		return nil
+	}
+	l.Release(id, entry, err)
+	return err
+}
+
+// OnSettingsChanged passes the plugin its new settings.
+// It returns ErrNotImplemented if the plugin does not implement SettingsListener.
+func (c *GRPCClient) OnSettingsChanged(settings []byte) error {
+	if !c.sdk.Supports(CapabilitySettingsListener) {
+		return notCapable("OnSettingsChanged")
+	}
+	_, err := c.client.SettingsChanged(context.Background(), &pb.Settings{SettingsJson: settings})
+	return translateError(err)
+}
+
+// --- Plugin side ---
+
+func (s *grpcServer) SettingsChanged(ctx context.Context, request *pb.Settings) (*pb.Empty, error) {
+	listener, ok := s.impl.(SettingsListener)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "plugin does not implement OnSettingsChanged")
+	}
+
+	if err := listener.OnSettingsChanged(request.SettingsJson); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+// GetSettings decodes the settings saved for the plugin in the dashboard into
+// into, typically a pointer to a struct; a plugin without saved settings gets
+// an empty object. Secret values are left as their {"$secret": name}
+// references, read with Secret. It returns an error if called before the host
+// has connected.
+func GetSettings(into interface{}) error {
+	client, err := hostClient()
+	if err != nil {
+		return err
+	}
+
+	response, err := client.GetSettings(context.Background(), &pb.Empty{})
+	if err != nil {
+		return translateError(err)
+	}
+	if err := json.Unmarshal(response.SettingsJson, into); err != nil {
+		return fmt.Errorf("decoding settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listeningPlugin records the settings it is handed.
+type listeningPlugin struct {
+	fakePlugin
+	received []string
+	fail     bool
+}
+
+func (p *listeningPlugin) OnSettingsChanged(settings []byte) error {
+	if p.fail {
+		return errors.New("unknown currency")
+	}
+	p.received = append(p.received, string(settings))
+	return nil
+}
+
+func TestGetSettings_ReadsStoredSettings(t *testing.T) {
+	store := newMemorySettingsStore()
+	_ = store.SavePluginSettings("finance-tracker", []byte(`{"default_currency":"USD"}`), "1.0.0")
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "finance-tracker", Settings: store})
+
+	var settings struct {
+		DefaultCurrency string `json:"default_currency"`
+	}
+	if err := GetSettings(&settings); err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if settings.DefaultCurrency != "USD" {
+		t.Errorf("expected the stored currency, got %q", settings.DefaultCurrency)
+	}
+}
+
+func TestGetSettings_WithoutStoredSettings(t *testing.T) {
+	connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "finance-tracker", Settings: newMemorySettingsStore()})
+
+	settings := map[string]string{}
+	if err := GetSettings(&settings); err != nil || len(settings) != 0 {
+		t.Errorf("expected empty settings, got %v, %v", settings, err)
+	}
+}
+
+func TestGetSettings_WithoutStore(t *testing.T) {
+	connectOverGRPC(t, "finance-tracker", nil)
+
+	var settings map[string]string
+	if err := GetSettings(&settings); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable without a settings store, got %v", err)
+	}
+}
+
+func TestNotifySettingsChanged_ReachesListener(t *testing.T) {
+	impl := &listeningPlugin{}
+	registry := NewRegistry()
+	loader := NewLoader("", "", registry)
+	client := connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: impl, PluginID: "finance-tracker"})
+	client.sdk = SDKInfo{Protocol: SDKProtocolVersion, Capabilities: capabilitiesOf(impl)}
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker"})
+	entry, _ := registry.Get("finance-tracker")
+	entry.Plugin = client
+
+	if err := loader.NotifySettingsChanged("finance-tracker", []byte(`{"default_currency":"USD"}`)); err != nil {
+		t.Fatalf("NotifySettingsChanged failed: %v", err)
+	}
+	if len(impl.received) != 1 || impl.received[0] != `{"default_currency":"USD"}` {
+		t.Errorf("expected the plugin to receive its settings, got %v", impl.received)
+	}
+
+	impl.fail = true
+	if err := loader.NotifySettingsChanged("finance-tracker", []byte(`{"default_currency":"XXX"}`)); err == nil {
+		t.Error("expected the plugin's error to be returned")
+	}
+}
+
+func TestNotifySettingsChanged_SkipsPluginsWithoutHook(t *testing.T) {
+	registry := NewRegistry()
+	loader := NewLoader("", "", registry)
+	client := connectPluginOverGRPC(t, &CortexGRPCPlugin{Impl: &fakePlugin{}, PluginID: "quick-notes"})
+	registry.Register("quick-notes", nil, &Manifest{ID: "quick-notes"})
+	entry, _ := registry.Get("quick-notes")
+	entry.Plugin = client
+
+	// Without capabilities the host calls the hook and the plugin reports it lacks it.
+	if err := loader.NotifySettingsChanged("quick-notes", []byte(`{}`)); err != nil {
+		t.Errorf("expected plugins without the hook to be skipped, got %v", err)
+	}
+	if err := loader.NotifySettingsChanged("not-loaded", []byte(`{}`)); err != nil {
+		t.Errorf("expected plugins that are not loaded to be skipped, got %v", err)
+	}
+	if registry.Breaker("quick-notes").State() != BreakerClosed {
+		t.Errorf("expected a missing hook not to count as a failure, got %s", registry.Breaker("quick-notes").State())
+	}
+}
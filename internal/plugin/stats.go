@@ -0,0 +1,266 @@
+package plugin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+)
+
+// ErrNoDatabase is returned by DatabaseStats for plugins without a database,
+// either because they lack a db permission or have not created it yet.
+var ErrNoDatabase = errors.New("plugin has no database")
+
+// DatabaseStats describes how much a plugin stores and where the space goes.
+type DatabaseStats struct {
+	PluginID string `json:"plugin_id"`
+	// FileSize is the size on disk, including the WAL for plaintext databases.
+	FileSize  int64 `json:"file_size"`
+	Encrypted bool  `json:"encrypted"`
+	// LastWriteAt is the modification time of the newest database file.
+	LastWriteAt string       `json:"last_write_at"`
+	PageSize    int64        `json:"page_size"`
+	FreePages   int64        `json:"free_pages"`
+	Tables      []TableStats `json:"tables"`
+	Indexes     []IndexStats `json:"indexes"`
+}
+
+// TableStats is one table of a plugin database. Size is measured by the
+// dbstat virtual table and counts every page of the table's b-tree.
+type TableStats struct {
+	Name  string `json:"name"`
+	Rows  int64  `json:"rows"`
+	Size  int64  `json:"size"`
+	Pages int64  `json:"pages"`
+}
+
+// IndexStats is one index of a plugin database, including the automatic
+// indexes behind UNIQUE and PRIMARY KEY constraints.
+type IndexStats struct {
+	Name    string   `json:"name"`
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Size    int64    `json:"size"`
+	Pages   int64    `json:"pages"`
+}
+
+// DatabaseStats reads the storage statistics of plugin id's database from
+// disk. The plugin keeps running: plaintext databases are opened read-only,
+// and encrypted ones are inspected through an in-memory copy of the last
+// version written to disk.
+func (l *Loader) DatabaseStats(id string) (*DatabaseStats, error) {
+	entry, ok := l.registry.Get(id)
+	if !ok || isCanaryKey(id) {
+		return nil, ErrPluginNotFound
+	}
+	if !needsDatabase(entry.Manifest) {
+		return nil, ErrNoDatabase
+	}
+
+	path := filepath.Join(l.dataDir, "plugins", id, "db.sqlite")
+	stats := &DatabaseStats{PluginID: id, Tables: []TableStats{}, Indexes: []IndexStats{}}
+
+	var lastWrite time.Time
+	stats.FileSize, lastWrite, stats.Encrypted = databaseFileSize(path)
+	if lastWrite.IsZero() {
+		return nil, ErrNoDatabase
+	}
+	stats.LastWriteAt = lastWrite.UTC().Format(time.RFC3339)
+
+	database, err := l.openForStats(id, path, stats.Encrypted)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	if err := collectDatabaseStats(database, stats); err != nil {
+		return nil, fmt.Errorf("reading %s database statistics: %w", id, err)
+	}
+	return stats, nil
+}
+
+// DatabaseSize returns the size on disk of plugin id's database, including
+// the WAL for plaintext databases, without opening it.
+func (l *Loader) DatabaseSize(id string) (int64, error) {
+	entry, ok := l.registry.Get(id)
+	if !ok || isCanaryKey(id) {
+		return 0, ErrPluginNotFound
+	}
+	if !needsDatabase(entry.Manifest) {
+		return 0, ErrNoDatabase
+	}
+
+	size, lastWrite, _ := databaseFileSize(filepath.Join(l.dataDir, "plugins", id, "db.sqlite"))
+	if lastWrite.IsZero() {
+		return 0, ErrNoDatabase
+	}
+	return size, nil
+}
+
+// databaseFileSize adds up the files of the database at path: the encrypted
+// file when there is one, otherwise the database and its WAL. lastWrite is
+// zero when none of them exists.
+func databaseFileSize(path string) (size int64, lastWrite time.Time, encrypted bool) {
+	files := []string{path, path + "-wal"}
+	if _, err := os.Stat(atrest.EncryptedPath(path)); err == nil {
+		encrypted = true
+		files = []string{atrest.EncryptedPath(path)}
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		size += info.Size()
+		if info.ModTime().After(lastWrite) {
+			lastWrite = info.ModTime()
+		}
+	}
+	return size, lastWrite, encrypted
+}
+
+func (l *Loader) openForStats(id string, path string, encrypted bool) (*sql.DB, error) {
+	if encrypted {
+		if l.databaseKey == nil {
+			return nil, atrest.ErrEncryptedDatabase
+		}
+		return atrest.OpenSnapshot(atrest.EncryptedPath(path), atrest.PluginKey(l.databaseKey, id))
+	}
+
+	database, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return database, nil
+}
+
+func collectDatabaseStats(database *sql.DB, stats *DatabaseStats) error {
+	if err := database.QueryRow("PRAGMA page_size").Scan(&stats.PageSize); err != nil {
+		return fmt.Errorf("reading page size: %w", err)
+	}
+	if err := database.QueryRow("PRAGMA freelist_count").Scan(&stats.FreePages); err != nil {
+		return fmt.Errorf("reading free pages: %w", err)
+	}
+
+	sizes, err := btreeSizes(database)
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.Query(`SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY name`)
+	if err != nil {
+		return fmt.Errorf("listing tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning table: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("listing tables: %w", err)
+	}
+
+	for _, table := range tables {
+		tableStats := TableStats{Name: table, Size: sizes[table].size, Pages: sizes[table].pages}
+		// Virtual tables have no b-tree and may not support counting.
+		if err := database.QueryRow("SELECT COUNT(*) FROM " + quoteIdentifier(table)).Scan(&tableStats.Rows); err != nil {
+			tableStats.Rows = -1
+		}
+		stats.Tables = append(stats.Tables, tableStats)
+
+		indexes, err := tableIndexes(database, table, sizes)
+		if err != nil {
+			return err
+		}
+		stats.Indexes = append(stats.Indexes, indexes...)
+	}
+
+	return nil
+}
+
+type btreeSize struct {
+	size  int64
+	pages int64
+}
+
+// btreeSizes sums the pages of every table and index with the dbstat
+// virtual table.
+func btreeSizes(database *sql.DB) (map[string]btreeSize, error) {
+	rows, err := database.Query("SELECT name, SUM(pgsize), COUNT(*) FROM dbstat GROUP BY name")
+	if err != nil {
+		return nil, fmt.Errorf("reading dbstat: %w", err)
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]btreeSize)
+	for rows.Next() {
+		var name string
+		var size btreeSize
+		if err := rows.Scan(&name, &size.size, &size.pages); err != nil {
+			return nil, fmt.Errorf("scanning dbstat: %w", err)
+		}
+		sizes[name] = size
+	}
+	return sizes, rows.Err()
+}
+
+func tableIndexes(database *sql.DB, table string, sizes map[string]btreeSize) ([]IndexStats, error) {
+	rows, err := database.Query("SELECT name, \"unique\" FROM pragma_index_list(?) ORDER BY name", table)
+	if err != nil {
+		return nil, fmt.Errorf("listing indexes of %s: %w", table, err)
+	}
+	var indexes []IndexStats
+	for rows.Next() {
+		index := IndexStats{Table: table, Columns: []string{}}
+		if err := rows.Scan(&index.Name, &index.Unique); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning index: %w", err)
+		}
+		index.Size, index.Pages = sizes[index.Name].size, sizes[index.Name].pages
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("listing indexes of %s: %w", table, err)
+	}
+
+	for i := range indexes {
+		columns, err := database.Query("SELECT name FROM pragma_index_info(?) ORDER BY seqno", indexes[i].Name)
+		if err != nil {
+			return nil, fmt.Errorf("reading columns of %s: %w", indexes[i].Name, err)
+		}
+		for columns.Next() {
+			var column sql.NullString
+			if err := columns.Scan(&column); err != nil {
+				columns.Close()
+				return nil, fmt.Errorf("scanning index column: %w", err)
+			}
+			// Expression columns have no name.
+			if column.Valid {
+				indexes[i].Columns = append(indexes[i].Columns, column.String)
+			} else {
+				indexes[i].Columns = append(indexes[i].Columns, "<expression>")
+			}
+		}
+		columns.Close()
+	}
+
+	return indexes, nil
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
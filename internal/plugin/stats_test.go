@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+)
+
+func TestLoaderDatabaseStats_Encrypted(t *testing.T) {
+	dataDir := t.TempDir()
+	registry := NewRegistry()
+	registry.Register("vault", nil, &Manifest{ID: "vault", Permissions: []string{PermissionDBWrite}})
+
+	master := bytes.Repeat([]byte{3}, atrest.KeySize)
+	databasePath := filepath.Join(dataDir, "plugins", "vault", "db.sqlite")
+	if err := os.MkdirAll(filepath.Dir(databasePath), 0755); err != nil {
+		t.Fatalf("creating plugin directory: %v", err)
+	}
+	database, err := atrest.OpenDatabase(databasePath, atrest.PluginKey(master, "vault"))
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	for _, statement := range []string{
+		"CREATE TABLE secrets (name TEXT NOT NULL)",
+		"INSERT INTO secrets (name) VALUES ('a'), ('b')",
+	} {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+	if err := database.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	loader := NewLoader(t.TempDir(), dataDir, registry)
+	if _, err := loader.DatabaseStats("vault"); err == nil {
+		t.Fatal("expected an error without the database key")
+	}
+
+	loader.SetDatabaseKey(master)
+	stats, err := loader.DatabaseStats("vault")
+	if err != nil {
+		t.Fatalf("DatabaseStats failed: %v", err)
+	}
+	if !stats.Encrypted || len(stats.Tables) != 1 || stats.Tables[0].Rows != 2 {
+		t.Errorf("unexpected statistics: %+v", stats)
+	}
+	if _, err := os.Stat(databasePath); !os.IsNotExist(err) {
+		t.Error("expected reading statistics not to write a plaintext copy")
+	}
+}
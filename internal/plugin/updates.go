@@ -0,0 +1,258 @@
+package plugin
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// releaseCheckTimeout bounds fetching one plugin's release manifest.
+	releaseCheckTimeout = 30 * time.Second
+	// maxReleaseSize caps a release manifest.
+	maxReleaseSize = 1 << 20
+)
+
+// Errors returned by the update checker. The HTTP layer maps them to error codes.
+var (
+	ErrNoUpdateURL    = errors.New("plugin declares no update_url")
+	ErrUpToDate       = errors.New("plugin is up to date")
+	ErrInvalidRelease = errors.New("invalid release manifest")
+	ErrUpdateRestored = errors.New("updated plugin failed to load, previous version restored")
+)
+
+// Release is the latest build of a plugin, as published at the update_url of
+// its manifest:
+//
+//	{"version": "1.3.0", "url": "https://example.com/weather.cortexplugin", "sha256": "...", "signature": "..."}
+//
+// The archive is installed and verified like any other remote install.
+type Release struct {
+	Version string `json:"version"`
+	InstallSource
+}
+
+// Update is a newer release found for a loaded plugin.
+type Update struct {
+	Version   string    `json:"version"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// checkedRelease is the release last fetched for a plugin.
+type checkedRelease struct {
+	release   Release
+	checkedAt time.Time
+}
+
+// UpdateChecker compares loaded plugins against the release manifests their
+// manifests point to. Plugins without an update_url are never checked.
+type UpdateChecker struct {
+	registry *Registry
+	client   *http.Client
+
+	mu       sync.RWMutex
+	releases map[string]checkedRelease
+}
+
+// NewUpdateChecker creates an update checker for the plugins in registry.
+func NewUpdateChecker(registry *Registry) *UpdateChecker {
+	return &UpdateChecker{
+		registry: registry,
+		client:   &http.Client{Timeout: releaseCheckTimeout},
+		releases: make(map[string]checkedRelease),
+	}
+}
+
+// Start checks every plugin right away and then every interval, until ctx is
+// cancelled.
+func (c *UpdateChecker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.CheckAll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll fetches the release manifest of every loaded plugin that has an
+// update_url. A plugin whose check fails keeps the result of its last one.
+func (c *UpdateChecker) CheckAll() {
+	for _, manifest := range c.registry.List() {
+		if manifest.UpdateURL == "" {
+			continue
+		}
+		release, err := c.fetch(manifest)
+		if err != nil {
+			slog.Warn("checking plugin for updates failed", "plugin", manifest.ID, "error", err)
+			continue
+		}
+		if compareVersions(release.Version, manifest.Version) > 0 {
+			slog.Info("plugin update available", "plugin", manifest.ID, "version", manifest.Version, "latest", release.Version)
+		}
+	}
+}
+
+// Available returns the update found for a plugin by the last check, if that
+// release is newer than the version running now.
+func (c *UpdateChecker) Available(manifest *Manifest) (*Update, bool) {
+	c.mu.RLock()
+	checked, ok := c.releases[manifest.ID]
+	c.mu.RUnlock()
+
+	if !ok || compareVersions(checked.release.Version, manifest.Version) <= 0 {
+		return nil, false
+	}
+	return &Update{Version: checked.release.Version, CheckedAt: checked.checkedAt}, true
+}
+
+// Latest fetches a plugin's release manifest now and returns the release if
+// it is newer than the running version, or ErrUpToDate.
+func (c *UpdateChecker) Latest(manifest *Manifest) (*Release, error) {
+	release, err := c.fetch(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if compareVersions(release.Version, manifest.Version) <= 0 {
+		return nil, fmt.Errorf("%w: %s is the latest release", ErrUpToDate, manifest.Version)
+	}
+	return release, nil
+}
+
+// fetch downloads and validates a plugin's release manifest and remembers it.
+func (c *UpdateChecker) fetch(manifest *Manifest) (*Release, error) {
+	if manifest.UpdateURL == "" {
+		return nil, ErrNoUpdateURL
+	}
+
+	response, err := c.client.Get(manifest.UpdateURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: release manifest returned status %d", ErrDownloadFailed, response.StatusCode)
+	}
+
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxReleaseSize)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRelease, err)
+	}
+	if release.Version == "" || release.URL == "" || release.SHA256 == "" {
+		return nil, fmt.Errorf("%w: version, url and sha256 are required", ErrInvalidRelease)
+	}
+
+	c.mu.Lock()
+	c.releases[manifest.ID] = checkedRelease{release: release, checkedAt: time.Now().UTC()}
+	c.mu.Unlock()
+	return &release, nil
+}
+
+// Upgrade replaces a loaded plugin with the unpacked build at build, as
+// staged by Installer.StageUpdate, and loads it, migrating its settings. If
+// the new build fails to load, the previous version is put back and loaded
+// instead. A running canary of the plugin is stopped.
+func (l *Loader) Upgrade(id string, build string) error {
+	if _, ok := l.registry.Get(id); !ok || isCanaryKey(id) {
+		return ErrPluginNotFound
+	}
+
+	if err := l.replaceBuild(id, build, ErrUpdateRestored); err != nil {
+		return err
+	}
+
+	slog.Info("plugin updated", "plugin", id)
+	return nil
+}
+
+// validateUpdateURL rejects an update_url that is not an http or https URL.
+func validateUpdateURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("invalid update_url %q: must be an http or https URL", raw)
+	}
+	return nil
+}
+
+// compareVersions orders two semantic versions such as "1.2.0" or
+// "v2.0.0-beta.1", returning -1, 0 or 1. Numeric parts compare as numbers,
+// missing ones count as 0, and a pre-release sorts before its release.
+func compareVersions(a string, b string) int {
+	// Build metadata takes no part in the order
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "+")
+
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+	if order := compareIdentifiers(strings.Split(coreA, "."), strings.Split(coreB, "."), "0"); order != 0 {
+		return order
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareIdentifiers(strings.Split(preA, "."), strings.Split(preB, "."), "")
+}
+
+// compareIdentifiers compares dot-separated version identifiers, filling the
+// shorter list with missing. Numeric identifiers sort before alphanumeric ones.
+func compareIdentifiers(a []string, b []string, missing string) int {
+	for i := range max(len(a), len(b)) {
+		x, y := missing, missing
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x == y {
+			continue
+		}
+		if x == "" {
+			return -1
+		}
+		if y == "" {
+			return 1
+		}
+
+		numberX, errX := strconv.Atoi(x)
+		numberY, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil:
+			if order := cmp.Compare(numberX, numberY); order != 0 {
+				return order
+			}
+		case errX == nil:
+			return -1
+		case errY == nil:
+			return 1
+		default:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
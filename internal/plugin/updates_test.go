@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.2.0", "1.10.0", -1},
+		{"2.0.0", "1.9.9", 1},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.0.0-beta", "1.0.0", -1},
+		{"1.0.0-alpha.2", "1.0.0-alpha.10", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := compareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestValidateUpdateURL(t *testing.T) {
+	for _, valid := range []string{"", "https://example.com/weather.json", "http://localhost:8000/release.json"} {
+		if err := validateUpdateURL(valid); err != nil {
+			t.Errorf("expected %q to be valid, got %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"ftp://example.com/release.json", "not a url", "https://"} {
+		if err := validateUpdateURL(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestUpdateChecker_AvailableFollowsRunningVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"version":"1.1.0","url":"https://example.com/weather.cortexplugin","sha256":"00"}`))
+	}))
+	defer server.Close()
+
+	registry := NewRegistry()
+	manifest := &Manifest{ID: "weather", Version: "1.0.0", UpdateURL: server.URL}
+	registry.Register("weather", nil, manifest)
+
+	checker := NewUpdateChecker(registry)
+	if _, ok := checker.Available(manifest); ok {
+		t.Fatal("expected no update before the first check")
+	}
+
+	checker.CheckAll()
+	update, ok := checker.Available(manifest)
+	if !ok || update.Version != "1.1.0" {
+		t.Fatalf("expected update 1.1.0, got %+v", update)
+	}
+
+	// Once the release is running, it is no longer offered
+	if _, ok := checker.Available(&Manifest{ID: "weather", Version: "1.1.0"}); ok {
+		t.Error("expected no update for the version already running")
+	}
+	if _, err := checker.Latest(&Manifest{ID: "weather", Version: "1.1.0", UpdateURL: server.URL}); err == nil {
+		t.Error("expected Latest to report the plugin up to date")
+	}
+}
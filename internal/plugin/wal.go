@@ -0,0 +1,203 @@
+package plugin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+)
+
+// systemNotificationSource is the notification source of the host's own
+// warnings about plugins, such as oversized WALs and failing routes.
+const systemNotificationSource = "system"
+
+// WALStats describes the write-ahead log of a plugin's plaintext database, as
+// seen by the host's periodic checks. Encrypted databases have no WAL on disk
+// and are not reported.
+type WALStats struct {
+	PluginID string `json:"plugin_id"`
+	// WALBytes is the size of the -wal file. SQLite reuses the file after a
+	// checkpoint instead of shrinking it, so it reflects the largest backlog
+	// since the plugin opened its database.
+	WALBytes int64 `json:"wal_bytes"`
+	// WALFrames is how many pages the WAL held at the last check.
+	WALFrames int64 `json:"wal_frames"`
+	// Checkpoints counts checks that found new frames in the WAL and copied
+	// all of them into the database.
+	Checkpoints int64 `json:"checkpoints"`
+	// BusyRetries counts checks that could not complete because a reader or
+	// writer held the database; they are retried at the next check.
+	BusyRetries      int64  `json:"busy_retries"`
+	LastCheckpointAt string `json:"last_checkpoint_at,omitempty"`
+	CheckedAt        string `json:"checked_at"`
+
+	// pending is set while the last check left frames behind.
+	pending bool
+}
+
+// MonitorWAL checks the WAL of every loaded plugin's database each interval
+// until ctx is done. Each check runs a passive checkpoint, which never waits
+// for or blocks the plugin's own connections. When a WAL grows past
+// warnBytes, a notification is sent once, until it drops below again.
+func (l *Loader) MonitorWAL(ctx context.Context, interval time.Duration, warnBytes int64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.checkWAL(warnBytes)
+		}
+	}
+}
+
+// WALStats returns the latest WAL statistics of every checked plugin database,
+// ordered by plugin ID.
+func (l *Loader) WALStats() []WALStats {
+	l.walMu.Lock()
+	defer l.walMu.Unlock()
+
+	stats := make([]WALStats, 0, len(l.walStats))
+	for _, entry := range l.walStats {
+		stats = append(stats, *entry)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].PluginID < stats[j].PluginID })
+	return stats
+}
+
+// checkWAL checks the WAL of every loaded plugin with a plaintext database.
+func (l *Loader) checkWAL(warnBytes int64) {
+	loaded := make(map[string]bool)
+	for _, manifest := range l.registry.List() {
+		if !needsDatabase(manifest) {
+			continue
+		}
+		path := filepath.Join(l.dataDir, "plugins", manifest.ID, "db.sqlite")
+		if _, err := os.Stat(atrest.EncryptedPath(path)); err == nil {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		loaded[manifest.ID] = true
+		l.checkPluginWAL(manifest, path, warnBytes)
+	}
+
+	// Counters of unloaded plugins start over if they are loaded again.
+	l.walMu.Lock()
+	for id := range l.walStats {
+		if !loaded[id] {
+			delete(l.walStats, id)
+			delete(l.walWarned, id)
+		}
+	}
+	l.walMu.Unlock()
+}
+
+func (l *Loader) checkPluginWAL(manifest *Manifest, path string, warnBytes int64) {
+	frames, checkpointed, busy, err := passiveCheckpoint(path)
+	if err != nil {
+		slog.Warn("checking plugin WAL", "plugin", manifest.ID, "error", err)
+		return
+	}
+
+	var walBytes int64
+	if info, err := os.Stat(path + "-wal"); err == nil {
+		walBytes = info.Size()
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	l.walMu.Lock()
+	if l.walStats == nil {
+		l.walStats = make(map[string]*WALStats)
+		l.walWarned = make(map[string]bool)
+	}
+	stats, ok := l.walStats[manifest.ID]
+	if !ok {
+		stats = &WALStats{PluginID: manifest.ID}
+		l.walStats[manifest.ID] = stats
+	}
+	switch {
+	case busy || checkpointed < frames:
+		stats.BusyRetries++
+		stats.pending = true
+	case frames > 0 && (frames != stats.WALFrames || stats.pending):
+		stats.Checkpoints++
+		stats.LastCheckpointAt = now
+		stats.pending = false
+	}
+	stats.WALBytes = walBytes
+	stats.WALFrames = frames
+	stats.CheckedAt = now
+
+	warn := warnBytes > 0 && walBytes > warnBytes && !l.walWarned[manifest.ID]
+	if warnBytes > 0 {
+		l.walWarned[manifest.ID] = walBytes > warnBytes
+	}
+	l.walMu.Unlock()
+
+	if warn {
+		l.warnLargeWAL(manifest, walBytes, warnBytes)
+	}
+}
+
+// passiveCheckpoint runs a passive checkpoint on the database at path and
+// returns how many WAL frames it held and how many were copied back. busy is
+// true when another checkpoint was running.
+func passiveCheckpoint(path string) (frames int64, checkpointed int64, busy bool, err error) {
+	database, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(0)", path))
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("opening database: %w", err)
+	}
+	defer database.Close()
+
+	var blocked int
+	err = database.QueryRow("PRAGMA wal_checkpoint(PASSIVE)").Scan(&blocked, &frames, &checkpointed)
+	if isBusy(err) {
+		return 0, 0, true, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("running checkpoint: %w", err)
+	}
+	// A database outside WAL mode reports -1 frames.
+	if frames < 0 {
+		return 0, 0, false, nil
+	}
+	return frames, checkpointed, blocked != 0, nil
+}
+
+// isBusy reports whether err is SQLITE_BUSY or one of its extended codes.
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY
+}
+
+func (l *Loader) warnLargeWAL(manifest *Manifest, walBytes int64, warnBytes int64) {
+	slog.Warn("plugin WAL is larger than the warning threshold", "plugin", manifest.ID,
+		"wal_bytes", walBytes, "threshold_bytes", warnBytes)
+	if l.notifier == nil {
+		return
+	}
+
+	title := fmt.Sprintf("%s database log is %d MiB", manifest.Name, walBytes>>20)
+	body := fmt.Sprintf("The write-ahead log of %s has grown past %d MiB, so backups of its database are larger than they need to be. "+
+		"It usually means a long-running reader keeps checkpoints from completing; restarting the plugin resets it.",
+		manifest.ID, warnBytes>>20)
+	if err := l.notifier.SendNotification(systemNotificationSource, title, body, false); err != nil {
+		slog.Warn("sending WAL warning", "plugin", manifest.ID, "error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openPluginDatabase opens a plaintext WAL database for plugin id, as the SDK
+// does, and keeps it open for the test like a running plugin would.
+func openPluginDatabase(t *testing.T, dataDir string, id string) *sql.DB {
+	t.Helper()
+
+	path := filepath.Join(dataDir, "plugins", id, "db.sqlite")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("creating plugin directory: %v", err)
+	}
+	database, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	for _, statement := range []string{
+		"PRAGMA journal_mode=WAL",
+		"CREATE TABLE entries (body TEXT NOT NULL)",
+	} {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+	return database
+}
+
+func insertEntries(t *testing.T, database *sql.DB, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		if _, err := database.Exec("INSERT INTO entries (body) VALUES (hex(randomblob(512)))"); err != nil {
+			t.Fatalf("inserting entry: %v", err)
+		}
+	}
+}
+
+func TestCheckWAL_CountsCheckpointsAndWarnsOnce(t *testing.T) {
+	dataDir := t.TempDir()
+	registry := NewRegistry()
+	registry.Register("finance-tracker", nil, &Manifest{ID: "finance-tracker", Name: "Finance", Permissions: []string{PermissionDBWrite}})
+	registry.Register("clock", nil, &Manifest{ID: "clock", Name: "Clock"})
+
+	database := openPluginDatabase(t, dataDir, "finance-tracker")
+	insertEntries(t, database, 20)
+
+	notifier := &recordingNotifier{}
+	loader := NewLoader(t.TempDir(), dataDir, registry)
+	loader.SetNotifier(notifier)
+
+	loader.checkWAL(1)
+	loader.checkWAL(1)
+	if stats := loader.WALStats(); len(stats) != 1 || stats[0].Checkpoints != 1 {
+		t.Fatalf("expected a check without new frames not to count as a checkpoint, got %+v", stats)
+	}
+	insertEntries(t, database, 20)
+	loader.checkWAL(1)
+
+	stats := loader.WALStats()
+	if len(stats) != 1 || stats[0].PluginID != "finance-tracker" {
+		t.Fatalf("expected statistics for the plugin with a database only, got %+v", stats)
+	}
+	if stats[0].WALBytes == 0 || stats[0].WALFrames == 0 {
+		t.Errorf("expected the WAL to be measured, got %+v", stats[0])
+	}
+	if stats[0].Checkpoints != 2 || stats[0].BusyRetries != 0 || stats[0].LastCheckpointAt == "" {
+		t.Errorf("expected two complete checkpoints, got %+v", stats[0])
+	}
+	if len(notifier.titles) != 1 || notifier.sources[0] != systemNotificationSource {
+		t.Errorf("expected a single WAL warning, got %+v", notifier)
+	}
+
+	registry.Unregister("finance-tracker")
+	loader.checkWAL(1)
+	if stats := loader.WALStats(); len(stats) != 0 {
+		t.Errorf("expected unloaded plugins to be dropped, got %+v", stats)
+	}
+}
+
+func TestCheckWAL_ReaderBlocksCheckpoint(t *testing.T) {
+	dataDir := t.TempDir()
+	registry := NewRegistry()
+	registry.Register("quick-notes", nil, &Manifest{ID: "quick-notes", Permissions: []string{PermissionDBWrite}})
+
+	database := openPluginDatabase(t, dataDir, "quick-notes")
+	insertEntries(t, database, 5)
+
+	// A reader holding an old snapshot keeps later frames in the WAL.
+	reader, err := database.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer reader.Rollback()
+	var count int
+	if err := reader.QueryRow("SELECT COUNT(*) FROM entries").Scan(&count); err != nil {
+		t.Fatalf("reading entries: %v", err)
+	}
+	insertEntries(t, database, 5)
+
+	loader := NewLoader(t.TempDir(), dataDir, registry)
+	loader.checkWAL(0)
+
+	stats := loader.WALStats()
+	if len(stats) != 1 || stats[0].BusyRetries != 1 || stats[0].Checkpoints != 0 {
+		t.Fatalf("expected the blocked checkpoint to count as a busy retry, got %+v", stats)
+	}
+
+	// Once the reader is done, the retry completes the checkpoint.
+	if err := reader.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	loader.checkWAL(0)
+	if stats := loader.WALStats(); stats[0].BusyRetries != 1 || stats[0].Checkpoints != 1 {
+		t.Errorf("expected the retried checkpoint to complete, got %+v", stats)
+	}
+}
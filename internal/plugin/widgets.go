@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	// maxWidgetSize is the most grid columns or rows a widget can suggest,
+	// the width of the dashboard grid.
+	maxWidgetSize = 12
+	// maxWidgetTitleLength bounds the titles of widgets.
+	maxWidgetTitleLength = 60
+	// minWidgetRefresh is the shortest refresh interval a widget can ask for,
+	// in seconds.
+	minWidgetRefresh = 10
+	// maxWidgets bounds how many widgets a plugin can declare.
+	maxWidgets = 16
+)
+
+// widgetSlotPattern matches widget slot names, such as "dashboard-widget".
+var widgetSlotPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,63}$`)
+
+// Widget is a dashboard widget a plugin declares in its manifest. The
+// dashboard fetches its data from /api/plugins/{id}/widget/{slot}, which the
+// plugin answers in GetWidgetData.
+type Widget struct {
+	Slot        string `json:"slot"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Width and Height are the size the widget suggests on the dashboard
+	// grid, in columns and rows; users can resize it.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// RefreshInterval is how often, in seconds, the dashboard refetches the
+	// widget's data, for data that changes without the plugin calling
+	// NotifyChanged, such as time-based summaries. 0 only refetches on changes.
+	RefreshInterval int `json:"refresh_interval,omitempty"`
+}
+
+// FindWidget returns the manifest's widget for slot.
+func (m *Manifest) FindWidget(slot string) (Widget, bool) {
+	for _, widget := range m.Widgets {
+		if widget.Slot == slot {
+			return widget, true
+		}
+	}
+	return Widget{}, false
+}
+
+// validateWidgets rejects a manifest's widgets unless each has a valid,
+// unique slot, a title, and a size and refresh interval in range.
+func validateWidgets(widgets []Widget) error {
+	if len(widgets) > maxWidgets {
+		return fmt.Errorf("at most %d widgets can be declared", maxWidgets)
+	}
+
+	seen := make(map[string]bool, len(widgets))
+	for _, widget := range widgets {
+		if !widgetSlotPattern.MatchString(widget.Slot) {
+			return fmt.Errorf("invalid widget slot %q: use lowercase letters, digits and dashes", widget.Slot)
+		}
+		if seen[widget.Slot] {
+			return fmt.Errorf("widget %q is declared twice", widget.Slot)
+		}
+		seen[widget.Slot] = true
+
+		if widget.Title == "" || len(widget.Title) > maxWidgetTitleLength {
+			return fmt.Errorf("widget %s: title must be between 1 and %d characters", widget.Slot, maxWidgetTitleLength)
+		}
+		if widget.Width < 0 || widget.Width > maxWidgetSize || widget.Height < 0 || widget.Height > maxWidgetSize {
+			return fmt.Errorf("widget %s: width and height must be between 1 and %d, or 0 for the default", widget.Slot, maxWidgetSize)
+		}
+		if widget.RefreshInterval < 0 || (widget.RefreshInterval > 0 && widget.RefreshInterval < minWidgetRefresh) {
+			return fmt.Errorf("widget %s: refresh_interval must be at least %d seconds, or 0", widget.Slot, minWidgetRefresh)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package plugin
+
+import "testing"
+
+func TestValidateWidgets(t *testing.T) {
+	valid := []Widget{
+		{Slot: "dashboard-widget", Title: "Monthly balance", Width: 4, Height: 2},
+		{Slot: "upcoming", Title: "Upcoming", RefreshInterval: 3600},
+	}
+	if err := validateWidgets(valid); err != nil {
+		t.Errorf("expected valid widgets, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		widgets []Widget
+	}{
+		{"invalid slot", []Widget{{Slot: "Dashboard", Title: "x"}}},
+		{"duplicate slot", []Widget{{Slot: "a", Title: "x"}, {Slot: "a", Title: "y"}}},
+		{"missing title", []Widget{{Slot: "a"}}},
+		{"too wide", []Widget{{Slot: "a", Title: "x", Width: 13}}},
+		{"negative height", []Widget{{Slot: "a", Title: "x", Height: -1}}},
+		{"refresh too often", []Widget{{Slot: "a", Title: "x", RefreshInterval: 5}}},
+	}
+	for _, test := range tests {
+		if err := validateWidgets(test.widgets); err == nil {
+			t.Errorf("%s: expected widgets to be rejected", test.name)
+		}
+	}
+}
+
+func TestManifest_FindWidget(t *testing.T) {
+	manifest := &Manifest{Widgets: []Widget{{Slot: "balance", Title: "Balance"}}}
+
+	if widget, ok := manifest.FindWidget("balance"); !ok || widget.Title != "Balance" {
+		t.Errorf("expected the balance widget, got %+v, %v", widget, ok)
+	}
+	if _, ok := manifest.FindWidget("budget"); ok {
+		t.Error("expected no widget for an undeclared slot")
+	}
+}
@@ -0,0 +1,124 @@
+// Package secrets keeps the credentials plugin settings refer to by name, such
+// as a GitHub token or a price API key, encrypted with a key derived from the
+// at-rest master key. Values are only ever decrypted to be handed to the
+// plugins whose settings refer to them.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// MaxValueSize is the largest secret value accepted.
+const MaxValueSize = 8 << 10
+
+// ErrDisabled is returned when the host runs without a master key, so there
+// is nothing to encrypt secrets with.
+var ErrDisabled = errors.New("the secret store needs at-rest encryption: set CORTEX_DB_PASSPHRASE")
+
+// Store encrypts secrets into the host database. It implements
+// plugin.SecretStore.
+type Store struct {
+	records *db.HostDB
+	key     []byte
+}
+
+// NewStore creates a store backed by records. A nil master key disables it.
+func NewStore(records *db.HostDB, master []byte) *Store {
+	store := &Store{records: records}
+	if master != nil {
+		store.key = atrest.SecretsKey(master)
+	}
+	return store
+}
+
+// Enabled reports whether secrets can be stored and read.
+func (s *Store) Enabled() bool {
+	return s.key != nil
+}
+
+// List returns the stored secrets, without their values.
+func (s *Store) List() ([]db.PluginSecret, error) {
+	return s.records.ListPluginSecrets()
+}
+
+// Set encrypts value and stores it under name, replacing any previous value.
+func (s *Store) Set(name string, value string) error {
+	if !s.Enabled() {
+		return ErrDisabled
+	}
+	if !plugin.ValidSecretName(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	if value == "" || len(value) > MaxValueSize {
+		return fmt.Errorf("secret value must be between 1 and %d bytes", MaxValueSize)
+	}
+
+	sealed, err := atrest.Seal(s.key, []byte(value))
+	if err != nil {
+		return fmt.Errorf("encrypting secret: %w", err)
+	}
+	return s.records.SavePluginSecret(name, sealed)
+}
+
+// Secret returns the decrypted value stored under name, or
+// plugin.ErrSecretNotFound.
+func (s *Store) Secret(name string) (string, error) {
+	if !s.Enabled() {
+		return "", ErrDisabled
+	}
+
+	sealed, err := s.records.GetPluginSecret(name)
+	if errors.Is(err, db.ErrNotFound) {
+		return "", plugin.ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	value, err := atrest.Open(s.key, sealed)
+	if err != nil {
+		return "", fmt.Errorf("decrypting secret %s: %w", name, err)
+	}
+	return string(value), nil
+}
+
+// Exists reports whether a secret is stored under name.
+func (s *Store) Exists(name string) (bool, error) {
+	_, err := s.records.GetPluginSecret(name)
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes the secret stored under name, or returns
+// plugin.ErrSecretNotFound.
+func (s *Store) Delete(name string) error {
+	err := s.records.DeletePluginSecret(name)
+	if errors.Is(err, db.ErrNotFound) {
+		return plugin.ErrSecretNotFound
+	}
+	return err
+}
+
+// Sealed returns the encrypted value stored under name, or
+// plugin.ErrSecretNotFound. It is what Restore takes to put a deleted secret
+// back, and needs no master key.
+func (s *Store) Sealed(name string) ([]byte, error) {
+	sealed, err := s.records.GetPluginSecret(name)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, plugin.ErrSecretNotFound
+	}
+	return sealed, err
+}
+
+// Restore puts back a deleted secret from the value Sealed returned for it,
+// or returns db.ErrExists if a secret has been saved under name since.
+func (s *Store) Restore(name string, sealed []byte) error {
+	return s.records.RestorePluginSecret(name, sealed)
+}
@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+func newTestHostDB(t *testing.T) *db.HostDB {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+	return hostDB
+}
+
+func TestStore_EncryptsValues(t *testing.T) {
+	hostDB := newTestHostDB(t)
+	store := NewStore(hostDB, bytes.Repeat([]byte{7}, 32))
+
+	if err := store.Set("github-token", "ghp_secret"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	sealed, err := hostDB.GetPluginSecret("github-token")
+	if err != nil {
+		t.Fatalf("reading stored secret: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("ghp_secret")) {
+		t.Fatal("expected the stored value to be encrypted")
+	}
+
+	value, err := store.Secret("github-token")
+	if err != nil || value != "ghp_secret" {
+		t.Fatalf("expected ghp_secret, got %q, %v", value, err)
+	}
+
+	other := NewStore(hostDB, bytes.Repeat([]byte{8}, 32))
+	if _, err := other.Secret("github-token"); err == nil {
+		t.Error("expected a different master key to fail decrypting")
+	}
+
+	listed, err := store.List()
+	if err != nil || len(listed) != 1 || listed[0].Name != "github-token" {
+		t.Errorf("expected github-token to be listed, got %+v, %v", listed, err)
+	}
+
+	if err := store.Delete("github-token"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Secret("github-token"); !errors.Is(err, plugin.ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound after delete, got %v", err)
+	}
+	if err := store.Delete("github-token"); !errors.Is(err, plugin.ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestStore_DisabledWithoutMasterKey(t *testing.T) {
+	store := NewStore(newTestHostDB(t), nil)
+
+	if store.Enabled() {
+		t.Error("expected the store to be disabled without a master key")
+	}
+	if err := store.Set("github-token", "ghp_secret"); !errors.Is(err, ErrDisabled) {
+		t.Errorf("expected ErrDisabled, got %v", err)
+	}
+	if _, err := store.Secret("github-token"); !errors.Is(err, ErrDisabled) {
+		t.Errorf("expected ErrDisabled, got %v", err)
+	}
+}
+
+func TestStore_Validation(t *testing.T) {
+	store := NewStore(newTestHostDB(t), bytes.Repeat([]byte{7}, 32))
+
+	if err := store.Set("GitHub Token", "ghp_secret"); err == nil {
+		t.Error("expected an invalid name to be rejected")
+	}
+	if err := store.Set("github-token", ""); err == nil {
+		t.Error("expected an empty value to be rejected")
+	}
+}
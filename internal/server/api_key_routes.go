@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	// apiKeyPrefix makes API keys recognizable in scripts and secret scanners.
+	apiKeyPrefix = "cxk_"
+	// apiKeyDisplayLength is how much of the key is kept in clear text for listings.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// apiKeyTouchInterval throttles last_used_at writes to one per key per minute.
+	apiKeyTouchInterval = time.Minute
+	maxAPIKeyNameLength = 100
+)
+
+type apiKeyContextKey struct{}
+
+// apiKeyRoutes registers API key management endpoints.
+func apiKeyRoutes(router chi.Router, hostDB *db.HostDB) {
+	// GET /api/keys -- list API keys (never includes the secret)
+	router.Get("/api/keys", func(writer http.ResponseWriter, request *http.Request) {
+		keys, err := hostDB.ListAPIKeys()
+		if err != nil {
+			writeAPIKeyError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list API keys")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": keys})
+	})
+
+	// POST /api/keys -- create an API key (the key is returned only once)
+	router.Post("/api/keys", func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Name string `json:"name"`
+		}
+
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			writeAPIKeyError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		body.Name = strings.TrimSpace(body.Name)
+		if body.Name == "" || len(body.Name) > maxAPIKeyNameLength {
+			writeAPIKeyError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "name is required and must be at most 100 characters")
+			return
+		}
+
+		key, err := generateToken(apiKeyPrefix)
+		if err != nil {
+			writeAPIKeyError(writer, http.StatusInternalServerError, "INTERNAL", "failed to generate API key")
+			return
+		}
+
+		stored, err := hostDB.CreateAPIKey(body.Name, key[:apiKeyDisplayLength], hashToken(key))
+		if err != nil {
+			writeAPIKeyError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to create API key")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"api_key": stored,
+				"key":     key,
+			},
+		})
+	})
+
+	// DELETE /api/keys/{keyID} -- revoke an API key
+	router.Delete("/api/keys/{keyID}", func(writer http.ResponseWriter, request *http.Request) {
+		keyID, err := strconv.ParseInt(chi.URLParam(request, "keyID"), 10, 64)
+		if err != nil || keyID <= 0 {
+			writeAPIKeyError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid API key ID")
+			return
+		}
+
+		if err := hostDB.DeleteAPIKey(keyID); err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				writeAPIKeyError(writer, http.StatusNotFound, "NOT_FOUND", "API key not found")
+				return
+			}
+			writeAPIKeyError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to delete API key")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": keyID, "status": "deleted"},
+		})
+	})
+}
+
+// apiKeyAuth resolves `Authorization: Bearer cxk_...` headers. While no key
+// exists, requests without an API key pass through. Once one does, requests
+// for data need an API key or a device token, which browsers send as the
+// device cookie, so a client whose key was deleted cannot get back in by
+// leaving the header off. Unknown or deleted keys are rejected with 401. The
+// matched key is stored in the request context and its last use recorded.
+// It must run after deviceTracking.
+func apiKeyAuth(hostDB *db.HostDB, registry *plugin.Registry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			key, ok := bearerAPIKey(request)
+			if !ok {
+				if _, ok := deviceFromContext(request.Context()); ok || !credentialRequired(request, registry) {
+					next.ServeHTTP(writer, request)
+					return
+				}
+				exists, err := hostDB.HasAPIKeys()
+				if err != nil {
+					writeAPIKeyError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to verify API key")
+					return
+				}
+				if exists {
+					writeAPIKeyError(writer, http.StatusUnauthorized, "UNAUTHORIZED", "an API key or device token is required")
+					return
+				}
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			apiKey, err := hostDB.GetAPIKeyByHash(hashToken(key))
+			if err != nil {
+				if errors.Is(err, db.ErrNotFound) {
+					writeAPIKeyError(writer, http.StatusUnauthorized, "UNAUTHORIZED", "API key is invalid or revoked")
+					return
+				}
+				writeAPIKeyError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to verify API key")
+				return
+			}
+
+			ip, userAgent := clientInfo(request)
+			if err := hostDB.TouchAPIKey(apiKey.ID, apiKeyTouchInterval, ip, userAgent); err != nil {
+				slog.Warn("updating API key last use", "api_key", apiKey.ID, "error", err)
+			}
+
+			ctx := context.WithValue(request.Context(), apiKeyContextKey{}, apiKey)
+			next.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}
+}
+
+// bearerAPIKey extracts a Cortex API key from the Authorization header.
+// Bearer tokens without the API key prefix are left for other handlers.
+func bearerAPIKey(request *http.Request) (string, bool) {
+	header := request.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	if !strings.HasPrefix(token, apiKeyPrefix) {
+		return "", false
+	}
+	return token, true
+}
+
+// apiKeyFromContext returns the API key resolved by apiKeyAuth, if any.
+func apiKeyFromContext(ctx context.Context) (*db.APIKey, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey{}).(*db.APIKey)
+	return apiKey, ok
+}
+
+// writeAPIKeyError writes a standardized error JSON response for API key endpoints.
+func writeAPIKeyError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
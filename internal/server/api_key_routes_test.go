@@ -0,0 +1,247 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// newAPIKeyRouter creates a router with API key auth, key routes, and a probe
+// endpoint that reports which key (if any) authenticated the request.
+func newAPIKeyRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	router := chi.NewRouter()
+	router.Use(apiKeyAuth(hostDB, nil))
+	apiKeyRoutes(router, hostDB)
+	router.Get("/probe", func(writer http.ResponseWriter, request *http.Request) {
+		if apiKey, ok := apiKeyFromContext(request.Context()); ok {
+			_, _ = writer.Write([]byte(apiKey.Name))
+		}
+	})
+	return router
+}
+
+// createAPIKey creates a key through the API, authenticated with credential
+// once a device or key exists, and returns its ID and secret.
+func createAPIKey(t *testing.T, router *chi.Mux, name string, credential http.Header) (int64, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(`{"name": "`+name+`"}`))
+	for header, values := range credential {
+		req.Header[header] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data struct {
+			APIKey db.APIKey `json:"api_key"`
+			Key    string    `json:"key"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse create response: %v", err)
+	}
+
+	if !strings.HasPrefix(response.Data.Key, response.Data.APIKey.Prefix) {
+		t.Errorf("expected key %q to start with listed prefix %q", response.Data.Key, response.Data.APIKey.Prefix)
+	}
+	return response.Data.APIKey.ID, response.Data.Key
+}
+
+func TestAPIKey_AuthenticatesBearer(t *testing.T) {
+	router := newAPIKeyRouter(t)
+	_, key := createAPIKey(t, router, "iOS shortcut", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "iOS shortcut" {
+		t.Errorf("expected request to be authenticated as 'iOS shortcut', got %q", rec.Body.String())
+	}
+
+	listReq := httptest.NewRequest(http.MethodGet, "/api/keys", nil)
+	listReq.Header.Set("Authorization", "Bearer "+key)
+	listRec := httptest.NewRecorder()
+	router.ServeHTTP(listRec, listReq)
+
+	var listed struct {
+		Data []db.APIKey `json:"data"`
+	}
+	if err := json.Unmarshal(listRec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("failed to parse list response: %v", err)
+	}
+	if len(listed.Data) != 1 || listed.Data[0].LastUsedAt == nil {
+		t.Errorf("expected one key with last_used_at set, got %+v", listed.Data)
+	}
+	if strings.Contains(listRec.Body.String(), key) {
+		t.Error("listing must not expose the full API key")
+	}
+}
+
+func TestAPIKey_DeletedKeyRejected(t *testing.T) {
+	router := newAPIKeyRouter(t)
+	id, key := createAPIKey(t, router, "script", nil)
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/api/keys/"+strconv.FormatInt(id, 10), nil)
+	deleteReq.Header.Set("Authorization", "Bearer "+key)
+	deleteRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteRec, deleteReq)
+
+	if deleteRec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", deleteRec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 for deleted key, got %d", rec.Code)
+	}
+
+	// With no key left, the instance is open again.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/keys", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 once every key is deleted, got %d", rec.Code)
+	}
+}
+
+func TestAPIKey_DeviceSigninRecoversAccess(t *testing.T) {
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+	router := chi.NewRouter()
+	router.Use(deviceTracking(hostDB, nil))
+	router.Use(apiKeyAuth(hostDB, nil))
+	deviceRoutes(router, hostDB)
+	apiKeyRoutes(router, hostDB)
+
+	// The only key is lost, so the web UI is locked out.
+	createAPIKey(t, router, "script", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/keys", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 without a key, got %d", rec.Code)
+	}
+
+	// `cortex device add` issues a token the browser signs in with.
+	_, token, err := IssueDevice(hostDB, "Recovery", "web")
+	if err != nil {
+		t.Fatalf("failed to issue device: %v", err)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/devices/signin", strings.NewReader(`{"token": "`+token+`"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected sign-in to work with a key in use, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/keys", nil)
+	for _, cookie := range rec.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the device cookie to pass API key auth, got %d", rec.Code)
+	}
+}
+
+func TestAPIKey_RequiredOnceCreated(t *testing.T) {
+	router := newAPIKeyRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/keys", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected requests without a key to pass before any key is created, got %d", rec.Code)
+	}
+
+	_, key := createAPIKey(t, router, "script", nil)
+
+	tests := []struct {
+		path          string
+		authorization string
+		want          int
+	}{
+		{"/api/keys", "", http.StatusUnauthorized},
+		{"/api/plugins/notes/notes", "", http.StatusUnauthorized},
+		{"/api/keys", "Bearer some-other-token", http.StatusUnauthorized},
+		{"/api/keys", "Bearer " + key, http.StatusOK},
+		{"/probe", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("GET %s with %q: expected status %d, got %d", tt.path, tt.authorization, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestAPIKey_NonCortexBearerPassesThrough(t *testing.T) {
+	router := newAPIKeyRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/probe", nil)
+	req.Header.Set("Authorization", "Bearer some-other-token")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Fatalf("expected unauthenticated pass-through, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeleteAPIKey_NotFound(t *testing.T) {
+	router := newAPIKeyRouter(t)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/keys/77", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestCreateAPIKey_Validation(t *testing.T) {
+	router := newAPIKeyRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(`{"name": "  "}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// attachmentRoutes registers host-level endpoints for the attachment store.
+// Plugins read and write attachments over the host connection, not HTTP.
+func attachmentRoutes(router chi.Router, hostDB *db.HostDB) {
+	// GET /api/attachments/stats -- stored vs referenced bytes, deduplication savings, per-plugin usage
+	router.Get("/api/attachments/stats", func(writer http.ResponseWriter, request *http.Request) {
+		stats, err := hostDB.GetAttachmentStats()
+		if err != nil {
+			slog.Error("failed to get attachment stats", "error", err)
+			writeAttachmentError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get attachment stats")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": stats})
+	})
+}
+
+// writeAttachmentError writes a standardized error JSON response for attachment endpoints.
+func writeAttachmentError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
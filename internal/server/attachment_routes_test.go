@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/attachments"
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+func TestAttachmentStats(t *testing.T) {
+	dataDir := t.TempDir()
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	store := attachments.NewStore(filepath.Join(dataDir, "attachments"), hostDB)
+	invoice := []byte("invoice #42")
+	for _, pluginID := range []string{"finance-tracker", "finance-tracker", "project-hub"} {
+		if _, err := store.Put(pluginID, "invoice.txt", "text/plain", invoice); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	router := chi.NewRouter()
+	attachmentRoutes(router, hostDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/attachments/stats", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data db.AttachmentStats `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	stats := response.Data
+	size := int64(len(invoice))
+	if stats.Blobs != 1 || stats.Attachments != 3 {
+		t.Errorf("expected 3 attachments sharing 1 blob, got %+v", stats)
+	}
+	if stats.StoredBytes != size || stats.ReferencedBytes != 3*size || stats.SavedBytes != 2*size {
+		t.Errorf("unexpected byte counts: %+v", stats)
+	}
+	if len(stats.Plugins) != 2 || stats.Plugins[0].PluginID != "finance-tracker" || stats.Plugins[0].Attachments != 2 {
+		t.Errorf("unexpected per-plugin usage: %+v", stats.Plugins)
+	}
+}
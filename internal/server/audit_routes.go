@@ -0,0 +1,198 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+// auditedMethods are the request methods that can change data.
+var auditedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// auditLog records every state-changing request in the host database once it
+// has been answered: the route, the plugin it reached, a hash of its body,
+// the API key or device behind it and the response status. It must run after
+// apiKeyAuth and deviceTracking so the caller is known.
+func auditLog(hostDB *db.HostDB, registry *plugin.Registry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if !auditedMethods[request.Method] || request.Body == nil {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			body := &hashingBody{ReadCloser: request.Body, hash: sha256.New()}
+			request.Body = body
+			wrapped := middleware.NewWrapResponseWriter(writer, request.ProtoMajor)
+
+			next.ServeHTTP(wrapped, request)
+
+			// Hash the part of the body the handler did not read
+			_, _ = io.Copy(io.Discard, body)
+
+			status := wrapped.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			ip, _ := clientInfo(request)
+			entry := db.AuditEntry{
+				Method:    request.Method,
+				Path:      request.URL.Path,
+				PluginID:  auditedPlugin(request, registry),
+				Actor:     auditActor(request),
+				IP:        ip,
+				Status:    status,
+				RequestID: middleware.GetReqID(request.Context()),
+			}
+			if body.read > 0 {
+				entry.PayloadHash = hex.EncodeToString(body.hash.Sum(nil))
+			}
+			if err := hostDB.RecordAuditEntry(entry); err != nil {
+				slog.Warn("recording audit entry", "method", entry.Method, "path", entry.Path, "error", err)
+			}
+		})
+	}
+}
+
+// hashingBody hashes a request body as it is read.
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+	read int64
+}
+
+func (b *hashingBody) Read(buffer []byte) (int, error) {
+	n, err := b.ReadCloser.Read(buffer)
+	b.hash.Write(buffer[:n])
+	b.read += int64(n)
+	return n, err
+}
+
+// auditedPlugin returns the plugin a request was routed to, through
+// /api/plugins/{id} or a route alias, or "" for host routes.
+func auditedPlugin(request *http.Request, registry *plugin.Registry) string {
+	if routeContext := chi.RouteContext(request.Context()); routeContext != nil {
+		if pluginID := routeContext.URLParam("pluginID"); pluginID != "" {
+			return pluginID
+		}
+	}
+	if strings.HasPrefix(request.URL.Path, "/api/") {
+		return ""
+	}
+	if pluginID, _, ok := registry.ResolveRoute(request.URL.Path); ok {
+		return pluginID
+	}
+	return ""
+}
+
+// auditActor names the API key or device a request authenticated with.
+func auditActor(request *http.Request) string {
+	if apiKey, ok := apiKeyFromContext(request.Context()); ok {
+		return "api_key:" + apiKey.Name
+	}
+	if device, ok := deviceFromContext(request.Context()); ok {
+		return "device:" + device.Name
+	}
+	return ""
+}
+
+// auditRoutes registers the audit log endpoint.
+func auditRoutes(router chi.Router, hostDB *db.HostDB) {
+	// GET /api/audit -- state-changing requests, newest first (?plugin=, ?method=, ?status=, ?since=, ?limit=, ?cursor=)
+	router.Get("/api/audit", func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		filter := db.AuditFilter{PluginID: query.Get("plugin"), Method: strings.ToUpper(query.Get("method"))}
+
+		if rawStatus := query.Get("status"); rawStatus != "" {
+			parsed, err := strconv.Atoi(rawStatus)
+			if err != nil || parsed < 100 || parsed > 599 {
+				writeAuditError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "status must be an HTTP status code")
+				return
+			}
+			filter.Status = parsed
+		}
+		if rawSince := query.Get("since"); rawSince != "" {
+			parsed, err := time.Parse(time.RFC3339, rawSince)
+			if err != nil {
+				writeAuditError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "since must be an RFC 3339 timestamp")
+				return
+			}
+			filter.Since = parsed
+		}
+		if rawCursor := query.Get("cursor"); rawCursor != "" {
+			parsed, err := strconv.ParseInt(rawCursor, 10, 64)
+			if err != nil || parsed < 1 {
+				writeAuditError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "invalid cursor")
+				return
+			}
+			filter.Before = parsed
+		}
+
+		limit := defaultAuditLimit
+		if rawLimit := query.Get("limit"); rawLimit != "" {
+			parsed, err := strconv.Atoi(rawLimit)
+			if err != nil || parsed < 1 || parsed > maxAuditLimit {
+				writeAuditError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "limit must be between 1 and 200")
+				return
+			}
+			limit = parsed
+		}
+
+		// One extra entry tells whether there is another page
+		entries, err := hostDB.ListAuditEntries(filter, limit+1)
+		if err != nil {
+			slog.Error("listing audit entries", "error", err)
+			writeAuditError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list audit entries")
+			return
+		}
+
+		hasMore := len(entries) > limit
+		cursor := ""
+		if hasMore {
+			entries = entries[:limit]
+			cursor = strconv.FormatInt(entries[limit-1].ID, 10)
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data":     entries,
+			"cursor":   cursor,
+			"has_more": hasMore,
+		})
+	})
+}
+
+// writeAuditError writes a standardized error JSON response for audit endpoints.
+func writeAuditError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
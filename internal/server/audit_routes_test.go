@@ -0,0 +1,116 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// newAuditRouter creates a router that audits requests to the plugin proxy
+// and the audit endpoint, with an API key named "ci" ("cxk_audit").
+func newAuditRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes", plugin.PermissionDBRead, plugin.PermissionDBWrite)
+	hostDB, undoLog := newTestUndoLog(t)
+	if _, err := hostDB.CreateAPIKey("ci", "cxk_audi", hashToken("cxk_audit")); err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	router := chi.NewRouter()
+	router.Use(apiKeyAuth(hostDB, nil))
+	router.Use(auditLog(hostDB, registry))
+	pluginAPIRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry), plugin.NewInstaller(tempDir, "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(0, nil))
+	auditRoutes(router, hostDB)
+	return router
+}
+
+// listAudit returns the entries and cursor of GET /api/audit with query.
+func listAudit(t *testing.T, router *chi.Mux, query string) ([]db.AuditEntry, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit"+query, nil)
+	req.Header.Set("Authorization", "Bearer cxk_audit")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Data   []db.AuditEntry `json:"data"`
+		Cursor string          `json:"cursor"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	return body.Data, body.Cursor
+}
+
+func TestAuditLog_RecordsMutatingRequests(t *testing.T) {
+	router := newAuditRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/notes/notes", strings.NewReader(`{"title":"idea"}`))
+	req.Header.Set("Authorization", "Bearer cxk_audit")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	req = httptest.NewRequest(http.MethodGet, "/api/plugins/notes/notes", nil)
+	req.Header.Set("Authorization", "Bearer cxk_audit")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	req = httptest.NewRequest(http.MethodDelete, "/api/plugins/missing", nil)
+	req.Header.Set("Authorization", "Bearer cxk_audit")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	// Requests refused for lack of a credential never reach the audit log.
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/api/plugins/notes", nil))
+
+	entries, _ := listAudit(t, router, "")
+	if len(entries) != 2 {
+		t.Fatalf("expected the POST and DELETE to be audited, got %+v", entries)
+	}
+
+	deleted, posted := entries[0], entries[1]
+	sum := sha256.Sum256([]byte(`{"title":"idea"}`))
+	if posted.Method != http.MethodPost || posted.Path != "/api/plugins/notes/notes" || posted.PluginID != "notes" || posted.Status != http.StatusOK {
+		t.Errorf("unexpected entry for the POST: %+v", posted)
+	}
+	if posted.PayloadHash != hex.EncodeToString(sum[:]) || posted.Actor != "api_key:ci" {
+		t.Errorf("expected the body hash and API key, got %q and %q", posted.PayloadHash, posted.Actor)
+	}
+	if deleted.Status != http.StatusNotFound || deleted.PayloadHash != "" || deleted.Actor != "api_key:ci" {
+		t.Errorf("unexpected entry for the DELETE: %+v", deleted)
+	}
+
+	if filtered, _ := listAudit(t, router, "?method=delete&status=404"); len(filtered) != 1 || filtered[0].ID != deleted.ID {
+		t.Errorf("expected the filters to match the DELETE only, got %+v", filtered)
+	}
+	page, cursor := listAudit(t, router, "?limit=1")
+	if len(page) != 1 || cursor == "" {
+		t.Fatalf("expected a first page and a cursor, got %+v, %q", page, cursor)
+	}
+	if next, cursor := listAudit(t, router, "?limit=1&cursor="+cursor); len(next) != 1 || next[0].ID != posted.ID || cursor != "" {
+		t.Errorf("expected the last page to hold the POST, got %+v, %q", next, cursor)
+	}
+}
+
+func TestAuditLog_Validation(t *testing.T) {
+	router := newAuditRouter(t)
+
+	for _, query := range []string{"?limit=0", "?limit=201", "?status=7", "?since=yesterday", "?cursor=x"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/audit"+query, nil)
+		req.Header.Set("Authorization", "Bearer cxk_audit")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+		}
+	}
+}
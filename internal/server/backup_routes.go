@@ -0,0 +1,231 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/backup"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	// maxRestoreSize caps uploaded archives for POST /api/restore, as sent.
+	maxRestoreSize = 1 << 30
+	// maxRestoreDecodedSize caps a compressed upload once decoded. Archives
+	// are gzipped already, so sending one compressed gains little; the cap
+	// only stops a small body from expanding without end.
+	maxRestoreDecodedSize = 4 << 30
+)
+
+var (
+	// errInvalidRestoreRequest is returned for malformed restore request bodies.
+	errInvalidRestoreRequest = errors.New("invalid restore request")
+	// errMissingPassphrase is returned for encrypted uploads sent without a passphrase.
+	errMissingPassphrase = errors.New("missing passphrase")
+)
+
+// backupRoutes registers the backup and restore endpoints.
+func backupRoutes(router chi.Router, backups *backup.Manager, registry *plugin.Registry, loader *plugin.Loader) {
+	// GET /api/backups -- list stored backups, newest first
+	router.Get("/api/backups", func(writer http.ResponseWriter, request *http.Request) {
+		list, err := backups.List()
+		if err != nil {
+			writeBackupError(writer, http.StatusInternalServerError, "INTERNAL", "failed to list backups")
+			return
+		}
+
+		writeBackupJSON(writer, http.StatusOK, list)
+	})
+
+	// POST /api/backup -- take a backup of the host and plugin databases now
+	router.Post("/api/backup", func(writer http.ResponseWriter, request *http.Request) {
+		info, err := backups.Create()
+		if err != nil {
+			slog.Error("backup failed", "error", err)
+			writeBackupError(writer, http.StatusInternalServerError, "BACKUP_ERROR", "failed to create backup")
+			return
+		}
+
+		writeBackupJSON(writer, http.StatusCreated, info)
+	})
+
+	// GET /api/backups/{name} -- download a stored backup
+	router.Get("/api/backups/{name}", func(writer http.ResponseWriter, request *http.Request) {
+		name := chi.URLParam(request, "name")
+		file, err := backups.Open(name)
+		if err != nil {
+			if errors.Is(err, backup.ErrNotFound) {
+				writeBackupError(writer, http.StatusNotFound, "NOT_FOUND", "backup not found")
+				return
+			}
+			writeBackupError(writer, http.StatusInternalServerError, "INTERNAL", "failed to read backup")
+			return
+		}
+		defer file.Close()
+
+		writer.Header().Set("Content-Type", "application/gzip")
+		writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		writer.WriteHeader(http.StatusOK)
+		_, _ = io.Copy(writer, file)
+	})
+
+	// POST /api/restore -- restore a stored backup ({"name"}) or an uploaded archive
+	router.Post("/api/restore", func(writer http.ResponseWriter, request *http.Request) {
+		request.Body = http.MaxBytesReader(writer, request.Body, maxRestoreSize)
+		if !decodeRequestBody(writer, request, maxRestoreDecodedSize) {
+			return
+		}
+		defer request.Body.Close()
+
+		archive, cleanup, err := restoreSource(request, backups)
+		if err != nil {
+			writeRestoreSourceError(writer, err)
+			return
+		}
+		defer cleanup()
+
+		result, err := backups.Restore(archive, func() func() {
+			return pausePlugins(registry, loader)
+		})
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeBackupError(writer, http.StatusRequestEntityTooLarge, "TOO_LARGE", "archive is too large")
+				return
+			}
+			if errors.Is(err, backup.ErrInvalidArchive) {
+				writeBackupError(writer, http.StatusBadRequest, "INVALID_ARCHIVE", err.Error())
+				return
+			}
+			slog.Error("restore failed", "error", err)
+			writeBackupError(writer, http.StatusInternalServerError, "RESTORE_ERROR", "failed to restore backup")
+			return
+		}
+
+		writeBackupJSON(writer, http.StatusOK, result)
+	})
+}
+
+// restoreSource returns the plaintext archive a restore request refers to: a
+// stored backup named in a JSON body, or the request body itself. Encrypted
+// exports are decrypted with the X-Export-Passphrase header.
+func restoreSource(request *http.Request, backups *backup.Manager) (io.Reader, func(), error) {
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Name == "" {
+			return nil, nil, fmt.Errorf("%w: body must be {\"name\": \"...\"}", errInvalidRestoreRequest)
+		}
+
+		file, err := backups.Open(body.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return file, func() { file.Close() }, nil
+	}
+
+	body := bufio.NewReader(request.Body)
+	if !backup.IsEncrypted(body) {
+		return body, func() {}, nil
+	}
+
+	passphrase := request.Header.Get(exportPassphraseHeader)
+	if passphrase == "" {
+		return nil, nil, errMissingPassphrase
+	}
+
+	// Decrypt fully before restoring: the archive is only trusted once every chunk authenticates.
+	decrypted, err := os.CreateTemp("", "cortex-restore-*.tar.gz")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		decrypted.Close()
+		os.Remove(decrypted.Name())
+	}
+
+	if err := backup.Decrypt(decrypted, body, passphrase); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if _, err := decrypted.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return decrypted, cleanup, nil
+}
+
+// writeRestoreSourceError maps a restoreSource failure to a response.
+func writeRestoreSourceError(writer http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, errInvalidRestoreRequest):
+		writeBackupError(writer, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, backup.ErrNotFound):
+		writeBackupError(writer, http.StatusNotFound, "NOT_FOUND", "backup not found")
+	case errors.Is(err, errMissingPassphrase):
+		writeBackupError(writer, http.StatusBadRequest, "VALIDATION_ERROR",
+			fmt.Sprintf("archive is encrypted: send its passphrase in the %s header", exportPassphraseHeader))
+	case errors.Is(err, backup.ErrDecryption):
+		writeBackupError(writer, http.StatusBadRequest, "DECRYPTION_ERROR", err.Error())
+	case errors.As(err, &maxBytesErr):
+		writeBackupError(writer, http.StatusRequestEntityTooLarge, "TOO_LARGE", "archive is too large")
+	default:
+		slog.Error("reading restore archive failed", "error", err)
+		writeBackupError(writer, http.StatusInternalServerError, "INTERNAL", "failed to read archive")
+	}
+}
+
+// pausePlugins unloads every loaded plugin so none holds its database open
+// during a restore. The returned function loads them again, dependencies
+// first, which also runs their migrations against the restored databases.
+func pausePlugins(registry *plugin.Registry, loader *plugin.Loader) func() {
+	order := registry.DependencyOrder()
+	var paused []string
+	for i := len(order) - 1; i >= 0; i-- {
+		if err := loader.UnloadPlugin(order[i]); err != nil {
+			slog.Error("failed to unload plugin for restore", "plugin", order[i], "error", err)
+			continue
+		}
+		paused = append(paused, order[i])
+	}
+
+	return func() {
+		for i := len(paused) - 1; i >= 0; i-- {
+			id := paused[i]
+			if err := loader.LoadPlugin(id); err != nil {
+				slog.Error("failed to reload plugin after restore", "plugin", id, "error", err)
+			}
+		}
+	}
+}
+
+// writeBackupJSON writes a {data} JSON response for backup endpoints.
+func writeBackupJSON(writer http.ResponseWriter, statusCode int, data interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": data})
+}
+
+// writeBackupError writes a standardized error JSON response for backup endpoints.
+func writeBackupError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/backup"
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// newBackupRouter creates a router with backup routes over a data dir containing a host database.
+func newBackupRouter(t *testing.T) (*chi.Mux, *db.HostDB, string) {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	registry := plugin.NewRegistry()
+	router := chi.NewRouter()
+	backupRoutes(router, backup.NewManager(dataDir, filepath.Join(dataDir, "backups"), 5),
+		registry, plugin.NewLoader(t.TempDir(), dataDir, registry))
+	return router, hostDB, dataDir
+}
+
+func TestBackup_CreateAndRestoreByName(t *testing.T) {
+	router, hostDB, _ := newBackupRouter(t)
+	if _, err := hostDB.CreateAPIKey("before", "ctx_aaaa", "hash-before"); err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/backup", nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data backup.Info `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+
+	if _, err := hostDB.CreateAPIKey("after", "ctx_bbbb", "hash-after"); err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/restore", strings.NewReader(`{"name": "`+created.Data.Name+`"}`))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, request)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	keys, err := hostDB.ListAPIKeys()
+	if err != nil {
+		t.Fatalf("failed to list api keys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Name != "before" {
+		t.Fatalf("expected only the key from before the backup, got %+v", keys)
+	}
+
+	// The restore took a safety backup, so both are listed.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/backups", nil))
+	var listed struct {
+		Data []backup.Info `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	if len(listed.Data) != 2 {
+		t.Errorf("expected 2 backups, got %+v", listed.Data)
+	}
+}
+
+func TestRestore_EncryptedUpload(t *testing.T) {
+	router, hostDB, dataDir := newBackupRouter(t)
+	if _, err := hostDB.CreateAPIKey("exported", "ctx_aaaa", "hash-exported"); err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if err := writeExport(&archive, dataDir, true, "correct horse battery"); err != nil {
+		t.Fatalf("failed to write export: %v", err)
+	}
+	if _, err := hostDB.CreateAPIKey("later", "ctx_bbbb", "hash-later"); err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	restore := func(passphrase string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/api/restore", bytes.NewReader(archive.Bytes()))
+		request.Header.Set("Content-Type", "application/octet-stream")
+		if passphrase != "" {
+			request.Header.Set(exportPassphraseHeader, passphrase)
+		}
+		router.ServeHTTP(rec, request)
+		return rec
+	}
+
+	if rec := restore(""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a passphrase, got %d", rec.Code)
+	}
+	if rec := restore("wrong passphrase"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 with a wrong passphrase, got %d", rec.Code)
+	}
+	if rec := restore("correct horse battery"); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	keys, err := hostDB.ListAPIKeys()
+	if err != nil {
+		t.Fatalf("failed to list api keys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Name != "exported" {
+		t.Fatalf("expected only the exported key, got %+v", keys)
+	}
+}
+
+func TestRestore_CompressedUpload(t *testing.T) {
+	router, hostDB, dataDir := newBackupRouter(t)
+	if _, err := hostDB.CreateAPIKey("exported", "ctx_aaaa", "hash-exported"); err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if err := writeExport(&archive, dataDir, false, ""); err != nil {
+		t.Fatalf("failed to write export: %v", err)
+	}
+	if _, err := hostDB.CreateAPIKey("later", "ctx_bbbb", "hash-later"); err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, _ = gw.Write(archive.Bytes())
+	_ = gw.Close()
+
+	// The global middleware leaves the body to the restore handler, which
+	// caps it as sent and once decoded.
+	handler := decompressRequests(limitBody(1024)(router))
+	rec := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/restore", &compressed)
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("Content-Encoding", "gzip")
+	handler.ServeHTTP(rec, request)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	keys, err := hostDB.ListAPIKeys()
+	if err != nil {
+		t.Fatalf("failed to list api keys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Name != "exported" {
+		t.Fatalf("expected only the exported key, got %+v", keys)
+	}
+
+	rec = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/api/restore", strings.NewReader("compressed"))
+	request.Header.Set("Content-Encoding", "zstd")
+	handler.ServeHTTP(rec, request)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected status 415 for an unknown encoding, got %d", rec.Code)
+	}
+}
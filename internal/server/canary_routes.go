@@ -0,0 +1,185 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// canaryHeader marks responses served by a plugin's canary instead of its live version.
+const canaryHeader = "X-Cortex-Canary"
+
+// pluginCanaryRoutes registers the endpoints that run a new plugin version
+// next to the live one, shift traffic to it, and promote or roll it back.
+func pluginCanaryRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader, installer *plugin.Installer) {
+	// Current canary and its traffic split
+	router.Get("/api/plugins/{pluginID}/canary", func(writer http.ResponseWriter, request *http.Request) {
+		status, err := loader.Canary(chi.URLParam(request, "pluginID"))
+		if err != nil {
+			writeCanaryError(writer, err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": status})
+	})
+
+	// Start a canary, from a remote archive (url or name) or from the build
+	// already in the plugin's canary directory
+	router.Post("/api/plugins/{pluginID}/canary", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
+		var body struct {
+			URL       string `json:"url"`
+			Name      string `json:"name"`
+			SHA256    string `json:"sha256"`
+			Signature string `json:"signature"`
+			plugin.Rollout
+		}
+
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			writePluginError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		if body.URL != "" && body.Name != "" {
+			writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "at most one of url or name may be given")
+			return
+		}
+
+		if _, ok := registry.Get(pluginID); !ok {
+			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+			return
+		}
+
+		if body.URL != "" || body.Name != "" {
+			source := &plugin.InstallSource{URL: body.URL, SHA256: body.SHA256, Signature: body.Signature}
+			if body.URL == "" {
+				resolved, err := installer.Resolve(body.Name)
+				if err != nil {
+					writeInstallError(writer, err)
+					return
+				}
+				source = resolved
+			} else if body.SHA256 == "" {
+				writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "sha256 is required when installing from a url")
+				return
+			}
+
+			if err := installer.InstallCanary(*source, pluginID); err != nil {
+				writeInstallError(writer, err)
+				return
+			}
+		}
+
+		if err := loader.StartCanary(pluginID, body.Rollout); err != nil {
+			writeCanaryError(writer, err)
+			return
+		}
+
+		status, err := loader.Canary(pluginID)
+		if err != nil {
+			writeCanaryError(writer, err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": status})
+	})
+
+	// Change the share of traffic the running canary receives
+	router.Put("/api/plugins/{pluginID}/canary", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
+		var rollout plugin.Rollout
+		if err := json.NewDecoder(request.Body).Decode(&rollout); err != nil {
+			writePluginError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		if err := loader.UpdateRollout(pluginID, rollout); err != nil {
+			writeCanaryError(writer, err)
+			return
+		}
+
+		status, err := loader.Canary(pluginID)
+		if err != nil {
+			writeCanaryError(writer, err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": status})
+	})
+
+	// Make the canary the live version
+	router.Post("/api/plugins/{pluginID}/canary/promote", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
+		if err := loader.PromoteCanary(pluginID); err != nil {
+			writeCanaryError(writer, err)
+			return
+		}
+
+		version := ""
+		if entry, ok := registry.Get(pluginID); ok {
+			version = entry.Manifest.Version
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":      pluginID,
+				"version": version,
+				"status":  "promoted",
+			},
+		})
+	})
+
+	// Stop the canary and discard its build
+	router.Delete("/api/plugins/{pluginID}/canary", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
+		if err := loader.RollbackCanary(pluginID); err != nil {
+			writeCanaryError(writer, err)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":     pluginID,
+				"status": "rolled_back",
+			},
+		})
+	})
+}
+
+// writeCanaryError maps canary lifecycle errors to API error responses.
+func writeCanaryError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, plugin.ErrPluginNotFound):
+		writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+	case errors.Is(err, plugin.ErrNoCanary):
+		writePluginError(writer, http.StatusNotFound, "NO_CANARY", "plugin has no running canary")
+	case errors.Is(err, plugin.ErrNoCanaryBuild):
+		writePluginError(writer, http.StatusNotFound, "NO_CANARY_BUILD", "no canary build found; give a url or name, or place the build in the plugin's canary directory")
+	case errors.Is(err, plugin.ErrCanaryEncrypted):
+		writePluginError(writer, http.StatusConflict, "CANARY_UNAVAILABLE", "canary rollouts are not available for plugins with an encrypted database")
+	case errors.Is(err, plugin.ErrInvalidRollout):
+		writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "percent must be between 0 and 100 and api_key_ids must be positive")
+	case errors.Is(err, plugin.ErrPromoteRestored):
+		slog.Error("promoting canary failed", "error", err)
+		writePluginError(writer, http.StatusInternalServerError, "PROMOTE_ERROR", "canary failed to load as the live version; the previous version was restored")
+	default:
+		slog.Error("canary operation failed", "error", err)
+		writePluginError(writer, http.StatusInternalServerError, "CANARY_ERROR", "canary operation failed")
+	}
+}
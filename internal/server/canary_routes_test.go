@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+func TestPluginCanary_NoCanary(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes", plugin.PermissionDBRead)
+	router := newPluginRouter(t, registry)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/notes/canary", nil))
+	if rec.Code != http.StatusNotFound || decodeErrorCode(t, rec) != "NO_CANARY" {
+		t.Errorf("expected 404 NO_CANARY, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Without a url, name or build on disk there is nothing to start.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/notes/canary", nil))
+	if rec.Code != http.StatusNotFound || decodeErrorCode(t, rec) != "NO_CANARY_BUILD" {
+		t.Errorf("expected 404 NO_CANARY_BUILD, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/missing/canary", nil))
+	if rec.Code != http.StatusNotFound || decodeErrorCode(t, rec) != "NOT_FOUND" {
+		t.Errorf("expected 404 NOT_FOUND for an unknown plugin, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPluginCanary_RolloutAndRollback(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes", plugin.PermissionDBRead)
+	registerStubPlugin(registry, plugin.CanaryKey("notes"), plugin.PermissionDBRead)
+	router := newPluginRouter(t, registry)
+
+	// A running canary gets no traffic until a rollout sends some its way.
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/notes/items", nil))
+	if rec.Header().Get(canaryHeader) != "" {
+		t.Errorf("expected the live plugin to serve requests without a rollout")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/plugins/notes/canary", strings.NewReader(`{"percent":101}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid percent, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/plugins/notes/canary", strings.NewReader(`{"percent":100}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"percent":100`) {
+		t.Errorf("expected the new rollout in the response, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/notes/items", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(canaryHeader) != "true" {
+		t.Errorf("expected the canary to serve the request, got %d with header %q", rec.Code, rec.Header().Get(canaryHeader))
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/plugins/notes/canary", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/notes/items", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(canaryHeader) != "" {
+		t.Errorf("expected the live plugin to serve requests after rollback, got %d with header %q", rec.Code, rec.Header().Get(canaryHeader))
+	}
+}
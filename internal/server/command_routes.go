@@ -0,0 +1,118 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// CommandGroup is the CLI commands of one plugin, as listed by GET /api/commands.
+type CommandGroup struct {
+	Plugin   string           `json:"plugin"`
+	CLI      string           `json:"cli"`
+	Commands []CommandSummary `json:"commands"`
+}
+
+// CommandSummary describes a plugin command for the CLI's help.
+type CommandSummary struct {
+	Name        string              `json:"name"`
+	Usage       string              `json:"usage"`
+	Description string              `json:"description,omitempty"`
+	Args        []plugin.CommandArg `json:"args,omitempty"`
+}
+
+// pluginCommandRoutes registers the endpoints behind plugin CLI commands:
+// the list `cortex` resolves subcommands against, and the run of one command.
+func pluginCommandRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader) {
+	// Every loaded plugin's commands, by CLI group
+	router.Get("/api/commands", func(writer http.ResponseWriter, request *http.Request) {
+		groups := []CommandGroup{}
+		for _, manifest := range registry.List() {
+			if len(manifest.Commands) == 0 {
+				continue
+			}
+			group := CommandGroup{Plugin: manifest.ID, CLI: manifest.CLIName(), Commands: make([]CommandSummary, 0, len(manifest.Commands))}
+			for _, command := range manifest.Commands {
+				group.Commands = append(group.Commands, CommandSummary{
+					Name:        command.Name,
+					Usage:       command.Usage(),
+					Description: command.Description,
+					Args:        command.Args,
+				})
+			}
+			groups = append(groups, group)
+		}
+		slices.SortFunc(groups, func(a, b CommandGroup) int { return cmp.Compare(a.CLI, b.CLI) })
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": groups})
+	})
+
+	// Run a command with {"args": [...]}, answered with the plugin's response
+	router.Post("/api/plugins/{pluginID}/commands/{command}", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+		target := registry.RouteTarget(pluginID, requestAPIKeyID(request))
+		entry, ok := registry.Get(target)
+		if !ok {
+			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+			return
+		}
+
+		command, ok := entry.Manifest.FindCommand(chi.URLParam(request, "command"))
+		if !ok {
+			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin has no such command")
+			return
+		}
+
+		var body struct {
+			Args []string `json:"args"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, plugin.MaxAPIBodySize)).Decode(&body); err != nil {
+			writePluginError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+		apiRequest, err := command.Request(body.Args)
+		if err != nil {
+			writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+
+		// The command is held to the permissions of the request it makes
+		if required := plugin.RequiredPermissionForMethod(apiRequest.Method); required != "" {
+			if err := plugin.RequirePermission(entry.Manifest, required); err != nil {
+				writePluginError(writer, http.StatusForbidden, "PERMISSION_DENIED", "plugin has not declared the "+required+" permission")
+				return
+			}
+		}
+
+		entry, err = loader.Acquire(target)
+		if err != nil {
+			writeAcquireError(writer, err)
+			return
+		}
+
+		ctx, cancel := loader.RequestContext(request.Context())
+		defer cancel()
+		response, err := entry.Plugin.HandleAPI(ctx, apiRequest)
+		if crashed := loader.Release(target, entry, err); crashed {
+			writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin stopped while handling the request")
+			return
+		}
+		if errors.Is(err, plugin.ErrPluginTimeout) {
+			writePluginError(writer, http.StatusGatewayTimeout, "PLUGIN_TIMEOUT", "plugin did not answer in time")
+			return
+		}
+		if err != nil {
+			writePluginError(writer, http.StatusInternalServerError, "PLUGIN_ERROR", "plugin command failed")
+			return
+		}
+
+		writePluginResponse(writer, response, target != pluginID)
+	})
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// commandStubPlugin records the API request a command is turned into.
+type commandStubPlugin struct {
+	stubPlugin
+	request *plugin.APIRequest
+}
+
+func (p *commandStubPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	p.request = request
+	return &plugin.APIResponse{StatusCode: http.StatusCreated, Body: []byte(`{"data":{"id":1}}`), ContentType: "application/json"}, nil
+}
+
+var addExpenseCommand = plugin.Command{
+	Name:        "add-expense",
+	Description: "Record an expense",
+	Method:      http.MethodPost,
+	Path:        "/transactions",
+	Args:        []plugin.CommandArg{{Name: "amount", Type: plugin.CommandArgNumber}, {Name: "category"}},
+	Fields:      map[string]any{"type": "expense"},
+}
+
+func registerCommandStub(registry *plugin.Registry, manifest *plugin.Manifest) *commandStubPlugin {
+	registry.Register(manifest.ID, nil, manifest)
+	entry, _ := registry.Get(manifest.ID)
+	stub := &commandStubPlugin{}
+	entry.Plugin = stub
+	return stub
+}
+
+func newCommandRouter(t *testing.T, registry *plugin.Registry) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	router := chi.NewRouter()
+	pluginCommandRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry))
+	return router
+}
+
+func TestCommands_ListsCommandsByCLIGroup(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerCommandStub(registry, &plugin.Manifest{ID: "finance-tracker", CLI: "finance", Commands: []plugin.Command{addExpenseCommand}})
+	registerCommandStub(registry, &plugin.Manifest{ID: "project-hub"})
+
+	router := newCommandRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/commands", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data []CommandGroup `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("expected only the plugin with commands, got %+v", body.Data)
+	}
+	group := body.Data[0]
+	if group.Plugin != "finance-tracker" || group.CLI != "finance" || len(group.Commands) != 1 {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	if usage := group.Commands[0].Usage; usage != "add-expense <amount> <category>" {
+		t.Errorf("unexpected usage %q", usage)
+	}
+}
+
+func TestCommands_RunsCommandThroughHandleAPI(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := registerCommandStub(registry, &plugin.Manifest{
+		ID:          "finance-tracker",
+		CLI:         "finance",
+		Permissions: []string{plugin.PermissionDBWrite},
+		Commands:    []plugin.Command{addExpenseCommand},
+	})
+
+	router := newCommandRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/finance-tracker/commands/add-expense", strings.NewReader(`{"args":["12.5","coffee"]}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected the plugin's status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if stub.request == nil || stub.request.Method != http.MethodPost || stub.request.Path != "/transactions" {
+		t.Fatalf("unexpected request to the plugin: %+v", stub.request)
+	}
+	var sent map[string]any
+	if err := json.Unmarshal(stub.request.Body, &sent); err != nil {
+		t.Fatalf("failed to parse the plugin request body: %v", err)
+	}
+	if sent["amount"] != 12.5 || sent["category"] != "coffee" || sent["type"] != "expense" {
+		t.Errorf("unexpected plugin request body %v", sent)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"missing argument", "/api/plugins/finance-tracker/commands/add-expense", `{"args":["12.5"]}`, http.StatusBadRequest},
+		{"invalid number", "/api/plugins/finance-tracker/commands/add-expense", `{"args":["lots","coffee"]}`, http.StatusBadRequest},
+		{"unknown command", "/api/plugins/finance-tracker/commands/remove", `{"args":[]}`, http.StatusNotFound},
+		{"unknown plugin", "/api/plugins/missing/commands/add-expense", `{"args":[]}`, http.StatusNotFound},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body)))
+		if rec.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.status, rec.Code)
+		}
+	}
+}
+
+func TestCommands_RequirePermissionOfTheirRequest(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := registerCommandStub(registry, &plugin.Manifest{
+		ID:          "finance-tracker",
+		Permissions: []string{plugin.PermissionDBRead},
+		Commands:    []plugin.Command{addExpenseCommand},
+	})
+
+	router := newCommandRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/finance-tracker/commands/add-expense", strings.NewReader(`{"args":["12.5","coffee"]}`)))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if stub.request != nil {
+		t.Error("expected the command not to reach the plugin")
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// compressionLevel trades CPU for size on compressed responses. 5 keeps most
+// of the gain of the higher levels at a fraction of their cost.
+const compressionLevel = 5
+
+// compressibleTypes are the response types worth compressing. Archives,
+// images and other already compressed payloads are sent as they are.
+var compressibleTypes = []string{
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/manifest+json",
+	"image/svg+xml",
+	"text/html",
+	"text/css",
+	"text/csv",
+	"text/plain",
+	"text/markdown",
+	"text/javascript",
+	"text/xml",
+}
+
+// compressResponses compresses responses of the compressibleTypes with
+// brotli, gzip or deflate, preferred in that order among those the client
+// accepts. Responses that already carry a
+// Content-Encoding, such as a plugin's own, are left alone, and so are range
+// requests, whose Content-Range refers to the uncompressed body.
+func compressResponses() func(http.Handler) http.Handler {
+	compressor := middleware.NewCompressor(compressionLevel, compressibleTypes...)
+	// HTTP's deflate is zlib-wrapped, not the raw stream chi writes for it.
+	// Setting an encoder gives it precedence, so gzip is set again after it,
+	// and brotli last to be the preferred one.
+	compressor.SetEncoder("deflate", func(writer io.Writer, level int) io.Writer {
+		zw, err := zlib.NewWriterLevel(writer, level)
+		if err != nil {
+			return nil
+		}
+		return zw
+	})
+	compressor.SetEncoder("gzip", func(writer io.Writer, level int) io.Writer {
+		gw, err := gzip.NewWriterLevel(writer, level)
+		if err != nil {
+			return nil
+		}
+		return gw
+	})
+	compressor.SetEncoder("br", func(writer io.Writer, level int) io.Writer {
+		return brotli.NewWriterLevel(writer, level)
+	})
+	return func(next http.Handler) http.Handler {
+		compressed := compressor.Handler(next)
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.Header.Get("Range") != "" {
+				next.ServeHTTP(writer, request)
+				return
+			}
+			compressed.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// decompressRequests decodes request bodies sent with a br, gzip or deflate
+// Content-Encoding, so handlers always read the body as sent. Other
+// encodings are answered with 415 and an Accept-Encoding header listing the
+// supported ones. It runs before limitBody, which then caps the decoded
+// size rather than the compressed one. The routes exempt from limitBody
+// decode their bodies themselves, so they can cap both sizes.
+func decompressRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if bodyLimitExempt[request.URL.Path] {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if !decodeRequestBody(writer, request, 0) {
+			return
+		}
+		defer request.Body.Close()
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// decodeRequestBody replaces the body of a request sent with a br, gzip or
+// deflate Content-Encoding by the decoded stream, cut off at maxBytes when it
+// is above zero. It answers requests it cannot decode itself and then
+// returns false.
+func decodeRequestBody(writer http.ResponseWriter, request *http.Request, maxBytes int64) bool {
+	encoding := strings.ToLower(strings.TrimSpace(request.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		return true
+	}
+
+	var body io.ReadCloser
+	switch encoding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(request.Body)
+		if err != nil {
+			writeLimitError(writer, http.StatusBadRequest, "INVALID_BODY", "request body is not valid gzip")
+			return false
+		}
+		body = reader
+	case "deflate":
+		reader, err := zlib.NewReader(request.Body)
+		if err != nil {
+			writeLimitError(writer, http.StatusBadRequest, "INVALID_BODY", "request body is not valid deflate")
+			return false
+		}
+		body = reader
+	case "br":
+		// A brotli stream has no header to check up front; a malformed
+		// one fails when the handler reads it.
+		body = io.NopCloser(brotli.NewReader(request.Body))
+	default:
+		writer.Header().Set("Accept-Encoding", "br, gzip, deflate")
+		writeLimitError(writer, http.StatusUnsupportedMediaType, "UNSUPPORTED_ENCODING",
+			"request bodies must be sent uncompressed or with br, gzip or deflate")
+		return false
+	}
+	if maxBytes > 0 {
+		body = http.MaxBytesReader(writer, body, maxBytes)
+	}
+
+	request.Body = body
+	request.ContentLength = -1
+	request.Header.Del("Content-Encoding")
+	request.Header.Del("Content-Length")
+	return true
+}
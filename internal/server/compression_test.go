@@ -0,0 +1,201 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestCompressResponses(t *testing.T) {
+	payload := strings.Repeat(`{"description":"groceries","amount":-42.5},`, 200)
+	handler := compressResponses()(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/archive" {
+			writer.Header().Set("Content-Type", "application/zip")
+		} else {
+			writer.Header().Set("Content-Type", "application/json")
+		}
+		_, _ = io.WriteString(writer, payload)
+	}))
+
+	serve := func(path string, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/transactions", "gzip, deflate, br")
+	if rec.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("expected br to be preferred, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Body.Len() >= len(payload) {
+		t.Errorf("expected a smaller body, got %d bytes for %d", rec.Body.Len(), len(payload))
+	}
+	if body, err := io.ReadAll(brotli.NewReader(rec.Body)); err != nil || string(body) != payload {
+		t.Errorf("expected the decompressed body to match, got error %v", err)
+	}
+
+	rec = serve("/transactions", "gzip, deflate")
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip to be preferred over deflate, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("reading gzip: %v", err)
+	}
+	if body, _ := io.ReadAll(reader); string(body) != payload {
+		t.Error("expected the decompressed body to match")
+	}
+
+	rec = serve("/transactions", "deflate")
+	if rec.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expected deflate, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	zr, err := zlib.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("expected deflate to be zlib-wrapped: %v", err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != payload {
+		t.Error("expected the inflated body to match")
+	}
+
+	if rec := serve("/transactions", ""); rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != payload {
+		t.Error("expected no compression without Accept-Encoding")
+	}
+	if rec := serve("/archive", "gzip"); rec.Header().Get("Content-Encoding") != "" {
+		t.Error("expected archives to be sent as they are")
+	}
+}
+
+func TestDecompressRequests(t *testing.T) {
+	var received string
+	var readErr error
+	handler := decompressRequests(limitBody(64)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body []byte
+		body, readErr = io.ReadAll(request.Body)
+		received = string(body)
+	})))
+
+	gzipped := func(content string) *bytes.Buffer {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		_, _ = io.WriteString(gw, content)
+		_ = gw.Close()
+		return &buf
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/notes", gzipped(`{"title":"Groceries"}`))
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || readErr != nil || received != `{"title":"Groceries"}` {
+		t.Fatalf("expected the decoded body, got %d %q %v", rec.Code, received, readErr)
+	}
+
+	var deflated bytes.Buffer
+	zw := zlib.NewWriter(&deflated)
+	_, _ = io.WriteString(zw, "deflated")
+	_ = zw.Close()
+	req = httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/notes", &deflated)
+	req.Header.Set("Content-Encoding", "deflate")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if readErr != nil || received != "deflated" {
+		t.Errorf("expected the inflated body, got %q %v", received, readErr)
+	}
+
+	var brotlied bytes.Buffer
+	bw := brotli.NewWriter(&brotlied)
+	_, _ = io.WriteString(bw, "brotli")
+	_ = bw.Close()
+	req = httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/notes", &brotlied)
+	req.Header.Set("Content-Encoding", "br")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if readErr != nil || received != "brotli" {
+		t.Errorf("expected the brotli body decoded, got %q %v", received, readErr)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/notes", strings.NewReader("not brotli"))
+	req.Header.Set("Content-Encoding", "br")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if readErr == nil {
+		t.Error("expected a malformed brotli body to fail when read")
+	}
+
+	// The limit applies to the decoded size, not the compressed one
+	req = httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/notes", gzipped(strings.Repeat("a", 1000)))
+	req.Header.Set("Content-Encoding", "gzip")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if readErr == nil {
+		t.Error("expected a body decoding past the limit to fail")
+	}
+
+	// Restores decode their bodies themselves
+	req = httptest.NewRequest(http.MethodPost, "/api/restore", gzipped("archive"))
+	req.Header.Set("Content-Encoding", "gzip")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if readErr != nil || received == "archive" {
+		t.Errorf("expected the restore body to be passed on as sent, got %q %v", received, readErr)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/notes", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusBadRequest || code != "INVALID_BODY" {
+		t.Errorf("expected 400 INVALID_BODY, got %d %s", rec.Code, code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/notes", strings.NewReader("compressed"))
+	req.Header.Set("Content-Encoding", "zstd")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusUnsupportedMediaType || code != "UNSUPPORTED_ENCODING" {
+		t.Errorf("expected 415 UNSUPPORTED_ENCODING, got %d %s", rec.Code, code)
+	}
+	if rec.Header().Get("Accept-Encoding") != "br, gzip, deflate" {
+		t.Errorf("expected the supported encodings to be listed, got %q", rec.Header().Get("Accept-Encoding"))
+	}
+}
+
+func TestDecodeRequestBody_CapsDecodedSize(t *testing.T) {
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, _ = io.WriteString(gw, strings.Repeat("a", 1<<20))
+	_ = gw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/restore", &compressed)
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	if !decodeRequestBody(rec, req, 1024) {
+		t.Fatalf("expected the body to be decoded, got %d", rec.Code)
+	}
+	if req.Header.Get("Content-Encoding") != "" || req.ContentLength != -1 {
+		t.Errorf("expected the decoded body to drop its encoding and length, got %q %d", req.Header.Get("Content-Encoding"), req.ContentLength)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) || len(body) != 1024 {
+		t.Errorf("expected decoding to stop at 1024 bytes, read %d with %v", len(body), err)
+	}
+
+	// Without a cap, or an encoding, the body is read in full.
+	req = httptest.NewRequest(http.MethodPost, "/api/restore", strings.NewReader("plain"))
+	if !decodeRequestBody(rec, req, 1) {
+		t.Fatal("expected an unencoded body to be accepted")
+	}
+	if body, err := io.ReadAll(req.Body); err != nil || string(body) != "plain" {
+		t.Errorf("expected the unencoded body as sent, got %q %v", body, err)
+	}
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/config"
+)
+
+// configRoutes registers the endpoint showing the configuration the host runs
+// with, after the config file and environment overrides were applied.
+func configRoutes(router chi.Router, cfg *config.Config) {
+	// GET /api/config -- effective configuration, with secrets reduced to whether they are set
+	router.Get("/api/config", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": cfg.Sanitized()})
+	})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/config"
+)
+
+func TestConfig_HidesSecrets(t *testing.T) {
+	cfg := &config.Config{
+		Port:         8443,
+		SMTPHost:     "smtp.example.com",
+		SMTPPassword: "hunter2",
+		DBPassphrase: "correct horse",
+		TLSCertFile:  "/etc/cortex/cert.pem",
+		TLSKeyFile:   "/etc/cortex/key.pem",
+	}
+	router := chi.NewRouter()
+	configRoutes(router, cfg)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/config", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "hunter2") || strings.Contains(body, "correct horse") {
+		t.Fatalf("expected secrets to be hidden, got %s", body)
+	}
+
+	var response struct {
+		Data struct {
+			Port int `json:"port"`
+			SMTP struct {
+				PasswordSet bool `json:"password_set"`
+			} `json:"smtp"`
+			TLS struct {
+				Enabled bool `json:"enabled"`
+			} `json:"tls"`
+			Database struct {
+				Encrypted bool `json:"encrypted"`
+			} `json:"database"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if response.Data.Port != 8443 || !response.Data.SMTP.PasswordSet || !response.Data.TLS.Enabled || !response.Data.Database.Encrypted {
+		t.Errorf("unexpected configuration: %+v", response.Data)
+	}
+}
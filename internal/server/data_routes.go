@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// pluginDataRoutes registers the data export and import endpoints of plugins
+// implementing DataPorter. An export is a self-describing JSON bundle that
+// another Cortex instance imports as is, replacing the plugin's data there.
+// Both go to the stable build, never to a canary.
+func pluginDataRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader, widgets *WidgetCache) {
+	// GET /api/plugins/{id}/export -- download the plugin's data as a bundle
+	router.Get("/api/plugins/{pluginID}/export", func(writer http.ResponseWriter, request *http.Request) {
+		var bundle *plugin.DataBundle
+		withDataPorter(writer, request, registry, loader, func(porter plugin.DataPorter, manifest *plugin.Manifest) error {
+			data, err := porter.ExportData()
+			if err != nil {
+				return err
+			}
+			bundle, err = plugin.NewDataBundle(manifest, data, time.Now())
+			return err
+		}, func() {
+			filename := fmt.Sprintf("%s-%s-%s.json", bundle.PluginID, bundle.PluginVersion, bundle.ExportedAt.Format("20060102-150405"))
+			writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+			_ = json.NewEncoder(writer).Encode(bundle)
+		})
+	})
+
+	// POST /api/plugins/{id}/import -- replace the plugin's data with a bundle's
+	router.Post("/api/plugins/{pluginID}/import", func(writer http.ResponseWriter, request *http.Request) {
+		var bundle plugin.DataBundle
+		if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, plugin.MaxAPIBodySize)).Decode(&bundle); err != nil {
+			writePluginError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		withDataPorter(writer, request, registry, loader, func(porter plugin.DataPorter, manifest *plugin.Manifest) error {
+			if err := bundle.CheckImport(manifest); err != nil {
+				return err
+			}
+			return porter.ImportData(bundle.PluginVersion, bundle.Data)
+		}, func() {
+			widgets.Invalidate(bundle.PluginID)
+			_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":           bundle.PluginID,
+					"status":       "imported",
+					"from_version": bundle.PluginVersion,
+					"exported_at":  bundle.ExportedAt,
+				},
+			})
+		})
+	})
+}
+
+// withDataPorter runs call on the plugin serving the request and calls write
+// once it succeeded. The plugin must declare the permission the request
+// method needs, as for requests proxied to HandleAPI.
+func withDataPorter(writer http.ResponseWriter, request *http.Request, registry *plugin.Registry, loader *plugin.Loader, call func(plugin.DataPorter, *plugin.Manifest) error, write func()) {
+	pluginID := chi.URLParam(request, "pluginID")
+	entry, ok := registry.Get(pluginID)
+	if !ok {
+		writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+		return
+	}
+
+	if required := plugin.RequiredPermissionForMethod(request.Method); required != "" {
+		if err := plugin.RequirePermission(entry.Manifest, required); err != nil {
+			writePluginError(writer, http.StatusForbidden, "PERMISSION_DENIED", "plugin has not declared the "+required+" permission")
+			return
+		}
+	}
+
+	entry, err := loader.Acquire(pluginID)
+	if err != nil {
+		writeAcquireError(writer, err)
+		return
+	}
+
+	porter, ok := entry.Plugin.(plugin.DataPorter)
+	if !ok {
+		loader.Release(pluginID, entry, nil)
+		writePluginError(writer, http.StatusNotImplemented, "NOT_IMPLEMENTED", "plugin does not support data export")
+		return
+	}
+
+	err = call(porter, entry.Manifest)
+	switch {
+	case errors.Is(err, plugin.ErrNotImplemented):
+		// Lacking an optional hook is not a plugin failure.
+		loader.Release(pluginID, entry, nil)
+		writePluginError(writer, http.StatusNotImplemented, "NOT_IMPLEMENTED", "plugin does not support data export")
+		return
+	case errors.Is(err, plugin.ErrInvalidBundle):
+		loader.Release(pluginID, entry, nil)
+		writePluginError(writer, http.StatusBadRequest, "INVALID_BUNDLE", err.Error())
+		return
+	case errors.Is(err, plugin.ErrBundleTooNew):
+		loader.Release(pluginID, entry, nil)
+		writePluginError(writer, http.StatusConflict, "VERSION_MISMATCH", err.Error()+"; update the plugin first")
+		return
+	case errors.Is(err, plugin.ErrInvalidData):
+		loader.Release(pluginID, entry, nil)
+		writePluginError(writer, http.StatusUnprocessableEntity, "INVALID_DATA", err.Error())
+		return
+	}
+	if crashed := loader.Release(pluginID, entry, err); crashed {
+		writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin stopped while handling the request")
+		return
+	}
+	if err != nil {
+		slog.Error("plugin data transfer failed", "plugin", pluginID, "method", request.Method, "error", err)
+		writePluginError(writer, http.StatusInternalServerError, "PLUGIN_ERROR", "plugin data transfer failed")
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	write()
+}
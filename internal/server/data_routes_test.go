@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// portingStubPlugin exports and imports an in-memory payload.
+type portingStubPlugin struct {
+	stubPlugin
+	data        []byte
+	fromVersion string
+}
+
+func (p *portingStubPlugin) ExportData() ([]byte, error) {
+	return p.data, nil
+}
+
+func (p *portingStubPlugin) ImportData(fromVersion string, data []byte) error {
+	if !bytes.HasPrefix(data, []byte(`{"notes"`)) {
+		return fmt.Errorf("%w: no notes", plugin.ErrInvalidData)
+	}
+	p.fromVersion, p.data = fromVersion, data
+	return nil
+}
+
+func newDataRouter(t *testing.T, registry *plugin.Registry) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	router := chi.NewRouter()
+	pluginDataRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry), NewWidgetCache(0, nil))
+	return router
+}
+
+func registerPortingStub(registry *plugin.Registry, version string, stub plugin.CortexPlugin) {
+	registry.Register("quick-notes", nil, &plugin.Manifest{ID: "quick-notes", Name: "Quick Notes", Version: version, Permissions: []string{plugin.PermissionDBWrite}})
+	entry, _ := registry.Get("quick-notes")
+	entry.Plugin = stub
+}
+
+func TestPluginData_ExportsAndImportsBundle(t *testing.T) {
+	source := plugin.NewRegistry()
+	registerPortingStub(source, "1.2.0", &portingStubPlugin{data: []byte(`{"notes":[{"id":1,"title":"Groceries"}]}`)})
+
+	rec := httptest.NewRecorder()
+	newDataRouter(t, source).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/quick-notes/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="quick-notes-1.2.0-`) {
+		t.Errorf("expected a bundle download, got Content-Disposition %q", disposition)
+	}
+	var bundle plugin.DataBundle
+	if err := json.Unmarshal(rec.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("failed to parse bundle: %v", err)
+	}
+	if bundle.Format != plugin.DataBundleFormat || bundle.PluginID != "quick-notes" || bundle.PluginVersion != "1.2.0" || bundle.ExportedAt.IsZero() {
+		t.Errorf("expected a self-describing bundle, got %+v", bundle)
+	}
+	if string(bundle.Data) != `{"notes":[{"id":1,"title":"Groceries"}]}` {
+		t.Errorf("expected the plugin's data in the bundle, got %s", bundle.Data)
+	}
+
+	// Another instance, with a newer build of the plugin, takes the bundle as is.
+	target := plugin.NewRegistry()
+	imported := &portingStubPlugin{}
+	registerPortingStub(target, "1.3.0", imported)
+
+	exported := rec.Body.Bytes()
+	rec = httptest.NewRecorder()
+	newDataRouter(t, target).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/import", bytes.NewReader(exported)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if imported.fromVersion != "1.2.0" || string(imported.data) != string(bundle.Data) {
+		t.Errorf("expected the plugin to import the data from 1.2.0, got %q from %q", imported.data, imported.fromVersion)
+	}
+}
+
+func TestPluginData_RejectsBundlesItCannotImport(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerPortingStub(registry, "1.2.0", &portingStubPlugin{})
+	router := newDataRouter(t, registry)
+
+	cases := []struct {
+		name string
+		body string
+		want int
+		code string
+	}{
+		{"not JSON", `{`, http.StatusBadRequest, "BAD_REQUEST"},
+		{"unknown format", `{"format":"sqlite","format_version":1,"plugin_id":"quick-notes","plugin_version":"1.0.0","data":{}}`, http.StatusBadRequest, "INVALID_BUNDLE"},
+		{"other plugin", `{"format":"cortex-plugin-data","format_version":1,"plugin_id":"project-hub","plugin_version":"1.0.0","data":{}}`, http.StatusBadRequest, "INVALID_BUNDLE"},
+		{"newer plugin", `{"format":"cortex-plugin-data","format_version":1,"plugin_id":"quick-notes","plugin_version":"2.0.0","data":{"notes":[]}}`, http.StatusConflict, "VERSION_MISMATCH"},
+		{"rejected data", `{"format":"cortex-plugin-data","format_version":1,"plugin_id":"quick-notes","plugin_version":"1.0.0","data":{"tasks":[]}}`, http.StatusUnprocessableEntity, "INVALID_DATA"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/import", strings.NewReader(tc.body)))
+			if rec.Code != tc.want || !strings.Contains(rec.Body.String(), `"`+tc.code+`"`) {
+				t.Errorf("expected %d %s, got %d: %s", tc.want, tc.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPluginData_PluginWithoutHook(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerPortingStub(registry, "1.0.0", &stubPlugin{})
+	router := newDataRouter(t, registry)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/quick-notes/export", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("expected status 501, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/missing/export", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown plugin, got %d", rec.Code)
+	}
+}
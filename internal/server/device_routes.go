@@ -0,0 +1,324 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	// deviceTokenHeader carries the token issued when a device registers.
+	deviceTokenHeader = "X-Device-Token"
+	// deviceTokenPrefix makes device tokens recognizable in logs and password managers.
+	deviceTokenPrefix = "cxd_"
+	// deviceCookieName carries the device token of a browser that signed in,
+	// which cannot set headers on page loads and WebSocket handshakes.
+	deviceCookieName = "cortex_device"
+	// deviceCookieMaxAge is the longest lifetime browsers accept for a cookie.
+	deviceCookieMaxAge = 400 * 24 * time.Hour
+	// deviceTouchInterval throttles last_seen_at writes to one per device per minute.
+	deviceTouchInterval = time.Minute
+	maxDeviceNameLength = 100
+	maxPlatformLength   = 50
+)
+
+type deviceContextKey struct{}
+
+// deviceRoutes registers host-level device registration endpoints.
+func deviceRoutes(router chi.Router, hostDB *db.HostDB) {
+	// GET /api/devices -- list registered devices with last-seen/last-sync
+	router.Get("/api/devices", func(writer http.ResponseWriter, request *http.Request) {
+		devices, err := hostDB.ListDevices()
+		if err != nil {
+			writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list devices")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": devices})
+	})
+
+	// POST /api/devices -- register a device and issue its token (shown only once)
+	router.Post("/api/devices", func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Name     string `json:"name"`
+			Platform string `json:"platform"`
+		}
+
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			writeDeviceError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		body.Name = strings.TrimSpace(body.Name)
+		body.Platform = strings.ToLower(strings.TrimSpace(body.Platform))
+
+		if body.Name == "" || len(body.Name) > maxDeviceNameLength {
+			writeDeviceError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "name is required and must be at most 100 characters")
+			return
+		}
+		if body.Platform == "" || len(body.Platform) > maxPlatformLength {
+			writeDeviceError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "platform is required and must be at most 50 characters")
+			return
+		}
+
+		device, token, err := IssueDevice(hostDB, body.Name, body.Platform)
+		if err != nil {
+			writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to register device")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"device": device,
+				"token":  token,
+			},
+		})
+	})
+
+	// POST /api/devices/signin -- keep a device token in a cookie, so the web UI
+	// and its WebSocket are sent it without setting headers
+	router.Post("/api/devices/signin", func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Token string `json:"token"`
+		}
+
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			writeDeviceError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		token := strings.TrimSpace(body.Token)
+		device, err := hostDB.GetDeviceByTokenHash(hashToken(token))
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to verify device token")
+			return
+		}
+		if err != nil || device.RevokedAt != nil {
+			writeDeviceError(writer, http.StatusUnauthorized, "UNAUTHORIZED", "device token is invalid or revoked")
+			return
+		}
+
+		http.SetCookie(writer, &http.Cookie{
+			Name:     deviceCookieName,
+			Value:    token,
+			Path:     "/",
+			MaxAge:   int(deviceCookieMaxAge.Seconds()),
+			HttpOnly: true,
+			Secure:   request.TLS != nil,
+			SameSite: http.SameSiteStrictMode,
+		})
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": device})
+	})
+
+	// POST /api/devices/signout -- drop the cookie set at sign-in
+	router.Post("/api/devices/signout", func(writer http.ResponseWriter, request *http.Request) {
+		http.SetCookie(writer, &http.Cookie{
+			Name:     deviceCookieName,
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   request.TLS != nil,
+			SameSite: http.SameSiteStrictMode,
+		})
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	// POST /api/devices/sync -- the calling device reports a completed sync
+	router.Post("/api/devices/sync", func(writer http.ResponseWriter, request *http.Request) {
+		device, ok := deviceFromContext(request.Context())
+		if !ok {
+			writeDeviceError(writer, http.StatusUnauthorized, "UNAUTHORIZED", "missing X-Device-Token header")
+			return
+		}
+
+		if err := hostDB.MarkDeviceSynced(device.ID); err != nil {
+			writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to record sync")
+			return
+		}
+
+		updated, err := hostDB.GetDevice(device.ID)
+		if err != nil {
+			writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get device")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": updated})
+	})
+
+	// DELETE /api/devices/{deviceID} -- revoke a device token remotely
+	router.Delete("/api/devices/{deviceID}", func(writer http.ResponseWriter, request *http.Request) {
+		deviceID, err := strconv.ParseInt(chi.URLParam(request, "deviceID"), 10, 64)
+		if err != nil || deviceID <= 0 {
+			writeDeviceError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid device ID")
+			return
+		}
+
+		if err := hostDB.RevokeDevice(deviceID); err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				writeDeviceError(writer, http.StatusNotFound, "NOT_FOUND", "device not found")
+				return
+			}
+			writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to revoke device")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": deviceID, "status": "revoked"},
+		})
+	})
+}
+
+// deviceTracking resolves the device token of every request, sent in the
+// X-Device-Token header or, by browsers that signed in, the device cookie.
+// While no device can connect, requests without a token pass through
+// untouched. Once one can, requests for data need a device token or an API
+// key, so a revoked device cannot get back in by leaving the header off.
+// Unknown or revoked tokens are rejected with 401; a stale cookie counts as
+// no token, so the browser can sign in again. Valid tokens update the
+// device's last-seen timestamp, IP and user agent.
+func deviceTracking(hostDB *db.HostDB, registry *plugin.Registry) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if token, fromCookie := deviceToken(request); token != "" {
+				device, err := hostDB.GetDeviceByTokenHash(hashToken(token))
+				if err != nil && !errors.Is(err, db.ErrNotFound) {
+					writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to verify device token")
+					return
+				}
+
+				if err == nil && device.RevokedAt == nil {
+					ip, userAgent := clientInfo(request)
+					if err := hostDB.TouchDevice(device.ID, deviceTouchInterval, ip, userAgent); err != nil {
+						slog.Warn("updating device last seen", "device", device.ID, "error", err)
+					}
+
+					ctx := context.WithValue(request.Context(), deviceContextKey{}, device)
+					next.ServeHTTP(writer, request.WithContext(ctx))
+					return
+				}
+
+				if !fromCookie {
+					writeDeviceError(writer, http.StatusUnauthorized, "UNAUTHORIZED", "device token is invalid or revoked")
+					return
+				}
+			}
+
+			// A request with an API key is left for apiKeyAuth to verify.
+			if _, ok := bearerAPIKey(request); ok || !credentialRequired(request, registry) {
+				next.ServeHTTP(writer, request)
+				return
+			}
+			registered, err := hostDB.HasDevices()
+			if err != nil {
+				writeDeviceError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to verify device token")
+				return
+			}
+			if registered {
+				writeDeviceError(writer, http.StatusUnauthorized, "UNAUTHORIZED", "a device token or API key is required")
+				return
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// deviceToken returns the device token of a request and whether it came
+// from the device cookie rather than the X-Device-Token header.
+func deviceToken(request *http.Request) (token string, fromCookie bool) {
+	if token := request.Header.Get(deviceTokenHeader); token != "" {
+		return token, false
+	}
+	if cookie, err := request.Cookie(deviceCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+	return "", false
+}
+
+// credentialRequired reports whether a request reaches data that only
+// registered devices and API keys may read once they are in use: the API
+// except its health check, plugin route aliases and the lite pages. The
+// frontend's static files, shared widget links and signing in and out stay
+// public.
+func credentialRequired(request *http.Request, registry *plugin.Registry) bool {
+	path := request.URL.Path
+	switch {
+	case path == "/api/health", path == "/api/devices/signin", path == "/api/devices/signout":
+		return false
+	case strings.HasPrefix(path, "/api/"), path == "/lite", strings.HasPrefix(path, "/lite/"):
+		return true
+	}
+	if registry == nil {
+		return false
+	}
+	_, _, ok := registry.ResolveRoute(path)
+	return ok
+}
+
+// deviceFromContext returns the device resolved by deviceTracking, if any.
+func deviceFromContext(ctx context.Context) (*db.Device, bool) {
+	device, ok := ctx.Value(deviceContextKey{}).(*db.Device)
+	return device, ok
+}
+
+// IssueDevice registers a device and returns it with its token, which is not
+// stored and cannot be shown again. Besides POST /api/devices, `cortex device
+// add` uses it to get back into an instance nobody can sign in to anymore.
+func IssueDevice(hostDB *db.HostDB, name string, platform string) (*db.Device, string, error) {
+	token, err := generateToken(deviceTokenPrefix)
+	if err != nil {
+		return nil, "", fmt.Errorf("generating device token: %w", err)
+	}
+
+	device, err := hostDB.CreateDevice(name, platform, hashToken(token))
+	if err != nil {
+		return nil, "", err
+	}
+	return device, token, nil
+}
+
+// generateToken returns a random 256-bit token encoded as hex with the given prefix.
+func generateToken(prefix string) (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(buffer), nil
+}
+
+// hashToken returns the SHA-256 hex digest used to store and look up tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeDeviceError writes a standardized error JSON response for device endpoints.
+func writeDeviceError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
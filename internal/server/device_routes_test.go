@@ -0,0 +1,341 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// newDeviceRouter creates a minimal chi router with device tracking and device routes registered.
+func newDeviceRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	hostDB, err := db.NewHostDB(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	router := chi.NewRouter()
+	router.Use(deviceTracking(hostDB, nil))
+	deviceRoutes(router, hostDB)
+	return router
+}
+
+// deviceCredential returns the header that authenticates a request as a device.
+func deviceCredential(token string) http.Header {
+	return http.Header{deviceTokenHeader: {token}}
+}
+
+// registerDevice registers a device through the API, authenticated with
+// credential once a device or key exists, and returns its ID and token.
+func registerDevice(t *testing.T, router *chi.Mux, name string, credential http.Header) (int64, string) {
+	t.Helper()
+
+	body := `{"name": "` + name + `", "platform": "iOS"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/devices", strings.NewReader(body))
+	for header, values := range credential {
+		req.Header[header] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data struct {
+			Device db.Device `json:"device"`
+			Token  string    `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse register response: %v", err)
+	}
+
+	return response.Data.Device.ID, response.Data.Token
+}
+
+func TestRegisterDevice(t *testing.T) {
+	router := newDeviceRouter(t)
+
+	id, token := registerDevice(t, router, "Old phone", nil)
+
+	if id == 0 {
+		t.Error("expected device ID to be set")
+	}
+	if !strings.HasPrefix(token, deviceTokenPrefix) {
+		t.Errorf("expected token with prefix %q, got %q", deviceTokenPrefix, token)
+	}
+	registerDevice(t, router, "New phone", deviceCredential(token))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/devices", nil)
+	req.Header.Set(deviceTokenHeader, token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response struct {
+		Data []db.Device `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse list response: %v", err)
+	}
+
+	if len(response.Data) != 2 || response.Data[0].Name != "New phone" {
+		t.Fatalf("expected 2 devices, newest first, got %+v", response.Data)
+	}
+	if response.Data[0].Platform != "ios" {
+		t.Errorf("expected platform to be normalized to 'ios', got %q", response.Data[0].Platform)
+	}
+	if response.Data[0].LastSeenAt != nil {
+		t.Error("expected last_seen_at to be null before the device is used")
+	}
+}
+
+func TestRegisterDevice_Validation(t *testing.T) {
+	router := newDeviceRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/devices", strings.NewReader(`{"name": "", "platform": "android"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse error body: %v", err)
+	}
+	if body.Error.Code != "VALIDATION_ERROR" {
+		t.Errorf("expected error code 'VALIDATION_ERROR', got '%s'", body.Error.Code)
+	}
+}
+
+func TestDeviceTracking_UpdatesLastSeenAndSync(t *testing.T) {
+	router := newDeviceRouter(t)
+	_, token := registerDevice(t, router, "Laptop", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/devices/sync", nil)
+	req.Header.Set(deviceTokenHeader, token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data db.Device `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse sync response: %v", err)
+	}
+
+	if response.Data.LastSeenAt == nil {
+		t.Error("expected last_seen_at to be set")
+	}
+	if response.Data.LastSyncAt == nil {
+		t.Error("expected last_sync_at to be set")
+	}
+}
+
+func TestDeviceSync_WithoutToken(t *testing.T) {
+	router := newDeviceRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/devices/sync", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestRevokeDevice_LocksOutToken(t *testing.T) {
+	router := newDeviceRouter(t)
+	_, laptop := registerDevice(t, router, "Laptop", nil)
+	id, token := registerDevice(t, router, "Old phone", deviceCredential(laptop))
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/api/devices/"+strconv.FormatInt(id, 10), nil)
+	deleteReq.Header.Set(deviceTokenHeader, laptop)
+	deleteRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteRec, deleteReq)
+
+	if deleteRec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", deleteRec.Code, deleteRec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/devices", nil)
+	req.Header.Set(deviceTokenHeader, token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected revoked token to get 401, got %d", rec.Code)
+	}
+
+	// Leaving the header off does not get the revoked device back in.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/devices", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a request without a token to get 401, got %d", rec.Code)
+	}
+}
+
+func TestDeviceTracking_RequiresTokenOnceRegistered(t *testing.T) {
+	router := newDeviceRouter(t)
+	router.Get("/api/health", func(writer http.ResponseWriter, request *http.Request) {})
+	router.Get("/app.js", func(writer http.ResponseWriter, request *http.Request) {})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/devices", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected requests without a token to pass before any device is registered, got %d", rec.Code)
+	}
+
+	id, token := registerDevice(t, router, "Phone", nil)
+
+	for path, want := range map[string]int{
+		"/api/devices":       http.StatusUnauthorized,
+		"/api/plugins/notes": http.StatusUnauthorized,
+		"/lite/notes":        http.StatusUnauthorized,
+		"/api/health":        http.StatusOK,
+		"/app.js":            http.StatusOK,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s without a token: expected status %d, got %d", path, want, rec.Code)
+		}
+	}
+
+	// Once no device can connect, the instance is open again.
+	req := httptest.NewRequest(http.MethodDelete, "/api/devices/"+strconv.FormatInt(id, 10), nil)
+	req.Header.Set(deviceTokenHeader, token)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/devices", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected requests without a token to pass once every device is revoked, got %d", rec.Code)
+	}
+}
+
+func TestDeviceSignin_AuthenticatesWithCookie(t *testing.T) {
+	router := newDeviceRouter(t)
+	_, laptop := registerDevice(t, router, "Laptop", nil)
+	id, browser := registerDevice(t, router, "Browser", deviceCredential(laptop))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/devices/signin", strings.NewReader(`{"token": "cxd_unknown"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected an unknown token to get 401, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/devices/signin", strings.NewReader(`{"token": "`+browser+`"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != deviceCookieName || cookies[0].Value != browser || !cookies[0].HttpOnly {
+		t.Fatalf("expected an HttpOnly device cookie, got %+v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/devices", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the cookie to authenticate, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/devices/"+strconv.FormatInt(id, 10), nil)
+	req.Header.Set(deviceTokenHeader, laptop)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// A revoked cookie counts as no credential, and signing in still works.
+	req = httptest.NewRequest(http.MethodGet, "/api/devices", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "is required") {
+		t.Fatalf("expected a revoked cookie to need a credential, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/devices/signin", strings.NewReader(`{"token": "`+laptop+`"}`))
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected signing in over a revoked cookie to work, got %d", rec.Code)
+	}
+}
+
+func TestIssueDevice_RecoversLockedInstance(t *testing.T) {
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+	router := chi.NewRouter()
+	router.Use(deviceTracking(hostDB, nil))
+	deviceRoutes(router, hostDB)
+
+	registerDevice(t, router, "Lost phone", nil)
+
+	// What `cortex device add` does on the host's machine.
+	_, token, err := IssueDevice(hostDB, "Recovery", "web")
+	if err != nil {
+		t.Fatalf("failed to issue device: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/devices", nil)
+	req.Header.Set(deviceTokenHeader, token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the issued token to get in, got %d", rec.Code)
+	}
+}
+
+func TestRevokeDevice_NotFound(t *testing.T) {
+	router := newDeviceRouter(t)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/devices/999", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestDeviceTracking_UnknownToken(t *testing.T) {
+	router := newDeviceRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/devices", nil)
+	req.Header.Set(deviceTokenHeader, "cxd_unknown")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// eventBufferSize is how many undelivered events a slow subscriber may
+	// queue before further events to it are dropped.
+	eventBufferSize = 64
+	// eventWriteTimeout bounds how long a single push to a subscriber may take.
+	eventWriteTimeout = 10 * time.Second
+	// eventPingInterval is how often idle subscribers receive a ping, which
+	// also detects connections that went away without closing.
+	eventPingInterval = 30 * time.Second
+)
+
+// Event types pushed over /api/ws.
+const (
+	EventPluginChanged = "plugin.changed"
+	eventPing          = "ping"
+)
+
+// Event is a message pushed to dashboard subscribers.
+type Event struct {
+	Type   string `json:"type"`
+	Plugin string `json:"plugin,omitempty"`
+	Topic  string `json:"topic,omitempty"`
+	Time   string `json:"time"`
+}
+
+// EventHub fans out plugin change notifications to WebSocket subscribers.
+// It implements plugin.ChangeListener.
+type EventHub struct {
+	mu          sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+}
+
+// eventSubscriber is one connected client. An empty plugins set means it
+// receives events from every plugin.
+type eventSubscriber struct {
+	events  chan Event
+	plugins map[string]bool
+}
+
+// NewEventHub creates a hub with no subscribers.
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: make(map[*eventSubscriber]struct{})}
+}
+
+// PluginChanged pushes a plugin.changed event to every interested subscriber.
+// It never blocks: subscribers that are not keeping up miss the event.
+func (h *EventHub) PluginChanged(pluginID string, topic string) {
+	h.publish(Event{
+		Type:   EventPluginChanged,
+		Plugin: pluginID,
+		Topic:  topic,
+		Time:   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (h *EventHub) publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscriber := range h.subscribers {
+		if len(subscriber.plugins) > 0 && !subscriber.plugins[event.Plugin] {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+		}
+	}
+}
+
+func (h *EventHub) subscribe(plugins map[string]bool) *eventSubscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscriber := &eventSubscriber{events: make(chan Event, eventBufferSize), plugins: plugins}
+	h.subscribers[subscriber] = struct{}{}
+	return subscriber
+}
+
+func (h *EventHub) unsubscribe(subscriber *eventSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers, subscriber)
+}
+
+// eventRoutes registers the WebSocket push channel. Clients may pass
+// ?plugins=a,b to only receive events from those plugins. origins are the
+// allowed origins of the CORS policy.
+func eventRoutes(router chi.Router, hub *EventHub, origins []string) {
+	router.Handle("/api/ws", websocket.Server{
+		Handshake: func(config *websocket.Config, request *http.Request) error {
+			return checkEventOrigin(config, request, origins)
+		},
+		Handler: func(connection *websocket.Conn) {
+			serveEvents(connection, hub)
+		},
+	})
+}
+
+// serveEvents pushes events to one connection until it closes.
+func serveEvents(connection *websocket.Conn, hub *EventHub) {
+	defer connection.Close()
+
+	plugins := make(map[string]bool)
+	for _, id := range strings.Split(connection.Request().URL.Query().Get("plugins"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			plugins[id] = true
+		}
+	}
+
+	subscriber := hub.subscribe(plugins)
+	defer hub.unsubscribe(subscriber)
+
+	// The connection was hijacked from the HTTP server, whose read timeout
+	// would otherwise still apply. Clients send nothing; reading only detects
+	// when they disconnect.
+	_ = connection.SetReadDeadline(time.Time{})
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var discard []byte
+		for {
+			if err := websocket.Message.Receive(connection, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+
+	for {
+		var event Event
+		select {
+		case <-closed:
+			return
+		case event = <-subscriber.events:
+		case <-ping.C:
+			event = Event{Type: eventPing, Time: time.Now().UTC().Format(time.RFC3339)}
+		}
+
+		_ = connection.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+		if err := websocket.JSON.Send(connection, event); err != nil {
+			return
+		}
+	}
+}
+
+// checkEventOrigin accepts clients without an Origin header (scripts, tests)
+// and browser pages served by this host or from the allowed origins, matching
+// the CORS policy. Other sites cannot subscribe on the user's behalf.
+func checkEventOrigin(config *websocket.Config, request *http.Request, origins []string) error {
+	origin := request.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	config.Origin = parsed
+
+	if parsed.Host == request.Host || originAllowed(origin, origins) {
+		return nil
+	}
+	return websocket.ErrBadWebSocketOrigin
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/websocket"
+)
+
+// newEventServer starts an HTTP server with only the event routes registered.
+func newEventServer(t *testing.T) (*httptest.Server, *EventHub) {
+	t.Helper()
+
+	hub := NewEventHub()
+	router := chi.NewRouter()
+	eventRoutes(router, hub, []string{"http://localhost", "http://localhost:*"})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, hub
+}
+
+// dialEvents subscribes to the event server, waiting until the hub has
+// registered the subscriber so no published event is missed.
+func dialEvents(t *testing.T, server *httptest.Server, hub *EventHub, query string, origin string) *websocket.Conn {
+	t.Helper()
+
+	before := subscriberCount(hub)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws" + query
+	connection, err := websocket.Dial(url, "", origin)
+	if err != nil {
+		t.Fatalf("dialing %s: %v", url, err)
+	}
+	t.Cleanup(func() { connection.Close() })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for subscriberCount(hub) == before {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber was not registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return connection
+}
+
+func subscriberCount(hub *EventHub) int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	return len(hub.subscribers)
+}
+
+func receiveEvent(t *testing.T, connection *websocket.Conn) Event {
+	t.Helper()
+
+	_ = connection.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event Event
+	if err := websocket.JSON.Receive(connection, &event); err != nil {
+		t.Fatalf("receiving event: %v", err)
+	}
+	return event
+}
+
+func TestEvents_PushesPluginChanges(t *testing.T) {
+	server, hub := newEventServer(t)
+	connection := dialEvents(t, server, hub, "", "http://localhost:5173")
+
+	hub.PluginChanged("finance-tracker", "transactions")
+
+	event := receiveEvent(t, connection)
+	if event.Type != EventPluginChanged || event.Plugin != "finance-tracker" || event.Topic != "transactions" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if event.Time == "" {
+		t.Error("expected the event to carry a timestamp")
+	}
+}
+
+func TestEvents_FiltersByPlugin(t *testing.T) {
+	server, hub := newEventServer(t)
+	connection := dialEvents(t, server, hub, "?plugins=quick-notes", "http://localhost")
+
+	hub.PluginChanged("finance-tracker", "transactions")
+	hub.PluginChanged("quick-notes", "notes")
+
+	// Events are delivered in order, so the first one received proves the
+	// finance-tracker event was filtered out.
+	event := receiveEvent(t, connection)
+	if event.Plugin != "quick-notes" {
+		t.Errorf("expected only quick-notes events, got %+v", event)
+	}
+}
+
+func TestEvents_RejectsForeignOrigin(t *testing.T) {
+	server, _ := newEventServer(t)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws"
+	if _, err := websocket.Dial(url, "", "https://evil.example.com"); err == nil {
+		t.Error("expected a connection from a foreign origin to be rejected")
+	}
+}
+
+func TestEvents_UnsubscribesOnClose(t *testing.T) {
+	server, hub := newEventServer(t)
+	connection := dialEvents(t, server, hub, "", "http://localhost")
+
+	connection.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for subscriberCount(hub) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the subscriber to be removed after the client disconnected")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/backup"
+)
+
+// exportPassphraseHeader carries the passphrase for encrypted exports. A header is
+// used instead of a query parameter so the passphrase never ends up in access logs.
+const exportPassphraseHeader = "X-Export-Passphrase"
+
+// exportRoutes registers the data export endpoint.
+func exportRoutes(router chi.Router, dataDir string) {
+	// GET /api/export -- download a snapshot of all databases (?encrypt=true to encrypt)
+	router.Get("/api/export", func(writer http.ResponseWriter, request *http.Request) {
+		encrypt := request.URL.Query().Get("encrypt") == "true"
+		passphrase := request.Header.Get(exportPassphraseHeader)
+
+		if encrypt && len(passphrase) < backup.MinPassphraseLength {
+			writeExportError(writer, http.StatusBadRequest, "VALIDATION_ERROR",
+				fmt.Sprintf("%s header must be at least %d characters when encrypt=true", exportPassphraseHeader, backup.MinPassphraseLength))
+			return
+		}
+
+		// Build the archive in a temp file first so a failure can still be
+		// reported as a proper error response instead of a truncated download.
+		archiveFile, err := os.CreateTemp("", "cortex-export-*")
+		if err != nil {
+			writeExportError(writer, http.StatusInternalServerError, "INTERNAL", "failed to create export")
+			return
+		}
+		defer func() {
+			archiveFile.Close()
+			os.Remove(archiveFile.Name())
+		}()
+
+		if err := writeExport(archiveFile, dataDir, encrypt, passphrase); err != nil {
+			slog.Error("export failed", "error", err)
+			writeExportError(writer, http.StatusInternalServerError, "EXPORT_ERROR", "failed to create export")
+			return
+		}
+
+		if _, err := archiveFile.Seek(0, io.SeekStart); err != nil {
+			writeExportError(writer, http.StatusInternalServerError, "INTERNAL", "failed to read export")
+			return
+		}
+
+		filename := "cortex-export-" + time.Now().UTC().Format("20060102-150405") + ".tar.gz"
+		contentType := "application/gzip"
+		if encrypt {
+			filename += ".enc"
+			contentType = "application/octet-stream"
+		}
+
+		writer.Header().Set("Content-Type", contentType)
+		writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		writer.WriteHeader(http.StatusOK)
+		_, _ = io.Copy(writer, archiveFile)
+	})
+}
+
+// writeExport writes the archive to destination, encrypting it when requested.
+func writeExport(destination io.Writer, dataDir string, encrypt bool, passphrase string) error {
+	if !encrypt {
+		return backup.WriteArchive(destination, dataDir)
+	}
+
+	encryptWriter, err := backup.NewEncryptWriter(destination, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := backup.WriteArchive(encryptWriter, dataDir); err != nil {
+		return err
+	}
+
+	return encryptWriter.Close()
+}
+
+// writeExportError writes a standardized error JSON response for export endpoints.
+func writeExportError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
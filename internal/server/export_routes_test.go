@@ -0,0 +1,124 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/backup"
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// newExportRouter creates a router with export routes over a data dir containing a host database.
+func newExportRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	router := chi.NewRouter()
+	exportRoutes(router, dataDir)
+	return router
+}
+
+// archiveEntries lists the file names inside a tar.gz archive.
+func archiveEntries(t *testing.T, archive []byte) []string {
+	t.Helper()
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("failed to open gzip stream: %v", err)
+	}
+
+	var names []string
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read tar entry: %v", err)
+		}
+		names = append(names, header.Name)
+	}
+	return names
+}
+
+func TestExport_Plain(t *testing.T) {
+	router := newExportRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/gzip" {
+		t.Errorf("expected Content-Type 'application/gzip', got %q", contentType)
+	}
+
+	names := archiveEntries(t, rec.Body.Bytes())
+	if len(names) != 2 || names[0] != "export.json" || names[1] != "cortex.db" {
+		t.Errorf("expected [export.json cortex.db], got %v", names)
+	}
+}
+
+func TestExport_EncryptedRoundTrip(t *testing.T) {
+	router := newExportRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export?encrypt=true", nil)
+	req.Header.Set(exportPassphraseHeader, "correct horse battery")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var decrypted bytes.Buffer
+	if err := backup.Decrypt(&decrypted, rec.Body, "correct horse battery"); err != nil {
+		t.Fatalf("failed to decrypt export: %v", err)
+	}
+
+	names := archiveEntries(t, decrypted.Bytes())
+	if len(names) != 2 {
+		t.Errorf("expected 2 archive entries, got %v", names)
+	}
+}
+
+func TestExport_EncryptWithoutPassphrase(t *testing.T) {
+	router := newExportRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export?encrypt=true", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse error body: %v", err)
+	}
+	if body.Error.Code != "VALIDATION_ERROR" {
+		t.Errorf("expected error code 'VALIDATION_ERROR', got '%s'", body.Error.Code)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package server
+
+// filesystemSpace is not available on this platform; GET /api/system leaves
+// the filesystem's size and free space out.
+func filesystemSpace(string) (total uint64, free uint64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package server
+
+import "syscall"
+
+// filesystemSpace returns the size of the filesystem holding path and the
+// space left on it for unprivileged users.
+func filesystemSpace(path string) (total uint64, free uint64, ok bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, false
+	}
+	return uint64(stat.Blocks) * uint64(stat.Bsize), uint64(stat.Bavail) * uint64(stat.Bsize), true
+}
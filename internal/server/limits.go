@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is how often clients idle long enough to have a
+// full bucket are forgotten.
+const rateLimitSweepInterval = time.Minute
+
+// bodyLimitExempt lists the routes that decode and cap their bodies
+// themselves, at more than the global limit.
+var bodyLimitExempt = map[string]bool{
+	"/api/restore": true,
+}
+
+// rateLimiter is a token bucket per client IP: each client may make burst
+// requests at once, refilled at perMinute requests a minute.
+type rateLimiter struct {
+	perSecond float64
+	burst     float64
+	now       func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*rateBucket
+	lastSweep time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	seen   time.Time
+}
+
+func newRateLimiter(perMinute int, burst int) *rateLimiter {
+	return &rateLimiter{
+		perSecond: float64(perMinute) / 60,
+		burst:     float64(burst),
+		now:       time.Now,
+		clients:   make(map[string]*rateBucket),
+	}
+}
+
+// allow takes a token from the client's bucket. When it is empty it returns
+// false and how long until the next token.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.clients[client]
+	if !ok {
+		bucket = &rateBucket{tokens: l.burst}
+		l.clients[client] = bucket
+	} else {
+		bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.seen).Seconds()*l.perSecond)
+	}
+	bucket.seen = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// sweep forgets clients whose buckets have refilled, so the map does not
+// grow with every address ever seen.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	full := time.Duration(l.burst / l.perSecond * float64(time.Second))
+	for client, bucket := range l.clients {
+		if now.Sub(bucket.seen) >= full {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// rateLimit answers 429 with a Retry-After header to clients making more
+// than perMinute requests a minute, past a burst of burst requests. Clients
+// are told apart by IP, so it must run after trustedProxies. The health
+// check is never limited. perMinute 0 disables it.
+func rateLimit(perMinute int, burst int) func(http.Handler) http.Handler {
+	if perMinute <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	limiter := newRateLimiter(perMinute, burst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.URL.Path == "/api/health" {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			if ok, wait := limiter.allow(clientIP(request)); !ok {
+				writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeLimitError(writer, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests, slow down")
+				return
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// limitBody rejects requests whose Content-Length is over maxBytes with 413.
+// Bodies sent without a length are cut off at maxBytes, so the handler
+// reading them gets an error instead of the rest.
+func limitBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if bodyLimitExempt[request.URL.Path] {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			if request.ContentLength > maxBytes {
+				writeLimitError(writer, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE",
+					fmt.Sprintf("request body exceeds %d MiB", maxBytes>>20))
+				return
+			}
+			request.Body = http.MaxBytesReader(writer, request.Body, maxBytes)
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// clientIP returns the IP a request came from, without its port.
+func clientIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
+// writeLimitError writes a standardized error JSON response for requests
+// rejected by the rate and size limits.
+func writeLimitError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
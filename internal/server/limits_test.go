@@ -0,0 +1,87 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_RefillsOverTime(t *testing.T) {
+	limiter := newRateLimiter(60, 2)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("10.0.0.1"); !ok {
+			t.Fatalf("expected request %d of the burst to be allowed", i+1)
+		}
+	}
+	ok, wait := limiter.allow("10.0.0.1")
+	if ok || wait <= 0 || wait > time.Second {
+		t.Errorf("expected the third request to wait up to a second, got %v, %s", ok, wait)
+	}
+	if ok, _ := limiter.allow("10.0.0.2"); !ok {
+		t.Error("expected other clients to have their own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := limiter.allow("10.0.0.1"); !ok {
+		t.Error("expected a token back after a second at 60 per minute")
+	}
+}
+
+func TestRateLimit_Answers429(t *testing.T) {
+	handler := rateLimit(60, 1)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.0.2.1:5000"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/api/plugins"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the first request to pass, got %d", rec.Code)
+	}
+	rec := serve("/api/plugins")
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusTooManyRequests || code != "RATE_LIMITED" {
+		t.Errorf("expected 429 RATE_LIMITED, got %d %s", rec.Code, code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After 1, got %q", rec.Header().Get("Retry-After"))
+	}
+	if rec := serve("/api/health"); rec.Code != http.StatusOK {
+		t.Errorf("expected the health check not to be limited, got %d", rec.Code)
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	var readErr error
+	handler := limitBody(8)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, readErr = io.ReadAll(request.Body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/dashboard/layout", strings.NewReader("0123456789")))
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusRequestEntityTooLarge || code != "PAYLOAD_TOO_LARGE" {
+		t.Errorf("expected 413 PAYLOAD_TOO_LARGE, got %d %s", rec.Code, code)
+	}
+
+	// Without a Content-Length the body is cut off where the limit is
+	req := httptest.NewRequest(http.MethodPost, "/api/dashboard/layout", strings.NewReader("0123456789"))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if readErr == nil {
+		t.Error("expected reading past the limit to fail")
+	}
+
+	readErr = nil
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/restore", strings.NewReader("0123456789")))
+	if readErr != nil {
+		t.Errorf("expected restore uploads to be exempt, got %v", readErr)
+	}
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// liteContentLength caps long text cells, such as note contents.
+const liteContentLength = 140
+
+// liteView is a read-only page listing records from a plugin API.
+type liteView struct {
+	Name     string
+	Title    string
+	PluginID string
+	APIPath  string
+	// Filters are the query parameters passed through to the plugin API,
+	// rendered as a plain GET form.
+	Filters []string
+	Columns []liteColumn
+}
+
+// liteColumn renders one field of each record.
+type liteColumn struct {
+	Header string
+	Field  string
+	// Format is "money" for two decimals, "text" to shorten long values,
+	// or empty to print the value as is.
+	Format string
+}
+
+// liteViews are the core views available at /lite/{name}.
+var liteViews = []liteView{
+	{
+		Name: "transactions", Title: "Transactions", PluginID: "finance-tracker", APIPath: "/transactions",
+		Filters: []string{"month", "search"},
+		Columns: []liteColumn{
+			{Header: "Date", Field: "date"},
+			{Header: "Description", Field: "description", Format: "text"},
+			{Header: "Category", Field: "category"},
+			{Header: "Type", Field: "type"},
+			{Header: "Amount", Field: "amount", Format: "money"},
+		},
+	},
+	{
+		Name: "notes", Title: "Notes", PluginID: "quick-notes", APIPath: "/notes",
+		Columns: []liteColumn{
+			{Header: "Title", Field: "title"},
+			{Header: "Content", Field: "content", Format: "text"},
+			{Header: "Pinned", Field: "pinned"},
+			{Header: "Updated", Field: "updated_at"},
+		},
+	},
+	{
+		Name: "projects", Title: "Projects", PluginID: "project-hub", APIPath: "/projects",
+		Filters: []string{"status", "tag"},
+		Columns: []liteColumn{
+			{Header: "Name", Field: "name"},
+			{Header: "Status", Field: "status"},
+			{Header: "Category", Field: "category"},
+			{Header: "Tagline", Field: "tagline", Format: "text"},
+		},
+	},
+}
+
+// liteTemplate renders every lite page: no scripts, no stylesheets beyond a
+// few inline rules, and nothing an old browser could fail on.
+var liteTemplate = template.Must(template.New("lite").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - Cortex</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<p><a href="{{.Base}}/lite/">Cortex lite</a>{{range .Views}} | <a href="{{$.Base}}/lite/{{.Name}}">{{.Title}}</a>{{end}} | <a href="{{.Base}}/">Full app</a></p>
+<h1>{{.Title}}</h1>
+{{if .Error}}<p><strong>{{.Error}}</strong></p>
+{{else if .Index}}<ul>
+{{range .Views}}<li><a href="{{$.Base}}/lite/{{.Name}}">{{.Title}}</a></li>
+{{else}}<li>No views are available: none of their plugins is loaded.</li>
+{{end}}</ul>
+{{else}}{{if .Filters}}<form method="get" action="{{.Base}}/lite/{{.Name}}">
+{{range .Filters}}<label>{{.Name}} <input type="text" name="{{.Name}}" value="{{.Value}}"></label>
+{{end}}<input type="submit" value="Filter">
+</form>
+{{end}}<p>{{len .Rows}} records</p>
+<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// litePage is the data liteTemplate renders.
+type litePage struct {
+	// Base is the path prefix Cortex is served under, for the page's links.
+	Base    string
+	Title   string
+	Name    string
+	Views   []liteView
+	Index   bool
+	Error   string
+	Filters []liteFilter
+	Headers []string
+	Rows    [][]string
+}
+
+type liteFilter struct {
+	Name  string
+	Value string
+}
+
+// liteRoutes registers server-rendered HTML pages for the core views, so the
+// data stays reachable from old browsers or when the SPA bundle fails.
+func liteRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader) {
+	// GET /lite/ -- index of the views whose plugins are loaded
+	router.Get("/lite", func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, basePath(request.Context())+"/lite/", http.StatusMovedPermanently)
+	})
+	router.Get("/lite/", func(writer http.ResponseWriter, request *http.Request) {
+		renderLitePage(writer, request, http.StatusOK, litePage{Title: "Cortex lite", Views: availableLiteViews(registry), Index: true})
+	})
+
+	// GET /lite/{view} -- one view's records as an HTML table
+	router.Get("/lite/{view}", func(writer http.ResponseWriter, request *http.Request) {
+		views := availableLiteViews(registry)
+		view, ok := findLiteView(chi.URLParam(request, "view"))
+		if !ok {
+			renderLitePage(writer, request, http.StatusNotFound, litePage{Title: "Not found", Views: views, Error: "There is no such view."})
+			return
+		}
+
+		page := litePage{Title: view.Title, Name: view.Name, Views: views}
+		query := make(map[string]string)
+		for _, name := range view.Filters {
+			value := strings.TrimSpace(request.URL.Query().Get(name))
+			page.Filters = append(page.Filters, liteFilter{Name: name, Value: value})
+			if value != "" {
+				query[name] = value
+			}
+		}
+
+		records, status, message := fetchLiteRecords(request.Context(), registry, loader, view, query)
+		if message != "" {
+			page.Error = message
+			renderLitePage(writer, request, status, page)
+			return
+		}
+
+		for _, column := range view.Columns {
+			page.Headers = append(page.Headers, column.Header)
+		}
+		for _, record := range records {
+			row := make([]string, len(view.Columns))
+			for index, column := range view.Columns {
+				row[index] = formatLiteValue(record[column.Field], column.Format)
+			}
+			page.Rows = append(page.Rows, row)
+		}
+		renderLitePage(writer, request, http.StatusOK, page)
+	})
+}
+
+// fetchLiteRecords calls the view's plugin API and decodes its {data: [...]}
+// response. On failure it returns the status and message to show instead.
+func fetchLiteRecords(ctx context.Context, registry *plugin.Registry, loader *plugin.Loader, view liteView, query map[string]string) ([]map[string]interface{}, int, string) {
+	entry, err := loader.Acquire(view.PluginID)
+	if err != nil {
+		if _, ok := registry.Get(view.PluginID); !ok {
+			return nil, http.StatusNotFound, "The " + view.PluginID + " plugin is not installed."
+		}
+		return nil, http.StatusServiceUnavailable, "The " + view.PluginID + " plugin is temporarily unavailable."
+	}
+
+	ctx, cancel := loader.RequestContext(ctx)
+	defer cancel()
+	response, err := entry.Plugin.HandleAPI(ctx, &plugin.APIRequest{Method: http.MethodGet, Path: view.APIPath, Query: query})
+	if crashed := loader.Release(view.PluginID, entry, err); crashed || err != nil {
+		if errors.Is(err, plugin.ErrPluginTimeout) {
+			return nil, http.StatusGatewayTimeout, "The " + view.PluginID + " plugin took too long to answer."
+		}
+		return nil, http.StatusServiceUnavailable, "The " + view.PluginID + " plugin could not answer."
+	}
+
+	var body struct {
+		Data  []map[string]interface{} `json:"data"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(response.Body, &body); err != nil {
+		slog.Warn("lite view got an unexpected response", "plugin", view.PluginID, "error", err)
+		return nil, http.StatusBadGateway, "The " + view.PluginID + " plugin returned an unexpected response."
+	}
+	if response.StatusCode >= 400 {
+		message := body.Error.Message
+		if message == "" {
+			message = "The request failed."
+		}
+		return nil, response.StatusCode, message
+	}
+	return body.Data, http.StatusOK, ""
+}
+
+func availableLiteViews(registry *plugin.Registry) []liteView {
+	views := make([]liteView, 0, len(liteViews))
+	for _, view := range liteViews {
+		if _, ok := registry.Get(view.PluginID); ok {
+			views = append(views, view)
+		}
+	}
+	return views
+}
+
+func findLiteView(name string) (liteView, bool) {
+	for _, view := range liteViews {
+		if view.Name == name {
+			return view, true
+		}
+	}
+	return liteView{}, false
+}
+
+// formatLiteValue turns a decoded JSON value into cell text.
+func formatLiteValue(value interface{}, format string) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		if typed {
+			return "yes"
+		}
+		return ""
+	case float64:
+		if format == "money" {
+			return strconv.FormatFloat(typed, 'f', 2, 64)
+		}
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case string:
+		if format == "text" {
+			text := strings.Join(strings.Fields(typed), " ")
+			if runes := []rune(text); len(runes) > liteContentLength {
+				return string(runes[:liteContentLength]) + "…"
+			}
+			return text
+		}
+		return typed
+	default:
+		encoded, _ := json.Marshal(typed)
+		return string(encoded)
+	}
+}
+
+func renderLitePage(writer http.ResponseWriter, request *http.Request, statusCode int, page litePage) {
+	page.Base = basePath(request.Context())
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(statusCode)
+	if err := liteTemplate.Execute(writer, page); err != nil {
+		slog.Warn("rendering lite page", "page", page.Name, "error", err)
+	}
+}
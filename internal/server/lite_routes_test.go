@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// notesStubPlugin answers every request with fixed notes.
+type notesStubPlugin struct {
+	stubPlugin
+}
+
+func (p *notesStubPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	return &plugin.APIResponse{
+		StatusCode:  http.StatusOK,
+		Body:        []byte(`{"data":[{"id":1,"title":"<script>alert(1)</script>","content":"buy milk","pinned":true,"updated_at":"2026-03-01"}]}`),
+		ContentType: "application/json",
+	}, nil
+}
+
+func newLiteRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	registry := plugin.NewRegistry()
+	registry.Register("quick-notes", nil, &plugin.Manifest{ID: "quick-notes", Name: "Quick Notes", Version: "1.0.0"})
+	entry, _ := registry.Get("quick-notes")
+	entry.Plugin = &notesStubPlugin{}
+
+	router := chi.NewRouter()
+	liteRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry))
+	return router
+}
+
+func TestLitePages(t *testing.T) {
+	router := newLiteRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lite/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `href="/lite/notes"`) {
+		t.Fatalf("expected the index to link the notes view, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), `href="/lite/transactions"`) {
+		t.Error("expected views of plugins that are not loaded to be hidden")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lite/notes", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatalf("expected an HTML page, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(body, "<td>buy milk</td>") || !strings.Contains(body, "<td>yes</td>") {
+		t.Errorf("expected the notes in the table, got %s", body)
+	}
+	if strings.Contains(body, "<script") {
+		t.Error("expected plugin data to be escaped")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lite/transactions", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "not installed") {
+		t.Errorf("expected a 404 page for a missing plugin, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lite/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 page for an unknown view, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// walMetric is one metric family exported for every plugin database.
+type walMetric struct {
+	name  string
+	kind  string
+	help  string
+	value func(plugin.WALStats) int64
+}
+
+var walMetrics = []walMetric{
+	{"cortex_plugin_wal_bytes", "gauge", "Size of the plugin database's write-ahead log file.",
+		func(stats plugin.WALStats) int64 { return stats.WALBytes }},
+	{"cortex_plugin_wal_frames", "gauge", "Pages held in the plugin database's write-ahead log.",
+		func(stats plugin.WALStats) int64 { return stats.WALFrames }},
+	{"cortex_plugin_wal_checkpoints_total", "counter", "Checkpoints that copied the whole write-ahead log into the database.",
+		func(stats plugin.WALStats) int64 { return stats.Checkpoints }},
+	{"cortex_plugin_wal_busy_retries_total", "counter", "Checkpoints left incomplete by a busy database and retried.",
+		func(stats plugin.WALStats) int64 { return stats.BusyRetries }},
+}
+
+// metricsRoutes registers the Prometheus scrape endpoint.
+func metricsRoutes(router chi.Router, loader *plugin.Loader) {
+	// GET /metrics -- plugin database WAL metrics in the Prometheus text format
+	router.Get("/metrics", func(writer http.ResponseWriter, request *http.Request) {
+		stats := loader.WALStats()
+
+		var body strings.Builder
+		for _, metric := range walMetrics {
+			fmt.Fprintf(&body, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+			for _, entry := range stats {
+				fmt.Fprintf(&body, "%s{plugin=%q} %d\n", metric.name, entry.PluginID, metric.value(entry))
+			}
+		}
+
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = writer.Write([]byte(body.String()))
+	})
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+func TestMetrics_PluginWAL(t *testing.T) {
+	dataDir := t.TempDir()
+	registry := plugin.NewRegistry()
+	registry.Register("finance-tracker", nil, &plugin.Manifest{ID: "finance-tracker", Permissions: []string{plugin.PermissionDBWrite}})
+
+	path := filepath.Join(dataDir, "plugins", "finance-tracker", "db.sqlite")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("creating plugin directory: %v", err)
+	}
+	database, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	for _, statement := range []string{"PRAGMA journal_mode=WAL", "CREATE TABLE entries (body TEXT)", "INSERT INTO entries VALUES ('a')"} {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+
+	loader := plugin.NewLoader(t.TempDir(), dataDir, registry)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loader.MonitorWAL(ctx, time.Millisecond, 0)
+	for deadline := time.Now().Add(5 * time.Second); len(loader.WALStats()) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the first WAL check")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	router := chi.NewRouter()
+	metricsRoutes(router, loader)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("expected the Prometheus text format, got %q", contentType)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE cortex_plugin_wal_bytes gauge\n",
+		"# TYPE cortex_plugin_wal_checkpoints_total counter\n",
+		`cortex_plugin_wal_busy_retries_total{plugin="finance-tracker"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the metrics, got:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/notify"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 200
+)
+
+// notificationRoutes registers the notification center and digest settings endpoints.
+func notificationRoutes(router chi.Router, hostDB *db.HostDB, center *notify.Center) {
+	// GET /api/notifications -- list recent notifications (?unread=true, ?limit=N)
+	router.Get("/api/notifications", func(writer http.ResponseWriter, request *http.Request) {
+		limit := defaultNotificationLimit
+		if rawLimit := request.URL.Query().Get("limit"); rawLimit != "" {
+			parsed, err := strconv.Atoi(rawLimit)
+			if err != nil || parsed < 1 || parsed > maxNotificationLimit {
+				writeNotificationError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "limit must be between 1 and 200")
+				return
+			}
+			limit = parsed
+		}
+
+		notifications, err := hostDB.ListNotifications(request.URL.Query().Get("unread") == "true", limit)
+		if err != nil {
+			writeNotificationError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list notifications")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": notifications})
+	})
+
+	// POST /api/notifications -- create a notification (urgent ones skip the digest)
+	router.Post("/api/notifications", func(writer http.ResponseWriter, request *http.Request) {
+		var body db.Notification
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			writeNotificationError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		body.Source = strings.TrimSpace(body.Source)
+		body.Title = strings.TrimSpace(body.Title)
+
+		if body.Source == "" {
+			writeNotificationError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "source is required")
+			return
+		}
+		if body.Title == "" || len(body.Title) > 200 {
+			writeNotificationError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "title is required and must be at most 200 characters")
+			return
+		}
+
+		notification, err := center.Notify(body)
+		if err != nil {
+			writeNotificationError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to create notification")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": notification})
+	})
+
+	// PUT /api/notifications/{notificationID}/read -- mark a notification as read
+	router.Put("/api/notifications/{notificationID}/read", func(writer http.ResponseWriter, request *http.Request) {
+		notificationID, err := strconv.ParseInt(chi.URLParam(request, "notificationID"), 10, 64)
+		if err != nil || notificationID <= 0 {
+			writeNotificationError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid notification ID")
+			return
+		}
+
+		if err := hostDB.MarkNotificationRead(notificationID); err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				writeNotificationError(writer, http.StatusNotFound, "NOT_FOUND", "notification not found")
+				return
+			}
+			writeNotificationError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to update notification")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": notificationID, "status": "read"},
+		})
+	})
+
+	// GET /api/notifications/digest -- current digest schedule and channels
+	router.Get("/api/notifications/digest", func(writer http.ResponseWriter, request *http.Request) {
+		settings, err := hostDB.GetDigestSettings()
+		if err != nil {
+			writeNotificationError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get digest settings")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": settings})
+	})
+
+	// PUT /api/notifications/digest -- configure the digest (full replace)
+	router.Put("/api/notifications/digest", func(writer http.ResponseWriter, request *http.Request) {
+		var body db.DigestSettings
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			writeNotificationError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		body.WebhookURL = strings.TrimSpace(body.WebhookURL)
+		body.Email = strings.TrimSpace(body.Email)
+
+		if err := notify.ValidateDigestSettings(body); err != nil {
+			writeNotificationError(writer, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+
+		if err := hostDB.SaveDigestSettings(body); err != nil {
+			writeNotificationError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to save digest settings")
+			return
+		}
+
+		settings, err := hostDB.GetDigestSettings()
+		if err != nil {
+			writeNotificationError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get digest settings")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": settings})
+	})
+}
+
+// writeNotificationError writes a standardized error JSON response for notification endpoints.
+func writeNotificationError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/notify"
+)
+
+// newNotificationRouter creates a minimal chi router with only notification routes registered.
+func newNotificationRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	router := chi.NewRouter()
+	notificationRoutes(router, hostDB, notify.NewCenter(hostDB, notify.SMTPConfig{}))
+	return router
+}
+
+func TestCreateAndListNotifications(t *testing.T) {
+	router := newNotificationRouter(t)
+
+	createReq := httptest.NewRequest(http.MethodPost, "/api/notifications",
+		strings.NewReader(`{"source": "finance-tracker", "title": "Budget Food at 90%"}`))
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d. Body: %s", createRec.Code, createRec.Body.String())
+	}
+
+	var created struct {
+		Data db.Notification `json:"data"`
+	}
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to parse create response: %v", err)
+	}
+
+	readReq := httptest.NewRequest(http.MethodPut, "/api/notifications/1/read", nil)
+	readRec := httptest.NewRecorder()
+	router.ServeHTTP(readRec, readReq)
+
+	if readRec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 marking read, got %d", readRec.Code)
+	}
+
+	listReq := httptest.NewRequest(http.MethodGet, "/api/notifications?unread=true", nil)
+	listRec := httptest.NewRecorder()
+	router.ServeHTTP(listRec, listReq)
+
+	var listed struct {
+		Data []db.Notification `json:"data"`
+	}
+	if err := json.Unmarshal(listRec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("failed to parse list response: %v", err)
+	}
+	if len(listed.Data) != 0 {
+		t.Errorf("expected no unread notifications, got %d", len(listed.Data))
+	}
+}
+
+func TestCreateNotification_Validation(t *testing.T) {
+	router := newNotificationRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/notifications", strings.NewReader(`{"source": "x", "title": ""}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestMarkNotificationRead_NotFound(t *testing.T) {
+	router := newNotificationRouter(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/notifications/42/read", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestSaveDigestSettings(t *testing.T) {
+	router := newNotificationRouter(t)
+
+	body := `{"enabled": true, "frequency": "weekly", "time_of_day": "18:00", "weekday": 0, "webhook_url": "https://ntfy.example.com/cortex"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/notifications/digest", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data db.DigestSettings `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if !response.Data.Enabled || response.Data.Frequency != "weekly" || response.Data.TimeOfDay != "18:00" {
+		t.Errorf("unexpected settings: %+v", response.Data)
+	}
+}
+
+func TestSaveDigestSettings_InvalidFrequency(t *testing.T) {
+	router := newNotificationRouter(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/notifications/digest",
+		strings.NewReader(`{"enabled": true, "frequency": "hourly", "time_of_day": "08:00"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
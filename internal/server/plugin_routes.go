@@ -1,23 +1,135 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/logging"
 	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/undo"
 )
 
+const (
+	// defaultLogTail is how many lines GET /api/plugins/{id}/logs returns.
+	defaultLogTail = 200
+	// maxLogTail caps the ?tail= query parameter.
+	maxLogTail = 5000
+)
+
+// staleHeader marks a response the host served from its store of successful
+// responses because the plugin's route is failing.
+const staleHeader = "X-Cortex-Stale"
+
+// PluginListing is an installed plugin as listed by GET /api/plugins: its
+// manifest, the plugin API it was built for, when a newer release has been
+// found the update available, and what the host remembers of its installs,
+// loads, versions and migrations.
+type PluginListing struct {
+	*plugin.Manifest
+	SDK     *plugin.SDKInfo   `json:"sdk,omitempty"`
+	Update  *plugin.Update    `json:"update,omitempty"`
+	Install *db.PluginInstall `json:"install,omitempty"`
+}
+
 // pluginAPIRoutes registers all plugin-related API endpoints.
-func pluginAPIRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader) {
+func pluginAPIRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader, installer *plugin.Installer, updates *plugin.UpdateChecker, hostDB *db.HostDB, undoLog *undo.Log, widgets *WidgetCache) {
+	undoLog.Handle(undoPluginPurge, restorePurgedPlugin(registry, loader, hostDB, undoLog))
+	undoLog.Handle(undoPluginDelete, restoreDeletedRecords(loader, widgets))
+
 	// List installed plugins
 	router.Get("/api/plugins", func(writer http.ResponseWriter, request *http.Request) {
 		manifests := registry.List()
+		installs, err := hostDB.ListPluginInstalls()
+		if err != nil {
+			slog.Error("listing plugin installs", "error", err)
+			writePluginError(writer, http.StatusInternalServerError, "INTERNAL", "failed to list plugins")
+			return
+		}
+		listings := make([]PluginListing, 0, len(manifests))
+		for _, manifest := range manifests {
+			listing := PluginListing{Manifest: manifest}
+			if install, ok := installs[manifest.ID]; ok {
+				listing.Install = &install
+			}
+			if entry, ok := registry.Get(manifest.ID); ok && entry.SDK.Protocol != 0 {
+				sdk := entry.SDK
+				listing.SDK = &sdk
+			}
+			if update, ok := updates.Available(manifest); ok {
+				listing.Update = update
+			}
+			listings = append(listings, listing)
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": listings})
+	})
+
+	// Install a plugin from a remote archive URL or by registry name, then load it
+	router.Post("/api/plugins/install", func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			URL       string `json:"url"`
+			Name      string `json:"name"`
+			SHA256    string `json:"sha256"`
+			Signature string `json:"signature"`
+		}
+
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			writePluginError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		if (body.URL == "") == (body.Name == "") {
+			writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "exactly one of url or name is required")
+			return
+		}
+
+		source := &plugin.InstallSource{URL: body.URL, SHA256: body.SHA256, Signature: body.Signature}
+		if body.URL == "" {
+			resolved, err := installer.Resolve(body.Name)
+			if err != nil {
+				writeInstallError(writer, err)
+				return
+			}
+			source = resolved
+		} else if body.SHA256 == "" {
+			writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "sha256 is required when installing from a url")
+			return
+		}
+
+		pluginID, err := installer.Install(*source)
+		if err != nil {
+			writeInstallError(writer, err)
+			return
+		}
+
+		if err := loader.LoadPlugin(pluginID); err != nil {
+			slog.Error("installed plugin failed to load, removing it", "plugin", pluginID, "error", err)
+			if removeErr := installer.Remove(pluginID); removeErr != nil {
+				slog.Warn("removing plugin", "plugin", pluginID, "error", removeErr)
+			}
+			writePluginError(writer, http.StatusInternalServerError, "INSTALL_ERROR", "failed to install plugin")
+			return
+		}
+
 		writer.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": manifests})
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":     pluginID,
+				"status": "installed",
+			},
+		})
 	})
 
 	// Install (load) a plugin that exists on disk but is not currently loaded
@@ -45,7 +157,15 @@ func pluginAPIRoutes(router chi.Router, registry *plugin.Registry, loader *plugi
 		})
 	})
 
-	// Uninstall (unload) a running plugin
+	// Dependencies of the running plugins, in the order they start
+	router.Get("/api/plugins/dependencies", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": registry.DependencyGraph()})
+	})
+
+	// Uninstall (unload) a running plugin. With ?purge=true its data and
+	// settings are removed too, which can be undone for the undo window.
+	// Plugins other running plugins depend on are only unloaded with ?force=true.
 	router.Delete("/api/plugins/{pluginID}", func(writer http.ResponseWriter, request *http.Request) {
 		pluginID := chi.URLParam(request, "pluginID")
 
@@ -54,6 +174,37 @@ func pluginAPIRoutes(router chi.Router, registry *plugin.Registry, loader *plugi
 			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
 			return
 		}
+		if request.URL.Query().Get("force") != "true" {
+			if err := loader.CheckUnload(pluginID); err != nil {
+				writePluginError(writer, http.StatusConflict, "HAS_DEPENDENTS", err.Error()+"; unload them first or pass force=true")
+				return
+			}
+		}
+		widgets.Invalidate(pluginID)
+
+		if request.URL.Query().Get("purge") == "true" {
+			action, err := purgePlugin(pluginID, loader, hostDB, undoLog)
+			if err != nil && action == nil {
+				slog.Error("purging plugin failed", "plugin", pluginID, "error", err)
+				writePluginError(writer, http.StatusInternalServerError, "PURGE_ERROR", "failed to purge plugin")
+				return
+			}
+			if err != nil {
+				slog.Error("purging plugin settings failed", "plugin", pluginID, "error", err)
+				writePluginError(writer, http.StatusInternalServerError, "PURGE_ERROR", "plugin data was purged but its settings could not be removed")
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":     pluginID,
+					"status": "purged",
+					"undo":   action,
+				},
+			})
+			return
+		}
 
 		// Unload the plugin (calls Teardown + kills subprocess + unregisters)
 		if err := loader.UnloadPlugin(pluginID); err != nil {
@@ -90,6 +241,7 @@ func pluginAPIRoutes(router chi.Router, registry *plugin.Registry, loader *plugi
 			writePluginError(writer, http.StatusInternalServerError, "LOAD_ERROR", "failed to reload plugin")
 			return
 		}
+		widgets.Invalidate(pluginID)
 
 		writer.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
@@ -104,30 +256,111 @@ func pluginAPIRoutes(router chi.Router, registry *plugin.Registry, loader *plugi
 	router.Get("/api/plugins/{pluginID}/widget/{slot}", func(writer http.ResponseWriter, request *http.Request) {
 		pluginID := chi.URLParam(request, "pluginID")
 		slot := chi.URLParam(request, "slot")
+		target := registry.RouteTarget(pluginID, requestAPIKeyID(request))
 
-		entry, ok := registry.Get(pluginID)
-		if !ok {
-			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
-			return
+		// Plugins that declare their widgets only answer for those slots
+		if current, ok := registry.Get(target); ok && current.Manifest != nil && len(current.Manifest.Widgets) > 0 {
+			if _, declared := current.Manifest.FindWidget(slot); !declared {
+				writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "widget not found")
+				return
+			}
+		}
+
+		// Cached data is served without calling the plugin at all
+		if current, ok := registry.Get(target); ok {
+			if data, etag, ok := widgets.Get(target, slot, current); ok {
+				writeWidgetData(writer, request, pluginID, target, data, etag)
+				return
+			}
 		}
 
-		if entry.Plugin == nil {
-			writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin is not running")
+		generation := widgets.Generation()
+		entry, err := loader.Acquire(target)
+		if err != nil {
+			writeAcquireError(writer, err)
 			return
 		}
 
 		data, err := entry.Plugin.GetWidgetData(slot)
+		if crashed := loader.Release(target, entry, err); crashed {
+			writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin stopped while handling the request")
+			return
+		}
 		if err != nil {
 			writePluginError(writer, http.StatusInternalServerError, "PLUGIN_ERROR", "failed to get widget data")
 			return
 		}
 
+		etag := widgets.Put(pluginID, target, slot, entry, generation, data)
+		writeWidgetData(writer, request, pluginID, target, data, etag)
+	})
+
+	// Captured plugin output, newest lines last. Works for plugins that failed
+	// to load, which is when the logs are needed most.
+	router.Get("/api/plugins/{pluginID}/logs", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
+		tail := defaultLogTail
+		if raw := request.URL.Query().Get("tail"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 || parsed > maxLogTail {
+				writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "tail must be a number between 1 and "+strconv.Itoa(maxLogTail))
+				return
+			}
+			tail = parsed
+		}
+
+		lines, err := loader.TailLogs(pluginID, tail)
+		if errors.Is(err, logging.ErrNoLogs) {
+			// A running plugin that has not printed anything yet has empty logs.
+			if _, ok := registry.Get(pluginID); ok {
+				lines, err = []string{}, nil
+			}
+		}
+		switch {
+		case errors.Is(err, logging.ErrNoLogs), errors.Is(err, logging.ErrInvalidPluginID):
+			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "no logs found for plugin")
+			return
+		case err != nil:
+			slog.Error("reading plugin logs", "plugin", pluginID, "error", err)
+			writePluginError(writer, http.StatusInternalServerError, "LOGS_ERROR", "failed to read plugin logs")
+			return
+		}
+
 		writer.Header().Set("Content-Type", "application/json")
-		_, _ = writer.Write(data)
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":    pluginID,
+				"lines": lines,
+			},
+		})
 	})
 
-	// Proxy all other plugin API requests (catch-all, must be registered last)
-	router.HandleFunc("/api/plugins/{pluginID}/*", func(writer http.ResponseWriter, request *http.Request) {
+	// Storage statistics of a plugin's database: row counts, sizes and indexes
+	router.Get("/api/plugins/{pluginID}/stats", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
+		stats, err := loader.DatabaseStats(pluginID)
+		switch {
+		case errors.Is(err, plugin.ErrPluginNotFound):
+			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+			return
+		case errors.Is(err, plugin.ErrNoDatabase):
+			writePluginError(writer, http.StatusNotFound, "NO_DATABASE", "plugin has no database")
+			return
+		case err != nil:
+			slog.Error("reading plugin database statistics", "plugin", pluginID, "error", err)
+			writePluginError(writer, http.StatusInternalServerError, "STATS_ERROR", "failed to read plugin database statistics")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": stats})
+	})
+
+	// Health of a loaded plugin: whether its process runs, its circuit breaker,
+	// and its resource limits, usage and last kill for exceeding them
+	router.Get("/api/plugins/{pluginID}/health", func(writer http.ResponseWriter, request *http.Request) {
 		pluginID := chi.URLParam(request, "pluginID")
 
 		entry, ok := registry.Get(pluginID)
@@ -135,41 +368,331 @@ func pluginAPIRoutes(router chi.Router, registry *plugin.Registry, loader *plugi
 			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
 			return
 		}
-
-		if entry.Plugin == nil {
-			writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin is not running")
+		resources, err := loader.ResourceHealth(pluginID)
+		if err != nil {
+			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
 			return
 		}
 
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":               pluginID,
+				"running":          !entry.Exited(),
+				"circuit":          registry.Breaker(pluginID).State(),
+				"limits":           resources.Limits,
+				"enforcement":      resources.Enforcement,
+				"usage":            resources.Usage,
+				"last_termination": resources.LastTermination,
+			},
+		})
+	})
+
+	// Canary rollout of a new plugin version (start, adjust, promote, roll back)
+	pluginCanaryRoutes(router, registry, loader, installer)
+
+	// One-click update to the latest release at the manifest's update_url
+	pluginUpdateRoutes(router, registry, loader, installer, updates)
+
+	// Offline sync of plugins implementing Syncer
+	pluginSyncRoutes(router, registry, loader)
+
+	// Data export and import of plugins implementing DataPorter
+	pluginDataRoutes(router, registry, loader, widgets)
+
+	// CLI subcommands declared in plugin manifests
+	pluginCommandRoutes(router, registry, loader)
+
+	// Proxy all other plugin API requests (catch-all, must be registered last)
+	router.HandleFunc("/api/plugins/{pluginID}/*", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
 		// Extract the sub-path after /api/plugins/{id}/
-		fullPath := request.URL.Path
-		prefix := "/api/plugins/" + pluginID + "/"
-		subPath := strings.TrimPrefix(fullPath, prefix)
+		subPath := "/" + strings.TrimPrefix(request.URL.Path, "/api/plugins/"+pluginID+"/")
+		proxyPluginRequest(writer, request, registry, loader, undoLog, widgets, pluginID, subPath)
+	})
+}
+
+// pluginRouteAliases serves requests under a path alias claimed in a plugin
+// manifest, such as /finance/* for finance-tracker, exactly like the matching
+// /api/plugins/{id}/* request. Paths no plugin claims fall through to next.
+func pluginRouteAliases(registry *plugin.Registry, loader *plugin.Loader, undoLog *undo.Log, widgets *WidgetCache, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		pluginID, subPath, ok := registry.ResolveRoute(request.URL.Path)
+		if !ok {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		proxyPluginRequest(writer, request, registry, loader, undoLog, widgets, pluginID, subPath)
+	})
+}
 
-		body, _ := io.ReadAll(request.Body)
+// proxyPluginRequest forwards an HTTP request to a plugin's HandleAPI as subPath.
+// While the plugin has a canary, the rollout decides which of the two serves it.
+// Records a delete removed go to the undo log, in place of the response, and
+// a successful write drops the plugin's cached widget data.
+func proxyPluginRequest(writer http.ResponseWriter, request *http.Request, registry *plugin.Registry, loader *plugin.Loader, undoLog *undo.Log, widgets *WidgetCache, pluginID string, subPath string) {
+	target := registry.RouteTarget(pluginID, requestAPIKeyID(request))
+	entry, ok := registry.Get(target)
+	if !ok {
+		writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+		return
+	}
 
-		query := make(map[string]string)
-		for key, values := range request.URL.Query() {
-			if len(values) > 0 {
-				query[key] = values[0]
-			}
+	// Enforce declared permissions before the request reaches the plugin
+	if required := plugin.RequiredPermissionForMethod(request.Method); required != "" {
+		if err := plugin.RequirePermission(entry.Manifest, required); err != nil {
+			writePluginError(writer, http.StatusForbidden, "PERMISSION_DENIED", "plugin has not declared the "+required+" permission")
+			return
 		}
+	}
 
-		response, err := entry.Plugin.HandleAPI(&plugin.APIRequest{
-			Method: request.Method,
-			Path:   "/" + subPath,
-			Body:   body,
-			Query:  query,
-		})
-		if err != nil {
-			writePluginError(writer, http.StatusInternalServerError, "PLUGIN_ERROR", "plugin request failed")
+	body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, plugin.MaxAPIBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writePluginError(writer, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "request body exceeds 32 MiB")
 			return
 		}
+		writePluginError(writer, http.StatusBadRequest, "BAD_REQUEST", "failed to read request body")
+		return
+	}
 
-		writer.Header().Set("Content-Type", response.ContentType)
-		writer.WriteHeader(response.StatusCode)
-		_, _ = writer.Write(response.Body)
-	})
+	query := make(map[string]string)
+	for key, values := range request.URL.Query() {
+		if len(values) > 0 {
+			query[key] = values[0]
+		}
+	}
+
+	apiRequest := &plugin.APIRequest{
+		Method:      request.Method,
+		Path:        subPath,
+		Body:        body,
+		Query:       query,
+		Headers:     forwardedHeaders(request.Header),
+		ContentType: request.Header.Get("Content-Type"),
+		RequestID:   middleware.GetReqID(request.Context()),
+	}
+	canary := target != pluginID
+
+	// While the route is failing too often, GETs get the last good response
+	if stale, ok := loader.StaleResponse(target, apiRequest); ok {
+		writeStaleResponse(writer, stale, canary)
+		return
+	}
+
+	// Relaunches a crashed plugin, or fails fast while its circuit is open
+	entry, err = loader.Acquire(target)
+	if err != nil {
+		if stale, ok := loader.RecordResponse(target, apiRequest, nil, err); ok {
+			writeStaleResponse(writer, stale, canary)
+			return
+		}
+		writeAcquireError(writer, err)
+		return
+	}
+
+	ctx, cancel := loader.RequestContext(request.Context())
+	defer cancel()
+	response, err := entry.Plugin.HandleAPI(ctx, apiRequest)
+	crashed := loader.Release(target, entry, err)
+	if errors.Is(err, context.Canceled) {
+		// The client went away; there is nobody to answer
+		return
+	}
+	if stale, ok := loader.RecordResponse(target, apiRequest, response, err); ok {
+		writeStaleResponse(writer, stale, canary)
+		return
+	}
+	if err != nil {
+		slog.Warn("plugin request failed", "plugin", target, "method", request.Method, "path", subPath, "crashed", crashed, "request_id", apiRequest.RequestID, "error", err)
+	}
+	if crashed {
+		writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin stopped while handling the request")
+		return
+	}
+	if errors.Is(err, plugin.ErrPluginTimeout) {
+		writePluginError(writer, http.StatusGatewayTimeout, "PLUGIN_TIMEOUT", "plugin did not answer in time")
+		return
+	}
+	if err != nil {
+		writePluginError(writer, http.StatusInternalServerError, "PLUGIN_ERROR", "plugin request failed")
+		return
+	}
+
+	if response.Deleted != nil {
+		recordDeletedRecords(writer, undoLog, entry, target, response)
+	}
+	// Widgets reflect what the write changed without waiting for the TTL,
+	// whether or not the plugin calls sdk.NotifyChanged
+	if widgets != nil && plugin.RequiredPermissionForMethod(request.Method) == plugin.PermissionDBWrite &&
+		response.StatusCode >= 200 && response.StatusCode <= 299 {
+		widgets.Invalidate(pluginID)
+	}
+	writePluginResponse(writer, response, canary)
+}
+
+// recordDeletedRecords keeps what a successful delete removed in the undo
+// log, when the plugin can restore it, and tells the client the token that
+// undoes the delete and until when it does.
+func recordDeletedRecords(writer http.ResponseWriter, undoLog *undo.Log, entry *plugin.RegistryEntry, target string, response *plugin.APIResponse) {
+	if response.StatusCode < 200 || response.StatusCode > 299 || !entry.SDK.Supports(plugin.CapabilityRestore) {
+		return
+	}
+	if _, ok := entry.Plugin.(plugin.Restorer); !ok {
+		return
+	}
+
+	action, err := undoLog.Record(undoPluginDelete, target, deletedRecords{Kind: response.Deleted.Kind, Data: response.Deleted.Data})
+	if err != nil {
+		slog.Warn("recording deleted records failed", "plugin", target, "kind", response.Deleted.Kind, "error", err)
+		return
+	}
+	writer.Header().Set(undoTokenHeader, action.ID)
+	writer.Header().Set(undoExpiresHeader, action.ExpiresAt)
+}
+
+// writePluginResponse writes a plugin's response.
+func writePluginResponse(writer http.ResponseWriter, response *plugin.APIResponse, canary bool) {
+	setPluginHeaders(writer, response, canary)
+	writer.WriteHeader(response.StatusCode)
+	_, _ = writer.Write(response.Body)
+}
+
+// writeStaleResponse writes a stored response in place of a plugin's own,
+// marked as stale and with its age in seconds.
+func writeStaleResponse(writer http.ResponseWriter, stale *plugin.StaleResponse, canary bool) {
+	setPluginHeaders(writer, stale.Response, canary)
+	writer.Header().Set(staleHeader, "true")
+	writer.Header().Set("Age", strconv.Itoa(int(time.Since(stale.StoredAt).Seconds())))
+	writer.WriteHeader(stale.Response.StatusCode)
+	_, _ = writer.Write(stale.Response.Body)
+}
+
+// setPluginHeaders sets the headers of a plugin's response, except those the
+// host reserves.
+func setPluginHeaders(writer http.ResponseWriter, response *plugin.APIResponse, canary bool) {
+	for name, value := range response.Headers {
+		name = http.CanonicalHeaderKey(name)
+		if !reservedResponseHeaders[name] && !strings.HasPrefix(name, "Access-Control-") {
+			writer.Header().Set(name, value)
+		}
+	}
+	if canary {
+		writer.Header().Set(canaryHeader, "true")
+	}
+	writer.Header().Set("Content-Type", response.ContentType)
+}
+
+// hostCredentialHeaders authenticate requests to the host and are not passed
+// on to plugins, nor is the header naming the plugin behind a CallPlugin
+// request, which clients cannot claim.
+var hostCredentialHeaders = map[string]bool{
+	"Authorization":        true,
+	"Cookie":               true,
+	deviceTokenHeader:      true,
+	exportPassphraseHeader: true,
+	plugin.CallerHeader:    true,
+}
+
+// reservedResponseHeaders are set by the host or the HTTP server and cannot be
+// overridden by plugin responses, nor can the CORS headers.
+var reservedResponseHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Content-Type":      true,
+	"Transfer-Encoding": true,
+	canaryHeader:        true,
+	staleHeader:         true,
+	requestIDHeader:     true,
+	undoTokenHeader:     true,
+	undoExpiresHeader:   true,
+}
+
+// forwardedHeaders returns the first value of each request header a plugin
+// may see.
+func forwardedHeaders(header http.Header) map[string]string {
+	forwarded := make(map[string]string, len(header))
+	for name, values := range header {
+		if len(values) == 0 || hostCredentialHeaders[name] || strings.HasPrefix(name, "Access-Control-") {
+			continue
+		}
+		forwarded[name] = values[0]
+	}
+	return forwarded
+}
+
+// requestAPIKeyID returns the ID of the API key a request was made with, or
+// zero for requests without one.
+func requestAPIKeyID(request *http.Request) int64 {
+	if apiKey, ok := apiKeyFromContext(request.Context()); ok {
+		return apiKey.ID
+	}
+	return 0
+}
+
+// writeAcquireError maps Loader.Acquire errors to API error responses.
+func writeAcquireError(writer http.ResponseWriter, err error) {
+	if errors.Is(err, plugin.ErrPluginNotFound) {
+		writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+		return
+	}
+	writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin is temporarily unavailable")
+}
+
+// writeInstallError maps installer errors to API error responses.
+func writeInstallError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, plugin.ErrNoRegistry):
+		writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "no plugin registry is configured")
+	case errors.Is(err, plugin.ErrRegistryNotFound):
+		writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found in registry")
+	case errors.Is(err, plugin.ErrPluginExists):
+		writePluginError(writer, http.StatusConflict, "ALREADY_INSTALLED", "plugin is already installed")
+	case errors.Is(err, plugin.ErrChecksumMismatch):
+		writePluginError(writer, http.StatusUnprocessableEntity, "CHECKSUM_MISMATCH", "plugin archive does not match the expected sha256")
+	case errors.Is(err, plugin.ErrSignatureRequired), errors.Is(err, plugin.ErrSignatureInvalid):
+		writePluginError(writer, http.StatusUnprocessableEntity, "SIGNATURE_INVALID", "plugin archive signature is missing or invalid")
+	case errors.Is(err, plugin.ErrUnsupportedPlatform):
+		writePluginError(writer, http.StatusUnprocessableEntity, "UNSUPPORTED_PLATFORM", "plugin archive has no binary for this platform")
+	case errors.Is(err, plugin.ErrInvalidArchive):
+		writePluginError(writer, http.StatusUnprocessableEntity, "INVALID_ARCHIVE", "plugin archive is invalid")
+	case errors.Is(err, plugin.ErrDownloadFailed):
+		writePluginError(writer, http.StatusBadGateway, "DOWNLOAD_ERROR", "failed to download plugin")
+	default:
+		slog.Error("plugin install failed", "error", err)
+		writePluginError(writer, http.StatusInternalServerError, "INSTALL_ERROR", "failed to install plugin")
+	}
+}
+
+// writeWidgetData writes a widget's data with its ETag, or 304 Not Modified
+// when the client already has it. no-cache makes browsers revalidate on every
+// dashboard load instead of showing data that may have changed.
+func writeWidgetData(writer http.ResponseWriter, request *http.Request, pluginID string, target string, data []byte, etag string) {
+	writer.Header().Set("ETag", etag)
+	writer.Header().Set("Cache-Control", "no-cache")
+	if target != pluginID {
+		writer.Header().Set(canaryHeader, "true")
+	}
+	if etagMatches(request.Header.Get("If-None-Match"), etag) {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write(data)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
 }
 
 // writePluginError writes a standardized error JSON response.
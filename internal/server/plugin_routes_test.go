@@ -1,12 +1,26 @@
 package server
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/alvarotorresc/cortex/internal/plugin"
 )
@@ -19,8 +33,9 @@ func newPluginRouter(t *testing.T, registry *plugin.Registry) *chi.Mux {
 	tempDir := t.TempDir()
 	loader := plugin.NewLoader(tempDir, tempDir, registry)
 
+	hostDB, undoLog := newTestUndoLog(t)
 	router := chi.NewRouter()
-	pluginAPIRoutes(router, registry, loader)
+	pluginAPIRoutes(router, registry, loader, plugin.NewInstaller(tempDir, "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(time.Minute, nil))
 	return router
 }
 
@@ -99,6 +114,64 @@ func TestListPlugins_WithPlugins(t *testing.T) {
 	}
 }
 
+func TestListPlugins_IncludesInstallRecord(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registry.Register("alpha", nil, &plugin.Manifest{ID: "alpha", Name: "Alpha", Version: "1.1.0"})
+	registry.Register("beta", nil, &plugin.Manifest{ID: "beta", Name: "Beta", Version: "2.0.0"})
+
+	hostDB, undoLog := newTestUndoLog(t)
+	for _, step := range []func() error{
+		func() error { return hostDB.RecordPluginMigration("alpha", plugin.MigrationApplied, "") },
+		func() error { return hostDB.RecordPluginStart("alpha", "1.0.0") },
+		func() error { return hostDB.RecordPluginStart("alpha", "1.0.0") },
+		func() error { return hostDB.RecordPluginMigration("alpha", plugin.MigrationApplied, "") },
+		func() error { return hostDB.RecordPluginStart("alpha", "1.1.0") },
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("recording plugin install: %v", err)
+		}
+	}
+	if version, found, err := hostDB.LastPluginVersion("alpha"); err != nil || !found || version != "1.1.0" {
+		t.Fatalf("expected last version 1.1.0, got %q %v %v", version, found, err)
+	}
+	if _, found, err := hostDB.LastPluginVersion("beta"); err != nil || found {
+		t.Fatalf("expected no version for a plugin never loaded, got %v %v", found, err)
+	}
+
+	tempDir := t.TempDir()
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry), plugin.NewInstaller(tempDir, "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(0, nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Data []PluginListing `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	listings := make(map[string]PluginListing)
+	for _, listing := range body.Data {
+		listings[listing.ID] = listing
+	}
+
+	install := listings["alpha"].Install
+	if install == nil {
+		t.Fatalf("expected alpha to carry its install record, got %s", rec.Body.String())
+	}
+	if install.Version != "1.1.0" || install.PreviousVersion != "1.0.0" || install.UpgradedAt == nil ||
+		install.LastLoadedAt == nil || install.InstalledAt == "" || install.MigrationStatus != plugin.MigrationApplied {
+		t.Errorf("unexpected install record: %+v", install)
+	}
+	if listings["beta"].Install != nil {
+		t.Errorf("expected no install record for beta, got %+v", listings["beta"].Install)
+	}
+}
+
 func TestPluginWidget_NotFound(t *testing.T) {
 	registry := plugin.NewRegistry()
 	router := newPluginRouter(t, registry)
@@ -127,6 +200,164 @@ func TestPluginWidget_NotFound(t *testing.T) {
 	}
 }
 
+// widgetCountingPlugin is a stub plugin whose widget data changes on every
+// call, so tests can tell a cached response from a fresh one.
+type widgetCountingPlugin struct {
+	stubPlugin
+	calls int
+}
+
+func (p *widgetCountingPlugin) GetWidgetData(slot string) ([]byte, error) {
+	p.calls++
+	return []byte(fmt.Sprintf(`{"slot":%q,"call":%d}`, slot, p.calls)), nil
+}
+
+// registerWidgetPlugin registers a running widgetCountingPlugin.
+func registerWidgetPlugin(registry *plugin.Registry, id string) *widgetCountingPlugin {
+	registry.Register(id, nil, &plugin.Manifest{ID: id, Name: id, Version: "1.0.0"})
+	entry, _ := registry.Get(id)
+	stub := &widgetCountingPlugin{}
+	entry.Plugin = stub
+	return stub
+}
+
+// newWidgetRouter creates a router with plugin routes and the given widget cache.
+func newWidgetRouter(t *testing.T, registry *plugin.Registry, widgets *WidgetCache) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	hostDB, undoLog := newTestUndoLog(t)
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry), plugin.NewInstaller(tempDir, "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, widgets)
+	return router
+}
+
+func TestPluginWidget_CachedWithETag(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := registerWidgetPlugin(registry, "finance")
+	router := newWidgetRouter(t, registry, NewWidgetCache(time.Minute, nil))
+
+	first := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", first.Code, first.Body.String())
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" || first.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("expected an ETag and Cache-Control no-cache, got %v", first.Header())
+	}
+
+	second := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+	if second.Body.String() != first.Body.String() || second.Header().Get("ETag") != etag {
+		t.Errorf("expected the cached data, got %s", second.Body.String())
+	}
+	if stub.calls != 1 {
+		t.Errorf("expected the plugin to be called once, got %d", stub.calls)
+	}
+
+	// Other slots are cached separately
+	serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/budget", "")
+	if stub.calls != 2 {
+		t.Errorf("expected another slot to call the plugin, got %d calls", stub.calls)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins/finance/widget/balance", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected 304 with no body for a matching If-None-Match, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPluginWidget_InvalidatedOnChange(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := registerWidgetPlugin(registry, "finance")
+	events := NewEventHub()
+	widgets := NewWidgetCache(time.Minute, events)
+	router := newWidgetRouter(t, registry, widgets)
+
+	first := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+	widgets.PluginChanged("finance", "transactions")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins/finance/widget/balance", nil)
+	req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || stub.calls != 2 {
+		t.Fatalf("expected fresh data after a change, got %d after %d calls", rec.Code, stub.calls)
+	}
+	if rec.Header().Get("ETag") == first.Header().Get("ETag") {
+		t.Error("expected the fresh data to have a new ETag")
+	}
+
+	// A new plugin process does not serve the old process's data
+	registerWidgetPlugin(registry, "finance")
+	rec = serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+	if !strings.Contains(rec.Body.String(), `"call":1`) {
+		t.Errorf("expected data from the new process, got %s", rec.Body.String())
+	}
+}
+
+func TestPluginWidget_InvalidatedByWrites(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := registerWidgetPlugin(registry, "finance")
+	entry, _ := registry.Get("finance")
+	entry.Manifest.Permissions = []string{plugin.PermissionDBWrite}
+	router := newWidgetRouter(t, registry, NewWidgetCache(time.Minute, nil))
+
+	first := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+
+	// Reads keep the cached data
+	serveJSON(router, http.MethodGet, "/api/plugins/finance/transactions", "")
+	if rec := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", ""); rec.Body.String() != first.Body.String() || stub.calls != 1 {
+		t.Fatalf("expected a read to keep the cached widget, got %s after %d calls", rec.Body.String(), stub.calls)
+	}
+
+	if rec := serveJSON(router, http.MethodPost, "/api/plugins/finance/transactions", `{"amount":5}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected the write to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+	if stub.calls != 2 || !strings.Contains(rec.Body.String(), `"call":2`) {
+		t.Errorf("expected fresh widget data after a write, got %s after %d calls", rec.Body.String(), stub.calls)
+	}
+}
+
+func TestPluginWidget_CacheDisabled(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := registerWidgetPlugin(registry, "finance")
+	router := newWidgetRouter(t, registry, NewWidgetCache(0, nil))
+
+	first := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+	serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", "")
+	if stub.calls != 2 {
+		t.Errorf("expected every request to call the plugin, got %d calls", stub.calls)
+	}
+	if first.Header().Get("ETag") == "" {
+		t.Error("expected an ETag without the cache")
+	}
+}
+
+func TestPluginWidget_DeclaredSlotsOnly(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := registerWidgetPlugin(registry, "finance")
+	entry, _ := registry.Get("finance")
+	entry.Manifest.Widgets = []plugin.Widget{{Slot: "balance", Title: "Balance", Width: 4, Height: 2}}
+	router := newWidgetRouter(t, registry, NewWidgetCache(time.Minute, nil))
+
+	rec := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/other", "")
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusNotFound || code != "NOT_FOUND" {
+		t.Errorf("expected 404 for an undeclared slot, got %d %s", rec.Code, code)
+	}
+	if rec := serveJSON(router, http.MethodGet, "/api/plugins/finance/widget/balance", ""); rec.Code != http.StatusOK || stub.calls != 1 {
+		t.Errorf("expected the declared slot to reach the plugin, got %d after %d calls", rec.Code, stub.calls)
+	}
+
+	rec = serveJSON(router, http.MethodGet, "/api/plugins", "")
+	if !strings.Contains(rec.Body.String(), `"widgets":[{"slot":"balance","title":"Balance","width":4,"height":2}]`) {
+		t.Errorf("expected the plugin listing to declare its widgets, got %s", rec.Body.String())
+	}
+}
+
 func TestPluginProxy_NotFound(t *testing.T) {
 	registry := plugin.NewRegistry()
 	router := newPluginRouter(t, registry)
@@ -225,6 +456,46 @@ func TestUninstallPlugin_NotFound(t *testing.T) {
 	}
 }
 
+func TestUninstallPlugin_DependedOn(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registry.Register("finance-tracker", nil, &plugin.Manifest{ID: "finance-tracker"})
+	registry.Register("reports", nil, &plugin.Manifest{ID: "reports", Dependencies: []string{"finance-tracker"}})
+	router := newPluginRouter(t, registry)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/plugins/finance-tracker", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if code := decodeErrorCode(t, rec); code != "HAS_DEPENDENTS" {
+		t.Errorf("expected error code 'HAS_DEPENDENTS', got '%s'", code)
+	}
+	if _, ok := registry.Get("finance-tracker"); !ok {
+		t.Fatal("expected the plugin to stay loaded")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/dependencies", nil))
+	var graph struct {
+		Data []plugin.DependencyNode `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &graph); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	if len(graph.Data) != 2 || graph.Data[0].ID != "finance-tracker" || len(graph.Data[0].Dependents) != 1 || graph.Data[1].ID != "reports" {
+		t.Errorf("expected the graph in startup order with its dependents, got %+v", graph.Data)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/plugins/finance-tracker?force=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected a forced uninstall to succeed, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := registry.Get("finance-tracker"); ok {
+		t.Error("expected the plugin to be unloaded")
+	}
+}
+
 func TestInstallPlugin_AlreadyInstalled(t *testing.T) {
 	registry := plugin.NewRegistry()
 	registry.Register("alpha", nil, &plugin.Manifest{ID: "alpha", Name: "Alpha", Version: "1.0.0"})
@@ -284,6 +555,119 @@ func TestInstallPlugin_NotOnDisk(t *testing.T) {
 	}
 }
 
+// decodeErrorCode returns the error code of an API error response.
+func decodeErrorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse error body: %v", err)
+	}
+	return body.Error.Code
+}
+
+func TestRemoteInstall_Validation(t *testing.T) {
+	router := newPluginRouter(t, plugin.NewRegistry())
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{name: "neither url nor name", body: `{}`, wantCode: "VALIDATION_ERROR"},
+		{name: "both url and name", body: `{"url":"https://example.com/a.tar.gz","name":"a"}`, wantCode: "VALIDATION_ERROR"},
+		{name: "url without sha256", body: `{"url":"https://example.com/a.tar.gz"}`, wantCode: "VALIDATION_ERROR"},
+		{name: "name without registry", body: `{"name":"weather"}`, wantCode: "VALIDATION_ERROR"},
+		{name: "invalid json", body: `{`, wantCode: "BAD_REQUEST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/plugins/install", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d. Body: %s", rec.Code, rec.Body.String())
+			}
+			if code := decodeErrorCode(t, rec); code != tt.wantCode {
+				t.Errorf("expected error code %s, got %s", tt.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestRemoteInstall_ChecksumMismatch(t *testing.T) {
+	archiveServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("not the expected archive"))
+	}))
+	defer archiveServer.Close()
+
+	router := newPluginRouter(t, plugin.NewRegistry())
+
+	body := `{"url":"` + archiveServer.URL + `","sha256":"` + strings.Repeat("0", 64) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/install", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if code := decodeErrorCode(t, rec); code != "CHECKSUM_MISMATCH" {
+		t.Errorf("expected error code CHECKSUM_MISMATCH, got %s", code)
+	}
+}
+
+func TestRemoteInstall_LoadFailureRemovesPlugin(t *testing.T) {
+	// A well-formed archive whose binary is not a Cortex plugin.
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range map[string]string{
+		"manifest.json": `{"id":"broken","name":"Broken","version":"1.0.0"}`,
+		"plugin":        "#!/bin/sh\nexit 1\n",
+	} {
+		_ = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tarWriter.Write([]byte(content))
+	}
+	_ = tarWriter.Close()
+	_ = gzipWriter.Close()
+
+	archiveServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write(archive.Bytes())
+	}))
+	defer archiveServer.Close()
+
+	pluginDir := t.TempDir()
+	registry := plugin.NewRegistry()
+	hostDB, undoLog := newTestUndoLog(t)
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(pluginDir, t.TempDir(), registry), plugin.NewInstaller(pluginDir, "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(0, nil))
+
+	digest := sha256.Sum256(archive.Bytes())
+	body := `{"url":"` + archiveServer.URL + `","sha256":"` + hex.EncodeToString(digest[:]) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/install", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if code := decodeErrorCode(t, rec); code != "INSTALL_ERROR" {
+		t.Errorf("expected error code INSTALL_ERROR, got %s", code)
+	}
+	if _, err := os.Stat(filepath.Join(pluginDir, "broken")); !os.IsNotExist(err) {
+		t.Error("expected the unloadable plugin to be removed")
+	}
+}
+
 func TestReloadPlugin_NotFound(t *testing.T) {
 	registry := plugin.NewRegistry()
 	router := newPluginRouter(t, registry)
@@ -349,3 +733,497 @@ func TestReloadPlugin_UnloadSucceedsLoadFails(t *testing.T) {
 		t.Error("expected plugin 'alpha' to be removed from registry after failed reload")
 	}
 }
+
+// stubPlugin is an in-process CortexPlugin that echoes the request method and path.
+type stubPlugin struct{}
+
+func (p *stubPlugin) GetManifest() (*plugin.Manifest, error) { return &plugin.Manifest{}, nil }
+
+func (p *stubPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	return &plugin.APIResponse{
+		StatusCode:  http.StatusOK,
+		Body:        []byte(`{"data":{"method":"` + request.Method + `","path":"` + request.Path + `"}}`),
+		ContentType: "application/json",
+	}, nil
+}
+
+func (p *stubPlugin) GetWidgetData(slot string) ([]byte, error) { return []byte(`{}`), nil }
+func (p *stubPlugin) Migrate(databasePath string) error         { return nil }
+func (p *stubPlugin) Teardown() error                           { return nil }
+
+// registerStubPlugin registers a running stub plugin with the given permissions.
+func registerStubPlugin(registry *plugin.Registry, id string, permissions ...string) {
+	registry.Register(id, nil, &plugin.Manifest{ID: id, Name: id, Version: "1.0.0", Permissions: permissions})
+	entry, _ := registry.Get(id)
+	entry.Plugin = &stubPlugin{}
+}
+
+// requestEchoPlugin is a stub plugin that answers with the request ID it got.
+type requestEchoPlugin struct{ stubPlugin }
+
+func (p *requestEchoPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	return &plugin.APIResponse{StatusCode: http.StatusOK, Body: []byte(request.RequestID), ContentType: "text/plain"}, nil
+}
+
+func TestPluginProxy_ForwardsRequestID(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes", plugin.PermissionDBRead)
+	entry, _ := registry.Get("notes")
+	entry.Plugin = &requestEchoPlugin{}
+	router := requestID(newPluginRouter(t, registry))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins/notes/notes", nil)
+	req.Header.Set(requestIDHeader, "trace-7")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "trace-7" || rec.Header().Get(requestIDHeader) != "trace-7" {
+		t.Errorf("expected the plugin and response to carry the request ID, got %q and %q", rec.Body.String(), rec.Header().Get(requestIDHeader))
+	}
+}
+
+func TestPluginProxy_WriteWithoutPermissionDenied(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "reader", plugin.PermissionDBRead)
+	router := newPluginRouter(t, registry)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/reader/notes", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse error body: %v", err)
+	}
+
+	if body.Error.Code != "PERMISSION_DENIED" {
+		t.Errorf("expected error code 'PERMISSION_DENIED', got '%s'", body.Error.Code)
+	}
+}
+
+func TestPluginProxy_ReadWithoutWritePermissionAllowed(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "reader", plugin.PermissionDBRead)
+	router := newPluginRouter(t, registry)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins/reader/notes", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPluginProxy_WriteWithPermissionAllowed(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "writer", plugin.PermissionDBRead, plugin.PermissionDBWrite)
+	router := newPluginRouter(t, registry)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/plugins/writer/notes/1", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// crashingStubPlugin fails every call the way go-plugin reports a dead subprocess.
+type crashingStubPlugin struct{ stubPlugin }
+
+func (p *crashingStubPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	return nil, status.Error(codes.Unavailable, "connection closed")
+}
+
+func TestPluginProxy_CrashedPluginUnavailable(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "flaky", plugin.PermissionDBRead)
+	entry, _ := registry.Get("flaky")
+	entry.Plugin = &crashingStubPlugin{}
+	registerStubPlugin(registry, "healthy", plugin.PermissionDBRead)
+	router := newPluginRouter(t, registry)
+
+	// The crash mid-request is reported as 503, and the following request
+	// tries to relaunch the plugin, which fails because there is no binary on disk.
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/flaky/notes", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("request %d: expected status 503, got %d. Body: %s", i+1, rec.Code, rec.Body.String())
+		}
+		if code := decodeErrorCode(t, rec); code != "PLUGIN_UNAVAILABLE" {
+			t.Errorf("request %d: expected error code 'PLUGIN_UNAVAILABLE', got '%s'", i+1, code)
+		}
+	}
+
+	if state := registry.Breaker("flaky").State(); state != plugin.BreakerOpen {
+		t.Errorf("expected the circuit to open after a failed relaunch, got %s", state)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/healthy/notes", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected other plugins to keep working, got %d", rec.Code)
+	}
+}
+
+// slowStubPlugin fails every call the way the gRPC client reports a missed deadline.
+type slowStubPlugin struct{ stubPlugin }
+
+func (p *slowStubPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	return nil, fmt.Errorf("%w: context deadline exceeded", plugin.ErrPluginTimeout)
+}
+
+func TestPluginProxy_TimeoutIsGatewayTimeout(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "slow", plugin.PermissionDBRead)
+	entry, _ := registry.Get("slow")
+	entry.Plugin = &slowStubPlugin{}
+	router := newPluginRouter(t, registry)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/slow/notes", nil))
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if code := decodeErrorCode(t, rec); code != "PLUGIN_TIMEOUT" {
+		t.Errorf("expected error code 'PLUGIN_TIMEOUT', got '%s'", code)
+	}
+}
+
+// failingStubPlugin answers with 500 while failing is set, counting calls.
+type failingStubPlugin struct {
+	stubPlugin
+	failing bool
+	calls   int
+}
+
+func (p *failingStubPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	p.calls++
+	if p.failing {
+		return &plugin.APIResponse{StatusCode: http.StatusInternalServerError, Body: []byte(`{"error":{}}`), ContentType: "application/json"}, nil
+	}
+	return p.stubPlugin.HandleAPI(context.Background(), request)
+}
+
+func TestPluginProxy_DegradedRouteServesStaleResponse(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "reports", plugin.PermissionDBRead)
+	stub := &failingStubPlugin{}
+	entry, _ := registry.Get("reports")
+	entry.Plugin = stub
+	router := newPluginRouter(t, registry)
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/reports/summary?month=2026-02", nil))
+		return rec
+	}
+
+	if rec := get(); rec.Code != http.StatusOK || rec.Header().Get(staleHeader) != "" {
+		t.Fatalf("expected a fresh response, got %d %v", rec.Code, rec.Header())
+	}
+
+	// Failures are passed through until they use up the route's error budget.
+	stub.failing = true
+	for i := 1; i <= 8; i++ {
+		if rec := get(); rec.Code != http.StatusInternalServerError {
+			t.Fatalf("failure %d: expected the plugin's 500, got %d", i, rec.Code)
+		}
+	}
+
+	rec := get()
+	if rec.Code != http.StatusOK || rec.Header().Get(staleHeader) != "true" || rec.Header().Get("Age") == "" {
+		t.Fatalf("expected the last good response marked as stale, got %d %v", rec.Code, rec.Header())
+	}
+	if rec.Body.String() != `{"data":{"method":"GET","path":"/summary"}}` {
+		t.Errorf("unexpected stale body %s", rec.Body.String())
+	}
+
+	calls := stub.calls
+	if rec := get(); rec.Header().Get(staleHeader) != "true" || stub.calls != calls {
+		t.Errorf("expected the degraded route to be answered without calling the plugin")
+	}
+}
+
+// uploadStubPlugin records the request it receives and answers with a download.
+type uploadStubPlugin struct {
+	stubPlugin
+	received *plugin.APIRequest
+}
+
+func (p *uploadStubPlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	p.received = request
+	return &plugin.APIResponse{
+		StatusCode:  http.StatusOK,
+		Body:        []byte{0x25, 0x50, 0x44, 0x46, 0x00, 0xFF},
+		ContentType: "application/pdf",
+		Headers: map[string]string{
+			"content-disposition":         `attachment; filename="report.pdf"`,
+			"Access-Control-Allow-Origin": "*",
+			"Content-Length":              "1",
+		},
+	}, nil
+}
+
+func TestPluginProxy_HeadersAndBinaryBodies(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "files", plugin.PermissionDBRead, plugin.PermissionDBWrite)
+	stub := &uploadStubPlugin{}
+	entry, _ := registry.Get("files")
+	entry.Plugin = stub
+	router := newPluginRouter(t, registry)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/files/upload", strings.NewReader("\x00binary"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", "scan.png")
+	req.Header.Set("Authorization", "Bearer cxk_secret")
+	req.Header.Set("X-Device-Token", "device-secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if stub.received.ContentType != "application/octet-stream" || stub.received.Header("x-file-name") != "scan.png" {
+		t.Errorf("expected the content type and headers to reach the plugin, got %+v", stub.received)
+	}
+	if stub.received.Header("Authorization") != "" || stub.received.Header("X-Device-Token") != "" {
+		t.Error("expected host credentials not to be forwarded")
+	}
+
+	if rec.Header().Get("Content-Disposition") != `attachment; filename="report.pdf"` || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Errorf("expected the download headers, got %v", rec.Header())
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Body.Len() != 6 {
+		t.Errorf("expected reserved headers to be ignored and the full body, got %v (%d bytes)", rec.Header(), rec.Body.Len())
+	}
+
+	large := httptest.NewRequest(http.MethodPost, "/api/plugins/files/upload", bytes.NewReader(make([]byte, plugin.MaxAPIBodySize+1)))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, large)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413 for an oversized body, got %d", rec.Code)
+	}
+}
+
+func TestPluginLogs_Tail(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "quiet", plugin.PermissionDBRead)
+
+	dataDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dataDir, "logs"), 0755); err != nil {
+		t.Fatalf("creating log directory: %v", err)
+	}
+	// The plugin is not registered: it failed to load, but its output was kept.
+	logContent := "starting\nopening database\npanic: database is locked\n"
+	if err := os.WriteFile(filepath.Join(dataDir, "logs", "broken.log"), []byte(logContent), 0644); err != nil {
+		t.Fatalf("writing log file: %v", err)
+	}
+
+	hostDB, undoLog := newTestUndoLog(t)
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(t.TempDir(), dataDir, registry), plugin.NewInstaller(t.TempDir(), "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(0, nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/broken/logs?tail=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			ID    string   `json:"id"`
+			Lines []string `json:"lines"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	if len(body.Data.Lines) != 2 || body.Data.Lines[0] != "opening database" || body.Data.Lines[1] != "panic: database is locked" {
+		t.Errorf("expected the last 2 lines, got %q", body.Data.Lines)
+	}
+
+	// A running plugin that has not logged anything has empty logs.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/quiet/logs", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for a registered plugin without logs, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"lines":[]`) {
+		t.Errorf("expected empty lines, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/nonexistent/logs", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown plugin, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/broken/logs?tail=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid tail, got %d", rec.Code)
+	}
+}
+
+func TestPluginRouteAliases(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "finance-tracker", plugin.PermissionDBRead)
+	entry, _ := registry.Get("finance-tracker")
+	entry.Manifest.Routes = []string{"/finance/*"}
+
+	tempDir := t.TempDir()
+	router := chi.NewRouter()
+	frontend := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("frontend"))
+	})
+	router.Handle("/*", pluginRouteAliases(registry, plugin.NewLoader(tempDir, tempDir, registry), nil, nil, frontend))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/finance/accounts?limit=5", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"path":"/accounts"`) {
+		t.Errorf("expected the plugin to receive /accounts, got %s", rec.Body.String())
+	}
+
+	// Aliases are subject to the same permission checks as /api/plugins/{id}/*.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/finance/accounts", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 for a write without permission, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	if rec.Body.String() != "frontend" {
+		t.Errorf("expected unclaimed paths to reach the frontend, got %s", rec.Body.String())
+	}
+}
+
+func TestPluginStats(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes", plugin.PermissionDBRead)
+	registerStubPlugin(registry, "clock")
+
+	dataDir := t.TempDir()
+	databasePath := filepath.Join(dataDir, "plugins", "notes", "db.sqlite")
+	if err := os.MkdirAll(filepath.Dir(databasePath), 0755); err != nil {
+		t.Fatalf("creating plugin directory: %v", err)
+	}
+	database, err := sql.Open("sqlite", databasePath)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer database.Close()
+	for _, statement := range []string{
+		"PRAGMA journal_mode=WAL",
+		"CREATE TABLE notes (id INTEGER PRIMARY KEY, title TEXT NOT NULL UNIQUE, created_at TEXT)",
+		"CREATE INDEX idx_notes_created ON notes(created_at)",
+		"INSERT INTO notes (title) VALUES ('one'), ('two'), ('three')",
+	} {
+		if _, err := database.Exec(statement); err != nil {
+			t.Fatalf("%s failed: %v", statement, err)
+		}
+	}
+
+	hostDB, undoLog := newTestUndoLog(t)
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(t.TempDir(), dataDir, registry), plugin.NewInstaller(t.TempDir(), "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(0, nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/notes/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data plugin.DatabaseStats `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	stats := body.Data
+	if stats.FileSize == 0 || stats.LastWriteAt == "" || stats.Encrypted {
+		t.Errorf("unexpected file statistics: %+v", stats)
+	}
+	if len(stats.Tables) != 1 || stats.Tables[0].Name != "notes" || stats.Tables[0].Rows != 3 || stats.Tables[0].Size == 0 {
+		t.Errorf("unexpected table statistics: %+v", stats.Tables)
+	}
+	if len(stats.Indexes) != 2 {
+		t.Fatalf("expected the explicit and the UNIQUE index, got %+v", stats.Indexes)
+	}
+	if stats.Indexes[0].Name != "idx_notes_created" || stats.Indexes[0].Columns[0] != "created_at" || stats.Indexes[0].Unique {
+		t.Errorf("unexpected index statistics: %+v", stats.Indexes[0])
+	}
+	if !stats.Indexes[1].Unique || stats.Indexes[1].Size == 0 {
+		t.Errorf("expected the UNIQUE index with its size, got %+v", stats.Indexes[1])
+	}
+
+	for path, code := range map[string]string{
+		"/api/plugins/clock/stats":   "NO_DATABASE",
+		"/api/plugins/missing/stats": "NOT_FOUND",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), code) {
+			t.Errorf("%s: expected 404 %s, got %d %s", path, code, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestPluginHealth(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes")
+
+	hostDB, undoLog := newTestUndoLog(t)
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(t.TempDir(), t.TempDir(), registry), plugin.NewInstaller(t.TempDir(), "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(0, nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/notes/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			ID              string                 `json:"id"`
+			Running         bool                   `json:"running"`
+			Circuit         string                 `json:"circuit"`
+			Limits          *plugin.ResourceLimits `json:"limits"`
+			LastTermination *plugin.Termination    `json:"last_termination"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	if body.Data.ID != "notes" || !body.Data.Running || body.Data.Circuit != plugin.BreakerClosed {
+		t.Errorf("unexpected health: %+v", body.Data)
+	}
+	if body.Data.Limits != nil || body.Data.LastTermination != nil {
+		t.Errorf("expected an unlimited plugin to report no limits, got %+v", body.Data)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/missing/health", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a plugin that is not loaded, got %d", rec.Code)
+	}
+}
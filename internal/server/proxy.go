@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// proxyHeaders are the headers reverse proxies set about the original
+// request. They are only believed from trusted proxies.
+var proxyHeaders = []string{"X-Forwarded-For", "X-Real-IP", "X-Forwarded-Proto", "X-Forwarded-Host"}
+
+// basePathKey is the context key of the path prefix Cortex is served under.
+type basePathKey struct{}
+
+// trustedProxies replaces RemoteAddr with the client address reported by a
+// reverse proxy, when the request comes from one of proxies. X-Forwarded-For
+// is read from the right, skipping the hops that are trusted proxies too, so
+// a client cannot pose as another by sending the header itself; X-Real-IP is
+// used when it is absent. From other peers the forwarded headers are removed,
+// so no handler reads values the client made up.
+func trustedProxies(proxies []*net.IPNet) func(http.Handler) http.Handler {
+	trusted := func(ip net.IP) bool {
+		for _, network := range proxies {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			peer := net.ParseIP(clientIP(request))
+			if peer == nil || !trusted(peer) {
+				for _, header := range proxyHeaders {
+					request.Header.Del(header)
+				}
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			if client := forwardedClient(request, trusted); client != "" {
+				request.RemoteAddr = client
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// forwardedClient returns the client address the proxies of a request report:
+// the rightmost X-Forwarded-For hop that is not a trusted proxy, or X-Real-IP.
+func forwardedClient(request *http.Request, trusted func(net.IP) bool) string {
+	var hops []string
+	for _, value := range request.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+
+	client := ""
+	for index := len(hops) - 1; index >= 0; index-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[index]))
+		if ip == nil {
+			break
+		}
+		client = ip.String()
+		if !trusted(ip) {
+			break
+		}
+	}
+	if client != "" {
+		return client
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(request.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// withBasePath serves next under prefix, such as "/cortex", for reverse
+// proxies that pass the prefix through. The prefix is stripped before routing
+// and kept in the request context for the links Cortex builds; the bare
+// prefix redirects to itself with a trailing slash, and paths outside it are
+// not found. An empty prefix serves next at the root.
+func withBasePath(prefix string, next http.Handler) http.Handler {
+	if prefix == "" {
+		return next
+	}
+
+	stripped := http.StripPrefix(prefix, next)
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch {
+		case request.URL.Path == prefix:
+			target := prefix + "/"
+			if request.URL.RawQuery != "" {
+				target += "?" + request.URL.RawQuery
+			}
+			http.Redirect(writer, request, target, http.StatusMovedPermanently)
+		case strings.HasPrefix(request.URL.Path, prefix+"/"):
+			ctx := context.WithValue(request.Context(), basePathKey{}, prefix)
+			stripped.ServeHTTP(writer, request.WithContext(ctx))
+		default:
+			http.NotFound(writer, request)
+		}
+	})
+}
+
+// basePath returns the path prefix the request was served under, or "" at
+// the root.
+func basePath(ctx context.Context) string {
+	prefix, _ := ctx.Value(basePathKey{}).(string)
+	return prefix
+}
+
+// originAllowed reports whether origin matches one of patterns, compared
+// like the CORS middleware does: case-insensitively, with a "*" matching any
+// run of characters.
+func originAllowed(origin string, patterns []string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range patterns {
+		prefix, suffix, wildcard := strings.Cut(strings.ToLower(pattern), "*")
+		if !wildcard {
+			if origin == prefix {
+				return true
+			}
+			continue
+		}
+		if len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxies_ReadsForwardedClient(t *testing.T) {
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	_, internal, _ := net.ParseCIDR("10.0.0.0/8")
+
+	var remoteAddr, proto string
+	handler := trustedProxies([]*net.IPNet{loopback, internal})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		remoteAddr = request.RemoteAddr
+		proto = request.Header.Get("X-Forwarded-Proto")
+	}))
+
+	tests := []struct {
+		name       string
+		peer       string
+		forwarded  string
+		realIP     string
+		wantRemote string
+		wantProto  string
+	}{
+		{"trusted proxy", "127.0.0.1:4000", "203.0.113.7", "", "203.0.113.7", "https"},
+		{"spoofed hop before the client", "127.0.0.1:4000", "198.51.100.1, 203.0.113.7, 10.0.0.5", "", "203.0.113.7", "https"},
+		{"real ip header", "127.0.0.1:4000", "", "203.0.113.9", "203.0.113.9", "https"},
+		{"untrusted peer", "192.0.2.1:5000", "203.0.113.7", "", "192.0.2.1:5000", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/plugins", nil)
+			req.RemoteAddr = tt.peer
+			req.Header.Set("X-Forwarded-Proto", "https")
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if remoteAddr != tt.wantRemote || proto != tt.wantProto {
+				t.Errorf("expected remote %q and proto %q, got %q and %q", tt.wantRemote, tt.wantProto, remoteAddr, proto)
+			}
+		})
+	}
+}
+
+func TestWithBasePath(t *testing.T) {
+	var path, prefix string
+	handler := withBasePath("/cortex", http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		path = request.URL.Path
+		prefix = basePath(request.Context())
+	}))
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	if rec := serve("/cortex/api/health"); rec.Code != http.StatusOK || path != "/api/health" || prefix != "/cortex" {
+		t.Errorf("expected /api/health under /cortex, got %d %q %q", rec.Code, path, prefix)
+	}
+
+	rec := serve("/cortex?tab=1")
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/cortex/?tab=1" {
+		t.Errorf("expected a redirect to /cortex/?tab=1, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	if rec := serve("/api/health"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected paths outside the base path to be not found, got %d", rec.Code)
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	patterns := []string{"http://localhost:*", "https://dash.example.com"}
+
+	for origin, want := range map[string]bool{
+		"http://localhost:5173":             true,
+		"HTTPS://dash.example.com":          true,
+		"https://dash.example.com.evil.net": false,
+		"http://localhost":                  false,
+		"https://evil.example.com":          false,
+	} {
+		if got := originAllowed(origin, patterns); got != want {
+			t.Errorf("originAllowed(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// requestIDHeader carries a request's correlation ID, both ways.
+const requestIDHeader = "X-Request-ID"
+
+// requestIDPattern matches the IDs accepted from clients, so a caller
+// tracing a request across services can keep its own ID. Other values are
+// replaced rather than written to logs.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// requestID gives every request a correlation ID: the client's X-Request-ID
+// when it is valid, or a new random one. The ID is returned in the response's
+// X-Request-ID, logged with the request and passed to plugins in
+// APIRequest.RequestID. It is stored where chi's middleware.GetReqID finds it.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		id := request.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+
+		writer.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(request.Context(), middleware.RequestIDKey, id)
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// newRequestID returns a random 16-byte ID in hex.
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
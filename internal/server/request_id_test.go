@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := requestID(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		seen = middleware.GetReqID(request.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"client ID kept", "trace-1234.abc", true},
+		{"invalid client ID replaced", "bad id\nwith newline", false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		if test.incoming != "" {
+			req.Header.Set(requestIDHeader, test.incoming)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		returned := rec.Header().Get(requestIDHeader)
+		if returned == "" || returned != seen {
+			t.Errorf("%s: expected the response and context to carry the same ID, got %q and %q", test.name, returned, seen)
+		}
+		if (returned == test.incoming) != test.keep {
+			t.Errorf("%s: unexpected ID %q for incoming %q", test.name, returned, test.incoming)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// requestLogger logs one structured line per request. It must run after
+// requestID and trustedProxies so their values are available.
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		started := time.Now()
+		wrapped := middleware.NewWrapResponseWriter(writer, request.ProtoMajor)
+
+		next.ServeHTTP(wrapped, request)
+
+		status := wrapped.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		slog.Info("request",
+			"method", request.Method,
+			"path", request.URL.Path,
+			"status", status,
+			"bytes", wrapped.BytesWritten(),
+			"duration", time.Since(started),
+			"remote", request.RemoteAddr,
+			"request_id", middleware.GetReqID(request.Context()),
+		)
+	})
+}
@@ -11,9 +11,13 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
+	"github.com/alvarotorresc/cortex/internal/backup"
 	"github.com/alvarotorresc/cortex/internal/config"
 	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/notify"
 	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/secrets"
+	"github.com/alvarotorresc/cortex/internal/undo"
 )
 
 // HealthResponse is the JSON structure returned by the health check endpoint.
@@ -22,35 +26,96 @@ type HealthResponse struct {
 }
 
 // NewRouter creates and configures a chi router with middleware and routes.
-// It wires the plugin registry, loader, host database, and static asset serving.
-func NewRouter(cfg *config.Config, registry *plugin.Registry, loader *plugin.Loader, hostDB *db.HostDB) *chi.Mux {
+// It wires the plugin registry, loader, host database, notification center, event hub, backup manager, secret store, update checker, undo log, widget cache, and static asset serving.
+func NewRouter(cfg *config.Config, registry *plugin.Registry, loader *plugin.Loader, hostDB *db.HostDB, center *notify.Center, events *EventHub, backups *backup.Manager, secretStore *secrets.Store, updates *plugin.UpdateChecker, undoLog *undo.Log, widgets *WidgetCache) *chi.Mux {
 	router := chi.NewRouter()
 
 	// Middleware stack
-	router.Use(middleware.Logger)
+	router.Use(requestID)
+	router.Use(trustedProxies(cfg.TrustedProxyNets()))
+	router.Use(requestLogger)
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
+	router.Use(compressResponses())
+	router.Use(rateLimit(cfg.RateLimit, cfg.RateLimitBurst))
+	router.Use(decompressRequests)
+	router.Use(limitBody(int64(cfg.MaxBodyMB) << 20))
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:*", "http://127.0.0.1:*"},
+		AllowedOrigins:   cfg.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Encoding", "Content-Type", deviceTokenHeader, exportPassphraseHeader, requestIDHeader},
+		ExposedHeaders:   []string{"Link", requestIDHeader, undoTokenHeader, undoExpiresHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
+	router.Use(deviceTracking(hostDB, registry))
+	router.Use(apiKeyAuth(hostDB, registry))
+	router.Use(auditLog(hostDB, registry))
 
 	// Health check
 	router.Get("/api/health", handleHealth)
 
-	// Plugin API routes (list, install, uninstall, reload, widget data, proxy)
-	pluginAPIRoutes(router, registry, loader)
+	// Plugin API routes (list, install, remote install, update, uninstall, reload, widget data, proxy)
+	installer := plugin.NewInstaller(cfg.PluginDir, cfg.PluginRegistryURL, cfg.PluginSigningKey())
+	pluginAPIRoutes(router, registry, loader, installer, updates, hostDB, undoLog, widgets)
 
 	// Dashboard layout routes (host-level)
 	dashboardRoutes(router, hostDB)
 
-	// Serve main frontend (SvelteKit SPA with fallback to index.html)
-	router.Handle("/*", spaHandler(cfg.FrontendDir))
+	// Device registration routes (host-level)
+	deviceRoutes(router, hostDB)
+
+	// API key management for programmatic access (host-level)
+	apiKeyRoutes(router, hostDB)
+
+	// Session audit and revocation across devices and API keys (host-level)
+	sessionRoutes(router, hostDB)
+
+	// Encrypted secret store and the plugin settings that refer to it
+	secretRoutes(router, secretStore, undoLog)
+	settingsRoutes(router, hostDB, registry, loader, secretStore, undoLog)
+
+	// Undo log of destructive admin actions and plugin deletes (host-level)
+	undoRoutes(router, undoLog)
+
+	// Audit log of state-changing requests (host-level)
+	auditRoutes(router, hostDB)
+
+	// Data export (host and plugin databases)
+	exportRoutes(router, cfg.DataDir)
+
+	// Stored backups and restore (host and plugin databases)
+	backupRoutes(router, backups, registry, loader)
+
+	// Notification center and digest settings (host-level)
+	notificationRoutes(router, hostDB, center)
+
+	// Effective configuration, without secrets (host-level)
+	configRoutes(router, cfg)
+
+	// System status and history (host runs, crashes, plugin restarts)
+	systemRoutes(router, cfg.DataDir, hostDB, registry, loader)
+
+	// Prometheus metrics (plugin database WALs)
+	metricsRoutes(router, loader)
+
+	// Server-rendered HTML fallback for the core views
+	liteRoutes(router, registry, loader)
+
+	// Widget snapshots shared at signed, expiring public links
+	snapshotRoutes(router, registry, loader, hostDB)
+
+	// Deduplicated attachment store usage (host-level)
+	attachmentRoutes(router, hostDB)
+
+	// Global search across plugins implementing Search (host-level)
+	searchRoutes(router, registry, loader)
+
+	// Live push channel for plugin data changes (host-level)
+	eventRoutes(router, events, cfg.AllowedOrigins)
+
+	// Serve plugin route aliases (e.g. /finance/*), then the main frontend
+	// (SvelteKit SPA with fallback to index.html) with its security headers
+	router.Handle("/*", pluginRouteAliases(registry, loader, undoLog, widgets, securityHeaders(cfg, registry, spaHandler(cfg.FrontendDir))))
 
 	return router
 }
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	// maxSearchQueryLength caps the ?q= parameter of GET /api/search.
+	maxSearchQueryLength = 200
+	// defaultSearchLimit is how many results GET /api/search returns.
+	defaultSearchLimit = 20
+	// maxSearchLimit caps the ?limit= query parameter.
+	maxSearchLimit = 100
+	// searchTimeout bounds how long a search waits for plugins. Results of
+	// plugins that have not answered by then are left out.
+	searchTimeout = 3 * time.Second
+)
+
+// SearchHit is a plugin's search result as ranked by the host.
+type SearchHit struct {
+	Plugin string `json:"plugin"`
+	plugin.SearchResult
+	Score int `json:"score"`
+}
+
+// searchOutcome is one plugin's answer to a search.
+type searchOutcome struct {
+	pluginID string
+	results  []plugin.SearchResult
+	err      error
+}
+
+// searchRoutes registers the global search across every plugin implementing Searcher.
+func searchRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader) {
+	router.Get("/api/search", func(writer http.ResponseWriter, request *http.Request) {
+		query := strings.TrimSpace(request.URL.Query().Get("q"))
+		if query == "" || len(query) > maxSearchQueryLength {
+			writeSearchError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "q must be between 1 and "+strconv.Itoa(maxSearchQueryLength)+" characters")
+			return
+		}
+
+		limit := defaultSearchLimit
+		if raw := request.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 || parsed > maxSearchLimit {
+				writeSearchError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "limit must be a number between 1 and "+strconv.Itoa(maxSearchLimit))
+				return
+			}
+			limit = parsed
+		}
+
+		hits, failed := searchPlugins(registry, loader, query, requestAPIKeyID(request))
+		if len(hits) > limit {
+			hits = hits[:limit]
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"query":   query,
+				"results": hits,
+				"failed":  failed,
+			},
+		})
+	})
+}
+
+// searchPlugins queries all plugins concurrently and returns their results
+// ranked best first, together with the IDs of plugins that failed or timed
+// out. Plugins without a Search hook are skipped silently.
+func searchPlugins(registry *plugin.Registry, loader *plugin.Loader, query string, apiKeyID int64) ([]SearchHit, []string) {
+	manifests := registry.List()
+	outcomes := make(chan searchOutcome, len(manifests))
+	pending := make(map[string]bool, len(manifests))
+	for _, manifest := range manifests {
+		pending[manifest.ID] = true
+		go func(pluginID string) {
+			results, err := searchPlugin(registry, loader, pluginID, query, apiKeyID)
+			outcomes <- searchOutcome{pluginID: pluginID, results: results, err: err}
+		}(manifest.ID)
+	}
+
+	timeout := time.NewTimer(searchTimeout)
+	defer timeout.Stop()
+
+	answered := make([]searchOutcome, 0, len(manifests))
+	failed := []string{}
+	for len(pending) > 0 {
+		select {
+		case outcome := <-outcomes:
+			delete(pending, outcome.pluginID)
+			switch {
+			case errors.Is(outcome.err, plugin.ErrNotImplemented):
+			case outcome.err != nil:
+				slog.Warn("plugin search failed", "plugin", outcome.pluginID, "error", outcome.err)
+				failed = append(failed, outcome.pluginID)
+			default:
+				answered = append(answered, outcome)
+			}
+		case <-timeout.C:
+			for pluginID := range pending {
+				slog.Warn("plugin search timed out", "plugin", pluginID, "timeout", searchTimeout)
+				failed = append(failed, pluginID)
+			}
+			pending = nil
+		}
+	}
+	slices.Sort(failed)
+
+	// Merge in plugin order so equal scores rank the same on every request.
+	slices.SortFunc(answered, func(a, b searchOutcome) int { return cmp.Compare(a.pluginID, b.pluginID) })
+	hits := []SearchHit{}
+	for _, outcome := range answered {
+		for position, result := range outcome.results {
+			if position == plugin.MaxSearchResults {
+				break
+			}
+			if result.Link == "" {
+				result.Link = "/plugins/" + outcome.pluginID
+			}
+			hits = append(hits, SearchHit{
+				Plugin:       outcome.pluginID,
+				SearchResult: result,
+				Score:        scoreSearchResult(query, result, position),
+			})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b SearchHit) int { return cmp.Compare(b.Score, a.Score) })
+
+	return hits, failed
+}
+
+// searchPlugin runs one plugin's Search, through its canary when the rollout
+// selects this request.
+func searchPlugin(registry *plugin.Registry, loader *plugin.Loader, pluginID string, query string, apiKeyID int64) ([]plugin.SearchResult, error) {
+	target := registry.RouteTarget(pluginID, apiKeyID)
+	entry, err := loader.Acquire(target)
+	if err != nil {
+		return nil, err
+	}
+
+	searcher, ok := entry.Plugin.(plugin.Searcher)
+	if !ok {
+		loader.Release(target, entry, nil)
+		return nil, plugin.ErrNotImplemented
+	}
+
+	results, err := searcher.Search(query)
+	if errors.Is(err, plugin.ErrNotImplemented) {
+		// Lacking an optional hook is not a plugin failure.
+		loader.Release(target, entry, nil)
+		return nil, err
+	}
+	loader.Release(target, entry, err)
+	return results, err
+}
+
+// scoreSearchResult ranks results from different plugins on one scale by how
+// well their text matches the query: an exact title first, then titles
+// starting with or containing it, then matches in the snippet. Within a tier,
+// the plugin's own order decides.
+func scoreSearchResult(query string, result plugin.SearchResult, position int) int {
+	query = strings.ToLower(query)
+	title := strings.ToLower(result.Title)
+	snippet := strings.ToLower(result.Snippet)
+	terms := strings.Fields(query)
+
+	var score int
+	switch {
+	case title == query:
+		score = 1000
+	case strings.HasPrefix(title, query):
+		score = 800
+	case strings.Contains(title, query):
+		score = 600
+	case containsAll(title, terms):
+		score = 500
+	case strings.Contains(snippet, query):
+		score = 300
+	case containsAll(title+" "+snippet, terms):
+		score = 200
+	default:
+		// The plugin matched on a field it does not return.
+		score = 100
+	}
+
+	// Tiers are at least 100 points apart and at most plugin.MaxSearchResults results
+	// are ranked per plugin, so the position penalty never crosses into another tier.
+	return score - position
+}
+
+// containsAll reports whether text contains every term.
+func containsAll(text string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return len(terms) > 0
+}
+
+// writeSearchError writes a standardized error JSON response for the search endpoint.
+func writeSearchError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// searchStubPlugin returns fixed search results.
+type searchStubPlugin struct {
+	stubPlugin
+	results []plugin.SearchResult
+	err     error
+}
+
+func (p *searchStubPlugin) Search(query string) ([]plugin.SearchResult, error) {
+	return p.results, p.err
+}
+
+func registerSearchStub(registry *plugin.Registry, id string, impl plugin.CortexPlugin) {
+	registry.Register(id, nil, &plugin.Manifest{ID: id, Name: id, Version: "1.0.0"})
+	entry, _ := registry.Get(id)
+	entry.Plugin = impl
+}
+
+func newSearchRouter(t *testing.T, registry *plugin.Registry) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	router := chi.NewRouter()
+	searchRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry))
+	return router
+}
+
+func TestSearch_RanksAcrossPlugins(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerSearchStub(registry, "quick-notes", &searchStubPlugin{results: []plugin.SearchResult{
+		{Type: "note", ID: "1", Title: "Groceries", Snippet: "buy rent-free milk"},
+		{Type: "note", ID: "2", Title: "Rent", Snippet: "due on the 1st", Link: "/plugins/quick-notes"},
+	}})
+	registerSearchStub(registry, "finance-tracker", &searchStubPlugin{results: []plugin.SearchResult{
+		{Type: "transaction", ID: "9", Title: "Rent payment", Snippet: "-950.00 EUR"},
+	}})
+	registerSearchStub(registry, "broken", &searchStubPlugin{err: errors.New("database locked")})
+	// A plugin without a Search hook is skipped, not reported as failed.
+	registerSearchStub(registry, "project-hub", &stubPlugin{})
+
+	router := newSearchRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search?q=rent", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			Results []SearchHit `json:"results"`
+			Failed  []string    `json:"failed"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+
+	got := make([]string, 0, len(body.Data.Results))
+	for _, hit := range body.Data.Results {
+		got = append(got, hit.Plugin+"/"+hit.ID)
+	}
+	want := []string{"quick-notes/2", "finance-tracker/9", "quick-notes/1"}
+	if len(got) != len(want) {
+		t.Fatalf("expected results %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected results %v, got %v", want, got)
+		}
+	}
+
+	if link := body.Data.Results[1].Link; link != "/plugins/finance-tracker" {
+		t.Errorf("expected a default link to the plugin page, got %q", link)
+	}
+	if len(body.Data.Failed) != 1 || body.Data.Failed[0] != "broken" {
+		t.Errorf("expected only the broken plugin to fail, got %v", body.Data.Failed)
+	}
+}
+
+func TestSearch_Validation(t *testing.T) {
+	router := newSearchRouter(t, plugin.NewRegistry())
+
+	for _, target := range []string{"/api/search", "/api/search?q=%20", "/api/search?q=rent&limit=0"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, rec.Code)
+		}
+	}
+}
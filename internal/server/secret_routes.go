@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/secrets"
+	"github.com/alvarotorresc/cortex/internal/undo"
+)
+
+// secretRoutes registers the secret store endpoints. Values can be written
+// and deleted but are never returned; plugin settings refer to them by name.
+func secretRoutes(router chi.Router, store *secrets.Store, undoLog *undo.Log) {
+	undoLog.Handle(undoSecretDelete, restoreDeletedSecret(store))
+
+	// GET /api/secrets -- list secret names (never includes the values)
+	router.Get("/api/secrets", func(writer http.ResponseWriter, request *http.Request) {
+		stored, err := store.List()
+		if err != nil {
+			writeSecretError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list secrets")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"enabled": store.Enabled(),
+				"secrets": stored,
+			},
+		})
+	})
+
+	// PUT /api/secrets/{name} -- create or replace a secret
+	router.Put("/api/secrets/{name}", func(writer http.ResponseWriter, request *http.Request) {
+		name := chi.URLParam(request, "name")
+		if !plugin.ValidSecretName(name) {
+			writeSecretError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "name must be up to 64 lowercase letters, digits, dashes or underscores")
+			return
+		}
+		if !store.Enabled() {
+			writeSecretError(writer, http.StatusConflict, "ENCRYPTION_DISABLED", secrets.ErrDisabled.Error())
+			return
+		}
+
+		var body struct {
+			Value string `json:"value"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, secrets.MaxValueSize*2)).Decode(&body); err != nil {
+			writeSecretError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+		if body.Value == "" || len(body.Value) > secrets.MaxValueSize {
+			writeSecretError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "value is required and must be at most 8 KiB")
+			return
+		}
+
+		if err := store.Set(name, body.Value); err != nil {
+			writeSecretError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to save secret")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"name": name, "status": "saved"},
+		})
+	})
+
+	// DELETE /api/secrets/{name} -- delete a secret, which can be undone for the undo window
+	router.Delete("/api/secrets/{name}", func(writer http.ResponseWriter, request *http.Request) {
+		name := chi.URLParam(request, "name")
+		sealed, err := store.Sealed(name)
+		if err != nil {
+			if errors.Is(err, plugin.ErrSecretNotFound) {
+				writeSecretError(writer, http.StatusNotFound, "NOT_FOUND", "secret not found")
+				return
+			}
+			writeSecretError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to delete secret")
+			return
+		}
+
+		action, err := undoLog.Record(undoSecretDelete, name, deletedSecret{Sealed: sealed})
+		if err != nil {
+			writeSecretError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to delete secret")
+			return
+		}
+		if err := store.Delete(name); err != nil {
+			undoLog.Discard(action.ID)
+			if errors.Is(err, plugin.ErrSecretNotFound) {
+				writeSecretError(writer, http.StatusNotFound, "NOT_FOUND", "secret not found")
+				return
+			}
+			writeSecretError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to delete secret")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"name": name, "status": "deleted", "undo": action},
+		})
+	})
+}
+
+// writeSecretError writes a standardized error JSON response for secret endpoints.
+func writeSecretError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
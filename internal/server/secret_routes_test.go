@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/secrets"
+	"github.com/alvarotorresc/cortex/internal/undo"
+)
+
+// newSecretRouter creates a chi router with the secret and settings routes
+// registered for a loaded "prices" plugin.
+func newSecretRouter(t *testing.T, master []byte) *chi.Mux {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	registry := plugin.NewRegistry()
+	registry.Register("prices", nil, &plugin.Manifest{ID: "prices", Version: "1.2.0"})
+
+	store := secrets.NewStore(hostDB, master)
+	undoLog := undo.NewLog(hostDB, t.TempDir(), time.Minute)
+	router := chi.NewRouter()
+	secretRoutes(router, store, undoLog)
+	settingsRoutes(router, hostDB, registry, plugin.NewLoader(t.TempDir(), t.TempDir(), registry), store, undoLog)
+	undoRoutes(router, undoLog)
+	return router
+}
+
+func serveJSON(router *chi.Mux, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSettings_SecretsAreReferencedNeverReturned(t *testing.T) {
+	router := newSecretRouter(t, bytes.Repeat([]byte{7}, 32))
+
+	rec := serveJSON(router, http.MethodPut, "/api/secrets/price-api", `{"value": "sk_live_123"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 saving the secret, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveJSON(router, http.MethodPut, "/api/plugins/prices/settings", `{"currency": "EUR", "api_key": {"$secret": "price-api"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 saving settings, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, path := range []string{"/api/plugins/prices/settings", "/api/secrets"} {
+		rec = serveJSON(router, http.MethodGet, path, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected status 200, got %d", path, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "sk_live_123") {
+			t.Errorf("GET %s returned the secret value: %s", path, rec.Body.String())
+		}
+	}
+
+	rec = serveJSON(router, http.MethodGet, "/api/plugins/prices/settings", "")
+	var response struct {
+		Data pluginSettingsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse settings: %v", err)
+	}
+	if response.Data.Version != "1.2.0" || len(response.Data.Secrets) != 1 || response.Data.Secrets[0] != "price-api" {
+		t.Errorf("unexpected settings response: %+v", response.Data)
+	}
+	if !strings.Contains(string(response.Data.Settings), `{"$secret":"price-api"}`) {
+		t.Errorf("expected the secret reference in the settings, got %s", response.Data.Settings)
+	}
+}
+
+func TestSettings_Validation(t *testing.T) {
+	router := newSecretRouter(t, bytes.Repeat([]byte{7}, 32))
+
+	for _, tc := range []struct {
+		path, body string
+		want       int
+	}{
+		{"/api/plugins/prices/settings", `{"api_key": {"$secret": "missing"}}`, http.StatusBadRequest},
+		{"/api/plugins/prices/settings", `["not", "an", "object"]`, http.StatusBadRequest},
+		{"/api/plugins/unknown/settings", `{}`, http.StatusNotFound},
+		{"/api/secrets/Bad%20Name", `{"value": "x"}`, http.StatusBadRequest},
+		{"/api/secrets/price-api", `{"value": ""}`, http.StatusBadRequest},
+	} {
+		if rec := serveJSON(router, http.MethodPut, tc.path, tc.body); rec.Code != tc.want {
+			t.Errorf("PUT %s %s: expected status %d, got %d", tc.path, tc.body, tc.want, rec.Code)
+		}
+	}
+
+	if rec := serveJSON(router, http.MethodDelete, "/api/secrets/price-api", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting a missing secret, got %d", rec.Code)
+	}
+}
+
+// settingsListenerPlugin records the settings the host hands it.
+type settingsListenerPlugin struct {
+	stubPlugin
+	received []string
+}
+
+func (p *settingsListenerPlugin) OnSettingsChanged(settings []byte) error {
+	p.received = append(p.received, string(settings))
+	return nil
+}
+
+func TestSettings_ChangesReachRunningPlugin(t *testing.T) {
+	hostDB, undoLog := newTestUndoLog(t)
+	registry := plugin.NewRegistry()
+	registry.Register("prices", nil, &plugin.Manifest{ID: "prices", Version: "1.2.0"})
+	entry, _ := registry.Get("prices")
+	listener := &settingsListenerPlugin{}
+	entry.Plugin = listener
+
+	router := chi.NewRouter()
+	settingsRoutes(router, hostDB, registry, plugin.NewLoader(t.TempDir(), t.TempDir(), registry), secrets.NewStore(hostDB, nil), undoLog)
+	undoRoutes(router, undoLog)
+
+	if rec := serveJSON(router, http.MethodPut, "/api/plugins/prices/settings", `{"currency": "USD"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 saving settings, got %d", rec.Code)
+	}
+	rec := serveJSON(router, http.MethodDelete, "/api/plugins/prices/settings", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 wiping settings, got %d", rec.Code)
+	}
+	action := decodeUndoAction(t, rec.Body.Bytes())
+	if rec := serveJSON(router, http.MethodPost, "/api/admin/undo/"+action.ID, ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 undoing the wipe, got %d", rec.Code)
+	}
+
+	want := []string{`{"currency":"USD"}`, `{}`, `{"currency":"USD"}`}
+	if strings.Join(listener.received, " ") != strings.Join(want, " ") {
+		t.Errorf("expected the plugin to receive %v, got %v", want, listener.received)
+	}
+}
+
+func TestSecrets_DisabledWithoutEncryption(t *testing.T) {
+	router := newSecretRouter(t, nil)
+
+	rec := serveJSON(router, http.MethodPut, "/api/secrets/price-api", `{"value": "sk_live_123"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 without a master key, got %d", rec.Code)
+	}
+}
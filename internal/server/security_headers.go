@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/internal/config"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// cspOff disables the Content-Security-Policy header when set as CORTEX_CSP.
+const cspOff = "off"
+
+// securityHeaders sets security headers on the frontend assets next serves.
+// Plugin UIs run in the same document as the host's, so the
+// Content-Security-Policy is the configured base policy extended with the
+// sources every loaded plugin declares in its manifest.
+func securityHeaders(cfg *config.Config, registry *plugin.Registry, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		header := writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		if cfg.CSP != cspOff {
+			header.Set("Content-Security-Policy", buildCSP(cfg.CSP, cfg.FrameAncestors, registry.List()))
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// buildCSP adds frameAncestors and the manifests' CSP extensions to base. A
+// directive the base policy lacks starts from its default-src sources, so
+// extending it never narrows what default-src allowed.
+func buildCSP(base string, frameAncestors string, manifests []*plugin.Manifest) string {
+	var names []string
+	sources := make(map[string][]string)
+	for _, directive := range strings.Split(base, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, ok := sources[name]; !ok {
+			names = append(names, name)
+		}
+		sources[name] = fields[1:]
+	}
+
+	add := func(name string, extra []string) {
+		current, ok := sources[name]
+		if !ok {
+			names = append(names, name)
+			current = append([]string(nil), sources["default-src"]...)
+		}
+		for _, source := range extra {
+			if !containsString(current, source) {
+				current = append(current, source)
+			}
+		}
+		sources[name] = current
+	}
+
+	// Plugins are applied in ID order so the header is stable.
+	sorted := append([]*plugin.Manifest(nil), manifests...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	for _, manifest := range sorted {
+		directives := make([]string, 0, len(manifest.CSP))
+		for name := range manifest.CSP {
+			directives = append(directives, name)
+		}
+		sort.Strings(directives)
+		for _, name := range directives {
+			add(name, manifest.CSP[name])
+		}
+	}
+
+	if frameAncestors != "" {
+		if _, ok := sources["frame-ancestors"]; !ok {
+			names = append(names, "frame-ancestors")
+		}
+		sources["frame-ancestors"] = strings.Fields(frameAncestors)
+	}
+
+	directives := make([]string, 0, len(names))
+	for _, name := range names {
+		directives = append(directives, strings.Join(append([]string{name}, sources[name]...), " "))
+	}
+	return strings.Join(directives, "; ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
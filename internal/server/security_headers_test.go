@@ -0,0 +1,70 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvarotorresc/cortex/internal/config"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+func TestSecurityHeaders_MergesPluginCSP(t *testing.T) {
+	frontendDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(frontendDir, "index.html"), []byte("<html></html>"), 0644); err != nil {
+		t.Fatalf("writing index.html: %v", err)
+	}
+
+	registry := plugin.NewRegistry()
+	registry.Register("finance", nil, &plugin.Manifest{ID: "finance", CSP: map[string][]string{
+		"font-src":    {"https://fonts.gstatic.com"},
+		"style-src":   {"https://fonts.googleapis.com"},
+		"connect-src": {"wss://live.example.com"},
+	}})
+	registry.Register("maps", nil, &plugin.Manifest{ID: "maps", CSP: map[string][]string{
+		"font-src":  {"https://fonts.gstatic.com"},
+		"frame-src": {"https://*.openstreetmap.org"},
+	}})
+
+	cfg := &config.Config{
+		CSP:            "default-src 'self'; font-src 'self'; style-src 'self'; connect-src 'self'",
+		FrameAncestors: "'none'",
+		ReferrerPolicy: "no-referrer",
+	}
+	handler := securityHeaders(cfg, registry, spaHandler(frontendDir))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/finance/transactions", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	want := "default-src 'self'; font-src 'self' https://fonts.gstatic.com; style-src 'self' https://fonts.googleapis.com; " +
+		"connect-src 'self' wss://live.example.com; frame-src 'self' https://*.openstreetmap.org; frame-ancestors 'none'"
+	if got := rec.Header().Get("Content-Security-Policy"); got != want {
+		t.Errorf("expected CSP\n%s\ngot\n%s", want, got)
+	}
+	if got := rec.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("expected X-Content-Type-Options nosniff, got %q", got)
+	}
+	if got := rec.Header().Get("Referrer-Policy"); got != "no-referrer" {
+		t.Errorf("expected Referrer-Policy no-referrer, got %q", got)
+	}
+}
+
+func TestSecurityHeaders_CSPOff(t *testing.T) {
+	cfg := &config.Config{CSP: "off", FrameAncestors: "'self'", ReferrerPolicy: "same-origin"}
+	handler := securityHeaders(cfg, plugin.NewRegistry(), spaHandler(t.TempDir()))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("expected no CSP when disabled, got %q", got)
+	}
+	if got := rec.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("expected X-Content-Type-Options nosniff, got %q", got)
+	}
+}
@@ -3,16 +3,20 @@ package server
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/alvarotorresc/cortex/internal/backup"
 	"github.com/alvarotorresc/cortex/internal/config"
 	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/notify"
 	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/secrets"
+	"github.com/alvarotorresc/cortex/internal/undo"
 )
 
 const (
@@ -25,22 +29,34 @@ const (
 // Start initializes and runs the HTTP server with graceful shutdown.
 // It blocks until a termination signal is received (SIGINT or SIGTERM),
 // then gracefully shuts down the server.
-func Start(cfg *config.Config, registry *plugin.Registry, loader *plugin.Loader, hostDB *db.HostDB) error {
-	router := NewRouter(cfg, registry, loader, hostDB)
+func Start(cfg *config.Config, registry *plugin.Registry, loader *plugin.Loader, hostDB *db.HostDB, center *notify.Center, events *EventHub, backups *backup.Manager, secretStore *secrets.Store, updates *plugin.UpdateChecker, undoLog *undo.Log, widgets *WidgetCache) error {
+	router := NewRouter(cfg, registry, loader, hostDB, center, events, backups, secretStore, updates, undoLog, widgets)
 
 	server := &http.Server{
 		Addr:         cfg.Address(),
-		Handler:      router,
+		Handler:      withBasePath(cfg.BasePath, router),
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
 	}
 
+	if cfg.TLSEnabled() {
+		tlsConfig, err := loadTLSConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSSelfSigned, cfg.TLSHosts)
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = tlsConfig
+	}
+
 	// Channel to listen for errors from the server goroutine.
 	serverErrors := make(chan error, 1)
 
 	go func() {
-		log.Printf("Cortex server starting on %s", cfg.Address())
+		slog.Info("cortex server starting", "address", cfg.Address(), "tls", cfg.TLSEnabled())
+		if server.TLSConfig != nil {
+			serverErrors <- server.ListenAndServeTLS("", "")
+			return
+		}
 		serverErrors <- server.ListenAndServe()
 	}()
 
@@ -54,7 +70,7 @@ func Start(cfg *config.Config, registry *plugin.Registry, loader *plugin.Loader,
 		return fmt.Errorf("server error: %w", err)
 
 	case sig := <-shutdown:
-		log.Printf("Received signal %v, starting graceful shutdown", sig)
+		slog.Info("received signal, starting graceful shutdown", "signal", sig.String())
 
 		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
@@ -64,7 +80,7 @@ func Start(cfg *config.Config, registry *plugin.Registry, loader *plugin.Loader,
 			return fmt.Errorf("graceful shutdown failed: %w", err)
 		}
 
-		log.Println("Server stopped gracefully")
+		slog.Info("server stopped gracefully")
 	}
 
 	return nil
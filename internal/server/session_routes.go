@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// maxUserAgentLength caps the user agent stored for a session.
+const maxUserAgentLength = 255
+
+// sessionResponse is a session as listed by GET /api/sessions.
+type sessionResponse struct {
+	db.Session
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+// sessionRoutes registers the audit view over device tokens and API keys:
+// every credential that can currently reach the instance, with where and when
+// it was last used, and endpoints to revoke them.
+func sessionRoutes(router chi.Router, hostDB *db.HostDB) {
+	// GET /api/sessions -- list active sessions, most recently active first
+	router.Get("/api/sessions", func(writer http.ResponseWriter, request *http.Request) {
+		sessions, err := hostDB.ListSessions()
+		if err != nil {
+			writeSessionError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list sessions")
+			return
+		}
+
+		currentKind, currentID := currentSession(request)
+		response := make([]sessionResponse, len(sessions))
+		for i, session := range sessions {
+			response[i] = sessionResponse{
+				Session: session,
+				Current: session.Kind == currentKind && session.CredentialID == currentID,
+			}
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": response})
+	})
+
+	// DELETE /api/sessions/{kind}/{credentialID} -- revoke one session
+	router.Delete("/api/sessions/{kind}/{credentialID}", func(writer http.ResponseWriter, request *http.Request) {
+		credentialID, err := strconv.ParseInt(chi.URLParam(request, "credentialID"), 10, 64)
+		if err != nil || credentialID <= 0 {
+			writeSessionError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid session ID")
+			return
+		}
+
+		kind := chi.URLParam(request, "kind")
+		switch kind {
+		case db.SessionDevice:
+			err = hostDB.RevokeDevice(credentialID)
+		case db.SessionAPIKey:
+			err = hostDB.DeleteAPIKey(credentialID)
+		default:
+			writeSessionError(writer, http.StatusBadRequest, "BAD_REQUEST", "session kind must be device or api_key")
+			return
+		}
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				writeSessionError(writer, http.StatusNotFound, "NOT_FOUND", "session not found")
+				return
+			}
+			writeSessionError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to revoke session")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"kind": kind, "credential_id": credentialID, "status": "revoked"},
+		})
+	})
+
+	// POST /api/sessions/revoke-all -- log out everywhere except the calling session
+	router.Post("/api/sessions/revoke-all", func(writer http.ResponseWriter, request *http.Request) {
+		currentKind, currentID := currentSession(request)
+		revoked, err := hostDB.RevokeAllSessions(currentKind, currentID)
+		if err != nil {
+			writeSessionError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to revoke sessions")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"revoked": revoked},
+		})
+	})
+}
+
+// currentSession returns the kind and credential ID of the session the request
+// was made with, or an empty kind when it carried no credential.
+func currentSession(request *http.Request) (string, int64) {
+	if device, ok := deviceFromContext(request.Context()); ok {
+		return db.SessionDevice, device.ID
+	}
+	if apiKey, ok := apiKeyFromContext(request.Context()); ok {
+		return db.SessionAPIKey, apiKey.ID
+	}
+	return "", 0
+}
+
+// clientInfo returns the IP and user agent recorded for a session's request.
+// The IP comes from RemoteAddr, which the trustedProxies middleware has
+// replaced with the proxied client address when there is one.
+func clientInfo(request *http.Request) (string, string) {
+	ip := request.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	userAgent := request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return ip, userAgent
+}
+
+// writeSessionError writes a standardized error JSON response for session endpoints.
+func writeSessionError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
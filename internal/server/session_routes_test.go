@@ -0,0 +1,211 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// newSessionRouter creates a chi router with both credential middlewares and
+// the device, API key and session routes registered.
+func newSessionRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	router := chi.NewRouter()
+	router.Use(deviceTracking(hostDB, nil))
+	router.Use(apiKeyAuth(hostDB, nil))
+	deviceRoutes(router, hostDB)
+	apiKeyRoutes(router, hostDB)
+	sessionRoutes(router, hostDB)
+	return router
+}
+
+// listSessions fetches GET /api/sessions authenticated with the given API key.
+func listSessions(t *testing.T, router *chi.Mux, key string) []sessionResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data []sessionResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse sessions: %v", err)
+	}
+	return response.Data
+}
+
+func TestSessions_ListsDevicesAndKeysWithClientInfo(t *testing.T) {
+	router := newSessionRouter(t)
+	deviceID, token := registerDevice(t, router, "Phone", nil)
+	keyID, key := createAPIKey(t, router, "Shortcuts", deviceCredential(token))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/devices/sync", nil)
+	req.Header.Set(deviceTokenHeader, token)
+	req.Header.Set("User-Agent", "CortexApp/1.4 (iOS)")
+	req.RemoteAddr = "192.0.2.10:51234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	sessions := listSessions(t, router, key)
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+
+	byKind := make(map[string]sessionResponse)
+	for _, session := range sessions {
+		byKind[session.Kind] = session
+	}
+
+	device := byKind[db.SessionDevice]
+	if device.CredentialID != deviceID || device.Name != "Phone" {
+		t.Errorf("unexpected device session: %+v", device)
+	}
+	if device.IP != "192.0.2.10" || device.UserAgent != "CortexApp/1.4 (iOS)" {
+		t.Errorf("expected the device's IP and user agent, got %q and %q", device.IP, device.UserAgent)
+	}
+	if device.LastActivityAt == nil || device.Current {
+		t.Errorf("expected the device to be active and not current, got %+v", device)
+	}
+
+	apiKey := byKind[db.SessionAPIKey]
+	if apiKey.CredentialID != keyID || !apiKey.Current {
+		t.Errorf("expected the API key session to be current, got %+v", apiKey)
+	}
+}
+
+func TestSessions_RevokeAllKeepsCurrentSession(t *testing.T) {
+	router := newSessionRouter(t)
+	_, token := registerDevice(t, router, "Phone", nil)
+	registerDevice(t, router, "Laptop", deviceCredential(token))
+	createAPIKey(t, router, "Backup script", deviceCredential(token))
+	_, key := createAPIKey(t, router, "Shortcuts", deviceCredential(token))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/revoke-all", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Data struct {
+			Revoked int `json:"revoked"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.Data.Revoked != 3 {
+		t.Errorf("expected 3 revoked sessions, got %d", response.Data.Revoked)
+	}
+
+	sessions := listSessions(t, router, key)
+	if len(sessions) != 1 || !sessions[0].Current {
+		t.Errorf("expected only the current session to remain, got %+v", sessions)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/devices/sync", nil)
+	req.Header.Set(deviceTokenHeader, token)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected revoked device to get 401, got %d", rec.Code)
+	}
+}
+
+func TestSessions_RevokeOne(t *testing.T) {
+	router := newSessionRouter(t)
+	_, laptop := registerDevice(t, router, "Laptop", nil)
+	deviceID, token := registerDevice(t, router, "Phone", deviceCredential(laptop))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/sessions/device/"+strconv.FormatInt(deviceID, 10), nil)
+	req.Header.Set(deviceTokenHeader, laptop)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/devices/sync", nil)
+	req.Header.Set(deviceTokenHeader, token)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected revoked device to get 401, got %d", rec.Code)
+	}
+
+	for path, want := range map[string]int{
+		"/api/sessions/api_key/999": http.StatusNotFound,
+		"/api/sessions/cookie/1":    http.StatusBadRequest,
+		"/api/sessions/device/abc":  http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set(deviceTokenHeader, laptop)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("DELETE %s: expected status %d, got %d", path, want, rec.Code)
+		}
+	}
+}
+
+func TestSessions_RevokeAllThenAccess(t *testing.T) {
+	router := newSessionRouter(t)
+	phoneID, phone := registerDevice(t, router, "Phone", nil)
+	_, laptop := registerDevice(t, router, "Laptop", deviceCredential(phone))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/revoke-all", nil)
+	req.Header.Set(deviceTokenHeader, phone)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	for token, want := range map[string]int{phone: http.StatusOK, laptop: http.StatusUnauthorized, "": http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, "/api/sessions", nil)
+		if token != "" {
+			req.Header.Set(deviceTokenHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("token %q after revoke-all: expected status %d, got %d", token, want, rec.Code)
+		}
+	}
+
+	// Revoking the last session opens the instance again instead of locking it.
+	req = httptest.NewRequest(http.MethodDelete, "/api/sessions/device/"+strconv.FormatInt(phoneID, 10), nil)
+	req.Header.Set(deviceTokenHeader, phone)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected access without a credential once no session is left, got %d", rec.Code)
+	}
+}
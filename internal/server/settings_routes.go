@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/secrets"
+	"github.com/alvarotorresc/cortex/internal/undo"
+)
+
+// maxSettingsSize caps the settings JSON a plugin can be given.
+const maxSettingsSize = 64 << 10
+
+// pluginSettingsResponse is a plugin's stored settings. Secrets appear only as
+// the {"$secret": name} references the settings were saved with.
+type pluginSettingsResponse struct {
+	PluginID string          `json:"plugin_id"`
+	Version  string          `json:"version"`
+	Settings json.RawMessage `json:"settings"`
+	Secrets  []string        `json:"secrets"`
+}
+
+// settingsRoutes registers the plugin settings endpoints. Running plugins that
+// implement SettingsListener are handed every change. A settings value of
+// {"$secret": "name"} refers to a secret in the secret store, which the
+// plugin receives when it is next loaded.
+func settingsRoutes(router chi.Router, hostDB *db.HostDB, registry *plugin.Registry, loader *plugin.Loader, store *secrets.Store, undoLog *undo.Log) {
+	undoLog.Handle(undoSettingsWipe, restoreWipedSettings(hostDB, loader))
+
+	// GET /api/plugins/{pluginID}/settings -- stored settings, with secret references
+	router.Get("/api/plugins/{pluginID}/settings", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+		if _, ok := registry.Get(pluginID); !ok {
+			writeSettingsError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+			return
+		}
+
+		settings, version, found, err := hostDB.GetPluginSettings(pluginID)
+		if err != nil {
+			writeSettingsError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get plugin settings")
+			return
+		}
+		if !found {
+			settings = []byte("{}")
+		}
+
+		references, err := plugin.SecretReferences(settings)
+		if err != nil {
+			writeSettingsError(writer, http.StatusInternalServerError, "INTERNAL", "stored plugin settings are invalid")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": pluginSettingsResponse{PluginID: pluginID, Version: version, Settings: settings, Secrets: references},
+		})
+	})
+
+	// PUT /api/plugins/{pluginID}/settings -- replace the settings (a JSON object)
+	router.Put("/api/plugins/{pluginID}/settings", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+		entry, ok := registry.Get(pluginID)
+		if !ok {
+			writeSettingsError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, maxSettingsSize))
+		if err != nil {
+			writeSettingsError(writer, http.StatusRequestEntityTooLarge, "TOO_LARGE", "settings must be at most 64 KiB")
+			return
+		}
+
+		var settings map[string]json.RawMessage
+		if err := json.Unmarshal(body, &settings); err != nil || settings == nil {
+			writeSettingsError(writer, http.StatusBadRequest, "BAD_REQUEST", "settings must be a JSON object")
+			return
+		}
+
+		references, err := plugin.SecretReferences(body)
+		if err != nil {
+			writeSettingsError(writer, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		for _, name := range references {
+			exists, err := store.Exists(name)
+			if err != nil {
+				writeSettingsError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to look up secrets")
+				return
+			}
+			if !exists {
+				writeSettingsError(writer, http.StatusBadRequest, "VALIDATION_ERROR", fmt.Sprintf("secret %s does not exist", name))
+				return
+			}
+		}
+
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, body); err != nil {
+			writeSettingsError(writer, http.StatusBadRequest, "BAD_REQUEST", "settings must be a JSON object")
+			return
+		}
+
+		version := ""
+		if entry.Manifest != nil {
+			version = entry.Manifest.Version
+		}
+		if err := hostDB.SavePluginSettings(pluginID, compacted.Bytes(), version); err != nil {
+			writeSettingsError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to save plugin settings")
+			return
+		}
+		applySettings(loader, pluginID, compacted.Bytes())
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": pluginSettingsResponse{PluginID: pluginID, Version: version, Settings: compacted.Bytes(), Secrets: references},
+		})
+	})
+
+	// DELETE /api/plugins/{pluginID}/settings -- wipe the stored settings, which
+	// can be undone for the undo window
+	router.Delete("/api/plugins/{pluginID}/settings", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+		if _, ok := registry.Get(pluginID); !ok {
+			writeSettingsError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+			return
+		}
+
+		settings, version, found, err := hostDB.GetPluginSettings(pluginID)
+		if err != nil {
+			writeSettingsError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get plugin settings")
+			return
+		}
+		if !found {
+			writeSettingsError(writer, http.StatusNotFound, "NOT_FOUND", "plugin has no stored settings")
+			return
+		}
+
+		action, err := undoLog.Record(undoSettingsWipe, pluginID, wipedSettings{Settings: settings, Version: version})
+		if err != nil {
+			writeSettingsError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to wipe plugin settings")
+			return
+		}
+		if err := hostDB.DeletePluginSettings(pluginID); err != nil {
+			undoLog.Discard(action.ID)
+			writeSettingsError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to wipe plugin settings")
+			return
+		}
+		applySettings(loader, pluginID, []byte("{}"))
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"plugin_id": pluginID, "status": "wiped", "undo": action},
+		})
+	})
+}
+
+// applySettings hands saved settings to a running plugin. A plugin that fails
+// to apply them keeps its previous settings until it is reloaded.
+func applySettings(loader *plugin.Loader, pluginID string, settings []byte) {
+	if err := loader.NotifySettingsChanged(pluginID, settings); err != nil {
+		slog.Warn("plugin failed to apply its settings", "plugin", pluginID, "error", err)
+	}
+}
+
+// writeSettingsError writes a standardized error JSON response for settings endpoints.
+func writeSettingsError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/share"
+)
+
+const (
+	snapshotFormatJSON = "json"
+	snapshotFormatPNG  = "png"
+	// snapshotSecretName is the host secret that signs snapshot links.
+	snapshotSecretName = "widget-snapshots"
+	// defaultSnapshotLifetime and maxSnapshotLifetime bound expires_in.
+	defaultSnapshotLifetime = 7 * 24 * time.Hour
+	maxSnapshotLifetime     = 30 * 24 * time.Hour
+)
+
+// defaultSnapshotColor draws charts of plugins without a manifest color.
+var defaultSnapshotColor = color.RGBA{0x63, 0x66, 0xF1, 0xFF}
+
+// snapshotResponse is a snapshot with the public link to it.
+type snapshotResponse struct {
+	db.WidgetSnapshot
+	URL string `json:"url"`
+}
+
+// snapshotRoutes registers the endpoints that share frozen widget data at
+// signed, expiring links, and the public endpoint serving those links.
+func snapshotRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader, hostDB *db.HostDB) {
+	// POST /api/widgets/{pluginID}/{slot}/snapshot -- freeze the widget's current data behind a public link
+	router.Post("/api/widgets/{pluginID}/{slot}/snapshot", func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Format    string `json:"format"`
+			ExpiresIn int64  `json:"expires_in"`
+			Field     string `json:"field"`
+			Chart     string `json:"chart"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			writeSnapshotError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+
+		if body.Format == "" {
+			body.Format = snapshotFormatJSON
+		}
+		if body.Format != snapshotFormatJSON && body.Format != snapshotFormatPNG {
+			writeSnapshotError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "format must be json or png")
+			return
+		}
+		lifetime := defaultSnapshotLifetime
+		if body.ExpiresIn != 0 {
+			lifetime = time.Duration(body.ExpiresIn) * time.Second
+			if body.ExpiresIn < 0 || lifetime > maxSnapshotLifetime {
+				writeSnapshotError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "expires_in must be between 1 second and 30 days")
+				return
+			}
+		}
+
+		pluginID := chi.URLParam(request, "pluginID")
+		slot := chi.URLParam(request, "slot")
+		target := registry.RouteTarget(pluginID, requestAPIKeyID(request))
+
+		entry, err := loader.Acquire(target)
+		if err != nil {
+			writeAcquireError(writer, err)
+			return
+		}
+
+		widgetData, err := entry.Plugin.GetWidgetData(slot)
+		if crashed := loader.Release(target, entry, err); crashed {
+			writeSnapshotError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin stopped while handling the request")
+			return
+		}
+		if err != nil {
+			writeSnapshotError(writer, http.StatusInternalServerError, "PLUGIN_ERROR", "failed to get widget data")
+			return
+		}
+		if !json.Valid(widgetData) {
+			writeSnapshotError(writer, http.StatusBadGateway, "PLUGIN_ERROR", "plugin returned invalid widget data")
+			return
+		}
+
+		// Image snapshots keep only the charted series, not the widget data.
+		data := string(widgetData)
+		if body.Format == snapshotFormatPNG {
+			chart, err := share.ExtractChart(widgetData, body.Field, body.Chart)
+			if err != nil {
+				writeSnapshotError(writer, http.StatusUnprocessableEntity, "VALIDATION_ERROR", err.Error())
+				return
+			}
+			encoded, err := json.Marshal(chart)
+			if err != nil {
+				writeSnapshotError(writer, http.StatusInternalServerError, "INTERNAL", "failed to encode chart")
+				return
+			}
+			data = string(encoded)
+		}
+
+		id, err := generateToken("")
+		if err != nil {
+			writeSnapshotError(writer, http.StatusInternalServerError, "INTERNAL", "failed to generate snapshot ID")
+			return
+		}
+
+		now := time.Now().UTC()
+		snapshot := db.WidgetSnapshot{
+			ID:        id,
+			PluginID:  pluginID,
+			Slot:      slot,
+			Format:    body.Format,
+			Data:      data,
+			CreatedAt: now.Format(time.RFC3339),
+			ExpiresAt: now.Add(lifetime).Format(time.RFC3339),
+		}
+		if err := hostDB.CreateWidgetSnapshot(snapshot); err != nil {
+			slog.Error("failed to store widget snapshot", "plugin", pluginID, "error", err)
+			writeSnapshotError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to store snapshot")
+			return
+		}
+
+		response, err := newSnapshotResponse(request, hostDB, snapshot)
+		if err != nil {
+			slog.Error("failed to sign widget snapshot", "error", err)
+			writeSnapshotError(writer, http.StatusInternalServerError, "INTERNAL", "failed to sign snapshot link")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": response})
+	})
+
+	// GET /api/widgets/snapshots -- list the shared snapshots that have not expired
+	router.Get("/api/widgets/snapshots", func(writer http.ResponseWriter, request *http.Request) {
+		snapshots, err := hostDB.ListWidgetSnapshots()
+		if err != nil {
+			writeSnapshotError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list snapshots")
+			return
+		}
+
+		responses := make([]snapshotResponse, 0, len(snapshots))
+		for _, snapshot := range snapshots {
+			response, err := newSnapshotResponse(request, hostDB, snapshot)
+			if err != nil {
+				writeSnapshotError(writer, http.StatusInternalServerError, "INTERNAL", "failed to sign snapshot links")
+				return
+			}
+			responses = append(responses, response)
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": responses})
+	})
+
+	// DELETE /api/widgets/snapshots/{snapshotID} -- revoke a shared snapshot before it expires
+	router.Delete("/api/widgets/snapshots/{snapshotID}", func(writer http.ResponseWriter, request *http.Request) {
+		snapshotID := chi.URLParam(request, "snapshotID")
+		if err := hostDB.DeleteWidgetSnapshot(snapshotID); err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				writeSnapshotError(writer, http.StatusNotFound, "NOT_FOUND", "snapshot not found")
+				return
+			}
+			writeSnapshotError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to delete snapshot")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": snapshotID, "status": "deleted"},
+		})
+	})
+
+	// GET /share/widgets/{snapshotID} -- public: serve a snapshot to anyone holding a valid link
+	router.Get("/share/widgets/{snapshotID}", func(writer http.ResponseWriter, request *http.Request) {
+		snapshotID := chi.URLParam(request, "snapshotID")
+		query := request.URL.Query()
+
+		expiresUnix, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+		if err != nil {
+			writeSnapshotError(writer, http.StatusForbidden, "INVALID_LINK", "link is invalid")
+			return
+		}
+		expires := time.Unix(expiresUnix, 0)
+
+		secret, err := hostDB.HostSecret(snapshotSecretName, share.SecretSize)
+		if err != nil {
+			writeSnapshotError(writer, http.StatusInternalServerError, "INTERNAL", "failed to verify link")
+			return
+		}
+		if !share.Verify(secret, snapshotID, expires, query.Get("signature")) {
+			writeSnapshotError(writer, http.StatusForbidden, "INVALID_LINK", "link is invalid")
+			return
+		}
+		if !time.Now().Before(expires) {
+			writeSnapshotError(writer, http.StatusGone, "EXPIRED", "link has expired")
+			return
+		}
+
+		snapshot, err := hostDB.GetWidgetSnapshot(snapshotID)
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				writeSnapshotError(writer, http.StatusNotFound, "NOT_FOUND", "snapshot not found")
+				return
+			}
+			writeSnapshotError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get snapshot")
+			return
+		}
+
+		// Revoking deletes the snapshot, so responses must not be cached.
+		writer.Header().Set("Cache-Control", "no-store")
+		writer.Header().Set("X-Robots-Tag", "noindex")
+
+		if snapshot.Format == snapshotFormatPNG {
+			var chart share.Chart
+			if err := json.Unmarshal([]byte(snapshot.Data), &chart); err != nil {
+				writeSnapshotError(writer, http.StatusInternalServerError, "INTERNAL", "failed to read snapshot")
+				return
+			}
+			accent := defaultSnapshotColor
+			if entry, ok := registry.Get(snapshot.PluginID); ok {
+				accent = share.ParseColor(entry.Manifest.Color, defaultSnapshotColor)
+			}
+			image, err := chart.PNG(accent)
+			if err != nil {
+				writeSnapshotError(writer, http.StatusInternalServerError, "INTERNAL", "failed to render snapshot")
+				return
+			}
+			writer.Header().Set("Content-Type", "image/png")
+			_, _ = writer.Write(image)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": json.RawMessage(snapshot.Data),
+			"meta": map[string]interface{}{
+				"plugin_id":  snapshot.PluginID,
+				"slot":       snapshot.Slot,
+				"created_at": snapshot.CreatedAt,
+				"expires_at": snapshot.ExpiresAt,
+			},
+		})
+	})
+}
+
+// newSnapshotResponse signs the public link to snapshot. Links are absolute,
+// built from the host and base path the request was made to.
+func newSnapshotResponse(request *http.Request, hostDB *db.HostDB, snapshot db.WidgetSnapshot) (snapshotResponse, error) {
+	secret, err := hostDB.HostSecret(snapshotSecretName, share.SecretSize)
+	if err != nil {
+		return snapshotResponse{}, err
+	}
+	expires, err := time.Parse(time.RFC3339, snapshot.ExpiresAt)
+	if err != nil {
+		return snapshotResponse{}, fmt.Errorf("parsing snapshot expiry: %w", err)
+	}
+
+	scheme := "http"
+	if request.TLS != nil || request.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	link := url.URL{
+		Scheme: scheme,
+		Host:   request.Host,
+		Path:   basePath(request.Context()) + "/share/widgets/" + snapshot.ID,
+		RawQuery: url.Values{
+			"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+			"signature": {share.Sign(secret, snapshot.ID, expires)},
+		}.Encode(),
+	}
+	return snapshotResponse{WidgetSnapshot: snapshot, URL: link.String()}, nil
+}
+
+// writeSnapshotError writes a standardized error JSON response for snapshot endpoints.
+func writeSnapshotError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// widgetStubPlugin serves fixed widget data.
+type widgetStubPlugin struct {
+	stubPlugin
+}
+
+func (p *widgetStubPlugin) GetWidgetData(slot string) ([]byte, error) {
+	return []byte(`{"data": {"income": 2400, "expense": 1800, "sparkline": [{"month": "2026-02", "balance": 300}]}}`), nil
+}
+
+func newSnapshotRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	hostDB, err := db.NewHostDB(tempDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	registry := plugin.NewRegistry()
+	registry.Register("finance-tracker", nil, &plugin.Manifest{ID: "finance-tracker", Name: "Finance", Version: "1.0.0", Color: "#10B981"})
+	entry, _ := registry.Get("finance-tracker")
+	entry.Plugin = &widgetStubPlugin{}
+
+	router := chi.NewRouter()
+	snapshotRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry), hostDB)
+	return router
+}
+
+func createSnapshot(t *testing.T, router *chi.Mux, body string) snapshotResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/widgets/finance-tracker/dashboard-widget/snapshot", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Data snapshotResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	return response.Data
+}
+
+func getShared(t *testing.T, router *chi.Mux, link string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid link %q: %v", link, err)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, parsed.RequestURI(), nil))
+	return rec
+}
+
+func TestWidgetSnapshot_JSON(t *testing.T) {
+	router := newSnapshotRouter(t)
+	snapshot := createSnapshot(t, router, `{"expires_in": 3600}`)
+
+	if snapshot.Format != "json" || !strings.HasPrefix(snapshot.URL, "http://example.com/share/widgets/"+snapshot.ID+"?") {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	rec := getShared(t, router, snapshot.URL)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"income":2400`) || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("expected the frozen widget data, got %s", rec.Body.String())
+	}
+
+	tampered := strings.Replace(snapshot.URL, "expires=", "expires=1", 1)
+	if rec := getShared(t, router, tampered); rec.Code != http.StatusForbidden {
+		t.Errorf("expected a tampered link to be rejected, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/widgets/snapshots/"+snapshot.ID, nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if rec := getShared(t, router, snapshot.URL); rec.Code != http.StatusNotFound {
+		t.Errorf("expected a revoked link to be gone, got %d", rec.Code)
+	}
+}
+
+func TestWidgetSnapshot_PNG(t *testing.T) {
+	router := newSnapshotRouter(t)
+	snapshot := createSnapshot(t, router, `{"format": "png", "field": "sparkline"}`)
+
+	rec := getShared(t, router, snapshot.URL)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a PNG, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if _, err := png.Decode(bytes.NewReader(rec.Body.Bytes())); err != nil {
+		t.Errorf("invalid PNG: %v", err)
+	}
+
+	list := httptest.NewRecorder()
+	router.ServeHTTP(list, httptest.NewRequest(http.MethodGet, "/api/widgets/snapshots", nil))
+	if !strings.Contains(list.Body.String(), snapshot.ID) || strings.Contains(list.Body.String(), "income") {
+		t.Errorf("expected the listing to include the snapshot without its data, got %s", list.Body.String())
+	}
+}
+
+func TestWidgetSnapshot_Validation(t *testing.T) {
+	router := newSnapshotRouter(t)
+
+	for _, body := range []string{`{"format": "gif"}`, `{"expires_in": -1}`, `{"expires_in": 99999999}`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/widgets/finance-tracker/dashboard-widget/snapshot", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/widgets/finance-tracker/dashboard-widget/snapshot",
+		strings.NewReader(`{"format": "png", "field": "income.missing"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422 for a field that cannot be charted, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	// defaultSyncLimit is how many records GET /api/plugins/{id}/sync asks
+	// the plugin for.
+	defaultSyncLimit = 200
+	// maxSyncLimit caps the ?limit= query parameter.
+	maxSyncLimit = 1000
+	// maxSyncChanges caps how many changes one push may carry.
+	maxSyncChanges = 500
+)
+
+// pluginSyncRoutes registers the offline sync endpoint of plugins implementing
+// Syncer. Every plugin syncs through the same two requests: a pull of the
+// records changed after a cursor and a push of the changes made offline.
+func pluginSyncRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader) {
+	// Records changed after ?cursor=, tombstones included
+	router.Get("/api/plugins/{pluginID}/sync", func(writer http.ResponseWriter, request *http.Request) {
+		limit := defaultSyncLimit
+		if raw := request.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 || parsed > maxSyncLimit {
+				writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "limit must be a number between 1 and "+strconv.Itoa(maxSyncLimit))
+				return
+			}
+			limit = parsed
+		}
+		cursor := request.URL.Query().Get("cursor")
+
+		var page *plugin.SyncPage
+		withSyncer(writer, request, registry, loader, func(syncer plugin.Syncer) error {
+			var err error
+			page, err = syncer.SyncPull(cursor, limit)
+			return err
+		}, func() {
+			if page.Records == nil {
+				page.Records = []plugin.SyncRecord{}
+			}
+			_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": page})
+		})
+	})
+
+	// Changes made offline, applied in order
+	router.Post("/api/plugins/{pluginID}/sync", func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Changes []plugin.SyncChange `json:"changes"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, plugin.MaxAPIBodySize)).Decode(&body); err != nil {
+			writePluginError(writer, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON body")
+			return
+		}
+		if len(body.Changes) == 0 || len(body.Changes) > maxSyncChanges {
+			writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "changes must hold between 1 and "+strconv.Itoa(maxSyncChanges)+" items")
+			return
+		}
+		for i, change := range body.Changes {
+			if change.Collection == "" {
+				writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "changes["+strconv.Itoa(i)+"].collection is required")
+				return
+			}
+			if change.ID == "" && change.BaseVersion != 0 {
+				writePluginError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "changes["+strconv.Itoa(i)+"].id is required unless base_version is 0")
+				return
+			}
+		}
+
+		var results []plugin.SyncResult
+		withSyncer(writer, request, registry, loader, func(syncer plugin.Syncer) error {
+			var err error
+			results, err = syncer.SyncPush(body.Changes)
+			return err
+		}, func() {
+			if results == nil {
+				results = []plugin.SyncResult{}
+			}
+			_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": map[string]interface{}{"results": results}})
+		})
+	})
+}
+
+// withSyncer runs call on the plugin serving the request, through its canary
+// when the rollout selects this request, and calls write once it succeeded.
+// The plugin must declare the permission the request method needs, as for
+// requests proxied to HandleAPI.
+func withSyncer(writer http.ResponseWriter, request *http.Request, registry *plugin.Registry, loader *plugin.Loader, call func(plugin.Syncer) error, write func()) {
+	pluginID := chi.URLParam(request, "pluginID")
+	target := registry.RouteTarget(pluginID, requestAPIKeyID(request))
+	entry, ok := registry.Get(target)
+	if !ok {
+		writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+		return
+	}
+
+	if required := plugin.RequiredPermissionForMethod(request.Method); required != "" {
+		if err := plugin.RequirePermission(entry.Manifest, required); err != nil {
+			writePluginError(writer, http.StatusForbidden, "PERMISSION_DENIED", "plugin has not declared the "+required+" permission")
+			return
+		}
+	}
+
+	entry, err := loader.Acquire(target)
+	if err != nil {
+		writeAcquireError(writer, err)
+		return
+	}
+
+	syncer, ok := entry.Plugin.(plugin.Syncer)
+	if !ok {
+		loader.Release(target, entry, nil)
+		writePluginError(writer, http.StatusNotImplemented, "NOT_IMPLEMENTED", "plugin does not support sync")
+		return
+	}
+
+	err = call(syncer)
+	if errors.Is(err, plugin.ErrNotImplemented) {
+		// Lacking an optional hook is not a plugin failure.
+		loader.Release(target, entry, nil)
+		writePluginError(writer, http.StatusNotImplemented, "NOT_IMPLEMENTED", "plugin does not support sync")
+		return
+	}
+	if crashed := loader.Release(target, entry, err); crashed {
+		writePluginError(writer, http.StatusServiceUnavailable, "PLUGIN_UNAVAILABLE", "plugin stopped while handling the request")
+		return
+	}
+	if err != nil {
+		writePluginError(writer, http.StatusInternalServerError, "PLUGIN_ERROR", "plugin sync failed")
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if target != pluginID {
+		writer.Header().Set(canaryHeader, "true")
+	}
+	write()
+}
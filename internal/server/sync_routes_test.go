@@ -0,0 +1,189 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// syncStubPlugin serves a fixed sync page and records what it is asked.
+type syncStubPlugin struct {
+	stubPlugin
+	page    plugin.SyncPage
+	cursor  string
+	limit   int
+	changes []plugin.SyncChange
+}
+
+func (p *syncStubPlugin) SyncPull(cursor string, limit int) (*plugin.SyncPage, error) {
+	p.cursor, p.limit = cursor, limit
+	return &p.page, nil
+}
+
+func (p *syncStubPlugin) SyncPush(changes []plugin.SyncChange) ([]plugin.SyncResult, error) {
+	p.changes = changes
+	results := make([]plugin.SyncResult, 0, len(changes))
+	for _, change := range changes {
+		if change.BaseVersion == 1 {
+			results = append(results, plugin.SyncResult{
+				Collection: change.Collection, ID: change.ID, Status: plugin.SyncConflict, Version: 2,
+				Current: &plugin.SyncRecord{Collection: change.Collection, ID: change.ID, Version: 2, Data: json.RawMessage(`{"title":"theirs"}`)},
+			})
+			continue
+		}
+		results = append(results, plugin.SyncResult{
+			Collection: change.Collection, ID: "7", ClientID: change.ClientID, Status: plugin.SyncApplied, Version: 1,
+		})
+	}
+	return results, nil
+}
+
+func newSyncRouter(t *testing.T, registry *plugin.Registry) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	router := chi.NewRouter()
+	pluginSyncRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry))
+	return router
+}
+
+func TestSync_PullsChangedRecords(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := &syncStubPlugin{page: plugin.SyncPage{
+		Records: []plugin.SyncRecord{
+			{Collection: "notes", ID: "1", Version: 3, Data: json.RawMessage(`{"title":"Groceries"}`)},
+			{Collection: "notes", ID: "2", Version: 2, Deleted: true},
+		},
+		Cursor:  "42",
+		HasMore: true,
+	}}
+	registerSearchStub(registry, "quick-notes", stub)
+
+	router := newSyncRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/quick-notes/sync?cursor=40&limit=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if stub.cursor != "40" || stub.limit != 2 {
+		t.Errorf("expected pull from cursor 40 with limit 2, got %q and %d", stub.cursor, stub.limit)
+	}
+
+	var body struct {
+		Data plugin.SyncPage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	if body.Data.Cursor != "42" || !body.Data.HasMore || len(body.Data.Records) != 2 {
+		t.Fatalf("unexpected page: %+v", body.Data)
+	}
+	if !body.Data.Records[1].Deleted || len(body.Data.Records[1].Data) != 0 {
+		t.Errorf("expected record 2 to be a tombstone, got %+v", body.Data.Records[1])
+	}
+
+	// Without a limit the host asks for the default page size.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/quick-notes/sync", nil))
+	if rec.Code != http.StatusOK || stub.cursor != "" || stub.limit != defaultSyncLimit {
+		t.Errorf("expected a first pull with limit %d, got status %d, cursor %q, limit %d", defaultSyncLimit, rec.Code, stub.cursor, stub.limit)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/quick-notes/sync?limit=5000", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an oversized limit, got %d", rec.Code)
+	}
+}
+
+func TestSync_PushesChangesAndReportsConflicts(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := &syncStubPlugin{}
+	registry.Register("quick-notes", nil, &plugin.Manifest{ID: "quick-notes", Name: "quick-notes", Version: "1.0.0", Permissions: []string{plugin.PermissionDBWrite}})
+	entry, _ := registry.Get("quick-notes")
+	entry.Plugin = stub
+
+	router := newSyncRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/sync", strings.NewReader(`{"changes":[
+		{"collection":"notes","client_id":"tmp-1","base_version":0,"data":{"title":"New"}},
+		{"collection":"notes","id":"3","base_version":1,"data":{"title":"mine"}}
+	]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(stub.changes) != 2 || stub.changes[0].ClientID != "tmp-1" || string(stub.changes[1].Data) != `{"title":"mine"}` {
+		t.Fatalf("unexpected changes passed to the plugin: %+v", stub.changes)
+	}
+
+	var body struct {
+		Data struct {
+			Results []plugin.SyncResult `json:"results"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	if len(body.Data.Results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", body.Data.Results)
+	}
+	if created := body.Data.Results[0]; created.Status != plugin.SyncApplied || created.ID != "7" || created.ClientID != "tmp-1" {
+		t.Errorf("expected the new note to be applied as 7, got %+v", created)
+	}
+	if conflict := body.Data.Results[1]; conflict.Status != plugin.SyncConflict || conflict.Current == nil || conflict.Current.Version != 2 {
+		t.Errorf("expected a conflict with the current record, got %+v", conflict)
+	}
+
+	for name, payload := range map[string]string{
+		"no changes":         `{"changes":[]}`,
+		"missing collection": `{"changes":[{"id":"3","base_version":1}]}`,
+		"update without id":  `{"changes":[{"collection":"notes","base_version":2}]}`,
+		"invalid json":       `{"changes":`,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/sync", strings.NewReader(payload)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", name, rec.Code)
+		}
+	}
+}
+
+func TestSync_RequiresWritePermissionToPush(t *testing.T) {
+	registry := plugin.NewRegistry()
+	stub := &syncStubPlugin{}
+	registerSearchStub(registry, "quick-notes", stub)
+
+	router := newSyncRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/quick-notes/sync", strings.NewReader(`{"changes":[{"collection":"notes"}]}`)))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if stub.changes != nil {
+		t.Error("expected the push not to reach the plugin")
+	}
+}
+
+func TestSync_PluginWithoutSyncer(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registerSearchStub(registry, "project-hub", &stubPlugin{})
+
+	router := newSyncRouter(t, registry)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/project-hub/sync", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("expected status 501, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins/missing/sync", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown plugin, got %d", rec.Code)
+	}
+}
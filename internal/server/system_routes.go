@@ -0,0 +1,216 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/config"
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// hostStartedAt is when the host process started, for its uptime.
+var hostStartedAt = time.Now()
+
+// SystemStatus is the health overview returned by GET /api/system.
+type SystemStatus struct {
+	Version       string         `json:"version"`
+	StartedAt     time.Time      `json:"started_at"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	Disk          DiskUsage      `json:"disk"`
+	Plugins       []PluginStatus `json:"plugins"`
+	Runtime       RuntimeStats   `json:"runtime"`
+}
+
+// DiskUsage is the space the data directory takes and, where the platform
+// reports it, the size and free space of the filesystem holding it.
+type DiskUsage struct {
+	DataDir    string  `json:"data_dir"`
+	UsedBytes  int64   `json:"used_bytes"`
+	TotalBytes *uint64 `json:"total_bytes,omitempty"`
+	FreeBytes  *uint64 `json:"free_bytes,omitempty"`
+}
+
+// PluginStatus is a loaded plugin's process and the size of its database,
+// which is left out for plugins without one.
+type PluginStatus struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	plugin.PluginProcess
+	DatabaseSize *int64 `json:"database_size,omitempty"`
+}
+
+// RuntimeStats are the host process's Go runtime figures.
+type RuntimeStats struct {
+	GoVersion     string     `json:"go_version"`
+	OS            string     `json:"os"`
+	Arch          string     `json:"arch"`
+	CPUs          int        `json:"cpus"`
+	Goroutines    int        `json:"goroutines"`
+	HeapAlloc     uint64     `json:"heap_alloc_bytes"`
+	HeapSys       uint64     `json:"heap_sys_bytes"`
+	Sys           uint64     `json:"sys_bytes"`
+	GCCycles      uint32     `json:"gc_cycles"`
+	LastGCAt      *time.Time `json:"last_gc_at,omitempty"`
+	GCPauseTotalS float64    `json:"gc_pause_total_seconds"`
+}
+
+// systemRoutes registers host-level system introspection endpoints.
+func systemRoutes(router chi.Router, dataDir string, hostDB *db.HostDB, registry *plugin.Registry, loader *plugin.Loader) {
+	// GET /api/system -- host version and uptime, disk usage, plugin processes and databases, Go runtime
+	router.Get("/api/system", func(writer http.ResponseWriter, request *http.Request) {
+		status, err := systemStatus(dataDir, registry, loader, time.Now())
+		if err != nil {
+			slog.Error("reading system status", "error", err)
+			writeSystemError(writer, http.StatusInternalServerError, "INTERNAL", "failed to read system status")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": status})
+	})
+
+	// GET /api/system/history -- host runs (start, shutdown kind, uptime) and plugin restart counts and load times
+	router.Get("/api/system/history", func(writer http.ResponseWriter, request *http.Request) {
+		limit := defaultHistoryLimit
+		if rawLimit := request.URL.Query().Get("limit"); rawLimit != "" {
+			parsed, err := strconv.Atoi(rawLimit)
+			if err != nil || parsed < 1 || parsed > maxHistoryLimit {
+				writeSystemError(writer, http.StatusBadRequest, "VALIDATION_ERROR", "limit must be between 1 and 100")
+				return
+			}
+			limit = parsed
+		}
+
+		runs, err := hostDB.ListHostRuns(limit)
+		if err != nil {
+			writeSystemError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to get system history")
+			return
+		}
+
+		crashes := 0
+		for _, run := range runs {
+			if run.Shutdown == db.ShutdownCrash {
+				crashes++
+			}
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": runs,
+			"meta": map[string]interface{}{
+				"current_run_id": hostDB.CurrentRunID(),
+				"crashes":        crashes,
+			},
+		})
+	})
+}
+
+// systemStatus gathers the figures of GET /api/system at now.
+func systemStatus(dataDir string, registry *plugin.Registry, loader *plugin.Loader, now time.Time) (*SystemStatus, error) {
+	status := &SystemStatus{
+		Version:       config.Version,
+		StartedAt:     hostStartedAt.UTC(),
+		UptimeSeconds: int64(now.Sub(hostStartedAt).Seconds()),
+		Plugins:       []PluginStatus{},
+	}
+
+	absolute, err := filepath.Abs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	used, err := directorySize(absolute)
+	if err != nil {
+		return nil, err
+	}
+	status.Disk = DiskUsage{DataDir: absolute, UsedBytes: used}
+	if total, free, ok := filesystemSpace(absolute); ok {
+		status.Disk.TotalBytes, status.Disk.FreeBytes = &total, &free
+	}
+
+	for _, manifest := range registry.List() {
+		process, err := loader.Process(manifest.ID)
+		if err != nil {
+			// Unloaded since it was listed
+			continue
+		}
+		entry := PluginStatus{ID: manifest.ID, Version: manifest.Version, PluginProcess: process}
+		if size, err := loader.DatabaseSize(manifest.ID); err == nil {
+			entry.DatabaseSize = &size
+		}
+		status.Plugins = append(status.Plugins, entry)
+	}
+
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	status.Runtime = RuntimeStats{
+		GoVersion:     runtime.Version(),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		CPUs:          runtime.NumCPU(),
+		Goroutines:    runtime.NumGoroutine(),
+		HeapAlloc:     memory.HeapAlloc,
+		HeapSys:       memory.HeapSys,
+		Sys:           memory.Sys,
+		GCCycles:      memory.NumGC,
+		GCPauseTotalS: time.Duration(memory.PauseTotalNs).Seconds(),
+	}
+	if memory.LastGC != 0 {
+		lastGC := time.Unix(0, int64(memory.LastGC)).UTC()
+		status.Runtime.LastGCAt = &lastGC
+	}
+	return status, nil
+}
+
+// directorySize adds up the sizes of the regular files under root. Files
+// removed while it walks are skipped.
+func directorySize(root string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// writeSystemError writes a standardized error JSON response for system endpoints.
+func writeSystemError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
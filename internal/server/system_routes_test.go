@@ -0,0 +1,202 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+func TestSystemHistory_CrashAndRestarts(t *testing.T) {
+	dataDir := t.TempDir()
+
+	// First run: finance-tracker is loaded, then reloaded once, then the host dies.
+	firstDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	if _, err := firstDB.StartRun(); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	_ = firstDB.RecordPluginLoad("finance-tracker", 900*time.Millisecond, 0)
+	_ = firstDB.RecordPluginLoad("finance-tracker", 400*time.Millisecond, 150*time.Millisecond)
+	_ = firstDB.RecordPluginLoad("quick-notes", 200*time.Millisecond, 0)
+	firstDB.Close()
+
+	// Second run starts without the first having called EndRun.
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to reopen host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+	if _, err := hostDB.StartRun(); err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+
+	router := chi.NewRouter()
+	registry := plugin.NewRegistry()
+	systemRoutes(router, dataDir, hostDB, registry, plugin.NewLoader(dataDir, dataDir, registry))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system/history", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data []db.HostRun `json:"data"`
+		Meta struct {
+			CurrentRunID int64 `json:"current_run_id"`
+			Crashes      int   `json:"crashes"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if len(response.Data) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(response.Data))
+	}
+
+	current, previous := response.Data[0], response.Data[1]
+	if current.Shutdown != db.ShutdownRunning || current.ID != response.Meta.CurrentRunID {
+		t.Errorf("expected newest run to be the running one, got %+v", current)
+	}
+	if previous.Shutdown != db.ShutdownCrash || previous.StoppedAt == nil {
+		t.Errorf("expected previous run to be a crash with a stop time, got %+v", previous)
+	}
+	if previous.PluginRestarts["finance-tracker"] != 1 {
+		t.Errorf("expected 1 finance-tracker restart, got %v", previous.PluginRestarts)
+	}
+	if _, ok := previous.PluginRestarts["quick-notes"]; ok {
+		t.Errorf("a single load is not a restart, got %v", previous.PluginRestarts)
+	}
+	if loadTime := previous.PluginLoadTimes["finance-tracker"]; loadTime.LoadMS != 400 || loadTime.WarmupMS != 150 {
+		t.Errorf("expected the latest finance-tracker load times, got %+v", previous.PluginLoadTimes)
+	}
+	if response.Meta.Crashes != 1 {
+		t.Errorf("expected 1 crash, got %d", response.Meta.Crashes)
+	}
+}
+
+func TestSystemHistory_CleanShutdown(t *testing.T) {
+	dataDir := t.TempDir()
+
+	firstDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	_, _ = firstDB.StartRun()
+	if err := firstDB.EndRun(); err != nil {
+		t.Fatalf("EndRun failed: %v", err)
+	}
+	firstDB.Close()
+
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to reopen host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+	_, _ = hostDB.StartRun()
+
+	runs, err := hostDB.ListHostRuns(10)
+	if err != nil {
+		t.Fatalf("ListHostRuns failed: %v", err)
+	}
+	if len(runs) != 2 || runs[1].Shutdown != db.ShutdownClean {
+		t.Errorf("expected previous run to be clean, got %+v", runs)
+	}
+}
+
+func TestSystemHistory_InvalidLimit(t *testing.T) {
+	dataDir := t.TempDir()
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	router := chi.NewRouter()
+	registry := plugin.NewRegistry()
+	systemRoutes(router, dataDir, hostDB, registry, plugin.NewLoader(dataDir, dataDir, registry))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system/history?limit=0", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestSystemStatus_ReportsHostAndPlugins(t *testing.T) {
+	dataDir := t.TempDir()
+	hostDB, err := db.NewHostDB(dataDir)
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+
+	registry := plugin.NewRegistry()
+	registry.Register("quick-notes", nil, &plugin.Manifest{ID: "quick-notes", Version: "1.2.0", Permissions: []string{plugin.PermissionDBRead}})
+	registry.Register("clock", nil, &plugin.Manifest{ID: "clock", Version: "0.1.0"})
+	pluginDir := filepath.Join(dataDir, "plugins", "quick-notes")
+	if err := os.MkdirAll(pluginDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "db.sqlite"), make([]byte, 4096), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	router := chi.NewRouter()
+	systemRoutes(router, dataDir, hostDB, registry, plugin.NewLoader(dataDir, dataDir, registry))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/system", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Data SystemStatus `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	status := body.Data
+	if status.Version == "" || status.StartedAt.IsZero() || status.UptimeSeconds < 0 {
+		t.Errorf("expected the host version and uptime, got %+v", status)
+	}
+	if status.Disk.UsedBytes < 4096 {
+		t.Errorf("expected the data directory to hold at least the plugin database, got %d bytes", status.Disk.UsedBytes)
+	}
+	if status.Runtime.Goroutines == 0 || status.Runtime.GoVersion == "" || status.Runtime.HeapAlloc == 0 {
+		t.Errorf("expected Go runtime figures, got %+v", status.Runtime)
+	}
+
+	if len(status.Plugins) != 2 {
+		t.Fatalf("expected both plugins, got %+v", status.Plugins)
+	}
+	for _, entry := range status.Plugins {
+		switch entry.ID {
+		case "quick-notes":
+			if entry.DatabaseSize == nil || *entry.DatabaseSize != 4096 || entry.Version != "1.2.0" {
+				t.Errorf("expected quick-notes with its 4096-byte database, got %+v", entry)
+			}
+		case "clock":
+			if entry.DatabaseSize != nil {
+				t.Errorf("expected no database size for a plugin without one, got %d", *entry.DatabaseSize)
+			}
+		}
+	}
+}
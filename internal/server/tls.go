@@ -0,0 +1,181 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// selfSignedValidity is how long generated certificates are valid for.
+	selfSignedValidity = 825 * 24 * time.Hour
+	// selfSignedRenewal is how close to expiry a generated certificate is
+	// replaced at startup.
+	selfSignedRenewal = 30 * 24 * time.Hour
+	// selfSignedOrganization marks the certificates Cortex generated, which
+	// are the only ones it replaces.
+	selfSignedOrganization = "Cortex self-signed"
+)
+
+// loadTLSConfig returns the TLS configuration of the server, first
+// generating its self-signed certificate when asked to.
+func loadTLSConfig(certFile string, keyFile string, selfSigned bool, hosts []string) (*tls.Config, error) {
+	if selfSigned {
+		generated, err := ensureSelfSignedCertificate(certFile, keyFile, selfSignedHosts(hosts), time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("preparing self-signed certificate: %w", err)
+		}
+		if generated {
+			slog.Info("generated self-signed certificate", "cert", certFile, "key", keyFile)
+		}
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	slog.Info("serving HTTPS", "cert", certFile, "fingerprint", certificateFingerprint(certificate))
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+	}, nil
+}
+
+// ensureSelfSignedCertificate writes a new self-signed certificate for hosts
+// and its key unless both files already hold a certificate that is not about
+// to expire. A generated certificate close to expiry is replaced; other
+// certificates, such as one issued by a CA, are never overwritten. It reports
+// whether a certificate was generated.
+func ensureSelfSignedCertificate(certFile string, keyFile string, hosts []string, now time.Time) (bool, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	switch {
+	case errors.Is(certErr, os.ErrNotExist) && errors.Is(keyErr, os.ErrNotExist):
+		return true, writeSelfSignedCertificate(certFile, keyFile, hosts, now)
+	case certErr != nil || keyErr != nil:
+		return false, fmt.Errorf("certificate and key must both exist or both be missing: %w", errors.Join(certErr, keyErr))
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return false, err
+	}
+	leaf := certificate.Leaf
+	if now.Add(selfSignedRenewal).Before(leaf.NotAfter) {
+		return false, nil
+	}
+	if len(leaf.Subject.Organization) == 0 || leaf.Subject.Organization[0] != selfSignedOrganization {
+		return false, fmt.Errorf("certificate %s expires %s and was not generated by Cortex; replace it", certFile, leaf.NotAfter.Format(time.DateOnly))
+	}
+	return true, writeSelfSignedCertificate(certFile, keyFile, hosts, now)
+}
+
+// writeSelfSignedCertificate generates an ECDSA key and a certificate for
+// hosts signed with it. The key is only readable by the owner.
+func writeSelfSignedCertificate(certFile string, keyFile string, hosts []string, now time.Time) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("generating serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Cortex", Organization: []string{selfSignedOrganization}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("creating certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("encoding key: %w", err)
+	}
+
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+			return fmt.Errorf("creating certificate directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return fmt.Errorf("writing key: %w", err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644); err != nil {
+		return fmt.Errorf("writing certificate: %w", err)
+	}
+	return nil
+}
+
+// selfSignedHosts returns the names a generated certificate covers:
+// localhost, the machine's hostname, the IPs of its network interfaces, and
+// extra.
+func selfSignedHosts(extra []string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		hosts = append(hosts, hostname)
+		if !strings.Contains(hostname, ".") {
+			hosts = append(hosts, hostname+".local")
+		}
+	}
+	if addresses, err := net.InterfaceAddrs(); err == nil {
+		for _, address := range addresses {
+			if network, ok := address.(*net.IPNet); ok && !network.IP.IsLoopback() && !network.IP.IsLinkLocalUnicast() {
+				hosts = append(hosts, network.IP.String())
+			}
+		}
+	}
+	hosts = append(hosts, extra...)
+
+	seen := make(map[string]bool, len(hosts))
+	unique := hosts[:0]
+	for _, host := range hosts {
+		if !seen[host] {
+			seen[host] = true
+			unique = append(unique, host)
+		}
+	}
+	return unique
+}
+
+// certificateFingerprint returns the SHA-256 fingerprint of a certificate,
+// which users can compare with the one their browser shows for a
+// self-signed certificate.
+func certificateFingerprint(certificate tls.Certificate) string {
+	if len(certificate.Certificate) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(certificate.Certificate[0])
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnsureSelfSignedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls", "cert.pem")
+	keyFile := filepath.Join(dir, "tls", "key.pem")
+	now := time.Now()
+
+	generated, err := ensureSelfSignedCertificate(certFile, keyFile, []string{"localhost", "cortex.lan", "192.168.1.20"}, now)
+	if err != nil || !generated {
+		t.Fatalf("expected a certificate to be generated, got %v, %v", generated, err)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("loading generated certificate: %v", err)
+	}
+	if err := certificate.Leaf.VerifyHostname("cortex.lan"); err != nil {
+		t.Errorf("expected the certificate to cover cortex.lan: %v", err)
+	}
+	if err := certificate.Leaf.VerifyHostname("192.168.1.20"); err != nil {
+		t.Errorf("expected the certificate to cover 192.168.1.20: %v", err)
+	}
+	if info, err := os.Stat(keyFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the key to be readable by the owner only, got %v", info.Mode().Perm())
+	}
+
+	generated, err = ensureSelfSignedCertificate(certFile, keyFile, []string{"localhost"}, now)
+	if err != nil || generated {
+		t.Errorf("expected the persisted certificate to be kept, got %v, %v", generated, err)
+	}
+
+	generated, err = ensureSelfSignedCertificate(certFile, keyFile, []string{"localhost"}, now.Add(selfSignedValidity))
+	if err != nil || !generated {
+		t.Errorf("expected an expiring certificate to be replaced, got %v, %v", generated, err)
+	}
+}
+
+func TestEnsureSelfSignedCertificate_RejectsHalfPair(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ensureSelfSignedCertificate(certFile, filepath.Join(dir, "key.pem"), []string{"localhost"}, time.Now()); err == nil {
+		t.Error("expected an error when only the certificate exists")
+	}
+	if content, _ := os.ReadFile(certFile); string(content) != "not a certificate" {
+		t.Error("expected the existing certificate to be left alone")
+	}
+}
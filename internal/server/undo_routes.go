@@ -0,0 +1,289 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/secrets"
+	"github.com/alvarotorresc/cortex/internal/undo"
+)
+
+// Kinds of actions recorded in the undo log.
+const (
+	undoPluginPurge  = "plugin_purge"
+	undoSettingsWipe = "settings_wipe"
+	undoSecretDelete = "secret_delete"
+	undoPluginDelete = "plugin_delete"
+)
+
+// Headers of a plugin's delete response that can be undone: the token for
+// POST /api/undo/{token} and the time the undo window closes.
+const (
+	undoTokenHeader   = "X-Undo-Token"
+	undoExpiresHeader = "X-Undo-Expires-At"
+)
+
+// purgedDataDirName is where a purge moves the plugin's data directory to,
+// inside the trash of its action.
+const purgedDataDirName = "data"
+
+// wipedSettings is the undo payload of a settings wipe, and of a purge for
+// plugins that had settings stored.
+type wipedSettings struct {
+	Settings json.RawMessage `json:"settings,omitempty"`
+	Version  string          `json:"version,omitempty"`
+}
+
+// deletedSecret is the undo payload of a secret deletion: its value, still
+// encrypted.
+type deletedSecret struct {
+	Sealed []byte `json:"sealed"`
+}
+
+// deletedRecords is the undo payload of a plugin delete: what the plugin
+// attached to its response, handed back to its RestoreDeleted.
+type deletedRecords struct {
+	Kind string `json:"kind"`
+	Data []byte `json:"data"`
+}
+
+// undoRoutes registers the undo log endpoints. Destructive admin calls that
+// can be reversed return the action they recorded as "undo" in their
+// response; plugin deletes return its ID in the X-Undo-Token header.
+func undoRoutes(router chi.Router, undoLog *undo.Log) {
+	// GET /api/admin/undo -- actions that can still be undone, newest first
+	router.Get("/api/admin/undo", func(writer http.ResponseWriter, request *http.Request) {
+		actions, err := undoLog.List()
+		if err != nil {
+			writeUndoError(writer, http.StatusInternalServerError, "DB_ERROR", "failed to list undo actions")
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{"data": actions})
+	})
+
+	// POST /api/admin/undo/{actionID} -- reverse an action within its window
+	router.Post("/api/admin/undo/{actionID}", undoAction(undoLog, "actionID", ""))
+
+	// POST /api/undo/{token} -- restore what a plugin delete removed, within the undo window
+	router.Post("/api/undo/{token}", undoAction(undoLog, "token", undoPluginDelete))
+}
+
+// undoAction reverses the action named by the URL parameter param, which
+// must be of kind unless kind is empty.
+func undoAction(undoLog *undo.Log, param string, kind string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		action, err := undoLog.UndoKind(chi.URLParam(request, param), kind)
+		if err != nil {
+			switch {
+			case errors.Is(err, undo.ErrNotFound):
+				writeUndoError(writer, http.StatusNotFound, "NOT_FOUND", "undo action not found")
+			case errors.Is(err, undo.ErrExpired):
+				writeUndoError(writer, http.StatusGone, "EXPIRED", err.Error())
+			case errors.Is(err, undo.ErrUndone):
+				writeUndoError(writer, http.StatusConflict, "ALREADY_UNDONE", err.Error())
+			case errors.Is(err, undo.ErrConflict):
+				writeUndoError(writer, http.StatusConflict, "CONFLICT", err.Error())
+			default:
+				slog.Error("undo failed", "action", chi.URLParam(request, param), "error", err)
+				writeUndoError(writer, http.StatusInternalServerError, "UNDO_ERROR", "failed to undo action")
+			}
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":     action.ID,
+				"kind":   action.Kind,
+				"target": action.Target,
+				"status": "undone",
+			},
+		})
+	}
+}
+
+// purgePlugin unloads a plugin and removes its settings and data, which are
+// set aside in the undo log until its window passes.
+func purgePlugin(pluginID string, loader *plugin.Loader, hostDB *db.HostDB, undoLog *undo.Log) (*db.UndoAction, error) {
+	settings, version, found, err := hostDB.GetPluginSettings(pluginID)
+	if err != nil {
+		return nil, err
+	}
+	payload := wipedSettings{}
+	if found {
+		payload = wipedSettings{Settings: settings, Version: version}
+	}
+
+	action, err := undoLog.Record(undoPluginPurge, pluginID, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loader.UnloadPlugin(pluginID); err != nil {
+		undoLog.Discard(action.ID)
+		return nil, err
+	}
+
+	trash := undoLog.TrashPath(action.ID)
+	if err := os.MkdirAll(trash, 0700); err != nil {
+		undoLog.Discard(action.ID)
+		return nil, fmt.Errorf("creating undo trash: %w", err)
+	}
+	if err := os.Rename(loader.DataPath(pluginID), filepath.Join(trash, purgedDataDirName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		undoLog.Discard(action.ID)
+		return nil, fmt.Errorf("setting plugin data aside: %w", err)
+	}
+
+	if found {
+		if err := hostDB.DeletePluginSettings(pluginID); err != nil && !errors.Is(err, db.ErrNotFound) {
+			return action, fmt.Errorf("deleting plugin settings: %w", err)
+		}
+	}
+	return action, nil
+}
+
+// restorePurgedPlugin undoes a purge: it puts back the plugin's data and
+// settings and loads it again. A plugin loaded or configured since is left
+// alone.
+func restorePurgedPlugin(registry *plugin.Registry, loader *plugin.Loader, hostDB *db.HostDB, undoLog *undo.Log) undo.Handler {
+	return func(action db.UndoAction) error {
+		var payload wipedSettings
+		if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+			return fmt.Errorf("decoding undo payload: %w", err)
+		}
+
+		if _, loaded := registry.Get(action.Target); loaded {
+			return fmt.Errorf("%w: plugin %s has been loaded again", undo.ErrConflict, action.Target)
+		}
+		if _, err := os.Stat(loader.DataPath(action.Target)); err == nil {
+			return fmt.Errorf("%w: plugin %s has new data", undo.ErrConflict, action.Target)
+		}
+		if _, _, found, err := hostDB.GetPluginSettings(action.Target); err != nil || found {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: plugin %s has new settings", undo.ErrConflict, action.Target)
+		}
+
+		purged := filepath.Join(undoLog.TrashPath(action.ID), purgedDataDirName)
+		if _, err := os.Stat(purged); err == nil {
+			if err := os.MkdirAll(filepath.Dir(loader.DataPath(action.Target)), 0755); err != nil {
+				return fmt.Errorf("creating plugin data directory: %w", err)
+			}
+			if err := os.Rename(purged, loader.DataPath(action.Target)); err != nil {
+				return fmt.Errorf("restoring plugin data: %w", err)
+			}
+		}
+		if payload.Settings != nil {
+			if err := hostDB.RestorePluginSettings(action.Target, payload.Settings, payload.Version); err != nil {
+				return fmt.Errorf("restoring plugin settings: %w", err)
+			}
+		}
+
+		// The data is back either way; a plugin that no longer loads is
+		// reported in its logs like at startup.
+		if err := loader.LoadPlugin(action.Target); err != nil {
+			slog.Warn("restored plugin failed to load", "plugin", action.Target, "error", err)
+		}
+		return nil
+	}
+}
+
+// restoreWipedSettings undoes a settings wipe, unless settings were saved
+// since, and hands the restored settings to the plugin.
+func restoreWipedSettings(hostDB *db.HostDB, loader *plugin.Loader) undo.Handler {
+	return func(action db.UndoAction) error {
+		var payload wipedSettings
+		if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+			return fmt.Errorf("decoding undo payload: %w", err)
+		}
+
+		err := hostDB.RestorePluginSettings(action.Target, payload.Settings, payload.Version)
+		if errors.Is(err, db.ErrExists) {
+			return fmt.Errorf("%w: plugin %s has new settings", undo.ErrConflict, action.Target)
+		}
+		if err != nil {
+			return err
+		}
+		applySettings(loader, action.Target, payload.Settings)
+		return nil
+	}
+}
+
+// restoreDeletedSecret undoes a secret deletion, unless a secret was saved
+// under the same name since.
+func restoreDeletedSecret(store *secrets.Store) undo.Handler {
+	return func(action db.UndoAction) error {
+		var payload deletedSecret
+		if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+			return fmt.Errorf("decoding undo payload: %w", err)
+		}
+
+		err := store.Restore(action.Target, payload.Sealed)
+		if errors.Is(err, db.ErrExists) {
+			return fmt.Errorf("%w: secret %s has been saved again", undo.ErrConflict, action.Target)
+		}
+		return err
+	}
+}
+
+// restoreDeletedRecords undoes a plugin delete by handing what it removed
+// back to the plugin, unless the records have been taken again since. The
+// restore is a write the proxy never sees, so it drops the plugin's cached
+// widgets itself.
+func restoreDeletedRecords(loader *plugin.Loader, widgets *WidgetCache) undo.Handler {
+	return func(action db.UndoAction) error {
+		var payload deletedRecords
+		if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+			return fmt.Errorf("decoding undo payload: %w", err)
+		}
+
+		entry, err := loader.Acquire(action.Target)
+		if err != nil {
+			return fmt.Errorf("reaching plugin %s: %w", action.Target, err)
+		}
+		restorer, ok := entry.Plugin.(plugin.Restorer)
+		if !ok {
+			loader.Release(action.Target, entry, nil)
+			return fmt.Errorf("%w: plugin %s cannot restore deleted records", undo.ErrUnsupported, action.Target)
+		}
+
+		err = restorer.RestoreDeleted(payload.Kind, payload.Data)
+		switch {
+		case errors.Is(err, plugin.ErrRestoreConflict):
+			loader.Release(action.Target, entry, nil)
+			return fmt.Errorf("%w: %v", undo.ErrConflict, err)
+		case errors.Is(err, plugin.ErrNotImplemented):
+			loader.Release(action.Target, entry, nil)
+			return fmt.Errorf("%w: plugin %s cannot restore deleted records", undo.ErrUnsupported, action.Target)
+		}
+		loader.Release(action.Target, entry, err)
+		if err == nil && widgets != nil {
+			widgets.Invalidate(action.Target)
+		}
+		return err
+	}
+}
+
+// writeUndoError writes a standardized error JSON response for undo endpoints.
+func writeUndoError(writer http.ResponseWriter, statusCode int, code string, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
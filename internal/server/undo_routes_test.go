@@ -0,0 +1,308 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+	"github.com/alvarotorresc/cortex/internal/plugin"
+	"github.com/alvarotorresc/cortex/internal/undo"
+)
+
+// newTestUndoLog creates a host DB and an undo log with a one minute window.
+func newTestUndoLog(t *testing.T) (*db.HostDB, *undo.Log) {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+	return hostDB, undo.NewLog(hostDB, t.TempDir(), time.Minute)
+}
+
+// decodeUndoAction returns the action a destructive call recorded.
+func decodeUndoAction(t *testing.T, body []byte) db.UndoAction {
+	t.Helper()
+
+	var response struct {
+		Data struct {
+			Undo *db.UndoAction `json:"undo"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.Data.Undo == nil || response.Data.Undo.ID == "" {
+		t.Fatalf("expected the response to carry an undo action, got %s", body)
+	}
+	return *response.Data.Undo
+}
+
+func TestUndo_DeletedSecret(t *testing.T) {
+	router := newSecretRouter(t, bytes.Repeat([]byte{7}, 32))
+
+	if rec := serveJSON(router, http.MethodPut, "/api/secrets/price-api", `{"value": "sk_live_123"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 saving the secret, got %d", rec.Code)
+	}
+	rec := serveJSON(router, http.MethodDelete, "/api/secrets/price-api", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 deleting the secret, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	action := decodeUndoAction(t, rec.Body.Bytes())
+	if action.Kind != undoSecretDelete || action.Target != "price-api" {
+		t.Errorf("unexpected undo action: %+v", action)
+	}
+
+	rec = serveJSON(router, http.MethodGet, "/api/admin/undo", "")
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(action.ID)) || bytes.Contains(rec.Body.Bytes(), []byte("sealed")) {
+		t.Errorf("expected the action to be listed without its payload, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveJSON(router, http.MethodPost, "/api/admin/undo/"+action.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 undoing, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	// The restored secret can be referenced again
+	rec = serveJSON(router, http.MethodPut, "/api/plugins/prices/settings", `{"api_key": {"$secret": "price-api"}}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the secret to be back, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveJSON(router, http.MethodPost, "/api/admin/undo/"+action.ID, "")
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusConflict || code != "ALREADY_UNDONE" {
+		t.Errorf("expected 409 ALREADY_UNDONE undoing twice, got %d %s", rec.Code, code)
+	}
+	rec = serveJSON(router, http.MethodPost, "/api/admin/undo/missing", "")
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusNotFound || code != "NOT_FOUND" {
+		t.Errorf("expected 404 for an unknown action, got %d %s", rec.Code, code)
+	}
+}
+
+func TestUndo_WipedSettingsConflict(t *testing.T) {
+	router := newSecretRouter(t, nil)
+
+	if rec := serveJSON(router, http.MethodDelete, "/api/plugins/prices/settings", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 wiping settings that were never saved, got %d", rec.Code)
+	}
+
+	if rec := serveJSON(router, http.MethodPut, "/api/plugins/prices/settings", `{"currency": "EUR"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 saving settings, got %d", rec.Code)
+	}
+	rec := serveJSON(router, http.MethodDelete, "/api/plugins/prices/settings", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 wiping settings, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	action := decodeUndoAction(t, rec.Body.Bytes())
+
+	// Settings saved after the wipe are not overwritten by the undo
+	if rec := serveJSON(router, http.MethodPut, "/api/plugins/prices/settings", `{"currency": "USD"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 saving settings, got %d", rec.Code)
+	}
+	rec = serveJSON(router, http.MethodPost, "/api/admin/undo/"+action.ID, "")
+	if code := decodeErrorCode(t, rec); rec.Code != http.StatusConflict || code != "CONFLICT" {
+		t.Fatalf("expected 409 CONFLICT, got %d %s", rec.Code, code)
+	}
+
+	if rec := serveJSON(router, http.MethodDelete, "/api/plugins/prices/settings", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 wiping settings, got %d", rec.Code)
+	}
+	// The failed undo can be retried once the conflict is gone
+	rec = serveJSON(router, http.MethodPost, "/api/admin/undo/"+action.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 retrying the undo, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	rec = serveJSON(router, http.MethodGet, "/api/plugins/prices/settings", "")
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`"currency":"EUR"`)) {
+		t.Errorf("expected the wiped settings back, got %s", rec.Body.String())
+	}
+}
+
+func TestUndo_PurgedPlugin(t *testing.T) {
+	hostDB, undoLog := newTestUndoLog(t)
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes", plugin.PermissionDBRead)
+	dataDir := t.TempDir()
+	loader := plugin.NewLoader(t.TempDir(), dataDir, registry)
+
+	if err := os.MkdirAll(loader.DataPath("notes"), 0755); err != nil {
+		t.Fatalf("creating plugin data: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(loader.DataPath("notes"), "db.sqlite"), []byte("notes"), 0644); err != nil {
+		t.Fatalf("writing plugin data: %v", err)
+	}
+	if err := hostDB.SavePluginSettings("notes", []byte(`{"sort":"title"}`), "1.0.0"); err != nil {
+		t.Fatalf("saving settings: %v", err)
+	}
+
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, loader, plugin.NewInstaller(t.TempDir(), "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(0, nil))
+	undoRoutes(router, undoLog)
+
+	rec := serveJSON(router, http.MethodDelete, "/api/plugins/notes?purge=true", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 purging, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	action := decodeUndoAction(t, rec.Body.Bytes())
+
+	if _, ok := registry.Get("notes"); ok {
+		t.Error("expected the purged plugin to be unloaded")
+	}
+	if _, err := os.Stat(loader.DataPath("notes")); !os.IsNotExist(err) {
+		t.Errorf("expected the plugin data to be gone, got %v", err)
+	}
+	if _, _, found, _ := hostDB.GetPluginSettings("notes"); found {
+		t.Error("expected the plugin settings to be gone")
+	}
+
+	// There is no plugin binary to load again, so only the data comes back.
+	rec = serveJSON(router, http.MethodPost, "/api/admin/undo/"+action.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 undoing, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	data, err := os.ReadFile(filepath.Join(loader.DataPath("notes"), "db.sqlite"))
+	if err != nil || string(data) != "notes" {
+		t.Errorf("expected the plugin data back, got %q, %v", data, err)
+	}
+	settings, _, found, _ := hostDB.GetPluginSettings("notes")
+	if !found || string(settings) != `{"sort":"title"}` {
+		t.Errorf("expected the plugin settings back, got %s", settings)
+	}
+	if _, err := os.Stat(undoLog.TrashPath(action.ID)); !os.IsNotExist(err) {
+		t.Errorf("expected the undo trash to be removed, got %v", err)
+	}
+}
+
+// restorablePlugin is a stub plugin whose deletes can be undone.
+type restorablePlugin struct {
+	stubPlugin
+	restored    []string
+	conflict    bool
+	widgetCalls int
+}
+
+func (p *restorablePlugin) HandleAPI(_ context.Context, request *plugin.APIRequest) (*plugin.APIResponse, error) {
+	response := &plugin.APIResponse{StatusCode: http.StatusOK, Body: []byte(`{"data":{"deleted":"7"}}`), ContentType: "application/json"}
+	if request.Method == http.MethodDelete {
+		response.Deleted = &plugin.DeletedEntity{Kind: "note", Data: []byte(`{"id":7,"title":"Groceries"}`)}
+	}
+	return response, nil
+}
+
+func (p *restorablePlugin) RestoreDeleted(kind string, data []byte) error {
+	if p.conflict {
+		return plugin.ErrRestoreConflict
+	}
+	p.restored = append(p.restored, kind+" "+string(data))
+	return nil
+}
+
+func (p *restorablePlugin) GetWidgetData(slot string) ([]byte, error) {
+	p.widgetCalls++
+	return []byte(fmt.Sprintf(`{"notes":%d}`, p.widgetCalls)), nil
+}
+
+func newRestorableRouter(t *testing.T) (*chi.Mux, *restorablePlugin) {
+	t.Helper()
+
+	hostDB, undoLog := newTestUndoLog(t)
+	registry := plugin.NewRegistry()
+	registerStubPlugin(registry, "notes", plugin.PermissionDBWrite)
+	restorable := &restorablePlugin{}
+	entry, _ := registry.Get("notes")
+	entry.Plugin = restorable
+
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(t.TempDir(), t.TempDir(), registry), plugin.NewInstaller(t.TempDir(), "", nil), plugin.NewUpdateChecker(registry), hostDB, undoLog, NewWidgetCache(time.Minute, nil))
+	undoRoutes(router, undoLog)
+	return router, restorable
+}
+
+func TestUndo_PluginDelete(t *testing.T) {
+	router, restorable := newRestorableRouter(t)
+
+	rec := serveJSON(router, http.MethodDelete, "/api/plugins/notes/notes/7", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 deleting, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	token := rec.Header().Get(undoTokenHeader)
+	if token == "" || rec.Header().Get(undoExpiresHeader) == "" {
+		t.Fatalf("expected an undo token and its expiry, got %v", rec.Header())
+	}
+	if rec.Body.String() != `{"data":{"deleted":"7"}}` {
+		t.Errorf("expected the deleted records to stay out of the response, got %s", rec.Body.String())
+	}
+
+	rec = serveJSON(router, http.MethodPost, "/api/undo/"+token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 undoing, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	if len(restorable.restored) != 1 || restorable.restored[0] != `note {"id":7,"title":"Groceries"}` {
+		t.Errorf("expected the plugin to get its records back once, got %v", restorable.restored)
+	}
+	if rec := serveJSON(router, http.MethodPost, "/api/undo/"+token, ""); rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 undoing twice, got %d", rec.Code)
+	}
+}
+
+func TestUndo_PluginDeleteDropsCachedWidgets(t *testing.T) {
+	router, restorable := newRestorableRouter(t)
+
+	rec := serveJSON(router, http.MethodDelete, "/api/plugins/notes/notes/7", "")
+	token := rec.Header().Get(undoTokenHeader)
+
+	serveJSON(router, http.MethodGet, "/api/plugins/notes/widget/count", "")
+	serveJSON(router, http.MethodGet, "/api/plugins/notes/widget/count", "")
+	if restorable.widgetCalls != 1 {
+		t.Fatalf("expected the widget data cached after the delete, got %d calls", restorable.widgetCalls)
+	}
+
+	if rec := serveJSON(router, http.MethodPost, "/api/undo/"+token, ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 undoing, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	rec = serveJSON(router, http.MethodGet, "/api/plugins/notes/widget/count", "")
+	if restorable.widgetCalls != 2 || rec.Body.String() != `{"notes":2}` {
+		t.Errorf("expected fresh widget data after the restore, got %s after %d calls", rec.Body.String(), restorable.widgetCalls)
+	}
+}
+
+func TestUndo_PluginDeleteConflict(t *testing.T) {
+	router, restorable := newRestorableRouter(t)
+	restorable.conflict = true
+
+	rec := serveJSON(router, http.MethodDelete, "/api/plugins/notes/notes/7", "")
+	token := rec.Header().Get(undoTokenHeader)
+
+	rec = serveJSON(router, http.MethodPost, "/api/undo/"+token, "")
+	if rec.Code != http.StatusConflict || !bytes.Contains(rec.Body.Bytes(), []byte(`"CONFLICT"`)) {
+		t.Fatalf("expected status 409 CONFLICT, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+
+	// The action is released for another try once the conflict is gone.
+	restorable.conflict = false
+	if rec := serveJSON(router, http.MethodPost, "/api/undo/"+token, ""); rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 on retry, got %d", rec.Code)
+	}
+}
+
+func TestUndo_TokenRouteOnlyUndoesPluginDeletes(t *testing.T) {
+	router := newSecretRouter(t, bytes.Repeat([]byte{7}, 32))
+
+	serveJSON(router, http.MethodPut, "/api/secrets/price-api", `{"value": "sk_live_123"}`)
+	rec := serveJSON(router, http.MethodDelete, "/api/secrets/price-api", "")
+	action := decodeUndoAction(t, rec.Body.Bytes())
+
+	if rec := serveJSON(router, http.MethodPost, "/api/undo/"+action.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an admin action, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// pluginUpdateRoutes registers the one-click update of a plugin to the latest
+// release published at its manifest's update_url.
+func pluginUpdateRoutes(router chi.Router, registry *plugin.Registry, loader *plugin.Loader, installer *plugin.Installer, updates *plugin.UpdateChecker) {
+	// Install the latest release over the running version
+	router.Post("/api/plugins/{pluginID}/update", func(writer http.ResponseWriter, request *http.Request) {
+		pluginID := chi.URLParam(request, "pluginID")
+
+		entry, ok := registry.Get(pluginID)
+		if !ok {
+			writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+			return
+		}
+		previousVersion := entry.Manifest.Version
+
+		// The release is fetched again so the update installs what is published now
+		release, err := updates.Latest(entry.Manifest)
+		if err != nil {
+			writeUpdateError(writer, err)
+			return
+		}
+
+		staging, err := installer.StageUpdate(release.InstallSource, pluginID)
+		if err != nil {
+			writeInstallError(writer, err)
+			return
+		}
+		defer os.RemoveAll(staging)
+
+		if err := loader.Upgrade(pluginID, staging); err != nil {
+			writeUpdateError(writer, err)
+			return
+		}
+
+		version := release.Version
+		if entry, ok := registry.Get(pluginID); ok {
+			version = entry.Manifest.Version
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":               pluginID,
+				"version":          version,
+				"previous_version": previousVersion,
+				"status":           "updated",
+			},
+		})
+	})
+}
+
+// writeUpdateError maps update check and update errors to API error responses.
+func writeUpdateError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, plugin.ErrPluginNotFound):
+		writePluginError(writer, http.StatusNotFound, "NOT_FOUND", "plugin not found")
+	case errors.Is(err, plugin.ErrNoUpdateURL):
+		writePluginError(writer, http.StatusConflict, "NO_UPDATE_URL", "plugin declares no update_url to check for releases")
+	case errors.Is(err, plugin.ErrUpToDate):
+		writePluginError(writer, http.StatusConflict, "UP_TO_DATE", "plugin is already at the latest release")
+	case errors.Is(err, plugin.ErrInvalidRelease):
+		writePluginError(writer, http.StatusBadGateway, "INVALID_RELEASE", "plugin release manifest is invalid")
+	case errors.Is(err, plugin.ErrDownloadFailed):
+		writePluginError(writer, http.StatusBadGateway, "DOWNLOAD_ERROR", "failed to fetch plugin release manifest")
+	case errors.Is(err, plugin.ErrUpdateRestored):
+		slog.Error("updating plugin failed", "error", err)
+		writePluginError(writer, http.StatusInternalServerError, "UPDATE_ERROR", "new version failed to load; the previous version was restored")
+	default:
+		slog.Error("plugin update failed", "error", err)
+		writePluginError(writer, http.StatusInternalServerError, "UPDATE_ERROR", "failed to update plugin")
+	}
+}
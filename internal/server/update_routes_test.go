@@ -0,0 +1,167 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// releaseServer publishes release manifests by path, and serves archive at /archive.
+func releaseServer(t *testing.T, releases map[string]string, archive []byte) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/archive" {
+			_, _ = writer.Write(archive)
+			return
+		}
+		release, ok := releases[request.URL.Path]
+		if !ok {
+			http.NotFound(writer, request)
+			return
+		}
+		_, _ = writer.Write([]byte(release))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newUpdateRouter(t *testing.T, registry *plugin.Registry, updates *plugin.UpdateChecker) *chi.Mux {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	hostDB, undoLog := newTestUndoLog(t)
+	router := chi.NewRouter()
+	pluginAPIRoutes(router, registry, plugin.NewLoader(tempDir, tempDir, registry), plugin.NewInstaller(tempDir, "", nil), updates, hostDB, undoLog, NewWidgetCache(0, nil))
+	return router
+}
+
+func TestListPlugins_ShowsAvailableUpdates(t *testing.T) {
+	server := releaseServer(t, map[string]string{
+		"/alpha.json": `{"version":"1.1.0","url":"https://example.com/alpha.cortexplugin","sha256":"00"}`,
+		"/beta.json":  `{"version":"2.0.0","url":"https://example.com/beta.cortexplugin","sha256":"00"}`,
+	}, nil)
+
+	registry := plugin.NewRegistry()
+	registry.Register("alpha", nil, &plugin.Manifest{ID: "alpha", Version: "1.0.0", UpdateURL: server.URL + "/alpha.json"})
+	registry.Register("beta", nil, &plugin.Manifest{ID: "beta", Version: "2.0.0", UpdateURL: server.URL + "/beta.json"})
+	registry.Register("gamma", nil, &plugin.Manifest{ID: "gamma", Version: "1.0.0"})
+
+	updates := plugin.NewUpdateChecker(registry)
+	updates.CheckAll()
+
+	router := newUpdateRouter(t, registry, updates)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/plugins", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Data []struct {
+			ID     string         `json:"id"`
+			Update *plugin.Update `json:"update"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+
+	updateOf := make(map[string]*plugin.Update)
+	for _, listing := range body.Data {
+		updateOf[listing.ID] = listing.Update
+	}
+	if len(updateOf) != 3 {
+		t.Fatalf("expected 3 plugins, got %+v", body.Data)
+	}
+	if update := updateOf["alpha"]; update == nil || update.Version != "1.1.0" || update.CheckedAt.IsZero() {
+		t.Errorf("expected alpha to have update 1.1.0, got %+v", update)
+	}
+	if updateOf["beta"] != nil || updateOf["gamma"] != nil {
+		t.Errorf("expected no update for up-to-date or unchecked plugins, got %+v and %+v", updateOf["beta"], updateOf["gamma"])
+	}
+}
+
+func TestUpdatePlugin_Errors(t *testing.T) {
+	server := releaseServer(t, map[string]string{
+		"/current.json": `{"version":"1.0.0","url":"https://example.com/current.cortexplugin","sha256":"00"}`,
+		"/invalid.json": `{"version":"2.0.0"}`,
+	}, nil)
+
+	registry := plugin.NewRegistry()
+	registry.Register("unlisted", nil, &plugin.Manifest{ID: "unlisted", Version: "1.0.0"})
+	registry.Register("current", nil, &plugin.Manifest{ID: "current", Version: "1.0.0", UpdateURL: server.URL + "/current.json"})
+	registry.Register("invalid", nil, &plugin.Manifest{ID: "invalid", Version: "1.0.0", UpdateURL: server.URL + "/invalid.json"})
+	registry.Register("gone", nil, &plugin.Manifest{ID: "gone", Version: "1.0.0", UpdateURL: server.URL + "/gone.json"})
+
+	router := newUpdateRouter(t, registry, plugin.NewUpdateChecker(registry))
+
+	tests := []struct {
+		plugin string
+		status int
+		code   string
+	}{
+		{"missing", http.StatusNotFound, "NOT_FOUND"},
+		{"unlisted", http.StatusConflict, "NO_UPDATE_URL"},
+		{"current", http.StatusConflict, "UP_TO_DATE"},
+		{"invalid", http.StatusBadGateway, "INVALID_RELEASE"},
+		{"gone", http.StatusBadGateway, "DOWNLOAD_ERROR"},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/"+test.plugin+"/update", nil))
+		if rec.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d: %s", test.plugin, test.status, rec.Code, rec.Body.String())
+			continue
+		}
+		if code := decodeErrorCode(t, rec); code != test.code {
+			t.Errorf("%s: expected error code %s, got %s", test.plugin, test.code, code)
+		}
+	}
+}
+
+func TestUpdatePlugin_RejectsArchiveOfAnotherPlugin(t *testing.T) {
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range map[string]string{
+		"manifest.json": `{"id":"other","name":"Other","version":"2.0.0"}`,
+		"plugin":        "#!/bin/sh\nexit 1\n",
+	} {
+		_ = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tarWriter.Write([]byte(content))
+	}
+	_ = tarWriter.Close()
+	_ = gzipWriter.Close()
+	digest := sha256.Sum256(archive.Bytes())
+
+	releases := map[string]string{}
+	server := releaseServer(t, releases, archive.Bytes())
+	releases["/alpha.json"] = `{"version":"2.0.0","url":"` + server.URL + `/archive","sha256":"` + hex.EncodeToString(digest[:]) + `"}`
+
+	registry := plugin.NewRegistry()
+	registry.Register("alpha", nil, &plugin.Manifest{ID: "alpha", Version: "1.0.0", UpdateURL: server.URL + "/alpha.json"})
+
+	router := newUpdateRouter(t, registry, plugin.NewUpdateChecker(registry))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/plugins/alpha/update", nil))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if code := decodeErrorCode(t, rec); code != "INVALID_ARCHIVE" {
+		t.Errorf("expected error code INVALID_ARCHIVE, got %s", code)
+	}
+	if entry, ok := registry.Get("alpha"); !ok || entry.Manifest.Version != "1.0.0" {
+		t.Error("expected the running version to be left in place")
+	}
+}
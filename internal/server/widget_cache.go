@@ -0,0 +1,127 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// WidgetCache keeps the widget data plugins returned, so dashboard loads do
+// not hit plugin databases every time. Entries expire after the TTL, when
+// the plugin reports a change through NotifyChanged, and when the plugin
+// process serving them is replaced by a reload, update or restart.
+//
+// It implements plugin.ChangeListener and forwards every change to the next
+// listener, usually the EventHub.
+type WidgetCache struct {
+	ttl  time.Duration
+	next plugin.ChangeListener
+
+	mu sync.Mutex
+	// generation is bumped on every invalidation, so data fetched before one
+	// is not stored after it.
+	generation uint64
+	entries    map[widgetCacheKey]widgetCacheEntry
+}
+
+// widgetCacheKey identifies cached data by the plugin that served it, which
+// is the canary for requests routed to one, and the widget slot.
+type widgetCacheKey struct {
+	target string
+	slot   string
+}
+
+type widgetCacheEntry struct {
+	pluginID string
+	entry    *plugin.RegistryEntry
+	data     []byte
+	etag     string
+	expires  time.Time
+}
+
+// NewWidgetCache creates a cache whose entries live for ttl. A ttl of zero
+// disables caching; ETags are still computed. next may be nil.
+func NewWidgetCache(ttl time.Duration, next plugin.ChangeListener) *WidgetCache {
+	return &WidgetCache{ttl: ttl, next: next, entries: make(map[widgetCacheKey]widgetCacheEntry)}
+}
+
+// Get returns the cached data of a slot and its ETag. entry is the registry
+// entry currently serving target; data cached from another process is stale.
+func (c *WidgetCache) Get(target string, slot string, entry *plugin.RegistryEntry) (data []byte, etag string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := widgetCacheKey{target: target, slot: slot}
+	cached, ok := c.entries[key]
+	if !ok {
+		return nil, "", false
+	}
+	if cached.entry != entry || time.Now().After(cached.expires) {
+		delete(c.entries, key)
+		return nil, "", false
+	}
+	return cached.data, cached.etag, true
+}
+
+// Generation returns the current invalidation generation, to be passed to
+// Put with the data fetched after it.
+func (c *WidgetCache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Put caches the data of a slot and returns its ETag. The data is dropped if
+// the cache was invalidated since generation was read.
+func (c *WidgetCache) Put(pluginID string, target string, slot string, entry *plugin.RegistryEntry, generation uint64, data []byte) string {
+	etag := widgetETag(data)
+	if c.ttl <= 0 {
+		return etag
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return etag
+	}
+	c.entries[widgetCacheKey{target: target, slot: slot}] = widgetCacheEntry{
+		pluginID: pluginID,
+		entry:    entry,
+		data:     data,
+		etag:     etag,
+		expires:  time.Now().Add(c.ttl),
+	}
+	return etag
+}
+
+// Invalidate drops the cached data of a plugin and of its canary.
+func (c *WidgetCache) Invalidate(pluginID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for key, cached := range c.entries {
+		if cached.pluginID == pluginID || key.target == pluginID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// PluginChanged drops the plugin's cached widget data and forwards the
+// change to the next listener.
+func (c *WidgetCache) PluginChanged(pluginID string, topic string) {
+	c.Invalidate(pluginID)
+	if c.next != nil {
+		c.next.PluginChanged(pluginID, topic)
+	}
+}
+
+// widgetETag returns a strong ETag for widget data.
+func widgetETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,305 @@
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Chart kinds a snapshot can be rendered as.
+const (
+	ChartBars  = "bars"
+	ChartDonut = "donut"
+)
+
+const (
+	chartWidth  = 480
+	chartHeight = 270
+	chartMargin = 24
+	// maxChartValues bounds how many bars or slices are drawn.
+	maxChartValues = 60
+)
+
+// ErrNothingToChart is returned when widget data holds no numbers to draw.
+var ErrNothingToChart = errors.New("widget data has no numeric series to chart")
+
+// valueKeys are the fields tried, in order, for the value of each object in
+// an array of records, such as {"month": "2026-03", "balance": 120}.
+var valueKeys = []string{"value", "amount", "total", "balance", "count"}
+
+// palette colors donut slices after the first, which uses the accent color.
+var palette = []color.RGBA{
+	{0x3B, 0x82, 0xF6, 0xFF},
+	{0xF5, 0x9E, 0x0B, 0xFF},
+	{0xEF, 0x44, 0x44, 0xFF},
+	{0x8B, 0x5C, 0xF6, 0xFF},
+	{0x14, 0xB8, 0xA6, 0xFF},
+	{0xEC, 0x48, 0x99, 0xFF},
+	{0x84, 0xCC, 0x16, 0xFF},
+}
+
+// Chart is the numeric series of a widget snapshot. Only the series is kept
+// for image snapshots, so nothing else in the widget data is shared.
+type Chart struct {
+	Kind   string    `json:"kind"`
+	Labels []string  `json:"labels"`
+	Values []float64 `json:"values"`
+}
+
+// ExtractChart picks the series to chart from widget data. field is a dotted
+// path into the data (the plugin's {"data": ...} envelope is unwrapped first);
+// when empty, the first array of numbers or records is used, falling back to
+// the numeric fields of the data itself. kind defaults to bars for arrays and
+// to a donut for objects.
+func ExtractChart(widgetData []byte, field string, kind string) (*Chart, error) {
+	var value interface{}
+	if err := json.Unmarshal(widgetData, &value); err != nil {
+		return nil, fmt.Errorf("decoding widget data: %w", err)
+	}
+	if envelope, ok := value.(map[string]interface{}); ok {
+		if inner, ok := envelope["data"]; ok && len(envelope) == 1 {
+			value = inner
+		}
+	}
+
+	if field != "" {
+		for _, segment := range strings.Split(field, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("field %q not found in widget data", field)
+			}
+			if value, ok = object[segment]; !ok {
+				return nil, fmt.Errorf("field %q not found in widget data", field)
+			}
+		}
+	} else if object, ok := value.(map[string]interface{}); ok {
+		for _, key := range sortedKeys(object) {
+			if array, isArray := object[key].([]interface{}); isArray {
+				if _, values := series(array); len(values) > 0 {
+					value = array
+					break
+				}
+			}
+		}
+	}
+
+	labels, values := series(value)
+	if len(values) == 0 {
+		return nil, ErrNothingToChart
+	}
+	if len(values) > maxChartValues {
+		labels, values = labels[len(labels)-maxChartValues:], values[len(values)-maxChartValues:]
+	}
+
+	if kind == "" {
+		kind = ChartBars
+		if _, isObject := value.(map[string]interface{}); isObject {
+			kind = ChartDonut
+		}
+	}
+	if kind != ChartBars && kind != ChartDonut {
+		return nil, fmt.Errorf("chart must be %q or %q", ChartBars, ChartDonut)
+	}
+
+	return &Chart{Kind: kind, Labels: labels, Values: values}, nil
+}
+
+// series returns the labelled numbers of an array of numbers, an array of
+// records, or an object's numeric fields.
+func series(value interface{}) ([]string, []float64) {
+	var labels []string
+	var values []float64
+
+	switch typed := value.(type) {
+	case []interface{}:
+		for index, item := range typed {
+			switch element := item.(type) {
+			case float64:
+				labels, values = append(labels, strconv.Itoa(index+1)), append(values, element)
+			case map[string]interface{}:
+				number, ok := recordValue(element)
+				if !ok {
+					continue
+				}
+				labels, values = append(labels, recordLabel(element, index)), append(values, number)
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(typed) {
+			if number, ok := typed[key].(float64); ok {
+				labels, values = append(labels, key), append(values, number)
+			}
+		}
+	}
+
+	return labels, values
+}
+
+func recordValue(record map[string]interface{}) (float64, bool) {
+	for _, key := range valueKeys {
+		if number, ok := record[key].(float64); ok {
+			return number, true
+		}
+	}
+	for _, key := range sortedKeys(record) {
+		if number, ok := record[key].(float64); ok {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+func recordLabel(record map[string]interface{}, index int) string {
+	for _, key := range sortedKeys(record) {
+		if label, ok := record[key].(string); ok {
+			return label
+		}
+	}
+	return strconv.Itoa(index + 1)
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PNG renders the chart on a white background, using accent for bars and
+// the first donut slice.
+func (c *Chart) PNG(accent color.RGBA) ([]byte, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	fill(canvas, canvas.Bounds(), color.RGBA{0xFF, 0xFF, 0xFF, 0xFF})
+
+	switch c.Kind {
+	case ChartDonut:
+		if err := c.drawDonut(canvas, accent); err != nil {
+			return nil, err
+		}
+	default:
+		c.drawBars(canvas, accent)
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, canvas); err != nil {
+		return nil, fmt.Errorf("encoding chart: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// drawBars draws one bar per value from a zero baseline, so negative values
+// hang below it.
+func (c *Chart) drawBars(canvas *image.RGBA, accent color.RGBA) {
+	highest, lowest := 0.0, 0.0
+	for _, value := range c.Values {
+		highest, lowest = math.Max(highest, value), math.Min(lowest, value)
+	}
+	span := highest - lowest
+	if span == 0 {
+		span = 1
+	}
+
+	plotHeight := float64(chartHeight - 2*chartMargin)
+	baseline := chartMargin + int(highest/span*plotHeight)
+	fill(canvas, image.Rect(chartMargin, baseline, chartWidth-chartMargin, baseline+1), color.RGBA{0xD1, 0xD5, 0xDB, 0xFF})
+
+	slot := float64(chartWidth-2*chartMargin) / float64(len(c.Values))
+	gap := int(slot / 5)
+	negative := color.RGBA{0xEF, 0x44, 0x44, 0xFF}
+	for index, value := range c.Values {
+		left := chartMargin + int(float64(index)*slot) + gap/2
+		right := chartMargin + int(float64(index+1)*slot) - gap/2
+		height := int(math.Abs(value) / span * plotHeight)
+		if value >= 0 {
+			fill(canvas, image.Rect(left, baseline-height, right, baseline), accent)
+		} else {
+			fill(canvas, image.Rect(left, baseline+1, right, baseline+1+height), negative)
+		}
+	}
+}
+
+// drawDonut draws the positive values as slices clockwise from the top.
+func (c *Chart) drawDonut(canvas *image.RGBA, accent color.RGBA) error {
+	total := 0.0
+	for _, value := range c.Values {
+		if value > 0 {
+			total += value
+		}
+	}
+	if total == 0 {
+		return ErrNothingToChart
+	}
+
+	// Cumulative end angle of each slice, as a fraction of the full turn.
+	ends := make([]float64, len(c.Values))
+	cumulative := 0.0
+	for index, value := range c.Values {
+		if value > 0 {
+			cumulative += value / total
+		}
+		ends[index] = cumulative
+	}
+
+	centerX, centerY := float64(chartWidth)/2, float64(chartHeight)/2
+	outer := float64(chartHeight)/2 - chartMargin
+	inner := outer * 0.6
+	for y := 0; y < chartHeight; y++ {
+		for x := 0; x < chartWidth; x++ {
+			dx, dy := float64(x)+0.5-centerX, float64(y)+0.5-centerY
+			distance := math.Hypot(dx, dy)
+			if distance > outer || distance < inner {
+				continue
+			}
+			turn := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if turn < 0 {
+				turn++
+			}
+			slice := sort.SearchFloat64s(ends, turn)
+			if slice >= len(ends) {
+				slice = len(ends) - 1
+			}
+			canvas.SetRGBA(x, y, sliceColor(slice, accent))
+		}
+	}
+	return nil
+}
+
+func sliceColor(index int, accent color.RGBA) color.RGBA {
+	if index == 0 {
+		return accent
+	}
+	return palette[(index-1)%len(palette)]
+}
+
+func fill(canvas *image.RGBA, area image.Rectangle, shade color.RGBA) {
+	area = area.Intersect(canvas.Bounds())
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			canvas.SetRGBA(x, y, shade)
+		}
+	}
+}
+
+// ParseColor parses a manifest color such as "#10B981", returning fallback
+// for anything else.
+func ParseColor(hex string, fallback color.RGBA) color.RGBA {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return fallback
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return fallback
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xFF}
+}
@@ -0,0 +1,71 @@
+package share
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestExtractChart_FindsSeries(t *testing.T) {
+	finance := []byte(`{"data": {"income": 2400, "expense": 1800, "month": "2026-03",
+		"sparkline": [{"month": "2026-02", "balance": 300}, {"month": "2026-03", "balance": -50}]}}`)
+
+	chart, err := ExtractChart(finance, "", "")
+	if err != nil {
+		t.Fatalf("ExtractChart failed: %v", err)
+	}
+	if chart.Kind != ChartBars || len(chart.Values) != 2 || chart.Values[1] != -50 || chart.Labels[0] != "2026-02" {
+		t.Errorf("expected the sparkline as bars, got %+v", chart)
+	}
+
+	chart, err = ExtractChart(finance, "", ChartDonut)
+	if err != nil || chart.Kind != ChartDonut {
+		t.Fatalf("expected the requested chart kind, got %+v (%v)", chart, err)
+	}
+
+	projects := []byte(`{"data": {"total": 5, "by_status": {"active": 3, "archived": 2}}}`)
+	chart, err = ExtractChart(projects, "by_status", "")
+	if err != nil {
+		t.Fatalf("ExtractChart failed: %v", err)
+	}
+	if chart.Kind != ChartDonut || strings.Join(chart.Labels, ",") != "active,archived" {
+		t.Errorf("expected a donut of the statuses, got %+v", chart)
+	}
+
+	if _, err := ExtractChart(projects, "missing", ""); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+	if _, err := ExtractChart([]byte(`{"data": {"month": "2026-03"}}`), "", ""); err != ErrNothingToChart {
+		t.Errorf("expected ErrNothingToChart, got %v", err)
+	}
+}
+
+func TestChart_PNG(t *testing.T) {
+	accent := ParseColor("#10B981", color.RGBA{})
+	if accent != (color.RGBA{0x10, 0xB9, 0x81, 0xFF}) {
+		t.Fatalf("unexpected accent %v", accent)
+	}
+
+	for _, chart := range []Chart{
+		{Kind: ChartBars, Values: []float64{3, -1, 4}},
+		{Kind: ChartDonut, Values: []float64{3, 1, 0}},
+	} {
+		encoded, err := chart.PNG(accent)
+		if err != nil {
+			t.Fatalf("%s: PNG failed: %v", chart.Kind, err)
+		}
+		decoded, err := png.Decode(bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("%s: invalid PNG: %v", chart.Kind, err)
+		}
+		if bounds := decoded.Bounds(); bounds.Dx() != chartWidth || bounds.Dy() != chartHeight {
+			t.Errorf("%s: unexpected size %v", chart.Kind, bounds)
+		}
+	}
+
+	if _, err := (&Chart{Kind: ChartDonut, Values: []float64{0, -2}}).PNG(accent); err != ErrNothingToChart {
+		t.Errorf("expected ErrNothingToChart for a donut without positive values, got %v", err)
+	}
+}
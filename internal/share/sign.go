@@ -0,0 +1,32 @@
+// Package share signs public links to widget snapshots and renders the
+// snapshots shared as images.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"time"
+)
+
+// SecretSize is the length of the signing secret in bytes.
+const SecretSize = 32
+
+// Sign returns the signature of a link to snapshot id that expires at expires.
+func Sign(secret []byte, id string, expires time.Time) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id + "\n" + strconv.FormatInt(expires.Unix(), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature was produced by Sign for id and expires.
+// It does not check whether expires has passed.
+func Verify(secret []byte, id string, expires time.Time, signature string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	expected, _ := base64.RawURLEncoding.DecodeString(Sign(secret, id, expires))
+	return hmac.Equal(decoded, expected)
+}
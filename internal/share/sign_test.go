@@ -0,0 +1,23 @@
+package share
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := bytes.Repeat([]byte{9}, SecretSize)
+	expires := time.Unix(1_800_000_000, 0)
+	signature := Sign(secret, "abc", expires)
+
+	if !Verify(secret, "abc", expires, signature) {
+		t.Fatal("expected the signature to verify")
+	}
+	if Verify(secret, "abd", expires, signature) || Verify(secret, "abc", expires.Add(time.Second), signature) {
+		t.Error("expected the signature to bind the snapshot and expiry")
+	}
+	if Verify(bytes.Repeat([]byte{1}, SecretSize), "abc", expires, signature) || Verify(secret, "abc", expires, "%%") {
+		t.Error("expected other secrets and malformed signatures to be rejected")
+	}
+}
@@ -0,0 +1,211 @@
+// Package undo keeps a short-lived log of destructive admin actions, such as
+// wiping a plugin's settings or purging its data, so they can be reversed for
+// a grace window after the call that made them returned.
+package undo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+// Errors returned by Undo. The HTTP layer maps them to error codes.
+var (
+	ErrNotFound    = errors.New("undo action not found")
+	ErrExpired     = errors.New("the undo window of this action has passed")
+	ErrUndone      = errors.New("action was already undone")
+	ErrConflict    = errors.New("the target has changed since, undoing would overwrite it")
+	ErrUnsupported = errors.New("no handler for this kind of action")
+)
+
+// Handler reverses a recorded action. It returns an error wrapping
+// ErrConflict when the target has changed in a way the undo would destroy.
+type Handler func(action db.UndoAction) error
+
+// Log records reversible actions in the host database. Files an action sets
+// aside are kept under a directory of its own in trashDir, removed with the
+// action when its window passes.
+type Log struct {
+	records  *db.HostDB
+	trashDir string
+	window   time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewLog creates a log whose actions can be undone for window after they
+// were recorded.
+func NewLog(records *db.HostDB, trashDir string, window time.Duration) *Log {
+	return &Log{
+		records:  records,
+		trashDir: trashDir,
+		window:   window,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler that undoes actions of a kind.
+func (l *Log) Handle(kind string, handler Handler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers[kind] = handler
+}
+
+// Record logs an action on target before it is carried out, with the payload
+// its handler needs to reverse it. If the action then fails, the caller
+// discards the record with Discard.
+func (l *Log) Record(kind string, target string, payload interface{}) (*db.UndoAction, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding undo payload: %w", err)
+	}
+
+	id, err := newActionID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	action := db.UndoAction{
+		ID:        id,
+		Kind:      kind,
+		Target:    target,
+		Payload:   string(encoded),
+		CreatedAt: now.Format(time.RFC3339),
+		ExpiresAt: now.Add(l.window).Format(time.RFC3339),
+	}
+	if err := l.records.CreateUndoAction(action); err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// Discard forgets a recorded action that was not carried out, along with any
+// files set aside for it.
+func (l *Log) Discard(id string) {
+	if err := l.records.DeleteUndoAction(id); err != nil && !errors.Is(err, db.ErrNotFound) {
+		slog.Warn("discarding undo action failed", "action", id, "error", err)
+	}
+	if err := os.RemoveAll(l.TrashPath(id)); err != nil {
+		slog.Warn("removing undo trash failed", "action", id, "error", err)
+	}
+}
+
+// TrashPath is the directory where the files an action removes are moved to
+// while it can be undone. It does not exist until the action creates it.
+func (l *Log) TrashPath(id string) string {
+	return filepath.Join(l.trashDir, id)
+}
+
+// List returns the actions that can still be undone, newest first.
+func (l *Log) List() ([]db.UndoAction, error) {
+	return l.records.ListUndoActions(time.Now())
+}
+
+// Undo reverses an action with the handler of its kind. An action is undone
+// at most once; if its handler fails, it can be tried again within the window.
+func (l *Log) Undo(id string) (*db.UndoAction, error) {
+	return l.undo(id, "")
+}
+
+// UndoKind is Undo for actions of one kind only; an action of another kind is
+// reported as not found.
+func (l *Log) UndoKind(id string, kind string) (*db.UndoAction, error) {
+	return l.undo(id, kind)
+}
+
+// undo reverses an action, of any kind when kind is empty.
+func (l *Log) undo(id string, kind string) (*db.UndoAction, error) {
+	action, err := l.records.GetUndoAction(id)
+	if errors.Is(err, db.ErrNotFound) || (err == nil && kind != "" && action.Kind != kind) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.RLock()
+	handler, ok := l.handlers[action.Kind]
+	l.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, action.Kind)
+	}
+
+	claimed, err := l.records.ClaimUndoAction(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		if action.UndoneAt != nil {
+			return nil, ErrUndone
+		}
+		return nil, ErrExpired
+	}
+
+	if err := handler(*action); err != nil {
+		if releaseErr := l.records.ReleaseUndoAction(id); releaseErr != nil {
+			slog.Warn("releasing undo action failed", "action", id, "error", releaseErr)
+		}
+		return nil, err
+	}
+
+	if err := os.RemoveAll(l.TrashPath(id)); err != nil {
+		slog.Warn("removing undo trash failed", "action", id, "error", err)
+	}
+	slog.Info("admin action undone", "action", id, "kind", action.Kind, "target", action.Target)
+	return action, nil
+}
+
+// Prune forgets the actions whose window has passed and deletes the files
+// they set aside, for good.
+func (l *Log) Prune() error {
+	expired, err := l.records.DeleteExpiredUndoActions(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, id := range expired {
+		if err := os.RemoveAll(l.TrashPath(id)); err != nil {
+			return fmt.Errorf("removing undo trash: %w", err)
+		}
+	}
+	return nil
+}
+
+// Start prunes expired actions right away and then every interval, until ctx
+// is cancelled.
+func (l *Log) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.Prune(); err != nil {
+			slog.Warn("pruning undo log failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newActionID returns a random, URL-safe action ID.
+func newActionID() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", fmt.Errorf("generating undo action ID: %w", err)
+	}
+	return hex.EncodeToString(buffer), nil
+}
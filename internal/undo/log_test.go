@@ -0,0 +1,131 @@
+package undo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alvarotorresc/cortex/internal/db"
+)
+
+func newTestLog(t *testing.T, window time.Duration) *Log {
+	t.Helper()
+
+	hostDB, err := db.NewHostDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create host DB: %v", err)
+	}
+	t.Cleanup(func() { hostDB.Close() })
+	return NewLog(hostDB, t.TempDir(), window)
+}
+
+func TestLog_UndoRunsHandlerOnce(t *testing.T) {
+	log := newTestLog(t, time.Minute)
+	undone := make([]string, 0)
+	log.Handle("wipe", func(action db.UndoAction) error {
+		undone = append(undone, action.Target+" "+action.Payload)
+		return nil
+	})
+
+	action, err := log.Record("wipe", "notes", map[string]string{"sort": "title"})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if listed, err := log.List(); err != nil || len(listed) != 1 || listed[0].ID != action.ID {
+		t.Fatalf("expected the action to be listed, got %+v, %v", listed, err)
+	}
+
+	if _, err := log.Undo(action.ID); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if _, err := log.Undo(action.ID); !errors.Is(err, ErrUndone) {
+		t.Errorf("expected ErrUndone undoing twice, got %v", err)
+	}
+	if len(undone) != 1 || undone[0] != `notes {"sort":"title"}` {
+		t.Errorf("expected the handler to run once with the payload, got %v", undone)
+	}
+	if listed, _ := log.List(); len(listed) != 0 {
+		t.Errorf("expected undone actions not to be listed, got %+v", listed)
+	}
+}
+
+func TestLog_FailedUndoCanBeRetried(t *testing.T) {
+	log := newTestLog(t, time.Minute)
+	fail := true
+	log.Handle("wipe", func(action db.UndoAction) error {
+		if fail {
+			return ErrConflict
+		}
+		return nil
+	})
+
+	action, err := log.Record("wipe", "notes", nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := log.Undo(action.ID); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected the handler's error, got %v", err)
+	}
+
+	fail = false
+	if _, err := log.Undo(action.ID); err != nil {
+		t.Errorf("expected the retry to succeed, got %v", err)
+	}
+}
+
+func TestLog_ExpiredActionsArePruned(t *testing.T) {
+	log := newTestLog(t, -time.Second)
+	log.Handle("purge", func(action db.UndoAction) error { return nil })
+
+	action, err := log.Record("purge", "notes", nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(log.TrashPath(action.ID), "data"), 0700); err != nil {
+		t.Fatalf("creating trash: %v", err)
+	}
+
+	if _, err := log.Undo(action.ID); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+
+	if err := log.Prune(); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := os.Stat(log.TrashPath(action.ID)); !os.IsNotExist(err) {
+		t.Errorf("expected the trash of the expired action to be deleted, got %v", err)
+	}
+	if _, err := log.Undo(action.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after pruning, got %v", err)
+	}
+}
+
+func TestLog_UnknownKind(t *testing.T) {
+	log := newTestLog(t, time.Minute)
+
+	action, err := log.Record("unknown", "notes", nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := log.Undo(action.ID); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestLog_UndoKindIgnoresOtherKinds(t *testing.T) {
+	log := newTestLog(t, time.Minute)
+	log.Handle("wipe", func(action db.UndoAction) error { return nil })
+
+	action, err := log.Record("wipe", "notes", nil)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := log.UndoKind(action.ID, "delete"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another kind, got %v", err)
+	}
+	if _, err := log.UndoKind(action.ID, "wipe"); err != nil {
+		t.Errorf("expected the action to be undone, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package sdk
+
+import (
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// MaxAttachmentSize is the largest file PutAttachment accepts.
+const MaxAttachmentSize = cortexplugin.MaxAttachmentSize
+
+// Attachment describes a file stored with the host. Use its ID to reference
+// the file from the plugin's own records.
+type Attachment = cortexplugin.Attachment
+
+// Errors returned by the attachment functions.
+var (
+	ErrAttachmentNotFound = cortexplugin.ErrAttachmentNotFound
+	ErrAttachmentTooLarge = cortexplugin.ErrAttachmentTooLarge
+)
+
+// PutAttachment stores a file with the host, which keeps identical contents
+// only once across all plugins. An empty contentType is detected from the
+// content. The plugin must declare the "attachments" permission.
+func PutAttachment(name string, contentType string, content []byte) (*Attachment, error) {
+	return cortexplugin.PutAttachment(name, contentType, content)
+}
+
+// GetAttachment returns one of the plugin's attachments and its content.
+func GetAttachment(id int64) (*Attachment, []byte, error) {
+	return cortexplugin.GetAttachment(id)
+}
+
+// DeleteAttachment removes one of the plugin's attachments.
+func DeleteAttachment(id int64) error {
+	return cortexplugin.DeleteAttachment(id)
+}
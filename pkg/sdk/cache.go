@@ -0,0 +1,62 @@
+package sdk
+
+import "sync"
+
+// Cache memoizes expensive results, such as report aggregations, in the
+// plugin's memory until Invalidate is called. It is safe for concurrent use.
+//
+//	reports := sdk.NewCache[*Summary](64)
+//	summary, err := reports.Get("summary:"+month, func() (*Summary, error) {
+//		return computeSummary(month)
+//	})
+//
+// Call Invalidate after every write the cached results depend on.
+type Cache[V any] struct {
+	mu         sync.Mutex
+	entries    map[string]V
+	maxEntries int
+	// generation changes on Invalidate, so a result computed while a write
+	// happened is returned but not kept.
+	generation uint64
+}
+
+// NewCache creates a cache holding up to maxEntries results. When it is full
+// the cache is emptied before the next result is stored.
+func NewCache[V any](maxEntries int) *Cache[V] {
+	return &Cache[V]{entries: make(map[string]V), maxEntries: maxEntries}
+}
+
+// Get returns the result cached under key, calling compute and caching what
+// it returns on a miss. Errors are not cached.
+func (c *Cache[V]) Get(key string, compute func() (V, error)) (V, error) {
+	c.mu.Lock()
+	if value, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		return value, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	value, err := compute()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		if len(c.entries) >= c.maxEntries {
+			c.entries = make(map[string]V)
+		}
+		c.entries[key] = value
+	}
+	return value, nil
+}
+
+// Invalidate drops every cached result.
+func (c *Cache[V]) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]V)
+	c.generation++
+}
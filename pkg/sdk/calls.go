@@ -0,0 +1,28 @@
+package sdk
+
+import (
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// CallerHeader names the request header carrying the ID of the plugin that
+// sent a request with CallPlugin, so plugins can tell such requests apart.
+const CallerHeader = cortexplugin.CallerHeader
+
+// Errors returned by CallPlugin.
+var (
+	ErrPermissionDenied  = cortexplugin.ErrPermissionDenied
+	ErrPluginNotFound    = cortexplugin.ErrPluginNotFound
+	ErrPluginUnavailable = cortexplugin.ErrPluginUnavailable
+	ErrPluginTimeout     = cortexplugin.ErrPluginTimeout
+)
+
+// CallPlugin sends a GET request to the API of another plugin, such as
+// CallPlugin("finance-tracker", &sdk.APIRequest{Path: "/reports/summary"}), and
+// returns its response, whatever its status code. The host passes it on
+// like a request from the frontend, so the other plugin needs nothing
+// special to serve it. Pass req.WithContext(ctx) to bound the call by the
+// request being handled. The plugin must declare the "plugins:read"
+// permission.
+func CallPlugin(pluginID string, req *APIRequest) (*APIResponse, error) {
+	return cortexplugin.CallPlugin(pluginID, req)
+}
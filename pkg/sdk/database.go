@@ -0,0 +1,80 @@
+package sdk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+
+	"modernc.org/sqlite"
+
+	"github.com/alvarotorresc/cortex/internal/atrest"
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// ErrEncryptedDatabase is returned by OpenDatabase when the database is
+// encrypted but the host did not pass a key, for example because
+// CORTEX_DB_PASSPHRASE was removed from its configuration.
+var ErrEncryptedDatabase = atrest.ErrEncryptedDatabase
+
+// OpenDatabase opens the SQLite database at path, the one passed to Migrate,
+// with foreign keys enabled. Plugins should open their database with it
+// rather than with sql.Open:
+//
+//	func (p *MyPlugin) Migrate(databasePath string) error {
+//		database, err := sdk.OpenDatabase(databasePath)
+//		...
+//
+// When the host has at-rest encryption enabled, the database is stored
+// encrypted with a key unique to the plugin, and an existing plaintext
+// database is encrypted on first open. Otherwise it is a plain file in WAL
+// mode. Unless the plugin declared db:write, the database turns query-only
+// once Migrate returns, and writes fail. The caller is responsible for
+// closing the database; pending writes of an encrypted database reach the
+// disk when it is closed.
+func OpenDatabase(path string) (*sql.DB, error) {
+	key, err := atrest.KeyFromEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		connector, err := atrest.OpenConnector(path, key)
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(cortexplugin.GuardDatabase(connector)), nil
+	}
+
+	if _, err := os.Stat(atrest.EncryptedPath(path)); err == nil {
+		return nil, ErrEncryptedDatabase
+	}
+
+	// Pragmas in the DSN apply to every pooled connection; foreign_keys set
+	// with Exec would only hold on whichever connection ran it.
+	//
+	// A deferred transaction that reads and then writes cannot upgrade its
+	// lock while another connection is writing, and SQLite fails it with
+	// SQLITE_BUSY without waiting for busy_timeout. _txlock=immediate takes
+	// the write lock at BEGIN instead, so with busy_timeout a write waits up
+	// to five seconds for one running on another connection, such as a
+	// background job's.
+	connector := sqliteConnector(fmt.Sprintf("%s?_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate", path))
+	database := sql.OpenDB(cortexplugin.GuardDatabase(connector))
+	if err := database.Ping(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return database, nil
+}
+
+// sqliteConnector opens SQLite connections to the data source name it holds.
+type sqliteConnector string
+
+func (c sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.Driver().Open(string(c))
+}
+
+func (c sqliteConnector) Driver() driver.Driver {
+	return &sqlite.Driver{}
+}
@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// ErrInvalidData is returned by ImportData, and by ImportTables, when the
+// data is not an export the plugin can import.
+var ErrInvalidData = cortexplugin.ErrInvalidData
+
+// ExportTables reads every row of tables for ExportData, as SnapshotRows
+// reads them. List the tables in the order ImportTables should fill them:
+// the ones other tables refer to first.
+//
+//	func (p *MyPlugin) ExportData() ([]byte, error) {
+//		return sdk.ExportTables(p.db, "lists", "items")
+//	}
+func ExportTables(querier Querier, tables ...string) ([]byte, error) {
+	snapshots := make([]DeletedRows, 0, len(tables))
+	for _, table := range tables {
+		snapshot, err := SnapshotRows(querier, table, "1 = 1")
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling export: %w", err)
+	}
+	return data, nil
+}
+
+// ImportTables replaces, in tx, the rows of tables with data ExportTables
+// returned for the same tables, in the same order. Tables the data lacks,
+// such as ones added after it was exported, keep their rows. Data naming
+// tables outside tables, or rows that break the schema's constraints,
+// returns an error wrapping ErrInvalidData; roll tx back then.
+func ImportTables(tx *sql.Tx, data []byte, tables ...string) error {
+	var snapshots []DeletedRows
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return fmt.Errorf("%w: decoding export: %v", ErrInvalidData, err)
+	}
+
+	imported := make(map[string]DeletedRows, len(snapshots))
+	known := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		known[table] = true
+	}
+	for _, snapshot := range snapshots {
+		if !known[snapshot.Table] {
+			return fmt.Errorf("%w: unknown table %q", ErrInvalidData, snapshot.Table)
+		}
+		if _, repeated := imported[snapshot.Table]; repeated {
+			return fmt.Errorf("%w: table %q appears twice", ErrInvalidData, snapshot.Table)
+		}
+		imported[snapshot.Table] = snapshot
+	}
+
+	// Empty the tables that refer to others first, then fill them in order.
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, ok := imported[tables[i]]; !ok {
+			continue
+		}
+		if !sqlIdentifier.MatchString(tables[i]) {
+			return fmt.Errorf("invalid table name %q", tables[i])
+		}
+		if _, err := tx.Exec("DELETE FROM " + tables[i]); err != nil {
+			return fmt.Errorf("emptying %s: %w", tables[i], err)
+		}
+	}
+	for _, table := range tables {
+		snapshot, ok := imported[table]
+		if !ok {
+			continue
+		}
+		err := RestoreRows(tx, []DeletedRows{snapshot})
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrRestoreConflict), strings.Contains(err.Error(), "constraint failed"), strings.Contains(err.Error(), "has no column"):
+			return fmt.Errorf("%w: %v", ErrInvalidData, err)
+		default:
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package sdk
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// ErrRestoreConflict is returned by RestoreDeleted, and by RestoreRows, when
+// a deleted record's ID or another of its unique values has been taken again.
+var ErrRestoreConflict = cortexplugin.ErrRestoreConflict
+
+// WithDeleted attaches entity, a copy of what a delete removed, to the
+// delete's response. The host keeps it in its undo log instead of sending it
+// to the client and hands it back, JSON-encoded, to RestoreDeleted with kind
+// if the delete is undone:
+//
+//	response, err := sdk.Success(200, map[string]interface{}{"deleted": id})
+//	if err != nil {
+//		return nil, err
+//	}
+//	return sdk.WithDeleted(response, "note", rows)
+//
+// It only takes effect for plugins implementing Restorer.
+func WithDeleted(response *APIResponse, kind string, entity interface{}) (*APIResponse, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling deleted %s: %w", kind, err)
+	}
+	response.Deleted = &cortexplugin.DeletedEntity{Kind: kind, Data: data}
+	return response, nil
+}
+
+// DeletedRows holds rows of one table as they were before a delete, column by
+// column, so RestoreRows can insert them again with their original IDs.
+type DeletedRows struct {
+	Table string                   `json:"table"`
+	Rows  []map[string]interface{} `json:"rows"`
+}
+
+// Querier runs queries; *sql.DB and *sql.Tx both implement it.
+type Querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// sqlIdentifier matches the table and column names RestoreRows accepts.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SnapshotRows reads the rows of table matching where, an SQL condition such
+// as "note_id = ?", before a delete removes them. Run it in the transaction
+// of the delete. BLOB values are kept as text.
+func SnapshotRows(querier Querier, table string, where string, args ...interface{}) (DeletedRows, error) {
+	snapshot := DeletedRows{Table: table, Rows: []map[string]interface{}{}}
+	if !sqlIdentifier.MatchString(table) {
+		return snapshot, fmt.Errorf("invalid table name %q", table)
+	}
+
+	rows, err := querier.Query("SELECT * FROM "+table+" WHERE "+where, args...)
+	if err != nil {
+		return snapshot, fmt.Errorf("reading %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return snapshot, fmt.Errorf("reading %s columns: %w", table, err)
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return snapshot, fmt.Errorf("scanning %s: %w", table, err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if raw, ok := values[i].([]byte); ok {
+				values[i] = string(raw)
+			}
+			row[column] = values[i]
+		}
+		snapshot.Rows = append(snapshot.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return snapshot, fmt.Errorf("iterating %s: %w", table, err)
+	}
+	return snapshot, nil
+}
+
+// RestoreRows inserts snapshots taken by SnapshotRows back in tx, in order,
+// so the deleted records go first and the rows that refer to them after. The
+// first snapshot must go back whole: a row whose ID or unique value has been
+// taken again returns an error wrapping ErrRestoreConflict. Rows of the later
+// snapshots that refer to records deleted since, such as a tag, are skipped.
+func RestoreRows(tx *sql.Tx, snapshots []DeletedRows) error {
+	for index, snapshot := range snapshots {
+		if !sqlIdentifier.MatchString(snapshot.Table) {
+			return fmt.Errorf("invalid table name %q", snapshot.Table)
+		}
+		for _, row := range snapshot.Rows {
+			columns := make([]string, 0, len(row))
+			for column := range row {
+				if !sqlIdentifier.MatchString(column) {
+					return fmt.Errorf("invalid column name %q in %s", column, snapshot.Table)
+				}
+				columns = append(columns, column)
+			}
+			if len(columns) == 0 {
+				continue
+			}
+			sort.Strings(columns)
+
+			values := make([]interface{}, len(columns))
+			for i, column := range columns {
+				values[i] = restoredValue(row[column])
+			}
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+			query := "INSERT INTO " + snapshot.Table + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders + ")"
+
+			_, err := tx.Exec(query, values...)
+			switch {
+			case err == nil:
+			case strings.Contains(err.Error(), "UNIQUE constraint failed"):
+				return fmt.Errorf("%w: %s: %v", ErrRestoreConflict, snapshot.Table, err)
+			case strings.Contains(err.Error(), "FOREIGN KEY constraint failed") && index > 0:
+				// SQLite only undid the failed statement; the rest goes on
+			case strings.Contains(err.Error(), "FOREIGN KEY constraint failed"):
+				return fmt.Errorf("%w: %s refers to records deleted since", ErrRestoreConflict, snapshot.Table)
+			default:
+				return fmt.Errorf("restoring %s: %w", snapshot.Table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// restoredValue turns a value decoded from JSON back into one SQLite stores
+// like the original: whole numbers become integers again.
+func restoredValue(value interface{}) interface{} {
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) || math.Abs(number) > 1<<53 {
+		return value
+	}
+	return int64(number)
+}
+
+// DecodeDeletedRows decodes the data RestoreDeleted receives for a delete
+// that attached []DeletedRows with WithDeleted.
+func DecodeDeletedRows(data []byte) ([]DeletedRows, error) {
+	var snapshots []DeletedRows
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, fmt.Errorf("decoding deleted rows: %w", err)
+	}
+	if len(snapshots) == 0 {
+		return nil, errors.New("no deleted rows to restore")
+	}
+	return snapshots, nil
+}
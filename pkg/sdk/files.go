@@ -0,0 +1,28 @@
+package sdk
+
+import (
+	"mime"
+
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// MaxAPIBodySize is the largest request or response body the host passes to
+// and from a plugin.
+const MaxAPIBodySize = cortexplugin.MaxAPIBodySize
+
+// ErrNotMultipart is returned by APIRequest.MultipartForm for requests that
+// are not multipart/form-data.
+var ErrNotMultipart = cortexplugin.ErrNotMultipart
+
+// FileResponse returns content as a download named filename, such as a CSV
+// export or a PDF. Browsers save it instead of displaying it.
+func FileResponse(contentType string, filename string, content []byte) *APIResponse {
+	return &APIResponse{
+		StatusCode:  200,
+		Body:        content,
+		ContentType: contentType,
+		Headers: map[string]string{
+			"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": filename}),
+		},
+	}
+}
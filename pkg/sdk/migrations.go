@@ -0,0 +1,39 @@
+package sdk
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// EmbeddedMigrations returns the .sql files in dir of fsys, in name order,
+// which is the order plugins apply them in. It is meant for MigrationLister:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	func (p *MyPlugin) Migrations() ([]sdk.MigrationFile, error) {
+//		return sdk.EmbeddedMigrations(migrations, "migrations")
+//	}
+func EmbeddedMigrations(fsys fs.FS, dir string) ([]MigrationFile, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations dir: %w", err)
+	}
+
+	files := make([]MigrationFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading migration %s: %w", entry.Name(), err)
+		}
+		files = append(files, MigrationFile{Name: entry.Name(), SQL: string(content)})
+	}
+
+	return files, nil
+}
@@ -0,0 +1,14 @@
+package sdk
+
+import (
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// SendNotification adds a notification to the host's notification center,
+// with the plugin as its source. Urgent notifications are pushed right away to
+// the channels the user configured (webhook, ntfy, Gotify, email); the rest are
+// batched into the digest. The plugin must declare the "notifications"
+// permission.
+func SendNotification(title string, body string, urgent bool) error {
+	return cortexplugin.SendNotification(title, body, urgent)
+}
@@ -0,0 +1,103 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Envelope is the body of a successful plugin API response: {"data": ...}.
+type Envelope[T any] struct {
+	Data T `json:"data"`
+}
+
+// PaginatedEnvelope is the body of one page of a list. Cursor is passed back
+// to fetch the next page while HasMore is true.
+type PaginatedEnvelope[T any] struct {
+	Data    []T    `json:"data"`
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"has_more"`
+}
+
+// ErrorEnvelope is the body of a failed plugin API response:
+// {"error": {"code": ..., "message": ...}}.
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody is the machine-readable code and human-readable message of an
+// error response.
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AppError is an error with the code and HTTP status it is reported with.
+// Services return it so handlers can turn any failure into a consistent
+// response with its Response method.
+type AppError struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+// Error implements the error interface.
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+// Response returns the error response for e.
+func (e *AppError) Response() (*APIResponse, error) {
+	return Error(e.StatusCode, e.Code, e.Message)
+}
+
+// NewAppError creates an AppError with explicit code, message, and HTTP status.
+func NewAppError(code string, message string, statusCode int) *AppError {
+	return &AppError{Code: code, Message: message, StatusCode: statusCode}
+}
+
+// NewValidationError creates a 400 validation error.
+func NewValidationError(message string) *AppError {
+	return NewAppError("VALIDATION_ERROR", message, 400)
+}
+
+// NewNotFoundError creates a 404 not-found error for a specific resource.
+func NewNotFoundError(resource string, id string) *AppError {
+	return NewAppError("NOT_FOUND", fmt.Sprintf("%s %s not found", resource, id), 404)
+}
+
+// NewConflictError creates a 409 conflict error.
+func NewConflictError(message string) *AppError {
+	return NewAppError("CONFLICT", message, 409)
+}
+
+// Success returns data wrapped in {"data": ...} with the given status.
+func Success(status int, data interface{}) (*APIResponse, error) {
+	return jsonResponse(status, Envelope[interface{}]{Data: data})
+}
+
+// Paginated returns one page of a list with the cursor of the next one. A nil
+// page is sent as an empty list.
+func Paginated[T any](items []T, cursor string, hasMore bool) (*APIResponse, error) {
+	if items == nil {
+		items = []T{}
+	}
+	return jsonResponse(200, PaginatedEnvelope[T]{Data: items, Cursor: cursor, HasMore: hasMore})
+}
+
+// Error returns {"error": {"code": ..., "message": ...}} with the given status.
+func Error(status int, code string, message string) (*APIResponse, error) {
+	return jsonResponse(status, ErrorEnvelope{Error: ErrorBody{Code: code, Message: message}})
+}
+
+// jsonResponse returns body encoded as a JSON response.
+func jsonResponse(status int, body interface{}) (*APIResponse, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling response: %w", err)
+	}
+	return &APIResponse{
+		StatusCode:  status,
+		Body:        encoded,
+		ContentType: "application/json",
+	}, nil
+}
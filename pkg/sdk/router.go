@@ -0,0 +1,212 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HandlerFunc handles a request matched by a Router. The context of the
+// HandleAPI call is req.Context().
+type HandlerFunc func(req *APIRequest) (*APIResponse, error)
+
+// Router matches API requests to handlers by method and path, so HandleAPI
+// does not have to compare prefixes by hand:
+//
+//	router := sdk.NewRouter()
+//	router.Get("/transactions", p.listTransactions)
+//	router.Put("/transactions/{id}", p.updateTransaction)
+//
+//	func (p *MyPlugin) HandleAPI(ctx context.Context, req *sdk.APIRequest) (*sdk.APIResponse, error) {
+//		return router.HandleAPI(ctx, req)
+//	}
+//
+// A {name} segment matches any single non-empty segment, available to the
+// handler as req.PathParams[name]; a literal segment takes precedence over a
+// parameter, so "/projects/graph" wins over "/projects/{id}". Paths no route
+// matches get a 404 NOT_FOUND, and paths matched only with other methods a
+// 405 METHOD_NOT_ALLOWED listing them in the Allow header. A Router is safe
+// for concurrent use once its routes are registered.
+type Router struct {
+	routes []route
+}
+
+// route is one registered method and pattern.
+type route struct {
+	method   string
+	segments []string
+	handler  HandlerFunc
+}
+
+// NewRouter creates a router without routes.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Handle registers handler for method and pattern. It panics on a malformed
+// pattern or one registered twice for the same method, since both are
+// programming errors.
+func (r *Router) Handle(method string, pattern string, handler HandlerFunc) {
+	if !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("sdk: route pattern %q must start with /", pattern))
+	}
+
+	segments := splitPath(pattern)
+	params := make(map[string]bool)
+	for _, segment := range segments {
+		name, ok := paramName(segment)
+		if !ok {
+			if strings.ContainsAny(segment, "{}") {
+				panic(fmt.Sprintf("sdk: route pattern %q has a malformed parameter %q", pattern, segment))
+			}
+			continue
+		}
+		if name == "" || params[name] {
+			panic(fmt.Sprintf("sdk: route pattern %q has an empty or repeated parameter", pattern))
+		}
+		params[name] = true
+	}
+
+	method = strings.ToUpper(method)
+	for _, existing := range r.routes {
+		if existing.method == method && samePattern(existing.segments, segments) {
+			panic(fmt.Sprintf("sdk: route %s %s is registered twice", method, pattern))
+		}
+	}
+	r.routes = append(r.routes, route{method: method, segments: segments, handler: handler})
+}
+
+// Get registers handler for GET requests to pattern.
+func (r *Router) Get(pattern string, handler HandlerFunc) { r.Handle("GET", pattern, handler) }
+
+// Post registers handler for POST requests to pattern.
+func (r *Router) Post(pattern string, handler HandlerFunc) { r.Handle("POST", pattern, handler) }
+
+// Put registers handler for PUT requests to pattern.
+func (r *Router) Put(pattern string, handler HandlerFunc) { r.Handle("PUT", pattern, handler) }
+
+// Patch registers handler for PATCH requests to pattern.
+func (r *Router) Patch(pattern string, handler HandlerFunc) { r.Handle("PATCH", pattern, handler) }
+
+// Delete registers handler for DELETE requests to pattern.
+func (r *Router) Delete(pattern string, handler HandlerFunc) { r.Handle("DELETE", pattern, handler) }
+
+// HandleAPI calls the handler of the most specific route matching req, with
+// req.PathParams and req.Context() set, or answers 404 or 405.
+func (r *Router) HandleAPI(ctx context.Context, req *APIRequest) (*APIResponse, error) {
+	path := splitPath(req.Path)
+	method := strings.ToUpper(req.Method)
+
+	var best *route
+	var bestParams map[string]string
+	allowed := make(map[string]bool)
+	for i := range r.routes {
+		candidate := &r.routes[i]
+		params, ok := candidate.match(path)
+		if !ok {
+			continue
+		}
+		if candidate.method != method {
+			allowed[candidate.method] = true
+			continue
+		}
+		if best == nil || moreSpecific(candidate.segments, best.segments) {
+			best, bestParams = candidate, params
+		}
+	}
+
+	if best == nil {
+		if len(allowed) == 0 {
+			return Error(404, "NOT_FOUND", "route not found")
+		}
+		methods := make([]string, 0, len(allowed))
+		for allowedMethod := range allowed {
+			methods = append(methods, allowedMethod)
+		}
+		sort.Strings(methods)
+		response, err := Error(405, "METHOD_NOT_ALLOWED", "method not allowed")
+		if err != nil {
+			return nil, err
+		}
+		response.Headers = map[string]string{"Allow": strings.Join(methods, ", ")}
+		return response, nil
+	}
+
+	matched := req.WithContext(ctx)
+	matched.PathParams = bestParams
+	return best.handler(matched)
+}
+
+// match reports whether path fits the route's pattern and returns the values
+// of its parameters.
+func (rt *route) match(path []string) (map[string]string, bool) {
+	if len(path) != len(rt.segments) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, segment := range rt.segments {
+		if name, ok := paramName(segment); ok {
+			if path[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = path[i]
+			continue
+		}
+		if segment != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// moreSpecific reports whether pattern a should win over pattern b when both
+// match a path: at the first position where they differ, a has a literal
+// segment and b a parameter.
+func moreSpecific(a []string, b []string) bool {
+	for i := range a {
+		_, aParam := paramName(a[i])
+		_, bParam := paramName(b[i])
+		if aParam != bParam {
+			return bParam
+		}
+	}
+	return false
+}
+
+// samePattern reports whether two patterns match the same paths.
+func samePattern(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		_, aParam := paramName(a[i])
+		_, bParam := paramName(b[i])
+		if aParam != bParam || (!aParam && a[i] != b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath returns the segments of a path. "/" has none, and a trailing
+// slash leaves an empty last segment that only a pattern ending in a slash matches.
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// paramName returns the name of a {name} segment.
+func paramName(segment string) (string, bool) {
+	if len(segment) < 2 || segment[0] != '{' || segment[len(segment)-1] != '}' {
+		return "", false
+	}
+	return segment[1 : len(segment)-1], true
+}
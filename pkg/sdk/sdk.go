@@ -25,13 +25,101 @@ type (
 	// Manifest represents a plugin's metadata.
 	Manifest = cortexplugin.Manifest
 
+	// Widget is a dashboard widget a plugin declares in its manifest's
+	// widgets, answered by GetWidgetData for its slot.
+	Widget = cortexplugin.Widget
+
 	// APIRequest represents an incoming API request routed to a plugin.
 	APIRequest = cortexplugin.APIRequest
 
 	// APIResponse represents a plugin's response to an API request.
 	APIResponse = cortexplugin.APIResponse
+
+	// SettingsMigrator is an optional interface for plugins that change their
+	// settings schema between versions. Implement it to upgrade stored settings
+	// on load instead of having them reset to defaults.
+	SettingsMigrator = cortexplugin.SettingsMigrator
+
+	// Searcher is an optional interface for plugins with searchable data.
+	// Implement it to have the plugin's records appear in the host's global
+	// search (GET /api/search).
+	Searcher = cortexplugin.Searcher
+
+	// SearchResult is one record a plugin returns from Search.
+	SearchResult = cortexplugin.SearchResult
+
+	// MigrationLister is an optional interface for plugins with SQL
+	// migrations. Implement it, usually with EmbeddedMigrations, to have the
+	// host check them for unsafe statements before Migrate runs.
+	MigrationLister = cortexplugin.MigrationLister
+
+	// MigrationFile is one SQL migration returned by Migrations.
+	MigrationFile = cortexplugin.MigrationFile
+
+	// Upgrader is an optional interface for plugins with one-off work after
+	// an upgrade. Implement it to have the host call Upgrade with the
+	// previous version the first time a new version loads, after Migrate.
+	Upgrader = cortexplugin.Upgrader
+
+	// Warmer is an optional interface for plugins that prime caches, prepare
+	// statements or check their data before serving. Implement it to have the
+	// host call Warmup after every load and reload.
+	Warmer = cortexplugin.Warmer
+
+	// DemoSeeder is an optional interface for plugins that can produce sample
+	// data. In demo mode (CORTEX_DEMO=true) the host calls SeedDemo once,
+	// after the plugin's migrations.
+	DemoSeeder = cortexplugin.DemoSeeder
+
+	// Syncer is an optional interface for plugins whose records can be
+	// edited offline. Implement it to serve the host's uniform sync endpoint
+	// (GET and POST /api/plugins/{id}/sync).
+	Syncer = cortexplugin.Syncer
+
+	// SyncRecord is a changed record, or a tombstone, returned by SyncPull.
+	SyncRecord = cortexplugin.SyncRecord
+
+	// SyncPage is a page of changed records and the cursor to pull next.
+	SyncPage = cortexplugin.SyncPage
+
+	// SyncChange is a change made by an offline client, passed to SyncPush.
+	SyncChange = cortexplugin.SyncChange
+
+	// SyncResult is the outcome of one SyncChange.
+	SyncResult = cortexplugin.SyncResult
+
+	// SettingsListener is an optional interface for plugins that apply
+	// settings changes without a reload. Implement it to have the host call
+	// OnSettingsChanged with the new settings JSON every time they are saved,
+	// wiped or restored.
+	SettingsListener = cortexplugin.SettingsListener
+
+	// Restorer is an optional interface for plugins whose deletes can be
+	// undone. Implement it, and attach what a delete removed to its response
+	// with WithDeleted, to have the host offer POST /api/undo/{token}.
+	Restorer = cortexplugin.Restorer
+
+	// DataPorter is an optional interface for plugins whose data can move
+	// between instances. Implement it, usually with ExportTables and
+	// ImportTables, to serve GET and POST /api/plugins/{id}/export and import.
+	DataPorter = cortexplugin.DataPorter
+
+	// HostUser is an optional interface for plugins that use the host's
+	// services. Implement it to receive a HostServices handle once the host
+	// has connected, before Migrate.
+	HostUser = cortexplugin.HostUser
+
+	// HostServices is a plugin's handle on the host: a key-value store,
+	// notifications, jobs the host runs on a schedule and the host's log,
+	// all over the plugin's existing connection.
+	HostServices = cortexplugin.HostServices
 )
 
+// ProtocolVersion is the plugin API version this SDK speaks. The host
+// records it for every plugin and lists it in GET /api/plugins; a host that
+// no longer speaks it refuses to load the plugin until it is rebuilt.
+const ProtocolVersion = cortexplugin.SDKProtocolVersion
+
 // Serve starts the plugin subprocess and serves over gRPC.
 // This function blocks until the host process disconnects.
 // Plugin authors call this as the only line in main():
@@ -40,9 +128,17 @@ type (
 func Serve(impl CortexPlugin) {
 	goplugin.Serve(&goplugin.ServeConfig{
 		HandshakeConfig: cortexplugin.Handshake,
-		Plugins: map[string]goplugin.Plugin{
-			"cortex_plugin": &cortexplugin.CortexGRPCPlugin{Impl: impl},
+		VersionedPlugins: map[int]goplugin.PluginSet{
+			ProtocolVersion: {"cortex_plugin": &cortexplugin.CortexGRPCPlugin{Impl: impl}},
 		},
-		GRPCServer: goplugin.DefaultGRPCServer,
+		GRPCServer: cortexplugin.GRPCServer,
 	})
 }
+
+// NotifyChanged tells the host that the plugin's data for topic changed, such
+// as "transactions" after an import. Dashboards subscribed to /api/ws receive
+// the event and refresh the plugin's widgets instead of polling. It returns an
+// error if called before the host has connected, i.e. outside a plugin call.
+func NotifyChanged(topic string) error {
+	return cortexplugin.NotifyChanged(topic)
+}
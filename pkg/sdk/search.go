@@ -0,0 +1,43 @@
+package sdk
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// MaxSearchResults is how many results of a plugin's Search the host ranks.
+// Plugins should stop querying once they have this many.
+const MaxSearchResults = cortexplugin.MaxSearchResults
+
+// snippetLength is the rune length SearchSnippet aims for.
+const snippetLength = 160
+
+// SearchSnippet returns an excerpt of text for a search result: whitespace is
+// collapsed and, for long text, the excerpt is centred on the first
+// case-insensitive occurrence of query, with "…" marking cut ends.
+func SearchSnippet(text string, query string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= snippetLength {
+		return text
+	}
+
+	start := 0
+	if index := strings.Index(strings.ToLower(text), strings.ToLower(strings.TrimSpace(query))); index > 0 {
+		// Lowercasing can change byte lengths; count runes on the original text.
+		start = utf8.RuneCountInString(text[:min(index, len(text))]) - snippetLength/4
+	}
+	start = max(0, min(start, len(runes)-snippetLength))
+	end := start + snippetLength
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
@@ -0,0 +1,13 @@
+package sdk
+
+import (
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// Secret returns the value of a secret from the host's secret store, such as
+// an API token. The plugin receives the secrets its settings refer to with
+// {"$secret": "name"} when it is loaded, so after changing those settings the
+// plugin has to be reloaded. ok is false for secrets it was not given.
+func Secret(name string) (value string, ok bool) {
+	return cortexplugin.SecretFromEnvironment(name)
+}
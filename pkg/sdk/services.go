@@ -0,0 +1,17 @@
+package sdk
+
+import (
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+const (
+	// MaxValueSize caps one value in the key-value store of HostServices.
+	MaxValueSize = cortexplugin.MaxValueSize
+
+	// MinJobInterval is the shortest interval HostServices.Schedule accepts.
+	MinJobInterval = cortexplugin.MinJobInterval
+)
+
+// ErrValueTooLarge is returned by HostServices.SetValue for values larger
+// than MaxValueSize.
+var ErrValueTooLarge = cortexplugin.ErrValueTooLarge
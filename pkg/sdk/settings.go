@@ -0,0 +1,20 @@
+package sdk
+
+import (
+	cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+)
+
+// GetSettings decodes the settings saved for the plugin from the dashboard
+// (PUT /api/plugins/{id}/settings) into into, usually a pointer to a struct.
+// A plugin without saved settings gets an empty object, so fields keep the
+// defaults they had. Call it in Migrate, after the host has connected, and
+// implement SettingsListener to pick up later changes:
+//
+//	func (p *MyPlugin) Migrate(databasePath string) error {
+//		_ = sdk.GetSettings(&p.settings) // keeps the defaults without a host
+//		...
+//
+// Secret values stay {"$secret": name} references; read them with Secret.
+func GetSettings(into interface{}) error {
+	return cortexplugin.GetSettings(into)
+}
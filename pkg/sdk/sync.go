@@ -0,0 +1,14 @@
+package sdk
+
+import cortexplugin "github.com/alvarotorresc/cortex/internal/plugin"
+
+// Statuses of a SyncResult.
+const (
+	// SyncApplied means the change was saved.
+	SyncApplied = cortexplugin.SyncApplied
+	// SyncConflict means the record changed since the client's BaseVersion;
+	// set Current to the plugin's copy so the client can merge.
+	SyncConflict = cortexplugin.SyncConflict
+	// SyncRejected means the change was invalid; set Message to say why.
+	SyncRejected = cortexplugin.SyncRejected
+)
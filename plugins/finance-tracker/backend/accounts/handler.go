@@ -4,21 +4,47 @@ import (
 	"database/sql"
 	"encoding/json"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/alvarotorresc/cortex/pkg/sdk"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
 
+// DefaultCurrency is the currency of accounts created without one, until the
+// plugin's default_currency setting says otherwise.
+const DefaultCurrency = "EUR"
+
 // Handler routes account-related API requests to the appropriate service method.
 type Handler struct {
 	service *Service
+
+	mu              sync.RWMutex
+	defaultCurrency string
 }
 
 // NewHandler creates a Handler with all layers wired together.
 func NewHandler(db *sql.DB) *Handler {
 	repo := NewRepository(db)
 	svc := NewService(repo)
-	return &Handler{service: svc}
+	return &Handler{service: svc, defaultCurrency: DefaultCurrency}
+}
+
+// SetDefaultCurrency sets the currency of accounts saved without one.
+func (h *Handler) SetDefaultCurrency(currency string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.defaultCurrency = currency
+}
+
+// currency returns the currency of an account saved with requested.
+func (h *Handler) currency(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.defaultCurrency
 }
 
 // Handle dispatches the request to the correct handler based on method and path.
@@ -34,6 +60,8 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return h.archive(req)
 	case req.Method == "GET" && strings.HasSuffix(req.Path, "/balance"):
 		return h.getBalance(req)
+	case req.Method == "GET" && strings.HasSuffix(req.Path, "/history"):
+		return h.getHistory(req)
 	default:
 		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
 	}
@@ -53,9 +81,7 @@ func (h *Handler) create(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
 	}
 
-	if input.Currency == "" {
-		input.Currency = "EUR"
-	}
+	input.Currency = h.currency(input.Currency)
 
 	id, appErr := h.service.Create(&input)
 	if appErr != nil {
@@ -76,9 +102,7 @@ func (h *Handler) update(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
 	}
 
-	if input.Currency == "" {
-		input.Currency = "EUR"
-	}
+	input.Currency = h.currency(input.Currency)
 
 	if appErr := h.service.Update(id, &input); appErr != nil {
 		return shared.JSONError(appErr)
@@ -114,3 +138,18 @@ func (h *Handler) getBalance(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 
 	return shared.JSONSuccess(200, result)
 }
+
+func (h *Handler) getHistory(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Path: /accounts/{id}/history?granularity=month&from=&to=
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	history, appErr := h.service.History(id, req.Query["granularity"], req.Query["from"], req.Query["to"], time.Now())
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(200, history)
+}
@@ -2,42 +2,76 @@ package accounts
 
 // Account represents a financial account record.
 type Account struct {
-	ID           int64    `json:"id"`
-	Name         string   `json:"name"`
-	Type         string   `json:"type"`
-	Currency     string   `json:"currency"`
-	InterestRate *float64 `json:"interest_rate,omitempty"`
-	Icon         string   `json:"icon"`
-	Color        string   `json:"color"`
-	IsArchived   bool     `json:"is_archived"`
-	CreatedAt    string   `json:"created_at"`
-}
-
-// AccountWithBalance extends Account with a computed balance from transactions.
+	ID             int64    `json:"id"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Currency       string   `json:"currency"`
+	InterestRate   *float64 `json:"interest_rate,omitempty"`
+	OpeningBalance float64  `json:"opening_balance"`
+	Icon           string   `json:"icon"`
+	Color          string   `json:"color"`
+	IsArchived     bool     `json:"is_archived"`
+	CreatedAt      string   `json:"created_at"`
+}
+
+// AccountWithBalance extends Account with computed balances from transactions.
+// Balance is the current balance, pending transactions included, and
+// ClearedBalance counts only the cleared ones.
 type AccountWithBalance struct {
 	Account
 	Balance           float64  `json:"balance"`
+	ClearedBalance    float64  `json:"cleared_balance"`
 	EstimatedInterest *float64 `json:"estimated_interest,omitempty"`
 }
 
 // CreateAccountInput holds the validated input for creating an account.
 type CreateAccountInput struct {
-	Name         string   `json:"name"`
-	Type         string   `json:"type"`
-	Currency     string   `json:"currency"`
-	InterestRate *float64 `json:"interest_rate"`
-	Icon         string   `json:"icon"`
-	Color        string   `json:"color"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Currency       string   `json:"currency"`
+	InterestRate   *float64 `json:"interest_rate"`
+	OpeningBalance float64  `json:"opening_balance"`
+	Icon           string   `json:"icon"`
+	Color          string   `json:"color"`
 }
 
-// UpdateAccountInput holds the validated input for updating an account.
+// UpdateAccountInput holds the validated input for updating an account. A
+// missing opening_balance keeps the account's current one.
 type UpdateAccountInput struct {
-	Name         string   `json:"name"`
-	Type         string   `json:"type"`
-	Currency     string   `json:"currency"`
-	InterestRate *float64 `json:"interest_rate"`
-	Icon         string   `json:"icon"`
-	Color        string   `json:"color"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Currency       string   `json:"currency"`
+	InterestRate   *float64 `json:"interest_rate"`
+	OpeningBalance *float64 `json:"opening_balance"`
+	Icon           string   `json:"icon"`
+	Color          string   `json:"color"`
+}
+
+// Balance history granularities accepted by GET /accounts/{id}/history.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// MaxHistoryPoints caps how many periods one balance history returns.
+const MaxHistoryPoints = 1000
+
+// HistoryPoint is an account's balance at the end of one period. Period is
+// the day ("2026-02-03"), the Monday starting the week or the month
+// ("2026-02"), and Change is how much the period's transactions moved it.
+type HistoryPoint struct {
+	Period  string  `json:"period"`
+	Balance float64 `json:"balance"`
+	Change  float64 `json:"change"`
+}
+
+// BalanceHistory is the running balance of an account over time.
+type BalanceHistory struct {
+	AccountID      int64          `json:"account_id"`
+	Granularity    string         `json:"granularity"`
+	OpeningBalance float64        `json:"opening_balance"`
+	Points         []HistoryPoint `json:"points"`
 }
 
 // validAccountTypes defines the allowed account type values.
@@ -20,7 +20,7 @@ func NewRepository(db *sql.DB) *Repository {
 // ListActive returns all non-archived accounts ordered by id.
 func (r *Repository) ListActive() ([]Account, error) {
 	rows, err := r.db.Query(
-		`SELECT id, name, type, currency, interest_rate, icon, color, is_archived, created_at
+		`SELECT id, name, type, currency, interest_rate, opening_balance, icon, color, is_archived, created_at
 		 FROM accounts
 		 WHERE is_archived = 0
 		 ORDER BY id`,
@@ -41,11 +41,11 @@ func (r *Repository) GetByID(id int64) (*Account, *shared.AppError) {
 	var icon, color sql.NullString
 
 	err := r.db.QueryRow(
-		`SELECT id, name, type, currency, interest_rate, icon, color, is_archived, created_at
+		`SELECT id, name, type, currency, interest_rate, opening_balance, icon, color, is_archived, created_at
 		 FROM accounts WHERE id = ?`, id,
 	).Scan(
 		&account.ID, &account.Name, &account.Type, &account.Currency,
-		&interestRate, &icon, &color, &isArchived, &account.CreatedAt,
+		&interestRate, &account.OpeningBalance, &icon, &color, &isArchived, &account.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, shared.NewNotFoundError("account", fmt.Sprintf("%d", id))
@@ -75,9 +75,9 @@ func (r *Repository) Create(input *CreateAccountInput) (int64, error) {
 	}
 
 	result, err := r.db.Exec(
-		`INSERT INTO accounts (name, type, currency, interest_rate, icon, color)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
-		input.Name, input.Type, input.Currency, interestRate, input.Icon, input.Color,
+		`INSERT INTO accounts (name, type, currency, interest_rate, opening_balance, icon, color)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		input.Name, input.Type, input.Currency, interestRate, input.OpeningBalance, input.Icon, input.Color,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("inserting account: %w", err)
@@ -98,9 +98,10 @@ func (r *Repository) Update(id int64, input *UpdateAccountInput) error {
 	}
 
 	result, err := r.db.Exec(
-		`UPDATE accounts SET name = ?, type = ?, currency = ?, interest_rate = ?, icon = ?, color = ?
+		`UPDATE accounts SET name = ?, type = ?, currency = ?, interest_rate = ?,
+		 opening_balance = COALESCE(?, opening_balance), icon = ?, color = ?
 		 WHERE id = ?`,
-		input.Name, input.Type, input.Currency, interestRate, input.Icon, input.Color, id,
+		input.Name, input.Type, input.Currency, interestRate, input.OpeningBalance, input.Icon, input.Color, id,
 	)
 	if err != nil {
 		return fmt.Errorf("updating account: %w", err)
@@ -129,35 +130,77 @@ func (r *Repository) Archive(id int64) error {
 	return nil
 }
 
-// CalculateBalance computes the net balance for an account from all its
-// transactions, considering income, expense, and transfer types.
-func (r *Repository) CalculateBalance(accountID int64) (float64, error) {
-	var balance float64
+// CalculateBalance computes the current and cleared balances of an account
+// from its opening balance and double-entry movements, archived ones
+// included: income credits it, expenses debit it, and a transfer debits its
+// source and credits its destination. The current balance includes pending
+// transactions; void ones count in neither.
+func (r *Repository) CalculateBalance(accountID int64) (float64, float64, error) {
+	var balance, cleared float64
 	err := r.db.QueryRow(
-		`SELECT
-			COALESCE(SUM(
-				CASE
-					WHEN type='income' AND account_id=? THEN amount
-					WHEN type='transfer' AND dest_account_id=? THEN amount
-					ELSE 0
-				END
-			), 0) -
-			COALESCE(SUM(
-				CASE
-					WHEN type='expense' AND account_id=? THEN amount
-					WHEN type='transfer' AND account_id=? THEN amount
-					ELSE 0
-				END
-			), 0)
-		 FROM transactions
-		 WHERE account_id = ? OR dest_account_id = ?`,
-		accountID, accountID, accountID, accountID, accountID, accountID,
-	).Scan(&balance)
+		`SELECT a.opening_balance + COALESCE(SUM(e.amount), 0),
+		        a.opening_balance + COALESCE(SUM(CASE WHEN e.status = 'cleared' THEN e.amount ELSE 0 END), 0)
+		 FROM accounts a
+		 LEFT JOIN all_account_entries e ON e.account_id = a.id
+		 WHERE a.id = ?
+		 GROUP BY a.id`,
+		accountID,
+	).Scan(&balance, &cleared)
 	if err != nil {
-		return 0, fmt.Errorf("calculating balance for account %d: %w", accountID, err)
+		return 0, 0, fmt.Errorf("calculating balance for account %d: %w", accountID, err)
 	}
 
-	return balance, nil
+	return balance, cleared, nil
+}
+
+// periodKeys are the SQL expressions grouping an entry's date by granularity.
+// A week starts on the Monday on or before the date.
+var periodKeys = map[string]string{
+	GranularityDay:   "date",
+	GranularityWeek:  "date(date, '-6 days', 'weekday 1')",
+	GranularityMonth: "substr(date, 1, 7)",
+}
+
+// periodChange is the net movement of an account in one period.
+type periodChange struct {
+	period string
+	amount float64
+}
+
+// ListBalanceChanges returns the net movement of an account per period of
+// the given granularity, archived transactions included, oldest first.
+// Periods without transactions are left out.
+func (r *Repository) ListBalanceChanges(accountID int64, granularity string) ([]periodChange, error) {
+	key, ok := periodKeys[granularity]
+	if !ok {
+		return nil, fmt.Errorf("unknown granularity %q", granularity)
+	}
+
+	rows, err := r.db.Query(
+		`SELECT `+key+` AS period, SUM(amount)
+		 FROM all_account_entries
+		 WHERE account_id = ?
+		 GROUP BY period
+		 ORDER BY period`,
+		accountID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying balance changes for account %d: %w", accountID, err)
+	}
+	defer rows.Close()
+
+	changes := make([]periodChange, 0)
+	for rows.Next() {
+		var change periodChange
+		if err := rows.Scan(&change.period, &change.amount); err != nil {
+			return nil, fmt.Errorf("scanning balance change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating balance changes: %w", err)
+	}
+	return changes, nil
 }
 
 // scanAccounts reads all rows from the result set into a slice of Account.
@@ -171,7 +214,7 @@ func scanAccounts(rows *sql.Rows) ([]Account, error) {
 
 		if err := rows.Scan(
 			&a.ID, &a.Name, &a.Type, &a.Currency,
-			&interestRate, &icon, &color, &isArchived, &a.CreatedAt,
+			&interestRate, &a.OpeningBalance, &icon, &color, &isArchived, &a.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scanning account row: %w", err)
 		}
@@ -1,6 +1,9 @@
 package accounts
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
 
@@ -23,13 +26,14 @@ func (s *Service) ListActive() ([]AccountWithBalance, error) {
 
 	result := make([]AccountWithBalance, 0, len(accounts))
 	for _, account := range accounts {
-		balance, err := s.repo.CalculateBalance(account.ID)
+		balance, cleared, err := s.repo.CalculateBalance(account.ID)
 		if err != nil {
 			return nil, err
 		}
 		awb := AccountWithBalance{
-			Account: account,
-			Balance: balance,
+			Account:        account,
+			Balance:        balance,
+			ClearedBalance: cleared,
 		}
 		awb.EstimatedInterest = estimateInterest(&account, balance)
 		result = append(result, awb)
@@ -81,7 +85,7 @@ func (s *Service) Archive(id int64) *shared.AppError {
 	return nil
 }
 
-// GetBalance returns the computed balance for a specific account, including
+// GetBalance returns the current and cleared balances of an account, including
 // estimated annual interest for savings accounts with interest_rate set.
 func (s *Service) GetBalance(id int64) (*AccountWithBalance, *shared.AppError) {
 	account, appErr := s.repo.GetByID(id)
@@ -89,20 +93,129 @@ func (s *Service) GetBalance(id int64) (*AccountWithBalance, *shared.AppError) {
 		return nil, appErr
 	}
 
-	balance, err := s.repo.CalculateBalance(id)
+	balance, cleared, err := s.repo.CalculateBalance(id)
 	if err != nil {
 		return nil, shared.NewAppError("INTERNAL", "failed to calculate balance", 500)
 	}
 
 	awb := &AccountWithBalance{
-		Account: *account,
-		Balance: balance,
+		Account:        *account,
+		Balance:        balance,
+		ClearedBalance: cleared,
 	}
 	awb.EstimatedInterest = estimateInterest(account, balance)
 
 	return awb, nil
 }
 
+// History returns the running balance of an account at the end of every
+// period of the given granularity, from its opening balance. Without from and
+// to (YYYY-MM-DD), it runs from the period of the first transaction through
+// the current one, or the last one with a transaction if that is later.
+// Periods without transactions repeat the previous balance, so the points can
+// be charted as they are.
+func (s *Service) History(id int64, granularity, from, to string, today time.Time) (*BalanceHistory, *shared.AppError) {
+	if granularity == "" {
+		granularity = GranularityMonth
+	}
+	if _, ok := periodKeys[granularity]; !ok {
+		return nil, shared.NewValidationError("granularity must be 'day', 'week', or 'month'")
+	}
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, shared.NewValidationError("from and to must be in YYYY-MM-DD format")
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return nil, shared.NewValidationError("from must not be after to")
+	}
+
+	account, appErr := s.repo.GetByID(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+	changes, err := s.repo.ListBalanceChanges(id, granularity)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to calculate balance history", 500)
+	}
+
+	start := periodOf(today.Format("2006-01-02"), granularity)
+	if len(changes) > 0 {
+		start = changes[0].period
+	}
+	if from != "" {
+		start = periodOf(from, granularity)
+	}
+	end := periodOf(today.Format("2006-01-02"), granularity)
+	if len(changes) > 0 && changes[len(changes)-1].period > end {
+		end = changes[len(changes)-1].period
+	}
+	if to != "" {
+		end = periodOf(to, granularity)
+	}
+
+	history := &BalanceHistory{
+		AccountID:      id,
+		Granularity:    granularity,
+		OpeningBalance: account.OpeningBalance,
+		Points:         []HistoryPoint{},
+	}
+	balance := account.OpeningBalance
+	next := 0
+	for ; next < len(changes) && changes[next].period < start; next++ {
+		balance += changes[next].amount
+	}
+
+	for period := start; period <= end; period = nextPeriod(period, granularity) {
+		if len(history.Points) == MaxHistoryPoints {
+			return nil, shared.NewValidationError(fmt.Sprintf(
+				"the history would have more than %d points: narrow it with from and to or use a coarser granularity", MaxHistoryPoints))
+		}
+		point := HistoryPoint{Period: period}
+		if next < len(changes) && changes[next].period == period {
+			point.Change = shared.RoundCents(changes[next].amount)
+			balance += changes[next].amount
+			next++
+		}
+		point.Balance = shared.RoundCents(balance)
+		history.Points = append(history.Points, point)
+	}
+	return history, nil
+}
+
+// periodOf returns the period of the given granularity a YYYY-MM-DD date
+// falls in, formatted like the periodKeys expressions.
+func periodOf(date string, granularity string) string {
+	switch granularity {
+	case GranularityMonth:
+		return date[:7]
+	case GranularityWeek:
+		day, _ := time.Parse("2006-01-02", date)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset).Format("2006-01-02")
+	default:
+		return date
+	}
+}
+
+// nextPeriod returns the period following period.
+func nextPeriod(period string, granularity string) string {
+	switch granularity {
+	case GranularityMonth:
+		month, _ := time.Parse("2006-01", period)
+		return month.AddDate(0, 1, 0).Format("2006-01")
+	case GranularityWeek:
+		day, _ := time.Parse("2006-01-02", period)
+		return day.AddDate(0, 0, 7).Format("2006-01-02")
+	default:
+		day, _ := time.Parse("2006-01-02", period)
+		return day.AddDate(0, 0, 1).Format("2006-01-02")
+	}
+}
+
 // estimateInterest calculates estimated annual interest for savings accounts.
 // Returns nil if the account is not savings or has no interest_rate.
 func estimateInterest(account *Account, balance float64) *float64 {
@@ -0,0 +1,160 @@
+package alerts
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/budgets"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+	_ "modernc.org/sqlite"
+)
+
+// recorder is a Notifier that records alert titles, failing while err is set.
+type recorder struct {
+	titles []string
+	err    error
+}
+
+func (r *recorder) notify(title string, body string, urgent bool) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.titles = append(r.titles, title)
+	return nil
+}
+
+// newTestService creates a Service over a database migrated with the plugin's SQL migrations.
+func newTestService(t *testing.T) (*Service, *sql.DB, *recorder) {
+	t.Helper()
+
+	db, err := shared.OpenDatabase(filepath.Join(t.TempDir(), "alerts_test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatalf("listing migrations: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		if _, err := db.Exec(string(migration)); err != nil {
+			t.Fatalf("running %s: %v", file, err)
+		}
+	}
+
+	notifications := &recorder{}
+	service := NewService(NewRepository(db), budgets.NewService(budgets.NewRepository(db)), notifications.notify)
+	return service, db, notifications
+}
+
+func insertExpense(t *testing.T, db *sql.DB, amount float64, category string, date string) {
+	t.Helper()
+	if _, err := db.Exec(
+		"INSERT INTO transactions (amount, type, category, description, date) VALUES (?, 'expense', ?, 'test', ?)",
+		amount, category, date,
+	); err != nil {
+		t.Fatalf("inserting expense: %v", err)
+	}
+}
+
+func TestCheck_LargeExpenseAlertsOnce(t *testing.T) {
+	service, db, notifications := newTestService(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	insertExpense(t, db, 20, "food", "2026-10-14")
+	insertExpense(t, db, 900, "travel", "2026-10-14")
+
+	result, appErr := service.Check(now)
+	if appErr != nil {
+		t.Fatalf("Check failed: %v", appErr)
+	}
+	if result.Sent != 1 || len(notifications.titles) != 1 || notifications.titles[0] != "Large expense: 900.00" {
+		t.Fatalf("expected one large expense alert, got %+v", notifications.titles)
+	}
+
+	if result, _ := service.Check(now); result.Sent != 0 {
+		t.Errorf("expected checked expenses not to alert again, got %d", result.Sent)
+	}
+}
+
+func TestCheck_BudgetExceededOncePerMonth(t *testing.T) {
+	service, db, notifications := newTestService(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	if _, err := db.Exec("INSERT INTO budgets (name, category, amount, month) VALUES ('Food', 'food', 100, '')"); err != nil {
+		t.Fatalf("inserting budget: %v", err)
+	}
+
+	insertExpense(t, db, 80, "food", "2026-10-02")
+	if result, _ := service.Check(now); result.Sent != 0 {
+		t.Fatalf("expected no alert under budget, got %+v", notifications.titles)
+	}
+
+	insertExpense(t, db, 30, "food", "2026-10-10")
+	insertExpense(t, db, 30, "food", "2026-10-12")
+	if _, appErr := service.Check(now); appErr != nil {
+		t.Fatalf("Check failed: %v", appErr)
+	}
+	if len(notifications.titles) != 1 || notifications.titles[0] != `Budget "Food" exceeded` {
+		t.Errorf("expected one budget alert, got %+v", notifications.titles)
+	}
+
+	// The recurring budget alerts again in a month it is exceeded.
+	insertExpense(t, db, 150, "food", "2026-11-01")
+	if _, appErr := service.Check(now.AddDate(0, 1, 0)); appErr != nil {
+		t.Fatalf("Check failed: %v", appErr)
+	}
+	if len(notifications.titles) != 2 {
+		t.Errorf("expected a new alert for November, got %+v", notifications.titles)
+	}
+}
+
+func TestCheck_RetriesFailedNotifications(t *testing.T) {
+	service, db, notifications := newTestService(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	notifications.err = errors.New("host is not connected")
+	insertExpense(t, db, 1200, "rent", "2026-10-01")
+	if _, appErr := service.Check(now); appErr == nil {
+		t.Fatal("expected Check to report the failed notification")
+	}
+
+	notifications.err = nil
+	if result, _ := service.Check(now); result.Sent != 1 {
+		t.Errorf("expected the alert to be sent on the next check, got %+v", notifications.titles)
+	}
+}
+
+func TestUpdateSettings_DisablesLargeExpenseAlerts(t *testing.T) {
+	service, db, notifications := newTestService(t)
+
+	disabled := 0.0
+	settings, appErr := service.UpdateSettings(&UpdateSettingsInput{LargeExpenseThreshold: &disabled})
+	if appErr != nil {
+		t.Fatalf("UpdateSettings failed: %v", appErr)
+	}
+	if settings.LargeExpenseThreshold != 0 || !settings.BudgetAlerts {
+		t.Errorf("expected only the threshold to change, got %+v", settings)
+	}
+
+	insertExpense(t, db, 5000, "car", "2026-10-14")
+	if result, _ := service.Check(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)); result.Sent != 0 {
+		t.Errorf("expected no alerts with the threshold disabled, got %+v", notifications.titles)
+	}
+
+	negative := -1.0
+	if _, appErr := service.UpdateSettings(&UpdateSettingsInput{LargeExpenseThreshold: &negative}); appErr == nil {
+		t.Error("expected a negative threshold to be rejected")
+	}
+}
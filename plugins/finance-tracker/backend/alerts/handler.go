@@ -0,0 +1,64 @@
+package alerts
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/budgets"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Handler routes alert API requests to the appropriate service method.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Handler with all layers wired together. Alerts are
+// sent through notify.
+func NewHandler(db *sql.DB, notify Notifier) *Handler {
+	repo := NewRepository(db)
+	svc := NewService(repo, budgets.NewService(budgets.NewRepository(db)), notify)
+	return &Handler{service: svc}
+}
+
+// Handle dispatches the request to the correct handler based on method and path.
+func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	switch {
+	case req.Method == "GET" && req.Path == "/alerts/settings":
+		return h.getSettings()
+	case req.Method == "PUT" && req.Path == "/alerts/settings":
+		return h.updateSettings(req)
+	default:
+		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
+	}
+}
+
+// CheckPending sends the alerts due after a request that may have recorded
+// expenses or changed budgets.
+func (h *Handler) CheckPending() *shared.AppError {
+	_, appErr := h.service.Check(time.Now())
+	return appErr
+}
+
+func (h *Handler) getSettings() (*sdk.APIResponse, error) {
+	settings, appErr := h.service.GetSettings()
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, settings)
+}
+
+func (h *Handler) updateSettings(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input UpdateSettingsInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	settings, appErr := h.service.UpdateSettings(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, settings)
+}
@@ -0,0 +1,35 @@
+package alerts
+
+// Settings configures which alerts are sent. A LargeExpenseThreshold of 0
+// disables large expense alerts.
+type Settings struct {
+	BudgetAlerts          bool    `json:"budget_alerts"`
+	LargeExpenseThreshold float64 `json:"large_expense_threshold"`
+	UpdatedAt             string  `json:"updated_at"`
+
+	// lastTransactionID is the newest transaction already checked.
+	lastTransactionID int64
+}
+
+// UpdateSettingsInput holds a partial settings update; nil fields are kept.
+type UpdateSettingsInput struct {
+	BudgetAlerts          *bool    `json:"budget_alerts"`
+	LargeExpenseThreshold *float64 `json:"large_expense_threshold"`
+}
+
+// Notifier delivers an alert. The plugin passes sdk.SendNotification.
+type Notifier func(title string, body string, urgent bool) error
+
+// largeExpense is an expense at or above the threshold that has not been alerted yet.
+type largeExpense struct {
+	ID          int64
+	Amount      float64
+	Category    string
+	Description string
+	Date        string
+}
+
+// CheckResult summarizes an alert check.
+type CheckResult struct {
+	Sent int `json:"sent"`
+}
@@ -0,0 +1,107 @@
+package alerts
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Repository handles database operations for alert settings and sent alerts.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository backed by the given database connection.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// GetSettings returns the alert settings row.
+func (r *Repository) GetSettings() (*Settings, error) {
+	var settings Settings
+	err := r.db.QueryRow(`
+		SELECT budget_alerts, large_expense_threshold, last_transaction_id, updated_at
+		FROM alert_settings WHERE id = 1
+	`).Scan(&settings.BudgetAlerts, &settings.LargeExpenseThreshold, &settings.lastTransactionID, &settings.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("querying alert settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// SaveSettings updates the alert settings. The checked transaction cursor is kept.
+func (r *Repository) SaveSettings(settings *Settings) error {
+	if _, err := r.db.Exec(`
+		UPDATE alert_settings
+		SET budget_alerts = ?, large_expense_threshold = ?, updated_at = datetime('now')
+		WHERE id = 1
+	`, settings.BudgetAlerts, settings.LargeExpenseThreshold); err != nil {
+		return fmt.Errorf("saving alert settings: %w", err)
+	}
+	return nil
+}
+
+// LatestTransactionID returns the highest transaction ID, or 0 without transactions.
+func (r *Repository) LatestTransactionID() (int64, error) {
+	var id int64
+	if err := r.db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM transactions").Scan(&id); err != nil {
+		return 0, fmt.Errorf("querying latest transaction: %w", err)
+	}
+	return id, nil
+}
+
+// ListLargeExpenses returns the expenses with an ID in (afterID, upToID] whose
+// amount is at least threshold, oldest first.
+func (r *Repository) ListLargeExpenses(afterID int64, upToID int64, threshold float64) ([]largeExpense, error) {
+	rows, err := r.db.Query(`
+		SELECT id, amount, COALESCE(category, ''), COALESCE(description, ''), date
+		FROM transactions
+		WHERE type = 'expense' AND status != 'void' AND id > ? AND id <= ? AND amount >= ?
+		ORDER BY id
+	`, afterID, upToID, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("querying large expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := make([]largeExpense, 0)
+	for rows.Next() {
+		var expense largeExpense
+		if err := rows.Scan(&expense.ID, &expense.Amount, &expense.Category, &expense.Description, &expense.Date); err != nil {
+			return nil, fmt.Errorf("scanning large expense: %w", err)
+		}
+		expenses = append(expenses, expense)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating large expenses: %w", err)
+	}
+	return expenses, nil
+}
+
+// SetLastTransactionID records that transactions up to id have been checked.
+func (r *Repository) SetLastTransactionID(id int64) error {
+	if _, err := r.db.Exec("UPDATE alert_settings SET last_transaction_id = ? WHERE id = 1", id); err != nil {
+		return fmt.Errorf("updating checked transactions: %w", err)
+	}
+	return nil
+}
+
+// BudgetAlerted reports whether the budget already alerted for month.
+func (r *Repository) BudgetAlerted(budgetID int64, month string) (bool, error) {
+	var count int
+	if err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM budget_alerts WHERE budget_id = ? AND month = ?", budgetID, month,
+	).Scan(&count); err != nil {
+		return false, fmt.Errorf("checking budget alert: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RecordBudgetAlert marks the budget as alerted for month.
+func (r *Repository) RecordBudgetAlert(budgetID int64, month string, spent float64) error {
+	if _, err := r.db.Exec(
+		"INSERT OR IGNORE INTO budget_alerts (budget_id, month, spent) VALUES (?, ?, ?)", budgetID, month, spent,
+	); err != nil {
+		return fmt.Errorf("recording budget alert: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/budgets"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Service checks budgets and new expenses and sends the alerts that are due.
+type Service struct {
+	repo    *Repository
+	budgets *budgets.Service
+	notify  Notifier
+}
+
+// NewService creates a Service that sends alerts through notify.
+func NewService(repo *Repository, budgetService *budgets.Service, notify Notifier) *Service {
+	return &Service{repo: repo, budgets: budgetService, notify: notify}
+}
+
+// GetSettings returns the alert settings.
+func (s *Service) GetSettings() (*Settings, *shared.AppError) {
+	settings, err := s.repo.GetSettings()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to get alert settings", 500)
+	}
+	return settings, nil
+}
+
+// UpdateSettings applies a partial update to the alert settings.
+func (s *Service) UpdateSettings(input *UpdateSettingsInput) (*Settings, *shared.AppError) {
+	if input.LargeExpenseThreshold != nil && *input.LargeExpenseThreshold < 0 {
+		return nil, shared.NewValidationError("large_expense_threshold must be 0 (disabled) or greater")
+	}
+
+	settings, appErr := s.GetSettings()
+	if appErr != nil {
+		return nil, appErr
+	}
+	if input.BudgetAlerts != nil {
+		settings.BudgetAlerts = *input.BudgetAlerts
+	}
+	if input.LargeExpenseThreshold != nil {
+		settings.LargeExpenseThreshold = *input.LargeExpenseThreshold
+	}
+
+	if err := s.repo.SaveSettings(settings); err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to save alert settings", 500)
+	}
+	return s.GetSettings()
+}
+
+// Check alerts on expenses recorded since the last check that reach the
+// large expense threshold, and on budgets of now's month whose spending went
+// over their amount. Each expense and each budget and month alerts once. When
+// a notification cannot be sent, the check stops and the remaining alerts are
+// retried by the next one.
+func (s *Service) Check(now time.Time) (*CheckResult, *shared.AppError) {
+	settings, appErr := s.GetSettings()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	result := &CheckResult{}
+	if appErr := s.checkLargeExpenses(settings, result); appErr != nil {
+		return result, appErr
+	}
+	if settings.BudgetAlerts {
+		if appErr := s.checkBudgets(now.Format("2006-01"), result); appErr != nil {
+			return result, appErr
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) checkLargeExpenses(settings *Settings, result *CheckResult) *shared.AppError {
+	latestID, err := s.repo.LatestTransactionID()
+	if err != nil {
+		return shared.NewAppError("INTERNAL", "failed to check transactions", 500)
+	}
+	if latestID <= settings.lastTransactionID {
+		return nil
+	}
+
+	if settings.LargeExpenseThreshold > 0 {
+		expenses, err := s.repo.ListLargeExpenses(settings.lastTransactionID, latestID, settings.LargeExpenseThreshold)
+		if err != nil {
+			return shared.NewAppError("INTERNAL", "failed to check transactions", 500)
+		}
+		for _, expense := range expenses {
+			if err := s.notify(largeExpenseMessage(expense)); err != nil {
+				return shared.NewAppError("NOTIFICATION_FAILED", fmt.Sprintf("sending alert: %v", err), 502)
+			}
+			result.Sent++
+			if err := s.repo.SetLastTransactionID(expense.ID); err != nil {
+				return shared.NewAppError("INTERNAL", "failed to record alert", 500)
+			}
+		}
+	}
+
+	if err := s.repo.SetLastTransactionID(latestID); err != nil {
+		return shared.NewAppError("INTERNAL", "failed to record alert", 500)
+	}
+	return nil
+}
+
+func (s *Service) checkBudgets(month string, result *CheckResult) *shared.AppError {
+	progress, appErr := s.budgets.List(month)
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, budget := range progress {
+		if budget.Amount <= 0 || budget.Spent <= budget.Amount {
+			continue
+		}
+		alerted, err := s.repo.BudgetAlerted(budget.ID, month)
+		if err != nil {
+			return shared.NewAppError("INTERNAL", "failed to check budget alerts", 500)
+		}
+		if alerted {
+			continue
+		}
+
+		if err := s.notify(budgetMessage(budget, month)); err != nil {
+			return shared.NewAppError("NOTIFICATION_FAILED", fmt.Sprintf("sending alert: %v", err), 502)
+		}
+		result.Sent++
+		if err := s.repo.RecordBudgetAlert(budget.ID, month, budget.Spent); err != nil {
+			return shared.NewAppError("INTERNAL", "failed to record alert", 500)
+		}
+	}
+	return nil
+}
+
+// budgetMessage describes an exceeded budget. Budget alerts are urgent: they
+// are pushed right away instead of waiting for the digest.
+func budgetMessage(budget budgets.BudgetWithProgress, month string) (string, string, bool) {
+	name := budget.Name
+	if name == "" {
+		name = budget.Category
+	}
+	if name == "" {
+		name = "Monthly"
+	}
+
+	title := fmt.Sprintf("Budget %q exceeded", name)
+	body := fmt.Sprintf("Spent %.2f of %.2f in %s (%.0f%%).", budget.Spent, budget.Amount, month, budget.Percentage)
+	return title, body, true
+}
+
+// largeExpenseMessage describes a large expense, urgent so an unexpected
+// charge is noticed quickly.
+func largeExpenseMessage(expense largeExpense) (string, string, bool) {
+	title := fmt.Sprintf("Large expense: %.2f", expense.Amount)
+	body := expense.Date
+	if expense.Category != "" {
+		body += " · " + expense.Category
+	}
+	if expense.Description != "" {
+		body += " · " + expense.Description
+	}
+	return title, body, true
+}
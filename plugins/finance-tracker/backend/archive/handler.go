@@ -0,0 +1,71 @@
+package archive
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Handler routes archive API requests to the appropriate service method.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Handler with all layers wired together.
+func NewHandler(db *sql.DB) *Handler {
+	repo := NewRepository(db)
+	svc := NewService(repo)
+	return &Handler{service: svc}
+}
+
+// Handle dispatches the request to the correct handler based on method and path.
+func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	switch {
+	case req.Method == "GET" && req.Path == "/archive/settings":
+		return h.getSettings()
+	case req.Method == "PUT" && req.Path == "/archive/settings":
+		return h.updateSettings(req)
+	case req.Method == "POST" && req.Path == "/archive/run":
+		return h.run()
+	default:
+		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
+	}
+}
+
+// StartScheduler applies the archival policy in the background every
+// interval, calling onArchived after runs that moved transactions.
+func (h *Handler) StartScheduler(interval time.Duration, onArchived func()) (stop func()) {
+	return h.service.StartScheduler(interval, onArchived)
+}
+
+func (h *Handler) getSettings() (*sdk.APIResponse, error) {
+	settings, appErr := h.service.GetSettings()
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, settings)
+}
+
+func (h *Handler) updateSettings(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input UpdateSettingsInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	settings, appErr := h.service.UpdateSettings(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, settings)
+}
+
+func (h *Handler) run() (*sdk.APIResponse, error) {
+	result, appErr := h.service.Run(time.Now())
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, result)
+}
@@ -0,0 +1,23 @@
+package archive
+
+// Settings is the archival policy with a summary of what it has archived.
+type Settings struct {
+	// RetentionYears is how many years of transactions stay live; 0 disables archival.
+	RetentionYears int     `json:"retention_years"`
+	LastRunAt      *string `json:"last_run_at"`
+	UpdatedAt      string  `json:"updated_at"`
+	// LiveCount and ArchivedCount are the transactions in each table.
+	LiveCount     int `json:"live_count"`
+	ArchivedCount int `json:"archived_count"`
+}
+
+// UpdateSettingsInput holds the input for changing the archival policy.
+type UpdateSettingsInput struct {
+	RetentionYears *int `json:"retention_years"`
+}
+
+// RunResult summarizes an archival run. Cutoff is empty when archival is disabled.
+type RunResult struct {
+	Archived int64  `json:"archived"`
+	Cutoff   string `json:"cutoff"`
+}
@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Repository handles database operations for transaction archival.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository backed by the given database connection.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// GetSettings returns the archival policy and the size of both tables.
+func (r *Repository) GetSettings() (*Settings, error) {
+	var settings Settings
+	var lastRunAt sql.NullString
+	err := r.db.QueryRow(`
+		SELECT retention_years, last_run_at, updated_at,
+		       (SELECT COUNT(*) FROM transactions),
+		       (SELECT COUNT(*) FROM archived_transactions)
+		FROM archive_settings WHERE id = 1
+	`).Scan(&settings.RetentionYears, &lastRunAt, &settings.UpdatedAt, &settings.LiveCount, &settings.ArchivedCount)
+	if err != nil {
+		return nil, fmt.Errorf("querying archive settings: %w", err)
+	}
+	if lastRunAt.Valid {
+		settings.LastRunAt = &lastRunAt.String
+	}
+	return &settings, nil
+}
+
+// SaveRetention updates how many years of transactions stay live.
+func (r *Repository) SaveRetention(years int) error {
+	if _, err := r.db.Exec(
+		"UPDATE archive_settings SET retention_years = ?, updated_at = datetime('now') WHERE id = 1", years,
+	); err != nil {
+		return fmt.Errorf("saving archive settings: %w", err)
+	}
+	return nil
+}
+
+// ArchiveBefore moves the transactions dated before cutoff to
+// archived_transactions in one database transaction, keeping their tag names,
+// and returns how many were moved.
+func (r *Repository) ArchiveBefore(cutoff string, now time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning archive transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.Exec(`
+		INSERT INTO archived_transactions (
+			id, amount, type, category, description, date, account_id, dest_account_id,
+			is_recurring_instance, recurring_rule_id, created_at, status, tags, archived_at
+		)
+		SELECT t.id, t.amount, t.type, t.category, t.description, t.date, t.account_id, t.dest_account_id,
+		       t.is_recurring_instance, t.recurring_rule_id, t.created_at, t.status,
+		       COALESCE((
+		           SELECT group_concat(g.name, ',')
+		           FROM transaction_tags tt JOIN tags g ON g.id = tt.tag_id
+		           WHERE tt.transaction_id = t.id
+		       ), ''),
+		       ?
+		FROM transactions t
+		WHERE t.date < ?
+	`, now.UTC().Format(time.RFC3339), cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("copying transactions to the archive: %w", err)
+	}
+	archived, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting archived transactions: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM transactions WHERE date < ?", cutoff); err != nil {
+		return 0, fmt.Errorf("removing archived transactions: %w", err)
+	}
+	if _, err := tx.Exec(
+		"UPDATE archive_settings SET last_run_at = ? WHERE id = 1", now.UTC().Format(time.RFC3339),
+	); err != nil {
+		return 0, fmt.Errorf("recording archive run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing archive: %w", err)
+	}
+	return archived, nil
+}
@@ -0,0 +1,108 @@
+package archive
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// maxRetentionYears bounds the retention period.
+const maxRetentionYears = 100
+
+// Service applies the archival policy.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a Service backed by the given Repository.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// GetSettings returns the archival policy.
+func (s *Service) GetSettings() (*Settings, *shared.AppError) {
+	settings, err := s.repo.GetSettings()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to get archive settings", 500)
+	}
+	return settings, nil
+}
+
+// UpdateSettings changes the retention period. Lowering it archives more on
+// the next run; raising it does not move archived transactions back.
+func (s *Service) UpdateSettings(input *UpdateSettingsInput) (*Settings, *shared.AppError) {
+	if input.RetentionYears == nil {
+		return nil, shared.NewValidationError("retention_years is required")
+	}
+	if *input.RetentionYears < 0 || *input.RetentionYears > maxRetentionYears {
+		return nil, shared.NewValidationError(fmt.Sprintf("retention_years must be between 0 (disabled) and %d", maxRetentionYears))
+	}
+
+	if err := s.repo.SaveRetention(*input.RetentionYears); err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to save archive settings", 500)
+	}
+	return s.GetSettings()
+}
+
+// Run archives the transactions dated before the retention period, counted
+// back from now. Nothing is archived while the retention is 0.
+func (s *Service) Run(now time.Time) (*RunResult, *shared.AppError) {
+	settings, appErr := s.GetSettings()
+	if appErr != nil {
+		return nil, appErr
+	}
+	if settings.RetentionYears == 0 {
+		return &RunResult{}, nil
+	}
+
+	cutoff := Cutoff(now, settings.RetentionYears)
+	archived, err := s.repo.ArchiveBefore(cutoff, now)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("archiving transactions: %v", err), 500)
+	}
+	return &RunResult{Archived: archived, Cutoff: cutoff}, nil
+}
+
+// Cutoff returns the first day kept live: transactions dated before it are
+// archived. It is the first of now's month, years back, so whole months move
+// together and monthly reports never mix live and archived rows.
+func Cutoff(now time.Time, years int) string {
+	return time.Date(now.Year()-years, now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02")
+}
+
+// StartScheduler runs the archival policy now and then on every interval
+// until the returned stop function is called. onArchived is called after each
+// run that moved transactions.
+func (s *Service) StartScheduler(interval time.Duration, onArchived func()) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// A failing run archives nothing; it is retried next tick.
+			if result, appErr := s.Run(time.Now()); appErr == nil && result.Archived > 0 {
+				onArchived()
+			}
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
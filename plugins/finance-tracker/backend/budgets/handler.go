@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/alvarotorresc/cortex/pkg/sdk"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
@@ -28,6 +29,20 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return h.list(req)
 	case req.Method == "POST" && req.Path == "/budgets":
 		return h.create(req)
+	case req.Method == "POST" && req.Path == "/budgets/copy":
+		return h.copy(req)
+	case req.Method == "GET" && req.Path == "/budgets/templates":
+		return h.listTemplates()
+	case req.Method == "POST" && req.Path == "/budgets/templates":
+		return h.createTemplate(req)
+	case req.Method == "POST" && strings.HasPrefix(req.Path, "/budgets/templates/") && strings.HasSuffix(req.Path, "/apply"):
+		return h.applyTemplate(req)
+	case req.Method == "GET" && strings.HasPrefix(req.Path, "/budgets/templates/"):
+		return h.getTemplate(req)
+	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/budgets/templates/"):
+		return h.updateTemplate(req)
+	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/budgets/templates/"):
+		return h.deleteTemplate(req)
 	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/budgets/"):
 		return h.update(req)
 	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/budgets/"):
@@ -37,6 +52,16 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	}
 }
 
+// WidgetData returns the budget status widget payload: every budget of the
+// month containing now with its spending progress.
+func (h *Handler) WidgetData(now time.Time) ([]byte, error) {
+	budgets, appErr := h.service.List(now.Format("2006-01"))
+	if appErr != nil {
+		return nil, appErr
+	}
+	return json.Marshal(map[string]interface{}{"data": budgets})
+}
+
 func (h *Handler) list(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	month := req.Query["month"]
 	if month == "" {
@@ -96,3 +121,110 @@ func (h *Handler) delete(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 
 	return shared.JSONSuccess(200, map[string]interface{}{"deleted": id})
 }
+
+func (h *Handler) copy(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	from, to := req.Query["from"], req.Query["to"]
+	if from == "" || to == "" {
+		return shared.JSONError(shared.NewValidationError("'from' and 'to' query parameters are required (YYYY-MM)"))
+	}
+
+	result, appErr := h.service.Copy(from, to)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(201, result)
+}
+
+func (h *Handler) listTemplates() (*sdk.APIResponse, error) {
+	templates, appErr := h.service.ListTemplates()
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(200, templates)
+}
+
+func (h *Handler) getTemplate(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := templateIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	template, appErr := h.service.GetTemplate(id)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(200, template)
+}
+
+func (h *Handler) createTemplate(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input TemplateInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	template, appErr := h.service.CreateTemplate(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(201, template)
+}
+
+func (h *Handler) updateTemplate(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := templateIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	var input TemplateInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	template, appErr := h.service.UpdateTemplate(id, &input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(200, template)
+}
+
+func (h *Handler) deleteTemplate(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := templateIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	if appErr := h.service.DeleteTemplate(id); appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(200, map[string]interface{}{"deleted": id})
+}
+
+func (h *Handler) applyTemplate(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := templateIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	month := req.Query["month"]
+	if month == "" {
+		return shared.JSONError(shared.NewValidationError("month query parameter is required (YYYY-MM)"))
+	}
+
+	result, appErr := h.service.ApplyTemplate(id, month)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(201, result)
+}
+
+// templateIDFromPath reads the template ID from /budgets/templates/{id}[/apply].
+func templateIDFromPath(path string) (int64, *shared.AppError) {
+	return shared.ExtractIDFromPath(strings.TrimPrefix(path, "/budgets"))
+}
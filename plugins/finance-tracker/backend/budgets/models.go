@@ -36,6 +36,37 @@ type UpdateBudgetInput struct {
 	Month    string  `json:"month"`
 }
 
+// Template is a named set of budget limits that can be applied to any month.
+type Template struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Items     []TemplateItem `json:"items"`
+	CreatedAt string         `json:"created_at"`
+}
+
+// TemplateItem is one limit in a template. An empty category is a global budget.
+type TemplateItem struct {
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
+
+// TemplateInput holds input for creating or updating a template. Items may be
+// left out when Month names the month whose budgets the template copies.
+type TemplateInput struct {
+	Name  string         `json:"name"`
+	Month string         `json:"month"`
+	Items []TemplateItem `json:"items"`
+}
+
+// ApplyResult reports the budgets a copy or template created for a month.
+// Limits for a category the month already budgets are skipped.
+type ApplyResult struct {
+	Month   string         `json:"month"`
+	Created []Budget       `json:"created"`
+	Skipped []TemplateItem `json:"skipped"`
+}
+
 // monthPattern validates YYYY-MM format.
 var monthPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
 
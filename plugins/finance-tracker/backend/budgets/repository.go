@@ -3,6 +3,7 @@ package budgets
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
@@ -139,7 +140,7 @@ func (r *Repository) CalculateSpentGlobal(month string) (float64, error) {
 	err := r.db.QueryRow(`
 		SELECT COALESCE(SUM(amount), 0)
 		FROM transactions
-		WHERE type = 'expense' AND date LIKE ?
+		WHERE type = 'expense' AND date LIKE ? AND status != 'void'
 	`, month+"%").Scan(&spent)
 	if err != nil {
 		return 0, fmt.Errorf("calculating global spent: %w", err)
@@ -154,7 +155,7 @@ func (r *Repository) CalculateSpentByCategory(month string, category string) (fl
 	err := r.db.QueryRow(`
 		SELECT COALESCE(SUM(amount), 0)
 		FROM transactions
-		WHERE type = 'expense' AND date LIKE ? AND category = ?
+		WHERE type = 'expense' AND date LIKE ? AND category = ? AND status != 'void'
 	`, month+"%", category).Scan(&spent)
 	if err != nil {
 		return 0, fmt.Errorf("calculating category spent: %w", err)
@@ -177,3 +178,259 @@ func scanBudgets(rows *sql.Rows) ([]Budget, error) {
 	}
 	return budgets, nil
 }
+
+// ListMonthItems returns the limits of the budgets set for one month, not
+// counting recurring budgets, which apply to every month anyway.
+func (r *Repository) ListMonthItems(month string) ([]TemplateItem, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(name, ''), COALESCE(category, ''), amount
+		FROM budgets
+		WHERE month = ?
+		ORDER BY id
+	`, month)
+	if err != nil {
+		return nil, fmt.Errorf("querying month budgets: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTemplateItems(rows)
+}
+
+// ApplyItems creates a budget for the month from each item, in one
+// transaction. Items for a category the month already budgets, directly or
+// through a recurring budget, are skipped and returned.
+func (r *Repository) ApplyItems(month string, items []TemplateItem) ([]int64, []TemplateItem, error) {
+	transaction, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	rows, err := transaction.Query(`
+		SELECT COALESCE(category, '')
+		FROM budgets
+		WHERE month = ? OR month IS NULL OR month = ''
+	`, month)
+	if err != nil {
+		return nil, nil, fmt.Errorf("querying budgeted categories: %w", err)
+	}
+	budgeted := make(map[string]bool)
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("scanning budgeted category: %w", err)
+		}
+		budgeted[category] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterating budgeted categories: %w", err)
+	}
+
+	ids := make([]int64, 0, len(items))
+	skipped := make([]TemplateItem, 0)
+	for _, item := range items {
+		if budgeted[item.Category] {
+			skipped = append(skipped, item)
+			continue
+		}
+
+		var nameVal, categoryVal interface{}
+		if item.Name != "" {
+			nameVal = item.Name
+		}
+		if item.Category != "" {
+			categoryVal = item.Category
+		}
+		result, err := transaction.Exec(`
+			INSERT INTO budgets (name, category, amount, month)
+			VALUES (?, ?, ?, ?)
+		`, nameVal, categoryVal, item.Amount, month)
+		if err != nil {
+			return nil, nil, fmt.Errorf("inserting budget: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting last insert id: %w", err)
+		}
+		ids = append(ids, id)
+		budgeted[item.Category] = true
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("committing budgets: %w", err)
+	}
+	return ids, skipped, nil
+}
+
+// ListTemplates returns all budget templates with their items, by name.
+func (r *Repository) ListTemplates() ([]Template, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, created_at FROM budget_templates ORDER BY name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying budget templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]Template, 0)
+	for rows.Next() {
+		var t Template
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning budget template row: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating budget template rows: %w", err)
+	}
+
+	for i := range templates {
+		items, err := r.templateItems(templates[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		templates[i].Items = items
+	}
+	return templates, nil
+}
+
+// GetTemplate returns a single budget template with its items.
+func (r *Repository) GetTemplate(id int64) (*Template, *shared.AppError) {
+	var t Template
+	err := r.db.QueryRow(`
+		SELECT id, name, created_at FROM budget_templates WHERE id = ?
+	`, id).Scan(&t.ID, &t.Name, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, shared.NewNotFoundError("budget template", fmt.Sprintf("%d", id))
+	}
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying budget template: %v", err), 500)
+	}
+
+	items, err := r.templateItems(id)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", err.Error(), 500)
+	}
+	t.Items = items
+	return &t, nil
+}
+
+// CreateTemplate inserts a template and its items, returning the new ID.
+func (r *Repository) CreateTemplate(name string, items []TemplateItem) (int64, error) {
+	transaction, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec("INSERT INTO budget_templates (name) VALUES (?)", name)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 0, shared.NewConflictError(fmt.Sprintf("budget template '%s' already exists", name))
+		}
+		return 0, fmt.Errorf("inserting budget template: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("getting last insert id: %w", err)
+	}
+
+	if err := insertTemplateItems(transaction, id, items); err != nil {
+		return 0, err
+	}
+	if err := transaction.Commit(); err != nil {
+		return 0, fmt.Errorf("committing budget template: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateTemplate renames a template and replaces its items.
+func (r *Repository) UpdateTemplate(id int64, name string, items []TemplateItem) error {
+	transaction, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec("UPDATE budget_templates SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return shared.NewConflictError(fmt.Sprintf("budget template '%s' already exists", name))
+		}
+		return fmt.Errorf("updating budget template: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return shared.NewNotFoundError("budget template", fmt.Sprintf("%d", id))
+	}
+
+	if _, err := transaction.Exec("DELETE FROM budget_template_items WHERE template_id = ?", id); err != nil {
+		return fmt.Errorf("clearing budget template items: %w", err)
+	}
+	if err := insertTemplateItems(transaction, id, items); err != nil {
+		return err
+	}
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("committing budget template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate removes a template; its items go with it.
+func (r *Repository) DeleteTemplate(id int64) error {
+	result, err := r.db.Exec("DELETE FROM budget_templates WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting budget template: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return shared.NewNotFoundError("budget template", fmt.Sprintf("%d", id))
+	}
+	return nil
+}
+
+// templateItems returns a template's items in the order they were given.
+func (r *Repository) templateItems(templateID int64) ([]TemplateItem, error) {
+	rows, err := r.db.Query(`
+		SELECT name, category, amount FROM budget_template_items
+		WHERE template_id = ? ORDER BY id
+	`, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("querying budget template items: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTemplateItems(rows)
+}
+
+// insertTemplateItems stores a template's items within the transaction.
+func insertTemplateItems(transaction *sql.Tx, templateID int64, items []TemplateItem) error {
+	for _, item := range items {
+		if _, err := transaction.Exec(`
+			INSERT INTO budget_template_items (template_id, name, category, amount)
+			VALUES (?, ?, ?, ?)
+		`, templateID, item.Name, item.Category, item.Amount); err != nil {
+			return fmt.Errorf("inserting budget template item: %w", err)
+		}
+	}
+	return nil
+}
+
+// scanTemplateItems reads all rows from the result set into a slice of TemplateItem.
+func scanTemplateItems(rows *sql.Rows) ([]TemplateItem, error) {
+	items := make([]TemplateItem, 0)
+	for rows.Next() {
+		var item TemplateItem
+		if err := rows.Scan(&item.Name, &item.Category, &item.Amount); err != nil {
+			return nil, fmt.Errorf("scanning budget template item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating budget template items: %w", err)
+	}
+	return items, nil
+}
@@ -1,7 +1,9 @@
 package budgets
 
 import (
+	"fmt"
 	"math"
+	"strings"
 
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
@@ -121,6 +123,151 @@ func (s *Service) Delete(id int64) *shared.AppError {
 	return nil
 }
 
+// Copy creates budgets for the to month from the budgets set for the from
+// month. Recurring budgets already apply to every month and are not copied.
+func (s *Service) Copy(from, to string) (*ApplyResult, *shared.AppError) {
+	if !IsValidMonth(from) || !IsValidMonth(to) {
+		return nil, shared.NewValidationError("from and to must be in YYYY-MM format")
+	}
+	if from == to {
+		return nil, shared.NewValidationError("from and to must be different months")
+	}
+
+	items, err := s.repo.ListMonthItems(from)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to list budgets", 500)
+	}
+	if len(items) == 0 {
+		return nil, shared.NewNotFoundError("budgets for month", from)
+	}
+	return s.apply(to, items)
+}
+
+// ListTemplates returns all budget templates.
+func (s *Service) ListTemplates() ([]Template, *shared.AppError) {
+	templates, err := s.repo.ListTemplates()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to list budget templates", 500)
+	}
+	return templates, nil
+}
+
+// GetTemplate returns a single budget template.
+func (s *Service) GetTemplate(id int64) (*Template, *shared.AppError) {
+	return s.repo.GetTemplate(id)
+}
+
+// CreateTemplate validates input and saves a new template.
+func (s *Service) CreateTemplate(input *TemplateInput) (*Template, *shared.AppError) {
+	if appErr := s.prepareTemplateInput(input); appErr != nil {
+		return nil, appErr
+	}
+
+	id, err := s.repo.CreateTemplate(input.Name, input.Items)
+	if err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return nil, appErr
+		}
+		return nil, shared.NewAppError("INTERNAL", "failed to create budget template", 500)
+	}
+	return s.repo.GetTemplate(id)
+}
+
+// UpdateTemplate validates input and replaces a template's name and items.
+func (s *Service) UpdateTemplate(id int64, input *TemplateInput) (*Template, *shared.AppError) {
+	if appErr := s.prepareTemplateInput(input); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.repo.UpdateTemplate(id, input.Name, input.Items); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return nil, appErr
+		}
+		return nil, shared.NewAppError("INTERNAL", "failed to update budget template", 500)
+	}
+	return s.repo.GetTemplate(id)
+}
+
+// DeleteTemplate removes a budget template. Budgets it created are kept.
+func (s *Service) DeleteTemplate(id int64) *shared.AppError {
+	if err := s.repo.DeleteTemplate(id); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return appErr
+		}
+		return shared.NewAppError("INTERNAL", "failed to delete budget template", 500)
+	}
+	return nil
+}
+
+// ApplyTemplate creates budgets for the month from a template.
+func (s *Service) ApplyTemplate(id int64, month string) (*ApplyResult, *shared.AppError) {
+	if !IsValidMonth(month) {
+		return nil, shared.NewValidationError("month must be in YYYY-MM format")
+	}
+
+	template, appErr := s.repo.GetTemplate(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return s.apply(month, template.Items)
+}
+
+// apply creates the month's budgets from items and returns them.
+func (s *Service) apply(month string, items []TemplateItem) (*ApplyResult, *shared.AppError) {
+	ids, skipped, err := s.repo.ApplyItems(month, items)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to create budgets", 500)
+	}
+
+	result := &ApplyResult{Month: month, Created: make([]Budget, 0, len(ids)), Skipped: skipped}
+	for _, id := range ids {
+		budget, appErr := s.repo.GetByID(id)
+		if appErr != nil {
+			return nil, appErr
+		}
+		result.Created = append(result.Created, *budget)
+	}
+	return result, nil
+}
+
+// prepareTemplateInput validates a template and, when it names a month
+// instead of listing items, fills the items from that month's budgets.
+func (s *Service) prepareTemplateInput(input *TemplateInput) *shared.AppError {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return shared.NewValidationError("name is required")
+	}
+
+	if len(input.Items) == 0 && input.Month != "" {
+		if !IsValidMonth(input.Month) {
+			return shared.NewValidationError("month must be in YYYY-MM format")
+		}
+		items, err := s.repo.ListMonthItems(input.Month)
+		if err != nil {
+			return shared.NewAppError("INTERNAL", "failed to list budgets", 500)
+		}
+		input.Items = items
+	}
+	if len(input.Items) == 0 {
+		return shared.NewValidationError("items are required")
+	}
+
+	categories := make(map[string]bool, len(input.Items))
+	for i := range input.Items {
+		item := &input.Items[i]
+		item.Name = strings.TrimSpace(item.Name)
+		item.Category = strings.TrimSpace(item.Category)
+		if item.Amount <= 0 {
+			return shared.NewValidationError(fmt.Sprintf("items[%d]: amount must be greater than 0", i))
+		}
+		if categories[item.Category] {
+			return shared.NewValidationError(fmt.Sprintf("items[%d]: category '%s' appears more than once", i, item.Category))
+		}
+		categories[item.Category] = true
+	}
+	return nil
+}
+
 // validateCreateInput checks that all required fields are present and valid.
 func validateCreateInput(input *CreateBudgetInput) *shared.AppError {
 	if input.Amount <= 0 {
@@ -150,11 +150,11 @@ func (r *Repository) Update(id int64, input *UpdateCategoryInput) error {
 	return nil
 }
 
-// HasTransactions checks if any transactions reference the given category name.
+// HasTransactions checks if any transactions, live or archived, reference the given category name.
 func (r *Repository) HasTransactions(categoryName string) (bool, error) {
 	var count int
 	err := r.db.QueryRow(
-		"SELECT COUNT(*) FROM transactions WHERE category = ?", categoryName,
+		"SELECT COUNT(*) FROM all_transactions WHERE category = ?", categoryName,
 	).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("checking transactions for category: %w", err)
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// dataTables are the tables ExportData and ImportData move between
+// instances, in the order ImportData fills them: the ones other tables refer
+// to first.
+var dataTables = []string{
+	"accounts",
+	"categories",
+	"tags",
+	"savings_goals",
+	"recurring_rules",
+	"recurring_skips",
+	"transactions",
+	"transaction_tags",
+	"archived_transactions",
+	"budgets",
+	"budget_alerts",
+	"budget_templates",
+	"budget_template_items",
+	"goal_contributions",
+	"investments",
+	"roundup_rules",
+	"roundups",
+	"export_schedules",
+	"export_runs",
+	"alert_settings",
+	"archive_settings",
+	"import_presets",
+}
+
+// ExportData returns every account, transaction, budget, goal and rule for
+// GET /api/plugins/finance-tracker/export.
+func (p *FinancePlugin) ExportData() ([]byte, error) {
+	return sdk.ExportTables(p.db, dataTables...)
+}
+
+// ImportData replaces the plugin's data with an export from this or an older
+// version, all at once or not at all.
+func (p *FinancePlugin) ImportData(fromVersion string, data []byte) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := sdk.ImportTables(tx, data, dataTables...); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	p.reportsHandler.Invalidate()
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// demoMonths is how many months of history SeedDemo creates, the current one included.
+const demoMonths = 3
+
+// demoExpense is one expense SeedDemo records every month.
+type demoExpense struct {
+	day         int
+	amount      float64
+	category    string
+	description string
+}
+
+var demoExpenses = []demoExpense{
+	{day: 1, amount: 850, category: "bills", description: "Rent"},
+	{day: 3, amount: 64.20, category: "groceries", description: "Weekly shop"},
+	{day: 8, amount: 45.90, category: "bills", description: "Electricity"},
+	{day: 10, amount: 71.35, category: "groceries", description: "Weekly shop"},
+	{day: 12, amount: 38.50, category: "restaurants", description: "Dinner with friends"},
+	{day: 15, amount: 40, category: "transport", description: "Monthly transit pass"},
+	{day: 17, amount: 58.80, category: "groceries", description: "Weekly shop"},
+	{day: 20, amount: 12.99, category: "entertainment", description: "Streaming subscription"},
+	{day: 24, amount: 66.15, category: "groceries", description: "Weekly shop"},
+}
+
+// SeedDemo fills an empty database with a savings account, three months of
+// salary and everyday expenses, a groceries budget and a savings goal. A
+// database that already has transactions is left untouched.
+func (p *FinancePlugin) SeedDemo() error {
+	var count int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count); err != nil {
+		return fmt.Errorf("counting transactions: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	transaction, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec(`
+		INSERT INTO accounts (name, type, currency, interest_rate, icon, color)
+		VALUES ('Savings', 'savings', 'EUR', 2.5, 'piggy-bank', '#10B981')
+	`)
+	if err != nil {
+		return fmt.Errorf("creating demo account: %w", err)
+	}
+	savingsID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("reading demo account ID: %w", err)
+	}
+
+	now := time.Now()
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for offset := demoMonths - 1; offset >= 0; offset-- {
+		month := firstOfMonth.AddDate(0, -offset, 0)
+		// The current month only gets the entries up to today.
+		lastDay := 31
+		if offset == 0 {
+			lastDay = now.Day()
+		}
+
+		if _, err := transaction.Exec(`
+			INSERT INTO transactions (amount, type, category, description, date, account_id)
+			VALUES (2450, 'income', 'salary', 'Monthly salary', ?, 1)
+		`, month.Format("2006-01-02")); err != nil {
+			return fmt.Errorf("creating demo salary: %w", err)
+		}
+
+		for _, expense := range demoExpenses {
+			if expense.day > lastDay {
+				continue
+			}
+			date := month.AddDate(0, 0, expense.day-1).Format("2006-01-02")
+			if _, err := transaction.Exec(`
+				INSERT INTO transactions (amount, type, category, description, date, account_id)
+				VALUES (?, 'expense', ?, ?, ?, 1)
+			`, expense.amount, expense.category, expense.description, date); err != nil {
+				return fmt.Errorf("creating demo expense: %w", err)
+			}
+		}
+
+		if _, err := transaction.Exec(`
+			INSERT INTO transactions (amount, type, category, description, date, account_id, dest_account_id)
+			VALUES (300, 'transfer', '', 'Monthly savings', ?, 1, ?)
+		`, month.AddDate(0, 0, 1).Format("2006-01-02"), savingsID); err != nil {
+			return fmt.Errorf("creating demo transfer: %w", err)
+		}
+	}
+
+	if _, err := transaction.Exec(`
+		INSERT INTO budgets (name, category, amount, month) VALUES ('Groceries', 'groceries', 300, NULL)
+	`); err != nil {
+		return fmt.Errorf("creating demo budget: %w", err)
+	}
+
+	result, err = transaction.Exec(`
+		INSERT INTO savings_goals (name, target_amount, current_amount, target_date, icon, color)
+		VALUES ('Summer trip', 2000, 900, ?, 'plane', '#F59E0B')
+	`, firstOfMonth.AddDate(0, 8, 0).Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("creating demo goal: %w", err)
+	}
+	goalID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("reading demo goal ID: %w", err)
+	}
+	// The goal's 900 was saved in three monthly contributions, so the goals
+	// widget can project when it is reached.
+	for offset := demoMonths - 1; offset >= 0; offset-- {
+		if _, err := transaction.Exec(`
+			INSERT INTO goal_contributions (goal_id, amount, date) VALUES (?, 300, ?)
+		`, goalID, firstOfMonth.AddDate(0, -offset, 0).Format("2006-01-02")); err != nil {
+			return fmt.Errorf("creating demo goal contribution: %w", err)
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	p.reportsHandler.Invalidate()
+	return nil
+}
@@ -0,0 +1,196 @@
+package exports
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+	_ "modernc.org/sqlite"
+)
+
+// newTestService creates a Service over a database migrated with the plugin's SQL migrations.
+func newTestService(t *testing.T) (*Service, *sql.DB) {
+	t.Helper()
+
+	db, err := shared.OpenDatabase(filepath.Join(t.TempDir(), "exports_test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatalf("listing migrations: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		if _, err := db.Exec(string(migration)); err != nil {
+			t.Fatalf("running %s: %v", file, err)
+		}
+	}
+
+	return NewService(NewRepository(db), t.TempDir()), db
+}
+
+func TestPreviousMonth(t *testing.T) {
+	tests := map[string]string{
+		"2026-03-31": "2026-02",
+		"2026-01-01": "2025-12",
+		"2026-07-15": "2026-06",
+	}
+	for day, want := range tests {
+		now, _ := time.Parse("2006-01-02", day)
+		if got := previousMonth(now); got != want {
+			t.Errorf("previousMonth(%s) = %s, want %s", day, got, want)
+		}
+	}
+}
+
+func TestBuildCSV_SignsAmountsAndSanitizes(t *testing.T) {
+	savings := int64(2)
+	rows := []exportRow{
+		{ID: 1, Date: "2026-02-01", Type: "income", Category: "salary", Amount: 1000, AccountName: "Main"},
+		{ID: 2, Date: "2026-02-02", Type: "expense", Category: "food", Description: "=HYPERLINK(\"x\")", Amount: 12.5, AccountName: "Main"},
+		{ID: 3, Date: "2026-02-03", Type: "transfer", Amount: 100, AccountName: "Main", DestAccountID: &savings, DestAccount: "Savings"},
+	}
+
+	main, err := buildCSV(1, rows)
+	if err != nil {
+		t.Fatalf("buildCSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(main)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d lines", len(lines))
+	}
+	if !strings.Contains(lines[1], ",1000.00,") || !strings.Contains(lines[2], ",-12.50,") || !strings.Contains(lines[3], ",-100.00,") {
+		t.Errorf("unexpected signed amounts for the source account:\n%s", main)
+	}
+	if !strings.Contains(lines[2], `'=HYPERLINK`) {
+		t.Errorf("expected formula-like cells to be neutralized: %s", lines[2])
+	}
+
+	dest, err := buildCSV(savings, rows[2:])
+	if err != nil {
+		t.Fatalf("buildCSV failed: %v", err)
+	}
+	if !strings.Contains(string(dest), ",100.00,") {
+		t.Errorf("expected incoming transfer to be positive for the destination account:\n%s", dest)
+	}
+}
+
+func TestRunDue_ExportsPreviousMonthOnce(t *testing.T) {
+	service, db := newTestService(t)
+	target := t.TempDir()
+
+	if _, err := db.Exec(`INSERT INTO transactions (amount, type, category, date) VALUES (20, 'expense', 'food', '2026-02-10')`); err != nil {
+		t.Fatalf("inserting transaction: %v", err)
+	}
+	if _, appErr := service.Create(&ScheduleInput{Name: "Accountant", Target: target, DayOfMonth: 5}); appErr != nil {
+		t.Fatalf("creating schedule: %v", appErr)
+	}
+
+	// Before the configured day nothing runs.
+	attempted, err := service.RunDue(time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC))
+	if err != nil || attempted != 0 {
+		t.Fatalf("expected no runs before day_of_month, got %d (err %v)", attempted, err)
+	}
+
+	attempted, err = service.RunDue(time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC))
+	if err != nil || attempted != 1 {
+		t.Fatalf("expected 1 run on day_of_month, got %d (err %v)", attempted, err)
+	}
+	data, err := os.ReadFile(filepath.Join(target, exportFilename("2026-02", 1)))
+	if err != nil {
+		t.Fatalf("expected export file: %v", err)
+	}
+	if !strings.Contains(string(data), "2026-02-10,expense,food") {
+		t.Errorf("unexpected export content:\n%s", data)
+	}
+
+	// A successful month is not exported again.
+	attempted, err = service.RunDue(time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC))
+	if err != nil || attempted != 0 {
+		t.Errorf("expected the month to be exported once, got %d more runs (err %v)", attempted, err)
+	}
+}
+
+func TestRunDue_UploadsToHTTPTarget(t *testing.T) {
+	service, db := newTestService(t)
+
+	var uploads []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		uploads = append(uploads, request.Header.Get("X-Export-Filename"))
+	}))
+	defer server.Close()
+
+	if _, err := db.Exec(`INSERT INTO transactions (amount, type, category, date) VALUES (20, 'expense', 'food', '2026-02-10')`); err != nil {
+		t.Fatalf("inserting transaction: %v", err)
+	}
+	if _, appErr := service.Create(&ScheduleInput{Name: "Backup", TargetType: TargetHTTP, Target: server.URL, DayOfMonth: 1}); appErr != nil {
+		t.Fatalf("creating schedule: %v", appErr)
+	}
+
+	attempted, err := service.RunDue(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil || attempted != 1 {
+		t.Fatalf("expected 1 run, got %d (err %v)", attempted, err)
+	}
+	if len(uploads) != 1 || uploads[0] != exportFilename("2026-02", 1) {
+		t.Errorf("unexpected uploads: %v", uploads)
+	}
+}
+
+func TestRunDue_RetriesFailedMonthInPlace(t *testing.T) {
+	service, _ := newTestService(t)
+
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if failing {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	schedule, appErr := service.Create(&ScheduleInput{Name: "Backup", TargetType: TargetHTTP, Target: server.URL, DayOfMonth: 1})
+	if appErr != nil {
+		t.Fatalf("creating schedule: %v", appErr)
+	}
+
+	for hour := 9; hour < 12; hour++ {
+		if _, err := service.RunDue(time.Date(2026, 3, 1, hour, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatalf("RunDue failed: %v", err)
+		}
+	}
+	runs, appErr := service.Runs(schedule.ID)
+	if appErr != nil {
+		t.Fatalf("listing runs: %v", appErr)
+	}
+	if len(runs) != 1 || runs[0].Status != StatusFailed || !strings.Contains(runs[0].Error, "503") {
+		t.Fatalf("expected a single failed run after three checks, got %+v", runs)
+	}
+	failedID := runs[0].ID
+
+	failing = false
+	attempted, err := service.RunDue(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil || attempted != 1 {
+		t.Fatalf("expected the failed month to be retried, got %d (err %v)", attempted, err)
+	}
+	runs, _ = service.Runs(schedule.ID)
+	if len(runs) != 1 || runs[0].ID != failedID || runs[0].Status != StatusSuccess || runs[0].Error != "" {
+		t.Errorf("expected the failed run to become a success, got %+v", runs)
+	}
+
+	if attempted, _ := service.RunDue(time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC)); attempted != 0 {
+		t.Errorf("expected no runs once the month succeeded, got %d", attempted)
+	}
+}
@@ -0,0 +1,151 @@
+package exports
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Handler routes export-related API requests to the appropriate service method.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Handler with all layers wired together. dataDir is the
+// plugin data directory that relative export targets resolve against.
+func NewHandler(db *sql.DB, dataDir string) *Handler {
+	repo := NewRepository(db)
+	svc := NewService(repo, dataDir)
+	return &Handler{service: svc}
+}
+
+// Handle dispatches the request to the correct handler based on method and path.
+func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	switch {
+	case req.Method == "GET" && req.Path == "/exports":
+		return h.list()
+	case req.Method == "POST" && req.Path == "/exports":
+		return h.create(req)
+	case req.Method == "GET" && strings.HasPrefix(req.Path, "/exports/") && strings.HasSuffix(req.Path, "/runs"):
+		return h.runs(req)
+	case req.Method == "POST" && strings.HasPrefix(req.Path, "/exports/") && strings.HasSuffix(req.Path, "/run"):
+		return h.rerun(req)
+	case req.Method == "GET" && strings.HasPrefix(req.Path, "/exports/"):
+		return h.get(req)
+	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/exports/"):
+		return h.update(req)
+	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/exports/"):
+		return h.delete(req)
+	default:
+		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
+	}
+}
+
+// StartScheduler runs due exports in the background until the returned function is called.
+func (h *Handler) StartScheduler(interval time.Duration) (stop func()) {
+	return h.service.StartScheduler(interval)
+}
+
+func (h *Handler) list() (*sdk.APIResponse, error) {
+	schedules, appErr := h.service.List()
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, schedules)
+}
+
+func (h *Handler) get(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	schedule, appErr := h.service.Get(id)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, schedule)
+}
+
+func (h *Handler) create(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input ScheduleInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	schedule, appErr := h.service.Create(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(201, schedule)
+}
+
+func (h *Handler) update(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	var input ScheduleInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	schedule, appErr := h.service.Update(id, &input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, schedule)
+}
+
+func (h *Handler) delete(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	if appErr := h.service.Delete(id); appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, map[string]interface{}{"deleted": id})
+}
+
+func (h *Handler) runs(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	runs, appErr := h.service.Runs(id)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, runs)
+}
+
+func (h *Handler) rerun(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	var input RunInput
+	if len(req.Body) > 0 {
+		if err := json.Unmarshal(req.Body, &input); err != nil {
+			return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+		}
+	}
+	if month := req.Query["month"]; month != "" {
+		input.Month = month
+	}
+
+	run, appErr := h.service.Rerun(id, input.Month, time.Now())
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, run)
+}
@@ -0,0 +1,75 @@
+package exports
+
+// Schedule configures a monthly CSV export. AccountID nil exports every
+// account, one file each. Target is a directory path (relative paths resolve
+// against the plugin data directory) or an HTTP(S) URL files are POSTed to.
+type Schedule struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	AccountID  *int64 `json:"account_id,omitempty"`
+	TargetType string `json:"target_type"`
+	Target     string `json:"target"`
+	DayOfMonth int    `json:"day_of_month"`
+	IsActive   bool   `json:"is_active"`
+	CreatedAt  string `json:"created_at"`
+	LastRun    *Run   `json:"last_run"`
+}
+
+// Run records one export attempt for a schedule and month.
+type Run struct {
+	ID         int64    `json:"id"`
+	ScheduleID int64    `json:"schedule_id"`
+	Month      string   `json:"month"`
+	Status     string   `json:"status"`
+	Files      []string `json:"files"`
+	Error      string   `json:"error,omitempty"`
+	Rows       int      `json:"rows"`
+	StartedAt  string   `json:"started_at"`
+	FinishedAt string   `json:"finished_at"`
+}
+
+// ScheduleInput holds the input for creating or updating a schedule.
+type ScheduleInput struct {
+	Name       string `json:"name"`
+	AccountID  *int64 `json:"account_id"`
+	TargetType string `json:"target_type"`
+	Target     string `json:"target"`
+	DayOfMonth int    `json:"day_of_month"`
+	IsActive   *bool  `json:"is_active"`
+}
+
+// RunInput selects the month to (re-)export. Empty means the previous month.
+type RunInput struct {
+	Month string `json:"month"`
+}
+
+// Run statuses.
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Target types.
+const (
+	TargetDirectory = "directory"
+	TargetHTTP      = "http"
+)
+
+// exportRow is one transaction line in an exported CSV.
+type exportRow struct {
+	ID            int64
+	Date          string
+	Type          string
+	Category      string
+	Description   string
+	Amount        float64
+	AccountName   string
+	DestAccountID *int64
+	DestAccount   string
+}
+
+// exportAccount identifies an account to export.
+type exportAccount struct {
+	ID   int64
+	Name string
+}
@@ -0,0 +1,393 @@
+package exports
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Repository handles database operations for export schedules and runs.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository backed by the given database connection.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// List returns all schedules ordered by ID, each with its most recent run.
+func (r *Repository) List() ([]Schedule, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, account_id, target_type, target, day_of_month, is_active, created_at
+		FROM export_schedules
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying export schedules: %w", err)
+	}
+
+	schedules := make([]Schedule, 0)
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("iterating export schedules: %w", err)
+	}
+	rows.Close()
+
+	for i := range schedules {
+		run, err := r.lastRun(schedules[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		schedules[i].LastRun = run
+	}
+	return schedules, nil
+}
+
+// GetByID returns a single schedule with its most recent run.
+func (r *Repository) GetByID(id int64) (*Schedule, *shared.AppError) {
+	row := r.db.QueryRow(`
+		SELECT id, name, account_id, target_type, target, day_of_month, is_active, created_at
+		FROM export_schedules WHERE id = ?
+	`, id)
+
+	schedule, err := scanSchedule(row)
+	if err == sql.ErrNoRows {
+		return nil, shared.NewNotFoundError("export schedule", fmt.Sprintf("%d", id))
+	}
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying export schedule: %v", err), 500)
+	}
+
+	run, err := r.lastRun(id)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying last export run: %v", err), 500)
+	}
+	schedule.LastRun = run
+	return schedule, nil
+}
+
+// Create inserts a new schedule and returns its ID.
+func (r *Repository) Create(input *ScheduleInput) (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO export_schedules (name, account_id, target_type, target, day_of_month, is_active)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, input.Name, nullableID(input.AccountID), input.TargetType, input.Target, input.DayOfMonth, activeFlag(input.IsActive))
+	if err != nil {
+		return 0, fmt.Errorf("inserting export schedule: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// Update modifies an existing schedule.
+func (r *Repository) Update(id int64, input *ScheduleInput) error {
+	result, err := r.db.Exec(`
+		UPDATE export_schedules
+		SET name = ?, account_id = ?, target_type = ?, target = ?, day_of_month = ?, is_active = ?
+		WHERE id = ?
+	`, input.Name, nullableID(input.AccountID), input.TargetType, input.Target, input.DayOfMonth, activeFlag(input.IsActive), id)
+	if err != nil {
+		return fmt.Errorf("updating export schedule: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return shared.NewNotFoundError("export schedule", fmt.Sprintf("%d", id))
+	}
+	return nil
+}
+
+// Delete removes a schedule and its run history. Exported files are kept.
+func (r *Repository) Delete(id int64) error {
+	result, err := r.db.Exec("DELETE FROM export_schedules WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting export schedule: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return shared.NewNotFoundError("export schedule", fmt.Sprintf("%d", id))
+	}
+	return nil
+}
+
+// ListActive returns all active schedules.
+func (r *Repository) ListActive() ([]Schedule, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, account_id, target_type, target, day_of_month, is_active, created_at
+		FROM export_schedules
+		WHERE is_active = 1
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying active export schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]Schedule, 0)
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating active export schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// LastRunForMonth returns a schedule's most recent run for the given month,
+// or nil if it never exported that month.
+func (r *Repository) LastRunForMonth(scheduleID int64, month string) (*Run, error) {
+	run, err := scanRun(r.db.QueryRow(`
+		SELECT id, schedule_id, month, status, files, COALESCE(error, ''), rows, started_at, finished_at
+		FROM export_runs
+		WHERE schedule_id = ? AND month = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`, scheduleID, month))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return run, err
+}
+
+// InsertRun records an export attempt and returns its ID.
+func (r *Repository) InsertRun(run *Run) (int64, error) {
+	files, err := json.Marshal(run.Files)
+	if err != nil {
+		return 0, fmt.Errorf("encoding export files: %w", err)
+	}
+
+	var errorText interface{}
+	if run.Error != "" {
+		errorText = run.Error
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO export_runs (schedule_id, month, status, files, error, rows, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.ScheduleID, run.Month, run.Status, string(files), errorText, run.Rows, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return 0, fmt.Errorf("inserting export run: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// UpdateRun replaces the outcome recorded for an existing run.
+func (r *Repository) UpdateRun(run *Run) error {
+	files, err := json.Marshal(run.Files)
+	if err != nil {
+		return fmt.Errorf("encoding export files: %w", err)
+	}
+
+	var errorText interface{}
+	if run.Error != "" {
+		errorText = run.Error
+	}
+
+	if _, err := r.db.Exec(`
+		UPDATE export_runs
+		SET status = ?, files = ?, error = ?, rows = ?, started_at = ?, finished_at = ?
+		WHERE id = ?
+	`, run.Status, string(files), errorText, run.Rows, run.StartedAt, run.FinishedAt, run.ID); err != nil {
+		return fmt.Errorf("updating export run: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns a schedule's runs, newest first.
+func (r *Repository) ListRuns(scheduleID int64, limit int) ([]Run, error) {
+	rows, err := r.db.Query(`
+		SELECT id, schedule_id, month, status, files, COALESCE(error, ''), rows, started_at, finished_at
+		FROM export_runs
+		WHERE schedule_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying export runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]Run, 0)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating export runs: %w", err)
+	}
+	return runs, nil
+}
+
+// ListAccounts returns the accounts a schedule exports: the given one, or all
+// non-archived accounts when accountID is nil.
+func (r *Repository) ListAccounts(accountID *int64) ([]exportAccount, error) {
+	query := "SELECT id, name FROM accounts WHERE is_archived = 0 ORDER BY id"
+	args := []interface{}{}
+	if accountID != nil {
+		query = "SELECT id, name FROM accounts WHERE id = ?"
+		args = append(args, *accountID)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying export accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]exportAccount, 0)
+	for rows.Next() {
+		var account exportAccount
+		if err := rows.Scan(&account.ID, &account.Name); err != nil {
+			return nil, fmt.Errorf("scanning export account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating export accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// ListRows returns the month's transactions touching an account, including
+// transfers into it, ordered by date.
+func (r *Repository) ListRows(accountID int64, month string) ([]exportRow, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.date, t.type, t.category, COALESCE(t.description, ''), t.amount,
+		       COALESCE(a.name, ''), t.dest_account_id, COALESCE(d.name, '')
+		FROM transactions t
+		LEFT JOIN accounts a ON a.id = t.account_id
+		LEFT JOIN accounts d ON d.id = t.dest_account_id
+		WHERE (t.account_id = ? OR t.dest_account_id = ?) AND t.date LIKE ? AND t.status != 'void'
+		ORDER BY t.date, t.id
+	`, accountID, accountID, month+"%")
+	if err != nil {
+		return nil, fmt.Errorf("querying export rows: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]exportRow, 0)
+	for rows.Next() {
+		var row exportRow
+		var destAccountID sql.NullInt64
+		if err := rows.Scan(
+			&row.ID, &row.Date, &row.Type, &row.Category, &row.Description, &row.Amount,
+			&row.AccountName, &destAccountID, &row.DestAccount,
+		); err != nil {
+			return nil, fmt.Errorf("scanning export row: %w", err)
+		}
+		if destAccountID.Valid {
+			id := destAccountID.Int64
+			row.DestAccountID = &id
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating export rows: %w", err)
+	}
+	return result, nil
+}
+
+// AccountExists checks whether an account with the given ID exists.
+func (r *Repository) AccountExists(id int64) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM accounts WHERE id = ?", id).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("checking account existence: %w", err)
+	}
+	return count > 0, nil
+}
+
+// lastRun returns a schedule's most recent run, or nil if it never ran.
+func (r *Repository) lastRun(scheduleID int64) (*Run, error) {
+	runs, err := r.ListRuns(scheduleID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSchedule reads a single schedule from a row.
+func scanSchedule(row rowScanner) (*Schedule, error) {
+	var schedule Schedule
+	var accountID sql.NullInt64
+	var isActive int
+
+	if err := row.Scan(
+		&schedule.ID, &schedule.Name, &accountID, &schedule.TargetType, &schedule.Target,
+		&schedule.DayOfMonth, &isActive, &schedule.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scanning export schedule: %w", err)
+	}
+
+	if accountID.Valid {
+		id := accountID.Int64
+		schedule.AccountID = &id
+	}
+	schedule.IsActive = isActive == 1
+	return &schedule, nil
+}
+
+// scanRun reads a single run from a row.
+func scanRun(row rowScanner) (*Run, error) {
+	var run Run
+	var files string
+
+	if err := row.Scan(
+		&run.ID, &run.ScheduleID, &run.Month, &run.Status, &files, &run.Error,
+		&run.Rows, &run.StartedAt, &run.FinishedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scanning export run: %w", err)
+	}
+
+	run.Files = []string{}
+	if err := json.Unmarshal([]byte(files), &run.Files); err != nil {
+		return nil, fmt.Errorf("decoding export files: %w", err)
+	}
+	return &run, nil
+}
+
+// nullableID converts an optional ID to a value suitable for a nullable column.
+func nullableID(id *int64) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// activeFlag converts an optional is_active input to a column value, defaulting to active.
+func activeFlag(isActive *bool) int {
+	if isActive != nil && !*isActive {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,312 @@
+package exports
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+const (
+	// uploadTimeout bounds a single upload to an HTTP target.
+	uploadTimeout = 30 * time.Second
+	// runHistoryLimit caps the runs returned per schedule.
+	runHistoryLimit = 50
+)
+
+// Service contains the business logic for scheduled exports.
+type Service struct {
+	repo    *Repository
+	dataDir string
+	client  *http.Client
+
+	// mu serializes export runs so the scheduler and a manual re-run never
+	// write the same files at once.
+	mu sync.Mutex
+}
+
+// NewService creates a Service that resolves relative directory targets against dataDir.
+func NewService(repo *Repository, dataDir string) *Service {
+	return &Service{
+		repo:    repo,
+		dataDir: dataDir,
+		client:  &http.Client{Timeout: uploadTimeout},
+	}
+}
+
+// List returns all schedules with their latest run.
+func (s *Service) List() ([]Schedule, *shared.AppError) {
+	schedules, err := s.repo.List()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to list export schedules", 500)
+	}
+	return schedules, nil
+}
+
+// Get returns a single schedule with its latest run.
+func (s *Service) Get(id int64) (*Schedule, *shared.AppError) {
+	return s.repo.GetByID(id)
+}
+
+// Create validates input and inserts a new schedule.
+func (s *Service) Create(input *ScheduleInput) (*Schedule, *shared.AppError) {
+	if appErr := s.validateInput(input); appErr != nil {
+		return nil, appErr
+	}
+
+	id, err := s.repo.Create(input)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to create export schedule", 500)
+	}
+	return s.repo.GetByID(id)
+}
+
+// Update validates input and modifies an existing schedule.
+func (s *Service) Update(id int64, input *ScheduleInput) (*Schedule, *shared.AppError) {
+	if appErr := s.validateInput(input); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.repo.Update(id, input); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return nil, appErr
+		}
+		return nil, shared.NewAppError("INTERNAL", "failed to update export schedule", 500)
+	}
+	return s.repo.GetByID(id)
+}
+
+// Delete removes a schedule.
+func (s *Service) Delete(id int64) *shared.AppError {
+	if err := s.repo.Delete(id); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return appErr
+		}
+		return shared.NewAppError("INTERNAL", "failed to delete export schedule", 500)
+	}
+	return nil
+}
+
+// Runs returns a schedule's run history, newest first.
+func (s *Service) Runs(id int64) ([]Run, *shared.AppError) {
+	if _, appErr := s.repo.GetByID(id); appErr != nil {
+		return nil, appErr
+	}
+
+	runs, err := s.repo.ListRuns(id, runHistoryLimit)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to list export runs", 500)
+	}
+	return runs, nil
+}
+
+// Rerun exports a month for a schedule on demand, even if it already succeeded.
+// A failed export is still recorded and returned, so the caller sees the error.
+func (s *Service) Rerun(id int64, month string, now time.Time) (*Run, *shared.AppError) {
+	if month == "" {
+		month = previousMonth(now)
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return nil, shared.NewValidationError("month must be in YYYY-MM format")
+	}
+
+	schedule, appErr := s.repo.GetByID(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return s.execute(schedule, month, now, nil)
+}
+
+// RunDue exports the previous month for every active schedule whose day has
+// come and that has not exported that month successfully yet. Failed exports
+// are retried on the next check, updating the failed run in place so the run
+// history holds one entry per month rather than one per check. It returns the
+// number of runs attempted.
+func (s *Service) RunDue(now time.Time) (int, error) {
+	schedules, err := s.repo.ListActive()
+	if err != nil {
+		return 0, err
+	}
+
+	month := previousMonth(now)
+	attempted := 0
+	for i := range schedules {
+		schedule := &schedules[i]
+		if now.Day() < schedule.DayOfMonth {
+			continue
+		}
+
+		last, err := s.repo.LastRunForMonth(schedule.ID, month)
+		if err != nil {
+			return attempted, err
+		}
+		if last != nil && last.Status == StatusSuccess {
+			continue
+		}
+
+		if _, appErr := s.execute(schedule, month, now, last); appErr != nil {
+			return attempted, appErr
+		}
+		attempted++
+	}
+	return attempted, nil
+}
+
+// StartScheduler checks for due exports immediately and then at every
+// interval, until the returned stop function is called. Stop waits for an
+// in-flight check to finish.
+func (s *Service) StartScheduler(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// Errors are recorded per run; a failing check is retried next tick.
+			_, _ = s.RunDue(time.Now())
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// execute writes one CSV per account for the month to the schedule's target
+// and records the outcome. When retry is a failed run for the month, the
+// outcome replaces it instead of being recorded as another run.
+func (s *Service) execute(schedule *Schedule, month string, now time.Time, retry *Run) (*Run, *shared.AppError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run := &Run{
+		ScheduleID: schedule.ID,
+		Month:      month,
+		Status:     StatusSuccess,
+		Files:      []string{},
+		StartedAt:  now.UTC().Format(time.RFC3339),
+	}
+
+	if err := s.writeExports(schedule, month, run); err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+
+	if retry != nil && retry.Status == StatusFailed {
+		run.ID = retry.ID
+		if err := s.repo.UpdateRun(run); err != nil {
+			return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("recording export run: %v", err), 500)
+		}
+		return run, nil
+	}
+
+	id, err := s.repo.InsertRun(run)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("recording export run: %v", err), 500)
+	}
+	run.ID = id
+	return run, nil
+}
+
+// writeExports builds and delivers the CSV files, filling in run.Files and run.Rows.
+func (s *Service) writeExports(schedule *Schedule, month string, run *Run) error {
+	accounts, err := s.repo.ListAccounts(schedule.AccountID)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		rows, err := s.repo.ListRows(account.ID, month)
+		if err != nil {
+			return err
+		}
+
+		data, err := buildCSV(account.ID, rows)
+		if err != nil {
+			return err
+		}
+
+		filename := exportFilename(month, account.ID)
+		var location string
+		switch schedule.TargetType {
+		case TargetHTTP:
+			location, err = upload(s.client, schedule.Target, filename, data)
+		default:
+			location, err = writeToDirectory(resolveDirectory(s.dataDir, schedule.Target), filename, data)
+		}
+		if err != nil {
+			return err
+		}
+
+		run.Files = append(run.Files, location)
+		run.Rows += len(rows)
+	}
+	return nil
+}
+
+// validateInput applies defaults and checks that a schedule is well-formed.
+func (s *Service) validateInput(input *ScheduleInput) *shared.AppError {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return shared.NewValidationError("name is required")
+	}
+
+	if input.TargetType == "" {
+		input.TargetType = TargetDirectory
+	}
+	if input.TargetType == TargetDirectory && strings.TrimSpace(input.Target) == "" {
+		input.Target = "exports"
+	}
+
+	switch input.TargetType {
+	case TargetDirectory:
+	case TargetHTTP:
+		if !strings.HasPrefix(input.Target, "https://") && !strings.HasPrefix(input.Target, "http://") {
+			return shared.NewValidationError("target must be an http or https URL for http targets")
+		}
+	default:
+		return shared.NewValidationError("target_type must be 'directory' or 'http'")
+	}
+
+	if input.DayOfMonth == 0 {
+		input.DayOfMonth = 1
+	}
+	if input.DayOfMonth < 1 || input.DayOfMonth > 28 {
+		return shared.NewValidationError("day_of_month must be between 1 and 28")
+	}
+
+	if input.AccountID != nil {
+		exists, err := s.repo.AccountExists(*input.AccountID)
+		if err != nil {
+			return shared.NewAppError("INTERNAL", "failed to check account", 500)
+		}
+		if !exists {
+			return shared.NewValidationError(fmt.Sprintf("account %d not found", *input.AccountID))
+		}
+	}
+	return nil
+}
+
+// previousMonth returns the YYYY-MM month before now.
+func previousMonth(now time.Time) string {
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return firstOfMonth.AddDate(0, -1, 0).Format("2006-01")
+}
@@ -0,0 +1,126 @@
+package exports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// csvHeader lists the exported columns. Amounts are signed from the exported
+// account's point of view: money in is positive, money out is negative.
+var csvHeader = []string{"date", "type", "category", "description", "amount", "account", "dest_account", "transaction_id"}
+
+// buildCSV renders an account's transactions as CSV.
+func buildCSV(accountID int64, rows []exportRow) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, fmt.Errorf("writing csv header: %w", err)
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.Date,
+			row.Type,
+			shared.SanitizeCell(row.Category),
+			shared.SanitizeCell(row.Description),
+			strconv.FormatFloat(signedAmount(accountID, row), 'f', 2, 64),
+			shared.SanitizeCell(row.AccountName),
+			shared.SanitizeCell(row.DestAccount),
+			strconv.FormatInt(row.ID, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("writing csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("flushing csv: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// signedAmount returns a transaction's amount as seen by the exported account.
+func signedAmount(accountID int64, row exportRow) float64 {
+	switch row.Type {
+	case "income":
+		return row.Amount
+	case "transfer":
+		if row.DestAccountID != nil && *row.DestAccountID == accountID {
+			return row.Amount
+		}
+		return -row.Amount
+	default:
+		return -row.Amount
+	}
+}
+
+// exportFilename names the CSV for an account and month.
+func exportFilename(month string, accountID int64) string {
+	return fmt.Sprintf("transactions-%s-account-%d.csv", month, accountID)
+}
+
+// resolveDirectory resolves a directory target; relative paths are relative
+// to the plugin data directory.
+func resolveDirectory(dataDir string, target string) string {
+	if filepath.IsAbs(target) {
+		return target
+	}
+	return filepath.Join(dataDir, target)
+}
+
+// writeToDirectory writes a file atomically into dir and returns its path.
+func writeToDirectory(dir string, filename string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating export directory: %w", err)
+	}
+
+	path := filepath.Join(dir, filename)
+	temp, err := os.CreateTemp(dir, "."+filename+"-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temp export file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return "", fmt.Errorf("writing export file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return "", fmt.Errorf("closing export file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return "", fmt.Errorf("moving export file into place: %w", err)
+	}
+	return path, nil
+}
+
+// upload POSTs a file to an HTTP target and returns a description of where it went.
+// The plugin needs the network permission for uploads to leave the host.
+func upload(client *http.Client, url string, filename string, data []byte) (string, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("building upload request: %w", err)
+	}
+	request.Header.Set("Content-Type", "text/csv")
+	request.Header.Set("X-Export-Filename", filename)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("uploading export: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return "", fmt.Errorf("upload target returned status %d", response.StatusCode)
+	}
+	return url + "#" + filename, nil
+}
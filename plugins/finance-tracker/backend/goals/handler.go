@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/alvarotorresc/cortex/pkg/sdk"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
@@ -108,3 +109,13 @@ func (h *Handler) contribute(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	}
 	return shared.JSONSuccess(200, goal)
 }
+
+// WidgetData returns the goals widget payload: every goal with its progress
+// and projected completion date as of now.
+func (h *Handler) WidgetData(now time.Time) ([]byte, error) {
+	projections, appErr := h.service.Projections(now)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return json.Marshal(map[string]interface{}{"data": projections})
+}
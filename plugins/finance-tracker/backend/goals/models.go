@@ -31,7 +31,22 @@ type UpdateGoalInput struct {
 	Color        string  `json:"color"`
 }
 
-// ContributeInput holds the amount to add to a savings goal.
+// ContributeInput holds the amount to add to a savings goal. Date is
+// YYYY-MM-DD and defaults to today.
 type ContributeInput struct {
 	Amount float64 `json:"amount"`
+	Date   string  `json:"date"`
+}
+
+// GoalProjection is a goal with its progress and the date it is projected
+// to be reached at the average rate of the last three months' contributions.
+// ProjectedDate is nil for completed goals and goals nothing was saved
+// towards recently; OnTrack is nil for goals without a target date.
+type GoalProjection struct {
+	SavingsGoal
+	Percentage     float64 `json:"percentage"`
+	Remaining      float64 `json:"remaining"`
+	MonthlyAverage float64 `json:"monthly_average"`
+	ProjectedDate  *string `json:"projected_date"`
+	OnTrack        *bool   `json:"on_track"`
 }
@@ -124,14 +124,21 @@ func (r *Repository) Delete(id int64) error {
 	return nil
 }
 
-// UpdateAmountAndCompletion atomically updates current_amount and is_completed.
-func (r *Repository) UpdateAmountAndCompletion(id int64, newAmount float64, isCompleted bool) error {
+// AddContribution records a contribution and updates current_amount and
+// is_completed in one transaction.
+func (r *Repository) AddContribution(id int64, amount float64, date string, newAmount float64, isCompleted bool) error {
 	completed := 0
 	if isCompleted {
 		completed = 1
 	}
 
-	result, err := r.db.Exec(`
+	transaction, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec(`
 		UPDATE savings_goals
 		SET current_amount = ?, is_completed = ?
 		WHERE id = ?
@@ -144,9 +151,79 @@ func (r *Repository) UpdateAmountAndCompletion(id int64, newAmount float64, isCo
 	if rowsAffected == 0 {
 		return shared.NewNotFoundError("goal", fmt.Sprintf("%d", id))
 	}
+
+	if err := insertContribution(transaction, id, amount, date); err != nil {
+		return err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("committing goal contribution: %w", err)
+	}
 	return nil
 }
 
+// Contribute adds amount to a goal within tx, completing it once it reaches
+// its target, and records the contribution. Round-ups save through it, so
+// their savings show up in the goal's history and projections like any
+// other contribution.
+func Contribute(tx *sql.Tx, id int64, amount float64, date string) error {
+	result, err := tx.Exec(`
+		UPDATE savings_goals
+		SET current_amount = current_amount + ?,
+		    is_completed = CASE WHEN current_amount + ? >= target_amount THEN 1 ELSE is_completed END
+		WHERE id = ?
+	`, amount, amount, id)
+	if err != nil {
+		return fmt.Errorf("updating goal amount: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return shared.NewNotFoundError("goal", fmt.Sprintf("%d", id))
+	}
+
+	return insertContribution(tx, id, amount, date)
+}
+
+// insertContribution records a contribution to a goal within tx.
+func insertContribution(tx *sql.Tx, id int64, amount float64, date string) error {
+	if _, err := tx.Exec(`
+		INSERT INTO goal_contributions (goal_id, amount, date) VALUES (?, ?, ?)
+	`, id, amount, date); err != nil {
+		return fmt.Errorf("inserting goal contribution: %w", err)
+	}
+	return nil
+}
+
+// ContributionsBetween returns each goal's total contributions dated after
+// from and up to to, both YYYY-MM-DD, keyed by goal ID.
+func (r *Repository) ContributionsBetween(from, to string) (map[int64]float64, error) {
+	rows, err := r.db.Query(`
+		SELECT goal_id, SUM(amount)
+		FROM goal_contributions
+		WHERE date > ? AND date <= ?
+		GROUP BY goal_id
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying goal contributions: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[int64]float64)
+	for rows.Next() {
+		var goalID int64
+		var total float64
+		if err := rows.Scan(&goalID, &total); err != nil {
+			return nil, fmt.Errorf("scanning goal contribution: %w", err)
+		}
+		totals[goalID] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating goal contributions: %w", err)
+	}
+	return totals, nil
+}
+
 // scanGoals reads all rows from the result set into a slice of SavingsGoal.
 func scanGoals(rows *sql.Rows) ([]SavingsGoal, error) {
 	goals := make([]SavingsGoal, 0)
@@ -1,11 +1,22 @@
 package goals
 
 import (
+	"math"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
 
+const (
+	// dateLayout is the format of contribution dates.
+	dateLayout = "2006-01-02"
+	// projectionMonths is how many trailing months of contributions the
+	// projected completion date averages.
+	projectionMonths = 3
+)
+
 // Service contains the business logic for savings goal operations.
 type Service struct {
 	repo *Repository
@@ -83,6 +94,11 @@ func (s *Service) Contribute(id int64, input *ContributeInput) (*SavingsGoal, *s
 	if input.Amount <= 0 {
 		return nil, shared.NewValidationError("amount must be greater than 0")
 	}
+	if input.Date == "" {
+		input.Date = time.Now().Format(dateLayout)
+	} else if _, err := time.Parse(dateLayout, input.Date); err != nil {
+		return nil, shared.NewValidationError("date must be in YYYY-MM-DD format")
+	}
 
 	goal, appErr := s.repo.GetByID(id)
 	if appErr != nil {
@@ -92,7 +108,7 @@ func (s *Service) Contribute(id int64, input *ContributeInput) (*SavingsGoal, *s
 	newAmount := goal.CurrentAmount + input.Amount
 	isCompleted := newAmount >= goal.TargetAmount
 
-	if err := s.repo.UpdateAmountAndCompletion(id, newAmount, isCompleted); err != nil {
+	if err := s.repo.AddContribution(id, input.Amount, input.Date, newAmount, isCompleted); err != nil {
 		if appErr, ok := err.(*shared.AppError); ok {
 			return nil, appErr
 		}
@@ -106,6 +122,55 @@ func (s *Service) Contribute(id int64, input *ContributeInput) (*SavingsGoal, *s
 	return updated, nil
 }
 
+// Projections returns every goal with its progress and projected completion
+// date, open goals first. The projection assumes saving continues at the
+// average daily rate of the contributions in the last projectionMonths up to
+// now.
+func (s *Service) Projections(now time.Time) ([]GoalProjection, *shared.AppError) {
+	goals, err := s.repo.List()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to list goals", 500)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	windowStart := today.AddDate(0, -projectionMonths, 0)
+	contributed, err := s.repo.ContributionsBetween(windowStart.Format(dateLayout), today.Format(dateLayout))
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to load goal contributions", 500)
+	}
+	windowDays := today.Sub(windowStart).Hours() / 24
+
+	projections := make([]GoalProjection, 0, len(goals))
+	for _, goal := range goals {
+		projection := GoalProjection{
+			SavingsGoal:    goal,
+			Remaining:      shared.RoundCents(math.Max(goal.TargetAmount-goal.CurrentAmount, 0)),
+			MonthlyAverage: shared.RoundCents(contributed[goal.ID] / projectionMonths),
+		}
+		if goal.TargetAmount > 0 {
+			projection.Percentage = math.Min(math.Round(goal.CurrentAmount/goal.TargetAmount*10000)/100, 100)
+		}
+
+		if !goal.IsCompleted && projection.Remaining > 0 && contributed[goal.ID] > 0 {
+			dailyRate := contributed[goal.ID] / windowDays
+			days := int(math.Ceil(projection.Remaining / dailyRate))
+			projected := today.AddDate(0, 0, days).Format(dateLayout)
+			projection.ProjectedDate = &projected
+		}
+		if goal.TargetDate != nil && !goal.IsCompleted {
+			onTrack := projection.ProjectedDate != nil && *projection.ProjectedDate <= *goal.TargetDate
+			projection.OnTrack = &onTrack
+		}
+
+		projections = append(projections, projection)
+	}
+
+	sort.SliceStable(projections, func(i, j int) bool {
+		return !projections[i].IsCompleted && projections[j].IsCompleted
+	})
+	return projections, nil
+}
+
 // --- Validation ---
 
 func validateCreateInput(input *CreateGoalInput) *shared.AppError {
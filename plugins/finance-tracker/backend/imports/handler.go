@@ -0,0 +1,128 @@
+package imports
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Handler routes import-related API requests to the appropriate service method.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Handler with all layers wired together.
+func NewHandler(db *sql.DB) *Handler {
+	repo := NewRepository(db)
+	svc := NewService(repo)
+	return &Handler{service: svc}
+}
+
+// Handle dispatches the request to the correct handler based on method and path.
+func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	switch {
+	case req.Method == "POST" && req.Path == "/import":
+		return h.importCSV(req)
+	case req.Method == "GET" && req.Path == "/import/presets":
+		return h.listPresets()
+	case req.Method == "POST" && req.Path == "/import/presets":
+		return h.createPreset(req)
+	case req.Method == "GET" && strings.HasPrefix(req.Path, "/import/presets/"):
+		return h.getPreset(req)
+	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/import/presets/"):
+		return h.updatePreset(req)
+	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/import/presets/"):
+		return h.deletePreset(req)
+	default:
+		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
+	}
+}
+
+func (h *Handler) importCSV(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input ImportInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	result, appErr := h.service.Import(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	status := 201
+	if result.Imported == 0 {
+		status = 200
+	}
+	return shared.JSONSuccess(status, result)
+}
+
+func (h *Handler) listPresets() (*sdk.APIResponse, error) {
+	presets, appErr := h.service.ListPresets()
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, presets)
+}
+
+func (h *Handler) getPreset(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := presetIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	preset, appErr := h.service.GetPreset(id)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, preset)
+}
+
+func (h *Handler) createPreset(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input PresetInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	preset, appErr := h.service.CreatePreset(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(201, preset)
+}
+
+func (h *Handler) updatePreset(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := presetIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	var input PresetInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	preset, appErr := h.service.UpdatePreset(id, &input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, preset)
+}
+
+func (h *Handler) deletePreset(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := presetIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	if appErr := h.service.DeletePreset(id); appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, map[string]interface{}{"deleted": id})
+}
+
+// presetIDFromPath reads the preset ID from /import/presets/{id}.
+func presetIDFromPath(path string) (int64, *shared.AppError) {
+	return shared.ExtractIDFromPath(strings.TrimPrefix(path, "/import"))
+}
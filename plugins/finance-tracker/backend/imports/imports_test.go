@@ -0,0 +1,200 @@
+package imports
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+	_ "modernc.org/sqlite"
+)
+
+// newTestService creates a Service over a database migrated with the plugin's SQL migrations.
+func newTestService(t *testing.T) (*Service, *sql.DB) {
+	t.Helper()
+
+	db, err := shared.OpenDatabase(filepath.Join(t.TempDir(), "imports_test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	files, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatalf("listing migrations: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		if _, err := db.Exec(string(migration)); err != nil {
+			t.Fatalf("running %s: %v", file, err)
+		}
+	}
+
+	return NewService(NewRepository(db)), db
+}
+
+func TestParseAmount_Separators(t *testing.T) {
+	tests := []struct {
+		value, decimal, thousands string
+		want                      float64
+	}{
+		{"-1.234,56", ",", ".", -1234.56},
+		{"1,234.56", ".", ",", 1234.56},
+		{"+45,90", ",", ".", 45.90},
+		{"1 234,50", ",", " ", 1234.50},
+		{"12.5", ".", "", 12.5},
+	}
+	for _, test := range tests {
+		got, err := parseAmount(test.value, test.decimal, test.thousands)
+		if err != nil || got != test.want {
+			t.Errorf("parseAmount(%q) = %v, %v; want %v", test.value, got, err, test.want)
+		}
+	}
+
+	if _, err := parseAmount("twelve", ".", ""); err == nil {
+		t.Error("expected an error for a non-numeric amount")
+	}
+}
+
+func TestGoDateLayout(t *testing.T) {
+	if layout, err := goDateLayout("DD.MM.YYYY"); err != nil || layout != "02.01.2006" {
+		t.Errorf("unexpected layout %q, %v", layout, err)
+	}
+	if layout, err := goDateLayout("YYYY-MM-DD HH:mm:ss"); err != nil || layout != "2006-01-02 15:04:05" {
+		t.Errorf("unexpected layout %q, %v", layout, err)
+	}
+	for _, format := range []string{"MM/YYYY", "DD/MM/YYYY Z", "2006-01-02"} {
+		if _, err := goDateLayout(format); err == nil {
+			t.Errorf("expected %q to be rejected", format)
+		}
+	}
+}
+
+func TestImport_BuiltinPresetWithPreambleAndSplitAmounts(t *testing.T) {
+	svc, db := newTestService(t)
+
+	csv := "Kontoumsätze Girokonto\n" +
+		"Zeitraum: 01.03.2026 - 31.03.2026\n" +
+		"Kontonummer: 1234567\n" +
+		"\n" +
+		"Buchungstag;Wert;Verwendungszweck;Soll;Haben;Währung\n" +
+		"02.03.2026;02.03.2026;Miete März;-850,00;;EUR\n" +
+		"15.03.2026;15.03.2026;Gehalt;;2.450,00;EUR\n" +
+		"31.02.2026;31.02.2026;Kaputt;-1,00;;EUR\n" +
+		";;;;;\n"
+
+	result, appErr := svc.Import(&ImportInput{CSV: csv, Preset: "Deutsche-Bank"})
+	if appErr != nil {
+		t.Fatalf("Import failed: %v", appErr)
+	}
+	if result.Imported != 2 || len(result.Errors) != 1 {
+		t.Fatalf("expected 2 imported rows and 1 error, got %+v", result)
+	}
+	if result.Errors[0].Line != 8 || !strings.Contains(result.Errors[0].Message, "invalid date") {
+		t.Errorf("unexpected row error: %+v", result.Errors[0])
+	}
+
+	rent := result.Transactions[0]
+	if rent.Date != "2026-03-02" || rent.Type != "expense" || rent.Amount != 850 || rent.Category != "other" || rent.Description != "Miete März" {
+		t.Errorf("unexpected rent row: %+v", rent)
+	}
+	if salary := result.Transactions[1]; salary.Type != "income" || salary.Amount != 2450 {
+		t.Errorf("unexpected salary row: %+v", salary)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM transactions WHERE account_id = 1").Scan(&count); err != nil {
+		t.Fatalf("counting transactions: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 stored transactions, got %d", count)
+	}
+}
+
+func TestImport_DryRunAndMissingColumn(t *testing.T) {
+	svc, db := newTestService(t)
+	csv := "Booking Date,Partner Name,Amount (EUR)\n2026-03-04,Bakery,-3.20\n"
+
+	result, appErr := svc.Import(&ImportInput{CSV: csv, Preset: "n26", DryRun: true})
+	if appErr != nil {
+		t.Fatalf("Import failed: %v", appErr)
+	}
+	if result.Imported != 0 || len(result.Transactions) != 1 || result.Transactions[0].Description != "Bakery" {
+		t.Errorf("unexpected dry run result: %+v", result)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count); err != nil {
+		t.Fatalf("counting transactions: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected a dry run to store nothing, got %d transactions", count)
+	}
+
+	if _, appErr := svc.Import(&ImportInput{CSV: csv, Preset: "bbva"}); appErr == nil || appErr.StatusCode != 400 {
+		t.Errorf("expected a missing column to be rejected, got %v", appErr)
+	}
+	if _, appErr := svc.Import(&ImportInput{CSV: csv, Preset: "unknown-bank"}); appErr == nil || appErr.StatusCode != 404 {
+		t.Errorf("expected an unknown preset to be not found, got %v", appErr)
+	}
+}
+
+func TestPresets_SaveAndImportByName(t *testing.T) {
+	svc, _ := newTestService(t)
+
+	preset, appErr := svc.CreatePreset(&PresetInput{
+		Name:              "My Credit Union",
+		Delimiter:         "|",
+		DateColumn:        "when",
+		DateFormat:        "DD/MM/YY",
+		AmountColumn:      "value",
+		DescriptionColumn: "what",
+		CategoryColumn:    "kind",
+		DecimalSeparator:  ",",
+	})
+	if appErr != nil {
+		t.Fatalf("CreatePreset failed: %v", appErr)
+	}
+	if preset.ID == nil || preset.Builtin || preset.ThousandsSeparator != "" {
+		t.Errorf("unexpected saved preset: %+v", preset)
+	}
+
+	result, appErr := svc.Import(&ImportInput{CSV: "when|what|value|kind\n05/03/26|Cinema|-9,50|Entertainment\n", Preset: "my credit union"})
+	if appErr != nil {
+		t.Fatalf("Import failed: %v", appErr)
+	}
+	if result.Imported != 1 || result.Transactions[0].Category != "entertainment" || result.Transactions[0].Date != "2026-03-05" {
+		t.Errorf("unexpected import with saved preset: %+v", result)
+	}
+
+	presets, appErr := svc.ListPresets()
+	if appErr != nil {
+		t.Fatalf("ListPresets failed: %v", appErr)
+	}
+	if len(presets) != len(builtinPresets)+1 || !presets[0].Builtin || presets[len(presets)-1].Name != "My Credit Union" {
+		t.Errorf("expected built-in presets followed by the saved one, got %d presets", len(presets))
+	}
+
+	if _, appErr := svc.CreatePreset(&PresetInput{Name: "Revolut", DateColumn: "d", AmountColumn: "a"}); appErr == nil || appErr.StatusCode != 409 {
+		t.Errorf("expected a built-in name to conflict, got %v", appErr)
+	}
+	if _, appErr := svc.CreatePreset(&PresetInput{Name: "my credit union", DateColumn: "d", AmountColumn: "a"}); appErr == nil || appErr.StatusCode != 409 {
+		t.Errorf("expected a duplicate name to conflict, got %v", appErr)
+	}
+	if _, appErr := svc.CreatePreset(&PresetInput{Name: "Both", DateColumn: "d", AmountColumn: "a", DebitColumn: "b"}); appErr == nil || appErr.StatusCode != 400 {
+		t.Errorf("expected amount and debit columns together to be rejected, got %v", appErr)
+	}
+
+	if appErr := svc.DeletePreset(*preset.ID); appErr != nil {
+		t.Fatalf("DeletePreset failed: %v", appErr)
+	}
+	if _, appErr := svc.GetPreset(*preset.ID); appErr == nil || appErr.StatusCode != 404 {
+		t.Errorf("expected the deleted preset to be gone, got %v", appErr)
+	}
+}
@@ -0,0 +1,83 @@
+package imports
+
+// Preset describes how to read a bank's CSV export: the layout of its
+// columns, how dates are written and which decimal separator amounts use.
+// Built-in presets have no ID and cannot be changed.
+//
+// Column fields name CSV header cells. The amount is read from AmountColumn,
+// signed, or from DebitColumn and CreditColumn when the bank splits it.
+// DateFormat is written with DD, MM, YYYY, YY, HH, mm and ss, as in
+// "DD.MM.YYYY".
+type Preset struct {
+	ID                 *int64 `json:"id"`
+	Name               string `json:"name"`
+	Builtin            bool   `json:"builtin"`
+	Delimiter          string `json:"delimiter"`
+	SkipRows           int    `json:"skip_rows"`
+	DateColumn         string `json:"date_column"`
+	DateFormat         string `json:"date_format"`
+	AmountColumn       string `json:"amount_column"`
+	DebitColumn        string `json:"debit_column"`
+	CreditColumn       string `json:"credit_column"`
+	DescriptionColumn  string `json:"description_column"`
+	CategoryColumn     string `json:"category_column"`
+	DecimalSeparator   string `json:"decimal_separator"`
+	ThousandsSeparator string `json:"thousands_separator"`
+	CreatedAt          string `json:"created_at,omitempty"`
+}
+
+// PresetInput holds the input for creating or updating a custom preset.
+type PresetInput struct {
+	Name               string `json:"name"`
+	Delimiter          string `json:"delimiter"`
+	SkipRows           int    `json:"skip_rows"`
+	DateColumn         string `json:"date_column"`
+	DateFormat         string `json:"date_format"`
+	AmountColumn       string `json:"amount_column"`
+	DebitColumn        string `json:"debit_column"`
+	CreditColumn       string `json:"credit_column"`
+	DescriptionColumn  string `json:"description_column"`
+	CategoryColumn     string `json:"category_column"`
+	DecimalSeparator   string `json:"decimal_separator"`
+	ThousandsSeparator string `json:"thousands_separator"`
+}
+
+// ImportInput is the body of POST /import. Preset names a built-in or saved
+// preset; Mapping gives a layout inline instead. Category is used for rows
+// without a category column (default "other"). With DryRun the parsed rows
+// are returned without being saved.
+type ImportInput struct {
+	CSV       string       `json:"csv"`
+	Preset    string       `json:"preset"`
+	Mapping   *PresetInput `json:"mapping"`
+	AccountID int64        `json:"account_id"`
+	Category  string       `json:"category"`
+	DryRun    bool         `json:"dry_run"`
+}
+
+// ImportResult reports what an import did. Rows that could not be parsed are
+// listed in Errors and skipped; the others are imported.
+type ImportResult struct {
+	Preset       string        `json:"preset"`
+	Imported     int           `json:"imported"`
+	DryRun       bool          `json:"dry_run"`
+	Transactions []ImportedRow `json:"transactions"`
+	Errors       []RowError    `json:"errors"`
+}
+
+// ImportedRow is a CSV row read as a transaction.
+type ImportedRow struct {
+	Line        int     `json:"line"`
+	Date        string  `json:"date"`
+	Type        string  `json:"type"`
+	Amount      float64 `json:"amount"`
+	Category    string  `json:"category"`
+	Description string  `json:"description"`
+}
+
+// RowError is a CSV row that could not be imported. Line is 1-based and
+// counts the skipped rows and the header.
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,248 @@
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateFormatTokens translates the date format placeholders presets use into
+// Go layout elements. Longer tokens come first so YYYY is not read as YY twice.
+var dateFormatTokens = []string{
+	"YYYY", "2006",
+	"YY", "06",
+	"MM", "01",
+	"DD", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+}
+
+// dateSeparators are the characters a date format may use between tokens.
+const dateSeparators = " ./-:T"
+
+// goDateLayout converts a preset date format such as "DD.MM.YYYY" into a Go
+// time layout. A format must contain a year, a month and a day, and nothing
+// but the tokens and separators.
+func goDateLayout(format string) (string, error) {
+	remaining := format
+	for i := 0; i < len(dateFormatTokens); i += 2 {
+		remaining = strings.ReplaceAll(remaining, dateFormatTokens[i], "")
+	}
+	if strings.Trim(remaining, dateSeparators) != "" {
+		return "", fmt.Errorf("date_format may only contain DD, MM, YYYY, YY, HH, mm, ss and separators, got %q", format)
+	}
+
+	layout := strings.NewReplacer(dateFormatTokens...).Replace(format)
+	if !strings.Contains(layout, "06") || !strings.Contains(layout, "01") || !strings.Contains(layout, "02") {
+		return "", fmt.Errorf("date_format must include a day, a month and a year, got %q", format)
+	}
+	return layout, nil
+}
+
+// parseAmount reads a number written with the given decimal and thousands
+// separators, such as "-1.234,56" with "," and ".".
+func parseAmount(value string, decimalSeparator string, thousandsSeparator string) (float64, error) {
+	// Spaces, including non-breaking ones, also group thousands in some locales.
+	cleaned := strings.NewReplacer(" ", "", "\u00a0", "").Replace(strings.TrimSpace(value))
+	if thousandsSeparator != "" {
+		cleaned = strings.ReplaceAll(cleaned, thousandsSeparator, "")
+	}
+	if decimalSeparator != "." {
+		cleaned = strings.ReplaceAll(cleaned, decimalSeparator, ".")
+	}
+
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// columnIndex maps the preset's columns to their position in the CSV header.
+type columnIndex struct {
+	date, amount, debit, credit, description, category int
+}
+
+// indexColumns finds the preset's columns in header. Optional columns that
+// are not configured get -1; configured columns missing from the header are
+// an error, so a wrong preset fails loudly instead of importing blanks.
+func indexColumns(header []string, preset *Preset) (*columnIndex, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if _, seen := positions[name]; !seen {
+			positions[name] = i
+		}
+	}
+
+	find := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		position, ok := positions[name]
+		if !ok {
+			return -1, fmt.Errorf("column %q not found in the CSV header", name)
+		}
+		return position, nil
+	}
+
+	var index columnIndex
+	var err error
+	for _, column := range []struct {
+		name   string
+		target *int
+	}{
+		{preset.DateColumn, &index.date},
+		{preset.AmountColumn, &index.amount},
+		{preset.DebitColumn, &index.debit},
+		{preset.CreditColumn, &index.credit},
+		{preset.DescriptionColumn, &index.description},
+		{preset.CategoryColumn, &index.category},
+	} {
+		if *column.target, err = find(column.name); err != nil {
+			return nil, err
+		}
+	}
+	return &index, nil
+}
+
+// parseCSV reads content with preset, returning the rows that could be read
+// and an error for each that could not. The returned error is for problems
+// with the file as a whole, such as a missing header or column.
+func parseCSV(content string, preset *Preset, category string) ([]ImportedRow, []RowError, error) {
+	layout, err := goDateLayout(preset.DateFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content = strings.TrimPrefix(content, "\ufeff")
+	// Preamble lines before the header are dropped before parsing, since they
+	// rarely have the header's shape.
+	lines := strings.SplitAfterN(content, "\n", preset.SkipRows+1)
+	if len(lines) <= preset.SkipRows {
+		return nil, nil, errors.New("the CSV has no header row")
+	}
+
+	reader := csv.NewReader(strings.NewReader(lines[preset.SkipRows]))
+	reader.Comma = []rune(preset.Delimiter)[0]
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("the CSV has no header row")
+	}
+	index, err := indexColumns(header, preset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := []ImportedRow{}
+	rowErrors := []RowError{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("reading CSV: %w", err)
+			}
+			rowErrors = append(rowErrors, RowError{Line: parseErr.StartLine + preset.SkipRows, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		line += preset.SkipRows
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows)+len(rowErrors) >= MaxRows {
+			return nil, nil, fmt.Errorf("the CSV has more than %d rows", MaxRows)
+		}
+
+		row, err := parseRecord(record, index, preset, layout, category)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, *row)
+	}
+	return rows, rowErrors, nil
+}
+
+// parseRecord reads one CSV record as a transaction.
+func parseRecord(record []string, index *columnIndex, preset *Preset, layout string, category string) (*ImportedRow, error) {
+	field := func(position int) string {
+		if position < 0 || position >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[position])
+	}
+
+	date, err := time.Parse(layout, field(index.date))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected %s", field(index.date), preset.DateFormat)
+	}
+
+	var amount float64
+	if index.amount >= 0 {
+		if amount, err = parseAmount(field(index.amount), preset.DecimalSeparator, preset.ThousandsSeparator); err != nil {
+			return nil, err
+		}
+	} else {
+		debit, credit := field(index.debit), field(index.credit)
+		switch {
+		case debit != "":
+			value, err := parseAmount(debit, preset.DecimalSeparator, preset.ThousandsSeparator)
+			if err != nil {
+				return nil, err
+			}
+			amount = -math.Abs(value)
+		case credit != "":
+			value, err := parseAmount(credit, preset.DecimalSeparator, preset.ThousandsSeparator)
+			if err != nil {
+				return nil, err
+			}
+			amount = math.Abs(value)
+		default:
+			return nil, errors.New("row has neither a debit nor a credit amount")
+		}
+	}
+	if amount == 0 {
+		return nil, errors.New("amount is zero")
+	}
+
+	row := &ImportedRow{
+		Date:        date.Format("2006-01-02"),
+		Type:        "income",
+		Amount:      math.Abs(amount),
+		Category:    category,
+		Description: field(index.description),
+	}
+	if amount < 0 {
+		row.Type = "expense"
+	}
+	if value := field(index.category); value != "" {
+		row.Category = strings.ToLower(value)
+	}
+	return row, nil
+}
+
+// isBlankRecord reports whether every field of record is empty, as in the
+// trailing lines some banks append.
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
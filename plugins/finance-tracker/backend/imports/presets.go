@@ -0,0 +1,62 @@
+package imports
+
+import "strings"
+
+// builtinPresets are the CSV layouts of common European banks' exports,
+// selectable by name in POST /import. Banks change their exports from time to
+// time; a saved preset with the same layout fixes an import without waiting
+// for a release.
+var builtinPresets = []Preset{
+	{
+		Name: "n26", Builtin: true, Delimiter: ",",
+		DateColumn: "Booking Date", DateFormat: "YYYY-MM-DD",
+		AmountColumn: "Amount (EUR)", DescriptionColumn: "Partner Name",
+		DecimalSeparator: ".",
+	},
+	{
+		Name: "revolut", Builtin: true, Delimiter: ",",
+		DateColumn: "Completed Date", DateFormat: "YYYY-MM-DD HH:mm:ss",
+		AmountColumn: "Amount", DescriptionColumn: "Description",
+		DecimalSeparator: ".",
+	},
+	{
+		Name: "bbva", Builtin: true, Delimiter: ";",
+		DateColumn: "Fecha", DateFormat: "DD/MM/YYYY",
+		AmountColumn: "Importe", DescriptionColumn: "Concepto",
+		DecimalSeparator: ",", ThousandsSeparator: ".",
+	},
+	{
+		Name: "santander-es", Builtin: true, Delimiter: ";",
+		DateColumn: "Fecha Operación", DateFormat: "DD/MM/YYYY",
+		AmountColumn: "Importe", DescriptionColumn: "Concepto",
+		DecimalSeparator: ",", ThousandsSeparator: ".",
+	},
+	{
+		Name: "ing-de", Builtin: true, Delimiter: ";", SkipRows: 13,
+		DateColumn: "Buchung", DateFormat: "DD.MM.YYYY",
+		AmountColumn: "Betrag", DescriptionColumn: "Verwendungszweck",
+		DecimalSeparator: ",", ThousandsSeparator: ".",
+	},
+	{
+		Name: "deutsche-bank", Builtin: true, Delimiter: ";", SkipRows: 4,
+		DateColumn: "Buchungstag", DateFormat: "DD.MM.YYYY",
+		DebitColumn: "Soll", CreditColumn: "Haben", DescriptionColumn: "Verwendungszweck",
+		DecimalSeparator: ",", ThousandsSeparator: ".",
+	},
+	{
+		Name: "sparkasse", Builtin: true, Delimiter: ";",
+		DateColumn: "Buchungstag", DateFormat: "DD.MM.YY",
+		AmountColumn: "Betrag", DescriptionColumn: "Verwendungszweck",
+		DecimalSeparator: ",", ThousandsSeparator: ".",
+	},
+}
+
+// builtinPreset returns the built-in preset with the given name, ignoring case.
+func builtinPreset(name string) (Preset, bool) {
+	for _, preset := range builtinPresets {
+		if strings.EqualFold(preset.Name, name) {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
@@ -0,0 +1,190 @@
+package imports
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// presetColumns lists the import_presets columns in the order scanPreset reads them.
+const presetColumns = `id, name, delimiter, skip_rows, date_column, date_format, amount_column,
+	debit_column, credit_column, description_column, category_column,
+	decimal_separator, thousands_separator, created_at`
+
+// Repository handles database operations for import presets and imported transactions.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository backed by the given database connection.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// ListPresets returns the saved presets ordered by name.
+func (r *Repository) ListPresets() ([]Preset, error) {
+	rows, err := r.db.Query("SELECT " + presetColumns + " FROM import_presets ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("querying import presets: %w", err)
+	}
+	defer rows.Close()
+
+	presets := make([]Preset, 0)
+	for rows.Next() {
+		preset, err := scanPreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, *preset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating import presets: %w", err)
+	}
+	return presets, nil
+}
+
+// GetPreset returns a saved preset by ID.
+func (r *Repository) GetPreset(id int64) (*Preset, *shared.AppError) {
+	preset, err := scanPreset(r.db.QueryRow("SELECT "+presetColumns+" FROM import_presets WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, shared.NewNotFoundError("import preset", fmt.Sprintf("%d", id))
+	}
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying import preset: %v", err), 500)
+	}
+	return preset, nil
+}
+
+// GetPresetByName returns a saved preset by name, ignoring case, or nil if
+// there is none.
+func (r *Repository) GetPresetByName(name string) (*Preset, error) {
+	preset, err := scanPreset(r.db.QueryRow("SELECT "+presetColumns+" FROM import_presets WHERE name = ?", name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// CreatePreset inserts a new preset and returns its ID.
+func (r *Repository) CreatePreset(input *PresetInput) (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO import_presets (name, delimiter, skip_rows, date_column, date_format, amount_column,
+			debit_column, credit_column, description_column, category_column,
+			decimal_separator, thousands_separator)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.Name, input.Delimiter, input.SkipRows, input.DateColumn, input.DateFormat, input.AmountColumn,
+		input.DebitColumn, input.CreditColumn, input.DescriptionColumn, input.CategoryColumn,
+		input.DecimalSeparator, input.ThousandsSeparator)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 0, shared.NewConflictError(fmt.Sprintf("import preset '%s' already exists", input.Name))
+		}
+		return 0, fmt.Errorf("inserting import preset: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// UpdatePreset modifies an existing preset.
+func (r *Repository) UpdatePreset(id int64, input *PresetInput) error {
+	result, err := r.db.Exec(`
+		UPDATE import_presets
+		SET name = ?, delimiter = ?, skip_rows = ?, date_column = ?, date_format = ?, amount_column = ?,
+			debit_column = ?, credit_column = ?, description_column = ?, category_column = ?,
+			decimal_separator = ?, thousands_separator = ?
+		WHERE id = ?
+	`, input.Name, input.Delimiter, input.SkipRows, input.DateColumn, input.DateFormat, input.AmountColumn,
+		input.DebitColumn, input.CreditColumn, input.DescriptionColumn, input.CategoryColumn,
+		input.DecimalSeparator, input.ThousandsSeparator, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return shared.NewConflictError(fmt.Sprintf("import preset '%s' already exists", input.Name))
+		}
+		return fmt.Errorf("updating import preset: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return shared.NewNotFoundError("import preset", fmt.Sprintf("%d", id))
+	}
+	return nil
+}
+
+// DeletePreset removes a preset.
+func (r *Repository) DeletePreset(id int64) error {
+	result, err := r.db.Exec("DELETE FROM import_presets WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting import preset: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return shared.NewNotFoundError("import preset", fmt.Sprintf("%d", id))
+	}
+	return nil
+}
+
+// AccountExists checks whether an account with the given ID exists.
+func (r *Repository) AccountExists(id int64) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM accounts WHERE id = ?", id).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("checking account existence: %w", err)
+	}
+	return count > 0, nil
+}
+
+// InsertTransactions records the imported rows in one database transaction,
+// so an import is either saved whole or not at all.
+func (r *Repository) InsertTransactions(accountID int64, rows []ImportedRow) error {
+	transaction, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	statement, err := transaction.Prepare(`
+		INSERT INTO transactions (amount, type, category, description, date, account_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("preparing transaction insert: %w", err)
+	}
+	defer statement.Close()
+
+	for _, row := range rows {
+		if _, err := statement.Exec(row.Amount, row.Type, row.Category, row.Description, row.Date, accountID); err != nil {
+			return fmt.Errorf("inserting imported transaction: %w", err)
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPreset reads a single preset from a row.
+func scanPreset(row rowScanner) (*Preset, error) {
+	var preset Preset
+	var id int64
+	if err := row.Scan(
+		&id, &preset.Name, &preset.Delimiter, &preset.SkipRows, &preset.DateColumn, &preset.DateFormat,
+		&preset.AmountColumn, &preset.DebitColumn, &preset.CreditColumn, &preset.DescriptionColumn,
+		&preset.CategoryColumn, &preset.DecimalSeparator, &preset.ThousandsSeparator, &preset.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scanning import preset: %w", err)
+	}
+	preset.ID = &id
+	return &preset, nil
+}
@@ -0,0 +1,262 @@
+package imports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+const (
+	// MaxRows caps the transactions one import may hold.
+	MaxRows = 10000
+	// maxPresetNameLength caps the length of a saved preset's name.
+	maxPresetNameLength = 64
+	// maxSkipRows caps how many preamble lines a preset may skip.
+	maxSkipRows = 100
+	// defaultAccountID is the account imports go to unless one is given.
+	defaultAccountID = 1
+	// defaultCategory is given to imported rows without a category column.
+	defaultCategory = "other"
+)
+
+// delimiters are the field separators a preset may use.
+var delimiters = map[string]bool{",": true, ";": true, "\t": true, "|": true}
+
+// thousandsSeparators are the digit grouping characters a preset may strip.
+var thousandsSeparators = map[string]bool{"": true, ".": true, ",": true, " ": true, "'": true}
+
+// Service contains the business logic for CSV imports and their presets.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a Service with the given repository.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// ListPresets returns the built-in presets followed by the saved ones.
+func (s *Service) ListPresets() ([]Preset, *shared.AppError) {
+	saved, err := s.repo.ListPresets()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to list import presets", 500)
+	}
+	return append(append([]Preset{}, builtinPresets...), saved...), nil
+}
+
+// GetPreset returns a saved preset.
+func (s *Service) GetPreset(id int64) (*Preset, *shared.AppError) {
+	return s.repo.GetPreset(id)
+}
+
+// CreatePreset validates input and saves a new preset.
+func (s *Service) CreatePreset(input *PresetInput) (*Preset, *shared.AppError) {
+	if appErr := validatePreset(input); appErr != nil {
+		return nil, appErr
+	}
+
+	id, err := s.repo.CreatePreset(input)
+	if err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return nil, appErr
+		}
+		return nil, shared.NewAppError("INTERNAL", "failed to create import preset", 500)
+	}
+	return s.repo.GetPreset(id)
+}
+
+// UpdatePreset validates input and modifies a saved preset.
+func (s *Service) UpdatePreset(id int64, input *PresetInput) (*Preset, *shared.AppError) {
+	if appErr := validatePreset(input); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.repo.UpdatePreset(id, input); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return nil, appErr
+		}
+		return nil, shared.NewAppError("INTERNAL", "failed to update import preset", 500)
+	}
+	return s.repo.GetPreset(id)
+}
+
+// DeletePreset removes a saved preset.
+func (s *Service) DeletePreset(id int64) *shared.AppError {
+	if err := s.repo.DeletePreset(id); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return appErr
+		}
+		return shared.NewAppError("INTERNAL", "failed to delete import preset", 500)
+	}
+	return nil
+}
+
+// Import reads the CSV with the chosen preset or inline mapping and, unless
+// it is a dry run, records the rows that could be read as transactions.
+func (s *Service) Import(input *ImportInput) (*ImportResult, *shared.AppError) {
+	if strings.TrimSpace(input.CSV) == "" {
+		return nil, shared.NewValidationError("csv is required")
+	}
+
+	preset, appErr := s.resolvePreset(input)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if input.AccountID == 0 {
+		input.AccountID = defaultAccountID
+	}
+	exists, err := s.repo.AccountExists(input.AccountID)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to check account", 500)
+	}
+	if !exists {
+		return nil, shared.NewValidationError(fmt.Sprintf("account %d not found", input.AccountID))
+	}
+
+	category := strings.ToLower(strings.TrimSpace(input.Category))
+	if category == "" {
+		category = defaultCategory
+	}
+
+	rows, rowErrors, err := parseCSV(input.CSV, preset, category)
+	if err != nil {
+		return nil, shared.NewValidationError(err.Error())
+	}
+
+	result := &ImportResult{
+		Preset:       preset.Name,
+		DryRun:       input.DryRun,
+		Transactions: rows,
+		Errors:       rowErrors,
+	}
+	if input.DryRun || len(rows) == 0 {
+		return result, nil
+	}
+
+	if err := s.repo.InsertTransactions(input.AccountID, rows); err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to import transactions", 500)
+	}
+	result.Imported = len(rows)
+	return result, nil
+}
+
+// resolvePreset returns the inline mapping of an import, or the built-in or
+// saved preset it names.
+func (s *Service) resolvePreset(input *ImportInput) (*Preset, *shared.AppError) {
+	if input.Mapping != nil {
+		if appErr := validateLayout(input.Mapping); appErr != nil {
+			return nil, appErr
+		}
+		preset := presetFromInput(input.Mapping)
+		if preset.Name == "" {
+			preset.Name = "custom"
+		}
+		return preset, nil
+	}
+
+	name := strings.TrimSpace(input.Preset)
+	if name == "" {
+		return nil, shared.NewValidationError("preset or mapping is required")
+	}
+	if preset, ok := builtinPreset(name); ok {
+		return &preset, nil
+	}
+
+	preset, err := s.repo.GetPresetByName(name)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to look up import preset", 500)
+	}
+	if preset == nil {
+		return nil, shared.NewNotFoundError("import preset", name)
+	}
+	return preset, nil
+}
+
+// presetFromInput builds the preset an input describes.
+func presetFromInput(input *PresetInput) *Preset {
+	return &Preset{
+		Name:               input.Name,
+		Delimiter:          input.Delimiter,
+		SkipRows:           input.SkipRows,
+		DateColumn:         input.DateColumn,
+		DateFormat:         input.DateFormat,
+		AmountColumn:       input.AmountColumn,
+		DebitColumn:        input.DebitColumn,
+		CreditColumn:       input.CreditColumn,
+		DescriptionColumn:  input.DescriptionColumn,
+		CategoryColumn:     input.CategoryColumn,
+		DecimalSeparator:   input.DecimalSeparator,
+		ThousandsSeparator: input.ThousandsSeparator,
+	}
+}
+
+// validatePreset checks a preset to be saved: a unique name that does not
+// shadow a built-in preset, and a valid layout.
+func validatePreset(input *PresetInput) *shared.AppError {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return shared.NewValidationError("name is required")
+	}
+	if len(input.Name) > maxPresetNameLength {
+		return shared.NewValidationError(fmt.Sprintf("name must be at most %d characters", maxPresetNameLength))
+	}
+	if _, ok := builtinPreset(input.Name); ok {
+		return shared.NewConflictError(fmt.Sprintf("'%s' is a built-in import preset", input.Name))
+	}
+	return validateLayout(input)
+}
+
+// validateLayout applies defaults to a preset's layout and checks it.
+func validateLayout(input *PresetInput) *shared.AppError {
+	input.DateColumn = strings.TrimSpace(input.DateColumn)
+	input.AmountColumn = strings.TrimSpace(input.AmountColumn)
+	input.DebitColumn = strings.TrimSpace(input.DebitColumn)
+	input.CreditColumn = strings.TrimSpace(input.CreditColumn)
+	input.DescriptionColumn = strings.TrimSpace(input.DescriptionColumn)
+	input.CategoryColumn = strings.TrimSpace(input.CategoryColumn)
+	input.DateFormat = strings.TrimSpace(input.DateFormat)
+
+	if input.Delimiter == "" {
+		input.Delimiter = ","
+	}
+	if !delimiters[input.Delimiter] {
+		return shared.NewValidationError("delimiter must be one of , ; | or a tab")
+	}
+	if input.SkipRows < 0 || input.SkipRows > maxSkipRows {
+		return shared.NewValidationError(fmt.Sprintf("skip_rows must be between 0 and %d", maxSkipRows))
+	}
+
+	if input.DateColumn == "" {
+		return shared.NewValidationError("date_column is required")
+	}
+	if input.DateFormat == "" {
+		input.DateFormat = "YYYY-MM-DD"
+	}
+	if _, err := goDateLayout(input.DateFormat); err != nil {
+		return shared.NewValidationError(err.Error())
+	}
+
+	hasSplitAmount := input.DebitColumn != "" || input.CreditColumn != ""
+	if input.AmountColumn == "" && !hasSplitAmount {
+		return shared.NewValidationError("amount_column, or debit_column and credit_column, is required")
+	}
+	if input.AmountColumn != "" && hasSplitAmount {
+		return shared.NewValidationError("use either amount_column or debit_column and credit_column, not both")
+	}
+
+	if input.DecimalSeparator == "" {
+		input.DecimalSeparator = "."
+	}
+	if input.DecimalSeparator != "." && input.DecimalSeparator != "," {
+		return shared.NewValidationError("decimal_separator must be '.' or ','")
+	}
+	if !thousandsSeparators[input.ThousandsSeparator] {
+		return shared.NewValidationError("thousands_separator must be empty, '.', ',', ' ' or \"'\"")
+	}
+	if input.ThousandsSeparator == input.DecimalSeparator {
+		return shared.NewValidationError("thousands_separator must differ from decimal_separator")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package ledger
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Handler routes ledger API requests to the service layer.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Handler wired to the ledger service.
+func NewHandler(db *sql.DB) *Handler {
+	return &Handler{service: NewService(db)}
+}
+
+// Handle dispatches the request to the correct ledger handler.
+func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	switch {
+	case req.Method == "GET" && req.Path == "/ledger":
+		return h.month(req)
+	default:
+		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
+	}
+}
+
+func (h *Handler) month(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	month := req.Query["month"]
+	if month == "" {
+		month = time.Now().Format("2006-01")
+	}
+
+	ledger, appErr := h.service.Month(month)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, ledger)
+}
@@ -0,0 +1,53 @@
+package ledger
+
+// Ledger is every movement of a month across all accounts, with the balances
+// a two-column ledger shows: each account's opening and closing balance, and
+// running balances after every entry.
+type Ledger struct {
+	Month          string          `json:"month"`
+	OpeningBalance float64         `json:"opening_balance"`
+	ClosingBalance float64         `json:"closing_balance"`
+	Accounts       []AccountLedger `json:"accounts"`
+	Entries        []Entry         `json:"entries"`
+}
+
+// AccountLedger is one account's side of the month. Active accounts are
+// always listed; archived ones only when they moved during the month or
+// still hold a balance.
+type AccountLedger struct {
+	ID             int64   `json:"id"`
+	Name           string  `json:"name"`
+	Currency       string  `json:"currency"`
+	Color          string  `json:"color"`
+	IsArchived     bool    `json:"is_archived"`
+	OpeningBalance float64 `json:"opening_balance"`
+	ClosingBalance float64 `json:"closing_balance"`
+	Credits        float64 `json:"credits"`
+	Debits         float64 `json:"debits"`
+}
+
+// Entry is one transaction in the ledger. Debit and Credit are the money
+// leaving and entering the tracked accounts as a whole, so a transfer between
+// two accounts has neither; its movement is in Legs. Balance is the total of
+// all accounts after the entry.
+type Entry struct {
+	TransactionID int64   `json:"transaction_id"`
+	Date          string  `json:"date"`
+	Type          string  `json:"type"`
+	Category      string  `json:"category"`
+	Description   string  `json:"description"`
+	Amount        float64 `json:"amount"`
+	Debit         float64 `json:"debit"`
+	Credit        float64 `json:"credit"`
+	Balance       float64 `json:"balance"`
+	Legs          []Leg   `json:"legs"`
+}
+
+// Leg is the entry's effect on one account: Amount is signed, and Balance is
+// the account's running balance after it.
+type Leg struct {
+	AccountID   int64   `json:"account_id"`
+	AccountName string  `json:"account_name"`
+	Amount      float64 `json:"amount"`
+	Balance     float64 `json:"balance"`
+}
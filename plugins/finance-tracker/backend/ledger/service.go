@@ -0,0 +1,169 @@
+package ledger
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Service builds the combined month ledger. Like reports, it runs its queries
+// directly against *sql.DB. Archived transactions are included, so balances
+// match the account balances.
+type Service struct {
+	db *sql.DB
+}
+
+// NewService creates a new ledger Service.
+func NewService(db *sql.DB) *Service {
+	return &Service{db: db}
+}
+
+// Month returns the ledger for a YYYY-MM month.
+func (s *Service) Month(month string) (*Ledger, *shared.AppError) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, shared.NewValidationError("month must be in YYYY-MM format")
+	}
+	from := start.Format("2006-01-02")
+	until := start.AddDate(0, 1, 0).Format("2006-01-02")
+
+	accounts, err := s.loadAccounts(from)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", err.Error(), 500)
+	}
+
+	ledger := &Ledger{Month: month, Accounts: []AccountLedger{}, Entries: []Entry{}}
+	balances := make(map[int64]float64, len(accounts))
+	for _, account := range accounts {
+		balances[account.ID] = account.OpeningBalance
+		ledger.OpeningBalance += account.OpeningBalance
+	}
+
+	rows, err := s.db.Query(
+		`SELECT id, date, type, category, COALESCE(description, ''), amount, account_id, dest_account_id
+		 FROM all_transactions
+		 WHERE date >= ? AND date < ? AND status != 'void'
+		 ORDER BY date, id`,
+		from, until,
+	)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying ledger entries: %v", err), 500)
+	}
+	defer rows.Close()
+
+	moved := make(map[int64]bool)
+	total := ledger.OpeningBalance
+	for rows.Next() {
+		var entry Entry
+		var accountID int64
+		var destAccountID sql.NullInt64
+		if err := rows.Scan(
+			&entry.TransactionID, &entry.Date, &entry.Type, &entry.Category, &entry.Description,
+			&entry.Amount, &accountID, &destAccountID,
+		); err != nil {
+			return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("scanning ledger entry: %v", err), 500)
+		}
+
+		// The same signed movements as the account_entries view.
+		movements := []Leg{{AccountID: accountID, Amount: -entry.Amount}}
+		switch {
+		case entry.Type == "income":
+			movements[0].Amount = entry.Amount
+			entry.Credit = entry.Amount
+		case entry.Type == "transfer" && destAccountID.Valid:
+			movements = append(movements, Leg{AccountID: destAccountID.Int64, Amount: entry.Amount})
+		default:
+			// Expenses, and legacy transfers without a destination, leave the
+			// tracked accounts.
+			entry.Debit = entry.Amount
+		}
+
+		for i := range movements {
+			leg := &movements[i]
+			balances[leg.AccountID] = shared.RoundCents(balances[leg.AccountID] + leg.Amount)
+			leg.Balance = balances[leg.AccountID]
+			leg.AccountName = accounts[leg.AccountID].Name
+			moved[leg.AccountID] = true
+			total += leg.Amount
+		}
+		entry.Legs = movements
+		entry.Balance = shared.RoundCents(total)
+		ledger.Entries = append(ledger.Entries, entry)
+
+		for _, leg := range movements {
+			summary := accounts[leg.AccountID]
+			if leg.Amount > 0 {
+				summary.Credits = shared.RoundCents(summary.Credits + leg.Amount)
+			} else {
+				summary.Debits = shared.RoundCents(summary.Debits - leg.Amount)
+			}
+			accounts[leg.AccountID] = summary
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("iterating ledger entries: %v", err), 500)
+	}
+
+	for _, id := range sortedAccountIDs(accounts) {
+		account := accounts[id]
+		if account.IsArchived && !moved[id] && account.OpeningBalance == 0 {
+			continue
+		}
+		account.ClosingBalance = shared.RoundCents(balances[id])
+		ledger.Accounts = append(ledger.Accounts, account)
+	}
+	ledger.OpeningBalance = shared.RoundCents(ledger.OpeningBalance)
+	ledger.ClosingBalance = shared.RoundCents(total)
+	return ledger, nil
+}
+
+// loadAccounts returns every account keyed by ID, with its balance before
+// the from date, its own opening balance included, as the opening balance.
+func (s *Service) loadAccounts(from string) (map[int64]AccountLedger, error) {
+	rows, err := s.db.Query(
+		`SELECT a.id, a.name, a.currency, COALESCE(a.color, ''), a.is_archived,
+		        a.opening_balance + COALESCE((SELECT SUM(e.amount) FROM all_account_entries e
+		                  WHERE e.account_id = a.id AND e.date < ?), 0)
+		 FROM accounts a`,
+		from,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying ledger accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make(map[int64]AccountLedger)
+	for rows.Next() {
+		var account AccountLedger
+		var isArchived int
+		if err := rows.Scan(&account.ID, &account.Name, &account.Currency, &account.Color, &isArchived, &account.OpeningBalance); err != nil {
+			return nil, fmt.Errorf("scanning ledger account: %w", err)
+		}
+		account.IsArchived = isArchived == 1
+		account.OpeningBalance = shared.RoundCents(account.OpeningBalance)
+		accounts[account.ID] = account
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating ledger accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// sortedAccountIDs lists active accounts before archived ones, each by ID.
+func sortedAccountIDs(accounts map[int64]AccountLedger) []int64 {
+	ids := make([]int64, 0, len(accounts))
+	for id := range accounts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := accounts[ids[i]], accounts[ids[j]]
+		if a.IsArchived != b.IsArchived {
+			return !a.IsArchived
+		}
+		return a.ID < b.ID
+	})
+	return ids
+}
@@ -0,0 +1,23 @@
+-- Finance Tracker: round-up savings automation.
+-- Expenses on an account with a rule are rounded up to the next increment and
+-- the difference is moved to a savings account (real) and/or goal (virtual).
+
+-- One rule per source account
+CREATE TABLE IF NOT EXISTS roundup_rules (
+    account_id INTEGER PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    increment REAL NOT NULL DEFAULT 1 CHECK(increment > 0),
+    dest_account_id INTEGER REFERENCES accounts(id),
+    goal_id INTEGER REFERENCES savings_goals(id) ON DELETE SET NULL,
+    start_date TEXT NOT NULL,
+    is_active INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- One row per processed expense, so an expense is never rounded up twice
+CREATE TABLE IF NOT EXISTS roundups (
+    expense_id INTEGER PRIMARY KEY REFERENCES transactions(id) ON DELETE CASCADE,
+    transfer_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    goal_id INTEGER REFERENCES savings_goals(id) ON DELETE SET NULL,
+    amount REAL NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,28 @@
+-- Finance Tracker: scheduled CSV exports for accountants.
+-- Each schedule writes one CSV per account for the previous month to a
+-- directory or uploads it to an HTTP endpoint. Every attempt is recorded.
+
+CREATE TABLE IF NOT EXISTS export_schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    account_id INTEGER REFERENCES accounts(id) ON DELETE CASCADE,
+    target_type TEXT NOT NULL CHECK(target_type IN ('directory', 'http')),
+    target TEXT NOT NULL,
+    day_of_month INTEGER NOT NULL DEFAULT 1 CHECK(day_of_month BETWEEN 1 AND 28),
+    is_active INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS export_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    schedule_id INTEGER NOT NULL REFERENCES export_schedules(id) ON DELETE CASCADE,
+    month TEXT NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('success', 'failed')),
+    files TEXT NOT NULL DEFAULT '[]',
+    error TEXT,
+    rows INTEGER NOT NULL DEFAULT 0,
+    started_at TEXT NOT NULL,
+    finished_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_runs_schedule ON export_runs(schedule_id, month);
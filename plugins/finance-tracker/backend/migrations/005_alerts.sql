@@ -0,0 +1,24 @@
+-- Finance Tracker: alerts sent to the host's notification center when a
+-- budget is exceeded or a large expense is recorded.
+
+-- Single settings row. Expenses up to last_transaction_id have been checked,
+-- so history recorded before alerts existed never triggers one.
+CREATE TABLE IF NOT EXISTS alert_settings (
+    id INTEGER PRIMARY KEY CHECK(id = 1),
+    budget_alerts INTEGER NOT NULL DEFAULT 1,
+    large_expense_threshold REAL NOT NULL DEFAULT 500 CHECK(large_expense_threshold >= 0),
+    last_transaction_id INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+INSERT OR IGNORE INTO alert_settings (id, last_transaction_id)
+SELECT 1, COALESCE(MAX(id), 0) FROM transactions;
+
+-- One row per budget and month alerted, so a budget alerts once a month
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    budget_id INTEGER NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    month TEXT NOT NULL,
+    spent REAL NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (budget_id, month)
+);
@@ -0,0 +1,45 @@
+-- Finance Tracker: archival of old transactions.
+-- Transactions older than the retention period are moved from transactions to
+-- archived_transactions, so the table every list and monthly report reads
+-- stays small. all_transactions reads both, for balances and for reports
+-- asked to include archived history.
+
+-- Single settings row. A retention of 0 years keeps every transaction live.
+CREATE TABLE IF NOT EXISTS archive_settings (
+    id INTEGER PRIMARY KEY CHECK(id = 1),
+    retention_years INTEGER NOT NULL DEFAULT 0 CHECK(retention_years >= 0),
+    last_run_at TEXT,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+INSERT OR IGNORE INTO archive_settings (id) VALUES (1);
+
+-- The columns of transactions, plus the names of the transaction's tags
+-- (comma separated), since its transaction_tags rows are removed with it.
+CREATE TABLE IF NOT EXISTS archived_transactions (
+    id INTEGER PRIMARY KEY,
+    amount REAL NOT NULL,
+    type TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    description TEXT,
+    date TEXT NOT NULL,
+    account_id INTEGER NOT NULL DEFAULT 1,
+    dest_account_id INTEGER,
+    is_recurring_instance INTEGER NOT NULL DEFAULT 0,
+    recurring_rule_id INTEGER,
+    created_at TEXT NOT NULL,
+    tags TEXT NOT NULL DEFAULT '',
+    archived_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_archived_transactions_date ON archived_transactions(date);
+CREATE INDEX IF NOT EXISTS idx_archived_transactions_account ON archived_transactions(account_id);
+
+CREATE VIEW IF NOT EXISTS all_transactions AS
+    SELECT id, amount, type, category, description, date, account_id, dest_account_id,
+           is_recurring_instance, recurring_rule_id, created_at
+    FROM transactions
+    UNION ALL
+    SELECT id, amount, type, category, description, date, account_id, dest_account_id,
+           is_recurring_instance, recurring_rule_id, created_at
+    FROM archived_transactions;
@@ -0,0 +1,8 @@
+-- Finance Tracker: occurrences of a recurring rule skipped ahead of time.
+-- Generation passes over a skipped date without inserting a transaction.
+CREATE TABLE IF NOT EXISTS recurring_skips (
+    rule_id INTEGER NOT NULL REFERENCES recurring_rules(id) ON DELETE CASCADE,
+    date TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (rule_id, date)
+);
@@ -0,0 +1,26 @@
+-- Finance Tracker: double-entry view of account movements.
+-- A transaction is stored as one row, but a transfer moves money between two
+-- accounts: a debit on account_id and a credit on dest_account_id. These views
+-- expand every transaction into one signed entry per account it touches, so
+-- balances and per-account reports see both sides of a transfer. Existing
+-- transfers are covered as they are; a legacy transfer without a destination
+-- only debits its source, like money leaving the tracked accounts.
+
+CREATE VIEW IF NOT EXISTS account_entries AS
+    SELECT id AS transaction_id, account_id, type, date,
+           CASE WHEN type = 'income' THEN amount ELSE -amount END AS amount
+    FROM transactions
+    UNION ALL
+    SELECT id, dest_account_id, type, date, amount
+    FROM transactions
+    WHERE type = 'transfer' AND dest_account_id IS NOT NULL;
+
+-- The same entries for live and archived transactions.
+CREATE VIEW IF NOT EXISTS all_account_entries AS
+    SELECT id AS transaction_id, account_id, type, date,
+           CASE WHEN type = 'income' THEN amount ELSE -amount END AS amount
+    FROM all_transactions
+    UNION ALL
+    SELECT id, dest_account_id, type, date, amount
+    FROM all_transactions
+    WHERE type = 'transfer' AND dest_account_id IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Finance Tracker: saved CSV import mappings.
+-- Built-in presets for common banks live in code; these are the user's own.
+-- Column names refer to the CSV header; amounts come either from a signed
+-- amount column or from separate debit and credit columns.
+CREATE TABLE IF NOT EXISTS import_presets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    delimiter TEXT NOT NULL DEFAULT ',',
+    skip_rows INTEGER NOT NULL DEFAULT 0,
+    date_column TEXT NOT NULL,
+    date_format TEXT NOT NULL,
+    amount_column TEXT NOT NULL DEFAULT '',
+    debit_column TEXT NOT NULL DEFAULT '',
+    credit_column TEXT NOT NULL DEFAULT '',
+    description_column TEXT NOT NULL DEFAULT '',
+    category_column TEXT NOT NULL DEFAULT '',
+    decimal_separator TEXT NOT NULL DEFAULT '.',
+    thousands_separator TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
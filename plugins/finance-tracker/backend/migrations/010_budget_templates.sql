@@ -0,0 +1,19 @@
+-- Finance Tracker: named budget templates.
+-- A template is a set of budget limits that can be applied to any month,
+-- so a new month does not need its category limits entered again.
+CREATE TABLE IF NOT EXISTS budget_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+-- An item with an empty category is a global budget, as in budgets.
+CREATE TABLE IF NOT EXISTS budget_template_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id INTEGER NOT NULL REFERENCES budget_templates(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    amount REAL NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_template_items_template ON budget_template_items(template_id);
@@ -0,0 +1,19 @@
+-- Finance Tracker: savings goal contributions.
+-- Each contribution is kept so the goals widget can project a completion
+-- date from recent saving. Amounts saved before this migration are recorded
+-- as one contribution on the day the goal was created.
+CREATE TABLE IF NOT EXISTS goal_contributions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL REFERENCES savings_goals(id) ON DELETE CASCADE,
+    amount REAL NOT NULL,
+    date TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_contributions_goal_date ON goal_contributions(goal_id, date);
+
+INSERT INTO goal_contributions (goal_id, amount, date)
+SELECT id, current_amount, substr(created_at, 1, 10)
+FROM savings_goals
+WHERE current_amount > 0
+  AND NOT EXISTS (SELECT 1 FROM goal_contributions WHERE goal_id = savings_goals.id);
@@ -0,0 +1,50 @@
+-- Finance Tracker: pending, cleared and void transactions.
+-- A pending transaction has not reached the account yet, such as a card
+-- payment still being processed or a recurring instance due today; a void one
+-- was cancelled but is kept for the record. Existing transactions are cleared.
+-- Void transactions count in no balance or report, so the account entry views
+-- are rebuilt without them and carry the status for cleared balances.
+BEGIN;
+
+ALTER TABLE transactions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'cleared' CHECK(status IN ('pending', 'cleared', 'void'));
+
+ALTER TABLE archived_transactions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'cleared' CHECK(status IN ('pending', 'cleared', 'void'));
+
+CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status);
+
+DROP VIEW IF EXISTS all_account_entries;
+DROP VIEW IF EXISTS account_entries;
+DROP VIEW IF EXISTS all_transactions;
+
+CREATE VIEW all_transactions AS
+    SELECT id, amount, type, category, description, date, account_id, dest_account_id,
+           is_recurring_instance, recurring_rule_id, created_at, status
+    FROM transactions
+    UNION ALL
+    SELECT id, amount, type, category, description, date, account_id, dest_account_id,
+           is_recurring_instance, recurring_rule_id, created_at, status
+    FROM archived_transactions;
+
+CREATE VIEW account_entries AS
+    SELECT id AS transaction_id, account_id, type, date, status,
+           CASE WHEN type = 'income' THEN amount ELSE -amount END AS amount
+    FROM transactions
+    WHERE status != 'void'
+    UNION ALL
+    SELECT id, dest_account_id, type, date, status, amount
+    FROM transactions
+    WHERE type = 'transfer' AND dest_account_id IS NOT NULL AND status != 'void';
+
+CREATE VIEW all_account_entries AS
+    SELECT id AS transaction_id, account_id, type, date, status,
+           CASE WHEN type = 'income' THEN amount ELSE -amount END AS amount
+    FROM all_transactions
+    WHERE status != 'void'
+    UNION ALL
+    SELECT id, dest_account_id, type, date, status, amount
+    FROM all_transactions
+    WHERE type = 'transfer' AND dest_account_id IS NOT NULL AND status != 'void';
+
+COMMIT;
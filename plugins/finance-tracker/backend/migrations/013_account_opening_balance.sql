@@ -0,0 +1,5 @@
+-- Finance Tracker: opening balances.
+-- The money an account held before its first tracked transaction, so an
+-- account added to Cortex years after it was opened shows its real balance.
+-- Balances, the ledger and net worth start from it.
+ALTER TABLE accounts ADD COLUMN opening_balance REAL NOT NULL DEFAULT 0;
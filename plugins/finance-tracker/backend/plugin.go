@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -13,13 +15,20 @@ import (
 
 	"github.com/alvarotorresc/cortex/pkg/sdk"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/accounts"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/alerts"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/archive"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/budgets"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/categories"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/exports"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/goals"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/imports"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/investments"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/ledger"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/recurring"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/reports"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/roundup"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/stats"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/tags"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/transactions"
 )
@@ -27,6 +36,25 @@ import (
 //go:embed migrations/*.sql
 var migrations embed.FS
 
+// exportCheckInterval is how often the plugin looks for due scheduled exports.
+const exportCheckInterval = time.Hour
+
+// Widget slots declared in the manifest. dashboardSlot shows the monthly
+// balance, goalsSlot savings goals and their projected completion dates,
+// budgetsSlot the month's budgets, upcomingSlot the recurring transactions
+// due in the next 30 days and nextPaymentsSlot the next few of them, however
+// far ahead, with the accounts they are paid from.
+const (
+	dashboardSlot    = "dashboard-widget"
+	goalsSlot        = "goals-widget"
+	budgetsSlot      = "budgets-widget"
+	upcomingSlot     = "upcoming-widget"
+	nextPaymentsSlot = "upcoming-recurring"
+)
+
+// archiveInterval is how often the plugin archives transactions past the retention period.
+const archiveInterval = 24 * time.Hour
+
 // FinancePlugin implements sdk.CortexPlugin for personal finance tracking.
 type FinancePlugin struct {
 	db                  *sql.DB
@@ -39,6 +67,21 @@ type FinancePlugin struct {
 	transactionsHandler *transactions.Handler
 	recurringHandler    *recurring.Handler
 	reportsHandler      *reports.Handler
+	statsHandler        *stats.Handler
+	roundupHandler      *roundup.Handler
+	exportsHandler      *exports.Handler
+	alertsHandler       *alerts.Handler
+	archiveHandler      *archive.Handler
+	importsHandler      *imports.Handler
+	ledgerHandler       *ledger.Handler
+
+	// notifyChanged tells dashboards a topic's data changed. Nil means
+	// sdk.NotifyChanged.
+	notifyChanged func(topic string) error
+	// stopExports stops the scheduled exports loop started in Migrate.
+	stopExports func()
+	// stopArchive stops the archival loop started in Migrate.
+	stopArchive func()
 }
 
 // GetManifest returns the plugin's metadata.
@@ -50,7 +93,7 @@ func (p *FinancePlugin) GetManifest() (*sdk.Manifest, error) {
 		Description: "Complete personal finance management — accounts, budgets, goals, investments, reports",
 		Icon:        "wallet",
 		Color:       "#10B981",
-		Permissions: []string{"db:read", "db:write"},
+		Permissions: []string{"db:read", "db:write", "notifications", "network"},
 	}, nil
 }
 
@@ -119,31 +162,84 @@ func (p *FinancePlugin) Migrate(databasePath string) error {
 	p.transactionsHandler = transactions.NewHandler(p.db)
 	p.recurringHandler = recurring.NewHandler(p.db)
 	p.reportsHandler = reports.NewHandler(p.db)
+	p.statsHandler = stats.NewHandler(p.db)
+	p.roundupHandler = roundup.NewHandler(p.db)
+	p.exportsHandler = exports.NewHandler(p.db, filepath.Dir(databasePath))
+	p.alertsHandler = alerts.NewHandler(p.db, sdk.SendNotification)
+	p.archiveHandler = archive.NewHandler(p.db)
+	p.importsHandler = imports.NewHandler(p.db)
+	p.ledgerHandler = ledger.NewHandler(p.db)
+	p.loadSettings()
+
+	if p.stopExports != nil {
+		p.stopExports()
+	}
+	p.stopExports = p.exportsHandler.StartScheduler(exportCheckInterval)
+
+	if p.stopArchive != nil {
+		p.stopArchive()
+	}
+	p.stopArchive = p.archiveHandler.StartScheduler(archiveInterval, p.reportsHandler.Invalidate)
 
 	return nil
 }
 
+// Migrations lists the embedded SQL migrations so the host can lint them
+// before Migrate runs.
+func (p *FinancePlugin) Migrations() ([]sdk.MigrationFile, error) {
+	return sdk.EmbeddedMigrations(migrations, "migrations")
+}
+
+// Warmup primes the report cache after a load, so the first dashboard visit
+// does not pay for the aggregation queries.
+func (p *FinancePlugin) Warmup() error {
+	return p.reportsHandler.Warmup(time.Now())
+}
+
 // HandleAPI routes incoming API requests to the appropriate handler.
-func (p *FinancePlugin) HandleAPI(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+func (p *FinancePlugin) HandleAPI(ctx context.Context, req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Any write may change what the cached reports aggregate. They are
+	// dropped once the request, and the round-ups and alerts it triggered,
+	// are done.
+	if req.Method != "GET" {
+		defer p.reportsHandler.Invalidate()
+	}
+
+	var resp *sdk.APIResponse
+	var err error
 	switch {
 	case strings.HasPrefix(req.Path, "/transactions"):
-		return p.transactionsHandler.Handle(req)
+		resp, err = p.transactionsHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/categories"):
-		return p.categoriesHandler.Handle(req)
+		resp, err = p.categoriesHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/reports"):
-		return p.reportsHandler.Handle(req)
+		resp, err = p.reportsHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/accounts"):
-		return p.accountsHandler.Handle(req)
+		resp, err = p.accountsHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/budgets"):
-		return p.budgetsHandler.Handle(req)
+		resp, err = p.budgetsHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/goals"):
-		return p.goalsHandler.Handle(req)
+		resp, err = p.goalsHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/investments"):
-		return p.investmentsHandler.Handle(req)
+		resp, err = p.investmentsHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/tags"):
-		return p.tagsHandler.Handle(req)
+		resp, err = p.tagsHandler.Handle(req)
 	case strings.HasPrefix(req.Path, "/recurring"):
-		return p.recurringHandler.Handle(req)
+		resp, err = p.recurringHandler.Handle(req)
+	case strings.HasPrefix(req.Path, "/roundup"):
+		resp, err = p.roundupHandler.Handle(req)
+	case strings.HasPrefix(req.Path, "/alerts"):
+		resp, err = p.alertsHandler.Handle(req)
+	case strings.HasPrefix(req.Path, "/archive"):
+		resp, err = p.archiveHandler.Handle(req)
+	case strings.HasPrefix(req.Path, "/exports"):
+		resp, err = p.exportsHandler.Handle(req)
+	case strings.HasPrefix(req.Path, "/import"):
+		resp, err = p.importsHandler.Handle(req)
+	case strings.HasPrefix(req.Path, "/ledger"):
+		resp, err = p.ledgerHandler.Handle(req)
+	case strings.HasPrefix(req.Path, "/stats"):
+		resp, err = p.statsHandler.Handle(req)
 	// Legacy: /summary still works (redirects to reports).
 	case req.Method == "GET" && req.Path == "/summary":
 		req.Path = "/reports/summary"
@@ -151,6 +247,72 @@ func (p *FinancePlugin) HandleAPI(req *sdk.APIRequest) (*sdk.APIResponse, error)
 	default:
 		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
 	}
+	return p.afterWrite(req, resp, err)
+}
+
+// Search finds transactions for the host's global search.
+func (p *FinancePlugin) Search(query string) ([]sdk.SearchResult, error) {
+	return p.transactionsHandler.Search(query)
+}
+
+// RestoreDeleted puts back a transaction the host undoes the delete of. The
+// restore never goes through HandleAPI, so it drops the cached reports and
+// notifies dashboards itself.
+func (p *FinancePlugin) RestoreDeleted(kind string, data []byte) error {
+	if kind != transactions.DeletedKind {
+		return fmt.Errorf("cannot restore deleted %q", kind)
+	}
+	if err := p.transactionsHandler.RestoreDeleted(data); err != nil {
+		return err
+	}
+	p.reportsHandler.Invalidate()
+	p.notify("transactions")
+	return nil
+}
+
+// afterWrite runs the automations a successful write may trigger: the
+// round-ups of new or changed expenses after writes to transactions,
+// recurring rules or imports, then the budget and large expense alerts after
+// those and budget writes. It then tells dashboards that the write's topic
+// changed. Reads never trigger them, so a GET writes nothing. A failing
+// round-up or alert never fails the original request; the expense stays
+// pending and is picked up by the next run.
+func (p *FinancePlugin) afterWrite(req *sdk.APIRequest, resp *sdk.APIResponse, err error) (*sdk.APIResponse, error) {
+	if req.Method == "GET" || err != nil || resp == nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, err
+	}
+
+	topic := changeTopic(req.Path)
+	switch topic {
+	case "transactions", "recurring":
+		_ = p.roundupHandler.ApplyPending()
+		_ = p.alertsHandler.CheckPending()
+	case "budgets":
+		_ = p.alertsHandler.CheckPending()
+	}
+
+	p.notify(topic)
+	return resp, err
+}
+
+// changeTopic returns the topic a write to path changes: the route's first
+// path segment, with imports reported as the transactions they add.
+func changeTopic(path string) string {
+	topic, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if topic == "import" {
+		return "transactions"
+	}
+	return topic
+}
+
+// notify tells dashboards that topic changed. A failed notification is
+// dropped; the data itself is already written.
+func (p *FinancePlugin) notify(topic string) {
+	notify := p.notifyChanged
+	if notify == nil {
+		notify = sdk.NotifyChanged
+	}
+	_ = notify(topic)
 }
 
 // widgetSparklineEntry represents a single month in the sparkline trend data.
@@ -179,7 +341,17 @@ type widgetData struct {
 
 // GetWidgetData returns dashboard widget data for the requested slot.
 func (p *FinancePlugin) GetWidgetData(slot string) ([]byte, error) {
-	if slot != "dashboard-widget" {
+	switch slot {
+	case goalsSlot:
+		return p.goalsHandler.WidgetData(time.Now())
+	case budgetsSlot:
+		return p.budgetsHandler.WidgetData(time.Now())
+	case upcomingSlot:
+		return p.recurringHandler.WidgetData(time.Now())
+	case nextPaymentsSlot:
+		return p.recurringHandler.NextPaymentsWidgetData(time.Now())
+	case dashboardSlot:
+	default:
 		return json.Marshal(map[string]interface{}{"data": nil})
 	}
 
@@ -191,7 +363,7 @@ func (p *FinancePlugin) GetWidgetData(slot string) ([]byte, error) {
 	row := p.db.QueryRow(
 		`SELECT COALESCE(SUM(CASE WHEN type='income' THEN amount ELSE 0 END), 0),
 		        COALESCE(SUM(CASE WHEN type='expense' THEN amount ELSE 0 END), 0)
-		 FROM transactions WHERE date LIKE ?`,
+		 FROM transactions WHERE date LIKE ? AND status != 'void'`,
 		month+"%",
 	)
 	if err := row.Scan(&income, &expense); err != nil {
@@ -233,7 +405,7 @@ func (p *FinancePlugin) getSparkline(now time.Time) ([]widgetSparklineEntry, err
 		        COALESCE(SUM(CASE WHEN type='income' THEN amount ELSE 0 END), 0) -
 		        COALESCE(SUM(CASE WHEN type='expense' THEN amount ELSE 0 END), 0) as balance
 		 FROM transactions
-		 WHERE substr(date, 1, 7) >= ? AND substr(date, 1, 7) <= ?
+		 WHERE substr(date, 1, 7) >= ? AND substr(date, 1, 7) <= ? AND status != 'void'
 		 GROUP BY substr(date, 1, 7)
 		 ORDER BY month`,
 		startMonth, endMonth,
@@ -295,7 +467,7 @@ func (p *FinancePlugin) getGlobalBudgetProgress(month string) (*widgetBudgetProg
 	var spent float64
 	if err := p.db.QueryRow(
 		`SELECT COALESCE(SUM(amount), 0) FROM transactions
-		 WHERE type = 'expense' AND date LIKE ?`,
+		 WHERE type = 'expense' AND date LIKE ? AND status != 'void'`,
 		month+"%",
 	).Scan(&spent); err != nil {
 		return nil, err
@@ -315,8 +487,16 @@ func (p *FinancePlugin) getGlobalBudgetProgress(month string) (*widgetBudgetProg
 	}, nil
 }
 
-// Teardown closes the database connection when the plugin is unloaded.
+// Teardown stops background jobs and closes the database connection when the plugin is unloaded.
 func (p *FinancePlugin) Teardown() error {
+	if p.stopExports != nil {
+		p.stopExports()
+		p.stopExports = nil
+	}
+	if p.stopArchive != nil {
+		p.stopArchive()
+		p.stopArchive = nil
+	}
 	if p.db != nil {
 		return p.db.Close()
 	}
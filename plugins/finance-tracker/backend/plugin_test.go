@@ -1,19 +1,32 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/accounts"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/archive"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/budgets"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/exports"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/goals"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/investments"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/recurring"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/reports"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/roundup"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/stats"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/transactions"
 )
 
@@ -79,7 +92,7 @@ func parseDataObject(t *testing.T, resp *sdk.APIResponse) json.RawMessage {
 func createTransaction(t *testing.T, p *FinancePlugin, body string) int64 {
 	t.Helper()
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(body),
@@ -106,7 +119,7 @@ func createTag(t *testing.T, p *FinancePlugin, name string, color string) int64
 	t.Helper()
 
 	body := fmt.Sprintf(`{"name":"%s","color":"%s"}`, name, color)
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/tags",
 		Body:   []byte(body),
@@ -132,7 +145,7 @@ func createTag(t *testing.T, p *FinancePlugin, name string, color string) int64
 func createAccount(t *testing.T, p *FinancePlugin, body string) int64 {
 	t.Helper()
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(body),
@@ -193,7 +206,7 @@ func TestCreateTransaction_Valid(t *testing.T) {
 
 	createBody := `{"amount": 1500.50, "type": "income", "category": "salary", "description": "Monthly salary", "date": "2026-02-01"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(createBody),
@@ -207,7 +220,7 @@ func TestCreateTransaction_Valid(t *testing.T) {
 	}
 
 	// Verify the transaction appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-02"},
@@ -241,7 +254,7 @@ func TestCreateTransaction_NegativeAmount(t *testing.T) {
 
 	body := `{"amount": -100, "type": "expense", "category": "groceries", "date": "2026-02-01"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(body),
@@ -265,7 +278,7 @@ func TestCreateTransaction_InvalidType(t *testing.T) {
 
 	body := `{"amount": 100, "type": "other", "category": "groceries", "date": "2026-02-01"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(body),
@@ -299,7 +312,7 @@ func TestListTransactions_FilterByMonth(t *testing.T) {
 
 	for _, m := range months {
 		body := fmt.Sprintf(`{"amount": 50, "type": "expense", "category": "groceries", "date": "%s"}`, m.date)
-		resp, err := p.HandleAPI(&sdk.APIRequest{
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 			Method: "POST",
 			Path:   "/transactions",
 			Body:   []byte(body),
@@ -313,7 +326,7 @@ func TestListTransactions_FilterByMonth(t *testing.T) {
 	}
 
 	// Filter by January: should get 2 transactions.
-	janResp, err := p.HandleAPI(&sdk.APIRequest{
+	janResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-01"},
@@ -328,7 +341,7 @@ func TestListTransactions_FilterByMonth(t *testing.T) {
 	}
 
 	// Filter by February: should get 1 transaction.
-	febResp, err := p.HandleAPI(&sdk.APIRequest{
+	febResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-02"},
@@ -350,7 +363,7 @@ func TestDeleteTransaction(t *testing.T) {
 	txID := createTransaction(t, p, `{"amount": 200, "type": "expense", "category": "transport", "date": "2026-02-15"}`)
 
 	// Delete the transaction.
-	delResp, err := p.HandleAPI(&sdk.APIRequest{
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/transactions/%d", txID),
 	})
@@ -363,7 +376,7 @@ func TestDeleteTransaction(t *testing.T) {
 	}
 
 	// Verify the transaction no longer appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-02"},
@@ -378,6 +391,157 @@ func TestDeleteTransaction(t *testing.T) {
 	}
 }
 
+func TestDeleteTransaction_RestoreDeleted(t *testing.T) {
+	p := newTestPlugin(t)
+
+	kept := createTag(t, p, "essential", "#10B981")
+	dropped := createTag(t, p, "one-off", "#EF4444")
+	txID := createTransaction(t, p, fmt.Sprintf(
+		`{"amount": 42.5, "type": "expense", "category": "food", "description": "Groceries", "date": "2026-02-15", "tag_ids": [%d, %d]}`,
+		kept, dropped,
+	))
+
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/transactions/%d", txID),
+	})
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if delResp.Deleted == nil || delResp.Deleted.Kind != transactions.DeletedKind {
+		t.Fatalf("expected the delete to attach the transaction, got %+v", delResp.Deleted)
+	}
+
+	// A tag deleted in the meantime is left off the restored transaction.
+	if resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/tags/%d", dropped)}); resp.StatusCode != 200 {
+		t.Fatalf("expected status 200 deleting the tag, got %d", resp.StatusCode)
+	}
+
+	if err := p.RestoreDeleted(delResp.Deleted.Kind, delResp.Deleted.Data); err != nil {
+		t.Fatalf("RestoreDeleted failed: %v", err)
+	}
+
+	var amount float64
+	var description string
+	if err := p.db.QueryRow("SELECT amount, description FROM transactions WHERE id = ?", txID).Scan(&amount, &description); err != nil {
+		t.Fatalf("expected the transaction back with its ID: %v", err)
+	}
+	if amount != 42.5 || description != "Groceries" {
+		t.Errorf("expected the original amount and description, got %v %q", amount, description)
+	}
+	var tags int
+	_ = p.db.QueryRow("SELECT COUNT(*) FROM transaction_tags WHERE transaction_id = ?", txID).Scan(&tags)
+	if tags != 1 {
+		t.Errorf("expected only the remaining tag back, got %d", tags)
+	}
+
+	if err := p.RestoreDeleted(delResp.Deleted.Kind, delResp.Deleted.Data); !errors.Is(err, sdk.ErrRestoreConflict) {
+		t.Errorf("expected ErrRestoreConflict restoring again, got %v", err)
+	}
+}
+
+func TestRestoreDeleted_RefreshesReportsAndNotifies(t *testing.T) {
+	p := newTestPlugin(t)
+	var topics []string
+	p.notifyChanged = func(topic string) error {
+		topics = append(topics, topic)
+		return nil
+	}
+
+	createTransaction(t, p, `{"amount": 1000, "type": "income", "category": "salary", "date": "2026-02-01"}`)
+	txID := createTransaction(t, p, `{"amount": 250, "type": "expense", "category": "food", "date": "2026-02-15"}`)
+
+	summary := func() (income, expense float64) {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": "2026-02"}})
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("summary failed: %v %v", err, resp)
+		}
+		var result struct {
+			Income  float64 `json:"income"`
+			Expense float64 `json:"expense"`
+		}
+		if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+			t.Fatalf("failed to parse summary: %v", err)
+		}
+		return result.Income, result.Expense
+	}
+
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/transactions/%d", txID)})
+	if err != nil || delResp.Deleted == nil {
+		t.Fatalf("delete failed: %v %+v", err, delResp)
+	}
+	if income, expense := summary(); income != 1000 || expense != 0 {
+		t.Fatalf("expected the deleted expense left out, got income %v and expense %v", income, expense)
+	}
+
+	topics = nil
+	if err := p.RestoreDeleted(delResp.Deleted.Kind, delResp.Deleted.Data); err != nil {
+		t.Fatalf("RestoreDeleted failed: %v", err)
+	}
+	if income, expense := summary(); income != 1000 || expense != 250 {
+		t.Errorf("expected the cached summary dropped and the expense back, got income %v and expense %v", income, expense)
+	}
+	if got := strings.Join(topics, ","); got != "transactions" {
+		t.Errorf("expected transactions notified after the restore, got %q", got)
+	}
+}
+
+func TestExportImportData_MovesDataBetweenInstances(t *testing.T) {
+	source := newTestPlugin(t)
+	accountID := createAccount(t, source, `{"name": "Savings", "type": "savings", "currency": "EUR"}`)
+	tagID := createTag(t, source, "essential", "#10B981")
+	txID := createTransaction(t, source, fmt.Sprintf(
+		`{"amount": 42.5, "type": "expense", "account_id": %d, "category": "food", "description": "Groceries", "date": "2026-02-15", "tag_ids": [%d]}`,
+		accountID, tagID,
+	))
+
+	data, err := source.ExportData()
+	if err != nil {
+		t.Fatalf("ExportData failed: %v", err)
+	}
+
+	// The target's own transactions are replaced by the source's.
+	target := newTestPlugin(t)
+	createTransaction(t, target, `{"amount": 9.99, "type": "expense", "category": "fun", "date": "2026-01-03"}`)
+	createTransaction(t, target, `{"amount": 5, "type": "expense", "category": "fun", "date": "2026-01-04"}`)
+	if err := target.ImportData("1.0.0", data); err != nil {
+		t.Fatalf("ImportData failed: %v", err)
+	}
+
+	var count int
+	_ = target.db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count)
+	if count != 1 {
+		t.Fatalf("expected only the imported transaction, got %d", count)
+	}
+	var amount float64
+	var account string
+	if err := target.db.QueryRow(
+		"SELECT t.amount, a.name FROM transactions t JOIN accounts a ON a.id = t.account_id WHERE t.id = ?", txID,
+	).Scan(&amount, &account); err != nil {
+		t.Fatalf("expected the transaction with its original ID: %v", err)
+	}
+	if amount != 42.5 || account != "Savings" {
+		t.Errorf("expected 42.5 from Savings, got %v from %q", amount, account)
+	}
+	_ = target.db.QueryRow("SELECT COUNT(*) FROM transaction_tags WHERE transaction_id = ? AND tag_id = ?", txID, tagID).Scan(&count)
+	if count != 1 {
+		t.Errorf("expected the transaction's tag to be imported, got %d links", count)
+	}
+
+	// Data the plugin does not know leaves everything as it was.
+	if err := target.ImportData("1.0.0", []byte(`[{"table":"notes","rows":[]}]`)); !errors.Is(err, sdk.ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData for an unknown table, got %v", err)
+	}
+	if err := target.ImportData("1.0.0", []byte(`[{"table":"transactions","rows":[{"id":1,"amount":1,"type":"gift","category":"x","date":"2026-01-01"}]}]`)); !errors.Is(err, sdk.ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData for rows breaking the schema, got %v", err)
+	}
+	_ = target.db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count)
+	if count != 1 {
+		t.Errorf("expected a rejected import to keep the data, got %d transactions", count)
+	}
+}
+
 // --- Transaction v2 tests ---
 
 func TestCreateTransaction_WithAccount(t *testing.T) {
@@ -391,7 +555,7 @@ func TestCreateTransaction_WithAccount(t *testing.T) {
 	txID := createTransaction(t, p, body)
 
 	// Verify the transaction has the correct account_id.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-02"},
@@ -426,7 +590,7 @@ func TestCreateTransaction_DefaultAccount(t *testing.T) {
 	body := `{"amount":100,"type":"expense","category":"groceries","date":"2026-02-01"}`
 	txID := createTransaction(t, p, body)
 
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-02"},
@@ -460,7 +624,7 @@ func TestCreateTransaction_Transfer(t *testing.T) {
 	txID := createTransaction(t, p, body)
 
 	// Verify the transaction.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-02"},
@@ -490,7 +654,7 @@ func TestCreateTransaction_TransferMissingDest(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"amount":500,"type":"transfer","category":"","date":"2026-02-01","account_id":1}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(body),
@@ -515,7 +679,7 @@ func TestUpdateTransaction(t *testing.T) {
 
 	// Update amount, category, and description.
 	updateBody := `{"amount":150,"type":"expense","category":"transport","description":"Taxi ride","date":"2026-02-01"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/transactions/%d", txID),
 		Body:   []byte(updateBody),
@@ -549,7 +713,7 @@ func TestDeleteTransaction_V2(t *testing.T) {
 
 	txID := createTransaction(t, p, `{"amount":75,"type":"expense","category":"entertainment","date":"2026-02-10"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/transactions/%d", txID),
 	})
@@ -561,7 +725,7 @@ func TestDeleteTransaction_V2(t *testing.T) {
 	}
 
 	// Verify deletion.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"month": "2026-02"},
@@ -579,7 +743,7 @@ func TestDeleteTransaction_V2(t *testing.T) {
 func TestDeleteTransaction_NotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   "/transactions/999",
 	})
@@ -607,7 +771,7 @@ func TestListTransactions_FilterByAccount(t *testing.T) {
 	createTransaction(t, p, fmt.Sprintf(`{"amount":50,"type":"expense","category":"transport","date":"2026-02-01","account_id":%d}`, acctID))
 
 	// Filter by the second account.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"account": fmt.Sprintf("%d", acctID)},
@@ -637,7 +801,7 @@ func TestListTransactions_FilterByCategory(t *testing.T) {
 	createTransaction(t, p, `{"amount":50,"type":"expense","category":"transport","date":"2026-02-01"}`)
 	createTransaction(t, p, `{"amount":200,"type":"expense","category":"groceries","date":"2026-02-05"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"category": "groceries"},
@@ -669,7 +833,7 @@ func TestListTransactions_FilterByType(t *testing.T) {
 	createTransaction(t, p, `{"amount":100,"type":"expense","category":"groceries","date":"2026-02-01"}`)
 	createTransaction(t, p, `{"amount":50,"type":"expense","category":"transport","date":"2026-02-02"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"type": "income"},
@@ -698,7 +862,7 @@ func TestListTransactions_SearchDescription(t *testing.T) {
 	createTransaction(t, p, `{"amount":3000,"type":"income","category":"salary","description":"Monthly salary from ACME","date":"2026-02-01"}`)
 	createTransaction(t, p, `{"amount":100,"type":"expense","category":"groceries","description":"Supermarket run","date":"2026-02-02"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"search": "salary"},
@@ -732,7 +896,7 @@ func TestListTransactions_FilterByTag(t *testing.T) {
 	createTransaction(t, p, `{"amount":100,"type":"expense","category":"groceries","date":"2026-02-02"}`)
 
 	// Filter by tag.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"tag": fmt.Sprintf("%d", tagID)},
@@ -766,7 +930,7 @@ func TestCreateTransaction_WithTags(t *testing.T) {
 		tag1, tag2,
 	)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(body),
@@ -813,7 +977,7 @@ func TestUpdateTransaction_ChangeTags(t *testing.T) {
 
 	// Update to tag2.
 	updateBody := fmt.Sprintf(`{"amount":100,"type":"expense","category":"groceries","date":"2026-02-01","tag_ids":[%d]}`, tag2)
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/transactions/%d", txID),
 		Body:   []byte(updateBody),
@@ -842,10 +1006,348 @@ func TestUpdateTransaction_ChangeTags(t *testing.T) {
 
 // --- Categories tests ---
 
+// bulkTransactions posts body to /transactions/bulk and returns the summary.
+func bulkTransactions(t *testing.T, p *FinancePlugin, body string) transactions.BulkSummary {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/transactions/bulk",
+		Body:   []byte(body),
+	})
+	if err != nil {
+		t.Fatalf("bulk returned error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var summary transactions.BulkSummary
+	if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+		t.Fatalf("failed to parse bulk summary: %v", err)
+	}
+	return summary
+}
+
+func TestBulkTransactions_CreateReportsEachItem(t *testing.T) {
+	p := newTestPlugin(t)
+
+	summary := bulkTransactions(t, p, `{"action":"create","transactions":[
+		{"amount":40,"type":"expense","category":"groceries","date":"2026-02-01"},
+		{"amount":-5,"type":"expense","category":"groceries","date":"2026-02-02"},
+		{"amount":12,"type":"expense","category":"transport","date":"2026-02-03","tag_ids":[999]},
+		{"amount":900,"type":"income","category":"salary","date":"2026-02-04"}
+	]}`)
+
+	if summary.Succeeded != 2 || summary.Failed != 2 {
+		t.Fatalf("expected 2 created and 2 failed, got %+v", summary)
+	}
+	if summary.Results[0].Status != transactions.BulkCreated || summary.Results[0].ID == 0 {
+		t.Errorf("expected item 0 to be created with an ID, got %+v", summary.Results[0])
+	}
+	if summary.Results[1].Status != transactions.BulkFailed || summary.Results[1].Error != "amount must be greater than 0" {
+		t.Errorf("expected item 1 to fail validation, got %+v", summary.Results[1])
+	}
+	if summary.Results[2].Status != transactions.BulkFailed || summary.Results[2].Error != "tag 999 not found" {
+		t.Errorf("expected item 2 to fail on its tag, got %+v", summary.Results[2])
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions", Query: map[string]string{"month": "2026-02"}})
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	if items := parseDataArray(t, resp); len(items) != 2 {
+		t.Errorf("expected 2 stored transactions, got %d", len(items))
+	}
+}
+
+func TestBulkTransactions_Delete(t *testing.T) {
+	p := newTestPlugin(t)
+
+	first := createTransaction(t, p, `{"amount":40,"type":"expense","category":"groceries","date":"2026-02-01"}`)
+	second := createTransaction(t, p, `{"amount":60,"type":"expense","category":"groceries","date":"2026-02-02"}`)
+	kept := createTransaction(t, p, `{"amount":80,"type":"expense","category":"groceries","date":"2026-02-03"}`)
+
+	summary := bulkTransactions(t, p, fmt.Sprintf(`{"action":"delete","ids":[%d,99999,%d]}`, first, second))
+
+	if summary.Succeeded != 2 || summary.Failed != 1 {
+		t.Fatalf("expected 2 deleted and 1 failed, got %+v", summary)
+	}
+	if summary.Results[1].Status != transactions.BulkFailed || summary.Results[1].Error != "transaction not found" {
+		t.Errorf("expected the unknown ID to fail, got %+v", summary.Results[1])
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions", Query: map[string]string{"month": "2026-02"}})
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	items := parseDataArray(t, resp)
+	var tx transactions.Transaction
+	if len(items) != 1 || json.Unmarshal(items[0], &tx) != nil || tx.ID != kept {
+		t.Errorf("expected only transaction %d to remain, got %d items", kept, len(items))
+	}
+}
+
+func TestBulkTransactions_DeleteRestoreDeleted(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	if resp := saveRoundupRule(t, p, 1, fmt.Sprintf(`{"dest_account_id":%d,"start_date":"2026-01-01"}`, savingsID)); resp.StatusCode != 200 {
+		t.Fatalf("expected 200 saving the round-up rule, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	tagID := createTag(t, p, "essential", "#10B981")
+	first := createTransaction(t, p, fmt.Sprintf(`{"amount":3.40,"type":"expense","category":"groceries","date":"2026-02-01","tag_ids":[%d]}`, tagID))
+	second := createTransaction(t, p, fmt.Sprintf(`{"amount":5.20,"type":"expense","category":"groceries","date":"2026-02-02","tag_ids":[%d]}`, tagID))
+
+	bulk := func(body string) *sdk.APIResponse {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/transactions/bulk", Body: []byte(body)})
+		if err != nil {
+			t.Fatalf("bulk returned error: %v", err)
+		}
+		if resp.StatusCode != 200 {
+			t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+		}
+		return resp
+	}
+
+	// Nothing deleted, nothing to undo.
+	if resp := bulk(`{"action":"delete","ids":[99999]}`); resp.Deleted != nil {
+		t.Errorf("expected no undo for a bulk delete that removed nothing, got %+v", resp.Deleted)
+	}
+
+	resp := bulk(fmt.Sprintf(`{"action":"delete","ids":[%d,99999,%d]}`, first, second))
+	if resp.Deleted == nil || resp.Deleted.Kind != transactions.DeletedKind {
+		t.Fatalf("expected the bulk delete to attach the transactions, got %+v", resp.Deleted)
+	}
+
+	count := func(query string) int {
+		t.Helper()
+		var n int
+		if err := p.db.QueryRow(query, first, second).Scan(&n); err != nil {
+			t.Fatalf("counting rows: %v", err)
+		}
+		return n
+	}
+	const (
+		countTransactions = "SELECT COUNT(*) FROM transactions WHERE id IN (?, ?)"
+		countTags         = "SELECT COUNT(*) FROM transaction_tags WHERE transaction_id IN (?, ?)"
+		countRoundups     = "SELECT COUNT(*) FROM roundups WHERE expense_id IN (?, ?)"
+	)
+	if count(countTransactions) != 0 || count(countTags) != 0 || count(countRoundups) != 0 {
+		t.Fatal("expected the transactions, their tags and round-ups to be deleted")
+	}
+
+	// One undo puts back every transaction with its tags and round-ups.
+	if err := p.RestoreDeleted(resp.Deleted.Kind, resp.Deleted.Data); err != nil {
+		t.Fatalf("RestoreDeleted failed: %v", err)
+	}
+	if got := count(countTransactions); got != 2 {
+		t.Errorf("expected both transactions back, got %d", got)
+	}
+	if got := count(countTags); got != 2 {
+		t.Errorf("expected both transactions' tags back, got %d", got)
+	}
+	if got := count(countRoundups); got != 2 {
+		t.Errorf("expected both round-up records back, got %d", got)
+	}
+
+	if err := p.RestoreDeleted(resp.Deleted.Kind, resp.Deleted.Data); !errors.Is(err, sdk.ErrRestoreConflict) {
+		t.Errorf("expected ErrRestoreConflict restoring again, got %v", err)
+	}
+}
+
+func TestBulkTransactions_Recategorize(t *testing.T) {
+	p := newTestPlugin(t)
+
+	tagID := createTag(t, p, "imported", "#3B82F6")
+	first := createTransaction(t, p, `{"amount":40,"type":"expense","category":"other","date":"2026-02-01"}`)
+	second := createTransaction(t, p, `{"amount":60,"type":"expense","category":"other","date":"2026-02-02"}`)
+
+	summary := bulkTransactions(t, p, fmt.Sprintf(
+		`{"action":"recategorize","ids":[%d,%d],"category":"groceries","tag_ids":[%d]}`, first, second, tagID))
+	if summary.Succeeded != 2 || summary.Failed != 0 {
+		t.Fatalf("expected 2 updated, got %+v", summary)
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "GET",
+		Path:   "/transactions",
+		Query:  map[string]string{"category": "groceries", "tag": fmt.Sprintf("%d", tagID)},
+	})
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	if items := parseDataArray(t, resp); len(items) != 2 {
+		t.Errorf("expected both transactions recategorized and tagged, got %d", len(items))
+	}
+
+	// Only the tags change when no category is given; an empty list clears them.
+	bulkTransactions(t, p, fmt.Sprintf(`{"action":"recategorize","ids":[%d],"tag_ids":[]}`, first))
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions", Query: map[string]string{"tag": fmt.Sprintf("%d", tagID)}})
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	if items := parseDataArray(t, resp); len(items) != 1 {
+		t.Errorf("expected one tagged transaction left, got %d", len(items))
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/transactions/bulk",
+		Body:   []byte(fmt.Sprintf(`{"action":"recategorize","ids":[%d]}`, first)),
+	})
+	if err != nil {
+		t.Fatalf("bulk returned error: %v", err)
+	}
+	if code, _ := parseErrorResponse(t, resp); resp.StatusCode != 400 || code != "VALIDATION_ERROR" {
+		t.Errorf("expected a validation error without category or tag_ids, got %d %s", resp.StatusCode, code)
+	}
+}
+
+func TestTransactionDuplicates_FindAndMerge(t *testing.T) {
+	p := newTestPlugin(t)
+
+	tagID := createTag(t, p, "imported", "#3B82F6")
+	first := createTransaction(t, p, `{"amount":25.99,"type":"expense","category":"shopping","description":"AMAZON MKTP ES*1234","date":"2026-03-10"}`)
+	second := createTransaction(t, p, fmt.Sprintf(`{"amount":25.99,"type":"expense","category":"other","description":"Amazon Mktp ES","date":"2026-03-11","tag_ids":[%d]}`, tagID))
+	// Different description, a date too far apart or another amount are not duplicates.
+	createTransaction(t, p, `{"amount":25.99,"type":"expense","category":"food","description":"Coffee beans","date":"2026-03-10"}`)
+	createTransaction(t, p, `{"amount":25.99,"type":"expense","category":"shopping","description":"AMAZON MKTP ES","date":"2026-03-20"}`)
+	createTransaction(t, p, `{"amount":26.99,"type":"expense","category":"shopping","description":"AMAZON MKTP ES","date":"2026-03-10"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions/duplicates", Query: map[string]string{"month": "2026-03"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("duplicates failed: %v %v", err, resp)
+	}
+	var groups []transactions.DuplicateGroup
+	if err := json.Unmarshal(parseDataObject(t, resp), &groups); err != nil {
+		t.Fatalf("failed to parse duplicates: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Transactions) != 2 ||
+		groups[0].Transactions[0].ID != first || groups[0].Transactions[1].ID != second {
+		t.Fatalf("expected one group of the two amazon charges, got %+v", groups)
+	}
+	if groups[0].Similarity < transactions.DuplicateSimilarity || groups[0].Similarity > 1 {
+		t.Errorf("unexpected similarity %f", groups[0].Similarity)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/transactions/merge",
+		Body:   []byte(fmt.Sprintf(`{"keep_id":%d,"ids":[%d]}`, first, second)),
+	})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("merge failed: %v %v", err, resp)
+	}
+	var merged transactions.MergeResult
+	if err := json.Unmarshal(parseDataObject(t, resp), &merged); err != nil {
+		t.Fatalf("failed to parse merge result: %v", err)
+	}
+	if merged.Transaction.ID != first || len(merged.Transaction.Tags) != 1 || merged.Transaction.Tags[0].ID != tagID {
+		t.Errorf("expected the kept transaction with the merged tag, got %+v", merged.Transaction)
+	}
+	if resp.Deleted == nil || resp.Deleted.Kind != transactions.DeletedKind {
+		t.Fatalf("expected the merge to attach the removed transactions, got %+v", resp.Deleted)
+	}
+	deleted := resp.Deleted
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions/duplicates"})
+	if err != nil {
+		t.Fatalf("duplicates returned error: %v", err)
+	}
+	if items := parseDataArray(t, resp); len(items) != 0 {
+		t.Errorf("expected no duplicates after merging, got %d", len(items))
+	}
+
+	// Undoing the merge puts the removed transaction back.
+	if err := p.RestoreDeleted(deleted.Kind, deleted.Data); err != nil {
+		t.Fatalf("RestoreDeleted failed: %v", err)
+	}
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions", Query: map[string]string{"tag": fmt.Sprintf("%d", tagID)}})
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	if items := parseDataArray(t, resp); len(items) != 2 {
+		t.Errorf("expected both tagged transactions after the undo, got %d", len(items))
+	}
+
+	for _, body := range []string{
+		fmt.Sprintf(`{"keep_id":%d,"ids":[%d]}`, first, first),
+		fmt.Sprintf(`{"keep_id":%d,"ids":[]}`, first),
+	} {
+		resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/transactions/merge", Body: []byte(body)})
+		if err != nil {
+			t.Fatalf("merge returned error: %v", err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("expected 400 for %s, got %d", body, resp.StatusCode)
+		}
+	}
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/transactions/merge",
+		Body:   []byte(fmt.Sprintf(`{"keep_id":%d,"ids":[9999]}`, first)),
+	})
+	if err != nil {
+		t.Fatalf("merge returned error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404 for a missing transaction, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleAPI_RoutesToPackageHandlers(t *testing.T) {
+	p := newTestPlugin(t)
+
+	id := createTransaction(t, p, `{"amount":10,"type":"expense","category":"food","date":"2026-02-01"}`)
+	txPath := fmt.Sprintf("/transactions/%d", id)
+	requests := []*sdk.APIRequest{
+		{Method: "GET", Path: "/transactions", Query: map[string]string{"category": "food", "type": "expense"}},
+		{Method: "PUT", Path: txPath, Body: []byte(`{"amount":12,"type":"expense","category":"food","date":"2026-02-01"}`)},
+		{Method: "GET", Path: "/tags"},
+		{Method: "GET", Path: "/categories"},
+		{Method: "GET", Path: "/recurring"},
+		{Method: "GET", Path: "/budgets", Query: map[string]string{"month": "2026-02"}},
+		{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": "2026-02"}},
+		{Method: "DELETE", Path: txPath},
+	}
+	for _, req := range requests {
+		resp, err := p.HandleAPI(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s %s returned error: %v", req.Method, req.Path, err)
+		}
+		if resp.StatusCode != 200 {
+			t.Errorf("expected %s %s to be handled, got %d: %s", req.Method, req.Path, resp.StatusCode, resp.Body)
+		}
+	}
+
+	// The legacy /summary path answers like /reports/summary.
+	legacy, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/summary", Query: map[string]string{"month": "2026-02"}})
+	if err != nil {
+		t.Fatalf("legacy summary returned error: %v", err)
+	}
+	current, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": "2026-02"}})
+	if err != nil {
+		t.Fatalf("summary returned error: %v", err)
+	}
+	if legacy.StatusCode != 200 || string(legacy.Body) != string(current.Body) {
+		t.Errorf("expected /summary to match /reports/summary, got %d %s", legacy.StatusCode, legacy.Body)
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/unknown"})
+	if err != nil {
+		t.Fatalf("HandleAPI returned error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404 for an unknown path, got %d", resp.StatusCode)
+	}
+}
+
 func TestListCategories_DefaultsExist(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/categories",
 		Query:  map[string]string{},
@@ -891,7 +1393,7 @@ func TestListCategories_FilterByType(t *testing.T) {
 
 	// Create an income-only category.
 	createBody := `{"name":"freelance","type":"income","icon":"briefcase"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(createBody),
@@ -905,7 +1407,7 @@ func TestListCategories_FilterByType(t *testing.T) {
 
 	// Create an expense-only category.
 	createBody2 := `{"name":"gym","type":"expense","icon":"dumbbell"}`
-	createResp2, err := p.HandleAPI(&sdk.APIRequest{
+	createResp2, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(createBody2),
@@ -918,7 +1420,7 @@ func TestListCategories_FilterByType(t *testing.T) {
 	}
 
 	// Filter by income: should return income + both categories.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/categories",
 		Query:  map[string]string{"type": "income"},
@@ -970,7 +1472,7 @@ func TestCreateCategory(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"subscriptions","type":"expense","icon":"credit-card","color":"#FF5733"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(body),
@@ -983,7 +1485,7 @@ func TestCreateCategory(t *testing.T) {
 	}
 
 	// Verify it appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/categories",
 		Query:  map[string]string{},
@@ -1032,7 +1534,7 @@ func TestCreateCategory_DuplicateName(t *testing.T) {
 
 	// "salary" already exists as a default category.
 	body := `{"name":"salary","type":"income","icon":"banknote"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(body),
@@ -1054,7 +1556,7 @@ func TestCreateCategory_MissingName(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"type":"expense","icon":"box"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(body),
@@ -1076,7 +1578,7 @@ func TestCreateCategory_InvalidType(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"bad","type":"invalid","icon":"x"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(body),
@@ -1099,7 +1601,7 @@ func TestUpdateCategory(t *testing.T) {
 
 	// Create a category to update.
 	createBody := `{"name":"pets","type":"expense","icon":"dog"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(createBody),
@@ -1121,7 +1623,7 @@ func TestUpdateCategory(t *testing.T) {
 
 	// Update name, type, icon, color.
 	updateBody := `{"name":"animals","type":"both","icon":"cat","color":"#8B5CF6"}`
-	updateResp, err := p.HandleAPI(&sdk.APIRequest{
+	updateResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/categories/%d", created.ID),
 		Body:   []byte(updateBody),
@@ -1134,7 +1636,7 @@ func TestUpdateCategory(t *testing.T) {
 	}
 
 	// Verify the update in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/categories",
 		Query:  map[string]string{},
@@ -1182,7 +1684,7 @@ func TestDeleteCategory_NoTransactions(t *testing.T) {
 
 	// Create a category to delete.
 	createBody := `{"name":"temporary","type":"expense","icon":"trash"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/categories",
 		Body:   []byte(createBody),
@@ -1200,7 +1702,7 @@ func TestDeleteCategory_NoTransactions(t *testing.T) {
 	}
 
 	// Delete it.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/categories/%d", created.ID),
 	})
@@ -1212,7 +1714,7 @@ func TestDeleteCategory_NoTransactions(t *testing.T) {
 	}
 
 	// Verify it no longer appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/categories",
 		Query:  map[string]string{},
@@ -1249,7 +1751,7 @@ func TestDeleteCategory_HasTransactions(t *testing.T) {
 	}
 
 	// Attempt to delete it. Should fail with CONFLICT.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/categories/%d", groceriesID),
 	})
@@ -1270,7 +1772,7 @@ func TestReorderCategories(t *testing.T) {
 	p := newTestPlugin(t)
 
 	// Get current categories to know their IDs.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/categories",
 		Query:  map[string]string{},
@@ -1302,7 +1804,7 @@ func TestReorderCategories(t *testing.T) {
 		`[{"id":%d,"sort_order":2},{"id":%d,"sort_order":1},{"id":%d,"sort_order":0}]`,
 		ids[0], ids[1], ids[2],
 	)
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   "/categories/reorder",
 		Body:   []byte(reorderBody),
@@ -1341,7 +1843,7 @@ func TestWidgetData_MonthlyBalance(t *testing.T) {
 
 	// Create income transaction.
 	incomeBody := fmt.Sprintf(`{"amount": 3000, "type": "income", "category": "salary", "date": "%s-15"}`, currentMonth)
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(incomeBody),
@@ -1352,7 +1854,7 @@ func TestWidgetData_MonthlyBalance(t *testing.T) {
 
 	// Create expense transaction.
 	expenseBody := fmt.Sprintf(`{"amount": 750.50, "type": "expense", "category": "groceries", "date": "%s-20"}`, currentMonth)
-	resp, err = p.HandleAPI(&sdk.APIRequest{
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/transactions",
 		Body:   []byte(expenseBody),
@@ -1549,41 +2051,184 @@ func TestWidgetData_NoBudget(t *testing.T) {
 	}
 }
 
-// --- Migration v2 tests ---
-
-func TestMigrate_V2TablesExist(t *testing.T) {
+func TestWidgetData_BudgetStatus(t *testing.T) {
 	p := newTestPlugin(t)
 
-	expectedTables := []string{
-		"transactions", "categories", "accounts", "tags",
-		"transaction_tags", "recurring_rules", "budgets",
-		"savings_goals", "investments",
+	currentMonth := time.Now().Format("2006-01")
+	createBudget(t, p, fmt.Sprintf(`{"name":"Food","category":"food","amount":400,"month":"%s"}`, currentMonth))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":100,"type":"expense","category":"food","date":"%s-10"}`, currentMonth))
+
+	widgetData, err := p.GetWidgetData(budgetsSlot)
+	if err != nil {
+		t.Fatalf("GetWidgetData returned error: %v", err)
 	}
-	for _, table := range expectedTables {
-		var name string
-		err := p.db.QueryRow(
-			"SELECT name FROM sqlite_master WHERE type='table' AND name=?", table,
-		).Scan(&name)
-		if err != nil {
-			t.Errorf("table %s does not exist after migration: %v", table, err)
-		}
+
+	var widget struct {
+		Data []struct {
+			Name       string  `json:"name"`
+			Spent      float64 `json:"spent"`
+			Percentage float64 `json:"percentage"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(widgetData, &widget); err != nil {
+		t.Fatalf("failed to parse widget data: %v", err)
+	}
+	if len(widget.Data) != 1 || widget.Data[0].Name != "Food" || widget.Data[0].Spent != 100 || widget.Data[0].Percentage != 25 {
+		t.Errorf("expected the food budget at 25%%, got %+v", widget.Data)
 	}
 }
 
-func TestMigrate_DefaultAccountCreated(t *testing.T) {
+func TestWidgetData_UpcomingRecurring(t *testing.T) {
 	p := newTestPlugin(t)
 
-	var name, accountType string
-	err := p.db.QueryRow("SELECT name, type FROM accounts WHERE id = 1").Scan(&name, &accountType)
+	today := time.Now()
+	first := today.AddDate(0, 0, 2)
+	ruleID := createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 12.5, "type": "expense", "category": "subscriptions", "description": "Music",
+		"frequency": "weekly", "day_of_week": %d, "start_date": "%s"
+	}`, first.Weekday(), today.Format("2006-01-02")))
+
+	// Paused rules have no upcoming occurrences.
+	pausedID := createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 30, "type": "expense", "category": "gym",
+		"frequency": "weekly", "day_of_week": 1, "start_date": "%s"
+	}`, today.Format("2006-01-02")))
+	if resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: fmt.Sprintf("/recurring/%d/pause", pausedID)}); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("pausing rule failed: %v", err)
+	}
+
+	skipped := first.AddDate(0, 0, 7).Format("2006-01-02")
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   fmt.Sprintf("/recurring/%d/skip", ruleID),
+		Body:   []byte(fmt.Sprintf(`{"date":"%s"}`, skipped)),
+	})
+	if err != nil || resp.StatusCode >= 300 {
+		t.Fatalf("skipping occurrence failed: %v", err)
+	}
+
+	widgetData, err := p.GetWidgetData(upcomingSlot)
 	if err != nil {
-		t.Fatalf("default account not found: %v", err)
+		t.Fatalf("GetWidgetData returned error: %v", err)
 	}
-	if name != "Main Account" || accountType != "checking" {
-		t.Errorf("unexpected default account: name=%s type=%s", name, accountType)
+
+	var widget struct {
+		Data []recurring.Occurrence `json:"data"`
+	}
+	if err := json.Unmarshal(widgetData, &widget); err != nil {
+		t.Fatalf("failed to parse widget data: %v", err)
 	}
-}
 
-func TestMigrate_IdempotentExecution(t *testing.T) {
+	// Weekly from two days ahead through 30 days: five dates, one skipped.
+	if len(widget.Data) != 4 {
+		t.Fatalf("expected 4 upcoming occurrences, got %+v", widget.Data)
+	}
+	if widget.Data[0].Date != first.Format("2006-01-02") || widget.Data[0].RuleID != ruleID || widget.Data[0].Amount != 12.5 {
+		t.Errorf("unexpected first occurrence: %+v", widget.Data[0])
+	}
+	for i, occurrence := range widget.Data {
+		if occurrence.Date == skipped {
+			t.Errorf("expected the skipped date to be left out, got %+v", occurrence)
+		}
+		if i > 0 && occurrence.Date <= widget.Data[i-1].Date {
+			t.Errorf("expected occurrences soonest first, got %+v", widget.Data)
+		}
+	}
+}
+
+func TestWidgetData_NextPayments(t *testing.T) {
+	p := newTestPlugin(t)
+
+	today := time.Now()
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+
+	// A yearly rule due beyond the 30 days of the upcoming widget is listed.
+	yearly := today.AddDate(0, 5, 0)
+	createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 90, "type": "expense", "category": "insurance", "description": "Home insurance",
+		"frequency": "yearly", "day_of_month": %d, "month_of_year": %d, "start_date": "%s", "account_id": %d
+	}`, yearly.Day(), yearly.Month(), today.Format("2006-01-02"), savingsID))
+	createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 5, "type": "expense", "category": "subscriptions", "description": "Music",
+		"frequency": "weekly", "day_of_week": %d, "start_date": "%s"
+	}`, today.AddDate(0, 0, 1).Weekday(), today.Format("2006-01-02")))
+
+	widgetData, err := p.GetWidgetData(nextPaymentsSlot)
+	if err != nil {
+		t.Fatalf("GetWidgetData returned error: %v", err)
+	}
+	var widget struct {
+		Data []recurring.Payment `json:"data"`
+	}
+	if err := json.Unmarshal(widgetData, &widget); err != nil {
+		t.Fatalf("failed to parse widget data: %v", err)
+	}
+
+	// Ten weeks of the weekly rule fill the list before the yearly one is due.
+	if len(widget.Data) != recurring.NextPaymentsLimit {
+		t.Fatalf("expected %d payments, got %+v", recurring.NextPaymentsLimit, widget.Data)
+	}
+	first := widget.Data[0]
+	if first.Date != today.AddDate(0, 0, 1).Format("2006-01-02") || first.Description != "Music" || first.Amount != 5 || first.AccountName == "" {
+		t.Errorf("unexpected first payment: %+v", first)
+	}
+	for _, payment := range widget.Data {
+		if payment.Description != "Music" {
+			t.Errorf("expected only the weekly rule in the first payments, got %+v", payment)
+		}
+	}
+
+	// Once the weekly rule is gone, the yearly one is next.
+	if _, err := p.db.Exec("UPDATE recurring_rules SET is_active = 0 WHERE frequency = 'weekly'"); err != nil {
+		t.Fatalf("pausing weekly rule: %v", err)
+	}
+	widgetData, err = p.GetWidgetData(nextPaymentsSlot)
+	if err != nil {
+		t.Fatalf("GetWidgetData returned error: %v", err)
+	}
+	if err := json.Unmarshal(widgetData, &widget); err != nil {
+		t.Fatalf("failed to parse widget data: %v", err)
+	}
+	if len(widget.Data) != 1 || widget.Data[0].Date != yearly.Format("2006-01-02") || widget.Data[0].AccountID != savingsID || widget.Data[0].AccountName != "Savings" {
+		t.Errorf("expected the yearly payment from savings, got %+v", widget.Data)
+	}
+}
+
+// --- Migration v2 tests ---
+
+func TestMigrate_V2TablesExist(t *testing.T) {
+	p := newTestPlugin(t)
+
+	expectedTables := []string{
+		"transactions", "categories", "accounts", "tags",
+		"transaction_tags", "recurring_rules", "budgets",
+		"savings_goals", "investments",
+	}
+	for _, table := range expectedTables {
+		var name string
+		err := p.db.QueryRow(
+			"SELECT name FROM sqlite_master WHERE type='table' AND name=?", table,
+		).Scan(&name)
+		if err != nil {
+			t.Errorf("table %s does not exist after migration: %v", table, err)
+		}
+	}
+}
+
+func TestMigrate_DefaultAccountCreated(t *testing.T) {
+	p := newTestPlugin(t)
+
+	var name, accountType string
+	err := p.db.QueryRow("SELECT name, type FROM accounts WHERE id = 1").Scan(&name, &accountType)
+	if err != nil {
+		t.Fatalf("default account not found: %v", err)
+	}
+	if name != "Main Account" || accountType != "checking" {
+		t.Errorf("unexpected default account: name=%s type=%s", name, accountType)
+	}
+}
+
+func TestMigrate_IdempotentExecution(t *testing.T) {
 	p := &FinancePlugin{}
 	dbPath := filepath.Join(t.TempDir(), "test_idempotent.db")
 
@@ -1602,8 +2247,8 @@ func TestMigrate_IdempotentExecution(t *testing.T) {
 	if err := p2.db.QueryRow("SELECT COUNT(*) FROM _migrations").Scan(&count); err != nil {
 		t.Fatalf("query migrations count failed: %v", err)
 	}
-	if count != 2 {
-		t.Errorf("expected 2 migrations recorded, got %d", count)
+	if count != 13 {
+		t.Errorf("expected 13 migrations recorded, got %d", count)
 	}
 }
 
@@ -1625,10 +2270,10 @@ func TestMigrate_MigrationTrackingTable(t *testing.T) {
 		filenames = append(filenames, f)
 	}
 
-	if len(filenames) != 2 {
-		t.Fatalf("expected 2 migration records, got %d: %v", len(filenames), filenames)
+	if len(filenames) != 13 {
+		t.Fatalf("expected 13 migration records, got %d: %v", len(filenames), filenames)
 	}
-	if filenames[0] != "001_init.sql" || filenames[1] != "002_enhanced.sql" {
+	if filenames[0] != "001_init.sql" || filenames[1] != "002_enhanced.sql" || filenames[2] != "003_roundup.sql" || filenames[3] != "004_exports.sql" || filenames[4] != "005_alerts.sql" || filenames[5] != "006_archive.sql" || filenames[6] != "007_recurring_skips.sql" || filenames[7] != "008_account_entries.sql" || filenames[8] != "009_import_presets.sql" || filenames[9] != "010_budget_templates.sql" || filenames[10] != "011_goal_contributions.sql" || filenames[11] != "012_transaction_status.sql" || filenames[12] != "013_account_opening_balance.sql" {
 		t.Errorf("unexpected migration filenames: %v", filenames)
 	}
 }
@@ -1682,7 +2327,7 @@ func TestCreateAccount_Valid(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"Savings EUR","type":"savings","currency":"EUR"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(body),
@@ -1695,7 +2340,7 @@ func TestCreateAccount_Valid(t *testing.T) {
 	}
 
 	// Verify it appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/accounts",
 	})
@@ -1732,7 +2377,7 @@ func TestCreateAccount_MissingName(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"type":"checking","currency":"EUR"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(body),
@@ -1754,7 +2399,7 @@ func TestCreateAccount_InvalidType(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"Credit Card","type":"credit","currency":"EUR"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(body),
@@ -1777,7 +2422,7 @@ func TestCreateAccount_DefaultCurrency(t *testing.T) {
 
 	// Currency omitted, should default to EUR.
 	body := `{"name":"Cash Wallet","type":"cash"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(body),
@@ -1790,7 +2435,7 @@ func TestCreateAccount_DefaultCurrency(t *testing.T) {
 	}
 
 	// Verify the currency was set to EUR.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/accounts",
 	})
@@ -1824,7 +2469,7 @@ func TestCreateAccount_SavingsWithInterestRate(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"High Yield Savings","type":"savings","currency":"EUR","interest_rate":2.5}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(body),
@@ -1846,7 +2491,7 @@ func TestCreateAccount_SavingsWithInterestRate(t *testing.T) {
 	}
 
 	// Verify interest_rate is stored by fetching balance endpoint.
-	balanceResp, err := p.HandleAPI(&sdk.APIRequest{
+	balanceResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/accounts/%d/balance", created.ID),
 	})
@@ -1877,7 +2522,7 @@ func TestCreateAccount_InterestRateOnlyForSavings(t *testing.T) {
 
 	// Attempting to set interest_rate on a checking account should fail.
 	body := `{"name":"Bad Account","type":"checking","currency":"EUR","interest_rate":1.5}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(body),
@@ -1900,7 +2545,7 @@ func TestUpdateAccount(t *testing.T) {
 
 	// Update the default account (id=1).
 	body := `{"name":"Updated Main","type":"checking","currency":"USD"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   "/accounts/1",
 		Body:   []byte(body),
@@ -1913,7 +2558,7 @@ func TestUpdateAccount(t *testing.T) {
 	}
 
 	// Verify the update in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/accounts",
 	})
@@ -1945,7 +2590,7 @@ func TestUpdateAccount_NotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"Ghost","type":"checking","currency":"EUR"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   "/accounts/999",
 		Body:   []byte(body),
@@ -1968,7 +2613,7 @@ func TestArchiveAccount(t *testing.T) {
 
 	// Create an account to archive.
 	createBody := `{"name":"To Archive","type":"cash","currency":"EUR"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(createBody),
@@ -1986,7 +2631,7 @@ func TestArchiveAccount(t *testing.T) {
 	}
 
 	// Archive it.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/accounts/%d", created.ID),
 	})
@@ -2003,7 +2648,7 @@ func TestListAccounts_ExcludesArchived(t *testing.T) {
 
 	// Create an account.
 	createBody := `{"name":"Temporary","type":"cash","currency":"EUR"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(createBody),
@@ -2021,7 +2666,7 @@ func TestListAccounts_ExcludesArchived(t *testing.T) {
 	}
 
 	// Archive it.
-	_, err = p.HandleAPI(&sdk.APIRequest{
+	_, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/accounts/%d", created.ID),
 	})
@@ -2030,7 +2675,7 @@ func TestListAccounts_ExcludesArchived(t *testing.T) {
 	}
 
 	// List: should only show the default "Main Account".
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/accounts",
 	})
@@ -2062,7 +2707,7 @@ func TestListAccounts_IncludesBalance(t *testing.T) {
 	createTransaction(t, p, `{"amount":800,"type":"expense","category":"groceries","date":"2026-02-05"}`)
 
 	// List accounts with balance.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/accounts",
 	})
@@ -2098,7 +2743,7 @@ func TestAccountBalance_CalculatedFromTransactions(t *testing.T) {
 	createTransaction(t, p, `{"amount":300,"type":"expense","category":"groceries","date":"2026-02-05"}`)
 
 	// GET /accounts/1/balance
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/accounts/1/balance",
 	})
@@ -2123,10 +2768,279 @@ func TestAccountBalance_CalculatedFromTransactions(t *testing.T) {
 	}
 }
 
+func TestAccountBalance_TransfersDebitSourceAndCreditDestination(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings","currency":"EUR"}`)
+	createTransaction(t, p, `{"amount":1000,"type":"income","category":"salary","date":"2026-02-01"}`)
+	createTransaction(t, p, fmt.Sprintf(`{"amount":300,"type":"transfer","account_id":1,"dest_account_id":%d,"date":"2026-02-10"}`, savingsID))
+
+	balance := func(accountID int64) float64 {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: fmt.Sprintf("/accounts/%d/balance", accountID)})
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("balance failed: %v %v", err, resp)
+		}
+		var result struct {
+			Balance float64 `json:"balance"`
+		}
+		if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+			t.Fatalf("failed to parse balance: %v", err)
+		}
+		return result.Balance
+	}
+	if got := balance(1); got != 700 {
+		t.Errorf("expected source balance 700, got %f", got)
+	}
+	if got := balance(savingsID); got != 300 {
+		t.Errorf("expected destination balance 300, got %f", got)
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": "2026-02"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("summary failed: %v %v", err, resp)
+	}
+	var summary struct {
+		Balance   float64 `json:"balance"`
+		ByAccount []struct {
+			AccountID int64   `json:"account_id"`
+			Total     float64 `json:"total"`
+		} `json:"by_account"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+		t.Fatalf("failed to parse summary: %v", err)
+	}
+	if summary.Balance != 1000 {
+		t.Errorf("expected the transfer to leave the month's balance at 1000, got %f", summary.Balance)
+	}
+	totals := make(map[int64]float64)
+	for _, account := range summary.ByAccount {
+		totals[account.AccountID] = account.Total
+	}
+	if totals[1] != 700 || totals[savingsID] != 300 {
+		t.Errorf("expected account totals 700 and 300, got %v", totals)
+	}
+
+	netWorth := func() float64 {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/net-worth"})
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("net worth failed: %v %v", err, resp)
+		}
+		var result struct {
+			AccountsTotal float64 `json:"accounts_total"`
+		}
+		if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+			t.Fatalf("failed to parse net worth: %v", err)
+		}
+		return result.AccountsTotal
+	}
+	if got := netWorth(); got != 1000 {
+		t.Errorf("expected accounts total 1000, got %f", got)
+	}
+
+	// Money moved to an archived account no longer counts.
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/accounts/%d", savingsID)})
+	if err != nil || resp.StatusCode >= 300 {
+		t.Fatalf("archiving account failed: %v %v", err, resp)
+	}
+	if got := netWorth(); got != 700 {
+		t.Errorf("expected accounts total 700 after archiving savings, got %f", got)
+	}
+}
+
+func TestTransactionStatus_FilterAndBalances(t *testing.T) {
+	p := newTestPlugin(t)
+
+	createTransaction(t, p, `{"amount":1000,"type":"income","category":"salary","date":"2026-02-01"}`)
+	pendingID := createTransaction(t, p, `{"amount":200,"type":"expense","category":"bills","date":"2026-02-03","status":"pending"}`)
+	createTransaction(t, p, `{"amount":50,"type":"expense","category":"groceries","date":"2026-02-05","status":"void"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/transactions",
+		Body:   []byte(`{"amount":10,"type":"expense","category":"bills","status":"bounced"}`),
+	})
+	if err != nil {
+		t.Fatalf("create returned error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for an unknown status, got %d", resp.StatusCode)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "GET",
+		Path:   "/transactions",
+		Query:  map[string]string{"status": "pending"},
+	})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("list failed: %v %v", err, resp)
+	}
+	items := parseDataArray(t, resp)
+	if len(items) != 1 {
+		t.Fatalf("expected 1 pending transaction, got %d", len(items))
+	}
+	var pending transactions.Transaction
+	if err := json.Unmarshal(items[0], &pending); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if pending.ID != pendingID || pending.Status != transactions.StatusPending {
+		t.Errorf("expected pending transaction %d, got %d (%s)", pendingID, pending.ID, pending.Status)
+	}
+
+	balances := func() (float64, float64) {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/accounts/1/balance"})
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("balance failed: %v %v", err, resp)
+		}
+		var result struct {
+			Balance        float64 `json:"balance"`
+			ClearedBalance float64 `json:"cleared_balance"`
+		}
+		if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+			t.Fatalf("failed to parse balance: %v", err)
+		}
+		return result.Balance, result.ClearedBalance
+	}
+	if current, cleared := balances(); current != 800 || cleared != 1000 {
+		t.Errorf("expected balance 800 and cleared balance 1000, got %f and %f", current, cleared)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": "2026-02"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("summary failed: %v %v", err, resp)
+	}
+	var summary struct {
+		Expense float64 `json:"expense"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+		t.Fatalf("failed to parse summary: %v", err)
+	}
+	if summary.Expense != 200 {
+		t.Errorf("expected the void expense to be left out of the summary, got %f", summary.Expense)
+	}
+
+	// An update without a status keeps it; one with a status changes it.
+	update := func(body string) transactions.Transaction {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+			Method: "PUT",
+			Path:   fmt.Sprintf("/transactions/%d", pendingID),
+			Body:   []byte(body),
+		})
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("update failed: %v %v", err, resp)
+		}
+		var tx transactions.Transaction
+		if err := json.Unmarshal(parseDataObject(t, resp), &tx); err != nil {
+			t.Fatalf("failed to parse updated transaction: %v", err)
+		}
+		return tx
+	}
+	if tx := update(`{"amount":250,"type":"expense","category":"bills","date":"2026-02-03"}`); tx.Status != transactions.StatusPending {
+		t.Errorf("expected the status to stay pending, got %s", tx.Status)
+	}
+	if tx := update(`{"amount":250,"type":"expense","category":"bills","date":"2026-02-03","status":"cleared"}`); tx.Status != transactions.StatusCleared {
+		t.Errorf("expected the status to become cleared, got %s", tx.Status)
+	}
+	if current, cleared := balances(); current != 750 || cleared != 750 {
+		t.Errorf("expected both balances at 750, got %f and %f", current, cleared)
+	}
+}
+
+func TestAccountHistory_RunningBalanceFromOpeningBalance(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings","currency":"EUR","opening_balance":500}`)
+	createTransaction(t, p, fmt.Sprintf(`{"amount":100,"type":"income","category":"salary","account_id":%d,"date":"2026-01-10"}`, savingsID))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":40,"type":"expense","category":"bills","account_id":%d,"date":"2026-03-02"}`, savingsID))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":60,"type":"transfer","account_id":%d,"dest_account_id":1,"date":"2026-03-20"}`, savingsID))
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: fmt.Sprintf("/accounts/%d/balance", savingsID)})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("balance failed: %v %v", err, resp)
+	}
+	var balance struct {
+		Balance        float64 `json:"balance"`
+		OpeningBalance float64 `json:"opening_balance"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &balance); err != nil {
+		t.Fatalf("failed to parse balance: %v", err)
+	}
+	if balance.OpeningBalance != 500 || balance.Balance != 500 {
+		t.Errorf("expected opening balance 500 and balance 500, got %f and %f", balance.OpeningBalance, balance.Balance)
+	}
+
+	history := func(query map[string]string) (*sdk.APIResponse, accounts.BalanceHistory) {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+			Method: "GET",
+			Path:   fmt.Sprintf("/accounts/%d/history", savingsID),
+			Query:  query,
+		})
+		if err != nil {
+			t.Fatalf("history returned error: %v", err)
+		}
+		var result accounts.BalanceHistory
+		if resp.StatusCode == 200 {
+			if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+				t.Fatalf("failed to parse history: %v", err)
+			}
+		}
+		return resp, result
+	}
+
+	resp, monthly := history(map[string]string{"granularity": "month", "to": "2026-03-31"})
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	want := []accounts.HistoryPoint{
+		{Period: "2026-01", Balance: 600, Change: 100},
+		{Period: "2026-02", Balance: 600, Change: 0},
+		{Period: "2026-03", Balance: 500, Change: -100},
+	}
+	if len(monthly.Points) != len(want) {
+		t.Fatalf("expected %d points, got %v", len(want), monthly.Points)
+	}
+	for i := range want {
+		if monthly.Points[i] != want[i] {
+			t.Errorf("point %d: expected %+v, got %+v", i, want[i], monthly.Points[i])
+		}
+	}
+
+	// Weeks start on Monday; from carries the balance of earlier periods.
+	_, weekly := history(map[string]string{"granularity": "week", "from": "2026-03-01", "to": "2026-03-08"})
+	if len(weekly.Points) != 2 || weekly.Points[0].Period != "2026-02-23" || weekly.Points[0].Balance != 600 ||
+		weekly.Points[1].Period != "2026-03-02" || weekly.Points[1].Balance != 560 {
+		t.Errorf("unexpected weekly points: %+v", weekly.Points)
+	}
+
+	if resp, _ := history(map[string]string{"granularity": "year"}); resp.StatusCode != 400 {
+		t.Errorf("expected 400 for an unknown granularity, got %d", resp.StatusCode)
+	}
+	if resp, _ := history(map[string]string{"granularity": "day", "from": "2020-01-01", "to": "2026-01-01"}); resp.StatusCode != 400 {
+		t.Errorf("expected 400 for a history over %d points, got %d", accounts.MaxHistoryPoints, resp.StatusCode)
+	}
+
+	// Updating the account without opening_balance keeps it.
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/accounts/%d", savingsID),
+		Body:   []byte(`{"name":"Rainy day","type":"savings","currency":"EUR"}`),
+	})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("update failed: %v %v", err, resp)
+	}
+	if _, kept := history(map[string]string{"to": "2026-01-31"}); kept.OpeningBalance != 500 {
+		t.Errorf("expected the opening balance to stay 500, got %f", kept.OpeningBalance)
+	}
+}
+
 func TestAccountBalance_NotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/accounts/999/balance",
 	})
@@ -2149,7 +3063,7 @@ func TestCreateTag(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"vacation","color":"#3B82F6"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/tags",
 		Body:   []byte(body),
@@ -2162,7 +3076,7 @@ func TestCreateTag(t *testing.T) {
 	}
 
 	// Verify it appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/tags",
 	})
@@ -2194,7 +3108,7 @@ func TestCreateTag_DuplicateName(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name":"recurring","color":"#EF4444"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/tags",
 		Body:   []byte(body),
@@ -2204,7 +3118,7 @@ func TestCreateTag_DuplicateName(t *testing.T) {
 	}
 
 	// Attempt to create a tag with the same name.
-	resp, err = p.HandleAPI(&sdk.APIRequest{
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/tags",
 		Body:   []byte(body),
@@ -2226,7 +3140,7 @@ func TestCreateTag_MissingName(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"color":"#10B981"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/tags",
 		Body:   []byte(body),
@@ -2249,7 +3163,7 @@ func TestUpdateTag(t *testing.T) {
 
 	// Create a tag to update.
 	createBody := `{"name":"work","color":"#F59E0B"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/tags",
 		Body:   []byte(createBody),
@@ -2271,7 +3185,7 @@ func TestUpdateTag(t *testing.T) {
 
 	// Update name and color.
 	updateBody := `{"name":"office","color":"#8B5CF6"}`
-	updateResp, err := p.HandleAPI(&sdk.APIRequest{
+	updateResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/tags/%d", created.ID),
 		Body:   []byte(updateBody),
@@ -2284,7 +3198,7 @@ func TestUpdateTag(t *testing.T) {
 	}
 
 	// Verify the update in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/tags",
 	})
@@ -2321,7 +3235,7 @@ func TestDeleteTag(t *testing.T) {
 
 	// Create a tag to delete.
 	createBody := `{"name":"temporary","color":"#DC2626"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/tags",
 		Body:   []byte(createBody),
@@ -2339,7 +3253,7 @@ func TestDeleteTag(t *testing.T) {
 	}
 
 	// Delete it.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/tags/%d", created.ID),
 	})
@@ -2351,7 +3265,7 @@ func TestDeleteTag(t *testing.T) {
 	}
 
 	// Verify it no longer appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/tags",
 	})
@@ -2372,7 +3286,7 @@ func TestListTags_OrderedByName(t *testing.T) {
 	names := []string{"zebra", "alpha", "mango"}
 	for _, name := range names {
 		body := fmt.Sprintf(`{"name":"%s"}`, name)
-		resp, err := p.HandleAPI(&sdk.APIRequest{
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 			Method: "POST",
 			Path:   "/tags",
 			Body:   []byte(body),
@@ -2383,7 +3297,7 @@ func TestListTags_OrderedByName(t *testing.T) {
 	}
 
 	// List and verify alphabetical order.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/tags",
 	})
@@ -2415,7 +3329,7 @@ func TestAccountBalance_InterestEstimation(t *testing.T) {
 
 	// Create a savings account with 2.5% interest rate.
 	createBody := `{"name":"Savings","type":"savings","currency":"EUR","interest_rate":2.5}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/accounts",
 		Body:   []byte(createBody),
@@ -2442,7 +3356,7 @@ func TestAccountBalance_InterestEstimation(t *testing.T) {
 	}
 
 	// GET balance: should include estimated_interest = 10000 * (2.5 / 100) = 250.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   fmt.Sprintf("/accounts/%d/balance", created.ID),
 	})
@@ -2479,7 +3393,7 @@ func TestAccountBalance_InterestEstimation(t *testing.T) {
 func createRecurringRule(t *testing.T, p *FinancePlugin, body string) int64 {
 	t.Helper()
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/recurring",
 		Body:   []byte(body),
@@ -2505,7 +3419,7 @@ func createRecurringRule(t *testing.T, p *FinancePlugin, body string) int64 {
 func generateRecurring(t *testing.T, p *FinancePlugin) int {
 	t.Helper()
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/recurring/generate",
 	})
@@ -2543,7 +3457,7 @@ func TestCreateRecurringRule_Monthly(t *testing.T) {
 	}
 
 	// Verify it appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/recurring",
 	})
@@ -2629,7 +3543,7 @@ func TestCreateRecurringRule_MissingFields(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			resp, err := p.HandleAPI(&sdk.APIRequest{
+			resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 				Method: "POST",
 				Path:   "/recurring",
 				Body:   []byte(tc.body),
@@ -2688,7 +3602,7 @@ func TestGenerateRecurring_Monthly(t *testing.T) {
 	}
 
 	// Verify transactions exist by listing them for the start month.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query: map[string]string{
@@ -2781,7 +3695,7 @@ func TestGenerateRecurring_RespectsEndDate(t *testing.T) {
 	generateRecurring(t, p)
 
 	// Verify the rule is now inactive.
-	ruleResp, err := p.HandleAPI(&sdk.APIRequest{
+	ruleResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/recurring",
 	})
@@ -2811,7 +3725,7 @@ func TestGenerateRecurring_RespectsEndDate(t *testing.T) {
 	// Verify that generated transactions only go up to end_date.
 	// Transactions should NOT have dates after end_date.
 	endDateParsed, _ := time.Parse("2006-01-02", endDate)
-	txResp, err := p.HandleAPI(&sdk.APIRequest{
+	txResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"type": "expense"},
@@ -2878,83 +3792,266 @@ func TestGenerateRecurring_Weekly(t *testing.T) {
 	}
 }
 
-func TestUpdateRecurringRule(t *testing.T) {
+func TestGenerateRecurring_PendingUntilDatePasses(t *testing.T) {
 	p := newTestPlugin(t)
 
-	body := `{
-		"amount": 50.00,
-		"type": "expense",
-		"category": "subscriptions",
-		"description": "Netflix",
-		"frequency": "monthly",
-		"day_of_month": 15,
-		"start_date": "2026-01-15"
-	}`
-
-	ruleID := createRecurringRule(t, p, body)
-
-	// Update amount and frequency to weekly.
-	updateBody := `{
-		"amount": 75.00,
+	// A weekly rule on today's weekday, from two weeks ago: three instances.
+	today := time.Now()
+	createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 30.00,
 		"type": "expense",
-		"category": "subscriptions",
-		"description": "Netflix Premium",
+		"category": "groceries",
 		"frequency": "weekly",
-		"day_of_week": 3,
-		"start_date": "2026-01-15"
-	}`
+		"day_of_week": %d,
+		"start_date": "%s"
+	}`, int(today.Weekday()), today.AddDate(0, 0, -14).Format("2006-01-02")))
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
-		Method: "PUT",
-		Path:   fmt.Sprintf("/recurring/%d", ruleID),
-		Body:   []byte(updateBody),
-	})
-	if err != nil {
-		t.Fatalf("update returned error: %v", err)
-	}
-	if resp.StatusCode != 200 {
-		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	if generated := generateRecurring(t, p); generated != 3 {
+		t.Fatalf("expected 3 generated transactions, got %d", generated)
 	}
 
-	data := parseDataObject(t, resp)
-	var rule recurring.Rule
-	if err := json.Unmarshal(data, &rule); err != nil {
-		t.Fatalf("failed to unmarshal: %v", err)
+	statuses := func() map[string]string {
+		t.Helper()
+		rows, err := p.db.Query("SELECT date, status FROM transactions WHERE is_recurring_instance = 1")
+		if err != nil {
+			t.Fatalf("query statuses failed: %v", err)
+		}
+		defer rows.Close()
+		byDate := make(map[string]string)
+		for rows.Next() {
+			var date, status string
+			if err := rows.Scan(&date, &status); err != nil {
+				t.Fatalf("scan failed: %v", err)
+			}
+			byDate[date] = status
+		}
+		return byDate
+	}
+	todayStr := today.Format("2006-01-02")
+	for date, status := range statuses() {
+		want := transactions.StatusCleared
+		if date == todayStr {
+			want = transactions.StatusPending
+		}
+		if status != want {
+			t.Errorf("expected the instance of %s to be %s, got %s", date, want, status)
+		}
 	}
 
-	if rule.Amount != 75.00 {
-		t.Errorf("expected amount 75.00, got %f", rule.Amount)
+	// Once its date has passed, the next run clears it.
+	if _, err := p.db.Exec("UPDATE transactions SET date = ? WHERE status = 'pending'", today.AddDate(0, 0, -1).Format("2006-01-02")); err != nil {
+		t.Fatalf("moving the pending instance failed: %v", err)
 	}
-	if rule.Frequency != "weekly" {
-		t.Errorf("expected frequency 'weekly', got '%s'", rule.Frequency)
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/recurring/generate"})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("generate failed: %v %v", err, resp)
 	}
-	if rule.Description != "Netflix Premium" {
-		t.Errorf("expected description 'Netflix Premium', got '%s'", rule.Description)
+	var result recurring.GenerateResult
+	if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+		t.Fatalf("failed to parse generate result: %v", err)
+	}
+	if result.Cleared != 1 {
+		t.Errorf("expected 1 cleared instance, got %d", result.Cleared)
+	}
+	for date, status := range statuses() {
+		if status != transactions.StatusCleared {
+			t.Errorf("expected the instance of %s to be cleared, got %s", date, status)
+		}
 	}
 }
 
-func TestDeleteRecurringRule(t *testing.T) {
+// recurringAction posts to a rule's sub-route, such as /pause, and returns the response.
+func recurringAction(t *testing.T, p *FinancePlugin, ruleID int64, action string, body string) *sdk.APIResponse {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   fmt.Sprintf("/recurring/%d/%s", ruleID, action),
+		Body:   []byte(body),
+	})
+	if err != nil {
+		t.Fatalf("%s recurring rule failed: %v", action, err)
+	}
+	return resp
+}
+
+func TestRecurring_PauseAndResume(t *testing.T) {
 	p := newTestPlugin(t)
 
-	// Create a rule and generate some transactions.
 	twoMonthsAgo := time.Now().AddDate(0, -2, 0)
-	startDate := fmt.Sprintf("%d-%02d-01", twoMonthsAgo.Year(), twoMonthsAgo.Month())
-
-	body := fmt.Sprintf(`{
-		"amount": 100.00,
+	ruleID := createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 12.00,
 		"type": "expense",
-		"category": "rent",
-		"description": "Storage unit",
+		"category": "subscriptions",
+		"description": "Music",
 		"frequency": "monthly",
 		"day_of_month": 1,
-		"start_date": "%s"
+		"start_date": "%d-%02d-01"
+	}`, twoMonthsAgo.Year(), twoMonthsAgo.Month()))
+	if generateRecurring(t, p) == 0 {
+		t.Fatal("expected transactions to be generated before pausing")
+	}
+
+	resp := recurringAction(t, p, ruleID, "pause", "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	var paused recurring.Rule
+	if err := json.Unmarshal(parseDataObject(t, resp), &paused); err != nil {
+		t.Fatalf("failed to parse paused rule: %v", err)
+	}
+	if paused.IsActive {
+		t.Error("expected the paused rule to be inactive")
+	}
+	if paused.LastGenerated == "" {
+		t.Fatal("expected last_generated to be set")
+	}
+
+	resp = recurringAction(t, p, ruleID, "resume", "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	var resumed recurring.Rule
+	if err := json.Unmarshal(parseDataObject(t, resp), &resumed); err != nil {
+		t.Fatalf("failed to parse resumed rule: %v", err)
+	}
+	if !resumed.IsActive {
+		t.Error("expected the resumed rule to be active")
+	}
+	if resumed.LastGenerated != paused.LastGenerated {
+		t.Errorf("expected last_generated %q to be preserved, got %q", paused.LastGenerated, resumed.LastGenerated)
+	}
+	if count := generateRecurring(t, p); count != 0 {
+		t.Errorf("expected resuming not to regenerate occurrences, got %d", count)
+	}
+
+	if resp := recurringAction(t, p, 999, "pause", ""); resp.StatusCode != 404 {
+		t.Errorf("expected 404 for an unknown rule, got %d", resp.StatusCode)
+	}
+}
+
+func TestRecurring_SkipOccurrence(t *testing.T) {
+	p := newTestPlugin(t)
+
+	threeMonthsAgo := time.Now().AddDate(0, -3, 0)
+	ruleID := createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 40.00,
+		"type": "expense",
+		"category": "utilities",
+		"description": "Internet",
+		"frequency": "monthly",
+		"day_of_month": 5,
+		"start_date": "%d-%02d-01"
+	}`, threeMonthsAgo.Year(), threeMonthsAgo.Month()))
+
+	skippedMonth := time.Now().AddDate(0, -2, 0)
+	skippedDate := fmt.Sprintf("%d-%02d-05", skippedMonth.Year(), skippedMonth.Month())
+
+	if resp := recurringAction(t, p, ruleID, "skip", `{"date": "`+skippedMonth.Format("2006-01")+`-06"}`); resp.StatusCode != 400 {
+		t.Errorf("expected 400 for a date the rule does not fall on, got %d", resp.StatusCode)
+	}
+	resp := recurringAction(t, p, ruleID, "skip", `{"date": "`+skippedDate+`"}`)
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	generateRecurring(t, p)
+
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "GET",
+		Path:   "/transactions",
+		Query:  map[string]string{"month": skippedMonth.Format("2006-01")},
+	})
+	if err != nil {
+		t.Fatalf("list transactions returned error: %v", err)
+	}
+	if items := parseDataArray(t, listResp); len(items) != 0 {
+		t.Errorf("expected the skipped occurrence not to be generated, got %d transactions", len(items))
+	}
+
+	firstDate := fmt.Sprintf("%d-%02d-05", threeMonthsAgo.Year(), threeMonthsAgo.Month())
+	if resp := recurringAction(t, p, ruleID, "skip", `{"date": "`+firstDate+`"}`); resp.StatusCode != 409 {
+		t.Errorf("expected 409 for an occurrence already generated, got %d", resp.StatusCode)
+	}
+}
+
+func TestUpdateRecurringRule(t *testing.T) {
+	p := newTestPlugin(t)
+
+	body := `{
+		"amount": 50.00,
+		"type": "expense",
+		"category": "subscriptions",
+		"description": "Netflix",
+		"frequency": "monthly",
+		"day_of_month": 15,
+		"start_date": "2026-01-15"
+	}`
+
+	ruleID := createRecurringRule(t, p, body)
+
+	// Update amount and frequency to weekly.
+	updateBody := `{
+		"amount": 75.00,
+		"type": "expense",
+		"category": "subscriptions",
+		"description": "Netflix Premium",
+		"frequency": "weekly",
+		"day_of_week": 3,
+		"start_date": "2026-01-15"
+	}`
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/recurring/%d", ruleID),
+		Body:   []byte(updateBody),
+	})
+	if err != nil {
+		t.Fatalf("update returned error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	data := parseDataObject(t, resp)
+	var rule recurring.Rule
+	if err := json.Unmarshal(data, &rule); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if rule.Amount != 75.00 {
+		t.Errorf("expected amount 75.00, got %f", rule.Amount)
+	}
+	if rule.Frequency != "weekly" {
+		t.Errorf("expected frequency 'weekly', got '%s'", rule.Frequency)
+	}
+	if rule.Description != "Netflix Premium" {
+		t.Errorf("expected description 'Netflix Premium', got '%s'", rule.Description)
+	}
+}
+
+func TestDeleteRecurringRule(t *testing.T) {
+	p := newTestPlugin(t)
+
+	// Create a rule and generate some transactions.
+	twoMonthsAgo := time.Now().AddDate(0, -2, 0)
+	startDate := fmt.Sprintf("%d-%02d-01", twoMonthsAgo.Year(), twoMonthsAgo.Month())
+
+	body := fmt.Sprintf(`{
+		"amount": 100.00,
+		"type": "expense",
+		"category": "rent",
+		"description": "Storage unit",
+		"frequency": "monthly",
+		"day_of_month": 1,
+		"start_date": "%s"
 	}`, startDate)
 
 	ruleID := createRecurringRule(t, p, body)
 	generateRecurring(t, p)
 
 	// Count generated transactions before delete.
-	txResp, err := p.HandleAPI(&sdk.APIRequest{
+	txResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"type": "expense"},
@@ -2968,7 +4065,7 @@ func TestDeleteRecurringRule(t *testing.T) {
 	}
 
 	// Delete (deactivate) the rule.
-	delResp, err := p.HandleAPI(&sdk.APIRequest{
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/recurring/%d", ruleID),
 	})
@@ -2980,7 +4077,7 @@ func TestDeleteRecurringRule(t *testing.T) {
 	}
 
 	// Verify the rule is now inactive.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/recurring",
 	})
@@ -3000,7 +4097,7 @@ func TestDeleteRecurringRule(t *testing.T) {
 	}
 
 	// Verify generated transactions still exist (soft delete does not remove them).
-	txRespAfter, err := p.HandleAPI(&sdk.APIRequest{
+	txRespAfter, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/transactions",
 		Query:  map[string]string{"type": "expense"},
@@ -3020,7 +4117,7 @@ func TestDeleteRecurringRule(t *testing.T) {
 func createBudget(t *testing.T, p *FinancePlugin, body string) int64 {
 	t.Helper()
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/budgets",
 		Body:   []byte(body),
@@ -3045,7 +4142,7 @@ func createBudget(t *testing.T, p *FinancePlugin, body string) int64 {
 func TestCreateBudget_Global(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/budgets",
 		Body:   []byte(`{"name":"Monthly Total","amount":1000,"month":"2026-01"}`),
@@ -3083,7 +4180,7 @@ func TestCreateBudget_Global(t *testing.T) {
 func TestCreateBudget_PerCategory(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/budgets",
 		Body:   []byte(`{"name":"Eating Out","category":"restaurants","amount":200,"month":"2026-01"}`),
@@ -3119,7 +4216,7 @@ func TestListBudgets_CalculatesSpent(t *testing.T) {
 	createTransaction(t, p, `{"amount":150,"type":"expense","category":"restaurants","description":"dinner","date":"2026-02-10"}`)
 
 	// List budgets for 2026-02.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/budgets",
 		Query:  map[string]string{"month": "2026-02"},
@@ -3161,7 +4258,7 @@ func TestListBudgets_OverBudget(t *testing.T) {
 	// Spend 150 (over budget).
 	createTransaction(t, p, `{"amount":150,"type":"expense","category":"groceries","description":"weekly shop","date":"2026-03-05"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/budgets",
 		Query:  map[string]string{"month": "2026-03"},
@@ -3202,7 +4299,7 @@ func TestListBudgets_GlobalSumsAllExpenses(t *testing.T) {
 	// Also create an income transaction (should NOT be counted).
 	createTransaction(t, p, `{"amount":2000,"type":"income","category":"salary","description":"paycheck","date":"2026-04-01"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/budgets",
 		Query:  map[string]string{"month": "2026-04"},
@@ -3239,7 +4336,7 @@ func TestUpdateBudget(t *testing.T) {
 	budgetID := createBudget(t, p, `{"name":"Food","category":"food","amount":300,"month":"2026-05"}`)
 
 	// Update amount to 400.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/budgets/%d", budgetID),
 		Body:   []byte(`{"name":"Food Updated","category":"food","amount":400,"month":"2026-05"}`),
@@ -3271,7 +4368,7 @@ func TestDeleteBudget(t *testing.T) {
 	budgetID := createBudget(t, p, `{"name":"Temp","category":"misc","amount":100,"month":"2026-06"}`)
 
 	// Delete it.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/budgets/%d", budgetID),
 	})
@@ -3283,7 +4380,7 @@ func TestDeleteBudget(t *testing.T) {
 	}
 
 	// Verify it no longer appears in the list.
-	listResp, err := p.HandleAPI(&sdk.APIRequest{
+	listResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/budgets",
 		Query:  map[string]string{"month": "2026-06"},
@@ -3304,7 +4401,7 @@ func TestDeleteBudget(t *testing.T) {
 func createGoal(t *testing.T, p *FinancePlugin, body string) int64 {
 	t.Helper()
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/goals",
 		Body:   []byte(body),
@@ -3329,7 +4426,7 @@ func createGoal(t *testing.T, p *FinancePlugin, body string) int64 {
 func TestCreateGoal(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/goals",
 		Body:   []byte(`{"name":"Vacation Fund","target_amount":5000,"target_date":"2026-12-31","icon":"plane","color":"#3B82F6"}`),
@@ -3382,7 +4479,7 @@ func TestContribute(t *testing.T) {
 	goalID := createGoal(t, p, `{"name":"Emergency Fund","target_amount":2000,"icon":"shield","color":"#EF4444"}`)
 
 	// Contribute 500.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   fmt.Sprintf("/goals/%d/contribute", goalID),
 		Body:   []byte(`{"amount":500}`),
@@ -3414,7 +4511,7 @@ func TestContribute_AutoCompletes(t *testing.T) {
 	goalID := createGoal(t, p, `{"name":"New Laptop","target_amount":1000,"icon":"laptop","color":"#8B5CF6"}`)
 
 	// Contribute the full target amount.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   fmt.Sprintf("/goals/%d/contribute", goalID),
 		Body:   []byte(`{"amount":1000}`),
@@ -3446,7 +4543,7 @@ func TestUpdateGoal(t *testing.T) {
 	goalID := createGoal(t, p, `{"name":"Old Car","target_amount":3000,"icon":"car","color":"#F59E0B"}`)
 
 	// Update target amount and name.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/goals/%d", goalID),
 		Body:   []byte(`{"name":"New Car","target_amount":5000,"icon":"car","color":"#F59E0B"}`),
@@ -3478,7 +4575,7 @@ func TestDeleteGoal(t *testing.T) {
 	goalID := createGoal(t, p, `{"name":"Temp Goal","target_amount":100,"icon":"star","color":"#EC4899"}`)
 
 	// Delete it.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/goals/%d", goalID),
 	})
@@ -3490,7 +4587,7 @@ func TestDeleteGoal(t *testing.T) {
 	}
 
 	// Verify it returns 404 when we try to get it (via contribute).
-	resp2, err := p.HandleAPI(&sdk.APIRequest{
+	resp2, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   fmt.Sprintf("/goals/%d/contribute", goalID),
 		Body:   []byte(`{"amount":10}`),
@@ -3508,7 +4605,7 @@ func TestContribute_NegativeAmount(t *testing.T) {
 
 	goalID := createGoal(t, p, `{"name":"Test","target_amount":500,"icon":"star","color":"#000000"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   fmt.Sprintf("/goals/%d/contribute", goalID),
 		Body:   []byte(`{"amount":-50}`),
@@ -3529,7 +4626,7 @@ func TestContribute_NegativeAmount(t *testing.T) {
 func TestCreateGoal_EmptyName(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/goals",
 		Body:   []byte(`{"name":"","target_amount":500,"icon":"star","color":"#000000"}`),
@@ -3553,7 +4650,7 @@ func TestListGoals(t *testing.T) {
 	createGoal(t, p, `{"name":"Goal A","target_amount":1000,"icon":"a","color":"#111111"}`)
 	createGoal(t, p, `{"name":"Goal B","target_amount":2000,"icon":"b","color":"#222222"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/goals",
 	})
@@ -3594,7 +4691,7 @@ func TestListGoals(t *testing.T) {
 func createInvestment(t *testing.T, p *FinancePlugin, body string) int64 {
 	t.Helper()
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/investments",
 		Body:   []byte(body),
@@ -3619,7 +4716,7 @@ func createInvestment(t *testing.T, p *FinancePlugin, body string) int64 {
 func TestCreateInvestment(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/investments",
 		Body: []byte(`{
@@ -3686,7 +4783,7 @@ func TestListInvestments_CalculatesPnL(t *testing.T) {
 		"currency": "EUR"
 	}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/investments",
 	})
@@ -3738,7 +4835,7 @@ func TestUpdateInvestment_PriceUpdate(t *testing.T) {
 	}`)
 
 	// Update current_price to 4000.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/investments/%d", id),
 		Body: []byte(`{
@@ -3793,7 +4890,7 @@ func TestDeleteInvestment(t *testing.T) {
 	}`)
 
 	// Delete the investment.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/investments/%d", id),
 	})
@@ -3805,7 +4902,7 @@ func TestDeleteInvestment(t *testing.T) {
 	}
 
 	// Verify it returns 404 on update attempt.
-	resp, err = p.HandleAPI(&sdk.APIRequest{
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/investments/%d", id),
 		Body:   []byte(`{"name": "SPDR", "type": "etf"}`),
@@ -3821,7 +4918,7 @@ func TestDeleteInvestment(t *testing.T) {
 func TestCreateInvestment_InvalidType(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/investments",
 		Body:   []byte(`{"name": "Treasury Bond", "type": "bond"}`),
@@ -3852,7 +4949,7 @@ func TestPnLPercentage_ZeroInvested(t *testing.T) {
 		"currency": "EUR"
 	}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/investments",
 	})
@@ -3902,7 +4999,7 @@ func TestListInvestments_NilUnits(t *testing.T) {
 		"notes": "Tracking only"
 	}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/investments",
 	})
@@ -3952,7 +5049,7 @@ func TestSummary_MonthlyTotals(t *testing.T) {
 	createTransaction(t, p, `{"amount": 1200, "type": "expense", "category": "rent", "description": "Rent", "date": "2025-03-05"}`)
 	createTransaction(t, p, `{"amount": 200, "type": "expense", "category": "food", "description": "Groceries", "date": "2025-03-10"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/reports/summary",
 		Query:  map[string]string{"month": "2025-03"},
@@ -3992,7 +5089,7 @@ func TestSummary_ByCategory(t *testing.T) {
 	createTransaction(t, p, `{"amount": 150, "type": "expense", "category": "food", "description": "Restaurants", "date": "2025-04-10"}`)
 	createTransaction(t, p, `{"amount": 50, "type": "expense", "category": "transport", "description": "Metro", "date": "2025-04-15"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/reports/summary",
 		Query:  map[string]string{"month": "2025-04"},
@@ -4039,7 +5136,7 @@ func TestSummary_ByAccount(t *testing.T) {
 	// Transactions in Savings Account.
 	createTransaction(t, p, fmt.Sprintf(`{"amount": 1000, "type": "income", "category": "transfer", "description": "Savings deposit", "date": "2025-05-10", "account_id": %d}`, savingsID))
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/reports/summary",
 		Query:  map[string]string{"month": "2025-05"},
@@ -4106,7 +5203,7 @@ func TestTrends_SixMonthRange(t *testing.T) {
 		))
 	}
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/reports/trends",
 		Query:  map[string]string{"from": "2025-01", "to": "2025-06"},
@@ -4169,7 +5266,7 @@ func TestCategoryComparison_WithPreviousMonth(t *testing.T) {
 	createTransaction(t, p, `{"amount": 300, "type": "expense", "category": "food", "description": "Groceries", "date": "2025-03-10"}`)
 	createTransaction(t, p, `{"amount": 200, "type": "expense", "category": "transport", "description": "Metro", "date": "2025-03-15"}`)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/reports/categories",
 		Query:  map[string]string{"month": "2025-03"},
@@ -4255,7 +5352,7 @@ func TestNetWorth_AccountsAndInvestments(t *testing.T) {
 	createInvestment(t, p, `{"name": "Watchlist", "type": "crypto", "currency": "EUR"}`)
 	// Investments total = (10 * 115) + (5 * 250) = 1150 + 1250 = 2400.
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/reports/net-worth",
 	})
@@ -4282,3 +5379,1336 @@ func TestNetWorth_AccountsAndInvestments(t *testing.T) {
 		t.Errorf("expected net worth 7100, got %f", nw.NetWorth)
 	}
 }
+
+// --- Stats Tests ---
+
+func TestStatsToday_SpendWeekAndBudget(t *testing.T) {
+	p := newTestPlugin(t)
+
+	// Wednesday 2026-03-18: the week starts on Monday 2026-03-16.
+	createBudget(t, p, `{"name":"Monthly Total","amount":1000,"month":"2026-03"}`)
+	createTransaction(t, p, `{"amount":20,"type":"expense","category":"food","date":"2026-03-18"}`)
+	createTransaction(t, p, `{"amount":30,"type":"expense","category":"food","date":"2026-03-16"}`)
+	createTransaction(t, p, `{"amount":100,"type":"expense","category":"rent","date":"2026-03-02"}`)
+	// Outside the week or not an expense: excluded from today/week.
+	createTransaction(t, p, `{"amount":40,"type":"expense","category":"food","date":"2026-03-15"}`)
+	createTransaction(t, p, `{"amount":500,"type":"income","category":"salary","date":"2026-03-18"}`)
+	// Dated after today, so not spent yet.
+	createTransaction(t, p, `{"amount":60,"type":"expense","category":"food","date":"2026-03-19"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "GET",
+		Path:   "/stats/today",
+		Query:  map[string]string{"date": "2026-03-18"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	data := parseDataObject(t, resp)
+	var s stats.TodayStats
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("failed to parse stats: %v", err)
+	}
+
+	if s.Date != "2026-03-18" {
+		t.Errorf("expected date 2026-03-18, got %s", s.Date)
+	}
+	if s.Today != 20 {
+		t.Errorf("expected today 20, got %f", s.Today)
+	}
+	if s.Week != 50 {
+		t.Errorf("expected week 50, got %f", s.Week)
+	}
+	// Month spend counts March's expenses up to today: 20 + 30 + 100 + 40 = 190.
+	if s.BudgetRemaining == nil || *s.BudgetRemaining != 810 {
+		t.Errorf("expected budget remaining 810, got %v", s.BudgetRemaining)
+	}
+}
+
+func TestStatsToday_WeekSpansMonths(t *testing.T) {
+	p := newTestPlugin(t)
+
+	// Sunday 2026-03-01: the week started on Monday 2026-02-23.
+	createTransaction(t, p, `{"amount":15,"type":"expense","category":"food","date":"2026-02-25"}`)
+	createTransaction(t, p, `{"amount":10,"type":"expense","category":"food","date":"2026-03-01"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "GET",
+		Path:   "/stats/today",
+		Query:  map[string]string{"date": "2026-03-01"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := parseDataObject(t, resp)
+	var s stats.TodayStats
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("failed to parse stats: %v", err)
+	}
+
+	if s.Today != 10 {
+		t.Errorf("expected today 10, got %f", s.Today)
+	}
+	if s.Week != 25 {
+		t.Errorf("expected week 25, got %f", s.Week)
+	}
+	if s.BudgetRemaining != nil {
+		t.Errorf("expected no budget remaining without a global budget, got %f", *s.BudgetRemaining)
+	}
+}
+
+func TestStatsToday_DefaultsToToday(t *testing.T) {
+	p := newTestPlugin(t)
+
+	today := time.Now().Format("2006-01-02")
+	createTransaction(t, p, fmt.Sprintf(`{"amount":12.5,"type":"expense","category":"food","date":"%s"}`, today))
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/stats/today"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := parseDataObject(t, resp)
+	var s stats.TodayStats
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("failed to parse stats: %v", err)
+	}
+
+	if s.Date != today {
+		t.Errorf("expected date %s, got %s", today, s.Date)
+	}
+	if s.Today != 12.5 {
+		t.Errorf("expected today 12.5, got %f", s.Today)
+	}
+}
+
+func TestStatsToday_InvalidDate(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "GET",
+		Path:   "/stats/today",
+		Query:  map[string]string{"date": "18-03-2026"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+
+	code, _ := parseErrorResponse(t, resp)
+	if code != "VALIDATION_ERROR" {
+		t.Errorf("expected VALIDATION_ERROR, got %s", code)
+	}
+}
+
+// --- Round-up Tests ---
+
+// saveRoundupRule is a test helper that configures round-ups for an account.
+func saveRoundupRule(t *testing.T, p *FinancePlugin, accountID int64, body string) *sdk.APIResponse {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/roundup/rules/%d", accountID),
+		Body:   []byte(body),
+	})
+	if err != nil {
+		t.Fatalf("save roundup rule failed: %v", err)
+	}
+	return resp
+}
+
+func TestRoundUpAmount(t *testing.T) {
+	tests := []struct {
+		amount    float64
+		increment float64
+		want      float64
+	}{
+		{amount: 3.40, increment: 1, want: 0.60},
+		{amount: 3.00, increment: 1, want: 0},
+		{amount: 0.01, increment: 1, want: 0.99},
+		{amount: 12.30, increment: 5, want: 2.70},
+		{amount: 19.99, increment: 0.5, want: 0.01},
+	}
+
+	for _, tt := range tests {
+		if got := roundup.RoundUpAmount(tt.amount, tt.increment); math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("RoundUpAmount(%v, %v) = %v, want %v", tt.amount, tt.increment, got, tt.want)
+		}
+	}
+}
+
+func TestRoundup_TransfersToSavingsOnCreate(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	resp := saveRoundupRule(t, p, 1, fmt.Sprintf(`{"dest_account_id":%d,"start_date":"2026-01-01"}`, savingsID))
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	expenseID := createTransaction(t, p, `{"amount":3.40,"type":"expense","category":"food","date":"2026-03-10"}`)
+
+	// Creating the expense triggers the round-up transfer.
+	var amount float64
+	var accountID, destAccountID int64
+	err := p.db.QueryRow(
+		`SELECT amount, account_id, dest_account_id FROM transactions WHERE type = 'transfer' AND date = '2026-03-10'`,
+	).Scan(&amount, &accountID, &destAccountID)
+	if err != nil {
+		t.Fatalf("expected a round-up transfer: %v", err)
+	}
+	if math.Abs(amount-0.60) > 0.0001 || accountID != 1 || destAccountID != savingsID {
+		t.Errorf("unexpected transfer: amount=%v account=%d dest=%d", amount, accountID, destAccountID)
+	}
+
+	// Running again is a no-op: the expense is only rounded up once.
+	runResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/roundup/run"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result roundup.RunResult
+	if err := json.Unmarshal(parseDataObject(t, runResp), &result); err != nil {
+		t.Fatalf("failed to parse run result: %v", err)
+	}
+	if result.Processed != 0 {
+		t.Errorf("expected no pending round-ups, processed %d", result.Processed)
+	}
+
+	var recorded int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM roundups WHERE expense_id = ?", expenseID).Scan(&recorded); err != nil {
+		t.Fatalf("query roundups failed: %v", err)
+	}
+	if recorded != 1 {
+		t.Errorf("expected 1 round-up record, got %d", recorded)
+	}
+}
+
+func TestRoundup_VirtualGoal(t *testing.T) {
+	p := newTestPlugin(t)
+
+	goalID := createGoal(t, p, `{"name":"Holiday","target_amount":1}`)
+	saveRoundupRule(t, p, 1, fmt.Sprintf(`{"goal_id":%d,"start_date":"2026-01-01"}`, goalID))
+
+	createTransaction(t, p, `{"amount":2.25,"type":"expense","category":"food","date":"2026-03-10"}`)
+	createTransaction(t, p, `{"amount":4.50,"type":"expense","category":"food","date":"2026-03-11"}`)
+
+	var current float64
+	var completed int
+	if err := p.db.QueryRow("SELECT current_amount, is_completed FROM savings_goals WHERE id = ?", goalID).Scan(&current, &completed); err != nil {
+		t.Fatalf("query goal failed: %v", err)
+	}
+	if math.Abs(current-1.25) > 0.0001 {
+		t.Errorf("expected goal to hold 1.25, got %v", current)
+	}
+	if completed != 1 {
+		t.Error("expected goal to be completed once the target is reached")
+	}
+
+	// A virtual goal moves no money between accounts.
+	var transfers int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM transactions WHERE type = 'transfer'").Scan(&transfers); err != nil {
+		t.Fatalf("query transfers failed: %v", err)
+	}
+	if transfers != 0 {
+		t.Errorf("expected no transfers for a virtual goal, got %d", transfers)
+	}
+}
+
+func TestRoundup_GoalContributionsShowInProjections(t *testing.T) {
+	p := newTestPlugin(t)
+
+	goalID := createGoal(t, p, `{"name":"Holiday","target_amount":100}`)
+	saveRoundupRule(t, p, 1, fmt.Sprintf(`{"goal_id":%d,"start_date":"2026-01-01"}`, goalID))
+
+	createTransaction(t, p, `{"amount":2.25,"type":"expense","category":"food","date":"2026-03-10"}`)
+	createTransaction(t, p, `{"amount":4.50,"type":"expense","category":"food","date":"2026-03-11"}`)
+
+	var contributions int
+	var total float64
+	if err := p.db.QueryRow(
+		"SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM goal_contributions WHERE goal_id = ? AND date IN ('2026-03-10', '2026-03-11')", goalID,
+	).Scan(&contributions, &total); err != nil {
+		t.Fatalf("query contributions failed: %v", err)
+	}
+	if contributions != 2 || math.Abs(total-1.25) > 0.0001 {
+		t.Fatalf("expected 2 contributions totalling 1.25 on the expense dates, got %d totalling %v", contributions, total)
+	}
+
+	service := goals.NewService(goals.NewRepository(p.db))
+	projections, appErr := service.Projections(time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC))
+	if appErr != nil {
+		t.Fatalf("projections failed: %v", appErr)
+	}
+	if len(projections) != 1 || projections[0].MonthlyAverage <= 0 || projections[0].ProjectedDate == nil {
+		t.Errorf("expected the round-ups to drive the goal's projection, got %+v", projections)
+	}
+}
+
+func TestRoundup_SkipsBeforeStartAndOtherAccounts(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	cashID := createAccount(t, p, `{"name":"Cash","type":"cash"}`)
+
+	createTransaction(t, p, `{"amount":1.50,"type":"expense","category":"food","date":"2026-02-28"}`)
+	saveRoundupRule(t, p, 1, fmt.Sprintf(`{"dest_account_id":%d,"start_date":"2026-03-01"}`, savingsID))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":1.50,"type":"expense","category":"food","account_id":%d,"date":"2026-03-05"}`, cashID))
+	createTransaction(t, p, `{"amount":10,"type":"income","category":"salary","date":"2026-03-05"}`)
+
+	var transfers int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM transactions WHERE type = 'transfer'").Scan(&transfers); err != nil {
+		t.Fatalf("query transfers failed: %v", err)
+	}
+	if transfers != 0 {
+		t.Errorf("expected no round-ups, got %d transfers", transfers)
+	}
+}
+
+func TestRoundup_ReadsDoNotApplyPending(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	saveRoundupRule(t, p, 1, fmt.Sprintf(`{"dest_account_id":%d,"start_date":"2026-01-01"}`, savingsID))
+
+	// An expense written behind the API stays pending until the next write.
+	if _, err := p.db.Exec(
+		"INSERT INTO transactions (amount, type, category, description, date, account_id) VALUES (3.40, 'expense', 'food', '', '2026-03-10', 1)",
+	); err != nil {
+		t.Fatalf("insert expense failed: %v", err)
+	}
+
+	for _, path := range []string{"/transactions", "/recurring", "/budgets"} {
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: path, Query: map[string]string{"month": "2026-03"}})
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("GET %s failed: %v %v", path, resp, err)
+		}
+	}
+
+	var roundups, transfers int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM roundups").Scan(&roundups); err != nil {
+		t.Fatalf("query roundups failed: %v", err)
+	}
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM transactions WHERE type = 'transfer'").Scan(&transfers); err != nil {
+		t.Fatalf("query transfers failed: %v", err)
+	}
+	if roundups != 0 || transfers != 0 {
+		t.Errorf("expected reads to write no round-ups, got %d records and %d transfers", roundups, transfers)
+	}
+
+	// The next write picks the pending expense up.
+	createTransaction(t, p, `{"amount":10,"type":"income","category":"salary","date":"2026-03-11"}`)
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM roundups").Scan(&roundups); err != nil {
+		t.Fatalf("query roundups failed: %v", err)
+	}
+	if roundups != 1 {
+		t.Errorf("expected the pending expense rounded up by the next write, got %d records", roundups)
+	}
+}
+
+func TestHandleAPI_NotifiesChangedTopics(t *testing.T) {
+	p := newTestPlugin(t)
+	var topics []string
+	p.notifyChanged = func(topic string) error {
+		topics = append(topics, topic)
+		return nil
+	}
+
+	createTransaction(t, p, `{"amount":12,"type":"expense","category":"food","date":"2026-03-10"}`)
+	createBudget(t, p, `{"name":"Groceries","category":"groceries","amount":100,"month":"2026-03"}`)
+	createRecurringRule(t, p, `{"amount":30,"type":"expense","category":"gym","frequency":"weekly","day_of_week":1,"start_date":"2026-03-01"}`)
+	body := `{"preset": "revolut", "csv": "Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance\nCARD_PAYMENT,Current,2026-03-01 10:00:00,2026-03-02 09:30:00,Coffee,-2.80,0.00,EUR,COMPLETED,100.00\n"}`
+	if resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/import", Body: []byte(body)}); err != nil || resp.StatusCode != 201 {
+		t.Fatalf("import failed: %v %v", resp, err)
+	}
+
+	// Every other write reports its route as the topic.
+	goalID := createGoal(t, p, `{"name":"Holiday","target_amount":500}`)
+	if resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: fmt.Sprintf("/goals/%d/contribute", goalID), Body: []byte(`{"amount":50}`)}); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("contribution failed: %v %v", resp, err)
+	}
+	createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	tagID := createTag(t, p, "essential", "#10B981")
+	if resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/tags/%d", tagID)}); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("tag delete failed: %v %v", resp, err)
+	}
+
+	// Reads and refused writes change nothing to refresh.
+	p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions"})
+	p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/transactions", Body: []byte(`{"amount":-1}`)})
+	p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/goals", Body: []byte(`{}`)})
+
+	want := "transactions,budgets,recurring,transactions,goals,goals,accounts,tags,tags"
+	if got := strings.Join(topics, ","); got != want {
+		t.Errorf("expected %s notified, got %s", want, got)
+	}
+}
+
+func TestRoundup_RuleValidation(t *testing.T) {
+	p := newTestPlugin(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "no destination", body: `{}`},
+		{name: "same account", body: `{"dest_account_id":1}`},
+		{name: "unknown account", body: `{"dest_account_id":999}`},
+		{name: "unknown goal", body: `{"goal_id":999}`},
+		{name: "increment too large", body: `{"dest_account_id":2,"increment":500}`},
+	}
+
+	createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := saveRoundupRule(t, p, 1, tt.body)
+			if resp.StatusCode != 400 {
+				t.Fatalf("expected 400, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+			}
+		})
+	}
+}
+
+func TestRoundup_ListAndDeleteRule(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	saveRoundupRule(t, p, 1, fmt.Sprintf(`{"dest_account_id":%d}`, savingsID))
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/roundup/rules"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rules := parseDataArray(t, resp)
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+
+	var rule roundup.Rule
+	if err := json.Unmarshal(rules[0], &rule); err != nil {
+		t.Fatalf("failed to parse rule: %v", err)
+	}
+	if rule.Increment != 1 || !rule.IsActive || rule.StartDate != time.Now().Format("2006-01-02") {
+		t.Errorf("unexpected rule defaults: %+v", rule)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/roundup/rules/1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/roundup/rules/1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404 for a missing rule, got %d", resp.StatusCode)
+	}
+}
+
+// --- Export Tests ---
+
+// newExportTestPlugin creates a test plugin with the background export scheduler
+// stopped, so only the runs a test triggers are recorded.
+func newExportTestPlugin(t *testing.T) *FinancePlugin {
+	t.Helper()
+
+	p := newTestPlugin(t)
+	p.stopExports()
+	return p
+}
+
+// createExportSchedule is a test helper that creates an export schedule via the API and returns the ID.
+func createExportSchedule(t *testing.T, p *FinancePlugin, body string) int64 {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/exports",
+		Body:   []byte(body),
+	})
+	if err != nil {
+		t.Fatalf("create export schedule failed: %v", err)
+	}
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var schedule exports.Schedule
+	if err := json.Unmarshal(parseDataObject(t, resp), &schedule); err != nil {
+		t.Fatalf("failed to parse created export schedule: %v", err)
+	}
+	return schedule.ID
+}
+
+// runExport is a test helper that re-runs an export schedule for a month.
+func runExport(t *testing.T, p *FinancePlugin, id int64, month string) exports.Run {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   fmt.Sprintf("/exports/%d/run", id),
+		Body:   []byte(fmt.Sprintf(`{"month":%q}`, month)),
+	})
+	if err != nil {
+		t.Fatalf("run export failed: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var run exports.Run
+	if err := json.Unmarshal(parseDataObject(t, resp), &run); err != nil {
+		t.Fatalf("failed to parse export run: %v", err)
+	}
+	return run
+}
+
+func TestExports_RunWritesCSVPerAccount(t *testing.T) {
+	p := newExportTestPlugin(t)
+	target := t.TempDir()
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings"}`)
+	createTransaction(t, p, `{"amount":1200,"type":"income","category":"salary","date":"2026-03-01"}`)
+	createTransaction(t, p, `{"amount":45.50,"type":"expense","category":"food","description":"Market","date":"2026-03-05"}`)
+	createTransaction(t, p, fmt.Sprintf(`{"amount":200,"type":"transfer","category":"savings","date":"2026-03-10","dest_account_id":%d}`, savingsID))
+	createTransaction(t, p, `{"amount":10,"type":"expense","category":"food","date":"2026-04-02"}`)
+
+	id := createExportSchedule(t, p, fmt.Sprintf(`{"name":"Accountant","target":%q}`, target))
+	run := runExport(t, p, id, "2026-03")
+
+	if run.Status != exports.StatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if len(run.Files) != 2 || run.Rows != 4 {
+		t.Errorf("expected 2 files and 4 rows, got %d files and %d rows", len(run.Files), run.Rows)
+	}
+
+	mainCSV, err := os.ReadFile(filepath.Join(target, "transactions-2026-03-account-1.csv"))
+	if err != nil {
+		t.Fatalf("expected the main account export: %v", err)
+	}
+	if !strings.HasPrefix(string(mainCSV), "date,type,category,description,amount,account,dest_account,transaction_id\n") {
+		t.Errorf("unexpected CSV header:\n%s", mainCSV)
+	}
+	for _, want := range []string{",1200.00,", ",-45.50,", ",-200.00,"} {
+		if !strings.Contains(string(mainCSV), want) {
+			t.Errorf("expected %q in main account export:\n%s", want, mainCSV)
+		}
+	}
+	if strings.Contains(string(mainCSV), "2026-04-02") {
+		t.Errorf("expected only March transactions:\n%s", mainCSV)
+	}
+
+	savingsCSV, err := os.ReadFile(filepath.Join(target, fmt.Sprintf("transactions-2026-03-account-%d.csv", savingsID)))
+	if err != nil {
+		t.Fatalf("expected the savings account export: %v", err)
+	}
+	if !strings.Contains(string(savingsCSV), ",200.00,") {
+		t.Errorf("expected incoming transfer in savings export:\n%s", savingsCSV)
+	}
+}
+
+func TestExports_ListShowsLastRun(t *testing.T) {
+	p := newExportTestPlugin(t)
+
+	id := createExportSchedule(t, p, fmt.Sprintf(`{"name":"Accountant","target":%q,"day_of_month":3}`, t.TempDir()))
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/exports"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var schedules []exports.Schedule
+	if err := json.Unmarshal(parseDataObject(t, resp), &schedules); err != nil {
+		t.Fatalf("failed to parse schedules: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].LastRun != nil {
+		t.Fatalf("expected one schedule that never ran, got %+v", schedules)
+	}
+	if schedules[0].TargetType != exports.TargetDirectory || schedules[0].DayOfMonth != 3 || !schedules[0].IsActive {
+		t.Errorf("unexpected schedule: %+v", schedules[0])
+	}
+
+	runExport(t, p, id, "2026-02")
+	runExport(t, p, id, "2026-03")
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/exports"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &schedules); err != nil {
+		t.Fatalf("failed to parse schedules: %v", err)
+	}
+	if schedules[0].LastRun == nil || schedules[0].LastRun.Month != "2026-03" || schedules[0].LastRun.Status != exports.StatusSuccess {
+		t.Errorf("expected the latest run in the listing, got %+v", schedules[0].LastRun)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: fmt.Sprintf("/exports/%d/runs", id)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	runs := parseDataArray(t, resp)
+	if len(runs) != 2 {
+		t.Errorf("expected 2 runs in history, got %d", len(runs))
+	}
+}
+
+func TestExports_HTTPTarget(t *testing.T) {
+	p := newExportTestPlugin(t)
+
+	var received []string
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if fail {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(request.Body)
+		if request.Header.Get("Content-Type") != "text/csv" || !strings.HasPrefix(string(body), "date,") {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, request.Header.Get("X-Export-Filename"))
+	}))
+	defer server.Close()
+
+	createTransaction(t, p, `{"amount":30,"type":"expense","category":"food","date":"2026-03-05"}`)
+	id := createExportSchedule(t, p, fmt.Sprintf(`{"name":"Backup","target_type":"http","target":%q,"account_id":1}`, server.URL))
+
+	run := runExport(t, p, id, "2026-03")
+	if run.Status != exports.StatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if len(received) != 1 || received[0] != "transactions-2026-03-account-1.csv" {
+		t.Errorf("unexpected uploads: %v", received)
+	}
+
+	// A failing target is recorded as a failed run rather than an API error.
+	fail = true
+	run = runExport(t, p, id, "2026-03")
+	if run.Status != exports.StatusFailed || !strings.Contains(run.Error, "500") {
+		t.Errorf("expected a failed run mentioning status 500, got %+v", run)
+	}
+}
+
+func TestExports_HTTPTargetNeedsNetworkPermission(t *testing.T) {
+	// Without the network permission the host routes the plugin's outbound
+	// HTTP to a blocked proxy, and every upload to an HTTP target fails.
+	manifest, err := (&FinancePlugin{}).GetManifest()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(manifest.Permissions, "network") {
+		t.Errorf("expected GetManifest to declare the network permission, got %v", manifest.Permissions)
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "manifest.json"))
+	if err != nil {
+		t.Fatalf("reading manifest.json: %v", err)
+	}
+	var file struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("parsing manifest.json: %v", err)
+	}
+	if !slices.Contains(file.Permissions, "network") {
+		t.Errorf("expected manifest.json to declare the network permission, got %v", file.Permissions)
+	}
+}
+
+func TestExports_Validation(t *testing.T) {
+	p := newExportTestPlugin(t)
+
+	bodies := []string{
+		`{"target":"exports"}`,
+		`{"name":"Bad","target_type":"ftp","target":"x"}`,
+		`{"name":"Bad","target_type":"http","target":"not-a-url"}`,
+		`{"name":"Bad","day_of_month":31}`,
+		`{"name":"Bad","account_id":999}`,
+	}
+	for _, body := range bodies {
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/exports", Body: []byte(body)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("expected 400 for %s, got %d", body, resp.StatusCode)
+			continue
+		}
+		if code, _ := parseErrorResponse(t, resp); code != "VALIDATION_ERROR" {
+			t.Errorf("expected VALIDATION_ERROR for %s, got %s", body, code)
+		}
+	}
+
+	id := createExportSchedule(t, p, `{"name":"Accountant"}`)
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   fmt.Sprintf("/exports/%d/run", id),
+		Query:  map[string]string{"month": "March"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for an invalid month, got %d", resp.StatusCode)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/exports/999/run"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404 for a missing schedule, got %d", resp.StatusCode)
+	}
+}
+
+func TestExports_UpdateAndDelete(t *testing.T) {
+	p := newExportTestPlugin(t)
+
+	id := createExportSchedule(t, p, `{"name":"Accountant"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/exports/%d", id),
+		Body:   []byte(`{"name":"Accountant","day_of_month":10,"is_active":false}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	var schedule exports.Schedule
+	if err := json.Unmarshal(parseDataObject(t, resp), &schedule); err != nil {
+		t.Fatalf("failed to parse schedule: %v", err)
+	}
+	if schedule.DayOfMonth != 10 || schedule.IsActive || schedule.Target != "exports" {
+		t.Errorf("unexpected updated schedule: %+v", schedule)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/exports/%d", id)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: fmt.Sprintf("/exports/%d", id)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404 after delete, got %d", resp.StatusCode)
+	}
+}
+
+func TestSearch_Transactions(t *testing.T) {
+	p := newTestPlugin(t)
+
+	createTransaction(t, p, `{"amount":950,"type":"expense","category":"housing","description":"Rent March","date":"2026-03-01"}`)
+	createTransaction(t, p, `{"amount":950,"type":"expense","category":"housing","description":"Rent April","date":"2026-04-01"}`)
+	createTransaction(t, p, `{"amount":3000,"type":"income","category":"salary","description":"100% bonus","date":"2026-04-02"}`)
+
+	results, err := p.Search("rent")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results for 'rent', got %d", len(results))
+	}
+	if results[0].Title != "Rent April" || results[0].Type != "transaction" {
+		t.Errorf("expected the most recent rent first, got %+v", results[0])
+	}
+	if results[0].Snippet != "2026-04-01 · housing · -950.00" {
+		t.Errorf("unexpected snippet %q", results[0].Snippet)
+	}
+
+	// Categories match too, and LIKE wildcards match literally.
+	if results, _ := p.Search("housing"); len(results) != 2 {
+		t.Errorf("expected 2 results for the housing category, got %d", len(results))
+	}
+	if results, _ := p.Search("0%"); len(results) != 1 {
+		t.Errorf("expected only the literal '0%%' match, got %d", len(results))
+	}
+}
+
+func TestArchive_MovesOldTransactionsOutOfLiveQueries(t *testing.T) {
+	p := newTestPlugin(t)
+	// Stop the background loop so its first run cannot archive the
+	// transaction before POST /archive/run does.
+	p.stopArchive()
+
+	oldDate := time.Now().AddDate(-3, 0, 0).Format("2006-01-02")
+	oldMonth := oldDate[:7]
+	tagID := createTag(t, p, "tax", "#FF0000")
+	createTransaction(t, p, fmt.Sprintf(`{"amount":200,"type":"expense","category":"bills","date":"%s","tag_ids":[%d]}`, oldDate, tagID))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":1000,"type":"income","category":"salary","date":"%s"}`, time.Now().Format("2006-01-02")))
+
+	resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: "/archive/settings", Body: []byte(`{"retention_years":2}`)})
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/archive/run"})
+	var result struct {
+		Archived int64 `json:"archived"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil || result.Archived != 1 {
+		t.Fatalf("expected 1 transaction archived, got %s", string(resp.Body))
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions"})
+	if listed := parseDataArray(t, resp); len(listed) != 1 {
+		t.Errorf("expected only the live transaction to be listed, got %d", len(listed))
+	}
+
+	summaryExpense := func(query map[string]string) float64 {
+		resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: query})
+		var summary reports.MonthlySummary
+		if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+			t.Fatalf("failed to parse summary: %v", err)
+		}
+		return summary.Expense
+	}
+	if expense := summaryExpense(map[string]string{"month": oldMonth}); expense != 0 {
+		t.Errorf("expected the live summary to skip archived transactions, got %.2f", expense)
+	}
+	if expense := summaryExpense(map[string]string{"month": oldMonth, "include_archived": "true"}); expense != 200 {
+		t.Errorf("expected include_archived to report the archived expense, got %.2f", expense)
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/accounts/1/balance"})
+	var account struct {
+		Balance float64 `json:"balance"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &account); err != nil || account.Balance != 800 {
+		t.Errorf("expected the balance to still count archived transactions, got %s", string(resp.Body))
+	}
+
+	var tags string
+	if err := p.db.QueryRow("SELECT tags FROM archived_transactions").Scan(&tags); err != nil || tags != "tax" {
+		t.Errorf("expected the archived transaction to keep its tag names, got %q (%v)", tags, err)
+	}
+}
+
+func TestArchive_CutoffKeepsWholeMonths(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	if cutoff := archive.Cutoff(now, 2); cutoff != "2024-10-01" {
+		t.Errorf("expected 2024-10-01, got %s", cutoff)
+	}
+}
+
+func TestReports_Yearly(t *testing.T) {
+	p := newTestPlugin(t)
+
+	deductible := createTag(t, p, "Deductible", "#00ff00")
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings","currency":"EUR"}`)
+	createTransaction(t, p, `{"amount":3000,"type":"income","category":"salary","date":"2025-01-31"}`)
+	createTransaction(t, p, `{"amount":120,"type":"expense","category":"groceries","date":"2025-01-05"}`)
+	createTransaction(t, p, fmt.Sprintf(`{"amount":200,"type":"expense","category":"health","date":"2025-03-12","tag_ids":[%d]}`, deductible))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":80,"type":"expense","category":"donations","date":"2025-12-24","tag_ids":[%d]}`, deductible))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":500,"type":"transfer","account_id":1,"dest_account_id":%d,"date":"2025-06-01"}`, savingsID))
+	createTransaction(t, p, fmt.Sprintf(`{"amount":999,"type":"expense","category":"health","date":"2025-03-13","status":"void","tag_ids":[%d]}`, deductible))
+	createTransaction(t, p, `{"amount":50,"type":"expense","category":"groceries","date":"2026-01-05"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/yearly", Query: map[string]string{"year": "2025"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("yearly report failed: %v %v", err, resp)
+	}
+	var report reports.YearlyReport
+	if err := json.Unmarshal(parseDataObject(t, resp), &report); err != nil {
+		t.Fatalf("failed to parse yearly report: %v", err)
+	}
+	if report.Income != 3000 || report.Expense != 400 || report.Balance != 2600 || report.Transfers != 500 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if len(report.ByMonth) != 12 || report.ByMonth[0].Month != "2025-01" || report.ByMonth[0].Expense != 120 || report.ByMonth[1].Expense != 0 {
+		t.Errorf("unexpected months: %+v", report.ByMonth)
+	}
+	if len(report.TopCategories) != 3 || report.TopCategories[0].Category != "health" || report.TopCategories[0].Total != 200 {
+		t.Errorf("unexpected top categories: %+v", report.TopCategories)
+	}
+	if report.Deductible.Tag != reports.DefaultTaxTag || report.Deductible.Total != 280 || len(report.Deductible.ByCategory) != 2 {
+		t.Errorf("unexpected deductible summary: %+v", report.Deductible)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/yearly", Query: map[string]string{"year": "2025", "format": "csv"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("yearly csv failed: %v %v", err, resp)
+	}
+	if resp.ContentType != "text/csv" || !strings.Contains(resp.Headers["Content-Disposition"], "report-2025.csv") {
+		t.Errorf("unexpected csv response: %s %v", resp.ContentType, resp.Headers)
+	}
+	csvBody := string(resp.Body)
+	for _, line := range []string{"section,name,income,expense,balance,amount", "month,2025-01,3000.00,120.00,2880.00,", "transfers,,,,,500.00", "deductible,deductible,,,,280.00"} {
+		if !strings.Contains(csvBody, line+"\n") {
+			t.Errorf("expected csv line %q in:\n%s", line, csvBody)
+		}
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/yearly", Query: map[string]string{"year": "25"}})
+	if err != nil {
+		t.Fatalf("yearly report returned error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for an invalid year, got %d", resp.StatusCode)
+	}
+}
+
+func TestReports_Forecast(t *testing.T) {
+	p := newTestPlugin(t)
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= reports.DiscretionaryMonths; i++ {
+		date := monthStart.AddDate(0, -i, 9).Format("2006-01-02")
+		createTransaction(t, p, fmt.Sprintf(`{"amount":300,"type":"expense","category":"groceries","date":"%s"}`, date))
+	}
+	createTransaction(t, p, fmt.Sprintf(`{"amount":50,"type":"expense","category":"bills","date":"%s"}`, monthStart.AddDate(0, 1, 14).Format("2006-01-02")))
+	createRecurringRule(t, p, fmt.Sprintf(`{
+		"amount": 1000,
+		"type": "income",
+		"category": "salary",
+		"frequency": "monthly",
+		"day_of_month": 1,
+		"start_date": "%s"
+	}`, monthStart.AddDate(0, 1, 0).Format("2006-01-02")))
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/forecast", Query: map[string]string{"months": "3"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("forecast failed: %v %v", err, resp)
+	}
+	var forecast reports.Forecast
+	if err := json.Unmarshal(parseDataObject(t, resp), &forecast); err != nil {
+		t.Fatalf("failed to parse forecast: %v", err)
+	}
+	if forecast.StartingBalance != -900 || forecast.Discretionary != 300 {
+		t.Errorf("expected starting balance -900 and discretionary 300, got %f and %f", forecast.StartingBalance, forecast.Discretionary)
+	}
+	if len(forecast.Months) != 3 || len(forecast.Accounts) != 1 || len(forecast.Accounts[0].Months) != 3 {
+		t.Fatalf("expected 3 months for 1 account, got %+v", forecast)
+	}
+
+	// The current month only spends for the days left in it.
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	current := 300 * float64(daysInMonth-now.Day()+1) / float64(daysInMonth)
+	first := forecast.Months[0]
+	if first.Month != now.Format("2006-01") || first.Income != 0 || math.Abs(first.Expense-current) > 0.01 {
+		t.Errorf("unexpected current month: %+v", first)
+	}
+	second := forecast.Months[1]
+	if second.Income != 1000 || second.Expense != 350 || math.Abs(second.Balance-(first.Balance+650)) > 0.01 {
+		t.Errorf("unexpected next month: %+v after %+v", second, first)
+	}
+	if third := forecast.Months[2]; third.Income != 1000 || third.Expense != 300 || math.Abs(third.Balance-(second.Balance+700)) > 0.01 {
+		t.Errorf("unexpected third month: %+v after %+v", third, second)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/forecast", Query: map[string]string{"months": "0"}})
+	if err != nil {
+		t.Fatalf("forecast returned error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for months=0, got %d", resp.StatusCode)
+	}
+}
+
+func TestReports_CachedUntilWrite(t *testing.T) {
+	p := newTestPlugin(t)
+	month := time.Now().Format("2006-01")
+
+	summaryIncome := func() float64 {
+		resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": month}})
+		var summary reports.MonthlySummary
+		if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+			t.Fatalf("failed to parse summary: %v", err)
+		}
+		return summary.Income
+	}
+
+	if income := summaryIncome(); income != 0 {
+		t.Fatalf("expected no income yet, got %.2f", income)
+	}
+
+	// A write outside the API is not seen until the cache is invalidated.
+	if _, err := p.db.Exec("INSERT INTO transactions (amount, type, category, date) VALUES (50, 'income', 'gift', ?)", month+"-01"); err != nil {
+		t.Fatalf("inserting transaction: %v", err)
+	}
+	if income := summaryIncome(); income != 0 {
+		t.Errorf("expected the cached summary, got %.2f", income)
+	}
+
+	createTransaction(t, p, fmt.Sprintf(`{"amount":100,"type":"income","category":"salary","date":"%s-02"}`, month))
+	if income := summaryIncome(); income != 150 {
+		t.Errorf("expected a write through the API to refresh the summary, got %.2f", income)
+	}
+}
+
+func TestWarmup_PrimesReportCache(t *testing.T) {
+	p := newTestPlugin(t)
+	month := time.Now().Format("2006-01")
+
+	if err := p.Warmup(); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+
+	// Written behind the cache's back: the warmed summary does not see it.
+	if _, err := p.db.Exec("INSERT INTO transactions (amount, type, category, date) VALUES (75, 'income', 'gift', ?)", month+"-01"); err != nil {
+		t.Fatalf("inserting transaction: %v", err)
+	}
+
+	resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": month}})
+	var summary reports.MonthlySummary
+	if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+		t.Fatalf("failed to parse summary: %v", err)
+	}
+	if summary.Income != 0 {
+		t.Errorf("expected the summary computed during warm-up, got income %.2f", summary.Income)
+	}
+}
+
+func TestSeedDemo_FillsEmptyDatabaseOnce(t *testing.T) {
+	p := newTestPlugin(t)
+	month := time.Now().Format("2006-01")
+
+	if err := p.SeedDemo(); err != nil {
+		t.Fatalf("SeedDemo failed: %v", err)
+	}
+
+	var transactionCount int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&transactionCount); err != nil {
+		t.Fatalf("counting transactions: %v", err)
+	}
+	if transactionCount == 0 {
+		t.Fatal("expected demo transactions")
+	}
+
+	resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/reports/summary", Query: map[string]string{"month": month}})
+	var summary reports.MonthlySummary
+	if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+		t.Fatalf("failed to parse summary: %v", err)
+	}
+	if summary.Income != 2450 {
+		t.Errorf("expected this month's demo salary in the summary, got income %.2f", summary.Income)
+	}
+
+	// A database with transactions is left alone.
+	if err := p.SeedDemo(); err != nil {
+		t.Fatalf("second SeedDemo failed: %v", err)
+	}
+	var again int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&again); err != nil {
+		t.Fatalf("counting transactions: %v", err)
+	}
+	if again != transactionCount {
+		t.Errorf("expected seeding a non-empty database to do nothing, got %d transactions after %d", again, transactionCount)
+	}
+}
+
+func TestImport_RoutesThroughHandleAPI(t *testing.T) {
+	p := newTestPlugin(t)
+
+	body := `{"preset": "revolut", "csv": "Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance\nCARD_PAYMENT,Current,2026-03-01 10:00:00,2026-03-02 09:30:00,Coffee,-2.80,0.00,EUR,COMPLETED,100.00\n"}`
+	resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/import", Body: []byte(body)})
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/transactions", Query: map[string]string{"month": "2026-03"}})
+	if items := parseDataArray(t, resp); len(items) != 1 {
+		t.Errorf("expected the imported transaction to be listed, got %d", len(items))
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/import/presets"})
+	if resp.StatusCode != 200 || len(parseDataArray(t, resp)) == 0 {
+		t.Errorf("expected the built-in presets to be listed, got %d: %s", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestLedger_TransferShownOnceWithBothLegs(t *testing.T) {
+	p := newTestPlugin(t)
+
+	savingsID := createAccount(t, p, `{"name":"Savings","type":"savings","currency":"EUR"}`)
+	createTransaction(t, p, `{"amount":1000,"type":"income","category":"salary","date":"2026-02-25"}`)
+	createTransaction(t, p, `{"amount":40.10,"type":"expense","category":"food","date":"2026-03-02"}`)
+	createTransaction(t, p, fmt.Sprintf(`{"amount":300,"type":"transfer","account_id":1,"dest_account_id":%d,"date":"2026-03-10"}`, savingsID))
+	createTransaction(t, p, `{"amount":20.20,"type":"income","category":"other","date":"2026-03-15"}`)
+	createTransaction(t, p, `{"amount":99,"type":"expense","category":"food","date":"2026-04-01"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/ledger", Query: map[string]string{"month": "2026-03"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("ledger failed: %v %v", err, resp)
+	}
+	var result struct {
+		OpeningBalance float64 `json:"opening_balance"`
+		ClosingBalance float64 `json:"closing_balance"`
+		Accounts       []struct {
+			ID             int64   `json:"id"`
+			OpeningBalance float64 `json:"opening_balance"`
+			ClosingBalance float64 `json:"closing_balance"`
+			Credits        float64 `json:"credits"`
+			Debits         float64 `json:"debits"`
+		} `json:"accounts"`
+		Entries []struct {
+			Type    string  `json:"type"`
+			Debit   float64 `json:"debit"`
+			Credit  float64 `json:"credit"`
+			Balance float64 `json:"balance"`
+			Legs    []struct {
+				AccountID int64   `json:"account_id"`
+				Amount    float64 `json:"amount"`
+				Balance   float64 `json:"balance"`
+			} `json:"legs"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+		t.Fatalf("failed to parse ledger: %v", err)
+	}
+
+	if result.OpeningBalance != 1000 || result.ClosingBalance != 980.1 {
+		t.Errorf("expected balances 1000 -> 980.1, got %v -> %v", result.OpeningBalance, result.ClosingBalance)
+	}
+	if len(result.Entries) != 3 {
+		t.Fatalf("expected the month's 3 entries, got %d", len(result.Entries))
+	}
+	wantBalances := []float64{959.9, 959.9, 980.1}
+	for i, entry := range result.Entries {
+		if entry.Balance != wantBalances[i] {
+			t.Errorf("entry %d: expected running balance %v, got %v", i, wantBalances[i], entry.Balance)
+		}
+	}
+
+	transfer := result.Entries[1]
+	if transfer.Type != "transfer" || transfer.Debit != 0 || transfer.Credit != 0 || len(transfer.Legs) != 2 {
+		t.Fatalf("expected one transfer entry with two legs, got %+v", transfer)
+	}
+	if transfer.Legs[0].AccountID != 1 || transfer.Legs[0].Amount != -300 || transfer.Legs[0].Balance != 659.9 {
+		t.Errorf("unexpected source leg: %+v", transfer.Legs[0])
+	}
+	if transfer.Legs[1].AccountID != savingsID || transfer.Legs[1].Amount != 300 || transfer.Legs[1].Balance != 300 {
+		t.Errorf("unexpected destination leg: %+v", transfer.Legs[1])
+	}
+
+	if len(result.Accounts) != 2 {
+		t.Fatalf("expected 2 accounts, got %d", len(result.Accounts))
+	}
+	main := result.Accounts[0]
+	if main.OpeningBalance != 1000 || main.ClosingBalance != 680.1 || main.Debits != 340.1 || main.Credits != 20.2 {
+		t.Errorf("unexpected main account side: %+v", main)
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/ledger", Query: map[string]string{"month": "March"}})
+	if resp.StatusCode != 400 {
+		t.Errorf("expected an invalid month to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestBudgets_CopyFromPreviousMonthAndTemplates(t *testing.T) {
+	p := newTestPlugin(t)
+
+	createBudget(t, p, `{"name":"Food","category":"food","amount":300,"month":"2026-03"}`)
+	createBudget(t, p, `{"category":"transport","amount":80,"month":"2026-03"}`)
+	createBudget(t, p, `{"amount":2000,"month":"2026-03"}`)
+	createBudget(t, p, `{"category":"entertainment","amount":50}`)
+	createBudget(t, p, `{"category":"transport","amount":100,"month":"2026-04"}`)
+
+	var result budgets.ApplyResult
+	resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/budgets/copy", Query: map[string]string{"from": "2026-03", "to": "2026-04"}})
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, resp.Body)
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+		t.Fatalf("failed to parse copy result: %v", err)
+	}
+	if len(result.Created) != 2 || len(result.Skipped) != 1 || result.Skipped[0].Category != "transport" {
+		t.Fatalf("expected food and global copied and transport skipped, got %+v", result)
+	}
+	if result.Created[0].Month != "2026-04" || result.Created[0].Amount != 300 || result.Created[1].Category != "" {
+		t.Errorf("unexpected copied budgets: %+v", result.Created)
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/budgets/copy", Query: map[string]string{"from": "2025-01", "to": "2026-04"}})
+	if resp.StatusCode != 404 {
+		t.Errorf("expected copying from a month without budgets to be not found, got %d", resp.StatusCode)
+	}
+
+	// A template saved from March can set up any later month.
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/budgets/templates", Body: []byte(`{"name":"Usual month","month":"2026-03"}`)})
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, resp.Body)
+	}
+	var template budgets.Template
+	if err := json.Unmarshal(parseDataObject(t, resp), &template); err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	if len(template.Items) != 3 {
+		t.Fatalf("expected the template to hold March's 3 budgets, got %+v", template.Items)
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/budgets/templates", Body: []byte(`{"name":"usual month","items":[{"amount":10}]}`)})
+	if resp.StatusCode != 409 {
+		t.Errorf("expected a duplicate template name to conflict, got %d", resp.StatusCode)
+	}
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/budgets/templates", Body: []byte(`{"name":"Twice","items":[{"category":"food","amount":10},{"category":"food","amount":20}]}`)})
+	if resp.StatusCode != 400 {
+		t.Errorf("expected a repeated category to be rejected, got %d", resp.StatusCode)
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: fmt.Sprintf("/budgets/templates/%d/apply", template.ID), Query: map[string]string{"month": "2026-06"}})
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, resp.Body)
+	}
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/budgets", Query: map[string]string{"month": "2026-06"}})
+	if items := parseDataArray(t, resp); len(items) != 4 {
+		t.Errorf("expected 3 applied budgets and the recurring one in June, got %d", len(items))
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/budgets/templates/%d", template.ID)})
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/budgets/templates"})
+	if items := parseDataArray(t, resp); len(items) != 0 {
+		t.Errorf("expected no templates after delete, got %d", len(items))
+	}
+}
+
+func TestWidgetData_GoalsProjectCompletionDate(t *testing.T) {
+	p := newTestPlugin(t)
+
+	createGoal := func(body string) int64 {
+		t.Helper()
+		resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/goals", Body: []byte(body)})
+		if resp.StatusCode != 201 {
+			t.Fatalf("expected 201, got %d: %s", resp.StatusCode, resp.Body)
+		}
+		var goal struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(parseDataObject(t, resp), &goal); err != nil {
+			t.Fatalf("failed to parse goal: %v", err)
+		}
+		return goal.ID
+	}
+	contribute := func(id int64, body string, wantStatus int) {
+		t.Helper()
+		resp, _ := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: fmt.Sprintf("/goals/%d/contribute", id), Body: []byte(body)})
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("expected %d, got %d: %s", wantStatus, resp.StatusCode, resp.Body)
+		}
+	}
+
+	today := time.Now()
+	daysAgo := func(days int) string { return today.AddDate(0, 0, -days).Format("2006-01-02") }
+
+	carID := createGoal(`{"name":"Car","target_amount":1000,"target_date":"2099-01-01"}`)
+	contribute(carID, fmt.Sprintf(`{"amount":300,"date":%q}`, daysAgo(40)), 200)
+	contribute(carID, fmt.Sprintf(`{"amount":300,"date":%q}`, daysAgo(10)), 200)
+	// Saved too long ago to count towards the average.
+	contribute(carID, fmt.Sprintf(`{"amount":100,"date":%q}`, daysAgo(200)), 200)
+	contribute(carID, `{"amount":5,"date":"soon"}`, 400)
+
+	doneID := createGoal(`{"name":"Phone","target_amount":100}`)
+	contribute(doneID, `{"amount":120}`, 200)
+
+	createGoal(`{"name":"House","target_amount":50000,"target_date":"2030-01-01"}`)
+
+	raw, err := p.GetWidgetData("goals-widget")
+	if err != nil {
+		t.Fatalf("GetWidgetData failed: %v", err)
+	}
+	var widget struct {
+		Data []struct {
+			Name           string  `json:"name"`
+			IsCompleted    bool    `json:"is_completed"`
+			Percentage     float64 `json:"percentage"`
+			Remaining      float64 `json:"remaining"`
+			MonthlyAverage float64 `json:"monthly_average"`
+			ProjectedDate  *string `json:"projected_date"`
+			OnTrack        *bool   `json:"on_track"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &widget); err != nil {
+		t.Fatalf("failed to parse widget: %v", err)
+	}
+	if len(widget.Data) != 3 {
+		t.Fatalf("expected 3 goals, got %d", len(widget.Data))
+	}
+	goals := make(map[string]int)
+	for i, goal := range widget.Data {
+		goals[goal.Name] = i
+	}
+
+	car := widget.Data[goals["Car"]]
+	if car.Percentage != 70 || car.Remaining != 300 || car.MonthlyAverage != 200 {
+		t.Errorf("unexpected car progress: %+v", car)
+	}
+	// 300 left at 600 per three months is about a month and a half away.
+	earliest, latest := today.AddDate(0, 0, 40).Format("2006-01-02"), today.AddDate(0, 0, 50).Format("2006-01-02")
+	if car.ProjectedDate == nil || *car.ProjectedDate < earliest || *car.ProjectedDate > latest {
+		t.Errorf("expected the car projected between %s and %s, got %v", earliest, latest, car.ProjectedDate)
+	}
+	if car.OnTrack == nil || !*car.OnTrack {
+		t.Errorf("expected the car to be on track, got %v", car.OnTrack)
+	}
+
+	house := widget.Data[goals["House"]]
+	if house.ProjectedDate != nil || house.OnTrack == nil || *house.OnTrack {
+		t.Errorf("expected the house to have no projection and be off track, got %+v", house)
+	}
+
+	phone := widget.Data[goals["Phone"]]
+	if goals["Phone"] != 2 || !phone.IsCompleted || phone.Percentage != 100 || phone.ProjectedDate != nil || phone.OnTrack != nil {
+		t.Errorf("expected the completed phone last without a projection, got %+v at %d", phone, goals["Phone"])
+	}
+}
+
+// --- Settings tests ---
+
+func TestSettings_DefaultCurrency(t *testing.T) {
+	p := newTestPlugin(t)
+
+	if err := p.OnSettingsChanged([]byte(`{"default_currency":"USD"}`)); err != nil {
+		t.Fatalf("OnSettingsChanged failed: %v", err)
+	}
+	createAccount(t, p, `{"name":"Checking USD","type":"checking"}`)
+	createAccount(t, p, `{"name":"Savings GBP","type":"savings","currency":"GBP"}`)
+
+	if err := p.OnSettingsChanged([]byte(`{"default_currency":"dollars"}`)); err == nil {
+		t.Error("expected an invalid currency to be rejected")
+	}
+	if err := p.OnSettingsChanged([]byte(`{}`)); err != nil {
+		t.Fatalf("OnSettingsChanged failed: %v", err)
+	}
+	createAccount(t, p, `{"name":"Checking EUR","type":"checking"}`)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/accounts"})
+	if err != nil {
+		t.Fatalf("list returned error: %v", err)
+	}
+	currencies := make(map[string]string)
+	for _, item := range parseDataArray(t, resp) {
+		var account struct {
+			Name     string `json:"name"`
+			Currency string `json:"currency"`
+		}
+		if err := json.Unmarshal(item, &account); err != nil {
+			t.Fatalf("failed to parse account: %v", err)
+		}
+		currencies[account.Name] = account.Currency
+	}
+	if currencies["Checking USD"] != "USD" || currencies["Savings GBP"] != "GBP" || currencies["Checking EUR"] != "EUR" {
+		t.Errorf("expected accounts in the default currency in force when created, got %v", currencies)
+	}
+}
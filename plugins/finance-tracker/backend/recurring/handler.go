@@ -31,6 +31,12 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return h.create(req)
 	case req.Method == "POST" && req.Path == "/recurring/generate":
 		return h.generate(req)
+	case req.Method == "POST" && strings.HasPrefix(req.Path, "/recurring/") && strings.HasSuffix(req.Path, "/pause"):
+		return h.pause(req)
+	case req.Method == "POST" && strings.HasPrefix(req.Path, "/recurring/") && strings.HasSuffix(req.Path, "/resume"):
+		return h.resume(req)
+	case req.Method == "POST" && strings.HasPrefix(req.Path, "/recurring/") && strings.HasSuffix(req.Path, "/skip"):
+		return h.skip(req)
 	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/recurring/"):
 		return h.update(req)
 	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/recurring/"):
@@ -40,6 +46,26 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	}
 }
 
+// WidgetData returns the upcoming recurring widget payload: the occurrences
+// due in the UpcomingDays from now, soonest first.
+func (h *Handler) WidgetData(now time.Time) ([]byte, error) {
+	occurrences, appErr := h.service.Upcoming(now, UpcomingDays)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return json.Marshal(map[string]interface{}{"data": occurrences})
+}
+
+// NextPaymentsWidgetData returns the next payments widget payload: the next
+// NextPaymentsLimit occurrences across all active rules, soonest first.
+func (h *Handler) NextPaymentsWidgetData(now time.Time) ([]byte, error) {
+	payments, appErr := h.service.Next(now, NextPaymentsLimit)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return json.Marshal(map[string]interface{}{"data": payments})
+}
+
 func (h *Handler) list(_ *sdk.APIRequest) (*sdk.APIResponse, error) {
 	rules, err := h.service.List()
 	if err != nil {
@@ -94,6 +120,53 @@ func (h *Handler) delete(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	return shared.JSONSuccess(200, map[string]interface{}{"deactivated": id})
 }
 
+func (h *Handler) pause(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	rule, appErr := h.service.Pause(id)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(200, rule)
+}
+
+func (h *Handler) resume(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	rule, appErr := h.service.Resume(id)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(200, rule)
+}
+
+func (h *Handler) skip(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, appErr := shared.ExtractIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	var input SkipInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	skip, appErr := h.service.Skip(id, &input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	return shared.JSONSuccess(201, skip)
+}
+
 func (h *Handler) generate(_ *sdk.APIRequest) (*sdk.APIResponse, error) {
 	result, appErr := h.service.Generate(time.Now())
 	if appErr != nil {
@@ -52,9 +52,42 @@ type UpdateRuleInput struct {
 	EndDate       string  `json:"end_date"`
 }
 
-// GenerateResult holds the result of a generation run.
+// SkipInput holds the date of an occurrence to skip.
+type SkipInput struct {
+	Date string `json:"date"`
+}
+
+// Skip records an occurrence of a rule that generation passes over.
+type Skip struct {
+	RuleID    int64  `json:"rule_id"`
+	Date      string `json:"date"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Occurrence is a future transaction of a recurring rule.
+type Occurrence struct {
+	RuleID        int64   `json:"rule_id"`
+	Date          string  `json:"date"`
+	Amount        float64 `json:"amount"`
+	Type          string  `json:"type"`
+	AccountID     int64   `json:"account_id"`
+	DestAccountID *int64  `json:"dest_account_id,omitempty"`
+	Category      string  `json:"category"`
+	Description   string  `json:"description"`
+}
+
+// Payment is an occurrence with the name of the account it is paid from, as
+// listed by the next payments widget.
+type Payment struct {
+	Occurrence
+	AccountName string `json:"account_name"`
+}
+
+// GenerateResult holds the result of a generation run: how many instances it
+// created and how many pending ones it cleared because their date had passed.
 type GenerateResult struct {
 	Generated int `json:"generated"`
+	Cleared   int `json:"cleared"`
 }
 
 // validFrequencies defines the allowed frequency values.
@@ -199,6 +199,66 @@ func (r *Repository) Deactivate(id int64) error {
 	return nil
 }
 
+// SetActive pauses or resumes a rule. last_generated is left untouched.
+func (r *Repository) SetActive(id int64, active bool) error {
+	isActive := 0
+	if active {
+		isActive = 1
+	}
+
+	result, err := r.db.Exec("UPDATE recurring_rules SET is_active = ? WHERE id = ?", isActive, id)
+	if err != nil {
+		return fmt.Errorf("updating recurring rule state: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return shared.NewNotFoundError("recurring rule", fmt.Sprintf("%d", id))
+	}
+	return nil
+}
+
+// InsertSkip records an occurrence to skip. Skipping the same date twice
+// keeps the first record.
+func (r *Repository) InsertSkip(ruleID int64, date string) (*Skip, error) {
+	if _, err := r.db.Exec(
+		"INSERT OR IGNORE INTO recurring_skips (rule_id, date) VALUES (?, ?)", ruleID, date,
+	); err != nil {
+		return nil, fmt.Errorf("inserting recurring skip: %w", err)
+	}
+
+	skip := Skip{RuleID: ruleID, Date: date}
+	err := r.db.QueryRow(
+		"SELECT created_at FROM recurring_skips WHERE rule_id = ? AND date = ?", ruleID, date,
+	).Scan(&skip.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("querying recurring skip: %w", err)
+	}
+	return &skip, nil
+}
+
+// SkippedDates returns the skipped occurrence dates of a rule.
+func (r *Repository) SkippedDates(ruleID int64) (map[string]bool, error) {
+	rows, err := r.db.Query("SELECT date FROM recurring_skips WHERE rule_id = ?", ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("querying recurring skips: %w", err)
+	}
+	defer rows.Close()
+
+	dates := make(map[string]bool)
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, fmt.Errorf("scanning recurring skip: %w", err)
+		}
+		dates[date] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating recurring skips: %w", err)
+	}
+	return dates, nil
+}
+
 // ListActiveRules returns all active rules where last_generated is NULL or before the given date.
 func (r *Repository) ListActiveRules(today string) ([]Rule, error) {
 	rows, err := r.db.Query(`
@@ -219,7 +279,7 @@ func (r *Repository) ListActiveRules(today string) ([]Rule, error) {
 
 // InsertGeneratedTransaction inserts a transaction marked as a recurring instance.
 func (r *Repository) InsertGeneratedTransaction(ruleID int64, amount float64, txType string,
-	accountID int64, destAccountID *int64, category string, description string, date string) error {
+	accountID int64, destAccountID *int64, category string, description string, date string, status string) error {
 
 	var destAcct interface{}
 	if destAccountID != nil {
@@ -229,15 +289,32 @@ func (r *Repository) InsertGeneratedTransaction(ruleID int64, amount float64, tx
 	_, err := r.db.Exec(`
 		INSERT INTO transactions
 			(amount, type, account_id, dest_account_id, category, description, date,
-			 is_recurring_instance, recurring_rule_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?)
-	`, amount, txType, accountID, destAcct, category, description, date, ruleID)
+			 is_recurring_instance, recurring_rule_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+	`, amount, txType, accountID, destAcct, category, description, date, ruleID, status)
 	if err != nil {
 		return fmt.Errorf("inserting generated transaction: %w", err)
 	}
 	return nil
 }
 
+// ClearPastInstances marks the pending recurring instances dated before today
+// as cleared and returns how many there were.
+func (r *Repository) ClearPastInstances(today string) (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE transactions SET status = 'cleared'
+		WHERE is_recurring_instance = 1 AND status = 'pending' AND date < ?
+	`, today)
+	if err != nil {
+		return 0, fmt.Errorf("clearing past recurring instances: %w", err)
+	}
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting cleared recurring instances: %w", err)
+	}
+	return cleared, nil
+}
+
 // UpdateLastGenerated sets the last_generated date for a rule.
 func (r *Repository) UpdateLastGenerated(id int64, date string) error {
 	_, err := r.db.Exec("UPDATE recurring_rules SET last_generated = ? WHERE id = ?", date, id)
@@ -256,11 +333,12 @@ func (r *Repository) DeactivateRule(id int64) error {
 	return nil
 }
 
-// TransactionExistsForDate checks if a generated transaction already exists for the rule on the given date.
+// TransactionExistsForDate checks if a generated transaction already exists for the rule on the given date,
+// including one moved to the archive.
 func (r *Repository) TransactionExistsForDate(ruleID int64, date string) (bool, error) {
 	var count int
 	err := r.db.QueryRow(`
-		SELECT COUNT(*) FROM transactions
+		SELECT COUNT(*) FROM all_transactions
 		WHERE recurring_rule_id = ? AND date = ? AND is_recurring_instance = 1
 	`, ruleID, date).Scan(&count)
 	if err != nil {
@@ -279,6 +357,29 @@ func (r *Repository) AccountExists(id int64) (bool, error) {
 	return count > 0, nil
 }
 
+// AccountNames returns the names of all accounts by ID.
+func (r *Repository) AccountNames() (map[int64]string, error) {
+	rows, err := r.db.Query("SELECT id, name FROM accounts")
+	if err != nil {
+		return nil, fmt.Errorf("listing account names: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("scanning account name: %w", err)
+		}
+		names[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating account names: %w", err)
+	}
+	return names, nil
+}
+
 // scanRules reads all rows from the result set into a slice of Rule.
 func scanRules(rows *sql.Rows) ([]Rule, error) {
 	rules := make([]Rule, 0)
@@ -2,6 +2,7 @@ package recurring
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -110,11 +111,76 @@ func (s *Service) Deactivate(id int64) *shared.AppError {
 	return nil
 }
 
+// Pause stops generating transactions for a rule until it is resumed.
+func (s *Service) Pause(id int64) (*Rule, *shared.AppError) {
+	return s.setActive(id, false)
+}
+
+// Resume reactivates a paused rule. last_generated is preserved, so the next
+// Generate continues from the last generated occurrence, including those that
+// fell due while the rule was paused unless they were skipped.
+func (s *Service) Resume(id int64) (*Rule, *shared.AppError) {
+	return s.setActive(id, true)
+}
+
+func (s *Service) setActive(id int64, active bool) (*Rule, *shared.AppError) {
+	if err := s.repo.SetActive(id, active); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return nil, appErr
+		}
+		return nil, shared.NewAppError("INTERNAL", "failed to update recurring rule", 500)
+	}
+	return s.repo.GetByID(id)
+}
+
+// Skip records an upcoming occurrence of a rule that Generate must not turn
+// into a transaction. The date must be one the rule falls on and must not
+// have been generated yet.
+func (s *Service) Skip(id int64, input *SkipInput) (*Skip, *shared.AppError) {
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return nil, shared.NewValidationError("date must be in YYYY-MM-DD format")
+	}
+
+	rule, appErr := s.repo.GetByID(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if rule.LastGenerated != "" && input.Date <= rule.LastGenerated {
+		return nil, shared.NewConflictError(fmt.Sprintf("occurrence on %s was already generated", input.Date))
+	}
+
+	// The date must be one the next Generate would produce.
+	startFrom, appErr := pendingFrom(rule)
+	if appErr != nil {
+		return nil, appErr
+	}
+	dates := calculateDates(rule, startFrom, date)
+	if (rule.EndDate != "" && input.Date > rule.EndDate) ||
+		len(dates) == 0 || !dates[len(dates)-1].Equal(date) {
+		return nil, shared.NewValidationError(fmt.Sprintf("rule %d has no occurrence on %s", id, input.Date))
+	}
+
+	skip, err := s.repo.InsertSkip(id, input.Date)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to skip occurrence", 500)
+	}
+	return skip, nil
+}
+
 // Generate creates pending transaction instances for all active rules up to the given date.
 // It is idempotent: calling it twice will not create duplicate transactions.
+// Instances stay pending until their date passes; each run first clears those
+// generated by earlier runs whose date is before today.
 func (s *Service) Generate(today time.Time) (*GenerateResult, *shared.AppError) {
 	todayStr := today.Format("2006-01-02")
 
+	cleared, err := s.repo.ClearPastInstances(todayStr)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("clearing past instances: %v", err), 500)
+	}
+
 	rules, err := s.repo.ListActiveRules(todayStr)
 	if err != nil {
 		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("listing active rules: %v", err), 500)
@@ -131,28 +197,112 @@ func (s *Service) Generate(today time.Time) (*GenerateResult, *shared.AppError)
 		totalGenerated += count
 	}
 
-	return &GenerateResult{Generated: totalGenerated}, nil
+	return &GenerateResult{Generated: totalGenerated, Cleared: int(cleared)}, nil
 }
 
-// generateForRule calculates all pending dates for a single rule and inserts transactions.
-func (s *Service) generateForRule(rule *Rule, today time.Time) (int, *shared.AppError) {
-	// Determine the starting point.
-	var startFrom time.Time
-	if rule.LastGenerated != "" {
-		parsed, err := time.Parse("2006-01-02", rule.LastGenerated)
-		if err != nil {
-			return 0, shared.NewAppError("INTERNAL",
-				fmt.Sprintf("parsing last_generated for rule %d: %v", rule.ID, err), 500)
+// UpcomingDays is how far ahead Upcoming looks for the widget.
+const UpcomingDays = 30
+
+// Upcoming returns the occurrences of active rules due from today through
+// the given number of days ahead, soonest first. Skipped occurrences and
+// those already generated are left out.
+func (s *Service) Upcoming(today time.Time, days int) ([]Occurrence, *shared.AppError) {
+	start, _ := time.Parse("2006-01-02", today.Format("2006-01-02"))
+	end := start.AddDate(0, 0, days)
+
+	rules, err := s.repo.List()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("listing rules: %v", err), 500)
+	}
+
+	occurrences := make([]Occurrence, 0)
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.IsActive {
+			continue
 		}
-		// Start from the day after last generated.
-		startFrom = parsed.AddDate(0, 0, 1)
-	} else {
-		parsed, err := time.Parse("2006-01-02", rule.StartDate)
+
+		// Dates are calculated from the last generated one, not from today,
+		// so biweekly rules keep their phase.
+		from, appErr := pendingFrom(rule)
+		if appErr != nil {
+			return nil, appErr
+		}
+		boundary := end
+		if rule.EndDate != "" {
+			endDate, err := time.Parse("2006-01-02", rule.EndDate)
+			if err != nil {
+				return nil, shared.NewAppError("INTERNAL",
+					fmt.Sprintf("parsing end_date for rule %d: %v", rule.ID, err), 500)
+			}
+			if endDate.Before(boundary) {
+				boundary = endDate
+			}
+		}
+
+		skipped, err := s.repo.SkippedDates(rule.ID)
 		if err != nil {
-			return 0, shared.NewAppError("INTERNAL",
-				fmt.Sprintf("parsing start_date for rule %d: %v", rule.ID, err), 500)
+			return nil, shared.NewAppError("INTERNAL",
+				fmt.Sprintf("listing skipped occurrences: %v", err), 500)
 		}
-		startFrom = parsed
+		for _, date := range calculateDates(rule, from, boundary) {
+			dateStr := date.Format("2006-01-02")
+			if date.Before(start) || skipped[dateStr] {
+				continue
+			}
+			occurrences = append(occurrences, Occurrence{
+				RuleID:        rule.ID,
+				Date:          dateStr,
+				Amount:        rule.Amount,
+				Type:          rule.Type,
+				AccountID:     rule.AccountID,
+				DestAccountID: rule.DestAccountID,
+				Category:      rule.Category,
+				Description:   rule.Description,
+			})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Date < occurrences[j].Date
+	})
+	return occurrences, nil
+}
+
+// NextPaymentsLimit is how many occurrences Next returns for the widget, and
+// nextPaymentsDays how far ahead it looks for them, so yearly rules are seen.
+const (
+	NextPaymentsLimit = 10
+	nextPaymentsDays  = 366
+)
+
+// Next returns the first limit occurrences of active rules from today,
+// soonest first, with the names of their accounts.
+func (s *Service) Next(today time.Time, limit int) ([]Payment, *shared.AppError) {
+	occurrences, appErr := s.Upcoming(today, nextPaymentsDays)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(occurrences) > limit {
+		occurrences = occurrences[:limit]
+	}
+
+	names, err := s.repo.AccountNames()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", err.Error(), 500)
+	}
+	payments := make([]Payment, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		payments = append(payments, Payment{Occurrence: occurrence, AccountName: names[occurrence.AccountID]})
+	}
+	return payments, nil
+}
+
+// generateForRule calculates all pending dates for a single rule and inserts transactions.
+func (s *Service) generateForRule(rule *Rule, today time.Time) (int, *shared.AppError) {
+	startFrom, appErr := pendingFrom(rule)
+	if appErr != nil {
+		return 0, appErr
 	}
 
 	// Determine end boundary.
@@ -171,12 +321,24 @@ func (s *Service) generateForRule(rule *Rule, today time.Time) (int, *shared.App
 	// Calculate all pending dates.
 	dates := calculateDates(rule, startFrom, endBoundary)
 
+	skipped, err := s.repo.SkippedDates(rule.ID)
+	if err != nil {
+		return 0, shared.NewAppError("INTERNAL",
+			fmt.Sprintf("listing skipped occurrences: %v", err), 500)
+	}
+
 	generated := 0
 	var lastDate string
 
 	for _, date := range dates {
 		dateStr := date.Format("2006-01-02")
 
+		// Skipped occurrences count as processed without a transaction.
+		if skipped[dateStr] {
+			lastDate = dateStr
+			continue
+		}
+
 		// Idempotency check: skip if already generated.
 		exists, err := s.repo.TransactionExistsForDate(rule.ID, dateStr)
 		if err != nil {
@@ -188,9 +350,15 @@ func (s *Service) generateForRule(rule *Rule, today time.Time) (int, *shared.App
 			continue
 		}
 
+		// An instance due today is pending until the day passes.
+		status := "cleared"
+		if dateStr >= today.Format("2006-01-02") {
+			status = "pending"
+		}
+
 		err = s.repo.InsertGeneratedTransaction(
 			rule.ID, rule.Amount, rule.Type, rule.AccountID,
-			rule.DestAccountID, rule.Category, rule.Description, dateStr,
+			rule.DestAccountID, rule.Category, rule.Description, dateStr, status,
 		)
 		if err != nil {
 			return 0, shared.NewAppError("INTERNAL",
@@ -222,6 +390,27 @@ func (s *Service) generateForRule(rule *Rule, today time.Time) (int, *shared.App
 	return generated, nil
 }
 
+// pendingFrom returns the first date a rule's next occurrence can fall on:
+// the day after last_generated, or start_date if nothing was generated yet.
+func pendingFrom(rule *Rule) (time.Time, *shared.AppError) {
+	if rule.LastGenerated != "" {
+		parsed, err := time.Parse("2006-01-02", rule.LastGenerated)
+		if err != nil {
+			return time.Time{}, shared.NewAppError("INTERNAL",
+				fmt.Sprintf("parsing last_generated for rule %d: %v", rule.ID, err), 500)
+		}
+		// Start from the day after last generated.
+		return parsed.AddDate(0, 0, 1), nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", rule.StartDate)
+	if err != nil {
+		return time.Time{}, shared.NewAppError("INTERNAL",
+			fmt.Sprintf("parsing start_date for rule %d: %v", rule.ID, err), 500)
+	}
+	return parsed, nil
+}
+
 // calculateDates computes all occurrence dates for a rule between startFrom and endBoundary (inclusive).
 func calculateDates(rule *Rule, startFrom time.Time, endBoundary time.Time) []time.Time {
 	var dates []time.Time
@@ -0,0 +1,189 @@
+package reports
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// movement is a projected change to an account's balance in one month.
+type movement struct {
+	accountID int64
+	month     string
+	// kind is "income", "expense" or "transfer"; amount is signed from the
+	// account's point of view.
+	kind   string
+	amount float64
+}
+
+func (s *Service) forecast(months int, today time.Time) (*Forecast, *shared.AppError) {
+	todayStr := today.Format("2006-01-02")
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	horizon := monthStart.AddDate(0, months, 0)
+
+	forecast := &Forecast{From: todayStr, Months: []ForecastMonth{}, Accounts: []AccountForecast{}}
+	accounts, err := s.forecastAccounts(todayStr, monthStart)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", err.Error(), 500)
+	}
+	index := make(map[int64]int, len(accounts))
+	for i := range accounts {
+		index[accounts[i].AccountID] = i
+	}
+
+	movements, err := s.scheduledMovements(todayStr, horizon.Format("2006-01-02"))
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", err.Error(), 500)
+	}
+
+	// Occurrences from today through the last day of the horizon.
+	start := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	occurrences, appErr := s.recurring.Upcoming(today, int(horizon.Sub(start).Hours()/24)-1)
+	if appErr != nil {
+		return nil, appErr
+	}
+	for _, occurrence := range occurrences {
+		month := occurrence.Date[:7]
+		switch occurrence.Type {
+		case "income":
+			movements = append(movements, movement{occurrence.AccountID, month, "income", occurrence.Amount})
+		case "expense":
+			movements = append(movements, movement{occurrence.AccountID, month, "expense", -occurrence.Amount})
+		case "transfer":
+			movements = append(movements, movement{occurrence.AccountID, month, "transfer", -occurrence.Amount})
+			if occurrence.DestAccountID != nil {
+				movements = append(movements, movement{*occurrence.DestAccountID, month, "transfer", occurrence.Amount})
+			}
+		}
+	}
+
+	// Projected months of every account, then their sum.
+	monthIndex := make(map[string]int, months)
+	for i := 0; i < months; i++ {
+		month := monthStart.AddDate(0, i, 0).Format("2006-01")
+		monthIndex[month] = i
+		forecast.Months = append(forecast.Months, ForecastMonth{Month: month})
+	}
+	transfers := make([][]float64, len(accounts))
+	for i := range accounts {
+		accounts[i].Months = make([]ForecastMonth, months)
+		for month, j := range monthIndex {
+			accounts[i].Months[j].Month = month
+		}
+		transfers[i] = make([]float64, months)
+	}
+	for _, m := range movements {
+		i, tracked := index[m.accountID]
+		j, inHorizon := monthIndex[m.month]
+		if !tracked || !inHorizon {
+			continue
+		}
+		point := &accounts[i].Months[j]
+		switch m.kind {
+		case "income":
+			point.Income += m.amount
+		case "expense":
+			point.Expense -= m.amount
+		default:
+			transfers[i][j] += m.amount
+		}
+	}
+
+	// The rest of the current month only has the days left to spend in it.
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	remaining := float64(daysInMonth-today.Day()+1) / float64(daysInMonth)
+	for i := range accounts {
+		account := &accounts[i]
+		balance := account.StartingBalance
+		for j := range account.Months {
+			point := &account.Months[j]
+			discretionary := account.Discretionary
+			if j == 0 {
+				discretionary *= remaining
+			}
+			point.Expense += discretionary
+			balance += point.Income - point.Expense + transfers[i][j]
+			point.Income = shared.RoundCents(point.Income)
+			point.Expense = shared.RoundCents(point.Expense)
+			point.Balance = shared.RoundCents(balance)
+
+			total := &forecast.Months[j]
+			total.Income = shared.RoundCents(total.Income + point.Income)
+			total.Expense = shared.RoundCents(total.Expense + point.Expense)
+			total.Balance = shared.RoundCents(total.Balance + point.Balance)
+		}
+		forecast.StartingBalance += account.StartingBalance
+		forecast.Discretionary += account.Discretionary
+	}
+	forecast.StartingBalance = shared.RoundCents(forecast.StartingBalance)
+	forecast.Discretionary = shared.RoundCents(forecast.Discretionary)
+	forecast.Accounts = accounts
+	return forecast, nil
+}
+
+// forecastAccounts returns the active accounts with their balance at the end
+// of today and their average monthly spend outside recurring rules over the
+// DiscretionaryMonths complete months before monthStart.
+func (s *Service) forecastAccounts(today string, monthStart time.Time) ([]AccountForecast, error) {
+	rows, err := s.db.Query(
+		`SELECT a.id, a.name,
+		        a.opening_balance + COALESCE((SELECT SUM(e.amount) FROM all_account_entries e
+		                  WHERE e.account_id = a.id AND e.date <= ?), 0),
+		        COALESCE((SELECT SUM(t.amount) FROM all_transactions t
+		                  WHERE t.account_id = a.id AND t.type = 'expense' AND t.is_recurring_instance = 0
+		                    AND t.status != 'void' AND t.date >= ? AND t.date < ?), 0)
+		 FROM accounts a
+		 WHERE a.is_archived = 0
+		 ORDER BY a.id`,
+		today, monthStart.AddDate(0, -DiscretionaryMonths, 0).Format("2006-01-02"), monthStart.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying forecast accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]AccountForecast, 0)
+	for rows.Next() {
+		var account AccountForecast
+		var spent float64
+		if err := rows.Scan(&account.AccountID, &account.AccountName, &account.StartingBalance, &spent); err != nil {
+			return nil, fmt.Errorf("scanning forecast account: %w", err)
+		}
+		account.StartingBalance = shared.RoundCents(account.StartingBalance)
+		account.Discretionary = shared.RoundCents(spent / DiscretionaryMonths)
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating forecast accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// scheduledMovements returns the movements of the transactions dated after
+// today and before until, which the balances of today do not include yet.
+func (s *Service) scheduledMovements(today string, until string) ([]movement, error) {
+	rows, err := s.db.Query(
+		`SELECT account_id, type, substr(date, 1, 7), amount
+		 FROM account_entries
+		 WHERE date > ? AND date < ?`,
+		today, until,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying scheduled transactions: %w", err)
+	}
+	defer rows.Close()
+
+	movements := make([]movement, 0)
+	for rows.Next() {
+		var m movement
+		if err := rows.Scan(&m.accountID, &m.kind, &m.month, &m.amount); err != nil {
+			return nil, fmt.Errorf("scanning scheduled transaction: %w", err)
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating scheduled transactions: %w", err)
+	}
+	return movements, nil
+}
@@ -2,9 +2,13 @@ package reports
 
 import (
 	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/recurring"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
 
@@ -15,7 +19,7 @@ type Handler struct {
 
 // NewHandler creates a Handler wired to the reports service.
 func NewHandler(db *sql.DB) *Handler {
-	return &Handler{service: NewService(db)}
+	return &Handler{service: NewService(db, recurring.NewService(recurring.NewRepository(db)))}
 }
 
 // Handle dispatches the request to the correct report handler.
@@ -29,6 +33,10 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return h.categories(req)
 	case req.Method == "GET" && req.Path == "/reports/net-worth":
 		return h.netWorth()
+	case req.Method == "GET" && req.Path == "/reports/yearly":
+		return h.yearly(req)
+	case req.Method == "GET" && req.Path == "/reports/forecast":
+		return h.forecast(req)
 	default:
 		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
 	}
@@ -44,7 +52,7 @@ func (h *Handler) summary(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return shared.JSONError(err)
 	}
 
-	summary, appErr := h.service.Summary(month)
+	summary, appErr := h.service.Summary(month, includeArchived(req))
 	if appErr != nil {
 		return shared.JSONError(appErr)
 	}
@@ -66,7 +74,7 @@ func (h *Handler) trends(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return shared.JSONError(err)
 	}
 
-	trends, appErr := h.service.Trends(from, to)
+	trends, appErr := h.service.Trends(from, to, includeArchived(req))
 	if appErr != nil {
 		return shared.JSONError(appErr)
 	}
@@ -83,7 +91,7 @@ func (h *Handler) categories(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return shared.JSONError(err)
 	}
 
-	comparisons, appErr := h.service.Categories(month)
+	comparisons, appErr := h.service.Categories(month, includeArchived(req))
 	if appErr != nil {
 		return shared.JSONError(appErr)
 	}
@@ -98,6 +106,91 @@ func (h *Handler) netWorth() (*sdk.APIResponse, error) {
 	return shared.JSONSuccess(200, nw)
 }
 
+// yearly returns the year-end report of ?year= (the current year by
+// default), as JSON or, with ?format=csv, as a CSV download. Expenses tagged
+// ?tax_tag= (DefaultTaxTag by default) are totalled as deductible.
+func (h *Handler) yearly(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	year := time.Now().Year()
+	if rawYear := req.Query["year"]; rawYear != "" {
+		parsed, err := strconv.Atoi(rawYear)
+		if err != nil || parsed < 1000 || parsed > 9999 {
+			return shared.JSONError(shared.NewValidationError("year must be in YYYY format"))
+		}
+		year = parsed
+	}
+
+	format := req.Query["format"]
+	if format != "" && format != "json" && format != "csv" {
+		return shared.JSONError(shared.NewValidationError("format must be json or csv"))
+	}
+
+	taxTag := strings.TrimSpace(req.Query["tax_tag"])
+	if taxTag == "" {
+		taxTag = DefaultTaxTag
+	}
+
+	report, appErr := h.service.Yearly(year, taxTag, includeArchived(req))
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	if format == "csv" {
+		content, err := buildYearlyCSV(report)
+		if err != nil {
+			return nil, err
+		}
+		return sdk.FileResponse("text/csv", fmt.Sprintf("report-%d.csv", year), content), nil
+	}
+	return shared.JSONSuccess(200, report)
+}
+
+// forecast projects the account balances of the next ?months= months (6 by
+// default), the current one included.
+func (h *Handler) forecast(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	months := DefaultForecastMonths
+	if rawMonths := req.Query["months"]; rawMonths != "" {
+		parsed, err := strconv.Atoi(rawMonths)
+		if err != nil || parsed < 1 || parsed > MaxForecastMonths {
+			return shared.JSONError(shared.NewValidationError(fmt.Sprintf("months must be between 1 and %d", MaxForecastMonths)))
+		}
+		months = parsed
+	}
+
+	forecast, appErr := h.service.Forecast(months, time.Now())
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, forecast)
+}
+
+// includeArchived reports whether ?include_archived=true asks the report to
+// also read transactions moved to the archive.
+func includeArchived(req *sdk.APIRequest) bool {
+	return req.Query["include_archived"] == "true"
+}
+
+// Invalidate drops the cached reports after a write they depend on.
+func (h *Handler) Invalidate() {
+	h.service.Invalidate()
+}
+
+// Warmup computes the reports the dashboard opens with, the current month's
+// summary and categories and the net worth, so they are cached before the
+// first request.
+func (h *Handler) Warmup(now time.Time) error {
+	month := now.Format("2006-01")
+	if _, appErr := h.service.Summary(month, false); appErr != nil {
+		return appErr
+	}
+	if _, appErr := h.service.Categories(month, false); appErr != nil {
+		return appErr
+	}
+	if _, appErr := h.service.NetWorthReport(); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
 // validateMonth checks that a string is in YYYY-MM format.
 func validateMonth(month string) *shared.AppError {
 	if _, err := time.Parse("2006-01", month); err != nil {
@@ -48,3 +48,74 @@ type NetWorth struct {
 	InvestmentsTotal float64 `json:"investments_total"`
 	NetWorth         float64 `json:"net_worth"`
 }
+
+// DefaultTaxTag is the tag that marks deductible expenses in the yearly
+// report, unless ?tax_tag= names another.
+const DefaultTaxTag = "deductible"
+
+// TopCategoriesLimit is how many expense categories the yearly report lists.
+const TopCategoriesLimit = 10
+
+// YearlyReport aggregates a year for year-end review: totals, income and
+// expense per month, the top expense categories, how much was moved between
+// accounts, and the expenses tagged as tax deductible.
+type YearlyReport struct {
+	Year          int               `json:"year"`
+	Income        float64           `json:"income"`
+	Expense       float64           `json:"expense"`
+	Balance       float64           `json:"balance"`
+	Transfers     float64           `json:"transfers"`
+	ByMonth       []TrendPoint      `json:"by_month"`
+	TopCategories []CategoryTotal   `json:"top_categories"`
+	Deductible    DeductibleSummary `json:"deductible"`
+}
+
+// DeductibleSummary totals the year's expenses carrying the tax tag, by
+// category.
+type DeductibleSummary struct {
+	Tag        string          `json:"tag"`
+	Total      float64         `json:"total"`
+	ByCategory []CategoryTotal `json:"by_category"`
+}
+
+// Forecast horizon bounds, in months, for GET /reports/forecast.
+const (
+	DefaultForecastMonths = 6
+	MaxForecastMonths     = 24
+)
+
+// DiscretionaryMonths is how many complete months back the forecast averages
+// spending that no recurring rule accounts for.
+const DiscretionaryMonths = 3
+
+// ForecastMonth is the projected income, expense and end-of-month balance of
+// one month. Transfers move a balance without counting as income or expense.
+type ForecastMonth struct {
+	Month   string  `json:"month"`
+	Income  float64 `json:"income"`
+	Expense float64 `json:"expense"`
+	Balance float64 `json:"balance"`
+}
+
+// AccountForecast is the projection of one account. StartingBalance is its
+// balance today and Discretionary its average monthly spend outside
+// recurring rules.
+type AccountForecast struct {
+	AccountID       int64           `json:"account_id"`
+	AccountName     string          `json:"account_name"`
+	StartingBalance float64         `json:"starting_balance"`
+	Discretionary   float64         `json:"discretionary"`
+	Months          []ForecastMonth `json:"months"`
+}
+
+// Forecast projects the balances of the active accounts from the current
+// month on, from their recurring rules, the transactions already scheduled
+// after today and their average discretionary spend. Months adds up the
+// accounts.
+type Forecast struct {
+	From            string            `json:"from"`
+	StartingBalance float64           `json:"starting_balance"`
+	Discretionary   float64           `json:"discretionary"`
+	Months          []ForecastMonth   `json:"months"`
+	Accounts        []AccountForecast `json:"accounts"`
+}
@@ -1,93 +1,227 @@
 package reports
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/recurring"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
 
+const (
+	// cacheSize bounds how many results of each report are kept in memory.
+	cacheSize = 64
+	// reportTimeout is the deadline shared by the concurrent queries of a report.
+	reportTimeout = 10 * time.Second
+)
+
 // Service executes read-only aggregation queries for financial reports.
 // It takes *sql.DB directly (no repository layer) since these are complex
-// aggregation queries, not standard CRUD operations.
+// aggregation queries, not standard CRUD operations. Results are cached by
+// their parameters until Invalidate is called.
 type Service struct {
 	db *sql.DB
+
+	summaries  *sdk.Cache[*MonthlySummary]
+	trends     *sdk.Cache[[]TrendPoint]
+	categories *sdk.Cache[[]CategoryComparison]
+	netWorth   *sdk.Cache[*NetWorth]
+	yearly     *sdk.Cache[*YearlyReport]
+	forecasts  *sdk.Cache[*Forecast]
+
+	recurring *recurring.Service
 }
 
-// NewService creates a new reports Service.
-func NewService(db *sql.DB) *Service {
-	return &Service{db: db}
+// NewService creates a new reports Service. Forecasts project the rules of
+// recurringService.
+func NewService(db *sql.DB, recurringService *recurring.Service) *Service {
+	return &Service{
+		db:         db,
+		summaries:  sdk.NewCache[*MonthlySummary](cacheSize),
+		trends:     sdk.NewCache[[]TrendPoint](cacheSize),
+		categories: sdk.NewCache[[]CategoryComparison](cacheSize),
+		netWorth:   sdk.NewCache[*NetWorth](1),
+		yearly:     sdk.NewCache[*YearlyReport](cacheSize),
+		forecasts:  sdk.NewCache[*Forecast](cacheSize),
+		recurring:  recurringService,
+	}
+}
+
+// Invalidate drops every cached report. It must be called after any write to
+// transactions, accounts, investments or recurring rules.
+func (s *Service) Invalidate() {
+	s.summaries.Invalidate()
+	s.trends.Invalidate()
+	s.categories.Invalidate()
+	s.netWorth.Invalidate()
+	s.yearly.Invalidate()
+	s.forecasts.Invalidate()
 }
 
 // Summary returns income, expense, balance, and breakdowns for a given month.
-func (s *Service) Summary(month string) (*MonthlySummary, *shared.AppError) {
-	prefix := month + "%"
+func (s *Service) Summary(month string, includeArchived bool) (*MonthlySummary, *shared.AppError) {
+	return cached(s.summaries, fmt.Sprintf("%s:%t", month, includeArchived), func() (*MonthlySummary, *shared.AppError) {
+		return s.summary(month, includeArchived)
+	})
+}
 
-	// Total income and expense for the month.
-	var income, expense float64
-	err := s.db.QueryRow(
-		`SELECT COALESCE(SUM(CASE WHEN type='income' THEN amount ELSE 0 END), 0),
-		        COALESCE(SUM(CASE WHEN type='expense' THEN amount ELSE 0 END), 0)
-		 FROM transactions WHERE date LIKE ?`,
-		prefix,
-	).Scan(&income, &expense)
-	if err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying monthly totals: %v", err), 500)
-	}
+// Trends returns monthly income/expense/balance totals between two months (inclusive).
+func (s *Service) Trends(from, to string, includeArchived bool) ([]TrendPoint, *shared.AppError) {
+	return cached(s.trends, fmt.Sprintf("%s:%s:%t", from, to, includeArchived), func() ([]TrendPoint, *shared.AppError) {
+		return s.trendPoints(from, to, includeArchived)
+	})
+}
 
-	// By category (expenses only).
-	catRows, err := s.db.Query(
-		`SELECT category, SUM(amount) as total
-		 FROM transactions
-		 WHERE type = 'expense' AND date LIKE ?
-		 GROUP BY category ORDER BY total DESC`,
-		prefix,
-	)
-	if err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying categories: %v", err), 500)
-	}
-	defer catRows.Close()
+// Categories returns expense totals by category for the given month compared
+// to the previous month, including percentage change.
+func (s *Service) Categories(month string, includeArchived bool) ([]CategoryComparison, *shared.AppError) {
+	return cached(s.categories, fmt.Sprintf("%s:%t", month, includeArchived), func() ([]CategoryComparison, *shared.AppError) {
+		return s.categoryComparisons(month, includeArchived)
+	})
+}
 
-	categories := make([]CategoryTotal, 0)
-	for catRows.Next() {
-		var ct CategoryTotal
-		if err := catRows.Scan(&ct.Category, &ct.Total); err != nil {
-			return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("scanning category: %v", err), 500)
+// Yearly returns the year-end report of a year, with the expenses tagged
+// taxTag as deductible.
+func (s *Service) Yearly(year int, taxTag string, includeArchived bool) (*YearlyReport, *shared.AppError) {
+	return cached(s.yearly, fmt.Sprintf("%d:%s:%t", year, taxTag, includeArchived), func() (*YearlyReport, *shared.AppError) {
+		return s.yearlyReport(year, taxTag, includeArchived)
+	})
+}
+
+// Forecast returns the projection of the given number of months from today's.
+func (s *Service) Forecast(months int, today time.Time) (*Forecast, *shared.AppError) {
+	return cached(s.forecasts, fmt.Sprintf("%s:%d", today.Format("2006-01-02"), months), func() (*Forecast, *shared.AppError) {
+		return s.forecast(months, today)
+	})
+}
+
+// NetWorthReport returns total net worth computed from account transaction
+// totals and investment positions.
+func (s *Service) NetWorthReport() (*NetWorth, *shared.AppError) {
+	return cached(s.netWorth, "net-worth", s.computeNetWorth)
+}
+
+// cached returns the result cached under key, computing it on a miss.
+func cached[V any](cache *sdk.Cache[V], key string, compute func() (V, *shared.AppError)) (V, *shared.AppError) {
+	value, err := cache.Get(key, func() (V, error) {
+		value, appErr := compute()
+		if appErr != nil {
+			return value, appErr
 		}
-		categories = append(categories, ct)
+		return value, nil
+	})
+	if err != nil {
+		return value, err.(*shared.AppError)
 	}
-	if err := catRows.Err(); err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("iterating categories: %v", err), 500)
+	return value, nil
+}
+
+// transactionSource is the table or view a report reads: live transactions
+// only, or all_transactions to include those moved to the archive.
+func transactionSource(includeArchived bool) string {
+	if includeArchived {
+		return "all_transactions"
 	}
+	return "transactions"
+}
 
-	// By account (net balance per account for the month).
-	acctRows, err := s.db.Query(
-		`SELECT a.id, a.name,
-		        COALESCE(SUM(CASE WHEN t.type='income' THEN t.amount
-		                          WHEN t.type='expense' THEN -t.amount
-		                          ELSE 0 END), 0) as total
-		 FROM accounts a
-		 LEFT JOIN transactions t ON t.account_id = a.id AND t.date LIKE ?
-		 WHERE a.is_archived = 0
-		 GROUP BY a.id, a.name`,
-		prefix,
-	)
-	if err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying accounts: %v", err), 500)
+// entrySource is the double-entry counterpart of transactionSource, with one
+// signed row per account a transaction touches.
+func entrySource(includeArchived bool) string {
+	if includeArchived {
+		return "all_account_entries"
 	}
-	defer acctRows.Close()
+	return "account_entries"
+}
+
+func (s *Service) summary(month string, includeArchived bool) (*MonthlySummary, *shared.AppError) {
+	prefix := month + "%"
+	source := transactionSource(includeArchived)
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+	group, ctx := errgroup.WithContext(ctx)
+
+	// Total income and expense for the month.
+	var income, expense float64
+	group.Go(func() error {
+		err := s.db.QueryRowContext(ctx,
+			`SELECT COALESCE(SUM(CASE WHEN type='income' THEN amount ELSE 0 END), 0),
+			        COALESCE(SUM(CASE WHEN type='expense' THEN amount ELSE 0 END), 0)
+			 FROM `+source+` WHERE date LIKE ? AND status != 'void'`,
+			prefix,
+		).Scan(&income, &expense)
+		if err != nil {
+			return fmt.Errorf("querying monthly totals: %w", err)
+		}
+		return nil
+	})
 
+	// By category (expenses only).
+	categories := make([]CategoryTotal, 0)
+	group.Go(func() error {
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT category, SUM(amount) as total
+			 FROM `+source+`
+			 WHERE type = 'expense' AND date LIKE ? AND status != 'void'
+			 GROUP BY category ORDER BY total DESC`,
+			prefix,
+		)
+		if err != nil {
+			return fmt.Errorf("querying categories: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var ct CategoryTotal
+			if err := rows.Scan(&ct.Category, &ct.Total); err != nil {
+				return fmt.Errorf("scanning category: %w", err)
+			}
+			categories = append(categories, ct)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterating categories: %w", err)
+		}
+		return nil
+	})
+
+	// By account (net movement per account for the month, transfers included).
 	accounts := make([]AccountTotal, 0)
-	for acctRows.Next() {
-		var at AccountTotal
-		if err := acctRows.Scan(&at.AccountID, &at.AccountName, &at.Total); err != nil {
-			return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("scanning account: %v", err), 500)
+	group.Go(func() error {
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT a.id, a.name, COALESCE(SUM(e.amount), 0) as total
+			 FROM accounts a
+			 LEFT JOIN `+entrySource(includeArchived)+` e ON e.account_id = a.id AND e.date LIKE ?
+			 WHERE a.is_archived = 0
+			 GROUP BY a.id, a.name`,
+			prefix,
+		)
+		if err != nil {
+			return fmt.Errorf("querying accounts: %w", err)
 		}
-		accounts = append(accounts, at)
-	}
-	if err := acctRows.Err(); err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("iterating accounts: %v", err), 500)
+		defer rows.Close()
+
+		for rows.Next() {
+			var at AccountTotal
+			if err := rows.Scan(&at.AccountID, &at.AccountName, &at.Total); err != nil {
+				return fmt.Errorf("scanning account: %w", err)
+			}
+			accounts = append(accounts, at)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterating accounts: %w", err)
+		}
+		return nil
+	})
+
+	if err := group.Wait(); err != nil {
+		return nil, queryError(err)
 	}
 
 	return &MonthlySummary{
@@ -100,8 +234,7 @@ func (s *Service) Summary(month string) (*MonthlySummary, *shared.AppError) {
 	}, nil
 }
 
-// Trends returns monthly income/expense/balance totals between two months (inclusive).
-func (s *Service) Trends(from, to string) ([]TrendPoint, *shared.AppError) {
+func (s *Service) trendPoints(from, to string, includeArchived bool) ([]TrendPoint, *shared.AppError) {
 	// Generate all months in range.
 	months, appErr := generateMonths(from, to)
 	if appErr != nil {
@@ -113,8 +246,8 @@ func (s *Service) Trends(from, to string) ([]TrendPoint, *shared.AppError) {
 		`SELECT substr(date, 1, 7) as month,
 		        COALESCE(SUM(CASE WHEN type='income' THEN amount ELSE 0 END), 0) as income,
 		        COALESCE(SUM(CASE WHEN type='expense' THEN amount ELSE 0 END), 0) as expense
-		 FROM transactions
-		 WHERE substr(date, 1, 7) >= ? AND substr(date, 1, 7) <= ?
+		 FROM `+transactionSource(includeArchived)+`
+		 WHERE substr(date, 1, 7) >= ? AND substr(date, 1, 7) <= ? AND status != 'void'
 		 GROUP BY substr(date, 1, 7)
 		 ORDER BY month`,
 		from, to,
@@ -151,74 +284,53 @@ func (s *Service) Trends(from, to string) ([]TrendPoint, *shared.AppError) {
 	return result, nil
 }
 
-// Categories returns expense totals by category for the given month compared
-// to the previous month, including percentage change.
-func (s *Service) Categories(month string) ([]CategoryComparison, *shared.AppError) {
+func (s *Service) categoryComparisons(month string, includeArchived bool) ([]CategoryComparison, *shared.AppError) {
 	prevMonth, appErr := previousMonth(month)
 	if appErr != nil {
 		return nil, appErr
 	}
 
-	currentPrefix := month + "%"
-	prevPrefix := prevMonth + "%"
+	source := transactionSource(includeArchived)
 
-	// Current month expenses by category.
-	currentMap := make(map[string]float64)
-	currentRows, err := s.db.Query(
-		`SELECT category, SUM(amount) as total
-		 FROM transactions
-		 WHERE type = 'expense' AND date LIKE ?
-		 GROUP BY category`,
-		currentPrefix,
-	)
-	if err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying current categories: %v", err), 500)
-	}
-	defer currentRows.Close()
-
-	var allCategories []string
-	for currentRows.Next() {
-		var cat string
-		var total float64
-		if err := currentRows.Scan(&cat, &total); err != nil {
-			return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("scanning current category: %v", err), 500)
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+	group, ctx := errgroup.WithContext(ctx)
+
+	// Current and previous month expenses by category.
+	var current, previous []CategoryTotal
+	group.Go(func() error {
+		var err error
+		if current, err = s.expensesByCategory(ctx, source, month); err != nil {
+			return fmt.Errorf("current categories: %w", err)
 		}
-		currentMap[cat] = total
-		allCategories = append(allCategories, cat)
-	}
-	if err := currentRows.Err(); err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("iterating current categories: %v", err), 500)
-	}
+		return nil
+	})
+	group.Go(func() error {
+		var err error
+		if previous, err = s.expensesByCategory(ctx, source, prevMonth); err != nil {
+			return fmt.Errorf("previous categories: %w", err)
+		}
+		return nil
+	})
 
-	// Previous month expenses by category.
-	prevMap := make(map[string]float64)
-	prevRows, err := s.db.Query(
-		`SELECT category, SUM(amount) as total
-		 FROM transactions
-		 WHERE type = 'expense' AND date LIKE ?
-		 GROUP BY category`,
-		prevPrefix,
-	)
-	if err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying previous categories: %v", err), 500)
+	if err := group.Wait(); err != nil {
+		return nil, queryError(err)
 	}
-	defer prevRows.Close()
 
-	for prevRows.Next() {
-		var cat string
-		var total float64
-		if err := prevRows.Scan(&cat, &total); err != nil {
-			return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("scanning previous category: %v", err), 500)
-		}
-		prevMap[cat] = total
+	currentMap := make(map[string]float64, len(current))
+	allCategories := make([]string, 0, len(current)+len(previous))
+	for _, ct := range current {
+		currentMap[ct.Category] = ct.Total
+		allCategories = append(allCategories, ct.Category)
+	}
+	prevMap := make(map[string]float64, len(previous))
+	for _, ct := range previous {
+		prevMap[ct.Category] = ct.Total
 		// Add categories that only appear in previous month.
-		if _, exists := currentMap[cat]; !exists {
-			allCategories = append(allCategories, cat)
+		if _, exists := currentMap[ct.Category]; !exists {
+			allCategories = append(allCategories, ct.Category)
 		}
 	}
-	if err := prevRows.Err(); err != nil {
-		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("iterating previous categories: %v", err), 500)
-	}
 
 	// Build comparisons.
 	result := make([]CategoryComparison, 0, len(allCategories))
@@ -242,17 +354,52 @@ func (s *Service) Categories(month string) ([]CategoryComparison, *shared.AppErr
 	return result, nil
 }
 
-// NetWorthReport returns total net worth computed from account transaction
-// totals and investment positions.
-func (s *Service) NetWorthReport() (*NetWorth, *shared.AppError) {
-	// Sum of all income - expense across non-archived accounts.
+// expensesByCategory totals a month's expenses per category.
+func (s *Service) expensesByCategory(ctx context.Context, source, month string) ([]CategoryTotal, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT category, SUM(amount) as total
+		 FROM `+source+`
+		 WHERE type = 'expense' AND date LIKE ? AND status != 'void'
+		 GROUP BY category`,
+		month+"%",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []CategoryTotal
+	for rows.Next() {
+		var ct CategoryTotal
+		if err := rows.Scan(&ct.Category, &ct.Total); err != nil {
+			return nil, fmt.Errorf("scanning: %w", err)
+		}
+		totals = append(totals, ct)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating: %w", err)
+	}
+	return totals, nil
+}
+
+// queryError converts the first failure of a report's concurrent queries.
+func queryError(err error) *shared.AppError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return shared.NewAppError("TIMEOUT", "report took too long to compute", 504)
+	}
+	return shared.NewAppError("INTERNAL", err.Error(), 500)
+}
+
+// computeNetWorth sums account transaction totals and investment positions.
+// Archived transactions always count: they still make up the account balances.
+func (s *Service) computeNetWorth() (*NetWorth, *shared.AppError) {
+	// Sum of the balances of non-archived accounts, opening balances included.
+	// Transfers between two of them cancel out; those to or from an archived
+	// account do not.
 	var accountsTotal float64
 	err := s.db.QueryRow(
-		`SELECT COALESCE(SUM(
-		    CASE WHEN type='income' THEN amount
-		         WHEN type='expense' THEN -amount
-		         ELSE 0 END
-		), 0) FROM transactions
+		`SELECT COALESCE((SELECT SUM(opening_balance) FROM accounts WHERE is_archived = 0), 0) +
+		        COALESCE(SUM(amount), 0) FROM all_account_entries
 		WHERE account_id IN (SELECT id FROM accounts WHERE is_archived = 0)`,
 	).Scan(&accountsTotal)
 	if err != nil {
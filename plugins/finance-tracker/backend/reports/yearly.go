@@ -0,0 +1,176 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// yearlyCSVHeader lists the columns of the yearly report's CSV. Every row is
+// one figure of the report, named by its section: "month" and "year" rows
+// fill income, expense and balance; "transfers", "category", "deductible" and
+// "deductible_category" rows fill amount.
+var yearlyCSVHeader = []string{"section", "name", "income", "expense", "balance", "amount"}
+
+func (s *Service) yearlyReport(year int, taxTag string, includeArchived bool) (*YearlyReport, *shared.AppError) {
+	prefix := fmt.Sprintf("%04d-%%", year)
+	source := transactionSource(includeArchived)
+
+	months, appErr := s.trendPoints(fmt.Sprintf("%04d-01", year), fmt.Sprintf("%04d-12", year), includeArchived)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+	group, ctx := errgroup.WithContext(ctx)
+
+	// Money moved between accounts, which is neither income nor expense.
+	var transfers float64
+	group.Go(func() error {
+		err := s.db.QueryRowContext(ctx,
+			`SELECT COALESCE(SUM(amount), 0)
+			 FROM `+source+`
+			 WHERE type = 'transfer' AND date LIKE ? AND status != 'void'`,
+			prefix,
+		).Scan(&transfers)
+		if err != nil {
+			return fmt.Errorf("querying transfers: %w", err)
+		}
+		return nil
+	})
+
+	// Top expense categories.
+	var topCategories []CategoryTotal
+	group.Go(func() error {
+		totals, err := s.categoryTotals(ctx,
+			`SELECT category, SUM(amount) as total
+			 FROM `+source+`
+			 WHERE type = 'expense' AND date LIKE ? AND status != 'void'
+			 GROUP BY category ORDER BY total DESC, category
+			 LIMIT ?`,
+			prefix, TopCategoriesLimit,
+		)
+		if err != nil {
+			return fmt.Errorf("querying top categories: %w", err)
+		}
+		topCategories = totals
+		return nil
+	})
+
+	// Deductible expenses by category. Archived transactions keep the names
+	// of their tags instead of tag links.
+	var deductible []CategoryTotal
+	group.Go(func() error {
+		query := `SELECT t.category, t.amount FROM transactions t
+			WHERE t.type = 'expense' AND t.date LIKE ? AND t.status != 'void'
+			  AND t.id IN (
+			      SELECT tt.transaction_id FROM transaction_tags tt
+			      INNER JOIN tags g ON g.id = tt.tag_id
+			      WHERE g.name = ? COLLATE NOCASE
+			  )`
+		args := []interface{}{prefix, taxTag}
+		if includeArchived {
+			query += `
+			UNION ALL
+			SELECT category, amount FROM archived_transactions
+			WHERE type = 'expense' AND date LIKE ? AND status != 'void'
+			  AND instr(',' || lower(tags) || ',', ',' || lower(?) || ',') > 0`
+			args = append(args, prefix, taxTag)
+		}
+
+		totals, err := s.categoryTotals(ctx,
+			`SELECT category, SUM(amount) as total FROM (`+query+`)
+			 GROUP BY category ORDER BY total DESC, category`,
+			args...,
+		)
+		if err != nil {
+			return fmt.Errorf("querying deductible expenses: %w", err)
+		}
+		deductible = totals
+		return nil
+	})
+
+	if err := group.Wait(); err != nil {
+		return nil, queryError(err)
+	}
+
+	report := &YearlyReport{
+		Year:          year,
+		Transfers:     transfers,
+		ByMonth:       months,
+		TopCategories: topCategories,
+		Deductible:    DeductibleSummary{Tag: taxTag, ByCategory: deductible},
+	}
+	for _, month := range months {
+		report.Income += month.Income
+		report.Expense += month.Expense
+	}
+	report.Balance = report.Income - report.Expense
+	for _, category := range deductible {
+		report.Deductible.Total += category.Total
+	}
+	return report, nil
+}
+
+// categoryTotals runs a query returning a category and a total per row.
+func (s *Service) categoryTotals(ctx context.Context, query string, args ...interface{}) ([]CategoryTotal, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make([]CategoryTotal, 0)
+	for rows.Next() {
+		var ct CategoryTotal
+		if err := rows.Scan(&ct.Category, &ct.Total); err != nil {
+			return nil, fmt.Errorf("scanning category: %w", err)
+		}
+		totals = append(totals, ct)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating categories: %w", err)
+	}
+	return totals, nil
+}
+
+// buildYearlyCSV renders a yearly report as CSV, one figure per row.
+func buildYearlyCSV(report *YearlyReport) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	records := [][]string{yearlyCSVHeader}
+	for _, month := range report.ByMonth {
+		records = append(records, []string{"month", month.Month,
+			formatAmount(month.Income), formatAmount(month.Expense), formatAmount(month.Balance), ""})
+	}
+	records = append(records,
+		[]string{"year", strconv.Itoa(report.Year),
+			formatAmount(report.Income), formatAmount(report.Expense), formatAmount(report.Balance), ""},
+		[]string{"transfers", "", "", "", "", formatAmount(report.Transfers)},
+	)
+	for _, category := range report.TopCategories {
+		records = append(records, []string{"category", shared.SanitizeCell(category.Category), "", "", "", formatAmount(category.Total)})
+	}
+	records = append(records, []string{"deductible", shared.SanitizeCell(report.Deductible.Tag), "", "", "", formatAmount(report.Deductible.Total)})
+	for _, category := range report.Deductible.ByCategory {
+		records = append(records, []string{"deductible_category", shared.SanitizeCell(category.Category), "", "", "", formatAmount(category.Total)})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("writing yearly csv: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// formatAmount formats an amount with two decimals.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
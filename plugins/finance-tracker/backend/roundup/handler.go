@@ -0,0 +1,103 @@
+package roundup
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Handler routes round-up API requests to the appropriate service method.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Handler with all layers wired together.
+func NewHandler(db *sql.DB) *Handler {
+	repo := NewRepository(db)
+	svc := NewService(repo)
+	return &Handler{service: svc}
+}
+
+// Handle dispatches the request to the correct handler based on method and path.
+func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	switch {
+	case req.Method == "GET" && req.Path == "/roundup/rules":
+		return h.list()
+	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/roundup/rules/"):
+		return h.save(req)
+	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/roundup/rules/"):
+		return h.delete(req)
+	case req.Method == "POST" && req.Path == "/roundup/run":
+		return h.run()
+	default:
+		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
+	}
+}
+
+// ApplyPending rounds up any expenses not processed yet. It runs after
+// transactions are created so round-ups happen without waiting for the
+// nightly POST /roundup/run.
+func (h *Handler) ApplyPending() *shared.AppError {
+	_, appErr := h.service.Run(time.Now())
+	return appErr
+}
+
+func (h *Handler) list() (*sdk.APIResponse, error) {
+	rules, appErr := h.service.List()
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, rules)
+}
+
+func (h *Handler) save(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	accountID, appErr := accountIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	var input SaveRuleInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	rule, appErr := h.service.Save(accountID, &input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, rule)
+}
+
+func (h *Handler) delete(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	accountID, appErr := accountIDFromPath(req.Path)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	if appErr := h.service.Delete(accountID); appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, map[string]interface{}{"deleted": accountID})
+}
+
+func (h *Handler) run() (*sdk.APIResponse, error) {
+	result, appErr := h.service.Run(time.Now())
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, result)
+}
+
+// accountIDFromPath parses the account ID from /roundup/rules/{accountID}.
+func accountIDFromPath(path string) (int64, *shared.AppError) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(path, "/roundup/rules/"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, shared.NewValidationError("invalid account ID: must be a number")
+	}
+	return id, nil
+}
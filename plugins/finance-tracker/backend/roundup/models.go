@@ -0,0 +1,40 @@
+package roundup
+
+// Rule configures round-up savings for one source account. The difference is
+// transferred to DestAccountID (a real account) and/or added to GoalID (a
+// virtual savings goal). Only expenses dated on or after StartDate are rounded.
+type Rule struct {
+	AccountID     int64   `json:"account_id"`
+	Increment     float64 `json:"increment"`
+	DestAccountID *int64  `json:"dest_account_id,omitempty"`
+	GoalID        *int64  `json:"goal_id,omitempty"`
+	StartDate     string  `json:"start_date"`
+	IsActive      bool    `json:"is_active"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// SaveRuleInput holds the input for creating or replacing an account's rule.
+type SaveRuleInput struct {
+	Increment     float64 `json:"increment"`
+	DestAccountID *int64  `json:"dest_account_id"`
+	GoalID        *int64  `json:"goal_id"`
+	StartDate     string  `json:"start_date"`
+	IsActive      *bool   `json:"is_active"`
+}
+
+// pendingExpense is an expense on a rounded-up account that has not been processed yet.
+type pendingExpense struct {
+	ID            int64
+	Amount        float64
+	Date          string
+	AccountID     int64
+	Increment     float64
+	DestAccountID *int64
+	GoalID        *int64
+}
+
+// RunResult summarizes a round-up run.
+type RunResult struct {
+	Processed int     `json:"processed"`
+	Saved     float64 `json:"saved"`
+}
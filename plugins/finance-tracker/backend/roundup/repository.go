@@ -0,0 +1,244 @@
+package roundup
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/goals"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Repository handles database operations for round-up rules and records.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Repository backed by the given database connection.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// List returns all round-up rules ordered by account.
+func (r *Repository) List() ([]Rule, error) {
+	rows, err := r.db.Query(`
+		SELECT account_id, increment, dest_account_id, goal_id, start_date, is_active, created_at
+		FROM roundup_rules
+		ORDER BY account_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying roundup rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]Rule, 0)
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating roundup rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetByAccount returns the round-up rule for an account.
+func (r *Repository) GetByAccount(accountID int64) (*Rule, *shared.AppError) {
+	row := r.db.QueryRow(`
+		SELECT account_id, increment, dest_account_id, goal_id, start_date, is_active, created_at
+		FROM roundup_rules WHERE account_id = ?
+	`, accountID)
+
+	rule, err := scanRule(row)
+	if err == sql.ErrNoRows {
+		return nil, shared.NewNotFoundError("roundup rule for account", fmt.Sprintf("%d", accountID))
+	}
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying roundup rule: %v", err), 500)
+	}
+	return rule, nil
+}
+
+// Save creates or replaces the rule for an account.
+func (r *Repository) Save(accountID int64, input *SaveRuleInput) error {
+	isActive := 1
+	if input.IsActive != nil && !*input.IsActive {
+		isActive = 0
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO roundup_rules (account_id, increment, dest_account_id, goal_id, start_date, is_active)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(account_id) DO UPDATE SET
+			increment = excluded.increment,
+			dest_account_id = excluded.dest_account_id,
+			goal_id = excluded.goal_id,
+			start_date = excluded.start_date,
+			is_active = excluded.is_active
+	`, accountID, input.Increment, nullableID(input.DestAccountID), nullableID(input.GoalID), input.StartDate, isActive)
+	if err != nil {
+		return fmt.Errorf("saving roundup rule: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the rule for an account. Past round-ups are kept.
+func (r *Repository) Delete(accountID int64) error {
+	result, err := r.db.Exec("DELETE FROM roundup_rules WHERE account_id = ?", accountID)
+	if err != nil {
+		return fmt.Errorf("deleting roundup rule: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return shared.NewNotFoundError("roundup rule for account", fmt.Sprintf("%d", accountID))
+	}
+	return nil
+}
+
+// ListPending returns expenses on accounts with an active rule, dated between
+// the rule's start date and today, that have not been rounded up yet.
+func (r *Repository) ListPending(today string) ([]pendingExpense, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.amount, t.date, r.account_id, r.increment, r.dest_account_id, r.goal_id
+		FROM transactions t
+		JOIN roundup_rules r ON r.account_id = t.account_id AND r.is_active = 1
+		LEFT JOIN roundups ru ON ru.expense_id = t.id
+		WHERE t.type = 'expense' AND t.status != 'void'
+		  AND t.date >= r.start_date AND t.date <= ?
+		  AND ru.expense_id IS NULL
+		ORDER BY t.id
+	`, today)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending roundups: %w", err)
+	}
+	defer rows.Close()
+
+	pending := make([]pendingExpense, 0)
+	for rows.Next() {
+		var expense pendingExpense
+		var destAccountID, goalID sql.NullInt64
+		if err := rows.Scan(
+			&expense.ID, &expense.Amount, &expense.Date, &expense.AccountID,
+			&expense.Increment, &destAccountID, &goalID,
+		); err != nil {
+			return nil, fmt.Errorf("scanning pending roundup: %w", err)
+		}
+		expense.DestAccountID = int64Ptr(destAccountID)
+		expense.GoalID = int64Ptr(goalID)
+		pending = append(pending, expense)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating pending roundups: %w", err)
+	}
+	return pending, nil
+}
+
+// Apply records the round-up of one expense atomically: the transfer to the
+// savings account, the goal contribution, and the processed marker.
+func (r *Repository) Apply(expense *pendingExpense, amount float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning roundup transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var transferID interface{}
+	if amount > 0 && expense.DestAccountID != nil {
+		result, err := tx.Exec(`
+			INSERT INTO transactions (amount, type, account_id, dest_account_id, category, description, date)
+			VALUES (?, 'transfer', ?, ?, '', ?, ?)
+		`, amount, expense.AccountID, *expense.DestAccountID,
+			fmt.Sprintf("Round-up of transaction #%d", expense.ID), expense.Date)
+		if err != nil {
+			return fmt.Errorf("inserting roundup transfer: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("reading roundup transfer id: %w", err)
+		}
+		transferID = id
+	}
+
+	if amount > 0 && expense.GoalID != nil {
+		if err := goals.Contribute(tx, *expense.GoalID, amount, expense.Date); err != nil {
+			return fmt.Errorf("contributing roundup to goal: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO roundups (expense_id, transfer_id, goal_id, amount) VALUES (?, ?, ?, ?)
+	`, expense.ID, transferID, nullableID(expense.GoalID), amount); err != nil {
+		return fmt.Errorf("recording roundup: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing roundup: %w", err)
+	}
+	return nil
+}
+
+// AccountExists checks whether an account with the given ID exists.
+func (r *Repository) AccountExists(id int64) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM accounts WHERE id = ?", id).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("checking account existence: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GoalExists checks whether a savings goal with the given ID exists.
+func (r *Repository) GoalExists(id int64) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM savings_goals WHERE id = ?", id).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("checking goal existence: %w", err)
+	}
+	return count > 0, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRule reads a single rule from a row.
+func scanRule(row rowScanner) (*Rule, error) {
+	var rule Rule
+	var destAccountID, goalID sql.NullInt64
+	var isActive int
+
+	if err := row.Scan(
+		&rule.AccountID, &rule.Increment, &destAccountID, &goalID,
+		&rule.StartDate, &isActive, &rule.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scanning roundup rule: %w", err)
+	}
+
+	rule.DestAccountID = int64Ptr(destAccountID)
+	rule.GoalID = int64Ptr(goalID)
+	rule.IsActive = isActive == 1
+	return &rule, nil
+}
+
+// nullableID converts an optional ID to a value suitable for a nullable column.
+func nullableID(id *int64) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// int64Ptr converts a nullable column into an optional ID.
+func int64Ptr(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	id := value.Int64
+	return &id
+}
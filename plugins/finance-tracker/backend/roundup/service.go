@@ -0,0 +1,151 @@
+package roundup
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// maxIncrement bounds the rounding increment to keep round-ups meaningful.
+const maxIncrement = 100
+
+// Service contains the business logic for round-up savings.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a Service backed by the given Repository.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns all round-up rules.
+func (s *Service) List() ([]Rule, *shared.AppError) {
+	rules, err := s.repo.List()
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to list roundup rules", 500)
+	}
+	return rules, nil
+}
+
+// Save validates input and creates or replaces the rule for an account.
+func (s *Service) Save(accountID int64, input *SaveRuleInput) (*Rule, *shared.AppError) {
+	// Default to rounding up to the next whole unit, starting today.
+	if input.Increment == 0 {
+		input.Increment = 1
+	}
+	if input.StartDate == "" {
+		input.StartDate = time.Now().Format("2006-01-02")
+	}
+
+	if appErr := validateSaveInput(accountID, input); appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr := s.validateAccountExists(accountID); appErr != nil {
+		return nil, appErr
+	}
+	if input.DestAccountID != nil {
+		if appErr := s.validateAccountExists(*input.DestAccountID); appErr != nil {
+			return nil, appErr
+		}
+	}
+	if input.GoalID != nil {
+		exists, err := s.repo.GoalExists(*input.GoalID)
+		if err != nil {
+			return nil, shared.NewAppError("INTERNAL", "failed to check goal", 500)
+		}
+		if !exists {
+			return nil, shared.NewValidationError(fmt.Sprintf("goal %d not found", *input.GoalID))
+		}
+	}
+
+	if err := s.repo.Save(accountID, input); err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to save roundup rule", 500)
+	}
+
+	return s.repo.GetByAccount(accountID)
+}
+
+// Delete removes the rule for an account.
+func (s *Service) Delete(accountID int64) *shared.AppError {
+	if err := s.repo.Delete(accountID); err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return appErr
+		}
+		return shared.NewAppError("INTERNAL", "failed to delete roundup rule", 500)
+	}
+	return nil
+}
+
+// Run rounds up every pending expense dated up to the given day.
+// It is idempotent: each expense is rounded up at most once.
+func (s *Service) Run(today time.Time) (*RunResult, *shared.AppError) {
+	pending, err := s.repo.ListPending(today.Format("2006-01-02"))
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("listing pending roundups: %v", err), 500)
+	}
+
+	result := &RunResult{}
+	for i := range pending {
+		expense := &pending[i]
+		amount := RoundUpAmount(expense.Amount, expense.Increment)
+
+		if err := s.repo.Apply(expense, amount); err != nil {
+			return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("applying roundup: %v", err), 500)
+		}
+
+		result.Processed++
+		result.Saved = math.Round((result.Saved+amount)*100) / 100
+	}
+
+	return result, nil
+}
+
+// RoundUpAmount returns how much is needed to round amount up to the next
+// multiple of increment. Amounts are compared in cents to avoid float drift,
+// so an expense of exactly 3.00 rounds up by 0.
+func RoundUpAmount(amount float64, increment float64) float64 {
+	cents := int64(math.Round(amount * 100))
+	incrementCents := int64(math.Round(increment * 100))
+	if incrementCents <= 0 {
+		return 0
+	}
+
+	remainder := cents % incrementCents
+	if remainder == 0 {
+		return 0
+	}
+	return float64(incrementCents-remainder) / 100
+}
+
+// validateAccountExists checks that an account with the given ID exists.
+func (s *Service) validateAccountExists(accountID int64) *shared.AppError {
+	exists, err := s.repo.AccountExists(accountID)
+	if err != nil {
+		return shared.NewAppError("INTERNAL", "failed to check account", 500)
+	}
+	if !exists {
+		return shared.NewValidationError(fmt.Sprintf("account %d not found", accountID))
+	}
+	return nil
+}
+
+// validateSaveInput checks that the rule input is well-formed.
+func validateSaveInput(accountID int64, input *SaveRuleInput) *shared.AppError {
+	if input.Increment < 0.01 || input.Increment > maxIncrement {
+		return shared.NewValidationError("increment must be between 0.01 and 100")
+	}
+	if input.DestAccountID == nil && input.GoalID == nil {
+		return shared.NewValidationError("dest_account_id or goal_id is required")
+	}
+	if input.DestAccountID != nil && *input.DestAccountID == accountID {
+		return shared.NewValidationError("dest_account_id must differ from the rounded-up account")
+	}
+	if _, err := time.Parse("2006-01-02", input.StartDate); err != nil {
+		return shared.NewValidationError("start_date must be in YYYY-MM-DD format")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/accounts"
+)
+
+// financeSettings are the plugin options set from the dashboard.
+type financeSettings struct {
+	// DefaultCurrency is the currency of accounts created without one.
+	DefaultCurrency string `json:"default_currency"`
+}
+
+// currencyCodeRegex matches ISO 4217 currency codes such as "USD".
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// loadSettings applies the settings saved for the plugin. Without a host
+// connection, as in tests, the defaults stay in place.
+func (p *FinancePlugin) loadSettings() {
+	var settings financeSettings
+	if err := sdk.GetSettings(&settings); err != nil {
+		return
+	}
+	_ = p.applySettings(settings)
+}
+
+// OnSettingsChanged applies settings saved from the dashboard while the
+// plugin is running.
+func (p *FinancePlugin) OnSettingsChanged(raw []byte) error {
+	var settings financeSettings
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return fmt.Errorf("decoding settings: %w", err)
+	}
+	return p.applySettings(settings)
+}
+
+// applySettings validates settings and hands them to the handlers using them.
+func (p *FinancePlugin) applySettings(settings financeSettings) error {
+	currency := settings.DefaultCurrency
+	if currency == "" {
+		currency = accounts.DefaultCurrency
+	}
+	if !currencyCodeRegex.MatchString(currency) {
+		return fmt.Errorf("default_currency must be a three-letter currency code such as USD, got %q", currency)
+	}
+
+	p.accountsHandler.SetDefaultCurrency(currency)
+	return nil
+}
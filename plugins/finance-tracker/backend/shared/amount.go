@@ -0,0 +1,9 @@
+package shared
+
+import "math"
+
+// RoundCents rounds an amount to cents, so running sums do not accumulate
+// floating point noise.
+func RoundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
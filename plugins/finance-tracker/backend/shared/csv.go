@@ -0,0 +1,13 @@
+package shared
+
+import "strings"
+
+// SanitizeCell neutralizes values spreadsheet software would run as formulas
+// by prefixing them with a quote. Every CSV the plugin writes passes its text
+// cells through it.
+func SanitizeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
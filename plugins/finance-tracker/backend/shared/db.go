@@ -2,9 +2,10 @@ package shared
 
 import (
 	"database/sql"
-	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
 )
 
 // ExtractIDFromPath parses a numeric resource ID from the second segment of a
@@ -22,19 +23,8 @@ func ExtractIDFromPath(path string) (int64, *AppError) {
 }
 
 // OpenDatabase opens a SQLite database at the given path with WAL mode and
-// foreign keys enabled. The caller is responsible for closing the database.
+// foreign keys enabled, or encrypted when the host has at-rest encryption
+// enabled. The caller is responsible for closing the database.
 func OpenDatabase(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", path)
-	if err != nil {
-		return nil, fmt.Errorf("opening database: %w", err)
-	}
-	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("enabling WAL mode: %w", err)
-	}
-	if _, err := db.Exec("PRAGMA foreign_keys=ON"); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("enabling foreign keys: %w", err)
-	}
-	return db, nil
+	return sdk.OpenDatabase(path)
 }
@@ -1,37 +1,28 @@
 package shared
 
-import "fmt"
+import "github.com/alvarotorresc/cortex/pkg/sdk"
 
 // AppError represents a typed application error with HTTP status code.
 // All domain errors should use this type instead of raw strings, enabling
-// consistent error responses across the plugin.
-type AppError struct {
-	Code       string
-	Message    string
-	StatusCode int
-}
-
-// Error implements the error interface.
-func (e *AppError) Error() string {
-	return e.Message
-}
+// consistent error responses across the plugin. It is the SDK's AppError.
+type AppError = sdk.AppError
 
 // NewAppError creates an AppError with explicit code, message, and HTTP status.
 func NewAppError(code string, message string, statusCode int) *AppError {
-	return &AppError{Code: code, Message: message, StatusCode: statusCode}
+	return sdk.NewAppError(code, message, statusCode)
 }
 
 // NewValidationError creates a 400 validation error.
 func NewValidationError(message string) *AppError {
-	return NewAppError("VALIDATION_ERROR", message, 400)
+	return sdk.NewValidationError(message)
 }
 
 // NewNotFoundError creates a 404 not-found error for a specific resource.
 func NewNotFoundError(resource string, id string) *AppError {
-	return NewAppError("NOT_FOUND", fmt.Sprintf("%s %s not found", resource, id), 404)
+	return sdk.NewNotFoundError(resource, id)
 }
 
 // NewConflictError creates a 409 conflict error.
 func NewConflictError(message string) *AppError {
-	return NewAppError("CONFLICT", message, 409)
+	return sdk.NewConflictError(message)
 }
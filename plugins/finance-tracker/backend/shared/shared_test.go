@@ -239,3 +239,47 @@ func TestExtractIDFromPath_SingleSegment(t *testing.T) {
 		t.Errorf("expected code 'VALIDATION_ERROR', got '%s'", appErr.Code)
 	}
 }
+
+// --- SanitizeCell tests ---
+
+func TestSanitizeCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Groceries", "Groceries"},
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+34 600", "'+34 600"},
+		{"-5", "'-5"},
+		{"@cmd", "'@cmd"},
+		{"\tindented", "'\tindented"},
+		{"\rreturn", "'\rreturn"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeCell(tt.value); got != tt.want {
+			t.Errorf("SanitizeCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// --- RoundCents tests ---
+
+func TestRoundCents(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   float64
+	}{
+		{0, 0},
+		{0.1 + 0.2, 0.3},
+		{12.345, 12.35},
+		{-12.345, -12.35},
+		{99.994, 99.99},
+	}
+	for _, tt := range tests {
+		if got := RoundCents(tt.amount); got != tt.want {
+			t.Errorf("RoundCents(%v) = %v, want %v", tt.amount, got, tt.want)
+		}
+	}
+}
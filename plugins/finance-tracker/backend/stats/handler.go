@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Handler routes quick stats API requests to the service layer.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a Handler wired to the stats service.
+func NewHandler(db *sql.DB) *Handler {
+	return &Handler{service: NewService(db)}
+}
+
+// Handle dispatches the request to the correct stats handler.
+func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	switch {
+	case req.Method == "GET" && req.Path == "/stats/today":
+		return h.today(req)
+	default:
+		return shared.JSONError(shared.NewAppError("NOT_FOUND", "route not found", 404))
+	}
+}
+
+// today serves GET /stats/today. Widgets may pass ?date=YYYY-MM-DD so "today"
+// follows the device's local day rather than the server's.
+func (h *Handler) today(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	day := time.Now()
+	if date := req.Query["date"]; date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return shared.JSONError(shared.NewValidationError("date must be in YYYY-MM-DD format"))
+		}
+		day = parsed
+	}
+
+	stats, appErr := h.service.Today(day)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, stats)
+}
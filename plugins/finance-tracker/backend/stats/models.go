@@ -0,0 +1,10 @@
+package stats
+
+// TodayStats is the compact spending snapshot served to home-screen widgets.
+// BudgetRemaining is nil when no global budget applies to the current month.
+type TodayStats struct {
+	Date            string   `json:"date"`
+	Today           float64  `json:"today"`
+	Week            float64  `json:"week"`
+	BudgetRemaining *float64 `json:"budget_remaining"`
+}
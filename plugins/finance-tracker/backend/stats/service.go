@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
+)
+
+// Service computes the quick stats shown by mobile widgets. Like reports, it
+// runs aggregation queries directly against *sql.DB.
+type Service struct {
+	db *sql.DB
+}
+
+// NewService creates a new stats Service.
+func NewService(db *sql.DB) *Service {
+	return &Service{db: db}
+}
+
+// Today returns spending for the given day, for its week (Monday to that day),
+// and what is left of the month's global budget.
+func (s *Service) Today(day time.Time) (*TodayStats, *shared.AppError) {
+	date := day.Format("2006-01-02")
+	month := day.Format("2006-01")
+	weekStart := startOfWeek(day).Format("2006-01-02")
+
+	// Today, week-to-date and month-to-date spend in a single pass. Expenses
+	// dated after day, such as scheduled ones, are not spent yet.
+	var today, week, monthSpent float64
+	err := s.db.QueryRow(
+		`SELECT COALESCE(SUM(CASE WHEN date = ? THEN amount ELSE 0 END), 0),
+		        COALESCE(SUM(CASE WHEN date >= ? THEN amount ELSE 0 END), 0),
+		        COALESCE(SUM(CASE WHEN date LIKE ? THEN amount ELSE 0 END), 0)
+		 FROM transactions
+		 WHERE type = 'expense' AND date >= ? AND date <= ? AND status != 'void'`,
+		date, weekStart, month+"%", minDate(weekStart, month+"-01"), date,
+	).Scan(&today, &week, &monthSpent)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying spending: %v", err), 500)
+	}
+
+	// Global budget for the month, preferring a month-specific one over a recurring one.
+	var budgetAmount float64
+	var remaining *float64
+	err = s.db.QueryRow(
+		`SELECT amount FROM budgets
+		 WHERE (category IS NULL OR category = '')
+		   AND (month = ? OR month = '' OR month IS NULL)
+		 ORDER BY CASE WHEN month = ? THEN 0 ELSE 1 END
+		 LIMIT 1`,
+		month, month,
+	).Scan(&budgetAmount)
+	switch {
+	case err == nil:
+		left := budgetAmount - monthSpent
+		remaining = &left
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, shared.NewAppError("INTERNAL", fmt.Sprintf("querying budget: %v", err), 500)
+	}
+
+	return &TodayStats{
+		Date:            date,
+		Today:           today,
+		Week:            week,
+		BudgetRemaining: remaining,
+	}, nil
+}
+
+// startOfWeek returns the Monday of the week containing day.
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// minDate returns the earlier of two YYYY-MM-DD dates.
+func minDate(a, b string) string {
+	if a < b {
+		return a
+	}
+	return b
+}
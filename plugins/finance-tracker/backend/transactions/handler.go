@@ -3,6 +3,8 @@ package transactions
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +31,12 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return h.list(req)
 	case req.Method == "POST" && req.Path == "/transactions":
 		return h.create(req)
+	case req.Method == "POST" && req.Path == "/transactions/bulk":
+		return h.bulk(req)
+	case req.Method == "GET" && req.Path == "/transactions/duplicates":
+		return h.duplicates(req)
+	case req.Method == "POST" && req.Path == "/transactions/merge":
+		return h.merge(req)
 	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/transactions/"):
 		return h.update(req)
 	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/transactions/"):
@@ -38,6 +46,37 @@ func (h *Handler) Handle(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	}
 }
 
+// Search returns transactions matching query as results for the host's
+// global search. Descriptions are used as titles, falling back to the category.
+func (h *Handler) Search(query string) ([]sdk.SearchResult, error) {
+	transactions, err := h.service.Search(query, sdk.MaxSearchResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]sdk.SearchResult, 0, len(transactions))
+	for _, tx := range transactions {
+		title := tx.Description
+		if title == "" {
+			title = tx.Category
+		}
+
+		amount := fmt.Sprintf("%.2f", tx.Amount)
+		if tx.Type == "expense" {
+			amount = "-" + amount
+		}
+
+		results = append(results, sdk.SearchResult{
+			Type:    "transaction",
+			ID:      strconv.FormatInt(tx.ID, 10),
+			Title:   title,
+			Snippet: tx.Date + " · " + tx.Category + " · " + amount,
+			Link:    "/plugins/finance-tracker",
+		})
+	}
+	return results, nil
+}
+
 func (h *Handler) list(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	filter := &TransactionFilter{
 		Month:    req.Query["month"],
@@ -45,6 +84,7 @@ func (h *Handler) list(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		Category: req.Query["category"],
 		Tag:      req.Query["tag"],
 		Type:     req.Query["type"],
+		Status:   req.Query["status"],
 		Search:   req.Query["search"],
 	}
 
@@ -55,9 +95,13 @@ func (h *Handler) list(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		}
 	}
 
+	if filter.Status != "" && !IsValidStatus(filter.Status) {
+		return shared.JSONError(shared.NewValidationError("status must be 'pending', 'cleared', or 'void'"))
+	}
+
 	// Default month to current if no filters are provided.
 	if filter.Month == "" && filter.Account == "" && filter.Category == "" &&
-		filter.Tag == "" && filter.Type == "" && filter.Search == "" {
+		filter.Tag == "" && filter.Type == "" && filter.Status == "" && filter.Search == "" {
 		filter.Month = time.Now().Format("2006-01")
 	}
 
@@ -82,6 +126,57 @@ func (h *Handler) create(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	return shared.JSONSuccess(201, tx)
 }
 
+func (h *Handler) bulk(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input BulkInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	summary, deleted, appErr := h.service.Bulk(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	response, err := shared.JSONSuccess(200, summary)
+	if err != nil || deleted == nil || summary.Succeeded == 0 {
+		return response, err
+	}
+	return sdk.WithDeleted(response, DeletedKind, deleted)
+}
+
+func (h *Handler) duplicates(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	month := req.Query["month"]
+	if month != "" {
+		if _, err := time.Parse("2006-01", month); err != nil {
+			return shared.JSONError(shared.NewValidationError("month must be in YYYY-MM format"))
+		}
+	}
+
+	groups, appErr := h.service.Duplicates(month)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+	return shared.JSONSuccess(200, groups)
+}
+
+func (h *Handler) merge(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input MergeInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return shared.JSONError(shared.NewValidationError("invalid JSON body"))
+	}
+
+	result, deleted, appErr := h.service.Merge(&input)
+	if appErr != nil {
+		return shared.JSONError(appErr)
+	}
+
+	response, err := shared.JSONSuccess(200, result)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.WithDeleted(response, DeletedKind, deleted)
+}
+
 func (h *Handler) update(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	id, appErr := shared.ExtractIDFromPath(req.Path)
 	if appErr != nil {
@@ -107,9 +202,20 @@ func (h *Handler) delete(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 		return shared.JSONError(appErr)
 	}
 
-	if appErr := h.service.Delete(id); appErr != nil {
+	deleted, appErr := h.service.Delete(id)
+	if appErr != nil {
 		return shared.JSONError(appErr)
 	}
 
-	return shared.JSONSuccess(200, map[string]interface{}{"deleted": id})
+	response, err := shared.JSONSuccess(200, map[string]interface{}{"deleted": id})
+	if err != nil {
+		return nil, err
+	}
+	return sdk.WithDeleted(response, DeletedKind, deleted)
+}
+
+// RestoreDeleted puts back the transactions removed by DELETE
+// /transactions/{id} or a bulk delete.
+func (h *Handler) RestoreDeleted(data []byte) error {
+	return h.service.Restore(data)
 }
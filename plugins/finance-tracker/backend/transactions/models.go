@@ -12,6 +12,7 @@ type Transaction struct {
 	Date                string  `json:"date"`
 	IsRecurringInstance bool    `json:"is_recurring_instance"`
 	RecurringRuleID     *int64  `json:"recurring_rule_id,omitempty"`
+	Status              string  `json:"status"`
 	Tags                []Tag   `json:"tags"`
 	CreatedAt           string  `json:"created_at"`
 }
@@ -30,6 +31,7 @@ type TransactionFilter struct {
 	Category string
 	Tag      string
 	Type     string
+	Status   string
 	Search   string
 }
 
@@ -42,10 +44,12 @@ type CreateTransactionInput struct {
 	Category      string  `json:"category"`
 	Description   string  `json:"description"`
 	Date          string  `json:"date"`
+	Status        string  `json:"status"`
 	TagIDs        []int64 `json:"tag_ids"`
 }
 
 // UpdateTransactionInput holds the validated input for updating a transaction.
+// An empty Status keeps the transaction's current one.
 type UpdateTransactionInput struct {
 	Amount        float64 `json:"amount"`
 	Type          string  `json:"type"`
@@ -54,9 +58,94 @@ type UpdateTransactionInput struct {
 	Category      string  `json:"category"`
 	Description   string  `json:"description"`
 	Date          string  `json:"date"`
+	Status        string  `json:"status"`
 	TagIDs        []int64 `json:"tag_ids"`
 }
 
+// Transaction statuses. A pending transaction has not reached the account
+// yet and counts in its current balance but not its cleared one; a void one
+// was cancelled and counts in no balance or report.
+const (
+	StatusPending = "pending"
+	StatusCleared = "cleared"
+	StatusVoid    = "void"
+)
+
+// DeletedKind names a deleted transaction handed back to RestoreDeleted.
+const DeletedKind = "transaction"
+
+// Bulk actions accepted by POST /transactions/bulk.
+const (
+	BulkCreate       = "create"
+	BulkDelete       = "delete"
+	BulkRecategorize = "recategorize"
+)
+
+// Bulk item statuses.
+const (
+	BulkCreated = "created"
+	BulkDeleted = "deleted"
+	BulkUpdated = "updated"
+	BulkFailed  = "error"
+)
+
+// BulkInput is the body of POST /transactions/bulk. Create inserts
+// Transactions; delete removes IDs; recategorize sets Category and/or TagIDs
+// on every transaction in IDs. A missing tag_ids leaves tags unchanged, while
+// an empty list clears them.
+type BulkInput struct {
+	Action       string                   `json:"action"`
+	Transactions []CreateTransactionInput `json:"transactions"`
+	IDs          []int64                  `json:"ids"`
+	Category     *string                  `json:"category"`
+	TagIDs       []int64                  `json:"tag_ids"`
+}
+
+// BulkResult reports what happened to the item at Index in the request.
+type BulkResult struct {
+	Index  int    `json:"index"`
+	ID     int64  `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkSummary is the response of POST /transactions/bulk.
+type BulkSummary struct {
+	Action    string       `json:"action"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []BulkResult `json:"results"`
+}
+
+// Duplicate detection. Two transactions are likely duplicates when they have
+// the same type, accounts and amount, dates at most DuplicateDateWindow days
+// apart and descriptions at least DuplicateSimilarity alike.
+const (
+	DuplicateDateWindow = 1
+	DuplicateSimilarity = 0.5
+)
+
+// DuplicateGroup is a set of transactions that look like the same one
+// recorded more than once, oldest first. Similarity is the lowest of the
+// description similarities that put them together, from 0 to 1.
+type DuplicateGroup struct {
+	Similarity   float64       `json:"similarity"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// MergeInput is the body of POST /transactions/merge. The transactions in
+// IDs are removed and their tags added to the one in KeepID.
+type MergeInput struct {
+	KeepID int64   `json:"keep_id"`
+	IDs    []int64 `json:"ids"`
+}
+
+// MergeResult is the response of POST /transactions/merge.
+type MergeResult struct {
+	Transaction *Transaction `json:"transaction"`
+	Merged      []int64      `json:"merged"`
+}
+
 // validTransactionTypes defines the allowed transaction type values.
 var validTransactionTypes = map[string]bool{
 	"income":   true,
@@ -68,3 +157,15 @@ var validTransactionTypes = map[string]bool{
 func IsValidTransactionType(txType string) bool {
 	return validTransactionTypes[txType]
 }
+
+// validStatuses defines the allowed transaction status values.
+var validStatuses = map[string]bool{
+	StatusPending: true,
+	StatusCleared: true,
+	StatusVoid:    true,
+}
+
+// IsValidStatus checks whether a given status string is allowed.
+func IsValidStatus(status string) bool {
+	return validStatuses[status]
+}
@@ -3,7 +3,9 @@ package transactions
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/alvarotorresc/cortex/pkg/sdk"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
 
@@ -21,7 +23,7 @@ func NewRepository(db *sql.DB) *Repository {
 // Builds WHERE clauses dynamically based on non-empty filter fields.
 func (r *Repository) List(filter *TransactionFilter) ([]Transaction, error) {
 	query := `SELECT id, amount, type, account_id, dest_account_id, category, description, date,
-	          is_recurring_instance, recurring_rule_id, created_at
+	          is_recurring_instance, recurring_rule_id, created_at, status
 	          FROM transactions WHERE 1=1`
 	args := []interface{}{}
 
@@ -41,6 +43,10 @@ func (r *Repository) List(filter *TransactionFilter) ([]Transaction, error) {
 		query += " AND type = ?"
 		args = append(args, filter.Type)
 	}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
 	if filter.Search != "" {
 		query += " AND (description LIKE ? OR category LIKE ?)"
 		args = append(args, "%"+filter.Search+"%", "%"+filter.Search+"%")
@@ -75,6 +81,32 @@ func (r *Repository) List(filter *TransactionFilter) ([]Transaction, error) {
 	return transactions, nil
 }
 
+// Search returns up to limit transactions whose description, category or tag
+// names contain query, most recent first. LIKE wildcards in query match literally.
+func (r *Repository) Search(query string, limit int) ([]Transaction, error) {
+	pattern := "%" + escapeLike(query) + "%"
+	rows, err := r.db.Query(
+		`SELECT id, amount, type, account_id, dest_account_id, category, description, date,
+		 is_recurring_instance, recurring_rule_id, created_at, status
+		 FROM transactions
+		 WHERE description LIKE ? ESCAPE '\' OR category LIKE ? ESCAPE '\'
+		    OR id IN (
+		       SELECT tt.transaction_id FROM transaction_tags tt
+		       INNER JOIN tags t ON t.id = tt.tag_id
+		       WHERE t.name LIKE ? ESCAPE '\'
+		    )
+		 ORDER BY date DESC, id DESC
+		 LIMIT ?`,
+		pattern, pattern, pattern, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching transactions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTransactions(rows)
+}
+
 // GetByID returns a single transaction by its ID.
 func (r *Repository) GetByID(id int64) (*Transaction, *shared.AppError) {
 	var tx Transaction
@@ -84,12 +116,12 @@ func (r *Repository) GetByID(id int64) (*Transaction, *shared.AppError) {
 
 	err := r.db.QueryRow(
 		`SELECT id, amount, type, account_id, dest_account_id, category, description, date,
-		 is_recurring_instance, recurring_rule_id, created_at
+		 is_recurring_instance, recurring_rule_id, created_at, status
 		 FROM transactions WHERE id = ?`, id,
 	).Scan(
 		&tx.ID, &tx.Amount, &tx.Type, &tx.AccountID, &destAccountID,
 		&tx.Category, &tx.Description, &tx.Date,
-		&isRecurring, &recurringRuleID, &tx.CreatedAt,
+		&isRecurring, &recurringRuleID, &tx.CreatedAt, &tx.Status,
 	)
 	if err == sql.ErrNoRows {
 		return nil, shared.NewNotFoundError("transaction", fmt.Sprintf("%d", id))
@@ -123,16 +155,29 @@ func (r *Repository) Create(input *CreateTransactionInput) (int64, error) {
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	id, err := insertTransaction(tx, input)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return id, nil
+}
+
+// insertTransaction inserts a transaction and its tag links within tx.
+func insertTransaction(tx *sql.Tx, input *CreateTransactionInput) (int64, error) {
 	var destAccountID interface{}
 	if input.DestAccountID != nil {
 		destAccountID = *input.DestAccountID
 	}
 
 	result, err := tx.Exec(
-		`INSERT INTO transactions (amount, type, account_id, dest_account_id, category, description, date)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO transactions (amount, type, account_id, dest_account_id, category, description, date, status)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 		input.Amount, input.Type, *input.AccountID, destAccountID,
-		input.Category, input.Description, input.Date,
+		input.Category, input.Description, input.Date, input.Status,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("inserting transaction: %w", err)
@@ -151,10 +196,6 @@ func (r *Repository) Create(input *CreateTransactionInput) (int64, error) {
 			return 0, fmt.Errorf("inserting transaction tag: %w", err)
 		}
 	}
-
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("committing transaction: %w", err)
-	}
 	return id, nil
 }
 
@@ -178,10 +219,10 @@ func (r *Repository) Update(id int64, input *UpdateTransactionInput) error {
 
 	result, err := tx.Exec(
 		`UPDATE transactions SET amount = ?, type = ?, account_id = ?, dest_account_id = ?,
-		 category = ?, description = ?, date = ?
+		 category = ?, description = ?, date = ?, status = COALESCE(NULLIF(?, ''), status)
 		 WHERE id = ?`,
 		input.Amount, input.Type, accountID, destAccountID,
-		input.Category, input.Description, input.Date, id,
+		input.Category, input.Description, input.Date, input.Status, id,
 	)
 	if err != nil {
 		return fmt.Errorf("updating transaction: %w", err)
@@ -208,20 +249,304 @@ func (r *Repository) Update(id int64, input *UpdateTransactionInput) error {
 	return tx.Commit()
 }
 
-// Delete removes a transaction by its ID.
-func (r *Repository) Delete(id int64) error {
-	result, err := r.db.Exec("DELETE FROM transactions WHERE id = ?", id)
+// Delete removes a transaction by its ID and returns a copy of it, with its
+// tags and round-up, from which Restore can put it back.
+func (r *Repository) Delete(id int64) ([]sdk.DeletedRows, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("deleting transaction: %w", err)
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	tables := []struct{ table, where string }{
+		{"transactions", "id = ?"},
+		{"transaction_tags", "transaction_id = ?"},
+		{"roundups", "expense_id = ?"},
+	}
+	deleted := make([]sdk.DeletedRows, 0, len(tables))
+	for _, table := range tables {
+		snapshot, err := sdk.SnapshotRows(tx, table.table, table.where, id)
+		if err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, snapshot)
+	}
+
+	result, err := tx.Exec("DELETE FROM transactions WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting transaction: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return shared.NewNotFoundError("transaction", fmt.Sprintf("%d", id))
+		return nil, shared.NewNotFoundError("transaction", fmt.Sprintf("%d", id))
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// Restore inserts back the transactions removed by Delete or DeleteMany, with
+// their original IDs. Tags deleted since are left off.
+func (r *Repository) Restore(deleted []sdk.DeletedRows) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := sdk.RestoreRows(tx, deleted); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
 	}
 	return nil
 }
 
+// duplicatePair is two transactions that could be duplicates, before their
+// descriptions are compared.
+type duplicatePair struct {
+	first, second                       int64
+	firstDescription, secondDescription string
+}
+
+// ListDuplicateCandidates returns the pairs of transactions, other than void
+// ones, with the same type, accounts and amount and dates at most
+// DuplicateDateWindow days apart. With a month (YYYY-MM), only pairs with a
+// transaction in it are returned.
+func (r *Repository) ListDuplicateCandidates(month string) ([]duplicatePair, error) {
+	query := `SELECT a.id, a.description, b.id, b.description
+	          FROM transactions a
+	          INNER JOIN transactions b
+	            ON b.id > a.id AND b.amount = a.amount AND b.type = a.type
+	           AND b.account_id = a.account_id AND b.dest_account_id IS a.dest_account_id
+	           AND abs(julianday(b.date) - julianday(a.date)) <= ?
+	          WHERE a.status != 'void' AND b.status != 'void'`
+	args := []interface{}{DuplicateDateWindow}
+	if month != "" {
+		query += " AND (a.date LIKE ? OR b.date LIKE ?)"
+		args = append(args, month+"%", month+"%")
+	}
+	query += " ORDER BY a.id, b.id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying duplicate candidates: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := make([]duplicatePair, 0)
+	for rows.Next() {
+		var pair duplicatePair
+		if err := rows.Scan(&pair.first, &pair.firstDescription, &pair.second, &pair.secondDescription); err != nil {
+			return nil, fmt.Errorf("scanning duplicate candidate: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating duplicate candidates: %w", err)
+	}
+	return pairs, nil
+}
+
+// Merge removes the transactions in ids and adds their tags to keepID in one
+// DB transaction, returning a copy of what it removed from which Restore can
+// put it back. Round-ups of the removed expenses go with them.
+func (r *Repository) Merge(keepID int64, ids []int64) ([]sdk.DeletedRows, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, id := range append([]int64{keepID}, ids...) {
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM transactions WHERE id = ?", id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("checking transaction %d: %w", id, err)
+		}
+		if exists == 0 {
+			return nil, shared.NewNotFoundError("transaction", fmt.Sprintf("%d", id))
+		}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	tables := []struct{ table, where string }{
+		{"transactions", "id IN (" + placeholders + ")"},
+		{"transaction_tags", "transaction_id IN (" + placeholders + ")"},
+		{"roundups", "expense_id IN (" + placeholders + ")"},
+	}
+	deleted := make([]sdk.DeletedRows, 0, len(tables))
+	for _, table := range tables {
+		snapshot, err := sdk.SnapshotRows(tx, table.table, table.where, args...)
+		if err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, snapshot)
+	}
+
+	if _, err := tx.Exec(
+		`INSERT OR IGNORE INTO transaction_tags (transaction_id, tag_id)
+		 SELECT ?, tag_id FROM transaction_tags WHERE transaction_id IN (`+placeholders+`)`,
+		append([]interface{}{keepID}, args...)...,
+	); err != nil {
+		return nil, fmt.Errorf("moving merged tags: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM transactions WHERE id IN ("+placeholders+")", args...); err != nil {
+		return nil, fmt.Errorf("deleting merged transactions: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// CreateMany inserts inputs[i] for every result still pending (with no
+// status) in one DB transaction, recording the new IDs in results.
+func (r *Repository) CreateMany(inputs []CreateTransactionInput, results []BulkResult) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i := range results {
+		if results[i].Status != "" {
+			continue
+		}
+		id, err := insertTransaction(tx, &inputs[i])
+		if err != nil {
+			return err
+		}
+		results[i].ID = id
+		results[i].Status = BulkCreated
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteMany removes the transactions with the given IDs in one DB
+// transaction and returns a copy of what it removed, for Restore. IDs that do
+// not exist are reported as failed in results.
+func (r *Repository) DeleteMany(ids []int64, results []BulkResult) ([]sdk.DeletedRows, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	tables := []struct{ table, where string }{
+		{"transactions", "id IN (" + placeholders + ")"},
+		{"transaction_tags", "transaction_id IN (" + placeholders + ")"},
+		{"roundups", "expense_id IN (" + placeholders + ")"},
+	}
+	deleted := make([]sdk.DeletedRows, 0, len(tables))
+	for _, table := range tables {
+		snapshot, err := sdk.SnapshotRows(tx, table.table, table.where, args...)
+		if err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, snapshot)
+	}
+
+	for i, id := range ids {
+		result, err := tx.Exec("DELETE FROM transactions WHERE id = ?", id)
+		if err != nil {
+			return nil, fmt.Errorf("deleting transaction %d: %w", id, err)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			results[i].Status = BulkFailed
+			results[i].Error = "transaction not found"
+			continue
+		}
+		results[i].ID = id
+		results[i].Status = BulkDeleted
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// RecategorizeMany sets the category (when not nil) and replaces the tags
+// (when tagIDs is not nil) of the transactions with the given IDs in one DB
+// transaction. IDs that do not exist are reported as failed in results.
+func (r *Repository) RecategorizeMany(ids []int64, category *string, tagIDs []int64, results []BulkResult) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, id := range ids {
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM transactions WHERE id = ?", id).Scan(&exists); err != nil {
+			return fmt.Errorf("checking transaction %d: %w", id, err)
+		}
+		if exists == 0 {
+			results[i].Status = BulkFailed
+			results[i].Error = "transaction not found"
+			continue
+		}
+
+		if category != nil {
+			if _, err := tx.Exec("UPDATE transactions SET category = ? WHERE id = ?", *category, id); err != nil {
+				return fmt.Errorf("updating category of transaction %d: %w", id, err)
+			}
+		}
+		if tagIDs != nil {
+			if _, err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id = ?", id); err != nil {
+				return fmt.Errorf("clearing transaction tags: %w", err)
+			}
+			for _, tagID := range tagIDs {
+				if _, err := tx.Exec(
+					"INSERT OR IGNORE INTO transaction_tags (transaction_id, tag_id) VALUES (?, ?)",
+					id, tagID,
+				); err != nil {
+					return fmt.Errorf("inserting transaction tag: %w", err)
+				}
+			}
+		}
+		results[i].ID = id
+		results[i].Status = BulkUpdated
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// FindMissingTag returns the first of tagIDs with no tag, if any.
+func (r *Repository) FindMissingTag(tagIDs []int64) (int64, bool, error) {
+	for _, tagID := range tagIDs {
+		var count int
+		if err := r.db.QueryRow("SELECT COUNT(*) FROM tags WHERE id = ?", tagID).Scan(&count); err != nil {
+			return 0, false, fmt.Errorf("checking tag existence: %w", err)
+		}
+		if count == 0 {
+			return tagID, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
 // AccountExists checks whether an account with the given ID exists.
 func (r *Repository) AccountExists(id int64) (bool, error) {
 	var count int
@@ -288,7 +613,7 @@ func scanTransactions(rows *sql.Rows) ([]Transaction, error) {
 		if err := rows.Scan(
 			&tx.ID, &tx.Amount, &tx.Type, &tx.AccountID, &destAccountID,
 			&tx.Category, &tx.Description, &tx.Date,
-			&isRecurring, &recurringRuleID, &tx.CreatedAt,
+			&isRecurring, &recurringRuleID, &tx.CreatedAt, &tx.Status,
 		); err != nil {
 			return nil, fmt.Errorf("scanning transaction row: %w", err)
 		}
@@ -308,3 +633,11 @@ func scanTransactions(rows *sql.Rows) ([]Transaction, error) {
 	}
 	return transactions, nil
 }
+
+// escapeLike escapes LIKE wildcard characters (%, _) in a search string.
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `%`, `\%`)
+	s = strings.ReplaceAll(s, `_`, `\_`)
+	return s
+}
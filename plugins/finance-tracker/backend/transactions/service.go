@@ -2,12 +2,19 @@ package transactions
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/alvarotorresc/cortex/pkg/sdk"
 	"github.com/alvarotorresc/cortex/plugins/finance-tracker/backend/shared"
 )
 
+// maxBulkItems caps how many transactions or IDs one bulk request may carry.
+const maxBulkItems = 1000
+
 // Service contains the business logic for transaction operations.
 type Service struct {
 	repo *Repository
@@ -23,12 +30,39 @@ func (s *Service) List(filter *TransactionFilter) ([]Transaction, error) {
 	return s.repo.List(filter)
 }
 
+// Search returns up to limit transactions matching query, most recent first.
+func (s *Service) Search(query string, limit int) ([]Transaction, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []Transaction{}, nil
+	}
+	return s.repo.Search(query, limit)
+}
+
 // Create validates input, applies defaults, inserts the transaction, and links tags.
 func (s *Service) Create(input *CreateTransactionInput) (*Transaction, *shared.AppError) {
-	if appErr := validateCreateInput(input); appErr != nil {
+	if appErr := s.prepareCreate(input); appErr != nil {
 		return nil, appErr
 	}
 
+	id, err := s.repo.Create(input)
+	if err != nil {
+		return nil, shared.NewAppError("INTERNAL", "failed to create transaction", 500)
+	}
+
+	tx, appErr := s.repo.GetByID(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return tx, nil
+}
+
+// prepareCreate validates input and applies the defaults of a new transaction.
+func (s *Service) prepareCreate(input *CreateTransactionInput) *shared.AppError {
+	if appErr := validateCreateInput(input); appErr != nil {
+		return appErr
+	}
+
 	// Default account_id to 1 (backward compatibility with v1).
 	if input.AccountID == nil {
 		defaultID := int64(1)
@@ -40,28 +74,258 @@ func (s *Service) Create(input *CreateTransactionInput) (*Transaction, *shared.A
 		input.Date = time.Now().Format("2006-01-02")
 	}
 
+	// Default status to cleared.
+	if input.Status == "" {
+		input.Status = StatusCleared
+	}
+
 	// Validate account exists.
 	if appErr := s.validateAccountExists(*input.AccountID); appErr != nil {
-		return nil, appErr
+		return appErr
 	}
 
 	// For transfers, validate dest_account_id.
 	if input.Type == "transfer" {
 		if appErr := s.validateAccountExists(*input.DestAccountID); appErr != nil {
-			return nil, appErr
+			return appErr
+		}
+	}
+	return nil
+}
+
+// Bulk applies one action to many transactions inside a single SQL
+// transaction. Items that are invalid or do not exist are reported in the
+// results and do not stop the rest; a database error rolls back the whole
+// batch. A delete also returns a copy of what it removed, for Restore.
+func (s *Service) Bulk(input *BulkInput) (*BulkSummary, []sdk.DeletedRows, *shared.AppError) {
+	var count int
+	switch input.Action {
+	case BulkCreate:
+		count = len(input.Transactions)
+	case BulkDelete, BulkRecategorize:
+		count = len(input.IDs)
+	default:
+		return nil, nil, shared.NewValidationError("action must be 'create', 'delete', or 'recategorize'")
+	}
+	if count == 0 {
+		return nil, nil, shared.NewValidationError("at least one item is required")
+	}
+	if count > maxBulkItems {
+		return nil, nil, shared.NewValidationError(fmt.Sprintf("at most %d items can be processed at once", maxBulkItems))
+	}
+
+	results := make([]BulkResult, count)
+	for i := range results {
+		results[i].Index = i
+	}
+
+	var deleted []sdk.DeletedRows
+	var err error
+	switch input.Action {
+	case BulkCreate:
+		for i := range input.Transactions {
+			if appErr := s.prepareCreate(&input.Transactions[i]); appErr != nil {
+				results[i].Status = BulkFailed
+				results[i].Error = appErr.Message
+				continue
+			}
+			if appErr := s.validateTagsExist(input.Transactions[i].TagIDs); appErr != nil {
+				results[i].Status = BulkFailed
+				results[i].Error = appErr.Message
+			}
+		}
+		err = s.repo.CreateMany(input.Transactions, results)
+	case BulkDelete:
+		deleted, err = s.repo.DeleteMany(input.IDs, results)
+	case BulkRecategorize:
+		if input.Category == nil && input.TagIDs == nil {
+			return nil, nil, shared.NewValidationError("category or tag_ids is required")
+		}
+		if input.Category != nil && strings.TrimSpace(*input.Category) == "" {
+			return nil, nil, shared.NewValidationError("category must not be empty")
 		}
+		if appErr := s.validateTagsExist(input.TagIDs); appErr != nil {
+			return nil, nil, appErr
+		}
+		err = s.repo.RecategorizeMany(input.IDs, input.Category, input.TagIDs, results)
+	}
+	if err != nil {
+		return nil, nil, shared.NewAppError("INTERNAL", fmt.Sprintf("bulk %s failed: %v", input.Action, err), 500)
 	}
 
-	id, err := s.repo.Create(input)
+	summary := &BulkSummary{Action: input.Action, Results: results}
+	for _, result := range results {
+		if result.Status == BulkFailed {
+			summary.Failed++
+		} else {
+			summary.Succeeded++
+		}
+	}
+	return summary, deleted, nil
+}
+
+// Duplicates returns the groups of likely duplicate transactions, optionally
+// those with a transaction in month (YYYY-MM), oldest group first. Pairs of
+// candidates with similar descriptions are joined, so a group may hold
+// transactions more than DuplicateDateWindow days apart through a third one.
+func (s *Service) Duplicates(month string) ([]DuplicateGroup, *shared.AppError) {
+	pairs, err := s.repo.ListDuplicateCandidates(month)
 	if err != nil {
-		return nil, shared.NewAppError("INTERNAL", "failed to create transaction", 500)
+		return nil, shared.NewAppError("INTERNAL", "failed to find duplicates", 500)
 	}
 
-	tx, appErr := s.repo.GetByID(id)
+	// Union-find over the pairs alike enough, keyed by the lowest ID.
+	parent := make(map[int64]int64)
+	var find func(id int64) int64
+	find = func(id int64) int64 {
+		if parent[id] == id {
+			return id
+		}
+		parent[id] = find(parent[id])
+		return parent[id]
+	}
+	similarity := make(map[int64]float64)
+	for _, pair := range pairs {
+		score := descriptionSimilarity(pair.firstDescription, pair.secondDescription)
+		if score < DuplicateSimilarity {
+			continue
+		}
+		for _, id := range []int64{pair.first, pair.second} {
+			if _, ok := parent[id]; !ok {
+				parent[id] = id
+				similarity[id] = 1
+			}
+		}
+		a, b := find(pair.first), find(pair.second)
+		if a > b {
+			a, b = b, a
+		}
+		if a != b {
+			parent[b] = a
+			similarity[a] = math.Min(similarity[a], similarity[b])
+		}
+		similarity[a] = math.Min(similarity[a], score)
+	}
+
+	members := make(map[int64][]int64)
+	roots := make([]int64, 0)
+	ids := make([]int64, 0, len(parent))
+	for id := range parent {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		root := find(id)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], id)
+	}
+
+	groups := make([]DuplicateGroup, 0, len(roots))
+	for _, root := range roots {
+		group := DuplicateGroup{Similarity: math.Round(similarity[root]*100) / 100}
+		for _, id := range members[root] {
+			tx, appErr := s.repo.GetByID(id)
+			if appErr != nil {
+				return nil, appErr
+			}
+			group.Transactions = append(group.Transactions, *tx)
+		}
+		sort.SliceStable(group.Transactions, func(i, j int) bool {
+			return group.Transactions[i].Date < group.Transactions[j].Date
+		})
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Transactions[0].Date < groups[j].Transactions[0].Date
+	})
+	return groups, nil
+}
+
+// Merge collapses duplicates into the transaction in input.KeepID: the others
+// are removed and their tags added to it. It returns the kept transaction and
+// a copy of what it removed, for Restore.
+func (s *Service) Merge(input *MergeInput) (*MergeResult, []sdk.DeletedRows, *shared.AppError) {
+	if input.KeepID <= 0 {
+		return nil, nil, shared.NewValidationError("keep_id is required")
+	}
+	if len(input.IDs) == 0 {
+		return nil, nil, shared.NewValidationError("at least one id to merge is required")
+	}
+	if len(input.IDs) > maxBulkItems {
+		return nil, nil, shared.NewValidationError(fmt.Sprintf("at most %d transactions can be merged at once", maxBulkItems))
+	}
+	seen := make(map[int64]bool, len(input.IDs))
+	ids := make([]int64, 0, len(input.IDs))
+	for _, id := range input.IDs {
+		if id == input.KeepID {
+			return nil, nil, shared.NewValidationError("ids must not include keep_id")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	deleted, err := s.repo.Merge(input.KeepID, ids)
+	if err != nil {
+		if appErr, ok := err.(*shared.AppError); ok {
+			return nil, nil, appErr
+		}
+		return nil, nil, shared.NewAppError("INTERNAL", "failed to merge transactions", 500)
+	}
+
+	tx, appErr := s.repo.GetByID(input.KeepID)
 	if appErr != nil {
-		return nil, appErr
+		return nil, nil, appErr
 	}
-	return tx, nil
+	return &MergeResult{Transaction: tx, Merged: ids}, deleted, nil
+}
+
+// descriptionSimilarity compares two descriptions by the trigrams of their
+// normalized words, from 0 (nothing in common) to 1 (the same).
+func descriptionSimilarity(a, b string) float64 {
+	a, b = normalizeDescription(a), normalizeDescription(b)
+	if a == b {
+		return 1
+	}
+	first, second := trigrams(a), trigrams(b)
+	if len(first) == 0 || len(second) == 0 {
+		return 0
+	}
+	common := 0
+	for trigram := range first {
+		if second[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(first)+len(second)-common)
+}
+
+// normalizeDescription lowercases a description and keeps only its letters
+// and digits, one space between words.
+func normalizeDescription(description string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, description)
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+// trigrams returns the three-rune sequences of every word, padded so that the
+// start and end of words count.
+func trigrams(normalized string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(normalized) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
 }
 
 // Update validates input, modifies the transaction, and updates tag links.
@@ -112,15 +376,27 @@ func (s *Service) Update(id int64, input *UpdateTransactionInput) (*Transaction,
 	return tx, nil
 }
 
-// Delete removes a transaction by its ID.
-func (s *Service) Delete(id int64) *shared.AppError {
-	if err := s.repo.Delete(id); err != nil {
+// Delete removes a transaction by its ID and returns a copy of what it
+// removed, for Restore.
+func (s *Service) Delete(id int64) ([]sdk.DeletedRows, *shared.AppError) {
+	deleted, err := s.repo.Delete(id)
+	if err != nil {
 		if appErr, ok := err.(*shared.AppError); ok {
-			return appErr
+			return nil, appErr
 		}
-		return shared.NewAppError("INTERNAL", "failed to delete transaction", 500)
+		return nil, shared.NewAppError("INTERNAL", "failed to delete transaction", 500)
 	}
-	return nil
+	return deleted, nil
+}
+
+// Restore puts back what Delete or a bulk delete removed. It returns an error
+// wrapping sdk.ErrRestoreConflict if its ID has been taken again.
+func (s *Service) Restore(data []byte) error {
+	deleted, err := sdk.DecodeDeletedRows(data)
+	if err != nil {
+		return err
+	}
+	return s.repo.Restore(deleted)
 }
 
 // validateAccountExists checks that an account with the given ID exists.
@@ -135,6 +411,18 @@ func (s *Service) validateAccountExists(accountID int64) *shared.AppError {
 	return nil
 }
 
+// validateTagsExist checks that every tag in tagIDs exists.
+func (s *Service) validateTagsExist(tagIDs []int64) *shared.AppError {
+	tagID, missing, err := s.repo.FindMissingTag(tagIDs)
+	if err != nil {
+		return shared.NewAppError("INTERNAL", "failed to check tags", 500)
+	}
+	if missing {
+		return shared.NewValidationError(fmt.Sprintf("tag %d not found", tagID))
+	}
+	return nil
+}
+
 // validateCreateInput checks that all required fields are present and valid.
 func validateCreateInput(input *CreateTransactionInput) *shared.AppError {
 	if input.Amount <= 0 {
@@ -154,6 +442,9 @@ func validateCreateInput(input *CreateTransactionInput) *shared.AppError {
 	if input.Type != "transfer" && strings.TrimSpace(input.Category) == "" {
 		return shared.NewValidationError("category is required")
 	}
+	if input.Status != "" && !IsValidStatus(input.Status) {
+		return shared.NewValidationError("status must be 'pending', 'cleared', or 'void'")
+	}
 	return nil
 }
 
@@ -176,5 +467,8 @@ func validateUpdateInput(input *UpdateTransactionInput) *shared.AppError {
 	if input.Type != "transfer" && strings.TrimSpace(input.Category) == "" {
 		return shared.NewValidationError("category is required")
 	}
+	if input.Status != "" && !IsValidStatus(input.Status) {
+		return shared.NewValidationError("status must be 'pending', 'cleared', or 'void'")
+	}
 	return nil
 }
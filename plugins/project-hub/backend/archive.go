@@ -0,0 +1,70 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// archivedAtColumn selects Project.ArchivedAt for the projects row aliased p.
+const archivedAtColumn = `(SELECT pa.archived_at FROM project_archive pa WHERE pa.project_id = p.id)`
+
+// notArchived matches the projects, aliased p, that are not archived.
+const notArchived = `NOT EXISTS (SELECT 1 FROM project_archive pa WHERE pa.project_id = p.id)`
+
+// includeArchived reports whether a request asks for archived projects too,
+// with ?include_archived=true.
+func includeArchived(req *sdk.APIRequest) bool {
+	return req.Query["include_archived"] == "true"
+}
+
+// archiveProject handles DELETE /projects/{slug}. The project and everything
+// attached to it stay in the database until it is deleted for good with
+// DELETE /projects/{slug}/permanent. Archiving an archived project keeps its
+// original archived_at.
+func (p *ProjectHubPlugin) archiveProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	slug := req.PathParams["slug"]
+
+	projectID, err := p.projectIDBySlug(slug)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.db.Exec("INSERT OR IGNORE INTO project_archive (project_id) VALUES (?)", projectID); err != nil {
+		return nil, fmt.Errorf("archiving project: %w", err)
+	}
+	var archivedAt string
+	if err := p.db.QueryRow("SELECT archived_at FROM project_archive WHERE project_id = ?", projectID).Scan(&archivedAt); err != nil {
+		return nil, fmt.Errorf("querying archived project: %w", err)
+	}
+
+	return sdk.Success(200, map[string]interface{}{"archived": slug, "archived_at": archivedAt})
+}
+
+// restoreProject handles POST /projects/{slug}/restore, bringing an archived
+// project back into listings.
+func (p *ProjectHubPlugin) restoreProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	slug := req.PathParams["slug"]
+
+	projectID, err := p.projectIDBySlug(slug)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.db.Exec("DELETE FROM project_archive WHERE project_id = ?", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("restoring project: %w", err)
+	}
+	if restored, _ := result.RowsAffected(); restored == 0 {
+		return sdk.Error(409, "NOT_ARCHIVED", "project is not archived")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"restored": slug})
+}
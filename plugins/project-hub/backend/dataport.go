@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// dataTables are the tables ExportData and ImportData move between
+// instances, in the order ImportData fills them: the ones other tables refer
+// to first.
+var dataTables = []string{
+	"projects",
+	"project_links",
+	"tags",
+	"project_tags",
+	"milestones",
+	"tasks",
+	"project_watches",
+	"changelog_entries",
+	"time_entries",
+	"project_relations",
+	"project_notes",
+	"project_note_revisions",
+	"stale_settings",
+	"project_attention",
+	"status_history",
+	"project_archive",
+}
+
+// retiredTables are tables older versions exported or snapshotted that
+// migrations have since dropped. Their rows are skipped on import and
+// restore.
+var retiredTables = map[string]bool{
+	"notifications": true,
+}
+
+// withoutRetiredTables returns snapshots without the rows of retiredTables.
+func withoutRetiredTables(snapshots []sdk.DeletedRows) []sdk.DeletedRows {
+	kept := make([]sdk.DeletedRows, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if !retiredTables[snapshot.Table] {
+			kept = append(kept, snapshot)
+		}
+	}
+	return kept
+}
+
+// ExportData returns every project with its milestones, notes, time entries
+// and relations for GET /api/plugins/project-hub/export.
+func (p *ProjectHubPlugin) ExportData() ([]byte, error) {
+	return sdk.ExportTables(p.db, dataTables...)
+}
+
+// ImportData replaces the plugin's data with an export from this or an older
+// version, all at once or not at all.
+func (p *ProjectHubPlugin) ImportData(fromVersion string, data []byte) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var snapshots []sdk.DeletedRows
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return fmt.Errorf("%w: decoding export: %v", sdk.ErrInvalidData, err)
+	}
+	data, err = json.Marshal(withoutRetiredTables(snapshots))
+	if err != nil {
+		return fmt.Errorf("marshaling export: %w", err)
+	}
+
+	if err := sdk.ImportTables(tx, data, dataTables...); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
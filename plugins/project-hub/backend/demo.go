@@ -0,0 +1,112 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// demoProjectSlug is the seeded project SeedDemo plans milestones for.
+const demoProjectSlug = "cortex"
+
+// demoTask is one task of a demo milestone; dueIn is days from today.
+type demoTask struct {
+	title  string
+	status string
+	dueIn  int
+}
+
+// demoMilestone is a milestone SeedDemo creates; startIn and dueIn are days from today.
+type demoMilestone struct {
+	name    string
+	status  string
+	startIn int
+	dueIn   int
+	tasks   []demoTask
+}
+
+var demoMilestones = []demoMilestone{
+	{
+		name: "Plugin SDK v1", status: "closed", startIn: -42, dueIn: -14,
+		tasks: []demoTask{
+			{title: "Stabilize the gRPC contract", status: "done", dueIn: -30},
+			{title: "Publish SDK docs", status: "done", dueIn: -16},
+		},
+	},
+	{
+		name: "Public beta", status: "open", startIn: -13, dueIn: 21,
+		tasks: []demoTask{
+			{title: "Write the install guide", status: "done", dueIn: -3},
+			{title: "Record a demo video", status: "in_progress", dueIn: 2},
+			{title: "Fix the dashboard layout on mobile", status: "todo", dueIn: 4},
+			{title: "Announce the beta", status: "todo", dueIn: 21},
+		},
+	},
+}
+
+// SeedDemo plans milestones and tasks for one of the projects the seed
+// migration created, spread around today so progress and the tasks-due widget
+// have something to show. A database that already has milestones is left
+// untouched.
+func (p *ProjectHubPlugin) SeedDemo() error {
+	var count int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM milestones").Scan(&count); err != nil {
+		return fmt.Errorf("counting milestones: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var projectID int64
+	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", demoProjectSlug).Scan(&projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("finding demo project: %w", err)
+	}
+
+	transaction, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	today := time.Now().UTC()
+	day := func(offset int) string {
+		return today.AddDate(0, 0, offset).Format("2006-01-02")
+	}
+
+	for milestoneOrder, milestone := range demoMilestones {
+		result, err := transaction.Exec(`
+			INSERT INTO milestones (project_id, name, status, start_date, due_date, sort_order)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, projectID, milestone.name, milestone.status, day(milestone.startIn), day(milestone.dueIn), milestoneOrder)
+		if err != nil {
+			return fmt.Errorf("creating demo milestone: %w", err)
+		}
+		milestoneID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("reading demo milestone ID: %w", err)
+		}
+
+		for taskOrder, task := range milestone.tasks {
+			var completedAt interface{}
+			if task.status == "done" {
+				completedAt = today.AddDate(0, 0, task.dueIn).Format("2006-01-02 15:04:05")
+			}
+			if _, err := transaction.Exec(`
+				INSERT INTO tasks (milestone_id, title, status, due_date, sort_order, completed_at)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, milestoneID, task.title, task.status, day(task.dueIn), taskOrder, completedAt); err != nil {
+				return fmt.Errorf("creating demo task: %w", err)
+			}
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// renderMarkdown renders the markdown subset notes support to HTML:
+// headings, paragraphs, bullet and numbered lists, block quotes, fenced code
+// blocks, horizontal rules, and inline code, bold, italic and links. The
+// source is HTML-escaped before any markup is added, so raw HTML in a note
+// is shown as text and never reaches the page; links are kept only for
+// http, https and mailto URLs.
+func renderMarkdown(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	var list string // "ul" or "ol" while a list is open.
+	var quote []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderInline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			out.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	flushQuote := func() {
+		if len(quote) > 0 {
+			out.WriteString("<blockquote><p>" + renderInline(strings.Join(quote, "\n")) + "</p></blockquote>\n")
+			quote = nil
+		}
+	}
+	flushAll := func() {
+		flushParagraph()
+		closeList()
+		flushQuote()
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flushAll()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if trimmed == "" {
+			flushAll()
+			continue
+		}
+
+		if match := headingPattern.FindStringSubmatch(trimmed); match != nil {
+			flushAll()
+			level := len(match[1])
+			out.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", level, renderInline(match[2]), level))
+			continue
+		}
+
+		if rulePattern.MatchString(trimmed) {
+			flushAll()
+			out.WriteString("<hr>\n")
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			flushParagraph()
+			closeList()
+			quote = append(quote, strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+			continue
+		}
+		flushQuote()
+
+		if match := bulletPattern.FindStringSubmatch(trimmed); match != nil {
+			flushParagraph()
+			openList(&out, &list, "ul")
+			out.WriteString("<li>" + renderInline(match[1]) + "</li>\n")
+			continue
+		}
+		if match := numberPattern.FindStringSubmatch(trimmed); match != nil {
+			flushParagraph()
+			openList(&out, &list, "ol")
+			out.WriteString("<li>" + renderInline(match[1]) + "</li>\n")
+			continue
+		}
+
+		closeList()
+		paragraph = append(paragraph, trimmed)
+	}
+	flushAll()
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	rulePattern    = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,})$`)
+	bulletPattern  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	numberPattern  = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+
+	linkPattern   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+)
+
+// openList starts a list of the given kind, closing a list of the other kind.
+func openList(out *strings.Builder, list *string, kind string) {
+	if *list == kind {
+		return
+	}
+	if *list != "" {
+		out.WriteString("</" + *list + ">\n")
+	}
+	out.WriteString("<" + kind + ">\n")
+	*list = kind
+}
+
+// renderInline escapes text and renders its inline markup. Text between
+// backticks is code and is left as written.
+func renderInline(text string) string {
+	parts := strings.Split(text, "`")
+	var out strings.Builder
+	for i, part := range parts {
+		// An unmatched trailing backtick is kept as a literal.
+		if i%2 == 1 && i < len(parts)-1 {
+			out.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			out.WriteString("`")
+		}
+		out.WriteString(renderEmphasis(html.EscapeString(part)))
+	}
+	return out.String()
+}
+
+// renderEmphasis renders links, bold and italic in already escaped text.
+func renderEmphasis(escaped string) string {
+	escaped = linkPattern.ReplaceAllStringFunc(escaped, func(match string) string {
+		parts := linkPattern.FindStringSubmatch(match)
+		label, target := parts[1], html.UnescapeString(parts[2])
+		if !isSafeLinkTarget(target) {
+			return label
+		}
+		// Emphasis markers in the URL are percent-encoded so the passes
+		// below leave the attribute alone.
+		href := strings.NewReplacer("*", "%2A", "_", "%5F").Replace(html.EscapeString(target))
+		return fmt.Sprintf(`<a href="%s" rel="noopener noreferrer">%s</a>`, href, label)
+	})
+	escaped = boldPattern.ReplaceAllString(escaped, "<strong>$1$2</strong>")
+	return italicPattern.ReplaceAllString(escaped, "<em>$1</em>")
+}
+
+// isSafeLinkTarget reports whether a link may be rendered as a link.
+func isSafeLinkTarget(target string) bool {
+	lower := strings.ToLower(target)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:")
+}
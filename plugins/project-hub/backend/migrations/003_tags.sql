@@ -18,8 +18,10 @@ CREATE TABLE IF NOT EXISTS project_tags (
 CREATE INDEX IF NOT EXISTS idx_project_tags_project_id ON project_tags(project_id);
 CREATE INDEX IF NOT EXISTS idx_project_tags_tag_id ON project_tags(tag_id);
 
--- Seed common technology tags with brand colors.
-INSERT OR IGNORE INTO tags (name, color) VALUES
+-- Seed common technology tags with brand colors. Only an empty tags table is
+-- seeded, so renamed, merged and deleted tags are not brought back on reload.
+INSERT OR IGNORE INTO tags (name, color)
+SELECT column1, column2 FROM (VALUES
     ('React', '#61DAFB'),
     ('React Native', '#61DAFB'),
     ('Ionic', '#3880FF'),
@@ -52,88 +54,106 @@ INSERT OR IGNORE INTO tags (name, color) VALUES
     ('Rust', '#CE422B'),
     ('WebSocket', '#010101'),
     ('Docker', '#2496ED'),
-    ('TBD', '#6B7280');
+    ('TBD', '#6B7280')
+) WHERE NOT EXISTS (SELECT 1 FROM tags);
 
 -- Migrate existing stack data into tags.
 -- For each project, split the stack field by comma and link to matching tags.
 -- This uses a recursive CTE to split comma-separated values.
+-- A project that already has tags is left as it is.
 
 -- Quedamos: React, Ionic, Capacitor, NestJS, Supabase
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'quedamos' AND t.name IN ('React', 'Ionic', 'Capacitor', 'NestJS', 'Supabase');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'quedamos' AND t.name IN ('React', 'Ionic', 'Capacitor', 'NestJS', 'Supabase');
 
 -- Sinherencia: Astro 5 -> Astro
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'sinherencia' AND t.name IN ('Astro');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'sinherencia' AND t.name IN ('Astro');
 
 -- Guitar App: SolidJS, Web Audio API
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'guitar-app' AND t.name IN ('SolidJS', 'Web Audio API');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'guitar-app' AND t.name IN ('SolidJS', 'Web Audio API');
 
 -- Cortex: Go, SvelteKit, gRPC, SQLite
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'cortex' AND t.name IN ('Go', 'SvelteKit', 'gRPC', 'SQLite');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'cortex' AND t.name IN ('Go', 'SvelteKit', 'gRPC', 'SQLite');
 
 -- Fogon: React Native (Expo), NestJS, Supabase -> React Native, Expo, NestJS, Supabase
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'fogon' AND t.name IN ('React Native', 'Expo', 'NestJS', 'Supabase');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'fogon' AND t.name IN ('React Native', 'Expo', 'NestJS', 'Supabase');
 
 -- Huellas: Next.js 15, React Native (Expo), NestJS, Supabase, PostGIS
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'huellas' AND t.name IN ('Next.js', 'React Native', 'Expo', 'NestJS', 'Supabase', 'PostGIS');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'huellas' AND t.name IN ('Next.js', 'React Native', 'Expo', 'NestJS', 'Supabase', 'PostGIS');
 
 -- Libroteca: TBD
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'libroteca' AND t.name IN ('TBD');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'libroteca' AND t.name IN ('TBD');
 
 -- create-astro-blog: Node.js CLI -> Node.js
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'create-astro-blog' AND t.name IN ('Node.js');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'create-astro-blog' AND t.name IN ('Node.js');
 
 -- PokeUtils: HTML, CSS, JavaScript
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'pokeutils' AND t.name IN ('HTML', 'CSS', 'JavaScript');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'pokeutils' AND t.name IN ('HTML', 'CSS', 'JavaScript');
 
 -- DevTools: Astro 5, TypeScript -> Astro, TypeScript
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'devtools' AND t.name IN ('Astro', 'TypeScript');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'devtools' AND t.name IN ('Astro', 'TypeScript');
 
 -- Clipboard Manager: Go, WebSocket
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'clipboard-manager' AND t.name IN ('Go', 'WebSocket');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'clipboard-manager' AND t.name IN ('Go', 'WebSocket');
 
 -- System Config Manager: Rust CLI -> Rust
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'system-config-manager' AND t.name IN ('Rust');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'system-config-manager' AND t.name IN ('Rust');
 
 -- Swiss Knife: Kotlin, Jetpack Compose, Material 3
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'swiss-knife' AND t.name IN ('Kotlin', 'Jetpack Compose', 'Material 3');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'swiss-knife' AND t.name IN ('Kotlin', 'Jetpack Compose', 'Material 3');
 
 -- IronLog: React Native (Expo), expo-sqlite -> React Native, Expo, SQLite
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'ironlog' AND t.name IN ('React Native', 'Expo', 'SQLite');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'ironlog' AND t.name IN ('React Native', 'Expo', 'SQLite');
 
 -- Finance App: Tauri 2, Svelte 5, SQLite -> Tauri, Svelte, SQLite
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'finance-app' AND t.name IN ('Tauri', 'Svelte', 'SQLite');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'finance-app' AND t.name IN ('Tauri', 'Svelte', 'SQLite');
 
 -- Price Tracker: Python, FastAPI, Turso
 INSERT OR IGNORE INTO project_tags (project_id, tag_id)
 SELECT p.id, t.id FROM projects p, tags t
-WHERE p.slug = 'price-tracker' AND t.name IN ('Python', 'FastAPI', 'Turso');
+WHERE NOT EXISTS (SELECT 1 FROM project_tags pt WHERE pt.project_id = p.id)
+    AND p.slug = 'price-tracker' AND t.name IN ('Python', 'FastAPI', 'Turso');
@@ -0,0 +1,34 @@
+-- Project Hub: watchers, changelog, and notifications
+-- Watched projects record a notification when their status changes or a
+-- changelog entry is added; unwatched projects stay quiet.
+
+CREATE TABLE IF NOT EXISTS project_watches (
+    project_id INTEGER PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    notify_status INTEGER NOT NULL DEFAULT 1,
+    notify_changelog INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS changelog_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    version TEXT,
+    title TEXT NOT NULL,
+    body TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_changelog_entries_project_id ON changelog_entries(project_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK(kind IN ('status', 'changelog')),
+    title TEXT NOT NULL,
+    body TEXT,
+    is_read INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_is_read ON notifications(is_read);
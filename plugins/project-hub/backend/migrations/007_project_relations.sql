@@ -0,0 +1,22 @@
+-- Project Hub: relations between projects
+-- Each row reads "project kind related project", e.g. fogon depends-on
+-- cortex. A project is absorbed into at most one other project.
+
+CREATE TABLE IF NOT EXISTS project_relations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    related_project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK(kind IN ('depends-on', 'absorbed-into', 'spun-off-from')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    CHECK(project_id != related_project_id),
+    UNIQUE(project_id, related_project_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_relations_related ON project_relations(related_project_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_relations_absorbed ON project_relations(project_id) WHERE kind = 'absorbed-into';
+
+-- The seeded absorbed projects.
+INSERT OR IGNORE INTO project_relations (project_id, related_project_id, kind)
+SELECT p.id, r.id, 'absorbed-into' FROM projects p, projects r
+WHERE (p.slug = 'finance-app' AND r.slug = 'cortex')
+   OR (p.slug = 'price-tracker' AND r.slug = 'fogon');
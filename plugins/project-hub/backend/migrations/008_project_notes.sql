@@ -0,0 +1,40 @@
+-- Project Hub: markdown notes with revision history
+-- A project has any number of notes. Saving a note keeps its previous title
+-- and body as a revision, stamped with when that version was written.
+-- The old notes column is moved in the same transaction, so a failure cannot
+-- leave the text cleared without its note.
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS project_notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_notes_project_id ON project_notes(project_id);
+
+CREATE TABLE IF NOT EXISTS project_note_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    note_id INTEGER NOT NULL REFERENCES project_notes(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    saved_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_note_revisions_note_id ON project_note_revisions(note_id);
+
+-- The projects.notes column is superseded: its text moves into a note and the
+-- column is cleared, so running this again moves nothing. Text already moved
+-- is not moved twice either.
+INSERT INTO project_notes (project_id, title, body, created_at, updated_at)
+SELECT id, 'Notes', notes, updated_at, updated_at
+FROM projects
+WHERE notes IS NOT NULL AND notes != ''
+  AND NOT EXISTS (SELECT 1 FROM project_notes pn WHERE pn.project_id = projects.id AND pn.body = projects.notes);
+
+UPDATE projects SET notes = NULL WHERE notes IS NOT NULL;
+
+COMMIT;
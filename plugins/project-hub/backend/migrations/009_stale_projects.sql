@@ -0,0 +1,21 @@
+-- Project Hub: stale project detection
+-- A scheduled check flags projects in development or design that have not
+-- been touched for stale_days, and a weekly digest notification lists them.
+
+-- Single settings row.
+CREATE TABLE IF NOT EXISTS stale_settings (
+    id INTEGER PRIMARY KEY CHECK(id = 1),
+    stale_days INTEGER NOT NULL DEFAULT 30 CHECK(stale_days > 0),
+    last_check_at TEXT,
+    last_digest_at TEXT,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+INSERT OR IGNORE INTO stale_settings (id) VALUES (1);
+
+-- Projects the last check flagged. A flag no longer counts once the project
+-- is touched after flagged_at; the next check removes it.
+CREATE TABLE IF NOT EXISTS project_attention (
+    project_id INTEGER PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    flagged_at TEXT NOT NULL
+);
@@ -0,0 +1,23 @@
+-- Project Hub: status history
+-- Every status a project has been in, with when it moved there, so the time
+-- spent in each status can be worked out. from_status is NULL for the status
+-- a project was created with; forced marks transitions made with the
+-- override flag.
+
+CREATE TABLE IF NOT EXISTS status_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    forced INTEGER NOT NULL DEFAULT 0,
+    changed_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_history_project ON status_history(project_id, changed_at);
+
+-- Projects without any history start it with their current status, since
+-- they were created.
+INSERT INTO status_history (project_id, to_status, changed_at)
+SELECT id, status, created_at FROM projects
+WHERE NOT EXISTS (SELECT 1 FROM status_history WHERE status_history.project_id = projects.id);
@@ -0,0 +1,9 @@
+-- Project Hub: archived projects
+-- DELETE /projects/{slug} archives a project instead of removing it. An
+-- archived project keeps its links, tags, milestones and history, is left
+-- out of listings, and comes back with POST /projects/{slug}/restore.
+
+CREATE TABLE IF NOT EXISTS project_archive (
+    project_id INTEGER PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    archived_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,5 @@
+-- Project Hub: drop the plugin's own notifications table
+-- Watcher notifications now go to the host's notification center, so the
+-- notifications table kept by 005_watchers.sql is no longer used.
+
+DROP TABLE IF EXISTS notifications;
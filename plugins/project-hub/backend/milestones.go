@@ -0,0 +1,641 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// tasksDueSlot is the widget slot listing open tasks due this week across
+// all projects.
+const tasksDueSlot = "tasks-due-widget"
+
+const maxMilestoneNameLength = 100
+const maxTaskTitleLength = 200
+
+var validMilestoneStatuses = map[string]bool{"open": true, "closed": true}
+
+var validTaskStatuses = map[string]bool{"todo": true, "in_progress": true, "done": true}
+
+// Milestone is a project milestone with a count of its tasks by status.
+type Milestone struct {
+	ID        int64      `json:"id"`
+	ProjectID int64      `json:"project_id"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	StartDate *string    `json:"start_date"`
+	DueDate   *string    `json:"due_date"`
+	SortOrder int        `json:"sort_order"`
+	Tasks     TaskCounts `json:"tasks"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
+
+// Task is a unit of work in a milestone. CompletedAt is set when the task is
+// marked done and cleared when it is reopened.
+type Task struct {
+	ID          int64   `json:"id"`
+	MilestoneID int64   `json:"milestone_id"`
+	Title       string  `json:"title"`
+	Status      string  `json:"status"`
+	DueDate     *string `json:"due_date"`
+	SortOrder   int     `json:"sort_order"`
+	CompletedAt *string `json:"completed_at"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+// TaskCounts counts tasks by status. Overdue counts the unfinished tasks
+// whose due date has passed.
+type TaskCounts struct {
+	Todo       int `json:"todo"`
+	InProgress int `json:"in_progress"`
+	Done       int `json:"done"`
+	Overdue    int `json:"overdue"`
+}
+
+// ProjectProgress rolls up a project's milestones and tasks for the project
+// detail response.
+type ProjectProgress struct {
+	OpenMilestones   int        `json:"open_milestones"`
+	ClosedMilestones int        `json:"closed_milestones"`
+	Tasks            TaskCounts `json:"tasks"`
+	// PercentDone is the share of tasks that are done, or 0 without tasks.
+	PercentDone float64 `json:"percent_done"`
+}
+
+// DueTask is an open task due this week, with the project and milestone it
+// belongs to, as shown by the tasks-due widget.
+type DueTask struct {
+	Task
+	Project       string `json:"project"`
+	ProjectName   string `json:"project_name"`
+	MilestoneName string `json:"milestone_name"`
+}
+
+// taskCountColumns aggregates the tasks table (aliased t) into TaskCounts order.
+const taskCountColumns = `COALESCE(SUM(CASE WHEN t.status = 'todo' THEN 1 ELSE 0 END), 0),
+	COALESCE(SUM(CASE WHEN t.status = 'in_progress' THEN 1 ELSE 0 END), 0),
+	COALESCE(SUM(CASE WHEN t.status = 'done' THEN 1 ELSE 0 END), 0),
+	COALESCE(SUM(CASE WHEN t.status != 'done' AND t.due_date < date('now') THEN 1 ELSE 0 END), 0)`
+
+// --- Milestone handlers ---
+
+func (p *ProjectHubPlugin) listMilestones(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/milestones
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	projectID, err := p.projectIDBySlug(pathParts[1])
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.db.Query(
+		`SELECT m.id, m.project_id, m.name, m.status, m.start_date, m.due_date, m.sort_order,
+		        m.created_at, m.updated_at, `+taskCountColumns+`
+		 FROM milestones m LEFT JOIN tasks t ON t.milestone_id = m.id
+		 WHERE m.project_id = ?
+		 GROUP BY m.id
+		 ORDER BY m.sort_order, m.due_date IS NULL, m.due_date, m.id`, projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying milestones: %w", err)
+	}
+	defer rows.Close()
+
+	milestones := make([]Milestone, 0)
+	for rows.Next() {
+		milestone, err := scanMilestone(rows)
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, *milestone)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating milestones: %w", err)
+	}
+
+	return sdk.Success(200, milestones)
+}
+
+func (p *ProjectHubPlugin) createMilestone(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/milestones
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	projectID, err := p.projectIDBySlug(pathParts[1])
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var input struct {
+		Name      string  `json:"name"`
+		Status    string  `json:"status"`
+		StartDate *string `json:"start_date"`
+		DueDate   *string `json:"due_date"`
+		SortOrder int     `json:"sort_order"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || len(input.Name) > maxMilestoneNameLength {
+		return sdk.Error(400, "VALIDATION_ERROR", "name is required and must be at most 100 characters")
+	}
+	if input.Status == "" {
+		input.Status = "open"
+	}
+	if !validMilestoneStatuses[input.Status] {
+		return sdk.Error(400, "VALIDATION_ERROR", "status must be open or closed")
+	}
+	if !isValidOptionalDate(input.StartDate) || !isValidOptionalDate(input.DueDate) {
+		return sdk.Error(400, "VALIDATION_ERROR", "dates must use the YYYY-MM-DD format")
+	}
+	if nullableDate(input.StartDate) != nil && nullableDate(input.DueDate) != nil && *input.DueDate < *input.StartDate {
+		return sdk.Error(400, "VALIDATION_ERROR", "due_date must not be before start_date")
+	}
+
+	result, err := p.db.Exec(
+		"INSERT INTO milestones (project_id, name, status, start_date, due_date, sort_order) VALUES (?, ?, ?, ?, ?, ?)",
+		projectID, input.Name, input.Status, nullableDate(input.StartDate), nullableDate(input.DueDate), input.SortOrder,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting milestone: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	milestone, err := p.getMilestoneByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(201, milestone)
+}
+
+func (p *ProjectHubPlugin) updateMilestone(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, err := strconv.ParseInt(extractPathParam(req.Path, "/milestones/"), 10, 64)
+	if err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid milestone ID")
+	}
+
+	var input struct {
+		Name      *string `json:"name"`
+		Status    *string `json:"status"`
+		StartDate *string `json:"start_date"`
+		DueDate   *string `json:"due_date"`
+		SortOrder *int    `json:"sort_order"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	setClauses := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || len(name) > maxMilestoneNameLength {
+			return sdk.Error(400, "VALIDATION_ERROR", "name is required and must be at most 100 characters")
+		}
+		setClauses = append(setClauses, "name = ?")
+		args = append(args, name)
+	}
+	if input.Status != nil {
+		if !validMilestoneStatuses[*input.Status] {
+			return sdk.Error(400, "VALIDATION_ERROR", "status must be open or closed")
+		}
+		setClauses = append(setClauses, "status = ?")
+		args = append(args, *input.Status)
+	}
+	if input.StartDate != nil {
+		if !isValidOptionalDate(input.StartDate) {
+			return sdk.Error(400, "VALIDATION_ERROR", "dates must use the YYYY-MM-DD format")
+		}
+		setClauses = append(setClauses, "start_date = ?")
+		args = append(args, nullableDate(input.StartDate))
+	}
+	if input.DueDate != nil {
+		if !isValidOptionalDate(input.DueDate) {
+			return sdk.Error(400, "VALIDATION_ERROR", "dates must use the YYYY-MM-DD format")
+		}
+		setClauses = append(setClauses, "due_date = ?")
+		args = append(args, nullableDate(input.DueDate))
+	}
+	if input.SortOrder != nil {
+		setClauses = append(setClauses, "sort_order = ?")
+		args = append(args, *input.SortOrder)
+	}
+
+	if len(setClauses) == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "no fields to update")
+	}
+
+	setClauses = append(setClauses, "updated_at = datetime('now')")
+	query := fmt.Sprintf("UPDATE milestones SET %s WHERE id = ?", strings.Join(setClauses, ", "))
+	args = append(args, id)
+
+	result, err := p.db.Exec(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("updating milestone: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "milestone not found")
+	}
+
+	milestone, err := p.getMilestoneByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, milestone)
+}
+
+func (p *ProjectHubPlugin) deleteMilestone(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id := extractPathParam(req.Path, "/milestones/")
+
+	// Tasks are removed with the milestone by ON DELETE CASCADE.
+	result, err := p.db.Exec("DELETE FROM milestones WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting milestone: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "milestone not found")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// --- Task handlers ---
+
+func (p *ProjectHubPlugin) listTasks(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract milestone ID from /milestones/{id}/tasks
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	exists, err := p.milestoneExists(pathParts[1])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return sdk.Error(404, "NOT_FOUND", "milestone not found")
+	}
+
+	rows, err := p.db.Query(
+		`SELECT id, milestone_id, title, status, due_date, sort_order, completed_at, created_at, updated_at
+		 FROM tasks WHERE milestone_id = ?
+		 ORDER BY sort_order, due_date IS NULL, due_date, id`, pathParts[1],
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating tasks: %w", err)
+	}
+
+	return sdk.Success(200, tasks)
+}
+
+func (p *ProjectHubPlugin) createTask(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract milestone ID from /milestones/{id}/tasks
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	exists, err := p.milestoneExists(pathParts[1])
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return sdk.Error(404, "NOT_FOUND", "milestone not found")
+	}
+
+	var input struct {
+		Title     string  `json:"title"`
+		Status    string  `json:"status"`
+		DueDate   *string `json:"due_date"`
+		SortOrder int     `json:"sort_order"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" || len(input.Title) > maxTaskTitleLength {
+		return sdk.Error(400, "VALIDATION_ERROR", "title is required and must be at most 200 characters")
+	}
+	if input.Status == "" {
+		input.Status = "todo"
+	}
+	if !validTaskStatuses[input.Status] {
+		return sdk.Error(400, "VALIDATION_ERROR", "status must be todo, in_progress or done")
+	}
+	if !isValidOptionalDate(input.DueDate) {
+		return sdk.Error(400, "VALIDATION_ERROR", "due_date must use the YYYY-MM-DD format")
+	}
+
+	result, err := p.db.Exec(
+		`INSERT INTO tasks (milestone_id, title, status, due_date, sort_order, completed_at)
+		 VALUES (?, ?, ?, ?, ?, CASE WHEN ? = 'done' THEN datetime('now') END)`,
+		pathParts[1], input.Title, input.Status, nullableDate(input.DueDate), input.SortOrder, input.Status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting task: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	task, err := p.getTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(201, task)
+}
+
+func (p *ProjectHubPlugin) updateTask(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, err := strconv.ParseInt(extractPathParam(req.Path, "/tasks/"), 10, 64)
+	if err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid task ID")
+	}
+
+	var input struct {
+		Title     *string `json:"title"`
+		Status    *string `json:"status"`
+		DueDate   *string `json:"due_date"`
+		SortOrder *int    `json:"sort_order"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	setClauses := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" || len(title) > maxTaskTitleLength {
+			return sdk.Error(400, "VALIDATION_ERROR", "title is required and must be at most 200 characters")
+		}
+		setClauses = append(setClauses, "title = ?")
+		args = append(args, title)
+	}
+	if input.Status != nil {
+		if !validTaskStatuses[*input.Status] {
+			return sdk.Error(400, "VALIDATION_ERROR", "status must be todo, in_progress or done")
+		}
+		// Keep the original completion time when a done task is saved again,
+		// so burndowns do not move.
+		setClauses = append(setClauses,
+			"status = ?",
+			"completed_at = CASE WHEN ? = 'done' THEN COALESCE(completed_at, datetime('now')) END",
+		)
+		args = append(args, *input.Status, *input.Status)
+	}
+	if input.DueDate != nil {
+		if !isValidOptionalDate(input.DueDate) {
+			return sdk.Error(400, "VALIDATION_ERROR", "due_date must use the YYYY-MM-DD format")
+		}
+		setClauses = append(setClauses, "due_date = ?")
+		args = append(args, nullableDate(input.DueDate))
+	}
+	if input.SortOrder != nil {
+		setClauses = append(setClauses, "sort_order = ?")
+		args = append(args, *input.SortOrder)
+	}
+
+	if len(setClauses) == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "no fields to update")
+	}
+
+	setClauses = append(setClauses, "updated_at = datetime('now')")
+	query := fmt.Sprintf("UPDATE tasks SET %s WHERE id = ?", strings.Join(setClauses, ", "))
+	args = append(args, id)
+
+	result, err := p.db.Exec(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("updating task: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "task not found")
+	}
+
+	task, err := p.getTaskByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, task)
+}
+
+func (p *ProjectHubPlugin) deleteTask(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id := extractPathParam(req.Path, "/tasks/")
+
+	result, err := p.db.Exec("DELETE FROM tasks WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting task: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "task not found")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// --- Roll-ups ---
+
+// loadProjectProgress counts the project's milestones by status and the tasks
+// across all of them.
+func (p *ProjectHubPlugin) loadProjectProgress(projectID int64) (*ProjectProgress, error) {
+	var progress ProjectProgress
+	if err := p.db.QueryRow(
+		`SELECT COALESCE(SUM(CASE WHEN status = 'open' THEN 1 ELSE 0 END), 0),
+		        COALESCE(SUM(CASE WHEN status = 'closed' THEN 1 ELSE 0 END), 0)
+		 FROM milestones WHERE project_id = ?`, projectID,
+	).Scan(&progress.OpenMilestones, &progress.ClosedMilestones); err != nil {
+		return nil, fmt.Errorf("counting milestones: %w", err)
+	}
+
+	if err := p.db.QueryRow(
+		`SELECT `+taskCountColumns+`
+		 FROM tasks t JOIN milestones m ON m.id = t.milestone_id
+		 WHERE m.project_id = ?`, projectID,
+	).Scan(&progress.Tasks.Todo, &progress.Tasks.InProgress, &progress.Tasks.Done, &progress.Tasks.Overdue); err != nil {
+		return nil, fmt.Errorf("counting tasks: %w", err)
+	}
+
+	if total := progress.Tasks.Todo + progress.Tasks.InProgress + progress.Tasks.Done; total > 0 {
+		progress.PercentDone = float64(progress.Tasks.Done) * 100 / float64(total)
+	}
+
+	return &progress, nil
+}
+
+// tasksDueWidgetData returns the unfinished tasks due from Monday to Sunday of
+// the week containing now, across all projects, soonest first.
+func (p *ProjectHubPlugin) tasksDueWidgetData(now time.Time) ([]byte, error) {
+	weekStart := startOfWeek(now)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	rows, err := p.db.Query(
+		`SELECT t.id, t.milestone_id, t.title, t.status, t.due_date, t.sort_order, t.completed_at,
+		        t.created_at, t.updated_at, pr.slug, pr.name, m.name
+		 FROM tasks t
+		 JOIN milestones m ON m.id = t.milestone_id
+		 JOIN projects pr ON pr.id = m.project_id
+		 WHERE t.status != 'done' AND t.due_date BETWEEN ? AND ?
+		 ORDER BY t.due_date, pr.sort_order, t.sort_order, t.id`,
+		weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying tasks due: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]DueTask, 0)
+	for rows.Next() {
+		var task DueTask
+		if err := rows.Scan(
+			&task.ID, &task.MilestoneID, &task.Title, &task.Status, &task.DueDate, &task.SortOrder,
+			&task.CompletedAt, &task.CreatedAt, &task.UpdatedAt, &task.Project, &task.ProjectName, &task.MilestoneName,
+		); err != nil {
+			return nil, fmt.Errorf("scanning task due: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating tasks due: %w", err)
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"week_start": weekStart.Format("2006-01-02"),
+			"week_end":   weekEnd.Format("2006-01-02"),
+			"total":      len(tasks),
+			"tasks":      tasks,
+		},
+	})
+}
+
+// --- Helpers ---
+
+func (p *ProjectHubPlugin) projectIDBySlug(slug string) (int64, error) {
+	var projectID int64
+	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("querying project: %w", err)
+	}
+	return projectID, err
+}
+
+func (p *ProjectHubPlugin) milestoneExists(id string) (bool, error) {
+	var count int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM milestones WHERE id = ?", id).Scan(&count); err != nil {
+		return false, fmt.Errorf("querying milestone: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (p *ProjectHubPlugin) getMilestoneByID(id int64) (*Milestone, error) {
+	row := p.db.QueryRow(
+		`SELECT m.id, m.project_id, m.name, m.status, m.start_date, m.due_date, m.sort_order,
+		        m.created_at, m.updated_at, `+taskCountColumns+`
+		 FROM milestones m LEFT JOIN tasks t ON t.milestone_id = m.id
+		 WHERE m.id = ?
+		 GROUP BY m.id`, id,
+	)
+	return scanMilestone(row)
+}
+
+func (p *ProjectHubPlugin) getTaskByID(id int64) (*Task, error) {
+	row := p.db.QueryRow(
+		`SELECT id, milestone_id, title, status, due_date, sort_order, completed_at, created_at, updated_at
+		 FROM tasks WHERE id = ?`, id,
+	)
+	return scanTask(row)
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMilestone(scanner rowScanner) (*Milestone, error) {
+	var milestone Milestone
+	if err := scanner.Scan(
+		&milestone.ID, &milestone.ProjectID, &milestone.Name, &milestone.Status, &milestone.StartDate,
+		&milestone.DueDate, &milestone.SortOrder, &milestone.CreatedAt, &milestone.UpdatedAt,
+		&milestone.Tasks.Todo, &milestone.Tasks.InProgress, &milestone.Tasks.Done, &milestone.Tasks.Overdue,
+	); err != nil {
+		return nil, fmt.Errorf("scanning milestone: %w", err)
+	}
+	return &milestone, nil
+}
+
+func scanTask(scanner rowScanner) (*Task, error) {
+	var task Task
+	if err := scanner.Scan(
+		&task.ID, &task.MilestoneID, &task.Title, &task.Status, &task.DueDate, &task.SortOrder,
+		&task.CompletedAt, &task.CreatedAt, &task.UpdatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scanning task: %w", err)
+	}
+	return &task, nil
+}
+
+// nullableDate returns date for storage, with an empty date stored as NULL so
+// it can be cleared.
+func nullableDate(date *string) interface{} {
+	if date == nil || *date == "" {
+		return nil
+	}
+	return *date
+}
+
+// isValidOptionalDate reports whether date is nil, empty or a YYYY-MM-DD date.
+func isValidOptionalDate(date *string) bool {
+	if date == nil || *date == "" {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", *date)
+	return err == nil
+}
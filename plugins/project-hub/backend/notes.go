@@ -0,0 +1,310 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+const (
+	maxNoteTitleLength = 200
+	maxNoteBodyLength  = 100000
+)
+
+// notesMovedMessage answers requests that still send a project's notes with
+// the project, which replaced the single projects.notes column.
+const notesMovedMessage = "notes are kept as project notes: use /projects/{slug}/notes"
+
+// ProjectNote is a markdown note on a project. HTML is the body rendered
+// and sanitized by renderMarkdown.
+type ProjectNote struct {
+	ID            int64  `json:"id"`
+	ProjectID     int64  `json:"project_id"`
+	Title         string `json:"title"`
+	Body          string `json:"body"`
+	HTML          string `json:"html"`
+	RevisionCount int    `json:"revision_count"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// NoteRevision is an earlier version of a note, as it was saved at SavedAt.
+type NoteRevision struct {
+	ID      int64  `json:"id"`
+	NoteID  int64  `json:"note_id"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTML    string `json:"html"`
+	SavedAt string `json:"saved_at"`
+}
+
+// noteSelect is the base query ProjectNote rows are scanned from.
+const noteSelect = `SELECT n.id, n.project_id, n.title, n.body,
+	(SELECT COUNT(*) FROM project_note_revisions r WHERE r.note_id = n.id),
+	n.created_at, n.updated_at
+	FROM project_notes n`
+
+// --- Note handlers ---
+
+func (p *ProjectHubPlugin) listNotes(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/notes
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	projectID, err := p.projectIDBySlug(pathParts[1])
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.db.Query(noteSelect+" WHERE n.project_id = ? ORDER BY n.updated_at DESC, n.id DESC", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("querying notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := make([]ProjectNote, 0)
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, *note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notes: %w", err)
+	}
+
+	return sdk.Success(200, notes)
+}
+
+func (p *ProjectHubPlugin) createNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/notes
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	projectID, err := p.projectIDBySlug(pathParts[1])
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var input struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	if resp, err := validateNote(input.Title, input.Body); resp != nil || err != nil {
+		return resp, err
+	}
+
+	result, err := p.db.Exec(
+		"INSERT INTO project_notes (project_id, title, body) VALUES (?, ?, ?)",
+		projectID, input.Title, input.Body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting note: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	note, err := p.getNoteByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(201, note)
+}
+
+func (p *ProjectHubPlugin) getNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, ok := noteIDFromPath(req.Path)
+	if !ok {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid note id")
+	}
+
+	note, err := p.getNoteByID(id)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "note not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, note)
+}
+
+// updateNote saves a note's new title or body, keeping the version it
+// replaces as a revision. Saving identical content records no revision.
+func (p *ProjectHubPlugin) updateNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, ok := noteIDFromPath(req.Path)
+	if !ok {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid note id")
+	}
+
+	current, err := p.getNoteByID(id)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "note not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var input struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+	}
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+	if input.Title == nil && input.Body == nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "no fields to update")
+	}
+
+	title, body := current.Title, current.Body
+	if input.Title != nil {
+		title = strings.TrimSpace(*input.Title)
+	}
+	if input.Body != nil {
+		body = *input.Body
+	}
+	if resp, err := validateNote(title, body); resp != nil || err != nil {
+		return resp, err
+	}
+
+	if title != current.Title || body != current.Body {
+		transaction, err := p.db.Begin()
+		if err != nil {
+			return nil, fmt.Errorf("beginning transaction: %w", err)
+		}
+		defer transaction.Rollback()
+
+		if _, err := transaction.Exec(
+			"INSERT INTO project_note_revisions (note_id, title, body, saved_at) VALUES (?, ?, ?, ?)",
+			id, current.Title, current.Body, current.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("inserting note revision: %w", err)
+		}
+		if _, err := transaction.Exec(
+			"UPDATE project_notes SET title = ?, body = ?, updated_at = datetime('now') WHERE id = ?",
+			title, body, id,
+		); err != nil {
+			return nil, fmt.Errorf("updating note: %w", err)
+		}
+		if err := transaction.Commit(); err != nil {
+			return nil, fmt.Errorf("committing note: %w", err)
+		}
+	}
+
+	note, err := p.getNoteByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, note)
+}
+
+func (p *ProjectHubPlugin) deleteNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, ok := noteIDFromPath(req.Path)
+	if !ok {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid note id")
+	}
+
+	result, err := p.db.Exec("DELETE FROM project_notes WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting note: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "note not found")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// listNoteRevisions returns a note's earlier versions, newest first.
+func (p *ProjectHubPlugin) listNoteRevisions(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, ok := noteIDFromPath(req.Path)
+	if !ok {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid note id")
+	}
+
+	if _, err := p.getNoteByID(id); err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "note not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.db.Query(
+		"SELECT id, note_id, title, body, saved_at FROM project_note_revisions WHERE note_id = ? ORDER BY id DESC",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying note revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]NoteRevision, 0)
+	for rows.Next() {
+		var revision NoteRevision
+		if err := rows.Scan(&revision.ID, &revision.NoteID, &revision.Title, &revision.Body, &revision.SavedAt); err != nil {
+			return nil, fmt.Errorf("scanning note revision: %w", err)
+		}
+		revision.HTML = renderMarkdown(revision.Body)
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating note revisions: %w", err)
+	}
+
+	return sdk.Success(200, revisions)
+}
+
+// --- Helpers ---
+
+func validateNote(title, body string) (*sdk.APIResponse, error) {
+	if title == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "title is required")
+	}
+	if len(title) > maxNoteTitleLength {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("title must be %d characters or less", maxNoteTitleLength))
+	}
+	if len(body) > maxNoteBodyLength {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("body must be %d characters or less", maxNoteBodyLength))
+	}
+	return nil, nil
+}
+
+// noteIDFromPath reads the note ID from /notes/{id} or /notes/{id}/revisions.
+func noteIDFromPath(path string) (int64, bool) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(path, "/notes/"), "/revisions")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	return id, err == nil && id > 0
+}
+
+func (p *ProjectHubPlugin) getNoteByID(id int64) (*ProjectNote, error) {
+	return scanNote(p.db.QueryRow(noteSelect+" WHERE n.id = ?", id))
+}
+
+func scanNote(row rowScanner) (*ProjectNote, error) {
+	var note ProjectNote
+	err := row.Scan(&note.ID, &note.ProjectID, &note.Title, &note.Body, &note.RevisionCount, &note.CreatedAt, &note.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning note: %w", err)
+	}
+	note.HTML = renderMarkdown(note.Body)
+	return &note, nil
+}
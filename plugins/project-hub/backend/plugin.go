@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	_ "modernc.org/sqlite"
 
@@ -19,6 +23,19 @@ var migrations embed.FS
 // ProjectHubPlugin implements sdk.CortexPlugin for project ecosystem tracking.
 type ProjectHubPlugin struct {
 	db *sql.DB
+
+	// notify delivers the stale projects digest and watcher notifications. Nil
+	// means sdk.SendNotification.
+	notify func(title string, body string, urgent bool) error
+	// notifyChanged tells dashboards a topic's data changed. Nil means
+	// sdk.NotifyChanged.
+	notifyChanged func(topic string) error
+	// stopStaleCheck stops the stale projects loop started in Migrate.
+	stopStaleCheck func()
+
+	// router serves HandleAPI, built on the first request.
+	router     *sdk.Router
+	routerOnce sync.Once
 }
 
 // GetManifest returns the plugin's metadata.
@@ -30,28 +47,20 @@ func (p *ProjectHubPlugin) GetManifest() (*sdk.Manifest, error) {
 		Description: "Track the state of your entire project ecosystem",
 		Icon:        "folder-git-2",
 		Color:       "#8B5CF6",
-		Permissions: []string{"db:read", "db:write"},
+		Permissions: []string{"db:read", "db:write", "notifications"},
 	}, nil
 }
 
 // Migrate opens the SQLite database and runs embedded SQL migrations.
 func (p *ProjectHubPlugin) Migrate(databasePath string) error {
-	database, err := sql.Open("sqlite", databasePath)
+	// The SDK enables WAL mode and foreign keys, and encrypts the database
+	// when the host has at-rest encryption enabled.
+	database, err := sdk.OpenDatabase(databasePath)
 	if err != nil {
 		return fmt.Errorf("opening database: %w", err)
 	}
 	p.db = database
 
-	// Enable WAL mode for better concurrent read performance.
-	if _, err := p.db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		return fmt.Errorf("enabling WAL mode: %w", err)
-	}
-
-	// Enable foreign keys for CASCADE deletes.
-	if _, err := p.db.Exec("PRAGMA foreign_keys=ON"); err != nil {
-		return fmt.Errorf("enabling foreign keys: %w", err)
-	}
-
 	schemaSQL, err := migrations.ReadFile("migrations/001_schema.sql")
 	if err != nil {
 		return fmt.Errorf("reading schema migration: %w", err)
@@ -79,56 +88,217 @@ func (p *ProjectHubPlugin) Migrate(databasePath string) error {
 		return fmt.Errorf("running tags migration: %w", err)
 	}
 
+	milestonesSQL, err := migrations.ReadFile("migrations/004_milestones.sql")
+	if err != nil {
+		return fmt.Errorf("reading milestones migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(milestonesSQL)); err != nil {
+		return fmt.Errorf("running milestones migration: %w", err)
+	}
+
+	watchersSQL, err := migrations.ReadFile("migrations/005_watchers.sql")
+	if err != nil {
+		return fmt.Errorf("reading watchers migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(watchersSQL)); err != nil {
+		return fmt.Errorf("running watchers migration: %w", err)
+	}
+
+	timeSQL, err := migrations.ReadFile("migrations/006_time_entries.sql")
+	if err != nil {
+		return fmt.Errorf("reading time entries migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(timeSQL)); err != nil {
+		return fmt.Errorf("running time entries migration: %w", err)
+	}
+
+	relationsSQL, err := migrations.ReadFile("migrations/007_project_relations.sql")
+	if err != nil {
+		return fmt.Errorf("reading project relations migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(relationsSQL)); err != nil {
+		return fmt.Errorf("running project relations migration: %w", err)
+	}
+
+	notesSQL, err := migrations.ReadFile("migrations/008_project_notes.sql")
+	if err != nil {
+		return fmt.Errorf("reading project notes migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(notesSQL)); err != nil {
+		return fmt.Errorf("running project notes migration: %w", err)
+	}
+
+	staleSQL, err := migrations.ReadFile("migrations/009_stale_projects.sql")
+	if err != nil {
+		return fmt.Errorf("reading stale projects migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(staleSQL)); err != nil {
+		return fmt.Errorf("running stale projects migration: %w", err)
+	}
+
+	historySQL, err := migrations.ReadFile("migrations/010_status_history.sql")
+	if err != nil {
+		return fmt.Errorf("reading status history migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(historySQL)); err != nil {
+		return fmt.Errorf("running status history migration: %w", err)
+	}
+
+	archiveSQL, err := migrations.ReadFile("migrations/011_project_archive.sql")
+	if err != nil {
+		return fmt.Errorf("reading project archive migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(archiveSQL)); err != nil {
+		return fmt.Errorf("running project archive migration: %w", err)
+	}
+
+	dropNotificationsSQL, err := migrations.ReadFile("migrations/012_drop_notifications.sql")
+	if err != nil {
+		return fmt.Errorf("reading drop notifications migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(dropNotificationsSQL)); err != nil {
+		return fmt.Errorf("running drop notifications migration: %w", err)
+	}
+
+	if p.stopStaleCheck != nil {
+		p.stopStaleCheck()
+	}
+	p.stopStaleCheck = p.startStaleCheck(staleCheckInterval)
+
 	return nil
 }
 
+// Migrations lists the embedded SQL migrations so the host can lint them
+// before Migrate runs.
+func (p *ProjectHubPlugin) Migrations() ([]sdk.MigrationFile, error) {
+	return sdk.EmbeddedMigrations(migrations, "migrations")
+}
+
 // HandleAPI routes incoming API requests to the appropriate handler.
-func (p *ProjectHubPlugin) HandleAPI(req *sdk.APIRequest) (*sdk.APIResponse, error) {
-	switch {
+func (p *ProjectHubPlugin) HandleAPI(ctx context.Context, req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	p.routerOnce.Do(func() { p.router = p.routes() })
+	return p.router.HandleAPI(ctx, req)
+}
+
+// routes registers the plugin's API. The project handlers read their slug
+// from req.PathParams; the others still parse req.Path themselves.
+func (p *ProjectHubPlugin) routes() *sdk.Router {
+	router := sdk.NewRouter()
+
 	// Projects
-	case req.Method == "GET" && req.Path == "/projects":
-		return p.listProjects(req)
-	case req.Method == "POST" && req.Path == "/projects":
-		return p.createProject(req)
-	case req.Method == "GET" && strings.HasPrefix(req.Path, "/projects/") && !strings.Contains(req.Path[len("/projects/"):], "/"):
-		return p.getProject(req)
-	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/projects/") && !strings.Contains(req.Path[len("/projects/"):], "/"):
-		return p.updateProject(req)
-	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/projects/") && !strings.Contains(req.Path[len("/projects/"):], "/"):
-		return p.deleteProject(req)
+	router.Get("/projects", p.listProjects)
+	router.Post("/projects", p.changes("projects", p.createProject))
+	router.Get("/projects/graph", p.getProjectGraph)
+	router.Get("/projects/{slug}", p.getProject)
+	router.Put("/projects/{slug}", p.changes("projects", p.updateProject))
+	router.Delete("/projects/{slug}", p.changes("projects", p.archiveProject))
+	router.Post("/projects/{slug}/restore", p.changes("projects", p.restoreProject))
+	router.Delete("/projects/{slug}/permanent", p.changes("projects", p.deleteProject))
+	router.Get("/projects/{slug}/history", p.getStatusHistory)
 
 	// Project links
-	case req.Method == "POST" && strings.HasPrefix(req.Path, "/projects/") && strings.HasSuffix(req.Path, "/links"):
-		return p.createLink(req)
-	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/links/"):
-		return p.updateLink(req)
-	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/links/"):
-		return p.deleteLink(req)
+	router.Post("/projects/{slug}/links", p.createLink)
+	router.Put("/links/{id}", p.updateLink)
+	router.Delete("/links/{id}", p.deleteLink)
+
+	// Project relations
+	router.Get("/projects/{slug}/relations", p.listRelations)
+	router.Post("/projects/{slug}/relations", p.createRelation)
+	router.Delete("/relations/{id}", p.deleteRelation)
+
+	// Project notes
+	router.Get("/projects/{slug}/notes", p.listNotes)
+	router.Post("/projects/{slug}/notes", p.createNote)
+	router.Get("/notes/{id}/revisions", p.listNoteRevisions)
+	router.Get("/notes/{id}", p.getNote)
+	router.Put("/notes/{id}", p.updateNote)
+	router.Delete("/notes/{id}", p.deleteNote)
 
 	// Tags
-	case req.Method == "GET" && req.Path == "/tags":
-		return p.listTags(req)
-	case req.Method == "POST" && req.Path == "/tags":
-		return p.createTag(req)
-	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/tags/"):
-		return p.deleteTag(req)
+	router.Get("/tags", p.listTags)
+	router.Post("/tags", p.createTag)
+	router.Post("/tags/merge", p.mergeTags)
+	router.Put("/tags/{id}", p.updateTag)
+	router.Delete("/tags/{id}", p.deleteTag)
 
 	// Project tags
-	case req.Method == "POST" && strings.HasPrefix(req.Path, "/projects/") && strings.HasSuffix(req.Path, "/tags"):
-		return p.setProjectTags(req)
+	router.Post("/projects/{slug}/tags", p.setProjectTags)
+
+	// Milestones and tasks
+	router.Get("/projects/{slug}/milestones", p.listMilestones)
+	router.Post("/projects/{slug}/milestones", p.changes("tasks", p.createMilestone))
+	router.Get("/milestones/{id}/tasks", p.listTasks)
+	router.Post("/milestones/{id}/tasks", p.changes("tasks", p.createTask))
+	router.Put("/milestones/{id}", p.changes("tasks", p.updateMilestone))
+	router.Delete("/milestones/{id}", p.changes("tasks", p.deleteMilestone))
+	router.Put("/tasks/{id}", p.changes("tasks", p.updateTask))
+	router.Delete("/tasks/{id}", p.changes("tasks", p.deleteTask))
+
+	// Time tracking
+	router.Get("/projects/{slug}/time", p.getTimeSummary)
+	router.Post("/projects/{slug}/time", p.changes("time", p.recordTime))
+	router.Delete("/time/{id}", p.changes("time", p.deleteTimeEntry))
+
+	// Project stats
+	router.Get("/projects/{slug}/stats", p.getProjectStats)
+
+	// Watchers and changelog
+	router.Post("/projects/{slug}/watch", p.watchProject)
+	router.Delete("/projects/{slug}/watch", p.unwatchProject)
+	router.Get("/projects/{slug}/changelog", p.listChangelog)
+	router.Post("/projects/{slug}/changelog", p.createChangelogEntry)
+
+	// Portfolio export and import
+	router.Get("/export", p.exportPortfolio)
+	router.Post("/import", p.changes("projects", p.importPortfolio))
+
+	// Stale projects
+	router.Get("/stale/settings", p.getStaleSettings)
+	router.Put("/stale/settings", p.updateStaleSettings)
+	router.Post("/stale/check", p.runStaleCheck)
+
+	return router
+}
 
-	default:
-		return jsonError(404, "NOT_FOUND", "route not found")
+// changes wraps a handler that writes topic's data, so that dashboards
+// refresh the widgets showing it once the write succeeded.
+func (p *ProjectHubPlugin) changes(topic string, handler sdk.HandlerFunc) sdk.HandlerFunc {
+	return func(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+		resp, err := handler(req)
+		if err != nil || resp == nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return resp, err
+		}
+		notify := p.notifyChanged
+		if notify == nil {
+			notify = sdk.NotifyChanged
+		}
+		_ = notify(topic)
+		return resp, err
 	}
 }
 
 // GetWidgetData returns dashboard widget data for the requested slot.
 func (p *ProjectHubPlugin) GetWidgetData(slot string) ([]byte, error) {
+	if slot == tasksDueSlot {
+		return p.tasksDueWidgetData(time.Now().UTC())
+	}
+	if slot == timeSummarySlot {
+		return p.timeSummaryWidgetData(time.Now().UTC())
+	}
 	if slot != "dashboard-widget" {
 		return json.Marshal(map[string]interface{}{"data": nil})
 	}
 
-	rows, err := p.db.Query("SELECT status, COUNT(*) FROM projects GROUP BY status")
+	rows, err := p.db.Query("SELECT status, COUNT(*) FROM projects p WHERE " + notArchived + " GROUP BY status")
 	if err != nil {
 		return nil, fmt.Errorf("querying project counts: %w", err)
 	}
@@ -158,8 +328,13 @@ func (p *ProjectHubPlugin) GetWidgetData(slot string) ([]byte, error) {
 	})
 }
 
-// Teardown closes the database connection when the plugin is unloaded.
+// Teardown stops the stale projects check and closes the database connection
+// when the plugin is unloaded.
 func (p *ProjectHubPlugin) Teardown() error {
+	if p.stopStaleCheck != nil {
+		p.stopStaleCheck()
+		p.stopStaleCheck = nil
+	}
 	if p.db != nil {
 		return p.db.Close()
 	}
@@ -184,10 +359,13 @@ type Project struct {
 	WebURL    *string `json:"web_url"`
 	DocsURL   *string `json:"docs_url"`
 	Hosting   *string `json:"hosting"`
-	Notes     *string `json:"notes"`
 	SortOrder int     `json:"sort_order"`
 	CreatedAt string  `json:"created_at"`
 	UpdatedAt string  `json:"updated_at"`
+	// NeedsAttention is set on projects the stale check flagged.
+	NeedsAttention bool `json:"needs_attention"`
+	// ArchivedAt is set on projects DELETE /projects/{slug} archived.
+	ArchivedAt *string `json:"archived_at"`
 }
 
 // Tag represents a technology tag with a display color.
@@ -212,27 +390,37 @@ type ProjectWithTags struct {
 	Tags []Tag `json:"tags"`
 }
 
-// ProjectWithLinksAndTags is a project with its associated links and tags.
+// ProjectWithLinksAndTags is a project with its associated links and tags,
+// and the progress of its milestones.
 type ProjectWithLinksAndTags struct {
 	Project
-	Links []ProjectLink `json:"links"`
-	Tags  []Tag         `json:"tags"`
+	Links    []ProjectLink    `json:"links"`
+	Tags     []Tag            `json:"tags"`
+	Progress *ProjectProgress `json:"progress"`
 }
 
 // --- Handlers ---
 
 func (p *ProjectHubPlugin) listProjects(req *sdk.APIRequest) (*sdk.APIResponse, error) {
-	query := "SELECT DISTINCT p.id, p.name, p.slug, p.tagline, p.status, p.category, p.version, p.stack, p.icon, p.color, p.repo_url, p.web_url, p.docs_url, p.hosting, p.notes, p.sort_order, p.created_at, p.updated_at FROM projects p"
+	query := "SELECT DISTINCT p.id, p.name, p.slug, p.tagline, p.status, p.category, p.version, p.stack, p.icon, p.color, p.repo_url, p.web_url, p.docs_url, p.hosting, p.sort_order, p.created_at, p.updated_at, " + needsAttentionColumn + ", " + archivedAtColumn + " FROM projects p"
 	args := make([]interface{}, 0)
 	joins := ""
 	wheres := make([]string, 0)
 
+	if !includeArchived(req) {
+		wheres = append(wheres, notArchived)
+	}
+
 	if tag := req.Query["tag"]; tag != "" {
 		joins += " JOIN project_tags pt ON pt.project_id = p.id JOIN tags t ON t.id = pt.tag_id"
 		wheres = append(wheres, "t.name = ?")
 		args = append(args, tag)
 	}
 
+	if req.Query["watched"] == "true" {
+		joins += " JOIN project_watches w ON w.project_id = p.id"
+	}
+
 	if status := req.Query["status"]; status != "" {
 		wheres = append(wheres, "p.status = ?")
 		args = append(args, status)
@@ -267,7 +455,8 @@ func (p *ProjectHubPlugin) listProjects(req *sdk.APIRequest) (*sdk.APIResponse,
 		if err := rows.Scan(
 			&proj.ID, &proj.Name, &proj.Slug, &proj.Tagline, &proj.Status, &proj.Category,
 			&proj.Version, &proj.Stack, &proj.Icon, &proj.Color, &proj.RepoURL, &proj.WebURL,
-			&proj.DocsURL, &proj.Hosting, &proj.Notes, &proj.SortOrder, &proj.CreatedAt, &proj.UpdatedAt,
+			&proj.DocsURL, &proj.Hosting, &proj.SortOrder, &proj.CreatedAt, &proj.UpdatedAt,
+			&proj.NeedsAttention, &proj.ArchivedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scanning project: %w", err)
 		}
@@ -317,24 +506,28 @@ func (p *ProjectHubPlugin) listProjects(req *sdk.APIRequest) (*sdk.APIResponse,
 		}
 	}
 
-	return jsonSuccess(200, projects)
+	return sdk.Success(200, projects)
 }
 
 func (p *ProjectHubPlugin) getProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
-	slug := extractPathParam(req.Path, "/projects/")
+	slug := req.PathParams["slug"]
 
 	var proj Project
 	err := p.db.QueryRow(
 		`SELECT id, name, slug, tagline, status, category, version, stack, icon, color,
-		        repo_url, web_url, docs_url, hosting, notes, sort_order, created_at, updated_at
-		 FROM projects WHERE slug = ?`, slug,
+		        repo_url, web_url, docs_url, hosting, sort_order, created_at, updated_at, `+needsAttentionColumn+`, `+archivedAtColumn+`
+		 FROM projects p WHERE slug = ?`, slug,
 	).Scan(
 		&proj.ID, &proj.Name, &proj.Slug, &proj.Tagline, &proj.Status, &proj.Category,
 		&proj.Version, &proj.Stack, &proj.Icon, &proj.Color, &proj.RepoURL, &proj.WebURL,
-		&proj.DocsURL, &proj.Hosting, &proj.Notes, &proj.SortOrder, &proj.CreatedAt, &proj.UpdatedAt,
+		&proj.DocsURL, &proj.Hosting, &proj.SortOrder, &proj.CreatedAt, &proj.UpdatedAt,
+		&proj.NeedsAttention, &proj.ArchivedAt,
 	)
+	if err == nil && proj.ArchivedAt != nil && !includeArchived(req) {
+		err = sql.ErrNoRows
+	}
 	if err == sql.ErrNoRows {
-		return jsonError(404, "NOT_FOUND", "project not found")
+		return sdk.Error(404, "NOT_FOUND", "project not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("querying project: %w", err)
@@ -386,73 +579,108 @@ func (p *ProjectHubPlugin) getProject(req *sdk.APIRequest) (*sdk.APIResponse, er
 		return nil, fmt.Errorf("iterating tags: %w", err)
 	}
 
+	progress, err := p.loadProjectProgress(proj.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	result := ProjectWithLinksAndTags{
-		Project: proj,
-		Links:   links,
-		Tags:    tags,
+		Project:  proj,
+		Links:    links,
+		Tags:     tags,
+		Progress: progress,
 	}
 
-	return jsonSuccess(200, result)
+	return sdk.Success(200, result)
 }
 
 func (p *ProjectHubPlugin) createProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	var input struct {
-		Name      string  `json:"name"`
-		Tagline   string  `json:"tagline"`
-		Status    string  `json:"status"`
-		Category  string  `json:"category"`
-		Version   *string `json:"version"`
-		Stack     string  `json:"stack"`
-		Icon      string  `json:"icon"`
-		Color     string  `json:"color"`
-		RepoURL   *string `json:"repo_url"`
-		WebURL    *string `json:"web_url"`
-		DocsURL   *string `json:"docs_url"`
-		Hosting   *string `json:"hosting"`
+		Name     string  `json:"name"`
+		Tagline  string  `json:"tagline"`
+		Status   string  `json:"status"`
+		Category string  `json:"category"`
+		Version  *string `json:"version"`
+		Stack    string  `json:"stack"`
+		Icon     string  `json:"icon"`
+		Color    string  `json:"color"`
+		RepoURL  *string `json:"repo_url"`
+		WebURL   *string `json:"web_url"`
+		DocsURL  *string `json:"docs_url"`
+		Hosting  *string `json:"hosting"`
+		// Notes is no longer stored on the project; see notes.go.
 		Notes     *string `json:"notes"`
 		SortOrder int     `json:"sort_order"`
 		TagIDs    []int64 `json:"tag_ids"`
+		// AbsorbedInto is the slug of the project an absorbed project
+		// became part of.
+		AbsorbedInto string `json:"absorbed_into"`
+		// StatusNote is recorded in the status history with the initial
+		// status.
+		StatusNote string `json:"status_note"`
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if input.Notes != nil && *input.Notes != "" {
+		return sdk.Error(400, "VALIDATION_ERROR", notesMovedMessage)
 	}
 
 	// Validate required fields.
 	if strings.TrimSpace(input.Name) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "name is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "name is required")
 	}
 	if len(input.Name) > 100 {
-		return jsonError(400, "VALIDATION_ERROR", "name must be 100 characters or less")
+		return sdk.Error(400, "VALIDATION_ERROR", "name must be 100 characters or less")
 	}
 	if strings.TrimSpace(input.Tagline) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "tagline is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "tagline is required")
 	}
 	if len(input.Tagline) > 200 {
-		return jsonError(400, "VALIDATION_ERROR", "tagline must be 200 characters or less")
+		return sdk.Error(400, "VALIDATION_ERROR", "tagline must be 200 characters or less")
 	}
 	if !isValidStatus(input.Status) {
-		return jsonError(400, "VALIDATION_ERROR", "status must be one of: concept, design, development, active, maintenance, archived, absorbed")
+		return sdk.Error(400, "VALIDATION_ERROR", "status must be one of: concept, design, development, active, maintenance, archived, absorbed")
 	}
 	if input.Category != "flagship" && input.Category != "lab" {
-		return jsonError(400, "VALIDATION_ERROR", "category must be 'flagship' or 'lab'")
+		return sdk.Error(400, "VALIDATION_ERROR", "category must be 'flagship' or 'lab'")
 	}
 	if strings.TrimSpace(input.Stack) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "stack is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "stack is required")
 	}
 	if input.Color != "" && !isValidHexColor(input.Color) {
-		return jsonError(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
+		return sdk.Error(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
+	}
+	if len(input.StatusNote) > maxStatusNoteLength {
+		return sdk.Error(400, "VALIDATION_ERROR", "status_note must be 500 characters or less")
 	}
 
 	// Validate URL fields (prevent javascript: XSS).
 	for _, u := range []*string{input.RepoURL, input.WebURL, input.DocsURL} {
 		if u != nil && *u != "" && !isValidURL(*u) {
-			return jsonError(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
+			return sdk.Error(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
 		}
 	}
 
 	// Generate slug from name.
 	slug := toSlug(input.Name)
+	if slug == graphSlug {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("'%s' is reserved and cannot be used as a project name", input.Name))
+	}
+
+	var absorbedIntoID int64
+	if input.AbsorbedInto != "" {
+		if input.Status != "absorbed" {
+			return sdk.Error(400, "VALIDATION_ERROR", "absorbed_into requires status 'absorbed'")
+		}
+		targetID, resp, err := p.absorbedTarget(0, input.AbsorbedInto)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		absorbedIntoID = targetID
+	}
 
 	// Defaults.
 	if input.Icon == "" {
@@ -463,21 +691,25 @@ func (p *ProjectHubPlugin) createProject(req *sdk.APIRequest) (*sdk.APIResponse,
 	}
 
 	result, err := p.db.Exec(
-		`INSERT INTO projects (name, slug, tagline, status, category, version, stack, icon, color, repo_url, web_url, docs_url, hosting, notes, sort_order)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO projects (name, slug, tagline, status, category, version, stack, icon, color, repo_url, web_url, docs_url, hosting, sort_order)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		input.Name, slug, input.Tagline, input.Status, input.Category, input.Version,
 		input.Stack, input.Icon, input.Color, input.RepoURL, input.WebURL, input.DocsURL,
-		input.Hosting, input.Notes, input.SortOrder,
+		input.Hosting, input.SortOrder,
 	)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE") {
-			return jsonError(409, "CONFLICT", "a project with this name or slug already exists")
+			return sdk.Error(409, "CONFLICT", "a project with this name or slug already exists")
 		}
 		return nil, fmt.Errorf("inserting project: %w", err)
 	}
 
 	id, _ := result.LastInsertId()
 
+	if err := recordStatusChange(p.db, id, "", input.Status, strings.TrimSpace(input.StatusNote), false); err != nil {
+		return nil, err
+	}
+
 	// Assign tags if provided.
 	for _, tagID := range input.TagIDs {
 		if _, err := p.db.Exec("INSERT OR IGNORE INTO project_tags (project_id, tag_id) VALUES (?, ?)", id, tagID); err != nil {
@@ -485,41 +717,76 @@ func (p *ProjectHubPlugin) createProject(req *sdk.APIRequest) (*sdk.APIResponse,
 		}
 	}
 
-	return jsonSuccess(201, map[string]interface{}{"id": id, "slug": slug})
+	if absorbedIntoID != 0 {
+		if err := p.setAbsorbedInto(id, absorbedIntoID); err != nil {
+			return nil, err
+		}
+	}
+
+	return sdk.Success(201, map[string]interface{}{"id": id, "slug": slug})
 }
 
 func (p *ProjectHubPlugin) updateProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
-	slug := extractPathParam(req.Path, "/projects/")
+	slug := req.PathParams["slug"]
 
 	// Check project exists.
 	var projectID int64
-	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
+	var projectName, previousStatus string
+	err := p.db.QueryRow("SELECT id, name, status FROM projects WHERE slug = ?", slug).Scan(&projectID, &projectName, &previousStatus)
 	if err == sql.ErrNoRows {
-		return jsonError(404, "NOT_FOUND", "project not found")
+		return sdk.Error(404, "NOT_FOUND", "project not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("querying project: %w", err)
 	}
 
 	var input struct {
-		Name      *string `json:"name"`
-		Tagline   *string `json:"tagline"`
-		Status    *string `json:"status"`
-		Category  *string `json:"category"`
-		Version   *string `json:"version"`
-		Stack     *string `json:"stack"`
-		Icon      *string `json:"icon"`
-		Color     *string `json:"color"`
-		RepoURL   *string `json:"repo_url"`
-		WebURL    *string `json:"web_url"`
-		DocsURL   *string `json:"docs_url"`
-		Hosting   *string `json:"hosting"`
+		Name     *string `json:"name"`
+		Tagline  *string `json:"tagline"`
+		Status   *string `json:"status"`
+		Category *string `json:"category"`
+		Version  *string `json:"version"`
+		Stack    *string `json:"stack"`
+		Icon     *string `json:"icon"`
+		Color    *string `json:"color"`
+		RepoURL  *string `json:"repo_url"`
+		WebURL   *string `json:"web_url"`
+		DocsURL  *string `json:"docs_url"`
+		Hosting  *string `json:"hosting"`
+		// Notes is no longer stored on the project; see notes.go.
 		Notes     *string `json:"notes"`
 		SortOrder *int    `json:"sort_order"`
+		// AbsorbedInto records, or replaces, the project this one was
+		// absorbed into. It is only accepted while the status is absorbed.
+		AbsorbedInto *string `json:"absorbed_into"`
+		// StatusNote is recorded in the status history with a status
+		// change, and Force allows one statusTransitions does not.
+		StatusNote *string `json:"status_note"`
+		Force      bool    `json:"force"`
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if input.Notes != nil && *input.Notes != "" {
+		return sdk.Error(400, "VALIDATION_ERROR", notesMovedMessage)
+	}
+
+	status := previousStatus
+	if input.Status != nil {
+		status = *input.Status
+	}
+	var absorbedIntoID int64
+	if input.AbsorbedInto != nil && *input.AbsorbedInto != "" {
+		if status != "absorbed" {
+			return sdk.Error(400, "VALIDATION_ERROR", "absorbed_into requires status 'absorbed'")
+		}
+		targetID, resp, err := p.absorbedTarget(projectID, *input.AbsorbedInto)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		absorbedIntoID = targetID
 	}
 
 	// Build dynamic update query.
@@ -528,34 +795,52 @@ func (p *ProjectHubPlugin) updateProject(req *sdk.APIRequest) (*sdk.APIResponse,
 
 	if input.Name != nil {
 		if strings.TrimSpace(*input.Name) == "" {
-			return jsonError(400, "VALIDATION_ERROR", "name is required")
+			return sdk.Error(400, "VALIDATION_ERROR", "name is required")
 		}
 		if len(*input.Name) > 100 {
-			return jsonError(400, "VALIDATION_ERROR", "name must be 100 characters or less")
+			return sdk.Error(400, "VALIDATION_ERROR", "name must be 100 characters or less")
 		}
 		setClauses = append(setClauses, "name = ?")
 		args = append(args, *input.Name)
 	}
 	if input.Tagline != nil {
 		if strings.TrimSpace(*input.Tagline) == "" {
-			return jsonError(400, "VALIDATION_ERROR", "tagline is required")
+			return sdk.Error(400, "VALIDATION_ERROR", "tagline is required")
 		}
 		if len(*input.Tagline) > 200 {
-			return jsonError(400, "VALIDATION_ERROR", "tagline must be 200 characters or less")
+			return sdk.Error(400, "VALIDATION_ERROR", "tagline must be 200 characters or less")
 		}
 		setClauses = append(setClauses, "tagline = ?")
 		args = append(args, *input.Tagline)
 	}
+	statusChanged := input.Status != nil && *input.Status != previousStatus
+	forced := false
+	if input.StatusNote != nil {
+		if !statusChanged {
+			return sdk.Error(400, "VALIDATION_ERROR", "status_note requires a status change")
+		}
+		if len(*input.StatusNote) > maxStatusNoteLength {
+			return sdk.Error(400, "VALIDATION_ERROR", "status_note must be 500 characters or less")
+		}
+	}
 	if input.Status != nil {
 		if !isValidStatus(*input.Status) {
-			return jsonError(400, "VALIDATION_ERROR", "status must be one of: concept, design, development, active, maintenance, archived, absorbed")
+			return sdk.Error(400, "VALIDATION_ERROR", "status must be one of: concept, design, development, active, maintenance, archived, absorbed")
+		}
+		if statusChanged {
+			if reason := checkTransition(previousStatus, *input.Status); reason != "" {
+				if !input.Force {
+					return sdk.Error(409, "INVALID_TRANSITION", reason)
+				}
+				forced = true
+			}
 		}
 		setClauses = append(setClauses, "status = ?")
 		args = append(args, *input.Status)
 	}
 	if input.Category != nil {
 		if *input.Category != "flagship" && *input.Category != "lab" {
-			return jsonError(400, "VALIDATION_ERROR", "category must be 'flagship' or 'lab'")
+			return sdk.Error(400, "VALIDATION_ERROR", "category must be 'flagship' or 'lab'")
 		}
 		setClauses = append(setClauses, "category = ?")
 		args = append(args, *input.Category)
@@ -566,7 +851,7 @@ func (p *ProjectHubPlugin) updateProject(req *sdk.APIRequest) (*sdk.APIResponse,
 	}
 	if input.Stack != nil {
 		if strings.TrimSpace(*input.Stack) == "" {
-			return jsonError(400, "VALIDATION_ERROR", "stack is required")
+			return sdk.Error(400, "VALIDATION_ERROR", "stack is required")
 		}
 		setClauses = append(setClauses, "stack = ?")
 		args = append(args, *input.Stack)
@@ -577,28 +862,28 @@ func (p *ProjectHubPlugin) updateProject(req *sdk.APIRequest) (*sdk.APIResponse,
 	}
 	if input.Color != nil {
 		if *input.Color != "" && !isValidHexColor(*input.Color) {
-			return jsonError(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
+			return sdk.Error(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
 		}
 		setClauses = append(setClauses, "color = ?")
 		args = append(args, *input.Color)
 	}
 	if input.RepoURL != nil {
 		if *input.RepoURL != "" && !isValidURL(*input.RepoURL) {
-			return jsonError(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
+			return sdk.Error(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
 		}
 		setClauses = append(setClauses, "repo_url = ?")
 		args = append(args, *input.RepoURL)
 	}
 	if input.WebURL != nil {
 		if *input.WebURL != "" && !isValidURL(*input.WebURL) {
-			return jsonError(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
+			return sdk.Error(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
 		}
 		setClauses = append(setClauses, "web_url = ?")
 		args = append(args, *input.WebURL)
 	}
 	if input.DocsURL != nil {
 		if *input.DocsURL != "" && !isValidURL(*input.DocsURL) {
-			return jsonError(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
+			return sdk.Error(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
 		}
 		setClauses = append(setClauses, "docs_url = ?")
 		args = append(args, *input.DocsURL)
@@ -607,17 +892,13 @@ func (p *ProjectHubPlugin) updateProject(req *sdk.APIRequest) (*sdk.APIResponse,
 		setClauses = append(setClauses, "hosting = ?")
 		args = append(args, *input.Hosting)
 	}
-	if input.Notes != nil {
-		setClauses = append(setClauses, "notes = ?")
-		args = append(args, *input.Notes)
-	}
 	if input.SortOrder != nil {
 		setClauses = append(setClauses, "sort_order = ?")
 		args = append(args, *input.SortOrder)
 	}
 
-	if len(setClauses) == 0 {
-		return jsonError(400, "VALIDATION_ERROR", "no fields to update")
+	if len(setClauses) == 0 && absorbedIntoID == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "no fields to update")
 	}
 
 	// Always update updated_at.
@@ -626,30 +907,101 @@ func (p *ProjectHubPlugin) updateProject(req *sdk.APIRequest) (*sdk.APIResponse,
 	query := fmt.Sprintf("UPDATE projects SET %s WHERE id = ?", strings.Join(setClauses, ", "))
 	args = append(args, projectID)
 
-	if _, err := p.db.Exec(query, args...); err != nil {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(query, args...); err != nil {
 		if strings.Contains(err.Error(), "UNIQUE") {
-			return jsonError(409, "CONFLICT", "a project with this name already exists")
+			return sdk.Error(409, "CONFLICT", "a project with this name already exists")
 		}
 		return nil, fmt.Errorf("updating project: %w", err)
 	}
+	if statusChanged {
+		var note string
+		if input.StatusNote != nil {
+			note = strings.TrimSpace(*input.StatusNote)
+		}
+		if err := recordStatusChange(tx, projectID, previousStatus, *input.Status, note, forced); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	// An absorbed project points at the project it became part of; one that
+	// is no longer absorbed drops that relation.
+	if absorbedIntoID != 0 {
+		if err := p.setAbsorbedInto(projectID, absorbedIntoID); err != nil {
+			return nil, err
+		}
+	} else if previousStatus == "absorbed" && status != "absorbed" {
+		if _, err := p.db.Exec("DELETE FROM project_relations WHERE project_id = ? AND kind = ?", projectID, relationAbsorbedInto); err != nil {
+			return nil, fmt.Errorf("clearing absorbed-into relation: %w", err)
+		}
+	}
 
-	return jsonSuccess(200, map[string]interface{}{"updated": slug})
+	if statusChanged {
+		if input.Name != nil {
+			projectName = *input.Name
+		}
+		title := fmt.Sprintf("%s is now %s", projectName, *input.Status)
+		body := fmt.Sprintf("Status changed from %s to %s.", previousStatus, *input.Status)
+		if err := p.notifyWatchers(projectID, notificationStatus, title, body); err != nil {
+			return nil, err
+		}
+	}
+
+	return sdk.Success(200, map[string]interface{}{"updated": slug})
 }
 
+// deleteProject handles DELETE /projects/{slug}/permanent, which removes a
+// project and everything attached to it. It must be confirmed by repeating
+// the slug as ?confirm={slug}; the host can still undo it within its undo
+// window.
 func (p *ProjectHubPlugin) deleteProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
-	slug := extractPathParam(req.Path, "/projects/")
+	slug := req.PathParams["slug"]
+	if req.Query["confirm"] != slug {
+		return sdk.Error(400, "CONFIRMATION_REQUIRED", "deleting a project for good must be confirmed with ?confirm="+slug+"; DELETE /projects/"+slug+" archives it instead")
+	}
 
-	result, err := p.db.Exec("DELETE FROM projects WHERE slug = ?", slug)
+	tx, err := p.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("deleting project: %w", err)
+		return nil, fmt.Errorf("beginning transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return jsonError(404, "NOT_FOUND", "project not found")
+	var projectID int64
+	err = tx.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying project: %w", err)
+	}
+
+	// Keep a copy of the project and everything that cascades with it, for
+	// the host to offer an undo
+	deleted, err := snapshotProject(tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM projects WHERE id = ?", projectID); err != nil {
+		return nil, fmt.Errorf("deleting project: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
 	}
 
-	return jsonSuccess(200, map[string]interface{}{"deleted": slug})
+	response, err := sdk.Success(200, map[string]interface{}{"deleted": slug})
+	if err != nil {
+		return nil, err
+	}
+	return sdk.WithDeleted(response, deletedProjectKind, deleted)
 }
 
 // --- Link handlers ---
@@ -658,7 +1010,7 @@ func (p *ProjectHubPlugin) createLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 	// Extract slug from /projects/{slug}/links
 	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
 	if len(pathParts) < 3 {
-		return jsonError(400, "VALIDATION_ERROR", "invalid path")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
 	}
 	slug := pathParts[1]
 
@@ -666,7 +1018,7 @@ func (p *ProjectHubPlugin) createLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 	var projectID int64
 	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
 	if err == sql.ErrNoRows {
-		return jsonError(404, "NOT_FOUND", "project not found")
+		return sdk.Error(404, "NOT_FOUND", "project not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("querying project: %w", err)
@@ -679,17 +1031,17 @@ func (p *ProjectHubPlugin) createLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
 	}
 
 	if strings.TrimSpace(input.Label) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "label is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "label is required")
 	}
 	if strings.TrimSpace(input.URL) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "url is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "url is required")
 	}
 	if !isValidURL(input.URL) {
-		return jsonError(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
+		return sdk.Error(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
 	}
 
 	result, err := p.db.Exec(
@@ -701,7 +1053,7 @@ func (p *ProjectHubPlugin) createLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 	}
 
 	id, _ := result.LastInsertId()
-	return jsonSuccess(201, map[string]interface{}{"id": id})
+	return sdk.Success(201, map[string]interface{}{"id": id})
 }
 
 func (p *ProjectHubPlugin) updateLink(req *sdk.APIRequest) (*sdk.APIResponse, error) {
@@ -714,7 +1066,7 @@ func (p *ProjectHubPlugin) updateLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
 	}
 
 	setClauses := make([]string, 0)
@@ -722,17 +1074,17 @@ func (p *ProjectHubPlugin) updateLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 
 	if input.Label != nil {
 		if strings.TrimSpace(*input.Label) == "" {
-			return jsonError(400, "VALIDATION_ERROR", "label is required")
+			return sdk.Error(400, "VALIDATION_ERROR", "label is required")
 		}
 		setClauses = append(setClauses, "label = ?")
 		args = append(args, *input.Label)
 	}
 	if input.URL != nil {
 		if strings.TrimSpace(*input.URL) == "" {
-			return jsonError(400, "VALIDATION_ERROR", "url is required")
+			return sdk.Error(400, "VALIDATION_ERROR", "url is required")
 		}
 		if !isValidURL(*input.URL) {
-			return jsonError(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
+			return sdk.Error(400, "VALIDATION_ERROR", "URLs must use http:// or https://")
 		}
 		setClauses = append(setClauses, "url = ?")
 		args = append(args, *input.URL)
@@ -743,7 +1095,7 @@ func (p *ProjectHubPlugin) updateLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 	}
 
 	if len(setClauses) == 0 {
-		return jsonError(400, "VALIDATION_ERROR", "no fields to update")
+		return sdk.Error(400, "VALIDATION_ERROR", "no fields to update")
 	}
 
 	query := fmt.Sprintf("UPDATE project_links SET %s WHERE id = ?", strings.Join(setClauses, ", "))
@@ -756,10 +1108,10 @@ func (p *ProjectHubPlugin) updateLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return jsonError(404, "NOT_FOUND", "link not found")
+		return sdk.Error(404, "NOT_FOUND", "link not found")
 	}
 
-	return jsonSuccess(200, map[string]interface{}{"updated": id})
+	return sdk.Success(200, map[string]interface{}{"updated": id})
 }
 
 func (p *ProjectHubPlugin) deleteLink(req *sdk.APIRequest) (*sdk.APIResponse, error) {
@@ -772,10 +1124,10 @@ func (p *ProjectHubPlugin) deleteLink(req *sdk.APIRequest) (*sdk.APIResponse, er
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return jsonError(404, "NOT_FOUND", "link not found")
+		return sdk.Error(404, "NOT_FOUND", "link not found")
 	}
 
-	return jsonSuccess(200, map[string]interface{}{"deleted": id})
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
 }
 
 // --- Tag handlers ---
@@ -800,7 +1152,7 @@ func (p *ProjectHubPlugin) listTags(req *sdk.APIRequest) (*sdk.APIResponse, erro
 		return nil, fmt.Errorf("iterating tags: %w", err)
 	}
 
-	return jsonSuccess(200, tags)
+	return sdk.Success(200, tags)
 }
 
 func (p *ProjectHubPlugin) createTag(req *sdk.APIRequest) (*sdk.APIResponse, error) {
@@ -810,32 +1162,32 @@ func (p *ProjectHubPlugin) createTag(req *sdk.APIRequest) (*sdk.APIResponse, err
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
 	}
 
 	if strings.TrimSpace(input.Name) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "name is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "name is required")
 	}
 	if len(input.Name) > 50 {
-		return jsonError(400, "VALIDATION_ERROR", "name must be 50 characters or less")
+		return sdk.Error(400, "VALIDATION_ERROR", "name must be 50 characters or less")
 	}
 	if input.Color == "" {
 		input.Color = "#6B7280"
 	}
 	if !isValidHexColor(input.Color) {
-		return jsonError(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
+		return sdk.Error(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
 	}
 
 	result, err := p.db.Exec("INSERT INTO tags (name, color) VALUES (?, ?)", input.Name, input.Color)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE") {
-			return jsonError(409, "CONFLICT", "a tag with this name already exists")
+			return sdk.Error(409, "CONFLICT", "a tag with this name already exists")
 		}
 		return nil, fmt.Errorf("inserting tag: %w", err)
 	}
 
 	id, _ := result.LastInsertId()
-	return jsonSuccess(201, map[string]interface{}{"id": id, "name": input.Name, "color": input.Color})
+	return sdk.Success(201, map[string]interface{}{"id": id, "name": input.Name, "color": input.Color})
 }
 
 func (p *ProjectHubPlugin) deleteTag(req *sdk.APIRequest) (*sdk.APIResponse, error) {
@@ -848,10 +1200,131 @@ func (p *ProjectHubPlugin) deleteTag(req *sdk.APIRequest) (*sdk.APIResponse, err
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return jsonError(404, "NOT_FOUND", "tag not found")
+		return sdk.Error(404, "NOT_FOUND", "tag not found")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// updateTag renames or recolors a tag. Tag names are unique regardless of
+// case, so changing only the case of a name is a rename of the same tag.
+func (p *ProjectHubPlugin) updateTag(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id, err := strconv.ParseInt(extractPathParam(req.Path, "/tags/"), 10, 64)
+	if err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid tag ID")
+	}
+
+	var input struct {
+		Name  *string `json:"name"`
+		Color *string `json:"color"`
+	}
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+	if input.Name == nil && input.Color == nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "name or color is required")
+	}
+
+	var tag Tag
+	err = p.db.QueryRow("SELECT id, name, color FROM tags WHERE id = ?", id).Scan(&tag.ID, &tag.Name, &tag.Color)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "tag not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying tag: %w", err)
+	}
+
+	if input.Name != nil {
+		if strings.TrimSpace(*input.Name) == "" {
+			return sdk.Error(400, "VALIDATION_ERROR", "name is required")
+		}
+		if len(*input.Name) > 50 {
+			return sdk.Error(400, "VALIDATION_ERROR", "name must be 50 characters or less")
+		}
+		tag.Name = *input.Name
+	}
+	if input.Color != nil {
+		if !isValidHexColor(*input.Color) {
+			return sdk.Error(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
+		}
+		tag.Color = *input.Color
+	}
+
+	if _, err := p.db.Exec("UPDATE tags SET name = ?, color = ? WHERE id = ?", tag.Name, tag.Color, id); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return sdk.Error(409, "CONFLICT", "a tag with this name already exists; merge the tags instead")
+		}
+		return nil, fmt.Errorf("updating tag: %w", err)
+	}
+
+	return sdk.Success(200, tag)
+}
+
+// mergeTags folds the source tag into the target: every project tagged with
+// the source is tagged with the target instead, and the source is deleted.
+func (p *ProjectHubPlugin) mergeTags(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input struct {
+		SourceID int64 `json:"source_id"`
+		TargetID int64 `json:"target_id"`
+	}
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+	if input.SourceID == 0 || input.TargetID == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "source_id and target_id are required")
+	}
+	if input.SourceID == input.TargetID {
+		return sdk.Error(400, "VALIDATION_ERROR", "cannot merge a tag into itself")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var target Tag
+	err = tx.QueryRow("SELECT id, name, color FROM tags WHERE id = ?", input.TargetID).Scan(&target.ID, &target.Name, &target.Color)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "target tag not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying target tag: %w", err)
+	}
+
+	var sourceName string
+	err = tx.QueryRow("SELECT name FROM tags WHERE id = ?", input.SourceID).Scan(&sourceName)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "source tag not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying source tag: %w", err)
+	}
+
+	// Projects already tagged with both keep a single target tag; their
+	// source rows go with the source tag.
+	result, err := tx.Exec(
+		"INSERT OR IGNORE INTO project_tags (project_id, tag_id) SELECT project_id, ? FROM project_tags WHERE tag_id = ?",
+		input.TargetID, input.SourceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("retagging projects: %w", err)
 	}
+	retagged, _ := result.RowsAffected()
 
-	return jsonSuccess(200, map[string]interface{}{"deleted": id})
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = ?", input.SourceID); err != nil {
+		return nil, fmt.Errorf("deleting source tag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing merge: %w", err)
+	}
+
+	return sdk.Success(200, map[string]interface{}{
+		"tag":      target,
+		"merged":   sourceName,
+		"retagged": retagged,
+	})
 }
 
 // setProjectTags replaces all tags for a project with the given tag IDs.
@@ -859,7 +1332,7 @@ func (p *ProjectHubPlugin) setProjectTags(req *sdk.APIRequest) (*sdk.APIResponse
 	// Extract slug from /projects/{slug}/tags
 	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
 	if len(pathParts) < 3 {
-		return jsonError(400, "VALIDATION_ERROR", "invalid path")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
 	}
 	slug := pathParts[1]
 
@@ -867,7 +1340,7 @@ func (p *ProjectHubPlugin) setProjectTags(req *sdk.APIRequest) (*sdk.APIResponse
 	var projectID int64
 	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
 	if err == sql.ErrNoRows {
-		return jsonError(404, "NOT_FOUND", "project not found")
+		return sdk.Error(404, "NOT_FOUND", "project not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("querying project: %w", err)
@@ -878,7 +1351,7 @@ func (p *ProjectHubPlugin) setProjectTags(req *sdk.APIRequest) (*sdk.APIResponse
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
 	}
 
 	// Replace all tags in a transaction.
@@ -909,7 +1382,7 @@ func (p *ProjectHubPlugin) setProjectTags(req *sdk.APIRequest) (*sdk.APIResponse
 		return nil, fmt.Errorf("updating project timestamp: %w", err)
 	}
 
-	return jsonSuccess(200, map[string]interface{}{"project": slug, "tag_count": len(input.TagIDs)})
+	return sdk.Success(200, map[string]interface{}{"project": slug, "tag_count": len(input.TagIDs)})
 }
 
 // --- Helpers ---
@@ -954,33 +1427,3 @@ func escapeLike(s string) string {
 	s = strings.ReplaceAll(s, `_`, `\_`)
 	return s
 }
-
-// --- JSON response helpers ---
-
-// jsonSuccess wraps data in `{ "data": ... }` format per PATTERNS.md.
-func jsonSuccess(status int, data interface{}) (*sdk.APIResponse, error) {
-	body, err := json.Marshal(map[string]interface{}{"data": data})
-	if err != nil {
-		return nil, fmt.Errorf("marshaling response: %w", err)
-	}
-	return &sdk.APIResponse{
-		StatusCode:  status,
-		Body:        body,
-		ContentType: "application/json",
-	}, nil
-}
-
-// jsonError wraps errors in `{ "error": { "code": ..., "message": ... } }` format per PATTERNS.md.
-func jsonError(status int, code string, message string) (*sdk.APIResponse, error) {
-	body, _ := json.Marshal(map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    code,
-			"message": message,
-		},
-	})
-	return &sdk.APIResponse{
-		StatusCode:  status,
-		Body:        body,
-		ContentType: "application/json",
-	}, nil
-}
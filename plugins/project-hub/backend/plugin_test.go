@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alvarotorresc/cortex/pkg/sdk"
 )
@@ -104,7 +108,7 @@ func TestMigrate_CreatesTables(t *testing.T) {
 func TestSeedData_LoadsProjects(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{},
@@ -131,7 +135,7 @@ func TestCreateProject_Valid(t *testing.T) {
 
 	body := `{"name": "Test Project", "tagline": "A test project", "status": "concept", "category": "lab", "stack": "Go, TypeScript"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects",
 		Body:   []byte(body),
@@ -164,7 +168,7 @@ func TestCreateProject_MissingName(t *testing.T) {
 
 	body := `{"tagline": "A test", "status": "concept", "category": "lab", "stack": "Go"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects",
 		Body:   []byte(body),
@@ -188,7 +192,7 @@ func TestCreateProject_InvalidStatus(t *testing.T) {
 
 	body := `{"name": "Bad Status", "tagline": "Test", "status": "unknown", "category": "lab", "stack": "Go"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects",
 		Body:   []byte(body),
@@ -213,7 +217,7 @@ func TestCreateProject_DuplicateName(t *testing.T) {
 	// "Cortex" already exists in seed data.
 	body := `{"name": "Cortex", "tagline": "Duplicate", "status": "concept", "category": "lab", "stack": "Go"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects",
 		Body:   []byte(body),
@@ -230,7 +234,7 @@ func TestCreateProject_DuplicateName(t *testing.T) {
 func TestGetProject_BySlug(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects/cortex",
 		Query:  map[string]string{},
@@ -263,7 +267,7 @@ func TestGetProject_BySlug(t *testing.T) {
 func TestGetProject_NotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects/nonexistent",
 		Query:  map[string]string{},
@@ -282,7 +286,7 @@ func TestUpdateProject(t *testing.T) {
 
 	body := `{"status": "active", "version": "v1.0.0"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   "/projects/cortex",
 		Body:   []byte(body),
@@ -296,7 +300,7 @@ func TestUpdateProject(t *testing.T) {
 	}
 
 	// Verify the update.
-	getResp, err := p.HandleAPI(&sdk.APIRequest{
+	getResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects/cortex",
 		Query:  map[string]string{},
@@ -324,7 +328,7 @@ func TestUpdateProject_NotFound(t *testing.T) {
 
 	body := `{"status": "active"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   "/projects/nonexistent",
 		Body:   []byte(body),
@@ -341,7 +345,7 @@ func TestUpdateProject_NotFound(t *testing.T) {
 func TestDeleteProject(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   "/projects/cortex",
 	})
@@ -354,7 +358,7 @@ func TestDeleteProject(t *testing.T) {
 	}
 
 	// Verify it's gone.
-	getResp, err := p.HandleAPI(&sdk.APIRequest{
+	getResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects/cortex",
 		Query:  map[string]string{},
@@ -371,7 +375,7 @@ func TestDeleteProject(t *testing.T) {
 func TestDeleteProject_NotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   "/projects/nonexistent",
 	})
@@ -389,7 +393,7 @@ func TestDeleteProject_NotFound(t *testing.T) {
 func TestListProjects_FilterByStatus(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{"status": "active"},
@@ -423,7 +427,7 @@ func TestListProjects_FilterByStatus(t *testing.T) {
 func TestListProjects_FilterByCategory(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{"category": "flagship"},
@@ -441,7 +445,7 @@ func TestListProjects_FilterByCategory(t *testing.T) {
 func TestListProjects_SearchByName(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{"search": "Swiss"},
@@ -459,7 +463,7 @@ func TestListProjects_SearchByName(t *testing.T) {
 func TestListProjects_CombinedFilters(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{"status": "concept", "category": "flagship"},
@@ -482,7 +486,7 @@ func TestCreateLink(t *testing.T) {
 
 	body := `{"label": "Play Store", "url": "https://play.google.com/store/apps/details?id=com.example"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects/cortex/links",
 		Body:   []byte(body),
@@ -496,7 +500,7 @@ func TestCreateLink(t *testing.T) {
 	}
 
 	// Verify link appears in project detail.
-	getResp, err := p.HandleAPI(&sdk.APIRequest{
+	getResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects/cortex",
 		Query:  map[string]string{},
@@ -524,7 +528,7 @@ func TestCreateLink_MissingLabel(t *testing.T) {
 
 	body := `{"url": "https://example.com"}`
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects/cortex/links",
 		Body:   []byte(body),
@@ -543,7 +547,7 @@ func TestUpdateLink(t *testing.T) {
 
 	// Create a link first.
 	createBody := `{"label": "Old Label", "url": "https://old.com"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects/cortex/links",
 		Body:   []byte(createBody),
@@ -562,7 +566,7 @@ func TestUpdateLink(t *testing.T) {
 
 	// Update the link.
 	updateBody := `{"label": "New Label", "url": "https://new.com"}`
-	updateResp, err := p.HandleAPI(&sdk.APIRequest{
+	updateResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "PUT",
 		Path:   fmt.Sprintf("/links/%d", createResult.ID),
 		Body:   []byte(updateBody),
@@ -576,7 +580,7 @@ func TestUpdateLink(t *testing.T) {
 	}
 
 	// Verify the update took effect.
-	getResp, err := p.HandleAPI(&sdk.APIRequest{
+	getResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects/cortex",
 		Query:  map[string]string{},
@@ -607,7 +611,7 @@ func TestDeleteLink(t *testing.T) {
 
 	// Create a link first.
 	createBody := `{"label": "To Delete", "url": "https://delete.me"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects/cortex/links",
 		Body:   []byte(createBody),
@@ -625,7 +629,7 @@ func TestDeleteLink(t *testing.T) {
 	}
 
 	// Delete the link.
-	delResp, err := p.HandleAPI(&sdk.APIRequest{
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
 		Path:   fmt.Sprintf("/links/%d", createResult.ID),
 	})
@@ -638,7 +642,7 @@ func TestDeleteLink(t *testing.T) {
 	}
 
 	// Verify the link is actually gone.
-	getResp, err := p.HandleAPI(&sdk.APIRequest{
+	getResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects/cortex",
 		Query:  map[string]string{},
@@ -670,7 +674,7 @@ func TestDeleteProject_CascadesLinks(t *testing.T) {
 
 	// Create a link on Cortex.
 	linkBody := `{"label": "Test Link", "url": "https://test.com"}`
-	_, err = p.HandleAPI(&sdk.APIRequest{
+	_, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "POST",
 		Path:   "/projects/cortex/links",
 		Body:   []byte(linkBody),
@@ -689,10 +693,11 @@ func TestDeleteProject_CascadesLinks(t *testing.T) {
 		t.Fatalf("expected 1 link before delete, got %d", linkCountBefore)
 	}
 
-	// Delete the project.
-	delResp, err := p.HandleAPI(&sdk.APIRequest{
+	// Delete the project for good.
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "DELETE",
-		Path:   "/projects/cortex",
+		Path:   "/projects/cortex/permanent",
+		Query:  map[string]string{"confirm": "cortex"},
 	})
 	if err != nil {
 		t.Fatalf("delete project failed: %v", err)
@@ -713,6 +718,139 @@ func TestDeleteProject_CascadesLinks(t *testing.T) {
 	}
 }
 
+func TestDeleteProject_RestoreDeleted(t *testing.T) {
+	p := newTestPlugin(t)
+
+	var projectID int64
+	if err := p.db.QueryRow("SELECT id FROM projects WHERE slug = 'cortex'").Scan(&projectID); err != nil {
+		t.Fatalf("failed to get project ID: %v", err)
+	}
+	_, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/projects/cortex/links",
+		Body:   []byte(`{"label": "Test Link", "url": "https://test.com"}`),
+	})
+	if err != nil {
+		t.Fatalf("create link failed: %v", err)
+	}
+
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/projects/cortex/permanent", Query: map[string]string{"confirm": "cortex"}})
+	if err != nil {
+		t.Fatalf("delete project failed: %v", err)
+	}
+	if delResp.Deleted == nil || delResp.Deleted.Kind != deletedProjectKind {
+		t.Fatalf("expected the delete to attach the project, got %+v", delResp.Deleted)
+	}
+
+	if err := p.RestoreDeleted(delResp.Deleted.Kind, delResp.Deleted.Data); err != nil {
+		t.Fatalf("RestoreDeleted failed: %v", err)
+	}
+
+	var restoredID int64
+	if err := p.db.QueryRow("SELECT id FROM projects WHERE slug = 'cortex'").Scan(&restoredID); err != nil || restoredID != projectID {
+		t.Fatalf("expected the project back with ID %d, got %d, %v", projectID, restoredID, err)
+	}
+	var links, tags int
+	_ = p.db.QueryRow("SELECT COUNT(*) FROM project_links WHERE project_id = ?", projectID).Scan(&links)
+	_ = p.db.QueryRow("SELECT COUNT(*) FROM project_tags WHERE project_id = ?", projectID).Scan(&tags)
+	if links != 1 || tags == 0 {
+		t.Errorf("expected the link and tags back, got %d links and %d tags", links, tags)
+	}
+
+	// The project is there again; restoring it twice would duplicate it.
+	if err := p.RestoreDeleted(delResp.Deleted.Kind, delResp.Deleted.Data); !errors.Is(err, sdk.ErrRestoreConflict) {
+		t.Errorf("expected ErrRestoreConflict restoring again, got %v", err)
+	}
+}
+
+func TestExportImportData_MovesDataBetweenInstances(t *testing.T) {
+	source := newTestPlugin(t)
+	resp, err := source.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/projects",
+		Body:   []byte(`{"name": "Exported Project", "tagline": "Moves between instances", "status": "concept", "category": "lab", "stack": "Go"}`),
+	})
+	if err != nil {
+		t.Fatalf("create project failed: %v", err)
+	}
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected status 201, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	if _, err := source.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/projects/exported-project/links",
+		Body:   []byte(`{"label": "Repo", "url": "https://example.com"}`),
+	}); err != nil {
+		t.Fatalf("create link failed: %v", err)
+	}
+
+	data, err := source.ExportData()
+	if err != nil {
+		t.Fatalf("ExportData failed: %v", err)
+	}
+
+	target := newTestPlugin(t)
+	if _, err := target.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/projects/cortex"}); err != nil {
+		t.Fatalf("delete project failed: %v", err)
+	}
+	if err := target.ImportData("1.0.0", data); err != nil {
+		t.Fatalf("ImportData failed: %v", err)
+	}
+
+	var projects, links int
+	_ = target.db.QueryRow("SELECT COUNT(*) FROM projects").Scan(&projects)
+	_ = target.db.QueryRow(
+		"SELECT COUNT(*) FROM project_links l JOIN projects p ON p.id = l.project_id WHERE p.slug = 'exported-project'",
+	).Scan(&links)
+	if projects != 17 || links != 1 {
+		t.Errorf("expected the 17 exported projects and the link, got %d projects and %d links", projects, links)
+	}
+	if resp, _ := target.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex"}); resp.StatusCode != 200 {
+		t.Errorf("expected the project deleted on the target to come back with the import, got status %d", resp.StatusCode)
+	}
+
+	if err := target.ImportData("1.0.0", []byte(`{"projects":[]}`)); !errors.Is(err, sdk.ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData for data that is not an export, got %v", err)
+	}
+}
+
+func TestImportData_SkipsDroppedNotificationsTable(t *testing.T) {
+	p := newTestPlugin(t)
+
+	var tables int
+	_ = p.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'notifications'").Scan(&tables)
+	if tables != 0 {
+		t.Fatal("expected migrations to drop the notifications table")
+	}
+
+	// Exports from before the table was dropped still carry its rows.
+	data, err := p.ExportData()
+	if err != nil {
+		t.Fatalf("ExportData failed: %v", err)
+	}
+	var snapshots []sdk.DeletedRows
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	snapshots = append(snapshots, sdk.DeletedRows{
+		Table: "notifications",
+		Rows:  []map[string]interface{}{{"id": 1, "project_id": 1, "kind": "status", "title": "Cortex is now active", "is_read": 0}},
+	})
+	data, err = json.Marshal(snapshots)
+	if err != nil {
+		t.Fatalf("failed to marshal export: %v", err)
+	}
+
+	if err := p.ImportData("0.1.0", data); err != nil {
+		t.Fatalf("ImportData failed: %v", err)
+	}
+	var projects int
+	_ = p.db.QueryRow("SELECT COUNT(*) FROM projects").Scan(&projects)
+	if projects != 16 {
+		t.Errorf("expected the 16 exported projects, got %d", projects)
+	}
+}
+
 // --- Widget tests ---
 
 func TestWidgetData_CountsByStatus(t *testing.T) {
@@ -780,7 +918,7 @@ func TestWidgetData_UnknownSlot(t *testing.T) {
 func TestRouteNotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/nonexistent",
 		Query:  map[string]string{},
@@ -799,13 +937,36 @@ func TestRouteNotFound(t *testing.T) {
 	}
 }
 
+func TestRouteMethodNotAllowed(t *testing.T) {
+	p := newTestPlugin(t)
+
+	// Links can only be created under a project, not listed there.
+	resp := callAPI(t, p, "GET", "/projects/cortex/links", "", 405)
+	if code, _ := parseErrorResponse(t, resp); code != "METHOD_NOT_ALLOWED" {
+		t.Errorf("expected METHOD_NOT_ALLOWED, got '%s'", code)
+	}
+	if resp.Headers["Allow"] != "POST" {
+		t.Errorf("expected Allow: POST, got '%s'", resp.Headers["Allow"])
+	}
+}
+
+func TestRouteDoesNotMatchDeeperPaths(t *testing.T) {
+	p := newTestPlugin(t)
+
+	callAPI(t, p, "GET", "/projects/cortex", "", 200)
+	callAPI(t, p, "GET", "/projects/graph", "", 200)
+	callAPI(t, p, "GET", "/projects/cortex/unknown", "", 404)
+	callAPI(t, p, "DELETE", "/links/1/extra", "", 404)
+	callAPI(t, p, "GET", "/projects/", "", 404)
+}
+
 // --- Additional validation tests ---
 
 func TestCreateProject_MissingTagline(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name": "No Tagline", "status": "concept", "category": "lab", "stack": "Go"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -825,7 +986,7 @@ func TestCreateProject_InvalidCategory(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name": "Bad Cat", "tagline": "Test", "status": "concept", "category": "unknown", "stack": "Go"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -842,7 +1003,7 @@ func TestCreateProject_MissingStack(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name": "No Stack", "tagline": "Test", "status": "concept", "category": "lab"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -863,7 +1024,7 @@ func TestCreateProject_NameTooLong(t *testing.T) {
 		longName[i] = 'a'
 	}
 	body := fmt.Sprintf(`{"name": "%s", "tagline": "Test", "status": "concept", "category": "lab", "stack": "Go"}`, string(longName))
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -880,7 +1041,7 @@ func TestCreateProject_InvalidColor(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name": "Bad Color", "tagline": "Test", "status": "concept", "category": "lab", "stack": "Go", "color": "not-hex"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -896,7 +1057,7 @@ func TestCreateProject_InvalidColor(t *testing.T) {
 func TestCreateProject_InvalidJSON(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(`not json`)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(`not json`)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -908,7 +1069,7 @@ func TestCreateProject_InvalidJSON(t *testing.T) {
 func TestUpdateProject_NoFields(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "PUT", Path: "/projects/cortex", Body: []byte(`{}`)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: "/projects/cortex", Body: []byte(`{}`)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -920,7 +1081,7 @@ func TestUpdateProject_NoFields(t *testing.T) {
 func TestUpdateProject_InvalidStatus(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "PUT", Path: "/projects/cortex", Body: []byte(`{"status": "banana"}`)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: "/projects/cortex", Body: []byte(`{"status": "banana"}`)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -933,7 +1094,7 @@ func TestCreateLink_NonexistentProject(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"label": "Test", "url": "https://test.com"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects/nonexistent/links", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects/nonexistent/links", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -946,7 +1107,7 @@ func TestCreateLink_MissingURL(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"label": "Missing URL"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects/cortex/links", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects/cortex/links", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -958,7 +1119,7 @@ func TestCreateLink_MissingURL(t *testing.T) {
 func TestDeleteLink_NotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "DELETE", Path: "/links/99999"})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/links/99999"})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -970,7 +1131,7 @@ func TestDeleteLink_NotFound(t *testing.T) {
 func TestUpdateLink_NotFound(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "PUT", Path: "/links/99999", Body: []byte(`{"label": "test"}`)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: "/links/99999", Body: []byte(`{"label": "test"}`)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -998,7 +1159,7 @@ func TestWidgetData_AfterMutation(t *testing.T) {
 	p := newTestPlugin(t)
 
 	// Delete one active project to change counts.
-	_, err := p.HandleAPI(&sdk.APIRequest{Method: "DELETE", Path: "/projects/sinherencia"})
+	_, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/projects/sinherencia"})
 	if err != nil {
 		t.Fatalf("delete failed: %v", err)
 	}
@@ -1029,7 +1190,7 @@ func TestWidgetData_AfterMutation(t *testing.T) {
 func TestListProjects_EmptyResultReturnsArray(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{"status": "archived"},
@@ -1051,7 +1212,7 @@ func TestSearchWithLikeWildcards(t *testing.T) {
 	p := newTestPlugin(t)
 
 	// Search with % should not match everything.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{"search": "%"},
@@ -1094,7 +1255,7 @@ func TestToSlug(t *testing.T) {
 func TestSeedData_CreatesTags(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "GET", Path: "/tags", Query: map[string]string{}})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/tags", Query: map[string]string{}})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1114,7 +1275,7 @@ func TestSeedData_AssignsTagsToProjects(t *testing.T) {
 	p := newTestPlugin(t)
 
 	// Cortex should have Go, SvelteKit, gRPC, SQLite tags
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "GET", Path: "/projects/cortex", Query: map[string]string{}})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex", Query: map[string]string{}})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1144,7 +1305,7 @@ func TestSeedData_AssignsTagsToProjects(t *testing.T) {
 func TestListProjects_IncludesTags(t *testing.T) {
 	p := newTestPlugin(t)
 
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "GET", Path: "/projects", Query: map[string]string{}})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects", Query: map[string]string{}})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1173,7 +1334,7 @@ func TestListProjects_FilterByTag(t *testing.T) {
 	p := newTestPlugin(t)
 
 	// Filter by "Go" tag should return Cortex and Clipboard Manager.
-	resp, err := p.HandleAPI(&sdk.APIRequest{
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
 		Method: "GET",
 		Path:   "/projects",
 		Query:  map[string]string{"tag": "Go"},
@@ -1208,7 +1369,7 @@ func TestCreateTag(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"name": "Vue.js", "color": "#4FC08D"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1240,7 +1401,7 @@ func TestCreateTag_DuplicateName(t *testing.T) {
 
 	// "Go" already exists in seed data.
 	body := `{"name": "Go", "color": "#00ADD8"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1254,7 +1415,7 @@ func TestCreateTag_MissingName(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"color": "#FF0000"}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1269,7 +1430,7 @@ func TestDeleteTag(t *testing.T) {
 
 	// Create a tag then delete it.
 	createBody := `{"name": "ToDelete", "color": "#FF0000"}`
-	createResp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(createBody)})
+	createResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/tags", Body: []byte(createBody)})
 	if err != nil {
 		t.Fatalf("create tag failed: %v", err)
 	}
@@ -1282,7 +1443,7 @@ func TestDeleteTag(t *testing.T) {
 		t.Fatalf("failed to parse: %v", err)
 	}
 
-	delResp, err := p.HandleAPI(&sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/tags/%d", result.ID)})
+	delResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: fmt.Sprintf("/tags/%d", result.ID)})
 	if err != nil {
 		t.Fatalf("delete tag failed: %v", err)
 	}
@@ -1292,6 +1453,129 @@ func TestDeleteTag(t *testing.T) {
 	}
 }
 
+func TestUpdateTag_RenamesAndRecolors(t *testing.T) {
+	p := &ProjectHubPlugin{}
+	dbPath := filepath.Join(t.TempDir(), "project_hub_test.db")
+	if err := p.Migrate(dbPath); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	var svelteKitID, goID int64
+	if err := p.db.QueryRow("SELECT id FROM tags WHERE name = 'SvelteKit'").Scan(&svelteKitID); err != nil {
+		t.Fatalf("failed to get SvelteKit tag: %v", err)
+	}
+	if err := p.db.QueryRow("SELECT id FROM tags WHERE name = 'Go'").Scan(&goID); err != nil {
+		t.Fatalf("failed to get Go tag: %v", err)
+	}
+
+	// Changing only the case renames the same tag.
+	resp := callAPI(t, p, "PUT", fmt.Sprintf("/tags/%d", svelteKitID), `{"name": "Sveltekit"}`, 200)
+	var tag Tag
+	if err := json.Unmarshal(parseDataObject(t, resp), &tag); err != nil {
+		t.Fatalf("failed to unmarshal tag: %v", err)
+	}
+	if tag.Name != "Sveltekit" || tag.Color != "#FF3E00" {
+		t.Errorf("expected Sveltekit keeping its color, got %+v", tag)
+	}
+
+	resp = callAPI(t, p, "PUT", fmt.Sprintf("/tags/%d", svelteKitID), `{"color": "#FF3E01"}`, 200)
+	if err := json.Unmarshal(parseDataObject(t, resp), &tag); err != nil {
+		t.Fatalf("failed to unmarshal tag: %v", err)
+	}
+	if tag.Name != "Sveltekit" || tag.Color != "#FF3E01" {
+		t.Errorf("expected a recolored Sveltekit, got %+v", tag)
+	}
+
+	resp = callAPI(t, p, "PUT", fmt.Sprintf("/tags/%d", goID), `{"name": "sveltekit"}`, 409)
+	if code, _ := parseErrorResponse(t, resp); code != "CONFLICT" {
+		t.Errorf("expected CONFLICT for a name taken by another tag, got %s", code)
+	}
+	callAPI(t, p, "PUT", fmt.Sprintf("/tags/%d", goID), `{"color": "blue"}`, 400)
+	callAPI(t, p, "PUT", fmt.Sprintf("/tags/%d", goID), `{"name": " "}`, 400)
+	callAPI(t, p, "PUT", fmt.Sprintf("/tags/%d", goID), `{}`, 400)
+	callAPI(t, p, "PUT", "/tags/99999", `{"name": "Gone"}`, 404)
+
+	// The seed does not bring a renamed tag back on the next load.
+	var tbdID int64
+	if err := p.db.QueryRow("SELECT id FROM tags WHERE name = 'TBD'").Scan(&tbdID); err != nil {
+		t.Fatalf("failed to get TBD tag: %v", err)
+	}
+	callAPI(t, p, "PUT", fmt.Sprintf("/tags/%d", tbdID), `{"name": "Undecided"}`, 200)
+
+	p.Teardown()
+	p = &ProjectHubPlugin{}
+	if err := p.Migrate(dbPath); err != nil {
+		t.Fatalf("failed to migrate again: %v", err)
+	}
+	t.Cleanup(func() { p.Teardown() })
+
+	var count, tagged int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM tags WHERE name = 'TBD'").Scan(&count); err != nil {
+		t.Fatalf("failed to count tags: %v", err)
+	}
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM project_tags pt JOIN projects p ON p.id = pt.project_id WHERE p.slug = 'libroteca'").Scan(&tagged); err != nil {
+		t.Fatalf("failed to count project tags: %v", err)
+	}
+	if count != 0 || tagged != 1 {
+		t.Errorf("expected only the renamed tag after reload, got %d TBD tags and %d tags on libroteca", count, tagged)
+	}
+}
+
+func TestMergeTags_RetagsProjects(t *testing.T) {
+	p := newTestPlugin(t)
+
+	var goID, sqliteID int64
+	if err := p.db.QueryRow("SELECT id FROM tags WHERE name = 'Go'").Scan(&goID); err != nil {
+		t.Fatalf("failed to get Go tag: %v", err)
+	}
+	resp := callAPI(t, p, "POST", "/tags", `{"name": "Golang"}`, 201)
+	var golang Tag
+	if err := json.Unmarshal(parseDataObject(t, resp), &golang); err != nil {
+		t.Fatalf("failed to unmarshal tag: %v", err)
+	}
+	if err := p.db.QueryRow("SELECT id FROM tags WHERE name = 'SQLite'").Scan(&sqliteID); err != nil {
+		t.Fatalf("failed to get SQLite tag: %v", err)
+	}
+
+	// Cortex already has Go; libroteca gets only the duplicate.
+	callAPI(t, p, "POST", "/projects/cortex/tags", fmt.Sprintf(`{"tag_ids": [%d, %d, %d]}`, goID, golang.ID, sqliteID), 200)
+	callAPI(t, p, "POST", "/projects/libroteca/tags", fmt.Sprintf(`{"tag_ids": [%d]}`, golang.ID), 200)
+
+	resp = callAPI(t, p, "POST", "/tags/merge", fmt.Sprintf(`{"source_id": %d, "target_id": %d}`, golang.ID, goID), 200)
+	var merged struct {
+		Tag      Tag    `json:"tag"`
+		Merged   string `json:"merged"`
+		Retagged int64  `json:"retagged"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &merged); err != nil {
+		t.Fatalf("failed to unmarshal merge: %v", err)
+	}
+	if merged.Tag.ID != goID || merged.Merged != "Golang" || merged.Retagged != 1 {
+		t.Errorf("expected Golang merged into Go retagging one project, got %+v", merged)
+	}
+
+	var exists int
+	p.db.QueryRow("SELECT COUNT(*) FROM tags WHERE id = ?", golang.ID).Scan(&exists)
+	if exists != 0 {
+		t.Error("expected the source tag to be deleted")
+	}
+	for slug, want := range map[string]int{"cortex": 2, "libroteca": 1} {
+		var tagged, total int
+		p.db.QueryRow(
+			"SELECT COUNT(*) FILTER (WHERE pt.tag_id = ?), COUNT(*) FROM project_tags pt JOIN projects p ON p.id = pt.project_id WHERE p.slug = ?",
+			goID, slug,
+		).Scan(&tagged, &total)
+		if tagged != 1 || total != want {
+			t.Errorf("expected %s tagged Go once with %d tags, got %d and %d", slug, want, tagged, total)
+		}
+	}
+
+	callAPI(t, p, "POST", "/tags/merge", fmt.Sprintf(`{"source_id": %d, "target_id": %d}`, goID, goID), 400)
+	callAPI(t, p, "POST", "/tags/merge", fmt.Sprintf(`{"source_id": %d}`, goID), 400)
+	callAPI(t, p, "POST", "/tags/merge", fmt.Sprintf(`{"source_id": %d, "target_id": %d}`, golang.ID, goID), 404)
+	callAPI(t, p, "POST", "/tags/merge", fmt.Sprintf(`{"source_id": %d, "target_id": 99999}`, sqliteID), 404)
+}
+
 func TestSetProjectTags(t *testing.T) {
 	p := newTestPlugin(t)
 
@@ -1308,7 +1592,7 @@ func TestSetProjectTags(t *testing.T) {
 
 	// Set tags for Cortex to React + TypeScript (replacing Go, SvelteKit, gRPC, SQLite).
 	body := fmt.Sprintf(`{"tag_ids": [%d, %d]}`, reactID, tsID)
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects/cortex/tags", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects/cortex/tags", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1318,7 +1602,7 @@ func TestSetProjectTags(t *testing.T) {
 	}
 
 	// Verify tags changed.
-	getResp, err := p.HandleAPI(&sdk.APIRequest{Method: "GET", Path: "/projects/cortex", Query: map[string]string{}})
+	getResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex", Query: map[string]string{}})
 	if err != nil {
 		t.Fatalf("get project failed: %v", err)
 	}
@@ -1347,7 +1631,7 @@ func TestSetProjectTags_NonexistentProject(t *testing.T) {
 	p := newTestPlugin(t)
 
 	body := `{"tag_ids": [1]}`
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects/nonexistent/tags", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects/nonexistent/tags", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1368,7 +1652,7 @@ func TestCreateProject_WithTagIDs(t *testing.T) {
 	}
 
 	body := fmt.Sprintf(`{"name": "Tag Test", "tagline": "Test tags", "status": "concept", "category": "lab", "stack": "Go", "tag_ids": [%d]}`, goID)
-	resp, err := p.HandleAPI(&sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects", Body: []byte(body)})
 	if err != nil {
 		t.Fatalf("HandleAPI returned error: %v", err)
 	}
@@ -1378,7 +1662,7 @@ func TestCreateProject_WithTagIDs(t *testing.T) {
 	}
 
 	// Verify tags assigned.
-	getResp, err := p.HandleAPI(&sdk.APIRequest{Method: "GET", Path: "/projects/tag-test", Query: map[string]string{}})
+	getResp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/tag-test", Query: map[string]string{}})
 	if err != nil {
 		t.Fatalf("get project failed: %v", err)
 	}
@@ -1396,3 +1680,1287 @@ func TestCreateProject_WithTagIDs(t *testing.T) {
 		t.Errorf("expected tag 'Go', got '%s'", proj.Tags[0].Name)
 	}
 }
+
+// --- Stats tests ---
+
+func mustTimestamp(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := parseTimestamp(value)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestComputeProjectStats_WeeklySeries(t *testing.T) {
+	done := mustTimestamp(t, "2026-03-04 10:00:00")
+	tasks := []statsTask{
+		{MilestoneID: 1, CreatedAt: mustTimestamp(t, "2026-02-24 09:00:00"), CompletedAt: &done},
+		{MilestoneID: 1, CreatedAt: mustTimestamp(t, "2026-02-25 09:00:00")},
+		{MilestoneID: 1, CreatedAt: mustTimestamp(t, "2026-03-03 09:00:00")},
+	}
+
+	// 2026-03-11 is a Wednesday; the last week starts Monday 2026-03-09.
+	stats := computeProjectStats(tasks, nil, mustTimestamp(t, "2026-03-11 12:00:00"), 3)
+
+	if stats.OpenTasks != 2 || stats.ClosedTasks != 1 {
+		t.Errorf("expected 2 open and 1 closed, got %d and %d", stats.OpenTasks, stats.ClosedTasks)
+	}
+	if len(stats.Weekly) != 3 {
+		t.Fatalf("expected 3 weeks, got %d", len(stats.Weekly))
+	}
+
+	want := []WeeklyStat{
+		{WeekStart: "2026-02-23", Opened: 2, Completed: 0, Open: 2, Closed: 0},
+		{WeekStart: "2026-03-02", Opened: 1, Completed: 1, Open: 2, Closed: 1},
+		{WeekStart: "2026-03-09", Opened: 0, Completed: 0, Open: 2, Closed: 1},
+	}
+	for i, week := range stats.Weekly {
+		if week != want[i] {
+			t.Errorf("week %d: expected %+v, got %+v", i, want[i], week)
+		}
+	}
+	if stats.Burndown != nil {
+		t.Errorf("expected no burndown without a milestone")
+	}
+}
+
+func TestComputeProjectStats_Burndown(t *testing.T) {
+	due := mustTimestamp(t, "2026-03-05")
+	milestone := &statsMilestone{ID: 1, Name: "v1", StartDate: mustTimestamp(t, "2026-03-01"), DueDate: &due}
+
+	first := mustTimestamp(t, "2026-03-02 18:00:00")
+	second := mustTimestamp(t, "2026-03-03 08:00:00")
+	tasks := []statsTask{
+		{MilestoneID: 1, CreatedAt: mustTimestamp(t, "2026-03-01 09:00:00"), CompletedAt: &first},
+		{MilestoneID: 1, CreatedAt: mustTimestamp(t, "2026-03-01 09:00:00"), CompletedAt: &second},
+		{MilestoneID: 1, CreatedAt: mustTimestamp(t, "2026-03-01 09:00:00")},
+		{MilestoneID: 1, CreatedAt: mustTimestamp(t, "2026-03-01 09:00:00")},
+		{MilestoneID: 2, CreatedAt: mustTimestamp(t, "2026-03-01 09:00:00")},
+	}
+
+	stats := computeProjectStats(tasks, milestone, mustTimestamp(t, "2026-03-03 12:00:00"), 1)
+	burndown := stats.Burndown
+	if burndown == nil {
+		t.Fatal("expected a burndown")
+	}
+	if burndown.Total != 4 || len(burndown.Points) != 5 {
+		t.Fatalf("expected 4 tasks over 5 days, got %d tasks over %d days", burndown.Total, len(burndown.Points))
+	}
+
+	wantRemaining := []int{4, 3, 2}
+	for i, want := range wantRemaining {
+		point := burndown.Points[i]
+		if point.Remaining == nil || *point.Remaining != want {
+			t.Errorf("day %s: expected %d remaining, got %v", point.Date, want, point.Remaining)
+		}
+	}
+	for _, point := range burndown.Points[3:] {
+		if point.Remaining != nil {
+			t.Errorf("day %s: expected no remaining value for a future day", point.Date)
+		}
+	}
+	if burndown.Points[0].Ideal != 4 || burndown.Points[2].Ideal != 2 || burndown.Points[4].Ideal != 0 {
+		t.Errorf("unexpected ideal line: %+v", burndown.Points)
+	}
+}
+
+func TestGetProjectStats(t *testing.T) {
+	p := newTestPlugin(t)
+
+	result, err := p.db.Exec(
+		"INSERT INTO milestones (project_id, name, start_date) SELECT id, 'MVP', date('now', '-2 days') FROM projects WHERE slug = 'cortex'",
+	)
+	if err != nil {
+		t.Fatalf("inserting milestone: %v", err)
+	}
+	milestoneID, _ := result.LastInsertId()
+
+	if _, err := p.db.Exec(
+		`INSERT INTO tasks (milestone_id, title, status, completed_at) VALUES
+		    (?, 'Loader', 'done', datetime('now')),
+		    (?, 'Proxy', 'todo', NULL)`,
+		milestoneID, milestoneID,
+	); err != nil {
+		t.Fatalf("inserting tasks: %v", err)
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex/stats", Query: map[string]string{"weeks": "4"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var stats ProjectStats
+	if err := json.Unmarshal(parseDataObject(t, resp), &stats); err != nil {
+		t.Fatalf("failed to parse stats: %v", err)
+	}
+	if stats.Project != "cortex" || stats.OpenTasks != 1 || stats.ClosedTasks != 1 {
+		t.Errorf("unexpected totals: %+v", stats)
+	}
+	if len(stats.Weekly) != 4 {
+		t.Errorf("expected 4 weeks, got %d", len(stats.Weekly))
+	}
+	if stats.Burndown == nil || stats.Burndown.Name != "MVP" || stats.Burndown.Total != 2 || len(stats.Burndown.Points) != 3 {
+		t.Fatalf("unexpected burndown: %+v", stats.Burndown)
+	}
+	last := stats.Burndown.Points[len(stats.Burndown.Points)-1]
+	if last.Remaining == nil || *last.Remaining != 1 {
+		t.Errorf("expected 1 task remaining today, got %v", last.Remaining)
+	}
+}
+
+func TestGetProjectStats_Errors(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/nonexistent/stats"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex/stats", Query: map[string]string{"weeks": "0"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for invalid weeks, got %d", resp.StatusCode)
+	}
+	if code, _ := parseErrorResponse(t, resp); code != "VALIDATION_ERROR" {
+		t.Errorf("expected VALIDATION_ERROR, got %s", code)
+	}
+}
+
+// --- Watcher tests ---
+
+// sentNotification is a notification the plugin sent to the host.
+type sentNotification struct {
+	Title string
+	Body  string
+}
+
+// captureNotifications is a test helper that records the notifications p
+// sends instead of sending them to the host. It stops the stale check loop so
+// only the requests in the test send any.
+func captureNotifications(t *testing.T, p *ProjectHubPlugin) *[]sentNotification {
+	t.Helper()
+
+	p.stopStaleCheck()
+	sent := &[]sentNotification{}
+	p.notify = func(title string, body string, urgent bool) error {
+		*sent = append(*sent, sentNotification{Title: title, Body: body})
+		return nil
+	}
+	return sent
+}
+
+func TestWatch_StatusChangeOnWatchedProjectNotifies(t *testing.T) {
+	p := newTestPlugin(t)
+	sent := captureNotifications(t, p)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects/cortex/watch"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	// Unwatched projects stay quiet.
+	for _, slug := range []string{"cortex", "pokeutils"} {
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+			Method: "PUT",
+			Path:   "/projects/" + slug,
+			Body:   []byte(`{"status":"active"}`),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != 200 {
+			t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+		}
+	}
+
+	if len(*sent) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(*sent))
+	}
+	if got := (*sent)[0]; got.Title != "Cortex is now active" || got.Body != "Status changed from development to active." {
+		t.Errorf("unexpected notification: %+v", got)
+	}
+
+	// Updating without changing the status does not notify again.
+	if _, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: "/projects/cortex", Body: []byte(`{"status":"active"}`)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(*sent); got != 1 {
+		t.Errorf("expected no new notification for an unchanged status, got %d total", got)
+	}
+}
+
+func TestWatch_ChangelogRules(t *testing.T) {
+	p := newTestPlugin(t)
+	sent := captureNotifications(t, p)
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/projects/cortex/watch",
+		Body:   []byte(`{"notify_status":false}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var watch Watch
+	if err := json.Unmarshal(parseDataObject(t, resp), &watch); err != nil {
+		t.Fatalf("failed to parse watch: %v", err)
+	}
+	if watch.NotifyStatus || !watch.NotifyChangelog {
+		t.Errorf("unexpected watch rules: %+v", watch)
+	}
+
+	if _, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: "/projects/cortex", Body: []byte(`{"status":"active"}`)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   "/projects/cortex/changelog",
+		Body:   []byte(`{"version":"v0.3","title":"Plugin archives","body":"Install .cortexplugin files."}`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	if len(*sent) != 1 || (*sent)[0].Title != "Cortex v0.3: Plugin archives" || (*sent)[0].Body != "Install .cortexplugin files." {
+		t.Fatalf("expected only the changelog notification, got %+v", *sent)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex/changelog"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries := parseDataArray(t, resp); len(entries) != 1 {
+		t.Errorf("expected 1 changelog entry, got %d", len(entries))
+	}
+}
+
+func TestWatch_UnwatchAndFilter(t *testing.T) {
+	p := newTestPlugin(t)
+	sent := captureNotifications(t, p)
+
+	if _, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects/cortex/watch"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects", Query: map[string]string{"watched": "true"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if projects := parseDataArray(t, resp); len(projects) != 1 {
+		t.Fatalf("expected 1 watched project, got %d", len(projects))
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/projects/cortex/watch"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	if _, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: "/projects/cortex", Body: []byte(`{"status":"active"}`)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(*sent); got != 0 {
+		t.Errorf("expected no notifications after unwatching, got %d", got)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/projects/cortex/watch"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404 for an unwatched project, got %d", resp.StatusCode)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/projects/nonexistent/watch"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("expected 404 for a missing project, got %d", resp.StatusCode)
+	}
+}
+
+// --- Search ---
+
+func TestSearch(t *testing.T) {
+	p := newTestPlugin(t)
+
+	results, err := p.Search("cortex")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) == 0 || results[0].Title != "Cortex" {
+		t.Fatalf("expected the Cortex project first, got %+v", results)
+	}
+	if results[0].Type != "project" || results[0].Link != "/plugins/project-hub" {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+
+	// Matches in other fields are found too; Finance App's notes mention Cortex.
+	found := false
+	for _, result := range results {
+		if result.Title == "Finance App" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a project mentioning Cortex in its notes, got %+v", results)
+	}
+
+	// LIKE wildcards in the query are matched literally.
+	results, err = p.Search("%")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no projects containing %%, got %d", len(results))
+	}
+}
+
+// --- Milestone tests ---
+
+// callAPI is a test helper that sends a request and checks its status code.
+func callAPI(t *testing.T, p *ProjectHubPlugin, method string, path string, body string, wantStatus int) *sdk.APIResponse {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: method, Path: path, Body: []byte(body)})
+	if err != nil {
+		t.Fatalf("%s %s: unexpected error: %v", method, path, err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s: expected %d, got %d. Body: %s", method, path, wantStatus, resp.StatusCode, string(resp.Body))
+	}
+	return resp
+}
+
+func TestMilestones_CRUDAndProjectProgress(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "MVP", "start_date": "2026-03-01", "due_date": "2026-03-31"}`, 201)
+	var milestone Milestone
+	if err := json.Unmarshal(parseDataObject(t, resp), &milestone); err != nil {
+		t.Fatalf("failed to parse milestone: %v", err)
+	}
+	if milestone.Name != "MVP" || milestone.Status != "open" || milestone.DueDate == nil || *milestone.DueDate != "2026-03-31" {
+		t.Errorf("unexpected milestone: %+v", milestone)
+	}
+	tasksPath := fmt.Sprintf("/milestones/%d/tasks", milestone.ID)
+
+	callAPI(t, p, "POST", tasksPath, `{"title": "Loader", "status": "done"}`, 201)
+	callAPI(t, p, "POST", tasksPath, `{"title": "Proxy", "status": "in_progress"}`, 201)
+	callAPI(t, p, "POST", tasksPath, `{"title": "Overdue docs", "due_date": "2020-01-01"}`, 201)
+	callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "v1", "status": "closed"}`, 201)
+
+	resp = callAPI(t, p, "GET", "/projects/cortex/milestones", "", 200)
+	var milestones []Milestone
+	if err := json.Unmarshal(parseDataObject(t, resp), &milestones); err != nil {
+		t.Fatalf("failed to parse milestones: %v", err)
+	}
+	if len(milestones) != 2 {
+		t.Fatalf("expected 2 milestones, got %d", len(milestones))
+	}
+	if counts := milestones[0].Tasks; counts != (TaskCounts{Todo: 1, InProgress: 1, Done: 1, Overdue: 1}) {
+		t.Errorf("unexpected task counts for MVP: %+v", counts)
+	}
+
+	resp = callAPI(t, p, "GET", "/projects/cortex", "", 200)
+	var project ProjectWithLinksAndTags
+	if err := json.Unmarshal(parseDataObject(t, resp), &project); err != nil {
+		t.Fatalf("failed to parse project: %v", err)
+	}
+	progress := project.Progress
+	if progress == nil || progress.OpenMilestones != 1 || progress.ClosedMilestones != 1 || progress.Tasks.Done != 1 {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+	if progress.PercentDone < 33.3 || progress.PercentDone > 33.4 {
+		t.Errorf("expected a third of the tasks done, got %v", progress.PercentDone)
+	}
+
+	resp = callAPI(t, p, "PUT", fmt.Sprintf("/milestones/%d", milestone.ID), `{"status": "closed", "due_date": ""}`, 200)
+	if err := json.Unmarshal(parseDataObject(t, resp), &milestone); err != nil {
+		t.Fatalf("failed to parse milestone: %v", err)
+	}
+	if milestone.Status != "closed" || milestone.DueDate != nil {
+		t.Errorf("expected a closed milestone without due date, got %+v", milestone)
+	}
+
+	callAPI(t, p, "DELETE", fmt.Sprintf("/milestones/%d", milestone.ID), "", 200)
+	callAPI(t, p, "GET", tasksPath, "", 404)
+
+	var remaining int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&remaining); err != nil {
+		t.Fatalf("counting tasks: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("expected tasks to be deleted with their milestone, got %d", remaining)
+	}
+}
+
+func TestMilestones_Validation(t *testing.T) {
+	p := newTestPlugin(t)
+
+	callAPI(t, p, "POST", "/projects/nonexistent/milestones", `{"name": "MVP"}`, 404)
+	callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": ""}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "MVP", "status": "done"}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "MVP", "due_date": "next week"}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "MVP", "start_date": "2026-03-10", "due_date": "2026-03-01"}`, 400)
+	callAPI(t, p, "PUT", "/milestones/999", `{"name": "MVP"}`, 404)
+	callAPI(t, p, "POST", "/milestones/999/tasks", `{"title": "Loader"}`, 404)
+	callAPI(t, p, "PUT", "/tasks/999", `{"status": "done"}`, 404)
+	callAPI(t, p, "DELETE", "/tasks/999", "", 404)
+}
+
+func TestTasks_CompletionFollowsStatus(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "MVP"}`, 201)
+	var milestone Milestone
+	if err := json.Unmarshal(parseDataObject(t, resp), &milestone); err != nil {
+		t.Fatalf("failed to parse milestone: %v", err)
+	}
+
+	resp = callAPI(t, p, "POST", fmt.Sprintf("/milestones/%d/tasks", milestone.ID), `{"title": "Loader"}`, 201)
+	var task Task
+	if err := json.Unmarshal(parseDataObject(t, resp), &task); err != nil {
+		t.Fatalf("failed to parse task: %v", err)
+	}
+	if task.Status != "todo" || task.CompletedAt != nil {
+		t.Fatalf("expected an open task, got %+v", task)
+	}
+	taskPath := fmt.Sprintf("/tasks/%d", task.ID)
+
+	resp = callAPI(t, p, "PUT", taskPath, `{"status": "done"}`, 200)
+	if err := json.Unmarshal(parseDataObject(t, resp), &task); err != nil {
+		t.Fatalf("failed to parse task: %v", err)
+	}
+	if task.CompletedAt == nil {
+		t.Fatal("expected completed_at to be set when the task is done")
+	}
+
+	// Saving a done task again keeps its completion time.
+	if _, err := p.db.Exec("UPDATE tasks SET completed_at = '2026-03-01 09:00:00' WHERE id = ?", task.ID); err != nil {
+		t.Fatalf("backdating task: %v", err)
+	}
+	resp = callAPI(t, p, "PUT", taskPath, `{"status": "done", "title": "Plugin loader"}`, 200)
+	if err := json.Unmarshal(parseDataObject(t, resp), &task); err != nil {
+		t.Fatalf("failed to parse task: %v", err)
+	}
+	if task.CompletedAt == nil || *task.CompletedAt != "2026-03-01 09:00:00" || task.Title != "Plugin loader" {
+		t.Errorf("expected the original completion time to be kept, got %+v", task)
+	}
+
+	resp = callAPI(t, p, "PUT", taskPath, `{"status": "todo"}`, 200)
+	if err := json.Unmarshal(parseDataObject(t, resp), &task); err != nil {
+		t.Fatalf("failed to parse task: %v", err)
+	}
+	if task.CompletedAt != nil {
+		t.Errorf("expected completed_at to be cleared when the task is reopened, got %v", *task.CompletedAt)
+	}
+
+	callAPI(t, p, "DELETE", taskPath, "", 200)
+}
+
+func TestWidgetData_TasksDueThisWeek(t *testing.T) {
+	p := newTestPlugin(t)
+	now := mustTimestamp(t, "2026-03-11 12:00:00") // a Wednesday
+
+	result, err := p.db.Exec("INSERT INTO milestones (project_id, name) SELECT id, 'MVP' FROM projects WHERE slug = 'cortex'")
+	if err != nil {
+		t.Fatalf("inserting milestone: %v", err)
+	}
+	milestoneID, _ := result.LastInsertId()
+
+	if _, err := p.db.Exec(
+		`INSERT INTO tasks (milestone_id, title, status, due_date) VALUES
+		    (?, 'Sunday', 'todo', '2026-03-15'),
+		    (?, 'Monday', 'in_progress', '2026-03-09'),
+		    (?, 'Finished', 'done', '2026-03-10'),
+		    (?, 'Last week', 'todo', '2026-03-08'),
+		    (?, 'Next week', 'todo', '2026-03-16'),
+		    (?, 'Undated', 'todo', NULL)`,
+		milestoneID, milestoneID, milestoneID, milestoneID, milestoneID, milestoneID,
+	); err != nil {
+		t.Fatalf("inserting tasks: %v", err)
+	}
+
+	data, err := p.tasksDueWidgetData(now)
+	if err != nil {
+		t.Fatalf("tasksDueWidgetData failed: %v", err)
+	}
+
+	var widget struct {
+		Data struct {
+			WeekStart string    `json:"week_start"`
+			WeekEnd   string    `json:"week_end"`
+			Total     int       `json:"total"`
+			Tasks     []DueTask `json:"tasks"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &widget); err != nil {
+		t.Fatalf("failed to parse widget data: %v", err)
+	}
+
+	if widget.Data.WeekStart != "2026-03-09" || widget.Data.WeekEnd != "2026-03-15" {
+		t.Errorf("unexpected week %s..%s", widget.Data.WeekStart, widget.Data.WeekEnd)
+	}
+	if widget.Data.Total != 2 || len(widget.Data.Tasks) != 2 {
+		t.Fatalf("expected 2 tasks due, got %+v", widget.Data.Tasks)
+	}
+	first := widget.Data.Tasks[0]
+	if first.Title != "Monday" || first.Project != "cortex" || first.MilestoneName != "MVP" {
+		t.Errorf("unexpected first task: %+v", first)
+	}
+	if widget.Data.Tasks[1].Title != "Sunday" {
+		t.Errorf("expected Sunday's task last, got %+v", widget.Data.Tasks[1])
+	}
+}
+
+func TestSeedDemo_PlansMilestonesOnce(t *testing.T) {
+	p := newTestPlugin(t)
+
+	if err := p.SeedDemo(); err != nil {
+		t.Fatalf("SeedDemo failed: %v", err)
+	}
+	if err := p.SeedDemo(); err != nil {
+		t.Fatalf("second SeedDemo failed: %v", err)
+	}
+
+	resp := callAPI(t, p, "GET", "/projects/"+demoProjectSlug+"/milestones", "", 200)
+	var milestones []Milestone
+	if err := json.Unmarshal(parseDataObject(t, resp), &milestones); err != nil {
+		t.Fatalf("failed to parse milestones: %v", err)
+	}
+	if len(milestones) != len(demoMilestones) {
+		t.Fatalf("expected %d demo milestones seeded once, got %d", len(demoMilestones), len(milestones))
+	}
+
+	resp = callAPI(t, p, "GET", "/projects/"+demoProjectSlug, "", 200)
+	var project ProjectWithLinksAndTags
+	if err := json.Unmarshal(parseDataObject(t, resp), &project); err != nil {
+		t.Fatalf("failed to parse project: %v", err)
+	}
+	if project.Progress == nil || project.Progress.Tasks.Done != 3 || project.Progress.OpenMilestones != 1 {
+		t.Errorf("unexpected demo progress: %+v", project.Progress)
+	}
+}
+
+// --- Time tracking tests ---
+
+func TestTime_TimerStartStop(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := callAPI(t, p, "POST", "/projects/cortex/time", `{"action": "start", "note": "Loader refactor"}`, 201)
+	var entry TimeEntry
+	if err := json.Unmarshal(parseDataObject(t, resp), &entry); err != nil {
+		t.Fatalf("failed to parse time entry: %v", err)
+	}
+	if !entry.Running || entry.EndedAt != nil {
+		t.Errorf("expected a running timer, got %+v", entry)
+	}
+
+	callAPI(t, p, "POST", "/projects/cortex/time", `{"action": "start"}`, 409)
+	// Timers are per project: another project can run one at the same time.
+	callAPI(t, p, "POST", "/projects/quedamos/time", `{"action": "start"}`, 201)
+
+	resp = callAPI(t, p, "POST", "/projects/cortex/time", `{"action": "stop"}`, 200)
+	if err := json.Unmarshal(parseDataObject(t, resp), &entry); err != nil {
+		t.Fatalf("failed to parse time entry: %v", err)
+	}
+	if entry.Running || entry.EndedAt == nil {
+		t.Errorf("expected a stopped timer, got %+v", entry)
+	}
+
+	callAPI(t, p, "POST", "/projects/cortex/time", `{"action": "stop"}`, 404)
+	callAPI(t, p, "POST", "/projects/cortex/time", `{"action": "pause"}`, 400)
+	callAPI(t, p, "POST", "/projects/nope/time", `{"action": "start"}`, 404)
+}
+
+func TestTime_ManualEntriesAndSummary(t *testing.T) {
+	p := newTestPlugin(t)
+	thisWeek := startOfWeek(time.Now().UTC())
+	lastWeek := thisWeek.AddDate(0, 0, -7).Add(10 * time.Hour).Format(time.RFC3339)
+
+	callAPI(t, p, "POST", "/projects/cortex/time", fmt.Sprintf(`{"duration_minutes": 90, "started_at": %q, "note": "Docs"}`, thisWeek.Format(time.RFC3339)), 201)
+	callAPI(t, p, "POST", "/projects/cortex/time",
+		fmt.Sprintf(`{"started_at": %q, "ended_at": %q}`, lastWeek, thisWeek.AddDate(0, 0, -7).Add(12*time.Hour).Format(time.RFC3339)), 201)
+
+	callAPI(t, p, "POST", "/projects/cortex/time", `{"duration_minutes": 0}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/time", `{"duration_minutes": 1500}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/time", `{"started_at": "2026-03-02T12:00:00Z", "ended_at": "2026-03-02T11:00:00Z"}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/time", `{"started_at": "2026-03-02T12:00:00Z", "ended_at": "2026-03-02T13:00:00Z", "duration_minutes": 60}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/time", `{}`, 400)
+
+	resp := callAPI(t, p, "GET", "/projects/cortex/time", "", 200)
+	var summary TimeSummary
+	if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+		t.Fatalf("failed to parse summary: %v", err)
+	}
+	if summary.Group != "week" || len(summary.Periods) != defaultTimeWeeks {
+		t.Fatalf("expected %d weekly periods, got %+v", defaultTimeWeeks, summary)
+	}
+	last := summary.Periods[len(summary.Periods)-1]
+	previous := summary.Periods[len(summary.Periods)-2]
+	if last.Start != thisWeek.Format("2006-01-02") || last.Seconds != 90*60 || previous.Seconds != 2*3600 {
+		t.Errorf("unexpected weekly totals: this week %+v, last week %+v", last, previous)
+	}
+	if summary.TotalSeconds != 90*60+2*3600 || len(summary.Recent) != 2 || summary.Running != nil {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex/time", Query: map[string]string{"group": "month", "periods": "3"}})
+	if err := json.Unmarshal(parseDataObject(t, resp), &summary); err != nil {
+		t.Fatalf("failed to parse summary: %v", err)
+	}
+	if summary.Group != "month" || len(summary.Periods) != 3 {
+		t.Errorf("expected 3 monthly periods, got %+v", summary.Periods)
+	}
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex/time", Query: map[string]string{"group": "year"}})
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for an unknown group, got %d", resp.StatusCode)
+	}
+
+	callAPI(t, p, "DELETE", fmt.Sprintf("/time/%d", summary.Recent[0].ID), "", 200)
+	callAPI(t, p, "DELETE", fmt.Sprintf("/time/%d", summary.Recent[0].ID), "", 404)
+}
+
+func TestComputeTimeSummary_BucketsByStart(t *testing.T) {
+	starts := periodStarts(mustTimestamp(t, "2026-03-11 12:00:00"), "month", 2)
+	if starts[0].Format("2006-01-02") != "2026-02-01" || starts[1].Format("2006-01-02") != "2026-03-01" {
+		t.Fatalf("unexpected month starts: %v", starts)
+	}
+
+	summary := computeTimeSummary([]TimeEntry{
+		{StartedAt: "2026-01-31 23:00:00", DurationSeconds: 7200},
+		{StartedAt: "2026-02-28 23:30:00", DurationSeconds: 3600},
+		{StartedAt: "2026-03-01 00:00:00", DurationSeconds: 60},
+	}, starts)
+	if summary.Periods[0].Seconds != 3600 || summary.Periods[1].Seconds != 60 || summary.TotalSeconds != 3660 {
+		t.Errorf("expected entries to count in the month they started in, got %+v", summary)
+	}
+}
+
+func TestWidgetData_TimeSummaryThisWeek(t *testing.T) {
+	p := newTestPlugin(t)
+	now := mustTimestamp(t, "2026-03-11 12:00:00") // a Wednesday
+
+	if _, err := p.db.Exec(
+		`INSERT INTO time_entries (project_id, started_at, ended_at, duration_seconds)
+		 SELECT id, '2026-03-09 09:00:00', '2026-03-09 10:00:00', 3600 FROM projects WHERE slug = 'quedamos'
+		 UNION ALL SELECT id, '2026-03-10 09:00:00', '2026-03-10 11:00:00', 7200 FROM projects WHERE slug = 'cortex'
+		 UNION ALL SELECT id, '2026-03-11 11:30:00', NULL, NULL FROM projects WHERE slug = 'cortex'
+		 UNION ALL SELECT id, '2026-03-08 09:00:00', '2026-03-08 18:00:00', 32400 FROM projects WHERE slug = 'cortex'`,
+	); err != nil {
+		t.Fatalf("inserting time entries: %v", err)
+	}
+
+	data, err := p.timeSummaryWidgetData(now)
+	if err != nil {
+		t.Fatalf("timeSummaryWidgetData failed: %v", err)
+	}
+
+	var widget struct {
+		Data struct {
+			WeekStart    string        `json:"week_start"`
+			TotalSeconds int64         `json:"total_seconds"`
+			Projects     []ProjectTime `json:"projects"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &widget); err != nil {
+		t.Fatalf("failed to parse widget data: %v", err)
+	}
+	if widget.Data.WeekStart != "2026-03-09" || widget.Data.TotalSeconds != 3600+7200+1800 {
+		t.Errorf("unexpected week totals: %+v", widget.Data)
+	}
+	if len(widget.Data.Projects) != 2 || widget.Data.Projects[0].Project != "cortex" || widget.Data.Projects[0].Seconds != 9000 {
+		t.Errorf("expected cortex first with the running timer counted, got %+v", widget.Data.Projects)
+	}
+}
+
+// --- Relation tests ---
+
+func TestRelations_GraphAndCycles(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := callAPI(t, p, "POST", "/projects/fogon/relations", `{"target": "cortex", "kind": "depends-on"}`, 201)
+	var relation ProjectRelation
+	if err := json.Unmarshal(parseDataObject(t, resp), &relation); err != nil {
+		t.Fatalf("failed to parse relation: %v", err)
+	}
+	if relation.Source != "fogon" || relation.Target != "cortex" || relation.Kind != "depends-on" {
+		t.Errorf("unexpected relation: %+v", relation)
+	}
+
+	callAPI(t, p, "POST", "/projects/fogon/relations", `{"target": "cortex", "kind": "depends-on"}`, 409)
+	callAPI(t, p, "POST", "/projects/cortex/relations", `{"target": "fogon", "kind": "depends-on"}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/relations", `{"target": "cortex", "kind": "spun-off-from"}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/relations", `{"target": "fogon", "kind": "blocks"}`, 400)
+	callAPI(t, p, "POST", "/projects/cortex/relations", `{"target": "nope", "kind": "depends-on"}`, 400)
+
+	resp = callAPI(t, p, "GET", "/projects/graph", "", 200)
+	var graph ProjectGraph
+	if err := json.Unmarshal(parseDataObject(t, resp), &graph); err != nil {
+		t.Fatalf("failed to parse graph: %v", err)
+	}
+	if len(graph.Nodes) != 16 {
+		t.Errorf("expected 16 nodes, got %d", len(graph.Nodes))
+	}
+	// The two seeded absorbed projects plus the new dependency.
+	if len(graph.Edges) != 3 {
+		t.Fatalf("expected 3 edges, got %+v", graph.Edges)
+	}
+	if graph.Edges[0].Source != "finance-app" || graph.Edges[0].Target != "cortex" || graph.Edges[0].Kind != "absorbed-into" {
+		t.Errorf("unexpected seeded edge: %+v", graph.Edges[0])
+	}
+
+	resp = callAPI(t, p, "GET", "/projects/cortex/relations", "", 200)
+	var relations []ProjectRelation
+	if err := json.Unmarshal(parseDataObject(t, resp), &relations); err != nil {
+		t.Fatalf("failed to parse relations: %v", err)
+	}
+	if len(relations) != 2 {
+		t.Errorf("expected cortex on both ends of 2 relations, got %+v", relations)
+	}
+
+	callAPI(t, p, "DELETE", fmt.Sprintf("/relations/%d", relation.ID), "", 200)
+	callAPI(t, p, "DELETE", fmt.Sprintf("/relations/%d", relation.ID), "", 404)
+
+	callAPI(t, p, "POST", "/projects", `{"name": "Graph", "tagline": "x", "status": "concept", "category": "lab", "stack": "Go"}`, 400)
+}
+
+func TestRelations_AbsorbedStatusCreatesRelation(t *testing.T) {
+	p := newTestPlugin(t)
+
+	callAPI(t, p, "PUT", "/projects/ironlog", `{"absorbed_into": "cortex"}`, 400)
+	callAPI(t, p, "PUT", "/projects/ironlog", `{"status": "absorbed", "absorbed_into": "ironlog"}`, 400)
+	callAPI(t, p, "PUT", "/projects/ironlog", `{"status": "absorbed", "absorbed_into": "cortex"}`, 200)
+
+	absorbedInto := func(slug string) []string {
+		t.Helper()
+		resp := callAPI(t, p, "GET", "/projects/"+slug+"/relations", "", 200)
+		var relations []ProjectRelation
+		if err := json.Unmarshal(parseDataObject(t, resp), &relations); err != nil {
+			t.Fatalf("failed to parse relations: %v", err)
+		}
+		targets := make([]string, 0)
+		for _, relation := range relations {
+			if relation.Source == slug && relation.Kind == relationAbsorbedInto {
+				targets = append(targets, relation.Target)
+			}
+		}
+		return targets
+	}
+
+	if got := absorbedInto("ironlog"); len(got) != 1 || got[0] != "cortex" {
+		t.Fatalf("expected ironlog absorbed into cortex, got %v", got)
+	}
+
+	// Naming another target replaces the relation.
+	callAPI(t, p, "PUT", "/projects/ironlog", `{"absorbed_into": "fogon"}`, 200)
+	if got := absorbedInto("ironlog"); len(got) != 1 || got[0] != "fogon" {
+		t.Errorf("expected ironlog absorbed into fogon, got %v", got)
+	}
+	callAPI(t, p, "POST", "/projects/ironlog/relations", `{"target": "cortex", "kind": "absorbed-into"}`, 409)
+
+	// A project that is no longer absorbed drops the relation.
+	callAPI(t, p, "PUT", "/projects/ironlog", `{"status": "active"}`, 200)
+	if got := absorbedInto("ironlog"); len(got) != 0 {
+		t.Errorf("expected no absorbed-into relation, got %v", got)
+	}
+
+	callAPI(t, p, "POST", "/projects", `{"name": "Old Notes", "tagline": "x", "status": "absorbed", "category": "lab", "stack": "Go", "absorbed_into": "cortex"}`, 201)
+	if got := absorbedInto("old-notes"); len(got) != 1 || got[0] != "cortex" {
+		t.Errorf("expected old-notes absorbed into cortex, got %v", got)
+	}
+}
+
+// --- Note tests ---
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name, source, want string
+	}{
+		{"heading and paragraph", "# Plan\nShip **v1** and *then* rest.", "<h1>Plan</h1>\n<p>Ship <strong>v1</strong> and <em>then</em> rest.</p>"},
+		{"lists", "- one\n- two\n1. first", "<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n<ol>\n<li>first</li>\n</ol>"},
+		{"raw html is escaped", "<script>alert(1)</script> <b onclick=\"x\">hi</b>", "<p>&lt;script&gt;alert(1)&lt;/script&gt; &lt;b onclick=&#34;x&#34;&gt;hi&lt;/b&gt;</p>"},
+		{"safe link", "[docs](https://example.com/a_b?x=1&y=2)", "<p><a href=\"https://example.com/a%5Fb?x=1&amp;y=2\" rel=\"noopener noreferrer\">docs</a></p>"},
+		{"unsafe link", "[click](javascript:void)", "<p>click</p>"},
+		{"code", "Run `go test <./...>`\n```\n<b>**kept**</b>\n```", "<p>Run <code>go test &lt;./...&gt;</code></p>\n<pre><code>&lt;b&gt;**kept**&lt;/b&gt;</code></pre>"},
+		{"quote and rule", "> was an idea\n\n---", "<blockquote><p>was an idea</p></blockquote>\n<hr>"},
+	}
+	for _, test := range tests {
+		if got := renderMarkdown(test.source); got != test.want {
+			t.Errorf("%s: renderMarkdown(%q)\n got %q\nwant %q", test.name, test.source, got, test.want)
+		}
+	}
+}
+
+func TestNotes_RevisionsAndLegacyNotes(t *testing.T) {
+	p := newTestPlugin(t)
+
+	// The seeded projects.notes text was moved into a note.
+	resp := callAPI(t, p, "GET", "/projects/finance-app/notes", "", 200)
+	var notes []ProjectNote
+	if err := json.Unmarshal(parseDataObject(t, resp), &notes); err != nil {
+		t.Fatalf("failed to parse notes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Title != "Notes" || notes[0].Body != "Absorbed into Cortex as Finance Tracker plugin." {
+		t.Fatalf("expected the legacy notes as one note, got %+v", notes)
+	}
+	callAPI(t, p, "PUT", "/projects/finance-app", `{"notes": "new thoughts"}`, 400)
+	callAPI(t, p, "PUT", "/projects/finance-app", `{"notes": null, "tagline": "Old finance app"}`, 200)
+
+	resp = callAPI(t, p, "POST", "/projects/cortex/notes", `{"title": "Architecture", "body": "Plugins talk **gRPC**."}`, 201)
+	var note ProjectNote
+	if err := json.Unmarshal(parseDataObject(t, resp), &note); err != nil {
+		t.Fatalf("failed to parse note: %v", err)
+	}
+	if note.HTML != "<p>Plugins talk <strong>gRPC</strong>.</p>" || note.RevisionCount != 0 {
+		t.Errorf("unexpected note: %+v", note)
+	}
+	notePath := fmt.Sprintf("/notes/%d", note.ID)
+
+	callAPI(t, p, "POST", "/projects/cortex/notes", `{"title": " ", "body": "x"}`, 400)
+	callAPI(t, p, "PUT", notePath, `{"body": "Plugins talk gRPC over go-plugin."}`, 200)
+	callAPI(t, p, "PUT", notePath, `{"body": "Plugins talk gRPC over go-plugin."}`, 200)
+	resp = callAPI(t, p, "PUT", notePath, `{"title": "Design"}`, 200)
+	if err := json.Unmarshal(parseDataObject(t, resp), &note); err != nil {
+		t.Fatalf("failed to parse note: %v", err)
+	}
+	if note.Title != "Design" || note.Body != "Plugins talk gRPC over go-plugin." || note.RevisionCount != 2 {
+		t.Errorf("expected two revisions after two changes, got %+v", note)
+	}
+
+	resp = callAPI(t, p, "GET", notePath+"/revisions", "", 200)
+	var revisions []NoteRevision
+	if err := json.Unmarshal(parseDataObject(t, resp), &revisions); err != nil {
+		t.Fatalf("failed to parse revisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Title != "Architecture" || revisions[0].Body != "Plugins talk gRPC over go-plugin." {
+		t.Fatalf("unexpected newest revision: %+v", revisions)
+	}
+	if revisions[1].Body != "Plugins talk **gRPC**." || revisions[1].HTML != "<p>Plugins talk <strong>gRPC</strong>.</p>" {
+		t.Errorf("unexpected oldest revision: %+v", revisions[1])
+	}
+
+	results, err := p.Search("go-plugin")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Cortex" {
+		t.Errorf("expected the note to make Cortex searchable, got %+v", results)
+	}
+
+	callAPI(t, p, "DELETE", notePath, "", 200)
+	callAPI(t, p, "GET", notePath, "", 404)
+	callAPI(t, p, "GET", notePath+"/revisions", "", 404)
+}
+
+func TestStale_FlagsIdleProjectsAndSendsWeeklyDigest(t *testing.T) {
+	p := newTestPlugin(t)
+	// Stop the background loop so only the checks below run.
+	p.stopStaleCheck()
+
+	var digests []string
+	p.notify = func(title string, body string, urgent bool) error {
+		digests = append(digests, title+"\n"+body)
+		return nil
+	}
+
+	for _, slug := range []string{"quedamos", "sinherencia"} {
+		if _, err := p.db.Exec("UPDATE projects SET updated_at = datetime('now', '-40 days') WHERE slug = ?", slug); err != nil {
+			t.Fatalf("failed to age project: %v", err)
+		}
+	}
+
+	resp := callAPI(t, p, "POST", "/stale/check", "", 200)
+	var result StaleCheckResult
+	if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+		t.Fatalf("failed to parse check result: %v", err)
+	}
+	// sinherencia is active, so only the project in development is stale.
+	if len(result.Stale) != 1 || result.Stale[0].Slug != "quedamos" || result.Stale[0].DaysIdle != 40 || !result.DigestSent {
+		t.Fatalf("unexpected check result: %+v", result)
+	}
+	if len(digests) != 1 || digests[0] != "1 stale project\nNot updated in the last 30 days:\n- Quedamos (development): untouched for 40 days" {
+		t.Errorf("unexpected digest: %q", digests)
+	}
+
+	resp = callAPI(t, p, "GET", "/projects", "", 200)
+	for _, raw := range parseDataArray(t, resp) {
+		var project ProjectWithTags
+		if err := json.Unmarshal(raw, &project); err != nil {
+			t.Fatalf("failed to parse project: %v", err)
+		}
+		if project.NeedsAttention != (project.Slug == "quedamos") {
+			t.Errorf("project %s: needs_attention = %v", project.Slug, project.NeedsAttention)
+		}
+	}
+
+	// The digest is weekly.
+	callAPI(t, p, "POST", "/stale/check", "", 200)
+	if len(digests) != 1 {
+		t.Errorf("expected no second digest within the week, got %d", len(digests))
+	}
+	if _, err := p.db.Exec("UPDATE stale_settings SET last_digest_at = datetime('now', '-8 days')"); err != nil {
+		t.Fatalf("failed to age digest: %v", err)
+	}
+	callAPI(t, p, "POST", "/stale/check", "", 200)
+	if len(digests) != 2 {
+		t.Errorf("expected a digest a week later, got %d", len(digests))
+	}
+
+	// Touching the project clears its flag.
+	callAPI(t, p, "PUT", "/projects/quedamos", `{"tagline": "Coordinar quedadas"}`, 200)
+	resp = callAPI(t, p, "GET", "/projects/quedamos", "", 200)
+	var project Project
+	if err := json.Unmarshal(parseDataObject(t, resp), &project); err != nil {
+		t.Fatalf("failed to parse project: %v", err)
+	}
+	if project.NeedsAttention {
+		t.Error("expected the touched project not to need attention")
+	}
+
+	callAPI(t, p, "PUT", "/stale/settings", `{"stale_days": 0}`, 400)
+	callAPI(t, p, "PUT", "/stale/settings", `{}`, 400)
+	resp = callAPI(t, p, "PUT", "/stale/settings", `{"stale_days": 45}`, 200)
+	var settings StaleSettings
+	if err := json.Unmarshal(parseDataObject(t, resp), &settings); err != nil {
+		t.Fatalf("failed to parse settings: %v", err)
+	}
+	if settings.StaleDays != 45 || settings.LastDigestAt == nil {
+		t.Errorf("unexpected settings: %+v", settings)
+	}
+}
+
+func TestPortfolio_ExportAndImportRoundTrip(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "v1.0", "due_date": "2026-12-01"}`, 201)
+	var milestone Milestone
+	if err := json.Unmarshal(parseDataObject(t, resp), &milestone); err != nil {
+		t.Fatalf("failed to parse milestone: %v", err)
+	}
+	callAPI(t, p, "POST", fmt.Sprintf("/milestones/%d/tasks", milestone.ID), `{"title": "=Ship it", "status": "done"}`, 201)
+	callAPI(t, p, "POST", "/projects/cortex/links", `{"label": "Roadmap", "url": "https://example.com/roadmap"}`, 201)
+
+	export := func(format string) []byte {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/export", Query: map[string]string{"format": format}})
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("export %s failed: %v %+v", format, err, resp)
+		}
+		return resp.Body
+	}
+	importBody := func(format string, body []byte, wantStatus int) *sdk.APIResponse {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/import", Body: body, Query: map[string]string{"format": format}})
+		if err != nil {
+			t.Fatalf("import %s failed: %v", format, err)
+		}
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("import %s: expected %d, got %d: %s", format, wantStatus, resp.StatusCode, resp.Body)
+		}
+		return resp
+	}
+	lookup := func(portfolio Portfolio, slug string) PortfolioProject {
+		t.Helper()
+		for _, project := range portfolio.Projects {
+			if project.Slug == slug {
+				return project
+			}
+		}
+		t.Fatalf("project %s not in portfolio", slug)
+		return PortfolioProject{}
+	}
+
+	var portfolio Portfolio
+	if err := json.Unmarshal(export("json"), &portfolio); err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if len(portfolio.Projects) != 16 {
+		t.Fatalf("expected 16 projects, got %d", len(portfolio.Projects))
+	}
+	cortex := lookup(portfolio, "cortex")
+	if len(cortex.Tags) != 4 || len(cortex.Links) != 1 || len(cortex.Milestones) != 1 || len(cortex.Milestones[0].Tasks) != 1 {
+		t.Fatalf("unexpected cortex export: %+v", cortex)
+	}
+
+	// The CSV round trip leaves every project as it was.
+	csvExport := export("csv")
+	var result ImportResult
+	if err := json.Unmarshal(parseDataObject(t, importBody("csv", csvExport, 200)), &result); err != nil {
+		t.Fatalf("failed to parse import result: %v", err)
+	}
+	if result.Created != 0 || result.Updated != 16 {
+		t.Errorf("expected 16 updated projects, got %+v", result)
+	}
+	var reimported Portfolio
+	if err := json.Unmarshal(export("json"), &reimported); err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	cortex = lookup(reimported, "cortex")
+	task := cortex.Milestones[0].Tasks[0]
+	if task.Title != "=Ship it" || task.Status != "done" || task.CompletedAt == nil || *cortex.Milestones[0].DueDate != "2026-12-01" {
+		t.Errorf("unexpected task after CSV round trip: %+v", cortex.Milestones[0])
+	}
+	if len(cortex.Tags) != 4 || len(cortex.Links) != 1 {
+		t.Errorf("unexpected cortex after CSV round trip: %+v", cortex)
+	}
+
+	// A JSON import updates by slug and creates new projects.
+	body := `{"projects": [
+		{"slug": "cortex", "name": "Cortex", "tagline": "Personal dashboard", "status": "active", "category": "flagship", "stack": "Go", "tags": [{"name": "Go"}, {"name": "Plugins", "color": "#123456"}]},
+		{"slug": "new-thing", "name": "New Thing", "tagline": "Fresh", "status": "concept", "category": "lab", "stack": "Go",
+		 "milestones": [{"name": "MVP", "tasks": [{"title": "Sketch"}]}]}
+	]}`
+	if err := json.Unmarshal(parseDataObject(t, importBody("json", []byte(body), 200)), &result); err != nil {
+		t.Fatalf("failed to parse import result: %v", err)
+	}
+	if result.Created != 1 || result.Updated != 1 {
+		t.Errorf("expected one created and one updated project, got %+v", result)
+	}
+	if err := json.Unmarshal(export("json"), &reimported); err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	cortex = lookup(reimported, "cortex")
+	if cortex.Tagline != "Personal dashboard" || len(cortex.Tags) != 2 || len(cortex.Links) != 0 || len(cortex.Milestones) != 0 {
+		t.Errorf("expected cortex to be replaced by the import, got %+v", cortex)
+	}
+	newThing := lookup(reimported, "new-thing")
+	if newThing.Icon != "folder" || len(newThing.Milestones) != 1 || newThing.Milestones[0].Tasks[0].Status != "todo" {
+		t.Errorf("unexpected new project: %+v", newThing)
+	}
+	if len(reimported.Projects) != 17 {
+		t.Errorf("expected projects missing from the import to be kept, got %d", len(reimported.Projects))
+	}
+
+	importBody("json", []byte(`{"projects": [{"slug": "Bad Slug", "name": "x", "tagline": "x", "status": "concept", "category": "lab", "stack": "x"}]}`), 400)
+	importBody("json", []byte(`{"projects": [{"slug": "fresh", "name": "Cortex", "tagline": "x", "status": "concept", "category": "lab", "stack": "x"}]}`), 409)
+	importBody("csv", []byte("record,project,name\ntask,cortex,Orphan\n"), 400)
+	importBody("xml", []byte(`{}`), 400)
+}
+
+func TestStatusHistory_EnforcesAndRecordsTransitions(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := callAPI(t, p, "POST", "/projects", `{"name": "Atlas", "tagline": "x", "status": "concept", "category": "lab", "stack": "Go", "status_note": "just an idea"}`, 201)
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &created); err != nil {
+		t.Fatalf("failed to parse project: %v", err)
+	}
+
+	resp = callAPI(t, p, "PUT", "/projects/atlas", `{"status": "maintenance"}`, 409)
+	if code, _ := parseErrorResponse(t, resp); code != "INVALID_TRANSITION" {
+		t.Errorf("expected INVALID_TRANSITION, got %s", code)
+	}
+	callAPI(t, p, "PUT", "/projects/atlas", `{"tagline": "y", "status_note": "no change"}`, 400)
+	callAPI(t, p, "PUT", "/projects/atlas", `{"status": "design", "status_note": "specs ready"}`, 200)
+	callAPI(t, p, "PUT", "/projects/atlas", `{"status": "development"}`, 200)
+	callAPI(t, p, "PUT", "/projects/atlas", `{"status": "concept"}`, 409)
+	callAPI(t, p, "PUT", "/projects/atlas", `{"status": "concept", "force": true, "status_note": "back to the drawing board"}`, 200)
+
+	var history StatusHistory
+	if err := json.Unmarshal(parseDataObject(t, callAPI(t, p, "GET", "/projects/atlas/history", "", 200)), &history); err != nil {
+		t.Fatalf("failed to parse history: %v", err)
+	}
+	if history.Status != "concept" || len(history.Transitions) != 4 {
+		t.Fatalf("expected 4 transitions ending in concept, got %+v", history)
+	}
+	first, last := history.Transitions[0], history.Transitions[3]
+	if first.FromStatus != nil || first.ToStatus != "concept" || first.Note != "just an idea" {
+		t.Errorf("expected the creation to start the history, got %+v", first)
+	}
+	if history.Transitions[1].Note != "specs ready" || history.Transitions[1].Forced {
+		t.Errorf("expected an allowed transition with its note, got %+v", history.Transitions[1])
+	}
+	if last.FromStatus == nil || *last.FromStatus != "development" || !last.Forced {
+		t.Errorf("expected a forced transition from development, got %+v", last)
+	}
+
+	callAPI(t, p, "GET", "/projects/missing/history", "", 404)
+
+	// Durations run to the next change, and for the current status to now.
+	for i, changedAt := range []string{"2026-01-01 00:00:00", "2026-01-11 00:00:00", "2026-01-13 00:00:00", "2026-03-14 00:00:00"} {
+		if _, err := p.db.Exec("UPDATE status_history SET changed_at = ? WHERE id = ?", changedAt, history.Transitions[i].ID); err != nil {
+			t.Fatalf("failed to backdate history: %v", err)
+		}
+	}
+	timed, err := p.statusHistory(created.ID, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	const day = 24 * 60 * 60
+	if got := timed.TimeInStatus["development"]; got != 60*day {
+		t.Errorf("expected 60 days in development, got %d seconds", got)
+	}
+	if got := timed.TimeInStatus["concept"]; got != 11*day {
+		t.Errorf("expected 11 days in concept across both stays, got %d seconds", got)
+	}
+}
+
+func TestStatusHistory_BackfillsExistingProjects(t *testing.T) {
+	p := newTestPlugin(t)
+
+	var history StatusHistory
+	if err := json.Unmarshal(parseDataObject(t, callAPI(t, p, "GET", "/projects/cortex/history", "", 200)), &history); err != nil {
+		t.Fatalf("failed to parse history: %v", err)
+	}
+	if len(history.Transitions) != 1 || history.Transitions[0].ToStatus != history.Status {
+		t.Fatalf("expected the seeded project to start with its current status, got %+v", history)
+	}
+
+	// Running the migration again does not add to it.
+	migrationSQL, err := migrations.ReadFile("migrations/010_status_history.sql")
+	if err != nil {
+		t.Fatalf("failed to read migration: %v", err)
+	}
+	if _, err := p.db.Exec(string(migrationSQL)); err != nil {
+		t.Fatalf("failed to run migration again: %v", err)
+	}
+	var count int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM status_history WHERE project_id = (SELECT id FROM projects WHERE slug = 'cortex')").Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected one history entry after migrating again, got %d (%v)", count, err)
+	}
+}
+
+func TestArchiveProject_HidesAndRestores(t *testing.T) {
+	p := newTestPlugin(t)
+
+	countProjects := func(query map[string]string) int {
+		t.Helper()
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects", Query: query})
+		if err != nil {
+			t.Fatalf("list projects failed: %v", err)
+		}
+		return len(parseDataArray(t, resp))
+	}
+	before := countProjects(nil)
+
+	resp := callAPI(t, p, "DELETE", "/projects/cortex", "", 200)
+	var archived struct {
+		Archived   string `json:"archived"`
+		ArchivedAt string `json:"archived_at"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &archived); err != nil || archived.Archived != "cortex" || archived.ArchivedAt == "" {
+		t.Fatalf("expected the project archived, got %+v (%v)", archived, err)
+	}
+	if resp.Deleted != nil {
+		t.Error("expected no undo copy for an archive, which POST /restore reverses")
+	}
+
+	if got := countProjects(nil); got != before-1 {
+		t.Errorf("expected %d listed projects without the archived one, got %d", before-1, got)
+	}
+	if got := countProjects(map[string]string{"include_archived": "true"}); got != before {
+		t.Errorf("expected %d projects with include_archived, got %d", before, got)
+	}
+	callAPI(t, p, "GET", "/projects/cortex", "", 404)
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex", Query: map[string]string{"include_archived": "true"}})
+	if err != nil || resp.StatusCode != 200 || !strings.Contains(string(resp.Body), `"archived_at":"`+archived.ArchivedAt+`"`) {
+		t.Fatalf("expected the archived project with include_archived, got %v: %s", err, resp.Body)
+	}
+	results, err := p.Search("cortex")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	for _, result := range results {
+		if result.Title == "Cortex" {
+			t.Errorf("expected archived projects left out of search, got %+v", result)
+		}
+	}
+
+	// Its links and tags are kept, and archiving again changes nothing.
+	var tags int
+	_ = p.db.QueryRow("SELECT COUNT(*) FROM project_tags WHERE project_id = (SELECT id FROM projects WHERE slug = 'cortex')").Scan(&tags)
+	if tags == 0 {
+		t.Error("expected an archived project to keep its tags")
+	}
+	callAPI(t, p, "DELETE", "/projects/cortex", "", 200)
+
+	callAPI(t, p, "POST", "/projects/cortex/restore", "", 200)
+	callAPI(t, p, "POST", "/projects/cortex/restore", "", 409)
+	callAPI(t, p, "GET", "/projects/cortex", "", 200)
+	if got := countProjects(nil); got != before {
+		t.Errorf("expected %d projects after restoring, got %d", before, got)
+	}
+	callAPI(t, p, "POST", "/projects/missing/restore", "", 404)
+}
+
+func TestDeleteProject_PermanentNeedsConfirmation(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := callAPI(t, p, "DELETE", "/projects/cortex/permanent", "", 400)
+	if code, _ := parseErrorResponse(t, resp); code != "CONFIRMATION_REQUIRED" {
+		t.Errorf("expected CONFIRMATION_REQUIRED, got %s", code)
+	}
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/projects/cortex/permanent", Query: map[string]string{"confirm": "fogon"}})
+	if err != nil || resp.StatusCode != 400 {
+		t.Fatalf("expected another project's slug to be refused, got %v %d", err, resp.StatusCode)
+	}
+
+	resp, err = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "DELETE", Path: "/projects/cortex/permanent", Query: map[string]string{"confirm": "cortex"}})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("expected the confirmed delete to succeed, got %v %d", err, resp.StatusCode)
+	}
+	resp, _ = p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/projects/cortex", Query: map[string]string{"include_archived": "true"}})
+	if resp.StatusCode != 404 {
+		t.Errorf("expected the project gone for good, got %d", resp.StatusCode)
+	}
+}
+
+// --- Change notification tests ---
+
+func TestWrites_NotifyChangedTopics(t *testing.T) {
+	p := newTestPlugin(t)
+	var topics []string
+	p.notifyChanged = func(topic string) error {
+		topics = append(topics, topic)
+		return nil
+	}
+
+	callAPI(t, p, "POST", "/projects", `{"name": "Notified", "tagline": "x", "status": "concept", "category": "lab", "stack": "Go"}`, 201)
+	resp := callAPI(t, p, "POST", "/projects/cortex/milestones", `{"name": "MVP"}`, 201)
+	var milestone Milestone
+	if err := json.Unmarshal(parseDataObject(t, resp), &milestone); err != nil {
+		t.Fatalf("failed to parse milestone: %v", err)
+	}
+	resp = callAPI(t, p, "POST", fmt.Sprintf("/milestones/%d/tasks", milestone.ID), `{"title": "Loader"}`, 201)
+	var task struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &task); err != nil {
+		t.Fatalf("failed to parse task: %v", err)
+	}
+	callAPI(t, p, "PUT", fmt.Sprintf("/tasks/%d", task.ID), `{"status": "done"}`, 200)
+	callAPI(t, p, "DELETE", "/projects/notified", "", 200)
+
+	// Reads and refused writes change nothing to refresh.
+	callAPI(t, p, "GET", "/projects", "", 200)
+	callAPI(t, p, "POST", "/projects", `{"name": ""}`, 400)
+	callAPI(t, p, "PUT", "/tasks/999", `{"status": "done"}`, 404)
+
+	if got := strings.Join(topics, ","); got != "projects,tasks,tasks,tasks,projects" {
+		t.Errorf("expected projects, tasks and projects notified, got %s", got)
+	}
+}
@@ -0,0 +1,721 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// portfolioStatusNote is the note of the status changes a portfolio import
+// makes. Imports are not held to statusTransitions.
+const portfolioStatusNote = "portfolio import"
+
+// Portfolio is every project with its tags, links, milestones and tasks, as
+// GET /export writes it and POST /import reads it.
+type Portfolio struct {
+	ExportedAt string             `json:"exported_at"`
+	Projects   []PortfolioProject `json:"projects"`
+}
+
+// PortfolioProject is a project in a portfolio. Projects are matched by slug
+// on import.
+type PortfolioProject struct {
+	Slug       string               `json:"slug"`
+	Name       string               `json:"name"`
+	Tagline    string               `json:"tagline"`
+	Status     string               `json:"status"`
+	Category   string               `json:"category"`
+	Version    *string              `json:"version"`
+	Stack      string               `json:"stack"`
+	Icon       string               `json:"icon"`
+	Color      string               `json:"color"`
+	RepoURL    *string              `json:"repo_url"`
+	WebURL     *string              `json:"web_url"`
+	DocsURL    *string              `json:"docs_url"`
+	Hosting    *string              `json:"hosting"`
+	SortOrder  int                  `json:"sort_order"`
+	Tags       []PortfolioTag       `json:"tags"`
+	Links      []PortfolioLink      `json:"links"`
+	Milestones []PortfolioMilestone `json:"milestones"`
+}
+
+// PortfolioTag is a tag by name. Tags missing on import are created.
+type PortfolioTag struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// PortfolioLink is a custom link of a project.
+type PortfolioLink struct {
+	Label     string `json:"label"`
+	URL       string `json:"url"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// PortfolioMilestone is a milestone with its tasks.
+type PortfolioMilestone struct {
+	Name      string          `json:"name"`
+	Status    string          `json:"status"`
+	StartDate *string         `json:"start_date"`
+	DueDate   *string         `json:"due_date"`
+	SortOrder int             `json:"sort_order"`
+	Tasks     []PortfolioTask `json:"tasks"`
+}
+
+// PortfolioTask is a task of a milestone.
+type PortfolioTask struct {
+	Title       string  `json:"title"`
+	Status      string  `json:"status"`
+	DueDate     *string `json:"due_date"`
+	SortOrder   int     `json:"sort_order"`
+	CompletedAt *string `json:"completed_at"`
+}
+
+// ImportResult counts the projects an import created and updated.
+type ImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// portfolioCSVHeader lists the CSV columns. Every row is one record: a
+// project, or a link, milestone or task of the project in the project
+// column. A task belongs to the milestone row above it.
+var portfolioCSVHeader = []string{
+	"record", "project", "name", "tagline", "status", "category", "version", "stack", "icon", "color",
+	"repo_url", "web_url", "docs_url", "hosting", "sort_order", "tags", "url", "milestone",
+	"start_date", "due_date", "completed_at",
+}
+
+// --- Portfolio handlers ---
+
+// exportPortfolio downloads the portfolio as JSON, or as CSV with ?format=csv.
+func (p *ProjectHubPlugin) exportPortfolio(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	format := req.Query["format"]
+	if format != "" && format != "json" && format != "csv" {
+		return sdk.Error(400, "VALIDATION_ERROR", "format must be json or csv")
+	}
+
+	portfolio, err := p.loadPortfolio(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	filename := "portfolio-" + portfolio.ExportedAt[:len("2006-01-02")]
+	if format == "csv" {
+		content, err := buildPortfolioCSV(portfolio)
+		if err != nil {
+			return nil, err
+		}
+		return sdk.FileResponse("text/csv", filename+".csv", content), nil
+	}
+
+	content, err := json.MarshalIndent(portfolio, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling portfolio: %w", err)
+	}
+	return sdk.FileResponse("application/json", filename+".json", content), nil
+}
+
+// importPortfolio reads a portfolio in the format GET /export writes, JSON by
+// default or CSV with ?format=csv. Projects are upserted by slug: an existing
+// project takes the imported fields, and its tags, links, milestones and
+// tasks are replaced by the imported ones. Projects missing from the import
+// are left alone. Nothing is saved unless every project is valid.
+func (p *ProjectHubPlugin) importPortfolio(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var portfolio Portfolio
+	switch req.Query["format"] {
+	case "", "json":
+		if err := json.Unmarshal(req.Body, &portfolio); err != nil {
+			return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+		}
+	case "csv":
+		parsed, err := parsePortfolioCSV(req.Body)
+		if err != nil {
+			return sdk.Error(400, "VALIDATION_ERROR", err.Error())
+		}
+		portfolio = *parsed
+	default:
+		return sdk.Error(400, "VALIDATION_ERROR", "format must be json or csv")
+	}
+
+	if len(portfolio.Projects) == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "the import has no projects")
+	}
+	seen := make(map[string]bool, len(portfolio.Projects))
+	for i := range portfolio.Projects {
+		project := &portfolio.Projects[i]
+		if message := validatePortfolioProject(project); message != "" {
+			return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("project %q: %s", project.Slug, message))
+		}
+		if seen[project.Slug] {
+			return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("project %q appears more than once", project.Slug))
+		}
+		seen[project.Slug] = true
+	}
+
+	result, err := p.savePortfolio(portfolio.Projects)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return sdk.Error(409, "CONFLICT", "an imported project's name is taken by another project")
+		}
+		return nil, err
+	}
+	return sdk.Success(200, result)
+}
+
+// --- Export ---
+
+func (p *ProjectHubPlugin) loadPortfolio(now time.Time) (*Portfolio, error) {
+	rows, err := p.db.Query(
+		`SELECT id, slug, name, tagline, status, category, version, stack, icon, color,
+		        repo_url, web_url, docs_url, hosting, sort_order
+		 FROM projects ORDER BY category, sort_order, name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying projects: %w", err)
+	}
+	defer rows.Close()
+
+	portfolio := &Portfolio{ExportedAt: now.Format(time.RFC3339), Projects: make([]PortfolioProject, 0)}
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		var project PortfolioProject
+		if err := rows.Scan(
+			&id, &project.Slug, &project.Name, &project.Tagline, &project.Status, &project.Category,
+			&project.Version, &project.Stack, &project.Icon, &project.Color, &project.RepoURL,
+			&project.WebURL, &project.DocsURL, &project.Hosting, &project.SortOrder,
+		); err != nil {
+			return nil, fmt.Errorf("scanning project: %w", err)
+		}
+		ids = append(ids, id)
+		portfolio.Projects = append(portfolio.Projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating projects: %w", err)
+	}
+	rows.Close()
+
+	for i, id := range ids {
+		if err := p.loadPortfolioChildren(id, &portfolio.Projects[i]); err != nil {
+			return nil, err
+		}
+	}
+	return portfolio, nil
+}
+
+// loadPortfolioChildren fills in a project's tags, links, milestones and tasks.
+func (p *ProjectHubPlugin) loadPortfolioChildren(projectID int64, project *PortfolioProject) error {
+	project.Tags = make([]PortfolioTag, 0)
+	tagRows, err := p.db.Query(
+		"SELECT t.name, t.color FROM tags t JOIN project_tags pt ON pt.tag_id = t.id WHERE pt.project_id = ? ORDER BY t.name",
+		projectID,
+	)
+	if err != nil {
+		return fmt.Errorf("querying tags: %w", err)
+	}
+	defer tagRows.Close()
+	for tagRows.Next() {
+		var tag PortfolioTag
+		if err := tagRows.Scan(&tag.Name, &tag.Color); err != nil {
+			return fmt.Errorf("scanning tag: %w", err)
+		}
+		project.Tags = append(project.Tags, tag)
+	}
+	if err := tagRows.Err(); err != nil {
+		return fmt.Errorf("iterating tags: %w", err)
+	}
+
+	project.Links = make([]PortfolioLink, 0)
+	linkRows, err := p.db.Query(
+		"SELECT label, url, sort_order FROM project_links WHERE project_id = ? ORDER BY sort_order, id", projectID,
+	)
+	if err != nil {
+		return fmt.Errorf("querying links: %w", err)
+	}
+	defer linkRows.Close()
+	for linkRows.Next() {
+		var link PortfolioLink
+		if err := linkRows.Scan(&link.Label, &link.URL, &link.SortOrder); err != nil {
+			return fmt.Errorf("scanning link: %w", err)
+		}
+		project.Links = append(project.Links, link)
+	}
+	if err := linkRows.Err(); err != nil {
+		return fmt.Errorf("iterating links: %w", err)
+	}
+
+	project.Milestones = make([]PortfolioMilestone, 0)
+	milestoneRows, err := p.db.Query(
+		`SELECT id, name, status, start_date, due_date, sort_order FROM milestones
+		 WHERE project_id = ? ORDER BY sort_order, id`,
+		projectID,
+	)
+	if err != nil {
+		return fmt.Errorf("querying milestones: %w", err)
+	}
+	defer milestoneRows.Close()
+	milestoneIDs := make([]int64, 0)
+	for milestoneRows.Next() {
+		var id int64
+		var milestone PortfolioMilestone
+		if err := milestoneRows.Scan(&id, &milestone.Name, &milestone.Status, &milestone.StartDate, &milestone.DueDate, &milestone.SortOrder); err != nil {
+			return fmt.Errorf("scanning milestone: %w", err)
+		}
+		milestone.Tasks = make([]PortfolioTask, 0)
+		milestoneIDs = append(milestoneIDs, id)
+		project.Milestones = append(project.Milestones, milestone)
+	}
+	if err := milestoneRows.Err(); err != nil {
+		return fmt.Errorf("iterating milestones: %w", err)
+	}
+	milestoneRows.Close()
+
+	for i, milestoneID := range milestoneIDs {
+		taskRows, err := p.db.Query(
+			`SELECT title, status, due_date, sort_order, completed_at FROM tasks
+			 WHERE milestone_id = ? ORDER BY sort_order, id`,
+			milestoneID,
+		)
+		if err != nil {
+			return fmt.Errorf("querying tasks: %w", err)
+		}
+		for taskRows.Next() {
+			var task PortfolioTask
+			if err := taskRows.Scan(&task.Title, &task.Status, &task.DueDate, &task.SortOrder, &task.CompletedAt); err != nil {
+				taskRows.Close()
+				return fmt.Errorf("scanning task: %w", err)
+			}
+			project.Milestones[i].Tasks = append(project.Milestones[i].Tasks, task)
+		}
+		err = taskRows.Err()
+		taskRows.Close()
+		if err != nil {
+			return fmt.Errorf("iterating tasks: %w", err)
+		}
+	}
+	return nil
+}
+
+// buildPortfolioCSV renders a portfolio as CSV rows, each project followed by
+// its links, then each milestone followed by its tasks.
+func buildPortfolioCSV(portfolio *Portfolio) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	if err := writer.Write(portfolioCSVHeader); err != nil {
+		return nil, fmt.Errorf("writing csv header: %w", err)
+	}
+
+	write := func(values map[string]string) error {
+		record := make([]string, len(portfolioCSVHeader))
+		for i, column := range portfolioCSVHeader {
+			record[i] = sanitizeCell(values[column])
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("writing csv row: %w", err)
+		}
+		return nil
+	}
+
+	for _, project := range portfolio.Projects {
+		tagNames := make([]string, len(project.Tags))
+		for i, tag := range project.Tags {
+			tagNames[i] = tag.Name
+		}
+		if err := write(map[string]string{
+			"record": "project", "project": project.Slug, "name": project.Name, "tagline": project.Tagline,
+			"status": project.Status, "category": project.Category, "version": stringValue(project.Version),
+			"stack": project.Stack, "icon": project.Icon, "color": project.Color,
+			"repo_url": stringValue(project.RepoURL), "web_url": stringValue(project.WebURL),
+			"docs_url": stringValue(project.DocsURL), "hosting": stringValue(project.Hosting),
+			"sort_order": strconv.Itoa(project.SortOrder), "tags": strings.Join(tagNames, ";"),
+		}); err != nil {
+			return nil, err
+		}
+
+		for _, link := range project.Links {
+			if err := write(map[string]string{
+				"record": "link", "project": project.Slug, "name": link.Label, "url": link.URL,
+				"sort_order": strconv.Itoa(link.SortOrder),
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, milestone := range project.Milestones {
+			if err := write(map[string]string{
+				"record": "milestone", "project": project.Slug, "name": milestone.Name, "status": milestone.Status,
+				"start_date": stringValue(milestone.StartDate), "due_date": stringValue(milestone.DueDate),
+				"sort_order": strconv.Itoa(milestone.SortOrder),
+			}); err != nil {
+				return nil, err
+			}
+			for _, task := range milestone.Tasks {
+				if err := write(map[string]string{
+					"record": "task", "project": project.Slug, "milestone": milestone.Name, "name": task.Title,
+					"status": task.Status, "due_date": stringValue(task.DueDate),
+					"sort_order": strconv.Itoa(task.SortOrder), "completed_at": stringValue(task.CompletedAt),
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("flushing csv: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// --- Import ---
+
+// parsePortfolioCSV reads the CSV buildPortfolioCSV writes. Columns are found
+// by header name, so they may be reordered or left out in a spreadsheet.
+func parsePortfolioCSV(content []byte) (*Portfolio, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, required := range []string{"record", "project", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the CSV has no %s column", required)
+		}
+	}
+
+	portfolio := &Portfolio{Projects: make([]PortfolioProject, 0)}
+	projectIndex := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+
+		cell := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return unsanitizeCell(strings.TrimSpace(record[i]))
+		}
+		optional := func(column string) *string {
+			if value := cell(column); value != "" {
+				return &value
+			}
+			return nil
+		}
+		sortOrder := 0
+		if value := cell("sort_order"); value != "" {
+			if sortOrder, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("line %d: sort_order must be a whole number", line)
+			}
+		}
+
+		kind, slug := cell("record"), cell("project")
+		if kind == "" && slug == "" && cell("name") == "" {
+			continue
+		}
+		if kind == "project" {
+			if _, ok := projectIndex[slug]; ok {
+				return nil, fmt.Errorf("line %d: project %q appears more than once", line, slug)
+			}
+			project := PortfolioProject{
+				Slug: slug, Name: cell("name"), Tagline: cell("tagline"), Status: cell("status"),
+				Category: cell("category"), Version: optional("version"), Stack: cell("stack"),
+				Icon: cell("icon"), Color: cell("color"), RepoURL: optional("repo_url"),
+				WebURL: optional("web_url"), DocsURL: optional("docs_url"), Hosting: optional("hosting"),
+				SortOrder: sortOrder, Tags: make([]PortfolioTag, 0),
+				Links: make([]PortfolioLink, 0), Milestones: make([]PortfolioMilestone, 0),
+			}
+			for _, name := range strings.Split(cell("tags"), ";") {
+				if name = strings.TrimSpace(name); name != "" {
+					project.Tags = append(project.Tags, PortfolioTag{Name: name})
+				}
+			}
+			projectIndex[slug] = len(portfolio.Projects)
+			portfolio.Projects = append(portfolio.Projects, project)
+			continue
+		}
+
+		index, ok := projectIndex[slug]
+		if !ok {
+			return nil, fmt.Errorf("line %d: project %q must have its project row before its %s rows", line, slug, kind)
+		}
+		project := &portfolio.Projects[index]
+		switch kind {
+		case "link":
+			project.Links = append(project.Links, PortfolioLink{Label: cell("name"), URL: cell("url"), SortOrder: sortOrder})
+		case "milestone":
+			project.Milestones = append(project.Milestones, PortfolioMilestone{
+				Name: cell("name"), Status: cell("status"), StartDate: optional("start_date"),
+				DueDate: optional("due_date"), SortOrder: sortOrder, Tasks: make([]PortfolioTask, 0),
+			})
+		case "task":
+			if len(project.Milestones) == 0 {
+				return nil, fmt.Errorf("line %d: a task row must follow its milestone row", line)
+			}
+			milestone := &project.Milestones[len(project.Milestones)-1]
+			milestone.Tasks = append(milestone.Tasks, PortfolioTask{
+				Title: cell("name"), Status: cell("status"), DueDate: optional("due_date"),
+				SortOrder: sortOrder, CompletedAt: optional("completed_at"),
+			})
+		default:
+			return nil, fmt.Errorf("line %d: record must be project, link, milestone or task", line)
+		}
+	}
+	return portfolio, nil
+}
+
+// validatePortfolioProject applies the rules the project, link, milestone and
+// task handlers apply, and fills in their defaults. It returns a message
+// describing the first invalid field, or "" when the project is valid.
+func validatePortfolioProject(project *PortfolioProject) string {
+	project.Slug = strings.TrimSpace(project.Slug)
+	project.Name = strings.TrimSpace(project.Name)
+	switch {
+	case project.Slug == "" || toSlug(project.Slug) != project.Slug:
+		return "slug must be lowercase letters, digits and dashes"
+	case project.Slug == graphSlug:
+		return fmt.Sprintf("'%s' is reserved", graphSlug)
+	case project.Name == "" || len(project.Name) > 100:
+		return "name is required and must be 100 characters or less"
+	case strings.TrimSpace(project.Tagline) == "" || len(project.Tagline) > 200:
+		return "tagline is required and must be 200 characters or less"
+	case !isValidStatus(project.Status):
+		return "status must be one of: concept, design, development, active, maintenance, archived, absorbed"
+	case project.Category != "flagship" && project.Category != "lab":
+		return "category must be 'flagship' or 'lab'"
+	case strings.TrimSpace(project.Stack) == "":
+		return "stack is required"
+	case project.Color != "" && !isValidHexColor(project.Color):
+		return "color must be a valid hex color (e.g. #0070F3)"
+	}
+	for _, u := range []*string{project.RepoURL, project.WebURL, project.DocsURL} {
+		if u != nil && *u != "" && !isValidURL(*u) {
+			return "URLs must use http:// or https://"
+		}
+	}
+	if project.Icon == "" {
+		project.Icon = "folder"
+	}
+	if project.Color == "" {
+		project.Color = "#0070F3"
+	}
+
+	for i := range project.Tags {
+		tag := &project.Tags[i]
+		tag.Name = strings.TrimSpace(tag.Name)
+		if tag.Name == "" || len(tag.Name) > 50 {
+			return "tag names are required and must be 50 characters or less"
+		}
+		if tag.Color == "" {
+			tag.Color = "#6B7280"
+		}
+		if !isValidHexColor(tag.Color) {
+			return fmt.Sprintf("tag %q: color must be a valid hex color", tag.Name)
+		}
+	}
+	for _, link := range project.Links {
+		if strings.TrimSpace(link.Label) == "" || !isValidURL(link.URL) {
+			return "links need a label and an http:// or https:// URL"
+		}
+	}
+	for i := range project.Milestones {
+		milestone := &project.Milestones[i]
+		milestone.Name = strings.TrimSpace(milestone.Name)
+		if milestone.Name == "" || len(milestone.Name) > maxMilestoneNameLength {
+			return "milestone names are required and must be at most 100 characters"
+		}
+		if milestone.Status == "" {
+			milestone.Status = "open"
+		}
+		if !validMilestoneStatuses[milestone.Status] {
+			return fmt.Sprintf("milestone %q: status must be open or closed", milestone.Name)
+		}
+		if !isValidOptionalDate(milestone.StartDate) || !isValidOptionalDate(milestone.DueDate) {
+			return fmt.Sprintf("milestone %q: dates must use the YYYY-MM-DD format", milestone.Name)
+		}
+		for j := range milestone.Tasks {
+			task := &milestone.Tasks[j]
+			task.Title = strings.TrimSpace(task.Title)
+			if task.Title == "" || len(task.Title) > maxTaskTitleLength {
+				return fmt.Sprintf("milestone %q: task titles are required and must be at most 200 characters", milestone.Name)
+			}
+			if task.Status == "" {
+				task.Status = "todo"
+			}
+			if !validTaskStatuses[task.Status] {
+				return fmt.Sprintf("task %q: status must be todo, in_progress or done", task.Title)
+			}
+			if !isValidOptionalDate(task.DueDate) {
+				return fmt.Sprintf("task %q: due_date must use the YYYY-MM-DD format", task.Title)
+			}
+		}
+	}
+	return ""
+}
+
+// savePortfolio upserts the projects in one transaction.
+func (p *ProjectHubPlugin) savePortfolio(projects []PortfolioProject) (*ImportResult, error) {
+	transaction, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	result := &ImportResult{}
+	for _, project := range projects {
+		var projectID int64
+		var previousStatus string
+		err := transaction.QueryRow("SELECT id, status FROM projects WHERE slug = ?", project.Slug).Scan(&projectID, &previousStatus)
+		switch {
+		case err == sql.ErrNoRows:
+			inserted, err := transaction.Exec(
+				`INSERT INTO projects (name, slug, tagline, status, category, version, stack, icon, color, repo_url, web_url, docs_url, hosting, sort_order)
+				 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				project.Name, project.Slug, project.Tagline, project.Status, project.Category, project.Version,
+				project.Stack, project.Icon, project.Color, project.RepoURL, project.WebURL, project.DocsURL,
+				project.Hosting, project.SortOrder,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("inserting project: %w", err)
+			}
+			projectID, _ = inserted.LastInsertId()
+			if err := recordStatusChange(transaction, projectID, "", project.Status, portfolioStatusNote, false); err != nil {
+				return nil, err
+			}
+			result.Created++
+		case err != nil:
+			return nil, fmt.Errorf("querying project: %w", err)
+		default:
+			if _, err := transaction.Exec(
+				`UPDATE projects SET name = ?, tagline = ?, status = ?, category = ?, version = ?, stack = ?, icon = ?,
+				        color = ?, repo_url = ?, web_url = ?, docs_url = ?, hosting = ?, sort_order = ?, updated_at = datetime('now')
+				 WHERE id = ?`,
+				project.Name, project.Tagline, project.Status, project.Category, project.Version, project.Stack,
+				project.Icon, project.Color, project.RepoURL, project.WebURL, project.DocsURL, project.Hosting,
+				project.SortOrder, projectID,
+			); err != nil {
+				return nil, fmt.Errorf("updating project: %w", err)
+			}
+			if project.Status != previousStatus {
+				forced := checkTransition(previousStatus, project.Status) != ""
+				if err := recordStatusChange(transaction, projectID, previousStatus, project.Status, portfolioStatusNote, forced); err != nil {
+					return nil, err
+				}
+			}
+			for _, table := range []string{"project_tags", "project_links", "milestones"} {
+				if _, err := transaction.Exec("DELETE FROM "+table+" WHERE project_id = ?", projectID); err != nil {
+					return nil, fmt.Errorf("clearing %s: %w", table, err)
+				}
+			}
+			result.Updated++
+		}
+
+		if err := savePortfolioChildren(transaction, projectID, &project); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, fmt.Errorf("committing import: %w", err)
+	}
+	return result, nil
+}
+
+// savePortfolioChildren inserts a project's tags, links, milestones and tasks.
+func savePortfolioChildren(transaction *sql.Tx, projectID int64, project *PortfolioProject) error {
+	for _, tag := range project.Tags {
+		if _, err := transaction.Exec("INSERT OR IGNORE INTO tags (name, color) VALUES (?, ?)", tag.Name, tag.Color); err != nil {
+			return fmt.Errorf("creating tag: %w", err)
+		}
+		if _, err := transaction.Exec(
+			"INSERT OR IGNORE INTO project_tags (project_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			projectID, tag.Name,
+		); err != nil {
+			return fmt.Errorf("assigning tag: %w", err)
+		}
+	}
+
+	for _, link := range project.Links {
+		if _, err := transaction.Exec(
+			"INSERT INTO project_links (project_id, label, url, sort_order) VALUES (?, ?, ?, ?)",
+			projectID, strings.TrimSpace(link.Label), link.URL, link.SortOrder,
+		); err != nil {
+			return fmt.Errorf("inserting link: %w", err)
+		}
+	}
+
+	for _, milestone := range project.Milestones {
+		inserted, err := transaction.Exec(
+			"INSERT INTO milestones (project_id, name, status, start_date, due_date, sort_order) VALUES (?, ?, ?, ?, ?, ?)",
+			projectID, milestone.Name, milestone.Status, nullableDate(milestone.StartDate),
+			nullableDate(milestone.DueDate), milestone.SortOrder,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting milestone: %w", err)
+		}
+		milestoneID, _ := inserted.LastInsertId()
+
+		for _, task := range milestone.Tasks {
+			// A done task keeps its completion time, or is completed now.
+			if _, err := transaction.Exec(
+				`INSERT INTO tasks (milestone_id, title, status, due_date, sort_order, completed_at)
+				 VALUES (?, ?, ?, ?, ?, CASE WHEN ? = 'done' THEN COALESCE(?, datetime('now')) END)`,
+				milestoneID, task.Title, task.Status, nullableDate(task.DueDate), task.SortOrder,
+				task.Status, task.CompletedAt,
+			); err != nil {
+				return fmt.Errorf("inserting task: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// --- Helpers ---
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// sanitizeCell neutralizes values spreadsheet software would run as formulas.
+func sanitizeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unsanitizeCell reverses sanitizeCell.
+func unsanitizeCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// Relation kinds, matching the project_relations.kind column.
+const (
+	relationDependsOn    = "depends-on"
+	relationAbsorbedInto = "absorbed-into"
+	relationSpunOffFrom  = "spun-off-from"
+)
+
+// graphSlug is the path segment GET /projects/graph uses, so no project may
+// take it as a slug.
+const graphSlug = "graph"
+
+// ProjectRelation reads "source kind target", e.g. fogon depends-on cortex.
+type ProjectRelation struct {
+	ID        int64  `json:"id"`
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Kind      string `json:"kind"`
+	CreatedAt string `json:"created_at"`
+}
+
+// GraphNode is a project in the ecosystem graph.
+type GraphNode struct {
+	ID       int64  `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Category string `json:"category"`
+	Icon     string `json:"icon"`
+	Color    string `json:"color"`
+}
+
+// ProjectGraph is every project and every relation between them.
+type ProjectGraph struct {
+	Nodes []GraphNode       `json:"nodes"`
+	Edges []ProjectRelation `json:"edges"`
+}
+
+// relationSelect is the base query ProjectRelation rows are scanned from.
+const relationSelect = `SELECT r.id, s.slug, t.slug, r.kind, r.created_at
+	FROM project_relations r
+	JOIN projects s ON s.id = r.project_id
+	JOIN projects t ON t.id = r.related_project_id`
+
+// --- Relation handlers ---
+
+// getProjectGraph returns the nodes and edges of the project ecosystem.
+func (p *ProjectHubPlugin) getProjectGraph(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	rows, err := p.db.Query("SELECT id, slug, name, status, category, icon, color FROM projects ORDER BY category, sort_order, name")
+	if err != nil {
+		return nil, fmt.Errorf("querying projects: %w", err)
+	}
+	defer rows.Close()
+
+	graph := ProjectGraph{Nodes: make([]GraphNode, 0), Edges: make([]ProjectRelation, 0)}
+	for rows.Next() {
+		var node GraphNode
+		if err := rows.Scan(&node.ID, &node.Slug, &node.Name, &node.Status, &node.Category, &node.Icon, &node.Color); err != nil {
+			return nil, fmt.Errorf("scanning project: %w", err)
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating projects: %w", err)
+	}
+
+	edges, err := p.queryRelations(relationSelect + " ORDER BY r.id")
+	if err != nil {
+		return nil, err
+	}
+	graph.Edges = edges
+
+	return sdk.Success(200, graph)
+}
+
+// listRelations returns the relations a project is on either end of.
+func (p *ProjectHubPlugin) listRelations(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/relations
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	projectID, err := p.projectIDBySlug(pathParts[1])
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	relations, err := p.queryRelations(
+		relationSelect+" WHERE r.project_id = ? OR r.related_project_id = ? ORDER BY r.id",
+		projectID, projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, relations)
+}
+
+// createRelation links the project in the path to a target project.
+func (p *ProjectHubPlugin) createRelation(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/relations
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	projectID, err := p.projectIDBySlug(pathParts[1])
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var input struct {
+		Target string `json:"target"`
+		Kind   string `json:"kind"`
+	}
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if !isValidRelationKind(input.Kind) {
+		return sdk.Error(400, "VALIDATION_ERROR", "kind must be one of: depends-on, absorbed-into, spun-off-from")
+	}
+	if strings.TrimSpace(input.Target) == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "target is required")
+	}
+	targetID, err := p.projectIDBySlug(input.Target)
+	if err == sql.ErrNoRows {
+		return sdk.Error(400, "VALIDATION_ERROR", "target project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if targetID == projectID {
+		return sdk.Error(400, "VALIDATION_ERROR", "a project cannot be related to itself")
+	}
+
+	cycle, err := p.relationReaches(input.Kind, targetID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("%s %s %s would create a cycle", pathParts[1], input.Kind, input.Target))
+	}
+
+	result, err := p.db.Exec(
+		"INSERT INTO project_relations (project_id, related_project_id, kind) VALUES (?, ?, ?)",
+		projectID, targetID, input.Kind,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			if input.Kind == relationAbsorbedInto {
+				return sdk.Error(409, "CONFLICT", "project is already absorbed into another project")
+			}
+			return sdk.Error(409, "CONFLICT", "relation already exists")
+		}
+		return nil, fmt.Errorf("inserting relation: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	relation, err := p.getRelationByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(201, relation)
+}
+
+// deleteRelation removes a relation. It leaves the projects' statuses as
+// they are.
+func (p *ProjectHubPlugin) deleteRelation(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	idStr := strings.TrimPrefix(req.Path, "/relations/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid relation id")
+	}
+
+	result, err := p.db.Exec("DELETE FROM project_relations WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting relation: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "relation not found")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// --- Helpers ---
+
+// absorbedTarget resolves the absorbed_into slug given with a project's
+// status. It returns an error response when the target is invalid.
+func (p *ProjectHubPlugin) absorbedTarget(projectID int64, slug string) (int64, *sdk.APIResponse, error) {
+	targetID, err := p.projectIDBySlug(slug)
+	if err == sql.ErrNoRows {
+		resp, err := sdk.Error(400, "VALIDATION_ERROR", "absorbed_into project not found")
+		return 0, resp, err
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if targetID == projectID {
+		resp, err := sdk.Error(400, "VALIDATION_ERROR", "a project cannot be absorbed into itself")
+		return 0, resp, err
+	}
+
+	cycle, err := p.relationReaches(relationAbsorbedInto, targetID, projectID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if cycle {
+		resp, err := sdk.Error(400, "VALIDATION_ERROR", "absorbed_into would create a cycle")
+		return 0, resp, err
+	}
+	return targetID, nil, nil
+}
+
+// setAbsorbedInto records that a project was absorbed into the target,
+// replacing any earlier absorbed-into relation.
+func (p *ProjectHubPlugin) setAbsorbedInto(projectID, targetID int64) error {
+	transaction, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	if _, err := transaction.Exec(
+		"DELETE FROM project_relations WHERE project_id = ? AND kind = ?", projectID, relationAbsorbedInto,
+	); err != nil {
+		return fmt.Errorf("clearing absorbed-into relation: %w", err)
+	}
+	if _, err := transaction.Exec(
+		"INSERT INTO project_relations (project_id, related_project_id, kind) VALUES (?, ?, ?)",
+		projectID, targetID, relationAbsorbedInto,
+	); err != nil {
+		return fmt.Errorf("inserting absorbed-into relation: %w", err)
+	}
+	return transaction.Commit()
+}
+
+// relationReaches reports whether from already leads to to through
+// relations of the kind, in which case linking to to from would close a cycle.
+func (p *ProjectHubPlugin) relationReaches(kind string, from, to int64) (bool, error) {
+	var count int
+	err := p.db.QueryRow(
+		`WITH RECURSIVE reachable(id) AS (
+			SELECT ?
+			UNION
+			SELECT r.related_project_id FROM project_relations r
+			JOIN reachable ON r.project_id = reachable.id
+			WHERE r.kind = ?
+		)
+		SELECT COUNT(*) FROM reachable WHERE id = ?`,
+		from, kind, to,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("checking relation cycle: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (p *ProjectHubPlugin) getRelationByID(id int64) (*ProjectRelation, error) {
+	relations, err := p.queryRelations(relationSelect+" WHERE r.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(relations) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &relations[0], nil
+}
+
+func (p *ProjectHubPlugin) queryRelations(query string, args ...interface{}) ([]ProjectRelation, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying relations: %w", err)
+	}
+	defer rows.Close()
+
+	relations := make([]ProjectRelation, 0)
+	for rows.Next() {
+		var relation ProjectRelation
+		if err := rows.Scan(&relation.ID, &relation.Source, &relation.Target, &relation.Kind, &relation.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning relation: %w", err)
+		}
+		relations = append(relations, relation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating relations: %w", err)
+	}
+	return relations, nil
+}
+
+func isValidRelationKind(kind string) bool {
+	return kind == relationDependsOn || kind == relationAbsorbedInto || kind == relationSpunOffFrom
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// deletedProjectKind names a deleted project handed back to RestoreDeleted.
+const deletedProjectKind = "project"
+
+// projectTables are the rows deleting a project removes, by table, in the
+// order RestoreDeleted inserts them back: the project first, then the rows
+// that cascade with it. Every ? is the project's ID.
+var projectTables = []struct{ table, where string }{
+	{"projects", "id = ?"},
+	{"project_links", "project_id = ?"},
+	{"project_tags", "project_id = ?"},
+	{"milestones", "project_id = ?"},
+	{"tasks", "milestone_id IN (SELECT id FROM milestones WHERE project_id = ?)"},
+	{"project_watches", "project_id = ?"},
+	{"changelog_entries", "project_id = ?"},
+	{"time_entries", "project_id = ?"},
+	{"project_relations", "project_id = ? OR related_project_id = ?"},
+	{"project_notes", "project_id = ?"},
+	{"project_note_revisions", "note_id IN (SELECT id FROM project_notes WHERE project_id = ?)"},
+	{"project_attention", "project_id = ?"},
+	{"status_history", "project_id = ?"},
+	{"project_archive", "project_id = ?"},
+}
+
+// snapshotProject copies a project and the rows that cascade with it before
+// it is deleted.
+func snapshotProject(tx *sql.Tx, projectID int64) ([]sdk.DeletedRows, error) {
+	snapshots := make([]sdk.DeletedRows, 0, len(projectTables))
+	for _, table := range projectTables {
+		args := make([]interface{}, strings.Count(table.where, "?"))
+		for i := range args {
+			args[i] = projectID
+		}
+		snapshot, err := sdk.SnapshotRows(tx, table.table, table.where, args...)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// RestoreDeleted puts back a project removed by DELETE
+// /projects/{slug}/permanent, with its original ID and the links, tags,
+// milestones, notes, time entries and relations deleted with it, when the
+// host undoes the delete. Relations to
+// projects and tags deleted since are left off.
+func (p *ProjectHubPlugin) RestoreDeleted(kind string, data []byte) error {
+	if kind != deletedProjectKind {
+		return fmt.Errorf("cannot restore deleted %q", kind)
+	}
+	snapshots, err := sdk.DecodeDeletedRows(data)
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := sdk.RestoreRows(tx, withoutRetiredTables(snapshots)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
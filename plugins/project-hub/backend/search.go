@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// Search finds projects whose name, tagline, stack, notes or tags contain
+// query, for the host's global search. Name matches come first, then the
+// projects' usual order.
+func (p *ProjectHubPlugin) Search(query string) ([]sdk.SearchResult, error) {
+	pattern := "%" + escapeLike(strings.TrimSpace(query)) + "%"
+	rows, err := p.db.Query(
+		`SELECT p.id, p.name, p.tagline, p.status
+		 FROM projects p
+		 WHERE `+notArchived+` AND (p.name LIKE ? ESCAPE '\' OR p.tagline LIKE ? ESCAPE '\'
+		    OR p.stack LIKE ? ESCAPE '\'
+		    OR EXISTS (
+		       SELECT 1 FROM project_notes n
+		       WHERE n.project_id = p.id AND (n.title LIKE ? ESCAPE '\' OR n.body LIKE ? ESCAPE '\')
+		    )
+		    OR EXISTS (
+		       SELECT 1 FROM project_tags pt JOIN tags t ON t.id = pt.tag_id
+		       WHERE pt.project_id = p.id AND t.name LIKE ? ESCAPE '\'
+		    ))
+		 ORDER BY p.name LIKE ? ESCAPE '\' DESC, p.sort_order, p.name
+		 LIMIT ?`,
+		pattern, pattern, pattern, pattern, pattern, pattern, pattern, sdk.MaxSearchResults,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching projects: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]sdk.SearchResult, 0)
+	for rows.Next() {
+		var id int64
+		var name, tagline, status string
+		if err := rows.Scan(&id, &name, &tagline, &status); err != nil {
+			return nil, fmt.Errorf("scanning project: %w", err)
+		}
+		results = append(results, sdk.SearchResult{
+			Type:    "project",
+			ID:      strconv.FormatInt(id, 10),
+			Title:   name,
+			Snippet: sdk.SearchSnippet(tagline+" · "+status, query),
+			Link:    "/plugins/project-hub",
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating projects: %w", err)
+	}
+
+	return results, nil
+}
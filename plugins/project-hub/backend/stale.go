@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// staleCheckInterval is how often the plugin looks for stale projects.
+const staleCheckInterval = time.Hour
+
+// digestInterval is how long the plugin waits between two stale projects
+// digests.
+const digestInterval = 7 * 24 * time.Hour
+
+// maxStaleDays bounds the stale_days setting.
+const maxStaleDays = 365
+
+// sqliteTimeLayout is the format of datetime('now').
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// needsAttentionColumn selects Project.NeedsAttention for the projects row
+// aliased p. Touching a project clears its flag right away, before the next
+// check removes it.
+const needsAttentionColumn = `EXISTS(SELECT 1 FROM project_attention a WHERE a.project_id = p.id AND a.flagged_at > p.updated_at)`
+
+// errDigestNotSent reports that the digest could not be delivered. The check
+// itself is saved; the digest is retried on the next check.
+var errDigestNotSent = errors.New("sending stale projects digest")
+
+// StaleSettings configures the stale projects check.
+type StaleSettings struct {
+	StaleDays    int     `json:"stale_days"`
+	LastCheckAt  *string `json:"last_check_at"`
+	LastDigestAt *string `json:"last_digest_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
+
+// StaleProject is a project the check flagged.
+type StaleProject struct {
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	UpdatedAt string `json:"updated_at"`
+	DaysIdle  int    `json:"days_idle"`
+}
+
+// StaleCheckResult is the outcome of a stale projects check.
+type StaleCheckResult struct {
+	Stale      []StaleProject `json:"stale"`
+	DigestSent bool           `json:"digest_sent"`
+}
+
+// --- Stale handlers ---
+
+func (p *ProjectHubPlugin) getStaleSettings(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	settings, err := p.loadStaleSettings()
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, settings)
+}
+
+// updateStaleSettings changes how many days a project may go untouched. The
+// flags are recomputed right away; no digest is sent.
+func (p *ProjectHubPlugin) updateStaleSettings(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input struct {
+		StaleDays *int `json:"stale_days"`
+	}
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+	if input.StaleDays == nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "stale_days is required")
+	}
+	if *input.StaleDays < 1 || *input.StaleDays > maxStaleDays {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("stale_days must be between 1 and %d", maxStaleDays))
+	}
+
+	if _, err := p.db.Exec(
+		"UPDATE stale_settings SET stale_days = ?, updated_at = datetime('now') WHERE id = 1", *input.StaleDays,
+	); err != nil {
+		return nil, fmt.Errorf("updating stale settings: %w", err)
+	}
+	if _, err := p.flagStaleProjects(time.Now().UTC(), *input.StaleDays); err != nil {
+		return nil, err
+	}
+
+	settings, err := p.loadStaleSettings()
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, settings)
+}
+
+// runStaleCheck runs the stale projects check now, sending the digest if one
+// is due.
+func (p *ProjectHubPlugin) runStaleCheck(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	result, err := p.checkStaleProjects(time.Now().UTC())
+	if errors.Is(err, errDigestNotSent) {
+		return sdk.Error(502, "NOTIFICATION_FAILED", err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, result)
+}
+
+// --- Stale check ---
+
+// checkStaleProjects flags the projects in development or design untouched
+// for the configured number of days, and sends the digest listing them when
+// the last one is at least a week old. No digest is sent while nothing is
+// stale.
+func (p *ProjectHubPlugin) checkStaleProjects(now time.Time) (*StaleCheckResult, error) {
+	settings, err := p.loadStaleSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	stale, err := p.flagStaleProjects(now, settings.StaleDays)
+	if err != nil {
+		return nil, err
+	}
+	result := &StaleCheckResult{Stale: stale}
+
+	if len(stale) == 0 || !digestDue(settings.LastDigestAt, now) {
+		return result, nil
+	}
+
+	notify := p.notify
+	if notify == nil {
+		notify = sdk.SendNotification
+	}
+	title, body := staleDigest(stale, settings.StaleDays)
+	if err := notify(title, body, false); err != nil {
+		return nil, fmt.Errorf("%w: %v", errDigestNotSent, err)
+	}
+	if _, err := p.db.Exec(
+		"UPDATE stale_settings SET last_digest_at = ? WHERE id = 1", now.Format(sqliteTimeLayout),
+	); err != nil {
+		return nil, fmt.Errorf("recording stale digest: %w", err)
+	}
+	result.DigestSent = true
+	return result, nil
+}
+
+// flagStaleProjects replaces the attention flags with the projects stale at
+// now and returns them, longest idle first.
+func (p *ProjectHubPlugin) flagStaleProjects(now time.Time, staleDays int) ([]StaleProject, error) {
+	cutoff := now.AddDate(0, 0, -staleDays).Format(sqliteTimeLayout)
+	checkedAt := now.Format(sqliteTimeLayout)
+
+	transaction, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	rows, err := transaction.Query(
+		`SELECT id, slug, name, status, updated_at FROM projects p
+		 WHERE status IN ('development', 'design') AND updated_at < ? AND `+notArchived+`
+		 ORDER BY updated_at, name`,
+		cutoff,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying stale projects: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	stale := make([]StaleProject, 0)
+	for rows.Next() {
+		var id int64
+		var project StaleProject
+		if err := rows.Scan(&id, &project.Slug, &project.Name, &project.Status, &project.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning stale project: %w", err)
+		}
+		if updatedAt, err := time.Parse(sqliteTimeLayout, project.UpdatedAt); err == nil {
+			project.DaysIdle = int(now.Sub(updatedAt).Hours() / 24)
+		}
+		ids = append(ids, id)
+		stale = append(stale, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating stale projects: %w", err)
+	}
+	rows.Close()
+
+	if _, err := transaction.Exec("DELETE FROM project_attention"); err != nil {
+		return nil, fmt.Errorf("clearing attention flags: %w", err)
+	}
+	for _, id := range ids {
+		if _, err := transaction.Exec(
+			"INSERT INTO project_attention (project_id, flagged_at) VALUES (?, ?)", id, checkedAt,
+		); err != nil {
+			return nil, fmt.Errorf("flagging project: %w", err)
+		}
+	}
+	if _, err := transaction.Exec("UPDATE stale_settings SET last_check_at = ? WHERE id = 1", checkedAt); err != nil {
+		return nil, fmt.Errorf("recording stale check: %w", err)
+	}
+	if err := transaction.Commit(); err != nil {
+		return nil, fmt.Errorf("committing attention flags: %w", err)
+	}
+	return stale, nil
+}
+
+// startStaleCheck runs the stale projects check now and then on every
+// interval until the returned stop function is called.
+func (p *ProjectHubPlugin) startStaleCheck(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// A failing check changes nothing; it is retried next tick.
+			_, _ = p.checkStaleProjects(time.Now().UTC())
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// --- Helpers ---
+
+func (p *ProjectHubPlugin) loadStaleSettings() (*StaleSettings, error) {
+	var settings StaleSettings
+	err := p.db.QueryRow(
+		"SELECT stale_days, last_check_at, last_digest_at, updated_at FROM stale_settings WHERE id = 1",
+	).Scan(&settings.StaleDays, &settings.LastCheckAt, &settings.LastDigestAt, &settings.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("querying stale settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// digestDue reports whether a week has passed since the last digest.
+func digestDue(lastDigestAt *string, now time.Time) bool {
+	if lastDigestAt == nil {
+		return true
+	}
+	last, err := time.Parse(sqliteTimeLayout, *lastDigestAt)
+	if err != nil {
+		return true
+	}
+	return !now.Before(last.Add(digestInterval))
+}
+
+// staleDigest returns the title and body of the digest notification.
+func staleDigest(stale []StaleProject, staleDays int) (string, string) {
+	title := fmt.Sprintf("%d stale project", len(stale))
+	if len(stale) != 1 {
+		title += "s"
+	}
+
+	lines := make([]string, len(stale))
+	for i, project := range stale {
+		lines[i] = fmt.Sprintf("- %s (%s): untouched for %d days", project.Name, project.Status, project.DaysIdle)
+	}
+	body := fmt.Sprintf("Not updated in the last %d days:\n%s", staleDays, strings.Join(lines, "\n"))
+	return title, body
+}
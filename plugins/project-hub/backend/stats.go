@@ -0,0 +1,321 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+const (
+	// defaultStatsWeeks is how many weeks of history GET /projects/{slug}/stats returns.
+	defaultStatsWeeks = 12
+	// maxStatsWeeks caps the ?weeks= query parameter.
+	maxStatsWeeks = 52
+	// maxBurndownDays caps the burndown series so a milestone with a far-off
+	// due date does not produce an unbounded response.
+	maxBurndownDays = 366
+)
+
+// ProjectStats is the charting data for a single project.
+type ProjectStats struct {
+	Project     string       `json:"project"`
+	OpenTasks   int          `json:"open_tasks"`
+	ClosedTasks int          `json:"closed_tasks"`
+	Weekly      []WeeklyStat `json:"weekly"`
+	Burndown    *Burndown    `json:"burndown"`
+}
+
+// WeeklyStat summarizes task activity for one week, starting on Monday.
+// Open and Closed are the totals at the end of the week.
+type WeeklyStat struct {
+	WeekStart string `json:"week_start"`
+	Opened    int    `json:"opened"`
+	Completed int    `json:"completed"`
+	Open      int    `json:"open"`
+	Closed    int    `json:"closed"`
+}
+
+// Burndown is the remaining-work series for the current milestone.
+type Burndown struct {
+	MilestoneID int64           `json:"milestone_id"`
+	Name        string          `json:"name"`
+	StartDate   string          `json:"start_date"`
+	DueDate     *string         `json:"due_date"`
+	Total       int             `json:"total"`
+	Points      []BurndownPoint `json:"points"`
+}
+
+// BurndownPoint is one day of a burndown. Remaining is null for days that
+// have not happened yet; Ideal is the straight line from Total to zero.
+type BurndownPoint struct {
+	Date      string  `json:"date"`
+	Remaining *int    `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+}
+
+// statsTask is the subset of a task needed to compute stats.
+type statsTask struct {
+	MilestoneID int64
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// statsMilestone is the subset of a milestone needed to compute a burndown.
+type statsMilestone struct {
+	ID        int64
+	Name      string
+	StartDate time.Time
+	DueDate   *time.Time
+}
+
+func (p *ProjectHubPlugin) getProjectStats(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/stats
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+	slug := pathParts[1]
+
+	weeks := defaultStatsWeeks
+	if raw := req.Query["weeks"]; raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxStatsWeeks {
+			return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("weeks must be a number between 1 and %d", maxStatsWeeks))
+		}
+		weeks = parsed
+	}
+
+	var projectID int64
+	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying project: %w", err)
+	}
+
+	tasks, err := p.loadStatsTasks(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	milestone, err := p.loadCurrentMilestone(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := computeProjectStats(tasks, milestone, time.Now().UTC(), weeks)
+	stats.Project = slug
+	return sdk.Success(200, stats)
+}
+
+// loadStatsTasks returns every task in the project's milestones. Tasks marked
+// done without a completion time count as completed when last updated.
+func (p *ProjectHubPlugin) loadStatsTasks(projectID int64) ([]statsTask, error) {
+	rows, err := p.db.Query(
+		`SELECT t.milestone_id, t.created_at,
+		        COALESCE(t.completed_at, CASE WHEN t.status = 'done' THEN t.updated_at END)
+		 FROM tasks t JOIN milestones m ON m.id = t.milestone_id
+		 WHERE m.project_id = ?`, projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]statsTask, 0)
+	for rows.Next() {
+		var task statsTask
+		var createdAt string
+		var completedAt sql.NullString
+		if err := rows.Scan(&task.MilestoneID, &createdAt, &completedAt); err != nil {
+			return nil, fmt.Errorf("scanning task: %w", err)
+		}
+
+		task.CreatedAt, err = parseTimestamp(createdAt)
+		if err != nil {
+			return nil, err
+		}
+		if completedAt.Valid {
+			completed, err := parseTimestamp(completedAt.String)
+			if err != nil {
+				return nil, err
+			}
+			task.CompletedAt = &completed
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// loadCurrentMilestone returns the open milestone due soonest (undated ones
+// last), or nil if the project has no open milestones.
+func (p *ProjectHubPlugin) loadCurrentMilestone(projectID int64) (*statsMilestone, error) {
+	var milestone statsMilestone
+	var startDate string
+	var dueDate sql.NullString
+	err := p.db.QueryRow(
+		`SELECT id, name, COALESCE(start_date, created_at), due_date
+		 FROM milestones
+		 WHERE project_id = ? AND status = 'open'
+		 ORDER BY due_date IS NULL, due_date, sort_order, id
+		 LIMIT 1`, projectID,
+	).Scan(&milestone.ID, &milestone.Name, &startDate, &dueDate)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying current milestone: %w", err)
+	}
+
+	milestone.StartDate, err = parseTimestamp(startDate)
+	if err != nil {
+		return nil, err
+	}
+	if dueDate.Valid {
+		due, err := parseTimestamp(dueDate.String)
+		if err != nil {
+			return nil, err
+		}
+		milestone.DueDate = &due
+	}
+
+	return &milestone, nil
+}
+
+// computeProjectStats builds the weekly series ending with the week containing
+// now, and the burndown for milestone when there is one.
+func computeProjectStats(tasks []statsTask, milestone *statsMilestone, now time.Time, weeks int) *ProjectStats {
+	stats := &ProjectStats{Weekly: make([]WeeklyStat, 0, weeks)}
+
+	for _, task := range tasks {
+		if task.CompletedAt != nil && !task.CompletedAt.After(now) {
+			stats.ClosedTasks++
+		} else {
+			stats.OpenTasks++
+		}
+	}
+
+	currentWeek := startOfWeek(now)
+	for i := weeks - 1; i >= 0; i-- {
+		weekStart := currentWeek.AddDate(0, 0, -7*i)
+		weekEnd := weekStart.AddDate(0, 0, 7)
+
+		week := WeeklyStat{WeekStart: weekStart.Format("2006-01-02")}
+		for _, task := range tasks {
+			if !task.CreatedAt.Before(weekStart) && task.CreatedAt.Before(weekEnd) {
+				week.Opened++
+			}
+			if task.CompletedAt != nil && !task.CompletedAt.Before(weekStart) && task.CompletedAt.Before(weekEnd) {
+				week.Completed++
+			}
+			if task.CreatedAt.Before(weekEnd) {
+				if task.CompletedAt != nil && task.CompletedAt.Before(weekEnd) {
+					week.Closed++
+				} else {
+					week.Open++
+				}
+			}
+		}
+		stats.Weekly = append(stats.Weekly, week)
+	}
+
+	if milestone != nil {
+		stats.Burndown = computeBurndown(tasks, milestone, now)
+	}
+
+	return stats
+}
+
+// computeBurndown builds one point per day from the milestone start to its due
+// date, or to today for milestones without one.
+func computeBurndown(tasks []statsTask, milestone *statsMilestone, now time.Time) *Burndown {
+	start := startOfDay(milestone.StartDate)
+	today := startOfDay(now)
+
+	end := today
+	if milestone.DueDate != nil && !startOfDay(*milestone.DueDate).Before(start) {
+		end = startOfDay(*milestone.DueDate)
+	}
+	if end.Before(start) {
+		end = start
+	}
+	if limit := start.AddDate(0, 0, maxBurndownDays-1); end.After(limit) {
+		end = limit
+	}
+
+	burndown := &Burndown{
+		MilestoneID: milestone.ID,
+		Name:        milestone.Name,
+		StartDate:   start.Format("2006-01-02"),
+		Points:      make([]BurndownPoint, 0),
+	}
+	if milestone.DueDate != nil {
+		due := milestone.DueDate.Format("2006-01-02")
+		burndown.DueDate = &due
+	}
+
+	milestoneTasks := make([]statsTask, 0)
+	for _, task := range tasks {
+		if task.MilestoneID == milestone.ID {
+			milestoneTasks = append(milestoneTasks, task)
+		}
+	}
+	burndown.Total = len(milestoneTasks)
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	for i := 0; i < days; i++ {
+		day := start.AddDate(0, 0, i)
+		point := BurndownPoint{Date: day.Format("2006-01-02")}
+
+		if days > 1 {
+			point.Ideal = float64(burndown.Total) * float64(days-1-i) / float64(days-1)
+		}
+
+		if !day.After(today) {
+			dayEnd := day.AddDate(0, 0, 1)
+			remaining := 0
+			for _, task := range milestoneTasks {
+				if task.CreatedAt.Before(dayEnd) && (task.CompletedAt == nil || !task.CompletedAt.Before(dayEnd)) {
+					remaining++
+				}
+			}
+			point.Remaining = &remaining
+		}
+
+		burndown.Points = append(burndown.Points, point)
+	}
+
+	return burndown
+}
+
+// parseTimestamp parses the timestamp formats stored by SQLite's datetime(),
+// RFC 3339, and plain dates, always returning UTC.
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parsing timestamp %q", value)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// startOfWeek returns the Monday starting t's week.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// maxStatusNoteLength bounds the note recorded with a status change.
+const maxStatusNoteLength = 500
+
+// historyTimeLayout is the format SQLite's datetime('now') writes
+// changed_at in, in UTC.
+const historyTimeLayout = "2006-01-02 15:04:05"
+
+// statusTransitions lists, for each status, the ones a project may move to
+// without forcing it: forward through the lifecycle, back one step, or out
+// to archived or absorbed, from where it can be revived. Anything else, such
+// as going from concept straight to maintenance, needs the override flag.
+var statusTransitions = map[string][]string{
+	"concept":     {"design", "development", "archived", "absorbed"},
+	"design":      {"concept", "development", "archived", "absorbed"},
+	"development": {"design", "active", "archived", "absorbed"},
+	"active":      {"development", "maintenance", "archived", "absorbed"},
+	"maintenance": {"active", "development", "archived", "absorbed"},
+	"archived":    {"concept", "development", "active", "maintenance"},
+	"absorbed":    {"development", "active", "maintenance", "archived"},
+}
+
+// StatusChange is one entry of a project's status history. FromStatus is nil
+// for the status the project was created with. DurationSeconds is how long
+// the project held ToStatus: until the next change, or until now for the
+// current status.
+type StatusChange struct {
+	ID              int64   `json:"id"`
+	FromStatus      *string `json:"from_status"`
+	ToStatus        string  `json:"to_status"`
+	Note            string  `json:"note"`
+	Forced          bool    `json:"forced"`
+	ChangedAt       string  `json:"changed_at"`
+	DurationSeconds int64   `json:"duration_seconds"`
+}
+
+// StatusHistory is a project's status history, oldest change first, with the
+// total time spent in each status it has been in.
+type StatusHistory struct {
+	Status       string           `json:"status"`
+	Transitions  []StatusChange   `json:"transitions"`
+	TimeInStatus map[string]int64 `json:"time_in_status"`
+}
+
+// checkTransition reports why a project cannot move from one status to
+// another without the override flag, or "" when it can.
+func checkTransition(from string, to string) string {
+	allowed := statusTransitions[from]
+	for _, status := range allowed {
+		if status == to {
+			return ""
+		}
+	}
+	return fmt.Sprintf("cannot move a project from %s to %s; allowed: %s. Set force to override", from, to, strings.Join(allowed, ", "))
+}
+
+// execer is a *sql.DB or a *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordStatusChange adds a status change to the project's history. from is
+// "" for the status a project was created with.
+func recordStatusChange(exec execer, projectID int64, from string, to string, note string, forced bool) error {
+	var fromStatus interface{}
+	if from != "" {
+		fromStatus = from
+	}
+	if _, err := exec.Exec(
+		"INSERT INTO status_history (project_id, from_status, to_status, note, forced) VALUES (?, ?, ?, ?, ?)",
+		projectID, fromStatus, to, note, forced,
+	); err != nil {
+		return fmt.Errorf("recording status change: %w", err)
+	}
+	return nil
+}
+
+// getStatusHistory handles GET /projects/{slug}/history.
+func (p *ProjectHubPlugin) getStatusHistory(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var projectID int64
+	var status string
+	err := p.db.QueryRow("SELECT id, status FROM projects WHERE slug = ?", req.PathParams["slug"]).Scan(&projectID, &status)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying project: %w", err)
+	}
+
+	history, err := p.statusHistory(projectID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	history.Status = status
+	return sdk.Success(200, history)
+}
+
+// statusHistory reads a project's status history, measuring the current
+// status up to now.
+func (p *ProjectHubPlugin) statusHistory(projectID int64, now time.Time) (*StatusHistory, error) {
+	rows, err := p.db.Query(
+		`SELECT id, from_status, to_status, note, forced, changed_at
+		 FROM status_history WHERE project_id = ? ORDER BY changed_at, id`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := &StatusHistory{Transitions: make([]StatusChange, 0), TimeInStatus: make(map[string]int64)}
+	for rows.Next() {
+		var change StatusChange
+		var from sql.NullString
+		if err := rows.Scan(&change.ID, &from, &change.ToStatus, &change.Note, &change.Forced, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scanning status change: %w", err)
+		}
+		if from.Valid {
+			change.FromStatus = &from.String
+		}
+		history.Transitions = append(history.Transitions, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating status history: %w", err)
+	}
+
+	for i := range history.Transitions {
+		change := &history.Transitions[i]
+		start, err := time.Parse(historyTimeLayout, change.ChangedAt)
+		if err != nil {
+			continue
+		}
+		end := now.UTC()
+		if i+1 < len(history.Transitions) {
+			if next, err := time.Parse(historyTimeLayout, history.Transitions[i+1].ChangedAt); err == nil {
+				end = next
+			}
+		}
+		if end.After(start) {
+			change.DurationSeconds = int64(end.Sub(start) / time.Second)
+		}
+		history.TimeInStatus[change.ToStatus] += change.DurationSeconds
+	}
+	return history, nil
+}
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// timeSummarySlot is the widget slot summarizing this week's tracked time
+// across all projects.
+const timeSummarySlot = "time-summary-widget"
+
+// timestampLayout is how time entries store their start and end, matching
+// SQLite's datetime().
+const timestampLayout = "2006-01-02 15:04:05"
+
+const (
+	// maxEntryDuration caps a single time entry recorded by hand.
+	maxEntryDuration  = 24 * time.Hour
+	maxTimeNoteLength = 500
+	// defaultTimeWeeks and defaultTimeMonths are how many periods
+	// GET /projects/{slug}/time returns for each grouping.
+	defaultTimeWeeks  = 8
+	defaultTimeMonths = 6
+	// maxTimePeriods caps the ?periods= query parameter.
+	maxTimePeriods = 52
+	// recentTimeEntries is how many of the latest entries the summary lists.
+	recentTimeEntries = 20
+)
+
+// TimeEntry is a span of time spent on a project. A running timer has no
+// EndedAt, and its DurationSeconds is the time elapsed so far.
+type TimeEntry struct {
+	ID              int64   `json:"id"`
+	ProjectID       int64   `json:"project_id"`
+	StartedAt       string  `json:"started_at"`
+	EndedAt         *string `json:"ended_at"`
+	DurationSeconds int64   `json:"duration_seconds"`
+	Note            *string `json:"note"`
+	Running         bool    `json:"running"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// TimePeriod is the time tracked in one week, starting on Monday, or one
+// calendar month. Entries count in the period they started in.
+type TimePeriod struct {
+	Start   string `json:"start"`
+	Seconds int64  `json:"seconds"`
+	Entries int    `json:"entries"`
+}
+
+// TimeSummary aggregates a project's tracked time by week or month.
+type TimeSummary struct {
+	Project      string       `json:"project"`
+	Group        string       `json:"group"`
+	TotalSeconds int64        `json:"total_seconds"`
+	Periods      []TimePeriod `json:"periods"`
+	Running      *TimeEntry   `json:"running"`
+	Recent       []TimeEntry  `json:"recent"`
+}
+
+// ProjectTime is one project's tracked time, as shown by the weekly summary widget.
+type ProjectTime struct {
+	Project     string `json:"project"`
+	ProjectName string `json:"project_name"`
+	Color       string `json:"color"`
+	Seconds     int64  `json:"seconds"`
+}
+
+// timeInput is the body of POST /projects/{slug}/time. Action "start" and
+// "stop" drive the project's timer; without an action it records a finished
+// entry from StartedAt and either EndedAt or DurationMinutes.
+type timeInput struct {
+	Action          string  `json:"action"`
+	StartedAt       string  `json:"started_at"`
+	EndedAt         string  `json:"ended_at"`
+	DurationMinutes *int    `json:"duration_minutes"`
+	Note            *string `json:"note"`
+}
+
+// --- Time handlers ---
+
+func (p *ProjectHubPlugin) recordTime(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/time
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+
+	projectID, err := p.projectIDBySlug(pathParts[1])
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var input timeInput
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if input.Note != nil && len(*input.Note) > maxTimeNoteLength {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("note must be at most %d characters", maxTimeNoteLength))
+	}
+
+	now := time.Now().UTC()
+	switch input.Action {
+	case "start":
+		return p.startTimer(projectID, input.Note, now)
+	case "stop":
+		return p.stopTimer(projectID, now)
+	case "":
+	default:
+		return sdk.Error(400, "VALIDATION_ERROR", "action must be start or stop")
+	}
+
+	if input.EndedAt != "" && input.DurationMinutes != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "use either ended_at or duration_minutes, not both")
+	}
+
+	var startedAt, endedAt time.Time
+	switch {
+	case input.EndedAt != "":
+		if input.StartedAt == "" {
+			return sdk.Error(400, "VALIDATION_ERROR", "started_at is required with ended_at")
+		}
+		if startedAt, err = parseTimestamp(input.StartedAt); err != nil {
+			return sdk.Error(400, "VALIDATION_ERROR", "started_at must be an RFC 3339 timestamp")
+		}
+		if endedAt, err = parseTimestamp(input.EndedAt); err != nil {
+			return sdk.Error(400, "VALIDATION_ERROR", "ended_at must be an RFC 3339 timestamp")
+		}
+	case input.DurationMinutes != nil:
+		duration := time.Duration(*input.DurationMinutes) * time.Minute
+		if *input.DurationMinutes < 1 || duration > maxEntryDuration {
+			return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("duration_minutes must be between 1 and %d", int(maxEntryDuration.Minutes())))
+		}
+		// Without a start, the entry is taken to have just finished.
+		startedAt = now.Add(-duration)
+		if input.StartedAt != "" {
+			if startedAt, err = parseTimestamp(input.StartedAt); err != nil {
+				return sdk.Error(400, "VALIDATION_ERROR", "started_at must be an RFC 3339 timestamp")
+			}
+		}
+		endedAt = startedAt.Add(duration)
+	default:
+		return sdk.Error(400, "VALIDATION_ERROR", "action, ended_at or duration_minutes is required")
+	}
+
+	if !endedAt.After(startedAt) {
+		return sdk.Error(400, "VALIDATION_ERROR", "ended_at must be after started_at")
+	}
+	if endedAt.Sub(startedAt) > maxEntryDuration {
+		return sdk.Error(400, "VALIDATION_ERROR", "a time entry cannot be longer than 24 hours")
+	}
+
+	result, err := p.db.Exec(
+		`INSERT INTO time_entries (project_id, started_at, ended_at, duration_seconds, note)
+		 VALUES (?, ?, ?, ?, ?)`,
+		projectID, startedAt.Format(timestampLayout), endedAt.Format(timestampLayout),
+		int64(endedAt.Sub(startedAt).Seconds()), input.Note,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting time entry: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	entry, err := p.getTimeEntryByID(id, now)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(201, entry)
+}
+
+// startTimer starts the project's timer; a project has at most one running.
+func (p *ProjectHubPlugin) startTimer(projectID int64, note *string, now time.Time) (*sdk.APIResponse, error) {
+	result, err := p.db.Exec(
+		"INSERT INTO time_entries (project_id, started_at, note) VALUES (?, ?, ?)",
+		projectID, now.Format(timestampLayout), note,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return sdk.Error(409, "CONFLICT", "a timer is already running for this project")
+		}
+		return nil, fmt.Errorf("starting timer: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	entry, err := p.getTimeEntryByID(id, now)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(201, entry)
+}
+
+// stopTimer stops the project's running timer and records its duration.
+func (p *ProjectHubPlugin) stopTimer(projectID int64, now time.Time) (*sdk.APIResponse, error) {
+	var id int64
+	var startedAt string
+	err := p.db.QueryRow(
+		"SELECT id, started_at FROM time_entries WHERE project_id = ? AND ended_at IS NULL", projectID,
+	).Scan(&id, &startedAt)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "no timer is running for this project")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying running timer: %w", err)
+	}
+
+	if _, err := p.db.Exec(
+		"UPDATE time_entries SET ended_at = ?, duration_seconds = ? WHERE id = ?",
+		now.Format(timestampLayout), elapsedSeconds(startedAt, now), id,
+	); err != nil {
+		return nil, fmt.Errorf("stopping timer: %w", err)
+	}
+
+	entry, err := p.getTimeEntryByID(id, now)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, entry)
+}
+
+func (p *ProjectHubPlugin) getTimeSummary(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/time
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+	slug := pathParts[1]
+
+	group := req.Query["group"]
+	if group == "" {
+		group = "week"
+	}
+	periods := defaultTimeWeeks
+	switch group {
+	case "week":
+	case "month":
+		periods = defaultTimeMonths
+	default:
+		return sdk.Error(400, "VALIDATION_ERROR", "group must be week or month")
+	}
+	if raw := req.Query["periods"]; raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxTimePeriods {
+			return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("periods must be a number between 1 and %d", maxTimePeriods))
+		}
+		periods = parsed
+	}
+
+	projectID, err := p.projectIDBySlug(slug)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	starts := periodStarts(now, group, periods)
+	entries, err := p.listTimeEntries(projectID, starts[0], now)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := computeTimeSummary(entries, starts)
+	summary.Project = slug
+	summary.Group = group
+
+	recent, err := p.listRecentTimeEntries(projectID, now)
+	if err != nil {
+		return nil, err
+	}
+	summary.Recent = recent
+	for i := range recent {
+		if recent[i].Running {
+			summary.Running = &recent[i]
+			break
+		}
+	}
+	return sdk.Success(200, summary)
+}
+
+func (p *ProjectHubPlugin) deleteTimeEntry(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	idStr := strings.TrimPrefix(req.Path, "/time/")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid time entry id")
+	}
+
+	result, err := p.db.Exec("DELETE FROM time_entries WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting time entry: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "time entry not found")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// --- Roll-ups ---
+
+// periodStarts returns the starts of the last count weeks (from Monday) or
+// months up to and including the one containing now, oldest first.
+func periodStarts(now time.Time, group string, count int) []time.Time {
+	starts := make([]time.Time, count)
+	for i := 0; i < count; i++ {
+		offset := count - 1 - i
+		if group == "month" {
+			starts[i] = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -offset, 0)
+		} else {
+			starts[i] = startOfWeek(now).AddDate(0, 0, -7*offset)
+		}
+	}
+	return starts
+}
+
+// computeTimeSummary buckets entries into the periods beginning at starts.
+// Entries before the first period are ignored.
+func computeTimeSummary(entries []TimeEntry, starts []time.Time) *TimeSummary {
+	summary := &TimeSummary{Periods: make([]TimePeriod, len(starts))}
+	for i, start := range starts {
+		summary.Periods[i].Start = start.Format("2006-01-02")
+	}
+
+	for _, entry := range entries {
+		startedAt, err := parseTimestamp(entry.StartedAt)
+		if err != nil {
+			continue
+		}
+		for i := len(starts) - 1; i >= 0; i-- {
+			if !startedAt.Before(starts[i]) {
+				summary.Periods[i].Seconds += entry.DurationSeconds
+				summary.Periods[i].Entries++
+				summary.TotalSeconds += entry.DurationSeconds
+				break
+			}
+		}
+	}
+	return summary
+}
+
+// timeSummaryWidgetData returns the time tracked from Monday to Sunday of the
+// week containing now, per project, most time first.
+func (p *ProjectHubPlugin) timeSummaryWidgetData(now time.Time) ([]byte, error) {
+	weekStart := startOfWeek(now)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	rows, err := p.db.Query(
+		`SELECT pr.slug, pr.name, COALESCE(pr.color, ''), te.started_at, te.duration_seconds
+		 FROM time_entries te JOIN projects pr ON pr.id = te.project_id
+		 WHERE te.started_at >= ? AND te.started_at < ?
+		 ORDER BY pr.sort_order, pr.id`,
+		weekStart.Format(timestampLayout), weekEnd.Format(timestampLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying weekly time: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]ProjectTime, 0)
+	index := make(map[string]int)
+	var total int64
+	for rows.Next() {
+		var project ProjectTime
+		var startedAt string
+		var duration sql.NullInt64
+		if err := rows.Scan(&project.Project, &project.ProjectName, &project.Color, &startedAt, &duration); err != nil {
+			return nil, fmt.Errorf("scanning weekly time: %w", err)
+		}
+
+		seconds := duration.Int64
+		if !duration.Valid {
+			seconds = elapsedSeconds(startedAt, now)
+		}
+		position, ok := index[project.Project]
+		if !ok {
+			position = len(projects)
+			index[project.Project] = position
+			projects = append(projects, project)
+		}
+		projects[position].Seconds += seconds
+		total += seconds
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating weekly time: %w", err)
+	}
+
+	// Most time first; the query order keeps ties in project order.
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].Seconds > projects[j].Seconds })
+
+	return json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"week_start":    weekStart.Format("2006-01-02"),
+			"week_end":      weekStart.AddDate(0, 0, 6).Format("2006-01-02"),
+			"total_seconds": total,
+			"projects":      projects,
+		},
+	})
+}
+
+// --- Helpers ---
+
+// timeEntryColumns lists the time_entries columns in the order scanTimeEntry reads them.
+const timeEntryColumns = "id, project_id, started_at, ended_at, duration_seconds, note, created_at"
+
+// listTimeEntries returns the project's entries started between from and to.
+func (p *ProjectHubPlugin) listTimeEntries(projectID int64, from time.Time, to time.Time) ([]TimeEntry, error) {
+	rows, err := p.db.Query(
+		`SELECT `+timeEntryColumns+` FROM time_entries
+		 WHERE project_id = ? AND started_at >= ? AND started_at <= ?
+		 ORDER BY started_at`,
+		projectID, from.Format(timestampLayout), to.Format(timestampLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying time entries: %w", err)
+	}
+	return scanTimeEntries(rows, to)
+}
+
+// listRecentTimeEntries returns the project's latest entries, newest first.
+func (p *ProjectHubPlugin) listRecentTimeEntries(projectID int64, now time.Time) ([]TimeEntry, error) {
+	rows, err := p.db.Query(
+		`SELECT `+timeEntryColumns+` FROM time_entries
+		 WHERE project_id = ?
+		 ORDER BY started_at DESC, id DESC LIMIT ?`,
+		projectID, recentTimeEntries,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying recent time entries: %w", err)
+	}
+	return scanTimeEntries(rows, now)
+}
+
+func (p *ProjectHubPlugin) getTimeEntryByID(id int64, now time.Time) (*TimeEntry, error) {
+	row := p.db.QueryRow(`SELECT `+timeEntryColumns+` FROM time_entries WHERE id = ?`, id)
+	return scanTimeEntry(row, now)
+}
+
+func scanTimeEntries(rows *sql.Rows, now time.Time) ([]TimeEntry, error) {
+	defer rows.Close()
+
+	entries := make([]TimeEntry, 0)
+	for rows.Next() {
+		entry, err := scanTimeEntry(rows, now)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating time entries: %w", err)
+	}
+	return entries, nil
+}
+
+// scanTimeEntry reads a time entry; a running one gets the seconds elapsed by now.
+func scanTimeEntry(scanner rowScanner, now time.Time) (*TimeEntry, error) {
+	var entry TimeEntry
+	var duration sql.NullInt64
+	if err := scanner.Scan(
+		&entry.ID, &entry.ProjectID, &entry.StartedAt, &entry.EndedAt, &duration, &entry.Note, &entry.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scanning time entry: %w", err)
+	}
+
+	entry.Running = entry.EndedAt == nil
+	entry.DurationSeconds = duration.Int64
+	if entry.Running {
+		entry.DurationSeconds = elapsedSeconds(entry.StartedAt, now)
+	}
+	return &entry, nil
+}
+
+// elapsedSeconds returns the whole seconds from startedAt to now, or 0 when
+// startedAt cannot be read or lies in the future.
+func elapsedSeconds(startedAt string, now time.Time) int64 {
+	started, err := parseTimestamp(startedAt)
+	if err != nil || now.Before(started) {
+		return 0
+	}
+	return int64(now.Sub(started).Seconds())
+}
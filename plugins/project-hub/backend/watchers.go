@@ -0,0 +1,254 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// Notification kinds, each gated by its own rule on a watch.
+const (
+	notificationStatus    = "status"
+	notificationChangelog = "changelog"
+)
+
+// Watch holds the notification rules for a watched project.
+type Watch struct {
+	Project         string `json:"project"`
+	NotifyStatus    bool   `json:"notify_status"`
+	NotifyChangelog bool   `json:"notify_changelog"`
+}
+
+// ChangelogEntry is a release note or milestone recorded for a project.
+type ChangelogEntry struct {
+	ID        int64   `json:"id"`
+	ProjectID int64   `json:"project_id"`
+	Version   *string `json:"version"`
+	Title     string  `json:"title"`
+	Body      *string `json:"body"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// --- Watch handlers ---
+
+// watchProject marks a project as watched, or updates its notification rules.
+// Both rules default to on.
+func (p *ProjectHubPlugin) watchProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/watch
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+	slug := pathParts[1]
+
+	var projectID int64
+	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying project: %w", err)
+	}
+
+	var input struct {
+		NotifyStatus    *bool `json:"notify_status"`
+		NotifyChangelog *bool `json:"notify_changelog"`
+	}
+
+	if len(req.Body) > 0 {
+		if err := json.Unmarshal(req.Body, &input); err != nil {
+			return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+		}
+	}
+
+	watch := Watch{Project: slug, NotifyStatus: true, NotifyChangelog: true}
+	if input.NotifyStatus != nil {
+		watch.NotifyStatus = *input.NotifyStatus
+	}
+	if input.NotifyChangelog != nil {
+		watch.NotifyChangelog = *input.NotifyChangelog
+	}
+
+	if _, err := p.db.Exec(
+		`INSERT INTO project_watches (project_id, notify_status, notify_changelog) VALUES (?, ?, ?)
+		 ON CONFLICT(project_id) DO UPDATE SET
+		     notify_status = excluded.notify_status,
+		     notify_changelog = excluded.notify_changelog,
+		     updated_at = datetime('now')`,
+		projectID, boolToInt(watch.NotifyStatus), boolToInt(watch.NotifyChangelog),
+	); err != nil {
+		return nil, fmt.Errorf("saving watch: %w", err)
+	}
+
+	return sdk.Success(200, watch)
+}
+
+func (p *ProjectHubPlugin) unwatchProject(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/watch
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+	slug := pathParts[1]
+
+	result, err := p.db.Exec(
+		"DELETE FROM project_watches WHERE project_id = (SELECT id FROM projects WHERE slug = ?)", slug,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("deleting watch: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "project is not watched")
+	}
+
+	return sdk.Success(200, map[string]interface{}{"unwatched": slug})
+}
+
+// --- Changelog handlers ---
+
+func (p *ProjectHubPlugin) listChangelog(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/changelog
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+	slug := pathParts[1]
+
+	var projectID int64
+	err := p.db.QueryRow("SELECT id FROM projects WHERE slug = ?", slug).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying project: %w", err)
+	}
+
+	rows, err := p.db.Query(
+		"SELECT id, project_id, version, title, body, created_at FROM changelog_entries WHERE project_id = ? ORDER BY created_at DESC, id DESC",
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying changelog: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]ChangelogEntry, 0)
+	for rows.Next() {
+		var entry ChangelogEntry
+		if err := rows.Scan(&entry.ID, &entry.ProjectID, &entry.Version, &entry.Title, &entry.Body, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning changelog entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating changelog: %w", err)
+	}
+
+	return sdk.Success(200, entries)
+}
+
+func (p *ProjectHubPlugin) createChangelogEntry(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Extract slug from /projects/{slug}/changelog
+	pathParts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(pathParts) != 3 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid path")
+	}
+	slug := pathParts[1]
+
+	var projectID int64
+	var projectName string
+	err := p.db.QueryRow("SELECT id, name FROM projects WHERE slug = ?", slug).Scan(&projectID, &projectName)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying project: %w", err)
+	}
+
+	var input struct {
+		Version *string `json:"version"`
+		Title   string  `json:"title"`
+		Body    *string `json:"body"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if strings.TrimSpace(input.Title) == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "title is required")
+	}
+	if len(input.Title) > 200 {
+		return sdk.Error(400, "VALIDATION_ERROR", "title must be 200 characters or less")
+	}
+
+	result, err := p.db.Exec(
+		"INSERT INTO changelog_entries (project_id, version, title, body) VALUES (?, ?, ?, ?)",
+		projectID, input.Version, input.Title, input.Body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("inserting changelog entry: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+
+	title := fmt.Sprintf("%s: %s", projectName, input.Title)
+	if input.Version != nil && *input.Version != "" {
+		title = fmt.Sprintf("%s %s: %s", projectName, *input.Version, input.Title)
+	}
+	body := ""
+	if input.Body != nil {
+		body = *input.Body
+	}
+	if err := p.notifyWatchers(projectID, notificationChangelog, title, body); err != nil {
+		return nil, err
+	}
+
+	return sdk.Success(201, map[string]interface{}{"id": id})
+}
+
+// notifyWatchers sends a notification of the given kind to the host's
+// notification center if the project is watched with the matching rule
+// enabled. Unwatched projects stay quiet.
+func (p *ProjectHubPlugin) notifyWatchers(projectID int64, kind string, title string, body string) error {
+	column := "notify_status"
+	if kind == notificationChangelog {
+		column = "notify_changelog"
+	}
+
+	var enabled int
+	err := p.db.QueryRow(
+		fmt.Sprintf("SELECT %s FROM project_watches WHERE project_id = ?", column), projectID,
+	).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("querying watch: %w", err)
+	}
+	if enabled == 0 {
+		return nil
+	}
+
+	notify := p.notify
+	if notify == nil {
+		notify = sdk.SendNotification
+	}
+	// The change has already been saved, so a notification the host cannot
+	// deliver does not fail the request that caused it.
+	_ = notify(title, body, false)
+	return nil
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// maxBulkNotes caps how many notes a single POST /notes/bulk may carry or
+// act on.
+const maxBulkNotes = 1000
+
+// Bulk item statuses.
+const (
+	bulkCreated = "created"
+	bulkUpdated = "updated"
+	bulkDeleted = "deleted"
+	bulkFailed  = "error"
+)
+
+// Bulk actions on existing notes.
+const (
+	bulkActionDelete = "delete"
+	bulkActionPin    = "pin"
+	bulkActionUnpin  = "unpin"
+	bulkActionMove   = "move"
+	bulkActionTag    = "tag"
+)
+
+// BulkNoteInput is one note in a bulk import. Timestamps are optional and
+// accept RFC 3339 or "YYYY-MM-DD HH:MM:SS" (UTC).
+type BulkNoteInput struct {
+	Title     string   `json:"title"`
+	Content   string   `json:"content"`
+	Tags      []string `json:"tags"`
+	Pinned    bool     `json:"pinned"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// BulkNoteResult reports what happened to the note at Index in the request,
+// the notes of an import or the IDs of an action.
+type BulkNoteResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	ID     int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// bulkNotes handles POST /notes/bulk: a request with an "action" acts on
+// existing notes, and one without imports notes.
+func (p *QuickNotesPlugin) bulkNotes(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input struct {
+		Action string `json:"action"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if input.Action != "" {
+		return p.bulkNoteAction(req)
+	}
+	return p.bulkImportNotes(req)
+}
+
+// bulkNoteAction deletes, pins, unpins, moves to a notebook or tags every
+// note in "ids", in one transaction. A note that does not exist is reported
+// in the result list and does not stop the rest. Deleted notes can be
+// restored together with the host's undo.
+func (p *QuickNotesPlugin) bulkNoteAction(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// NotebookID is the notebook to move the notes to, or null to take them
+	// out of theirs; TagIDs are the tags to add to them.
+	var input struct {
+		Action     string          `json:"action"`
+		IDs        []int64         `json:"ids"`
+		NotebookID json.RawMessage `json:"notebook_id"`
+		TagIDs     []int64         `json:"tag_ids"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if len(input.IDs) == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "ids must contain at least one note ID")
+	}
+	if len(input.IDs) > maxBulkNotes {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("at most %d notes can be changed at once", maxBulkNotes))
+	}
+
+	var notebookID *int64
+	switch input.Action {
+	case bulkActionDelete, bulkActionPin, bulkActionUnpin:
+	case bulkActionMove:
+		if input.NotebookID == nil {
+			return sdk.Error(400, "VALIDATION_ERROR", "notebook_id is required to move notes")
+		}
+		parsed, resp, err := p.parseNotebookID(input.NotebookID)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		notebookID = parsed
+	case bulkActionTag:
+		if len(input.TagIDs) == 0 {
+			return sdk.Error(400, "VALIDATION_ERROR", "tag_ids must contain at least one tag ID")
+		}
+		if resp, err := p.checkTagIDs(input.TagIDs); resp != nil || err != nil {
+			return resp, err
+		}
+	default:
+		return sdk.Error(400, "VALIDATION_ERROR", "action must be one of delete, pin, unpin, move, tag")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	results := make([]BulkNoteResult, 0, len(input.IDs))
+	deleted := make([]sdk.DeletedRows, 0)
+	succeeded, failed := 0, 0
+	for index, id := range input.IDs {
+		result := BulkNoteResult{Index: index, ID: id, Status: bulkUpdated}
+		if input.Action == bulkActionDelete {
+			result.Status = bulkDeleted
+		}
+
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM notes WHERE id = ?)", id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("checking note: %w", err)
+		}
+		if !exists {
+			result.Status, result.Error = bulkFailed, "note not found"
+			failed++
+			results = append(results, result)
+			continue
+		}
+
+		switch input.Action {
+		case bulkActionDelete:
+			snapshot, err := snapshotNote(tx, strconv.FormatInt(id, 10))
+			if err != nil {
+				return nil, err
+			}
+			if _, err := tx.Exec("DELETE FROM notes WHERE id = ?", id); err != nil {
+				return nil, fmt.Errorf("deleting note: %w", err)
+			}
+			deleted = append(deleted, snapshot...)
+		case bulkActionPin, bulkActionUnpin:
+			if _, err := tx.Exec(
+				"UPDATE notes SET pinned = ?, updated_at = ? WHERE id = ?",
+				input.Action == bulkActionPin, now, id,
+			); err != nil {
+				return nil, fmt.Errorf("pinning note: %w", err)
+			}
+		case bulkActionMove:
+			if err := setNoteNotebook(tx, id, notebookID); err != nil {
+				return nil, err
+			}
+		case bulkActionTag:
+			for _, tagID := range input.TagIDs {
+				if _, err := tx.Exec("INSERT OR IGNORE INTO note_tags (note_id, tag_id) VALUES (?, ?)", id, tagID); err != nil {
+					return nil, fmt.Errorf("tagging note: %w", err)
+				}
+			}
+		}
+		succeeded++
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	if succeeded > 0 {
+		notifyNotesChanged()
+	}
+
+	response, err := sdk.Success(200, map[string]interface{}{
+		"action":    input.Action,
+		"succeeded": succeeded,
+		"failed":    failed,
+		"results":   results,
+	})
+	if err != nil || len(deleted) == 0 {
+		return response, err
+	}
+	return sdk.WithDeleted(response, deletedNoteKind, deleted)
+}
+
+// bulkImportNotes creates many notes in one request. With "upsert": true, a
+// note whose title matches an existing one updates it instead, adding its
+// tags. Invalid items are reported in the result list and do not stop the rest.
+func (p *QuickNotesPlugin) bulkImportNotes(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input struct {
+		Notes  []BulkNoteInput `json:"notes"`
+		Upsert bool            `json:"upsert"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if len(input.Notes) == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "notes must contain at least one note")
+	}
+	if len(input.Notes) > maxBulkNotes {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("at most %d notes can be imported at once", maxBulkNotes))
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	results := make([]BulkNoteResult, 0, len(input.Notes))
+	created, updated, failed := 0, 0, 0
+	for index, note := range input.Notes {
+		result, err := importNote(tx, note, input.Upsert, now)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Index = index
+		switch result.Status {
+		case bulkCreated:
+			created++
+		case bulkUpdated:
+			updated++
+		default:
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	if created+updated > 0 {
+		notifyNotesChanged()
+	}
+
+	return sdk.Success(200, map[string]interface{}{
+		"created": created,
+		"updated": updated,
+		"failed":  failed,
+		"results": results,
+	})
+}
+
+// importNote writes a single bulk item. Validation problems are returned in
+// the result; only database failures are returned as errors.
+func importNote(tx *sql.Tx, note BulkNoteInput, upsert bool, now string) (BulkNoteResult, error) {
+	if strings.TrimSpace(note.Title) == "" {
+		return BulkNoteResult{Status: bulkFailed, Error: "title is required"}, nil
+	}
+
+	createdAt, err := normalizeImportTimestamp(note.CreatedAt)
+	if err != nil {
+		return BulkNoteResult{Status: bulkFailed, Error: "created_at: " + err.Error()}, nil
+	}
+	updatedAt, err := normalizeImportTimestamp(note.UpdatedAt)
+	if err != nil {
+		return BulkNoteResult{Status: bulkFailed, Error: "updated_at: " + err.Error()}, nil
+	}
+
+	tags := make([]string, 0, len(note.Tags))
+	for _, tag := range note.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	result := BulkNoteResult{Status: bulkCreated}
+	if upsert {
+		err := tx.QueryRow(
+			"SELECT id FROM notes WHERE title = ? ORDER BY updated_at DESC, id DESC LIMIT 1", note.Title,
+		).Scan(&result.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return BulkNoteResult{}, fmt.Errorf("looking up note by title: %w", err)
+		}
+		if err == nil {
+			result.Status = bulkUpdated
+		}
+	}
+
+	if result.Status == bulkUpdated {
+		// An updated note keeps its original creation time unless one is given.
+		if updatedAt == "" {
+			updatedAt = now
+		}
+		if _, err := tx.Exec(
+			"UPDATE notes SET content = ?, pinned = ?, created_at = COALESCE(?, created_at), updated_at = ? WHERE id = ?",
+			note.Content, note.Pinned, nullIfEmpty(createdAt), updatedAt, result.ID,
+		); err != nil {
+			return BulkNoteResult{}, fmt.Errorf("updating note: %w", err)
+		}
+	} else {
+		// A note that was never edited was last updated when it was created.
+		if createdAt == "" {
+			createdAt = now
+		}
+		if updatedAt == "" {
+			updatedAt = createdAt
+		}
+		inserted, err := tx.Exec(
+			"INSERT INTO notes (title, content, pinned, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+			note.Title, note.Content, note.Pinned, createdAt, updatedAt,
+		)
+		if err != nil {
+			return BulkNoteResult{}, fmt.Errorf("inserting note: %w", err)
+		}
+		result.ID, _ = inserted.LastInsertId()
+	}
+
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+			return BulkNoteResult{}, fmt.Errorf("creating tag: %w", err)
+		}
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO note_tags (note_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			result.ID, tag,
+		); err != nil {
+			return BulkNoteResult{}, fmt.Errorf("tagging note: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// normalizeImportTimestamp converts an imported timestamp to the format
+// SQLite's datetime() stores. Times without a zone, as Obsidian writes them,
+// are taken as UTC. An empty value stays empty.
+func normalizeImportTimestamp(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC().Format("2006-01-02 15:04:05"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid timestamp %q", value)
+}
+
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+const (
+	defaultChangesLimit = 500
+	maxChangesLimit     = 1000
+)
+
+// NoteChange is the latest change to a note after the requested cursor.
+// Version counts every change the note has had, so a client can tell whether
+// its copy is current.
+type NoteChange struct {
+	ID        int64  `json:"id"`
+	Change    string `json:"change"`
+	Version   int    `json:"version"`
+	ChangedAt string `json:"changed_at"`
+}
+
+// listNoteChanges returns the notes created, updated or deleted after the
+// ?since= cursor, one entry per note, oldest change first. Without since, it
+// lists every note ever written, for a client's first sync. A note created
+// after the cursor is reported as created even when it was edited since, so
+// the client knows it has no copy yet. The page's cursor is passed as since
+// to read the next page, or to poll for later changes.
+func (p *QuickNotesPlugin) listNoteChanges(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var since int64
+	if value := req.Query["since"]; value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			return sdk.Error(400, "VALIDATION_ERROR", "since must be a cursor returned by this endpoint")
+		}
+		since = parsed
+	}
+
+	limit := defaultChangesLimit
+	if value := req.Query["limit"]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxChangesLimit {
+			return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("limit must be between 1 and %d", maxChangesLimit))
+		}
+		limit = parsed
+	}
+
+	// One row more than the limit tells whether another page follows.
+	rows, err := p.db.Query(
+		`SELECT latest.note_id, latest.seq, c.kind, c.changed_at,
+		        (SELECT COUNT(*) FROM note_changes v WHERE v.note_id = latest.note_id),
+		        EXISTS (SELECT 1 FROM note_changes n WHERE n.note_id = latest.note_id AND n.seq > ? AND n.kind = 'created')
+		 FROM (SELECT note_id, MAX(seq) AS seq FROM note_changes GROUP BY note_id HAVING MAX(seq) > ?) latest
+		 JOIN note_changes c ON c.seq = latest.seq
+		 ORDER BY latest.seq
+		 LIMIT ?`,
+		since, since, limit+1,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying note changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]NoteChange, 0)
+	cursor := strconv.FormatInt(since, 10)
+	hasMore := false
+	for rows.Next() {
+		var change NoteChange
+		var seq int64
+		var createdSince bool
+		if err := rows.Scan(&change.ID, &seq, &change.Change, &change.ChangedAt, &change.Version, &createdSince); err != nil {
+			return nil, fmt.Errorf("scanning note change: %w", err)
+		}
+		if len(changes) == limit {
+			hasMore = true
+			break
+		}
+		if createdSince && change.Change == "updated" {
+			change.Change = "created"
+		}
+		changes = append(changes, change)
+		cursor = strconv.FormatInt(seq, 10)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating note changes: %w", err)
+	}
+
+	return sdk.Paginated(changes, cursor, hasMore)
+}
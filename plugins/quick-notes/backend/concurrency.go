@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// noteTimeLayout is the UTC format notes store created_at and updated_at in.
+const noteTimeLayout = "2006-01-02 15:04:05"
+
+// notePrecondition is the version of a note a client last saw, so that an
+// update made from a stale copy is refused instead of overwriting changes
+// made elsewhere.
+type notePrecondition struct {
+	// updatedAt is the note's updated_at when the client read it.
+	updatedAt string
+	// exact requires updated_at to be unchanged. Otherwise, as for
+	// If-Unmodified-Since, it only must not be later.
+	exact bool
+}
+
+// parseNotePrecondition reads the precondition of an update: the
+// expected_updated_at field, in the format notes are returned in or RFC 3339,
+// or else the If-Unmodified-Since header. It returns nil when the request
+// has neither, and the update then overwrites whatever is stored.
+func parseNotePrecondition(req *sdk.APIRequest, expected *string) (*notePrecondition, *sdk.APIResponse, error) {
+	if expected != nil {
+		at, err := time.Parse(noteTimeLayout, *expected)
+		if err != nil {
+			at, err = time.Parse(time.RFC3339, *expected)
+		}
+		if err != nil {
+			resp, err := sdk.Error(400, "VALIDATION_ERROR", "expected_updated_at must be the updated_at of the note")
+			return nil, resp, err
+		}
+		return &notePrecondition{updatedAt: at.UTC().Format(noteTimeLayout), exact: true}, nil, nil
+	}
+
+	if header := req.Headers["If-Unmodified-Since"]; header != "" {
+		at, err := http.ParseTime(header)
+		if err != nil {
+			resp, err := sdk.Error(400, "VALIDATION_ERROR", "If-Unmodified-Since must be an HTTP date")
+			return nil, resp, err
+		}
+		return &notePrecondition{updatedAt: at.UTC().Format(noteTimeLayout)}, nil, nil
+	}
+	return nil, nil, nil
+}
+
+// holds reports whether a note last updated at updatedAt may be written.
+// Timestamps in noteTimeLayout sort as strings.
+func (c *notePrecondition) holds(updatedAt string) bool {
+	if c.exact {
+		return updatedAt == c.updatedAt
+	}
+	return updatedAt <= c.updatedAt
+}
+
+// nextUpdatedAt is the updated_at of a note written now that was last
+// updated at previous. It is a second past previous when that is not
+// earlier than now, so every update moves updated_at forward and a client
+// holding the old value always sees its copy is stale.
+func nextUpdatedAt(now time.Time, previous string) string {
+	next := now.UTC().Format(noteTimeLayout)
+	if next > previous {
+		return next
+	}
+	if parsed, err := time.Parse(noteTimeLayout, previous); err == nil {
+		return parsed.Add(time.Second).Format(noteTimeLayout)
+	}
+	return next
+}
+
+// noteConflict answers an update refused by its precondition with 409
+// CONFLICT and the stored copy of the note under "current", for the client
+// to merge with its own changes or to reload.
+func (p *QuickNotesPlugin) noteConflict(noteID int64) (*sdk.APIResponse, error) {
+	notes, err := p.queryNotes(noteFilter{noteID: noteID})
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return sdk.Error(404, "NOT_FOUND", "note not found")
+	}
+
+	body, err := json.Marshal(struct {
+		Error   sdk.ErrorBody `json:"error"`
+		Current Note          `json:"current"`
+	}{
+		Error:   sdk.ErrorBody{Code: "CONFLICT", Message: "the note was changed since it was read"},
+		Current: notes[0],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling conflict: %w", err)
+	}
+	return &sdk.APIResponse{StatusCode: 409, Body: body, ContentType: "application/json"}, nil
+}
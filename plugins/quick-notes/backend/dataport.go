@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// dataTables are the tables ExportData and ImportData move between
+// instances, in the order ImportData fills them. The change log is left
+// out: importing records its own changes, so sync clients pull the new notes.
+var dataTables = []string{"notes", "tags", "note_tags", "note_reminders", "notebooks", "note_notebooks"}
+
+// ExportData returns every note with its tags, reminder and notebook for
+// GET /api/plugins/quick-notes/export.
+func (p *QuickNotesPlugin) ExportData() ([]byte, error) {
+	return sdk.ExportTables(p.db, dataTables...)
+}
+
+// ImportData replaces the plugin's notes with an export from this or an
+// older version, all at once or not at all.
+func (p *QuickNotesPlugin) ImportData(fromVersion string, data []byte) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := sdk.ImportTables(tx, data, dataTables...); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	notifyNotesChanged()
+	return nil
+}
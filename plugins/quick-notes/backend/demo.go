@@ -0,0 +1,88 @@
+package main
+
+import "fmt"
+
+// demoNote is a note SeedDemo creates, with the names of its tags.
+type demoNote struct {
+	title   string
+	content string
+	pinned  bool
+	tags    []string
+}
+
+var demoNotes = []demoNote{
+	{
+		title:   "Weekly groceries",
+		content: "- Oat milk\n- Eggs\n- Spinach\n- Coffee beans\n- Tomatoes",
+		pinned:  true,
+		tags:    []string{"home"},
+	},
+	{
+		title:   "Book recommendations",
+		content: "The Pragmatic Programmer, Designing Data-Intensive Applications, A Philosophy of Software Design.",
+		tags:    []string{"reading"},
+	},
+	{
+		title:   "Ideas for the home server",
+		content: "Move backups to the NAS, add a media plugin, try a reverse proxy with automatic certificates.",
+		tags:    []string{"ideas", "home"},
+	},
+	{
+		title:   "Meeting notes",
+		content: "Agreed on the release date. Follow up on the onboarding docs before Friday.",
+		tags:    []string{"work"},
+	},
+}
+
+// SeedDemo fills an empty database with a few tagged notes, one of them
+// pinned. A database that already has notes is left untouched.
+func (p *QuickNotesPlugin) SeedDemo() error {
+	var count int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count); err != nil {
+		return fmt.Errorf("counting notes: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	transaction, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer transaction.Rollback()
+
+	for _, note := range demoNotes {
+		pinned := 0
+		if note.pinned {
+			pinned = 1
+		}
+		result, err := transaction.Exec(
+			"INSERT INTO notes (title, content, pinned) VALUES (?, ?, ?)",
+			note.title, note.content, pinned,
+		)
+		if err != nil {
+			return fmt.Errorf("creating demo note: %w", err)
+		}
+		noteID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("reading demo note ID: %w", err)
+		}
+
+		for _, tag := range note.tags {
+			if _, err := transaction.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+				return fmt.Errorf("creating demo tag: %w", err)
+			}
+			if _, err := transaction.Exec(
+				"INSERT INTO note_tags (note_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+				noteID, tag,
+			); err != nil {
+				return fmt.Errorf("tagging demo note: %w", err)
+			}
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// defaultDuplicateThreshold is the minimum similarity score, from 0 to 1, for
+// two notes to be reported as likely duplicates.
+const defaultDuplicateThreshold = 0.7
+
+// DuplicatePair is two notes that look like copies of each other.
+type DuplicatePair struct {
+	Note              Note    `json:"note"`
+	Duplicate         Note    `json:"duplicate"`
+	Score             float64 `json:"score"`
+	TitleSimilarity   float64 `json:"title_similarity"`
+	ContentSimilarity float64 `json:"content_similarity"`
+}
+
+// findDuplicates returns pairs of notes whose titles and content are similar,
+// most similar first. The older note of each pair is reported as "note", so
+// merging "duplicate" into it keeps the original.
+func (p *QuickNotesPlugin) findDuplicates(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	threshold := defaultDuplicateThreshold
+	if raw := req.Query["threshold"]; raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			return sdk.Error(400, "VALIDATION_ERROR", "threshold must be a number greater than 0 and at most 1")
+		}
+		threshold = parsed
+	}
+
+	rows, err := p.db.Query(
+		`SELECT id, title, content, pinned, created_at, updated_at
+		 FROM notes
+		 ORDER BY created_at, id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := make([]Note, 0)
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.Title, &n.Content, &n.Pinned, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notes: %w", err)
+	}
+
+	if err := p.attachTags(notes); err != nil {
+		return nil, err
+	}
+	if err := p.attachReminders(notes); err != nil {
+		return nil, err
+	}
+	if err := p.attachNotebooks(notes); err != nil {
+		return nil, err
+	}
+
+	// Normalize once up front; every note is compared with every other one.
+	titles := make([]string, len(notes))
+	words := make([]map[string]bool, len(notes))
+	for i, n := range notes {
+		titles[i] = normalizeText(n.Title)
+		words[i] = wordSet(n.Content)
+	}
+
+	pairs := make([]DuplicatePair, 0)
+	for i := 0; i < len(notes); i++ {
+		for j := i + 1; j < len(notes); j++ {
+			titleSimilarity := stringSimilarity(titles[i], titles[j])
+			contentSimilarity := jaccard(words[i], words[j])
+
+			// Notes without content are compared on their titles alone.
+			score := titleSimilarity
+			if len(words[i]) > 0 || len(words[j]) > 0 {
+				score = (titleSimilarity + contentSimilarity) / 2
+			}
+
+			if score >= threshold {
+				pairs = append(pairs, DuplicatePair{
+					Note:              notes[i],
+					Duplicate:         notes[j],
+					Score:             round2(score),
+					TitleSimilarity:   round2(titleSimilarity),
+					ContentSimilarity: round2(contentSimilarity),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].Score > pairs[b].Score })
+
+	return sdk.Success(200, pairs)
+}
+
+// mergeNote merges a note into another one and deletes it. The target keeps
+// its title; the source content is appended unless one copy already
+// contains the other, tags are unioned, and [[Source Title]] links in other notes
+// are rewritten to point at the target.
+func (p *QuickNotesPlugin) mergeNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	// Path: /notes/{id}/merge-into/{targetID}
+	parts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
+	if len(parts) != 4 || parts[2] != "merge-into" {
+		return sdk.Error(404, "NOT_FOUND", "route not found")
+	}
+
+	sourceID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || sourceID <= 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid note ID")
+	}
+	targetID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || targetID <= 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid target note ID")
+	}
+	if sourceID == targetID {
+		return sdk.Error(400, "VALIDATION_ERROR", "a note cannot be merged into itself")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var source, target Note
+	for _, item := range []struct {
+		id   int64
+		note *Note
+	}{{sourceID, &source}, {targetID, &target}} {
+		err := tx.QueryRow(
+			"SELECT id, title, content, pinned, created_at, updated_at FROM notes WHERE id = ?", item.id,
+		).Scan(&item.note.ID, &item.note.Title, &item.note.Content, &item.note.Pinned, &item.note.CreatedAt, &item.note.UpdatedAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return sdk.Error(404, "NOT_FOUND", fmt.Sprintf("note %d not found", item.id))
+			}
+			return nil, fmt.Errorf("querying note: %w", err)
+		}
+	}
+
+	// When one copy already contains the other, keep the longer one as is.
+	content := target.Content
+	sourceContent := strings.TrimSpace(source.Content)
+	targetContent := strings.TrimSpace(target.Content)
+	switch {
+	case sourceContent == "" || strings.Contains(targetContent, sourceContent):
+	case targetContent == "" || strings.Contains(sourceContent, targetContent):
+		content = source.Content
+	default:
+		content = strings.TrimRight(target.Content, "\n") + "\n\n" + source.Content
+	}
+
+	// The merged note is as old as the oldest copy.
+	createdAt := target.CreatedAt
+	if source.CreatedAt < createdAt {
+		createdAt = source.CreatedAt
+	}
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	if _, err := tx.Exec(
+		"UPDATE notes SET content = ?, pinned = ?, created_at = ?, updated_at = ? WHERE id = ?",
+		content, target.Pinned || source.Pinned, createdAt, now, targetID,
+	); err != nil {
+		return nil, fmt.Errorf("updating target note: %w", err)
+	}
+
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO note_tags (note_id, tag_id) SELECT ?, tag_id FROM note_tags WHERE note_id = ?",
+		targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("merging tags: %w", err)
+	}
+
+	// The target keeps its own reminder; otherwise it takes the source's.
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO note_reminders (note_id, remind_at, fired_at) SELECT ?, remind_at, fired_at FROM note_reminders WHERE note_id = ?",
+		targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("merging reminders: %w", err)
+	}
+
+	// Likewise for the notebook.
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO note_notebooks (note_id, notebook_id) SELECT ?, notebook_id FROM note_notebooks WHERE note_id = ?",
+		targetID, sourceID,
+	); err != nil {
+		return nil, fmt.Errorf("merging notebooks: %w", err)
+	}
+
+	// Redirect wiki-style backlinks unless both notes share a title already.
+	var backlinksUpdated int64
+	if source.Title != target.Title {
+		oldLink := "[[" + source.Title + "]]"
+		result, err := tx.Exec(
+			"UPDATE notes SET content = REPLACE(content, ?, ?), updated_at = ? WHERE id != ? AND instr(content, ?) > 0",
+			oldLink, "[["+target.Title+"]]", now, sourceID, oldLink,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("redirecting backlinks: %w", err)
+		}
+		backlinksUpdated, _ = result.RowsAffected()
+	}
+
+	if _, err := tx.Exec("DELETE FROM notes WHERE id = ?", sourceID); err != nil {
+		return nil, fmt.Errorf("deleting merged note: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	notifyNotesChanged()
+	return sdk.Success(200, map[string]interface{}{
+		"id":                targetID,
+		"merged":            sourceID,
+		"backlinks_updated": backlinksUpdated,
+		"updated_at":        now,
+	})
+}
+
+// --- Similarity helpers ---
+
+// normalizeText lowercases s and collapses punctuation and whitespace runs
+// into single spaces, so "Fwd: Groceries!" and "fwd groceries" compare equal.
+func normalizeText(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// wordSet returns the distinct normalized words in s.
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(normalizeText(s)) {
+		set[word] = true
+	}
+	return set
+}
+
+// jaccard returns the Jaccard similarity of two word sets. Two empty sets are identical.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for word := range a {
+		if b[word] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// stringSimilarity returns 1 minus the Levenshtein distance between a and b,
+// relative to the longer string.
+func stringSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func round2(value float64) float64 {
+	return float64(int(value*100+0.5)) / 100
+}
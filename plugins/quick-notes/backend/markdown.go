@@ -0,0 +1,518 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+const (
+	// maxMarkdownFileSize caps a single note read from an import.
+	maxMarkdownFileSize = 1 << 20
+	// maxMarkdownImportSize caps the markdown an import may expand to, so a
+	// small zip cannot unpack into something much larger.
+	maxMarkdownImportSize = 64 << 20
+	// maxMarkdownFileName caps the length of an exported file name, in
+	// characters, before its extension.
+	maxMarkdownFileName = 100
+)
+
+// markdownTimeLayout is how exported frontmatter writes timestamps.
+const markdownTimeLayout = time.RFC3339
+
+// zipMagic starts every zip archive.
+var zipMagic = []byte("PK\x03\x04")
+
+// markdownFrontmatter is the YAML header of an exported note. Obsidian
+// shows it as the note's properties and keeps the fields it does not know.
+type markdownFrontmatter struct {
+	Title   string   `yaml:"title"`
+	Tags    []string `yaml:"tags,omitempty"`
+	Pinned  bool     `yaml:"pinned,omitempty"`
+	Created string   `yaml:"created,omitempty"`
+	Updated string   `yaml:"updated,omitempty"`
+}
+
+// importedFrontmatter is the YAML header of an imported note. Besides the
+// fields export writes, it takes the names other tools use for the
+// timestamps, and tags as a list or as one comma or space separated string.
+type importedFrontmatter struct {
+	Title     string    `yaml:"title"`
+	Tags      yaml.Node `yaml:"tags"`
+	Pinned    bool      `yaml:"pinned"`
+	Created   string    `yaml:"created"`
+	CreatedAt string    `yaml:"created_at"`
+	Updated   string    `yaml:"updated"`
+	UpdatedAt string    `yaml:"updated_at"`
+	Modified  string    `yaml:"modified"`
+}
+
+// markdownFile is a markdown file of an import: its path, with the folder
+// naming the notebook, and its content, or why it cannot be imported.
+type markdownFile struct {
+	name    string
+	content []byte
+	err     string
+}
+
+// MarkdownImportResult reports what happened to one imported file.
+type MarkdownImportResult struct {
+	File string `json:"file"`
+	BulkNoteResult
+}
+
+// exportMarkdown handles GET /notes/export: a zip with a markdown file per
+// note, with its title, tags, pin and timestamps in YAML frontmatter, in a
+// folder named after its notebook. Opened as an Obsidian vault, the folders
+// show as folders and the frontmatter as properties.
+func (p *QuickNotesPlugin) exportMarkdown(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	notes, err := p.queryNotes(noteFilter{})
+	if err != nil {
+		return nil, err
+	}
+	notebooks, err := p.queryNotebooks()
+	if err != nil {
+		return nil, err
+	}
+	notebookFolders := make(map[int64]string, len(notebooks))
+	for _, notebook := range notebooks {
+		notebookFolders[notebook.ID] = markdownFileName(notebook.Name)
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	taken := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		folder := ""
+		if note.NotebookID != nil {
+			folder = notebookFolders[*note.NotebookID]
+		}
+		name := uniqueMarkdownPath(folder, markdownFileName(note.Title), taken)
+
+		content, err := renderMarkdownNote(note)
+		if err != nil {
+			return nil, err
+		}
+		modified, _ := time.Parse("2006-01-02 15:04:05", note.UpdatedAt)
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, fmt.Errorf("adding %s to export: %w", name, err)
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, fmt.Errorf("writing %s to export: %w", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("closing export: %w", err)
+	}
+
+	if buffer.Len() > sdk.MaxAPIBodySize {
+		return sdk.Error(413, "EXPORT_TOO_LARGE", "the notes are too large to export at once")
+	}
+	filename := fmt.Sprintf("quick-notes-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	return sdk.FileResponse("application/zip", filename, buffer.Bytes()), nil
+}
+
+// renderMarkdownNote writes a note as markdown with YAML frontmatter.
+func renderMarkdownNote(note Note) ([]byte, error) {
+	frontmatter := markdownFrontmatter{
+		Title:   note.Title,
+		Pinned:  note.Pinned,
+		Created: markdownTimestamp(note.CreatedAt),
+		Updated: markdownTimestamp(note.UpdatedAt),
+	}
+	for _, tag := range note.Tags {
+		frontmatter.Tags = append(frontmatter.Tags, tag.Name)
+	}
+
+	var content bytes.Buffer
+	content.WriteString("---\n")
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(frontmatter); err != nil {
+		return nil, fmt.Errorf("writing frontmatter: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("writing frontmatter: %w", err)
+	}
+	content.WriteString("---\n")
+	content.WriteString(note.Content)
+	return content.Bytes(), nil
+}
+
+// markdownTimestamp turns a stored timestamp into RFC 3339.
+func markdownTimestamp(stored string) string {
+	parsed, err := time.Parse("2006-01-02 15:04:05", stored)
+	if err != nil {
+		return stored
+	}
+	return parsed.UTC().Format(markdownTimeLayout)
+}
+
+// markdownFileName makes a title safe to use as a file or folder name on
+// every platform Obsidian runs on.
+func markdownFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|#^[]`, r):
+			return '-'
+		case r < ' ':
+			return -1
+		}
+		return r
+	}, title)
+	name = strings.Trim(name, " .")
+	if utf8.RuneCountInString(name) > maxMarkdownFileName {
+		name = strings.TrimRight(string([]rune(name)[:maxMarkdownFileName]), " .")
+	}
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
+
+// uniqueMarkdownPath names a note's file in folder, numbering it when another
+// note already took the name. Names differing only in case count as taken,
+// as they do on macOS and Windows.
+func uniqueMarkdownPath(folder string, name string, taken map[string]bool) string {
+	candidate := path.Join(folder, name+".md")
+	for i := 2; taken[strings.ToLower(candidate)]; i++ {
+		candidate = path.Join(folder, fmt.Sprintf("%s (%d).md", name, i))
+	}
+	taken[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// importMarkdown handles POST /notes/import. The body is a zip of markdown
+// files, such as an Obsidian vault or GET /notes/export, a single markdown
+// file named by ?filename=, or a multipart form with either. A note's title
+// is the one in its frontmatter or else its file name, and the folder it is
+// in names its notebook, created when missing. With ?upsert=true, a note
+// whose title matches an existing one updates it instead. Files that cannot
+// be imported are reported in the result list and do not stop the rest.
+func (p *QuickNotesPlugin) importMarkdown(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	files, err := markdownImportFiles(req)
+	if err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", err.Error())
+	}
+	if len(files) == 0 {
+		return sdk.Error(400, "VALIDATION_ERROR", "the import has no markdown files")
+	}
+	if len(files) > maxBulkNotes {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("at most %d notes can be imported at once", maxBulkNotes))
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	results := make([]MarkdownImportResult, 0, len(files))
+	created, updated, failed := 0, 0, 0
+	for index, file := range files {
+		result := MarkdownImportResult{File: file.name, BulkNoteResult: BulkNoteResult{Index: index, Status: bulkFailed, Error: file.err}}
+		if file.err == "" {
+			result.BulkNoteResult, err = importMarkdownNote(tx, file, req.Query["upsert"] == "true", now)
+			if err != nil {
+				return nil, err
+			}
+			result.Index = index
+		}
+
+		switch result.Status {
+		case bulkCreated:
+			created++
+		case bulkUpdated:
+			updated++
+		default:
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	if created+updated > 0 {
+		notifyNotesChanged()
+	}
+
+	return sdk.Success(200, map[string]interface{}{
+		"created": created,
+		"updated": updated,
+		"failed":  failed,
+		"results": results,
+	})
+}
+
+// importMarkdownNote writes one markdown file as a note and files it in the
+// notebook its folder names. Like importNote, it only returns database
+// failures as errors.
+func importMarkdownNote(tx *sql.Tx, file markdownFile, upsert bool, now string) (BulkNoteResult, error) {
+	note, err := parseMarkdownNote(file)
+	if err != nil {
+		return BulkNoteResult{Status: bulkFailed, Error: err.Error()}, nil
+	}
+
+	notebook := strings.TrimSpace(path.Base(path.Dir(file.name)))
+	if notebook == "." || notebook == "/" {
+		notebook = ""
+	}
+	if notebook != "" && utf8.RuneCountInString(notebook) > maxNotebookNameLength {
+		return BulkNoteResult{Status: bulkFailed, Error: fmt.Sprintf("folder names a notebook longer than %d characters", maxNotebookNameLength)}, nil
+	}
+
+	result, err := importNote(tx, note, upsert, now)
+	if err != nil || result.Status == bulkFailed || notebook == "" {
+		return result, err
+	}
+
+	if _, err := tx.Exec("INSERT OR IGNORE INTO notebooks (name) VALUES (?)", notebook); err != nil {
+		return BulkNoteResult{}, fmt.Errorf("creating notebook: %w", err)
+	}
+	var notebookID int64
+	if err := tx.QueryRow("SELECT id FROM notebooks WHERE name = ?", notebook).Scan(&notebookID); err != nil {
+		return BulkNoteResult{}, fmt.Errorf("reading notebook: %w", err)
+	}
+	if err := setNoteNotebook(tx, result.ID, &notebookID); err != nil {
+		return BulkNoteResult{}, err
+	}
+	return result, nil
+}
+
+// parseMarkdownNote reads a markdown file, with or without frontmatter, into
+// the note importNote writes.
+func parseMarkdownNote(file markdownFile) (BulkNoteInput, error) {
+	if !utf8.Valid(file.content) {
+		return BulkNoteInput{}, errors.New("file is not UTF-8 text")
+	}
+	text := strings.TrimPrefix(string(file.content), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var frontmatter importedFrontmatter
+	if header, body, ok := splitFrontmatter(text); ok {
+		if err := yaml.Unmarshal([]byte(header), &frontmatter); err != nil {
+			return BulkNoteInput{}, fmt.Errorf("invalid frontmatter: %v", err)
+		}
+		text = body
+	}
+
+	note := BulkNoteInput{
+		Title:     strings.TrimSpace(frontmatter.Title),
+		Content:   text,
+		Pinned:    frontmatter.Pinned,
+		CreatedAt: firstNonEmpty(frontmatter.Created, frontmatter.CreatedAt),
+		UpdatedAt: firstNonEmpty(frontmatter.Updated, frontmatter.UpdatedAt, frontmatter.Modified),
+		Tags:      frontmatterTags(&frontmatter.Tags),
+	}
+	if note.Title == "" {
+		base := path.Base(file.name)
+		note.Title = strings.TrimSpace(strings.TrimSuffix(base, path.Ext(base)))
+	}
+	return note, nil
+}
+
+// splitFrontmatter separates the YAML frontmatter of a markdown document,
+// between a first line of "---" and the next "---" or "...", from its body.
+func splitFrontmatter(text string) (header string, body string, ok bool) {
+	rest, found := strings.CutPrefix(text, "---\n")
+	if !found {
+		return "", text, false
+	}
+	offset := 0
+	for {
+		end := strings.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if line == "---" || line == "..." {
+			body := ""
+			if end >= 0 {
+				body = rest[offset+end+1:]
+			}
+			return rest[:offset], body, true
+		}
+		if end < 0 {
+			return "", text, false
+		}
+		offset += end + 1
+	}
+}
+
+// frontmatterTags reads the tags property: a list, or a string of tags
+// separated by commas or spaces. A leading "#" is dropped.
+func frontmatterTags(node *yaml.Node) []string {
+	var values []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		values = strings.FieldsFunc(node.Value, func(r rune) bool { return r == ',' || r == ' ' })
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				values = append(values, item.Value)
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(values))
+	for _, value := range values {
+		if tag := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "#")); tag != "" && utf8.RuneCountInString(tag) <= maxTagNameLength {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// markdownImportFiles collects the markdown files of an import request.
+func markdownImportFiles(req *sdk.APIRequest) ([]markdownFile, error) {
+	budget := maxMarkdownImportSize
+
+	form, err := req.MultipartForm()
+	if errors.Is(err, sdk.ErrNotMultipart) {
+		if len(req.Body) == 0 {
+			return nil, nil
+		}
+		if bytes.HasPrefix(req.Body, zipMagic) {
+			return readMarkdownZip(req.Body, &budget)
+		}
+		name := req.Query["filename"]
+		if name == "" {
+			name = "Untitled.md"
+		}
+		return []markdownFile{{name: path.Base(name), content: req.Body}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = form.RemoveAll() }()
+
+	fields := make([]string, 0, len(form.File))
+	for field := range form.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var files []markdownFile
+	for _, field := range fields {
+		for _, header := range form.File[field] {
+			content, err := readFormFile(header)
+			if err != nil {
+				return nil, err
+			}
+			if bytes.HasPrefix(content, zipMagic) {
+				unpacked, err := readMarkdownZip(content, &budget)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", header.Filename, err)
+				}
+				files = append(files, unpacked...)
+				continue
+			}
+			file := markdownFile{name: path.Base(header.Filename), content: content}
+			if !isMarkdownName(file.name) {
+				file.err = "not a markdown file"
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// readFormFile reads an uploaded file of a multipart form.
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", header.Filename, err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// readMarkdownZip reads the markdown files of a zip, skipping other files and
+// hidden folders such as .obsidian and .trash. budget is how much markdown
+// may still be unpacked, shared by every zip of a request.
+func readMarkdownZip(content []byte, budget *int) ([]markdownFile, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip: %v", err)
+	}
+
+	var files []markdownFile
+	for _, entry := range archive.File {
+		name := path.Clean("/" + strings.ReplaceAll(entry.Name, `\`, "/"))[1:]
+		if entry.FileInfo().IsDir() || !isMarkdownName(name) || hiddenZipPath(name) {
+			continue
+		}
+
+		file := markdownFile{name: name}
+		if entry.UncompressedSize64 > maxMarkdownFileSize {
+			file.err = fmt.Sprintf("file is larger than %d bytes", maxMarkdownFileSize)
+			files = append(files, file)
+			continue
+		}
+		reader, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", name, err)
+		}
+		file.content, err = io.ReadAll(io.LimitReader(reader, maxMarkdownFileSize+1))
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %v", name, err)
+		}
+		if len(file.content) > maxMarkdownFileSize {
+			file.content, file.err = nil, fmt.Sprintf("file is larger than %d bytes", maxMarkdownFileSize)
+		}
+
+		*budget -= len(file.content)
+		if *budget < 0 {
+			return nil, fmt.Errorf("the import unpacks to more than %d bytes", maxMarkdownImportSize)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// isMarkdownName reports whether a file name has a markdown extension.
+func isMarkdownName(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// hiddenZipPath reports whether a zip entry is in a folder or file that
+// starts with a dot, or in the __MACOSX folder macOS adds to zips.
+func hiddenZipPath(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") || segment == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
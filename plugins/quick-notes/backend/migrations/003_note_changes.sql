@@ -0,0 +1,47 @@
+-- Quick Notes: change log for incremental sync
+-- Every insert, update and delete of a note, and every change to its tags,
+-- appends a row. Sync clients read the rows after the last seq they saw
+-- through GET /notes/changes. Triggers record the changes so no write path
+-- can miss one.
+
+CREATE TABLE IF NOT EXISTS note_changes (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    note_id INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK(kind IN ('created', 'updated', 'deleted')),
+    changed_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_changes_note_id ON note_changes(note_id);
+
+CREATE TRIGGER IF NOT EXISTS note_changes_insert AFTER INSERT ON notes
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (NEW.id, 'created');
+END;
+
+CREATE TRIGGER IF NOT EXISTS note_changes_update AFTER UPDATE ON notes
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (NEW.id, 'updated');
+END;
+
+CREATE TRIGGER IF NOT EXISTS note_changes_delete AFTER DELETE ON notes
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (OLD.id, 'deleted');
+END;
+
+-- Tag changes update the note, unless the note itself is being deleted.
+CREATE TRIGGER IF NOT EXISTS note_changes_tag_insert AFTER INSERT ON note_tags
+WHEN EXISTS (SELECT 1 FROM notes WHERE id = NEW.note_id)
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (NEW.note_id, 'updated');
+END;
+
+CREATE TRIGGER IF NOT EXISTS note_changes_tag_delete AFTER DELETE ON note_tags
+WHEN EXISTS (SELECT 1 FROM notes WHERE id = OLD.note_id)
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (OLD.note_id, 'updated');
+END;
+
+-- Notes written before the log existed start at version 1.
+INSERT INTO note_changes (note_id, kind, changed_at)
+SELECT id, 'created', updated_at FROM notes
+WHERE NOT EXISTS (SELECT 1 FROM note_changes c WHERE c.note_id = notes.id);
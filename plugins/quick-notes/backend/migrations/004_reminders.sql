@@ -0,0 +1,31 @@
+-- Quick Notes: reminders
+-- A note has at most one reminder. The plugin sends a notification once
+-- remind_at has passed and records when in fired_at; moving the reminder
+-- clears fired_at so it fires again.
+
+CREATE TABLE IF NOT EXISTS note_reminders (
+    note_id INTEGER PRIMARY KEY REFERENCES notes(id) ON DELETE CASCADE,
+    remind_at TEXT NOT NULL,
+    fired_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_reminders_pending ON note_reminders(remind_at) WHERE fired_at IS NULL;
+
+-- Setting, moving or clearing a reminder updates the note, unless the note
+-- itself is being deleted. Firing it does not.
+CREATE TRIGGER IF NOT EXISTS note_changes_reminder_insert AFTER INSERT ON note_reminders
+WHEN EXISTS (SELECT 1 FROM notes WHERE id = NEW.note_id)
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (NEW.note_id, 'updated');
+END;
+
+CREATE TRIGGER IF NOT EXISTS note_changes_reminder_update AFTER UPDATE OF remind_at ON note_reminders
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (NEW.note_id, 'updated');
+END;
+
+CREATE TRIGGER IF NOT EXISTS note_changes_reminder_delete AFTER DELETE ON note_reminders
+WHEN EXISTS (SELECT 1 FROM notes WHERE id = OLD.note_id)
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (OLD.note_id, 'updated');
+END;
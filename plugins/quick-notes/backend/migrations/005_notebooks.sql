@@ -0,0 +1,35 @@
+-- Quick Notes: notebooks
+-- A note is in at most one notebook, or in none. Deleting a notebook leaves
+-- its notes outside any notebook rather than deleting them.
+
+CREATE TABLE IF NOT EXISTS notebooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS note_notebooks (
+    note_id INTEGER PRIMARY KEY REFERENCES notes(id) ON DELETE CASCADE,
+    notebook_id INTEGER NOT NULL REFERENCES notebooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_notebooks_notebook_id ON note_notebooks(notebook_id);
+
+-- Filing a note, moving it or taking it out of its notebook updates the note,
+-- unless the note itself is being deleted.
+CREATE TRIGGER IF NOT EXISTS note_changes_notebook_insert AFTER INSERT ON note_notebooks
+WHEN EXISTS (SELECT 1 FROM notes WHERE id = NEW.note_id)
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (NEW.note_id, 'updated');
+END;
+
+CREATE TRIGGER IF NOT EXISTS note_changes_notebook_update AFTER UPDATE OF notebook_id ON note_notebooks
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (NEW.note_id, 'updated');
+END;
+
+CREATE TRIGGER IF NOT EXISTS note_changes_notebook_delete AFTER DELETE ON note_notebooks
+WHEN EXISTS (SELECT 1 FROM notes WHERE id = OLD.note_id)
+BEGIN
+    INSERT INTO note_changes (note_id, kind) VALUES (OLD.note_id, 'updated');
+END;
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+const maxNotebookNameLength = 50
+
+// notebookNone is the ?notebook= value that lists the notes outside any
+// notebook.
+const notebookNone = "none"
+
+// Notebook is a folder of notes. NoteCount is how many notes it holds.
+type Notebook struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	NoteCount int    `json:"note_count"`
+}
+
+// --- Notebook handlers ---
+
+func (p *QuickNotesPlugin) listNotebooks() (*sdk.APIResponse, error) {
+	notebooks, err := p.queryNotebooks()
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, notebooks)
+}
+
+func (p *QuickNotesPlugin) createNotebook(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if resp, err := validateNotebookName(input.Name); resp != nil || err != nil {
+		return resp, err
+	}
+
+	result, err := p.db.Exec("INSERT INTO notebooks (name) VALUES (?)", input.Name)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return sdk.Error(409, "CONFLICT", "a notebook with this name already exists")
+		}
+		return nil, fmt.Errorf("inserting notebook: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	var notebook Notebook
+	if err := p.db.QueryRow("SELECT id, name, created_at FROM notebooks WHERE id = ?", id).Scan(&notebook.ID, &notebook.Name, &notebook.CreatedAt); err != nil {
+		return nil, fmt.Errorf("reading notebook: %w", err)
+	}
+	return sdk.Success(201, notebook)
+}
+
+// updateNotebook renames a notebook.
+func (p *QuickNotesPlugin) updateNotebook(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id := extractID(req.Path, "/notebooks/")
+	if id == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "missing notebook ID")
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if resp, err := validateNotebookName(input.Name); resp != nil || err != nil {
+		return resp, err
+	}
+
+	result, err := p.db.Exec("UPDATE notebooks SET name = ? WHERE id = ?", input.Name, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return sdk.Error(409, "CONFLICT", "a notebook with this name already exists")
+		}
+		return nil, fmt.Errorf("updating notebook: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "notebook not found")
+	}
+
+	notifyNotesChanged()
+	return sdk.Success(200, map[string]interface{}{"id": id, "name": input.Name})
+}
+
+// deleteNotebook removes a notebook. Its notes are kept, outside any notebook.
+func (p *QuickNotesPlugin) deleteNotebook(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id := extractID(req.Path, "/notebooks/")
+	if id == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "missing notebook ID")
+	}
+
+	result, err := p.db.Exec("DELETE FROM notebooks WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting notebook: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "notebook not found")
+	}
+
+	notifyNotesChanged()
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// --- Helpers ---
+
+func validateNotebookName(name string) (*sdk.APIResponse, error) {
+	if name == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "name is required")
+	}
+	if len(name) > maxNotebookNameLength {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("name must be %d characters or less", maxNotebookNameLength))
+	}
+	return nil, nil
+}
+
+// queryNotebooks lists every notebook by name with its note count.
+func (p *QuickNotesPlugin) queryNotebooks() ([]Notebook, error) {
+	rows, err := p.db.Query(
+		`SELECT nb.id, nb.name, nb.created_at, COUNT(nn.note_id)
+		 FROM notebooks nb LEFT JOIN note_notebooks nn ON nn.notebook_id = nb.id
+		 GROUP BY nb.id
+		 ORDER BY nb.name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying notebooks: %w", err)
+	}
+	defer rows.Close()
+
+	notebooks := make([]Notebook, 0)
+	for rows.Next() {
+		var notebook Notebook
+		if err := rows.Scan(&notebook.ID, &notebook.Name, &notebook.CreatedAt, &notebook.NoteCount); err != nil {
+			return nil, fmt.Errorf("scanning notebook: %w", err)
+		}
+		notebooks = append(notebooks, notebook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notebooks: %w", err)
+	}
+	return notebooks, nil
+}
+
+// parseNotebookID reads a notebook_id field and checks that the notebook
+// exists. It returns nil for null.
+func (p *QuickNotesPlugin) parseNotebookID(raw json.RawMessage) (*int64, *sdk.APIResponse, error) {
+	var value *int64
+	if err := json.Unmarshal(raw, &value); err != nil {
+		resp, err := sdk.Error(400, "VALIDATION_ERROR", "notebook_id must be a notebook ID or null")
+		return nil, resp, err
+	}
+	if value == nil {
+		return nil, nil, nil
+	}
+
+	var exists bool
+	if err := p.db.QueryRow("SELECT EXISTS(SELECT 1 FROM notebooks WHERE id = ?)", *value).Scan(&exists); err != nil {
+		return nil, nil, fmt.Errorf("checking notebook: %w", err)
+	}
+	if !exists {
+		resp, err := sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("notebook %d not found", *value))
+		return nil, resp, err
+	}
+	return value, nil, nil
+}
+
+// setNoteNotebook files a note in a notebook or, for nil, takes it out of
+// its notebook.
+func setNoteNotebook(tx *sql.Tx, noteID int64, notebookID *int64) error {
+	if notebookID == nil {
+		if _, err := tx.Exec("DELETE FROM note_notebooks WHERE note_id = ?", noteID); err != nil {
+			return fmt.Errorf("removing note from notebook: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO note_notebooks (note_id, notebook_id) VALUES (?, ?)
+		 ON CONFLICT(note_id) DO UPDATE SET notebook_id = excluded.notebook_id
+		 WHERE note_notebooks.notebook_id != excluded.notebook_id`,
+		noteID, *notebookID,
+	); err != nil {
+		return fmt.Errorf("filing note in notebook: %w", err)
+	}
+	return nil
+}
+
+// attachNotebooks fills in the notebook of each note with a single query.
+func (p *QuickNotesPlugin) attachNotebooks(notes []Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(notes))
+	placeholders := make([]string, len(notes))
+	idToIdx := make(map[int64]int, len(notes))
+	for i, n := range notes {
+		ids[i] = n.ID
+		placeholders[i] = "?"
+		idToIdx[n.ID] = i
+	}
+
+	rows, err := p.db.Query(
+		fmt.Sprintf("SELECT note_id, notebook_id FROM note_notebooks WHERE note_id IN (%s)", strings.Join(placeholders, ",")),
+		ids...,
+	)
+	if err != nil {
+		return fmt.Errorf("querying note notebooks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noteID, notebookID int64
+		if err := rows.Scan(&noteID, &notebookID); err != nil {
+			return fmt.Errorf("scanning note notebook: %w", err)
+		}
+		if idx, ok := idToIdx[noteID]; ok {
+			notes[idx].NotebookID = &notebookID
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating note notebooks: %w", err)
+	}
+	return nil
+}
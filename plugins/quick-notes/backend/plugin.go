@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +21,16 @@ var migrations embed.FS
 // QuickNotesPlugin implements sdk.CortexPlugin for note-taking.
 type QuickNotesPlugin struct {
 	db *sql.DB
+	// host schedules the reminder check; it is nil outside the host.
+	host *sdk.HostServices
+	// notify delivers reminders. Nil means sdk.SendNotification.
+	notify func(title string, body string, urgent bool) error
+}
+
+// UseHost keeps the host's services, which Migrate schedules the reminder
+// check with.
+func (p *QuickNotesPlugin) UseHost(host *sdk.HostServices) {
+	p.host = host
 }
 
 // GetManifest returns the plugin's metadata.
@@ -30,23 +42,20 @@ func (p *QuickNotesPlugin) GetManifest() (*sdk.Manifest, error) {
 		Description: "Capture ideas and notes quickly, local and private",
 		Icon:        "notebook-pen",
 		Color:       "#6366F1",
-		Permissions: []string{"db:read", "db:write"},
+		Permissions: []string{"db:read", "db:write", "notifications"},
 	}, nil
 }
 
 // Migrate opens the SQLite database and runs embedded SQL migrations.
 func (p *QuickNotesPlugin) Migrate(databasePath string) error {
-	database, err := sql.Open("sqlite", databasePath)
+	// The SDK enables WAL mode and foreign keys, and encrypts the database
+	// when the host has at-rest encryption enabled.
+	database, err := sdk.OpenDatabase(databasePath)
 	if err != nil {
 		return fmt.Errorf("opening database: %w", err)
 	}
 	p.db = database
 
-	// Enable WAL mode for better concurrent read performance.
-	if _, err := p.db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		return fmt.Errorf("enabling WAL mode: %w", err)
-	}
-
 	migrationSQL, err := migrations.ReadFile("migrations/001_init.sql")
 	if err != nil {
 		return fmt.Errorf("reading migration: %w", err)
@@ -56,24 +65,97 @@ func (p *QuickNotesPlugin) Migrate(databasePath string) error {
 		return fmt.Errorf("running migration: %w", err)
 	}
 
+	tagsSQL, err := migrations.ReadFile("migrations/002_tags.sql")
+	if err != nil {
+		return fmt.Errorf("reading tags migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(tagsSQL)); err != nil {
+		return fmt.Errorf("running tags migration: %w", err)
+	}
+
+	changesSQL, err := migrations.ReadFile("migrations/003_note_changes.sql")
+	if err != nil {
+		return fmt.Errorf("reading note changes migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(changesSQL)); err != nil {
+		return fmt.Errorf("running note changes migration: %w", err)
+	}
+
+	remindersSQL, err := migrations.ReadFile("migrations/004_reminders.sql")
+	if err != nil {
+		return fmt.Errorf("reading reminders migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(remindersSQL)); err != nil {
+		return fmt.Errorf("running reminders migration: %w", err)
+	}
+
+	notebooksSQL, err := migrations.ReadFile("migrations/005_notebooks.sql")
+	if err != nil {
+		return fmt.Errorf("reading notebooks migration: %w", err)
+	}
+
+	if _, err := p.db.Exec(string(notebooksSQL)); err != nil {
+		return fmt.Errorf("running notebooks migration: %w", err)
+	}
+
+	if err := p.scheduleReminders(reminderCheckInterval); err != nil {
+		return fmt.Errorf("scheduling reminders: %w", err)
+	}
 	return nil
 }
 
+// Migrations lists the embedded SQL migrations so the host can lint them
+// before Migrate runs.
+func (p *QuickNotesPlugin) Migrations() ([]sdk.MigrationFile, error) {
+	return sdk.EmbeddedMigrations(migrations, "migrations")
+}
+
 // HandleAPI routes incoming API requests to the appropriate handler.
-func (p *QuickNotesPlugin) HandleAPI(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+func (p *QuickNotesPlugin) HandleAPI(ctx context.Context, req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	switch {
 	case req.Method == "GET" && req.Path == "/notes":
-		return p.listNotes()
+		return p.listNotes(req)
 	case req.Method == "POST" && req.Path == "/notes":
 		return p.createNote(req)
+	case req.Method == "GET" && req.Path == "/notes/export":
+		return p.exportMarkdown(req)
+	case req.Method == "POST" && req.Path == "/notes/import":
+		return p.importMarkdown(req)
+	case req.Method == "POST" && req.Path == "/notes/bulk":
+		return p.bulkNotes(req)
+	case req.Method == "GET" && req.Path == "/notes/changes":
+		return p.listNoteChanges(req)
+	case req.Method == "GET" && req.Path == "/notes/duplicates":
+		return p.findDuplicates(req)
+	case req.Method == "POST" && strings.HasPrefix(req.Path, "/notes/") && strings.Contains(req.Path, "/merge-into/"):
+		return p.mergeNote(req)
 	case req.Method == "PUT" && matchPath(req.Path, "/notes/", "/pin"):
 		return p.togglePin(req)
 	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/notes/"):
 		return p.updateNote(req)
 	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/notes/"):
 		return p.deleteNote(req)
+	case req.Method == "GET" && req.Path == "/tags":
+		return p.listTags()
+	case req.Method == "POST" && req.Path == "/tags":
+		return p.createTag(req)
+	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/tags/"):
+		return p.updateTag(req)
+	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/tags/"):
+		return p.deleteTag(req)
+	case req.Method == "GET" && req.Path == "/notebooks":
+		return p.listNotebooks()
+	case req.Method == "POST" && req.Path == "/notebooks":
+		return p.createNotebook(req)
+	case req.Method == "PUT" && strings.HasPrefix(req.Path, "/notebooks/"):
+		return p.updateNotebook(req)
+	case req.Method == "DELETE" && strings.HasPrefix(req.Path, "/notebooks/"):
+		return p.deleteNotebook(req)
 	default:
-		return jsonError(404, "NOT_FOUND", "route not found")
+		return sdk.Error(404, "NOT_FOUND", "route not found")
 	}
 }
 
@@ -108,6 +190,21 @@ func (p *QuickNotesPlugin) GetWidgetData(slot string) ([]byte, error) {
 		return nil, fmt.Errorf("iterating notes: %w", err)
 	}
 
+	if err := p.attachTags(latestNotes); err != nil {
+		return nil, err
+	}
+	if err := p.attachReminders(latestNotes); err != nil {
+		return nil, err
+	}
+	if err := p.attachNotebooks(latestNotes); err != nil {
+		return nil, err
+	}
+
+	dueToday, err := p.queryNotes(noteFilter{dueToday: true})
+	if err != nil {
+		return nil, err
+	}
+
 	// Count pinned notes
 	var pinnedCount int
 	row := p.db.QueryRow("SELECT COUNT(*) FROM notes WHERE pinned = 1")
@@ -115,10 +212,23 @@ func (p *QuickNotesPlugin) GetWidgetData(slot string) ([]byte, error) {
 		return nil, fmt.Errorf("counting pinned notes: %w", err)
 	}
 
+	notebooks, err := p.queryNotebooks()
+	if err != nil {
+		return nil, err
+	}
+	var unfiledCount int
+	row = p.db.QueryRow("SELECT COUNT(*) FROM notes n WHERE NOT EXISTS (SELECT 1 FROM note_notebooks nn WHERE nn.note_id = n.id)")
+	if err := row.Scan(&unfiledCount); err != nil {
+		return nil, fmt.Errorf("counting notes outside notebooks: %w", err)
+	}
+
 	return json.Marshal(map[string]interface{}{
 		"data": map[string]interface{}{
-			"latest":       latestNotes,
-			"pinned_count": pinnedCount,
+			"latest":        latestNotes,
+			"pinned_count":  pinnedCount,
+			"due_today":     dueToday,
+			"notebooks":     notebooks,
+			"unfiled_count": unfiledCount,
 		},
 	})
 }
@@ -135,22 +245,104 @@ func (p *QuickNotesPlugin) Teardown() error {
 
 // Note represents a user note.
 type Note struct {
-	ID        int64  `json:"id"`
-	Title     string `json:"title"`
-	Content   string `json:"content"`
-	Pinned    bool   `json:"pinned"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        int64   `json:"id"`
+	Title     string  `json:"title"`
+	Content   string  `json:"content"`
+	Pinned    bool    `json:"pinned"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	Tags      []Tag   `json:"tags"`
+	RemindAt  *string `json:"remind_at"`
+	// NotebookID is nil for a note outside any notebook.
+	NotebookID *int64 `json:"notebook_id"`
 }
 
 // --- Handlers ---
 
-func (p *QuickNotesPlugin) listNotes() (*sdk.APIResponse, error) {
-	rows, err := p.db.Query(
-		`SELECT id, title, content, pinned, created_at, updated_at
-		 FROM notes
-		 ORDER BY pinned DESC, updated_at DESC`,
-	)
+// listNotes returns every note with its tags, reminder and notebook. ?tag=
+// keeps the notes with that tag name, ?notebook= those in the notebook with
+// that ID, or outside any notebook for "none", and ?due=today those with a
+// reminder still to fire by the end of the day, overdue ones included,
+// soonest first.
+func (p *QuickNotesPlugin) listNotes(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	dueToday := false
+	switch req.Query["due"] {
+	case "":
+	case "today":
+		dueToday = true
+	default:
+		return sdk.Error(400, "VALIDATION_ERROR", "due must be today")
+	}
+
+	filter := noteFilter{tag: req.Query["tag"], dueToday: dueToday}
+	if notebook := req.Query["notebook"]; notebook != "" {
+		if notebook != notebookNone {
+			if _, err := strconv.ParseInt(notebook, 10, 64); err != nil {
+				return sdk.Error(400, "VALIDATION_ERROR", "notebook must be a notebook ID or none")
+			}
+		}
+		filter.notebook = notebook
+	}
+
+	notes, err := p.queryNotes(filter)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Success(200, notes)
+}
+
+// noteFilter narrows the notes queryNotes lists. Zero fields do not filter.
+type noteFilter struct {
+	// tag is a tag name.
+	tag string
+	// notebook is a notebook ID, or notebookNone.
+	notebook string
+	// dueToday keeps the notes with a reminder due by the end of the day.
+	dueToday bool
+	// noteID is the ID of a single note.
+	noteID int64
+}
+
+// queryNotes lists notes with their tags, reminders and notebooks, optionally
+// only those matching filter.
+func (p *QuickNotesPlugin) queryNotes(filter noteFilter) ([]Note, error) {
+	query := "SELECT n.id, n.title, n.content, n.pinned, n.created_at, n.updated_at FROM notes n"
+	conditions := make([]string, 0, 3)
+	args := make([]interface{}, 0, 3)
+
+	if filter.tag != "" {
+		query += " JOIN note_tags nt ON nt.note_id = n.id JOIN tags t ON t.id = nt.tag_id"
+		conditions = append(conditions, "t.name = ?")
+		args = append(args, filter.tag)
+	}
+	switch filter.notebook {
+	case "":
+	case notebookNone:
+		conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM note_notebooks nn WHERE nn.note_id = n.id)")
+	default:
+		query += " JOIN note_notebooks nn ON nn.note_id = n.id"
+		conditions = append(conditions, "nn.notebook_id = ?")
+		args = append(args, filter.notebook)
+	}
+	if filter.noteID != 0 {
+		conditions = append(conditions, "n.id = ?")
+		args = append(args, filter.noteID)
+	}
+	if filter.dueToday {
+		query += " JOIN note_reminders r ON r.note_id = n.id"
+		conditions = append(conditions, "r.fired_at IS NULL AND r.remind_at < ?")
+		args = append(args, endOfToday(time.Now()))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if filter.dueToday {
+		query += " ORDER BY r.remind_at, n.id"
+	} else {
+		query += " ORDER BY n.pinned DESC, n.updated_at DESC"
+	}
+
+	rows, err := p.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying notes: %w", err)
 	}
@@ -169,24 +361,61 @@ func (p *QuickNotesPlugin) listNotes() (*sdk.APIResponse, error) {
 		return nil, fmt.Errorf("iterating notes: %w", err)
 	}
 
-	return jsonSuccess(200, notes)
+	if err := p.attachTags(notes); err != nil {
+		return nil, err
+	}
+	if err := p.attachReminders(notes); err != nil {
+		return nil, err
+	}
+	if err := p.attachNotebooks(notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
 }
 
 func (p *QuickNotesPlugin) createNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	var input struct {
-		Title   string `json:"title"`
-		Content string `json:"content"`
+		Title      string          `json:"title"`
+		Content    string          `json:"content"`
+		TagIDs     []int64         `json:"tag_ids"`
+		RemindAt   json.RawMessage `json:"remind_at"`
+		NotebookID json.RawMessage `json:"notebook_id"`
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
 	}
 
 	if strings.TrimSpace(input.Title) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "title is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "title is required")
+	}
+	if resp, err := p.checkTagIDs(input.TagIDs); resp != nil || err != nil {
+		return resp, err
+	}
+	var remindAt *string
+	if input.RemindAt != nil {
+		parsed, resp, err := parseRemindAt(input.RemindAt)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		remindAt = parsed
+	}
+	var notebookID *int64
+	if input.NotebookID != nil {
+		parsed, resp, err := p.parseNotebookID(input.NotebookID)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		notebookID = parsed
 	}
 
-	result, err := p.db.Exec(
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.Exec(
 		"INSERT INTO notes (title, content) VALUES (?, ?)",
 		input.Title, input.Content,
 	)
@@ -195,69 +424,178 @@ func (p *QuickNotesPlugin) createNote(req *sdk.APIRequest) (*sdk.APIResponse, er
 	}
 
 	id, _ := result.LastInsertId()
-	return jsonSuccess(201, map[string]interface{}{"id": id})
+	if err := setNoteTags(tx, id, input.TagIDs); err != nil {
+		return nil, err
+	}
+	if remindAt != nil {
+		if err := setNoteReminder(tx, id, remindAt); err != nil {
+			return nil, err
+		}
+	}
+	if notebookID != nil {
+		if err := setNoteNotebook(tx, id, notebookID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	notifyNotesChanged()
+	return sdk.Success(201, map[string]interface{}{"id": id})
 }
 
+// updateNote saves a note. With expected_updated_at or If-Unmodified-Since,
+// it refuses to overwrite a note changed since the client read it, answering
+// 409 CONFLICT with the stored copy.
 func (p *QuickNotesPlugin) updateNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	id := extractID(req.Path, "/notes/")
 	if id == "" {
-		return jsonError(400, "VALIDATION_ERROR", "missing note ID")
+		return sdk.Error(400, "VALIDATION_ERROR", "missing note ID")
 	}
 
+	// TagIDs replaces the note's tags, RemindAt its reminder and NotebookID
+	// its notebook, or clears them when null; omitting them keeps them.
 	var input struct {
-		Title   string `json:"title"`
-		Content string `json:"content"`
+		Title      string          `json:"title"`
+		Content    string          `json:"content"`
+		TagIDs     *[]int64        `json:"tag_ids"`
+		RemindAt   json.RawMessage `json:"remind_at"`
+		NotebookID json.RawMessage `json:"notebook_id"`
+		// ExpectedUpdatedAt is the updated_at of the copy being saved.
+		ExpectedUpdatedAt *string `json:"expected_updated_at"`
 	}
 
 	if err := json.Unmarshal(req.Body, &input); err != nil {
-		return jsonError(400, "VALIDATION_ERROR", "invalid JSON body")
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
 	}
 
 	if strings.TrimSpace(input.Title) == "" {
-		return jsonError(400, "VALIDATION_ERROR", "title is required")
+		return sdk.Error(400, "VALIDATION_ERROR", "title is required")
+	}
+	precondition, resp, err := parseNotePrecondition(req, input.ExpectedUpdatedAt)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+	if input.TagIDs != nil {
+		if resp, err := p.checkTagIDs(*input.TagIDs); resp != nil || err != nil {
+			return resp, err
+		}
+	}
+	var remindAt *string
+	if input.RemindAt != nil {
+		parsed, resp, err := parseRemindAt(input.RemindAt)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		remindAt = parsed
+	}
+	var notebookID *int64
+	if input.NotebookID != nil {
+		parsed, resp, err := p.parseNotebookID(input.NotebookID)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		notebookID = parsed
 	}
 
-	now := time.Now().UTC().Format("2006-01-02 15:04:05")
-	result, err := p.db.Exec(
-		"UPDATE notes SET title = ?, content = ?, updated_at = ? WHERE id = ?",
-		input.Title, input.Content, now, id,
-	)
+	tx, err := p.db.Begin()
 	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var noteID int64
+	var previous string
+	err = tx.QueryRow("SELECT id, updated_at FROM notes WHERE id = ?", id).Scan(&noteID, &previous)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "note not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying note: %w", err)
+	}
+	if precondition != nil && !precondition.holds(previous) {
+		_ = tx.Rollback()
+		return p.noteConflict(noteID)
+	}
+
+	now := nextUpdatedAt(time.Now(), previous)
+	if _, err := tx.Exec(
+		"UPDATE notes SET title = ?, content = ?, updated_at = ? WHERE id = ?",
+		input.Title, input.Content, now, noteID,
+	); err != nil {
 		return nil, fmt.Errorf("updating note: %w", err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return jsonError(404, "NOT_FOUND", "note not found")
+	if input.TagIDs != nil || input.RemindAt != nil || input.NotebookID != nil {
+		if input.TagIDs != nil {
+			if err := setNoteTags(tx, noteID, *input.TagIDs); err != nil {
+				return nil, err
+			}
+		}
+		if input.RemindAt != nil {
+			if err := setNoteReminder(tx, noteID, remindAt); err != nil {
+				return nil, err
+			}
+		}
+		if input.NotebookID != nil {
+			if err := setNoteNotebook(tx, noteID, notebookID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
 	}
 
-	return jsonSuccess(200, map[string]interface{}{"id": id, "updated_at": now})
+	notifyNotesChanged()
+	return sdk.Success(200, map[string]interface{}{"id": id, "updated_at": now})
 }
 
 func (p *QuickNotesPlugin) deleteNote(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	id := extractID(req.Path, "/notes/")
 	if id == "" {
-		return jsonError(400, "VALIDATION_ERROR", "missing note ID")
+		return sdk.Error(400, "VALIDATION_ERROR", "missing note ID")
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	result, err := p.db.Exec("DELETE FROM notes WHERE id = ?", id)
+	// Keep a copy of the note for the host to offer an undo
+	deleted, err := snapshotNote(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec("DELETE FROM notes WHERE id = ?", id)
 	if err != nil {
 		return nil, fmt.Errorf("deleting note: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return jsonError(404, "NOT_FOUND", "note not found")
+		return sdk.Error(404, "NOT_FOUND", "note not found")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
 	}
 
-	return jsonSuccess(200, map[string]interface{}{"deleted": id})
+	notifyNotesChanged()
+	response, err := sdk.Success(200, map[string]interface{}{"deleted": id})
+	if err != nil {
+		return nil, err
+	}
+	return sdk.WithDeleted(response, deletedNoteKind, deleted)
 }
 
 func (p *QuickNotesPlugin) togglePin(req *sdk.APIRequest) (*sdk.APIResponse, error) {
 	// Path: /notes/{id}/pin
 	parts := strings.Split(strings.TrimPrefix(req.Path, "/"), "/")
 	if len(parts) < 3 || parts[1] == "" {
-		return jsonError(400, "VALIDATION_ERROR", "missing note ID")
+		return sdk.Error(400, "VALIDATION_ERROR", "missing note ID")
 	}
 	id := parts[1]
 
@@ -273,7 +611,7 @@ func (p *QuickNotesPlugin) togglePin(req *sdk.APIRequest) (*sdk.APIResponse, err
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return jsonError(404, "NOT_FOUND", "note not found")
+		return sdk.Error(404, "NOT_FOUND", "note not found")
 	}
 
 	// Read the new state
@@ -283,7 +621,8 @@ func (p *QuickNotesPlugin) togglePin(req *sdk.APIRequest) (*sdk.APIResponse, err
 		return nil, fmt.Errorf("reading pin state: %w", err)
 	}
 
-	return jsonSuccess(200, map[string]interface{}{"id": id, "pinned": pinned})
+	notifyNotesChanged()
+	return sdk.Success(200, map[string]interface{}{"id": id, "pinned": pinned})
 }
 
 // --- Helpers ---
@@ -312,32 +651,9 @@ func extractID(path string, prefix string) string {
 	return parts[0]
 }
 
-// --- JSON response helpers ---
-
-// jsonSuccess wraps data in `{ "data": ... }` format per PATTERNS.md.
-func jsonSuccess(status int, data interface{}) (*sdk.APIResponse, error) {
-	body, err := json.Marshal(map[string]interface{}{"data": data})
-	if err != nil {
-		return nil, fmt.Errorf("marshaling response: %w", err)
-	}
-	return &sdk.APIResponse{
-		StatusCode:  status,
-		Body:        body,
-		ContentType: "application/json",
-	}, nil
-}
-
-// jsonError wraps errors in `{ "error": { "code": ..., "message": ... } }` format per PATTERNS.md.
-func jsonError(status int, code string, message string) (*sdk.APIResponse, error) {
-	body, _ := json.Marshal(map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    code,
-			"message": message,
-		},
-	})
-	return &sdk.APIResponse{
-		StatusCode:  status,
-		Body:        body,
-		ContentType: "application/json",
-	}, nil
+// notifyNotesChanged tells the host that notes changed so the dashboard widget
+// refreshes. It is best effort: without a host connection there is simply no
+// live update.
+func notifyNotesChanged() {
+	_ = sdk.NotifyChanged("notes")
 }
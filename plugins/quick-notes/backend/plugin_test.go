@@ -0,0 +1,1899 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// newTestPlugin creates a QuickNotesPlugin with a migrated SQLite database in a temp directory.
+// It returns the plugin ready for testing and calls t.Cleanup to close the database.
+func newTestPlugin(t *testing.T) *QuickNotesPlugin {
+	t.Helper()
+
+	p := &QuickNotesPlugin{}
+	dbPath := filepath.Join(t.TempDir(), "quick_notes_test.db")
+
+	if err := p.Migrate(dbPath); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	t.Cleanup(func() { p.Teardown() })
+	return p
+}
+
+// call sends a request to the plugin and fails the test on a handler error.
+func call(t *testing.T, p *QuickNotesPlugin, method string, path string, body string) *sdk.APIResponse {
+	t.Helper()
+
+	req := &sdk.APIRequest{Method: method, Path: path}
+	if body != "" {
+		req.Body = []byte(body)
+	}
+	resp, err := p.HandleAPI(context.Background(), req)
+	if err != nil {
+		t.Fatalf("%s %s: unexpected error: %v", method, path, err)
+	}
+	return resp
+}
+
+// parseDataObject parses an APIResponse body and returns the "data" field as raw JSON.
+func parseDataObject(t *testing.T, resp *sdk.APIResponse) json.RawMessage {
+	t.Helper()
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("failed to parse response body: %v", err)
+	}
+	return body.Data
+}
+
+// parseErrorResponse parses an error APIResponse body and returns the code and message.
+func parseErrorResponse(t *testing.T, resp *sdk.APIResponse) (code string, message string) {
+	t.Helper()
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("failed to parse error body: %v", err)
+	}
+	return body.Error.Code, body.Error.Message
+}
+
+// createNote is a test helper that creates a note via the API and returns its ID.
+func createNote(t *testing.T, p *QuickNotesPlugin, body string) int64 {
+	t.Helper()
+
+	resp := call(t, p, "POST", "/notes", body)
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201 creating note, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &created); err != nil {
+		t.Fatalf("failed to parse created note: %v", err)
+	}
+	return created.ID
+}
+
+// createTag is a test helper that inserts a tag and returns its ID.
+func createTag(t *testing.T, p *QuickNotesPlugin, name string) int64 {
+	t.Helper()
+
+	result, err := p.db.Exec("INSERT INTO tags (name) VALUES (?)", name)
+	if err != nil {
+		t.Fatalf("creating tag %q: %v", name, err)
+	}
+	id, _ := result.LastInsertId()
+	return id
+}
+
+// tagNote is a test helper that links a note to the given tags.
+func tagNote(t *testing.T, p *QuickNotesPlugin, noteID int64, tagIDs ...int64) {
+	t.Helper()
+
+	for _, tagID := range tagIDs {
+		if _, err := p.db.Exec("INSERT INTO note_tags (note_id, tag_id) VALUES (?, ?)", noteID, tagID); err != nil {
+			t.Fatalf("tagging note %d: %v", noteID, err)
+		}
+	}
+}
+
+// createNotebook is a test helper that creates a notebook via the API and returns its ID.
+func createNotebook(t *testing.T, p *QuickNotesPlugin, name string) int64 {
+	t.Helper()
+
+	resp := call(t, p, "POST", "/notebooks", fmt.Sprintf(`{"name":%q}`, name))
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201 creating notebook, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var notebook Notebook
+	if err := json.Unmarshal(parseDataObject(t, resp), &notebook); err != nil {
+		t.Fatalf("failed to parse notebook: %v", err)
+	}
+	return notebook.ID
+}
+
+// listNoteIDs is a test helper that lists notes via the API with the given
+// query and returns their IDs.
+func listNoteIDs(t *testing.T, p *QuickNotesPlugin, query map[string]string) []int64 {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/notes", Query: query})
+	if err != nil {
+		t.Fatalf("listing notes: unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 listing notes, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var notes []Note
+	if err := json.Unmarshal(parseDataObject(t, resp), &notes); err != nil {
+		t.Fatalf("failed to parse notes: %v", err)
+	}
+	ids := make([]int64, len(notes))
+	for i, n := range notes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// getNote is a test helper that reads a single note with its notebook, or returns nil
+// when it does not exist.
+func getNote(t *testing.T, p *QuickNotesPlugin, id int64) *Note {
+	t.Helper()
+
+	var n Note
+	err := p.db.QueryRow(
+		`SELECT n.id, n.title, n.content, n.pinned, n.created_at, n.updated_at, nn.notebook_id
+		 FROM notes n LEFT JOIN note_notebooks nn ON nn.note_id = n.id
+		 WHERE n.id = ?`, id,
+	).Scan(&n.ID, &n.Title, &n.Content, &n.Pinned, &n.CreatedAt, &n.UpdatedAt, &n.NotebookID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("querying note %d: %v", id, err)
+	}
+	return &n
+}
+
+// tagNames returns the names of a note's tags in alphabetical order.
+func tagNames(t *testing.T, p *QuickNotesPlugin, noteID int64) []string {
+	t.Helper()
+
+	rows, err := p.db.Query(
+		"SELECT t.name FROM tags t JOIN note_tags nt ON nt.tag_id = t.id WHERE nt.note_id = ? ORDER BY t.name", noteID,
+	)
+	if err != nil {
+		t.Fatalf("querying tags of note %d: %v", noteID, err)
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("scanning tag: %v", err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// --- Duplicate detection & merge tests ---
+
+func TestStringSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"groceries", "groceries", 1},
+		{"", "", 1},
+		{"", "abc", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"meeting notes", "meeting notes 2", 1 - 2.0/15},
+		// Distance is counted in runes, not bytes.
+		{"café", "cafe", 0.75},
+	}
+	for _, tt := range tests {
+		if got := stringSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("stringSimilarity(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"milk eggs", "", 0},
+		{"milk eggs", "Eggs, milk!", 1},
+		{"milk eggs bread", "eggs bread butter", 0.5},
+		{"milk", "eggs", 0},
+	}
+	for _, tt := range tests {
+		if got := jaccard(wordSet(tt.a), wordSet(tt.b)); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("jaccard(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFindDuplicates_Threshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     string
+		second    string
+		threshold string
+		wantPairs int
+		wantScore float64
+	}{
+		{
+			name:      "identical notes",
+			first:     `{"title":"Groceries","content":"milk eggs bread"}`,
+			second:    `{"title":"Groceries","content":"milk eggs bread"}`,
+			wantPairs: 1,
+			wantScore: 1,
+		},
+		{
+			name:      "titles differing only in punctuation and case, without content",
+			first:     `{"title":"Fwd: Groceries!"}`,
+			second:    `{"title":"fwd groceries"}`,
+			wantPairs: 1,
+			wantScore: 1,
+		},
+		{
+			// (0.87 + 0.5) / 2 is just under the default of 0.7.
+			name:      "similar notes below the default threshold",
+			first:     `{"title":"Meeting notes","content":"agenda budget review"}`,
+			second:    `{"title":"Meeting notes 2","content":"agenda budget hiring"}`,
+			wantPairs: 0,
+		},
+		{
+			name:      "similar notes with a lower threshold",
+			first:     `{"title":"Meeting notes","content":"agenda budget review"}`,
+			second:    `{"title":"Meeting notes 2","content":"agenda budget hiring"}`,
+			threshold: "0.6",
+			wantPairs: 1,
+			wantScore: 0.68,
+		},
+		{
+			name:      "unrelated notes",
+			first:     `{"title":"Groceries","content":"milk eggs bread"}`,
+			second:    `{"title":"Trip to Lisbon","content":"flights hotel museums"}`,
+			threshold: "0.1",
+			wantPairs: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlugin(t)
+			firstID := createNote(t, p, tt.first)
+			createNote(t, p, tt.second)
+
+			req := &sdk.APIRequest{Method: "GET", Path: "/notes/duplicates", Query: map[string]string{}}
+			if tt.threshold != "" {
+				req.Query["threshold"] = tt.threshold
+			}
+			resp, err := p.HandleAPI(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != 200 {
+				t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+			}
+
+			var pairs []DuplicatePair
+			if err := json.Unmarshal(parseDataObject(t, resp), &pairs); err != nil {
+				t.Fatalf("failed to parse pairs: %v", err)
+			}
+			if len(pairs) != tt.wantPairs {
+				t.Fatalf("expected %d pairs, got %d: %+v", tt.wantPairs, len(pairs), pairs)
+			}
+			if tt.wantPairs == 0 {
+				return
+			}
+			if pairs[0].Score != tt.wantScore {
+				t.Errorf("expected score %.2f, got %.2f", tt.wantScore, pairs[0].Score)
+			}
+			if pairs[0].Note.ID != firstID {
+				t.Errorf("expected the older note %d to be reported as the original, got %d", firstID, pairs[0].Note.ID)
+			}
+		})
+	}
+}
+
+func TestFindDuplicates_InvalidThreshold(t *testing.T) {
+	p := newTestPlugin(t)
+
+	for _, threshold := range []string{"0", "1.5", "abc"} {
+		resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+			Method: "GET",
+			Path:   "/notes/duplicates",
+			Query:  map[string]string{"threshold": threshold},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.StatusCode != 400 {
+			t.Errorf("threshold %q: expected 400, got %d", threshold, resp.StatusCode)
+		}
+	}
+}
+
+func TestMergeNote_UnionsTagsAndRedirectsBacklinks(t *testing.T) {
+	p := newTestPlugin(t)
+	shopping := createTag(t, p, "shopping")
+	weekly := createTag(t, p, "weekly")
+
+	targetID := createNote(t, p, `{"title":"Groceries","content":"milk eggs"}`)
+	sourceID := createNote(t, p, `{"title":"Grocery list","content":"bread"}`)
+	tagNote(t, p, targetID, shopping)
+	tagNote(t, p, sourceID, shopping, weekly)
+	linkingID := createNote(t, p, `{"title":"Plans","content":"See [[Grocery list]] before Saturday"}`)
+
+	resp := call(t, p, "POST", fmt.Sprintf("/notes/%d/merge-into/%d", sourceID, targetID), "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	var result struct {
+		BacklinksUpdated int64 `json:"backlinks_updated"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &result); err != nil {
+		t.Fatalf("failed to parse merge result: %v", err)
+	}
+	if result.BacklinksUpdated != 1 {
+		t.Errorf("expected 1 backlink updated, got %d", result.BacklinksUpdated)
+	}
+
+	target := getNote(t, p, targetID)
+	if target.Title != "Groceries" || target.Content != "milk eggs\n\nbread" {
+		t.Errorf("expected the target to keep its title and gain the source content, got %q: %q", target.Title, target.Content)
+	}
+	if names := tagNames(t, p, targetID); len(names) != 2 {
+		t.Errorf("expected the tags to be unioned, got %v", names)
+	}
+	if getNote(t, p, sourceID) != nil {
+		t.Error("expected the source note to be deleted")
+	}
+	if linking := getNote(t, p, linkingID); linking.Content != "See [[Groceries]] before Saturday" {
+		t.Errorf("expected the backlink to point at the target, got %q", linking.Content)
+	}
+}
+
+func TestMergeNote_KeepsContainedContentOnce(t *testing.T) {
+	p := newTestPlugin(t)
+	targetID := createNote(t, p, `{"title":"Groceries","content":"milk"}`)
+	sourceID := createNote(t, p, `{"title":"Groceries","content":"milk eggs bread"}`)
+
+	resp := call(t, p, "POST", fmt.Sprintf("/notes/%d/merge-into/%d", sourceID, targetID), "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	if target := getNote(t, p, targetID); target.Content != "milk eggs bread" {
+		t.Errorf("expected the longer copy to be kept as is, got %q", target.Content)
+	}
+}
+
+func TestMergeNote_RollsBackOnFailure(t *testing.T) {
+	p := newTestPlugin(t)
+	shopping := createTag(t, p, "shopping")
+	weekly := createTag(t, p, "weekly")
+
+	targetID := createNote(t, p, `{"title":"Groceries","content":"milk eggs"}`)
+	sourceID := createNote(t, p, `{"title":"Grocery list","content":"bread"}`)
+	tagNote(t, p, targetID, shopping)
+	tagNote(t, p, sourceID, weekly)
+	linkingID := createNote(t, p, `{"title":"Plans","content":"See [[Grocery list]]"}`)
+
+	// Fail the last step of the merge, after every other write was made.
+	if _, err := p.db.Exec(`CREATE TRIGGER refuse_note_delete BEFORE DELETE ON notes
+		BEGIN SELECT RAISE(ABORT, 'delete refused'); END`); err != nil {
+		t.Fatalf("creating trigger: %v", err)
+	}
+
+	_, err := p.HandleAPI(context.Background(), &sdk.APIRequest{
+		Method: "POST",
+		Path:   fmt.Sprintf("/notes/%d/merge-into/%d", sourceID, targetID),
+	})
+	if err == nil || !strings.Contains(err.Error(), "delete refused") {
+		t.Fatalf("expected the merge to fail, got %v", err)
+	}
+
+	target := getNote(t, p, targetID)
+	if target.Content != "milk eggs" {
+		t.Errorf("expected the target content to be rolled back, got %q", target.Content)
+	}
+	if names := tagNames(t, p, targetID); len(names) != 1 || names[0] != "shopping" {
+		t.Errorf("expected the target tags to be rolled back, got %v", names)
+	}
+	if getNote(t, p, sourceID) == nil {
+		t.Error("expected the source note to be kept")
+	}
+	if linking := getNote(t, p, linkingID); linking.Content != "See [[Grocery list]]" {
+		t.Errorf("expected the backlink to be rolled back, got %q", linking.Content)
+	}
+}
+
+func TestMergeNote_Validation(t *testing.T) {
+	p := newTestPlugin(t)
+	id := createNote(t, p, `{"title":"Groceries"}`)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{fmt.Sprintf("/notes/%d/merge-into/%d", id, id), 400},
+		{fmt.Sprintf("/notes/%d/merge-into/abc", id), 400},
+		{fmt.Sprintf("/notes/%d/merge-into/999", id), 404},
+		{fmt.Sprintf("/notes/999/merge-into/%d", id), 404},
+	}
+	for _, tt := range tests {
+		resp := call(t, p, "POST", tt.path, "")
+		if resp.StatusCode != tt.want {
+			t.Errorf("POST %s: expected %d, got %d", tt.path, tt.want, resp.StatusCode)
+		}
+	}
+}
+
+// --- Notebook tests ---
+
+func TestNotebooks_CreateListRenameDelete(t *testing.T) {
+	p := newTestPlugin(t)
+
+	work := createNotebook(t, p, "  Work  ")
+	home := createNotebook(t, p, "Home")
+	createNote(t, p, fmt.Sprintf(`{"title":"Standup","notebook_id":%d}`, work))
+
+	resp := call(t, p, "GET", "/notebooks", "")
+	var notebooks []Notebook
+	if err := json.Unmarshal(parseDataObject(t, resp), &notebooks); err != nil {
+		t.Fatalf("failed to parse notebooks: %v", err)
+	}
+	if len(notebooks) != 2 || notebooks[0].Name != "Home" || notebooks[1].Name != "Work" {
+		t.Fatalf("expected Home and Work by name with the name trimmed, got %+v", notebooks)
+	}
+	if notebooks[0].NoteCount != 0 || notebooks[1].NoteCount != 1 {
+		t.Errorf("expected note counts 0 and 1, got %d and %d", notebooks[0].NoteCount, notebooks[1].NoteCount)
+	}
+
+	resp = call(t, p, "PUT", fmt.Sprintf("/notebooks/%d", home), `{"name":"Personal"}`)
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 renaming, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	var name string
+	if err := p.db.QueryRow("SELECT name FROM notebooks WHERE id = ?", home).Scan(&name); err != nil || name != "Personal" {
+		t.Errorf("expected the notebook renamed to Personal, got %q (%v)", name, err)
+	}
+
+	resp = call(t, p, "DELETE", fmt.Sprintf("/notebooks/%d", home), "")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 deleting, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	resp = call(t, p, "DELETE", fmt.Sprintf("/notebooks/%d", home), "")
+	if code, _ := parseErrorResponse(t, resp); resp.StatusCode != 404 || code != "NOT_FOUND" {
+		t.Errorf("expected 404 deleting a deleted notebook, got %d (%s)", resp.StatusCode, code)
+	}
+}
+
+func TestNotebooks_Validation(t *testing.T) {
+	p := newTestPlugin(t)
+	work := createNotebook(t, p, "Work")
+	home := createNotebook(t, p, "Home")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"empty name", "POST", "/notebooks", `{"name":"   "}`, 400, "VALIDATION_ERROR"},
+		{"long name", "POST", "/notebooks", fmt.Sprintf(`{"name":%q}`, strings.Repeat("n", maxNotebookNameLength+1)), 400, "VALIDATION_ERROR"},
+		{"invalid JSON", "POST", "/notebooks", `{`, 400, "VALIDATION_ERROR"},
+		{"duplicate name", "POST", "/notebooks", `{"name":"Work"}`, 409, "CONFLICT"},
+		{"rename to taken name", "PUT", fmt.Sprintf("/notebooks/%d", home), `{"name":"Work"}`, 409, "CONFLICT"},
+		{"rename unknown", "PUT", "/notebooks/9999", `{"name":"Other"}`, 404, "NOT_FOUND"},
+		{"rename empty", "PUT", fmt.Sprintf("/notebooks/%d", work), `{"name":""}`, 400, "VALIDATION_ERROR"},
+		{"missing ID", "DELETE", "/notebooks/", "", 400, "VALIDATION_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := call(t, p, tt.method, tt.path, tt.body)
+			code, _ := parseErrorResponse(t, resp)
+			if resp.StatusCode != tt.status || code != tt.code {
+				t.Errorf("expected %d %s, got %d %s", tt.status, tt.code, resp.StatusCode, code)
+			}
+		})
+	}
+}
+
+func TestNotebooks_DeleteKeepsNotes(t *testing.T) {
+	p := newTestPlugin(t)
+	work := createNotebook(t, p, "Work")
+	noteID := createNote(t, p, fmt.Sprintf(`{"title":"Standup","notebook_id":%d}`, work))
+
+	call(t, p, "DELETE", fmt.Sprintf("/notebooks/%d", work), "")
+
+	note := getNote(t, p, noteID)
+	if note == nil {
+		t.Fatal("expected the note to survive its notebook")
+	}
+	if note.NotebookID != nil {
+		t.Errorf("expected the note outside any notebook, got %d", *note.NotebookID)
+	}
+}
+
+func TestMoveNote_BetweenNotebooks(t *testing.T) {
+	p := newTestPlugin(t)
+	work := createNotebook(t, p, "Work")
+	home := createNotebook(t, p, "Home")
+	noteID := createNote(t, p, fmt.Sprintf(`{"title":"Groceries","notebook_id":%d}`, work))
+	loose := createNote(t, p, `{"title":"Loose"}`)
+
+	resp := call(t, p, "PUT", fmt.Sprintf("/notes/%d", noteID), fmt.Sprintf(`{"title":"Groceries","notebook_id":%d}`, home))
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 moving note, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	if note := getNote(t, p, noteID); note.NotebookID == nil || *note.NotebookID != home {
+		t.Fatalf("expected the note in Home, got %v", note.NotebookID)
+	}
+	if ids := listNoteIDs(t, p, map[string]string{"notebook": fmt.Sprint(home)}); len(ids) != 1 || ids[0] != noteID {
+		t.Errorf("expected only the moved note in Home, got %v", ids)
+	}
+	if ids := listNoteIDs(t, p, map[string]string{"notebook": fmt.Sprint(work)}); len(ids) != 0 {
+		t.Errorf("expected Work to be empty, got %v", ids)
+	}
+
+	// Omitting notebook_id keeps the notebook.
+	call(t, p, "PUT", fmt.Sprintf("/notes/%d", noteID), `{"title":"Groceries for the week"}`)
+	if note := getNote(t, p, noteID); note.NotebookID == nil || *note.NotebookID != home {
+		t.Errorf("expected an update without notebook_id to keep Home, got %v", note.NotebookID)
+	}
+
+	// Null takes the note out of its notebook.
+	call(t, p, "PUT", fmt.Sprintf("/notes/%d", noteID), `{"title":"Groceries","notebook_id":null}`)
+	if note := getNote(t, p, noteID); note.NotebookID != nil {
+		t.Errorf("expected the note outside any notebook, got %d", *note.NotebookID)
+	}
+	ids := listNoteIDs(t, p, map[string]string{"notebook": notebookNone})
+	if len(ids) != 2 {
+		t.Errorf("expected both notes outside notebooks, got %v", ids)
+	}
+	for _, id := range ids {
+		if id != noteID && id != loose {
+			t.Errorf("unexpected note %d outside notebooks", id)
+		}
+	}
+}
+
+func TestMoveNote_Validation(t *testing.T) {
+	p := newTestPlugin(t)
+	work := createNotebook(t, p, "Work")
+	noteID := createNote(t, p, fmt.Sprintf(`{"title":"Standup","notebook_id":%d}`, work))
+
+	resp := call(t, p, "PUT", fmt.Sprintf("/notes/%d", noteID), `{"title":"Standup","notebook_id":9999}`)
+	if code, _ := parseErrorResponse(t, resp); resp.StatusCode != 400 || code != "VALIDATION_ERROR" {
+		t.Errorf("expected 400 moving to an unknown notebook, got %d (%s)", resp.StatusCode, code)
+	}
+	if note := getNote(t, p, noteID); note.NotebookID == nil || *note.NotebookID != work {
+		t.Errorf("expected a refused move to keep the note in Work, got %v", note.NotebookID)
+	}
+
+	resp = call(t, p, "PUT", fmt.Sprintf("/notes/%d", noteID), `{"title":"Standup","notebook_id":"work"}`)
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for a notebook_id that is not an ID, got %d", resp.StatusCode)
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/notes", Query: map[string]string{"notebook": "work"}})
+	if err != nil || resp.StatusCode != 400 {
+		t.Errorf("expected 400 filtering by a notebook that is not an ID, got %v %v", resp, err)
+	}
+}
+
+// --- Bulk tests ---
+
+// bulkOutcome is the body of a POST /notes/bulk response.
+type bulkOutcome struct {
+	Action    string           `json:"action"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkNoteResult `json:"results"`
+}
+
+// postBulk is a test helper that sends a bulk request expected to succeed
+// and returns its outcome with the raw response.
+func postBulk(t *testing.T, p *QuickNotesPlugin, body string) (bulkOutcome, *sdk.APIResponse) {
+	t.Helper()
+
+	resp := call(t, p, "POST", "/notes/bulk", body)
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 from bulk, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	var outcome bulkOutcome
+	if err := json.Unmarshal(parseDataObject(t, resp), &outcome); err != nil {
+		t.Fatalf("failed to parse bulk outcome: %v", err)
+	}
+	return outcome, resp
+}
+
+func TestBulkAction_DeleteReportsMissingNotes(t *testing.T) {
+	p := newTestPlugin(t)
+	first := createNote(t, p, `{"title":"First"}`)
+	second := createNote(t, p, `{"title":"Second"}`)
+	kept := createNote(t, p, `{"title":"Kept"}`)
+
+	outcome, resp := postBulk(t, p, fmt.Sprintf(`{"action":"delete","ids":[%d,9999,%d]}`, first, second))
+	if outcome.Succeeded != 2 || outcome.Failed != 1 || len(outcome.Results) != 3 {
+		t.Fatalf("expected 2 deleted and 1 failed, got %+v", outcome)
+	}
+	if r := outcome.Results[1]; r.Index != 1 || r.ID != 9999 || r.Status != bulkFailed || r.Error != "note not found" {
+		t.Errorf("expected the missing note reported at index 1, got %+v", r)
+	}
+	for _, i := range []int{0, 2} {
+		if outcome.Results[i].Status != bulkDeleted {
+			t.Errorf("expected result %d deleted, got %+v", i, outcome.Results[i])
+		}
+	}
+
+	if getNote(t, p, first) != nil || getNote(t, p, second) != nil {
+		t.Error("expected both notes deleted despite the missing one")
+	}
+	if getNote(t, p, kept) == nil {
+		t.Error("expected the note left out of the request to be kept")
+	}
+	if resp.Deleted == nil || resp.Deleted.Kind != deletedNoteKind {
+		t.Errorf("expected the deleted notes attached for undo, got %+v", resp.Deleted)
+	}
+}
+
+func TestBulkAction_PinAndUnpin(t *testing.T) {
+	p := newTestPlugin(t)
+	first := createNote(t, p, `{"title":"First"}`)
+	second := createNote(t, p, `{"title":"Second"}`)
+
+	outcome, _ := postBulk(t, p, fmt.Sprintf(`{"action":"pin","ids":[%d,%d,9999]}`, first, second))
+	if outcome.Succeeded != 2 || outcome.Failed != 1 {
+		t.Fatalf("expected 2 pinned and 1 failed, got %+v", outcome)
+	}
+	if !getNote(t, p, first).Pinned || !getNote(t, p, second).Pinned {
+		t.Fatal("expected both notes pinned")
+	}
+
+	postBulk(t, p, fmt.Sprintf(`{"action":"unpin","ids":[%d]}`, first))
+	if getNote(t, p, first).Pinned || !getNote(t, p, second).Pinned {
+		t.Error("expected only the first note unpinned")
+	}
+}
+
+func TestBulkAction_MoveToNotebook(t *testing.T) {
+	p := newTestPlugin(t)
+	work := createNotebook(t, p, "Work")
+	first := createNote(t, p, `{"title":"First"}`)
+	second := createNote(t, p, `{"title":"Second"}`)
+
+	outcome, _ := postBulk(t, p, fmt.Sprintf(`{"action":"move","ids":[%d,9999,%d],"notebook_id":%d}`, first, second, work))
+	if outcome.Succeeded != 2 || outcome.Failed != 1 || outcome.Results[1].Status != bulkFailed {
+		t.Fatalf("expected 2 moved and the missing note failed, got %+v", outcome)
+	}
+	if ids := listNoteIDs(t, p, map[string]string{"notebook": fmt.Sprint(work)}); len(ids) != 2 {
+		t.Errorf("expected both notes in Work, got %v", ids)
+	}
+
+	postBulk(t, p, fmt.Sprintf(`{"action":"move","ids":[%d],"notebook_id":null}`, first))
+	if note := getNote(t, p, first); note.NotebookID != nil {
+		t.Errorf("expected a move to null to take the note out of Work, got %d", *note.NotebookID)
+	}
+	if note := getNote(t, p, second); note.NotebookID == nil || *note.NotebookID != work {
+		t.Errorf("expected the other note to stay in Work, got %v", note.NotebookID)
+	}
+}
+
+func TestBulkAction_TagAddsToExistingTags(t *testing.T) {
+	p := newTestPlugin(t)
+	work := createTag(t, p, "work")
+	urgent := createTag(t, p, "urgent")
+	tagged := createNote(t, p, fmt.Sprintf(`{"title":"Tagged","tag_ids":[%d]}`, work))
+	plain := createNote(t, p, `{"title":"Plain"}`)
+
+	outcome, _ := postBulk(t, p, fmt.Sprintf(`{"action":"tag","ids":[%d,%d,9999],"tag_ids":[%d,%d]}`, tagged, plain, work, urgent))
+	if outcome.Succeeded != 2 || outcome.Failed != 1 {
+		t.Fatalf("expected 2 tagged and 1 failed, got %+v", outcome)
+	}
+	for _, id := range []int64{tagged, plain} {
+		names := tagNames(t, p, id)
+		if strings.Join(names, ",") != "urgent,work" {
+			t.Errorf("expected note %d tagged urgent and work once each, got %v", id, names)
+		}
+	}
+}
+
+func TestBulkAction_Validation(t *testing.T) {
+	p := newTestPlugin(t)
+	noteID := createNote(t, p, `{"title":"Note"}`)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no ids", `{"action":"pin","ids":[]}`},
+		{"unknown action", fmt.Sprintf(`{"action":"archive","ids":[%d]}`, noteID)},
+		{"move without notebook", fmt.Sprintf(`{"action":"move","ids":[%d]}`, noteID)},
+		{"move to unknown notebook", fmt.Sprintf(`{"action":"move","ids":[%d],"notebook_id":9999}`, noteID)},
+		{"tag without tags", fmt.Sprintf(`{"action":"tag","ids":[%d]}`, noteID)},
+		{"tag with unknown tag", fmt.Sprintf(`{"action":"tag","ids":[%d],"tag_ids":[9999]}`, noteID)},
+		{"invalid JSON", `{"action":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := call(t, p, "POST", "/notes/bulk", tt.body)
+			if code, _ := parseErrorResponse(t, resp); resp.StatusCode != 400 || code != "VALIDATION_ERROR" {
+				t.Errorf("expected 400 VALIDATION_ERROR, got %d %s", resp.StatusCode, code)
+			}
+		})
+	}
+	if note := getNote(t, p, noteID); note.Pinned || note.NotebookID != nil || len(tagNames(t, p, noteID)) != 0 {
+		t.Errorf("expected refused requests to leave the note alone, got %+v", note)
+	}
+}
+
+func TestBulkImport_ReportsInvalidItems(t *testing.T) {
+	p := newTestPlugin(t)
+	existing := createNote(t, p, `{"title":"Groceries","content":"milk"}`)
+
+	outcome, _ := postBulk(t, p, `{"upsert":true,"notes":[
+		{"title":"Groceries","content":"milk, eggs","tags":["home"]},
+		{"title":"  "},
+		{"title":"Dated","created_at":"yesterday"},
+		{"title":"Trip","content":"pack","tags":["travel"," "],"pinned":true,"created_at":"2026-03-01T10:00:00Z"}
+	]}`)
+	if outcome.Created != 1 || outcome.Updated != 1 || outcome.Failed != 2 {
+		t.Fatalf("expected 1 created, 1 updated and 2 failed, got %+v", outcome)
+	}
+	if r := outcome.Results[0]; r.Status != bulkUpdated || r.ID != existing {
+		t.Errorf("expected the first item to update note %d, got %+v", existing, r)
+	}
+	if r := outcome.Results[1]; r.Status != bulkFailed || r.Error != "title is required" {
+		t.Errorf("expected a blank title to fail, got %+v", r)
+	}
+	if r := outcome.Results[2]; r.Status != bulkFailed || !strings.HasPrefix(r.Error, "created_at: ") {
+		t.Errorf("expected an invalid timestamp to fail, got %+v", r)
+	}
+
+	if note := getNote(t, p, existing); note.Content != "milk, eggs" || strings.Join(tagNames(t, p, existing), ",") != "home" {
+		t.Errorf("expected the upsert to update content and add tags, got %+v", note)
+	}
+	trip := getNote(t, p, outcome.Results[3].ID)
+	if trip == nil || !trip.Pinned || trip.CreatedAt != "2026-03-01 10:00:00" || trip.UpdatedAt != trip.CreatedAt {
+		t.Fatalf("expected the trip note pinned with its creation time, got %+v", trip)
+	}
+	if strings.Join(tagNames(t, p, trip.ID), ",") != "travel" {
+		t.Errorf("expected blank tags skipped, got %v", tagNames(t, p, trip.ID))
+	}
+}
+
+func TestBulkImport_CreatesNotes(t *testing.T) {
+	p := newTestPlugin(t)
+	existing := createNote(t, p, `{"title":"Groceries","content":"milk"}`)
+
+	outcome, _ := postBulk(t, p, `{"notes":[
+		{"title":"Groceries","content":"eggs"},
+		{"title":"Trip","content":"pack","tags":["travel","home"],"created_at":"2026-03-01 10:00:00","updated_at":"2026-03-02T09:30:00+01:00"}
+	]}`)
+	if outcome.Created != 2 || outcome.Updated != 0 || outcome.Failed != 0 {
+		t.Fatalf("expected 2 created, got %+v", outcome)
+	}
+	for i, r := range outcome.Results {
+		if r.Index != i || r.Status != bulkCreated || r.ID == 0 {
+			t.Errorf("expected item %d to be created with an ID, got %+v", i, r)
+		}
+	}
+
+	// Without upsert, a taken title makes a new note.
+	if outcome.Results[0].ID == existing {
+		t.Error("expected a new note for a taken title without upsert")
+	}
+	if note := getNote(t, p, existing); note.Content != "milk" {
+		t.Errorf("expected the existing note unchanged, got %q", note.Content)
+	}
+
+	trip := getNote(t, p, outcome.Results[1].ID)
+	if trip == nil || trip.CreatedAt != "2026-03-01 10:00:00" || trip.UpdatedAt != "2026-03-02 08:30:00" {
+		t.Fatalf("expected the trip note with its timestamps in UTC, got %+v", trip)
+	}
+	if names := tagNames(t, p, trip.ID); strings.Join(names, ",") != "home,travel" {
+		t.Errorf("expected the trip note tagged home and travel, got %v", names)
+	}
+
+	// Tags are matched by name, so importing one again reuses it.
+	postBulk(t, p, `{"notes":[{"title":"Packing list","tags":["Travel"]}]}`)
+	var tags int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM tags").Scan(&tags); err != nil {
+		t.Fatalf("counting tags: %v", err)
+	}
+	if tags != 2 {
+		t.Errorf("expected the travel tag reused, got %d tags", tags)
+	}
+}
+
+// --- Markdown export and import tests ---
+
+// markdownOutcome is the body of a POST /notes/import response.
+type markdownOutcome struct {
+	Created int                    `json:"created"`
+	Updated int                    `json:"updated"`
+	Failed  int                    `json:"failed"`
+	Results []MarkdownImportResult `json:"results"`
+}
+
+// postMarkdown is a test helper that sends body to POST /notes/import with
+// the given query.
+func postMarkdown(t *testing.T, p *QuickNotesPlugin, body []byte, query map[string]string) *sdk.APIResponse {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "POST", Path: "/notes/import", Body: body, Query: query})
+	if err != nil {
+		t.Fatalf("importing markdown: unexpected error: %v", err)
+	}
+	return resp
+}
+
+// parseMarkdownOutcome is a test helper that reads a successful import's outcome.
+func parseMarkdownOutcome(t *testing.T, resp *sdk.APIResponse) markdownOutcome {
+	t.Helper()
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200 importing markdown, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	var outcome markdownOutcome
+	if err := json.Unmarshal(parseDataObject(t, resp), &outcome); err != nil {
+		t.Fatalf("failed to parse import outcome: %v", err)
+	}
+	return outcome
+}
+
+// markdownZip is a test helper that packs files, by path, into a zip.
+func markdownZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, name := range names {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// noteByTitle is a test helper that finds a note by its title.
+func noteByTitle(t *testing.T, p *QuickNotesPlugin, title string) *Note {
+	t.Helper()
+
+	notes, err := p.queryNotes(noteFilter{})
+	if err != nil {
+		t.Fatalf("querying notes: %v", err)
+	}
+	for i := range notes {
+		if notes[i].Title == title {
+			return &notes[i]
+		}
+	}
+	return nil
+}
+
+// notebookName is a test helper that returns the name of a note's notebook,
+// or "" for a note outside any notebook.
+func notebookName(t *testing.T, p *QuickNotesPlugin, n *Note) string {
+	t.Helper()
+
+	if n.NotebookID == nil {
+		return ""
+	}
+	var name string
+	if err := p.db.QueryRow("SELECT name FROM notebooks WHERE id = ?", *n.NotebookID).Scan(&name); err != nil {
+		t.Fatalf("reading notebook %d: %v", *n.NotebookID, err)
+	}
+	return name
+}
+
+func TestMarkdown_ExportImportRoundTrip(t *testing.T) {
+	source := newTestPlugin(t)
+	work := createNotebook(t, source, "Work")
+	urgent := createTag(t, source, "urgent")
+	meeting := createTag(t, source, "meeting")
+	standup := createNote(t, source, fmt.Sprintf(`{"title":"Standup: Monday","content":"# Agenda\n\n- blockers\n---\nnot frontmatter\n","tag_ids":[%d,%d],"notebook_id":%d}`, urgent, meeting, work))
+	call(t, source, "PUT", fmt.Sprintf("/notes/%d/pin", standup), "")
+	createNote(t, source, `{"title":"Loose","content":"no notebook"}`)
+
+	resp := call(t, source, "GET", "/notes/export", "")
+	if resp.StatusCode != 200 || resp.ContentType != "application/zip" {
+		t.Fatalf("expected a zip export, got %d %s", resp.StatusCode, resp.ContentType)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(resp.Body), int64(len(resp.Body)))
+	if err != nil {
+		t.Fatalf("expected a valid zip: %v", err)
+	}
+	entries := make(map[string]string)
+	for _, entry := range archive.File {
+		reader, err := entry.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		entries[entry.Name] = string(content)
+	}
+	exported, ok := entries["Work/Standup- Monday.md"]
+	if !ok || len(entries) != 2 {
+		t.Fatalf("expected Work/Standup- Monday.md and Loose.md, got %v", entries)
+	}
+	if _, ok := entries["Loose.md"]; !ok {
+		t.Errorf("expected the note outside notebooks at the top level, got %v", entries)
+	}
+	for _, want := range []string{"---\ntitle: 'Standup: Monday'\n", "tags:\n  - meeting\n  - urgent\n", "pinned: true\n", "created: ", "updated: ", "---\n# Agenda\n"} {
+		if !strings.Contains(exported, want) {
+			t.Errorf("expected the export to contain %q, got:\n%s", want, exported)
+		}
+	}
+
+	original := getNote(t, source, standup)
+	target := newTestPlugin(t)
+	outcome := parseMarkdownOutcome(t, postMarkdown(t, target, resp.Body, nil))
+	if outcome.Created != 2 || outcome.Failed != 0 {
+		t.Fatalf("expected both notes created, got %+v", outcome)
+	}
+
+	imported := noteByTitle(t, target, "Standup: Monday")
+	if imported == nil {
+		t.Fatal("expected the note imported under its frontmatter title")
+	}
+	if imported.Content != original.Content {
+		t.Errorf("expected content %q, got %q", original.Content, imported.Content)
+	}
+	if !imported.Pinned || imported.CreatedAt != original.CreatedAt || imported.UpdatedAt != original.UpdatedAt {
+		t.Errorf("expected pin and timestamps kept, got %+v, want %+v", imported, original)
+	}
+	if got := strings.Join(tagNames(t, target, imported.ID), ","); got != "meeting,urgent" {
+		t.Errorf("expected tags meeting and urgent, got %s", got)
+	}
+	if got := notebookName(t, target, imported); got != "Work" {
+		t.Errorf("expected the note filed in Work, got %q", got)
+	}
+	if loose := noteByTitle(t, target, "Loose"); loose == nil || loose.NotebookID != nil {
+		t.Errorf("expected Loose imported outside any notebook, got %+v", loose)
+	}
+
+	// Importing the export again with upsert updates the notes in place.
+	outcome = parseMarkdownOutcome(t, postMarkdown(t, target, resp.Body, map[string]string{"upsert": "true"}))
+	if outcome.Created != 0 || outcome.Updated != 2 {
+		t.Errorf("expected an upsert to update both notes, got %+v", outcome)
+	}
+}
+
+func TestMarkdown_ImportReportsMalformedFiles(t *testing.T) {
+	p := newTestPlugin(t)
+
+	body := markdownZip(t, map[string]string{
+		"Broken.md":           "---\ntitle: [unclosed\n---\nbody\n",
+		"Binary.md":           "\xff\xfe\x00",
+		"Unclosed.md":         "---\ntitle: never closed\nbody\n",
+		"Obsidian/Tagged.md":  "---\ntags: \"#home, errands\"\ncreated_at: 2026-03-01\n---\nmilk\n",
+		".obsidian/config.md": "hidden",
+		"Bad date.md":         "---\ncreated: tomorrow\n---\n",
+		"picture.png":         "not markdown",
+		strings.Repeat("n", maxNotebookNameLength+1) + "/Deep.md": "too long a folder",
+	})
+	outcome := parseMarkdownOutcome(t, postMarkdown(t, p, body, nil))
+	if outcome.Created != 2 || outcome.Failed != 4 || len(outcome.Results) != 6 {
+		t.Fatalf("expected 2 created and 4 failed, got %+v", outcome)
+	}
+
+	failures := make(map[string]string)
+	for _, result := range outcome.Results {
+		if result.Status == bulkFailed {
+			failures[result.File] = result.Error
+		}
+	}
+	for file, want := range map[string]string{
+		"Broken.md":   "invalid frontmatter",
+		"Binary.md":   "file is not UTF-8 text",
+		"Bad date.md": "created_at: invalid timestamp",
+		strings.Repeat("n", maxNotebookNameLength+1) + "/Deep.md": "folder names a notebook longer than",
+	} {
+		if !strings.HasPrefix(failures[file], want) {
+			t.Errorf("expected %s to fail with %q, got %q", file, want, failures[file])
+		}
+	}
+
+	// Frontmatter that is never closed is part of the body.
+	if unclosed := noteByTitle(t, p, "Unclosed"); unclosed == nil || !strings.HasPrefix(unclosed.Content, "---\ntitle: never closed") {
+		t.Errorf("expected an unclosed frontmatter kept as content, got %+v", unclosed)
+	}
+	tagged := noteByTitle(t, p, "Tagged")
+	if tagged == nil {
+		t.Fatal("expected Tagged imported under its file name")
+	}
+	if got := strings.Join(tagNames(t, p, tagged.ID), ","); got != "errands,home" {
+		t.Errorf("expected tags from a comma separated string, got %s", got)
+	}
+	if tagged.CreatedAt != "2026-03-01 00:00:00" || notebookName(t, p, tagged) != "Obsidian" {
+		t.Errorf("expected the created_at alias and the folder notebook, got %+v", tagged)
+	}
+}
+
+func TestMarkdown_ImportRejectsUnreadableRequests(t *testing.T) {
+	p := newTestPlugin(t)
+
+	tests := []struct {
+		name string
+		body []byte
+		want string
+	}{
+		{"empty body", nil, "the import has no markdown files"},
+		{"corrupt zip", append([]byte("PK\x03\x04"), "truncated"...), "invalid zip"},
+		{"zip without markdown", markdownZip(t, map[string]string{"notes.txt": "plain"}), "the import has no markdown files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := postMarkdown(t, p, tt.body, nil)
+			code, message := parseErrorResponse(t, resp)
+			if resp.StatusCode != 400 || code != "VALIDATION_ERROR" || !strings.HasPrefix(message, tt.want) {
+				t.Errorf("expected 400 %q, got %d %s %q", tt.want, resp.StatusCode, code, message)
+			}
+		})
+	}
+	if notes, _ := p.queryNotes(noteFilter{}); len(notes) != 0 {
+		t.Errorf("expected nothing imported, got %d notes", len(notes))
+	}
+}
+
+func TestMarkdown_ImportSingleFile(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := postMarkdown(t, p, []byte("\ufeff# Heading\r\nline\r\n"), map[string]string{"filename": "folder/Plain note.md"})
+	outcome := parseMarkdownOutcome(t, resp)
+	if outcome.Created != 1 || outcome.Results[0].File != "Plain note.md" {
+		t.Fatalf("expected one note from the file name, got %+v", outcome)
+	}
+	note := noteByTitle(t, p, "Plain note")
+	if note == nil || note.Content != "# Heading\nline\n" || note.NotebookID != nil {
+		t.Errorf("expected the BOM and CRLFs dropped and no notebook, got %+v", note)
+	}
+}
+
+// --- Concurrent update tests ---
+
+// storedUpdatedAt is the updated_at test notes are set to before an update,
+// so preconditions compare against a known value.
+const storedUpdatedAt = "2026-01-01 10:00:00"
+
+// putNote is a test helper that updates a note with the given body and headers.
+func putNote(t *testing.T, p *QuickNotesPlugin, id int64, body string, headers map[string]string) *sdk.APIResponse {
+	t.Helper()
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "PUT", Path: fmt.Sprintf("/notes/%d", id), Body: []byte(body), Headers: headers})
+	if err != nil {
+		t.Fatalf("updating note %d: unexpected error: %v", id, err)
+	}
+	return resp
+}
+
+// setUpdatedAt is a test helper that stores updatedAt as a note's updated_at.
+func setUpdatedAt(t *testing.T, p *QuickNotesPlugin, id int64, updatedAt string) {
+	t.Helper()
+
+	if _, err := p.db.Exec("UPDATE notes SET updated_at = ? WHERE id = ?", updatedAt, id); err != nil {
+		t.Fatalf("setting updated_at: %v", err)
+	}
+}
+
+// parseConflict is a test helper that reads a 409 response and returns the
+// stored note it carries.
+func parseConflict(t *testing.T, resp *sdk.APIResponse) Note {
+	t.Helper()
+
+	if resp.StatusCode != 409 {
+		t.Fatalf("expected 409, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	var body struct {
+		Error   sdk.ErrorBody `json:"error"`
+		Current *Note         `json:"current"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("failed to parse conflict body: %v", err)
+	}
+	if body.Error.Code != "CONFLICT" || body.Current == nil {
+		t.Fatalf("expected a CONFLICT error with the current note, got %s", string(resp.Body))
+	}
+	return *body.Current
+}
+
+func TestUpdateNote_ExpectedUpdatedAtMustMatchExactly(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		status   int
+	}{
+		{"stored format", storedUpdatedAt, 200},
+		{"RFC 3339", "2026-01-01T10:00:00Z", 200},
+		{"RFC 3339 with offset", "2026-01-01T11:00:00+01:00", 200},
+		{"earlier", "2026-01-01 09:59:59", 409},
+		{"later", "2026-01-01 10:00:01", 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlugin(t)
+			id := createNote(t, p, `{"title":"Draft","content":"stored"}`)
+			setUpdatedAt(t, p, id, storedUpdatedAt)
+
+			resp := putNote(t, p, id, fmt.Sprintf(`{"title":"Draft","content":"mine","expected_updated_at":%q}`, tt.expected), nil)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d. Body: %s", tt.status, resp.StatusCode, string(resp.Body))
+			}
+			want := "mine"
+			if tt.status == 409 {
+				want = "stored"
+			}
+			if note := getNote(t, p, id); note.Content != want {
+				t.Errorf("expected content %q, got %q", want, note.Content)
+			}
+		})
+	}
+}
+
+func TestUpdateNote_IfUnmodifiedSinceAllowsNotLater(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"same second", "Thu, 01 Jan 2026 10:00:00 GMT", 200},
+		{"later", "Thu, 01 Jan 2026 10:00:01 GMT", 200},
+		{"earlier", "Thu, 01 Jan 2026 09:59:59 GMT", 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPlugin(t)
+			id := createNote(t, p, `{"title":"Draft","content":"stored"}`)
+			setUpdatedAt(t, p, id, storedUpdatedAt)
+
+			resp := putNote(t, p, id, `{"title":"Draft","content":"mine"}`, map[string]string{"If-Unmodified-Since": tt.header})
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %d. Body: %s", tt.status, resp.StatusCode, string(resp.Body))
+			}
+		})
+	}
+}
+
+func TestUpdateNote_ExpectedUpdatedAtTakesPrecedence(t *testing.T) {
+	p := newTestPlugin(t)
+	id := createNote(t, p, `{"title":"Draft"}`)
+	setUpdatedAt(t, p, id, storedUpdatedAt)
+
+	// The header alone would allow the update; the stale field refuses it.
+	resp := putNote(t, p, id, `{"title":"Draft","expected_updated_at":"2026-01-01 09:00:00"}`,
+		map[string]string{"If-Unmodified-Since": "Thu, 01 Jan 2026 11:00:00 GMT"})
+	parseConflict(t, resp)
+}
+
+func TestUpdateNote_ConflictReturnsCurrentNote(t *testing.T) {
+	p := newTestPlugin(t)
+	work := createNotebook(t, p, "Work")
+	tag := createTag(t, p, "urgent")
+	id := createNote(t, p, fmt.Sprintf(`{"title":"Plan","content":"theirs","tag_ids":[%d],"notebook_id":%d}`, tag, work))
+	setUpdatedAt(t, p, id, storedUpdatedAt)
+
+	resp := putNote(t, p, id, `{"title":"Plan","content":"mine","tag_ids":[],"notebook_id":null,"expected_updated_at":"2025-12-31 23:00:00"}`, nil)
+	current := parseConflict(t, resp)
+	if current.ID != id || current.Content != "theirs" || current.UpdatedAt != storedUpdatedAt {
+		t.Errorf("expected the stored note as current, got %+v", current)
+	}
+	if len(current.Tags) != 1 || current.Tags[0].Name != "urgent" || current.NotebookID == nil || *current.NotebookID != work {
+		t.Errorf("expected current with its tags and notebook, got %+v", current)
+	}
+
+	// Nothing of the refused update is written.
+	note := getNote(t, p, id)
+	if note.Content != "theirs" || len(tagNames(t, p, id)) != 1 || note.NotebookID == nil || note.UpdatedAt != storedUpdatedAt {
+		t.Errorf("expected the refused update to change nothing, got %+v", note)
+	}
+}
+
+func TestUpdateNote_InvalidPrecondition(t *testing.T) {
+	p := newTestPlugin(t)
+	id := createNote(t, p, `{"title":"Draft"}`)
+
+	resp := putNote(t, p, id, `{"title":"Draft","expected_updated_at":"yesterday"}`, nil)
+	if code, _ := parseErrorResponse(t, resp); resp.StatusCode != 400 || code != "VALIDATION_ERROR" {
+		t.Errorf("expected 400 for an invalid expected_updated_at, got %d %s", resp.StatusCode, code)
+	}
+	resp = putNote(t, p, id, `{"title":"Draft"}`, map[string]string{"If-Unmodified-Since": "yesterday"})
+	if code, _ := parseErrorResponse(t, resp); resp.StatusCode != 400 || code != "VALIDATION_ERROR" {
+		t.Errorf("expected 400 for an invalid If-Unmodified-Since, got %d %s", resp.StatusCode, code)
+	}
+}
+
+func TestUpdateNote_SameSecondUpdatesStayDistinct(t *testing.T) {
+	p := newTestPlugin(t)
+	id := createNote(t, p, `{"title":"Draft"}`)
+
+	// A stored updated_at ahead of the clock stands for an update made
+	// within the same second, or by a host whose clock runs ahead.
+	ahead := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	setUpdatedAt(t, p, id, ahead.Format(noteTimeLayout))
+
+	resp := putNote(t, p, id, fmt.Sprintf(`{"title":"Draft","content":"first","expected_updated_at":%q}`, ahead.Format(noteTimeLayout)), nil)
+	var saved struct {
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := json.Unmarshal(parseDataObject(t, resp), &saved); err != nil {
+		t.Fatalf("failed to parse update: %v", err)
+	}
+	if want := ahead.Add(time.Second).Format(noteTimeLayout); saved.UpdatedAt != want {
+		t.Fatalf("expected updated_at bumped to %s, got %s", want, saved.UpdatedAt)
+	}
+
+	// A client still holding the first value is now refused.
+	resp = putNote(t, p, id, fmt.Sprintf(`{"title":"Draft","content":"stale","expected_updated_at":%q}`, ahead.Format(noteTimeLayout)), nil)
+	if current := parseConflict(t, resp); current.Content != "first" || current.UpdatedAt != saved.UpdatedAt {
+		t.Errorf("expected the first update as current, got %+v", current)
+	}
+}
+
+func TestNextUpdatedAt(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 500_000_000, time.UTC)
+	tests := []struct {
+		name     string
+		previous string
+		want     string
+	}{
+		{"earlier previous", "2026-01-01 09:59:59", "2026-01-01 10:00:00"},
+		{"same second", "2026-01-01 10:00:00", "2026-01-01 10:00:01"},
+		{"previous ahead", "2026-01-01 10:05:00", "2026-01-01 10:05:01"},
+		{"unparseable previous", "9999", "2026-01-01 10:00:00"},
+	}
+	for _, tt := range tests {
+		if got := nextUpdatedAt(now, tt.previous); got != tt.want {
+			t.Errorf("%s: nextUpdatedAt(%s) = %s, want %s", tt.name, tt.previous, got, tt.want)
+		}
+	}
+
+	local := now.In(time.FixedZone("CET", 3600))
+	if got := nextUpdatedAt(local, ""); got != "2026-01-01 10:00:00" {
+		t.Errorf("expected updated_at in UTC, got %s", got)
+	}
+}
+
+// --- Changes feed tests ---
+
+func TestListNoteChanges_PagesWithCursor(t *testing.T) {
+	p := newTestPlugin(t)
+	first := createNote(t, p, `{"title":"First"}`)
+	second := createNote(t, p, `{"title":"Second"}`)
+
+	readPage := func(since string) sdk.PaginatedEnvelope[NoteChange] {
+		t.Helper()
+		req := &sdk.APIRequest{Method: "GET", Path: "/notes/changes", Query: map[string]string{"since": since, "limit": "1"}}
+		resp, err := p.HandleAPI(context.Background(), req)
+		if err != nil || resp.StatusCode != 200 {
+			t.Fatalf("listing changes since %q: %v %v", since, resp, err)
+		}
+		var page sdk.PaginatedEnvelope[NoteChange]
+		if err := json.Unmarshal(resp.Body, &page); err != nil {
+			t.Fatalf("failed to parse changes page: %v", err)
+		}
+		return page
+	}
+
+	page := readPage("")
+	if len(page.Data) != 1 || page.Data[0].ID != first || page.Data[0].Change != "created" || !page.HasMore {
+		t.Fatalf("expected first note created with more to follow, got %+v", page)
+	}
+	page = readPage(page.Cursor)
+	if len(page.Data) != 1 || page.Data[0].ID != second || page.HasMore {
+		t.Fatalf("expected second note as the last page, got %+v", page)
+	}
+	cursor := page.Cursor
+	page = readPage(cursor)
+	if len(page.Data) != 0 || page.HasMore || page.Cursor != cursor {
+		t.Errorf("expected an empty page keeping the cursor %q, got %+v", cursor, page)
+	}
+}
+
+// --- Reminder tests ---
+
+// createReminderNote is a test helper that creates a note with a reminder
+// via the API and returns its ID.
+func createReminderNote(t *testing.T, p *QuickNotesPlugin, title string, content string, remindAt time.Time) int64 {
+	t.Helper()
+
+	return createNote(t, p, fmt.Sprintf(`{"title":%q,"content":%q,"remind_at":%q}`, title, content, remindAt.Format(time.RFC3339)))
+}
+
+// reminderState returns a note's stored reminder time and when it fired.
+func reminderState(t *testing.T, p *QuickNotesPlugin, noteID int64) (remindAt string, firedAt *string) {
+	t.Helper()
+
+	err := p.db.QueryRow("SELECT remind_at, fired_at FROM note_reminders WHERE note_id = ?", noteID).Scan(&remindAt, &firedAt)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		t.Fatalf("querying reminder of note %d: %v", noteID, err)
+	}
+	return remindAt, firedAt
+}
+
+// strPtr returns a pointer to s.
+func strPtr(s string) *string {
+	return &s
+}
+
+// sentNotification is a notification recorded by recordNotifications.
+type sentNotification struct {
+	title string
+	body  string
+}
+
+// recordNotifications replaces the plugin's notifier with one that records
+// what it is sent, failing while fail is set.
+func recordNotifications(p *QuickNotesPlugin, fail *bool) *[]sentNotification {
+	sent := make([]sentNotification, 0)
+	p.notify = func(title string, body string, urgent bool) error {
+		if fail != nil && *fail {
+			return fmt.Errorf("host unavailable")
+		}
+		sent = append(sent, sentNotification{title: title, body: body})
+		return nil
+	}
+	return &sent
+}
+
+func TestParseRemindAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    *string
+		wantErr bool
+	}{
+		{name: "RFC 3339 with offset", raw: `"2026-03-01T09:00:00+01:00"`, want: strPtr("2026-03-01 08:00:00")},
+		{name: "RFC 3339 in UTC", raw: `"2026-03-01T09:00:00Z"`, want: strPtr("2026-03-01 09:00:00")},
+		{name: "offset crossing midnight", raw: `"2026-03-01T01:30:00+05:30"`, want: strPtr("2026-02-28 20:00:00")},
+		{name: "stored layout taken as UTC", raw: `"2026-03-01 09:00:00"`, want: strPtr("2026-03-01 09:00:00")},
+		{name: "null clears", raw: `null`, want: nil},
+		{name: "date without time", raw: `"2026-03-01"`, wantErr: true},
+		{name: "not a date", raw: `"tomorrow"`, wantErr: true},
+		{name: "number", raw: `1772355600`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resp, err := parseRemindAt(json.RawMessage(tt.raw))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if resp == nil || resp.StatusCode != 400 {
+					t.Fatalf("expected a 400 response, got %+v", resp)
+				}
+				if code, _ := parseErrorResponse(t, resp); code != "VALIDATION_ERROR" {
+					t.Errorf("expected VALIDATION_ERROR, got %q", code)
+				}
+				return
+			}
+			if resp != nil {
+				t.Fatalf("expected no error response, got %d: %s", resp.StatusCode, string(resp.Body))
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected nil, got %s", *got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("expected %s, got %v", *tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEndOfToday_UsesLocalDay(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{name: "UTC", now: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), want: "2026-03-02 00:00:00"},
+		{name: "ahead of UTC late at night", now: time.Date(2026, 3, 1, 23, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60)), want: "2026-03-01 22:00:00"},
+		{name: "behind UTC", now: time.Date(2026, 3, 1, 20, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60)), want: "2026-03-02 05:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := endOfToday(tt.now); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetNoteReminder(t *testing.T) {
+	p := newTestPlugin(t)
+	noteID := createNote(t, p, `{"title":"Call the bank"}`)
+
+	set := func(remindAt *string) {
+		t.Helper()
+		tx, err := p.db.Begin()
+		if err != nil {
+			t.Fatalf("beginning transaction: %v", err)
+		}
+		if err := setNoteReminder(tx, noteID, remindAt); err != nil {
+			tx.Rollback()
+			t.Fatalf("setting reminder: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("committing reminder: %v", err)
+		}
+	}
+
+	set(strPtr("2026-03-01 09:00:00"))
+	if _, err := p.db.Exec("UPDATE note_reminders SET fired_at = '2026-03-01 09:00:30' WHERE note_id = ?", noteID); err != nil {
+		t.Fatalf("marking reminder fired: %v", err)
+	}
+
+	// Saving the same time keeps the reminder fired.
+	set(strPtr("2026-03-01 09:00:00"))
+	if remindAt, firedAt := reminderState(t, p, noteID); remindAt != "2026-03-01 09:00:00" || firedAt == nil {
+		t.Errorf("expected the unchanged reminder to stay fired, got %s fired %v", remindAt, firedAt)
+	}
+
+	// Moving it makes it fire again.
+	set(strPtr("2026-03-02 09:00:00"))
+	if remindAt, firedAt := reminderState(t, p, noteID); remindAt != "2026-03-02 09:00:00" || firedAt != nil {
+		t.Errorf("expected the moved reminder to be pending, got %s fired %v", remindAt, firedAt)
+	}
+
+	set(nil)
+	if remindAt, _ := reminderState(t, p, noteID); remindAt != "" {
+		t.Errorf("expected the reminder to be cleared, got %s", remindAt)
+	}
+	if note := getNote(t, p, noteID); note == nil {
+		t.Error("clearing a reminder must keep its note")
+	}
+}
+
+func TestFireDueReminders_FiresOnce(t *testing.T) {
+	p := newTestPlugin(t)
+	sent := recordNotifications(p, nil)
+	now := time.Now()
+
+	due := createReminderNote(t, p, "Call the bank", "  Ask about the card  ", now.Add(-time.Minute))
+	later := createReminderNote(t, p, "Water plants", "", now.Add(time.Hour))
+
+	for i := 0; i < 2; i++ {
+		if err := p.fireDueReminders(now); err != nil {
+			t.Fatalf("firing reminders: %v", err)
+		}
+	}
+
+	if len(*sent) != 1 {
+		t.Fatalf("expected exactly one notification, got %+v", *sent)
+	}
+	if (*sent)[0].title != "Reminder: Call the bank" || (*sent)[0].body != "Ask about the card" {
+		t.Errorf("unexpected notification %+v", (*sent)[0])
+	}
+	if _, firedAt := reminderState(t, p, due); firedAt == nil {
+		t.Error("expected the due reminder to be marked fired")
+	}
+	if _, firedAt := reminderState(t, p, later); firedAt != nil {
+		t.Error("expected the future reminder to stay pending")
+	}
+
+	// The later reminder fires once its time has come.
+	if err := p.fireDueReminders(now.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("firing reminders: %v", err)
+	}
+	if len(*sent) != 2 || (*sent)[1].title != "Reminder: Water plants" {
+		t.Errorf("expected the later reminder to fire once, got %+v", *sent)
+	}
+}
+
+func TestFireDueReminders_RetriesFailedNotification(t *testing.T) {
+	p := newTestPlugin(t)
+	fail := true
+	sent := recordNotifications(p, &fail)
+	now := time.Now()
+
+	noteID := createReminderNote(t, p, "Call the bank", "", now.Add(-time.Minute))
+
+	if err := p.fireDueReminders(now); err == nil {
+		t.Fatal("expected an error when the notification cannot be sent")
+	}
+	if _, firedAt := reminderState(t, p, noteID); firedAt != nil {
+		t.Fatal("expected the reminder to stay pending after a failed notification")
+	}
+
+	fail = false
+	if err := p.fireDueReminders(now); err != nil {
+		t.Fatalf("firing reminders: %v", err)
+	}
+	if len(*sent) != 1 {
+		t.Errorf("expected the reminder to fire on the next check, got %+v", *sent)
+	}
+}
+
+func TestListNotes_DueToday(t *testing.T) {
+	p := newTestPlugin(t)
+	recordNotifications(p, nil)
+	now := time.Now()
+
+	endOfDay, _ := time.Parse(reminderTimeLayout, endOfToday(now))
+	createReminderNote(t, p, "Fired", "", now.Add(-3*time.Hour))
+	overdue := createReminderNote(t, p, "Overdue", "", now.Add(-2*time.Hour))
+	today := createReminderNote(t, p, "Today", "", endOfDay.Add(-time.Second))
+	createReminderNote(t, p, "Tomorrow", "", endOfDay.Add(time.Hour))
+	createNote(t, p, `{"title":"No reminder"}`)
+
+	// Firing before the overdue note's time only fires the oldest reminder.
+	if err := p.fireDueReminders(now.Add(-150 * time.Minute)); err != nil {
+		t.Fatalf("firing reminders: %v", err)
+	}
+
+	ids := listNoteIDs(t, p, map[string]string{"due": "today"})
+	if len(ids) != 2 || ids[0] != overdue || ids[1] != today {
+		t.Errorf("expected overdue then today's note, got %v", ids)
+	}
+
+	resp, err := p.HandleAPI(context.Background(), &sdk.APIRequest{Method: "GET", Path: "/notes", Query: map[string]string{"due": "tomorrow"}})
+	if err != nil {
+		t.Fatalf("listing notes: unexpected error: %v", err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("expected 400 for due=tomorrow, got %d", resp.StatusCode)
+	}
+
+	widget, err := p.GetWidgetData("dashboard-widget")
+	if err != nil {
+		t.Fatalf("getting widget data: %v", err)
+	}
+	var data struct {
+		Data struct {
+			DueToday []Note `json:"due_today"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(widget, &data); err != nil {
+		t.Fatalf("failed to parse widget data: %v", err)
+	}
+	if len(data.Data.DueToday) != 2 || data.Data.DueToday[0].ID != overdue || data.Data.DueToday[1].ID != today {
+		t.Fatalf("expected the widget to list overdue then today's note, got %+v", data.Data.DueToday)
+	}
+	if data.Data.DueToday[0].RemindAt == nil {
+		t.Error("expected widget notes to carry their reminder time")
+	}
+}
+
+func TestScheduleReminders_WithoutHost(t *testing.T) {
+	p := &QuickNotesPlugin{}
+	if err := p.scheduleReminders(reminderCheckInterval); err != nil {
+		t.Errorf("expected no error scheduling without a host, got %v", err)
+	}
+}
+
+// --- Tag tests ---
+
+// parseTag parses a tag from a successful tag response.
+func parseTag(t *testing.T, resp *sdk.APIResponse) Tag {
+	t.Helper()
+
+	var tag Tag
+	if err := json.Unmarshal(parseDataObject(t, resp), &tag); err != nil {
+		t.Fatalf("failed to parse tag: %v", err)
+	}
+	return tag
+}
+
+// tagNamesAll returns the names of every tag in alphabetical order.
+func tagNamesAll(t *testing.T, p *QuickNotesPlugin) []string {
+	t.Helper()
+
+	var tags []Tag
+	if err := json.Unmarshal(parseDataObject(t, call(t, p, "GET", "/tags", "")), &tags); err != nil {
+		t.Fatalf("failed to parse tags: %v", err)
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func TestTags_CreateUpdateDelete(t *testing.T) {
+	p := newTestPlugin(t)
+
+	resp := call(t, p, "POST", "/tags", `{"name":"  work  "}`)
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected 201, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	tag := parseTag(t, resp)
+	if tag.Name != "work" || tag.Color != defaultTagColor {
+		t.Errorf("expected trimmed name and default color, got %+v", tag)
+	}
+	path := fmt.Sprintf("/tags/%d", tag.ID)
+
+	// Omitted fields keep their value.
+	resp = call(t, p, "PUT", path, `{"color":"#0070F3"}`)
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	if updated := parseTag(t, resp); updated.Name != "work" || updated.Color != "#0070F3" {
+		t.Errorf("expected recolored tag keeping its name, got %+v", updated)
+	}
+	resp = call(t, p, "PUT", path, `{"name":"office"}`)
+	if updated := parseTag(t, resp); updated.Name != "office" || updated.Color != "#0070F3" {
+		t.Errorf("expected renamed tag keeping its color, got %+v", updated)
+	}
+
+	if names := tagNamesAll(t, p); len(names) != 1 || names[0] != "office" {
+		t.Errorf("expected the renamed tag listed, got %v", names)
+	}
+
+	if resp := call(t, p, "DELETE", path, ""); resp.StatusCode != 200 {
+		t.Fatalf("expected 200 deleting tag, got %d", resp.StatusCode)
+	}
+	if resp := call(t, p, "DELETE", path, ""); resp.StatusCode != 404 {
+		t.Errorf("expected 404 deleting a deleted tag, got %d", resp.StatusCode)
+	}
+	if resp := call(t, p, "PUT", path, `{"name":"again"}`); resp.StatusCode != 404 {
+		t.Errorf("expected 404 updating a deleted tag, got %d", resp.StatusCode)
+	}
+}
+
+func TestTags_Validation(t *testing.T) {
+	p := newTestPlugin(t)
+	createTag(t, p, "work")
+	homeID := createTag(t, p, "home")
+	homePath := fmt.Sprintf("/tags/%d", homeID)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"create invalid JSON", "POST", "/tags", `{`, 400, "VALIDATION_ERROR"},
+		{"create empty name", "POST", "/tags", `{"name":""}`, 400, "VALIDATION_ERROR"},
+		{"create blank name", "POST", "/tags", `{"name":"   "}`, 400, "VALIDATION_ERROR"},
+		{"create name too long", "POST", "/tags", fmt.Sprintf(`{"name":%q}`, strings.Repeat("a", maxTagNameLength+1)), 400, "VALIDATION_ERROR"},
+		{"create named color", "POST", "/tags", `{"name":"errands","color":"red"}`, 400, "VALIDATION_ERROR"},
+		{"create short hex color", "POST", "/tags", `{"name":"errands","color":"#FFF"}`, 400, "VALIDATION_ERROR"},
+		{"create duplicate name", "POST", "/tags", `{"name":"work"}`, 409, "CONFLICT"},
+		{"update invalid JSON", "PUT", homePath, `{`, 400, "VALIDATION_ERROR"},
+		{"update blank name", "PUT", homePath, `{"name":" "}`, 400, "VALIDATION_ERROR"},
+		{"update invalid color", "PUT", homePath, `{"color":"#GGGGGG"}`, 400, "VALIDATION_ERROR"},
+		{"update to a taken name", "PUT", homePath, `{"name":"work"}`, 409, "CONFLICT"},
+		{"update missing tag", "PUT", "/tags/999", `{"name":"other"}`, 404, "NOT_FOUND"},
+		{"delete missing tag", "DELETE", "/tags/999", "", 404, "NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := call(t, p, tt.method, tt.path, tt.body)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d. Body: %s", tt.status, resp.StatusCode, string(resp.Body))
+			}
+			if code, _ := parseErrorResponse(t, resp); code != tt.code {
+				t.Errorf("expected %s, got %q", tt.code, code)
+			}
+		})
+	}
+
+	if names := tagNamesAll(t, p); strings.Join(names, ",") != "home,work" {
+		t.Errorf("expected failed requests to leave the tags alone, got %v", names)
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name      string
+		tagName   string
+		color     string
+		wantError string
+	}{
+		{"valid", "work", "#0070f3", ""},
+		{"longest name", strings.Repeat("a", maxTagNameLength), defaultTagColor, ""},
+		{"empty name", "", defaultTagColor, "name is required"},
+		{"name too long", strings.Repeat("a", maxTagNameLength+1), defaultTagColor, "name must be 50 characters or less"},
+		{"color without hash", "work", "0070F3", "color must be a valid hex color (e.g. #0070F3)"},
+		{"color too long", "work", "#0070F3FF", "color must be a valid hex color (e.g. #0070F3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := validateTag(tt.tagName, tt.color)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantError == "" {
+				if resp != nil {
+					t.Fatalf("expected the tag to be valid, got %s", string(resp.Body))
+				}
+				return
+			}
+			if resp == nil {
+				t.Fatalf("expected %q, got a valid tag", tt.wantError)
+			}
+			if _, message := parseErrorResponse(t, resp); message != tt.wantError {
+				t.Errorf("expected %q, got %q", tt.wantError, message)
+			}
+		})
+	}
+}
+
+func TestNotes_RejectMissingTagIDs(t *testing.T) {
+	p := newTestPlugin(t)
+	workID := createTag(t, p, "work")
+
+	resp := call(t, p, "POST", "/notes", `{"title":"Plan","tag_ids":[999]}`)
+	if resp.StatusCode != 400 {
+		t.Fatalf("expected 400 creating a note with a missing tag, got %d", resp.StatusCode)
+	}
+	if _, message := parseErrorResponse(t, resp); message != "tag 999 not found" {
+		t.Errorf("expected the missing tag to be named, got %q", message)
+	}
+	if ids := listNoteIDs(t, p, nil); len(ids) != 0 {
+		t.Errorf("expected no note to be created, got %v", ids)
+	}
+
+	noteID := createNote(t, p, fmt.Sprintf(`{"title":"Plan","tag_ids":[%d]}`, workID))
+	resp = putNote(t, p, noteID, fmt.Sprintf(`{"title":"Plan","tag_ids":[%d,999]}`, workID), nil)
+	if resp.StatusCode != 400 {
+		t.Fatalf("expected 400 updating a note with a missing tag, got %d", resp.StatusCode)
+	}
+	if names := tagNames(t, p, noteID); len(names) != 1 || names[0] != "work" {
+		t.Errorf("expected the note to keep its tags, got %v", names)
+	}
+
+	if resp, err := p.checkTagIDs([]int64{workID}); resp != nil || err != nil {
+		t.Errorf("expected existing tag IDs to pass, got %v %v", resp, err)
+	}
+}
+
+func TestNoteTags_ReplacedAndRemovedWithTag(t *testing.T) {
+	p := newTestPlugin(t)
+	workID := createTag(t, p, "work")
+	homeID := createTag(t, p, "home")
+	noteID := createNote(t, p, fmt.Sprintf(`{"title":"Plan","tag_ids":[%d,%d,%d]}`, workID, homeID, workID))
+
+	if names := tagNames(t, p, noteID); strings.Join(names, ",") != "home,work" {
+		t.Fatalf("expected repeated tag IDs to tag the note once, got %v", names)
+	}
+
+	// tag_ids replaces the note's tags.
+	if resp := putNote(t, p, noteID, fmt.Sprintf(`{"title":"Plan","tag_ids":[%d]}`, homeID), nil); resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	if names := tagNames(t, p, noteID); strings.Join(names, ",") != "home" {
+		t.Fatalf("expected only the home tag, got %v", names)
+	}
+
+	// Deleting a tag removes it from every note and keeps the notes.
+	other := createNote(t, p, fmt.Sprintf(`{"title":"Groceries","tag_ids":[%d,%d]}`, workID, homeID))
+	if resp := call(t, p, "DELETE", fmt.Sprintf("/tags/%d", homeID), ""); resp.StatusCode != 200 {
+		t.Fatalf("expected 200 deleting tag, got %d", resp.StatusCode)
+	}
+	var links int
+	if err := p.db.QueryRow("SELECT COUNT(*) FROM note_tags WHERE tag_id = ?", homeID).Scan(&links); err != nil {
+		t.Fatalf("counting note tags: %v", err)
+	}
+	if links != 0 {
+		t.Errorf("expected the deleted tag's links to cascade, %d left", links)
+	}
+
+	notes := make([]Note, 0, 2)
+	for _, id := range []int64{noteID, other} {
+		note := getNote(t, p, id)
+		if note == nil {
+			t.Fatalf("expected note %d to survive its tag's deletion", id)
+		}
+		notes = append(notes, *note)
+	}
+	if err := p.attachTags(notes); err != nil {
+		t.Fatalf("attaching tags: %v", err)
+	}
+	if len(notes[0].Tags) != 0 {
+		t.Errorf("expected the first note to have no tags left, got %+v", notes[0].Tags)
+	}
+	if len(notes[1].Tags) != 1 || notes[1].Tags[0].ID != workID {
+		t.Errorf("expected the second note to keep the work tag, got %+v", notes[1].Tags)
+	}
+
+	// An empty list clears the note's tags.
+	if resp := putNote(t, p, other, `{"title":"Groceries","tag_ids":[]}`, nil); resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d. Body: %s", resp.StatusCode, string(resp.Body))
+	}
+	if names := tagNames(t, p, other); len(names) != 0 {
+		t.Errorf("expected no tags, got %v", names)
+	}
+}
+
+func TestListNotes_TagFilter(t *testing.T) {
+	p := newTestPlugin(t)
+	workID := createTag(t, p, "work")
+	homeID := createTag(t, p, "home")
+	plan := createNote(t, p, fmt.Sprintf(`{"title":"Plan","tag_ids":[%d]}`, workID))
+	both := createNote(t, p, fmt.Sprintf(`{"title":"Both","tag_ids":[%d,%d]}`, workID, homeID))
+	groceries := createNote(t, p, fmt.Sprintf(`{"title":"Groceries","tag_ids":[%d]}`, homeID))
+	createNote(t, p, `{"title":"Untagged"}`)
+
+	tests := []struct {
+		tag  string
+		want []int64
+	}{
+		{"work", []int64{plan, both}},
+		{"home", []int64{both, groceries}},
+		{"Work", []int64{plan, both}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			ids := listNoteIDs(t, p, map[string]string{"tag": tt.tag})
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			if fmt.Sprint(ids) != fmt.Sprint(append([]int64{}, tt.want...)) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+
+	if resp := call(t, p, "DELETE", fmt.Sprintf("/tags/%d", workID), ""); resp.StatusCode != 200 {
+		t.Fatalf("expected 200 deleting tag, got %d", resp.StatusCode)
+	}
+	if ids := listNoteIDs(t, p, map[string]string{"tag": "work"}); len(ids) != 0 {
+		t.Errorf("expected no notes for a deleted tag, got %v", ids)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// reminderCheckInterval is how often the plugin looks for due reminders, and
+// so how late a reminder may fire.
+const reminderCheckInterval = time.Minute
+
+// reminderTimeLayout is the UTC format reminders are stored and returned in,
+// the same as the notes' timestamps.
+const reminderTimeLayout = "2006-01-02 15:04:05"
+
+// reminderBodyLength is how much of a note's content its reminder shows.
+const reminderBodyLength = 200
+
+// parseRemindAt reads a remind_at field: an RFC 3339 timestamp, or one in
+// reminderTimeLayout taken as UTC. It returns nil for null.
+func parseRemindAt(raw json.RawMessage) (*string, *sdk.APIResponse, error) {
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		resp, err := sdk.Error(400, "VALIDATION_ERROR", "remind_at must be a timestamp or null")
+		return nil, resp, err
+	}
+	if value == nil {
+		return nil, nil, nil
+	}
+
+	at, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		at, err = time.Parse(reminderTimeLayout, *value)
+	}
+	if err != nil {
+		resp, err := sdk.Error(400, "VALIDATION_ERROR", "remind_at must be an RFC 3339 timestamp, such as 2026-03-01T09:00:00+01:00")
+		return nil, resp, err
+	}
+
+	stored := at.UTC().Format(reminderTimeLayout)
+	return &stored, nil, nil
+}
+
+// setNoteReminder sets or, for nil, clears a note's reminder. Moving a
+// reminder makes it fire again; saving the same time keeps it as it was.
+func setNoteReminder(tx *sql.Tx, noteID int64, remindAt *string) error {
+	if remindAt == nil {
+		if _, err := tx.Exec("DELETE FROM note_reminders WHERE note_id = ?", noteID); err != nil {
+			return fmt.Errorf("clearing note reminder: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO note_reminders (note_id, remind_at) VALUES (?, ?)
+		 ON CONFLICT(note_id) DO UPDATE SET remind_at = excluded.remind_at, fired_at = NULL
+		 WHERE note_reminders.remind_at != excluded.remind_at`,
+		noteID, *remindAt,
+	); err != nil {
+		return fmt.Errorf("setting note reminder: %w", err)
+	}
+	return nil
+}
+
+// attachReminders fills in the reminder time of each note with a single query.
+func (p *QuickNotesPlugin) attachReminders(notes []Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(notes))
+	placeholders := make([]string, len(notes))
+	idToIdx := make(map[int64]int, len(notes))
+	for i, n := range notes {
+		ids[i] = n.ID
+		placeholders[i] = "?"
+		idToIdx[n.ID] = i
+	}
+
+	rows, err := p.db.Query(
+		fmt.Sprintf("SELECT note_id, remind_at FROM note_reminders WHERE note_id IN (%s)", strings.Join(placeholders, ",")),
+		ids...,
+	)
+	if err != nil {
+		return fmt.Errorf("querying note reminders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noteID int64
+		var remindAt string
+		if err := rows.Scan(&noteID, &remindAt); err != nil {
+			return fmt.Errorf("scanning note reminder: %w", err)
+		}
+		if idx, ok := idToIdx[noteID]; ok {
+			notes[idx].RemindAt = &remindAt
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating note reminders: %w", err)
+	}
+	return nil
+}
+
+// endOfToday returns the end of the local day of now, in reminderTimeLayout.
+// Reminders are compared as UTC strings, the day is the user's.
+func endOfToday(now time.Time) string {
+	year, month, day := now.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()).UTC().Format(reminderTimeLayout)
+}
+
+// --- Reminder delivery ---
+
+// fireDueReminders sends a notification for every reminder whose time has
+// passed and marks it fired. A reminder whose notification fails is left
+// pending and retried on the next check.
+func (p *QuickNotesPlugin) fireDueReminders(now time.Time) error {
+	rows, err := p.db.Query(
+		`SELECT r.note_id, r.remind_at, n.title, n.content
+		 FROM note_reminders r JOIN notes n ON n.id = r.note_id
+		 WHERE r.fired_at IS NULL AND r.remind_at <= ?
+		 ORDER BY r.remind_at`,
+		now.UTC().Format(reminderTimeLayout),
+	)
+	if err != nil {
+		return fmt.Errorf("querying due reminders: %w", err)
+	}
+
+	type dueReminder struct {
+		noteID   int64
+		remindAt string
+		title    string
+		content  string
+	}
+	due := make([]dueReminder, 0)
+	for rows.Next() {
+		var reminder dueReminder
+		if err := rows.Scan(&reminder.noteID, &reminder.remindAt, &reminder.title, &reminder.content); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning due reminder: %w", err)
+		}
+		due = append(due, reminder)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating due reminders: %w", err)
+	}
+
+	notify := p.notify
+	if notify == nil {
+		notify = sdk.SendNotification
+	}
+	for _, reminder := range due {
+		if err := notify("Reminder: "+reminder.title, reminderBody(reminder.content), false); err != nil {
+			return fmt.Errorf("sending reminder for note %d: %w", reminder.noteID, err)
+		}
+		// Only the reminder that fired is marked, in case it was moved meanwhile.
+		if _, err := p.db.Exec(
+			"UPDATE note_reminders SET fired_at = ? WHERE note_id = ? AND remind_at = ?",
+			now.UTC().Format(reminderTimeLayout), reminder.noteID, reminder.remindAt,
+		); err != nil {
+			return fmt.Errorf("marking reminder fired: %w", err)
+		}
+	}
+	return nil
+}
+
+// scheduleReminders has the host check for due reminders on every interval
+// while the plugin is loaded. Without a host, as in tests, nothing is
+// scheduled.
+func (p *QuickNotesPlugin) scheduleReminders(interval time.Duration) error {
+	if p.host == nil {
+		return nil
+	}
+	// A failing check changes nothing; the host logs it and it is retried on
+	// the next run.
+	return p.host.Schedule("reminders", interval, func() error {
+		return p.fireDueReminders(time.Now())
+	})
+}
+
+// reminderBody returns the start of a note's content for its notification.
+func reminderBody(content string) string {
+	content = strings.TrimSpace(content)
+	if utf8.RuneCountInString(content) <= reminderBodyLength {
+		return content
+	}
+	runes := []rune(content)
+	return strings.TrimSpace(string(runes[:reminderBodyLength])) + "…"
+}
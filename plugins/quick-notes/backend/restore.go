@@ -0,0 +1,62 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// deletedNoteKind names a deleted note handed back to RestoreDeleted.
+const deletedNoteKind = "note"
+
+// snapshotNote copies a note with its tags, reminder and notebook before it is deleted,
+// in the order RestoreDeleted inserts them back.
+func snapshotNote(tx *sql.Tx, id string) ([]sdk.DeletedRows, error) {
+	tables := []struct{ table, where string }{
+		{"notes", "id = ?"},
+		{"note_tags", "note_id = ?"},
+		{"note_reminders", "note_id = ?"},
+		{"note_notebooks", "note_id = ?"},
+	}
+
+	snapshots := make([]sdk.DeletedRows, 0, len(tables))
+	for _, table := range tables {
+		snapshot, err := sdk.SnapshotRows(tx, table.table, table.where, id)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// RestoreDeleted puts back a note removed by DELETE /notes/{id}, or the notes
+// of a bulk delete, with their original IDs, tags, reminders and notebooks,
+// when the host undoes the delete. Tags and notebooks deleted since are left
+// off.
+func (p *QuickNotesPlugin) RestoreDeleted(kind string, data []byte) error {
+	if kind != deletedNoteKind {
+		return fmt.Errorf("cannot restore deleted %q", kind)
+	}
+	snapshots, err := sdk.DecodeDeletedRows(data)
+	if err != nil {
+		return err
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := sdk.RestoreRows(tx, snapshots); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	notifyNotesChanged()
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+// Search finds notes whose title, content or tags contain query, for the
+// host's global search. Title matches come first, then the most recently
+// updated notes.
+func (p *QuickNotesPlugin) Search(query string) ([]sdk.SearchResult, error) {
+	pattern := "%" + escapeLike(strings.TrimSpace(query)) + "%"
+	rows, err := p.db.Query(
+		`SELECT n.id, n.title, n.content
+		 FROM notes n
+		 WHERE n.title LIKE ? ESCAPE '\' OR n.content LIKE ? ESCAPE '\'
+		    OR EXISTS (
+		       SELECT 1 FROM note_tags nt JOIN tags t ON t.id = nt.tag_id
+		       WHERE nt.note_id = n.id AND t.name LIKE ? ESCAPE '\'
+		    )
+		 ORDER BY n.title LIKE ? ESCAPE '\' DESC, n.updated_at DESC
+		 LIMIT ?`,
+		pattern, pattern, pattern, pattern, sdk.MaxSearchResults,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching notes: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]sdk.SearchResult, 0)
+	for rows.Next() {
+		var id int64
+		var title, content string
+		if err := rows.Scan(&id, &title, &content); err != nil {
+			return nil, fmt.Errorf("scanning note: %w", err)
+		}
+		results = append(results, sdk.SearchResult{
+			Type:    "note",
+			ID:      strconv.FormatInt(id, 10),
+			Title:   title,
+			Snippet: sdk.SearchSnippet(content, query),
+			Link:    "/plugins/quick-notes",
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notes: %w", err)
+	}
+
+	return results, nil
+}
+
+// escapeLike escapes LIKE wildcard characters (%, _) in a search string.
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `%`, `\%`)
+	s = strings.ReplaceAll(s, `_`, `\_`)
+	return s
+}
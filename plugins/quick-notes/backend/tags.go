@@ -0,0 +1,234 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alvarotorresc/cortex/pkg/sdk"
+)
+
+const (
+	maxTagNameLength = 50
+	defaultTagColor  = "#6B7280"
+)
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Tag is a label shared across notes.
+type Tag struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// --- Tag handlers ---
+
+func (p *QuickNotesPlugin) listTags() (*sdk.APIResponse, error) {
+	rows, err := p.db.Query("SELECT id, name, color FROM tags ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("querying tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]Tag, 0)
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating tags: %w", err)
+	}
+
+	return sdk.Success(200, tags)
+}
+
+func (p *QuickNotesPlugin) createTag(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	var input struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Color == "" {
+		input.Color = defaultTagColor
+	}
+	if resp, err := validateTag(input.Name, input.Color); resp != nil || err != nil {
+		return resp, err
+	}
+
+	result, err := p.db.Exec("INSERT INTO tags (name, color) VALUES (?, ?)", input.Name, input.Color)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return sdk.Error(409, "CONFLICT", "a tag with this name already exists")
+		}
+		return nil, fmt.Errorf("inserting tag: %w", err)
+	}
+
+	id, _ := result.LastInsertId()
+	return sdk.Success(201, Tag{ID: id, Name: input.Name, Color: input.Color})
+}
+
+// updateTag renames or recolors a tag. Omitted fields keep their value.
+func (p *QuickNotesPlugin) updateTag(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id := extractID(req.Path, "/tags/")
+	if id == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "missing tag ID")
+	}
+
+	var tag Tag
+	err := p.db.QueryRow("SELECT id, name, color FROM tags WHERE id = ?", id).Scan(&tag.ID, &tag.Name, &tag.Color)
+	if err == sql.ErrNoRows {
+		return sdk.Error(404, "NOT_FOUND", "tag not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying tag: %w", err)
+	}
+
+	var input struct {
+		Name  *string `json:"name"`
+		Color *string `json:"color"`
+	}
+
+	if err := json.Unmarshal(req.Body, &input); err != nil {
+		return sdk.Error(400, "VALIDATION_ERROR", "invalid JSON body")
+	}
+
+	if input.Name != nil {
+		tag.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Color != nil {
+		tag.Color = *input.Color
+	}
+	if resp, err := validateTag(tag.Name, tag.Color); resp != nil || err != nil {
+		return resp, err
+	}
+
+	if _, err := p.db.Exec("UPDATE tags SET name = ?, color = ? WHERE id = ?", tag.Name, tag.Color, tag.ID); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return sdk.Error(409, "CONFLICT", "a tag with this name already exists")
+		}
+		return nil, fmt.Errorf("updating tag: %w", err)
+	}
+
+	notifyNotesChanged()
+	return sdk.Success(200, tag)
+}
+
+// deleteTag removes a tag from every note that has it.
+func (p *QuickNotesPlugin) deleteTag(req *sdk.APIRequest) (*sdk.APIResponse, error) {
+	id := extractID(req.Path, "/tags/")
+	if id == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "missing tag ID")
+	}
+
+	result, err := p.db.Exec("DELETE FROM tags WHERE id = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("deleting tag: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sdk.Error(404, "NOT_FOUND", "tag not found")
+	}
+
+	notifyNotesChanged()
+	return sdk.Success(200, map[string]interface{}{"deleted": id})
+}
+
+// --- Helpers ---
+
+func validateTag(name, color string) (*sdk.APIResponse, error) {
+	if name == "" {
+		return sdk.Error(400, "VALIDATION_ERROR", "name is required")
+	}
+	if len(name) > maxTagNameLength {
+		return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("name must be %d characters or less", maxTagNameLength))
+	}
+	if !hexColorRegex.MatchString(color) {
+		return sdk.Error(400, "VALIDATION_ERROR", "color must be a valid hex color (e.g. #0070F3)")
+	}
+	return nil, nil
+}
+
+// checkTagIDs returns an error response unless every ID names an existing tag.
+func (p *QuickNotesPlugin) checkTagIDs(tagIDs []int64) (*sdk.APIResponse, error) {
+	for _, tagID := range tagIDs {
+		var exists bool
+		if err := p.db.QueryRow("SELECT EXISTS(SELECT 1 FROM tags WHERE id = ?)", tagID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("checking tag: %w", err)
+		}
+		if !exists {
+			return sdk.Error(400, "VALIDATION_ERROR", fmt.Sprintf("tag %d not found", tagID))
+		}
+	}
+	return nil, nil
+}
+
+// setNoteTags replaces a note's tags with the given tag IDs.
+func setNoteTags(tx *sql.Tx, noteID int64, tagIDs []int64) error {
+	if _, err := tx.Exec("DELETE FROM note_tags WHERE note_id = ?", noteID); err != nil {
+		return fmt.Errorf("removing note tags: %w", err)
+	}
+	for _, tagID := range tagIDs {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO note_tags (note_id, tag_id) VALUES (?, ?)", noteID, tagID); err != nil {
+			return fmt.Errorf("inserting note tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachTags fills in the tags of each note with a single query.
+func (p *QuickNotesPlugin) attachTags(notes []Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(notes))
+	placeholders := make([]string, len(notes))
+	idToIdx := make(map[int64]int, len(notes))
+	for i, n := range notes {
+		ids[i] = n.ID
+		placeholders[i] = "?"
+		idToIdx[n.ID] = i
+		notes[i].Tags = make([]Tag, 0)
+	}
+
+	rows, err := p.db.Query(
+		fmt.Sprintf(
+			"SELECT nt.note_id, t.id, t.name, t.color FROM note_tags nt JOIN tags t ON t.id = nt.tag_id WHERE nt.note_id IN (%s) ORDER BY t.name",
+			strings.Join(placeholders, ","),
+		),
+		ids...,
+	)
+	if err != nil {
+		return fmt.Errorf("querying note tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noteID int64
+		var tag Tag
+		if err := rows.Scan(&noteID, &tag.ID, &tag.Name, &tag.Color); err != nil {
+			return fmt.Errorf("scanning note tag: %w", err)
+		}
+		if idx, ok := idToIdx[noteID]; ok {
+			notes[idx].Tags = append(notes[idx].Tags, tag)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating note tags: %w", err)
+	}
+	return nil
+}